@@ -106,3 +106,41 @@ export function queryTradingVolume(params, cb) {
 }
 
 
+
+const adminTokenKey = "bbgo-admin-token"
+
+export function loadAdminToken() {
+    if (typeof window === "undefined") {
+        return ""
+    }
+
+    return window.localStorage.getItem(adminTokenKey) || ""
+}
+
+export function saveAdminToken(token) {
+    window.localStorage.setItem(adminTokenKey, token)
+}
+
+export function queryDashboard(token, cb) {
+    return axios.get(baseURL + '/api/admin/dashboard', {headers: {Authorization: `Bearer ${token}`}})
+        .then(response => {
+            cb(response.data)
+        });
+}
+
+// connectDashboard opens the websocket of the dashboard, the dashboard snapshot is pushed every few seconds
+export function connectDashboard(token, onSnapshot, onClose) {
+    let url = baseURL
+    if (!url) {
+        url = window.location.protocol + "//" + window.location.host
+    }
+
+    url = url.replace(/^http/, "ws") + '/api/admin/dashboard/ws?token=' + encodeURIComponent(token)
+
+    const ws = new WebSocket(url)
+    ws.onmessage = (event) => {
+        onSnapshot(JSON.parse(event.data))
+    }
+    ws.onclose = onClose
+    return ws
+}
@@ -0,0 +1,44 @@
+import {ResponsiveBar} from '@nivo/bar';
+
+function toTimeString(time) {
+    const t = new Date(time)
+    return t.getHours() + ":" + String(t.getMinutes()).padStart(2, "0")
+}
+
+export default function PnLBar(props) {
+    const data = (props.pnl || []).map((p) => {
+        return {
+            time: toTimeString(p.time),
+            realized: Math.round(p.realized * 100) / 100,
+            unrealized: Math.round(p.unrealized * 100) / 100,
+        }
+    })
+
+    return <div style={{height: props.height || 240}}>
+        <ResponsiveBar
+            data={data}
+            keys={["realized", "unrealized"]}
+            indexBy="time"
+            margin={{top: 10, right: 110, bottom: 40, left: 60}}
+            padding={0.2}
+            groupMode="grouped"
+            colors={{scheme: 'paired'}}
+            axisBottom={{
+                tickRotation: -45,
+                tickValues: data.filter((d, i) => i % Math.max(1, Math.ceil(data.length / 12)) === 0).map((d) => d.time),
+            }}
+            enableLabel={false}
+            legends={[
+                {
+                    dataFrom: 'keys',
+                    anchor: 'bottom-right',
+                    direction: 'column',
+                    translateX: 120,
+                    itemWidth: 100,
+                    itemHeight: 20,
+                },
+            ]}
+            animate={false}
+        />
+    </div>
+}
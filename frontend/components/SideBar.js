@@ -8,6 +8,7 @@ import DashboardIcon from "@material-ui/icons/Dashboard";
 import ListItemText from "@material-ui/core/ListItemText";
 import ListIcon from "@material-ui/icons/List";
 import TrendingUpIcon from "@material-ui/icons/TrendingUp";
+import TimelineIcon from "@material-ui/icons/Timeline";
 import React from "react";
 import {makeStyles} from "@material-ui/core/styles";
 
@@ -70,6 +71,14 @@ export default function SideBar() {
                     <ListItemText primary="Dashboard"/>
                 </ListItem>
             </Link>
+            <Link href={"/live"}>
+                <ListItem button>
+                    <ListItemIcon>
+                        <TimelineIcon/>
+                    </ListItemIcon>
+                    <ListItemText primary="Live"/>
+                </ListItem>
+            </Link>
         </List>
         <Divider/>
         <List>
@@ -0,0 +1,176 @@
+import React, {useEffect, useState} from 'react';
+
+import {makeStyles} from '@material-ui/core/styles';
+import Typography from '@material-ui/core/Typography';
+import Paper from '@material-ui/core/Paper';
+import Grid from '@material-ui/core/Grid';
+import Chip from '@material-ui/core/Chip';
+import TextField from '@material-ui/core/TextField';
+import Button from '@material-ui/core/Button';
+import Table from '@material-ui/core/Table';
+import TableBody from '@material-ui/core/TableBody';
+import TableCell from '@material-ui/core/TableCell';
+import TableHead from '@material-ui/core/TableHead';
+import TableRow from '@material-ui/core/TableRow';
+import Alert from '@material-ui/lab/Alert';
+
+import DashboardLayout from '../layouts/DashboardLayout';
+import PnLBar from '../components/PnLBar';
+import {connectDashboard, loadAdminToken, queryDashboard, saveAdminToken} from '../api/bbgo';
+
+const useStyles = makeStyles((theme) => ({
+    paper: {
+        margin: theme.spacing(2),
+        padding: theme.spacing(2),
+    },
+    chip: {
+        marginRight: theme.spacing(1),
+    },
+}));
+
+function SimpleTable(props) {
+    if (!props.rows || props.rows.length === 0) {
+        return <Typography variant="body2" color="textSecondary">No {props.title.toLowerCase()}</Typography>
+    }
+
+    return <Table size="small">
+        <TableHead>
+            <TableRow>
+                {props.columns.map((c) => <TableCell key={c.field}>{c.headerName}</TableCell>)}
+            </TableRow>
+        </TableHead>
+        <TableBody>
+            {props.rows.map((row, i) =>
+                <TableRow key={i}>
+                    {props.columns.map((c) => <TableCell key={c.field}>{String(row[c.field])}</TableCell>)}
+                </TableRow>
+            )}
+        </TableBody>
+    </Table>
+}
+
+const positionColumns = [
+    {field: 'symbol', headerName: 'Symbol'},
+    {field: 'base', headerName: 'Base'},
+    {field: 'quote', headerName: 'Quote'},
+    {field: 'averageCost', headerName: 'Average Cost'},
+    {field: 'realizedProfit', headerName: 'Realized Profit'},
+];
+
+const orderColumns = [
+    {field: 'orderID', headerName: 'Order ID'},
+    {field: 'symbol', headerName: 'Symbol'},
+    {field: 'side', headerName: 'Side'},
+    {field: 'price', headerName: 'Price'},
+    {field: 'quantity', headerName: 'Quantity'},
+    {field: 'executedQuantity', headerName: 'Executed'},
+    {field: 'status', headerName: 'Status'},
+];
+
+const tradeColumns = [
+    {field: 'tradedAt', headerName: 'Trade Time'},
+    {field: 'symbol', headerName: 'Symbol'},
+    {field: 'side', headerName: 'Side'},
+    {field: 'price', headerName: 'Price'},
+    {field: 'quantity', headerName: 'Quantity'},
+    {field: 'fee', headerName: 'Fee'},
+];
+
+function LiveSession(props) {
+    const classes = useStyles();
+    const session = props.session
+
+    return <Paper className={classes.paper}>
+        <Typography variant="h5" gutterBottom>
+            {session.name}
+            {" "}
+            <Chip size="small" className={classes.chip}
+                  color={session.healthy ? "primary" : "secondary"}
+                  label={session.healthy ? "healthy" : "unhealthy"}/>
+        </Typography>
+
+        {session.health.map((h) =>
+            <Chip key={h.name} size="small" variant="outlined" className={classes.chip}
+                  label={h.name + (h.error ? ": " + h.error : "")}/>
+        )}
+
+        <Grid container spacing={2}>
+            <Grid item xs={12}>
+                <Typography variant="h6">PnL</Typography>
+                <PnLBar pnl={session.pnl}/>
+            </Grid>
+            <Grid item xs={12}>
+                <Typography variant="h6">Positions</Typography>
+                <SimpleTable title="Positions" columns={positionColumns} rows={session.positions}/>
+            </Grid>
+            <Grid item xs={12}>
+                <Typography variant="h6">Open Orders</Typography>
+                <SimpleTable title="Open Orders" columns={orderColumns} rows={session.openOrders}/>
+            </Grid>
+            <Grid item xs={12}>
+                <Typography variant="h6">Recent Trades</Typography>
+                <SimpleTable title="Recent Trades" columns={tradeColumns} rows={session.recentTrades}/>
+            </Grid>
+        </Grid>
+    </Paper>
+}
+
+export default function Live() {
+    const classes = useStyles();
+
+    const [token, setToken] = useState("")
+    const [connectedToken, setConnectedToken] = useState("")
+    const [snapshot, setSnapshot] = useState(null)
+    const [error, setError] = useState(null)
+
+    useEffect(() => {
+        const saved = loadAdminToken()
+        setToken(saved)
+        setConnectedToken(saved)
+    }, [])
+
+    useEffect(() => {
+        if (!connectedToken) {
+            return
+        }
+
+        setError(null)
+        queryDashboard(connectedToken, setSnapshot).catch(() => {
+            setError("can not load the dashboard, please check the admin token")
+        })
+
+        const ws = connectDashboard(connectedToken, setSnapshot, (event) => {
+            if (event.code !== 1000) {
+                setError("dashboard connection is closed, please check the admin token")
+            }
+        })
+
+        return () => ws.close(1000)
+    }, [connectedToken])
+
+    const connect = () => {
+        saveAdminToken(token)
+        setConnectedToken(token)
+    }
+
+    return (
+        <DashboardLayout>
+            <Paper className={classes.paper}>
+                <Typography variant="h4" gutterBottom>
+                    Live
+                </Typography>
+                <TextField label="Admin Token" type="password" size="small" value={token}
+                           onChange={(event) => setToken(event.target.value)}/>
+                {" "}
+                <Button variant="contained" color="primary" onClick={connect}>Connect</Button>
+                {error ? <Alert severity="error">{error}</Alert> : null}
+                {snapshot ?
+                    <Typography variant="body2" color="textSecondary">
+                        Updated at {new Date(snapshot.time).toLocaleTimeString()}
+                    </Typography> : null}
+            </Paper>
+
+            {snapshot ? snapshot.sessions.map((session) => <LiveSession key={session.name} session={session}/>) : null}
+        </DashboardLayout>
+    );
+}
@@ -0,0 +1,25 @@
+-- +up
+-- +begin
+CREATE TABLE `agg_trades`
+(
+    `gid`            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `id`             BIGINT UNSIGNED NOT NULL,
+    `exchange`       VARCHAR(24)     NOT NULL DEFAULT '',
+    `symbol`         VARCHAR(20)     NOT NULL,
+    `price`          DECIMAL(16, 8)  NOT NULL,
+    `quantity`       DECIMAL(16, 8)  NOT NULL,
+    `first_trade_id` BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    `last_trade_id`  BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    `is_buyer_maker` BOOLEAN         NOT NULL DEFAULT FALSE,
+    `traded_at`      DATETIME(3)     NOT NULL,
+    PRIMARY KEY (`gid`),
+    UNIQUE KEY `agg_trades_id` (`exchange`, `symbol`, `id`),
+    INDEX `agg_trades_traded_at` (`exchange`, `symbol`, `traded_at`)
+);
+-- +end
+
+-- +down
+
+-- +begin
+DROP TABLE IF EXISTS `agg_trades`;
+-- +end
@@ -0,0 +1,23 @@
+-- +up
+-- +begin
+CREATE TABLE `commands`
+(
+    `gid`          BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `type`         VARCHAR(32)     NOT NULL,
+    `strategy`     VARCHAR(64)     NOT NULL DEFAULT '',
+    `payload`      TEXT            NOT NULL,
+    `status`       VARCHAR(12)     NOT NULL DEFAULT 'pending',
+    `error`        TEXT            NULL,
+    `source`       VARCHAR(32)     NOT NULL DEFAULT '',
+    `created_at`   DATETIME(3)     NOT NULL,
+    `processed_at` DATETIME(3)     NULL,
+    PRIMARY KEY (`gid`),
+    INDEX `commands_status` (`status`, `gid`)
+);
+-- +end
+
+-- +down
+
+-- +begin
+DROP TABLE IF EXISTS `commands`;
+-- +end
@@ -0,0 +1,16 @@
+-- +up
+-- +begin
+CREATE TABLE `persistence`
+(
+    `id`         VARCHAR(255) NOT NULL,
+    `value`      MEDIUMTEXT   NOT NULL,
+    `updated_at` DATETIME(3)  NOT NULL,
+    PRIMARY KEY (`id`)
+);
+-- +end
+
+-- +down
+
+-- +begin
+DROP TABLE IF EXISTS `persistence`;
+-- +end
@@ -0,0 +1,20 @@
+-- +up
+-- +begin
+CREATE TABLE `balance_snapshots`
+(
+    `gid`      BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `session`  VARCHAR(30)     NOT NULL,
+    `exchange` VARCHAR(30)     NOT NULL,
+    `currency` VARCHAR(12)     NOT NULL,
+    `value`    DECIMAL(32, 8)  NOT NULL,
+    `time`     DATETIME(3)     NOT NULL,
+    PRIMARY KEY (`gid`),
+    INDEX `balance_snapshots_session_time` (`session`, `time`)
+);
+-- +end
+
+-- +down
+
+-- +begin
+DROP TABLE IF EXISTS `balance_snapshots`;
+-- +end
@@ -0,0 +1,25 @@
+-- +up
+CREATE TABLE "trades"
+(
+    "gid"            BIGSERIAL      NOT NULL,
+
+    "id"             BIGINT,
+    "exchange"       VARCHAR(24)    NOT NULL DEFAULT '',
+    "symbol"         VARCHAR(8)     NOT NULL,
+    "price"          DECIMAL(16, 8) NOT NULL,
+    "quantity"       DECIMAL(16, 8) NOT NULL,
+    "quote_quantity" DECIMAL(16, 8) NOT NULL,
+    "fee"            DECIMAL(16, 8) NOT NULL,
+    "fee_currency"   VARCHAR(4)     NOT NULL,
+    "is_buyer"       BOOLEAN        NOT NULL DEFAULT FALSE,
+    "is_maker"       BOOLEAN        NOT NULL DEFAULT FALSE,
+    "side"           VARCHAR(4)     NOT NULL DEFAULT '',
+    "traded_at"      TIMESTAMP(3)   NOT NULL,
+
+    PRIMARY KEY ("gid")
+);
+
+CREATE UNIQUE INDEX "trades_id" ON "trades" ("id");
+
+-- +down
+DROP TABLE IF EXISTS "trades";
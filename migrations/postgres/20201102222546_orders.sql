@@ -0,0 +1,27 @@
+-- +up
+CREATE TABLE "orders"
+(
+    "gid"               BIGSERIAL      NOT NULL,
+
+    "exchange"          VARCHAR(24)    NOT NULL DEFAULT '',
+    -- order_id is the order id returned from the exchange
+    "order_id"          BIGINT         NOT NULL,
+    "client_order_id"   VARCHAR(122)   NOT NULL DEFAULT '',
+    "order_type"        VARCHAR(16)    NOT NULL,
+    "symbol"            VARCHAR(8)     NOT NULL,
+    "status"            VARCHAR(12)    NOT NULL,
+    "time_in_force"     VARCHAR(4)     NOT NULL,
+    "price"             DECIMAL(16, 8) NOT NULL,
+    "stop_price"        DECIMAL(16, 8) NOT NULL,
+    "quantity"          DECIMAL(16, 8) NOT NULL,
+    "executed_quantity" DECIMAL(16, 8) NOT NULL DEFAULT 0.0,
+    "side"              VARCHAR(4)     NOT NULL DEFAULT '',
+    "is_working"        BOOLEAN        NOT NULL DEFAULT FALSE,
+    "created_at"        TIMESTAMP(3)   NOT NULL,
+    "updated_at"        TIMESTAMP(3)   NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+
+    PRIMARY KEY ("gid")
+);
+
+-- +down
+DROP TABLE IF EXISTS "orders";
@@ -0,0 +1,7 @@
+-- +up
+ALTER TABLE "trades"
+    ADD COLUMN "order_id" BIGINT NOT NULL;
+
+-- +down
+ALTER TABLE "trades"
+    DROP COLUMN "order_id";
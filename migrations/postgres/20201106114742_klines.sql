@@ -0,0 +1,95 @@
+-- +up
+CREATE TABLE "klines"
+(
+    "gid"           BIGSERIAL      NOT NULL,
+    "exchange"      VARCHAR(10)    NOT NULL,
+    "start_time"    TIMESTAMP(3)   NOT NULL,
+    "end_time"      TIMESTAMP(3)   NOT NULL,
+    "interval"      VARCHAR(3)     NOT NULL,
+    "symbol"        VARCHAR(7)     NOT NULL,
+    "open"          DECIMAL(16, 8) NOT NULL,
+    "high"          DECIMAL(16, 8) NOT NULL,
+    "low"           DECIMAL(16, 8) NOT NULL,
+    "close"         DECIMAL(16, 8) NOT NULL DEFAULT 0.0,
+    "volume"        DECIMAL(16, 8) NOT NULL DEFAULT 0.0,
+    "closed"        BOOLEAN        NOT NULL DEFAULT TRUE,
+    "last_trade_id" BIGINT         NOT NULL DEFAULT 0,
+    "num_trades"    BIGINT         NOT NULL DEFAULT 0,
+
+    PRIMARY KEY ("gid")
+);
+
+CREATE INDEX "klines_end_time_symbol_interval" ON "klines" ("end_time", "symbol", "interval");
+
+-- the tables are created separately since postgres shares the sequence of the copied serial column
+CREATE TABLE "okex_klines"
+(
+    "gid"           BIGSERIAL      NOT NULL,
+    "exchange"      VARCHAR(10)    NOT NULL,
+    "start_time"    TIMESTAMP(3)   NOT NULL,
+    "end_time"      TIMESTAMP(3)   NOT NULL,
+    "interval"      VARCHAR(3)     NOT NULL,
+    "symbol"        VARCHAR(7)     NOT NULL,
+    "open"          DECIMAL(16, 8) NOT NULL,
+    "high"          DECIMAL(16, 8) NOT NULL,
+    "low"           DECIMAL(16, 8) NOT NULL,
+    "close"         DECIMAL(16, 8) NOT NULL DEFAULT 0.0,
+    "volume"        DECIMAL(16, 8) NOT NULL DEFAULT 0.0,
+    "closed"        BOOLEAN        NOT NULL DEFAULT TRUE,
+    "last_trade_id" BIGINT         NOT NULL DEFAULT 0,
+    "num_trades"    BIGINT         NOT NULL DEFAULT 0,
+
+    PRIMARY KEY ("gid")
+);
+
+CREATE INDEX "okex_klines_end_time_symbol_interval" ON "okex_klines" ("end_time", "symbol", "interval");
+
+CREATE TABLE "binance_klines"
+(
+    "gid"           BIGSERIAL      NOT NULL,
+    "exchange"      VARCHAR(10)    NOT NULL,
+    "start_time"    TIMESTAMP(3)   NOT NULL,
+    "end_time"      TIMESTAMP(3)   NOT NULL,
+    "interval"      VARCHAR(3)     NOT NULL,
+    "symbol"        VARCHAR(7)     NOT NULL,
+    "open"          DECIMAL(16, 8) NOT NULL,
+    "high"          DECIMAL(16, 8) NOT NULL,
+    "low"           DECIMAL(16, 8) NOT NULL,
+    "close"         DECIMAL(16, 8) NOT NULL DEFAULT 0.0,
+    "volume"        DECIMAL(16, 8) NOT NULL DEFAULT 0.0,
+    "closed"        BOOLEAN        NOT NULL DEFAULT TRUE,
+    "last_trade_id" BIGINT         NOT NULL DEFAULT 0,
+    "num_trades"    BIGINT         NOT NULL DEFAULT 0,
+
+    PRIMARY KEY ("gid")
+);
+
+CREATE INDEX "binance_klines_end_time_symbol_interval" ON "binance_klines" ("end_time", "symbol", "interval");
+
+CREATE TABLE "max_klines"
+(
+    "gid"           BIGSERIAL      NOT NULL,
+    "exchange"      VARCHAR(10)    NOT NULL,
+    "start_time"    TIMESTAMP(3)   NOT NULL,
+    "end_time"      TIMESTAMP(3)   NOT NULL,
+    "interval"      VARCHAR(3)     NOT NULL,
+    "symbol"        VARCHAR(7)     NOT NULL,
+    "open"          DECIMAL(16, 8) NOT NULL,
+    "high"          DECIMAL(16, 8) NOT NULL,
+    "low"           DECIMAL(16, 8) NOT NULL,
+    "close"         DECIMAL(16, 8) NOT NULL DEFAULT 0.0,
+    "volume"        DECIMAL(16, 8) NOT NULL DEFAULT 0.0,
+    "closed"        BOOLEAN        NOT NULL DEFAULT TRUE,
+    "last_trade_id" BIGINT         NOT NULL DEFAULT 0,
+    "num_trades"    BIGINT         NOT NULL DEFAULT 0,
+
+    PRIMARY KEY ("gid")
+);
+
+CREATE INDEX "max_klines_end_time_symbol_interval" ON "max_klines" ("end_time", "symbol", "interval");
+
+-- +down
+DROP TABLE IF EXISTS "binance_klines";
+DROP TABLE IF EXISTS "okex_klines";
+DROP TABLE IF EXISTS "max_klines";
+DROP TABLE IF EXISTS "klines";
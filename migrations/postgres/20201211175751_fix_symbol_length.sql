@@ -0,0 +1,7 @@
+-- +up
+ALTER TABLE trades ALTER COLUMN symbol TYPE VARCHAR(9);
+ALTER TABLE orders ALTER COLUMN symbol TYPE VARCHAR(9);
+
+-- +down
+ALTER TABLE trades ALTER COLUMN symbol TYPE VARCHAR(8);
+ALTER TABLE orders ALTER COLUMN symbol TYPE VARCHAR(8);
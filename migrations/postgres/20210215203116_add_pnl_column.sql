@@ -0,0 +1,9 @@
+-- +up
+ALTER TABLE "trades" ADD COLUMN "pnl" DECIMAL NULL;
+
+ALTER TABLE "trades" ADD COLUMN "strategy" VARCHAR(32) NULL;
+
+-- +down
+ALTER TABLE "trades" DROP COLUMN "pnl";
+
+ALTER TABLE "trades" DROP COLUMN "strategy";
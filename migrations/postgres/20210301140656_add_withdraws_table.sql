@@ -0,0 +1,25 @@
+-- +up
+CREATE TABLE "withdraws"
+(
+    "gid"              BIGSERIAL      NOT NULL,
+    "exchange"         VARCHAR(24)    NOT NULL DEFAULT '',
+
+    -- asset is the asset name (currency)
+    "asset"            VARCHAR(10)    NOT NULL,
+
+    "address"          VARCHAR(128)   NOT NULL,
+    "network"          VARCHAR(32)    NOT NULL DEFAULT '',
+
+    "amount"           DECIMAL(16, 8) NOT NULL,
+    "txn_id"           VARCHAR(256)   NOT NULL,
+    "txn_fee"          DECIMAL(16, 8) NOT NULL DEFAULT 0,
+    "txn_fee_currency" VARCHAR(32)    NOT NULL DEFAULT '',
+    "time"             TIMESTAMP(3)   NOT NULL,
+
+    PRIMARY KEY ("gid")
+);
+
+CREATE UNIQUE INDEX "withdraws_txn_id" ON "withdraws" ("exchange", "txn_id");
+
+-- +down
+DROP TABLE IF EXISTS "withdraws";
@@ -0,0 +1,21 @@
+-- +up
+CREATE TABLE "deposits"
+(
+    "gid"      BIGSERIAL      NOT NULL,
+    "exchange" VARCHAR(24)    NOT NULL,
+
+    -- asset is the asset name (currency)
+    "asset"    VARCHAR(10)    NOT NULL,
+
+    "address"  VARCHAR(128)   NOT NULL DEFAULT '',
+    "amount"   DECIMAL(16, 8) NOT NULL,
+    "txn_id"   VARCHAR(256)   NOT NULL,
+    "time"     TIMESTAMP(3)   NOT NULL,
+
+    PRIMARY KEY ("gid")
+);
+
+CREATE UNIQUE INDEX "deposits_txn_id" ON "deposits" ("exchange", "txn_id");
+
+-- +down
+DROP TABLE IF EXISTS "deposits";
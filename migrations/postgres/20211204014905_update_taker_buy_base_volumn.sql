@@ -0,0 +1,9 @@
+-- +up
+ALTER TABLE binance_klines ALTER COLUMN taker_buy_base_volume TYPE DECIMAL(32, 8);
+ALTER TABLE max_klines ALTER COLUMN taker_buy_base_volume TYPE DECIMAL(32, 8);
+ALTER TABLE okex_klines ALTER COLUMN taker_buy_base_volume TYPE DECIMAL(32, 8);
+
+-- +down
+ALTER TABLE binance_klines ALTER COLUMN taker_buy_base_volume TYPE DECIMAL(16, 8);
+ALTER TABLE max_klines ALTER COLUMN taker_buy_base_volume TYPE DECIMAL(16, 8);
+ALTER TABLE okex_klines ALTER COLUMN taker_buy_base_volume TYPE DECIMAL(16, 8);
@@ -0,0 +1,28 @@
+-- +up
+CREATE TABLE IF NOT EXISTS "ftx_klines"
+(
+    "gid"                    BIGSERIAL      NOT NULL,
+    "exchange"               VARCHAR(10)    NOT NULL,
+    "start_time"             TIMESTAMP(3)   NOT NULL,
+    "end_time"               TIMESTAMP(3)   NOT NULL,
+    "interval"               VARCHAR(3)     NOT NULL,
+    "symbol"                 VARCHAR(12)    NOT NULL,
+    "open"                   DECIMAL(16, 8) NOT NULL,
+    "high"                   DECIMAL(16, 8) NOT NULL,
+    "low"                    DECIMAL(16, 8) NOT NULL,
+    "close"                  DECIMAL(16, 8) NOT NULL DEFAULT 0.0,
+    "volume"                 DECIMAL(20, 8) NOT NULL DEFAULT 0.0,
+    "closed"                 BOOLEAN        NOT NULL DEFAULT TRUE,
+    "last_trade_id"          BIGINT         NOT NULL DEFAULT 0,
+    "num_trades"             BIGINT         NOT NULL DEFAULT 0,
+    "quote_volume"           DECIMAL(32, 4) NOT NULL DEFAULT 0.0,
+    "taker_buy_base_volume"  DECIMAL(32, 8) NOT NULL,
+    "taker_buy_quote_volume" DECIMAL(32, 4) NOT NULL DEFAULT 0.0,
+
+    PRIMARY KEY ("gid")
+);
+
+CREATE INDEX "ftx_klines_end_time_symbol_interval" ON "ftx_klines" ("end_time", "symbol", "interval");
+
+-- +down
+DROP TABLE IF EXISTS "ftx_klines";
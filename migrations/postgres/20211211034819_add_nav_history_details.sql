@@ -0,0 +1,21 @@
+-- +up
+CREATE TABLE "nav_history_details"
+(
+    "gid"            BIGSERIAL      NOT NULL,
+    "exchange"       VARCHAR(30)    NOT NULL,
+    "subaccount"     VARCHAR(30)    NOT NULL,
+    "time"           TIMESTAMP(3)   NOT NULL,
+    "currency"       VARCHAR(12)    NOT NULL,
+    "balance_in_usd" DECIMAL(32, 8) NOT NULL DEFAULT 0.0,
+    "balance_in_btc" DECIMAL(32, 8) NOT NULL DEFAULT 0.0,
+    "balance"        DECIMAL(32, 8) NOT NULL DEFAULT 0.0,
+    "available"      DECIMAL(32, 8) NOT NULL DEFAULT 0.0,
+    "locked"         DECIMAL(32, 8) NOT NULL DEFAULT 0.0,
+
+    PRIMARY KEY ("gid")
+);
+
+CREATE INDEX "idx_nav_history_details" ON "nav_history_details" ("time", "currency", "exchange");
+
+-- +down
+DROP TABLE IF EXISTS "nav_history_details";
@@ -0,0 +1,5 @@
+-- +up
+ALTER TABLE trades ALTER COLUMN fee_currency TYPE VARCHAR(10);
+
+-- +down
+ALTER TABLE trades ALTER COLUMN fee_currency TYPE VARCHAR(4);
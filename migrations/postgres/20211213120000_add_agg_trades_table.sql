@@ -0,0 +1,22 @@
+-- +up
+CREATE TABLE "agg_trades"
+(
+    "gid"            BIGSERIAL      NOT NULL,
+    "id"             BIGINT         NOT NULL,
+    "exchange"       VARCHAR(24)    NOT NULL DEFAULT '',
+    "symbol"         VARCHAR(20)    NOT NULL,
+    "price"          DECIMAL(16, 8) NOT NULL,
+    "quantity"       DECIMAL(16, 8) NOT NULL,
+    "first_trade_id" BIGINT         NOT NULL DEFAULT 0,
+    "last_trade_id"  BIGINT         NOT NULL DEFAULT 0,
+    "is_buyer_maker" BOOLEAN        NOT NULL DEFAULT FALSE,
+    "traded_at"      TIMESTAMP(3)   NOT NULL,
+
+    PRIMARY KEY ("gid")
+);
+
+CREATE UNIQUE INDEX "agg_trades_id" ON "agg_trades" ("exchange", "symbol", "id");
+CREATE INDEX "agg_trades_traded_at" ON "agg_trades" ("exchange", "symbol", "traded_at");
+
+-- +down
+DROP TABLE IF EXISTS "agg_trades";
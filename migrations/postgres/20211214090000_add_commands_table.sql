@@ -0,0 +1,20 @@
+-- +up
+CREATE TABLE "commands"
+(
+    "gid"          BIGSERIAL    NOT NULL,
+    "type"         VARCHAR(32)  NOT NULL,
+    "strategy"     VARCHAR(64)  NOT NULL DEFAULT '',
+    "payload"      TEXT         NOT NULL,
+    "status"       VARCHAR(12)  NOT NULL DEFAULT 'pending',
+    "error"        TEXT         NULL,
+    "source"       VARCHAR(32)  NOT NULL DEFAULT '',
+    "created_at"   TIMESTAMP(3) NOT NULL,
+    "processed_at" TIMESTAMP(3) NULL,
+
+    PRIMARY KEY ("gid")
+);
+
+CREATE INDEX "commands_status" ON "commands" ("status", "gid");
+
+-- +down
+DROP TABLE IF EXISTS "commands";
@@ -0,0 +1,12 @@
+-- +up
+CREATE TABLE "persistence"
+(
+    "id"         VARCHAR(255) NOT NULL,
+    "value"      TEXT         NOT NULL,
+    "updated_at" TIMESTAMP(3) NOT NULL,
+
+    PRIMARY KEY ("id")
+);
+
+-- +down
+DROP TABLE IF EXISTS "persistence";
@@ -0,0 +1,21 @@
+-- +up
+CREATE TABLE "profits"
+(
+    "gid"                  BIGSERIAL      NOT NULL,
+    "strategy"             VARCHAR(32)    NOT NULL,
+    "strategy_instance_id" VARCHAR(64)    NOT NULL DEFAULT '',
+    "symbol"               VARCHAR(20)    NOT NULL,
+    "base_currency"        VARCHAR(10)    NOT NULL,
+    "quote_currency"       VARCHAR(10)    NOT NULL,
+    "profit"               DECIMAL(16, 8) NOT NULL,
+    "net_profit"           DECIMAL(16, 8) NOT NULL,
+    "trade_amount"         DECIMAL(16, 8) NOT NULL,
+    "time"                 TIMESTAMP(3)   NOT NULL,
+
+    PRIMARY KEY ("gid")
+);
+
+CREATE INDEX "profits_strategy_time" ON "profits" ("strategy", "time");
+
+-- +down
+DROP TABLE IF EXISTS "profits";
@@ -0,0 +1,17 @@
+-- +up
+CREATE TABLE "balance_snapshots"
+(
+    "gid"      BIGSERIAL      NOT NULL,
+    "session"  VARCHAR(30)    NOT NULL,
+    "exchange" VARCHAR(30)    NOT NULL,
+    "currency" VARCHAR(12)    NOT NULL,
+    "value"    DECIMAL(32, 8) NOT NULL,
+    "time"     TIMESTAMP(3)   NOT NULL,
+
+    PRIMARY KEY ("gid")
+);
+
+CREATE INDEX "balance_snapshots_session_time" ON "balance_snapshots" ("session", "time");
+
+-- +down
+DROP TABLE IF EXISTS "balance_snapshots";
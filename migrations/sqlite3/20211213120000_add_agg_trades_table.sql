@@ -0,0 +1,30 @@
+-- +up
+-- +begin
+CREATE TABLE `agg_trades`
+(
+    `gid`            INTEGER PRIMARY KEY AUTOINCREMENT,
+    `id`             INTEGER        NOT NULL,
+    `exchange`       VARCHAR(24)    NOT NULL DEFAULT '',
+    `symbol`         VARCHAR(20)    NOT NULL,
+    `price`          DECIMAL(16, 8) NOT NULL,
+    `quantity`       DECIMAL(16, 8) NOT NULL,
+    `first_trade_id` INTEGER        NOT NULL DEFAULT 0,
+    `last_trade_id`  INTEGER        NOT NULL DEFAULT 0,
+    `is_buyer_maker` BOOLEAN        NOT NULL DEFAULT FALSE,
+    `traded_at`      DATETIME(3)    NOT NULL
+);
+-- +end
+
+-- +begin
+CREATE UNIQUE INDEX `agg_trades_id` ON `agg_trades` (`exchange`, `symbol`, `id`);
+-- +end
+
+-- +begin
+CREATE INDEX `agg_trades_traded_at` ON `agg_trades` (`exchange`, `symbol`, `traded_at`);
+-- +end
+
+-- +down
+
+-- +begin
+DROP TABLE IF EXISTS `agg_trades`;
+-- +end
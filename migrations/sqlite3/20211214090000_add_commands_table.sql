@@ -0,0 +1,25 @@
+-- +up
+-- +begin
+CREATE TABLE `commands`
+(
+    `gid`          INTEGER PRIMARY KEY AUTOINCREMENT,
+    `type`         VARCHAR(32) NOT NULL,
+    `strategy`     VARCHAR(64) NOT NULL DEFAULT '',
+    `payload`      TEXT        NOT NULL,
+    `status`       VARCHAR(12) NOT NULL DEFAULT 'pending',
+    `error`        TEXT        NULL,
+    `source`       VARCHAR(32) NOT NULL DEFAULT '',
+    `created_at`   DATETIME(3) NOT NULL,
+    `processed_at` DATETIME(3) NULL
+);
+-- +end
+
+-- +begin
+CREATE INDEX `commands_status` ON `commands` (`status`, `gid`);
+-- +end
+
+-- +down
+
+-- +begin
+DROP TABLE IF EXISTS `commands`;
+-- +end
@@ -0,0 +1,15 @@
+-- +up
+-- +begin
+CREATE TABLE `persistence`
+(
+    `id`         VARCHAR(255) NOT NULL PRIMARY KEY,
+    `value`      TEXT         NOT NULL,
+    `updated_at` DATETIME(3)  NOT NULL
+);
+-- +end
+
+-- +down
+
+-- +begin
+DROP TABLE IF EXISTS `persistence`;
+-- +end
@@ -0,0 +1,26 @@
+-- +up
+-- +begin
+CREATE TABLE `profits`
+(
+    `gid`                  INTEGER PRIMARY KEY AUTOINCREMENT,
+    `strategy`             VARCHAR(32)    NOT NULL,
+    `strategy_instance_id` VARCHAR(64)    NOT NULL DEFAULT '',
+    `symbol`               VARCHAR(20)    NOT NULL,
+    `base_currency`        VARCHAR(10)    NOT NULL,
+    `quote_currency`       VARCHAR(10)    NOT NULL,
+    `profit`               DECIMAL(16, 8) NOT NULL,
+    `net_profit`           DECIMAL(16, 8) NOT NULL,
+    `trade_amount`         DECIMAL(16, 8) NOT NULL,
+    `time`                 DATETIME(3)    NOT NULL
+);
+-- +end
+
+-- +begin
+CREATE INDEX `profits_strategy_time` ON `profits` (`strategy`, `time`);
+-- +end
+
+-- +down
+
+-- +begin
+DROP TABLE IF EXISTS `profits`;
+-- +end
@@ -0,0 +1,22 @@
+-- +up
+-- +begin
+CREATE TABLE `balance_snapshots`
+(
+    `gid`      INTEGER PRIMARY KEY AUTOINCREMENT,
+    `session`  VARCHAR(30)    NOT NULL,
+    `exchange` VARCHAR(30)    NOT NULL,
+    `currency` VARCHAR(12)    NOT NULL,
+    `value`    DECIMAL(32, 8) NOT NULL,
+    `time`     DATETIME(3)    NOT NULL
+);
+-- +end
+
+-- +begin
+CREATE INDEX `balance_snapshots_session_time` ON `balance_snapshots` (`session`, `time`);
+-- +end
+
+-- +down
+
+-- +begin
+DROP TABLE IF EXISTS `balance_snapshots`;
+-- +end
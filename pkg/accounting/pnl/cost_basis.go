@@ -0,0 +1,310 @@
+package pnl
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// CostBasisMethod is the method of matching the sell trades with the bought lots
+type CostBasisMethod string
+
+const (
+	// CostBasisFIFO sells the earliest bought lots first
+	CostBasisFIFO CostBasisMethod = "fifo"
+
+	// CostBasisLIFO sells the latest bought lots first
+	CostBasisLIFO CostBasisMethod = "lifo"
+
+	// CostBasisAverage pools the bought lots by the weighted average cost
+	CostBasisAverage CostBasisMethod = "average"
+)
+
+func ParseCostBasisMethod(s string) (CostBasisMethod, error) {
+	switch strings.ToLower(s) {
+	case "fifo":
+		return CostBasisFIFO, nil
+	case "lifo":
+		return CostBasisLIFO, nil
+	case "avg", "average":
+		return CostBasisAverage, nil
+	}
+
+	return "", fmt.Errorf("unsupported cost basis method %q, valid methods are fifo, lifo and average", s)
+}
+
+// Lot is the open quantity of a buy trade, the cost includes the trading fee paid in the quote currency
+type Lot struct {
+	TradeID  int64     `json:"tradeID"`
+	Time     time.Time `json:"time"`
+	Price    float64   `json:"price"`
+	Quantity float64   `json:"quantity"`
+	Cost     float64   `json:"cost"`
+}
+
+// UnitCost is the cost basis of one unit
+func (lot Lot) UnitCost() float64 {
+	if lot.Quantity == 0 {
+		return 0
+	}
+
+	return lot.Cost / lot.Quantity
+}
+
+// RealizedGain is the gain of a lot (or a part of the lot) closed by a sell trade
+type RealizedGain struct {
+	Symbol   string  `json:"symbol"`
+	Quantity float64 `json:"quantity"`
+
+	BuyTradeID  int64     `json:"buyTradeID,omitempty"`
+	AcquiredAt  time.Time `json:"acquiredAt"`
+	SellTradeID int64     `json:"sellTradeID"`
+	SoldAt      time.Time `json:"soldAt"`
+
+	// CostBasis is the cost of the sold quantity, Proceeds is the sold amount after the trading fee
+	CostBasis float64 `json:"costBasis"`
+	Proceeds  float64 `json:"proceeds"`
+	Gain      float64 `json:"gain"`
+
+	// Uncovered is true if the sold quantity is not covered by the bought lots, the cost basis is zero
+	Uncovered bool `json:"uncovered,omitempty"`
+}
+
+// HoldingPeriod is the duration between the acquired time and the sold time
+func (g RealizedGain) HoldingPeriod() time.Duration {
+	if g.AcquiredAt.IsZero() {
+		return 0
+	}
+
+	return g.SoldAt.Sub(g.AcquiredAt)
+}
+
+type CostBasisReport struct {
+	Symbol    string          `json:"symbol"`
+	Method    CostBasisMethod `json:"method"`
+	LastPrice float64         `json:"lastPrice"`
+	StartTime time.Time       `json:"startTime"`
+	NumTrades int             `json:"numTrades"`
+
+	Gains        []RealizedGain `json:"gains"`
+	RealizedGain float64        `json:"realizedGain"`
+
+	OpenLots       []Lot   `json:"openLots"`
+	Stock          float64 `json:"stock"`
+	CostBasis      float64 `json:"costBasis"`
+	UnrealizedGain float64 `json:"unrealizedGain"`
+}
+
+func (report *CostBasisReport) Print() {
+	log.Infof("COST BASIS METHOD: %s", report.Method)
+	log.Infof("TRADES SINCE: %v", report.StartTime)
+	log.Infof("NUMBER OF TRADES: %d", report.NumTrades)
+	log.Infof("REALIZED GAINS:")
+	for _, gain := range report.Gains {
+		acquiredAt := "-"
+		if !gain.AcquiredAt.IsZero() {
+			acquiredAt = gain.AcquiredAt.Format(time.RFC3339)
+		}
+
+		log.Infof(" - %f acquired %s sold %s: cost basis %f, proceeds %f, gain %f",
+			gain.Quantity, acquiredAt, gain.SoldAt.Format(time.RFC3339), gain.CostBasis, gain.Proceeds, gain.Gain)
+	}
+	log.Infof("REALIZED GAIN: %s", types.USD.FormatMoneyFloat64(report.RealizedGain))
+	log.Infof("STOCK: %f", report.Stock)
+	log.Infof("COST BASIS OF STOCK: %s", types.USD.FormatMoneyFloat64(report.CostBasis))
+	log.Infof("CURRENT PRICE: %s", types.USD.FormatMoneyFloat64(report.LastPrice))
+	log.Infof("UNREALIZED GAIN: %s", types.USD.FormatMoneyFloat64(report.UnrealizedGain))
+}
+
+// CostBasisCalculator matches the sell trades with the bought lots by the cost basis method.
+// The trading fee paid in the base currency reduces the bought or increases the sold quantity, and the trading fee
+// paid in the quote currency is added to the cost basis or deducted from the proceeds. The trading fee paid in the
+// other currencies (e.g., BNB) is not included.
+type CostBasisCalculator struct {
+	Method CostBasisMethod
+	Market types.Market
+}
+
+func (c *CostBasisCalculator) isBaseFee(trade types.Trade) bool {
+	if len(c.Market.BaseCurrency) > 0 {
+		return trade.FeeCurrency == c.Market.BaseCurrency
+	}
+
+	return len(trade.FeeCurrency) > 0 && strings.HasPrefix(trade.Symbol, trade.FeeCurrency)
+}
+
+func (c *CostBasisCalculator) isQuoteFee(trade types.Trade) bool {
+	if len(c.Market.QuoteCurrency) > 0 {
+		return trade.FeeCurrency == c.Market.QuoteCurrency
+	}
+
+	return len(trade.FeeCurrency) > 0 && strings.HasSuffix(trade.Symbol, trade.FeeCurrency)
+}
+
+// Calculate calculates the realized gains of the trades, the trades should be sorted by the trade time
+func (c *CostBasisCalculator) Calculate(symbol string, trades []types.Trade, currentPrice float64) *CostBasisReport {
+	method := c.Method
+	if len(method) == 0 {
+		method = CostBasisFIFO
+	}
+
+	report := &CostBasisReport{
+		Symbol:    symbol,
+		Method:    method,
+		LastPrice: currentPrice,
+	}
+
+	var lots []Lot
+	for _, trade := range trades {
+		if trade.Symbol != symbol {
+			continue
+		}
+
+		if report.NumTrades == 0 {
+			report.StartTime = trade.Time.Time()
+		}
+		report.NumTrades++
+
+		quantity := trade.Quantity
+		amount := trade.QuoteQuantity
+		if amount == 0 {
+			amount = trade.Price * trade.Quantity
+		}
+
+		if trade.IsBuyer {
+			if c.isBaseFee(trade) {
+				quantity -= trade.Fee
+			} else if c.isQuoteFee(trade) {
+				amount += trade.Fee
+			}
+
+			lot := Lot{
+				TradeID:  trade.ID,
+				Time:     trade.Time.Time(),
+				Price:    trade.Price,
+				Quantity: quantity,
+				Cost:     amount,
+			}
+
+			if method == CostBasisAverage && len(lots) > 0 {
+				lots[0] = mergeLots(lots[0], lot)
+			} else {
+				lots = append(lots, lot)
+			}
+			continue
+		}
+
+		if c.isBaseFee(trade) {
+			quantity += trade.Fee
+		} else if c.isQuoteFee(trade) {
+			amount -= trade.Fee
+		}
+
+		var gains []RealizedGain
+		lots, gains = consumeLots(lots, method, trade, quantity, amount)
+		for _, gain := range gains {
+			report.RealizedGain += gain.Gain
+		}
+		report.Gains = append(report.Gains, gains...)
+	}
+
+	report.OpenLots = lots
+	for _, lot := range lots {
+		report.Stock += lot.Quantity
+		report.CostBasis += lot.Cost
+	}
+
+	report.Stock = round(report.Stock)
+	report.CostBasis = round(report.CostBasis)
+	report.RealizedGain = round(report.RealizedGain)
+	if report.Stock > 0 {
+		report.UnrealizedGain = round(report.Stock*currentPrice - report.CostBasis)
+	}
+
+	return report
+}
+
+// mergeLots merges the lot into the pooled lot of the average cost method, the acquired time of the pool is the
+// time of the earliest lot
+func mergeLots(pool, lot Lot) Lot {
+	pool.Quantity += lot.Quantity
+	pool.Cost += lot.Cost
+	if pool.Quantity > 0 {
+		pool.Price = pool.Cost / pool.Quantity
+	}
+	return pool
+}
+
+// consumeLots closes the lots by the sold quantity and returns the remaining lots and the realized gains,
+// the proceeds are allocated to the closed lots by the quantity.
+func consumeLots(lots []Lot, method CostBasisMethod, trade types.Trade, quantity, proceeds float64) ([]Lot, []RealizedGain) {
+	var gains []RealizedGain
+	var unitProceeds = 0.0
+	if quantity > 0 {
+		unitProceeds = proceeds / quantity
+	}
+
+	remaining := quantity
+	for len(lots) > 0 && !zero(remaining) {
+		idx := 0
+		if method == CostBasisLIFO {
+			idx = len(lots) - 1
+		}
+
+		lot := lots[idx]
+		q := math.Min(lot.Quantity, remaining)
+		cost := lot.UnitCost() * q
+
+		gain := RealizedGain{
+			Symbol:      trade.Symbol,
+			Quantity:    round(q),
+			BuyTradeID:  lot.TradeID,
+			AcquiredAt:  lot.Time,
+			SellTradeID: trade.ID,
+			SoldAt:      trade.Time.Time(),
+			CostBasis:   round(cost),
+			Proceeds:    round(unitProceeds * q),
+		}
+		gain.Gain = round(gain.Proceeds - gain.CostBasis)
+		gains = append(gains, gain)
+
+		lot.Quantity = round(lot.Quantity - q)
+		lot.Cost = round(lot.Cost - cost)
+		remaining = round(remaining - q)
+
+		if zero(lot.Quantity) {
+			lots = append(lots[:idx], lots[idx+1:]...)
+		} else {
+			lots[idx] = lot
+		}
+	}
+
+	if !zero(remaining) {
+		log.Warnf("the sold quantity %f of trade %d is not covered by the bought lots, the cost basis is zero", remaining, trade.ID)
+		gain := RealizedGain{
+			Symbol:      trade.Symbol,
+			Quantity:    round(remaining),
+			SellTradeID: trade.ID,
+			SoldAt:      trade.Time.Time(),
+			Proceeds:    round(unitProceeds * remaining),
+			Uncovered:   true,
+		}
+		gain.Gain = gain.Proceeds
+		gains = append(gains, gain)
+	}
+
+	return lots, gains
+}
+
+func zero(a float64) bool {
+	return int(math.Round(a*1e8)) == 0
+}
+
+func round(a float64) float64 {
+	return math.Round(a*1e8) / 1e8
+}
@@ -0,0 +1,141 @@
+package pnl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var costBasisTestMarket = types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"}
+
+func newCostBasisTestTrade(id int64, day int, isBuyer bool, price, quantity float64) types.Trade {
+	return types.Trade{
+		ID:            id,
+		Symbol:        "BTCUSDT",
+		IsBuyer:       isBuyer,
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: price * quantity,
+		Time:          types.Time(time.Date(2021, 1, day, 0, 0, 0, 0, time.UTC)),
+	}
+}
+
+func costBasisTestTrades() []types.Trade {
+	return []types.Trade{
+		newCostBasisTestTrade(1, 1, true, 100.0, 1.0),
+		newCostBasisTestTrade(2, 2, true, 200.0, 1.0),
+		newCostBasisTestTrade(3, 3, false, 300.0, 1.5),
+	}
+}
+
+func TestParseCostBasisMethod(t *testing.T) {
+	method, err := ParseCostBasisMethod("FIFO")
+	assert.NoError(t, err)
+	assert.Equal(t, CostBasisFIFO, method)
+
+	method, err = ParseCostBasisMethod("avg")
+	assert.NoError(t, err)
+	assert.Equal(t, CostBasisAverage, method)
+
+	_, err = ParseCostBasisMethod("hifo")
+	assert.Error(t, err)
+}
+
+func TestCostBasisCalculator_FIFO(t *testing.T) {
+	calculator := &CostBasisCalculator{Method: CostBasisFIFO, Market: costBasisTestMarket}
+	report := calculator.Calculate("BTCUSDT", costBasisTestTrades(), 400.0)
+
+	if assert.Len(t, report.Gains, 2) {
+		assert.Equal(t, int64(1), report.Gains[0].BuyTradeID)
+		assert.Equal(t, 1.0, report.Gains[0].Quantity)
+		assert.Equal(t, 100.0, report.Gains[0].CostBasis)
+		assert.Equal(t, 300.0, report.Gains[0].Proceeds)
+		assert.Equal(t, 200.0, report.Gains[0].Gain)
+		assert.Equal(t, 48*time.Hour, report.Gains[0].HoldingPeriod())
+
+		assert.Equal(t, int64(2), report.Gains[1].BuyTradeID)
+		assert.Equal(t, 0.5, report.Gains[1].Quantity)
+		assert.Equal(t, 50.0, report.Gains[1].Gain)
+	}
+
+	assert.Equal(t, 250.0, report.RealizedGain)
+	assert.Equal(t, 0.5, report.Stock)
+	assert.Equal(t, 100.0, report.CostBasis)
+	assert.Equal(t, 100.0, report.UnrealizedGain)
+	assert.Equal(t, 3, report.NumTrades)
+}
+
+func TestCostBasisCalculator_LIFO(t *testing.T) {
+	calculator := &CostBasisCalculator{Method: CostBasisLIFO, Market: costBasisTestMarket}
+	report := calculator.Calculate("BTCUSDT", costBasisTestTrades(), 400.0)
+
+	if assert.Len(t, report.Gains, 2) {
+		assert.Equal(t, int64(2), report.Gains[0].BuyTradeID)
+		assert.Equal(t, 100.0, report.Gains[0].Gain)
+		assert.Equal(t, int64(1), report.Gains[1].BuyTradeID)
+		assert.Equal(t, 0.5, report.Gains[1].Quantity)
+		assert.Equal(t, 100.0, report.Gains[1].Gain)
+	}
+
+	assert.Equal(t, 200.0, report.RealizedGain)
+	assert.Equal(t, 50.0, report.CostBasis)
+	assert.Equal(t, 150.0, report.UnrealizedGain)
+}
+
+func TestCostBasisCalculator_Average(t *testing.T) {
+	calculator := &CostBasisCalculator{Method: CostBasisAverage, Market: costBasisTestMarket}
+	report := calculator.Calculate("BTCUSDT", costBasisTestTrades(), 400.0)
+
+	if assert.Len(t, report.Gains, 1) {
+		assert.Equal(t, 1.5, report.Gains[0].Quantity)
+		assert.Equal(t, 225.0, report.Gains[0].CostBasis)
+		assert.Equal(t, 225.0, report.Gains[0].Gain)
+		assert.Equal(t, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), report.Gains[0].AcquiredAt)
+	}
+
+	assert.Equal(t, 225.0, report.RealizedGain)
+	assert.Equal(t, 75.0, report.CostBasis)
+	if assert.Len(t, report.OpenLots, 1) {
+		assert.Equal(t, 150.0, report.OpenLots[0].UnitCost())
+	}
+}
+
+func TestCostBasisCalculator_Fees(t *testing.T) {
+	buy := newCostBasisTestTrade(1, 1, true, 100.0, 1.0)
+	buy.Fee = 1.0
+	buy.FeeCurrency = "USDT"
+
+	sell := newCostBasisTestTrade(2, 2, false, 200.0, 0.5)
+	sell.Fee = 0.5
+	sell.FeeCurrency = "USDT"
+
+	// the fee paid in the other currency is not included
+	sell2 := newCostBasisTestTrade(3, 3, false, 200.0, 0.5)
+	sell2.Fee = 0.01
+	sell2.FeeCurrency = "BNB"
+
+	// the sold quantity is not covered by the bought lots
+	sell3 := newCostBasisTestTrade(4, 4, false, 200.0, 0.1)
+
+	calculator := &CostBasisCalculator{Method: CostBasisFIFO, Market: costBasisTestMarket}
+	report := calculator.Calculate("BTCUSDT", []types.Trade{buy, sell, sell2, sell3}, 200.0)
+
+	if assert.Len(t, report.Gains, 3) {
+		assert.Equal(t, 50.5, report.Gains[0].CostBasis)
+		assert.Equal(t, 99.5, report.Gains[0].Proceeds)
+		assert.Equal(t, 49.0, report.Gains[0].Gain)
+
+		assert.Equal(t, 100.0, report.Gains[1].Proceeds)
+		assert.Equal(t, 49.5, report.Gains[1].Gain)
+
+		assert.True(t, report.Gains[2].Uncovered)
+		assert.Equal(t, 20.0, report.Gains[2].Gain)
+		assert.Equal(t, time.Duration(0), report.Gains[2].HoldingPeriod())
+	}
+
+	assert.Equal(t, 0.0, report.Stock)
+	assert.Equal(t, 0.0, report.UnrealizedGain)
+}
@@ -54,6 +54,27 @@ func (report AverageCostPnlReport) Print() {
 	log.Infof("UNREALIZED PROFIT: %s", types.USD.FormatMoneyFloat64(report.UnrealizedProfit.Float64()))
 }
 
+// SlackBlocks renders the report as the message blocks
+func (report AverageCostPnlReport) SlackBlocks() []slack.Block {
+	icon := ":chart_with_downwards_trend:"
+	if report.UnrealizedProfit > 0 {
+		icon = ":chart_with_upwards_trend:"
+	}
+
+	text := icon + " *" + report.Symbol + " Profit and Loss report*\nProfit " + types.USD.FormatMoney(report.Profit)
+	return []slack.Block{
+		slack.NewSectionBlock(slackstyle.Markdown(text), []*slack.TextBlockObject{
+			slackstyle.Field("Profit", types.USD.FormatMoney(report.Profit)),
+			slackstyle.Field("Unrealized Profit", types.USD.FormatMoney(report.UnrealizedProfit)),
+			slackstyle.Field("Current Price", report.Market.FormatPrice(report.LastPrice)),
+			slackstyle.Field("Average Cost", report.Market.FormatPrice(report.AverageCost)),
+			slackstyle.Field("Stock", strconv.FormatFloat(report.Stock, 'f', 8, 64)),
+			slackstyle.Field("Number of Trades", strconv.Itoa(report.NumTrades)),
+		}, nil),
+		slack.NewContextBlock("", slackstyle.Markdown("since "+report.StartTime.Format(time.RFC822))),
+	}
+}
+
 func (report AverageCostPnlReport) SlackAttachment() slack.Attachment {
 	var color = slackstyle.Red
 
@@ -0,0 +1,310 @@
+package pnl
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// TaxEventType is the type of the taxable event in the tax report
+type TaxEventType string
+
+const (
+	// TaxEventDisposal is the sold lot of the trades, it's always included in the tax report
+	TaxEventDisposal TaxEventType = "disposal"
+
+	TaxEventDeposit    TaxEventType = "deposit"
+	TaxEventWithdrawal TaxEventType = "withdrawal"
+	TaxEventReward     TaxEventType = "reward"
+)
+
+// ParseTaxEventTypes parses the comma separated optional taxable events, e.g., "deposits,rewards"
+func ParseTaxEventTypes(s string) ([]TaxEventType, error) {
+	var eventTypes []TaxEventType
+	for _, name := range strings.Split(s, ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "":
+			continue
+		case "deposit", "deposits":
+			eventTypes = append(eventTypes, TaxEventDeposit)
+		case "withdraw", "withdrawal", "withdrawals":
+			eventTypes = append(eventTypes, TaxEventWithdrawal)
+		case "reward", "rewards":
+			eventTypes = append(eventTypes, TaxEventReward)
+		default:
+			return nil, fmt.Errorf("unsupported taxable event %q, valid events are deposits, withdrawals and rewards", name)
+		}
+	}
+
+	return eventTypes, nil
+}
+
+// PriceLookup returns the price of the asset in the valuation currency at the given time
+type PriceLookup func(asset string, t time.Time) (float64, bool)
+
+// TaxEvent is a row of the tax report.
+//
+// For the disposal, the quantity of the asset is sold at Time and acquired at AcquiredAt, the cost basis, the proceeds
+// and the gain are in the quote currency. For the deposits, the withdrawals and the rewards, Value is the market value
+// of the quantity in the valuation currency, and the market value of the deposits and the rewards is the income (Gain).
+type TaxEvent struct {
+	Type       TaxEventType `json:"type"`
+	Time       time.Time    `json:"time"`
+	AcquiredAt time.Time    `json:"acquiredAt,omitempty"`
+	Asset      string       `json:"asset"`
+	Symbol     string       `json:"symbol,omitempty"`
+	Quantity   float64      `json:"quantity"`
+
+	CostBasis float64 `json:"costBasis"`
+	Proceeds  float64 `json:"proceeds"`
+	Gain      float64 `json:"gain"`
+	Value     float64 `json:"value"`
+	Currency  string  `json:"currency"`
+
+	// Term is "long" if the disposed lot is held for more than one year, otherwise it's "short"
+	Term        string `json:"term,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type TaxReport struct {
+	Year     int             `json:"year"`
+	Method   CostBasisMethod `json:"method"`
+	Location *time.Location  `json:"-"`
+	Events   []TaxEvent      `json:"events"`
+}
+
+// Totals returns the total gain of the events by the event type and the currency
+func (r *TaxReport) Totals() map[TaxEventType]map[string]float64 {
+	var totals = make(map[TaxEventType]map[string]float64)
+	for _, event := range r.Events {
+		if _, ok := totals[event.Type]; !ok {
+			totals[event.Type] = make(map[string]float64)
+		}
+
+		totals[event.Type][event.Currency] = round(totals[event.Type][event.Currency] + event.Gain)
+	}
+
+	return totals
+}
+
+var taxReportCSVHeader = []string{
+	"Type", "Date", "Date Acquired", "Asset", "Symbol", "Quantity",
+	"Cost Basis", "Proceeds", "Gain/Loss", "Value", "Currency", "Term", "Description",
+}
+
+// WriteCSV writes the events in CSV, the dates are formatted as YYYY-MM-DD in the location of the report
+func (r *TaxReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(taxReportCSVHeader); err != nil {
+		return err
+	}
+
+	for _, event := range r.Events {
+		record := []string{
+			string(event.Type),
+			r.formatDate(event.Time),
+			r.formatDate(event.AcquiredAt),
+			event.Asset,
+			event.Symbol,
+			formatFloat(event.Quantity),
+			formatFloat(event.CostBasis),
+			formatFloat(event.Proceeds),
+			formatFloat(event.Gain),
+			formatFloat(event.Value),
+			event.Currency,
+			event.Term,
+			event.Description,
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func (r *TaxReport) formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	if r.Location != nil {
+		t = t.In(r.Location)
+	}
+
+	return t.Format("2006-01-02")
+}
+
+func formatFloat(a float64) string {
+	return strconv.FormatFloat(a, 'f', -1, 64)
+}
+
+// TaxReportBuilder builds the tax report of the year. The disposals are calculated by the cost basis method from the
+// trades since the first trade, so the lots acquired before the year are included. The deposits, the withdrawals and
+// the rewards are only included if they are configured as the taxable events.
+type TaxReportBuilder struct {
+	Year     int
+	Method   CostBasisMethod
+	Location *time.Location
+
+	// TaxableEvents are the optional taxable events: deposits, withdrawals and rewards
+	TaxableEvents []TaxEventType
+
+	// Currency is the valuation currency of the deposits, the withdrawals and the rewards,
+	// PriceLookup returns the price of the asset in the currency, the value is zero if the price is not found.
+	Currency    string
+	PriceLookup PriceLookup
+
+	events []TaxEvent
+}
+
+// Range returns the time range [since, until) of the year
+func (b *TaxReportBuilder) Range() (since, until time.Time) {
+	loc := b.Location
+	if loc == nil {
+		loc = time.Local
+	}
+
+	since = time.Date(b.Year, time.January, 1, 0, 0, 0, 0, loc)
+	return since, since.AddDate(1, 0, 0)
+}
+
+func (b *TaxReportBuilder) inYear(t time.Time) bool {
+	since, until := b.Range()
+	return !t.Before(since) && t.Before(until)
+}
+
+func (b *TaxReportBuilder) isTaxable(eventType TaxEventType) bool {
+	for _, t := range b.TaxableEvents {
+		if t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AddTrades adds the disposals of the market in the year, the trades should be sorted by the trade time
+func (b *TaxReportBuilder) AddTrades(market types.Market, trades []types.Trade) {
+	calculator := &CostBasisCalculator{
+		Method: b.Method,
+		Market: market,
+	}
+
+	report := calculator.Calculate(market.Symbol, trades, 0)
+	for _, gain := range report.Gains {
+		if !b.inYear(gain.SoldAt) {
+			continue
+		}
+
+		event := TaxEvent{
+			Type:       TaxEventDisposal,
+			Time:       gain.SoldAt,
+			AcquiredAt: gain.AcquiredAt,
+			Asset:      market.BaseCurrency,
+			Symbol:     gain.Symbol,
+			Quantity:   gain.Quantity,
+			CostBasis:  gain.CostBasis,
+			Proceeds:   gain.Proceeds,
+			Gain:       gain.Gain,
+			Currency:   market.QuoteCurrency,
+			Term:       "short",
+		}
+
+		if gain.Uncovered {
+			event.Term = ""
+			event.Description = "uncovered by the bought lots"
+		} else if gain.AcquiredAt.AddDate(1, 0, 0).Before(gain.SoldAt) {
+			event.Term = "long"
+		}
+
+		b.events = append(b.events, event)
+	}
+}
+
+func (b *TaxReportBuilder) valueOf(asset string, quantity float64, t time.Time) float64 {
+	if asset == b.Currency {
+		return quantity
+	}
+
+	if b.PriceLookup == nil {
+		return 0
+	}
+
+	price, ok := b.PriceLookup(asset, t)
+	if !ok {
+		return 0
+	}
+
+	return round(price * quantity)
+}
+
+func (b *TaxReportBuilder) addTransferEvent(eventType TaxEventType, asset string, quantity float64, t time.Time, description string) {
+	if !b.isTaxable(eventType) || !b.inYear(t) {
+		return
+	}
+
+	event := TaxEvent{
+		Type:        eventType,
+		Time:        t,
+		Asset:       asset,
+		Quantity:    quantity,
+		Value:       b.valueOf(asset, quantity, t),
+		Currency:    b.Currency,
+		Description: description,
+	}
+
+	// the withdrawal is not an income, the gain of the withdrawn asset depends on the jurisdiction
+	if eventType != TaxEventWithdrawal {
+		event.Gain = event.Value
+	}
+
+	b.events = append(b.events, event)
+}
+
+func (b *TaxReportBuilder) AddDeposits(deposits []types.Deposit) {
+	for _, deposit := range deposits {
+		b.addTransferEvent(TaxEventDeposit, deposit.Asset, deposit.Amount, deposit.Time.Time(), deposit.TransactionID)
+	}
+}
+
+func (b *TaxReportBuilder) AddWithdrawals(withdrawals []types.Withdraw) {
+	for _, withdrawal := range withdrawals {
+		b.addTransferEvent(TaxEventWithdrawal, withdrawal.Asset, withdrawal.Amount, withdrawal.ApplyTime.Time(), withdrawal.TransactionID)
+	}
+}
+
+func (b *TaxReportBuilder) AddRewards(rewards []types.Reward) {
+	for _, reward := range rewards {
+		b.addTransferEvent(TaxEventReward, reward.Currency, reward.Quantity.Float64(), reward.CreatedAt.Time(), string(reward.Type))
+	}
+}
+
+// Build returns the report of the added events sorted by the time
+func (b *TaxReportBuilder) Build() *TaxReport {
+	method := b.Method
+	if len(method) == 0 {
+		method = CostBasisFIFO
+	}
+
+	events := make([]TaxEvent, len(b.events))
+	copy(events, b.events)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+
+	return &TaxReport{
+		Year:     b.Year,
+		Method:   method,
+		Location: b.Location,
+		Events:   events,
+	}
+}
@@ -0,0 +1,136 @@
+package pnl
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func newTaxReportTestTrade(id int64, t time.Time, isBuyer bool, price, quantity float64) types.Trade {
+	trade := newCostBasisTestTrade(id, 1, isBuyer, price, quantity)
+	trade.Time = types.Time(t)
+	return trade
+}
+
+func taxReportTestBuilder(taxableEvents ...TaxEventType) *TaxReportBuilder {
+	builder := &TaxReportBuilder{
+		Year:          2021,
+		Method:        CostBasisFIFO,
+		Location:      time.UTC,
+		TaxableEvents: taxableEvents,
+		Currency:      "USDT",
+		PriceLookup: func(asset string, t time.Time) (float64, bool) {
+			if asset == "MAX" {
+				return 0.5, true
+			}
+			return 0, false
+		},
+	}
+
+	builder.AddTrades(costBasisTestMarket, []types.Trade{
+		newTaxReportTestTrade(1, time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC), true, 100.0, 1.0),
+		newTaxReportTestTrade(2, time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC), true, 200.0, 1.0),
+		newTaxReportTestTrade(3, time.Date(2020, 12, 15, 0, 0, 0, 0, time.UTC), false, 300.0, 0.5),
+		newTaxReportTestTrade(4, time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC), false, 400.0, 1.0),
+		newTaxReportTestTrade(5, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), false, 500.0, 0.5),
+	})
+
+	builder.AddDeposits([]types.Deposit{
+		{Asset: "USDT", Amount: 1000.0, TransactionID: "deposit01", Time: types.Time(time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC))},
+		{Asset: "USDT", Amount: 1000.0, TransactionID: "deposit02", Time: types.Time(time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC))},
+	})
+
+	builder.AddWithdrawals([]types.Withdraw{
+		{Asset: "BTC", Amount: 0.1, TransactionID: "withdraw01", ApplyTime: types.Time(time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC))},
+	})
+
+	builder.AddRewards([]types.Reward{
+		{Type: types.RewardCommission, Currency: "MAX", Quantity: fixedpoint.NewFromFloat(10.0), CreatedAt: types.Time(time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC))},
+	})
+
+	return builder
+}
+
+func TestParseTaxEventTypes(t *testing.T) {
+	eventTypes, err := ParseTaxEventTypes("deposits, Withdraw,rewards")
+	assert.NoError(t, err)
+	assert.Equal(t, []TaxEventType{TaxEventDeposit, TaxEventWithdrawal, TaxEventReward}, eventTypes)
+
+	eventTypes, err = ParseTaxEventTypes("")
+	assert.NoError(t, err)
+	assert.Empty(t, eventTypes)
+
+	_, err = ParseTaxEventTypes("airdrops")
+	assert.Error(t, err)
+}
+
+func TestTaxReportBuilder_Disposals(t *testing.T) {
+	report := taxReportTestBuilder().Build()
+
+	// the lot bought in 2019 is partially sold in 2020, the sell in 2022 is out of the year
+	if assert.Len(t, report.Events, 2) {
+		assert.Equal(t, TaxEventDisposal, report.Events[0].Type)
+		assert.Equal(t, "BTC", report.Events[0].Asset)
+		assert.Equal(t, 0.5, report.Events[0].Quantity)
+		assert.Equal(t, time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC), report.Events[0].AcquiredAt)
+		assert.Equal(t, 50.0, report.Events[0].CostBasis)
+		assert.Equal(t, 200.0, report.Events[0].Proceeds)
+		assert.Equal(t, 150.0, report.Events[0].Gain)
+		assert.Equal(t, "USDT", report.Events[0].Currency)
+		assert.Equal(t, "long", report.Events[0].Term)
+
+		assert.Equal(t, 0.5, report.Events[1].Quantity)
+		assert.Equal(t, time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC), report.Events[1].AcquiredAt)
+		assert.Equal(t, 100.0, report.Events[1].CostBasis)
+		assert.Equal(t, 100.0, report.Events[1].Gain)
+		assert.Equal(t, "short", report.Events[1].Term)
+	}
+
+	assert.Equal(t, 250.0, report.Totals()[TaxEventDisposal]["USDT"])
+}
+
+func TestTaxReportBuilder_TaxableEvents(t *testing.T) {
+	report := taxReportTestBuilder(TaxEventDeposit, TaxEventWithdrawal, TaxEventReward).Build()
+	if !assert.Len(t, report.Events, 5) {
+		return
+	}
+
+	var eventTypes []TaxEventType
+	for _, event := range report.Events {
+		eventTypes = append(eventTypes, event.Type)
+	}
+	assert.Equal(t, []TaxEventType{TaxEventDeposit, TaxEventDisposal, TaxEventDisposal, TaxEventWithdrawal, TaxEventReward}, eventTypes)
+
+	deposit := report.Events[0]
+	assert.Equal(t, 1000.0, deposit.Value)
+	assert.Equal(t, 1000.0, deposit.Gain)
+	assert.Equal(t, "deposit01", deposit.Description)
+
+	// the price of BTC is not found
+	withdrawal := report.Events[3]
+	assert.Equal(t, 0.1, withdrawal.Quantity)
+	assert.Equal(t, 0.0, withdrawal.Value)
+	assert.Equal(t, 0.0, withdrawal.Gain)
+
+	reward := report.Events[4]
+	assert.Equal(t, "MAX", reward.Asset)
+	assert.Equal(t, 5.0, reward.Value)
+	assert.Equal(t, 5.0, reward.Gain)
+	assert.Equal(t, "commission", reward.Description)
+}
+
+func TestTaxReport_WriteCSV(t *testing.T) {
+	report := taxReportTestBuilder(TaxEventReward).Build()
+
+	var buf bytes.Buffer
+	assert.NoError(t, report.WriteCSV(&buf))
+	assert.Equal(t, "Type,Date,Date Acquired,Asset,Symbol,Quantity,Cost Basis,Proceeds,Gain/Loss,Value,Currency,Term,Description\n"+
+		"disposal,2021-03-01,2019-06-01,BTC,BTCUSDT,0.5,50,200,150,0,USDT,long,\n"+
+		"disposal,2021-03-01,2020-12-01,BTC,BTCUSDT,0.5,100,200,100,0,USDT,short,\n"+
+		"reward,2021-05-01,,MAX,,10,0,0,5,5,USDT,,commission\n", buf.String())
+}
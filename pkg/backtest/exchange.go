@@ -39,6 +39,7 @@ import (
 
 	"github.com/c9s/bbgo/pkg/bbgo"
 	"github.com/c9s/bbgo/pkg/exchange/binance"
+	"github.com/c9s/bbgo/pkg/exchange/bitfinex"
 	"github.com/c9s/bbgo/pkg/exchange/max"
 	"github.com/c9s/bbgo/pkg/service"
 	"github.com/c9s/bbgo/pkg/types"
@@ -95,10 +96,9 @@ func NewExchange(sourceName types.ExchangeName, srv *service.BacktestService, co
 		return nil, err
 	}
 
-	account := &types.Account{
-		MakerFeeRate: config.Account.MakerFeeRate,
-		TakerFeeRate: config.Account.TakerFeeRate,
-		AccountType:  "SPOT", // currently not used
+	account, err := newAccount(sourceName, config.Account)
+	if err != nil {
+		return nil, err
 	}
 
 	balances := config.Account.Balances.BalanceMap()
@@ -122,6 +122,37 @@ func NewExchange(sourceName types.ExchangeName, srv *service.BacktestService, co
 	return e, nil
 }
 
+// newAccount creates the account with the configured fee rates, the fee tier cached by the live trading is only used
+// when UseFeeTierCache is enabled and the fee rates are not configured
+func newAccount(sourceName types.ExchangeName, config bbgo.BacktestAccount) (*types.Account, error) {
+	account := &types.Account{
+		MakerFeeRate: config.MakerFeeRate,
+		TakerFeeRate: config.TakerFeeRate,
+		AccountType:  "SPOT", // currently not used
+	}
+
+	if !config.UseFeeTierCache || account.MakerFeeRate != 0 || account.TakerFeeRate != 0 {
+		return account, nil
+	}
+
+	tier, err := bbgo.LoadFeeTierCache(sourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	if tier == nil {
+		log.Warnf("useFeeTierCache is enabled but the %s fee tier is not cached, using the default fee rates", sourceName)
+		return account, nil
+	}
+
+	log.Infof("using the cached %s fee tier: maker %f taker %f discount %f",
+		sourceName, tier.MakerFeeRate.Float64(), tier.TakerFeeRate.Float64(), tier.FeeDiscount.Float64())
+
+	account.MakerFeeRate = tier.EffectiveMakerFeeRate()
+	account.TakerFeeRate = tier.EffectiveTakerFeeRate()
+	return account, nil
+}
+
 func (e *Exchange) addTrade(trade types.Trade) {
 	e.tradesMutex.Lock()
 	e.trades[trade.Symbol] = append(e.trades[trade.Symbol], trade)
@@ -151,9 +182,11 @@ func (e *Exchange) addMatchingBook(symbol string, market types.Market) {
 
 func (e *Exchange) _addMatchingBook(symbol string, market types.Market) {
 	e.matchingBooks[symbol] = &SimplePriceMatching{
-		CurrentTime: e.startTime,
-		Account:     e.account,
-		Market:      market,
+		CurrentTime:  e.startTime,
+		Account:      e.account,
+		Market:       market,
+		MakerFeeRate: e.account.MakerFeeRate,
+		TakerFeeRate: e.account.TakerFeeRate,
 	}
 }
 
@@ -319,6 +352,8 @@ func newPublicExchange(sourceExchange types.ExchangeName) (types.Exchange, error
 		return ftx.NewExchange("", "", ""), nil
 	case types.ExchangeOKEx:
 		return okex.New("", "", ""), nil
+	case types.ExchangeBitfinex:
+		return bitfinex.New("", ""), nil
 	}
 
 	return nil, fmt.Errorf("public data from exchange %s is not supported", sourceExchange)
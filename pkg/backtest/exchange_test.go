@@ -0,0 +1,56 @@
+package backtest
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func Test_newAccount(t *testing.T) {
+	home, err := ioutil.TempDir("", "bbgo-home")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(home)
+
+	oldHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", oldHome)
+	_ = os.Setenv("HOME", home)
+
+	assert.NoError(t, bbgo.SaveFeeTierCache(types.ExchangeBinance, types.FeeTier{
+		MakerFeeRate: fixedpoint.NewFromFloat(0.0009),
+		TakerFeeRate: fixedpoint.NewFromFloat(0.001),
+	}))
+
+	// the cached fee tier is not used by default
+	account, err := newAccount(types.ExchangeBinance, bbgo.BacktestAccount{})
+	assert.NoError(t, err)
+	assert.Equal(t, fixedpoint.Value(0), account.MakerFeeRate)
+	assert.Equal(t, fixedpoint.Value(0), account.TakerFeeRate)
+
+	account, err = newAccount(types.ExchangeBinance, bbgo.BacktestAccount{UseFeeTierCache: true})
+	assert.NoError(t, err)
+	assert.Equal(t, fixedpoint.NewFromFloat(0.0009), account.MakerFeeRate)
+	assert.Equal(t, fixedpoint.NewFromFloat(0.001), account.TakerFeeRate)
+
+	// the configured fee rates take precedence
+	account, err = newAccount(types.ExchangeBinance, bbgo.BacktestAccount{
+		MakerFeeRate:    fixedpoint.NewFromFloat(0.0002),
+		TakerFeeRate:    fixedpoint.NewFromFloat(0.0004),
+		UseFeeTierCache: true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, fixedpoint.NewFromFloat(0.0002), account.MakerFeeRate)
+	assert.Equal(t, fixedpoint.NewFromFloat(0.0004), account.TakerFeeRate)
+
+	// the fee tier of the other exchange is not cached
+	account, err = newAccount(types.ExchangeMax, bbgo.BacktestAccount{UseFeeTierCache: true})
+	assert.NoError(t, err)
+	assert.Equal(t, fixedpoint.Value(0), account.MakerFeeRate)
+}
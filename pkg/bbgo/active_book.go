@@ -1,7 +1,9 @@
 package bbgo
 
 import (
+	"context"
 	"encoding/json"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -14,7 +16,8 @@ type LocalActiveOrderBook struct {
 	Bids *types.SyncOrderMap
 	Asks *types.SyncOrderMap
 
-	filledCallbacks []func(o types.Order)
+	filledCallbacks   []func(o types.Order)
+	canceledCallbacks []func(o types.Order)
 }
 
 func NewLocalActiveOrderBook() *LocalActiveOrderBook {
@@ -60,7 +63,9 @@ func (b *LocalActiveOrderBook) orderUpdateHandler(order types.Order) {
 
 	case types.OrderStatusCanceled, types.OrderStatusRejected:
 		log.Debugf("[LocalActiveOrderBook] order status %s, removing %d...", order.Status, order.OrderID)
-		b.Remove(order)
+		if b.Remove(order) {
+			b.EmitCanceled(order)
+		}
 
 	default:
 		log.Warnf("unhandled order status: %s", order.Status)
@@ -174,3 +179,151 @@ func (b *LocalActiveOrderBook) NumOfOrders() int {
 func (b *LocalActiveOrderBook) Orders() types.OrderSlice {
 	return append(b.Asks.Orders(), b.Bids.Orders()...)
 }
+
+// ReconcileResult is the orders resolved by the reconciliation
+type ReconcileResult struct {
+	// Updated is the orders that are still open but the executed quantity or the status is changed
+	Updated types.OrderSlice
+
+	// Filled is the orders that were filled while the update was missed
+	Filled types.OrderSlice
+
+	// Canceled is the orders that were canceled or rejected while the update was missed,
+	// the orders that can not be found in the order history are treated as canceled as well.
+	Canceled types.OrderSlice
+}
+
+// Reconcile compares the tracked orders with the open orders queried from the exchange to detect the missed order updates,
+// for example, the orders filled or canceled while the user data stream was disconnected.
+// The tracked orders that are not open any more are resolved by the closed order history if the exchange supports it,
+// and they go through the same update handler, so the filled callbacks are still triggered.
+func (b *LocalActiveOrderBook) Reconcile(ctx context.Context, exchange types.Exchange) (*ReconcileResult, error) {
+	var symbols []string
+	var ordersBySymbol = make(map[string]types.OrderSlice)
+	for _, order := range b.Orders() {
+		if _, ok := ordersBySymbol[order.Symbol]; !ok {
+			symbols = append(symbols, order.Symbol)
+		}
+		ordersBySymbol[order.Symbol] = append(ordersBySymbol[order.Symbol], order)
+	}
+
+	var result ReconcileResult
+	for _, symbol := range symbols {
+		openOrders, err := exchange.QueryOpenOrders(ctx, symbol)
+		if err != nil {
+			return &result, err
+		}
+
+		var openOrderMap = make(map[uint64]types.Order, len(openOrders))
+		for _, o := range openOrders {
+			openOrderMap[o.OrderID] = o
+		}
+
+		var missingOrders types.OrderSlice
+		for _, order := range ordersBySymbol[symbol] {
+			openOrder, ok := openOrderMap[order.OrderID]
+			if !ok {
+				missingOrders = append(missingOrders, order)
+				continue
+			}
+
+			if openOrder.ExecutedQuantity != order.ExecutedQuantity || openOrder.Status != order.Status {
+				b.Update(openOrder)
+				result.Updated = append(result.Updated, openOrder)
+			}
+		}
+
+		if len(missingOrders) == 0 {
+			continue
+		}
+
+		closedOrders, err := b.queryClosedOrders(ctx, exchange, symbol, missingOrders)
+		if err != nil {
+			return &result, err
+		}
+
+		for _, order := range missingOrders {
+			closedOrder, ok := closedOrders[order.OrderID]
+			if !ok {
+				log.Warnf("[LocalActiveOrderBook] %s order %d is not open and not found in the order history, treating it as canceled", order.Symbol, order.OrderID)
+				closedOrder = order
+				closedOrder.Status = types.OrderStatusCanceled
+			}
+
+			switch closedOrder.Status {
+			case types.OrderStatusFilled:
+				result.Filled = append(result.Filled, closedOrder)
+
+			case types.OrderStatusCanceled, types.OrderStatusRejected:
+				result.Canceled = append(result.Canceled, closedOrder)
+
+			default:
+				// the order history is not consistent with the open orders, leave it to the next reconciliation
+				continue
+			}
+
+			b.orderUpdateHandler(closedOrder)
+		}
+	}
+
+	return &result, nil
+}
+
+// queryClosedOrders queries the closed orders since the earliest missing order was created
+func (b *LocalActiveOrderBook) queryClosedOrders(ctx context.Context, exchange types.Exchange, symbol string, missingOrders types.OrderSlice) (map[uint64]types.Order, error) {
+	var closedOrders = make(map[uint64]types.Order)
+
+	service, ok := exchange.(types.ExchangeTradeHistoryService)
+	if !ok {
+		return closedOrders, nil
+	}
+
+	now := time.Now()
+	since := now
+	for _, order := range missingOrders {
+		if t := order.CreationTime.Time(); !t.IsZero() && t.Before(since) {
+			since = t
+		}
+	}
+
+	// query with a small margin in case the local clock is ahead of the exchange
+	orders, err := service.QueryClosedOrders(ctx, symbol, since.Add(-time.Minute), now, 0)
+	if err != nil {
+		return closedOrders, err
+	}
+
+	for _, o := range orders {
+		closedOrders[o.OrderID] = o
+	}
+
+	return closedOrders, nil
+}
+
+// RunReconciliation reconciles the tracked orders periodically until the context is done
+func (b *LocalActiveOrderBook) RunReconciliation(ctx context.Context, exchange types.Exchange, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			if b.NumOfOrders() == 0 {
+				continue
+			}
+
+			result, err := b.Reconcile(ctx, exchange)
+			if err != nil {
+				log.WithError(err).Errorf("[LocalActiveOrderBook] order reconciliation error")
+				continue
+			}
+
+			if n := len(result.Updated) + len(result.Filled) + len(result.Canceled); n > 0 {
+				log.Warnf("[LocalActiveOrderBook] reconciled %d missed order updates: %d updated, %d filled, %d canceled",
+					n, len(result.Updated), len(result.Filled), len(result.Canceled))
+			}
+		}
+	}
+}
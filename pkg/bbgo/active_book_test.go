@@ -0,0 +1,89 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type reconcileTestExchange struct {
+	types.Exchange
+
+	openOrders   []types.Order
+	closedOrders []types.Order
+}
+
+func (e *reconcileTestExchange) QueryOpenOrders(ctx context.Context, symbol string) ([]types.Order, error) {
+	return e.openOrders, nil
+}
+
+func (e *reconcileTestExchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) ([]types.Trade, error) {
+	return nil, nil
+}
+
+func (e *reconcileTestExchange) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) ([]types.Order, error) {
+	return e.closedOrders, nil
+}
+
+func newReconcileTestOrder(orderID uint64, side types.SideType, status types.OrderStatus) types.Order {
+	return types.Order{
+		SubmitOrder:  types.SubmitOrder{Symbol: "BTCUSDT", Side: side, Type: types.OrderTypeLimit, Price: 50000.0, Quantity: 0.1},
+		OrderID:      orderID,
+		Status:       status,
+		CreationTime: types.Time(time.Now()),
+	}
+}
+
+func TestLocalActiveOrderBook_Reconcile(t *testing.T) {
+	book := NewLocalActiveOrderBook()
+	book.Add(
+		newReconcileTestOrder(1, types.SideTypeBuy, types.OrderStatusNew),
+		newReconcileTestOrder(2, types.SideTypeBuy, types.OrderStatusNew),
+		newReconcileTestOrder(3, types.SideTypeSell, types.OrderStatusNew),
+		newReconcileTestOrder(4, types.SideTypeSell, types.OrderStatusNew),
+	)
+
+	var filledOrders, canceledOrders []types.Order
+	book.OnFilled(func(o types.Order) { filledOrders = append(filledOrders, o) })
+	book.OnCanceled(func(o types.Order) { canceledOrders = append(canceledOrders, o) })
+
+	// order 1 is partially filled, order 2 is filled, order 3 is canceled and order 4 is missing in the history
+	partiallyFilled := newReconcileTestOrder(1, types.SideTypeBuy, types.OrderStatusPartiallyFilled)
+	partiallyFilled.ExecutedQuantity = 0.05
+
+	filled := newReconcileTestOrder(2, types.SideTypeBuy, types.OrderStatusFilled)
+	filled.ExecutedQuantity = 0.1
+
+	exchange := &reconcileTestExchange{
+		openOrders: []types.Order{partiallyFilled},
+		closedOrders: []types.Order{
+			filled,
+			newReconcileTestOrder(3, types.SideTypeSell, types.OrderStatusCanceled),
+		},
+	}
+
+	result, err := book.Reconcile(context.Background(), exchange)
+	assert.NoError(t, err)
+	assert.Len(t, result.Updated, 1)
+	assert.Len(t, result.Filled, 1)
+	assert.Len(t, result.Canceled, 2)
+
+	assert.Equal(t, 1, book.NumOfOrders())
+	assert.Equal(t, 0.05, book.Orders()[0].ExecutedQuantity)
+
+	if assert.Len(t, filledOrders, 1) {
+		assert.Equal(t, uint64(2), filledOrders[0].OrderID)
+	}
+	assert.Len(t, canceledOrders, 2)
+
+	// nothing changes on the second reconciliation
+	result, err = book.Reconcile(context.Background(), exchange)
+	assert.NoError(t, err)
+	assert.Len(t, result.Updated, 0)
+	assert.Len(t, result.Filled, 0)
+	assert.Len(t, result.Canceled, 0)
+}
@@ -0,0 +1,123 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// AssetBalance is the balance of an asset aggregated across the sessions
+type AssetBalance struct {
+	Currency  string  `json:"currency"`
+	Available float64 `json:"available"`
+	Locked    float64 `json:"locked"`
+	Total     float64 `json:"total"`
+
+	// Value is the total in the reference currency, it's zero if the asset can not be converted
+	Value  float64 `json:"value"`
+	Valued bool    `json:"valued"`
+
+	// Sessions is the total of the asset by the session name
+	Sessions map[string]float64 `json:"sessions"`
+}
+
+// BalanceReport is the balances of the sessions aggregated by the asset and valued in the reference currency
+type BalanceReport struct {
+	Currency string         `json:"currency"`
+	Assets   []AssetBalance `json:"assets"`
+
+	// Total is the total value of the valued assets
+	Total float64 `json:"total"`
+
+	// Missing are the assets that can not be converted into the reference currency
+	Missing []string `json:"missing,omitempty"`
+}
+
+// AggregateBalances aggregates the balances (by the session name) by the asset, the assets are sorted by the value
+// in the descending order and then by the currency
+func AggregateBalances(ctx context.Context, converter *CurrencyConverter, currency string, balances map[string]types.BalanceMap) BalanceReport {
+	var assets = make(map[string]*AssetBalance)
+	for sessionName, sessionBalances := range balances {
+		for _, balance := range sessionBalances {
+			if balance.Total() == 0 {
+				continue
+			}
+
+			asset, ok := assets[balance.Currency]
+			if !ok {
+				asset = &AssetBalance{Currency: balance.Currency, Sessions: make(map[string]float64)}
+				assets[balance.Currency] = asset
+			}
+
+			asset.Available += balance.Available.Float64()
+			asset.Locked += balance.Locked.Float64()
+			asset.Total += balance.Total().Float64()
+			asset.Sessions[sessionName] += balance.Total().Float64()
+		}
+	}
+
+	report := BalanceReport{Currency: currency, Assets: []AssetBalance{}}
+	for _, asset := range assets {
+		if value, err := converter.Convert(ctx, asset.Total, asset.Currency, currency); err == nil {
+			asset.Value = value
+			asset.Valued = true
+			report.Total += value
+		} else {
+			report.Missing = append(report.Missing, asset.Currency)
+		}
+
+		report.Assets = append(report.Assets, *asset)
+	}
+
+	sort.Slice(report.Assets, func(i, j int) bool {
+		if report.Assets[i].Value != report.Assets[j].Value {
+			return report.Assets[i].Value > report.Assets[j].Value
+		}
+
+		return report.Assets[i].Currency < report.Assets[j].Currency
+	})
+	sort.Strings(report.Missing)
+	return report
+}
+
+// WriteTable writes the assets as a table with the total value
+func (r BalanceReport) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "ASSET\tTOTAL\tAVAILABLE\tLOCKED\tVALUE (%s)\tSESSIONS\n", r.Currency)
+	for _, asset := range r.Assets {
+		value := "-"
+		if asset.Valued {
+			value = fmt.Sprintf("%.2f", asset.Value)
+		}
+
+		var names []string
+		for name := range asset.Sessions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var sessions []string
+		for _, name := range names {
+			sessions = append(sessions, fmt.Sprintf("%s:%f", name, asset.Sessions[name]))
+		}
+
+		fmt.Fprintf(tw, "%s\t%f\t%f\t%f\t%s\t%s\n", asset.Currency, asset.Total, asset.Available, asset.Locked, value, strings.Join(sessions, " "))
+	}
+
+	fmt.Fprintf(tw, "TOTAL\t\t\t\t%.2f\t\n", r.Total)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(r.Missing) > 0 {
+		_, err := fmt.Fprintf(w, "%s can not be converted to %s, they are excluded from the total\n", strings.Join(r.Missing, ", "), r.Currency)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package bbgo
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestAggregateBalances(t *testing.T) {
+	converter := NewCurrencyConverter(CurrencyConversionConfig{}, nil)
+	converter.updateTime = time.Now()
+	converter.rates.Add("BTC", "USDT", 50000.0)
+
+	report := AggregateBalances(context.Background(), converter, "USDT", map[string]types.BalanceMap{
+		"binance": {
+			"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(0.1), Locked: fixedpoint.NewFromFloat(0.1)},
+			"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(1000.0)},
+			"BNB":  {Currency: "BNB"},
+		},
+		"max": {
+			"BTC": {Currency: "BTC", Available: fixedpoint.NewFromFloat(0.3)},
+			"MAX": {Currency: "MAX", Available: fixedpoint.NewFromFloat(100.0)},
+		},
+	})
+
+	assert.Equal(t, "USDT", report.Currency)
+	assert.InDelta(t, 25000.0+1000.0, report.Total, 1e-6)
+	assert.Equal(t, []string{"MAX"}, report.Missing)
+
+	if assert.Len(t, report.Assets, 3, "the zero balance of BNB is skipped") {
+		btc := report.Assets[0]
+		assert.Equal(t, "BTC", btc.Currency)
+		assert.InDelta(t, 0.5, btc.Total, 1e-9)
+		assert.InDelta(t, 0.1, btc.Locked, 1e-9)
+		assert.InDelta(t, 0.3, btc.Sessions["max"], 1e-9)
+		assert.True(t, btc.Valued)
+
+		assert.Equal(t, "USDT", report.Assets[1].Currency)
+		assert.Equal(t, "MAX", report.Assets[2].Currency)
+		assert.False(t, report.Assets[2].Valued)
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, report.WriteTable(&buf))
+	assert.Contains(t, buf.String(), "binance:0.200000 max:0.300000")
+	assert.Contains(t, buf.String(), "26000.00")
+	assert.Contains(t, buf.String(), "MAX can not be converted to USDT")
+}
@@ -0,0 +1,156 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultBalanceSnapshotInterval = time.Hour
+
+// BalanceSnapshotConfig records the total value of the session balances into the balance_snapshots table periodically,
+// the records can be used to plot the equity curve of the account
+type BalanceSnapshotConfig struct {
+	// Sessions are the sessions to snapshot, empty means all sessions
+	Sessions []string `json:"sessions,omitempty" yaml:"sessions,omitempty"`
+
+	// Currency is the reference currency of the value, defaults to the currency of the currency converter
+	Currency string `json:"currency,omitempty" yaml:"currency,omitempty"`
+
+	// Interval is the interval of the snapshots, defaults to 1h
+	Interval types.Duration `json:"interval,omitempty" yaml:"interval,omitempty"`
+}
+
+func (c *BalanceSnapshotConfig) Validate() error {
+	if c.Interval.Duration() != 0 && c.Interval.Duration() < time.Minute {
+		return fmt.Errorf("balance snapshot interval %s should be at least 1m", c.Interval.Duration())
+	}
+
+	return nil
+}
+
+// BalanceSnapshotter snapshots the balances of the sessions, the balances are converted to the reference currency
+// by the currency converter
+type BalanceSnapshotter struct {
+	Currency string
+	Interval time.Duration
+
+	service   *service.BalanceSnapshotService
+	sessions  map[string]*ExchangeSession
+	converter *CurrencyConverter
+}
+
+func NewBalanceSnapshotter(config BalanceSnapshotConfig, snapshotService *service.BalanceSnapshotService, sessions map[string]*ExchangeSession, converter *CurrencyConverter) *BalanceSnapshotter {
+	currency := config.Currency
+	if len(currency) == 0 {
+		currency = converter.Currency
+	}
+
+	interval := config.Interval.Duration()
+	if interval == 0 {
+		interval = defaultBalanceSnapshotInterval
+	}
+
+	return &BalanceSnapshotter{
+		Currency:  currency,
+		Interval:  interval,
+		service:   snapshotService,
+		sessions:  sessions,
+		converter: converter,
+	}
+}
+
+// Snapshot takes the snapshots of the sessions and inserts them into the database if the service is set,
+// the session that fails to be snapshotted is logged and skipped
+func (s *BalanceSnapshotter) Snapshot(ctx context.Context, now time.Time) []types.BalanceSnapshot {
+	var names []string
+	for name := range s.sessions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var snapshots []types.BalanceSnapshot
+	for _, name := range names {
+		snapshot, err := s.snapshotSession(ctx, name, s.sessions[name], now)
+		if err != nil {
+			log.WithError(err).Errorf("can not snapshot the balances of session %s", name)
+			continue
+		}
+
+		if s.service != nil {
+			if err := s.service.Insert(&snapshot); err != nil {
+				log.WithError(err).Errorf("can not insert the balance snapshot of session %s", name)
+				continue
+			}
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots
+}
+
+func (s *BalanceSnapshotter) snapshotSession(ctx context.Context, name string, session *ExchangeSession, now time.Time) (types.BalanceSnapshot, error) {
+	balances, err := session.Exchange.QueryAccountBalances(ctx)
+	if err != nil {
+		return types.BalanceSnapshot{}, err
+	}
+
+	value, missing := ValueBalances(ctx, s.converter, balances, s.Currency)
+	if len(missing) > 0 {
+		log.Warnf("session %s: %v can not be converted to %s, they are excluded from the snapshot", name, missing, s.Currency)
+	}
+
+	return types.BalanceSnapshot{
+		Session:  name,
+		Exchange: session.ExchangeName,
+		Currency: s.Currency,
+		Value:    value,
+		Time:     types.Time(now),
+	}, nil
+}
+
+// Run takes the snapshots every interval until the context is done
+func (s *BalanceSnapshotter) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case now := <-ticker.C:
+			s.Snapshot(ctx, now)
+		}
+	}
+}
+
+// ConfigureBalanceSnapshots starts the balance snapshot job of the sessions, the database is required
+func (environ *Environment) ConfigureBalanceSnapshots(ctx context.Context, config *BalanceSnapshotConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	if environ.BalanceSnapshotService == nil {
+		return errors.New("balance snapshots require the database, please check the DB_DRIVER and DB_DSN environment variables")
+	}
+
+	sessions := environ.SelectSessions(config.Sessions...)
+	if len(sessions) == 0 {
+		return errors.New("no session found for the balance snapshots")
+	}
+
+	snapshotter := NewBalanceSnapshotter(*config, environ.BalanceSnapshotService, sessions, environ.currencyConverter)
+
+	log.Infof("balance snapshots of %d sessions are configured, interval %s, currency %s", len(sessions), snapshotter.Interval, snapshotter.Currency)
+	go snapshotter.Run(ctx)
+	return nil
+}
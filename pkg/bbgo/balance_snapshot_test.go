@@ -0,0 +1,75 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type balanceSnapshotTestExchange struct {
+	types.Exchange
+
+	balances types.BalanceMap
+	tickers  map[string]types.Ticker
+}
+
+func (e *balanceSnapshotTestExchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	return e.balances, nil
+}
+
+func (e *balanceSnapshotTestExchange) QueryTickers(ctx context.Context, symbols ...string) (map[string]types.Ticker, error) {
+	var tickers = make(map[string]types.Ticker)
+	for _, symbol := range symbols {
+		if ticker, ok := e.tickers[symbol]; ok {
+			tickers[symbol] = ticker
+		}
+	}
+
+	return tickers, nil
+}
+
+func TestBalanceSnapshotter_Snapshot(t *testing.T) {
+	exchange := &balanceSnapshotTestExchange{
+		balances: types.BalanceMap{
+			"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(1000.0)},
+			"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(0.1)},
+		},
+		tickers: map[string]types.Ticker{"BTCUSDT": {Last: 50000.0}},
+	}
+
+	session := &ExchangeSession{
+		Name:         "binance",
+		ExchangeName: types.ExchangeBinance,
+		Exchange:     exchange,
+		markets: map[string]types.Market{
+			"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"},
+		},
+	}
+
+	sessions := map[string]*ExchangeSession{"binance": session}
+	snapshotter := NewBalanceSnapshotter(BalanceSnapshotConfig{}, nil, sessions, NewCurrencyConverter(CurrencyConversionConfig{}, sessions))
+	assert.Equal(t, "USDT", snapshotter.Currency)
+	assert.Equal(t, time.Hour, snapshotter.Interval)
+
+	now := time.Now()
+	snapshots := snapshotter.Snapshot(context.Background(), now)
+	if assert.Len(t, snapshots, 1) {
+		assert.Equal(t, "binance", snapshots[0].Session)
+		assert.Equal(t, types.ExchangeBinance, snapshots[0].Exchange)
+		assert.InDelta(t, 6000.0, snapshots[0].Value, 1e-6)
+		assert.Equal(t, now, snapshots[0].Time.Time())
+	}
+}
+
+func TestEnvironment_ConfigureBalanceSnapshots(t *testing.T) {
+	assert.Error(t, (&BalanceSnapshotConfig{Interval: types.Duration(time.Second)}).Validate())
+
+	environ := NewEnvironment()
+	err := environ.ConfigureBalanceSnapshots(context.Background(), &BalanceSnapshotConfig{})
+	assert.Error(t, err, "the database is required")
+}
@@ -0,0 +1,267 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// maxKLinesPerQuery is the kline limit of one query, most exchanges return at most 1000 klines
+const maxKLinesPerQuery = 1000
+
+// HistoryRequirement declares the historical data that a strategy needs before the real-time data,
+// either KLines or KLineWindow can be used to define the number of the klines.
+type HistoryRequirement struct {
+	// Session is only used by the cross exchange strategies
+	Session string `json:"session,omitempty"`
+
+	Symbol   string         `json:"symbol"`
+	Interval types.Interval `json:"interval,omitempty"`
+
+	// KLines is the number of the closed klines
+	KLines int `json:"klines,omitempty"`
+
+	// KLineWindow is the time range of the closed klines, e.g., 24h of the 1m klines
+	KLineWindow types.Duration `json:"klineWindow,omitempty"`
+
+	// TradeWindow is the time range of the public trades, the trades are loaded from the synced aggregated trades
+	TradeWindow types.Duration `json:"tradeWindow,omitempty"`
+}
+
+// NumOfKLines returns the number of the klines that the requirement needs
+func (r HistoryRequirement) NumOfKLines() int {
+	n := r.KLines
+	if r.KLineWindow > 0 && len(r.Interval) > 0 && r.Interval.Duration() > 0 {
+		if w := int(r.KLineWindow.Duration() / r.Interval.Duration()); w > n {
+			n = w
+		}
+	}
+
+	return n
+}
+
+// HistoryBootstrapper is implemented by the strategies that need the historical data at startup.
+// The historical klines are fed through the KLineClosed callbacks of the session market data stream and
+// the historical trades are fed through the MarketTrade callbacks, after all the strategies are started and
+// before the streams are connected, so the strategies warm up with the same handlers of the live data.
+// Submitting orders is rejected while the historical data is being fed.
+type HistoryBootstrapper interface {
+	HistoryRequirements() []HistoryRequirement
+}
+
+// historyStream is implemented by the streams based on the StandardStream
+type historyStream interface {
+	EmitKLineClosed(kline types.KLine)
+	EmitMarketTrade(trade types.Trade)
+}
+
+type historyKLineKey struct {
+	symbol   string
+	interval types.Interval
+}
+
+// historyPlan is the merged requirements of one session
+type historyPlan struct {
+	klines map[historyKLineKey]int
+	trades map[string]time.Duration
+}
+
+func newHistoryPlan() *historyPlan {
+	return &historyPlan{
+		klines: make(map[historyKLineKey]int),
+		trades: make(map[string]time.Duration),
+	}
+}
+
+// Add merges the requirement, the largest window of the same symbol and interval is used
+func (p *historyPlan) Add(r HistoryRequirement) {
+	if n := r.NumOfKLines(); n > 0 && len(r.Interval) > 0 {
+		key := historyKLineKey{symbol: r.Symbol, interval: r.Interval}
+		if n > p.klines[key] {
+			p.klines[key] = n
+		}
+	}
+
+	if w := r.TradeWindow.Duration(); w > p.trades[r.Symbol] {
+		p.trades[r.Symbol] = w
+	}
+}
+
+func (p *historyPlan) IsEmpty() bool {
+	return len(p.klines) == 0 && len(p.trades) == 0
+}
+
+// IsBootstrapping returns true when the session is feeding the historical data
+func (session *ExchangeSession) IsBootstrapping() bool {
+	return atomic.LoadInt32(&session.bootstrapping) == 1
+}
+
+func (session *ExchangeSession) setBootstrapping(b bool) {
+	var v int32
+	if b {
+		v = 1
+	}
+
+	atomic.StoreInt32(&session.bootstrapping, v)
+}
+
+// BootstrapHistory fetches the historical data of the requirements and feeds them through the session stream callbacks
+func (environ *Environment) BootstrapHistory(ctx context.Context, session *ExchangeSession, requirements []HistoryRequirement) error {
+	plan := newHistoryPlan()
+	for _, r := range requirements {
+		plan.Add(r)
+	}
+
+	if plan.IsEmpty() {
+		return nil
+	}
+
+	stream, ok := session.MarketDataStream.(historyStream)
+	if !ok {
+		return fmt.Errorf("the market data stream %T of session %s can not feed the historical data", session.MarketDataStream, session.Name)
+	}
+
+	session.setBootstrapping(true)
+	defer session.setBootstrapping(false)
+
+	var keys []historyKLineKey
+	for key := range plan.klines {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].symbol == keys[j].symbol {
+			return keys[i].interval.Duration() < keys[j].interval.Duration()
+		}
+		return keys[i].symbol < keys[j].symbol
+	})
+
+	for _, key := range keys {
+		kLines, err := queryHistoryKLines(ctx, session.Exchange, key.symbol, key.interval, environ.startTime, plan.klines[key])
+		if err != nil {
+			return errors.Wrapf(err, "can not query the historical %s %s klines", key.symbol, key.interval)
+		}
+
+		log.Infof("session %s: feeding %d historical %s %s klines", session.Name, len(kLines), key.symbol, key.interval)
+		for _, k := range kLines {
+			stream.EmitKLineClosed(k)
+		}
+	}
+
+	if len(plan.trades) > 0 {
+		if environ.DatabaseService == nil {
+			log.Warnf("session %s: database is not configured, historical trades are not fed", session.Name)
+			return nil
+		}
+
+		aggTradeService := &service.AggTradeService{DB: environ.DatabaseService.DB}
+		for symbol, window := range plan.trades {
+			trades, err := aggTradeService.Query(session.ExchangeName, symbol, environ.startTime.Add(-window), environ.startTime)
+			if err != nil {
+				return errors.Wrapf(err, "can not query the historical %s trades", symbol)
+			}
+
+			log.Infof("session %s: feeding %d historical %s trades", session.Name, len(trades), symbol)
+			for _, trade := range trades {
+				stream.EmitMarketTrade(trade.Trade())
+			}
+		}
+	}
+
+	return nil
+}
+
+// queryHistoryKLines queries the last n closed klines before the end time, the klines are queried backward
+// page by page since the exchanges limit the number of klines per query.
+func queryHistoryKLines(ctx context.Context, exchange types.Exchange, symbol string, interval types.Interval, endTime time.Time, n int) ([]types.KLine, error) {
+	var kLines []types.KLine
+	for len(kLines) < n {
+		limit := n - len(kLines)
+		if limit > maxKLinesPerQuery {
+			limit = maxKLinesPerQuery
+		}
+
+		// exclude the kline starts at the end time, it's not closed or it's already loaded
+		until := endTime.Add(-time.Millisecond)
+		batch, err := exchange.QueryKLines(ctx, symbol, interval, types.KLineQueryOptions{
+			EndTime: &until,
+			Limit:   limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// drop the klines that are not closed before the end time
+		var closed []types.KLine
+		for _, k := range batch {
+			if k.EndTime.Before(endTime) {
+				closed = append(closed, k)
+			}
+		}
+
+		if len(closed) == 0 {
+			break
+		}
+
+		kLines = append(closed, kLines...)
+		endTime = closed[0].StartTime
+
+		if len(batch) < limit {
+			break
+		}
+	}
+
+	if len(kLines) > n {
+		kLines = kLines[len(kLines)-n:]
+	}
+
+	return kLines, nil
+}
+
+// BootstrapHistory collects the history requirements of the strategies and feeds the historical data of each session
+func (trader *Trader) BootstrapHistory(ctx context.Context) error {
+	requirements := make(map[string][]HistoryRequirement)
+	for sessionName, strategies := range trader.exchangeStrategies {
+		for _, strategy := range strategies {
+			if bootstrapper, ok := strategy.(HistoryBootstrapper); ok {
+				requirements[sessionName] = append(requirements[sessionName], bootstrapper.HistoryRequirements()...)
+			}
+		}
+	}
+
+	for _, strategy := range trader.crossExchangeStrategies {
+		bootstrapper, ok := strategy.(HistoryBootstrapper)
+		if !ok {
+			continue
+		}
+
+		for _, r := range bootstrapper.HistoryRequirements() {
+			if len(r.Session) == 0 {
+				return fmt.Errorf("strategy %s: history requirement of %s needs the session name", strategy.ID(), r.Symbol)
+			}
+
+			requirements[r.Session] = append(requirements[r.Session], r)
+		}
+	}
+
+	for sessionName, sessionRequirements := range requirements {
+		session, ok := trader.environment.Session(sessionName)
+		if !ok {
+			return fmt.Errorf("session %s of the history requirements is not found", sessionName)
+		}
+
+		if err := trader.environment.BootstrapHistory(ctx, session, sessionRequirements); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
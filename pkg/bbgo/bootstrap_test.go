@@ -0,0 +1,157 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type bootstrapTestStream struct {
+	types.StandardStream
+}
+
+func (s *bootstrapTestStream) SetPublicOnly() {}
+
+type bootstrapTestExchange struct {
+	types.Exchange
+
+	kLines  []types.KLine
+	queries int
+}
+
+func (e *bootstrapTestExchange) QueryKLines(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
+	e.queries++
+
+	var kLines []types.KLine
+	for _, k := range e.kLines {
+		if k.Symbol == symbol && k.Interval == interval && !k.StartTime.After(*options.EndTime) {
+			kLines = append(kLines, k)
+		}
+	}
+
+	if len(kLines) > options.Limit {
+		kLines = kLines[len(kLines)-options.Limit:]
+	}
+
+	return kLines, nil
+}
+
+func newBootstrapTestExchange(startTime time.Time, n int) *bootstrapTestExchange {
+	exchange := &bootstrapTestExchange{}
+	for i := 0; i < n; i++ {
+		t := startTime.Add(time.Duration(i) * time.Minute)
+		exchange.kLines = append(exchange.kLines, types.KLine{
+			Symbol:    "BTCUSDT",
+			Interval:  types.Interval1m,
+			StartTime: t,
+			EndTime:   t.Add(time.Minute - time.Millisecond),
+			Close:     float64(i),
+		})
+	}
+
+	return exchange
+}
+
+func TestHistoryRequirement_NumOfKLines(t *testing.T) {
+	assert.Equal(t, 100, HistoryRequirement{Interval: types.Interval1m, KLines: 100}.NumOfKLines())
+	assert.Equal(t, 1440, HistoryRequirement{Interval: types.Interval1m, KLines: 100, KLineWindow: types.Duration(24 * time.Hour)}.NumOfKLines())
+	assert.Equal(t, 24, HistoryRequirement{Interval: types.Interval1h, KLineWindow: types.Duration(24 * time.Hour)}.NumOfKLines())
+}
+
+func Test_queryHistoryKLines(t *testing.T) {
+	startTime := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	exchange := newBootstrapTestExchange(startTime, 2500)
+
+	// the last kline is not closed at the end time
+	endTime := startTime.Add(2499*time.Minute + 30*time.Second)
+	kLines, err := queryHistoryKLines(context.Background(), exchange, "BTCUSDT", types.Interval1m, endTime, 2200)
+	assert.NoError(t, err)
+	if assert.Len(t, kLines, 2200) {
+		assert.Equal(t, 299.0, kLines[0].Close)
+		assert.Equal(t, 2498.0, kLines[len(kLines)-1].Close)
+
+		for i := 1; i < len(kLines); i++ {
+			assert.Equal(t, kLines[i-1].Close+1, kLines[i].Close)
+		}
+	}
+	assert.Equal(t, 3, exchange.queries)
+
+	// less klines than the requirement
+	kLines, err = queryHistoryKLines(context.Background(), exchange, "BTCUSDT", types.Interval1m, startTime.Add(10*time.Minute), 100)
+	assert.NoError(t, err)
+	assert.Len(t, kLines, 10)
+}
+
+type bootstrapTestStrategy struct {
+	requirements []HistoryRequirement
+}
+
+func (s *bootstrapTestStrategy) ID() string { return "bootstrap" }
+
+func (s *bootstrapTestStrategy) Run(ctx context.Context, orderExecutor OrderExecutor, session *ExchangeSession) error {
+	return nil
+}
+
+func (s *bootstrapTestStrategy) HistoryRequirements() []HistoryRequirement {
+	return s.requirements
+}
+
+func TestTrader_BootstrapHistory(t *testing.T) {
+	startTime := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	exchange := newBootstrapTestExchange(startTime, 100)
+
+	stream := &bootstrapTestStream{StandardStream: types.NewStandardStream()}
+	session := &ExchangeSession{
+		Name:             "binance",
+		Exchange:         exchange,
+		MarketDataStream: stream,
+	}
+	session.OrderExecutor = &ExchangeOrderExecutor{Session: session}
+
+	environ := NewEnvironment()
+	environ.SetStartTime(startTime.Add(100 * time.Minute))
+	environ.AddExchangeSession("binance", session)
+
+	var closedKLines []types.KLine
+	var submitErr error
+	stream.OnKLineClosed(func(k types.KLine) {
+		closedKLines = append(closedKLines, k)
+		_, submitErr = session.OrderExecutor.SubmitOrders(context.Background(), types.SubmitOrder{Symbol: "BTCUSDT"})
+	})
+
+	trader := NewTrader(environ)
+	err := trader.AttachStrategyOn("binance", &bootstrapTestStrategy{
+		requirements: []HistoryRequirement{
+			{Symbol: "BTCUSDT", Interval: types.Interval1m, KLines: 10},
+			{Symbol: "BTCUSDT", Interval: types.Interval1m, KLineWindow: types.Duration(30 * time.Minute)},
+		},
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, trader.BootstrapHistory(context.Background()))
+	if assert.Len(t, closedKLines, 30) {
+		assert.Equal(t, 70.0, closedKLines[0].Close)
+		assert.Equal(t, 99.0, closedKLines[29].Close)
+	}
+
+	assert.Equal(t, ErrSessionBootstrapping, submitErr)
+	assert.False(t, session.IsBootstrapping())
+}
+
+func TestMarketDataStore_AddKLine_Overlapped(t *testing.T) {
+	startTime := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMarketDataStore("BTCUSDT")
+
+	for _, i := range []int{0, 1, 2, 1, 3} {
+		kt := startTime.Add(time.Duration(i) * time.Minute)
+		store.AddKLine(types.KLine{Symbol: "BTCUSDT", Interval: types.Interval1m, StartTime: kt, EndTime: kt.Add(time.Minute)})
+	}
+
+	window, ok := store.KLinesOfInterval(types.Interval1m)
+	assert.True(t, ok)
+	assert.Len(t, window, 4)
+}
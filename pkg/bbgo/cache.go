@@ -60,7 +60,7 @@ func WithCache(key string, obj interface{}, fetcher DataFetcher) error {
 	return nil
 }
 
-func LoadExchangeMarketsWithCache(ctx context.Context, ex types.Exchange) (markets types.MarketMap, err error) {
+func exchangeMarketsCacheKey(ex types.Exchange) string {
 	key := fmt.Sprintf("%s-markets", ex.Name())
 	if futureExchange, implemented := ex.(types.FuturesExchange); implemented {
 		settings := futureExchange.GetFuturesSettings()
@@ -69,8 +69,22 @@ func LoadExchangeMarketsWithCache(ctx context.Context, ex types.Exchange) (marke
 		}
 	}
 
-	err = WithCache(key, &markets, func() (interface{}, error) {
+	return key
+}
+
+func LoadExchangeMarketsWithCache(ctx context.Context, ex types.Exchange) (markets types.MarketMap, err error) {
+	err = WithCache(exchangeMarketsCacheKey(ex), &markets, func() (interface{}, error) {
 		return ex.QueryMarkets(ctx)
 	})
 	return markets, err
 }
+
+// RefreshExchangeMarketsCache queries the markets from the exchange and replaces the markets cache
+func RefreshExchangeMarketsCache(ctx context.Context, ex types.Exchange) (types.MarketMap, error) {
+	cacheFile := path.Join(CacheDir(), exchangeMarketsCacheKey(ex)+".json")
+	if err := os.Remove(cacheFile); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return LoadExchangeMarketsWithCache(ctx, ex)
+}
@@ -0,0 +1,349 @@
+package bbgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultCommandPollInterval = 3 * time.Second
+
+// CommandHandler executes the command, the returned error is saved to the command
+type CommandHandler func(ctx context.Context, command types.Command) error
+
+// CommandQueue is the persistent command queue, the control layers (the api and the telegram bot) enqueue the
+// commands into the database, and the trader consumes them in the issued order. Since the commands are stored,
+// the commands issued while the bot is restarting are executed once the trader is running again.
+type CommandQueue struct {
+	Service *service.CommandService
+
+	PollInterval time.Duration
+
+	handlers map[types.CommandType]CommandHandler
+}
+
+func NewCommandQueue(commandService *service.CommandService) *CommandQueue {
+	return &CommandQueue{
+		Service:      commandService,
+		PollInterval: defaultCommandPollInterval,
+		handlers:     make(map[types.CommandType]CommandHandler),
+	}
+}
+
+// Register registers the handler of the command type
+func (q *CommandQueue) Register(commandType types.CommandType, handler CommandHandler) {
+	q.handlers[commandType] = handler
+}
+
+// Enqueue stores the command, the payload is encoded as json
+func (q *CommandQueue) Enqueue(commandType types.CommandType, strategy string, payload interface{}, source string) (*types.Command, error) {
+	var command = &types.Command{
+		Type:     commandType,
+		Strategy: strategy,
+		Source:   source,
+	}
+
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+
+		command.Payload = data
+	}
+
+	if err := q.Service.Insert(command); err != nil {
+		return nil, err
+	}
+
+	return command, nil
+}
+
+// Run processes the pending commands periodically until the context is canceled
+func (q *CommandQueue) Run(ctx context.Context) {
+	ticker := time.NewTicker(q.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := q.ProcessPending(ctx); err != nil {
+			log.WithError(err).Error("command queue process error")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessPending executes the pending commands in the issued order
+func (q *CommandQueue) ProcessPending(ctx context.Context) error {
+	commands, err := q.Service.QueryPending(100)
+	if err != nil {
+		return err
+	}
+
+	for _, command := range commands {
+		var status = types.CommandStatusDone
+		var errorMessage string
+
+		if err := q.execute(ctx, command); err != nil {
+			log.WithError(err).Errorf("command %d %s failed", command.GID, command.Type)
+			status = types.CommandStatusFailed
+			errorMessage = err.Error()
+		} else {
+			log.Infof("command %d %s from %s is executed", command.GID, command.Type, command.Source)
+		}
+
+		if err := q.Service.MarkProcessed(command.GID, status, errorMessage); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (q *CommandQueue) execute(ctx context.Context, command types.Command) error {
+	handler, ok := q.handlers[command.Type]
+	if !ok {
+		return fmt.Errorf("unsupported command type %s", command.Type)
+	}
+
+	return handler(ctx, command)
+}
+
+// Pausable is implemented by the strategies that can be paused by the pause_strategy command
+type Pausable interface {
+	Pause() error
+	Resume() error
+}
+
+// ParameterSetter is implemented by the strategies that update their parameters by themselves (e.g., with a lock),
+// otherwise the set_parameter command sets the field of the strategy with the matched json tag.
+type ParameterSetter interface {
+	SetParameter(name string, value json.RawMessage) error
+}
+
+// RegisterCommandHandlers registers the built-in command handlers of the trader
+func (trader *Trader) RegisterCommandHandlers(q *CommandQueue) {
+	q.Register(types.CommandTypePauseStrategy, func(ctx context.Context, command types.Command) error {
+		return trader.forEachStrategy(command.Strategy, func(strategy interface{}) error {
+			pausable, ok := strategy.(Pausable)
+			if !ok {
+				return fmt.Errorf("strategy %T can not be paused", strategy)
+			}
+			return pausable.Pause()
+		})
+	})
+
+	q.Register(types.CommandTypeSuspendStrategy, func(ctx context.Context, command types.Command) error {
+		return trader.SuspendStrategy(ctx, command.Strategy)
+	})
+
+	// the resume command resumes both the paused and the suspended strategies
+	q.Register(types.CommandTypeResumeStrategy, func(ctx context.Context, command types.Command) error {
+		return trader.ResumeStrategy(ctx, command.Strategy)
+	})
+
+	q.Register(types.CommandTypeSetParameter, func(ctx context.Context, command types.Command) error {
+		var payload types.SetParameterCommandPayload
+		if err := json.Unmarshal(command.Payload, &payload); err != nil {
+			return err
+		}
+
+		return trader.forEachStrategy(command.Strategy, func(strategy interface{}) error {
+			return setStrategyParameter(strategy, payload.Parameter, payload.Value)
+		})
+	})
+
+	q.Register(types.CommandTypeResetKillSwitch, func(ctx context.Context, command types.Command) error {
+		if trader.killSwitch == nil {
+			return errors.New("kill switch is not configured")
+		}
+
+		trader.killSwitch.Reset()
+		return nil
+	})
+
+	q.Register(types.CommandTypeHalt, func(ctx context.Context, command types.Command) error {
+		if trader.killSwitch == nil {
+			return errors.New("kill switch is not configured")
+		}
+
+		trader.killSwitch.Trip(ctx, time.Now(), fmt.Sprintf("halted by the %s command", command.Source))
+		return nil
+	})
+
+	q.Register(types.CommandTypeClosePosition, func(ctx context.Context, command types.Command) error {
+		var payload types.ClosePositionCommandPayload
+		if err := json.Unmarshal(command.Payload, &payload); err != nil {
+			return err
+		}
+
+		session, ok := trader.environment.Session(payload.Session)
+		if !ok {
+			return fmt.Errorf("session %s not found", payload.Session)
+		}
+
+		createdOrders, err := session.ClosePosition(ctx, payload.Symbol, payload.Percentage)
+		if err != nil {
+			return err
+		}
+
+		if len(createdOrders) == 0 {
+			return fmt.Errorf("no %s position to close in session %s", payload.Symbol, payload.Session)
+		}
+
+		return nil
+	})
+
+	q.Register(types.CommandTypeCancelAllOrders, func(ctx context.Context, command types.Command) error {
+		var payload types.CancelAllOrdersCommandPayload
+		if err := json.Unmarshal(command.Payload, &payload); err != nil {
+			return err
+		}
+
+		if len(payload.Symbol) == 0 {
+			return errors.New("symbol is required")
+		}
+
+		sessions := trader.environment.Sessions()
+		if len(payload.Session) > 0 {
+			session, ok := trader.environment.Session(payload.Session)
+			if !ok {
+				return fmt.Errorf("session %s not found", payload.Session)
+			}
+
+			sessions = map[string]*ExchangeSession{payload.Session: session}
+		}
+
+		for _, session := range sessions {
+			if _, ok := session.Market(payload.Symbol); !ok {
+				continue
+			}
+
+			canceledOrders, err := session.CancelAllOrders(ctx, payload.Symbol)
+			if err != nil {
+				return errors.Wrapf(err, "can not cancel %s orders on session %s", payload.Symbol, session.Name)
+			}
+
+			log.Infof("canceled %d %s orders on session %s", len(canceledOrders), payload.Symbol, session.Name)
+		}
+
+		return nil
+	})
+
+	q.Register(types.CommandTypeCancelOrder, func(ctx context.Context, command types.Command) error {
+		var payload types.CancelOrderCommandPayload
+		if err := json.Unmarshal(command.Payload, &payload); err != nil {
+			return err
+		}
+
+		session, ok := trader.environment.Session(payload.Session)
+		if !ok {
+			return fmt.Errorf("session %s not found", payload.Session)
+		}
+
+		return session.Exchange.CancelOrders(ctx, types.Order{
+			SubmitOrder: types.SubmitOrder{Symbol: payload.Symbol},
+			OrderID:     payload.OrderID,
+		})
+	})
+}
+
+// forEachStrategy calls the callback with the strategies matched by the target,
+// the strategy can be matched by its ID, or by its ID and symbol, e.g., grid:BTCUSDT
+func (trader *Trader) forEachStrategy(target string, cb func(strategy interface{}) error) error {
+	var matched = 0
+	for _, strategy := range trader.strategies() {
+		if !matchCommandStrategy(strategy, target) {
+			continue
+		}
+
+		matched++
+		if err := cb(strategy); err != nil {
+			return err
+		}
+	}
+
+	if matched == 0 {
+		return fmt.Errorf("strategy %s not found", target)
+	}
+
+	return nil
+}
+
+func matchCommandStrategy(strategy interface{}, target string) bool {
+	s, ok := strategy.(interface{ ID() string })
+	if !ok {
+		return false
+	}
+
+	id := s.ID()
+	if target == id {
+		return true
+	}
+
+	rs := reflect.ValueOf(strategy)
+	if rs.Kind() == reflect.Ptr {
+		rs = rs.Elem()
+	}
+
+	if rs.Kind() != reflect.Struct {
+		return false
+	}
+
+	if symbol, ok := isSymbolBasedStrategy(rs); ok {
+		return target == id+":"+symbol
+	}
+
+	return false
+}
+
+// setStrategyParameter sets the field of the strategy that has the json tag (or the field name) of the parameter
+func setStrategyParameter(strategy interface{}, name string, value json.RawMessage) error {
+	if setter, ok := strategy.(ParameterSetter); ok {
+		return setter.SetParameter(name, value)
+	}
+
+	rs := reflect.ValueOf(strategy)
+	if rs.Kind() != reflect.Ptr || rs.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("strategy %T is not a struct pointer", strategy)
+	}
+
+	rs = rs.Elem()
+	rt := rs.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+
+		tagName := strings.Split(field.Tag.Get("json"), ",")[0]
+		if tagName != name && field.Name != name {
+			continue
+		}
+
+		if tagName == "-" || !rs.Field(i).CanSet() {
+			return fmt.Errorf("parameter %s of %T can not be set", name, strategy)
+		}
+
+		newValue := reflect.New(field.Type)
+		if err := json.Unmarshal(value, newValue.Interface()); err != nil {
+			return errors.Wrapf(err, "invalid value of parameter %s", name)
+		}
+
+		rs.Field(i).Set(newValue.Elem())
+		return nil
+	}
+
+	return fmt.Errorf("parameter %s of %T not found", name, strategy)
+}
@@ -0,0 +1,216 @@
+package bbgo
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type commandTestStrategy struct {
+	Symbol   string  `json:"symbol"`
+	Quantity float64 `json:"quantity"`
+	Layers   int     `json:"gridNumber"`
+	Internal string  `json:"-"`
+
+	paused bool
+}
+
+func (s *commandTestStrategy) ID() string {
+	return "command-test"
+}
+
+func (s *commandTestStrategy) Run(ctx context.Context, orderExecutor OrderExecutor, session *ExchangeSession) error {
+	return nil
+}
+
+func (s *commandTestStrategy) Pause() error {
+	s.paused = true
+	return nil
+}
+
+func (s *commandTestStrategy) Resume() error {
+	s.paused = false
+	return nil
+}
+
+func Test_matchCommandStrategy(t *testing.T) {
+	s := &commandTestStrategy{Symbol: "BTCUSDT"}
+	assert.True(t, matchCommandStrategy(s, "command-test"))
+	assert.True(t, matchCommandStrategy(s, "command-test:BTCUSDT"))
+	assert.False(t, matchCommandStrategy(s, "command-test:ETHUSDT"))
+	assert.False(t, matchCommandStrategy(s, "grid"))
+}
+
+func Test_setStrategyParameter(t *testing.T) {
+	s := &commandTestStrategy{Symbol: "BTCUSDT"}
+
+	assert.NoError(t, setStrategyParameter(s, "quantity", json.RawMessage(`0.05`)))
+	assert.Equal(t, 0.05, s.Quantity)
+
+	// the field name also works
+	assert.NoError(t, setStrategyParameter(s, "Layers", json.RawMessage(`10`)))
+	assert.Equal(t, 10, s.Layers)
+
+	assert.Error(t, setStrategyParameter(s, "quantity", json.RawMessage(`"abc"`)))
+	assert.Equal(t, 0.05, s.Quantity)
+
+	assert.Error(t, setStrategyParameter(s, "Internal", json.RawMessage(`"x"`)))
+	assert.Error(t, setStrategyParameter(s, "paused", json.RawMessage(`true`)))
+	assert.Error(t, setStrategyParameter(s, "notFound", json.RawMessage(`1`)))
+}
+
+func TestTrader_RegisterCommandHandlers(t *testing.T) {
+	btc := &commandTestStrategy{Symbol: "BTCUSDT"}
+	eth := &commandTestStrategy{Symbol: "ETHUSDT"}
+
+	trader := &Trader{
+		exchangeStrategies: map[string][]SingleExchangeStrategy{
+			"binance": {btc, eth},
+		},
+	}
+
+	q := NewCommandQueue(nil)
+	trader.RegisterCommandHandlers(q)
+
+	ctx := context.Background()
+	err := q.execute(ctx, types.Command{Type: types.CommandTypePauseStrategy, Strategy: "command-test:BTCUSDT"})
+	assert.NoError(t, err)
+	assert.True(t, btc.paused)
+	assert.False(t, eth.paused)
+
+	err = q.execute(ctx, types.Command{Type: types.CommandTypePauseStrategy, Strategy: "command-test"})
+	assert.NoError(t, err)
+	assert.True(t, eth.paused)
+
+	err = q.execute(ctx, types.Command{Type: types.CommandTypeResumeStrategy, Strategy: "command-test:ETHUSDT"})
+	assert.NoError(t, err)
+	assert.True(t, btc.paused)
+	assert.False(t, eth.paused)
+
+	err = q.execute(ctx, types.Command{
+		Type:     types.CommandTypeSetParameter,
+		Strategy: "command-test:ETHUSDT",
+		Payload:  json.RawMessage(`{"parameter":"quantity","value":1.5}`),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, eth.Quantity)
+	assert.Equal(t, 0.0, btc.Quantity)
+
+	err = q.execute(ctx, types.Command{Type: types.CommandTypePauseStrategy, Strategy: "grid"})
+	assert.Error(t, err)
+
+	err = q.execute(ctx, types.Command{Type: "unknown"})
+	assert.Error(t, err)
+}
+
+func TestTrader_RiskCommandHandlers(t *testing.T) {
+	binance := newKillSwitchTestSession(&killSwitchTestExchange{})
+
+	exchange := &guardTestExchange{}
+	maxSession := newKillSwitchTestSession(exchange)
+	maxSession.Name = "max"
+
+	trader := &Trader{
+		environment: &Environment{
+			sessions: map[string]*ExchangeSession{"binance": binance, "max": maxSession},
+		},
+	}
+
+	q := NewCommandQueue(nil)
+	trader.RegisterCommandHandlers(q)
+
+	ctx := context.Background()
+	err := q.execute(ctx, types.Command{Type: types.CommandTypeHalt, Source: "telegram"})
+	assert.Error(t, err, "the kill switch is not configured")
+
+	trader.killSwitch = NewKillSwitch(KillSwitchConfig{MaxDailyLoss: fixedpoint.NewFromFloat(100.0)}, nil, nil)
+	trader.killSwitch.AddSession(binance)
+
+	err = q.execute(ctx, types.Command{Type: types.CommandTypeHalt, Source: "telegram"})
+	assert.NoError(t, err)
+	assert.True(t, trader.killSwitch.Tripped())
+	assert.Equal(t, "halted by the telegram command", trader.killSwitch.State().Reason)
+
+	// close the half of the 0.5 BTC position
+	err = q.execute(ctx, types.Command{
+		Type:    types.CommandTypeClosePosition,
+		Payload: json.RawMessage(`{"session":"max","symbol":"BTCUSDT","percentage":0.5}`),
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, exchange.orders, 1) {
+		assert.Equal(t, types.SideTypeSell, exchange.orders[0].Side)
+		assert.Equal(t, types.OrderTypeMarket, exchange.orders[0].Type)
+		assert.Equal(t, 0.25, exchange.orders[0].Quantity)
+	}
+
+	err = q.execute(ctx, types.Command{
+		Type:    types.CommandTypeClosePosition,
+		Payload: json.RawMessage(`{"session":"max","symbol":"BTCUSDT","percentage":1.5}`),
+	})
+	assert.Error(t, err)
+
+	err = q.execute(ctx, types.Command{
+		Type:    types.CommandTypeClosePosition,
+		Payload: json.RawMessage(`{"session":"okex","symbol":"BTCUSDT"}`),
+	})
+	assert.Error(t, err)
+}
+
+func TestTrader_CancelAllOrdersCommandHandler(t *testing.T) {
+	newOrder := func(orderID uint64, symbol string) types.Order {
+		return types.Order{SubmitOrder: types.SubmitOrder{Symbol: symbol}, OrderID: orderID}
+	}
+
+	binanceExchange := &killSwitchTestExchange{openOrders: []types.Order{newOrder(1, "BTCUSDT"), newOrder(2, "ETHUSDT")}}
+	maxExchange := &killSwitchTestExchange{openOrders: []types.Order{newOrder(3, "BTCUSDT")}}
+
+	binance := newKillSwitchTestSession(binanceExchange)
+	maxSession := newKillSwitchTestSession(maxExchange)
+	maxSession.Name = "max"
+
+	trader := &Trader{
+		environment: &Environment{
+			sessions: map[string]*ExchangeSession{"binance": binance, "max": maxSession},
+		},
+	}
+
+	q := NewCommandQueue(nil)
+	trader.RegisterCommandHandlers(q)
+
+	ctx := context.Background()
+	err := q.execute(ctx, types.Command{
+		Type:    types.CommandTypeCancelAllOrders,
+		Payload: json.RawMessage(`{"session":"max","symbol":"BTCUSDT"}`),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, binanceExchange.canceledOrders, 0)
+	assert.Len(t, maxExchange.canceledOrders, 1)
+
+	// the orders are canceled on all the sessions if the session is not specified
+	err = q.execute(ctx, types.Command{
+		Type:    types.CommandTypeCancelAllOrders,
+		Payload: json.RawMessage(`{"symbol":"BTCUSDT"}`),
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, binanceExchange.canceledOrders, 1) {
+		assert.Equal(t, uint64(1), binanceExchange.canceledOrders[0].OrderID)
+	}
+	assert.Len(t, maxExchange.canceledOrders, 2)
+
+	err = q.execute(ctx, types.Command{
+		Type:    types.CommandTypeCancelAllOrders,
+		Payload: json.RawMessage(`{"session":"okex","symbol":"BTCUSDT"}`),
+	})
+	assert.Error(t, err)
+
+	err = q.execute(ctx, types.Command{
+		Type:    types.CommandTypeCancelAllOrders,
+		Payload: json.RawMessage(`{}`),
+	})
+	assert.Error(t, err)
+}
@@ -4,7 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"path/filepath"
 	"reflect"
 	"runtime"
 	"time"
@@ -14,6 +14,8 @@ import (
 
 	"github.com/c9s/bbgo/pkg/datatype"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/notifier/discordnotifier"
+	"github.com/c9s/bbgo/pkg/notifier/webhooknotifier"
 	"github.com/c9s/bbgo/pkg/service"
 	"github.com/c9s/bbgo/pkg/types"
 )
@@ -56,6 +58,13 @@ func (m *ExchangeStrategyMount) Map() (map[string]interface{}, error) {
 type SlackNotification struct {
 	DefaultChannel string `json:"defaultChannel,omitempty"  yaml:"defaultChannel,omitempty"`
 	ErrorChannel   string `json:"errorChannel,omitempty"  yaml:"errorChannel,omitempty"`
+
+	// SigningSecret is the signing secret of the slack app, the interactive buttons (e.g., cancel all orders) are enabled with it.
+	// the interactivity request URL of the slack app is http://<bbgo server>/api/slack/interactions
+	SigningSecret string `json:"signingSecret,omitempty" yaml:"signingSecret,omitempty"`
+
+	// AllowedUsers is the slack user IDs that are allowed to click the buttons, all the workspace users are allowed if it's empty
+	AllowedUsers []string `json:"allowedUsers,omitempty" yaml:"allowedUsers,omitempty"`
 }
 
 type SlackNotificationRouting struct {
@@ -63,21 +72,119 @@ type SlackNotificationRouting struct {
 	Order       string `json:"order,omitempty" yaml:"order,omitempty"`
 	SubmitOrder string `json:"submitOrder,omitempty" yaml:"submitOrder,omitempty"`
 	PnL         string `json:"pnL,omitempty" yaml:"pnL,omitempty"`
+
+	// Position notifies the position (average cost, realized and unrealized profit) when the trade updates the position
+	Position string `json:"position,omitempty" yaml:"position,omitempty"`
 }
 
 type TelegramNotification struct {
 	Broadcast bool `json:"broadcast" yaml:"broadcast"`
+
+	// BotToken is the bot token from the bot father, the --telegram-bot-token option takes precedence
+	BotToken string `json:"botToken,omitempty" yaml:"botToken,omitempty"`
+
+	// AuthToken is the fixed token for the /auth command, the one-time password (OTP) is used if it's empty.
+	// the --telegram-bot-auth-token option takes precedence
+	AuthToken string `json:"authToken,omitempty" yaml:"authToken,omitempty"`
+
+	// ErrorLogs sends the error logs to the telegram chats
+	ErrorLogs bool `json:"errorLogs,omitempty" yaml:"errorLogs,omitempty"`
+}
+
+// DiscordNotification sends the notifications through the discord channel webhooks,
+// the routing rules (symbolChannels, sessionChannels and routing) are shared with slack,
+// the routed channel names are mapped to the webhook URLs by Channels.
+type DiscordNotification struct {
+	// WebhookURL is the webhook URL of the default channel, the --discord-webhook-url option takes precedence
+	WebhookURL string `json:"webhookURL,omitempty" yaml:"webhookURL,omitempty"`
+
+	// ErrorWebhookURL is the webhook URL of the channel that the error logs are sent to
+	ErrorWebhookURL string `json:"errorWebhookURL,omitempty" yaml:"errorWebhookURL,omitempty"`
+
+	// Channels maps the routed channel names to the webhook URLs, the unmapped channels fall back to the default webhook URL
+	Channels map[string]string `json:"channels,omitempty" yaml:"channels,omitempty"`
+
+	// Username overrides the default username of the webhooks
+	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+}
+
+func (c *DiscordNotification) Validate() error {
+	var urls = []string{c.WebhookURL, c.ErrorWebhookURL}
+	for _, webhookURL := range c.Channels {
+		urls = append(urls, webhookURL)
+	}
+
+	for _, webhookURL := range urls {
+		if len(webhookURL) == 0 {
+			continue
+		}
+
+		if err := discordnotifier.ValidateWebhookURL(webhookURL); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WebhookNotification posts the trade, order, position and error notifications to the webhook URL,
+// the body is the json payload (see webhooknotifier.Payload), or rendered by the go template,
+// e.g., '{"text": {{ json .Message }}}'
+type WebhookNotification struct {
+	// Name is the notifier name referred by the notification rules, defaults to "webhook"
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	URL string `json:"url" yaml:"url"`
+
+	// Events filters the events (trade, order, position, error and message), all the events are sent if it's empty
+	Events []webhooknotifier.Event `json:"events,omitempty" yaml:"events,omitempty"`
+
+	// Headers are the extra headers of the requests, e.g., the authorization header
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+
+	// Template is the body template of all the events
+	Template string `json:"template,omitempty" yaml:"template,omitempty"`
+
+	// Templates overrides the body template by the event
+	Templates map[webhooknotifier.Event]string `json:"templates,omitempty" yaml:"templates,omitempty"`
+
+	Timeout types.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+func (c *WebhookNotification) Validate() error {
+	if len(c.URL) == 0 {
+		return errors.New("webhook notification url is required")
+	}
+
+	for _, event := range c.Events {
+		if err := webhooknotifier.ValidateEvent(event); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 type NotificationConfig struct {
 	Slack *SlackNotification `json:"slack,omitempty" yaml:"slack,omitempty"`
 
+	Discord *DiscordNotification `json:"discord,omitempty" yaml:"discord,omitempty"`
+
+	Webhooks []WebhookNotification `json:"webhooks,omitempty" yaml:"webhooks,omitempty"`
+
+	// Email sends the daily or weekly summary reports by email
+	Email *EmailReportConfig `json:"email,omitempty" yaml:"email,omitempty"`
+
 	Telegram *TelegramNotification `json:"telegram,omitempty" yaml:"telegram,omitempty"`
 
 	SymbolChannels  map[string]string `json:"symbolChannels,omitempty" yaml:"symbolChannels,omitempty"`
 	SessionChannels map[string]string `json:"sessionChannels,omitempty" yaml:"sessionChannels,omitempty"`
 
 	Routing *SlackNotificationRouting `json:"routing,omitempty" yaml:"routing,omitempty"`
+
+	// Rules route the notifications to the notifiers by the event, session, symbol and severity,
+	// the first matched rule decides the notifiers, the notifications that match no rule are sent to all the notifiers
+	Rules []NotificationRule `json:"rules,omitempty" yaml:"rules,omitempty"`
 }
 
 type Session struct {
@@ -110,6 +217,9 @@ type Backtest struct {
 	Account      BacktestAccount `json:"account" yaml:"account"`
 	Symbols      []string        `json:"symbols" yaml:"symbols"`
 	Session      string          `json:"session" yaml:"session"`
+
+	// CompactIntervals syncs only the 1m klines from the exchange and builds the klines of these intervals locally
+	CompactIntervals []types.Interval `json:"compactIntervals,omitempty" yaml:"compactIntervals,omitempty"`
 }
 
 func parseTimeWithFormats(strTime string, formats []string) (time.Time, error) {
@@ -122,6 +232,11 @@ func parseTimeWithFormats(strTime string, formats []string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("failed to parse time %s, valid formats are %+v", strTime, formats)
 }
 
+// ParseTime parses the time string in one of the supported time formats, e.g., 2021-01-02
+func ParseTime(strTime string) (time.Time, error) {
+	return parseTimeWithFormats(strTime, supportedTimeFormats)
+}
+
 func (t Backtest) ParseEndTime() (time.Time, error) {
 	if len(t.EndTime) == 0 {
 		return time.Time{}, errors.New("backtest.endTime must be defined")
@@ -143,6 +258,10 @@ type BacktestAccount struct {
 	MakerFeeRate fixedpoint.Value `json:"makerFeeRate"`
 	TakerFeeRate fixedpoint.Value `json:"takerFeeRate"`
 
+	// UseFeeTierCache uses the fee tier cached by the live trading on this machine when the fee rates are not set,
+	// it's disabled by default so that the same config gives the same result on the different machines
+	UseFeeTierCache bool `json:"useFeeTierCache,omitempty" yaml:"useFeeTierCache,omitempty"`
+
 	MakerCommission  fixedpoint.Value          `json:"makerCommission"`
 	TakerCommission  fixedpoint.Value          `json:"takerCommission"`
 	BuyerCommission  int                       `json:"buyerCommission"`
@@ -167,6 +286,7 @@ func (m BacktestAccountBalanceMap) BalanceMap() types.BalanceMap {
 type PersistenceConfig struct {
 	Redis *service.RedisPersistenceConfig `json:"redis,omitempty" yaml:"redis,omitempty"`
 	Json  *service.JsonPersistenceConfig  `json:"json,omitempty" yaml:"json,omitempty"`
+	SQL   *service.SQLPersistenceConfig   `json:"sql,omitempty" yaml:"sql,omitempty"`
 }
 
 type BuildTargetConfig struct {
@@ -209,6 +329,36 @@ type Config struct {
 
 	RiskControls *RiskControls `json:"riskControls,omitempty" yaml:"riskControls,omitempty"`
 
+	FeatureFlags map[string]FeatureFlag `json:"featureFlags,omitempty" yaml:"featureFlags,omitempty"`
+
+	OrderWebhooks []OrderWebhookConfig `json:"orderWebhooks,omitempty" yaml:"orderWebhooks,omitempty"`
+
+	IndexPrices []IndexPriceConfig `json:"indexPrices,omitempty" yaml:"indexPrices,omitempty"`
+
+	EventPublishers []EventPublisherConfig `json:"eventPublishers,omitempty" yaml:"eventPublishers,omitempty"`
+
+	Recovery *RecoveryConfig `json:"recovery,omitempty" yaml:"recovery,omitempty"`
+
+	KLineCompaction *KLineCompactionConfig `json:"klineCompaction,omitempty" yaml:"klineCompaction,omitempty"`
+
+	// CurrencyConversion configures the conversion of the assets into the reference currency
+	CurrencyConversion *CurrencyConversionConfig `json:"currencyConversion,omitempty" yaml:"currencyConversion,omitempty"`
+
+	BalanceSnapshots *BalanceSnapshotConfig `json:"balanceSnapshots,omitempty" yaml:"balanceSnapshots,omitempty"`
+
+	Watchdog *WatchdogConfig `json:"watchdog,omitempty" yaml:"watchdog,omitempty"`
+
+	// HealthCheck configures the /healthz and /readyz endpoints
+	HealthCheck *HealthCheckConfig `json:"healthCheck,omitempty" yaml:"healthCheck,omitempty"`
+
+	// Shutdown is the shutdown policies of the strategies and the shutdown timeout
+	Shutdown *ShutdownConfig `json:"shutdown,omitempty" yaml:"shutdown,omitempty"`
+
+	ProfitReport *ProfitReportConfig `json:"profitReport,omitempty" yaml:"profitReport,omitempty"`
+
+	// StrategyPlugins are loaded before the strategies, so the strategies registered by the plugins can be used in the config
+	StrategyPlugins []StrategyPluginConfig `json:"strategyPlugins,omitempty" yaml:"strategyPlugins,omitempty"`
+
 	ExchangeStrategies      []ExchangeStrategyMount `json:"-" yaml:"-"`
 	CrossExchangeStrategies []CrossExchangeStrategy `json:"-" yaml:"-"`
 
@@ -296,7 +446,7 @@ func loadStash(config []byte) (Stash, error) {
 func LoadBuildConfig(configFile string) (*Config, error) {
 	var config Config
 
-	content, err := ioutil.ReadFile(configFile)
+	content, err := readConfigFiles(configFile)
 	if err != nil {
 		return nil, err
 	}
@@ -324,9 +474,19 @@ func LoadBuildConfig(configFile string) (*Config, error) {
 
 // Load parses the config
 func Load(configFile string, loadStrategies bool) (*Config, error) {
+	return load(configFile, loadStrategies, loadStrategies)
+}
+
+// LoadStrategies loads the config with the strategies, the strategy plugins are not loaded again,
+// it's used for reloading the strategy parameters of the running process.
+func LoadStrategies(configFile string) (*Config, error) {
+	return load(configFile, false, true)
+}
+
+func load(configFile string, loadPlugins, loadStrategies bool) (*Config, error) {
 	var config Config
 
-	content, err := ioutil.ReadFile(configFile)
+	content, err := readConfigFiles(configFile)
 	if err != nil {
 		return nil, err
 	}
@@ -352,6 +512,12 @@ func Load(configFile string, loadStrategies bool) (*Config, error) {
 		return nil, err
 	}
 
+	if loadPlugins {
+		if err := LoadStrategyPlugins(config.StrategyPlugins, filepath.Dir(configFile)); err != nil {
+			return nil, err
+		}
+	}
+
 	if loadStrategies {
 		if err := loadExchangeStrategies(&config, stash); err != nil {
 			return nil, err
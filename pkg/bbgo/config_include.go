@@ -0,0 +1,203 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/c9s/bbgo/pkg/secrets"
+)
+
+// configSource is a config file of the include tree
+type configSource struct {
+	File    string
+	Content []byte
+}
+
+// isConfigInclude returns true if the import is a config file, e.g., sessions.yaml or strategies/*.yaml.
+// The other imports are the go packages of the deprecated build imports.
+func isConfigInclude(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return true
+	}
+
+	return false
+}
+
+// resolveConfigSources reads the config file and the config files it includes, the included files come before the file
+// includes them, so that the values of the including file take precedence when they are merged.
+// The paths of the included files are relative to the including file and the glob patterns are supported.
+func resolveConfigSources(configFile string) ([]configSource, error) {
+	var sources []configSource
+	if err := resolveConfigSource(configFile, nil, make(map[string]bool), &sources); err != nil {
+		return nil, err
+	}
+
+	return sources, nil
+}
+
+func resolveConfigSource(configFile string, stack []string, visited map[string]bool, sources *[]configSource) error {
+	absPath, err := filepath.Abs(configFile)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range stack {
+		if f == absPath {
+			return fmt.Errorf("config %s is included recursively: %s", configFile, strings.Join(append(stack, absPath), " -> "))
+		}
+	}
+
+	// the file included twice is merged once
+	if visited[absPath] {
+		return nil
+	}
+	visited[absPath] = true
+
+	content, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return err
+	}
+
+	// the syntax error is reported by the config loader, the file without the includes is loaded as it is
+	var header struct {
+		Imports []string `yaml:"imports"`
+	}
+	_ = yaml.Unmarshal(content, &header)
+
+	stack = append(stack, absPath)
+	for _, include := range header.Imports {
+		if !isConfigInclude(include) {
+			continue
+		}
+
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(configFile), include)
+		}
+
+		matches, err := filepath.Glob(include)
+		if err != nil {
+			return errors.Wrapf(err, "config %s: invalid import pattern %s", configFile, include)
+		}
+
+		// a missing file is an error, but a pattern can match nothing, e.g., an empty strategies directory
+		if len(matches) == 0 && !strings.ContainsAny(include, "*?[") {
+			return fmt.Errorf("config %s: imported file %s does not exist", configFile, include)
+		}
+
+		for _, match := range matches {
+			if err := resolveConfigSource(match, stack, visited, sources); err != nil {
+				return err
+			}
+		}
+	}
+
+	*sources = append(*sources, configSource{File: configFile, Content: content})
+	return nil
+}
+
+// readConfigFiles reads the config file with its included files, the environment variables and the secrets are
+// interpolated in each file. The files are merged into one config, the maps are merged recursively and the lists are
+// concatenated, so the sessions and the strategies can be defined in the separate files. The config includes are removed
+// from the merged imports, the go package imports are kept for the build.
+func readConfigFiles(configFile string) ([]byte, error) {
+	sources, err := resolveConfigSources(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sources) == 1 {
+		return interpolateConfig(sources[0])
+	}
+
+	merged := make(Stash)
+	for _, source := range sources {
+		content, err := interpolateConfig(source)
+		if err != nil {
+			return nil, err
+		}
+
+		stash, err := loadStash(content)
+		if err != nil {
+			return nil, errors.Wrapf(err, "config %s", source.File)
+		}
+
+		stash["imports"] = removeConfigIncludes(stash["imports"])
+		if stash["imports"] == nil {
+			delete(stash, "imports")
+		}
+
+		merged = mergeConfigValues(merged, stash).(Stash)
+	}
+
+	return yaml.Marshal(merged)
+}
+
+func interpolateConfig(source configSource) ([]byte, error) {
+	content, err := secrets.Interpolate(context.Background(), source.Content)
+	if err != nil {
+		return nil, errors.Wrapf(err, "config %s interpolation error", source.File)
+	}
+
+	return content, nil
+}
+
+func removeConfigIncludes(imports interface{}) interface{} {
+	list, ok := imports.([]interface{})
+	if !ok {
+		return imports
+	}
+
+	var packages []interface{}
+	for _, item := range list {
+		if path, ok := item.(string); ok && isConfigInclude(path) {
+			continue
+		}
+
+		packages = append(packages, item)
+	}
+
+	if len(packages) == 0 {
+		return nil
+	}
+
+	return packages
+}
+
+// mergeConfigValues merges the src value into the dst value, the maps are merged recursively, the lists are
+// concatenated and the other values are replaced by the src value.
+func mergeConfigValues(dst, src interface{}) interface{} {
+	switch s := src.(type) {
+	case Stash:
+		d, ok := dst.(Stash)
+		if !ok {
+			return s
+		}
+
+		for key, value := range s {
+			if existing, ok := d[key]; ok {
+				d[key] = mergeConfigValues(existing, value)
+			} else {
+				d[key] = value
+			}
+		}
+
+		return d
+
+	case []interface{}:
+		d, ok := dst.([]interface{})
+		if !ok {
+			return s
+		}
+
+		return append(d, s...)
+	}
+
+	return src
+}
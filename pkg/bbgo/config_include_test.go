@@ -0,0 +1,131 @@
+package bbgo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfigFiles(t *testing.T, files map[string]string) (dir string) {
+	dir, err := ioutil.TempDir("", "bbgo-config-*")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+
+	return dir
+}
+
+func TestLoadConfig_Imports(t *testing.T) {
+	config, err := Load("testdata/include/bbgo.yaml", true)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Len(t, config.Sessions, 2)
+	assert.Contains(t, config.Sessions, "binance")
+	assert.Contains(t, config.Sessions, "max")
+
+	// the values of the including file take precedence
+	if assert.NotNil(t, config.Notifications) && assert.NotNil(t, config.Notifications.Slack) {
+		assert.Equal(t, "#bbgo", config.Notifications.Slack.DefaultChannel)
+		assert.Equal(t, "#error", config.Notifications.Slack.ErrorChannel)
+	}
+
+	// the strategies of the included files are loaded first
+	var symbols []string
+	for _, mount := range config.ExchangeStrategies {
+		symbols = append(symbols, mount.Strategy.(*TestStrategy).Symbol)
+	}
+	assert.Equal(t, []string{"BTCUSDT", "ETHUSDT", "BTCTWD"}, symbols)
+	assert.Equal(t, []string{"max"}, config.ExchangeStrategies[2].Mounts)
+
+	// the config includes are not the build imports
+	if assert.NotNil(t, config.Build) {
+		assert.Equal(t, []string{"github.com/c9s/bbgo/pkg/strategy/grid"}, config.Build.Imports)
+	}
+}
+
+func TestResolveConfigSources(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"bbgo.yaml":       "imports: [a.yaml, b.yaml, empty/*.yaml]\n",
+		"a.yaml":          "imports: [common.yaml]\n",
+		"b.yaml":          "imports: [common.yaml]\n",
+		"common.yaml":     "sessions: {}\n",
+		"recursive.yaml":  "imports: [recursive2.yaml]\n",
+		"recursive2.yaml": "imports: [recursive.yaml]\n",
+		"missing.yaml":    "imports: [not-found.yaml]\n",
+	})
+	defer os.RemoveAll(dir)
+
+	sources, err := resolveConfigSources(filepath.Join(dir, "bbgo.yaml"))
+	if assert.NoError(t, err) {
+		var files []string
+		for _, source := range sources {
+			files = append(files, filepath.Base(source.File))
+		}
+
+		// the file imported twice is merged once
+		assert.Equal(t, []string{"common.yaml", "a.yaml", "b.yaml", "bbgo.yaml"}, files)
+	}
+
+	_, err = resolveConfigSources(filepath.Join(dir, "recursive.yaml"))
+	assert.Error(t, err)
+
+	_, err = resolveConfigSources(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestMergeConfigValues(t *testing.T) {
+	merged := mergeConfigValues(Stash{
+		"sessions": Stash{"binance": Stash{"exchange": "binance"}},
+		"imports":  []interface{}{"a"},
+		"debug":    false,
+	}, Stash{
+		"sessions": Stash{"max": Stash{"exchange": "max"}},
+		"imports":  []interface{}{"b"},
+		"debug":    true,
+	})
+
+	assert.Equal(t, Stash{
+		"sessions": Stash{
+			"binance": Stash{"exchange": "binance"},
+			"max":     Stash{"exchange": "max"},
+		},
+		"imports": []interface{}{"a", "b"},
+		"debug":   true,
+	}, merged)
+}
+
+func TestValidateConfigFile_Imports(t *testing.T) {
+	issues, err := ValidateConfigFile("testdata/include/bbgo.yaml")
+	if assert.NoError(t, err) {
+		assert.Empty(t, issues)
+	}
+
+	dir := writeConfigFiles(t, map[string]string{
+		"bbgo.yaml":     "imports: [sessions.yaml, strategies.yaml]\n",
+		"sessions.yaml": "sessions:\n  binance:\n    exchange: binance\n",
+		"strategies.yaml": `exchangeStrategies:
+- on: [binance, ftx]
+  test:
+    symbol: BTCUSDT
+`,
+	})
+	defer os.RemoveAll(dir)
+
+	issues, err = ValidateConfigFile(filepath.Join(dir, "bbgo.yaml"))
+	if assert.NoError(t, err) && assert.Len(t, issues, 1) {
+		assert.Equal(t, filepath.Join(dir, "strategies.yaml"), issues[0].File)
+		assert.Equal(t, 2, issues[0].Line)
+		assert.Equal(t, "session ftx is not defined in sessions", issues[0].Message)
+	}
+}
@@ -3,7 +3,9 @@ package bbgo
 import (
 	"context"
 	"io/ioutil"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v3"
@@ -148,6 +150,22 @@ func TestLoadConfig(t *testing.T) {
 			},
 		},
 
+		{
+			name:    "feature flags",
+			args:    args{configFile: "testdata/featureflags.yaml"},
+			wantErr: false,
+			f: func(t *testing.T, config *Config) {
+				assert.Equal(t, map[string]FeatureFlag{
+					"new-sizing": {
+						Enabled:    true,
+						Percentage: 10,
+						Strategies: []string{"bollmaker"},
+					},
+					"fast-cancel": {Enabled: false},
+				}, config.FeatureFlags)
+			},
+		},
+
 		{
 			name:    "order_executor",
 			args:    args{configFile: "testdata/order_executor.yaml"},
@@ -211,3 +229,41 @@ func TestLoadConfig(t *testing.T) {
 	}
 
 }
+
+func TestLoadConfig_Interpolation(t *testing.T) {
+	os.Setenv("BBGO_TEST_BINANCE_API_KEY", "key")
+	os.Setenv("BBGO_TEST_BINANCE_API_SECRET", "secret")
+	defer os.Unsetenv("BBGO_TEST_BINANCE_API_KEY")
+	defer os.Unsetenv("BBGO_TEST_BINANCE_API_SECRET")
+
+	config, err := Load("testdata/interpolation.yaml", true)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if assert.Contains(t, config.Sessions, "binance") {
+		assert.Equal(t, "key", config.Sessions["binance"].Key)
+		assert.Equal(t, "secret", config.Sessions["binance"].Secret)
+	}
+
+	if assert.Len(t, config.ExchangeStrategies, 1) {
+		assert.Equal(t, "BTCUSDT", config.ExchangeStrategies[0].Strategy.(*TestStrategy).Symbol)
+	}
+
+	os.Unsetenv("BBGO_TEST_BINANCE_API_SECRET")
+	_, err = Load("testdata/interpolation.yaml", true)
+	assert.Error(t, err)
+}
+
+func TestParseTime(t *testing.T) {
+	tt, err := ParseTime("2021-05-01")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC), tt)
+
+	tt, err = ParseTime("2021-05-01T10:30:00Z")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 5, 1, 10, 30, 0, 0, time.UTC), tt)
+
+	_, err = ParseTime("05/01/2021")
+	assert.Error(t, err)
+}
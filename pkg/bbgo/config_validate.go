@@ -0,0 +1,761 @@
+package bbgo
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type ConfigIssueSeverity string
+
+const (
+	ConfigIssueError   ConfigIssueSeverity = "error"
+	ConfigIssueWarning ConfigIssueSeverity = "warning"
+)
+
+// ConfigIssue is a problem of the config found by ValidateConfig, the line and the column are 1-based
+type ConfigIssue struct {
+	Severity ConfigIssueSeverity `json:"severity"`
+
+	// File is the config file of the issue, it's empty if the config is not read from a file
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+
+	// Path is the path of the config value, e.g., exchangeStrategies[0].grid.gridNumber
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (i ConfigIssue) String() string {
+	if len(i.File) > 0 {
+		return fmt.Sprintf("%s:%d:%d: %s: %s: %s", i.File, i.Line, i.Column, i.Severity, i.Path, i.Message)
+	}
+
+	return fmt.Sprintf("%d:%d: %s: %s: %s", i.Line, i.Column, i.Severity, i.Path, i.Message)
+}
+
+// ConfigIssues are sorted by the file, the line and the column
+type ConfigIssues []ConfigIssue
+
+// HasErrors returns true if any issue is an error, the warnings do not stop the config from being loaded
+func (issues ConfigIssues) HasErrors() bool {
+	for _, issue := range issues {
+		if issue.Severity == ConfigIssueError {
+			return true
+		}
+	}
+
+	return false
+}
+
+var symbolPattern = regexp.MustCompile(`^[A-Z0-9]+([-/_][A-Z0-9]+)*$`)
+
+// typeErrorLinePattern parses the line number of the yaml type errors, e.g., "line 3: cannot unmarshal !!str ..."
+var typeErrorLinePattern = regexp.MustCompile(`^line (\d+): (.*)$`)
+
+// configDocument is the parsed config file, the included files are validated with their own line numbers
+type configDocument struct {
+	File string
+	Node *yaml.Node
+}
+
+type configValidator struct {
+	issues ConfigIssues
+
+	// file is the config file being validated
+	file string
+
+	// sessions are the key nodes of the defined sessions
+	sessions map[string]*yaml.Node
+
+	// referencedSessions are the sessions referenced by the strategies
+	referencedSessions map[string]bool
+
+	hasStrategies bool
+}
+
+// ValidateConfig validates the yaml config before anything connects to the exchanges. It checks the unknown keys,
+// the strategies and their parameters (by the Validate method of the strategies), the symbols, the intervals and
+// the sessions referenced by the strategies. The error is returned only if the yaml can not be parsed.
+//
+// The strategies are looked up from the registered strategies, so the strategy packages and the strategy plugins
+// should be loaded before the validation.
+func ValidateConfig(content []byte) (ConfigIssues, error) {
+	return validateConfigSources([]configSource{{Content: content}})
+}
+
+// ValidateConfigFile validates the config file and the config files it imports, the environment variables and
+// the secrets are interpolated and the strategy plugins of the config are loaded before the validation.
+func ValidateConfigFile(configFile string) (ConfigIssues, error) {
+	sources, err := resolveConfigSources(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, source := range sources {
+		content, err := interpolateConfig(source)
+		if err != nil {
+			return nil, err
+		}
+		sources[i].Content = content
+
+		// the syntax errors are reported by the validator
+		var config Config
+		if err := yaml.Unmarshal(content, &config); err == nil {
+			if err := LoadStrategyPlugins(config.StrategyPlugins, filepath.Dir(source.File)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return validateConfigSources(sources)
+}
+
+func validateConfigSources(sources []configSource) (ConfigIssues, error) {
+	v := &configValidator{
+		sessions:           make(map[string]*yaml.Node),
+		referencedSessions: make(map[string]bool),
+	}
+
+	var docs []configDocument
+	for _, source := range sources {
+		var root yaml.Node
+		if err := yaml.Unmarshal(source.Content, &root); err != nil {
+			if len(source.File) > 0 {
+				return nil, errors.Wrapf(err, "config %s", source.File)
+			}
+			return nil, err
+		}
+
+		if len(root.Content) == 0 {
+			continue
+		}
+
+		v.file = source.File
+		doc := root.Content[0]
+		if doc.Kind != yaml.MappingNode {
+			v.addError(doc, "", "the config should be a map")
+			continue
+		}
+
+		// the sessions of all the files are collected before the strategies are validated
+		if sessions := mappingValue(doc, "sessions"); sessions != nil && sessions.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(sessions.Content); i += 2 {
+				v.sessions[sessions.Content[i].Value] = sessions.Content[i]
+			}
+		}
+
+		docs = append(docs, configDocument{File: source.File, Node: doc})
+	}
+
+	for _, doc := range docs {
+		v.file = doc.File
+		v.validateDocument(doc.Node)
+	}
+
+	for _, doc := range docs {
+		v.file = doc.File
+		v.validateSessions(doc.Node)
+	}
+
+	fileOrder := make(map[string]int)
+	for i, source := range sources {
+		fileOrder[source.File] = i
+	}
+
+	sort.SliceStable(v.issues, func(i, j int) bool {
+		a, b := v.issues[i], v.issues[j]
+		if a.File != b.File {
+			return fileOrder[a.File] < fileOrder[b.File]
+		}
+		if a.Line == b.Line {
+			return a.Column < b.Column
+		}
+		return a.Line < b.Line
+	})
+
+	return v.issues, nil
+}
+
+func (v *configValidator) validateDocument(doc *yaml.Node) {
+	var config Config
+	if err := doc.Decode(&config); err != nil {
+		v.addDecodeError(doc, "", err)
+	}
+
+	configValue := reflect.ValueOf(&config).Elem()
+	fields := structFields(configValue.Type(), "yaml")
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		key, value := doc.Content[i], doc.Content[i+1]
+
+		switch key.Value {
+		case "exchangeStrategies", "strategies":
+			v.hasStrategies = true
+			v.validateExchangeStrategies(value, key.Value)
+			continue
+
+		case "crossExchangeStrategies":
+			v.hasStrategies = true
+			v.validateCrossExchangeStrategies(value, key.Value)
+			continue
+		}
+
+		field, ok := fields[key.Value]
+		if !ok {
+			v.addUnknownKey(key, "", "yaml", fields)
+			continue
+		}
+
+		v.walk(value, fieldByIndex(configValue, field.Index), key.Value, key.Value, "yaml")
+	}
+}
+
+func (v *configValidator) add(severity ConfigIssueSeverity, node *yaml.Node, path, message string) {
+	v.issues = append(v.issues, ConfigIssue{
+		Severity: severity,
+		File:     v.file,
+		Line:     node.Line,
+		Column:   node.Column,
+		Path:     path,
+		Message:  message,
+	})
+}
+
+func (v *configValidator) addError(node *yaml.Node, path, message string) {
+	v.add(ConfigIssueError, node, path, message)
+}
+
+func (v *configValidator) addWarning(node *yaml.Node, path, message string) {
+	v.add(ConfigIssueWarning, node, path, message)
+}
+
+// addDecodeError adds the yaml type errors with their own line numbers, the other errors are added to the node
+func (v *configValidator) addDecodeError(node *yaml.Node, path string, err error) {
+	typeError, ok := err.(*yaml.TypeError)
+	if !ok {
+		v.addError(node, path, err.Error())
+		return
+	}
+
+	for _, message := range typeError.Errors {
+		matches := typeErrorLinePattern.FindStringSubmatch(message)
+		if matches == nil {
+			v.addError(node, path, message)
+			continue
+		}
+
+		line, _ := strconv.Atoi(matches[1])
+		v.issues = append(v.issues, ConfigIssue{
+			Severity: ConfigIssueError,
+			File:     v.file,
+			Line:     line,
+			Column:   1,
+			Path:     path,
+			Message:  matches[2],
+		})
+	}
+}
+
+func (v *configValidator) addUnknownKey(key *yaml.Node, path, tag string, fields map[string]reflect.StructField) {
+	var names []string
+	for name, field := range fields {
+		// the json keys are in lower case, suggest the key of the tag
+		if tagName := strings.Split(field.Tag.Get(tag), ",")[0]; len(tagName) > 0 {
+			name = tagName
+		}
+		names = append(names, name)
+	}
+
+	message := fmt.Sprintf("unknown key %q", key.Value)
+	if suggestion := closestName(key.Value, names); len(suggestion) > 0 {
+		message += fmt.Sprintf(", did you mean %q?", suggestion)
+	}
+
+	v.addError(key, joinPath(path, key.Value), message)
+}
+
+func (v *configValidator) validateSessions(doc *yaml.Node) {
+	sessions := mappingValue(doc, "sessions")
+	if sessions == nil || sessions.Kind != yaml.MappingNode {
+		return
+	}
+
+	for i := 0; i+1 < len(sessions.Content); i += 2 {
+		key, value := sessions.Content[i], sessions.Content[i+1]
+		path := "sessions." + key.Value
+
+		if exchange := mappingValue(value, "exchange"); exchange == nil {
+			v.addError(key, path, "exchange is required")
+		} else if _, err := types.ValidExchangeName(exchange.Value); err != nil {
+			v.addError(exchange, path+".exchange", err.Error())
+		}
+
+		if v.hasStrategies && !v.referencedSessions[key.Value] {
+			v.addWarning(key, path, fmt.Sprintf("session %s is not referenced by any strategy", key.Value))
+		}
+	}
+}
+
+// mounts returns the sessions of the "on" field, it can be a string or a list of strings
+func (v *configValidator) mounts(node *yaml.Node, path string) (mounts []*yaml.Node) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		mounts = append(mounts, node)
+
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				v.addError(item, path, "session name should be a string")
+				continue
+			}
+			mounts = append(mounts, item)
+		}
+
+	default:
+		v.addError(node, path, "on should be a session name or a list of session names")
+	}
+
+	return mounts
+}
+
+func (v *configValidator) validateExchangeStrategies(node *yaml.Node, path string) {
+	if node.Kind != yaml.SequenceNode {
+		v.addError(node, path, "expecting a list of strategies")
+		return
+	}
+
+	for i, entry := range node.Content {
+		entryPath := fmt.Sprintf("%s[%d]", path, i)
+		if entry.Kind != yaml.MappingNode {
+			v.addError(entry, entryPath, "strategy config should be a map")
+			continue
+		}
+
+		var mounted = false
+		for j := 0; j+1 < len(entry.Content); j += 2 {
+			key, value := entry.Content[j], entry.Content[j+1]
+
+			switch key.Value {
+			case "on":
+				for _, mount := range v.mounts(value, entryPath+".on") {
+					mounted = true
+					v.referencedSessions[mount.Value] = true
+					if _, ok := v.sessions[mount.Value]; !ok {
+						v.addError(mount, entryPath+".on", fmt.Sprintf("session %s is not defined in sessions", mount.Value))
+					}
+				}
+				continue
+
+			case "off":
+				continue
+			}
+
+			prototype, ok := LoadedExchangeStrategies[key.Value]
+			if !ok {
+				v.addUnknownStrategy(key, entryPath, exchangeStrategyIDs())
+				continue
+			}
+
+			v.validateStrategy(key, value, prototype, joinPath(entryPath, key.Value))
+		}
+
+		if !mounted {
+			v.addWarning(entry, entryPath, "the strategy is not mounted on any session, please set the on field")
+		}
+	}
+}
+
+func (v *configValidator) validateCrossExchangeStrategies(node *yaml.Node, path string) {
+	if node.Kind != yaml.SequenceNode {
+		v.addError(node, path, "expecting a list of strategies")
+		return
+	}
+
+	for i, entry := range node.Content {
+		entryPath := fmt.Sprintf("%s[%d]", path, i)
+		if entry.Kind != yaml.MappingNode {
+			v.addError(entry, entryPath, "strategy config should be a map")
+			continue
+		}
+
+		for j := 0; j+1 < len(entry.Content); j += 2 {
+			key, value := entry.Content[j], entry.Content[j+1]
+
+			prototype, ok := LoadedCrossExchangeStrategies[key.Value]
+			if !ok {
+				v.addUnknownStrategy(key, entryPath, crossExchangeStrategyIDs())
+				continue
+			}
+
+			// the cross exchange strategies reference the sessions by their own fields, e.g., sourceExchange
+			v.referenceSessions(value)
+			v.validateStrategy(key, value, prototype, joinPath(entryPath, key.Value))
+		}
+	}
+}
+
+// referenceSessions marks the sessions that are mentioned by the string values of the node
+func (v *configValidator) referenceSessions(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode {
+		if _, ok := v.sessions[node.Value]; ok {
+			v.referencedSessions[node.Value] = true
+		}
+		return
+	}
+
+	for _, child := range node.Content {
+		v.referenceSessions(child)
+	}
+}
+
+func (v *configValidator) addUnknownStrategy(key *yaml.Node, path string, ids []string) {
+	message := fmt.Sprintf("strategy %s is not registered", key.Value)
+	if suggestion := closestName(key.Value, ids); len(suggestion) > 0 {
+		message += fmt.Sprintf(", did you mean %q?", suggestion)
+	}
+
+	v.addError(key, joinPath(path, key.Value), message)
+}
+
+// validateStrategy decodes the strategy like the config loader, and then checks the keys and the values of the strategy
+func (v *configValidator) validateStrategy(key, node *yaml.Node, prototype interface{}, path string) {
+	var conf interface{}
+	if err := node.Decode(&conf); err != nil {
+		v.addDecodeError(node, path, err)
+		return
+	}
+
+	strategy, err := reUnmarshal(conf, prototype)
+	if err != nil {
+		v.addError(key, path, err.Error())
+		return
+	}
+
+	v.walk(node, reflect.ValueOf(strategy), path, key.Value, "json")
+}
+
+// walk checks the node by the type of the value, the value is decoded from the node, it's used for calling the
+// Validate methods. The fields are looked up by the yaml tags for the config, and the json tags for the strategies.
+func (v *configValidator) walk(node *yaml.Node, value reflect.Value, path, key, tag string) {
+	if !value.IsValid() || node.Kind == yaml.AliasNode {
+		return
+	}
+
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			// the structure is still checked with the zero value, e.g., the null value
+			value = reflect.New(value.Type().Elem())
+		}
+
+		value = value.Elem()
+	}
+
+	if node.Kind == yaml.MappingNode && value.Kind() == reflect.Struct && value.CanAddr() {
+		if validator, ok := value.Addr().Interface().(interface{ Validate() error }); ok {
+			if err := validator.Validate(); err != nil {
+				v.addError(node, path, err.Error())
+			}
+		}
+	}
+
+	if node.Kind == yaml.ScalarNode {
+		v.checkScalar(node, value, path, key)
+	}
+
+	if hasCustomUnmarshaler(value.Type()) {
+		return
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+
+		fields := structFields(value.Type(), tag)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k, child := node.Content[i], node.Content[i+1]
+
+			name := k.Value
+			if tag == "json" {
+				// encoding/json matches the keys case-insensitively
+				name = strings.ToLower(name)
+			}
+
+			field, ok := fields[name]
+			if !ok {
+				v.addUnknownKey(k, path, tag, fields)
+				continue
+			}
+
+			v.walk(child, fieldByIndex(value, field.Index), joinPath(path, k.Value), k.Value, tag)
+		}
+
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode || value.Type().Key().Kind() != reflect.String {
+			return
+		}
+
+		elemType := value.Type().Elem()
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k, child := node.Content[i], node.Content[i+1]
+
+			elem := reflect.New(elemType).Elem()
+			if !value.IsNil() {
+				if found := value.MapIndex(reflect.ValueOf(k.Value).Convert(value.Type().Key())); found.IsValid() {
+					elem.Set(found)
+				}
+			}
+
+			v.walk(child, elem, joinPath(path, k.Value), key, tag)
+		}
+
+	case reflect.Slice, reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+
+		for i, child := range node.Content {
+			elem := reflect.New(value.Type().Elem()).Elem()
+			if i < value.Len() {
+				elem.Set(value.Index(i))
+			}
+
+			v.walk(child, elem, fmt.Sprintf("%s[%d]", path, i), key, tag)
+		}
+	}
+}
+
+// checkScalar checks the symbols and the intervals
+func (v *configValidator) checkScalar(node *yaml.Node, value reflect.Value, path, key string) {
+	if value.Kind() != reflect.String || len(node.Value) == 0 {
+		return
+	}
+
+	switch {
+	case value.Type() == reflect.TypeOf(types.Interval("")) || strings.EqualFold(key, "interval"):
+		// the intervals that are not supported by the exchanges are built from the smaller intervals
+		if types.Interval(node.Value).Minutes() == 0 {
+			v.addError(node, path, fmt.Sprintf("invalid interval %q, supported intervals: %s", node.Value, supportedIntervals()))
+		}
+
+	case strings.EqualFold(key, "symbol") || strings.EqualFold(key, "symbols"):
+		if symbolPattern.MatchString(node.Value) {
+			return
+		}
+
+		if symbolPattern.MatchString(strings.ToUpper(node.Value)) {
+			v.addError(node, path, fmt.Sprintf("symbol %q should be in upper case, e.g., %s", node.Value, strings.ToUpper(node.Value)))
+			return
+		}
+
+		v.addError(node, path, fmt.Sprintf("invalid symbol %q", node.Value))
+	}
+}
+
+func supportedIntervals() string {
+	var intervals []types.Interval
+	for interval := range types.SupportedIntervals {
+		intervals = append(intervals, interval)
+	}
+
+	sort.Slice(intervals, func(i, j int) bool {
+		return types.SupportedIntervals[intervals[i]] < types.SupportedIntervals[intervals[j]]
+	})
+
+	var names []string
+	for _, interval := range intervals {
+		names = append(names, string(interval))
+	}
+
+	return strings.Join(names, ", ")
+}
+
+var (
+	yamlUnmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// hasCustomUnmarshaler returns true if the type decodes itself, its structure is not checked
+func hasCustomUnmarshaler(t reflect.Type) bool {
+	pt := reflect.PtrTo(t)
+	return pt.Implements(yamlUnmarshalerType) || pt.Implements(jsonUnmarshalerType) || pt.Implements(textUnmarshalerType)
+}
+
+// structFields returns the fields of the struct by the key names of the tag, the embedded fields are
+// promoted like the decoders do. The json keys are in lower case since encoding/json matches them case-insensitively.
+func structFields(t reflect.Type, tag string) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tagValue := field.Tag.Get(tag)
+		if tagValue == "-" {
+			continue
+		}
+
+		options := strings.Split(tagValue, ",")
+		name := options[0]
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		inline := false
+		for _, option := range options[1:] {
+			if option == "inline" {
+				inline = true
+			}
+		}
+
+		// encoding/json promotes the untagged embedded struct, yaml.v3 promotes the inline struct only
+		if fieldType.Kind() == reflect.Struct && (inline || (tag == "json" && field.Anonymous && name == "")) {
+			for name, promoted := range structFields(fieldType, tag) {
+				if _, ok := fields[name]; ok {
+					continue
+				}
+
+				promoted.Index = append([]int{i}, promoted.Index...)
+				fields[name] = promoted
+			}
+			continue
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+			if tag == "yaml" {
+				name = strings.ToLower(name)
+			}
+		}
+
+		if tag == "json" {
+			name = strings.ToLower(name)
+		}
+
+		fields[name] = field
+	}
+
+	return fields
+}
+
+// fieldByIndex returns the nested field, the nil embedded pointers are allocated on a copy
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v = reflect.New(v.Type().Elem())
+			}
+			v = v.Elem()
+		}
+
+		v = v.Field(x)
+	}
+
+	return v
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+func joinPath(path, key string) string {
+	if len(path) == 0 {
+		return key
+	}
+
+	return path + "." + key
+}
+
+func exchangeStrategyIDs() (ids []string) {
+	for id := range LoadedExchangeStrategies {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func crossExchangeStrategyIDs() (ids []string) {
+	for id := range LoadedCrossExchangeStrategies {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// closestName returns the candidate that is similar to the name, it's used for the "did you mean" hints
+func closestName(name string, candidates []string) string {
+	var best string
+	var bestDistance = -1
+
+	sort.Strings(candidates)
+	for _, candidate := range candidates {
+		distance := editDistance(strings.ToLower(name), strings.ToLower(candidate))
+		if bestDistance == -1 || distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+
+	// the typos are usually less than 3 characters, and the short names are too easy to match
+	if bestDistance < 0 || bestDistance > 2 || bestDistance >= len(name) {
+		return ""
+	}
+
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = minInt(minInt(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
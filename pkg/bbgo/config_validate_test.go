@@ -0,0 +1,96 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateConfig(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		issues, err := ValidateConfig([]byte(`---
+sessions:
+  binance:
+    exchange: binance
+    envVarPrefix: binance
+
+exchangeStrategies:
+- on: binance
+  test:
+    symbol: BTCUSDT
+    interval: 1m
+    baseQuantity: 0.1
+`))
+		assert.NoError(t, err)
+		assert.Empty(t, issues)
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		_, err := ValidateConfig([]byte("sessions:\n  binance: [\n"))
+		assert.Error(t, err)
+	})
+
+	t.Run("issues", func(t *testing.T) {
+		issues, err := ValidateConfig([]byte(`---
+sessions:
+  binance:
+    exchange: binance
+  max:
+    exchange: maxx
+
+notifcations:
+  slack:
+    defaultChannel: "#bbgo"
+
+exchangeStrategies:
+- on: [binance, ftx]
+  test:
+    symbol: btcusdt
+    interval: 7x
+    baseQuantty: 0.1
+- on: binance
+  tset:
+    symbol: BTCUSDT
+- test:
+    symbol: BTC$USDT
+`))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, []ConfigIssue{
+			{Severity: ConfigIssueWarning, Line: 5, Column: 3, Path: "sessions.max", Message: "session max is not referenced by any strategy"},
+			{Severity: ConfigIssueError, Line: 6, Column: 15, Path: "sessions.max.exchange", Message: "invalid exchange name: maxx"},
+			{Severity: ConfigIssueError, Line: 8, Column: 1, Path: "notifcations", Message: `unknown key "notifcations", did you mean "notifications"?`},
+			{Severity: ConfigIssueError, Line: 13, Column: 17, Path: "exchangeStrategies[0].on", Message: "session ftx is not defined in sessions"},
+			{Severity: ConfigIssueError, Line: 15, Column: 13, Path: "exchangeStrategies[0].test.symbol", Message: `symbol "btcusdt" should be in upper case, e.g., BTCUSDT`},
+			{Severity: ConfigIssueError, Line: 16, Column: 15, Path: "exchangeStrategies[0].test.interval", Message: `invalid interval "7x", supported intervals: 1m, 5m, 15m, 30m, 1h, 2h, 4h, 6h, 12h, 1d, 3d`},
+			{Severity: ConfigIssueError, Line: 17, Column: 5, Path: "exchangeStrategies[0].test.baseQuantty", Message: `unknown key "baseQuantty", did you mean "baseQuantity"?`},
+			{Severity: ConfigIssueError, Line: 19, Column: 3, Path: "exchangeStrategies[1].tset", Message: `strategy tset is not registered, did you mean "test"?`},
+			{Severity: ConfigIssueWarning, Line: 21, Column: 3, Path: "exchangeStrategies[2]", Message: "the strategy is not mounted on any session, please set the on field"},
+			{Severity: ConfigIssueError, Line: 22, Column: 13, Path: "exchangeStrategies[2].test.symbol", Message: `invalid symbol "BTC$USDT"`},
+		}, []ConfigIssue(issues))
+		assert.True(t, issues.HasErrors())
+	})
+
+	t.Run("type error", func(t *testing.T) {
+		issues, err := ValidateConfig([]byte(`---
+sessions:
+  binance:
+    exchange: binance
+    publicOnly: maybe
+`))
+		if assert.NoError(t, err) && assert.Len(t, issues, 1) {
+			assert.Equal(t, 5, issues[0].Line)
+			assert.Equal(t, ConfigIssueError, issues[0].Severity)
+		}
+	})
+}
+
+func TestConfigIssue_String(t *testing.T) {
+	issue := ConfigIssue{Severity: ConfigIssueError, Line: 3, Column: 5, Path: "sessions.binance", Message: "exchange is required"}
+	assert.Equal(t, "3:5: error: sessions.binance: exchange is required", issue.String())
+
+	issue.File = "bbgo.yaml"
+	assert.Equal(t, "bbgo.yaml:3:5: error: sessions.binance: exchange is required", issue.String())
+}
@@ -0,0 +1,478 @@
+package bbgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/multierr"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const (
+	defaultConversionCurrency       = "USDT"
+	defaultConversionMaxHops        = 3
+	defaultConversionUpdateInterval = time.Minute
+	defaultPriceAPITimeout          = 10 * time.Second
+)
+
+// CurrencyConversionConfig configures the currency converter that values the assets in the reference currency,
+// which is used by the balance reports, the balance snapshots and the notional caps of the symbol guards
+type CurrencyConversionConfig struct {
+	// Currency is the default reference currency of the reports, defaults to USDT
+	Currency string `json:"currency,omitempty" yaml:"currency,omitempty"`
+
+	// MaxHops is the max number of the markets to go through, e.g., ETH -> BTC -> USDT is 2 hops, defaults to 3
+	MaxHops int `json:"maxHops,omitempty" yaml:"maxHops,omitempty"`
+
+	// UpdateInterval is the interval to refresh the tickers of the markets, defaults to 1m
+	UpdateInterval types.Duration `json:"updateInterval,omitempty" yaml:"updateInterval,omitempty"`
+
+	// Pegs are the currencies that are converted 1:1, e.g., {USD: USDT, BUSD: USDT}
+	Pegs map[string]string `json:"pegs,omitempty" yaml:"pegs,omitempty"`
+
+	// PriceAPI is the external price api, which is used when the asset can not be converted through the markets
+	PriceAPI *PriceAPIConfig `json:"priceAPI,omitempty" yaml:"priceAPI,omitempty"`
+}
+
+func (c *CurrencyConversionConfig) Validate() error {
+	if c.MaxHops < 0 {
+		return errors.New("currency conversion maxHops can not be negative")
+	}
+
+	for from, to := range c.Pegs {
+		if len(from) == 0 || len(to) == 0 || from == to {
+			return fmt.Errorf("invalid currency peg %q -> %q", from, to)
+		}
+	}
+
+	if c.PriceAPI != nil {
+		return c.PriceAPI.Validate()
+	}
+
+	return nil
+}
+
+// PriceAPIConfig is the http api that returns the price of the asset in the currency. The {asset} and the {currency}
+// placeholders of the url are replaced, and the response is a json number or a json object with the price field,
+// e.g., https://prices.example.com/v1/price?base={asset}&quote={currency}
+type PriceAPIConfig struct {
+	URL string `json:"url" yaml:"url"`
+
+	Timeout types.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+func (c *PriceAPIConfig) Validate() error {
+	if len(c.URL) == 0 {
+		return errors.New("price api url is required")
+	}
+
+	if !strings.Contains(c.URL, "{asset}") || !strings.Contains(c.URL, "{currency}") {
+		return fmt.Errorf("price api url %s should contain the {asset} and the {currency} placeholders", c.URL)
+	}
+
+	return nil
+}
+
+// PriceAPI queries the price of the asset from the external price api
+type PriceAPI struct {
+	Config PriceAPIConfig
+
+	client *http.Client
+}
+
+func NewPriceAPI(config PriceAPIConfig) *PriceAPI {
+	timeout := config.Timeout.Duration()
+	if timeout == 0 {
+		timeout = defaultPriceAPITimeout
+	}
+
+	return &PriceAPI{
+		Config: config,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (a *PriceAPI) QueryPrice(ctx context.Context, asset, currency string) (float64, error) {
+	u := strings.NewReplacer("{asset}", url.QueryEscape(asset), "{currency}", url.QueryEscape(currency)).Replace(a.Config.URL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("price api returns %s for %s/%s", resp.Status, asset, currency)
+	}
+
+	var body json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, err
+	}
+
+	return parsePriceAPIResponse(body)
+}
+
+func parsePriceAPIResponse(body json.RawMessage) (float64, error) {
+	var price float64
+	if err := json.Unmarshal(body, &price); err != nil {
+		var obj struct {
+			Price *float64 `json:"price"`
+		}
+
+		if err := json.Unmarshal(body, &obj); err != nil || obj.Price == nil {
+			return 0, fmt.Errorf("unexpected price api response: %s", string(body))
+		}
+
+		price = *obj.Price
+	}
+
+	if price <= 0 {
+		return 0, fmt.Errorf("invalid price %f from the price api", price)
+	}
+
+	return price, nil
+}
+
+// ConversionRates is the graph of the conversion rates between the currencies, rates[from][to] is the amount of
+// the currency "to" of one unit of the currency "from"
+type ConversionRates map[string]map[string]float64
+
+// Add adds the rate of the pair and the inverse rate, the zero rate is ignored
+func (r ConversionRates) Add(from, to string, rate float64) {
+	if rate <= 0 || from == to {
+		return
+	}
+
+	r.set(from, to, rate)
+	r.set(to, from, 1.0/rate)
+}
+
+func (r ConversionRates) set(from, to string, rate float64) {
+	if _, ok := r[from]; !ok {
+		r[from] = make(map[string]float64)
+	}
+
+	r[from][to] = rate
+}
+
+// Rate returns the rate of the path with the least hops, the path is searched in the alphabetical order of the
+// currencies, so the same path is used for the same rates
+func (r ConversionRates) Rate(from, to string, maxHops int) (float64, bool) {
+	if from == to {
+		return 1.0, true
+	}
+
+	type node struct {
+		currency string
+		rate     float64
+	}
+
+	visited := map[string]bool{from: true}
+	queue := []node{{currency: from, rate: 1.0}}
+	for hops := 0; hops < maxHops && len(queue) > 0; hops++ {
+		var next []node
+		for _, n := range queue {
+			edges := r[n.currency]
+
+			var currencies []string
+			for currency := range edges {
+				currencies = append(currencies, currency)
+			}
+			sort.Strings(currencies)
+
+			for _, currency := range currencies {
+				if visited[currency] {
+					continue
+				}
+
+				rate := n.rate * edges[currency]
+				if currency == to {
+					return rate, true
+				}
+
+				visited[currency] = true
+				next = append(next, node{currency: currency, rate: rate})
+			}
+		}
+
+		queue = next
+	}
+
+	return 0, false
+}
+
+// CurrencyConverter converts the assets into the other currencies with the tickers of the session markets,
+// the conversion goes through the other currencies when there is no direct market, e.g., ETH -> BTC -> USDT.
+// The external price api is queried when the asset can not be converted through the markets.
+type CurrencyConverter struct {
+	Currency       string
+	MaxHops        int
+	UpdateInterval time.Duration
+
+	pegs     map[string]string
+	priceAPI *PriceAPI
+	sessions map[string]*ExchangeSession
+
+	mu         sync.Mutex
+	rates      ConversionRates
+	updateTime time.Time
+
+	// apiPrices caches the prices of the price api by "asset/currency"
+	apiPrices map[string]apiPrice
+}
+
+type apiPrice struct {
+	price float64
+	time  time.Time
+}
+
+func NewCurrencyConverter(config CurrencyConversionConfig, sessions map[string]*ExchangeSession) *CurrencyConverter {
+	converter := &CurrencyConverter{
+		Currency:       config.Currency,
+		MaxHops:        config.MaxHops,
+		UpdateInterval: config.UpdateInterval.Duration(),
+		pegs:           config.Pegs,
+		sessions:       sessions,
+		rates:          make(ConversionRates),
+		apiPrices:      make(map[string]apiPrice),
+	}
+
+	if len(converter.Currency) == 0 {
+		converter.Currency = defaultConversionCurrency
+	}
+
+	if converter.MaxHops == 0 {
+		converter.MaxHops = defaultConversionMaxHops
+	}
+
+	if converter.UpdateInterval == 0 {
+		converter.UpdateInterval = defaultConversionUpdateInterval
+	}
+
+	if config.PriceAPI != nil {
+		converter.priceAPI = NewPriceAPI(*config.PriceAPI)
+	}
+
+	return converter
+}
+
+// Update rebuilds the conversion rates from the tickers of the session markets, the session that fails to query
+// the tickers is skipped and the error is returned after the rates are updated
+func (c *CurrencyConverter) Update(ctx context.Context) error {
+	rates := make(ConversionRates)
+	for from, to := range c.pegs {
+		rates.Add(from, to, 1.0)
+	}
+
+	var err error
+	for _, name := range sortedSessionNames(c.sessions) {
+		markets := c.sessions[name].Markets()
+		if len(markets) == 0 {
+			continue
+		}
+
+		var symbols []string
+		for symbol := range markets {
+			symbols = append(symbols, symbol)
+		}
+		sort.Strings(symbols)
+
+		tickers, queryErr := c.sessions[name].Exchange.QueryTickers(ctx, symbols...)
+		if queryErr != nil {
+			err = multierr.Append(err, errors.Wrapf(queryErr, "can not query the tickers of session %s", name))
+			continue
+		}
+
+		for _, symbol := range symbols {
+			ticker, ok := tickers[symbol]
+			if !ok {
+				continue
+			}
+
+			market := markets[symbol]
+			rates.Add(market.BaseCurrency, market.QuoteCurrency, tickerPrice(ticker))
+		}
+	}
+
+	c.mu.Lock()
+	c.rates = rates
+	c.updateTime = time.Now()
+	c.mu.Unlock()
+	return err
+}
+
+// CachedRate returns the rate from the current rates without updating the rates or querying the price api,
+// so it can be used in the order submission
+func (c *CurrencyConverter) CachedRate(from, to string) (float64, bool) {
+	if c == nil {
+		return 0, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.rates.Rate(from, to, c.MaxHops)
+}
+
+// Rate returns the conversion rate from the currency "from" to the currency "to", the rates are updated if they
+// are older than the update interval
+func (c *CurrencyConverter) Rate(ctx context.Context, from, to string) (float64, error) {
+	if from == to {
+		return 1.0, nil
+	}
+
+	c.mu.Lock()
+	stale := time.Since(c.updateTime) > c.UpdateInterval
+	c.mu.Unlock()
+
+	if stale {
+		if err := c.Update(ctx); err != nil {
+			log.WithError(err).Warn("currency converter update error")
+		}
+	}
+
+	if rate, ok := c.CachedRate(from, to); ok {
+		return rate, nil
+	}
+
+	if c.priceAPI != nil {
+		return c.queryPriceAPI(ctx, from, to)
+	}
+
+	return 0, fmt.Errorf("can not convert %s to %s", from, to)
+}
+
+// Convert converts the amount of the currency "from" into the currency "to"
+func (c *CurrencyConverter) Convert(ctx context.Context, amount float64, from, to string) (float64, error) {
+	rate, err := c.Rate(ctx, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	return amount * rate, nil
+}
+
+func (c *CurrencyConverter) queryPriceAPI(ctx context.Context, from, to string) (float64, error) {
+	key := from + "/" + to
+
+	c.mu.Lock()
+	cached, ok := c.apiPrices[key]
+	c.mu.Unlock()
+
+	if ok && time.Since(cached.time) <= c.UpdateInterval {
+		return cached.price, nil
+	}
+
+	price, err := c.priceAPI.QueryPrice(ctx, from, to)
+	if err != nil {
+		return 0, errors.Wrapf(err, "can not convert %s to %s", from, to)
+	}
+
+	c.mu.Lock()
+	c.apiPrices[key] = apiPrice{price: price, time: time.Now()}
+	c.mu.Unlock()
+
+	return price, nil
+}
+
+// Run updates the rates immediately and then every update interval until the context is done
+func (c *CurrencyConverter) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.UpdateInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.Update(ctx); err != nil {
+			log.WithError(err).Warn("currency converter update error")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+		}
+	}
+}
+
+// tickerPrice returns the last price of the ticker, or the mid price if the last price is not available
+func tickerPrice(ticker types.Ticker) float64 {
+	if ticker.Last > 0 {
+		return ticker.Last
+	}
+
+	if ticker.Buy > 0 && ticker.Sell > 0 {
+		return (ticker.Buy + ticker.Sell) / 2.0
+	}
+
+	return 0
+}
+
+// ValueBalances sums the balances in the currency, the assets that can not be converted are returned as missing
+func ValueBalances(ctx context.Context, converter *CurrencyConverter, balances types.BalanceMap, currency string) (value float64, missing []string) {
+	for _, balance := range balances {
+		total := balance.Total().Float64()
+		if total == 0 {
+			continue
+		}
+
+		v, err := converter.Convert(ctx, total, balance.Currency, currency)
+		if err != nil {
+			missing = append(missing, balance.Currency)
+			continue
+		}
+
+		value += v
+	}
+
+	sort.Strings(missing)
+	return value, missing
+}
+
+// CurrencyConverter returns the currency converter of the sessions
+func (environ *Environment) CurrencyConverter() *CurrencyConverter {
+	return environ.currencyConverter
+}
+
+// ConfigureCurrencyConversion replaces the currency converter with the config, the rates are updated in the
+// background when the config is given or the symbol guards cap the notional in the reference currency, since the
+// symbol guards only use the cached rates
+func (environ *Environment) ConfigureCurrencyConversion(ctx context.Context, config *CurrencyConversionConfig) error {
+	if config != nil {
+		if err := config.Validate(); err != nil {
+			return err
+		}
+
+		environ.currencyConverter = NewCurrencyConverter(*config, environ.sessions)
+	}
+
+	run := config != nil
+	for _, session := range environ.sessions {
+		session.currencyConverter = environ.currencyConverter
+		if session.SymbolGuard != nil && len(session.SymbolGuard.NotionalCurrency) > 0 {
+			run = true
+		}
+	}
+
+	if run {
+		log.Infof("currency converter is configured, reference currency %s", environ.currencyConverter.Currency)
+		go environ.currencyConverter.Run(ctx)
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package bbgo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestConversionRates_Rate(t *testing.T) {
+	rates := make(ConversionRates)
+	rates.Add("BTC", "USDT", 50000.0)
+	rates.Add("ETH", "BTC", 0.08)
+	rates.Add("USDT", "TWD", 30.0)
+	rates.Add("MAX", "TWD", 0.0)
+
+	rate, ok := rates.Rate("BTC", "USDT", 1)
+	assert.True(t, ok)
+	assert.Equal(t, 50000.0, rate)
+
+	rate, ok = rates.Rate("USDT", "BTC", 1)
+	assert.True(t, ok)
+	assert.InDelta(t, 1.0/50000.0, rate, 1e-12)
+
+	// ETH -> BTC -> USDT -> TWD
+	rate, ok = rates.Rate("ETH", "TWD", 3)
+	assert.True(t, ok)
+	assert.InDelta(t, 0.08*50000.0*30.0, rate, 1e-6)
+
+	_, ok = rates.Rate("ETH", "TWD", 2)
+	assert.False(t, ok, "the path exceeds the max hops")
+
+	_, ok = rates.Rate("MAX", "USDT", 3)
+	assert.False(t, ok, "the zero rate is ignored")
+
+	rate, ok = rates.Rate("DOGE", "DOGE", 0)
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, rate)
+}
+
+func TestCurrencyConverter(t *testing.T) {
+	exchange := &balanceSnapshotTestExchange{
+		tickers: map[string]types.Ticker{
+			"BTCUSD":  {Last: 50000.0},
+			"ETHBTC":  {Buy: 0.079, Sell: 0.081},
+			"USDTTWD": {Last: 30.0},
+		},
+	}
+
+	session := &ExchangeSession{
+		Name:     "ftx",
+		Exchange: exchange,
+		markets: map[string]types.Market{
+			"BTCUSD":  {Symbol: "BTCUSD", BaseCurrency: "BTC", QuoteCurrency: "USD"},
+			"ETHBTC":  {Symbol: "ETHBTC", BaseCurrency: "ETH", QuoteCurrency: "BTC"},
+			"USDTTWD": {Symbol: "USDTTWD", BaseCurrency: "USDT", QuoteCurrency: "TWD"},
+		},
+	}
+
+	converter := NewCurrencyConverter(CurrencyConversionConfig{
+		Pegs: map[string]string{"USD": "USDT"},
+	}, map[string]*ExchangeSession{"ftx": session})
+
+	_, ok := converter.CachedRate("BTC", "USDT")
+	assert.False(t, ok, "the rates are not updated yet")
+
+	ctx := context.Background()
+	value, err := converter.Convert(ctx, 0.5, "ETH", "USDT")
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.5*0.08*50000.0, value, 1e-6)
+
+	rate, ok := converter.CachedRate("BTC", "TWD")
+	assert.True(t, ok)
+	assert.InDelta(t, 50000.0*30.0, rate, 1e-6)
+
+	_, err = converter.Rate(ctx, "DOGE", "USDT")
+	assert.Error(t, err)
+
+	value, missing := ValueBalances(ctx, converter, types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(100.0), Locked: fixedpoint.NewFromFloat(50.0)},
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(0.1)},
+		"DOGE": {Currency: "DOGE", Available: fixedpoint.NewFromFloat(100.0)},
+		"MAX":  {Currency: "MAX"},
+	}, "USDT")
+	assert.InDelta(t, 150.0+5000.0, value, 1e-6)
+	assert.Equal(t, []string{"DOGE"}, missing, "the zero balance of MAX is skipped")
+}
+
+func TestCurrencyConverter_PriceAPI(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "DOGE", r.URL.Query().Get("base"))
+		assert.Equal(t, "USDT", r.URL.Query().Get("quote"))
+		_ = json.NewEncoder(w).Encode(map[string]float64{"price": 0.2})
+	}))
+	defer server.Close()
+
+	config := CurrencyConversionConfig{PriceAPI: &PriceAPIConfig{URL: server.URL + "/price?base={asset}&quote={currency}"}}
+	assert.NoError(t, config.Validate())
+
+	converter := NewCurrencyConverter(config, nil)
+	converter.updateTime = time.Now()
+
+	ctx := context.Background()
+	value, err := converter.Convert(ctx, 100.0, "DOGE", "USDT")
+	assert.NoError(t, err)
+	assert.InDelta(t, 20.0, value, 1e-9)
+
+	_, err = converter.Convert(ctx, 100.0, "DOGE", "USDT")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, requests, "the price is cached")
+
+	assert.Error(t, (&PriceAPIConfig{URL: server.URL}).Validate())
+	assert.Error(t, (&CurrencyConversionConfig{Pegs: map[string]string{"USD": "USD"}}).Validate())
+}
+
+func Test_parsePriceAPIResponse(t *testing.T) {
+	price, err := parsePriceAPIResponse(json.RawMessage(`1.5`))
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, price)
+
+	price, err = parsePriceAPIResponse(json.RawMessage(`{"price": 2.5}`))
+	assert.NoError(t, err)
+	assert.Equal(t, 2.5, price)
+
+	_, err = parsePriceAPIResponse(json.RawMessage(`{"last": 2.5}`))
+	assert.Error(t, err)
+
+	_, err = parsePriceAPIResponse(json.RawMessage(`0`))
+	assert.Error(t, err)
+}
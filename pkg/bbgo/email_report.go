@@ -0,0 +1,356 @@
+package bbgo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"github.com/c9s/bbgo/pkg/notifier/emailnotifier"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultEmailReportCheckInterval = time.Minute
+
+// EmailReportConfig sends the summary reports of the PnL, the positions and the balance changes as the HTML emails
+type EmailReportConfig struct {
+	Host string `json:"host" yaml:"host"`
+	Port int    `json:"port,omitempty" yaml:"port,omitempty"`
+
+	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+
+	// Password is the smtp password, the SMTP_PASSWORD environment variable is used if it's empty
+	Password string `json:"password,omitempty" yaml:"password,omitempty"`
+
+	From string   `json:"from" yaml:"from"`
+	To   []string `json:"to" yaml:"to"`
+
+	// Periods are the report periods, day, week or month, the report is sent when the period is over
+	Periods []types.ProfitPeriod `json:"periods" yaml:"periods"`
+}
+
+func (c *EmailReportConfig) Validate() error {
+	if len(c.Host) == 0 {
+		return errors.New("email report smtp host is required")
+	}
+
+	if len(c.From) == 0 || len(c.To) == 0 {
+		return errors.New("email report from and to addresses are required")
+	}
+
+	if len(c.Periods) == 0 {
+		return errors.New("email report periods are required")
+	}
+
+	for _, period := range c.Periods {
+		if err := period.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type EmailReportPosition struct {
+	Session           string
+	Symbol            string
+	Base              float64
+	AverageCost       float64
+	RealizedNetProfit float64
+}
+
+type EmailReportBalanceChange struct {
+	Session  string
+	Currency string
+	Previous float64
+	Current  float64
+	Change   float64
+}
+
+// EmailReport is the summary of the period, it's the data of the email template
+type EmailReport struct {
+	Period types.ProfitPeriod
+	Since  time.Time
+	Until  time.Time
+
+	// ProfitSummaries is nil if the database is not configured
+	ProfitSummaries []types.ProfitSummary
+	ProfitAvailable bool
+
+	Positions      []EmailReportPosition
+	BalanceChanges []EmailReportBalanceChange
+}
+
+func (r *EmailReport) Subject() string {
+	return fmt.Sprintf("[bbgo] %s report %s", r.Period, r.Since.Format("2006-01-02"))
+}
+
+var emailReportTemplate = template.Must(template.New("email-report").Funcs(template.FuncMap{
+	"date":       func(t time.Time) string { return t.Format("2006-01-02 15:04") },
+	"float":      func(f float64) string { return fmt.Sprintf("%.8g", f) },
+	"signed":     func(f float64) string { return fmt.Sprintf("%+.8g", f) },
+	"percentage": func(f float64) string { return fmt.Sprintf("%.2f%%", f*100.0) },
+}).Parse(`<html>
+<body style="font-family: sans-serif">
+<h2>BBGO {{ .Period }} report</h2>
+<p>{{ date .Since }} ~ {{ date .Until }}</p>
+
+<h3>Profit and Loss</h3>
+{{ if not .ProfitAvailable }}<p>The database is not configured, the profits are not recorded.</p>
+{{ else if not .ProfitSummaries }}<p>No profit in this period.</p>
+{{ else }}<table border="1" cellpadding="4" cellspacing="0">
+<tr><th>Strategy</th><th>Profit</th><th>Net Profit</th><th>Trade Amount</th><th>Winning Ratio</th></tr>
+{{ range .ProfitSummaries }}<tr><td>{{ .Strategy }}</td><td>{{ float .Profit }} {{ .QuoteCurrency }}</td><td>{{ float .NetProfit }} {{ .QuoteCurrency }}</td><td>{{ float .TradeAmount }} {{ .QuoteCurrency }}</td><td>{{ percentage .WinningRatio }}</td></tr>
+{{ end }}</table>
+{{ end }}
+<h3>Positions</h3>
+{{ if not .Positions }}<p>No position.</p>
+{{ else }}<table border="1" cellpadding="4" cellspacing="0">
+<tr><th>Session</th><th>Symbol</th><th>Base</th><th>Average Cost</th><th>Realized Net Profit</th></tr>
+{{ range .Positions }}<tr><td>{{ .Session }}</td><td>{{ .Symbol }}</td><td>{{ float .Base }}</td><td>{{ float .AverageCost }}</td><td>{{ float .RealizedNetProfit }}</td></tr>
+{{ end }}</table>
+{{ end }}
+<h3>Balance Changes</h3>
+{{ if not .BalanceChanges }}<p>No balance change.</p>
+{{ else }}<table border="1" cellpadding="4" cellspacing="0">
+<tr><th>Session</th><th>Currency</th><th>Previous</th><th>Current</th><th>Change</th></tr>
+{{ range .BalanceChanges }}<tr><td>{{ .Session }}</td><td>{{ .Currency }}</td><td>{{ float .Previous }}</td><td>{{ float .Current }}</td><td>{{ signed .Change }}</td></tr>
+{{ end }}</table>
+{{ end }}
+</body>
+</html>
+`))
+
+// RenderEmailReport renders the report as the HTML email body
+func RenderEmailReport(report *EmailReport) (string, error) {
+	var buf bytes.Buffer
+	if err := emailReportTemplate.Execute(&buf, report); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+type emailSender interface {
+	Send(subject, html string) error
+}
+
+// EmailReporter sends the email report when the period is over, the balance changes are compared with
+// the balances at the beginning of the period, which are taken in memory since the reporter is started.
+type EmailReporter struct {
+	periods       []types.ProfitPeriod
+	sender        emailSender
+	sessions      map[string]*ExchangeSession
+	profitService *service.ProfitService
+
+	mu           sync.Mutex
+	periodStarts map[types.ProfitPeriod]time.Time
+	balances     map[types.ProfitPeriod]map[string]types.BalanceMap
+}
+
+func NewEmailReporter(config EmailReportConfig, environ *Environment) (*EmailReporter, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	password := config.Password
+	if len(password) == 0 {
+		password = viper.GetString("smtp-password")
+	}
+
+	sender, err := emailnotifier.New(emailnotifier.Config{
+		Host:     config.Host,
+		Port:     config.Port,
+		Username: config.Username,
+		Password: password,
+		From:     config.From,
+		To:       config.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newEmailReporter(config.Periods, sender, environ.sessions, environ.ProfitService), nil
+}
+
+func newEmailReporter(periods []types.ProfitPeriod, sender emailSender, sessions map[string]*ExchangeSession, profitService *service.ProfitService) *EmailReporter {
+	return &EmailReporter{
+		periods:       periods,
+		sender:        sender,
+		sessions:      sessions,
+		profitService: profitService,
+		periodStarts:  make(map[types.ProfitPeriod]time.Time),
+		balances:      make(map[types.ProfitPeriod]map[string]types.BalanceMap),
+	}
+}
+
+func (r *EmailReporter) snapshotBalances() map[string]types.BalanceMap {
+	snapshot := make(map[string]types.BalanceMap, len(r.sessions))
+	for name, session := range r.sessions {
+		if session.Account != nil {
+			snapshot[name] = session.Account.Balances()
+		}
+	}
+	return snapshot
+}
+
+// Start takes the balance snapshots of the current periods
+func (r *EmailReporter) Start(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	balances := r.snapshotBalances()
+	for _, period := range r.periods {
+		r.periodStarts[period] = period.Start(now.Local())
+		r.balances[period] = balances
+	}
+}
+
+// Check sends the reports of the periods that are over at the given time
+func (r *EmailReporter) Check(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, period := range r.periods {
+		since, ok := r.periodStarts[period]
+		if !ok {
+			continue
+		}
+
+		until := period.End(since)
+		if now.Before(until) {
+			continue
+		}
+
+		report, err := r.buildReport(period, since, until)
+		if err != nil {
+			log.WithError(err).Errorf("can not build the %s email report", period)
+		} else if err := r.send(report); err != nil {
+			log.WithError(err).Errorf("can not send the %s email report", period)
+		}
+
+		r.periodStarts[period] = period.Start(now.Local())
+		r.balances[period] = r.snapshotBalances()
+	}
+}
+
+func (r *EmailReporter) send(report *EmailReport) error {
+	html, err := RenderEmailReport(report)
+	if err != nil {
+		return err
+	}
+
+	return r.sender.Send(report.Subject(), html)
+}
+
+func (r *EmailReporter) buildReport(period types.ProfitPeriod, since, until time.Time) (*EmailReport, error) {
+	report := &EmailReport{
+		Period: period,
+		Since:  since,
+		Until:  until,
+	}
+
+	if r.profitService != nil {
+		summaries, err := r.profitService.QuerySummaries(service.QueryProfitsOptions{Since: &since, Until: &until}, period)
+		if err != nil {
+			return nil, err
+		}
+
+		report.ProfitAvailable = true
+		report.ProfitSummaries = summaries
+	}
+
+	for _, name := range sortedSessionNames(r.sessions) {
+		positions := r.sessions[name].Positions()
+
+		var symbols []string
+		for symbol := range positions {
+			symbols = append(symbols, symbol)
+		}
+		sort.Strings(symbols)
+
+		for _, symbol := range symbols {
+			position := positions[symbol].Snapshot()
+			if position.Base == 0 {
+				continue
+			}
+
+			report.Positions = append(report.Positions, EmailReportPosition{
+				Session:           name,
+				Symbol:            symbol,
+				Base:              position.Base.Float64(),
+				AverageCost:       position.AverageCost.Float64(),
+				RealizedNetProfit: position.RealizedNetProfit.Float64(),
+			})
+		}
+	}
+
+	report.BalanceChanges = compareBalances(r.balances[period], r.snapshotBalances())
+	return report, nil
+}
+
+// compareBalances returns the changed total balances of the sessions, sorted by the session and the currency
+func compareBalances(previous, current map[string]types.BalanceMap) (changes []EmailReportBalanceChange) {
+	var sessionNames []string
+	for name := range current {
+		sessionNames = append(sessionNames, name)
+	}
+	sort.Strings(sessionNames)
+
+	for _, name := range sessionNames {
+		var currencies []string
+		for currency := range current[name] {
+			currencies = append(currencies, currency)
+		}
+		for currency := range previous[name] {
+			if _, ok := current[name][currency]; !ok {
+				currencies = append(currencies, currency)
+			}
+		}
+		sort.Strings(currencies)
+
+		for _, currency := range currencies {
+			before := previous[name][currency].Total().Float64()
+			after := current[name][currency].Total().Float64()
+			if before == after {
+				continue
+			}
+
+			changes = append(changes, EmailReportBalanceChange{
+				Session:  name,
+				Currency: currency,
+				Previous: before,
+				Current:  after,
+				Change:   after - before,
+			})
+		}
+	}
+
+	return changes
+}
+
+// Run checks the periods periodically until the context is done
+func (r *EmailReporter) Run(ctx context.Context) {
+	r.Start(time.Now())
+
+	ticker := time.NewTicker(defaultEmailReportCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case now := <-ticker.C:
+			r.Check(now)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package bbgo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type emailReportTestSender struct {
+	subjects []string
+	bodies   []string
+}
+
+func (s *emailReportTestSender) Send(subject, html string) error {
+	s.subjects = append(s.subjects, subject)
+	s.bodies = append(s.bodies, html)
+	return nil
+}
+
+func TestEmailReportConfig_Validate(t *testing.T) {
+	config := EmailReportConfig{Host: "smtp.example.com", From: "bbgo@example.com", To: []string{"me@example.com"}}
+	assert.Error(t, config.Validate(), "periods are required")
+
+	config.Periods = []types.ProfitPeriod{"hour"}
+	assert.Error(t, config.Validate())
+
+	config.Periods = []types.ProfitPeriod{types.ProfitPeriodDay, types.ProfitPeriodWeek}
+	assert.NoError(t, config.Validate())
+
+	config.To = nil
+	assert.Error(t, config.Validate())
+}
+
+func TestEmailReporter_Check(t *testing.T) {
+	session := newKillSwitchTestSession(&killSwitchTestExchange{})
+	session.Account = types.NewAccount()
+	session.Account.UpdateBalances(types.BalanceMap{
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(0.5)},
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(5000.0)},
+	})
+
+	sender := &emailReportTestSender{}
+	reporter := newEmailReporter([]types.ProfitPeriod{types.ProfitPeriodDay}, sender, map[string]*ExchangeSession{"binance": session}, nil)
+
+	start := time.Date(2021, 12, 20, 10, 0, 0, 0, time.Local)
+	reporter.Start(start)
+
+	session.Account.UpdateBalances(types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(4000.0)},
+	})
+
+	// the period is not over yet
+	reporter.Check(start.Add(time.Hour))
+	assert.Len(t, sender.subjects, 0)
+
+	reporter.Check(start.Add(15 * time.Hour))
+	if assert.Len(t, sender.subjects, 1) {
+		assert.Equal(t, "[bbgo] day report 2021-12-20", sender.subjects[0])
+		assert.Contains(t, sender.bodies[0], "The database is not configured")
+		assert.Contains(t, sender.bodies[0], "<td>BTCUSDT</td><td>0.5</td><td>10000</td>")
+		assert.Contains(t, sender.bodies[0], "<td>USDT</td><td>5000</td><td>4000</td><td>-1000</td>")
+		assert.NotContains(t, sender.bodies[0], "<td>BTC</td>")
+	}
+
+	// the next report is sent when the next period is over
+	reporter.Check(start.Add(16 * time.Hour))
+	assert.Len(t, sender.subjects, 1)
+
+	reporter.Check(start.Add(38 * time.Hour))
+	if assert.Len(t, sender.subjects, 2) {
+		assert.Equal(t, "[bbgo] day report 2021-12-21", sender.subjects[1])
+		assert.Contains(t, sender.bodies[1], "No balance change.")
+	}
+}
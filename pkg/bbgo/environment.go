@@ -3,24 +3,31 @@ package bbgo
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"image/png"
 	"io/ioutil"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/codingconcepts/env"
+	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
 	"github.com/pquerna/otp"
 	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
 	"github.com/spf13/viper"
 	"gopkg.in/tucnak/telebot.v2"
 
 	"github.com/c9s/bbgo/pkg/cmd/cmdutil"
+	"github.com/c9s/bbgo/pkg/notifier/discordnotifier"
 	"github.com/c9s/bbgo/pkg/notifier/slacknotifier"
 	"github.com/c9s/bbgo/pkg/notifier/telegramnotifier"
+	"github.com/c9s/bbgo/pkg/notifier/webhooknotifier"
 	"github.com/c9s/bbgo/pkg/service"
 	"github.com/c9s/bbgo/pkg/slack/slacklog"
 	"github.com/c9s/bbgo/pkg/types"
@@ -71,6 +78,22 @@ type Environment struct {
 	RewardService            *service.RewardService
 	SyncService              *service.SyncService
 	AccountService 			 *service.AccountService
+	ProfitService            *service.ProfitService
+	BalanceSnapshotService   *service.BalanceSnapshotService
+
+	AnalyticsService *service.AnalyticsService
+
+	// CommandQueue is configured with the database, the control commands are queued in it
+	CommandQueue *CommandQueue
+
+	// SlackInteraction handles the interactive buttons of the slack messages, it's configured with the slack signing secret
+	SlackInteraction *slacknotifier.InteractionHandler
+
+	// HealthChecker reports the liveness and the readiness of the sessions, the database and the strategies
+	HealthChecker *HealthChecker
+
+	// EventFeed rebroadcasts the normalized events of the sessions and the notifications to the local subscribers
+	EventFeed *EventFeed
 
 	// startTime is the time of start point (which is used in the backtest)
 	startTime time.Time
@@ -83,20 +106,32 @@ type Environment struct {
 	syncStatus      SyncStatus
 
 	sessions map[string]*ExchangeSession
+
+	// indexPrices is the index price services by symbol
+	indexPrices map[string]*IndexPriceService
+
+	// currencyConverter values the assets of the sessions in the reference currency
+	currencyConverter *CurrencyConverter
 }
 
 func NewEnvironment() *Environment {
-	return &Environment{
+	environ := &Environment{
 		// default trade scan time
 		syncStartTime: time.Now().AddDate(-1, 0, 0), // defaults to sync from 1 year ago
 		sessions:      make(map[string]*ExchangeSession),
+		indexPrices:   make(map[string]*IndexPriceService),
 		startTime:     time.Now(),
 
 		syncStatus: SyncNotStarted,
 		PersistenceServiceFacade: &service.PersistenceServiceFacade{
 			Memory: service.NewMemoryService(),
 		},
+		HealthChecker: NewHealthChecker(),
+		EventFeed:     NewEventFeed(),
 	}
+	environ.currencyConverter = NewCurrencyConverter(CurrencyConversionConfig{}, environ.sessions)
+	environ.HealthChecker.AddCheck("database", false, environ.databaseHealthCheck)
+	return environ
 }
 
 func (environ *Environment) Session(name string) (*ExchangeSession, bool) {
@@ -123,27 +158,81 @@ func (environ *Environment) SelectSessions(names ...string) map[string]*Exchange
 	return sessions
 }
 
-func (environ *Environment) ConfigureDatabase(ctx context.Context) error {
-	// configureDB configures the database service based on the environment variable
+// LookupDatabaseEnv returns the database driver and the dsn configured by the environment variables
+func LookupDatabaseEnv() (driver, dsn string, ok bool) {
 	if driver, ok := os.LookupEnv("DB_DRIVER"); ok {
 
 		if dsn, ok := os.LookupEnv("DB_DSN"); ok {
-			return environ.ConfigureDatabaseDriver(ctx, driver, dsn)
+			return driver, dsn, true
+		}
+
+		// the sqlite3 database file doesn't need to be created before
+		if driver == "sqlite3" {
+			return driver, service.DefaultSQLiteDSN, true
 		}
 
 	} else if dsn, ok := os.LookupEnv("SQLITE3_DSN"); ok {
 
-		return environ.ConfigureDatabaseDriver(ctx, "sqlite3", dsn)
+		return "sqlite3", dsn, true
 
 	} else if dsn, ok := os.LookupEnv("MYSQL_URL"); ok {
 
-		return environ.ConfigureDatabaseDriver(ctx, "mysql", dsn)
+		return "mysql", dsn, true
+
+	} else if dsn, ok := os.LookupEnv("POSTGRES_URL"); ok {
+
+		return "postgres", dsn, true
+
+	}
+
+	return "", "", false
+}
+
+func (environ *Environment) ConfigureDatabase(ctx context.Context) error {
+	// configureDB configures the database service based on the environment variable
+	if driver, dsn, ok := LookupDatabaseEnv(); ok {
+		return environ.ConfigureDatabaseDriver(ctx, driver, dsn)
+	}
+
+	return nil
+}
+
+// migrateDatabase applies the pending migrations unless --no-auto-migrate is given, the pending migrations are
+// reported instead, and they can be applied by the migrate command.
+func migrateDatabase(ctx context.Context, db *service.DatabaseService) error {
+	if !viper.GetBool("no-auto-migrate") {
+		return db.Upgrade(ctx)
+	}
 
+	pending, err := db.PendingMigrations()
+	if err != nil {
+		return err
+	}
+
+	if len(pending) > 0 {
+		log.Warnf("the database has %d pending migrations, the last one is %s, please run bbgo migrate up",
+			len(pending), pending[len(pending)-1].Name)
 	}
 
 	return nil
 }
 
+// ConfigureDefaultDatabase configures the database like ConfigureDatabase, but falls back to the sqlite3 database
+// file service.DefaultSQLiteDSN if no database is configured, so that the sync and the backtest can run without a
+// database server.
+func (environ *Environment) ConfigureDefaultDatabase(ctx context.Context) error {
+	if err := environ.ConfigureDatabase(ctx); err != nil {
+		return err
+	}
+
+	if environ.DatabaseService != nil {
+		return nil
+	}
+
+	log.Infof("no database is configured, using the sqlite3 database %s", service.DefaultSQLiteDSN)
+	return environ.ConfigureDatabaseDriver(ctx, "sqlite3", service.DefaultSQLiteDSN)
+}
+
 func (environ *Environment) ConfigureDatabaseDriver(ctx context.Context, driver string, dsn string) error {
 	environ.DatabaseService = service.NewDatabaseService(driver, dsn)
 	err := environ.DatabaseService.Connect()
@@ -151,7 +240,7 @@ func (environ *Environment) ConfigureDatabaseDriver(ctx context.Context, driver
 		return err
 	}
 
-	if err := environ.DatabaseService.Upgrade(ctx); err != nil {
+	if err := migrateDatabase(ctx, environ.DatabaseService); err != nil {
 		return err
 	}
 
@@ -161,6 +250,10 @@ func (environ *Environment) ConfigureDatabaseDriver(ctx context.Context, driver
 	environ.TradeService = &service.TradeService{DB: db}
 	environ.RewardService = &service.RewardService{DB: db}
 	environ.AccountService = &service.AccountService{DB: db}
+	environ.ProfitService = &service.ProfitService{DB: db}
+	environ.BalanceSnapshotService = &service.BalanceSnapshotService{DB: db}
+	environ.AnalyticsService = &service.AnalyticsService{KLines: &service.BacktestService{DB: db}}
+	environ.CommandQueue = NewCommandQueue(&service.CommandService{DB: db})
 
 	environ.SyncService = &service.SyncService{
 		TradeService:    environ.TradeService,
@@ -177,6 +270,7 @@ func (environ *Environment) ConfigureDatabaseDriver(ctx context.Context, driver
 func (environ *Environment) AddExchangeSession(name string, session *ExchangeSession) *ExchangeSession {
 	// update Notifiability from the environment
 	session.Notifiability = environ.Notifiability
+	session.currencyConverter = environ.currencyConverter
 
 	environ.sessions[name] = session
 	return session
@@ -268,12 +362,83 @@ func (environ *Environment) ConfigurePersistence(conf *PersistenceConfig) error
 		environ.PersistenceServiceFacade.Json = &service.JsonPersistenceService{Directory: conf.Json.Directory}
 	}
 
+	if conf.SQL != nil {
+		db, err := environ.persistenceDB(conf.SQL)
+		if err != nil {
+			return err
+		}
+
+		environ.PersistenceServiceFacade.SQL = &service.SQLPersistenceService{DB: db}
+	}
+
 	return nil
 }
 
+// persistenceDB returns the database of the sql persistence, the database of the environment is used
+// if the driver and the dsn are not set
+func (environ *Environment) persistenceDB(conf *service.SQLPersistenceConfig) (*sqlx.DB, error) {
+	if len(conf.Driver) == 0 && len(conf.DSN) == 0 {
+		if environ.DatabaseService == nil || environ.DatabaseService.DB == nil {
+			return nil, errors.New("sql persistence requires the database, please configure DB_DRIVER and DB_DSN or the sql persistence driver and dsn")
+		}
+
+		return environ.DatabaseService.DB, nil
+	}
+
+	if len(conf.Driver) == 0 || len(conf.DSN) == 0 {
+		return nil, errors.New("both driver and dsn of the sql persistence are required")
+	}
+
+	db := service.NewDatabaseService(conf.Driver, conf.DSN)
+	if err := db.Connect(); err != nil {
+		return nil, err
+	}
+
+	if err := migrateDatabase(context.Background(), db); err != nil {
+		return nil, err
+	}
+
+	return db.DB, nil
+}
+
 // ConfigureNotificationRouting configures the notification rules
 // for symbol-based routes, we should register the same symbol rules for each session.
 // for session-based routes, we should set the fixed callbacks for each session
+func (environ *Environment) configureNotificationRules(rules []NotificationRule) error {
+	router, err := NewNotificationRouter(rules, environ.sessionNamesOfExchange)
+	if err != nil {
+		return err
+	}
+
+	// the notifier could be absent if its token is not set, so it's a warning instead of an error
+	for _, rule := range rules {
+		for _, name := range rule.Notifiers {
+			if !environ.hasNotifier(name) {
+				log.Warnf("notifier %s of the notification rule is not configured", name)
+			}
+		}
+	}
+
+	environ.NotificationRouter = router
+
+	if levels := router.severityLevels(); len(levels) > 0 {
+		log.Debugf("notification rules route the log levels %v, setting up log hook...", levels)
+		log.AddHook(NewNotificationLogHook(&environ.Notifiability, levels...))
+	}
+
+	return nil
+}
+
+// sessionNamesOfExchange returns the names of the sessions connected to the exchange
+func (environ *Environment) sessionNamesOfExchange(exchange types.ExchangeName) (names []string) {
+	for name, session := range environ.sessions {
+		if session.ExchangeName == exchange {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 func (environ *Environment) ConfigureNotificationRouting(conf *NotificationConfig) error {
 	// configure routing here
 	if conf.SymbolChannels != nil {
@@ -283,6 +448,12 @@ func (environ *Environment) ConfigureNotificationRouting(conf *NotificationConfi
 		environ.SessionChannelRouter.AddRoute(conf.SessionChannels)
 	}
 
+	if len(conf.Rules) > 0 {
+		if err := environ.configureNotificationRules(conf.Rules); err != nil {
+			return err
+		}
+	}
+
 	if conf.Routing != nil {
 		// configure passive object notification routing
 		switch conf.Routing.Trade {
@@ -399,6 +570,49 @@ func (environ *Environment) ConfigureNotificationRouting(conf *NotificationConfi
 
 		}
 
+		switch conf.Routing.Position {
+
+		case "$silent": // silent, do not setup notification
+
+		case "$session":
+			for name := range environ.sessions {
+				session := environ.sessions[name]
+
+				channel, ok := environ.SessionChannelRouter.Route(name)
+				session.OnPositionUpdate(func(position *types.Position) {
+					if ok {
+						environ.NotifyTo(channel, position.Snapshot())
+					} else {
+						environ.Notify(position.Snapshot())
+					}
+				})
+			}
+
+		case "$symbol":
+			environ.ObjectChannelRouter.Route(func(obj interface{}) (channel string, ok bool) {
+				position, matched := obj.(*types.Position)
+				if !matched {
+					return
+				}
+
+				channel, ok = environ.SymbolChannelRouter.Route(position.Symbol)
+				return
+			})
+
+			handler := func(position *types.Position) {
+				snapshot := position.Snapshot()
+				channel, ok := environ.RouteObject(snapshot)
+				if ok {
+					environ.NotifyTo(channel, snapshot)
+				} else {
+					environ.Notify(snapshot)
+				}
+			}
+			for _, session := range environ.sessions {
+				session.OnPositionUpdate(handler)
+			}
+		}
+
 		// currently, not used
 		// FIXME: this is causing cyclic import
 		/*
@@ -436,6 +650,14 @@ func (environ *Environment) Connect(ctx context.Context) error {
 		var session = environ.sessions[n]
 		var logger = log.WithField("session", n)
 
+		if environ.HealthChecker != nil {
+			environ.HealthChecker.BindSession(session)
+		}
+
+		if environ.EventFeed != nil {
+			environ.EventFeed.BindSession(session)
+		}
+
 		if len(session.Subscriptions) == 0 {
 			logger.Warnf("exchange session %s has no subscriptions", session.Name)
 		} else {
@@ -457,6 +679,11 @@ func (environ *Environment) Connect(ctx context.Context) error {
 				return err
 			}
 		}
+
+		if session.marginMonitor != nil {
+			logger.Infof("starting %s margin monitor...", session.Name)
+			go session.marginMonitor.Run(ctx)
+		}
 	}
 
 	return nil
@@ -511,7 +738,7 @@ func (environ *Environment) SyncSession(ctx context.Context, session *ExchangeSe
 }
 
 func (environ *Environment) syncSession(ctx context.Context, session *ExchangeSession, defaultSymbols ...string) error {
-	symbols, err := getSessionSymbols(session, defaultSymbols...)
+	symbols, err := getSessionSymbols(ctx, session, defaultSymbols...)
 	if err != nil {
 		return err
 	}
@@ -521,7 +748,7 @@ func (environ *Environment) syncSession(ctx context.Context, session *ExchangeSe
 	return environ.SyncService.SyncSessionSymbols(ctx, session.Exchange, environ.syncStartTime, symbols...)
 }
 
-func getSessionSymbols(session *ExchangeSession, defaultSymbols ...string) ([]string, error) {
+func getSessionSymbols(ctx context.Context, session *ExchangeSession, defaultSymbols ...string) ([]string, error) {
 	if session.IsolatedMargin {
 		return []string{session.IsolatedMarginSymbol}, nil
 	}
@@ -530,7 +757,112 @@ func getSessionSymbols(session *ExchangeSession, defaultSymbols ...string) ([]st
 		return defaultSymbols, nil
 	}
 
-	return session.FindPossibleSymbols()
+	return session.FindPossibleSymbols(ctx)
+}
+
+// registerSlackActions registers the handlers of the slack message buttons, the actions are queued as the commands
+func (environ *Environment) registerSlackActions(interaction *slacknotifier.InteractionHandler) {
+	interaction.OnAction(types.SlackActionCancelAllOrders, func(user slack.User, action slack.BlockAction) (string, error) {
+		// the command queue is configured with the database, which could be configured after the notification system
+		if environ.CommandQueue == nil {
+			return "", errors.New("command queue is not configured, the database is required")
+		}
+
+		command, err := environ.CommandQueue.Enqueue(types.CommandTypeCancelAllOrders, "", types.CancelAllOrdersCommandPayload{
+			Symbol: action.Value,
+		}, "slack")
+		if err != nil {
+			return "", err
+		}
+
+		log.Infof("slack user %s queued the command %d %s %s", user.Name, command.GID, command.Type, action.Value)
+		return fmt.Sprintf(":white_check_mark: command %d %s %s is queued by <@%s>", command.GID, command.Type, action.Value, user.ID), nil
+	})
+}
+
+func (environ *Environment) configureDiscordNotifier(userConfig *Config) error {
+	var conf = &DiscordNotification{}
+	if userConfig.Notifications != nil && userConfig.Notifications.Discord != nil {
+		conf = userConfig.Notifications.Discord
+	}
+
+	webhookURL := viper.GetString("discord-webhook-url")
+	if len(webhookURL) == 0 {
+		webhookURL = conf.WebhookURL
+	}
+
+	if len(webhookURL) == 0 && len(conf.Channels) == 0 && len(conf.ErrorWebhookURL) == 0 {
+		return nil
+	}
+
+	if err := conf.Validate(); err != nil {
+		return err
+	}
+
+	log.Debugf("adding discord notifier with %d mapped channels", len(conf.Channels))
+
+	var notifier = discordnotifier.New(webhookURL,
+		discordnotifier.WithChannels(conf.Channels),
+		discordnotifier.WithUsername(conf.Username))
+
+	if len(conf.ErrorWebhookURL) > 0 {
+		log.Debugf("found discord error webhook configured, setting up log hook...")
+		log.AddHook(discordnotifier.NewLogHook(notifier, conf.ErrorWebhookURL))
+	}
+
+	environ.AddNamedNotifier("discord", notifier)
+	return nil
+}
+
+func (environ *Environment) configureWebhookNotifiers(configs []WebhookNotification) error {
+	for i := range configs {
+		if err := configs[i].Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, config := range configs {
+		notifier, err := webhooknotifier.New(webhooknotifier.Config{
+			URL:       config.URL,
+			Events:    config.Events,
+			Headers:   config.Headers,
+			Template:  config.Template,
+			Templates: config.Templates,
+			Timeout:   time.Duration(config.Timeout),
+		})
+		if err != nil {
+			return err
+		}
+
+		if hasWebhookEvent(config.Events, webhooknotifier.EventError) {
+			log.AddHook(webhooknotifier.NewLogHook(notifier))
+		}
+
+		name := config.Name
+		if len(name) == 0 {
+			name = "webhook"
+		}
+
+		log.Debugf("adding webhook notifier %s: %s", name, config.URL)
+		environ.AddNamedNotifier(name, notifier)
+	}
+
+	return nil
+}
+
+// hasWebhookEvent returns true if the event is enabled, all the events are enabled if the events are not specified
+func hasWebhookEvent(events []webhooknotifier.Event, event webhooknotifier.Event) bool {
+	if len(events) == 0 {
+		return true
+	}
+
+	for _, e := range events {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (environ *Environment) ConfigureNotificationSystem(userConfig *Config) error {
@@ -540,6 +872,11 @@ func (environ *Environment) ConfigureNotificationSystem(userConfig *Config) erro
 		ObjectChannelRouter:  NewObjectChannelRouter(),
 	}
 
+	if environ.EventFeed != nil {
+		// the event feed is not named, so the notification rules never filter the events of the feed
+		environ.AddNotifier(environ.EventFeed)
+	}
+
 	slackToken := viper.GetString("slack-token")
 	if len(slackToken) > 0 && userConfig.Notifications != nil {
 		if conf := userConfig.Notifications.Slack; conf != nil {
@@ -550,12 +887,36 @@ func (environ *Environment) ConfigureNotificationSystem(userConfig *Config) erro
 
 			log.Debugf("adding slack notifier with default channel: %s", conf.DefaultChannel)
 			var notifier = slacknotifier.New(slackToken, conf.DefaultChannel)
-			environ.AddNotifier(notifier)
+			environ.AddNamedNotifier("slack", notifier)
+
+			if conf.SigningSecret != "" {
+				environ.SlackInteraction = slacknotifier.NewInteractionHandler(conf.SigningSecret, conf.AllowedUsers)
+				environ.registerSlackActions(environ.SlackInteraction)
+			}
+		}
+	}
+
+	if err := environ.configureDiscordNotifier(userConfig); err != nil {
+		return err
+	}
+
+	if userConfig.Notifications != nil {
+		if err := environ.configureWebhookNotifiers(userConfig.Notifications.Webhooks); err != nil {
+			return err
 		}
 	}
 
+	var telegramConfig = &TelegramNotification{}
+	if userConfig.Notifications != nil && userConfig.Notifications.Telegram != nil {
+		telegramConfig = userConfig.Notifications.Telegram
+	}
+
 	persistence := environ.PersistenceServiceFacade.Get()
 	telegramBotToken := viper.GetString("telegram-bot-token")
+	if len(telegramBotToken) == 0 {
+		telegramBotToken = telegramConfig.BotToken
+	}
+
 	if len(telegramBotToken) > 0 {
 		tt := strings.Split(telegramBotToken, ":")
 		telegramID := tt[0]
@@ -577,6 +938,10 @@ func (environ *Environment) ConfigureNotificationSystem(userConfig *Config) erro
 		var interaction = telegramnotifier.NewInteraction(bot, sessionStore)
 
 		authToken := viper.GetString("telegram-bot-auth-token")
+		if len(authToken) == 0 {
+			authToken = telegramConfig.AuthToken
+		}
+
 		if len(authToken) > 0 {
 			interaction.SetAuthToken(authToken)
 
@@ -611,13 +976,20 @@ func (environ *Environment) ConfigureNotificationSystem(userConfig *Config) erro
 
 		var opts []telegramnotifier.Option
 
-		if userConfig.Notifications != nil && userConfig.Notifications.Telegram != nil {
+		if telegramConfig.Broadcast {
 			log.Infof("telegram broadcast is enabled")
 			opts = append(opts, telegramnotifier.UseBroadcast())
 		}
 
+		if telegramConfig.ErrorLogs {
+			log.Infof("telegram error logs are enabled")
+			log.AddHook(telegramnotifier.NewLogHook(interaction, telegramConfig.Broadcast))
+		}
+
+		environ.registerTelegramCommands(interaction)
+
 		var notifier = telegramnotifier.New(interaction, opts...)
-		environ.Notifiability.AddNotifier(notifier)
+		environ.Notifiability.AddNamedNotifier("telegram", notifier)
 	}
 
 	if userConfig.Notifications == nil {
@@ -638,6 +1010,305 @@ func (environ *Environment) ConfigureNotificationSystem(userConfig *Config) erro
 	return nil
 }
 
+// ConfigureOrderWebhooks binds the order webhooks to the order executors of the sessions,
+// the webhooks deliver the order events until the context is canceled.
+func (environ *Environment) ConfigureOrderWebhooks(ctx context.Context, configs []OrderWebhookConfig) error {
+	for i := range configs {
+		if err := configs[i].Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, config := range configs {
+		webhook := NewOrderWebhook(config)
+		for _, session := range environ.sessions {
+			webhook.BindSession(session)
+		}
+
+		log.Infof("order webhook %s is configured", config.URL)
+		go webhook.Run(ctx)
+	}
+
+	return nil
+}
+
+// ConfigureEventPublishers binds the event publishers to the sessions and the notification system,
+// the publishers mirror the events to the message buses until the context is canceled.
+func (environ *Environment) ConfigureEventPublishers(ctx context.Context, configs []EventPublisherConfig) error {
+	for i := range configs {
+		if err := configs[i].Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, config := range configs {
+		publisher, err := config.NewPublisher()
+		if err != nil {
+			return err
+		}
+
+		eventPublisher := NewEventPublisher(config, publisher)
+		for _, session := range environ.sessions {
+			eventPublisher.BindSession(session)
+		}
+
+		environ.Notifiability.AddNotifier(eventPublisher)
+
+		log.Infof("event publisher %s is configured", eventPublisher.Config.TopicPrefix)
+		go eventPublisher.Run(ctx)
+	}
+
+	return nil
+}
+
+// ConfigureIndexPrices creates the index price services from the ticker of the sessions,
+// the index prices are updated until the context is canceled.
+func (environ *Environment) ConfigureIndexPrices(ctx context.Context, configs []IndexPriceConfig) error {
+	for i := range configs {
+		if err := configs[i].Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, config := range configs {
+		if _, ok := environ.indexPrices[config.Symbol]; ok {
+			return fmt.Errorf("duplicated index price config of symbol %s", config.Symbol)
+		}
+
+		indexPrice := NewIndexPriceService(config)
+		for name, session := range environ.SelectSessions(config.Sessions...) {
+			indexPrice.AddSource(name, session.Exchange)
+		}
+
+		if len(indexPrice.sources) == 0 {
+			return fmt.Errorf("no session found for the %s index price", config.Symbol)
+		}
+
+		environ.indexPrices[config.Symbol] = indexPrice
+
+		log.Infof("%s index price is configured with %d sessions", config.Symbol, len(indexPrice.sources))
+		go indexPrice.Run(ctx)
+	}
+
+	return nil
+}
+
+// IndexPrice returns the index price service of the symbol
+func (environ *Environment) IndexPrice(symbol string) (*IndexPriceService, bool) {
+	indexPrice, ok := environ.indexPrices[symbol]
+	return indexPrice, ok
+}
+
+// registerTelegramCommands registers the telegram commands, the query commands reply directly,
+// and the control commands are enqueued to the command queue if it's configured
+func (environ *Environment) registerTelegramCommands(interaction *telegramnotifier.Interaction) {
+	// /balance [session]
+	interaction.Command("/balance", func(m *telebot.Message) {
+		if !interaction.IsOwner(m) {
+			log.Warnf("incorrect user tried to query the balances! sender: %+v", m.Sender)
+			return
+		}
+
+		interaction.Reply(m, formatSessionBalances(context.Background(), environ.SelectSessions(strings.Fields(m.Payload)...), environ.currencyConverter))
+	})
+
+	// /position [session] [symbol]
+	interaction.Command("/position", func(m *telebot.Message) {
+		if !interaction.IsOwner(m) {
+			log.Warnf("incorrect user tried to query the positions! sender: %+v", m.Sender)
+			return
+		}
+
+		var sessions = environ.Sessions()
+		var symbol string
+		args := strings.Fields(m.Payload)
+		if len(args) > 0 {
+			sessions = environ.SelectSessions(args[0])
+		}
+		if len(args) > 1 {
+			symbol = args[1]
+		}
+
+		interaction.Reply(m, formatSessionPositions(sessions, symbol))
+	})
+
+	if environ.CommandQueue == nil {
+		return
+	}
+
+	enqueue := func(m *telebot.Message, commandType types.CommandType, strategy string, payload interface{}) {
+		if !interaction.IsOwner(m) {
+			log.Warnf("incorrect user tried to issue the command %s! sender: %+v", commandType, m.Sender)
+			return
+		}
+
+		command, err := environ.CommandQueue.Enqueue(commandType, strategy, payload, "telegram")
+		if err != nil {
+			interaction.Reply(m, fmt.Sprintf("failed to queue the command: %s", err.Error()))
+			return
+		}
+
+		interaction.Reply(m, fmt.Sprintf("command %d %s is queued", command.GID, commandType))
+	}
+
+	interaction.Command("/pause", func(m *telebot.Message) {
+		enqueue(m, types.CommandTypePauseStrategy, strings.TrimSpace(m.Payload), nil)
+	})
+
+	interaction.Command("/suspend", func(m *telebot.Message) {
+		enqueue(m, types.CommandTypeSuspendStrategy, strings.TrimSpace(m.Payload), nil)
+	})
+
+	interaction.Command("/resume", func(m *telebot.Message) {
+		enqueue(m, types.CommandTypeResumeStrategy, strings.TrimSpace(m.Payload), nil)
+	})
+
+	interaction.Command("/resetkillswitch", func(m *telebot.Message) {
+		enqueue(m, types.CommandTypeResetKillSwitch, "", nil)
+	})
+
+	// /cancel binance BTCUSDT 12345
+	interaction.Command("/cancel", func(m *telebot.Message) {
+		args := strings.Fields(m.Payload)
+		if len(args) != 3 {
+			interaction.Reply(m, "usage: /cancel [session] [symbol] [order id]")
+			return
+		}
+
+		orderID, err := strconv.ParseUint(args[2], 10, 64)
+		if err != nil {
+			interaction.Reply(m, fmt.Sprintf("invalid order id %s", args[2]))
+			return
+		}
+
+		enqueue(m, types.CommandTypeCancelOrder, "", types.CancelOrderCommandPayload{
+			Session: args[0],
+			Symbol:  args[1],
+			OrderID: orderID,
+		})
+	})
+
+	// /set grid:BTCUSDT quantity 0.01
+	interaction.Command("/set", func(m *telebot.Message) {
+		args := strings.Fields(m.Payload)
+		if len(args) != 3 {
+			interaction.Reply(m, "usage: /set [strategy] [parameter] [json value]")
+			return
+		}
+
+		enqueue(m, types.CommandTypeSetParameter, args[0], types.SetParameterCommandPayload{
+			Parameter: args[1],
+			Value:     json.RawMessage(args[2]),
+		})
+	})
+
+	interaction.Command("/halt", func(m *telebot.Message) {
+		enqueue(m, types.CommandTypeHalt, "", nil)
+	})
+
+	// /closeposition binance BTCUSDT 0.5
+	interaction.Command("/closeposition", func(m *telebot.Message) {
+		args := strings.Fields(m.Payload)
+		if len(args) != 2 && len(args) != 3 {
+			interaction.Reply(m, "usage: /closeposition [session] [symbol] [percentage]")
+			return
+		}
+
+		payload := types.ClosePositionCommandPayload{
+			Session: args[0],
+			Symbol:  args[1],
+		}
+
+		if len(args) == 3 {
+			percentage, err := strconv.ParseFloat(args[2], 64)
+			if err != nil || percentage <= 0 || percentage > 1.0 {
+				interaction.Reply(m, fmt.Sprintf("invalid percentage %s, it should be in (0, 1]", args[2]))
+				return
+			}
+
+			payload.Percentage = percentage
+		}
+
+		enqueue(m, types.CommandTypeClosePosition, "", payload)
+	})
+}
+
+// formatSessionBalances formats the non-zero balances of the sessions, sorted by the session name and the currency.
+// The total value of the session in the reference currency is appended if the converter is given.
+func formatSessionBalances(ctx context.Context, sessions map[string]*ExchangeSession, converter *CurrencyConverter) string {
+	if len(sessions) == 0 {
+		return "no session found"
+	}
+
+	var sb strings.Builder
+	for _, name := range sortedSessionNames(sessions) {
+		balances := sessions[name].Account.Balances()
+
+		var currencies []string
+		for currency, balance := range balances {
+			if balance.Total() > 0 {
+				currencies = append(currencies, currency)
+			}
+		}
+		sort.Strings(currencies)
+
+		sb.WriteString(fmt.Sprintf("%s balances:\n", name))
+		if len(currencies) == 0 {
+			sb.WriteString("  (empty)\n")
+		}
+
+		for _, currency := range currencies {
+			sb.WriteString("  " + balances[currency].String() + "\n")
+		}
+
+		if converter != nil && len(currencies) > 0 {
+			value, missing := ValueBalances(ctx, converter, balances, converter.Currency)
+			sb.WriteString(fmt.Sprintf("  total: %f %s", value, converter.Currency))
+			if len(missing) > 0 {
+				sb.WriteString(fmt.Sprintf(" (excluding %s)", strings.Join(missing, ", ")))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// formatSessionPositions formats the non-empty positions of the sessions, all the symbols are included if symbol is empty
+func formatSessionPositions(sessions map[string]*ExchangeSession, symbol string) string {
+	var texts []string
+	for _, name := range sortedSessionNames(sessions) {
+		positions := sessions[name].Positions()
+
+		var symbols []string
+		for s, position := range positions {
+			if (symbol == "" || s == symbol) && position.Snapshot().Base != 0 {
+				symbols = append(symbols, s)
+			}
+		}
+		sort.Strings(symbols)
+
+		for _, s := range symbols {
+			texts = append(texts, name+" "+positions[s].Snapshot().PlainText())
+		}
+	}
+
+	if len(texts) == 0 {
+		return "no position found"
+	}
+
+	return strings.Join(texts, "\n")
+}
+
+func sortedSessionNames(sessions map[string]*ExchangeSession) []string {
+	var names []string
+	for name := range sessions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func writeOTPKeyAsQRCodePNG(key *otp.Key, imagePath string) error {
 	// Convert TOTP key into a PNG
 	var buf bytes.Buffer
@@ -0,0 +1,49 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func Test_formatSessionBalancesAndPositions(t *testing.T) {
+	binance := newKillSwitchTestSession(&killSwitchTestExchange{})
+	binance.Account = types.NewAccount()
+	binance.Account.UpdateBalances(types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(1000.0), Locked: fixedpoint.NewFromFloat(200.0)},
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(0.5)},
+		"ETH":  {Currency: "ETH"},
+	})
+
+	maxSession := newKillSwitchTestSession(&killSwitchTestExchange{})
+	maxSession.Name = "max"
+	maxSession.Account = types.NewAccount()
+	maxSession.positions = map[string]*types.Position{}
+
+	sessions := map[string]*ExchangeSession{"binance": binance, "max": maxSession}
+	assert.Equal(t, "binance balances:\n"+
+		"  BTC: 0.500000\n"+
+		"  USDT: 1000.000000 (locked 200.000000)\n"+
+		"max balances:\n"+
+		"  (empty)", formatSessionBalances(context.Background(), sessions, nil))
+	assert.Equal(t, "no session found", formatSessionBalances(context.Background(), nil, nil))
+
+	// the total value is appended with the converter, the assets without the rate are excluded
+	binance.Account.UpdateBalances(types.BalanceMap{"ETH": {Currency: "ETH", Available: fixedpoint.NewFromFloat(1.0)}})
+	converter := NewCurrencyConverter(CurrencyConversionConfig{}, nil)
+	converter.updateTime = time.Now()
+	converter.rates.Add("BTC", "USDT", 50000.0)
+	assert.Equal(t, "binance balances:\n"+
+		"  BTC: 0.500000\n"+
+		"  ETH: 1.000000\n"+
+		"  USDT: 1000.000000 (locked 200.000000)\n"+
+		"  total: 26200.000000 USDT (excluding ETH)", formatSessionBalances(context.Background(), map[string]*ExchangeSession{"binance": binance}, converter))
+
+	assert.Contains(t, formatSessionPositions(sessions, ""), "binance Position BTCUSDT")
+	assert.Equal(t, "no position found", formatSessionPositions(sessions, "ETHUSDT"))
+}
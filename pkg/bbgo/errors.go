@@ -4,3 +4,4 @@ import "errors"
 
 var ErrSessionAlreadyInitialized = errors.New("session is already initialized")
 
+var ErrSessionBootstrapping = errors.New("session is feeding the historical data, orders are not allowed")
@@ -0,0 +1,236 @@
+package bbgo
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const (
+	EventTypePosition     EventType = "position"
+	EventTypeNotification EventType = "notification"
+)
+
+const defaultEventFeedBufferSize = 1024
+
+// EventNotification is the normalized notification of the event feed
+type EventNotification struct {
+	Channel string `json:"channel,omitempty"`
+	Text    string `json:"text"`
+}
+
+// EventFeed rebroadcasts the order updates, the trades, the position changes and the notifications
+// to the in-process subscribers, e.g., the websocket clients of the http server.
+// The events are the same EventMessage objects published to the message buses.
+type EventFeed struct {
+	mu            sync.Mutex
+	subscriptions map[*EventSubscription]struct{}
+	sequence      int64
+
+	// now is used for the testing
+	now func() time.Time
+}
+
+func NewEventFeed() *EventFeed {
+	return &EventFeed{
+		subscriptions: make(map[*EventSubscription]struct{}),
+		now:           time.Now,
+	}
+}
+
+// EventSubscription receives the events of the feed until it's closed.
+// The events are dropped if the subscriber is too slow to consume the channel.
+type EventSubscription struct {
+	C <-chan EventMessage
+
+	c        chan EventMessage
+	events   []EventType
+	sessions []string
+	feed     *EventFeed
+	once     sync.Once
+	dropped  int64
+}
+
+// Subscribe subscribes the events of the given types and sessions, empty filters mean all events and all sessions
+func (f *EventFeed) Subscribe(events []EventType, sessions []string) *EventSubscription {
+	c := make(chan EventMessage, defaultEventFeedBufferSize)
+	sub := &EventSubscription{
+		C:        c,
+		c:        c,
+		events:   events,
+		sessions: sessions,
+		feed:     f,
+	}
+
+	f.mu.Lock()
+	f.subscriptions[sub] = struct{}{}
+	f.mu.Unlock()
+	return sub
+}
+
+// Close removes the subscription from the feed
+func (s *EventSubscription) Close() {
+	s.once.Do(func() {
+		s.feed.mu.Lock()
+		delete(s.feed.subscriptions, s)
+		s.feed.mu.Unlock()
+	})
+}
+
+// Dropped returns the number of the dropped events of the subscription
+func (s *EventSubscription) Dropped() int64 {
+	return atomic.LoadInt64(&s.dropped)
+}
+
+func (s *EventSubscription) accept(message EventMessage) bool {
+	if len(s.events) > 0 {
+		var found = false
+		for _, e := range s.events {
+			if e == message.Event {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	// the events without session, e.g., the notifications, are sent to all subscribers
+	if len(s.sessions) > 0 && len(message.Session) > 0 {
+		for _, name := range s.sessions {
+			if name == message.Session {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return true
+}
+
+// NumOfSubscriptions returns the number of the active subscriptions
+func (f *EventFeed) NumOfSubscriptions() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.subscriptions)
+}
+
+// BindSession rebroadcasts the order updates and the trades of the user data stream and the position changes of the session
+func (f *EventFeed) BindSession(session *ExchangeSession) {
+	sessionName := session.Name
+
+	if session.UserDataStream != nil {
+		session.UserDataStream.OnTradeUpdate(func(trade types.Trade) {
+			f.Emit(EventTypeTrade, sessionName, trade)
+		})
+
+		session.UserDataStream.OnOrderUpdate(func(order types.Order) {
+			f.Emit(EventTypeOrder, sessionName, order)
+		})
+	}
+
+	session.OnPositionUpdate(func(position *types.Position) {
+		// the position is still updated by the stream, so we send the snapshot of it
+		f.Emit(EventTypePosition, sessionName, position.Snapshot())
+	})
+}
+
+// Notify implements the Notifier interface, the PnL objects are sent as the pnl events and
+// the other notifications are sent as the text notification events
+func (f *EventFeed) Notify(obj interface{}, args ...interface{}) {
+	f.NotifyTo("", obj, args...)
+}
+
+func (f *EventFeed) NotifyTo(channel string, obj interface{}, args ...interface{}) {
+	switch o := obj.(type) {
+	case Profit:
+		f.Emit(EventTypePnL, "", o)
+		return
+	case *Profit:
+		f.Emit(EventTypePnL, "", o)
+		return
+	}
+
+	text, ok := notificationText(obj, args...)
+	if !ok {
+		return
+	}
+
+	f.Emit(EventTypeNotification, "", EventNotification{Channel: channel, Text: text})
+}
+
+// Emit sends the event to the subscribers
+func (f *EventFeed) Emit(event EventType, session string, data interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.subscriptions) == 0 {
+		return
+	}
+
+	message := EventMessage{
+		ID:       uuid.New().String(),
+		Sequence: atomic.AddInt64(&f.sequence, 1),
+		Event:    event,
+		Session:  session,
+		Time:     f.now(),
+		Data:     data,
+	}
+
+	for sub := range f.subscriptions {
+		if !sub.accept(message) {
+			continue
+		}
+
+		select {
+		case sub.c <- message:
+		default:
+			if atomic.AddInt64(&sub.dropped, 1) == 1 {
+				log.Warnf("event feed subscriber is too slow, event %s %s dropped", message.Event, message.ID)
+			}
+		}
+	}
+}
+
+// notificationText formats the notification as the plain text, the string notification is formatted with
+// the arguments before the first text object, just like the telegram notifier.
+func notificationText(obj interface{}, args ...interface{}) (string, bool) {
+	switch a := obj.(type) {
+	case string:
+		pureArgs := args
+		for idx, arg := range args {
+			if isTextObject(arg) {
+				pureArgs = args[:idx]
+				break
+			}
+		}
+
+		return fmt.Sprintf(a, pureArgs...), true
+
+	case types.PlainText:
+		return a.PlainText(), true
+
+	case types.Stringer:
+		return a.String(), true
+	}
+
+	return "", false
+}
+
+func isTextObject(obj interface{}) bool {
+	switch obj.(type) {
+	case types.PlainText, types.Stringer:
+		return true
+	}
+
+	return false
+}
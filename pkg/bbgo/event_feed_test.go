@@ -0,0 +1,99 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func receiveEvents(sub *EventSubscription) (messages []EventMessage) {
+	for {
+		select {
+		case message := <-sub.C:
+			messages = append(messages, message)
+		default:
+			return messages
+		}
+	}
+}
+
+func TestEventFeed_BindSession(t *testing.T) {
+	userDataStream := &bootstrapTestStream{StandardStream: types.NewStandardStream()}
+	session := &ExchangeSession{
+		Name:           "binance",
+		UserDataStream: userDataStream,
+	}
+
+	feed := NewEventFeed()
+	feed.BindSession(session)
+
+	all := feed.Subscribe(nil, nil)
+	trades := feed.Subscribe([]EventType{EventTypeTrade}, nil)
+	others := feed.Subscribe(nil, []string{"max"})
+	assert.Equal(t, 3, feed.NumOfSubscriptions())
+
+	userDataStream.EmitOrderUpdate(types.Order{OrderID: 1, Status: types.OrderStatusNew})
+	userDataStream.EmitTradeUpdate(types.Trade{ID: 1, Symbol: "BTCUSDT"})
+
+	position := types.NewPosition("BTCUSDT", "BTC", "USDT")
+	position.Base = fixedpoint.NewFromFloat(0.5)
+	session.EmitPositionUpdate(position)
+
+	messages := receiveEvents(all)
+	if assert.Len(t, messages, 3) {
+		assert.Equal(t, EventTypeOrder, messages[0].Event)
+		assert.Equal(t, EventTypeTrade, messages[1].Event)
+		assert.Equal(t, EventTypePosition, messages[2].Event)
+		assert.Equal(t, "binance", messages[2].Session)
+		assert.Equal(t, int64(3), messages[2].Sequence)
+		assert.NotSame(t, position, messages[2].Data, "the position snapshot is sent")
+	}
+
+	if messages := receiveEvents(trades); assert.Len(t, messages, 1) {
+		assert.Equal(t, EventTypeTrade, messages[0].Event)
+	}
+
+	assert.Len(t, receiveEvents(others), 0)
+
+	trades.Close()
+	trades.Close()
+	assert.Equal(t, 2, feed.NumOfSubscriptions())
+}
+
+func TestEventFeed_Notify(t *testing.T) {
+	feed := NewEventFeed()
+
+	// the events are not created without the subscribers
+	feed.Notify("no one is listening")
+
+	sub := feed.Subscribe(nil, []string{"binance"})
+	feed.Notify("order %d is filled", 1, types.Order{OrderID: 1})
+	feed.NotifyTo("#alerts", "kill switch is triggered")
+	feed.Notify(Profit{Symbol: "BTCUSDT"})
+	feed.Notify(123)
+
+	messages := receiveEvents(sub)
+	if assert.Len(t, messages, 3) {
+		assert.Equal(t, EventTypeNotification, messages[0].Event)
+		assert.Equal(t, EventNotification{Text: "order 1 is filled"}, messages[0].Data)
+		assert.Equal(t, EventNotification{Channel: "#alerts", Text: "kill switch is triggered"}, messages[1].Data)
+		assert.Equal(t, EventTypePnL, messages[2].Event)
+		assert.Equal(t, int64(1), messages[0].Sequence)
+	}
+}
+
+func TestEventFeed_SlowSubscriber(t *testing.T) {
+	feed := NewEventFeed()
+	sub := feed.Subscribe(nil, nil)
+	defer sub.Close()
+
+	for i := 0; i < defaultEventFeedBufferSize+10; i++ {
+		feed.Emit(EventTypeTrade, "binance", types.Trade{ID: int64(i)})
+	}
+
+	assert.Equal(t, int64(10), sub.Dropped())
+	assert.Len(t, receiveEvents(sub), defaultEventFeedBufferSize)
+}
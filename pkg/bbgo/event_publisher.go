@@ -0,0 +1,263 @@
+package bbgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/pubsub"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// EventType is the type of the events mirrored to the external message buses
+type EventType string
+
+const (
+	EventTypeTrade EventType = "trade"
+	EventTypeOrder EventType = "order"
+	EventTypeKLine EventType = "kline"
+	EventTypePnL   EventType = "pnl"
+)
+
+const (
+	defaultEventTopicPrefix        = "bbgo"
+	defaultEventPublisherQueueSize = 4096
+)
+
+type EventPublisherConfig struct {
+	Redis *pubsub.RedisConfig     `json:"redis,omitempty" yaml:"redis,omitempty"`
+	NATS  *pubsub.NATSConfig      `json:"nats,omitempty" yaml:"nats,omitempty"`
+	Kafka *pubsub.KafkaRestConfig `json:"kafka,omitempty" yaml:"kafka,omitempty"`
+
+	// TopicPrefix is prepended to the event type as the topic, e.g., bbgo.trade, default to bbgo
+	TopicPrefix string `json:"topicPrefix,omitempty" yaml:"topicPrefix,omitempty"`
+
+	// Events filters the event types to publish, empty means all events
+	Events []EventType `json:"events,omitempty" yaml:"events,omitempty"`
+
+	// Sessions filters the exchange sessions, empty means all sessions
+	Sessions []string `json:"sessions,omitempty" yaml:"sessions,omitempty"`
+}
+
+func (c *EventPublisherConfig) Validate() error {
+	var drivers int
+	if c.Redis != nil {
+		drivers++
+	}
+	if c.NATS != nil {
+		drivers++
+		if len(c.NATS.URL) == 0 {
+			return errors.New("event publisher nats url is required")
+		}
+	}
+	if c.Kafka != nil {
+		drivers++
+		if len(c.Kafka.URL) == 0 {
+			return errors.New("event publisher kafka url is required")
+		}
+	}
+
+	if drivers != 1 {
+		return errors.New("event publisher requires exactly one of redis, nats or kafka")
+	}
+
+	for _, event := range c.Events {
+		switch event {
+		case EventTypeTrade, EventTypeOrder, EventTypeKLine, EventTypePnL:
+		default:
+			return fmt.Errorf("unsupported event publisher event %q", event)
+		}
+	}
+
+	return nil
+}
+
+// NewPublisher creates the publisher of the configured message bus
+func (c *EventPublisherConfig) NewPublisher() (pubsub.Publisher, error) {
+	switch {
+	case c.Redis != nil:
+		return pubsub.NewRedisStreamPublisher(*c.Redis), nil
+	case c.NATS != nil:
+		return pubsub.NewNATSPublisher(*c.NATS), nil
+	case c.Kafka != nil:
+		return pubsub.NewKafkaRestPublisher(*c.Kafka), nil
+	}
+
+	return nil, errors.New("event publisher driver is not configured")
+}
+
+// EventMessage is the json message published to the message bus
+type EventMessage struct {
+	// ID is the unique id of the event, consumers can use it to drop the duplicated messages
+	ID string `json:"id"`
+
+	// Sequence is increased on every event of the publisher
+	Sequence int64 `json:"sequence"`
+
+	Event   EventType `json:"event"`
+	Session string    `json:"session,omitempty"`
+	Time    time.Time `json:"time"`
+
+	Data interface{} `json:"data"`
+}
+
+// EventPublisher mirrors the trades, the orders, the closed klines and the PnL notifications to the message bus.
+// The events are published by a background worker, so the trading is never blocked by the message bus.
+type EventPublisher struct {
+	Config EventPublisherConfig
+
+	publisher pubsub.Publisher
+	queue     chan EventMessage
+	sequence  int64
+
+	// now is used for the testing
+	now func() time.Time
+}
+
+func NewEventPublisher(config EventPublisherConfig, publisher pubsub.Publisher) *EventPublisher {
+	if len(config.TopicPrefix) == 0 {
+		config.TopicPrefix = defaultEventTopicPrefix
+	}
+
+	return &EventPublisher{
+		Config:    config,
+		publisher: publisher,
+		queue:     make(chan EventMessage, defaultEventPublisherQueueSize),
+		now:       time.Now,
+	}
+}
+
+// Topic returns the topic of the event type
+func (p *EventPublisher) Topic(event EventType) string {
+	return p.Config.TopicPrefix + "." + string(event)
+}
+
+// Run publishes the queued events until the context is canceled
+func (p *EventPublisher) Run(ctx context.Context) {
+	defer func() {
+		if err := p.publisher.Close(); err != nil {
+			log.WithError(err).Errorf("event publisher close error")
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case message := <-p.queue:
+			if err := p.publish(ctx, message); err != nil {
+				log.WithError(err).Errorf("event publisher error, event %s %s dropped", message.Event, message.ID)
+			}
+		}
+	}
+}
+
+func (p *EventPublisher) publish(ctx context.Context, message EventMessage) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	return p.publisher.Publish(ctx, p.Topic(message.Event), body)
+}
+
+// BindSession publishes the trades and the orders of the user data stream and the closed klines of the market data stream
+func (p *EventPublisher) BindSession(session *ExchangeSession) {
+	if !p.acceptSession(session.Name) {
+		return
+	}
+
+	sessionName := session.Name
+
+	if session.UserDataStream != nil {
+		session.UserDataStream.OnTradeUpdate(func(trade types.Trade) {
+			p.Emit(EventTypeTrade, sessionName, trade)
+		})
+
+		session.UserDataStream.OnOrderUpdate(func(order types.Order) {
+			p.Emit(EventTypeOrder, sessionName, order)
+		})
+	}
+
+	if session.MarketDataStream != nil {
+		session.MarketDataStream.OnKLineClosed(func(kline types.KLine) {
+			// the historical klines fed at startup are not the live data
+			if session.IsBootstrapping() {
+				return
+			}
+
+			p.Emit(EventTypeKLine, sessionName, kline)
+		})
+	}
+}
+
+// Notify implements the Notifier interface, the PnL objects are published and the other notifications are ignored
+func (p *EventPublisher) Notify(obj interface{}, args ...interface{}) {
+	switch o := obj.(type) {
+	case Profit:
+		p.Emit(EventTypePnL, "", o)
+	case *Profit:
+		p.Emit(EventTypePnL, "", o)
+	}
+}
+
+func (p *EventPublisher) NotifyTo(channel string, obj interface{}, args ...interface{}) {
+	p.Notify(obj, args...)
+}
+
+// Emit queues the event, the event is dropped if the queue is full
+func (p *EventPublisher) Emit(event EventType, session string, data interface{}) {
+	if !p.acceptEvent(event) {
+		return
+	}
+
+	message := EventMessage{
+		ID:       uuid.New().String(),
+		Sequence: atomic.AddInt64(&p.sequence, 1),
+		Event:    event,
+		Session:  session,
+		Time:     p.now(),
+		Data:     data,
+	}
+
+	select {
+	case p.queue <- message:
+	default:
+		log.Errorf("event publisher queue is full, event %s %s dropped", message.Event, message.ID)
+	}
+}
+
+func (p *EventPublisher) acceptEvent(event EventType) bool {
+	if len(p.Config.Events) == 0 {
+		return true
+	}
+
+	for _, e := range p.Config.Events {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *EventPublisher) acceptSession(name string) bool {
+	if len(p.Config.Sessions) == 0 {
+		return true
+	}
+
+	for _, s := range p.Config.Sessions {
+		if s == name {
+			return true
+		}
+	}
+
+	return false
+}
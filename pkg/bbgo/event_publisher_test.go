@@ -0,0 +1,113 @@
+package bbgo
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/pubsub"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type testPublisher struct {
+	mu       sync.Mutex
+	topics   []string
+	messages []EventMessage
+	closed   bool
+}
+
+func (p *testPublisher) Publish(ctx context.Context, topic string, payload []byte) error {
+	var message EventMessage
+	if err := json.Unmarshal(payload, &message); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.topics = append(p.topics, topic)
+	p.messages = append(p.messages, message)
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *testPublisher) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *testPublisher) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.messages)
+}
+
+func TestEventPublisherConfig_Validate(t *testing.T) {
+	config := EventPublisherConfig{NATS: &pubsub.NATSConfig{URL: "nats://localhost:4222"}}
+	assert.NoError(t, config.Validate())
+
+	config.Redis = &pubsub.RedisConfig{Host: "localhost"}
+	assert.Error(t, config.Validate())
+
+	config = EventPublisherConfig{Kafka: &pubsub.KafkaRestConfig{URL: "http://localhost:8082"}, Events: []EventType{"balance"}}
+	assert.Error(t, config.Validate())
+
+	assert.Error(t, (&EventPublisherConfig{}).Validate())
+}
+
+func TestEventPublisher(t *testing.T) {
+	userDataStream := &bootstrapTestStream{StandardStream: types.NewStandardStream()}
+	marketDataStream := &bootstrapTestStream{StandardStream: types.NewStandardStream()}
+	session := &ExchangeSession{
+		Name:             "binance",
+		UserDataStream:   userDataStream,
+		MarketDataStream: marketDataStream,
+	}
+
+	publisher := &testPublisher{}
+	eventPublisher := NewEventPublisher(EventPublisherConfig{
+		Events: []EventType{EventTypeTrade, EventTypeKLine, EventTypePnL},
+	}, publisher)
+	eventPublisher.BindSession(session)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		eventPublisher.Run(ctx)
+		close(done)
+	}()
+
+	userDataStream.EmitTradeUpdate(types.Trade{ID: 1, Symbol: "BTCUSDT", Price: 100.0, Quantity: 1.0})
+
+	// the order events are filtered out
+	userDataStream.EmitOrderUpdate(types.Order{OrderID: 2})
+
+	// the historical klines are not published
+	session.setBootstrapping(true)
+	marketDataStream.EmitKLineClosed(types.KLine{Symbol: "BTCUSDT", Close: 99.0})
+	session.setBootstrapping(false)
+	marketDataStream.EmitKLineClosed(types.KLine{Symbol: "BTCUSDT", Close: 101.0})
+
+	eventPublisher.Notify("hello")
+	eventPublisher.Notify(&Profit{Symbol: "BTCUSDT", Profit: fixedpoint.NewFromFloat(1.5)})
+
+	assert.Eventually(t, func() bool { return publisher.Len() == 3 }, time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+
+	assert.True(t, publisher.closed)
+	assert.Equal(t, []string{"bbgo.trade", "bbgo.kline", "bbgo.pnl"}, publisher.topics)
+
+	assert.Equal(t, EventTypeTrade, publisher.messages[0].Event)
+	assert.Equal(t, "binance", publisher.messages[0].Session)
+	assert.Equal(t, int64(1), publisher.messages[0].Sequence)
+	assert.NotEmpty(t, publisher.messages[0].ID)
+	assert.Equal(t, 101.0, publisher.messages[1].Data.(map[string]interface{})["close"])
+	assert.Equal(t, "BTCUSDT", publisher.messages[2].Data.(map[string]interface{})["symbol"])
+}
@@ -25,3 +25,23 @@ func (e *ExchangeOrderExecutor) EmitOrderUpdate(order types.Order) {
 		cb(order)
 	}
 }
+
+func (e *ExchangeOrderExecutor) OnSubmitOrder(cb func(order types.SubmitOrder)) {
+	e.submitOrderCallbacks = append(e.submitOrderCallbacks, cb)
+}
+
+func (e *ExchangeOrderExecutor) EmitSubmitOrder(order types.SubmitOrder) {
+	for _, cb := range e.submitOrderCallbacks {
+		cb(order)
+	}
+}
+
+func (e *ExchangeOrderExecutor) OnSubmitOrderError(cb func(order types.SubmitOrder, err error)) {
+	e.submitOrderErrorCallbacks = append(e.submitOrderErrorCallbacks, cb)
+}
+
+func (e *ExchangeOrderExecutor) EmitSubmitOrderError(order types.SubmitOrder, err error) {
+	for _, cb := range e.submitOrderErrorCallbacks {
+		cb(order, err)
+	}
+}
@@ -0,0 +1,220 @@
+package bbgo
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type ExportFormat string
+
+const (
+	ExportFormatCSV       = ExportFormat("csv")
+	ExportFormatJSONLines = ExportFormat("jsonl")
+	ExportFormatParquet   = ExportFormat("parquet")
+)
+
+var TradeExportColumns = []string{
+	"time", "exchange", "symbol", "id", "order_id", "side", "price", "quantity", "quote_quantity",
+	"fee", "fee_currency", "is_maker", "is_buyer", "is_margin", "is_futures", "is_isolated", "strategy", "pnl",
+}
+
+var KLineExportColumns = []string{
+	"start_time", "end_time", "exchange", "symbol", "interval", "open", "high", "low", "close",
+	"volume", "quote_volume", "taker_buy_base_volume", "taker_buy_quote_volume", "num_trades",
+}
+
+// ExportWriter writes the exported rows, the values of a row are ordered by the columns of the writer
+type ExportWriter interface {
+	WriteRow(values ...interface{}) error
+
+	// Flush writes the buffered rows to the underlying writer
+	Flush() error
+}
+
+// NewExportWriter creates the writer of the format, the csv header is written before the first row
+func NewExportWriter(format ExportFormat, w io.Writer, columns []string) (ExportWriter, error) {
+	switch format {
+	case ExportFormatCSV:
+		return &csvExportWriter{writer: csv.NewWriter(w), columns: columns}, nil
+
+	case ExportFormatJSONLines:
+		return &jsonLinesExportWriter{writer: bufio.NewWriter(w), columns: columns}, nil
+
+	case ExportFormatParquet:
+		return nil, fmt.Errorf("%s format is not supported by this build, please export csv and convert it with pandas or arrow", format)
+	}
+
+	return nil, fmt.Errorf("unsupported export format %q", format)
+}
+
+type csvExportWriter struct {
+	writer        *csv.Writer
+	columns       []string
+	headerWritten bool
+}
+
+func (w *csvExportWriter) writeHeader() error {
+	if w.headerWritten {
+		return nil
+	}
+
+	w.headerWritten = true
+	return w.writer.Write(w.columns)
+}
+
+func (w *csvExportWriter) WriteRow(values ...interface{}) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+
+	if len(values) != len(w.columns) {
+		return fmt.Errorf("export row has %d values, %d columns expected", len(values), len(w.columns))
+	}
+
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = formatExportValue(value)
+	}
+
+	return w.writer.Write(record)
+}
+
+func (w *csvExportWriter) Flush() error {
+	// an empty export still has the header
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+type jsonLinesExportWriter struct {
+	writer  *bufio.Writer
+	columns []string
+}
+
+func (w *jsonLinesExportWriter) WriteRow(values ...interface{}) error {
+	if len(values) != len(w.columns) {
+		return fmt.Errorf("export row has %d values, %d columns expected", len(values), len(w.columns))
+	}
+
+	// encode the object manually to keep the column order
+	w.writer.WriteByte('{')
+	for i, value := range values {
+		if i > 0 {
+			w.writer.WriteByte(',')
+		}
+
+		key, err := json.Marshal(w.columns[i])
+		if err != nil {
+			return err
+		}
+
+		if t, ok := value.(time.Time); ok {
+			value = t.UTC().Format(time.RFC3339Nano)
+		}
+
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		w.writer.Write(key)
+		w.writer.WriteByte(':')
+		w.writer.Write(data)
+	}
+
+	_, err := w.writer.WriteString("}\n")
+	return err
+}
+
+func (w *jsonLinesExportWriter) Flush() error {
+	return w.writer.Flush()
+}
+
+func formatExportValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	}
+
+	return fmt.Sprint(value)
+}
+
+func tradeExportRow(trade types.Trade) []interface{} {
+	var strategy, pnl interface{}
+	if trade.StrategyID.Valid {
+		strategy = trade.StrategyID.String
+	}
+
+	if trade.PnL.Valid {
+		pnl = trade.PnL.Float64
+	}
+
+	return []interface{}{
+		trade.Time.Time(), string(trade.Exchange), trade.Symbol, trade.ID, trade.OrderID, string(trade.Side),
+		trade.Price, trade.Quantity, trade.QuoteQuantity, trade.Fee, trade.FeeCurrency,
+		trade.IsMaker, trade.IsBuyer, trade.IsMargin, trade.IsFutures, trade.IsIsolated, strategy, pnl,
+	}
+}
+
+func klineExportRow(k types.KLine) []interface{} {
+	return []interface{}{
+		k.StartTime, k.EndTime, string(k.Exchange), k.Symbol, string(k.Interval), k.Open, k.High, k.Low, k.Close,
+		k.Volume, k.QuoteVolume, k.TakerBuyBaseAssetVolume, k.TakerBuyQuoteAssetVolume, k.NumberOfTrades,
+	}
+}
+
+// ExportTrades writes the trades from the channel until the channel is closed, the rows are written one by one,
+// so that the memory usage does not grow with the time range.
+func ExportTrades(w ExportWriter, tradeC <-chan types.Trade, errC <-chan error) (count int, err error) {
+	for trade := range tradeC {
+		if err := w.WriteRow(tradeExportRow(trade)...); err != nil {
+			return count, err
+		}
+
+		count++
+	}
+
+	if err := <-errC; err != nil {
+		return count, err
+	}
+
+	return count, w.Flush()
+}
+
+// ExportKLines writes the klines from the channel until the channel is closed
+func ExportKLines(w ExportWriter, klineC <-chan types.KLine, errC <-chan error) (count int, err error) {
+	// the kline query returns a nil channel with the error
+	if klineC == nil {
+		return 0, <-errC
+	}
+
+	for k := range klineC {
+		if err := w.WriteRow(klineExportRow(k)...); err != nil {
+			return count, err
+		}
+
+		count++
+	}
+
+	if err := <-errC; err != nil {
+		return count, err
+	}
+
+	return count, w.Flush()
+}
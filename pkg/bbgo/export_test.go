@@ -0,0 +1,151 @@
+package bbgo
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestNewExportWriter(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := NewExportWriter(ExportFormatParquet, &buf, TradeExportColumns)
+	assert.Error(t, err)
+
+	_, err = NewExportWriter("xlsx", &buf, TradeExportColumns)
+	assert.Error(t, err)
+
+	w, err := NewExportWriter(ExportFormatCSV, &buf, []string{"a", "b"})
+	assert.NoError(t, err)
+	assert.Error(t, w.WriteRow(1))
+
+	// the header is written without rows
+	buf.Reset()
+	w, _ = NewExportWriter(ExportFormatCSV, &buf, []string{"a", "b"})
+	assert.NoError(t, w.Flush())
+	assert.Equal(t, "a,b\n", buf.String())
+}
+
+func TestExportTrades(t *testing.T) {
+	tradeTime := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	trades := []types.Trade{
+		{
+			ID: 1, OrderID: 10, Exchange: types.ExchangeBinance, Symbol: "BTCUSDT", Side: types.SideTypeBuy,
+			Price: 35000.5, Quantity: 0.01, QuoteQuantity: 350.005, Fee: 0.00001, FeeCurrency: "BTC",
+			IsBuyer: true, Time: types.Time(tradeTime),
+			StrategyID: sql.NullString{String: "grid", Valid: true},
+		},
+		{
+			ID: 2, OrderID: 11, Exchange: types.ExchangeBinance, Symbol: "BTCUSDT", Side: types.SideTypeSell,
+			Price: 35100.0, Quantity: 0.01, QuoteQuantity: 351.0, Fee: 0.351, FeeCurrency: "USDT",
+			IsMaker: true, Time: types.Time(tradeTime.Add(time.Minute)),
+			PnL: sql.NullFloat64{Float64: 0.64, Valid: true},
+		},
+	}
+
+	newChannels := func() (chan types.Trade, chan error) {
+		tradeC := make(chan types.Trade, len(trades))
+		errC := make(chan error, 1)
+		for _, trade := range trades {
+			tradeC <- trade
+		}
+		close(tradeC)
+		close(errC)
+		return tradeC, errC
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		w, err := NewExportWriter(ExportFormatCSV, &buf, TradeExportColumns)
+		assert.NoError(t, err)
+
+		tradeC, errC := newChannels()
+		count, err := ExportTrades(w, tradeC, errC)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if assert.Len(t, lines, 3) {
+			assert.Equal(t, strings.Join(TradeExportColumns, ","), lines[0])
+			assert.Equal(t, "2021-06-01T12:00:00Z,binance,BTCUSDT,1,10,BUY,35000.5,0.01,350.005,0.00001,BTC,false,true,false,false,false,grid,", lines[1])
+			assert.Equal(t, "2021-06-01T12:01:00Z,binance,BTCUSDT,2,11,SELL,35100,0.01,351,0.351,USDT,true,false,false,false,false,,0.64", lines[2])
+		}
+	})
+
+	t.Run("jsonl", func(t *testing.T) {
+		var buf bytes.Buffer
+		w, err := NewExportWriter(ExportFormatJSONLines, &buf, TradeExportColumns)
+		assert.NoError(t, err)
+
+		tradeC, errC := newChannels()
+		count, err := ExportTrades(w, tradeC, errC)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if assert.Len(t, lines, 2) {
+			assert.True(t, strings.HasPrefix(lines[0], `{"time":"2021-06-01T12:00:00Z","exchange":"binance",`))
+
+			var row map[string]interface{}
+			assert.NoError(t, json.Unmarshal([]byte(lines[1]), &row))
+			assert.Equal(t, 35100.0, row["price"])
+			assert.Equal(t, true, row["is_maker"])
+			assert.Nil(t, row["strategy"])
+			assert.Equal(t, 0.64, row["pnl"])
+		}
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		var buf bytes.Buffer
+		w, _ := NewExportWriter(ExportFormatCSV, &buf, TradeExportColumns)
+
+		tradeC := make(chan types.Trade)
+		errC := make(chan error, 1)
+		close(tradeC)
+		errC <- errors.New("db error")
+		close(errC)
+
+		_, err := ExportTrades(w, tradeC, errC)
+		assert.Error(t, err)
+	})
+}
+
+func TestExportKLines(t *testing.T) {
+	startTime := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	klineC := make(chan types.KLine, 1)
+	errC := make(chan error, 1)
+	klineC <- types.KLine{
+		Exchange:  types.ExchangeBinance,
+		Symbol:    "BTCUSDT",
+		Interval:  types.Interval1m,
+		StartTime: startTime,
+		EndTime:   startTime.Add(time.Minute - time.Millisecond),
+		Open:      100.0, High: 110.0, Low: 90.0, Close: 105.0,
+		Volume: 1.5, QuoteVolume: 150.0, NumberOfTrades: 12,
+	}
+	close(klineC)
+	close(errC)
+
+	var buf bytes.Buffer
+	w, _ := NewExportWriter(ExportFormatCSV, &buf, KLineExportColumns)
+	count, err := ExportKLines(w, klineC, errC)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, strings.Join(KLineExportColumns, ",")+"\n"+
+		"2021-06-01T00:00:00Z,2021-06-01T00:00:59.999Z,binance,BTCUSDT,1m,100,110,90,105,1.5,150,0,0,12\n", buf.String())
+
+	// the kline query returns a nil channel with the error
+	errC = make(chan error, 1)
+	errC <- errors.New("symbols is empty")
+	close(errC)
+	_, err = ExportKLines(w, nil, errC)
+	assert.Error(t, err)
+}
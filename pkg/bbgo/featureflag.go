@@ -0,0 +1,205 @@
+package bbgo
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sync"
+)
+
+// FeatureFlag gates a new logic path of the strategies, it can be rolled out gradually by the percentage
+type FeatureFlag struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// Percentage is the rollout percentage (0 ~ 100) of the evaluations,
+	// e.g., 10 means 10% of the evaluations are enabled. Zero or unset means full rollout.
+	Percentage float64 `json:"percentage,omitempty" yaml:"percentage,omitempty"`
+
+	// Strategies limits the flag to the given strategy IDs, empty means all strategies
+	Strategies []string `json:"strategies,omitempty" yaml:"strategies,omitempty"`
+}
+
+func (flag FeatureFlag) appliesTo(strategyID string) bool {
+	if len(flag.Strategies) == 0 {
+		return true
+	}
+
+	for _, id := range flag.Strategies {
+		if id == strategyID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rollout returns true if the sample (0 ~ 100) falls into the rollout percentage
+func (flag FeatureFlag) rollout(sample float64) bool {
+	if flag.Percentage <= 0 || flag.Percentage >= 100 {
+		return true
+	}
+
+	return sample < flag.Percentage
+}
+
+type FeatureFlagVariantMetrics struct {
+	// Evaluations is the number of the evaluations that resolved to the variant
+	Evaluations int64 `json:"evaluations"`
+
+	// Records and Sum are the number and the sum of the values recorded by the strategies,
+	// for example, the profit of the orders that use the new logic path.
+	Records int64   `json:"records"`
+	Sum     float64 `json:"sum"`
+}
+
+// FeatureFlagMetrics collects the metrics of the enabled and the disabled paths of the flag
+type FeatureFlagMetrics struct {
+	Enabled  FeatureFlagVariantMetrics `json:"enabled"`
+	Disabled FeatureFlagVariantMetrics `json:"disabled"`
+}
+
+func (m *FeatureFlagMetrics) variant(enabled bool) *FeatureFlagVariantMetrics {
+	if enabled {
+		return &m.Enabled
+	}
+	return &m.Disabled
+}
+
+// FeatureFlags is the feature flag registry, the flags are loaded from the config and can be updated from the api.
+// Strategies get it injected by declaring a field:
+//
+//	FeatureFlags *bbgo.FeatureFlags
+//
+// All the methods are nil-safe, a nil registry evaluates every flag to false.
+type FeatureFlags struct {
+	mu      sync.RWMutex
+	flags   map[string]FeatureFlag
+	metrics map[string]*FeatureFlagMetrics
+
+	// sample returns a random number in [0, 100)
+	sample func() float64
+}
+
+func NewFeatureFlags(flags map[string]FeatureFlag) *FeatureFlags {
+	f := &FeatureFlags{
+		flags:   make(map[string]FeatureFlag),
+		metrics: make(map[string]*FeatureFlagMetrics),
+		sample: func() float64 {
+			return rand.Float64() * 100.0
+		},
+	}
+
+	for name, flag := range flags {
+		f.flags[name] = flag
+	}
+
+	return f
+}
+
+// Set adds or updates the flag
+func (f *FeatureFlags) Set(name string, flag FeatureFlag) {
+	if f == nil {
+		return
+	}
+
+	f.mu.Lock()
+	f.flags[name] = flag
+	f.mu.Unlock()
+}
+
+func (f *FeatureFlags) Get(name string) (FeatureFlag, bool) {
+	if f == nil {
+		return FeatureFlag{}, false
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	flag, ok := f.flags[name]
+	return flag, ok
+}
+
+// Flags returns a copy of the flags
+func (f *FeatureFlags) Flags() map[string]FeatureFlag {
+	var flags = make(map[string]FeatureFlag)
+	if f == nil {
+		return flags
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	for name, flag := range f.flags {
+		flags[name] = flag
+	}
+	return flags
+}
+
+// Metrics returns a copy of the metrics of the evaluated flags
+func (f *FeatureFlags) Metrics() map[string]FeatureFlagMetrics {
+	var metrics = make(map[string]FeatureFlagMetrics)
+	if f == nil {
+		return metrics
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	for name, m := range f.metrics {
+		metrics[name] = *m
+	}
+	return metrics
+}
+
+// IsEnabled evaluates the flag for the strategy with a random sample,
+// so that the given percentage of the evaluations are enabled, e.g., 10% of the orders use the new sizing.
+func (f *FeatureFlags) IsEnabled(strategyID, name string) bool {
+	if f == nil {
+		return false
+	}
+
+	return f.evaluate(strategyID, name, f.sample())
+}
+
+// IsEnabledFor evaluates the flag for the key (e.g., the symbol), the same key always gets the same result,
+// so that the rollout is sticky.
+func (f *FeatureFlags) IsEnabledFor(strategyID, name, key string) bool {
+	if f == nil {
+		return false
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name + ":" + key))
+	return f.evaluate(strategyID, name, float64(h.Sum32()%10000)/100.0)
+}
+
+func (f *FeatureFlags) evaluate(strategyID, name string, sample float64) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	flag, ok := f.flags[name]
+	enabled := ok && flag.Enabled && flag.appliesTo(strategyID) && flag.rollout(sample)
+
+	f.metricsOf(name).variant(enabled).Evaluations++
+	return enabled
+}
+
+// Record records the value (e.g., the profit) of the path that the evaluation resolved to,
+// so that the enabled and the disabled paths can be compared.
+func (f *FeatureFlags) Record(name string, enabled bool, value float64) {
+	if f == nil {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v := f.metricsOf(name).variant(enabled)
+	v.Records++
+	v.Sum += value
+}
+
+func (f *FeatureFlags) metricsOf(name string) *FeatureFlagMetrics {
+	m, ok := f.metrics[name]
+	if !ok {
+		m = &FeatureFlagMetrics{}
+		f.metrics[name] = m
+	}
+	return m
+}
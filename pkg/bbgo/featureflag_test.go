@@ -0,0 +1,84 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeatureFlags_IsEnabled(t *testing.T) {
+	flags := NewFeatureFlags(map[string]FeatureFlag{
+		"new-sizing": {Enabled: true, Percentage: 10},
+		"disabled":   {Enabled: false},
+		"bollmaker-only": {
+			Enabled:    true,
+			Strategies: []string{"bollmaker"},
+		},
+	})
+
+	var samples = []float64{5.0, 50.0, 9.99, 10.0}
+	flags.sample = func() float64 {
+		s := samples[0]
+		samples = samples[1:]
+		return s
+	}
+
+	assert.True(t, flags.IsEnabled("grid", "new-sizing"))
+	assert.False(t, flags.IsEnabled("grid", "new-sizing"))
+	assert.True(t, flags.IsEnabled("grid", "new-sizing"))
+	assert.False(t, flags.IsEnabled("grid", "new-sizing"))
+
+	flags.sample = func() float64 { return 0 }
+	assert.False(t, flags.IsEnabled("grid", "disabled"))
+	assert.False(t, flags.IsEnabled("grid", "undefined"))
+	assert.False(t, flags.IsEnabled("grid", "bollmaker-only"))
+	assert.True(t, flags.IsEnabled("bollmaker", "bollmaker-only"))
+
+	metrics := flags.Metrics()
+	assert.Equal(t, int64(2), metrics["new-sizing"].Enabled.Evaluations)
+	assert.Equal(t, int64(2), metrics["new-sizing"].Disabled.Evaluations)
+	assert.Equal(t, int64(1), metrics["undefined"].Disabled.Evaluations)
+
+	flags.Record("new-sizing", true, 1.5)
+	flags.Record("new-sizing", true, 0.5)
+	flags.Record("new-sizing", false, -1.0)
+
+	metrics = flags.Metrics()
+	assert.Equal(t, int64(2), metrics["new-sizing"].Enabled.Records)
+	assert.Equal(t, 2.0, metrics["new-sizing"].Enabled.Sum)
+	assert.Equal(t, -1.0, metrics["new-sizing"].Disabled.Sum)
+
+	// update the flag from the api
+	flags.Set("disabled", FeatureFlag{Enabled: true})
+	assert.True(t, flags.IsEnabled("grid", "disabled"))
+}
+
+func TestFeatureFlags_IsEnabledFor(t *testing.T) {
+	flags := NewFeatureFlags(map[string]FeatureFlag{
+		"new-sizing": {Enabled: true, Percentage: 50},
+	})
+
+	// the result of the key is sticky
+	for _, symbol := range []string{"BTCUSDT", "ETHUSDT", "MAXUSDT", "LINKUSDT"} {
+		first := flags.IsEnabledFor("grid", "new-sizing", symbol)
+		for i := 0; i < 10; i++ {
+			assert.Equal(t, first, flags.IsEnabledFor("grid", "new-sizing", symbol))
+		}
+	}
+
+	var enabled int
+	for i := 0; i < 1000; i++ {
+		if flags.IsEnabledFor("grid", "new-sizing", string(rune('a'+i%26))+string(rune('a'+i/26))) {
+			enabled++
+		}
+	}
+	assert.InDelta(t, 500, enabled, 100)
+}
+
+func TestFeatureFlags_Nil(t *testing.T) {
+	var flags *FeatureFlags
+	assert.False(t, flags.IsEnabled("grid", "new-sizing"))
+	assert.False(t, flags.IsEnabledFor("grid", "new-sizing", "BTCUSDT"))
+	assert.Empty(t, flags.Flags())
+	flags.Record("new-sizing", true, 1.0)
+}
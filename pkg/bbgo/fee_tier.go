@@ -0,0 +1,171 @@
+package bbgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultFeeTierRefreshInterval = time.Hour
+
+// UpdateFeeTier queries the fee tier of the session account and the fee discount of the platform token,
+// the fee rates of the session config override the queried fee rates.
+func (session *ExchangeSession) UpdateFeeTier(ctx context.Context) (types.FeeTier, error) {
+	account, err := session.Exchange.QueryAccount(ctx)
+	if err != nil {
+		return types.FeeTier{}, err
+	}
+
+	tier, ok := types.FeeTierFromAccount(account)
+	if session.MakerFeeRate > 0 {
+		tier.MakerFeeRate = session.MakerFeeRate
+		ok = true
+	}
+
+	if session.TakerFeeRate > 0 {
+		tier.TakerFeeRate = session.TakerFeeRate
+		ok = true
+	}
+
+	if !ok {
+		return tier, fmt.Errorf("fee rates of session %s are not available", session.Name)
+	}
+
+	if service, ok := session.Exchange.(types.ExchangeFeeDiscountService); ok {
+		discount, err := service.QueryFeeDiscount(ctx)
+		if err != nil {
+			// keep the previous discount, the discount setting is rarely changed
+			log.WithError(err).Warnf("can not query the fee discount of session %s", session.Name)
+			if last, ok := session.FeeTier(); ok {
+				discount = last.FeeDiscount
+			}
+		}
+
+		tier.FeeDiscount = discount
+	}
+
+	tier.UpdatedAt = time.Now()
+
+	session.feeTierMutex.Lock()
+	session.feeTier = &tier
+	session.feeTierMutex.Unlock()
+	return tier, nil
+}
+
+// FeeTier returns the last queried fee tier of the session
+func (session *ExchangeSession) FeeTier() (types.FeeTier, bool) {
+	session.feeTierMutex.Lock()
+	defer session.feeTierMutex.Unlock()
+
+	if session.feeTier == nil {
+		return types.FeeTier{}, false
+	}
+
+	return *session.feeTier, true
+}
+
+// ExchangeFee returns the effective fee rates of the session, the fee rates of the session config are used
+// when the fee tier is not queried yet.
+func (session *ExchangeSession) ExchangeFee() (types.ExchangeFee, bool) {
+	if tier, ok := session.FeeTier(); ok {
+		return tier.ExchangeFee(), true
+	}
+
+	if session.MakerFeeRate > 0 || session.TakerFeeRate > 0 {
+		return types.ExchangeFee{
+			MakerFeeRate: session.MakerFeeRate,
+			TakerFeeRate: session.TakerFeeRate,
+		}, true
+	}
+
+	return types.ExchangeFee{}, false
+}
+
+// TrackFeeTiers queries the fee tiers of the private sessions and refreshes them periodically until the context
+// is canceled. The queried fee tiers are cached, so that the backtest can use the real fee rates.
+func (environ *Environment) TrackFeeTiers(ctx context.Context, interval time.Duration) {
+	if interval == 0 {
+		interval = defaultFeeTierRefreshInterval
+	}
+
+	for n := range environ.sessions {
+		session := environ.sessions[n]
+		if session.PublicOnly {
+			continue
+		}
+
+		updateFeeTier(ctx, session)
+
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+
+				case <-ticker.C:
+					updateFeeTier(ctx, session)
+				}
+			}
+		}()
+	}
+}
+
+func updateFeeTier(ctx context.Context, session *ExchangeSession) {
+	tier, err := session.UpdateFeeTier(ctx)
+	if err != nil {
+		log.WithError(err).Errorf("can not update the fee tier of session %s", session.Name)
+		return
+	}
+
+	log.Infof("session %s fee tier: maker %f taker %f discount %f",
+		session.Name, tier.MakerFeeRate.Float64(), tier.TakerFeeRate.Float64(), tier.FeeDiscount.Float64())
+
+	if err := SaveFeeTierCache(session.ExchangeName, tier); err != nil {
+		log.WithError(err).Warnf("can not save the fee tier cache of session %s", session.Name)
+	}
+}
+
+func feeTierCacheFile(exchange types.ExchangeName) string {
+	return path.Join(CacheDir(), fmt.Sprintf("%s-fee-tier.json", exchange))
+}
+
+// SaveFeeTierCache saves the fee tier of the exchange to the cache directory
+func SaveFeeTierCache(exchange types.ExchangeName, tier types.FeeTier) error {
+	out, err := json.Marshal(tier)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(feeTierCacheFile(exchange), out, 0666)
+}
+
+// LoadFeeTierCache loads the last fee tier of the exchange saved by the live trading
+func LoadFeeTierCache(exchange types.ExchangeName) (*types.FeeTier, error) {
+	data, err := ioutil.ReadFile(feeTierCacheFile(exchange))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var tier types.FeeTier
+	if err := json.Unmarshal(data, &tier); err != nil {
+		return nil, errors.Wrapf(err, "invalid fee tier cache of %s", exchange)
+	}
+
+	return &tier, nil
+}
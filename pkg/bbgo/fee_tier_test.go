@@ -0,0 +1,115 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type feeTierTestExchange struct {
+	types.Exchange
+
+	account     *types.Account
+	discount    fixedpoint.Value
+	discountErr error
+}
+
+func (e *feeTierTestExchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	return e.account, nil
+}
+
+func (e *feeTierTestExchange) QueryFeeDiscount(ctx context.Context) (fixedpoint.Value, error) {
+	return e.discount, e.discountErr
+}
+
+func TestExchangeSession_UpdateFeeTier(t *testing.T) {
+	exchange := &feeTierTestExchange{
+		account: &types.Account{
+			MakerCommission: fixedpoint.NewFromFloat(0.001),
+			TakerCommission: fixedpoint.NewFromFloat(0.001),
+		},
+		discount: fixedpoint.NewFromFloat(0.25),
+	}
+
+	session := &ExchangeSession{Name: "binance", Exchange: exchange}
+
+	_, ok := session.FeeTier()
+	assert.False(t, ok)
+
+	_, ok = session.ExchangeFee()
+	assert.False(t, ok)
+
+	tier, err := session.UpdateFeeTier(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, fixedpoint.NewFromFloat(0.001), tier.MakerFeeRate)
+	assert.Equal(t, fixedpoint.NewFromFloat(0.25), tier.FeeDiscount)
+	assert.False(t, tier.UpdatedAt.IsZero())
+
+	fee, ok := session.ExchangeFee()
+	assert.True(t, ok)
+	assert.InDelta(t, 0.00075, fee.MakerFeeRate.Float64(), 1e-9)
+
+	// the previous discount is kept when the discount query fails
+	exchange.discountErr = errors.New("timeout")
+	tier, err = session.UpdateFeeTier(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, fixedpoint.NewFromFloat(0.25), tier.FeeDiscount)
+
+	// the session config overrides the queried fee rates
+	session.TakerFeeRate = fixedpoint.NewFromFloat(0.002)
+	tier, err = session.UpdateFeeTier(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, fixedpoint.NewFromFloat(0.002), tier.TakerFeeRate)
+
+	exchange.account = &types.Account{}
+	session.TakerFeeRate = 0
+	_, err = session.UpdateFeeTier(context.Background())
+	assert.Error(t, err)
+}
+
+func TestExchangeSession_ExchangeFee_Config(t *testing.T) {
+	session := &ExchangeSession{
+		MakerFeeRate: fixedpoint.NewFromFloat(0.0005),
+		TakerFeeRate: fixedpoint.NewFromFloat(0.0015),
+	}
+
+	fee, ok := session.ExchangeFee()
+	assert.True(t, ok)
+	assert.Equal(t, fixedpoint.NewFromFloat(0.0005), fee.MakerFeeRate)
+	assert.Equal(t, fixedpoint.NewFromFloat(0.0015), fee.TakerFeeRate)
+}
+
+func TestFeeTierCache(t *testing.T) {
+	home, err := ioutil.TempDir("", "bbgo-home")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(home)
+
+	oldHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", oldHome)
+	_ = os.Setenv("HOME", home)
+
+	tier, err := LoadFeeTierCache(types.ExchangeBinance)
+	assert.NoError(t, err)
+	assert.Nil(t, tier)
+
+	assert.NoError(t, SaveFeeTierCache(types.ExchangeBinance, types.FeeTier{
+		MakerFeeRate: fixedpoint.NewFromFloat(0.001),
+		TakerFeeRate: fixedpoint.NewFromFloat(0.001),
+		FeeDiscount:  fixedpoint.NewFromFloat(0.25),
+	}))
+
+	tier, err = LoadFeeTierCache(types.ExchangeBinance)
+	assert.NoError(t, err)
+	if assert.NotNil(t, tier) {
+		assert.Equal(t, fixedpoint.NewFromFloat(0.25), tier.FeeDiscount)
+	}
+}
@@ -0,0 +1,267 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const (
+	defaultHealthStaleTimeout = 5 * time.Minute
+	defaultHealthCheckTimeout = 3 * time.Second
+)
+
+type HealthCheckConfig struct {
+	// StaleTimeout is how long the market data stream can be silent before it's considered stale, defaults to 5m
+	StaleTimeout types.Duration `json:"staleTimeout,omitempty" yaml:"staleTimeout,omitempty"`
+}
+
+func (c *HealthCheckConfig) Validate() error {
+	if c.StaleTimeout < 0 {
+		return errors.New("health check stale timeout should not be negative")
+	}
+
+	return nil
+}
+
+// HealthCheckFunc returns an error if the component is unhealthy
+type HealthCheckFunc func(ctx context.Context, now time.Time) error
+
+type healthCheck struct {
+	name string
+
+	// liveness checks fail only if the process can not recover by itself, e.g., the streams are stale,
+	// so that the orchestrator restarts the process. The other checks are readiness checks.
+	liveness bool
+
+	check HealthCheckFunc
+}
+
+// HealthCheckResult is the result of a single check
+type HealthCheckResult struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// HealthReport is the report of the /healthz and /readyz endpoints
+type HealthReport struct {
+	Healthy bool                `json:"healthy"`
+	Time    time.Time           `json:"time"`
+	Checks  []HealthCheckResult `json:"checks"`
+}
+
+// streamHealth tracks the connection state and the last event of a stream
+type streamHealth struct {
+	mu          sync.Mutex
+	connected   bool
+	connectedAt time.Time
+	lastEvent   time.Time
+}
+
+func (s *streamHealth) bind(stream types.Stream) {
+	stream.OnConnect(func() {
+		s.mu.Lock()
+		s.connected = true
+		s.connectedAt = time.Now()
+		s.mu.Unlock()
+	})
+
+	stream.OnDisconnect(func() {
+		s.mu.Lock()
+		s.connected = false
+		s.mu.Unlock()
+	})
+}
+
+func (s *streamHealth) beat() {
+	s.mu.Lock()
+	s.lastEvent = time.Now()
+	s.mu.Unlock()
+}
+
+func (s *streamHealth) state() (connected bool, last time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	last = s.lastEvent
+	if s.connectedAt.After(last) {
+		last = s.connectedAt
+	}
+
+	return s.connected, last
+}
+
+// HealthChecker runs the registered health checks for the liveness and the readiness probes
+type HealthChecker struct {
+	Config HealthCheckConfig
+
+	mu       sync.Mutex
+	checks   []healthCheck
+	sessions map[string]struct{}
+
+	// startTime is the beginning of the staleness if the stream has never been connected
+	startTime time.Time
+}
+
+func NewHealthChecker() *HealthChecker {
+	return &HealthChecker{
+		sessions:  make(map[string]struct{}),
+		startTime: time.Now(),
+	}
+}
+
+// AddCheck registers the health check, the liveness checks are included in both of the liveness and the readiness reports
+func (h *HealthChecker) AddCheck(name string, liveness bool, check HealthCheckFunc) {
+	h.mu.Lock()
+	h.checks = append(h.checks, healthCheck{name: name, liveness: liveness, check: check})
+	h.mu.Unlock()
+}
+
+func (h *HealthChecker) staleTimeout() time.Duration {
+	if h.Config.StaleTimeout > 0 {
+		return h.Config.StaleTimeout.Duration()
+	}
+
+	return defaultHealthStaleTimeout
+}
+
+// BindSession registers the connectivity check and the staleness check of the session streams,
+// it should be called before the streams are connected.
+func (h *HealthChecker) BindSession(session *ExchangeSession) {
+	h.mu.Lock()
+	_, ok := h.sessions[session.Name]
+	h.sessions[session.Name] = struct{}{}
+	h.mu.Unlock()
+
+	if ok {
+		return
+	}
+
+	marketData := &streamHealth{}
+	marketData.bind(session.MarketDataStream)
+	session.MarketDataStream.OnKLine(func(kline types.KLine) { marketData.beat() })
+	session.MarketDataStream.OnBookUpdate(func(book types.SliceOrderBook) { marketData.beat() })
+	session.MarketDataStream.OnBookSnapshot(func(book types.SliceOrderBook) { marketData.beat() })
+	session.MarketDataStream.OnMarketTrade(func(trade types.Trade) { marketData.beat() })
+
+	var userData *streamHealth
+	if !session.PublicOnly && session.UserDataStream != nil {
+		userData = &streamHealth{}
+		userData.bind(session.UserDataStream)
+	}
+
+	h.AddCheck("session:"+session.Name+":connectivity", false, func(ctx context.Context, now time.Time) error {
+		var disconnected []string
+		if connected, _ := marketData.state(); !connected {
+			disconnected = append(disconnected, "market data stream")
+		}
+
+		if userData != nil {
+			if connected, _ := userData.state(); !connected {
+				disconnected = append(disconnected, "user data stream")
+			}
+		}
+
+		if len(disconnected) > 0 {
+			return fmt.Errorf("%s disconnected", strings.Join(disconnected, " and "))
+		}
+
+		return nil
+	})
+
+	// the market data stream without the subscriptions has no event
+	if len(session.Subscriptions) == 0 {
+		return
+	}
+
+	h.AddCheck("session:"+session.Name+":staleness", true, func(ctx context.Context, now time.Time) error {
+		_, last := marketData.state()
+		if last.IsZero() {
+			last = h.startTime
+		}
+
+		if elapsed := now.Sub(last); elapsed > h.staleTimeout() {
+			return fmt.Errorf("market data stream is stale, no event in the last %s", elapsed.Round(time.Second))
+		}
+
+		return nil
+	})
+}
+
+func (h *HealthChecker) run(ctx context.Context, now time.Time, livenessOnly bool) HealthReport {
+	h.mu.Lock()
+	checks := append([]healthCheck(nil), h.checks...)
+	h.mu.Unlock()
+
+	report := HealthReport{Healthy: true, Time: now, Checks: []HealthCheckResult{}}
+	for _, c := range checks {
+		if livenessOnly && !c.liveness {
+			continue
+		}
+
+		checkCtx, cancel := context.WithTimeout(ctx, defaultHealthCheckTimeout)
+		err := c.check(checkCtx, now)
+		cancel()
+
+		result := HealthCheckResult{Name: c.name, Healthy: err == nil}
+		if err != nil {
+			result.Error = err.Error()
+			report.Healthy = false
+		}
+
+		report.Checks = append(report.Checks, result)
+	}
+
+	return report
+}
+
+// Liveness runs the liveness checks, the process should be restarted if it's unhealthy
+func (h *HealthChecker) Liveness(ctx context.Context, now time.Time) HealthReport {
+	return h.run(ctx, now, true)
+}
+
+// Readiness runs all the checks, the process should not serve if it's unhealthy
+func (h *HealthChecker) Readiness(ctx context.Context, now time.Time) HealthReport {
+	return h.run(ctx, now, false)
+}
+
+// databaseHealthCheck pings the database, the check passes if the database is not configured
+func (environ *Environment) databaseHealthCheck(ctx context.Context, now time.Time) error {
+	if environ.DatabaseService == nil || environ.DatabaseService.DB == nil {
+		return nil
+	}
+
+	if err := environ.DatabaseService.DB.PingContext(ctx); err != nil {
+		return errors.Wrap(err, "database ping error")
+	}
+
+	return nil
+}
+
+// registerHealthChecks registers the health checks of the trader components, it's called when the trader runs
+func (trader *Trader) registerHealthChecks(h *HealthChecker) {
+	if trader.watchdog != nil {
+		h.AddCheck("watchdog", true, func(ctx context.Context, now time.Time) error {
+			if stalled := trader.watchdog.Stalled(); len(stalled) > 0 {
+				return fmt.Errorf("strategies %s are stalled", strings.Join(stalled, ", "))
+			}
+			return nil
+		})
+	}
+
+	if trader.killSwitch != nil {
+		h.AddCheck("kill_switch", false, func(ctx context.Context, now time.Time) error {
+			if state := trader.killSwitch.State(); state.Tripped {
+				return fmt.Errorf("kill switch is tripped: %s", state.Reason)
+			}
+			return nil
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func healthCheckResult(report HealthReport, name string) (HealthCheckResult, bool) {
+	for _, result := range report.Checks {
+		if result.Name == name {
+			return result, true
+		}
+	}
+
+	return HealthCheckResult{}, false
+}
+
+func TestHealthChecker_BindSession(t *testing.T) {
+	marketDataStream := &bootstrapTestStream{StandardStream: types.NewStandardStream()}
+	userDataStream := &bootstrapTestStream{StandardStream: types.NewStandardStream()}
+	session := &ExchangeSession{
+		Name:             "binance",
+		MarketDataStream: marketDataStream,
+		UserDataStream:   userDataStream,
+		Subscriptions:    make(map[types.Subscription]types.Subscription),
+	}
+
+	sub := types.Subscription{Symbol: "BTCUSDT", Channel: types.KLineChannel, Options: types.SubscribeOptions{Interval: "1m"}}
+	session.Subscriptions[sub] = sub
+
+	checker := NewHealthChecker()
+	checker.Config.StaleTimeout = types.Duration(time.Minute)
+	checker.BindSession(session)
+	checker.BindSession(session)
+
+	ctx := context.Background()
+	report := checker.Readiness(ctx, time.Now())
+	assert.False(t, report.Healthy)
+	assert.Len(t, report.Checks, 2)
+	if result, ok := healthCheckResult(report, "session:binance:connectivity"); assert.True(t, ok) {
+		assert.Equal(t, "market data stream and user data stream disconnected", result.Error)
+	}
+
+	// the staleness is a liveness check, the disconnected streams only fail the readiness
+	assert.True(t, checker.Liveness(ctx, time.Now()).Healthy)
+
+	marketDataStream.EmitConnect()
+	userDataStream.EmitConnect()
+	report = checker.Readiness(ctx, time.Now())
+	assert.True(t, report.Healthy)
+
+	report = checker.Liveness(ctx, time.Now().Add(2*time.Minute))
+	assert.False(t, report.Healthy)
+	if assert.Len(t, report.Checks, 1) {
+		assert.Equal(t, "session:binance:staleness", report.Checks[0].Name)
+	}
+
+	marketDataStream.EmitKLine(types.KLine{Symbol: "BTCUSDT"})
+	assert.True(t, checker.Liveness(ctx, time.Now().Add(30*time.Second)).Healthy)
+
+	userDataStream.EmitDisconnect()
+	report = checker.Readiness(ctx, time.Now())
+	if result, ok := healthCheckResult(report, "session:binance:connectivity"); assert.True(t, ok) {
+		assert.Equal(t, "user data stream disconnected", result.Error)
+	}
+}
+
+func TestHealthChecker_PublicOnlySession(t *testing.T) {
+	marketDataStream := &bootstrapTestStream{StandardStream: types.NewStandardStream()}
+	session := &ExchangeSession{
+		Name:             "max",
+		PublicOnly:       true,
+		MarketDataStream: marketDataStream,
+	}
+
+	checker := NewHealthChecker()
+	checker.BindSession(session)
+
+	// the stream without the subscriptions has no staleness check
+	report := checker.Readiness(context.Background(), time.Now().Add(time.Hour))
+	assert.Len(t, report.Checks, 1)
+	assert.False(t, report.Healthy)
+
+	marketDataStream.EmitConnect()
+	assert.True(t, checker.Readiness(context.Background(), time.Now().Add(time.Hour)).Healthy)
+}
+
+func TestTrader_registerHealthChecks(t *testing.T) {
+	exchange := &killSwitchTestExchange{}
+	session := newKillSwitchTestSession(exchange)
+
+	notifiability := &Notifiability{}
+	store := service.NewMemoryService().NewStore("bbgo", "kill_switch")
+	killSwitch := NewKillSwitch(KillSwitchConfig{
+		MaxDailyLoss: fixedpoint.NewFromFloat(100.0),
+		Currency:     "USDT",
+	}, notifiability, store)
+	killSwitch.AddSession(session)
+
+	environ := NewEnvironment()
+	trader := &Trader{environment: environ, killSwitch: killSwitch}
+	trader.registerHealthChecks(environ.HealthChecker)
+
+	ctx := context.Background()
+	now := time.Now()
+
+	// the database check passes without the database
+	report := environ.HealthChecker.Readiness(ctx, now)
+	assert.True(t, report.Healthy)
+	assert.Len(t, report.Checks, 2)
+	assert.True(t, environ.HealthChecker.Liveness(ctx, now).Healthy)
+
+	killSwitch.Trip(ctx, now, "manual")
+	report = environ.HealthChecker.Readiness(ctx, now)
+	assert.False(t, report.Healthy)
+	if result, ok := healthCheckResult(report, "kill_switch"); assert.True(t, ok) {
+		assert.Equal(t, "kill switch is tripped: manual", result.Error)
+	}
+
+	// tripping the kill switch does not restart the process
+	assert.True(t, environ.HealthChecker.Liveness(ctx, now).Healthy)
+}
@@ -0,0 +1,301 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const (
+	defaultIndexPriceUpdateInterval = 10 * time.Second
+	defaultIndexPriceMaxDeviation   = 0.05
+)
+
+type IndexPriceMethod string
+
+const (
+	// IndexPriceMethodMedian takes the median of the source prices, it's the default method
+	IndexPriceMethodMedian = IndexPriceMethod("median")
+
+	// IndexPriceMethodVWAP weights the source prices by the 24h volume of the sources
+	IndexPriceMethodVWAP = IndexPriceMethod("vwap")
+)
+
+type IndexPriceType string
+
+const (
+	// IndexPriceTypeLast uses the last trade price of the tickers, it's the default price type
+	IndexPriceTypeLast = IndexPriceType("last")
+
+	// IndexPriceTypeMid uses the mid price of the best bid and the best ask of the tickers
+	IndexPriceTypeMid = IndexPriceType("mid")
+)
+
+type IndexPriceConfig struct {
+	Symbol string `json:"symbol" yaml:"symbol"`
+
+	// Sessions are the sessions to aggregate, empty means all sessions
+	Sessions []string `json:"sessions,omitempty" yaml:"sessions,omitempty"`
+
+	Method    IndexPriceMethod `json:"method,omitempty" yaml:"method,omitempty"`
+	PriceType IndexPriceType   `json:"priceType,omitempty" yaml:"priceType,omitempty"`
+
+	// MaxDeviation rejects the source price that deviates from the median of the sources by more than the ratio,
+	// e.g., 0.05 rejects the price that is 5% away from the median.
+	MaxDeviation float64 `json:"maxDeviation,omitempty" yaml:"maxDeviation,omitempty"`
+
+	// MaxAge rejects the ticker older than the duration, zero means no limit
+	MaxAge types.Duration `json:"maxAge,omitempty" yaml:"maxAge,omitempty"`
+
+	UpdateInterval types.Duration `json:"updateInterval,omitempty" yaml:"updateInterval,omitempty"`
+}
+
+func (c *IndexPriceConfig) Validate() error {
+	if len(c.Symbol) == 0 {
+		return errors.New("index price symbol is required")
+	}
+
+	switch c.Method {
+	case "", IndexPriceMethodMedian, IndexPriceMethodVWAP:
+	default:
+		return fmt.Errorf("unsupported index price method %q", c.Method)
+	}
+
+	switch c.PriceType {
+	case "", IndexPriceTypeLast, IndexPriceTypeMid:
+	default:
+		return fmt.Errorf("unsupported index price type %q", c.PriceType)
+	}
+
+	if c.MaxDeviation < 0 {
+		return fmt.Errorf("index price maxDeviation can not be negative: %f", c.MaxDeviation)
+	}
+
+	return nil
+}
+
+// IndexPriceSource is the price of the symbol from one session
+type IndexPriceSource struct {
+	Session string    `json:"session"`
+	Price   float64   `json:"price"`
+	Volume  float64   `json:"volume"`
+	Time    time.Time `json:"time"`
+}
+
+// IndexPrice is the aggregated price of the symbol, Rejected contains the outlier sources
+type IndexPrice struct {
+	Symbol   string             `json:"symbol"`
+	Price    float64            `json:"price"`
+	Time     time.Time          `json:"time"`
+	Sources  []IndexPriceSource `json:"sources"`
+	Rejected []IndexPriceSource `json:"rejected,omitempty"`
+}
+
+// ComputeIndexPrice aggregates the source prices with the method, the sources that deviate from the median of the
+// sources by more than maxDeviation are rejected before the aggregation. Zero maxDeviation disables the rejection.
+func ComputeIndexPrice(method IndexPriceMethod, sources []IndexPriceSource, maxDeviation float64) (price float64, accepted, rejected []IndexPriceSource, err error) {
+	if len(sources) == 0 {
+		return 0, nil, nil, errors.New("no index price source")
+	}
+
+	median := medianPrice(sources)
+	for _, source := range sources {
+		if maxDeviation > 0 && math.Abs(source.Price-median)/median > maxDeviation {
+			rejected = append(rejected, source)
+			continue
+		}
+
+		accepted = append(accepted, source)
+	}
+
+	if len(accepted) == 0 {
+		return 0, nil, rejected, errors.New("all index price sources are rejected")
+	}
+
+	if method == IndexPriceMethodVWAP {
+		var sum, volume float64
+		for _, source := range accepted {
+			sum += source.Price * source.Volume
+			volume += source.Volume
+		}
+
+		if volume > 0 {
+			return sum / volume, accepted, rejected, nil
+		}
+	}
+
+	return medianPrice(accepted), accepted, rejected, nil
+}
+
+func medianPrice(sources []IndexPriceSource) float64 {
+	prices := make([]float64, len(sources))
+	for i, source := range sources {
+		prices[i] = source.Price
+	}
+
+	sort.Float64s(prices)
+
+	n := len(prices)
+	if n%2 == 1 {
+		return prices[n/2]
+	}
+
+	return (prices[n/2-1] + prices[n/2]) / 2.0
+}
+
+// SymbolTickerQuerier queries the public ticker of one symbol, it's implemented by the exchanges
+type SymbolTickerQuerier interface {
+	QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error)
+}
+
+//go:generate callbackgen -type IndexPriceService
+
+// IndexPriceService aggregates the ticker prices of the symbol from multiple sessions,
+// the index price is used as the fair value reference of the market makers and the circuit breakers.
+type IndexPriceService struct {
+	Config IndexPriceConfig
+
+	sources map[string]SymbolTickerQuerier
+
+	mu   sync.Mutex
+	last *IndexPrice
+
+	updateCallbacks []func(price IndexPrice)
+
+	// now is used for the testing
+	now func() time.Time
+}
+
+func NewIndexPriceService(config IndexPriceConfig) *IndexPriceService {
+	if config.Method == "" {
+		config.Method = IndexPriceMethodMedian
+	}
+
+	if config.PriceType == "" {
+		config.PriceType = IndexPriceTypeLast
+	}
+
+	if config.MaxDeviation == 0 {
+		config.MaxDeviation = defaultIndexPriceMaxDeviation
+	}
+
+	if config.UpdateInterval == 0 {
+		config.UpdateInterval = types.Duration(defaultIndexPriceUpdateInterval)
+	}
+
+	return &IndexPriceService{
+		Config:  config,
+		sources: make(map[string]SymbolTickerQuerier),
+		now:     time.Now,
+	}
+}
+
+// AddSource adds the ticker source of the session
+func (s *IndexPriceService) AddSource(session string, querier SymbolTickerQuerier) {
+	s.sources[session] = querier
+}
+
+// Last returns the last index price
+func (s *IndexPriceService) Last() (IndexPrice, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.last == nil {
+		return IndexPrice{}, false
+	}
+
+	return *s.last, true
+}
+
+// Update queries the tickers of the sources and updates the index price,
+// the failed and the stale sources are skipped.
+func (s *IndexPriceService) Update(ctx context.Context) (*IndexPrice, error) {
+	now := s.now()
+
+	var sessions []string
+	for session := range s.sources {
+		sessions = append(sessions, session)
+	}
+	sort.Strings(sessions)
+
+	var sources []IndexPriceSource
+	for _, session := range sessions {
+		ticker, err := s.sources[session].QueryTicker(ctx, s.Config.Symbol)
+		if err != nil {
+			log.WithError(err).Warnf("can not query %s ticker from session %s", s.Config.Symbol, session)
+			continue
+		}
+
+		if s.Config.MaxAge > 0 && !ticker.Time.IsZero() && now.Sub(ticker.Time) > s.Config.MaxAge.Duration() {
+			log.Warnf("%s ticker from session %s is stale: %s", s.Config.Symbol, session, ticker.Time)
+			continue
+		}
+
+		price := ticker.Last
+		if s.Config.PriceType == IndexPriceTypeMid && ticker.Buy > 0 && ticker.Sell > 0 {
+			price = (ticker.Buy + ticker.Sell) / 2.0
+		}
+
+		if price <= 0 {
+			continue
+		}
+
+		sources = append(sources, IndexPriceSource{
+			Session: session,
+			Price:   price,
+			Volume:  ticker.Volume,
+			Time:    ticker.Time,
+		})
+	}
+
+	price, accepted, rejected, err := ComputeIndexPrice(s.Config.Method, sources, s.Config.MaxDeviation)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can not compute %s index price", s.Config.Symbol)
+	}
+
+	for _, source := range rejected {
+		log.Warnf("%s price %f from session %s is rejected as an outlier", s.Config.Symbol, source.Price, source.Session)
+	}
+
+	index := &IndexPrice{
+		Symbol:   s.Config.Symbol,
+		Price:    price,
+		Time:     now,
+		Sources:  accepted,
+		Rejected: rejected,
+	}
+
+	s.mu.Lock()
+	s.last = index
+	s.mu.Unlock()
+
+	s.EmitUpdate(*index)
+	return index, nil
+}
+
+// Run updates the index price periodically until the context is canceled
+func (s *IndexPriceService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Config.UpdateInterval.Duration())
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Update(ctx); err != nil {
+			log.WithError(err).Error("index price update error")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+		}
+	}
+}
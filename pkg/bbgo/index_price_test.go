@@ -0,0 +1,113 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type tickerQuerierFunc func(ctx context.Context, symbol string) (*types.Ticker, error)
+
+func (f tickerQuerierFunc) QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
+	return f(ctx, symbol)
+}
+
+func staticTicker(ticker types.Ticker) SymbolTickerQuerier {
+	return tickerQuerierFunc(func(ctx context.Context, symbol string) (*types.Ticker, error) {
+		return &ticker, nil
+	})
+}
+
+func TestComputeIndexPrice(t *testing.T) {
+	sources := []IndexPriceSource{
+		{Session: "binance", Price: 100.0, Volume: 3.0},
+		{Session: "ftx", Price: 101.0, Volume: 1.0},
+		{Session: "max", Price: 102.0, Volume: 1.0},
+		{Session: "okex", Price: 150.0, Volume: 10.0},
+	}
+
+	price, accepted, rejected, err := ComputeIndexPrice(IndexPriceMethodMedian, sources, 0.05)
+	assert.NoError(t, err)
+	assert.Equal(t, 101.0, price)
+	assert.Len(t, accepted, 3)
+	if assert.Len(t, rejected, 1) {
+		assert.Equal(t, "okex", rejected[0].Session)
+	}
+
+	price, _, _, err = ComputeIndexPrice(IndexPriceMethodVWAP, sources, 0.05)
+	assert.NoError(t, err)
+	assert.InDelta(t, (300.0+101.0+102.0)/5.0, price, 1e-9)
+
+	// without the outlier rejection
+	price, _, rejected, err = ComputeIndexPrice(IndexPriceMethodMedian, sources, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 101.5, price)
+	assert.Empty(t, rejected)
+
+	// vwap falls back to the median without volume
+	price, _, _, err = ComputeIndexPrice(IndexPriceMethodVWAP, []IndexPriceSource{{Price: 100.0}, {Price: 102.0}}, 0.05)
+	assert.NoError(t, err)
+	assert.Equal(t, 101.0, price)
+
+	_, _, _, err = ComputeIndexPrice(IndexPriceMethodMedian, nil, 0.05)
+	assert.Error(t, err)
+}
+
+func TestIndexPriceConfig_Validate(t *testing.T) {
+	assert.NoError(t, (&IndexPriceConfig{Symbol: "BTCUSDT"}).Validate())
+	assert.NoError(t, (&IndexPriceConfig{Symbol: "BTCUSDT", Method: IndexPriceMethodVWAP, PriceType: IndexPriceTypeMid}).Validate())
+	assert.Error(t, (&IndexPriceConfig{}).Validate())
+	assert.Error(t, (&IndexPriceConfig{Symbol: "BTCUSDT", Method: "mean"}).Validate())
+	assert.Error(t, (&IndexPriceConfig{Symbol: "BTCUSDT", PriceType: "close"}).Validate())
+	assert.Error(t, (&IndexPriceConfig{Symbol: "BTCUSDT", MaxDeviation: -1}).Validate())
+}
+
+func TestIndexPriceService_Update(t *testing.T) {
+	now := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	service := NewIndexPriceService(IndexPriceConfig{
+		Symbol:    "BTCUSDT",
+		PriceType: IndexPriceTypeMid,
+		MaxAge:    types.Duration(time.Minute),
+	})
+	service.now = func() time.Time { return now }
+
+	service.AddSource("binance", staticTicker(types.Ticker{Time: now, Buy: 99.0, Sell: 101.0, Last: 90.0}))
+	service.AddSource("ftx", staticTicker(types.Ticker{Time: now, Buy: 101.0, Sell: 103.0}))
+	service.AddSource("max", staticTicker(types.Ticker{Time: now.Add(-time.Hour), Buy: 200.0, Sell: 202.0}))
+	service.AddSource("okex", tickerQuerierFunc(func(ctx context.Context, symbol string) (*types.Ticker, error) {
+		return nil, errors.New("timeout")
+	}))
+
+	_, ok := service.Last()
+	assert.False(t, ok)
+
+	var updates []IndexPrice
+	service.OnUpdate(func(price IndexPrice) {
+		updates = append(updates, price)
+	})
+
+	index, err := service.Update(context.Background())
+	if assert.NoError(t, err) {
+		assert.Equal(t, 101.0, index.Price)
+		assert.Equal(t, now, index.Time)
+		if assert.Len(t, index.Sources, 2) {
+			assert.Equal(t, "binance", index.Sources[0].Session)
+			assert.Equal(t, 100.0, index.Sources[0].Price)
+		}
+	}
+
+	last, ok := service.Last()
+	assert.True(t, ok)
+	assert.Equal(t, 101.0, last.Price)
+	assert.Len(t, updates, 1)
+
+	empty := NewIndexPriceService(IndexPriceConfig{Symbol: "BTCUSDT"})
+	_, err = empty.Update(context.Background())
+	assert.Error(t, err)
+}
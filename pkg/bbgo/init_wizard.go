@@ -0,0 +1,450 @@
+package bbgo
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// InitExchanges are the exchanges that can be set up by the init wizard
+var InitExchanges = []types.ExchangeName{
+	types.ExchangeBinance,
+	types.ExchangeMax,
+	types.ExchangeFTX,
+	types.ExchangeOKEx,
+	types.ExchangeBitfinex,
+}
+
+// InitStrategyParam is a parameter of the example strategy, the value is asked by the init wizard
+type InitStrategyParam struct {
+	Name     string
+	Question string
+	Default  string
+}
+
+// InitStrategy is an example strategy of the init wizard
+type InitStrategy struct {
+	ID          string
+	Description string
+	Params      []InitStrategyParam
+}
+
+// InitStrategies are the example strategies of the init wizard, the symbol parameter is asked separately
+var InitStrategies = []InitStrategy{
+	{
+		ID:          "pricealert",
+		Description: "notify when the price of a kline changes a lot, no order is submitted",
+		Params: []InitStrategyParam{
+			{Name: "interval", Question: "kline interval", Default: "1m"},
+			{Name: "minChange", Question: "minimal price change to notify", Default: "100.0"},
+		},
+	},
+	{
+		ID:          "dca",
+		Description: "buy a fixed quote amount periodically (dollar cost averaging)",
+		Params: []InitStrategyParam{
+			{Name: "schedule", Question: "cron schedule of the buys", Default: "0 8 * * *"},
+			{Name: "amount", Question: "quote amount of each buy", Default: "20.0"},
+			{Name: "budget", Question: "stop buying after spending", Default: "1000.0"},
+		},
+	},
+}
+
+func findInitStrategy(id string) (InitStrategy, bool) {
+	for _, strategy := range InitStrategies {
+		if strategy.ID == id {
+			return strategy, true
+		}
+	}
+
+	return InitStrategy{}, false
+}
+
+// InitOptions are the answers of the init wizard, they are used for generating bbgo.yaml and .env.local
+type InitOptions struct {
+	SessionName  string
+	Exchange     types.ExchangeName
+	EnvVarPrefix string
+
+	APIKey        string
+	APISecret     string
+	APIPassphrase string
+	SubAccount    string
+
+	Symbol string
+
+	// Persistence is "json", "redis" or empty for no persistence
+	Persistence string
+
+	SlackToken   string
+	SlackChannel string
+
+	TelegramBotToken string
+
+	// Strategy is the ID of the example strategy, no strategy is added if it's empty
+	Strategy       string
+	StrategyParams map[string]string
+}
+
+func (o *InitOptions) Validate() error {
+	if len(o.SessionName) == 0 {
+		return errors.New("session name is required")
+	}
+
+	if _, err := types.ValidExchangeName(o.Exchange.String()); err != nil {
+		return err
+	}
+
+	if len(o.EnvVarPrefix) == 0 {
+		return errors.New("env var prefix is required")
+	}
+
+	switch o.Persistence {
+	case "", "json", "redis":
+	default:
+		return fmt.Errorf("unsupported persistence: %s", o.Persistence)
+	}
+
+	if len(o.Strategy) > 0 {
+		if _, ok := findInitStrategy(o.Strategy); !ok {
+			return fmt.Errorf("example strategy %s is not found", o.Strategy)
+		}
+
+		if len(o.Symbol) == 0 {
+			return errors.New("symbol is required by the example strategy")
+		}
+	}
+
+	return nil
+}
+
+// initConfigSection is a top-level section of the generated config, it's encoded with the comment
+type initConfigSection struct {
+	comment string
+	value   interface{}
+}
+
+// GenerateInitConfig generates the content of bbgo.yaml and the variables of the dotenv file,
+// the api credentials are written to the dotenv file only.
+func GenerateInitConfig(o InitOptions) (config []byte, envVars map[string]string, err error) {
+	if err := o.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	prefix := strings.ToUpper(o.EnvVarPrefix)
+	envVars = map[string]string{
+		prefix + "_API_KEY":    o.APIKey,
+		prefix + "_API_SECRET": o.APISecret,
+	}
+
+	if len(o.APIPassphrase) > 0 {
+		envVars[prefix+"_API_PASSPHRASE"] = o.APIPassphrase
+	}
+
+	if len(o.SubAccount) > 0 {
+		envVars[prefix+"_SUBACCOUNT"] = o.SubAccount
+	}
+
+	if len(o.SlackToken) > 0 {
+		envVars["SLACK_TOKEN"] = o.SlackToken
+	}
+
+	if len(o.TelegramBotToken) > 0 {
+		envVars["TELEGRAM_BOT_TOKEN"] = o.TelegramBotToken
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# generated by bbgo init, see the config directory of the bbgo repository for more examples\n---\n")
+
+	// the sections are encoded separately to keep them in the reading order
+	var sections = []initConfigSection{
+		{
+			comment: fmt.Sprintf("# the api key and secret are loaded from %s_API_KEY and %s_API_SECRET of the dotenv file", prefix, prefix),
+			value: Stash{"sessions": Stash{
+				o.SessionName: Stash{
+					"exchange":     o.Exchange.String(),
+					"envVarPrefix": o.EnvVarPrefix,
+				},
+			}},
+		},
+	}
+
+	if len(o.SlackToken) > 0 && len(o.SlackChannel) > 0 {
+		sections = append(sections, initConfigSection{
+			comment: "# the slack token is loaded from SLACK_TOKEN of the dotenv file",
+			value: Stash{"notifications": NotificationConfig{
+				Slack: &SlackNotification{DefaultChannel: o.SlackChannel, ErrorChannel: o.SlackChannel},
+			}},
+		})
+	}
+
+	switch o.Persistence {
+	case "json":
+		sections = append(sections, initConfigSection{
+			comment: "# the strategy states are saved in the json files",
+			value: Stash{"persistence": PersistenceConfig{
+				Json: &service.JsonPersistenceConfig{Directory: "var/data"},
+			}},
+		})
+
+	case "redis":
+		sections = append(sections, initConfigSection{
+			comment: "# the strategy states are saved in redis",
+			value: Stash{"persistence": PersistenceConfig{
+				Redis: &service.RedisPersistenceConfig{Host: "127.0.0.1", Port: "6379", DB: 0},
+			}},
+		})
+	}
+
+	if strategy, ok := findInitStrategy(o.Strategy); ok {
+		// the parameters are kept in the order of the questions
+		var params = []interface{}{"symbol", o.Symbol}
+		for _, param := range strategy.Params {
+			value, ok := o.StrategyParams[param.Name]
+			if !ok {
+				value = param.Default
+			}
+
+			params = append(params, param.Name, parseInitValue(value))
+		}
+
+		paramsNode, err := orderedMapNode(params...)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		mountNode, err := orderedMapNode("on", o.SessionName, strategy.ID, paramsNode)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		sections = append(sections, initConfigSection{
+			comment: fmt.Sprintf("# %s: %s", strategy.ID, strategy.Description),
+			value:   Stash{"exchangeStrategies": []*yaml.Node{mountNode}},
+		})
+	}
+
+	for _, section := range sections {
+		out, err := encodeInitYAML(section.value)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		buf.WriteString("\n" + section.comment + "\n")
+		buf.Write(out)
+	}
+
+	return buf.Bytes(), envVars, nil
+}
+
+// parseInitValue converts the numbers so that they are not quoted in the yaml
+func parseInitValue(value string) interface{} {
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f
+	}
+
+	return value
+}
+
+// orderedMapNode encodes the key value pairs as a yaml mapping, the keys are not sorted like the maps
+func orderedMapNode(pairs ...interface{}) (*yaml.Node, error) {
+	var node = &yaml.Node{Kind: yaml.MappingNode}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		valueNode, ok := pairs[i+1].(*yaml.Node)
+		if !ok {
+			valueNode = &yaml.Node{}
+			if err := valueNode.Encode(pairs[i+1]); err != nil {
+				return nil, err
+			}
+		}
+
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: fmt.Sprint(pairs[i])}, valueNode)
+	}
+
+	return node, nil
+}
+
+func encodeInitYAML(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	var enc = yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(value); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// InitWizard asks the questions of the init options from the reader, the questions are written to the writer
+type InitWizard struct {
+	reader *bufio.Reader
+	out    io.Writer
+}
+
+func NewInitWizard(in io.Reader, out io.Writer) *InitWizard {
+	return &InitWizard{
+		reader: bufio.NewReader(in),
+		out:    out,
+	}
+}
+
+// Run asks the questions and returns the options, the default value is used if the answer is empty
+func (w *InitWizard) Run() (*InitOptions, error) {
+	var o = InitOptions{StrategyParams: make(map[string]string)}
+	var err error
+
+	var exchanges []string
+	for _, exchange := range InitExchanges {
+		exchanges = append(exchanges, exchange.String())
+	}
+
+	exchange, err := w.choose("Exchange", exchanges, exchanges[0])
+	if err != nil {
+		return nil, err
+	}
+	o.Exchange = types.ExchangeName(exchange)
+
+	if o.SessionName, err = w.ask("Session name", exchange); err != nil {
+		return nil, err
+	}
+
+	if o.EnvVarPrefix, err = w.ask("Env var prefix of the api credentials", exchange); err != nil {
+		return nil, err
+	}
+
+	w.println("The api key and secret are saved in the dotenv file, leave them empty to fill them in later.")
+	if o.APIKey, err = w.ask("API key", ""); err != nil {
+		return nil, err
+	}
+
+	if o.APISecret, err = w.ask("API secret", ""); err != nil {
+		return nil, err
+	}
+
+	switch o.Exchange {
+	case types.ExchangeOKEx:
+		if o.APIPassphrase, err = w.ask("API passphrase", ""); err != nil {
+			return nil, err
+		}
+
+	case types.ExchangeFTX:
+		if o.SubAccount, err = w.ask("Sub-account (optional)", ""); err != nil {
+			return nil, err
+		}
+	}
+
+	if o.Persistence, err = w.choose("Persistence of the strategy states", []string{"json", "redis", "none"}, "json"); err != nil {
+		return nil, err
+	}
+	if o.Persistence == "none" {
+		o.Persistence = ""
+	}
+
+	notification, err := w.choose("Notification", []string{"none", "slack", "telegram"}, "none")
+	if err != nil {
+		return nil, err
+	}
+
+	switch notification {
+	case "slack":
+		if o.SlackToken, err = w.ask("Slack bot token", ""); err != nil {
+			return nil, err
+		}
+
+		if o.SlackChannel, err = w.ask("Slack channel", "bbgo"); err != nil {
+			return nil, err
+		}
+
+	case "telegram":
+		if o.TelegramBotToken, err = w.ask("Telegram bot token", ""); err != nil {
+			return nil, err
+		}
+	}
+
+	var strategies = []string{"none"}
+	for _, strategy := range InitStrategies {
+		w.println(fmt.Sprintf("  %s: %s", strategy.ID, strategy.Description))
+		strategies = append(strategies, strategy.ID)
+	}
+
+	strategyID, err := w.choose("Example strategy", strategies, InitStrategies[0].ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if strategy, ok := findInitStrategy(strategyID); ok {
+		o.Strategy = strategy.ID
+
+		symbol, err := w.ask("Symbol", "BTCUSDT")
+		if err != nil {
+			return nil, err
+		}
+		o.Symbol = strings.ToUpper(symbol)
+
+		for _, param := range strategy.Params {
+			value, err := w.ask(param.Question, param.Default)
+			if err != nil {
+				return nil, err
+			}
+
+			o.StrategyParams[param.Name] = value
+		}
+	}
+
+	return &o, o.Validate()
+}
+
+func (w *InitWizard) println(s string) {
+	fmt.Fprintln(w.out, s)
+}
+
+func (w *InitWizard) ask(question, defaultValue string) (string, error) {
+	if len(defaultValue) > 0 {
+		fmt.Fprintf(w.out, "%s [%s]: ", question, defaultValue)
+	} else {
+		fmt.Fprintf(w.out, "%s: ", question)
+	}
+
+	answer, err := w.reader.ReadString('\n')
+	if err != nil && !(err == io.EOF && len(answer) > 0) {
+		return "", err
+	}
+
+	answer = strings.TrimSpace(answer)
+	if len(answer) == 0 {
+		return defaultValue, nil
+	}
+
+	return answer, nil
+}
+
+// choose asks again until the answer is one of the options
+func (w *InitWizard) choose(question string, options []string, defaultValue string) (string, error) {
+	for {
+		answer, err := w.ask(fmt.Sprintf("%s (%s)", question, strings.Join(options, "/")), defaultValue)
+		if err != nil {
+			return "", err
+		}
+
+		for _, option := range options {
+			if strings.EqualFold(answer, option) {
+				return option, nil
+			}
+		}
+
+		w.println(fmt.Sprintf("%q is not one of %s", answer, strings.Join(options, ", ")))
+	}
+}
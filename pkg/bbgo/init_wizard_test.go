@@ -0,0 +1,131 @@
+package bbgo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestInitWizard_Run(t *testing.T) {
+	input := strings.Join([]string{
+		"kraken", // not supported, asked again
+		"max",
+		"",
+		"",
+		"key",
+		"secret",
+		"redis",
+		"slack",
+		"xoxb-token",
+		"#bbgo",
+		"dca",
+		"ethusdt",
+		"",
+		"50",
+		"",
+	}, "\n") + "\n"
+
+	var out bytes.Buffer
+	options, err := NewInitWizard(strings.NewReader(input), &out).Run()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, &InitOptions{
+		SessionName:    "max",
+		Exchange:       types.ExchangeMax,
+		EnvVarPrefix:   "max",
+		APIKey:         "key",
+		APISecret:      "secret",
+		Symbol:         "ETHUSDT",
+		Persistence:    "redis",
+		SlackToken:     "xoxb-token",
+		SlackChannel:   "#bbgo",
+		Strategy:       "dca",
+		StrategyParams: map[string]string{"schedule": "0 8 * * *", "amount": "50", "budget": "1000.0"},
+	}, options)
+	assert.Contains(t, out.String(), `"kraken" is not one of`)
+
+	// the input is closed before all the questions are answered
+	_, err = NewInitWizard(strings.NewReader("binance\n"), &out).Run()
+	assert.Error(t, err)
+}
+
+func TestGenerateInitConfig(t *testing.T) {
+	config, envVars, err := GenerateInitConfig(InitOptions{
+		SessionName:    "max",
+		Exchange:       types.ExchangeMax,
+		EnvVarPrefix:   "max",
+		APIKey:         "key",
+		APISecret:      "secret",
+		Symbol:         "ETHUSDT",
+		Persistence:    "redis",
+		SlackToken:     "xoxb-token",
+		SlackChannel:   "#bbgo",
+		Strategy:       "dca",
+		StrategyParams: map[string]string{"amount": "50"},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, map[string]string{
+		"MAX_API_KEY":    "key",
+		"MAX_API_SECRET": "secret",
+		"SLACK_TOKEN":    "xoxb-token",
+	}, envVars)
+
+	assert.Equal(t, `# generated by bbgo init, see the config directory of the bbgo repository for more examples
+---
+
+# the api key and secret are loaded from MAX_API_KEY and MAX_API_SECRET of the dotenv file
+sessions:
+  max:
+    envVarPrefix: max
+    exchange: max
+
+# the slack token is loaded from SLACK_TOKEN of the dotenv file
+notifications:
+  slack:
+    defaultChannel: '#bbgo'
+    errorChannel: '#bbgo'
+
+# the strategy states are saved in redis
+persistence:
+  redis:
+    host: 127.0.0.1
+    port: "6379"
+    db: 0
+
+# dca: buy a fixed quote amount periodically (dollar cost averaging)
+exchangeStrategies:
+  - on: max
+    dca:
+      symbol: ETHUSDT
+      schedule: 0 8 * * *
+      amount: 50
+      budget: 1000
+`, string(config))
+
+	// the generated config can be loaded
+	var loaded Config
+	if assert.NoError(t, yaml.Unmarshal(config, &loaded)) {
+		if assert.Contains(t, loaded.Sessions, "max") {
+			assert.Equal(t, types.ExchangeMax, loaded.Sessions["max"].ExchangeName)
+		}
+		if assert.NotNil(t, loaded.Persistence) && assert.NotNil(t, loaded.Persistence.Redis) {
+			assert.Equal(t, "6379", loaded.Persistence.Redis.Port)
+		}
+	}
+
+	_, _, err = GenerateInitConfig(InitOptions{SessionName: "max", Exchange: "kraken", EnvVarPrefix: "max"})
+	assert.Error(t, err)
+
+	_, _, err = GenerateInitConfig(InitOptions{SessionName: "max", Exchange: types.ExchangeMax, EnvVarPrefix: "max", Strategy: "dca"})
+	assert.Error(t, err, "the symbol is required by the strategy")
+}
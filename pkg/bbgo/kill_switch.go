@@ -0,0 +1,355 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+const defaultKillSwitchCheckInterval = 10 * time.Second
+
+var ErrKillSwitchTripped = errors.New("order submission is halted by the kill switch")
+
+// KillSwitchConfig configures the daily loss circuit breaker
+type KillSwitchConfig struct {
+	// MaxDailyLoss is the loss threshold (a positive number) of the realized and unrealized profit of the day
+	MaxDailyLoss fixedpoint.Value `json:"maxDailyLoss" yaml:"maxDailyLoss"`
+
+	// Currency is the quote currency of the counted positions, e.g., USDT, empty means all the positions are counted
+	Currency string `json:"currency,omitempty" yaml:"currency,omitempty"`
+
+	// Sessions are the watched sessions, empty means all the sessions
+	Sessions []string `json:"sessions,omitempty" yaml:"sessions,omitempty"`
+
+	// ClosePositions flattens the session positions by the market orders when the kill switch is tripped
+	ClosePositions bool `json:"closePositions,omitempty" yaml:"closePositions,omitempty"`
+
+	// CheckInterval defaults to 10s
+	CheckInterval types.Duration `json:"checkInterval,omitempty" yaml:"checkInterval,omitempty"`
+}
+
+func (c *KillSwitchConfig) Validate() error {
+	if c.MaxDailyLoss <= 0 {
+		return errors.New("maxDailyLoss of the kill switch should be greater than zero")
+	}
+
+	if c.CheckInterval < 0 {
+		return errors.New("checkInterval of the kill switch can not be negative")
+	}
+
+	return nil
+}
+
+// KillSwitchState is the persisted state of the kill switch, so the halt and the loss of the day survive the restart
+type KillSwitchState struct {
+	Tripped   bool      `json:"tripped"`
+	TrippedAt time.Time `json:"trippedAt,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+
+	// Day is the beginning of the current day, Baseline is the profit of the positions at the beginning of the day
+	Day      time.Time `json:"day,omitempty"`
+	Baseline float64   `json:"baseline,omitempty"`
+}
+
+// KillSwitch tracks the realized and unrealized profit of the session positions by day, when the loss of the day
+// crosses the threshold, it cancels all the open orders, optionally flattens the positions, and halts the order
+// submission of the strategies until it's reset manually (the reset_kill_switch command).
+type KillSwitch struct {
+	*Notifiability
+
+	Config KillSwitchConfig
+
+	store service.Store
+
+	mu       sync.Mutex
+	sessions map[string]*ExchangeSession
+	state    KillSwitchState
+}
+
+func NewKillSwitch(config KillSwitchConfig, notifiability *Notifiability, store service.Store) *KillSwitch {
+	if config.CheckInterval == 0 {
+		config.CheckInterval = types.Duration(defaultKillSwitchCheckInterval)
+	}
+
+	k := &KillSwitch{
+		Notifiability: notifiability,
+		Config:        config,
+		store:         store,
+		sessions:      make(map[string]*ExchangeSession),
+	}
+
+	if store != nil {
+		if err := store.Load(&k.state); err != nil && err != service.ErrPersistenceNotExists {
+			log.WithError(err).Error("can not load the kill switch state")
+		}
+
+		if k.state.Tripped {
+			log.Warnf("the kill switch was tripped at %s: %s, the order submission is halted until it's reset", k.state.TrippedAt, k.state.Reason)
+		}
+	}
+
+	return k
+}
+
+// Watches returns true if the session is watched by the kill switch
+func (k *KillSwitch) Watches(sessionName string) bool {
+	return len(k.Config.Sessions) == 0 || containsSymbol(k.Config.Sessions, sessionName)
+}
+
+// AddSession adds the session to watch, the sessions not in the configured sessions are ignored
+func (k *KillSwitch) AddSession(session *ExchangeSession) {
+	if !k.Watches(session.Name) {
+		return
+	}
+
+	k.mu.Lock()
+	k.sessions[session.Name] = session
+	k.mu.Unlock()
+}
+
+// Tripped returns true if the order submission is halted
+func (k *KillSwitch) Tripped() bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.state.Tripped
+}
+
+func (k *KillSwitch) State() KillSwitchState {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.state
+}
+
+// Profit returns the accumulated realized profit and the unrealized profit at the last price of the watched positions
+func (k *KillSwitch) Profit() float64 {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.profit()
+}
+
+func (k *KillSwitch) profit() float64 {
+	var profit float64
+	for _, session := range k.sessions {
+		for symbol, position := range session.Positions() {
+			snapshot := position.Snapshot()
+			if len(k.Config.Currency) > 0 && snapshot.QuoteCurrency != k.Config.Currency {
+				continue
+			}
+
+			profit += snapshot.RealizedProfit.Float64()
+			if unrealizedProfit, ok := session.UnrealizedProfit(symbol); ok {
+				profit += unrealizedProfit.Float64()
+			}
+		}
+	}
+
+	return profit
+}
+
+// DailyProfit returns the profit since the beginning of the day, the baseline is reset when the day changes
+func (k *KillSwitch) DailyProfit(now time.Time) float64 {
+	k.mu.Lock()
+	dailyProfit, dayChanged := k.dailyProfit(now)
+	k.mu.Unlock()
+
+	if dayChanged {
+		k.save()
+	}
+
+	return dailyProfit
+}
+
+// dailyProfit returns the profit since the beginning of the day, and true if the baseline is reset by the new day.
+// The baseline restored from the store is kept if it's still the same day.
+func (k *KillSwitch) dailyProfit(now time.Time) (float64, bool) {
+	profit := k.profit()
+	if day := util.BeginningOfTheDay(now.Local()); !day.Equal(k.state.Day) {
+		k.state.Day = day
+		k.state.Baseline = profit
+		return 0, true
+	}
+
+	return profit - k.state.Baseline, false
+}
+
+// Check trips the kill switch if the loss of the day crosses the threshold, it returns true if the kill switch is tripped
+func (k *KillSwitch) Check(ctx context.Context, now time.Time) bool {
+	k.mu.Lock()
+	dailyProfit, dayChanged := k.dailyProfit(now)
+	tripped := k.state.Tripped
+	k.mu.Unlock()
+
+	if dayChanged {
+		k.save()
+	}
+
+	if tripped {
+		return true
+	}
+
+	if dailyProfit > -k.Config.MaxDailyLoss.Float64() {
+		return false
+	}
+
+	k.Trip(ctx, now, fmt.Sprintf("the daily loss %f crossed the max daily loss %f", -dailyProfit, k.Config.MaxDailyLoss.Float64()))
+	return true
+}
+
+// Trip halts the order submission, cancels the open orders and flattens the positions if it's configured
+func (k *KillSwitch) Trip(ctx context.Context, now time.Time, reason string) {
+	k.mu.Lock()
+	k.state.Tripped = true
+	k.state.TrippedAt = now
+	k.state.Reason = reason
+	sessions := make([]*ExchangeSession, 0, len(k.sessions))
+	for _, session := range k.sessions {
+		sessions = append(sessions, session)
+	}
+	k.mu.Unlock()
+
+	k.save()
+
+	log.Errorf("kill switch is tripped: %s", reason)
+	k.notify(":rotating_light: kill switch is tripped: %s, the order submission is halted until it's reset", reason)
+
+	for _, session := range sessions {
+		k.cancelOpenOrders(ctx, session)
+
+		if k.Config.ClosePositions {
+			k.closePositions(ctx, session)
+		}
+	}
+}
+
+// Reset resumes the order submission, the loss of the day is counted from now on
+func (k *KillSwitch) Reset() {
+	k.mu.Lock()
+	k.state = KillSwitchState{
+		Day:      util.BeginningOfTheDay(time.Now().Local()),
+		Baseline: k.profit(),
+	}
+	k.mu.Unlock()
+
+	k.save()
+
+	log.Info("kill switch is reset")
+	k.notify(":white_check_mark: kill switch is reset, the order submission is resumed")
+}
+
+func (k *KillSwitch) save() {
+	if k.store == nil {
+		return
+	}
+
+	state := k.State()
+	if err := k.store.Save(&state); err != nil {
+		log.WithError(err).Error("can not save the kill switch state")
+	}
+}
+
+func (k *KillSwitch) notify(format string, args ...interface{}) {
+	if k.Notifiability != nil {
+		k.Notify(format, args...)
+	}
+}
+
+// killSwitchSymbols returns the symbols of the positions and the order stores of the session
+func killSwitchSymbols(session *ExchangeSession) []string {
+	var symbols []string
+	var seen = make(map[string]struct{})
+	for symbol := range session.Positions() {
+		seen[symbol] = struct{}{}
+		symbols = append(symbols, symbol)
+	}
+
+	for symbol := range session.OrderStores() {
+		if _, ok := seen[symbol]; !ok {
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	return symbols
+}
+
+func (k *KillSwitch) cancelOpenOrders(ctx context.Context, session *ExchangeSession) {
+	for _, symbol := range killSwitchSymbols(session) {
+		canceledOrders, err := session.CancelAllOrders(ctx, symbol)
+		if err != nil {
+			log.WithError(err).Errorf("kill switch can not cancel the open orders of %s on session %s", symbol, session.Name)
+			k.notify(":warning: kill switch can not cancel the open orders of %s on session %s: %v", symbol, session.Name, err)
+			continue
+		}
+
+		if len(canceledOrders) > 0 {
+			k.notify("kill switch canceled %d open orders of %s on session %s", len(canceledOrders), symbol, session.Name)
+		}
+	}
+}
+
+func (k *KillSwitch) closePositions(ctx context.Context, session *ExchangeSession) {
+	for symbol, position := range session.Positions() {
+		snapshot := position.Snapshot()
+		if len(k.Config.Currency) > 0 && snapshot.QuoteCurrency != k.Config.Currency {
+			continue
+		}
+
+		// the orders are submitted to the exchange directly since the order executors are halted
+		createdOrders, err := session.ClosePosition(ctx, symbol, 1.0)
+		if err != nil {
+			log.WithError(err).Errorf("kill switch can not close the %s position on session %s", symbol, session.Name)
+			k.notify(":warning: kill switch can not close the %s position on session %s: %v", symbol, session.Name, err)
+			continue
+		}
+
+		if len(createdOrders) > 0 {
+			k.notify("kill switch closed the %s position %f on session %s", symbol, snapshot.Base.Float64(), session.Name)
+		}
+	}
+}
+
+// Run checks the daily loss periodically until the context is done
+func (k *KillSwitch) Run(ctx context.Context) {
+	ticker := time.NewTicker(k.Config.CheckInterval.Duration())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case now := <-ticker.C:
+			k.Check(ctx, now)
+		}
+	}
+}
+
+// KillSwitchOrderExecutor rejects the orders while the kill switch is tripped
+type KillSwitchOrderExecutor struct {
+	OrderExecutor
+
+	killSwitch *KillSwitch
+}
+
+func NewKillSwitchOrderExecutor(executor OrderExecutor, killSwitch *KillSwitch) *KillSwitchOrderExecutor {
+	return &KillSwitchOrderExecutor{
+		OrderExecutor: executor,
+		killSwitch:    killSwitch,
+	}
+}
+
+func (e *KillSwitchOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	if e.killSwitch.Tripped() {
+		return nil, ErrKillSwitchTripped
+	}
+
+	return e.OrderExecutor.SubmitOrders(ctx, orders...)
+}
@@ -0,0 +1,137 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type killSwitchTestExchange struct {
+	types.Exchange
+
+	openOrders      []types.Order
+	canceledOrders  []types.Order
+	submittedOrders []types.SubmitOrder
+}
+
+func (e *killSwitchTestExchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	for _, o := range e.openOrders {
+		if o.Symbol == symbol {
+			orders = append(orders, o)
+		}
+	}
+
+	return orders, nil
+}
+
+func (e *killSwitchTestExchange) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	e.canceledOrders = append(e.canceledOrders, orders...)
+	return nil
+}
+
+func (e *killSwitchTestExchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	e.submittedOrders = append(e.submittedOrders, orders...)
+	return nil, nil
+}
+
+func newKillSwitchTestSession(exchange types.Exchange) *ExchangeSession {
+	market := types.Market{
+		Symbol:          "BTCUSDT",
+		BaseCurrency:    "BTC",
+		QuoteCurrency:   "USDT",
+		MinQuantity:     0.0001,
+		StepSize:        0.0001,
+		TickSize:        0.01,
+		PricePrecision:  2,
+		VolumePrecision: 4,
+	}
+
+	position := types.NewPositionFromMarket(market)
+	position.AddTrade(types.Trade{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Price: 10000.0, Quantity: 0.5, QuoteQuantity: 5000.0})
+
+	return &ExchangeSession{
+		Name:        "binance",
+		Exchange:    exchange,
+		markets:     map[string]types.Market{"BTCUSDT": market},
+		positions:   map[string]*types.Position{"BTCUSDT": position},
+		orderStores: make(map[string]*OrderStore),
+		lastPrices:  map[string]float64{"BTCUSDT": 10000.0},
+	}
+}
+
+func TestKillSwitch_Check(t *testing.T) {
+	exchange := &killSwitchTestExchange{
+		openOrders: []types.Order{{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell}, OrderID: 1}},
+	}
+	session := newKillSwitchTestSession(exchange)
+
+	notifier := &profitReportTestNotifier{}
+	notifiability := &Notifiability{}
+	notifiability.AddNotifier(notifier)
+
+	store := service.NewMemoryService().NewStore("bbgo", "kill_switch")
+	killSwitch := NewKillSwitch(KillSwitchConfig{
+		MaxDailyLoss:   fixedpoint.NewFromFloat(100.0),
+		Currency:       "USDT",
+		ClosePositions: true,
+	}, notifiability, store)
+	killSwitch.AddSession(session)
+
+	ctx := context.Background()
+	now := time.Date(2021, 12, 20, 10, 0, 0, 0, time.Local)
+	assert.False(t, killSwitch.Check(ctx, now), "the baseline of the day is set")
+
+	// the unrealized loss is 0.5 * 150 = 75
+	session.lastPrices["BTCUSDT"] = 9850.0
+	assert.False(t, killSwitch.Check(ctx, now.Add(time.Hour)))
+	assert.Equal(t, -75.0, killSwitch.DailyProfit(now.Add(time.Hour)))
+
+	// the new day starts with the new baseline
+	assert.False(t, killSwitch.Check(ctx, now.Add(24*time.Hour)))
+
+	session.lastPrices["BTCUSDT"] = 9600.0
+	assert.True(t, killSwitch.Check(ctx, now.Add(25*time.Hour)))
+	assert.True(t, killSwitch.Tripped())
+
+	assert.Len(t, exchange.canceledOrders, 1, "the open orders are canceled")
+	if assert.Len(t, exchange.submittedOrders, 1, "the position is closed") {
+		assert.Equal(t, types.SideTypeSell, exchange.submittedOrders[0].Side)
+		assert.Equal(t, types.OrderTypeMarket, exchange.submittedOrders[0].Type)
+		assert.Equal(t, 0.5, exchange.submittedOrders[0].Quantity)
+	}
+	assert.NotEmpty(t, notifier.objects)
+
+	// the order submission is halted until the kill switch is reset
+	executor := NewKillSwitchOrderExecutor(&recordOrderExecutor{ExchangeOrderExecutor: &ExchangeOrderExecutor{}}, killSwitch)
+	_, err := executor.SubmitOrders(ctx, types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeMarket, Quantity: 0.1})
+	assert.Equal(t, ErrKillSwitchTripped, err)
+
+	// the tripped state and the baseline of the day are restored from the store
+	restored := NewKillSwitch(killSwitch.Config, nil, store)
+	restored.AddSession(session)
+	assert.True(t, restored.Tripped())
+	assert.Equal(t, -125.0, restored.DailyProfit(now.Add(26*time.Hour)))
+
+	killSwitch.Reset()
+	assert.False(t, killSwitch.Tripped())
+	assert.False(t, killSwitch.Check(ctx, time.Now()), "the loss is counted from the reset")
+
+	createdOrders, err := executor.SubmitOrders(ctx, types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeMarket, Quantity: 0.1})
+	assert.NoError(t, err)
+	assert.Len(t, createdOrders, 1)
+}
+
+func TestKillSwitch_Watches(t *testing.T) {
+	killSwitch := NewKillSwitch(KillSwitchConfig{MaxDailyLoss: fixedpoint.NewFromFloat(100.0), Sessions: []string{"binance"}}, nil, nil)
+	assert.True(t, killSwitch.Watches("binance"))
+	assert.False(t, killSwitch.Watches("max"))
+
+	killSwitch.AddSession(&ExchangeSession{Name: "max"})
+	assert.Equal(t, 0.0, killSwitch.Profit())
+}
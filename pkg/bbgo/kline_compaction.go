@@ -0,0 +1,91 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultKLineCompactionPeriod = time.Hour
+
+var defaultKLineCompactionIntervals = []types.Interval{types.Interval5m, types.Interval1h, types.Interval1d}
+
+// KLineCompactionConfig builds the klines of the higher intervals from the stored 1m klines in the background
+type KLineCompactionConfig struct {
+	// Session is the session of the exchange that the stored klines belong to
+	Session string `json:"session" yaml:"session"`
+
+	Symbols []string `json:"symbols" yaml:"symbols"`
+
+	// Intervals is the intervals to build, defaults to 5m, 1h and 1d
+	Intervals []types.Interval `json:"intervals,omitempty" yaml:"intervals,omitempty"`
+
+	// Period is the period of the compaction job, defaults to 1h
+	Period types.Duration `json:"period,omitempty" yaml:"period,omitempty"`
+
+	// VerifyTolerance verifies the compacted klines with the klines of the exchange by the relative tolerance,
+	// e.g., 0.0001, zero disables the verification
+	VerifyTolerance float64 `json:"verifyTolerance,omitempty" yaml:"verifyTolerance,omitempty"`
+}
+
+func (c *KLineCompactionConfig) Validate() error {
+	if len(c.Session) == 0 {
+		return errors.New("kline compaction session is required")
+	}
+
+	if len(c.Symbols) == 0 {
+		return errors.New("kline compaction symbols are required")
+	}
+
+	for _, interval := range c.Intervals {
+		if interval.Duration() <= types.Interval1m.Duration() {
+			return fmt.Errorf("kline compaction interval %s should be greater than 1m", interval)
+		}
+	}
+
+	if c.VerifyTolerance < 0 {
+		return errors.New("kline compaction verifyTolerance can not be negative")
+	}
+
+	return nil
+}
+
+// ConfigureKLineCompaction starts the kline compaction job of the stored klines, the database is required
+func (environ *Environment) ConfigureKLineCompaction(ctx context.Context, config *KLineCompactionConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	if environ.DatabaseService == nil {
+		return errors.New("kline compaction requires the database, please check the DB_DRIVER and DB_DSN environment variables")
+	}
+
+	session, ok := environ.Session(config.Session)
+	if !ok {
+		return fmt.Errorf("session %s of the kline compaction is not found", config.Session)
+	}
+
+	intervals := config.Intervals
+	if len(intervals) == 0 {
+		intervals = defaultKLineCompactionIntervals
+	}
+
+	period := config.Period.Duration()
+	if period == 0 {
+		period = defaultKLineCompactionPeriod
+	}
+
+	compactor := &service.KLineCompactor{
+		Store: &service.BacktestService{DB: environ.DatabaseService.DB},
+	}
+
+	log.Infof("kline compaction of %v %v is configured", config.Symbols, intervals)
+	go compactor.Run(ctx, session.Exchange, config.Symbols, intervals, period, config.VerifyTolerance)
+	return nil
+}
@@ -0,0 +1,31 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestKLineCompactionConfig_Validate(t *testing.T) {
+	config := &KLineCompactionConfig{Session: "binance", Symbols: []string{"BTCUSDT"}}
+	assert.NoError(t, config.Validate())
+
+	config.Intervals = []types.Interval{types.Interval5m, types.Interval1m}
+	assert.Error(t, config.Validate())
+
+	config.Intervals = []types.Interval{types.Interval1h}
+	config.VerifyTolerance = -0.1
+	assert.Error(t, config.Validate())
+
+	assert.Error(t, (&KLineCompactionConfig{Symbols: []string{"BTCUSDT"}}).Validate())
+	assert.Error(t, (&KLineCompactionConfig{Session: "binance"}).Validate())
+}
+
+func TestEnvironment_ConfigureKLineCompaction(t *testing.T) {
+	environ := NewEnvironment()
+	err := environ.ConfigureKLineCompaction(context.Background(), &KLineCompactionConfig{Session: "binance", Symbols: []string{"BTCUSDT"}})
+	assert.Error(t, err, "the database is required")
+}
@@ -0,0 +1,437 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// ShutdownOptions tells the strategies how to clean up on shutdown
+type ShutdownOptions struct {
+	// KeepOrders keeps the working orders of the strategies on the exchange, the working orders are canceled by default,
+	// so that the restarted strategies do not leave the orphan orders
+	KeepOrders bool `json:"keepOrders,omitempty" yaml:"keepOrders,omitempty"`
+
+	// ClosePosition flattens the positions of the strategies
+	ClosePosition bool `json:"closePosition,omitempty" yaml:"closePosition,omitempty"`
+}
+
+// StrategyShutdowner is the shutdown hook of the strategy, the trader calls the hooks on SIGINT/SIGTERM
+// before the graceful shutdown callbacks (which usually save the strategy states) are called.
+type StrategyShutdowner interface {
+	Shutdown(ctx context.Context, options ShutdownOptions) error
+}
+
+// StrategySuspender is implemented by the strategies that can be suspended, the suspended strategy stops
+// placing new orders and cancels its working orders, and then places its orders again when it's resumed.
+// Unlike Pausable, the hooks receive the context, so the orders can be canceled or placed in the hooks.
+type StrategySuspender interface {
+	Suspend(ctx context.Context) error
+	Resume(ctx context.Context) error
+}
+
+// ShutdownPolicy is what the strategy does with its orders and position on shutdown
+type ShutdownPolicy string
+
+const (
+	ShutdownPolicyCancelOrders  ShutdownPolicy = "cancelOrders"
+	ShutdownPolicyKeepOrders    ShutdownPolicy = "keepOrders"
+	ShutdownPolicyClosePosition ShutdownPolicy = "closePosition"
+)
+
+func (p ShutdownPolicy) Validate() error {
+	switch p {
+	case ShutdownPolicyCancelOrders, ShutdownPolicyKeepOrders, ShutdownPolicyClosePosition:
+		return nil
+	}
+
+	return fmt.Errorf("invalid shutdown policy %q, it should be cancelOrders, keepOrders or closePosition", p)
+}
+
+// Options returns the options of the shutdown hooks, the orders are canceled before the position is closed
+func (p ShutdownPolicy) Options() ShutdownOptions {
+	switch p {
+	case ShutdownPolicyKeepOrders:
+		return ShutdownOptions{KeepOrders: true}
+
+	case ShutdownPolicyClosePosition:
+		return ShutdownOptions{ClosePosition: true}
+	}
+
+	return ShutdownOptions{}
+}
+
+const defaultShutdownTimeout = 30 * time.Second
+
+// ShutdownConfig configures the shutdown of the strategies on SIGINT/SIGTERM, e.g.,
+//
+//	shutdown:
+//	  timeout: 30s
+//	  policy: cancelOrders
+//	  strategies:
+//	    grid:BTCUSDT: keepOrders
+//	    bollmaker: closePosition
+//
+// The strategies implementing StrategyShutdowner receive the options of their policies, the trader cancels the orders
+// submitted by the other single exchange strategies and closes their positions when a policy is configured.
+type ShutdownConfig struct {
+	// ShutdownOptions is passed to the strategies without a policy
+	ShutdownOptions `yaml:",inline"`
+
+	// Timeout is the deadline of the shutdown, default to 30s
+	Timeout types.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+
+	// Policy is the default policy of the strategies
+	Policy ShutdownPolicy `json:"policy,omitempty" yaml:"policy,omitempty"`
+
+	// Strategies overrides the policy by the strategy target, e.g., grid or grid:BTCUSDT
+	Strategies map[string]ShutdownPolicy `json:"strategies,omitempty" yaml:"strategies,omitempty"`
+}
+
+func (c *ShutdownConfig) Validate() error {
+	if c.Timeout < 0 {
+		return fmt.Errorf("shutdown timeout can not be negative")
+	}
+
+	if len(c.Policy) > 0 {
+		if err := c.Policy.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for target, policy := range c.Strategies {
+		if err := policy.Validate(); err != nil {
+			return fmt.Errorf("strategy %s: %w", target, err)
+		}
+	}
+
+	return nil
+}
+
+// hasPolicy returns true if any policy is configured
+func (c *ShutdownConfig) hasPolicy() bool {
+	return len(c.Policy) > 0 || len(c.Strategies) > 0
+}
+
+// options returns the shutdown options of the strategy, the policy of the target with the symbol is preferred
+func (c *ShutdownConfig) options(id, target string) ShutdownOptions {
+	if policy, ok := c.Strategies[target]; ok {
+		return policy.Options()
+	}
+
+	if policy, ok := c.Strategies[id]; ok {
+		return policy.Options()
+	}
+
+	if len(c.Policy) > 0 {
+		return c.Policy.Options()
+	}
+
+	return c.ShutdownOptions
+}
+
+// SetShutdownOptions sets the options passed to the shutdown hooks of the strategies
+func (trader *Trader) SetShutdownOptions(options ShutdownOptions) {
+	trader.shutdownConfig.ShutdownOptions = options
+}
+
+// SetShutdownConfig sets the shutdown policies and the shutdown timeout
+func (trader *Trader) SetShutdownConfig(config ShutdownConfig) {
+	trader.shutdownConfig = config
+}
+
+// ShutdownTimeout returns the deadline of the shutdown
+func (trader *Trader) ShutdownTimeout() time.Duration {
+	if trader.shutdownConfig.Timeout > 0 {
+		return trader.shutdownConfig.Timeout.Duration()
+	}
+
+	return defaultShutdownTimeout
+}
+
+func (trader *Trader) strategies() []interface{} {
+	var strategies []interface{}
+	for _, sessionStrategies := range trader.exchangeStrategies {
+		for _, strategy := range sessionStrategies {
+			strategies = append(strategies, strategy)
+		}
+	}
+
+	for _, strategy := range trader.crossExchangeStrategies {
+		strategies = append(strategies, strategy)
+	}
+
+	return strategies
+}
+
+// ShutdownStrategies calls the shutdown hooks of the strategies concurrently and waits until the hooks return
+// or the context is done. The shutdown policy is applied by the trader to the single exchange strategies without
+// the shutdown hook.
+func (trader *Trader) ShutdownStrategies(ctx context.Context) {
+	var wg sync.WaitGroup
+	var shutdown = func(strategy interface{}, cb func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := cb(); err != nil {
+				log.WithError(err).Errorf("strategy %T shutdown error", strategy)
+				if trader.environment != nil {
+					trader.environment.Notify(":warning: strategy %s shutdown error: %v", strategyName(strategy), err)
+				}
+			}
+		}()
+	}
+
+	for sessionName, strategies := range trader.exchangeStrategies {
+		for _, strategy := range strategies {
+			strategy := strategy
+			options := trader.strategyShutdownOptions(strategy, sessionName)
+
+			if shutdowner, ok := strategy.(StrategyShutdowner); ok {
+				shutdown(strategy, func() error {
+					return shutdowner.Shutdown(ctx, options)
+				})
+				continue
+			}
+
+			if !trader.shutdownConfig.hasPolicy() || trader.environment == nil {
+				continue
+			}
+
+			session, ok := trader.environment.Session(sessionName)
+			if !ok {
+				continue
+			}
+
+			shutdown(strategy, func() error {
+				return trader.applyShutdownPolicy(ctx, strategy, session, options)
+			})
+		}
+	}
+
+	for _, strategy := range trader.crossExchangeStrategies {
+		if shutdowner, ok := strategy.(StrategyShutdowner); ok {
+			options := trader.strategyShutdownOptions(strategy, "")
+			shutdown(strategy, func() error {
+				return shutdowner.Shutdown(ctx, options)
+			})
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Warnf("strategy shutdown hooks are not finished before the deadline: %v", ctx.Err())
+	}
+}
+
+func (trader *Trader) strategyShutdownOptions(strategy interface{}, sessionName string) ShutdownOptions {
+	rs := newRunningStrategy(strategy, sessionName)
+	return trader.shutdownConfig.options(rs.ID, rs.Target)
+}
+
+// applyShutdownPolicy cancels the working orders submitted by the strategy, and closes the session position of the
+// strategy symbol if the ClosePosition option is set
+func (trader *Trader) applyShutdownPolicy(ctx context.Context, strategy interface{}, session *ExchangeSession, options ShutdownOptions) error {
+	if !options.KeepOrders {
+		if orders := trader.shutdownOrders(strategy); len(orders) > 0 {
+			log.Infof("canceling %d orders of strategy %s on shutdown", len(orders), strategyName(strategy))
+			if err := session.Exchange.CancelOrders(ctx, orders...); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !options.ClosePosition {
+		return nil
+	}
+
+	symbol := newRunningStrategy(strategy, session.Name).Symbol
+	if len(symbol) == 0 {
+		return fmt.Errorf("strategy %s is not a symbol based strategy, the position can not be closed", strategyName(strategy))
+	}
+
+	_, err := session.ClosePosition(ctx, symbol, 1.0)
+	return err
+}
+
+// shutdownOrderExecutor keeps the working orders submitted by the strategy, so that the trader can cancel them
+// on shutdown for the strategies without the shutdown hook
+type shutdownOrderExecutor struct {
+	OrderExecutor
+
+	orderBook *LocalActiveOrderBook
+}
+
+func (e *shutdownOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	createdOrders, err := e.OrderExecutor.SubmitOrders(ctx, orders...)
+	if len(createdOrders) > 0 {
+		e.orderBook.Add(createdOrders...)
+	}
+
+	return createdOrders, err
+}
+
+// trackShutdownOrders wraps the order executor of the strategy to track its working orders if a shutdown policy
+// is configured
+func (trader *Trader) trackShutdownOrders(strategy interface{}, session *ExchangeSession, executor OrderExecutor) OrderExecutor {
+	if !trader.shutdownConfig.hasPolicy() {
+		return executor
+	}
+
+	if _, ok := strategy.(StrategyShutdowner); ok {
+		return executor
+	}
+
+	orderBook := NewLocalActiveOrderBook()
+	if session.UserDataStream != nil {
+		orderBook.BindStream(session.UserDataStream)
+	}
+
+	trader.shutdownMu.Lock()
+	if trader.shutdownOrderBooks == nil {
+		trader.shutdownOrderBooks = make(map[interface{}]*LocalActiveOrderBook)
+	}
+	trader.shutdownOrderBooks[strategy] = orderBook
+	trader.shutdownMu.Unlock()
+
+	return &shutdownOrderExecutor{OrderExecutor: executor, orderBook: orderBook}
+}
+
+func (trader *Trader) shutdownOrders(strategy interface{}) types.OrderSlice {
+	trader.shutdownMu.Lock()
+	orderBook, ok := trader.shutdownOrderBooks[strategy]
+	trader.shutdownMu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	return orderBook.Orders()
+}
+
+// Shutdown calls the shutdown hooks of the strategies and then the graceful shutdown callbacks,
+// the persistence fields of the strategies are saved at last.
+func (trader *Trader) Shutdown(ctx context.Context) {
+	trader.ShutdownStrategies(ctx)
+	trader.Graceful.Shutdown(ctx)
+
+	if err := trader.SavePersistenceFields(); err != nil {
+		log.WithError(err).Error("persistence fields save error")
+	}
+}
+
+// SuspendStrategy suspends the strategies matched by the target, e.g., grid or grid:BTCUSDT,
+// the strategies that only implement Pausable are paused.
+func (trader *Trader) SuspendStrategy(ctx context.Context, target string) error {
+	return trader.forEachStrategy(target, func(strategy interface{}) error {
+		switch s := strategy.(type) {
+		case StrategySuspender:
+			return s.Suspend(ctx)
+
+		case Pausable:
+			return s.Pause()
+		}
+
+		return fmt.Errorf("strategy %T can not be suspended", strategy)
+	})
+}
+
+// ResumeStrategy resumes the strategies matched by the target, the strategies that only implement Pausable are resumed by Resume()
+func (trader *Trader) ResumeStrategy(ctx context.Context, target string) error {
+	return trader.forEachStrategy(target, func(strategy interface{}) error {
+		switch s := strategy.(type) {
+		case StrategySuspender:
+			return s.Resume(ctx)
+
+		case Pausable:
+			return s.Resume()
+		}
+
+		return fmt.Errorf("strategy %T can not be resumed", strategy)
+	})
+}
+
+// RunningStrategy describes a strategy loaded by the trader, the Target can be passed to SuspendStrategy and ResumeStrategy
+type RunningStrategy struct {
+	ID      string `json:"id"`
+	Symbol  string `json:"symbol,omitempty"`
+	Session string `json:"session,omitempty"`
+
+	// Target is the strategy ID with the symbol, e.g., grid:BTCUSDT
+	Target string `json:"target"`
+
+	CrossExchange bool `json:"crossExchange,omitempty"`
+
+	// Suspendable is true if the strategy can be suspended or paused
+	Suspendable bool `json:"suspendable"`
+}
+
+func newRunningStrategy(strategy interface{}, session string) RunningStrategy {
+	s := RunningStrategy{ID: strategyName(strategy), Session: session, CrossExchange: session == ""}
+	s.Target = s.ID
+
+	rs := reflect.ValueOf(strategy)
+	if rs.Kind() == reflect.Ptr {
+		rs = rs.Elem()
+	}
+
+	if rs.Kind() == reflect.Struct {
+		if symbol, ok := isSymbolBasedStrategy(rs); ok {
+			s.Symbol = symbol
+			s.Target = s.ID + ":" + symbol
+		}
+	}
+
+	switch strategy.(type) {
+	case StrategySuspender, Pausable:
+		s.Suspendable = true
+	}
+
+	return s
+}
+
+// RunningStrategies returns the strategies loaded by the trader, sorted by the session and the target
+func (trader *Trader) RunningStrategies() []RunningStrategy {
+	var strategies []RunningStrategy
+	for sessionName, sessionStrategies := range trader.exchangeStrategies {
+		for _, strategy := range sessionStrategies {
+			strategies = append(strategies, newRunningStrategy(strategy, sessionName))
+		}
+	}
+
+	for _, strategy := range trader.crossExchangeStrategies {
+		strategies = append(strategies, newRunningStrategy(strategy, ""))
+	}
+
+	sort.Slice(strategies, func(i, j int) bool {
+		if strategies[i].Session == strategies[j].Session {
+			return strategies[i].Target < strategies[j].Target
+		}
+		return strategies[i].Session < strategies[j].Session
+	})
+
+	return strategies
+}
+
+func strategyName(strategy interface{}) string {
+	if s, ok := strategy.(interface{ ID() string }); ok {
+		return s.ID()
+	}
+
+	return fmt.Sprintf("%T", strategy)
+}
@@ -0,0 +1,204 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type lifecycleTestStrategy struct {
+	Symbol string `json:"symbol"`
+
+	suspended       bool
+	shutdownOptions *ShutdownOptions
+	shutdownErr     error
+
+	// block blocks the shutdown hook until the context is done
+	block bool
+}
+
+func (s *lifecycleTestStrategy) ID() string {
+	return "lifecycle-test"
+}
+
+func (s *lifecycleTestStrategy) Run(ctx context.Context, orderExecutor OrderExecutor, session *ExchangeSession) error {
+	return nil
+}
+
+func (s *lifecycleTestStrategy) Suspend(ctx context.Context) error {
+	s.suspended = true
+	return nil
+}
+
+func (s *lifecycleTestStrategy) Resume(ctx context.Context) error {
+	s.suspended = false
+	return nil
+}
+
+func (s *lifecycleTestStrategy) Shutdown(ctx context.Context, options ShutdownOptions) error {
+	if s.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	s.shutdownOptions = &options
+	return s.shutdownErr
+}
+
+func TestTrader_Shutdown(t *testing.T) {
+	btc := &lifecycleTestStrategy{Symbol: "BTCUSDT"}
+	eth := &lifecycleTestStrategy{Symbol: "ETHUSDT", shutdownErr: errors.New("can not cancel orders")}
+
+	var gracefulShutdown bool
+	trader := &Trader{
+		exchangeStrategies: map[string][]SingleExchangeStrategy{
+			"binance": {btc, eth, &commandTestStrategy{Symbol: "BTCUSDT"}},
+		},
+	}
+	trader.SetShutdownOptions(ShutdownOptions{ClosePosition: true})
+	trader.Graceful.OnShutdown(func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		// the shutdown hooks are called before the graceful shutdown callbacks
+		assert.NotNil(t, btc.shutdownOptions)
+		gracefulShutdown = true
+	})
+
+	trader.Shutdown(context.Background())
+	assert.True(t, gracefulShutdown)
+	if assert.NotNil(t, btc.shutdownOptions) && assert.NotNil(t, eth.shutdownOptions) {
+		assert.Equal(t, ShutdownOptions{ClosePosition: true}, *btc.shutdownOptions)
+		assert.False(t, btc.shutdownOptions.KeepOrders)
+	}
+}
+
+func TestShutdownConfig(t *testing.T) {
+	var config ShutdownConfig
+	assert.NoError(t, yaml.Unmarshal([]byte(`
+timeout: 1m
+policy: keepOrders
+strategies:
+  grid:BTCUSDT: cancelOrders
+  bollmaker: closePosition
+`), &config))
+	assert.NoError(t, config.Validate())
+	assert.Equal(t, types.Duration(time.Minute), config.Timeout)
+
+	assert.Equal(t, ShutdownOptions{}, config.options("grid", "grid:BTCUSDT"))
+	assert.Equal(t, ShutdownOptions{KeepOrders: true}, config.options("grid", "grid:ETHUSDT"))
+	assert.Equal(t, ShutdownOptions{ClosePosition: true}, config.options("bollmaker", "bollmaker:ETHUSDT"))
+
+	// the legacy options are used if no policy is set
+	var legacy ShutdownConfig
+	assert.NoError(t, yaml.Unmarshal([]byte("keepOrders: true\n"), &legacy))
+	assert.False(t, legacy.hasPolicy())
+	assert.Equal(t, ShutdownOptions{KeepOrders: true}, legacy.options("grid", "grid:BTCUSDT"))
+
+	config.Strategies["xmaker"] = "flatten"
+	assert.Error(t, config.Validate())
+}
+
+func TestTrader_ShutdownPolicy(t *testing.T) {
+	exchange := &killSwitchTestExchange{}
+	session := newKillSwitchTestSession(exchange)
+
+	environ := NewEnvironment()
+	environ.AddExchangeSession("binance", session)
+
+	strategy := &commandTestStrategy{Symbol: "BTCUSDT"}
+	trader := NewTrader(environ)
+	trader.exchangeStrategies["binance"] = []SingleExchangeStrategy{strategy}
+	trader.SetShutdownConfig(ShutdownConfig{
+		Timeout:    types.Duration(time.Minute),
+		Strategies: map[string]ShutdownPolicy{"command-test:BTCUSDT": ShutdownPolicyClosePosition},
+	})
+	assert.Equal(t, time.Minute, trader.ShutdownTimeout())
+
+	executor := trader.trackShutdownOrders(strategy, session, &recordOrderExecutor{ExchangeOrderExecutor: &ExchangeOrderExecutor{}})
+	_, err := executor.SubmitOrders(context.Background(), types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 9000.0, Quantity: 0.1})
+	assert.NoError(t, err)
+
+	trader.ShutdownStrategies(context.Background())
+
+	assert.Len(t, exchange.canceledOrders, 1, "the working order of the strategy is canceled")
+	if assert.Len(t, exchange.submittedOrders, 1, "the position is closed") {
+		assert.Equal(t, types.SideTypeSell, exchange.submittedOrders[0].Side)
+	}
+}
+
+func TestTrader_ShutdownStrategies_Deadline(t *testing.T) {
+	trader := &Trader{
+		exchangeStrategies: map[string][]SingleExchangeStrategy{
+			"binance": {&lifecycleTestStrategy{Symbol: "BTCUSDT", block: true}},
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	trader.ShutdownStrategies(ctx)
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+func TestTrader_SuspendStrategy(t *testing.T) {
+	btc := &lifecycleTestStrategy{Symbol: "BTCUSDT"}
+	eth := &lifecycleTestStrategy{Symbol: "ETHUSDT"}
+	pausable := &commandTestStrategy{Symbol: "BTCUSDT"}
+
+	trader := &Trader{
+		exchangeStrategies: map[string][]SingleExchangeStrategy{
+			"binance": {btc, eth, pausable},
+		},
+	}
+
+	q := NewCommandQueue(nil)
+	trader.RegisterCommandHandlers(q)
+
+	ctx := context.Background()
+	assert.NoError(t, q.execute(ctx, types.Command{Type: types.CommandTypeSuspendStrategy, Strategy: "lifecycle-test:BTCUSDT"}))
+	assert.True(t, btc.suspended)
+	assert.False(t, eth.suspended)
+
+	// the pausable strategy is paused by the suspend command
+	assert.NoError(t, q.execute(ctx, types.Command{Type: types.CommandTypeSuspendStrategy, Strategy: "command-test"}))
+	assert.True(t, pausable.paused)
+
+	assert.NoError(t, q.execute(ctx, types.Command{Type: types.CommandTypeResumeStrategy, Strategy: "lifecycle-test"}))
+	assert.False(t, btc.suspended)
+
+	assert.NoError(t, trader.ResumeStrategy(ctx, "command-test:BTCUSDT"))
+	assert.False(t, pausable.paused)
+
+	assert.Error(t, trader.SuspendStrategy(ctx, "grid"))
+}
+
+func TestTrader_RunningStrategies(t *testing.T) {
+	trader := &Trader{
+		exchangeStrategies: map[string][]SingleExchangeStrategy{
+			"max":     {&commandTestStrategy{Symbol: "BTCTWD"}},
+			"binance": {&lifecycleTestStrategy{Symbol: "ETHUSDT"}, &lifecycleTestStrategy{Symbol: "BTCUSDT"}},
+		},
+	}
+
+	strategies := trader.RunningStrategies()
+	if assert.Len(t, strategies, 3) {
+		assert.Equal(t, RunningStrategy{
+			ID:          "lifecycle-test",
+			Symbol:      "BTCUSDT",
+			Session:     "binance",
+			Target:      "lifecycle-test:BTCUSDT",
+			Suspendable: true,
+		}, strategies[0])
+		assert.Equal(t, "lifecycle-test:ETHUSDT", strategies[1].Target)
+		assert.Equal(t, "command-test:BTCTWD", strategies[2].Target)
+		assert.Equal(t, "max", strategies[2].Session)
+	}
+}
@@ -0,0 +1,432 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultMarginMonitorCheckInterval = 30 * time.Second
+
+const defaultMarginMonitorNotifyInterval = 10 * time.Minute
+
+// defaultLiquidationMarginLevel is the margin level of the forced liquidation of the binance margin account
+var defaultLiquidationMarginLevel = fixedpoint.NewFromFloat(1.1)
+
+// MarginRiskLevel is the escalation level of the margin level
+type MarginRiskLevel int
+
+const (
+	MarginRiskNormal MarginRiskLevel = iota
+	MarginRiskWarning
+	MarginRiskCritical
+)
+
+func (l MarginRiskLevel) String() string {
+	switch l {
+	case MarginRiskWarning:
+		return "warning"
+	case MarginRiskCritical:
+		return "critical"
+	}
+
+	return "normal"
+}
+
+// MarginMonitorConfig configures the margin level monitor of the margin session
+//
+//	marginMonitor:
+//	  warningMarginLevel: 1.5
+//	  criticalMarginLevel: 1.25
+//	  repay: true
+//	  reducePositionRatio: 0.5
+type MarginMonitorConfig struct {
+	// WarningMarginLevel sends the warning notification when the margin level falls below it
+	WarningMarginLevel fixedpoint.Value `json:"warningMarginLevel,omitempty" yaml:"warningMarginLevel,omitempty"`
+
+	// CriticalMarginLevel sends the critical notification and triggers the de-risking actions when the margin level falls below it
+	CriticalMarginLevel fixedpoint.Value `json:"criticalMarginLevel" yaml:"criticalMarginLevel"`
+
+	// LiquidationMarginLevel is the margin level of the forced liquidation, defaults to 1.1
+	LiquidationMarginLevel fixedpoint.Value `json:"liquidationMarginLevel,omitempty" yaml:"liquidationMarginLevel,omitempty"`
+
+	// Repay repays the borrowed assets with the free balances at the critical level
+	Repay bool `json:"repay,omitempty" yaml:"repay,omitempty"`
+
+	// ReducePositionRatio reduces the session positions by the ratio with the market orders at the critical level, e.g., 0.5
+	ReducePositionRatio fixedpoint.Value `json:"reducePositionRatio,omitempty" yaml:"reducePositionRatio,omitempty"`
+
+	// CheckInterval defaults to 30s
+	CheckInterval types.Duration `json:"checkInterval,omitempty" yaml:"checkInterval,omitempty"`
+
+	// NotifyInterval is the interval of the repeated notifications and de-risking actions while the margin level stays low,
+	// defaults to 10m
+	NotifyInterval types.Duration `json:"notifyInterval,omitempty" yaml:"notifyInterval,omitempty"`
+}
+
+func (c *MarginMonitorConfig) Validate() error {
+	if c.CriticalMarginLevel <= 0 {
+		return errors.New("criticalMarginLevel of the margin monitor should be greater than zero")
+	}
+
+	if c.WarningMarginLevel > 0 && c.WarningMarginLevel < c.CriticalMarginLevel {
+		return errors.New("warningMarginLevel of the margin monitor should be greater than criticalMarginLevel")
+	}
+
+	if c.LiquidationMarginLevel < 0 || c.LiquidationMarginLevel >= c.CriticalMarginLevel {
+		return errors.New("liquidationMarginLevel of the margin monitor should be less than criticalMarginLevel")
+	}
+
+	if c.ReducePositionRatio < 0 || c.ReducePositionRatio > fixedpoint.NewFromInt(1) {
+		return errors.New("reducePositionRatio of the margin monitor should be between 0 and 1")
+	}
+
+	if c.CheckInterval < 0 || c.NotifyInterval < 0 {
+		return errors.New("the intervals of the margin monitor can not be negative")
+	}
+
+	return nil
+}
+
+// MarginStatus is the checked margin status of the session
+type MarginStatus struct {
+	MarginLevel float64 `json:"marginLevel"`
+
+	// LiquidationDistance is the ratio the collateral value can drop before the liquidation
+	LiquidationDistance float64 `json:"liquidationDistance"`
+
+	// LiquidationPrice is only available for the isolated margin account
+	LiquidationPrice float64 `json:"liquidationPrice,omitempty"`
+
+	// HasLiability is false if nothing is borrowed, the margin level is meaningless in this case
+	HasLiability bool `json:"hasLiability"`
+
+	RiskLevel MarginRiskLevel `json:"riskLevel"`
+}
+
+// MarginMonitor tracks the margin level and the liquidation distance of the margin session, it sends the escalating
+// notifications when the margin level falls below the warning and the critical levels, and triggers the configured
+// de-risking actions (repay the borrowed assets, reduce the positions) at the critical level.
+type MarginMonitor struct {
+	Config MarginMonitorConfig
+
+	session *ExchangeSession
+
+	mu             sync.Mutex
+	status         MarginStatus
+	lastNotifiedAt time.Time
+}
+
+func NewMarginMonitor(session *ExchangeSession, config MarginMonitorConfig) *MarginMonitor {
+	if config.LiquidationMarginLevel == 0 {
+		config.LiquidationMarginLevel = defaultLiquidationMarginLevel
+	}
+
+	if config.CheckInterval == 0 {
+		config.CheckInterval = types.Duration(defaultMarginMonitorCheckInterval)
+	}
+
+	if config.NotifyInterval == 0 {
+		config.NotifyInterval = types.Duration(defaultMarginMonitorNotifyInterval)
+	}
+
+	return &MarginMonitor{
+		Config:  config,
+		session: session,
+	}
+}
+
+// Status returns the last checked margin status
+func (m *MarginMonitor) Status() MarginStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.status
+}
+
+// RiskLevel returns the risk level of the margin level
+func (m *MarginMonitor) RiskLevel(marginLevel float64) MarginRiskLevel {
+	if marginLevel < m.Config.CriticalMarginLevel.Float64() {
+		return MarginRiskCritical
+	}
+
+	if m.Config.WarningMarginLevel > 0 && marginLevel < m.Config.WarningMarginLevel.Float64() {
+		return MarginRiskWarning
+	}
+
+	return MarginRiskNormal
+}
+
+func (m *MarginMonitor) queryStatus(ctx context.Context) (MarginStatus, error) {
+	service, ok := m.session.Exchange.(types.MarginAccountService)
+	if !ok {
+		return MarginStatus{}, fmt.Errorf("exchange %s does not support querying the margin account", m.session.Exchange.Name())
+	}
+
+	var status MarginStatus
+	if m.session.IsolatedMargin {
+		account, err := service.QueryIsolatedMarginAccount(ctx, m.session.IsolatedMarginSymbol)
+		if err != nil {
+			return status, err
+		}
+
+		for _, asset := range account.Assets {
+			if asset.Symbol != m.session.IsolatedMarginSymbol {
+				continue
+			}
+
+			status.MarginLevel = asset.MarginLevel.Float64()
+			status.LiquidationPrice = asset.LiquidatePrice.Float64()
+			status.HasLiability = asset.BaseAsset.Borrowed > 0 || asset.QuoteAsset.Borrowed > 0
+			if asset.IndexPrice > 0 && asset.LiquidatePrice > 0 {
+				status.LiquidationDistance = math.Abs(asset.IndexPrice.Float64()-asset.LiquidatePrice.Float64()) / asset.IndexPrice.Float64()
+			}
+		}
+	} else {
+		account, err := service.QueryMarginAccount(ctx)
+		if err != nil {
+			return status, err
+		}
+
+		status.MarginLevel = account.MarginLevel.Float64()
+		status.HasLiability = account.TotalLiabilityOfBTC > 0
+	}
+
+	// the margin level is the ratio of the total asset to the total liability,
+	// so the collateral value can drop by 1 - liquidation level / margin level before the liquidation
+	if status.LiquidationDistance == 0 && status.MarginLevel > 0 {
+		status.LiquidationDistance = math.Max(0, 1.0-m.Config.LiquidationMarginLevel.Float64()/status.MarginLevel)
+	}
+
+	if status.HasLiability {
+		status.RiskLevel = m.RiskLevel(status.MarginLevel)
+	}
+
+	return status, nil
+}
+
+// Check queries the margin account, sends the notification when the risk level changes or stays high for the notify
+// interval, and triggers the de-risking actions at the critical level
+func (m *MarginMonitor) Check(ctx context.Context, now time.Time) (MarginStatus, error) {
+	status, err := m.queryStatus(ctx)
+	if err != nil {
+		return status, err
+	}
+
+	m.mu.Lock()
+	previous := m.status.RiskLevel
+	m.status = status
+
+	escalate := status.RiskLevel > previous ||
+		(status.RiskLevel != MarginRiskNormal && now.Sub(m.lastNotifiedAt) >= m.Config.NotifyInterval.Duration())
+	if escalate || status.RiskLevel < previous {
+		m.lastNotifiedAt = now
+	}
+	m.mu.Unlock()
+
+	if status.RiskLevel < previous {
+		m.session.Notify(":white_check_mark: session %s margin level recovered to %.3f (%s), liquidation distance %.2f%%",
+			m.session.Name, status.MarginLevel, status.RiskLevel, status.LiquidationDistance*100.0)
+		return status, nil
+	}
+
+	if !escalate {
+		return status, nil
+	}
+
+	switch status.RiskLevel {
+	case MarginRiskWarning:
+		log.Warnf("session %s margin level %f is below the warning level %f", m.session.Name, status.MarginLevel, m.Config.WarningMarginLevel.Float64())
+		m.session.Notify(":warning: session %s margin level %.3f is below the warning level %.3f, liquidation distance %.2f%%",
+			m.session.Name, status.MarginLevel, m.Config.WarningMarginLevel.Float64(), status.LiquidationDistance*100.0)
+
+	case MarginRiskCritical:
+		log.Errorf("session %s margin level %f is below the critical level %f", m.session.Name, status.MarginLevel, m.Config.CriticalMarginLevel.Float64())
+		m.session.Notify(":rotating_light: session %s margin level %.3f is below the critical level %.3f, liquidation distance %.2f%%",
+			m.session.Name, status.MarginLevel, m.Config.CriticalMarginLevel.Float64(), status.LiquidationDistance*100.0)
+		m.derisk(ctx)
+	}
+
+	return status, nil
+}
+
+func (m *MarginMonitor) derisk(ctx context.Context) {
+	if m.Config.Repay {
+		if err := m.repay(ctx); err != nil {
+			log.WithError(err).Errorf("margin monitor can not repay the borrowed assets of session %s", m.session.Name)
+			m.session.Notify(":warning: margin monitor can not repay the borrowed assets of session %s: %v", m.session.Name, err)
+		}
+	}
+
+	if m.Config.ReducePositionRatio > 0 {
+		m.reducePositions(ctx)
+	}
+}
+
+// repayAmounts returns the repayable amounts (asset -> amount) of the borrowed assets by the free balances
+func (m *MarginMonitor) repayAmounts(ctx context.Context) (map[string]fixedpoint.Value, error) {
+	service := m.session.Exchange.(types.MarginAccountService)
+	amounts := make(map[string]fixedpoint.Value)
+
+	addAmount := func(asset string, borrowed, interest, free fixedpoint.Value) {
+		debt := borrowed + interest
+		if debt <= 0 || free <= 0 {
+			return
+		}
+
+		if free < debt {
+			debt = free
+		}
+
+		amounts[asset] = debt
+	}
+
+	if m.session.IsolatedMargin {
+		account, err := service.QueryIsolatedMarginAccount(ctx, m.session.IsolatedMarginSymbol)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, asset := range account.Assets {
+			if asset.Symbol != m.session.IsolatedMarginSymbol {
+				continue
+			}
+
+			for _, userAsset := range []types.IsolatedUserAsset{asset.BaseAsset, asset.QuoteAsset} {
+				addAmount(userAsset.Asset, userAsset.Borrowed, userAsset.Interest, userAsset.Free)
+			}
+		}
+
+		return amounts, nil
+	}
+
+	account, err := service.QueryMarginAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, userAsset := range account.UserAssets {
+		addAmount(userAsset.Asset, userAsset.Borrowed, userAsset.Interest, userAsset.Free)
+	}
+
+	return amounts, nil
+}
+
+func (m *MarginMonitor) repay(ctx context.Context) error {
+	service, ok := m.session.Exchange.(types.MarginBorrowRepayService)
+	if !ok {
+		return fmt.Errorf("exchange %s does not support repaying the margin assets", m.session.Exchange.Name())
+	}
+
+	amounts, err := m.repayAmounts(ctx)
+	if err != nil {
+		return err
+	}
+
+	for asset, amount := range amounts {
+		if err := service.RepayMarginAsset(ctx, asset, amount); err != nil {
+			return errors.Wrapf(err, "can not repay %f %s", amount.Float64(), asset)
+		}
+
+		m.session.Notify("margin monitor repaid %f %s of session %s", amount.Float64(), asset, m.session.Name)
+	}
+
+	return nil
+}
+
+func (m *MarginMonitor) reducePositions(ctx context.Context) {
+	for symbol, position := range m.session.Positions() {
+		if m.session.IsolatedMargin && symbol != m.session.IsolatedMarginSymbol {
+			continue
+		}
+
+		base := position.Snapshot().Base.Float64()
+		market, ok := m.session.Market(symbol)
+		if !ok || base == 0 {
+			continue
+		}
+
+		quantity := math.Abs(base) * m.Config.ReducePositionRatio.Float64()
+		if quantity < market.MinQuantity {
+			continue
+		}
+
+		side := types.SideTypeSell
+		if base < 0 {
+			side = types.SideTypeBuy
+		}
+
+		_, err := m.session.OrderExecutor.SubmitOrders(ctx, types.SubmitOrder{
+			Symbol:           symbol,
+			Side:             side,
+			Type:             types.OrderTypeMarket,
+			Quantity:         quantity,
+			Market:           market,
+			MarginSideEffect: types.SideEffectTypeAutoRepay,
+		})
+		if err != nil {
+			log.WithError(err).Errorf("margin monitor can not reduce the %s position of session %s", symbol, m.session.Name)
+			m.session.Notify(":warning: margin monitor can not reduce the %s position of session %s: %v", symbol, m.session.Name, err)
+			continue
+		}
+
+		m.session.Notify("margin monitor reduced the %s position of session %s by %f", symbol, m.session.Name, quantity)
+	}
+}
+
+func (session *ExchangeSession) initMarginMonitor() error {
+	if !session.Margin {
+		return errors.New("the margin monitor requires the margin session")
+	}
+
+	if err := session.MarginMonitor.Validate(); err != nil {
+		return err
+	}
+
+	if _, ok := session.Exchange.(types.MarginAccountService); !ok {
+		return fmt.Errorf("exchange %s does not support querying the margin account", session.ExchangeName)
+	}
+
+	if session.MarginMonitor.Repay {
+		if _, ok := session.Exchange.(types.MarginBorrowRepayService); !ok {
+			return fmt.Errorf("exchange %s does not support repaying the margin assets", session.ExchangeName)
+		}
+	}
+
+	session.marginMonitor = NewMarginMonitor(session, *session.MarginMonitor)
+	return nil
+}
+
+// MarginStatus returns the last checked margin status, it returns false if the margin monitor is not configured
+func (session *ExchangeSession) MarginStatus() (MarginStatus, bool) {
+	if session.marginMonitor == nil {
+		return MarginStatus{}, false
+	}
+
+	return session.marginMonitor.Status(), true
+}
+
+// Run checks the margin level periodically until the context is done
+func (m *MarginMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.Config.CheckInterval.Duration())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case now := <-ticker.C:
+			if _, err := m.Check(ctx, now); err != nil {
+				log.WithError(err).Errorf("margin monitor can not check the margin level of session %s", m.session.Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type marginTestExchange struct {
+	guardTestExchange
+
+	account *types.MarginAccount
+	repaid  map[string]fixedpoint.Value
+}
+
+func (e *marginTestExchange) QueryMarginAccount(ctx context.Context) (*types.MarginAccount, error) {
+	return e.account, nil
+}
+
+func (e *marginTestExchange) QueryIsolatedMarginAccount(ctx context.Context, symbols ...string) (*types.IsolatedMarginAccount, error) {
+	return &types.IsolatedMarginAccount{}, nil
+}
+
+func (e *marginTestExchange) RepayMarginAsset(ctx context.Context, asset string, amount fixedpoint.Value) error {
+	e.repaid[asset] = amount
+	return nil
+}
+
+func TestMarginMonitorConfig_Validate(t *testing.T) {
+	config := MarginMonitorConfig{
+		WarningMarginLevel:  fixedpoint.NewFromFloat(1.5),
+		CriticalMarginLevel: fixedpoint.NewFromFloat(1.25),
+	}
+	assert.NoError(t, config.Validate())
+
+	config.WarningMarginLevel = fixedpoint.NewFromFloat(1.2)
+	assert.Error(t, config.Validate())
+
+	config.WarningMarginLevel = 0
+	config.ReducePositionRatio = fixedpoint.NewFromFloat(1.5)
+	assert.Error(t, config.Validate())
+}
+
+func TestMarginMonitor_Check(t *testing.T) {
+	exchange := &marginTestExchange{
+		account: &types.MarginAccount{
+			MarginLevel:         fixedpoint.NewFromFloat(2.0),
+			TotalLiabilityOfBTC: fixedpoint.NewFromFloat(0.1),
+			UserAssets: []types.MarginUserAsset{
+				{Asset: "USDT", Borrowed: fixedpoint.NewFromFloat(1000.0), Interest: fixedpoint.NewFromFloat(1.0), Free: fixedpoint.NewFromFloat(300.0)},
+				{Asset: "BTC", Free: fixedpoint.NewFromFloat(0.2)},
+			},
+		},
+		repaid: make(map[string]fixedpoint.Value),
+	}
+
+	market := types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT", MinQuantity: 0.001, StepSize: 0.001, TickSize: 0.01}
+	position := types.NewPositionFromMarket(market)
+	position.AddTrade(types.Trade{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Price: 10000.0, Quantity: 0.2, QuoteQuantity: 2000.0})
+
+	session := &ExchangeSession{
+		Name:      "binance",
+		Exchange:  exchange,
+		Margin:    true,
+		markets:   map[string]types.Market{"BTCUSDT": market},
+		positions: map[string]*types.Position{"BTCUSDT": position},
+		MarginMonitor: &MarginMonitorConfig{
+			WarningMarginLevel:  fixedpoint.NewFromFloat(1.5),
+			CriticalMarginLevel: fixedpoint.NewFromFloat(1.25),
+			Repay:               true,
+			ReducePositionRatio: fixedpoint.NewFromFloat(0.5),
+		},
+	}
+	session.OrderExecutor = &ExchangeOrderExecutor{Session: session}
+
+	notifier := &profitReportTestNotifier{}
+	session.AddNotifier(notifier)
+
+	assert.NoError(t, session.initMarginMonitor())
+	monitor := session.marginMonitor
+
+	ctx := context.Background()
+	now := time.Date(2021, 12, 20, 10, 0, 0, 0, time.Local)
+
+	status, err := monitor.Check(ctx, now)
+	assert.NoError(t, err)
+	assert.Equal(t, MarginRiskNormal, status.RiskLevel)
+	assert.InDelta(t, 0.45, status.LiquidationDistance, 1e-9)
+	assert.Len(t, notifier.objects, 0)
+
+	// the warning is sent once within the notify interval
+	exchange.account.MarginLevel = fixedpoint.NewFromFloat(1.4)
+	status, err = monitor.Check(ctx, now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, MarginRiskWarning, status.RiskLevel)
+	assert.Len(t, notifier.objects, 1)
+
+	_, err = monitor.Check(ctx, now.Add(2*time.Minute))
+	assert.NoError(t, err)
+	assert.Len(t, notifier.objects, 1)
+
+	// the critical level escalates immediately and triggers the de-risking actions
+	exchange.account.MarginLevel = fixedpoint.NewFromFloat(1.2)
+	status, err = monitor.Check(ctx, now.Add(3*time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, MarginRiskCritical, status.RiskLevel)
+	assert.Equal(t, map[string]fixedpoint.Value{"USDT": fixedpoint.NewFromFloat(300.0)}, exchange.repaid)
+	if assert.Len(t, exchange.orders, 1) {
+		assert.Equal(t, types.SideTypeSell, exchange.orders[0].Side)
+		assert.Equal(t, 0.1, exchange.orders[0].Quantity)
+		assert.Equal(t, types.SideEffectTypeAutoRepay, exchange.orders[0].MarginSideEffect)
+	}
+
+	// the recovery is notified as well
+	exchange.account.MarginLevel = fixedpoint.NewFromFloat(3.0)
+	status, err = monitor.Check(ctx, now.Add(4*time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, MarginRiskNormal, status.RiskLevel)
+
+	lastStatus, ok := session.MarginStatus()
+	assert.True(t, ok)
+	assert.Equal(t, 3.0, lastStatus.MarginLevel)
+
+	session.Margin = false
+	assert.Error(t, session.initMarginMonitor())
+}
@@ -0,0 +1,79 @@
+package bbgo
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// MarketInfo is the trading rules of a market with the fee tier of the session account
+type MarketInfo struct {
+	Session string       `json:"session"`
+	Market  types.Market `json:"market"`
+
+	// FeeTier is nil if the fee rates are not available, e.g., the public only session
+	FeeTier *types.FeeTier `json:"feeTier,omitempty"`
+}
+
+// WriteTable writes the filters, the precision and the fee rates of the market as a key-value table
+func (i MarketInfo) WriteTable(w io.Writer) error {
+	m := i.Market
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "SESSION\t%s\n", i.Session)
+	fmt.Fprintf(tw, "SYMBOL\t%s\n", m.Symbol)
+	if len(m.LocalSymbol) > 0 && m.LocalSymbol != m.Symbol {
+		fmt.Fprintf(tw, "LOCAL SYMBOL\t%s\n", m.LocalSymbol)
+	}
+
+	fmt.Fprintf(tw, "BASE/QUOTE\t%s/%s\n", m.BaseCurrency, m.QuoteCurrency)
+	fmt.Fprintf(tw, "TICK SIZE\t%s\n", formatMarketFilter(m.TickSize))
+	fmt.Fprintf(tw, "PRICE RANGE\t%s ~ %s\n", formatMarketFilter(m.MinPrice), formatMarketFilter(m.MaxPrice))
+	fmt.Fprintf(tw, "PRICE PRECISION\t%d\n", m.PricePrecision)
+	fmt.Fprintf(tw, "STEP SIZE\t%s\n", formatMarketFilter(m.StepSize))
+	fmt.Fprintf(tw, "QUANTITY RANGE\t%s ~ %s\n", formatMarketFilter(m.MinQuantity), formatMarketFilter(m.MaxQuantity))
+	fmt.Fprintf(tw, "VOLUME PRECISION\t%d\n", m.VolumePrecision)
+	fmt.Fprintf(tw, "MIN NOTIONAL\t%s %s\n", formatMarketFilter(m.MinNotional), m.QuoteCurrency)
+	fmt.Fprintf(tw, "MIN AMOUNT\t%s %s\n", formatMarketFilter(m.MinAmount), m.QuoteCurrency)
+
+	if i.FeeTier != nil {
+		fmt.Fprintf(tw, "MAKER FEE\t%.4f%% (effective %.4f%%)\n", i.FeeTier.MakerFeeRate.Float64()*100.0, i.FeeTier.EffectiveMakerFeeRate().Float64()*100.0)
+		fmt.Fprintf(tw, "TAKER FEE\t%.4f%% (effective %.4f%%)\n", i.FeeTier.TakerFeeRate.Float64()*100.0, i.FeeTier.EffectiveTakerFeeRate().Float64()*100.0)
+	} else {
+		fmt.Fprintf(tw, "MAKER FEE\t-\n")
+		fmt.Fprintf(tw, "TAKER FEE\t-\n")
+	}
+
+	return tw.Flush()
+}
+
+// WriteMarketsTable writes the markets sorted by the symbol, one market per line
+func WriteMarketsTable(w io.Writer, markets types.MarketMap) error {
+	var symbols []string
+	for symbol := range markets {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "SYMBOL\tBASE\tQUOTE\tTICK SIZE\tSTEP SIZE\tMIN QUANTITY\tMIN NOTIONAL\n")
+	for _, symbol := range symbols {
+		m := markets[symbol]
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", m.Symbol, m.BaseCurrency, m.QuoteCurrency,
+			formatMarketFilter(m.TickSize), formatMarketFilter(m.StepSize), formatMarketFilter(m.MinQuantity), formatMarketFilter(m.MinNotional))
+	}
+
+	return tw.Flush()
+}
+
+// formatMarketFilter formats the filter value without the trailing zeros, the zero value means the filter is not set
+func formatMarketFilter(v float64) string {
+	if v == 0 {
+		return "-"
+	}
+
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,61 @@
+package bbgo
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestMarketInfo_WriteTable(t *testing.T) {
+	info := MarketInfo{
+		Session: "binance",
+		Market: types.Market{
+			Symbol:          "BTCUSDT",
+			BaseCurrency:    "BTC",
+			QuoteCurrency:   "USDT",
+			TickSize:        0.01,
+			StepSize:        0.00001,
+			MinQuantity:     0.00001,
+			MaxQuantity:     9000,
+			MinNotional:     10,
+			PricePrecision:  2,
+			VolumePrecision: 5,
+		},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, info.WriteTable(&buf))
+	assert.Contains(t, buf.String(), "TICK SIZE         0.01\n")
+	assert.Contains(t, buf.String(), "QUANTITY RANGE    0.00001 ~ 9000\n")
+	assert.Contains(t, buf.String(), "MIN NOTIONAL      10 USDT\n")
+	assert.Contains(t, buf.String(), "PRICE RANGE       - ~ -\n")
+	assert.Contains(t, buf.String(), "MAKER FEE         -\n")
+
+	info.FeeTier = &types.FeeTier{
+		MakerFeeRate: fixedpoint.NewFromFloat(0.001),
+		TakerFeeRate: fixedpoint.NewFromFloat(0.001),
+		FeeDiscount:  fixedpoint.NewFromFloat(0.25),
+	}
+
+	buf.Reset()
+	assert.NoError(t, info.WriteTable(&buf))
+	assert.Contains(t, buf.String(), "MAKER FEE         0.1000% (effective 0.0750%)\n")
+}
+
+func TestWriteMarketsTable(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteMarketsTable(&buf, types.MarketMap{
+		"ETHUSDT": {Symbol: "ETHUSDT", BaseCurrency: "ETH", QuoteCurrency: "USDT", TickSize: 0.01},
+		"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT", TickSize: 0.01},
+	}))
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if assert.Len(t, lines, 3) {
+		assert.True(t, bytes.HasPrefix(lines[1], []byte("BTCUSDT")))
+		assert.True(t, bytes.HasPrefix(lines[2], []byte("ETHUSDT")))
+	}
+}
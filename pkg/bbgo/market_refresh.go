@@ -0,0 +1,101 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+const defaultMarketRefreshInterval = time.Hour
+
+// RefreshMarkets queries the markets from the exchange and replaces the markets of the session, the markets cache is
+// refreshed as well. The symbols of the markets with the changed precision or filters are returned in order.
+func (session *ExchangeSession) RefreshMarkets(ctx context.Context) ([]string, error) {
+	var disableMarketsCache = false
+	var markets types.MarketMap
+	var err error
+	if util.SetEnvVarBool("DISABLE_MARKETS_CACHE", &disableMarketsCache); disableMarketsCache {
+		markets, err = session.Exchange.QueryMarkets(ctx)
+	} else {
+		markets, err = RefreshExchangeMarketsCache(ctx, session.Exchange)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(markets) == 0 {
+		return nil, fmt.Errorf("market config should not be empty")
+	}
+
+	var changedSymbols []string
+	for symbol, last := range session.Markets() {
+		if market, ok := markets[symbol]; ok && marketFiltersChanged(last, market) {
+			changedSymbols = append(changedSymbols, symbol)
+		}
+	}
+
+	sort.Strings(changedSymbols)
+	session.SetMarkets(markets)
+	return changedSymbols, nil
+}
+
+// marketFiltersChanged compares the fields used by the order formatting and the market filters
+func marketFiltersChanged(a, b types.Market) bool {
+	return a.PricePrecision != b.PricePrecision ||
+		a.VolumePrecision != b.VolumePrecision ||
+		a.TickSize != b.TickSize ||
+		a.StepSize != b.StepSize ||
+		a.MinNotional != b.MinNotional ||
+		a.MinQuantity != b.MinQuantity ||
+		a.MaxQuantity != b.MaxQuantity ||
+		a.MinPrice != b.MinPrice ||
+		a.MaxPrice != b.MaxPrice
+}
+
+// TrackMarkets refreshes the markets of the sessions periodically until the context is canceled, so that the orders
+// are formatted and checked by the latest precision and filters of the exchange.
+func (environ *Environment) TrackMarkets(ctx context.Context, interval time.Duration) {
+	if interval == 0 {
+		interval = defaultMarketRefreshInterval
+	}
+
+	for n := range environ.sessions {
+		session := environ.sessions[n]
+
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+
+				case <-ticker.C:
+					refreshMarkets(ctx, session)
+				}
+			}
+		}()
+	}
+}
+
+func refreshMarkets(ctx context.Context, session *ExchangeSession) {
+	changedSymbols, err := session.RefreshMarkets(ctx)
+	if err != nil {
+		log.WithError(err).Errorf("can not refresh the markets of session %s", session.Name)
+		return
+	}
+
+	for _, symbol := range changedSymbols {
+		market, _ := session.Market(symbol)
+		session.Notify(":information_source: session %s %s market is changed: tick size %f, step size %f, min quantity %f, min notional %f",
+			session.Name, symbol, market.TickSize, market.StepSize, market.MinQuantity, market.MinNotional)
+	}
+}
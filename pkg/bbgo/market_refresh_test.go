@@ -0,0 +1,70 @@
+package bbgo
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type marketRefreshTestExchange struct {
+	types.Exchange
+
+	markets types.MarketMap
+}
+
+func (e *marketRefreshTestExchange) Name() types.ExchangeName {
+	return types.ExchangeBinance
+}
+
+func (e *marketRefreshTestExchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
+	return e.markets, nil
+}
+
+func TestExchangeSession_RefreshMarkets(t *testing.T) {
+	home, err := ioutil.TempDir("", "bbgo-home")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(home)
+
+	oldHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", oldHome)
+	_ = os.Setenv("HOME", home)
+
+	btcMarket := types.Market{Symbol: "BTCUSDT", TickSize: 0.01, StepSize: 0.0001, MinNotional: 10.0}
+	ethMarket := types.Market{Symbol: "ETHUSDT", TickSize: 0.01, StepSize: 0.001, MinNotional: 10.0}
+
+	exchange := &marketRefreshTestExchange{markets: types.MarketMap{"BTCUSDT": btcMarket, "ETHUSDT": ethMarket}}
+	session := &ExchangeSession{Name: "binance", Exchange: exchange}
+	session.SetMarkets(types.MarketMap{"BTCUSDT": btcMarket, "ETHUSDT": ethMarket})
+
+	changedSymbols, err := session.RefreshMarkets(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, changedSymbols, 0)
+
+	// the tick size of BTCUSDT is changed by the exchange
+	btcMarket.TickSize = 0.1
+	exchange.markets = types.MarketMap{"BTCUSDT": btcMarket, "ETHUSDT": ethMarket}
+
+	changedSymbols, err = session.RefreshMarkets(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"BTCUSDT"}, changedSymbols)
+
+	market, ok := session.Market("BTCUSDT")
+	assert.True(t, ok)
+	assert.Equal(t, 0.1, market.TickSize)
+
+	// the markets cache is refreshed as well
+	markets, err := LoadExchangeMarketsWithCache(context.Background(), exchange)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.1, markets["BTCUSDT"].TickSize)
+
+	exchange.markets = types.MarketMap{}
+	_, err = session.RefreshMarkets(context.Background())
+	assert.Error(t, err)
+}
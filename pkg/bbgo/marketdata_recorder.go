@@ -0,0 +1,265 @@
+package bbgo
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultMarketDataRecorderQueueSize = 4096
+
+type MarketDataType string
+
+const (
+	MarketDataTypeKLine        = MarketDataType("kline")
+	MarketDataTypeBookSnapshot = MarketDataType("bookSnapshot")
+	MarketDataTypeBookUpdate   = MarketDataType("bookUpdate")
+	MarketDataTypeTrade        = MarketDataType("trade")
+)
+
+// MarketDataRecord is one line of the recorded market data, the time is the time when the data is received.
+type MarketDataRecord struct {
+	Time     time.Time          `json:"time"`
+	Type     MarketDataType     `json:"type"`
+	Exchange types.ExchangeName `json:"exchange"`
+	Symbol   string             `json:"symbol"`
+
+	KLine *types.KLine          `json:"kline,omitempty"`
+	Book  *types.SliceOrderBook `json:"book,omitempty"`
+	Trade *types.Trade          `json:"trade,omitempty"`
+}
+
+// MarketDataSink writes the recorded market data
+type MarketDataSink interface {
+	Write(record MarketDataRecord) error
+	Close() error
+}
+
+// MarketDataRecorder records the market data of the streams to the sinks. The stream callbacks only queue the
+// records, the records are written by Run, so that the slow disk or database never blocks the stream.
+type MarketDataRecorder struct {
+	Sinks []MarketDataSink
+
+	queue chan MarketDataRecord
+	now   func() time.Time
+}
+
+func NewMarketDataRecorder(sinks ...MarketDataSink) *MarketDataRecorder {
+	return &MarketDataRecorder{
+		Sinks: sinks,
+		queue: make(chan MarketDataRecord, defaultMarketDataRecorderQueueSize),
+		now:   time.Now,
+	}
+}
+
+// BindStream records the closed klines, the order books and the market trades of the stream
+func (r *MarketDataRecorder) BindStream(exchange types.ExchangeName, stream types.StandardStreamEventHub) {
+	stream.OnKLineClosed(func(kline types.KLine) {
+		r.Record(MarketDataRecord{Type: MarketDataTypeKLine, Exchange: exchange, Symbol: kline.Symbol, KLine: &kline})
+	})
+
+	stream.OnBookSnapshot(func(book types.SliceOrderBook) {
+		r.Record(MarketDataRecord{Type: MarketDataTypeBookSnapshot, Exchange: exchange, Symbol: book.Symbol, Book: copyBook(book)})
+	})
+
+	stream.OnBookUpdate(func(book types.SliceOrderBook) {
+		r.Record(MarketDataRecord{Type: MarketDataTypeBookUpdate, Exchange: exchange, Symbol: book.Symbol, Book: copyBook(book)})
+	})
+
+	stream.OnMarketTrade(func(trade types.Trade) {
+		r.Record(MarketDataRecord{Type: MarketDataTypeTrade, Exchange: exchange, Symbol: trade.Symbol, Trade: &trade})
+	})
+}
+
+// Record queues the record, the record is dropped if the queue is full
+func (r *MarketDataRecorder) Record(record MarketDataRecord) {
+	if record.Time.IsZero() {
+		record.Time = r.now()
+	}
+
+	select {
+	case r.queue <- record:
+	default:
+		log.Errorf("market data recorder queue is full, %s %s record dropped", record.Symbol, record.Type)
+	}
+}
+
+// Run writes the queued records to the sinks until the context is canceled,
+// the remaining records are written and the sinks are closed before it returns.
+func (r *MarketDataRecorder) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			for {
+				select {
+				case record := <-r.queue:
+					r.write(record)
+				default:
+					r.close()
+					return
+				}
+			}
+
+		case record := <-r.queue:
+			r.write(record)
+		}
+	}
+}
+
+func (r *MarketDataRecorder) write(record MarketDataRecord) {
+	for _, sink := range r.Sinks {
+		if err := sink.Write(record); err != nil {
+			log.WithError(err).Errorf("market data sink write error, %s %s record dropped", record.Symbol, record.Type)
+		}
+	}
+}
+
+func (r *MarketDataRecorder) close() {
+	for _, sink := range r.Sinks {
+		if err := sink.Close(); err != nil {
+			log.WithError(err).Error("market data sink close error")
+		}
+	}
+}
+
+func copyBook(book types.SliceOrderBook) *types.SliceOrderBook {
+	return &types.SliceOrderBook{
+		Symbol: book.Symbol,
+		Bids:   book.Bids.Copy(),
+		Asks:   book.Asks.Copy(),
+	}
+}
+
+// FileMarketDataSink appends the records as gzip compressed json lines, the files are rotated daily:
+// <directory>/<exchange>/<symbol>/<type>-<date>.jsonl.gz
+type FileMarketDataSink struct {
+	Directory string
+
+	mu    sync.Mutex
+	files map[string]*marketDataFile
+}
+
+type marketDataFile struct {
+	file   *os.File
+	writer *gzip.Writer
+	date   string
+}
+
+func NewFileMarketDataSink(directory string) *FileMarketDataSink {
+	return &FileMarketDataSink{
+		Directory: directory,
+		files:     make(map[string]*marketDataFile),
+	}
+}
+
+func (s *FileMarketDataSink) Write(record MarketDataRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	date := record.Time.UTC().Format(types.DateFormat)
+	key := fmt.Sprintf("%s/%s/%s", record.Exchange, record.Symbol, record.Type)
+
+	f, ok := s.files[key]
+	if ok && f.date != date {
+		if err := f.close(); err != nil {
+			return err
+		}
+		ok = false
+	}
+
+	if !ok {
+		var err error
+		f, err = s.open(record, date)
+		if err != nil {
+			return err
+		}
+
+		s.files[key] = f
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.writer.Write(append(data, '\n'))
+	return err
+}
+
+// open opens the file in the append mode, the appended gzip member is concatenated to the previous members,
+// which is still a valid gzip stream.
+func (s *FileMarketDataSink) open(record MarketDataRecord, date string) (*marketDataFile, error) {
+	dir := filepath.Join(s.Directory, record.Exchange.String(), record.Symbol)
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, err
+	}
+
+	p := filepath.Join(dir, fmt.Sprintf("%s-%s.jsonl.gz", record.Type, date))
+	file, err := os.OpenFile(p, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can not open market data file %s", p)
+	}
+
+	return &marketDataFile{
+		file:   file,
+		writer: gzip.NewWriter(file),
+		date:   date,
+	}, nil
+}
+
+func (s *FileMarketDataSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var lastErr error
+	for key, f := range s.files {
+		if err := f.close(); err != nil {
+			lastErr = err
+		}
+		delete(s.files, key)
+	}
+
+	return lastErr
+}
+
+func (f *marketDataFile) close() error {
+	if err := f.writer.Close(); err != nil {
+		f.file.Close()
+		return err
+	}
+
+	return f.file.Close()
+}
+
+// KLineInserter inserts the kline, it's implemented by BacktestService
+type KLineInserter interface {
+	Insert(kline types.KLine) error
+}
+
+// KLineDatabaseSink inserts the recorded klines into the database, so that the recorded klines can be used by the
+// backtest directly. The other records are ignored.
+type KLineDatabaseSink struct {
+	KLines KLineInserter
+}
+
+func (s *KLineDatabaseSink) Write(record MarketDataRecord) error {
+	if record.Type != MarketDataTypeKLine || record.KLine == nil {
+		return nil
+	}
+
+	return s.KLines.Insert(*record.KLine)
+}
+
+func (s *KLineDatabaseSink) Close() error {
+	return nil
+}
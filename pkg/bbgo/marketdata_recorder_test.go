@@ -0,0 +1,150 @@
+package bbgo
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type memoryMarketDataSink struct {
+	records []MarketDataRecord
+	closed  bool
+}
+
+func (s *memoryMarketDataSink) Write(record MarketDataRecord) error {
+	s.records = append(s.records, record)
+	return nil
+}
+
+func (s *memoryMarketDataSink) Close() error {
+	s.closed = true
+	return nil
+}
+
+type kLineInserterFunc func(kline types.KLine) error
+
+func (f kLineInserterFunc) Insert(kline types.KLine) error {
+	return f(kline)
+}
+
+func readMarketDataFile(t *testing.T, p string) (records []MarketDataRecord) {
+	file, err := os.Open(p)
+	if !assert.NoError(t, err) {
+		return nil
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if !assert.NoError(t, err) {
+		return nil
+	}
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		var record MarketDataRecord
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+
+	assert.NoError(t, scanner.Err())
+	return records
+}
+
+func TestMarketDataRecorder_BindStream(t *testing.T) {
+	sink := &memoryMarketDataSink{}
+	recorder := NewMarketDataRecorder(sink)
+
+	stream := types.NewStandardStream()
+	recorder.BindStream(types.ExchangeBinance, &stream)
+
+	book := types.SliceOrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(100.0), Volume: fixedpoint.NewFromFloat(1.0)}},
+	}
+
+	stream.EmitKLineClosed(types.KLine{Symbol: "BTCUSDT", Interval: types.Interval1m, Close: 100.0})
+	stream.EmitBookSnapshot(book)
+	stream.EmitBookUpdate(book)
+	stream.EmitMarketTrade(types.Trade{ID: 1, Symbol: "BTCUSDT", Price: 100.0})
+
+	// the recorded book is a copy
+	book.Bids[0].Price = fixedpoint.NewFromFloat(101.0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	recorder.Run(ctx)
+
+	assert.True(t, sink.closed)
+	if assert.Len(t, sink.records, 4) {
+		assert.Equal(t, MarketDataTypeKLine, sink.records[0].Type)
+		assert.Equal(t, 100.0, sink.records[0].KLine.Close)
+		assert.Equal(t, MarketDataTypeBookSnapshot, sink.records[1].Type)
+		assert.Equal(t, 100.0, sink.records[1].Book.Bids[0].Price.Float64())
+		assert.Equal(t, MarketDataTypeBookUpdate, sink.records[2].Type)
+		assert.Equal(t, MarketDataTypeTrade, sink.records[3].Type)
+		assert.Equal(t, int64(1), sink.records[3].Trade.ID)
+
+		for _, record := range sink.records {
+			assert.Equal(t, types.ExchangeBinance, record.Exchange)
+			assert.Equal(t, "BTCUSDT", record.Symbol)
+			assert.False(t, record.Time.IsZero())
+		}
+	}
+}
+
+func TestFileMarketDataSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bbgo-record")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	day1 := time.Date(2021, 5, 1, 23, 59, 0, 0, time.UTC)
+	day2 := day1.Add(2 * time.Minute)
+
+	sink := NewFileMarketDataSink(dir)
+	assert.NoError(t, sink.Write(MarketDataRecord{Time: day1, Type: MarketDataTypeTrade, Exchange: types.ExchangeBinance, Symbol: "BTCUSDT", Trade: &types.Trade{ID: 1}}))
+	assert.NoError(t, sink.Write(MarketDataRecord{Time: day1, Type: MarketDataTypeTrade, Exchange: types.ExchangeBinance, Symbol: "BTCUSDT", Trade: &types.Trade{ID: 2}}))
+	assert.NoError(t, sink.Write(MarketDataRecord{Time: day2, Type: MarketDataTypeTrade, Exchange: types.ExchangeBinance, Symbol: "BTCUSDT", Trade: &types.Trade{ID: 3}}))
+	assert.NoError(t, sink.Close())
+
+	// append to the existing file of the day
+	sink = NewFileMarketDataSink(dir)
+	assert.NoError(t, sink.Write(MarketDataRecord{Time: day2, Type: MarketDataTypeTrade, Exchange: types.ExchangeBinance, Symbol: "BTCUSDT", Trade: &types.Trade{ID: 4}}))
+	assert.NoError(t, sink.Close())
+
+	records := readMarketDataFile(t, filepath.Join(dir, "binance", "BTCUSDT", "trade-2021-05-01.jsonl.gz"))
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, int64(1), records[0].Trade.ID)
+		assert.Equal(t, int64(2), records[1].Trade.ID)
+	}
+
+	records = readMarketDataFile(t, filepath.Join(dir, "binance", "BTCUSDT", "trade-2021-05-02.jsonl.gz"))
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, int64(3), records[0].Trade.ID)
+		assert.Equal(t, int64(4), records[1].Trade.ID)
+	}
+}
+
+func TestKLineDatabaseSink(t *testing.T) {
+	var inserted []types.KLine
+	sink := &KLineDatabaseSink{KLines: kLineInserterFunc(func(kline types.KLine) error {
+		inserted = append(inserted, kline)
+		return nil
+	})}
+
+	assert.NoError(t, sink.Write(MarketDataRecord{Type: MarketDataTypeKLine, KLine: &types.KLine{Symbol: "BTCUSDT"}}))
+	assert.NoError(t, sink.Write(MarketDataRecord{Type: MarketDataTypeTrade, Trade: &types.Trade{ID: 1}}))
+	assert.Len(t, inserted, 1)
+}
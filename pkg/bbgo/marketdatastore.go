@@ -52,6 +52,12 @@ func (store *MarketDataStore) AddKLine(kline types.KLine) {
 	if !ok {
 		window = make(types.KLineWindow, 0, 1000)
 	}
+
+	// skip the loaded klines, the historical klines fed at startup may overlap the initial klines
+	if len(window) > 0 && !kline.EndTime.After(window.Last().EndTime) {
+		return
+	}
+
 	window.Add(kline)
 
 	if len(window) > MaxNumOfKLines {
@@ -0,0 +1,329 @@
+package bbgo
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// NotificationEvent is the kind of the notification matched by the notification rules
+type NotificationEvent string
+
+const (
+	NotificationEventTrade       NotificationEvent = "trade"
+	NotificationEventOrder       NotificationEvent = "order"
+	NotificationEventSubmitOrder NotificationEvent = "submitOrder"
+	NotificationEventPosition    NotificationEvent = "position"
+	NotificationEventPnL         NotificationEvent = "pnl"
+	NotificationEventLog         NotificationEvent = "log"
+
+	// NotificationEventMessage is the text notification without the objects above
+	NotificationEventMessage NotificationEvent = "message"
+)
+
+// NotificationSeverity is the severity of the notification, the log notifications have the severity of the log level,
+// the other notifications are info
+type NotificationSeverity string
+
+const (
+	NotificationSeverityInfo    NotificationSeverity = "info"
+	NotificationSeverityWarning NotificationSeverity = "warning"
+	NotificationSeverityError   NotificationSeverity = "error"
+)
+
+// NotificationRule routes the matched notifications to the notifiers.
+// The empty matchers match all the notifications, e.g., the rule with the notifiers only is a catch-all rule.
+type NotificationRule struct {
+	// Notifiers are the names of the notifiers: slack, telegram, discord and the names of the webhooks
+	Notifiers []string `json:"notifiers" yaml:"notifiers"`
+
+	Events []NotificationEvent `json:"events,omitempty" yaml:"events,omitempty"`
+
+	// Sessions are the regular expressions of the session names,
+	// the trades and the orders are matched by the sessions of their exchange
+	Sessions []string `json:"sessions,omitempty" yaml:"sessions,omitempty"`
+
+	// Symbols are the regular expressions of the symbols
+	Symbols []string `json:"symbols,omitempty" yaml:"symbols,omitempty"`
+
+	Severities []NotificationSeverity `json:"severities,omitempty" yaml:"severities,omitempty"`
+
+	// Channel overrides the channel of the matched notifications, e.g., the slack channel
+	Channel string `json:"channel,omitempty" yaml:"channel,omitempty"`
+
+	sessionPatterns []*regexp.Regexp
+	symbolPatterns  []*regexp.Regexp
+}
+
+// Validate validates the rule and compiles the patterns
+func (r *NotificationRule) Validate() error {
+	if len(r.Notifiers) == 0 {
+		return errors.New("notification rule notifiers are required")
+	}
+
+	for _, event := range r.Events {
+		switch event {
+		case NotificationEventTrade, NotificationEventOrder, NotificationEventSubmitOrder, NotificationEventPosition,
+			NotificationEventPnL, NotificationEventLog, NotificationEventMessage:
+		default:
+			return fmt.Errorf("unsupported notification event %q", event)
+		}
+	}
+
+	for _, severity := range r.Severities {
+		switch severity {
+		case NotificationSeverityInfo, NotificationSeverityWarning, NotificationSeverityError:
+		default:
+			return fmt.Errorf("unsupported notification severity %q", severity)
+		}
+	}
+
+	r.sessionPatterns = nil
+	for _, pattern := range r.Sessions {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return errors.Wrapf(err, "invalid notification rule session pattern %q", pattern)
+		}
+		r.sessionPatterns = append(r.sessionPatterns, re)
+	}
+
+	r.symbolPatterns = nil
+	for _, pattern := range r.Symbols {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return errors.Wrapf(err, "invalid notification rule symbol pattern %q", pattern)
+		}
+		r.symbolPatterns = append(r.symbolPatterns, re)
+	}
+
+	return nil
+}
+
+func (r *NotificationRule) hasNotifier(name string) bool {
+	for _, n := range r.Notifiers {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *NotificationRule) match(n notification, sessionNames []string) bool {
+	if len(r.Events) > 0 && !containsEvent(r.Events, n.event) {
+		return false
+	}
+
+	if len(r.Severities) > 0 && !containsSeverity(r.Severities, n.severity) {
+		return false
+	}
+
+	if len(r.symbolPatterns) > 0 && !matchAnyPattern(r.symbolPatterns, n.symbol) {
+		return false
+	}
+
+	if len(r.sessionPatterns) > 0 {
+		var matched = false
+		for _, name := range sessionNames {
+			if matchAnyPattern(r.sessionPatterns, name) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsEvent(events []NotificationEvent, event NotificationEvent) bool {
+	for _, e := range events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func containsSeverity(severities []NotificationSeverity, severity NotificationSeverity) bool {
+	for _, s := range severities {
+		if s == severity {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAnyPattern(patterns []*regexp.Regexp, s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+
+	for _, re := range patterns {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// notification is the routing information extracted from the notification object and the arguments
+type notification struct {
+	event    NotificationEvent
+	severity NotificationSeverity
+	symbol   string
+	exchange types.ExchangeName
+}
+
+// newNotification extracts the routing information from the first known object of the notification
+func newNotification(obj interface{}, args ...interface{}) notification {
+	for _, o := range append([]interface{}{obj}, args...) {
+		switch o := o.(type) {
+		case types.Trade:
+			return notification{event: NotificationEventTrade, symbol: o.Symbol, exchange: o.Exchange}
+		case *types.Trade:
+			return notification{event: NotificationEventTrade, symbol: o.Symbol, exchange: o.Exchange}
+		case types.Order:
+			return notification{event: NotificationEventOrder, symbol: o.Symbol, exchange: o.Exchange}
+		case *types.Order:
+			return notification{event: NotificationEventOrder, symbol: o.Symbol, exchange: o.Exchange}
+		case types.SubmitOrder:
+			return notification{event: NotificationEventSubmitOrder, symbol: o.Symbol}
+		case *types.SubmitOrder:
+			return notification{event: NotificationEventSubmitOrder, symbol: o.Symbol}
+		case *types.Position:
+			return notification{event: NotificationEventPosition, symbol: o.Symbol}
+		case types.ProfitSummary, *types.ProfitSummary, Profit, *Profit:
+			return notification{event: NotificationEventPnL}
+		case *types.LogNotification:
+			return notification{event: NotificationEventLog, severity: severityOfLevel(o.Level)}
+		}
+	}
+
+	return notification{event: NotificationEventMessage}
+}
+
+func severityOfLevel(level string) NotificationSeverity {
+	switch level {
+	case logrus.WarnLevel.String():
+		return NotificationSeverityWarning
+	case logrus.ErrorLevel.String(), logrus.FatalLevel.String(), logrus.PanicLevel.String():
+		return NotificationSeverityError
+	}
+
+	return NotificationSeverityInfo
+}
+
+// NotificationRouter routes the notifications to the named notifiers by the rules.
+// The rules are evaluated in order, the first matched rule decides the notifiers,
+// the notifications that match no rule are sent to all the notifiers.
+type NotificationRouter struct {
+	rules []NotificationRule
+
+	// sessionNames returns the session names of the exchange
+	sessionNames func(exchange types.ExchangeName) []string
+}
+
+func NewNotificationRouter(rules []NotificationRule, sessionNames func(exchange types.ExchangeName) []string) (*NotificationRouter, error) {
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &NotificationRouter{rules: rules, sessionNames: sessionNames}, nil
+}
+
+// Match returns the first rule matched by the notification
+func (r *NotificationRouter) Match(obj interface{}, args ...interface{}) (*NotificationRule, bool) {
+	n := newNotification(obj, args...)
+
+	var sessionNames []string
+	if len(n.exchange) > 0 && r.sessionNames != nil {
+		sessionNames = r.sessionNames(n.exchange)
+	}
+
+	for i := range r.rules {
+		if r.rules[i].match(n, sessionNames) {
+			return &r.rules[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// severityLevels returns the log levels required by the severity rules
+func (r *NotificationRouter) severityLevels() (levels []logrus.Level) {
+	var warning, err bool
+	for _, rule := range r.rules {
+		warning = warning || containsSeverity(rule.Severities, NotificationSeverityWarning)
+		err = err || containsSeverity(rule.Severities, NotificationSeverityError)
+	}
+
+	if err {
+		levels = append(levels, logrus.ErrorLevel, logrus.FatalLevel, logrus.PanicLevel)
+	}
+
+	if warning {
+		levels = append(levels, logrus.WarnLevel)
+	}
+
+	return levels
+}
+
+var notificationLogHookLimiter = rate.NewLimiter(rate.Every(time.Minute), 20)
+
+// notifierServices are the service fields of the notifier error logs, they are not sent to avoid the notification loop
+var notifierServices = map[string]struct{}{
+	"slack": {}, "telegram": {}, "discord": {}, "webhook": {},
+}
+
+// NotificationLogHook sends the log entries as the log notifications, so that they are routed by the severity rules
+type NotificationLogHook struct {
+	notifiability *Notifiability
+	levels        []logrus.Level
+}
+
+func NewNotificationLogHook(notifiability *Notifiability, levels ...logrus.Level) *NotificationLogHook {
+	return &NotificationLogHook{notifiability: notifiability, levels: levels}
+}
+
+func (h *NotificationLogHook) Levels() []logrus.Level {
+	return h.levels
+}
+
+func (h *NotificationLogHook) Fire(e *logrus.Entry) error {
+	if service, ok := e.Data["service"].(string); ok {
+		if _, isNotifier := notifierServices[service]; isNotifier {
+			return nil
+		}
+	}
+
+	if !notificationLogHookLimiter.Allow() {
+		return nil
+	}
+
+	fields := make(map[string]interface{}, len(e.Data))
+	for k, v := range e.Data {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		fields[k] = v
+	}
+
+	h.notifiability.Notify(&types.LogNotification{
+		Level:   e.Level.String(),
+		Message: e.Message,
+		Fields:  fields,
+		Time:    e.Time,
+	})
+	return nil
+}
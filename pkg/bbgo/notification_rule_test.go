@@ -0,0 +1,153 @@
+package bbgo
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type channelTestNotifier struct {
+	channels []string
+	objects  []interface{}
+}
+
+func (n *channelTestNotifier) NotifyTo(channel string, obj interface{}, args ...interface{}) {
+	n.channels = append(n.channels, channel)
+	n.objects = append(n.objects, obj)
+}
+
+func (n *channelTestNotifier) Notify(obj interface{}, args ...interface{}) {
+	n.NotifyTo("", obj, args...)
+}
+
+func TestNotificationRule_Validate(t *testing.T) {
+	rule := NotificationRule{}
+	assert.Error(t, rule.Validate())
+
+	rule = NotificationRule{Notifiers: []string{"slack"}, Events: []NotificationEvent{"kline"}}
+	assert.Error(t, rule.Validate())
+
+	rule = NotificationRule{Notifiers: []string{"slack"}, Severities: []NotificationSeverity{"fatal"}}
+	assert.Error(t, rule.Validate())
+
+	rule = NotificationRule{Notifiers: []string{"slack"}, Symbols: []string{"^BTC("}}
+	assert.Error(t, rule.Validate())
+
+	rule = NotificationRule{Notifiers: []string{"slack"}, Symbols: []string{"^BTC"}, Sessions: []string{"^binance$"}}
+	assert.NoError(t, rule.Validate())
+}
+
+func TestNotificationRouter_Match(t *testing.T) {
+	sessionNames := func(exchange types.ExchangeName) []string {
+		if exchange == types.ExchangeBinance {
+			return []string{"binance", "binance-margin"}
+		}
+		return []string{string(exchange)}
+	}
+
+	router, err := NewNotificationRouter([]NotificationRule{
+		{Notifiers: []string{"pagerduty"}, Severities: []NotificationSeverity{NotificationSeverityError}},
+		{Notifiers: []string{"slack"}, Events: []NotificationEvent{NotificationEventTrade}, Symbols: []string{"^BTCUSDT$"}, Channel: "btc-fills"},
+		{Notifiers: []string{"telegram"}, Events: []NotificationEvent{NotificationEventPnL}},
+		{Notifiers: []string{"discord"}, Sessions: []string{"^max$"}},
+	}, sessionNames)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	rule, ok := router.Match(&types.LogNotification{Level: "error", Message: "stream error"})
+	if assert.True(t, ok) {
+		assert.Equal(t, []string{"pagerduty"}, rule.Notifiers)
+	}
+
+	// the warning logs are not matched by the error rule
+	_, ok = router.Match(&types.LogNotification{Level: "warning"})
+	assert.False(t, ok)
+
+	rule, ok = router.Match(&types.Trade{Symbol: "BTCUSDT", Exchange: types.ExchangeBinance})
+	if assert.True(t, ok) {
+		assert.Equal(t, "btc-fills", rule.Channel)
+	}
+
+	rule, ok = router.Match(types.ProfitSummary{Strategy: "grid"})
+	if assert.True(t, ok) {
+		assert.Equal(t, []string{"telegram"}, rule.Notifiers)
+	}
+
+	// the order is matched by the sessions of its exchange, the object in the arguments is used
+	rule, ok = router.Match("order is filled", &types.Order{SubmitOrder: types.SubmitOrder{Symbol: "ETHUSDT"}, Exchange: types.ExchangeMax})
+	if assert.True(t, ok) {
+		assert.Equal(t, []string{"discord"}, rule.Notifiers)
+	}
+
+	_, ok = router.Match(&types.Trade{Symbol: "ETHUSDT", Exchange: types.ExchangeBinance})
+	assert.False(t, ok)
+
+	_, ok = router.Match("hello")
+	assert.False(t, ok)
+}
+
+func TestNotifiability_NotificationRouter(t *testing.T) {
+	slack := &channelTestNotifier{}
+	telegram := &channelTestNotifier{}
+	publisher := &channelTestNotifier{}
+
+	notifiability := &Notifiability{}
+	notifiability.AddNamedNotifier("slack", slack)
+	notifiability.AddNamedNotifier("telegram", telegram)
+	notifiability.AddNotifier(publisher)
+
+	router, err := NewNotificationRouter([]NotificationRule{
+		{Notifiers: []string{"slack"}, Events: []NotificationEvent{NotificationEventTrade}, Channel: "fills"},
+		{Notifiers: []string{"telegram"}, Events: []NotificationEvent{NotificationEventPnL}},
+	}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	notifiability.NotificationRouter = router
+
+	notifiability.NotifyTo("btc", &types.Trade{Symbol: "BTCUSDT"})
+	assert.Equal(t, []string{"fills"}, slack.channels)
+	assert.Len(t, telegram.objects, 0)
+
+	notifiability.Notify(&types.ProfitSummary{Strategy: "grid"})
+	assert.Len(t, slack.objects, 1)
+	assert.Len(t, telegram.objects, 1)
+
+	// the notifications matched by no rule are sent to all the notifiers
+	notifiability.Notify("hello")
+	assert.Len(t, slack.objects, 2)
+	assert.Len(t, telegram.objects, 2)
+
+	// the unnamed notifiers are not routed
+	assert.Len(t, publisher.objects, 3)
+}
+
+func TestNotificationLogHook(t *testing.T) {
+	notifier := &channelTestNotifier{}
+	notifiability := &Notifiability{}
+	notifiability.AddNamedNotifier("pagerduty", notifier)
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	logger.AddHook(NewNotificationLogHook(notifiability, logrus.ErrorLevel))
+
+	logger.WithField("session", "binance").Error("stream error")
+	if assert.Len(t, notifier.objects, 1) {
+		n, ok := notifier.objects[0].(*types.LogNotification)
+		if assert.True(t, ok) {
+			assert.Equal(t, "error", n.Level)
+			assert.Equal(t, "stream error", n.Message)
+			assert.Equal(t, map[string]interface{}{"session": "binance"}, n.Fields)
+		}
+	}
+
+	// the errors of the notifiers are skipped
+	logger.WithField("service", "slack").Error("slack api error")
+	logger.Warn("warning is not sent")
+	assert.Len(t, notifier.objects, 1)
+}
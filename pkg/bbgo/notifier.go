@@ -12,10 +12,17 @@ func (n *NullNotifier) NotifyTo(channel string, obj interface{}, args ...interfa
 func (n *NullNotifier) Notify(obj interface{}, args ...interface{}) {}
 
 type Notifiability struct {
-	notifiers            []Notifier
+	notifiers []Notifier
+
+	// notifierNames are the names of the notifiers, the unnamed notifiers are not routed by the notification rules
+	notifierNames []string
+
 	SessionChannelRouter *PatternChannelRouter `json:"-"`
 	SymbolChannelRouter  *PatternChannelRouter `json:"-"`
 	ObjectChannelRouter  *ObjectChannelRouter  `json:"-"`
+
+	// NotificationRouter routes the notifications to the named notifiers by the notification rules
+	NotificationRouter *NotificationRouter `json:"-"`
 }
 
 // RouteSymbol routes symbol name to channel
@@ -44,17 +51,70 @@ func (m *Notifiability) RouteObject(obj interface{}) (channel string, ok bool) {
 
 // AddNotifier adds the notifier that implements the Notifier interface.
 func (m *Notifiability) AddNotifier(notifier Notifier) {
+	m.AddNamedNotifier("", notifier)
+}
+
+// AddNamedNotifier adds the notifier with the name, the notification rules refer to the notifiers by the names
+func (m *Notifiability) AddNamedNotifier(name string, notifier Notifier) {
 	m.notifiers = append(m.notifiers, notifier)
+	m.notifierNames = append(m.notifierNames, name)
+}
+
+func (m *Notifiability) hasNotifier(name string) bool {
+	for _, n := range m.notifierNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// matchRule returns the notification rule matched by the notification
+func (m *Notifiability) matchRule(obj interface{}, args ...interface{}) *NotificationRule {
+	if m.NotificationRouter == nil {
+		return nil
+	}
+
+	rule, _ := m.NotificationRouter.Match(obj, args...)
+	return rule
 }
 
 func (m *Notifiability) Notify(obj interface{}, args ...interface{}) {
-	for _, n := range m.notifiers {
-		n.Notify(obj, args...)
+	rule := m.matchRule(obj, args...)
+	for i, n := range m.notifiers {
+		if rule == nil || m.notifierNames[i] == "" {
+			n.Notify(obj, args...)
+			continue
+		}
+
+		if !rule.hasNotifier(m.notifierNames[i]) {
+			continue
+		}
+
+		if len(rule.Channel) > 0 {
+			n.NotifyTo(rule.Channel, obj, args...)
+		} else {
+			n.Notify(obj, args...)
+		}
 	}
 }
 
 func (m *Notifiability) NotifyTo(channel string, obj interface{}, args ...interface{}) {
-	for _, n := range m.notifiers {
-		n.NotifyTo(channel, obj, args...)
+	rule := m.matchRule(obj, args...)
+	for i, n := range m.notifiers {
+		if rule == nil || m.notifierNames[i] == "" {
+			n.NotifyTo(channel, obj, args...)
+			continue
+		}
+
+		if !rule.hasNotifier(m.notifierNames[i]) {
+			continue
+		}
+
+		if len(rule.Channel) > 0 {
+			n.NotifyTo(rule.Channel, obj, args...)
+		} else {
+			n.NotifyTo(channel, obj, args...)
+		}
 	}
 }
@@ -48,6 +48,22 @@ func (e *ExchangeOrderExecutionRouter) SubmitOrdersTo(ctx context.Context, sessi
 		return nil, err
 	}
 
+	if _, err := es.checkSymbolGuard(formattedOrders); err != nil {
+		return nil, err
+	}
+
+	if _, err := es.checkMarketFilters(formattedOrders); err != nil {
+		return nil, err
+	}
+
+	if _, err := es.checkOrderSanity(formattedOrders); err != nil {
+		return nil, err
+	}
+
+	if _, err := es.convertQuoteCurrencies(ctx, formattedOrders); err != nil {
+		log.WithError(err).Warnf("session %s can not convert the quote currencies", es.Name)
+	}
+
 	return es.Exchange.SubmitOrders(ctx, formattedOrders...)
 }
 
@@ -65,6 +81,12 @@ type ExchangeOrderExecutor struct {
 
 	// private order update callbacks
 	orderUpdateCallbacks []func(order types.Order)
+
+	// submitOrderCallbacks are called before the orders are sent to the exchange
+	submitOrderCallbacks []func(order types.SubmitOrder)
+
+	// submitOrderErrorCallbacks are called when the exchange rejects the order submission
+	submitOrderErrorCallbacks []func(order types.SubmitOrder, err error)
 }
 
 func (e *ExchangeOrderExecutor) notifySubmitOrders(orders ...types.SubmitOrder) {
@@ -80,11 +102,54 @@ func (e *ExchangeOrderExecutor) notifySubmitOrders(orders ...types.SubmitOrder)
 }
 
 func (e *ExchangeOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	if e.Session.IsBootstrapping() {
+		return nil, ErrSessionBootstrapping
+	}
+
 	formattedOrders, err := formatOrders(e.Session, orders)
 	if err != nil {
 		return nil, err
 	}
 
+	// the symbol guard rejects the whole batch, so that a misconfigured strategy does not trade partially
+	if rejectedOrder, err := e.Session.checkSymbolGuard(formattedOrders); err != nil {
+		e.Notify(":no_entry: session %s rejected the %s %s order: %v", e.Session.Name, rejectedOrder.Symbol, rejectedOrder.Side, err)
+		e.EmitSubmitOrderError(rejectedOrder, err)
+		return nil, err
+	}
+
+	// the orders violating the market filters can not be accepted by the exchange, reject them before submission
+	if rejectedOrder, err := e.Session.checkMarketFilters(formattedOrders); err != nil {
+		e.Notify(":no_entry: session %s rejected the %s %s order by the market filters: %v", e.Session.Name, rejectedOrder.Symbol, rejectedOrder.Side, err)
+		e.EmitSubmitOrderError(rejectedOrder, err)
+		return nil, err
+	}
+
+	// the fat-finger protection rejects the whole batch as well
+	if rejectedOrder, err := e.Session.checkOrderSanity(formattedOrders); err != nil {
+		e.Notify(":no_entry: session %s rejected the %s %s order by the sanity check: %v", e.Session.Name, rejectedOrder.Symbol, rejectedOrder.Side, err)
+		e.EmitSubmitOrderError(rejectedOrder, err)
+		return nil, err
+	}
+
+	// reject the unsupported orders before sending them to the exchange
+	capabilities := e.Session.Capabilities()
+	for _, order := range formattedOrders {
+		if err := checkOrderCapabilities(capabilities, order); err != nil {
+			return nil, fmt.Errorf("%s order of %s is not supported by exchange %s: %w", order.Type, order.Symbol, e.Session.ExchangeName, err)
+		}
+	}
+
+	// the conversion is best effort, the orders are still submitted and the exchange rejects them if the balance is not enough
+	conversions, err := e.Session.convertQuoteCurrencies(ctx, formattedOrders)
+	for _, c := range conversions {
+		e.Notify(":currency_exchange: session %s converted %s to %f %s for the buy orders", e.Session.Name, c.From, c.Amount, c.To, &c.Order)
+	}
+
+	if err != nil {
+		e.Notify(":warning: session %s can not convert the quote currencies: %v", e.Session.Name, err)
+	}
+
 	for _, order := range formattedOrders {
 		// pass submit order as an interface object.
 		channel, ok := e.RouteObject(&order)
@@ -99,7 +164,18 @@ func (e *ExchangeOrderExecutor) SubmitOrders(ctx context.Context, orders ...type
 
 	e.notifySubmitOrders(formattedOrders...)
 
-	return e.Session.Exchange.SubmitOrders(ctx, formattedOrders...)
+	for _, order := range formattedOrders {
+		e.EmitSubmitOrder(order)
+	}
+
+	createdOrders, err := e.Session.Exchange.SubmitOrders(ctx, formattedOrders...)
+	if err != nil && len(createdOrders) < len(formattedOrders) {
+		// the exchanges submit the orders one by one and stop at the first error,
+		// so the order right after the created orders is the rejected one.
+		e.EmitSubmitOrderError(formattedOrders[len(createdOrders)], err)
+	}
+
+	return createdOrders, err
 }
 
 type BasicRiskController struct {
@@ -300,6 +376,34 @@ func formatOrders(session *ExchangeSession, orders []types.SubmitOrder) (formatt
 	return formattedOrders, err
 }
 
+// checkOrderCapabilities verifies the order type, the time in force and the post-only mode against the exchange capabilities
+func checkOrderCapabilities(capabilities types.ExchangeCapabilities, order types.SubmitOrder) error {
+	if !capabilities.SupportsOrderType(order.Type) {
+		return fmt.Errorf("order type %s is not supported", order.Type)
+	}
+
+	if !capabilities.SupportsTimeInForce(order.TimeInForce) {
+		return fmt.Errorf("time in force %s is not supported", order.TimeInForce)
+	}
+
+	if order.PostOnly && !order.IsPostOnly() {
+		return errors.New("post-only is only applicable to the limit orders")
+	}
+
+	if order.IsPostOnly() {
+		if !capabilities.SupportsPostOnly() {
+			return errors.New("post-only is not supported")
+		}
+
+		switch order.TimeInForce {
+		case types.TimeInForceIOC, types.TimeInForceFOK:
+			return fmt.Errorf("post-only order can not be %s", order.TimeInForce)
+		}
+	}
+
+	return nil
+}
+
 func max(a, b int64) int64 {
 	if a > b {
 		return a
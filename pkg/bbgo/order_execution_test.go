@@ -0,0 +1,112 @@
+package bbgo
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// recordOrderExecutor records the submitted orders and creates them without the exchange
+type recordOrderExecutor struct {
+	*ExchangeOrderExecutor
+
+	mu     sync.Mutex
+	orders []types.SubmitOrder
+}
+
+func (e *recordOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, order := range orders {
+		e.orders = append(e.orders, order)
+		createdOrders = append(createdOrders, types.Order{
+			SubmitOrder: order,
+			OrderID:     uint64(len(e.orders)),
+			Status:      types.OrderStatusNew,
+		})
+	}
+
+	return createdOrders, nil
+}
+
+type capabilityTestExchange struct {
+	guardTestExchange
+
+	capabilities types.ExchangeCapabilities
+}
+
+func (e *capabilityTestExchange) Capabilities() types.ExchangeCapabilities {
+	return e.capabilities
+}
+
+func Test_checkOrderCapabilities(t *testing.T) {
+	capabilities := types.ExchangeCapabilities{
+		OrderTypes:   []types.OrderType{types.OrderTypeLimit, types.OrderTypeLimitMaker, types.OrderTypeMarket},
+		TimeInForces: []string{types.TimeInForceGTC, types.TimeInForceIOC},
+	}
+
+	limitOrder := types.SubmitOrder{Symbol: "BTCUSDT", Type: types.OrderTypeLimit}
+	assert.NoError(t, checkOrderCapabilities(capabilities, limitOrder))
+
+	order := limitOrder
+	order.TimeInForce = types.TimeInForceIOC
+	assert.NoError(t, checkOrderCapabilities(capabilities, order))
+
+	order.TimeInForce = types.TimeInForceFOK
+	assert.Error(t, checkOrderCapabilities(capabilities, order))
+
+	order = limitOrder
+	order.PostOnly = true
+	assert.NoError(t, checkOrderCapabilities(capabilities, order))
+
+	// the post-only order can not be taker
+	order.TimeInForce = types.TimeInForceIOC
+	assert.Error(t, checkOrderCapabilities(capabilities, order))
+
+	order = types.SubmitOrder{Symbol: "BTCUSDT", Type: types.OrderTypeMarket, PostOnly: true}
+	assert.Error(t, checkOrderCapabilities(capabilities, order))
+
+	capabilities.OrderTypes = []types.OrderType{types.OrderTypeLimit, types.OrderTypeMarket}
+	order = limitOrder
+	order.PostOnly = true
+	assert.Error(t, checkOrderCapabilities(capabilities, order))
+}
+
+func TestExchangeOrderExecutor_Capabilities(t *testing.T) {
+	exchange := &capabilityTestExchange{
+		capabilities: types.ExchangeCapabilities{
+			OrderTypes:   []types.OrderType{types.OrderTypeLimit, types.OrderTypeMarket},
+			TimeInForces: []string{types.TimeInForceGTC},
+		},
+	}
+
+	session := &ExchangeSession{
+		Name:         "binance",
+		ExchangeName: types.ExchangeBinance,
+		Exchange:     exchange,
+		markets: map[string]types.Market{
+			"BTCUSDT": {Symbol: "BTCUSDT", TickSize: 0.01, StepSize: 0.0001},
+		},
+	}
+	session.OrderExecutor = &ExchangeOrderExecutor{Session: session}
+
+	ctx := context.Background()
+	_, err := session.OrderExecutor.SubmitOrders(ctx,
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 100.0, Quantity: 0.01, TimeInForce: types.TimeInForceIOC})
+	assert.Error(t, err)
+
+	_, err = session.OrderExecutor.SubmitOrders(ctx,
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 100.0, Quantity: 0.01, PostOnly: true})
+	assert.Error(t, err)
+	assert.Len(t, exchange.orders, 0)
+
+	createdOrders, err := session.OrderExecutor.SubmitOrders(ctx,
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 100.0, Quantity: 0.01, TimeInForce: types.TimeInForceGTC})
+	assert.NoError(t, err)
+	assert.Len(t, createdOrders, 1)
+}
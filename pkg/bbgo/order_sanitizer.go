@@ -0,0 +1,92 @@
+package bbgo
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var ErrOrderPriceRequired = errors.New("order price is required")
+
+var ErrOrderPriceOutOfRange = errors.New("order price is out of the price range of the market")
+
+var ErrOrderQuantityTooSmall = errors.New("order quantity is less than the minimal quantity of the market")
+
+var ErrOrderQuantityTooLarge = errors.New("order quantity exceeds the maximal quantity of the market")
+
+var ErrOrderNotionalTooSmall = errors.New("order notional is less than the minimal notional of the market")
+
+// CheckMarketFilters checks the formatted order against the filters of the order market, the order can not be
+// accepted by the exchange if any of the filters is violated. The notional of the market orders is estimated by the
+// reference price, it's not checked if the reference price is not available.
+func CheckMarketFilters(order types.SubmitOrder, referencePrice float64) error {
+	market := order.Market
+
+	if order.Quantity <= 0 || order.Quantity < market.MinQuantity {
+		return errors.Wrapf(ErrOrderQuantityTooSmall, "%s %s order quantity %s < %f",
+			order.Symbol, order.Side, order.QuantityString, market.MinQuantity)
+	}
+
+	if market.MaxQuantity > 0 && order.Quantity > market.MaxQuantity {
+		return errors.Wrapf(ErrOrderQuantityTooLarge, "%s %s order quantity %s > %f",
+			order.Symbol, order.Side, order.QuantityString, market.MaxQuantity)
+	}
+
+	var price float64
+	switch order.Type {
+	case types.OrderTypeMarket:
+		price = referencePrice
+
+	case types.OrderTypeStopMarket:
+		price = order.StopPrice
+
+	default:
+		if order.Price <= 0 {
+			return errors.Wrapf(ErrOrderPriceRequired, "price is required for the %s order of %s", order.Type, order.Symbol)
+		}
+
+		price = order.Price
+	}
+
+	for _, p := range []float64{order.Price, order.StopPrice} {
+		if p <= 0 {
+			continue
+		}
+
+		if (market.MinPrice > 0 && p < market.MinPrice) || (market.MaxPrice > 0 && p > market.MaxPrice) {
+			return errors.Wrapf(ErrOrderPriceOutOfRange, "%s %s order price %f is not in the range [%f, %f]",
+				order.Symbol, order.Side, p, market.MinPrice, market.MaxPrice)
+		}
+	}
+
+	if notional := order.Quantity * price; price > 0 && notional < market.MinNotional {
+		return errors.Wrapf(ErrOrderNotionalTooSmall, "%s %s order notional %f < %f",
+			order.Symbol, order.Side, notional, market.MinNotional)
+	}
+
+	return nil
+}
+
+// SanitizeOrder formats the order by the market precision, the price and the quantity are truncated unless the
+// rounding policy of the order is set, and then checks the order against the market filters
+func (session *ExchangeSession) SanitizeOrder(order types.SubmitOrder) (types.SubmitOrder, error) {
+	order, err := session.FormatOrder(order)
+	if err != nil {
+		return order, err
+	}
+
+	referencePrice, _ := session.ReferencePrice(order.Symbol)
+	return order, CheckMarketFilters(order, referencePrice)
+}
+
+// checkMarketFilters checks the formatted orders by the market filters, the first rejected order is returned with the error
+func (session *ExchangeSession) checkMarketFilters(orders []types.SubmitOrder) (types.SubmitOrder, error) {
+	for _, order := range orders {
+		referencePrice, _ := session.ReferencePrice(order.Symbol)
+		if err := CheckMarketFilters(order, referencePrice); err != nil {
+			return order, err
+		}
+	}
+
+	return types.SubmitOrder{}, nil
+}
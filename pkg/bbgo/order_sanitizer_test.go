@@ -0,0 +1,94 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestCheckMarketFilters(t *testing.T) {
+	market := types.Market{
+		Symbol:      "BTCUSDT",
+		MinQuantity: 0.001,
+		MaxQuantity: 100.0,
+		MinPrice:    1.0,
+		MaxPrice:    1000000.0,
+		MinNotional: 10.0,
+		StepSize:    0.001,
+		TickSize:    0.01,
+	}
+
+	limitOrder := func(price, quantity float64) types.SubmitOrder {
+		return types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Market: market, Price: price, Quantity: quantity}
+	}
+
+	assert.NoError(t, CheckMarketFilters(limitOrder(10000.0, 0.001), 0))
+
+	err := CheckMarketFilters(limitOrder(10000.0, 0.0001), 0)
+	assert.Equal(t, ErrOrderQuantityTooSmall, errors.Cause(err))
+
+	err = CheckMarketFilters(limitOrder(10000.0, 101.0), 0)
+	assert.Equal(t, ErrOrderQuantityTooLarge, errors.Cause(err))
+
+	err = CheckMarketFilters(limitOrder(0, 1.0), 0)
+	assert.Equal(t, ErrOrderPriceRequired, errors.Cause(err))
+
+	err = CheckMarketFilters(limitOrder(0.5, 100.0), 0)
+	assert.Equal(t, ErrOrderPriceOutOfRange, errors.Cause(err))
+
+	err = CheckMarketFilters(limitOrder(1000.0, 0.005), 0)
+	assert.Equal(t, ErrOrderNotionalTooSmall, errors.Cause(err))
+
+	// the notional of the market orders is estimated by the reference price
+	marketOrder := types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeMarket, Market: market, Quantity: 0.005}
+	assert.NoError(t, CheckMarketFilters(marketOrder, 0))
+	assert.NoError(t, CheckMarketFilters(marketOrder, 10000.0))
+
+	err = CheckMarketFilters(marketOrder, 1000.0)
+	assert.Equal(t, ErrOrderNotionalTooSmall, errors.Cause(err))
+}
+
+func TestExchangeSession_SanitizeOrder(t *testing.T) {
+	session := newKillSwitchTestSession(&killSwitchTestExchange{})
+	market := session.markets["BTCUSDT"]
+	market.MinNotional = 10.0
+	session.markets["BTCUSDT"] = market
+
+	// the price and the quantity are truncated by the precision
+	order, err := session.SanitizeOrder(types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 10000.129, Quantity: 0.00129})
+	assert.NoError(t, err)
+	assert.Equal(t, "10000.12", order.PriceString)
+	assert.Equal(t, "0.0012", order.QuantityString)
+
+	// the notional of the truncated quantity 0.0009 is below the minimal notional
+	_, err = session.SanitizeOrder(types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 10000.0, Quantity: 0.00099})
+	assert.Equal(t, ErrOrderNotionalTooSmall, errors.Cause(err))
+}
+
+func TestExchangeOrderExecutor_SubmitOrders_MarketFilters(t *testing.T) {
+	exchange := &killSwitchTestExchange{}
+	session := newKillSwitchTestSession(exchange)
+	executor := &ExchangeOrderExecutor{Session: session}
+
+	var rejectedOrders []types.SubmitOrder
+	executor.OnSubmitOrderError(func(order types.SubmitOrder, err error) {
+		rejectedOrders = append(rejectedOrders, order)
+	})
+
+	_, err := executor.SubmitOrders(context.Background(),
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 9000.0, Quantity: 0.1},
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 9000.0, Quantity: 0.00001},
+	)
+	assert.Equal(t, ErrOrderQuantityTooSmall, errors.Cause(err))
+	assert.Len(t, rejectedOrders, 1)
+	assert.Len(t, exchange.submittedOrders, 0)
+
+	_, err = executor.SubmitOrders(context.Background(),
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 9000.0, Quantity: 0.1})
+	assert.NoError(t, err)
+	assert.Len(t, exchange.submittedOrders, 1)
+}
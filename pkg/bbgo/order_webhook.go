@@ -0,0 +1,305 @@
+package bbgo
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// OrderEvent is the order state transition sent to the order webhooks
+type OrderEvent string
+
+const (
+	OrderEventSubmitted     OrderEvent = "submitted"
+	OrderEventAck           OrderEvent = "ack"
+	OrderEventPartiallyFill OrderEvent = "partial_fill"
+	OrderEventFilled        OrderEvent = "filled"
+	OrderEventCancelled     OrderEvent = "cancelled"
+	OrderEventRejected      OrderEvent = "rejected"
+)
+
+const (
+	OrderWebhookSignatureHeader = "X-BBGO-Signature"
+	OrderWebhookTimestampHeader = "X-BBGO-Timestamp"
+	OrderWebhookEventHeader     = "X-BBGO-Event"
+)
+
+const (
+	defaultOrderWebhookTimeout    = 5 * time.Second
+	defaultOrderWebhookMaxRetries = 3
+	defaultOrderWebhookQueueSize  = 1024
+)
+
+// OrderEventOf maps the order status of the order update to the order event
+func OrderEventOf(order types.Order) (OrderEvent, bool) {
+	switch order.Status {
+	case types.OrderStatusNew:
+		return OrderEventAck, true
+	case types.OrderStatusPartiallyFilled:
+		return OrderEventPartiallyFill, true
+	case types.OrderStatusFilled:
+		return OrderEventFilled, true
+	case types.OrderStatusCanceled:
+		return OrderEventCancelled, true
+	case types.OrderStatusRejected:
+		return OrderEventRejected, true
+	}
+
+	return "", false
+}
+
+type OrderWebhookConfig struct {
+	URL string `json:"url" yaml:"url"`
+
+	// Secret is used to sign the payload with HMAC-SHA256, the signature is sent in the X-BBGO-Signature header
+	Secret string `json:"secret,omitempty" yaml:"secret,omitempty"`
+
+	// Events filters the order events to send, empty means all events
+	Events []OrderEvent `json:"events,omitempty" yaml:"events,omitempty"`
+
+	// Sessions filters the exchange sessions, empty means all sessions
+	Sessions []string `json:"sessions,omitempty" yaml:"sessions,omitempty"`
+
+	Timeout    types.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	MaxRetries int            `json:"maxRetries,omitempty" yaml:"maxRetries,omitempty"`
+}
+
+func (c *OrderWebhookConfig) Validate() error {
+	if len(c.URL) == 0 {
+		return errors.New("order webhook url is required")
+	}
+
+	for _, event := range c.Events {
+		switch event {
+		case OrderEventSubmitted, OrderEventAck, OrderEventPartiallyFill, OrderEventFilled, OrderEventCancelled, OrderEventRejected:
+		default:
+			return fmt.Errorf("unsupported order webhook event %q", event)
+		}
+	}
+
+	return nil
+}
+
+// OrderWebhookPayload is the json body of the webhook request
+type OrderWebhookPayload struct {
+	// ID is the unique id of the event, receivers can use it to drop the duplicated deliveries
+	ID string `json:"id"`
+
+	// Sequence is increased on every event of the webhook, receivers can use it to order the events
+	Sequence int64 `json:"sequence"`
+
+	Event   OrderEvent `json:"event"`
+	Session string     `json:"session"`
+	Time    time.Time  `json:"time"`
+
+	// SubmitOrder is set for the submitted event and the rejected event of the submission
+	SubmitOrder *types.SubmitOrder `json:"submitOrder,omitempty"`
+
+	// Order is set for the order update events
+	Order *types.Order `json:"order,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// SignOrderWebhookPayload signs the timestamp and the body with HMAC-SHA256,
+// the receivers should verify the signature with the same secret.
+func SignOrderWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// OrderWebhook sends the order state transitions to the external order-management system.
+// The events are delivered in order by a background worker, the failed deliveries are retried with backoff.
+type OrderWebhook struct {
+	Config OrderWebhookConfig
+
+	client   *http.Client
+	queue    chan OrderWebhookPayload
+	sequence int64
+
+	// retryBackoff is the initial delay of the retries, it's doubled on every retry
+	retryBackoff time.Duration
+
+	// now is used for the testing
+	now func() time.Time
+}
+
+func NewOrderWebhook(config OrderWebhookConfig) *OrderWebhook {
+	timeout := time.Duration(config.Timeout)
+	if timeout == 0 {
+		timeout = defaultOrderWebhookTimeout
+	}
+
+	if config.MaxRetries == 0 {
+		config.MaxRetries = defaultOrderWebhookMaxRetries
+	}
+
+	return &OrderWebhook{
+		Config: config,
+		client: &http.Client{Timeout: timeout},
+		queue:  make(chan OrderWebhookPayload, defaultOrderWebhookQueueSize),
+
+		retryBackoff: time.Second,
+		now:          time.Now,
+	}
+}
+
+// Run delivers the queued events until the context is canceled
+func (w *OrderWebhook) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case payload := <-w.queue:
+			if err := w.deliver(ctx, payload); err != nil {
+				log.WithError(err).Errorf("order webhook %s delivery error, event %s %s dropped", w.Config.URL, payload.Event, payload.ID)
+			}
+		}
+	}
+}
+
+// BindSession sends the order events of the session order executor
+func (w *OrderWebhook) BindSession(session *ExchangeSession) {
+	if !w.acceptSession(session.Name) {
+		return
+	}
+
+	sessionName := session.Name
+	executor := session.OrderExecutor
+
+	executor.OnSubmitOrder(func(order types.SubmitOrder) {
+		w.Emit(OrderWebhookPayload{Event: OrderEventSubmitted, Session: sessionName, SubmitOrder: &order})
+	})
+
+	executor.OnSubmitOrderError(func(order types.SubmitOrder, err error) {
+		w.Emit(OrderWebhookPayload{Event: OrderEventRejected, Session: sessionName, SubmitOrder: &order, Error: err.Error()})
+	})
+
+	executor.OnOrderUpdate(func(order types.Order) {
+		event, ok := OrderEventOf(order)
+		if !ok {
+			return
+		}
+
+		w.Emit(OrderWebhookPayload{Event: event, Session: sessionName, Order: &order})
+	})
+}
+
+// Emit queues the event, the event is dropped if the queue is full so that the trading is never blocked
+func (w *OrderWebhook) Emit(payload OrderWebhookPayload) {
+	if !w.acceptEvent(payload.Event) {
+		return
+	}
+
+	payload.ID = uuid.New().String()
+	payload.Sequence = atomic.AddInt64(&w.sequence, 1)
+	if payload.Time.IsZero() {
+		payload.Time = w.now()
+	}
+
+	select {
+	case w.queue <- payload:
+	default:
+		log.Errorf("order webhook %s queue is full, event %s %s dropped", w.Config.URL, payload.Event, payload.ID)
+	}
+}
+
+func (w *OrderWebhook) acceptEvent(event OrderEvent) bool {
+	if len(w.Config.Events) == 0 {
+		return true
+	}
+
+	for _, e := range w.Config.Events {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (w *OrderWebhook) acceptSession(name string) bool {
+	if len(w.Config.Sessions) == 0 {
+		return true
+	}
+
+	for _, s := range w.Config.Sessions {
+		if s == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (w *OrderWebhook) deliver(ctx context.Context, payload OrderWebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	backoff := w.retryBackoff
+	for retry := 0; ; retry++ {
+		err = w.post(ctx, payload.Event, body)
+		if err == nil || retry >= w.Config.MaxRetries {
+			return err
+		}
+
+		log.WithError(err).Warnf("order webhook %s delivery error, retrying in %s", w.Config.URL, backoff)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
+
+func (w *OrderWebhook) post(ctx context.Context, event OrderEvent, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, w.Config.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(OrderWebhookEventHeader, string(event))
+
+	if len(w.Config.Secret) > 0 {
+		timestamp := w.now().Unix()
+		req.Header.Set(OrderWebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(OrderWebhookSignatureHeader, SignOrderWebhookPayload(w.Config.Secret, timestamp, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("order webhook %s responded with status %d", w.Config.URL, resp.StatusCode)
+	}
+
+	return nil
+}
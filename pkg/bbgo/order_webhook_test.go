@@ -0,0 +1,163 @@
+package bbgo
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestOrderEventOf(t *testing.T) {
+	var tests = []struct {
+		status types.OrderStatus
+		event  OrderEvent
+		ok     bool
+	}{
+		{types.OrderStatusNew, OrderEventAck, true},
+		{types.OrderStatusPartiallyFilled, OrderEventPartiallyFill, true},
+		{types.OrderStatusFilled, OrderEventFilled, true},
+		{types.OrderStatusCanceled, OrderEventCancelled, true},
+		{types.OrderStatusRejected, OrderEventRejected, true},
+		{types.OrderStatus("EXPIRED"), "", false},
+	}
+
+	for _, test := range tests {
+		event, ok := OrderEventOf(types.Order{Status: test.status})
+		assert.Equal(t, test.ok, ok, string(test.status))
+		assert.Equal(t, test.event, event, string(test.status))
+	}
+}
+
+func TestOrderWebhookConfig_Validate(t *testing.T) {
+	config := OrderWebhookConfig{}
+	assert.Error(t, config.Validate())
+
+	config = OrderWebhookConfig{URL: "http://localhost", Events: []OrderEvent{OrderEventFilled, "expired"}}
+	assert.Error(t, config.Validate())
+
+	config = OrderWebhookConfig{URL: "http://localhost", Events: []OrderEvent{OrderEventFilled, OrderEventCancelled}}
+	assert.NoError(t, config.Validate())
+}
+
+type receivedOrderWebhook struct {
+	payload   OrderWebhookPayload
+	timestamp int64
+	signature string
+	body      []byte
+}
+
+func TestOrderWebhook_Deliver(t *testing.T) {
+	receivedC := make(chan receivedOrderWebhook, 10)
+	failures := 1
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// fail the first request to test the retry
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var received = receivedOrderWebhook{body: body, signature: r.Header.Get(OrderWebhookSignatureHeader)}
+		received.timestamp, _ = strconv.ParseInt(r.Header.Get(OrderWebhookTimestampHeader), 10, 64)
+		if err := json.Unmarshal(body, &received.payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		receivedC <- received
+	}))
+	defer server.Close()
+
+	webhook := NewOrderWebhook(OrderWebhookConfig{
+		URL:    server.URL,
+		Secret: "secret",
+		Events: []OrderEvent{OrderEventSubmitted, OrderEventFilled},
+	})
+	webhook.retryBackoff = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go webhook.Run(ctx)
+
+	submitOrder := types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Quantity: 0.1, Price: 48000.0}
+	webhook.Emit(OrderWebhookPayload{Event: OrderEventSubmitted, Session: "binance", SubmitOrder: &submitOrder})
+
+	// the ack event is filtered out
+	order := types.Order{SubmitOrder: submitOrder, OrderID: 1, Status: types.OrderStatusNew}
+	webhook.Emit(OrderWebhookPayload{Event: OrderEventAck, Session: "binance", Order: &order})
+
+	order.Status = types.OrderStatusFilled
+	order.ExecutedQuantity = 0.1
+	webhook.Emit(OrderWebhookPayload{Event: OrderEventFilled, Session: "binance", Order: &order})
+
+	receive := func() receivedOrderWebhook {
+		select {
+		case received := <-receivedC:
+			return received
+		case <-time.After(5 * time.Second):
+			t.Fatal("order webhook is not received")
+		}
+		return receivedOrderWebhook{}
+	}
+
+	received := receive()
+	assert.Equal(t, OrderEventSubmitted, received.payload.Event)
+	assert.Equal(t, int64(1), received.payload.Sequence)
+	assert.NotEmpty(t, received.payload.ID)
+	assert.Equal(t, "binance", received.payload.Session)
+	if assert.NotNil(t, received.payload.SubmitOrder) {
+		assert.Equal(t, "BTCUSDT", received.payload.SubmitOrder.Symbol)
+	}
+	assert.Equal(t, SignOrderWebhookPayload("secret", received.timestamp, received.body), received.signature)
+
+	received = receive()
+	assert.Equal(t, OrderEventFilled, received.payload.Event)
+	assert.Equal(t, int64(2), received.payload.Sequence)
+	if assert.NotNil(t, received.payload.Order) {
+		assert.Equal(t, uint64(1), received.payload.Order.OrderID)
+		assert.Equal(t, types.OrderStatusFilled, received.payload.Order.Status)
+	}
+	assert.Equal(t, SignOrderWebhookPayload("secret", received.timestamp, received.body), received.signature)
+}
+
+func TestOrderWebhook_BindSession(t *testing.T) {
+	session := &ExchangeSession{Name: "binance"}
+	session.OrderExecutor = &ExchangeOrderExecutor{Session: session}
+
+	webhook := NewOrderWebhook(OrderWebhookConfig{URL: "http://localhost"})
+	webhook.BindSession(session)
+
+	session.OrderExecutor.EmitSubmitOrder(types.SubmitOrder{Symbol: "BTCUSDT"})
+	session.OrderExecutor.EmitOrderUpdate(types.Order{Status: types.OrderStatusPartiallyFilled})
+	session.OrderExecutor.EmitSubmitOrderError(types.SubmitOrder{Symbol: "BTCUSDT"}, assert.AnError)
+
+	var events []OrderEvent
+	for len(webhook.queue) > 0 {
+		payload := <-webhook.queue
+		assert.Equal(t, "binance", payload.Session)
+		events = append(events, payload.Event)
+	}
+
+	assert.Equal(t, []OrderEvent{OrderEventSubmitted, OrderEventPartiallyFill, OrderEventRejected}, events)
+
+	// the session is filtered out
+	other := NewOrderWebhook(OrderWebhookConfig{URL: "http://localhost", Sessions: []string{"ftx"}})
+	other.BindSession(session)
+	session.OrderExecutor.EmitSubmitOrder(types.SubmitOrder{Symbol: "BTCUSDT"})
+	assert.Len(t, other.queue, 0)
+}
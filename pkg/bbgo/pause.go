@@ -0,0 +1,254 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var ErrOrderSubmissionPaused = errors.New("order submission is paused")
+
+// SessionPause is a paused session, or a paused strategy of the session if the Strategy is set
+type SessionPause struct {
+	Session string `json:"session"`
+
+	// Strategy is the strategy ID or the strategy ID with the symbol, e.g., grid or grid:BTCUSDT,
+	// all the strategies of the session are paused if it's empty
+	Strategy string `json:"strategy,omitempty"`
+
+	Time time.Time `json:"time"`
+}
+
+func (p SessionPause) key() string {
+	return pauseKey(p.Session, p.Strategy)
+}
+
+func pauseKey(session, strategy string) string {
+	return session + "/" + strategy
+}
+
+// PauseController records the paused sessions and strategies, the paused order executors reject the new orders
+// until they are resumed. The working orders and the order cancellation are not affected.
+type PauseController struct {
+	mu     sync.RWMutex
+	pauses map[string]SessionPause
+}
+
+func NewPauseController() *PauseController {
+	return &PauseController{
+		pauses: make(map[string]SessionPause),
+	}
+}
+
+// Pause pauses the strategy of the session, the whole session is paused if the strategy is empty
+func (c *PauseController) Pause(session, strategy string, now time.Time) {
+	p := SessionPause{Session: session, Strategy: strategy, Time: now}
+
+	c.mu.Lock()
+	c.pauses[p.key()] = p
+	c.mu.Unlock()
+}
+
+// Resume resumes the pause of the same session and strategy, it returns false if it's not paused
+func (c *PauseController) Resume(session, strategy string) bool {
+	key := pauseKey(session, strategy)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.pauses[key]; !ok {
+		return false
+	}
+
+	delete(c.pauses, key)
+	return true
+}
+
+// Paused returns true if the session, the strategy ID or the strategy ID with the symbol is paused
+func (c *PauseController) Paused(session, strategyID, symbol string) bool {
+	if c == nil {
+		return false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if len(c.pauses) == 0 {
+		return false
+	}
+
+	if _, ok := c.pauses[pauseKey(session, "")]; ok {
+		return true
+	}
+
+	if len(strategyID) == 0 {
+		return false
+	}
+
+	if _, ok := c.pauses[pauseKey(session, strategyID)]; ok {
+		return true
+	}
+
+	if len(symbol) > 0 {
+		if _, ok := c.pauses[pauseKey(session, strategyID+":"+symbol)]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Pauses returns the current pauses sorted by the session and the strategy
+func (c *PauseController) Pauses() []SessionPause {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var pauses = make([]SessionPause, 0, len(c.pauses))
+	for _, p := range c.pauses {
+		pauses = append(pauses, p)
+	}
+
+	sort.Slice(pauses, func(i, j int) bool {
+		return pauses[i].key() < pauses[j].key()
+	})
+
+	return pauses
+}
+
+// PauseOrderExecutor rejects the orders while the session or the strategy is paused
+type PauseOrderExecutor struct {
+	OrderExecutor
+
+	controller *PauseController
+	session    string
+	strategyID string
+	symbol     string
+}
+
+// NewPauseOrderExecutor wraps the order executor of the strategy, the strategy ID is empty for the session executor
+func NewPauseOrderExecutor(executor OrderExecutor, controller *PauseController, session, strategyID, symbol string) *PauseOrderExecutor {
+	return &PauseOrderExecutor{
+		OrderExecutor: executor,
+		controller:    controller,
+		session:       session,
+		strategyID:    strategyID,
+		symbol:        symbol,
+	}
+}
+
+func (e *PauseOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	if e.controller.Paused(e.session, e.strategyID, e.symbol) {
+		return nil, ErrOrderSubmissionPaused
+	}
+
+	return e.OrderExecutor.SubmitOrders(ctx, orders...)
+}
+
+// PauseSession stops the new orders of the strategy running on the session, all the strategies of the session are
+// paused if the strategy is empty. The open orders of the paused symbols are canceled if cancelOrders is true.
+func (trader *Trader) PauseSession(ctx context.Context, sessionName, strategy string, cancelOrders bool) ([]types.Order, error) {
+	if trader.pauses == nil {
+		return nil, errors.New("the trader is not created by NewTrader, pause is not supported")
+	}
+
+	session, symbols, err := trader.pauseTargets(sessionName, strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	trader.pauses.Pause(sessionName, strategy, time.Now())
+	trader.notifyPause(":double_vertical_bar: %s is paused", sessionName, strategy)
+
+	if !cancelOrders {
+		return nil, nil
+	}
+
+	var canceled []types.Order
+	for _, symbol := range symbols {
+		orders, err := session.CancelAllOrders(ctx, symbol)
+		if err != nil {
+			return canceled, fmt.Errorf("can not cancel the %s orders of session %s: %w", symbol, sessionName, err)
+		}
+
+		canceled = append(canceled, orders...)
+	}
+
+	log.Infof("%d orders of the paused %s are canceled", len(canceled), pauseName(sessionName, strategy))
+	return canceled, nil
+}
+
+// ResumeSession resumes the pause of the same session and strategy
+func (trader *Trader) ResumeSession(sessionName, strategy string) error {
+	if trader.pauses == nil || !trader.pauses.Resume(sessionName, strategy) {
+		return fmt.Errorf("%s is not paused", pauseName(sessionName, strategy))
+	}
+
+	trader.notifyPause(":arrow_forward: %s is resumed", sessionName, strategy)
+	return nil
+}
+
+// Pauses returns the paused sessions and strategies
+func (trader *Trader) Pauses() []SessionPause {
+	if trader.pauses == nil {
+		return []SessionPause{}
+	}
+
+	return trader.pauses.Pauses()
+}
+
+// pauseTargets returns the session and the symbols of the strategies matched by the strategy target
+func (trader *Trader) pauseTargets(sessionName, strategy string) (*ExchangeSession, []string, error) {
+	session, ok := trader.environment.Session(sessionName)
+	if !ok {
+		return nil, nil, fmt.Errorf("session %s not found", sessionName)
+	}
+
+	var matched = 0
+	var symbols []string
+	var seen = make(map[string]struct{})
+	for _, s := range trader.exchangeStrategies[sessionName] {
+		if len(strategy) > 0 && !matchCommandStrategy(s, strategy) {
+			continue
+		}
+
+		matched++
+
+		symbol := newRunningStrategy(s, sessionName).Symbol
+		if _, ok := seen[symbol]; ok || len(symbol) == 0 {
+			continue
+		}
+
+		seen[symbol] = struct{}{}
+		symbols = append(symbols, symbol)
+	}
+
+	if len(strategy) > 0 && matched == 0 {
+		return nil, nil, fmt.Errorf("strategy %s not found in session %s", strategy, sessionName)
+	}
+
+	return session, symbols, nil
+}
+
+func (trader *Trader) notifyPause(format, sessionName, strategy string) {
+	name := pauseName(sessionName, strategy)
+	log.Infof(format, name)
+
+	if trader.environment != nil {
+		trader.environment.Notify(format, name)
+	}
+}
+
+func pauseName(sessionName, strategy string) string {
+	if len(strategy) == 0 {
+		return "session " + sessionName
+	}
+
+	return fmt.Sprintf("strategy %s of session %s", strategy, sessionName)
+}
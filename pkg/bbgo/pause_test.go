@@ -0,0 +1,57 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestPauseController(t *testing.T) {
+	c := NewPauseController()
+	assert.False(t, c.Paused("binance", "grid", "BTCUSDT"))
+
+	c.Pause("binance", "grid:BTCUSDT", time.Now())
+	assert.True(t, c.Paused("binance", "grid", "BTCUSDT"))
+	assert.False(t, c.Paused("binance", "grid", "ETHUSDT"))
+	assert.False(t, c.Paused("binance", "", ""), "the session executor is not paused by the strategy pause")
+	assert.False(t, c.Paused("max", "grid", "BTCUSDT"))
+
+	c.Pause("binance", "grid", time.Now())
+	assert.True(t, c.Paused("binance", "grid", "ETHUSDT"))
+
+	c.Pause("max", "", time.Now())
+	assert.True(t, c.Paused("max", "", ""))
+	assert.True(t, c.Paused("max", "bollmaker", "BTCUSDT"))
+
+	pauses := c.Pauses()
+	if assert.Len(t, pauses, 3) {
+		assert.Equal(t, "grid", pauses[0].Strategy)
+		assert.Equal(t, "grid:BTCUSDT", pauses[1].Strategy)
+		assert.Equal(t, "max", pauses[2].Session)
+	}
+
+	assert.True(t, c.Resume("binance", "grid"))
+	assert.False(t, c.Resume("binance", "grid"))
+	assert.False(t, c.Paused("binance", "grid", "ETHUSDT"))
+	assert.True(t, c.Paused("binance", "grid", "BTCUSDT"))
+}
+
+func TestPauseOrderExecutor(t *testing.T) {
+	ctx := context.Background()
+	c := NewPauseController()
+	executor := NewPauseOrderExecutor(&recordOrderExecutor{ExchangeOrderExecutor: &ExchangeOrderExecutor{}}, c, "binance", "grid", "BTCUSDT")
+	order := types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeMarket, Quantity: 0.1}
+
+	c.Pause("binance", "", time.Now())
+	_, err := executor.SubmitOrders(ctx, order)
+	assert.Equal(t, ErrOrderSubmissionPaused, err)
+
+	c.Resume("binance", "")
+	createdOrders, err := executor.SubmitOrders(ctx, order)
+	assert.NoError(t, err)
+	assert.Len(t, createdOrders, 1)
+}
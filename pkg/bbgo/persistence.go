@@ -2,6 +2,10 @@ package bbgo
 
 import (
 	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 
 	"github.com/c9s/bbgo/pkg/service"
 )
@@ -22,6 +26,13 @@ type Persistence struct {
 }
 
 func (p *Persistence) backendService(t string) (service.PersistenceService, error) {
+	if p.Facade.ReadOnly {
+		switch t {
+		case "json", "redis", "sql", "memory":
+			return p.Facade.ReadOnlyService(t), nil
+		}
+	}
+
 	switch t {
 	case "json":
 		return p.Facade.Json, nil
@@ -29,6 +40,12 @@ func (p *Persistence) backendService(t string) (service.PersistenceService, erro
 	case "redis":
 		return p.Facade.Redis, nil
 
+	case "sql":
+		if p.Facade.SQL == nil {
+			return nil, fmt.Errorf("sql persistence is not configured")
+		}
+		return p.Facade.SQL, nil
+
 	case "memory":
 		return p.Facade.Memory, nil
 
@@ -64,3 +81,168 @@ func (p *Persistence) Save(val interface{}, subIDs ...string) error {
 	store := ps.NewStore(p.PersistenceSelector.StoreID, subIDs...)
 	return store.Save(val)
 }
+
+// persistenceFieldTag is the struct tag of the strategy fields that are loaded automatically before the strategy runs
+// and saved on shutdown, e.g.,
+//
+//	Position *types.Position `persistence:"position"`
+//
+// the fields are stored by the strategy ID (and the symbol of the symbol based strategy) and the tag value.
+const persistenceFieldTag = "persistence"
+
+// InstanceIDProvider is implemented by the strategies that run several instances with the same strategy ID,
+// the persistence fields of each instance are stored by the instance ID instead of the strategy ID.
+type InstanceIDProvider interface {
+	InstanceID() string
+}
+
+func persistenceSubIDs(rs reflect.Value, id string) []string {
+	if rs.CanAddr() {
+		if provider, ok := rs.Addr().Interface().(InstanceIDProvider); ok {
+			if instanceID := provider.InstanceID(); len(instanceID) > 0 {
+				return []string{instanceID}
+			}
+		}
+	}
+
+	if symbol, ok := isSymbolBasedStrategy(rs); ok && len(symbol) > 0 {
+		return []string{id, symbol}
+	}
+
+	return []string{id}
+}
+
+func iterateFieldsByTag(rs reflect.Value, tagName string, cb func(tag string, ft reflect.StructField, fv reflect.Value) error) error {
+	rt := rs.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		ft := rt.Field(i)
+		tag, ok := ft.Tag.Lookup(tagName)
+		if !ok || tag == "" || tag == "-" {
+			continue
+		}
+
+		fv := rs.Field(i)
+		if !fv.CanSet() {
+			return fmt.Errorf("persistence field %s of %s can not be set", ft.Name, rt)
+		}
+
+		if err := cb(tag, ft, fv); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadPersistenceFields loads the fields tagged with `persistence` of the strategy, the fields keep their default values
+// if nothing is stored.
+func loadPersistenceFields(strategy interface{}, id string, ps service.PersistenceService) error {
+	rs := reflect.ValueOf(strategy)
+	if rs.Kind() != reflect.Ptr || rs.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	rs = rs.Elem()
+	subIDs := persistenceSubIDs(rs, id)
+	return iterateFieldsByTag(rs, persistenceFieldTag, func(tag string, ft reflect.StructField, fv reflect.Value) error {
+		store := ps.NewStore(tag, subIDs...)
+
+		if ft.Type.Kind() == reflect.Ptr {
+			val := reflect.New(ft.Type.Elem())
+			if err := store.Load(val.Interface()); err != nil {
+				if err == service.ErrPersistenceNotExists {
+					return nil
+				}
+
+				return errors.Wrapf(err, "can not load persistence field %s of %s", ft.Name, rs.Type())
+			}
+
+			fv.Set(val)
+			return nil
+		}
+
+		val := reflect.New(ft.Type)
+		val.Elem().Set(fv)
+		if err := store.Load(val.Interface()); err != nil {
+			if err == service.ErrPersistenceNotExists {
+				return nil
+			}
+
+			return errors.Wrapf(err, "can not load persistence field %s of %s", ft.Name, rs.Type())
+		}
+
+		fv.Set(val.Elem())
+		return nil
+	})
+}
+
+// storePersistenceFields saves the fields tagged with `persistence` of the strategy, the nil pointer fields are skipped.
+func storePersistenceFields(strategy interface{}, id string, ps service.PersistenceService) error {
+	rs := reflect.ValueOf(strategy)
+	if rs.Kind() != reflect.Ptr || rs.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	rs = rs.Elem()
+	subIDs := persistenceSubIDs(rs, id)
+	return iterateFieldsByTag(rs, persistenceFieldTag, func(tag string, ft reflect.StructField, fv reflect.Value) error {
+		val := fv.Interface()
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				return nil
+			}
+		} else {
+			// the stores load the values into the pointers
+			val = fv.Addr().Interface()
+		}
+
+		store := ps.NewStore(tag, subIDs...)
+		if err := store.Save(val); err != nil {
+			return errors.Wrapf(err, "can not save persistence field %s of %s", ft.Name, rs.Type())
+		}
+
+		return nil
+	})
+}
+
+func (trader *Trader) persistenceService() service.PersistenceService {
+	if trader.environment == nil || trader.environment.PersistenceServiceFacade == nil {
+		return nil
+	}
+
+	return trader.environment.PersistenceServiceFacade.Get()
+}
+
+// LoadPersistenceFields loads the persistence fields of the strategy from the configured persistence service
+func (trader *Trader) LoadPersistenceFields(strategy interface{ ID() string }) error {
+	ps := trader.persistenceService()
+	if ps == nil {
+		return nil
+	}
+
+	return loadPersistenceFields(strategy, strategy.ID(), ps)
+}
+
+// SavePersistenceFields saves the persistence fields of all the strategies, it's called on shutdown
+// after the graceful shutdown callbacks.
+func (trader *Trader) SavePersistenceFields() error {
+	ps := trader.persistenceService()
+	if ps == nil {
+		return nil
+	}
+
+	var lastErr error
+	for _, strategy := range trader.strategies() {
+		s, ok := strategy.(interface{ ID() string })
+		if !ok {
+			continue
+		}
+
+		if err := storePersistenceFields(strategy, s.ID(), ps); err != nil {
+			log.WithError(err).Errorf("can not save the persistence fields of strategy %s", s.ID())
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
@@ -0,0 +1,112 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type persistenceTestStrategy struct {
+	Symbol string `json:"symbol"`
+
+	Position  *types.Position  `persistence:"position"`
+	Counter   int              `persistence:"counter"`
+	Threshold fixedpoint.Value `persistence:"threshold"`
+
+	// Ignored is not stored
+	Ignored int
+}
+
+func (s *persistenceTestStrategy) ID() string {
+	return "persistence-test"
+}
+
+func (s *persistenceTestStrategy) Run(ctx context.Context, orderExecutor OrderExecutor, session *ExchangeSession) error {
+	return nil
+}
+
+func TestPersistenceFields(t *testing.T) {
+	ps := service.NewMemoryService()
+
+	s := &persistenceTestStrategy{Symbol: "BTCUSDT", Counter: 1, Threshold: fixedpoint.NewFromFloat(0.5)}
+
+	// nothing is stored, the default values are kept
+	assert.NoError(t, loadPersistenceFields(s, s.ID(), ps))
+	assert.Nil(t, s.Position)
+	assert.Equal(t, 1, s.Counter)
+	assert.Equal(t, 0.5, s.Threshold.Float64())
+
+	s.Position = types.NewPositionFromMarket(types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"})
+	s.Position.Base = fixedpoint.NewFromFloat(1.5)
+	s.Counter = 10
+	s.Ignored = 3
+	assert.NoError(t, storePersistenceFields(s, s.ID(), ps))
+
+	restored := &persistenceTestStrategy{Symbol: "BTCUSDT"}
+	assert.NoError(t, loadPersistenceFields(restored, restored.ID(), ps))
+	if assert.NotNil(t, restored.Position) {
+		assert.Equal(t, 1.5, restored.Position.Base.Float64())
+	}
+	assert.Equal(t, 10, restored.Counter)
+	assert.Equal(t, 0.5, restored.Threshold.Float64())
+	assert.Equal(t, 0, restored.Ignored)
+
+	// the fields are stored by the symbol
+	other := &persistenceTestStrategy{Symbol: "ETHUSDT"}
+	assert.NoError(t, loadPersistenceFields(other, other.ID(), ps))
+	assert.Nil(t, other.Position)
+	assert.Equal(t, 0, other.Counter)
+}
+
+type persistenceInstanceTestStrategy struct {
+	Symbol   string `json:"symbol"`
+	Interval string `json:"interval"`
+
+	Counter int `persistence:"counter"`
+}
+
+func (s *persistenceInstanceTestStrategy) ID() string {
+	return "persistence-instance-test"
+}
+
+func (s *persistenceInstanceTestStrategy) InstanceID() string {
+	return s.ID() + ":" + s.Symbol + ":" + s.Interval
+}
+
+func TestPersistenceFields_InstanceID(t *testing.T) {
+	ps := service.NewMemoryService()
+
+	s := &persistenceInstanceTestStrategy{Symbol: "BTCUSDT", Interval: "1m", Counter: 3}
+	assert.NoError(t, storePersistenceFields(s, s.ID(), ps))
+
+	// the instances of the same strategy and symbol are stored separately
+	other := &persistenceInstanceTestStrategy{Symbol: "BTCUSDT", Interval: "5m"}
+	assert.NoError(t, loadPersistenceFields(other, other.ID(), ps))
+	assert.Equal(t, 0, other.Counter)
+
+	restored := &persistenceInstanceTestStrategy{Symbol: "BTCUSDT", Interval: "1m"}
+	assert.NoError(t, loadPersistenceFields(restored, restored.ID(), ps))
+	assert.Equal(t, 3, restored.Counter)
+}
+
+func TestTrader_SavePersistenceFields(t *testing.T) {
+	environ := NewEnvironment()
+	btc := &persistenceTestStrategy{Symbol: "BTCUSDT", Counter: 7}
+	trader := &Trader{
+		environment: environ,
+		exchangeStrategies: map[string][]SingleExchangeStrategy{
+			"binance": {btc},
+		},
+	}
+
+	trader.Shutdown(context.Background())
+
+	restored := &persistenceTestStrategy{Symbol: "BTCUSDT"}
+	assert.NoError(t, trader.LoadPersistenceFields(restored))
+	assert.Equal(t, 7, restored.Counter)
+}
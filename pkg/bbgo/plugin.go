@@ -0,0 +1,145 @@
+package bbgo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"plugin"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// StrategyPluginConfig is the strategy plugin built by `go build -buildmode=plugin`, the plugin registers its strategies
+// by calling RegisterStrategy in its init function, just like the built-in strategies.
+//
+// The plugin must be built with the same go version and the same bbgo version (and the same versions of the shared
+// dependencies) of the bbgo binary, otherwise the plugin can not be opened. Go plugins are only supported on linux and macOS.
+type StrategyPluginConfig struct {
+	// Path is the path of the plugin file, the relative path is resolved from the directory of the config file
+	Path string `json:"path" yaml:"path"`
+
+	// Checksum is the optional sha256 checksum (hex) of the plugin file, the plugin is not opened if the checksum does not match
+	Checksum string `json:"checksum,omitempty" yaml:"checksum,omitempty"`
+}
+
+func (c *StrategyPluginConfig) Validate() error {
+	if len(c.Path) == 0 {
+		return errors.New("strategy plugin path is required")
+	}
+
+	return nil
+}
+
+// openPlugin opens the go plugin, the init functions of the plugin packages are called when the plugin is opened
+var openPlugin = func(path string) error {
+	_, err := plugin.Open(path)
+	return err
+}
+
+var loadedPluginsMutex sync.Mutex
+var loadedPlugins = make(map[string][]string)
+
+// registeredStrategyIDs returns the sorted IDs of the registered strategies
+func registeredStrategyIDs() []string {
+	var ids []string
+	for id := range LoadedExchangeStrategies {
+		ids = append(ids, id)
+	}
+
+	for id := range LoadedCrossExchangeStrategies {
+		if _, ok := LoadedExchangeStrategies[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Strings(ids)
+	return ids
+}
+
+func verifyPluginChecksum(path, checksum string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(content)
+	if actual := hex.EncodeToString(sum[:]); !strings.EqualFold(actual, checksum) {
+		return fmt.Errorf("strategy plugin %s checksum mismatch, expected %s, got %s", path, checksum, actual)
+	}
+
+	return nil
+}
+
+// LoadStrategyPlugin opens the strategy plugin and returns the IDs of the strategies registered by the plugin,
+// the plugin that is already loaded is not opened again.
+func LoadStrategyPlugin(config StrategyPluginConfig, baseDir string) ([]string, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	path := config.Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	loadedPluginsMutex.Lock()
+	defer loadedPluginsMutex.Unlock()
+
+	if ids, ok := loadedPlugins[path]; ok {
+		return ids, nil
+	}
+
+	if len(config.Checksum) > 0 {
+		if err := verifyPluginChecksum(path, config.Checksum); err != nil {
+			return nil, err
+		}
+	}
+
+	registered := make(map[string]struct{})
+	for _, id := range registeredStrategyIDs() {
+		registered[id] = struct{}{}
+	}
+
+	if err := openPlugin(path); err != nil {
+		return nil, errors.Wrapf(err, "can not open strategy plugin %s", path)
+	}
+
+	var ids []string
+	for _, id := range registeredStrategyIDs() {
+		if _, ok := registered[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) == 0 {
+		log.Warnf("strategy plugin %s does not register any new strategy", path)
+	} else {
+		log.Infof("strategy plugin %s is loaded, registered strategies: %v", path, ids)
+	}
+
+	loadedPlugins[path] = ids
+	return ids, nil
+}
+
+// LoadStrategyPlugins loads the strategy plugins in order, the plugins must be loaded before the strategies are loaded
+// from the config
+func LoadStrategyPlugins(configs []StrategyPluginConfig, baseDir string) error {
+	for _, config := range configs {
+		if _, err := LoadStrategyPlugin(config, baseDir); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
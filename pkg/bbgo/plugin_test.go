@@ -0,0 +1,102 @@
+package bbgo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeOpenPlugin replaces the plugin opener, the fake plugin registers the strategy when it's opened
+func fakeOpenPlugin(id string) (opened *[]string, restore func()) {
+	opened = &[]string{}
+
+	originalOpenPlugin := openPlugin
+	openPlugin = func(path string) error {
+		*opened = append(*opened, path)
+		RegisterStrategy(id, &TestStrategy{})
+		return nil
+	}
+
+	restore = func() {
+		openPlugin = originalOpenPlugin
+		delete(LoadedExchangeStrategies, id)
+
+		loadedPluginsMutex.Lock()
+		loadedPlugins = make(map[string][]string)
+		loadedPluginsMutex.Unlock()
+	}
+
+	return opened, restore
+}
+
+func TestLoad_StrategyPlugins(t *testing.T) {
+	opened, restore := fakeOpenPlugin("plugintest")
+	defer restore()
+
+	config, err := Load("testdata/plugin.yaml", true)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	expectedPath, _ := filepath.Abs("testdata/plugins/plugintest.so")
+	assert.Equal(t, []string{expectedPath}, *opened)
+
+	if assert.Len(t, config.ExchangeStrategies, 1) {
+		assert.Equal(t, []string{"binance"}, config.ExchangeStrategies[0].Mounts)
+		assert.Equal(t, &TestStrategy{Symbol: "BTCUSDT", Interval: "1m"}, config.ExchangeStrategies[0].Strategy)
+	}
+
+	// the plugins are not loaded when the strategies are not loaded
+	_, err = Load("testdata/plugin.yaml", false)
+	assert.NoError(t, err)
+	assert.Len(t, *opened, 1)
+}
+
+func TestLoadStrategyPlugin(t *testing.T) {
+	opened, restore := fakeOpenPlugin("plugintest")
+	defer restore()
+
+	dir, err := ioutil.TempDir("", "bbgo-plugin")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	content := []byte("plugin")
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "plugintest.so"), content, 0644))
+
+	_, err = LoadStrategyPlugin(StrategyPluginConfig{Path: "plugintest.so", Checksum: "deadbeef"}, dir)
+	assert.Error(t, err)
+	assert.Len(t, *opened, 0)
+
+	sum := sha256.Sum256(content)
+	ids, err := LoadStrategyPlugin(StrategyPluginConfig{Path: "plugintest.so", Checksum: hex.EncodeToString(sum[:])}, dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"plugintest"}, ids)
+
+	// the loaded plugin is not opened again
+	ids, err = LoadStrategyPlugin(StrategyPluginConfig{Path: filepath.Join(dir, "plugintest.so")}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"plugintest"}, ids)
+	assert.Len(t, *opened, 1)
+
+	assert.Error(t, (&StrategyPluginConfig{}).Validate())
+}
+
+func TestLoadStrategyPlugin_OpenError(t *testing.T) {
+	originalOpenPlugin := openPlugin
+	defer func() { openPlugin = originalOpenPlugin }()
+
+	openPlugin = func(path string) error {
+		return errors.New("plugin was built with a different version of package")
+	}
+
+	_, err := LoadStrategyPlugin(StrategyPluginConfig{Path: "/tmp/not-loaded.so"}, "")
+	assert.Error(t, err)
+}
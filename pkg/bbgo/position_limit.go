@@ -0,0 +1,409 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var ErrPositionLimitExceeded = errors.New("order exceeds the position limit")
+
+// PositionLimitAction is the action taken on the order that violates the limit
+type PositionLimitAction string
+
+const (
+	// PositionLimitShrink shrinks the order quantity to fit the limit, the order is rejected if it can not be shrunk
+	PositionLimitShrink PositionLimitAction = "shrink"
+
+	// PositionLimitReject rejects the order that violates the limit
+	PositionLimitReject PositionLimitAction = "reject"
+)
+
+// PositionLimit is the limits of a symbol, the zero values mean no limit
+type PositionLimit struct {
+	// MaxPositionNotional is the max notional (|base position| * price) of the position after the order is filled
+	MaxPositionNotional fixedpoint.Value `json:"maxPositionNotional,omitempty" yaml:"maxPositionNotional,omitempty"`
+
+	// MaxOrderQuantity is the max base quantity of one order
+	MaxOrderQuantity fixedpoint.Value `json:"maxOrderQuantity,omitempty" yaml:"maxOrderQuantity,omitempty"`
+
+	// MaxOpenOrders is the max number of the open orders
+	MaxOpenOrders int `json:"maxOpenOrders,omitempty" yaml:"maxOpenOrders,omitempty"`
+
+	// Action is shrink (default) or reject
+	Action PositionLimitAction `json:"action,omitempty" yaml:"action,omitempty"`
+}
+
+func (l *PositionLimit) Validate() error {
+	switch l.Action {
+	case "", PositionLimitShrink, PositionLimitReject:
+	default:
+		return fmt.Errorf("unsupported position limit action %q, valid actions are shrink and reject", l.Action)
+	}
+
+	if l.MaxPositionNotional < 0 || l.MaxOrderQuantity < 0 || l.MaxOpenOrders < 0 {
+		return errors.New("position limits can not be negative")
+	}
+
+	return nil
+}
+
+func (l *PositionLimit) shrinkable() bool {
+	return l.Action != PositionLimitReject
+}
+
+// PositionLimitConfig declares the position limits by symbol, the symbol "*" matches all the symbols
+// that are not declared explicitly.
+//
+//	riskControls:
+//	  positionLimits:
+//	    global:
+//	      BTCUSDT:
+//	        maxPositionNotional: 10000.0
+//	        maxOpenOrders: 20
+//	    strategies:
+//	      bollmaker:
+//	        "*":
+//	          maxOrderQuantity: 0.1
+//	          action: reject
+type PositionLimitConfig struct {
+	// Global limits are checked with the session position and the open orders of all the strategies in the session
+	Global map[string]PositionLimit `json:"global,omitempty" yaml:"global,omitempty"`
+
+	// Strategies limits are checked with the position and the open orders made by the strategy, by strategy ID
+	Strategies map[string]map[string]PositionLimit `json:"strategies,omitempty" yaml:"strategies,omitempty"`
+}
+
+func (c *PositionLimitConfig) Validate() error {
+	for symbol, limit := range c.Global {
+		if err := limit.Validate(); err != nil {
+			return errors.Wrapf(err, "invalid global position limit of %s", symbol)
+		}
+	}
+
+	for strategyID, limits := range c.Strategies {
+		for symbol, limit := range limits {
+			if err := limit.Validate(); err != nil {
+				return errors.Wrapf(err, "invalid position limit of strategy %s %s", strategyID, symbol)
+			}
+		}
+	}
+
+	return nil
+}
+
+func lookupPositionLimit(limits map[string]PositionLimit, symbol string) (*PositionLimit, bool) {
+	for s, limit := range limits {
+		if strings.EqualFold(s, symbol) {
+			return &limit, true
+		}
+	}
+
+	if limit, ok := limits["*"]; ok {
+		return &limit, true
+	}
+
+	return nil, false
+}
+
+// PositionLimiter keeps the open orders of all the position limit order executors, so that the global open order
+// limit counts the orders of all the strategies in the session.
+type PositionLimiter struct {
+	Config PositionLimitConfig
+
+	notifiability *Notifiability
+
+	mu sync.Mutex
+
+	// openOrders are the open order IDs by the session and the symbol
+	openOrders map[string]map[uint64]struct{}
+}
+
+func NewPositionLimiter(config PositionLimitConfig, notifiability *Notifiability) *PositionLimiter {
+	return &PositionLimiter{
+		Config:        config,
+		notifiability: notifiability,
+		openOrders:    make(map[string]map[uint64]struct{}),
+	}
+}
+
+func (l *PositionLimiter) numOfOpenOrders(session, symbol string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.openOrders[session+":"+symbol])
+}
+
+func (l *PositionLimiter) addOpenOrder(session string, order types.Order) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := session + ":" + order.Symbol
+	if _, ok := l.openOrders[key]; !ok {
+		l.openOrders[key] = make(map[uint64]struct{})
+	}
+
+	l.openOrders[key][order.OrderID] = struct{}{}
+}
+
+func (l *PositionLimiter) removeOpenOrder(session string, order types.Order) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.openOrders[session+":"+order.Symbol], order.OrderID)
+}
+
+func (l *PositionLimiter) alert(format string, args ...interface{}) {
+	log.Warnf(format, args...)
+	if l.notifiability != nil {
+		l.notifiability.Notify(":no_entry: "+format, args...)
+	}
+}
+
+// PositionLimitOrderExecutor checks the orders of the strategy by the global and the strategy position limits,
+// the orders that violate the limits are shrunk or rejected, and an alert is sent for each of them.
+//
+// The strategy position is the net executed quantity of the orders submitted through the executor since it's created.
+type PositionLimitOrderExecutor struct {
+	OrderExecutor
+
+	limiter    *PositionLimiter
+	session    *ExchangeSession
+	strategyID string
+
+	mu sync.Mutex
+
+	// openOrders are the open orders of the strategy by the order ID
+	openOrders map[uint64]types.Order
+
+	// positions are the net executed base quantity of the strategy by the symbol
+	positions map[string]float64
+}
+
+func NewPositionLimitOrderExecutor(executor OrderExecutor, limiter *PositionLimiter, session *ExchangeSession, strategyID string) *PositionLimitOrderExecutor {
+	e := &PositionLimitOrderExecutor{
+		OrderExecutor: executor,
+		limiter:       limiter,
+		session:       session,
+		strategyID:    strategyID,
+		openOrders:    make(map[uint64]types.Order),
+		positions:     make(map[string]float64),
+	}
+
+	executor.OnOrderUpdate(e.handleOrderUpdate)
+	return e
+}
+
+// Position returns the net executed base quantity of the strategy
+func (e *PositionLimitOrderExecutor) Position(symbol string) float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.positions[symbol]
+}
+
+func (e *PositionLimitOrderExecutor) numOfOpenOrders(symbol string) (num int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, order := range e.openOrders {
+		if order.Symbol == symbol {
+			num++
+		}
+	}
+
+	return num
+}
+
+func (e *PositionLimitOrderExecutor) handleOrderUpdate(order types.Order) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.updateOrder(order)
+}
+
+func (e *PositionLimitOrderExecutor) updateOrder(order types.Order) {
+	previous, ok := e.openOrders[order.OrderID]
+	if !ok {
+		return
+	}
+
+	// the executed quantity is accumulated, so the position is updated by the delta
+	if delta := order.ExecutedQuantity - previous.ExecutedQuantity; delta > 0 {
+		if previous.Side == types.SideTypeSell {
+			delta = -delta
+		}
+		e.positions[previous.Symbol] += delta
+	}
+
+	switch order.Status {
+	case types.OrderStatusFilled, types.OrderStatusCanceled, types.OrderStatusRejected:
+		delete(e.openOrders, order.OrderID)
+		e.limiter.removeOpenOrder(e.session.Name, previous)
+
+	default:
+		previous.ExecutedQuantity = order.ExecutedQuantity
+		previous.Status = order.Status
+		e.openOrders[order.OrderID] = previous
+	}
+}
+
+// positionLimitState is the position and the open orders of the limit scope, including the pending orders of the batch
+type positionLimitState struct {
+	scope      string
+	limit      *PositionLimit
+	position   float64
+	openOrders int
+}
+
+func (e *PositionLimitOrderExecutor) limitStates(symbol string) (states []*positionLimitState) {
+	if limit, ok := lookupPositionLimit(e.limiter.Config.Global, symbol); ok {
+		var position float64
+		if pos, ok := e.session.Position(symbol); ok {
+			position = pos.Base.Float64()
+		}
+
+		states = append(states, &positionLimitState{
+			scope:      "global",
+			limit:      limit,
+			position:   position,
+			openOrders: e.limiter.numOfOpenOrders(e.session.Name, symbol),
+		})
+	}
+
+	if limit, ok := lookupPositionLimit(e.limiter.Config.Strategies[e.strategyID], symbol); ok {
+		states = append(states, &positionLimitState{
+			scope:      "strategy " + e.strategyID,
+			limit:      limit,
+			position:   e.Position(symbol),
+			openOrders: e.numOfOpenOrders(symbol),
+		})
+	}
+
+	return states
+}
+
+// checkOrder returns the order with the quantity shrunk by the limits, or an error if the order is rejected
+func (e *PositionLimitOrderExecutor) checkOrder(order types.SubmitOrder, state *positionLimitState) (types.SubmitOrder, error) {
+	limit := state.limit
+
+	if limit.MaxOpenOrders > 0 && state.openOrders >= limit.MaxOpenOrders {
+		return order, errors.Wrapf(ErrPositionLimitExceeded, "%s has %d open %s orders, max open orders is %d",
+			state.scope, state.openOrders, order.Symbol, limit.MaxOpenOrders)
+	}
+
+	if limit.MaxOrderQuantity > 0 && order.Quantity > limit.MaxOrderQuantity.Float64() {
+		if !limit.shrinkable() {
+			return order, errors.Wrapf(ErrPositionLimitExceeded, "%s %s %s order quantity %f > max order quantity %f",
+				state.scope, order.Symbol, order.Side, order.Quantity, limit.MaxOrderQuantity.Float64())
+		}
+
+		e.limiter.alert("%s %s %s order quantity is shrunk from %f to the max order quantity %f",
+			state.scope, order.Symbol, order.Side, order.Quantity, limit.MaxOrderQuantity.Float64())
+		order.Quantity = limit.MaxOrderQuantity.Float64()
+	}
+
+	if limit.MaxPositionNotional > 0 {
+		price := order.Price
+		if order.Type == types.OrderTypeMarket || order.Type == types.OrderTypeStopMarket || price == 0 {
+			price, _ = e.session.LastPrice(order.Symbol)
+		}
+
+		if price <= 0 {
+			return order, errors.Wrapf(ErrPositionLimitExceeded, "the position notional of %s %s order can not be determined without the price", order.Symbol, order.Side)
+		}
+
+		sign := 1.0
+		if order.Side == types.SideTypeSell {
+			sign = -1.0
+		}
+
+		maxBase := limit.MaxPositionNotional.Float64() / price
+		projected := state.position + sign*order.Quantity
+
+		// the orders that reduce the position are always allowed
+		if math.Abs(projected) > maxBase && math.Abs(projected) > math.Abs(state.position) {
+			quota := maxBase - sign*state.position
+			if !limit.shrinkable() || quota <= 0 || quota < order.Market.MinQuantity {
+				return order, errors.Wrapf(ErrPositionLimitExceeded, "%s %s position notional %f after the %s order > max position notional %f",
+					state.scope, order.Symbol, math.Abs(projected)*price, order.Side, limit.MaxPositionNotional.Float64())
+			}
+
+			e.limiter.alert("%s %s %s order quantity is shrunk from %f to %f by the max position notional %f",
+				state.scope, order.Symbol, order.Side, order.Quantity, quota, limit.MaxPositionNotional.Float64())
+			order.Quantity = quota
+		}
+	}
+
+	return order, nil
+}
+
+// SubmitOrders submits the orders that pass the limits, the error of the first rejected order is returned
+// with the created orders of the other orders.
+func (e *PositionLimitOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	var acceptedOrders []types.SubmitOrder
+	var rejectErr error
+
+	var states = make(map[string][]*positionLimitState)
+	for _, order := range orders {
+		symbolStates, ok := states[order.Symbol]
+		if !ok {
+			symbolStates = e.limitStates(order.Symbol)
+			states[order.Symbol] = symbolStates
+		}
+
+		var err error
+		for _, state := range symbolStates {
+			if order, err = e.checkOrder(order, state); err != nil {
+				break
+			}
+		}
+
+		if err != nil {
+			e.limiter.alert("session %s rejected the %s %s order of strategy %s: %v", e.session.Name, order.Symbol, order.Side, e.strategyID, err)
+			if rejectErr == nil {
+				rejectErr = err
+			}
+			continue
+		}
+
+		// the accepted orders of the batch are counted by the following orders
+		for _, state := range symbolStates {
+			state.openOrders++
+			if order.Side == types.SideTypeSell {
+				state.position -= order.Quantity
+			} else {
+				state.position += order.Quantity
+			}
+		}
+
+		acceptedOrders = append(acceptedOrders, order)
+	}
+
+	if len(acceptedOrders) == 0 {
+		return nil, rejectErr
+	}
+
+	createdOrders, err := e.OrderExecutor.SubmitOrders(ctx, acceptedOrders...)
+
+	e.mu.Lock()
+	for _, order := range createdOrders {
+		// the created order could be executed already, e.g., the market orders
+		created := order
+		created.ExecutedQuantity = 0
+		e.openOrders[order.OrderID] = created
+		e.limiter.addOpenOrder(e.session.Name, order)
+		e.updateOrder(order)
+	}
+	e.mu.Unlock()
+
+	if err != nil {
+		return createdOrders, err
+	}
+
+	return createdOrders, rejectErr
+}
@@ -0,0 +1,136 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func newPositionLimitTestSession() *ExchangeSession {
+	market := types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT", MinQuantity: 0.001}
+	return &ExchangeSession{
+		Name:       "binance",
+		markets:    map[string]types.Market{"BTCUSDT": market},
+		positions:  make(map[string]*types.Position),
+		lastPrices: map[string]float64{"BTCUSDT": 10000.0},
+	}
+}
+
+func TestPositionLimitConfig_Validate(t *testing.T) {
+	config := PositionLimitConfig{
+		Global: map[string]PositionLimit{"BTCUSDT": {MaxOpenOrders: 2}},
+		Strategies: map[string]map[string]PositionLimit{
+			"bollmaker": {"*": {MaxOrderQuantity: fixedpoint.NewFromFloat(0.1), Action: PositionLimitReject}},
+		},
+	}
+	assert.NoError(t, config.Validate())
+
+	config.Global["ETHUSDT"] = PositionLimit{Action: "close"}
+	assert.Error(t, config.Validate())
+}
+
+func TestPositionLimitOrderExecutor_MaxOrderQuantity(t *testing.T) {
+	base := &recordOrderExecutor{ExchangeOrderExecutor: &ExchangeOrderExecutor{}}
+	notifier := &profitReportTestNotifier{}
+	notifiability := &Notifiability{}
+	notifiability.AddNotifier(notifier)
+
+	limiter := NewPositionLimiter(PositionLimitConfig{
+		Strategies: map[string]map[string]PositionLimit{
+			"grid":      {"*": {MaxOrderQuantity: fixedpoint.NewFromFloat(0.1)}},
+			"bollmaker": {"BTCUSDT": {MaxOrderQuantity: fixedpoint.NewFromFloat(0.1), Action: PositionLimitReject}},
+		},
+	}, notifiability)
+
+	session := newPositionLimitTestSession()
+	grid := NewPositionLimitOrderExecutor(base, limiter, session, "grid")
+	createdOrders, err := grid.SubmitOrders(context.Background(),
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 9000.0, Quantity: 0.5})
+	assert.NoError(t, err)
+	if assert.Len(t, createdOrders, 1) {
+		assert.Equal(t, 0.1, createdOrders[0].Quantity, "the order is shrunk")
+	}
+	assert.Len(t, notifier.objects, 1, "the shrink alert is sent")
+
+	bollmaker := NewPositionLimitOrderExecutor(base, limiter, session, "bollmaker")
+	createdOrders, err = bollmaker.SubmitOrders(context.Background(),
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 9000.0, Quantity: 0.5},
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeLimit, Price: 11000.0, Quantity: 0.05})
+	assert.True(t, errors.Is(err, ErrPositionLimitExceeded))
+	if assert.Len(t, createdOrders, 1, "the other orders are still submitted") {
+		assert.Equal(t, types.SideTypeSell, createdOrders[0].Side)
+	}
+	assert.Len(t, notifier.objects, 2, "the reject alert is sent")
+}
+
+func TestPositionLimitOrderExecutor_MaxOpenOrders(t *testing.T) {
+	base := &recordOrderExecutor{ExchangeOrderExecutor: &ExchangeOrderExecutor{}}
+	limiter := NewPositionLimiter(PositionLimitConfig{
+		Global: map[string]PositionLimit{"BTCUSDT": {MaxOpenOrders: 2}},
+	}, nil)
+
+	session := newPositionLimitTestSession()
+	executor1 := NewPositionLimitOrderExecutor(base, limiter, session, "grid")
+	executor2 := NewPositionLimitOrderExecutor(base, limiter, session, "bollmaker")
+
+	order := types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 9000.0, Quantity: 0.01}
+	createdOrders, err := executor1.SubmitOrders(context.Background(), order)
+	assert.NoError(t, err)
+	assert.Len(t, createdOrders, 1)
+
+	// the global open order limit counts the orders of all the strategies
+	createdOrders, err = executor2.SubmitOrders(context.Background(), order, order)
+	assert.True(t, errors.Is(err, ErrPositionLimitExceeded))
+	assert.Len(t, createdOrders, 1)
+
+	// the closed orders are removed from the open orders
+	filled := createdOrders[0]
+	filled.Status = types.OrderStatusFilled
+	filled.ExecutedQuantity = filled.Quantity
+	base.EmitOrderUpdate(filled)
+
+	createdOrders, err = executor1.SubmitOrders(context.Background(), order)
+	assert.NoError(t, err)
+	assert.Len(t, createdOrders, 1)
+	assert.Equal(t, 0.01, executor2.Position("BTCUSDT"))
+	assert.Equal(t, 0.0, executor1.Position("BTCUSDT"))
+}
+
+func TestPositionLimitOrderExecutor_MaxPositionNotional(t *testing.T) {
+	base := &recordOrderExecutor{ExchangeOrderExecutor: &ExchangeOrderExecutor{}}
+	limiter := NewPositionLimiter(PositionLimitConfig{
+		Global: map[string]PositionLimit{"BTCUSDT": {MaxPositionNotional: fixedpoint.NewFromFloat(5000.0)}},
+	}, nil)
+
+	session := newPositionLimitTestSession()
+	position, _ := session.Position("BTCUSDT")
+	position.AddTrade(types.Trade{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Price: 10000.0, Quantity: 0.3, QuoteQuantity: 3000.0})
+
+	executor := NewPositionLimitOrderExecutor(base, limiter, session, "grid")
+
+	// the max position is 5000 / 10000 = 0.5 BTC, the quota of the buy order is 0.2 BTC
+	createdOrders, err := executor.SubmitOrders(context.Background(),
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeMarket, Quantity: 0.5, Market: position.Market})
+	assert.NoError(t, err)
+	if assert.Len(t, createdOrders, 1) {
+		assert.InDelta(t, 0.2, createdOrders[0].Quantity, 1e-9)
+	}
+
+	// the sell order reduces the position
+	createdOrders, err = executor.SubmitOrders(context.Background(),
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeMarket, Quantity: 0.5, Market: position.Market})
+	assert.NoError(t, err)
+	if assert.Len(t, createdOrders, 1) {
+		assert.Equal(t, 0.5, createdOrders[0].Quantity)
+	}
+
+	executor.limiter.Config.Global["BTCUSDT"] = PositionLimit{MaxPositionNotional: fixedpoint.NewFromFloat(2000.0), Action: PositionLimitReject}
+	_, err = executor.SubmitOrders(context.Background(),
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeMarket, Quantity: 0.1, Market: position.Market})
+	assert.True(t, errors.Is(err, ErrPositionLimitExceeded))
+}
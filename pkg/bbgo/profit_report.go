@@ -0,0 +1,201 @@
+package bbgo
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultProfitReportCheckInterval = time.Minute
+
+// ProfitReportConfig configures the periodic profit summary notifications of the strategies
+type ProfitReportConfig struct {
+	// Periods are the summary periods, day, week or month, the summary is notified when the period is over
+	Periods []types.ProfitPeriod `json:"periods" yaml:"periods"`
+}
+
+func (c *ProfitReportConfig) Validate() error {
+	for _, period := range c.Periods {
+		if err := period.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ProfitRecorder records the realized profits of the strategies, the profits are inserted into the profits table
+// if the database is configured, and are aggregated by the configured periods for the summary notifications.
+//
+// It's injected into the strategies that declare the ProfitRecorder field, the strategy calls Record when
+// the profit is made:
+//
+//	ProfitRecorder *bbgo.ProfitRecorder
+type ProfitRecorder struct {
+	notifiability *Notifiability
+	service       *service.ProfitService
+	periods       []types.ProfitPeriod
+
+	mu sync.Mutex
+
+	// summaries are the summaries of the current periods by the period, the strategy and the quote currency
+	summaries map[string]*types.ProfitSummary
+}
+
+func NewProfitRecorder(profitService *service.ProfitService, notifiability *Notifiability, periods ...types.ProfitPeriod) *ProfitRecorder {
+	return &ProfitRecorder{
+		notifiability: notifiability,
+		service:       profitService,
+		periods:       periods,
+		summaries:     make(map[string]*types.ProfitSummary),
+	}
+}
+
+// Record records the realized profit of the strategy, it's safe to call Record on the nil recorder
+func (r *ProfitRecorder) Record(strategyID string, profit Profit) {
+	if r == nil {
+		return
+	}
+
+	if len(profit.Strategy) == 0 {
+		profit.Strategy = strategyID
+	}
+
+	if profit.Time.IsZero() {
+		profit.Time = time.Now()
+	}
+
+	record := types.StrategyProfit{
+		Strategy:           profit.Strategy,
+		StrategyInstanceID: profit.StrategyInstanceID,
+		Symbol:             profit.Symbol,
+		BaseCurrency:       profit.BaseCurrency,
+		QuoteCurrency:      profit.QuoteCurrency,
+		Profit:             profit.Profit.Float64(),
+		NetProfit:          profit.NetProfit.Float64(),
+		TradeAmount:        profit.TradeAmount.Float64(),
+		Time:               types.Time(profit.Time),
+	}
+
+	if r.service != nil {
+		if err := r.service.Insert(&record); err != nil {
+			log.WithError(err).Errorf("can not insert the profit of strategy %s", record.Strategy)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := profit.Time.Local()
+	for _, period := range r.periods {
+		key := string(period) + ":" + record.Strategy + ":" + record.QuoteCurrency
+		summary, ok := r.summaries[key]
+		if ok && !summary.Contains(t) {
+			// the profit of the previous period is only recorded in the database
+			if t.Before(summary.Since) {
+				continue
+			}
+
+			// the period is over but the summary is not flushed yet
+			r.notify(summary)
+			ok = false
+		}
+
+		if !ok {
+			summary = types.NewProfitSummary(record.Strategy, record.QuoteCurrency, period, t)
+			r.summaries[key] = summary
+		}
+
+		summary.Add(record)
+	}
+}
+
+// Summaries returns the summaries of the current periods, sorted by the period and the strategy
+func (r *ProfitRecorder) Summaries() []types.ProfitSummary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var summaries []types.ProfitSummary
+	for _, summary := range r.summaries {
+		summaries = append(summaries, *summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Period != summaries[j].Period {
+			return summaries[i].Period < summaries[j].Period
+		}
+
+		if summaries[i].Strategy != summaries[j].Strategy {
+			return summaries[i].Strategy < summaries[j].Strategy
+		}
+
+		return summaries[i].QuoteCurrency < summaries[j].QuoteCurrency
+	})
+
+	return summaries
+}
+
+// Flush notifies and removes the summaries of the periods that are over at the given time
+func (r *ProfitRecorder) Flush(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, summary := range r.summaries {
+		if now.Before(summary.Period.End(summary.Since)) {
+			continue
+		}
+
+		r.notify(summary)
+		delete(r.summaries, key)
+	}
+}
+
+func (r *ProfitRecorder) notify(summary *types.ProfitSummary) {
+	log.Info(summary.PlainText())
+	if r.notifiability != nil {
+		r.notifiability.Notify(summary)
+	}
+}
+
+// Run flushes the summaries periodically until the context is done
+func (r *ProfitRecorder) Run(ctx context.Context) {
+	if len(r.periods) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(defaultProfitReportCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case now := <-ticker.C:
+			r.Flush(now)
+		}
+	}
+}
+
+// ProfitRecorder returns the profit recorder of the trader, the recorder without the summary periods is created
+// if the profit report is not configured, so the profits are still recorded in the database.
+func (trader *Trader) ProfitRecorder() *ProfitRecorder {
+	if trader.profitRecorder == nil {
+		var profitService *service.ProfitService
+		var notifiability *Notifiability
+		if trader.environment != nil {
+			profitService = trader.environment.ProfitService
+			notifiability = &trader.environment.Notifiability
+		}
+
+		trader.profitRecorder = NewProfitRecorder(profitService, notifiability)
+	}
+
+	return trader.profitRecorder
+}
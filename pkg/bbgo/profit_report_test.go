@@ -0,0 +1,93 @@
+package bbgo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type profitReportTestNotifier struct {
+	objects []interface{}
+}
+
+func (n *profitReportTestNotifier) NotifyTo(channel string, obj interface{}, args ...interface{}) {
+	n.objects = append(n.objects, obj)
+}
+
+func (n *profitReportTestNotifier) Notify(obj interface{}, args ...interface{}) {
+	n.objects = append(n.objects, obj)
+}
+
+func newTestProfit(profit float64, t time.Time) Profit {
+	return Profit{
+		Symbol:        "BTCUSDT",
+		BaseCurrency:  "BTC",
+		QuoteCurrency: "USDT",
+		Profit:        fixedpoint.NewFromFloat(profit),
+		NetProfit:     fixedpoint.NewFromFloat(profit),
+		TradeAmount:   fixedpoint.NewFromFloat(1000.0),
+		Time:          t,
+	}
+}
+
+func TestProfitRecorder_Record(t *testing.T) {
+	notifier := &profitReportTestNotifier{}
+	notifiability := &Notifiability{}
+	notifiability.AddNotifier(notifier)
+
+	recorder := NewProfitRecorder(nil, notifiability, types.ProfitPeriodDay, types.ProfitPeriodMonth)
+
+	day := time.Date(2021, 12, 15, 10, 0, 0, 0, time.Local)
+	recorder.Record("grid", newTestProfit(10.0, day))
+	recorder.Record("grid", newTestProfit(-4.0, day.Add(time.Hour)))
+	recorder.Record("bollpp", newTestProfit(3.0, day))
+
+	summaries := recorder.Summaries()
+	if assert.Len(t, summaries, 4) {
+		assert.Equal(t, types.ProfitPeriodDay, summaries[0].Period)
+		assert.Equal(t, "bollpp", summaries[0].Strategy)
+
+		grid := summaries[1]
+		assert.Equal(t, "grid", grid.Strategy)
+		assert.Equal(t, 6.0, grid.Profit)
+		assert.Equal(t, 10.0, grid.GrossProfit)
+		assert.Equal(t, -4.0, grid.GrossLoss)
+		assert.Equal(t, 2, grid.NumProfits)
+		assert.Equal(t, 0.5, grid.WinningRatio)
+		assert.Equal(t, time.Date(2021, 12, 15, 0, 0, 0, 0, time.Local), grid.Since)
+
+		assert.Equal(t, types.ProfitPeriodMonth, summaries[3].Period)
+		assert.Equal(t, time.Date(2021, 12, 1, 0, 0, 0, 0, time.Local), summaries[3].Since)
+	}
+
+	// the profit of the next day notifies the summary of the last day
+	recorder.Record("grid", newTestProfit(1.0, day.AddDate(0, 0, 1)))
+	if assert.Len(t, notifier.objects, 1) {
+		summary, ok := notifier.objects[0].(*types.ProfitSummary)
+		if assert.True(t, ok) {
+			assert.Equal(t, 6.0, summary.Profit)
+		}
+	}
+
+	// the late profit of the previous day is not added into the current summary
+	recorder.Record("grid", newTestProfit(100.0, day))
+	for _, summary := range recorder.Summaries() {
+		if summary.Strategy == "grid" && summary.Period == types.ProfitPeriodDay {
+			assert.Equal(t, 1.0, summary.Profit)
+		}
+	}
+
+	// the summaries of the periods are flushed when the periods are over
+	recorder.Flush(time.Date(2022, 1, 1, 0, 0, 1, 0, time.Local))
+	assert.Len(t, recorder.Summaries(), 0)
+	assert.Len(t, notifier.objects, 5)
+}
+
+func TestProfitRecorder_Nil(t *testing.T) {
+	var recorder *ProfitRecorder
+	recorder.Record("grid", newTestProfit(10.0, time.Now()))
+}
@@ -0,0 +1,250 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultQuoteConversionBuffer = 0.002
+
+// QuoteConversion converts the other quote currencies (e.g., USDC, BUSD) into the quote currency of the buy orders
+// before the orders are submitted, when the available quote balance is not enough for the buy orders.
+type QuoteConversion struct {
+	// Currencies is the currencies that can be converted, in the preferred order, e.g., [USDC, BUSD].
+	// The first currency that has a market with the target quote currency and has enough balance is used.
+	Currencies []string `json:"currencies" yaml:"currencies"`
+
+	// MaxAmount is the max amount of one conversion in the target quote currency
+	MaxAmount fixedpoint.Value `json:"maxAmount" yaml:"maxAmount"`
+
+	// MaxDailyAmount is the max amount of the conversions per day (UTC) in the target quote currency, zero means no limit
+	MaxDailyAmount fixedpoint.Value `json:"maxDailyAmount,omitempty" yaml:"maxDailyAmount,omitempty"`
+
+	// Buffer is the extra ratio converted to cover the fees and the price changes, defaults to 0.002 (0.2%)
+	Buffer fixedpoint.Value `json:"buffer,omitempty" yaml:"buffer,omitempty"`
+
+	mu             sync.Mutex
+	day            string
+	dailyConverted float64
+}
+
+// QuoteConversionResult is the conversion submitted for the buy orders
+type QuoteConversionResult struct {
+	From, To string
+
+	// Amount is the converted amount in the target currency
+	Amount float64
+
+	Order types.SubmitOrder
+}
+
+func (c *QuoteConversion) Validate() error {
+	if len(c.Currencies) == 0 {
+		return errors.New("quote conversion currencies are required")
+	}
+
+	if c.MaxAmount <= 0 {
+		return errors.New("quote conversion maxAmount should be greater than zero")
+	}
+
+	if c.MaxDailyAmount < 0 || c.Buffer < 0 {
+		return errors.New("quote conversion maxDailyAmount and buffer can not be negative")
+	}
+
+	return nil
+}
+
+func (c *QuoteConversion) buffer() float64 {
+	if c.Buffer == 0 {
+		return defaultQuoteConversionBuffer
+	}
+
+	return c.Buffer.Float64()
+}
+
+// reserve reserves the amount from the daily limit, the reservation is rejected if the daily limit is exceeded
+func (c *QuoteConversion) reserve(amount float64, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	day := now.UTC().Format("2006-01-02")
+	if day != c.day {
+		c.day = day
+		c.dailyConverted = 0
+	}
+
+	if c.MaxDailyAmount > 0 && c.dailyConverted+amount > c.MaxDailyAmount.Float64() {
+		return false
+	}
+
+	c.dailyConverted += amount
+	return true
+}
+
+func (c *QuoteConversion) release(amount float64) {
+	c.mu.Lock()
+	c.dailyConverted -= amount
+	c.mu.Unlock()
+}
+
+// requiredQuoteAmounts returns the quote amounts (by currency) that the buy orders require,
+// the last prices are used for the market orders.
+func requiredQuoteAmounts(session *ExchangeSession, orders []types.SubmitOrder) map[string]float64 {
+	amounts := make(map[string]float64)
+	for _, order := range orders {
+		if order.Side != types.SideTypeBuy {
+			continue
+		}
+
+		market, ok := session.Market(order.Symbol)
+		if !ok {
+			continue
+		}
+
+		price := order.Price
+		if order.Type == types.OrderTypeMarket || order.Type == types.OrderTypeStopMarket || price == 0 {
+			price, _ = session.LastPrice(order.Symbol)
+		}
+
+		amounts[market.QuoteCurrency] += price * order.Quantity
+	}
+
+	return amounts
+}
+
+// conversionOrder builds the market order that converts the source currency into the amount of the target currency,
+// the market of either direction (source/target or target/source) is used.
+func (c *QuoteConversion) conversionOrder(session *ExchangeSession, source, target string, amount float64) (types.SubmitOrder, float64, bool) {
+	// sell the source currency, e.g., sell USDC on USDCUSDT
+	if market, ok := session.Market(source + target); ok {
+		price, ok := session.LastPrice(market.Symbol)
+		if !ok || price <= 0 {
+			return types.SubmitOrder{}, 0, false
+		}
+
+		quantity := math.Max(roundUpVolume(market, amount/price), market.MinQuantity)
+		if quantity*price < market.MinNotional {
+			quantity = roundUpVolume(market, market.MinNotional/price)
+		}
+
+		return types.SubmitOrder{
+			Symbol:   market.Symbol,
+			Market:   market,
+			Side:     types.SideTypeSell,
+			Type:     types.OrderTypeMarket,
+			Quantity: quantity,
+		}, quantity, true
+	}
+
+	// buy the target currency, e.g., buy USDT on USDTDAI
+	if market, ok := session.Market(target + source); ok {
+		price, ok := session.LastPrice(market.Symbol)
+		if !ok || price <= 0 {
+			return types.SubmitOrder{}, 0, false
+		}
+
+		quantity := math.Max(roundUpVolume(market, amount), market.MinQuantity)
+		if quantity*price < market.MinNotional {
+			quantity = roundUpVolume(market, market.MinNotional/price)
+		}
+
+		return types.SubmitOrder{
+			Symbol:   market.Symbol,
+			Market:   market,
+			Side:     types.SideTypeBuy,
+			Type:     types.OrderTypeMarket,
+			Quantity: quantity,
+		}, quantity * price, true
+	}
+
+	return types.SubmitOrder{}, 0, false
+}
+
+func roundUpVolume(market types.Market, volume float64) float64 {
+	p := math.Pow10(market.VolumePrecision)
+	return math.Ceil(volume*p-1e-9) / p
+}
+
+// convertQuoteCurrencies converts the other quote currencies when the available quote balance is not enough
+// for the buy orders, the conversion orders are submitted to the exchange directly and checked by the symbol guard.
+func (session *ExchangeSession) convertQuoteCurrencies(ctx context.Context, orders []types.SubmitOrder) ([]QuoteConversionResult, error) {
+	c := session.QuoteConversion
+	if c == nil || session.Account == nil {
+		return nil, nil
+	}
+
+	var results []QuoteConversionResult
+	for target, required := range requiredQuoteAmounts(session, orders) {
+		var available float64
+		if b, ok := session.Account.Balance(target); ok {
+			available = b.Available.Float64()
+		}
+
+		if required <= available {
+			continue
+		}
+
+		amount := (required - available) * (1.0 + c.buffer())
+		if amount > c.MaxAmount.Float64() {
+			return results, fmt.Errorf("%s conversion amount %f exceeds the max conversion amount %f", target, amount, c.MaxAmount.Float64())
+		}
+
+		result, err := c.convert(ctx, session, target, amount)
+		if err != nil {
+			return results, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func (c *QuoteConversion) convert(ctx context.Context, session *ExchangeSession, target string, amount float64) (QuoteConversionResult, error) {
+	for _, source := range c.Currencies {
+		source = strings.ToUpper(source)
+		if source == target {
+			continue
+		}
+
+		order, cost, ok := c.conversionOrder(session, source, target, amount)
+		if !ok {
+			continue
+		}
+
+		order, err := session.FormatOrder(order)
+		if err != nil {
+			continue
+		}
+
+		if b, ok := session.Account.Balance(source); !ok || b.Available.Float64() < cost {
+			continue
+		}
+
+		if _, err := session.checkSymbolGuard([]types.SubmitOrder{order}); err != nil {
+			continue
+		}
+
+		if !c.reserve(amount, time.Now()) {
+			return QuoteConversionResult{}, fmt.Errorf("%s conversion amount %f exceeds the max daily conversion amount %f", target, amount, c.MaxDailyAmount.Float64())
+		}
+
+		if _, err := session.Exchange.SubmitOrders(ctx, order); err != nil {
+			c.release(amount)
+			return QuoteConversionResult{}, errors.Wrapf(err, "can not convert %s to %s", source, target)
+		}
+
+		return QuoteConversionResult{From: source, To: target, Amount: amount, Order: order}, nil
+	}
+
+	return QuoteConversionResult{}, fmt.Errorf("no currency of %v can be converted to %f %s", c.Currencies, amount, target)
+}
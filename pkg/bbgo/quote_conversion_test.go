@@ -0,0 +1,129 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func newQuoteConversionTestSession(exchange types.Exchange, balances types.BalanceMap) *ExchangeSession {
+	account := types.NewAccount()
+	account.UpdateBalances(balances)
+
+	session := &ExchangeSession{
+		Name:     "binance",
+		Exchange: exchange,
+		Account:  account,
+		markets: map[string]types.Market{
+			"BTCUSDT":  {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT", PricePrecision: 2, VolumePrecision: 4, TickSize: 0.01, StepSize: 0.0001},
+			"USDCUSDT": {Symbol: "USDCUSDT", BaseCurrency: "USDC", QuoteCurrency: "USDT", PricePrecision: 4, VolumePrecision: 0, TickSize: 0.0001, StepSize: 1, MinNotional: 10.0},
+			"USDTDAI":  {Symbol: "USDTDAI", BaseCurrency: "USDT", QuoteCurrency: "DAI", PricePrecision: 4, VolumePrecision: 2, TickSize: 0.0001, StepSize: 0.01},
+		},
+		lastPrices: map[string]float64{
+			"BTCUSDT":  50000.0,
+			"USDCUSDT": 1.0,
+			"USDTDAI":  1.001,
+		},
+		QuoteConversion: &QuoteConversion{
+			Currencies: []string{"USDC", "DAI"},
+			MaxAmount:  fixedpoint.NewFromFloat(1000.0),
+		},
+	}
+	session.OrderExecutor = &ExchangeOrderExecutor{Session: session}
+	return session
+}
+
+func newQuoteBalances(usdt, usdc, dai float64) types.BalanceMap {
+	return types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(usdt)},
+		"USDC": {Currency: "USDC", Available: fixedpoint.NewFromFloat(usdc)},
+		"DAI":  {Currency: "DAI", Available: fixedpoint.NewFromFloat(dai)},
+	}
+}
+
+func TestQuoteConversion_Validate(t *testing.T) {
+	c := &QuoteConversion{Currencies: []string{"USDC"}, MaxAmount: fixedpoint.NewFromFloat(100.0)}
+	assert.NoError(t, c.Validate())
+
+	c.MaxDailyAmount = fixedpoint.NewFromFloat(-1.0)
+	assert.Error(t, c.Validate())
+
+	assert.Error(t, (&QuoteConversion{Currencies: []string{"USDC"}}).Validate())
+	assert.Error(t, (&QuoteConversion{MaxAmount: fixedpoint.NewFromFloat(100.0)}).Validate())
+}
+
+func TestQuoteConversion_reserve(t *testing.T) {
+	c := &QuoteConversion{MaxDailyAmount: fixedpoint.NewFromFloat(100.0)}
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.True(t, c.reserve(60.0, now))
+	assert.False(t, c.reserve(60.0, now.Add(time.Hour)))
+
+	c.release(60.0)
+	assert.True(t, c.reserve(60.0, now.Add(time.Hour)))
+
+	// the limit is reset on the next day
+	assert.True(t, c.reserve(60.0, now.Add(24*time.Hour)))
+}
+
+func TestExchangeOrderExecutor_QuoteConversion(t *testing.T) {
+	ctx := context.Background()
+	order := types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 50000.0, Quantity: 0.01}
+
+	// the balance is enough, no conversion
+	exchange := &guardTestExchange{}
+	session := newQuoteConversionTestSession(exchange, newQuoteBalances(1000.0, 1000.0, 1000.0))
+	_, err := session.OrderExecutor.SubmitOrders(ctx, order)
+	assert.NoError(t, err)
+	if assert.Len(t, exchange.orders, 1) {
+		assert.Equal(t, "BTCUSDT", exchange.orders[0].Symbol)
+	}
+
+	// 300 USDT is missing, USDC is converted with the buffer
+	exchange = &guardTestExchange{}
+	session = newQuoteConversionTestSession(exchange, newQuoteBalances(200.0, 1000.0, 1000.0))
+	_, err = session.OrderExecutor.SubmitOrders(ctx, order)
+	assert.NoError(t, err)
+	if assert.Len(t, exchange.orders, 2) {
+		assert.Equal(t, "USDCUSDT", exchange.orders[0].Symbol)
+		assert.Equal(t, types.SideTypeSell, exchange.orders[0].Side)
+		assert.Equal(t, types.OrderTypeMarket, exchange.orders[0].Type)
+		assert.Equal(t, 301.0, exchange.orders[0].Quantity)
+		assert.Equal(t, "BTCUSDT", exchange.orders[1].Symbol)
+	}
+
+	// USDC is not enough, USDT is bought with DAI
+	exchange = &guardTestExchange{}
+	session = newQuoteConversionTestSession(exchange, newQuoteBalances(200.0, 10.0, 1000.0))
+	_, err = session.OrderExecutor.SubmitOrders(ctx, order)
+	assert.NoError(t, err)
+	if assert.Len(t, exchange.orders, 2) {
+		assert.Equal(t, "USDTDAI", exchange.orders[0].Symbol)
+		assert.Equal(t, types.SideTypeBuy, exchange.orders[0].Side)
+		assert.Equal(t, 300.6, exchange.orders[0].Quantity)
+	}
+
+	// the conversion exceeds the max amount, the order is still submitted
+	exchange = &guardTestExchange{}
+	session = newQuoteConversionTestSession(exchange, newQuoteBalances(200.0, 1000.0, 1000.0))
+	session.QuoteConversion.MaxAmount = fixedpoint.NewFromFloat(100.0)
+	_, err = session.OrderExecutor.SubmitOrders(ctx, order)
+	assert.NoError(t, err)
+	if assert.Len(t, exchange.orders, 1) {
+		assert.Equal(t, "BTCUSDT", exchange.orders[0].Symbol)
+	}
+
+	// the sell orders do not require the quote currency
+	exchange = &guardTestExchange{}
+	session = newQuoteConversionTestSession(exchange, newQuoteBalances(0, 1000.0, 1000.0))
+	results, err := session.convertQuoteCurrencies(ctx, []types.SubmitOrder{
+		{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeMarket, Quantity: 1.0},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, results, 0)
+}
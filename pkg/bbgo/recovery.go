@@ -0,0 +1,576 @@
+package bbgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultRecoverySnapshotPath = "var/data/recovery.json"
+
+const defaultRecoverySnapshotInterval = time.Minute
+
+// RecoveryConfig enables the recovery snapshot, the snapshot is written when the process starts, periodically,
+// on the termination signals and on panic, and it's removed after the graceful shutdown. So the snapshot left on
+// the disk means the last run was terminated unexpectedly, then it's reconciled with the exchange on the next start.
+type RecoveryConfig struct {
+	// Path is the file path of the snapshot, defaults to var/data/recovery.json
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+
+	// Interval is the interval of the periodic snapshot, defaults to 1m.
+	// The process killed by SIGKILL (e.g., the OOM killer) can not write the snapshot, the periodic snapshot is used in that case.
+	Interval types.Duration `json:"interval,omitempty" yaml:"interval,omitempty"`
+
+	// HaltOnMismatch stops the startup when the reconciliation finds any mismatch,
+	// the snapshot is kept so that the operator can check it and remove it before the next start.
+	HaltOnMismatch bool `json:"haltOnMismatch,omitempty" yaml:"haltOnMismatch,omitempty"`
+}
+
+// EventOffset is the last processed events of a symbol
+type EventOffset struct {
+	LastTradeID         int64     `json:"lastTradeID,omitempty"`
+	LastTradeTime       time.Time `json:"lastTradeTime,omitempty"`
+	LastOrderUpdateTime time.Time `json:"lastOrderUpdateTime,omitempty"`
+	LastKLineTime       time.Time `json:"lastKLineTime,omitempty"`
+}
+
+type SessionRecoverySnapshot struct {
+	// OpenOrders is the working orders of the session order stores and the strategies
+	OpenOrders []types.Order `json:"openOrders"`
+
+	Positions map[string]*types.Position `json:"positions,omitempty"`
+
+	Balances types.BalanceMap `json:"balances,omitempty"`
+
+	// Offsets is the last processed events by symbol
+	Offsets map[string]*EventOffset `json:"offsets,omitempty"`
+}
+
+type RecoverySnapshot struct {
+	Time time.Time `json:"time"`
+
+	// Reason is why the snapshot is written, e.g., "start", "periodic", "signal: terminated" or "panic: ..."
+	Reason string `json:"reason"`
+
+	Sessions map[string]*SessionRecoverySnapshot `json:"sessions"`
+
+	Strategies []StrategyState `json:"strategies,omitempty"`
+}
+
+// LoadRecoverySnapshot loads the snapshot, nil is returned if the snapshot does not exist
+func LoadRecoverySnapshot(path string) (*RecoverySnapshot, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var snapshot RecoverySnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, errors.Wrapf(err, "can not parse the recovery snapshot %s", path)
+	}
+
+	return &snapshot, nil
+}
+
+// RecoveryRecorder tracks the event offsets of the sessions and writes the recovery snapshot
+type RecoveryRecorder struct {
+	Path     string
+	Interval time.Duration
+
+	environ *Environment
+	trader  *Trader
+
+	mu      sync.Mutex
+	offsets map[string]map[string]*EventOffset
+}
+
+func NewRecoveryRecorder(environ *Environment, config RecoveryConfig) *RecoveryRecorder {
+	path := config.Path
+	if len(path) == 0 {
+		path = defaultRecoverySnapshotPath
+	}
+
+	interval := config.Interval.Duration()
+	if interval == 0 {
+		interval = defaultRecoverySnapshotInterval
+	}
+
+	return &RecoveryRecorder{
+		Path:     path,
+		Interval: interval,
+		environ:  environ,
+		offsets:  make(map[string]map[string]*EventOffset),
+	}
+}
+
+// SetTrader sets the trader to include the strategy states in the snapshot
+func (r *RecoveryRecorder) SetTrader(trader *Trader) {
+	r.trader = trader
+}
+
+// offset returns the event offset of the symbol, the lock must be held
+func (r *RecoveryRecorder) offset(sessionName, symbol string) *EventOffset {
+	offsets, ok := r.offsets[sessionName]
+	if !ok {
+		offsets = make(map[string]*EventOffset)
+		r.offsets[sessionName] = offsets
+	}
+
+	offset, ok := offsets[symbol]
+	if !ok {
+		offset = &EventOffset{}
+		offsets[symbol] = offset
+	}
+
+	return offset
+}
+
+// BindSessions tracks the last processed events of the sessions, it should be called before the streams are connected
+func (r *RecoveryRecorder) BindSessions() {
+	for sessionName, session := range r.environ.Sessions() {
+		sessionName := sessionName
+
+		session.UserDataStream.OnTradeUpdate(func(trade types.Trade) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+
+			offset := r.offset(sessionName, trade.Symbol)
+			if !trade.Time.Time().Before(offset.LastTradeTime) {
+				offset.LastTradeID = trade.ID
+				offset.LastTradeTime = trade.Time.Time()
+			}
+		})
+
+		session.UserDataStream.OnOrderUpdate(func(order types.Order) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+
+			offset := r.offset(sessionName, order.Symbol)
+			if t := order.UpdateTime.Time(); t.After(offset.LastOrderUpdateTime) {
+				offset.LastOrderUpdateTime = t
+			}
+		})
+
+		session.MarketDataStream.OnKLineClosed(func(k types.KLine) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+
+			offset := r.offset(sessionName, k.Symbol)
+			if k.EndTime.After(offset.LastKLineTime) {
+				offset.LastKLineTime = k.EndTime
+			}
+		})
+	}
+}
+
+// Snapshot takes the snapshot of the open orders, the positions, the balances and the event offsets
+func (r *RecoveryRecorder) Snapshot(reason string) *RecoverySnapshot {
+	snapshot := &RecoverySnapshot{
+		Time:     time.Now(),
+		Reason:   reason,
+		Sessions: make(map[string]*SessionRecoverySnapshot),
+	}
+
+	if r.trader != nil {
+		snapshot.Strategies = r.trader.StrategyStates()
+	}
+
+	for sessionName, session := range r.environ.Sessions() {
+		sessionSnapshot := &SessionRecoverySnapshot{
+			Positions: make(map[string]*types.Position),
+			Offsets:   make(map[string]*EventOffset),
+		}
+
+		if session.Account != nil {
+			sessionSnapshot.Balances = session.Account.Balances()
+		}
+
+		for symbol, position := range session.Positions() {
+			sessionSnapshot.Positions[symbol] = position.Snapshot()
+		}
+
+		var orderIDs = make(map[uint64]struct{})
+		var addOrder = func(o types.Order) {
+			if _, ok := orderIDs[o.OrderID]; ok || !isWorkingOrder(o) {
+				return
+			}
+
+			orderIDs[o.OrderID] = struct{}{}
+			sessionSnapshot.OpenOrders = append(sessionSnapshot.OpenOrders, o)
+		}
+
+		for _, store := range session.OrderStores() {
+			for _, o := range store.Orders() {
+				addOrder(o)
+			}
+		}
+
+		for _, state := range snapshot.Strategies {
+			if state.Session != sessionName {
+				continue
+			}
+
+			for _, o := range state.Orders {
+				addOrder(o)
+			}
+		}
+
+		sort.Slice(sessionSnapshot.OpenOrders, func(i, j int) bool {
+			return sessionSnapshot.OpenOrders[i].OrderID < sessionSnapshot.OpenOrders[j].OrderID
+		})
+
+		r.mu.Lock()
+		for symbol, offset := range r.offsets[sessionName] {
+			o := *offset
+			sessionSnapshot.Offsets[symbol] = &o
+		}
+		r.mu.Unlock()
+
+		snapshot.Sessions[sessionName] = sessionSnapshot
+	}
+
+	return snapshot
+}
+
+func isWorkingOrder(o types.Order) bool {
+	switch o.Status {
+	case types.OrderStatusNew, types.OrderStatusPartiallyFilled:
+		return true
+	}
+
+	return false
+}
+
+// Write writes the snapshot to a temporary file and then renames it, so that the crash during the writing
+// does not leave a broken snapshot.
+func (r *RecoveryRecorder) Write(reason string) error {
+	snapshot := r.Snapshot(reason)
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.Path), 0755); err != nil {
+		return err
+	}
+
+	tmpPath := r.Path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, r.Path)
+}
+
+// Run writes the snapshot at start and then periodically until the context is canceled
+func (r *RecoveryRecorder) Run(ctx context.Context) {
+	if err := r.Write("start"); err != nil {
+		log.WithError(err).Errorf("can not write the recovery snapshot %s", r.Path)
+	}
+
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			if err := r.Write("periodic"); err != nil {
+				log.WithError(err).Errorf("can not write the recovery snapshot %s", r.Path)
+			}
+		}
+	}
+}
+
+// Clean removes the snapshot after the graceful shutdown
+func (r *RecoveryRecorder) Clean() error {
+	if err := os.Remove(r.Path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// RecoverPanic writes the snapshot when the calling goroutine panics and then panics again,
+// it should be deferred directly: defer recorder.RecoverPanic()
+func (r *RecoveryRecorder) RecoverPanic() {
+	if p := recover(); p != nil {
+		if err := r.Write(fmt.Sprintf("panic: %v\n%s", p, debug.Stack())); err != nil {
+			log.WithError(err).Errorf("can not write the recovery snapshot %s", r.Path)
+		}
+
+		panic(p)
+	}
+}
+
+// Reconcile compares the snapshot left by the last run with the exchanges, the snapshot is renamed with the
+// ".reconciled" suffix after the reconciliation. Nil report is returned if the last run was shut down gracefully.
+func (r *RecoveryRecorder) Reconcile(ctx context.Context) (*ReconciliationReport, error) {
+	snapshot, err := LoadRecoverySnapshot(r.Path)
+	if err != nil || snapshot == nil {
+		return nil, err
+	}
+
+	report := r.environ.ReconcileRecoverySnapshot(ctx, snapshot)
+	if err := os.Rename(r.Path, r.Path+".reconciled"); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+type BalanceChange struct {
+	Currency string           `json:"currency"`
+	Before   fixedpoint.Value `json:"before"`
+	After    fixedpoint.Value `json:"after"`
+}
+
+type SessionReconciliation struct {
+	Session string `json:"session"`
+
+	// ClosedOrders are open in the snapshot but not open on the exchange, they were filled or canceled after the snapshot
+	ClosedOrders []types.Order `json:"closedOrders,omitempty"`
+
+	// ChangedOrders are still open but executed after the snapshot, the orders are the current state on the exchange
+	ChangedOrders []types.Order `json:"changedOrders,omitempty"`
+
+	// UnknownOrders are open on the exchange but not in the snapshot
+	UnknownOrders []types.Order `json:"unknownOrders,omitempty"`
+
+	// MissedTrades are the trades after the last processed trade
+	MissedTrades []types.Trade `json:"missedTrades,omitempty"`
+
+	BalanceChanges []BalanceChange `json:"balanceChanges,omitempty"`
+
+	// Errors are the query errors, the related items are not reconciled
+	Errors []string `json:"errors,omitempty"`
+}
+
+func (r *SessionReconciliation) IsClean() bool {
+	return len(r.ClosedOrders) == 0 && len(r.ChangedOrders) == 0 && len(r.UnknownOrders) == 0 &&
+		len(r.MissedTrades) == 0 && len(r.BalanceChanges) == 0 && len(r.Errors) == 0
+}
+
+// ReconciliationReport is the difference between the recovery snapshot and the exchanges
+type ReconciliationReport struct {
+	SnapshotTime time.Time               `json:"snapshotTime"`
+	Reason       string                  `json:"reason"`
+	Sessions     []SessionReconciliation `json:"sessions"`
+}
+
+func (r *ReconciliationReport) IsClean() bool {
+	for _, s := range r.Sessions {
+		if !s.IsClean() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *ReconciliationReport) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("recovery snapshot at %s (%s)\n", r.SnapshotTime.Format(time.RFC3339), strings.SplitN(r.Reason, "\n", 2)[0]))
+
+	for _, s := range r.Sessions {
+		if s.IsClean() {
+			sb.WriteString(fmt.Sprintf("session %s: clean\n", s.Session))
+			continue
+		}
+
+		sb.WriteString(fmt.Sprintf("session %s:\n", s.Session))
+		for _, o := range s.ClosedOrders {
+			sb.WriteString(fmt.Sprintf("  closed order: %s\n", o.String()))
+		}
+
+		for _, o := range s.ChangedOrders {
+			sb.WriteString(fmt.Sprintf("  changed order: %s executed %f\n", o.String(), o.ExecutedQuantity))
+		}
+
+		for _, o := range s.UnknownOrders {
+			sb.WriteString(fmt.Sprintf("  unknown order: %s\n", o.String()))
+		}
+
+		for _, trade := range s.MissedTrades {
+			sb.WriteString(fmt.Sprintf("  missed trade: %s\n", trade.String()))
+		}
+
+		for _, c := range s.BalanceChanges {
+			sb.WriteString(fmt.Sprintf("  balance %s: %f -> %f\n", c.Currency, c.Before.Float64(), c.After.Float64()))
+		}
+
+		for _, e := range s.Errors {
+			sb.WriteString(fmt.Sprintf("  error: %s\n", e))
+		}
+	}
+
+	return sb.String()
+}
+
+// ReconcileRecoverySnapshot compares the open orders, the trades after the last processed trade and the balances
+// of the snapshot with the exchanges. The query errors are recorded in the report instead of failing the reconciliation.
+func (environ *Environment) ReconcileRecoverySnapshot(ctx context.Context, snapshot *RecoverySnapshot) *ReconciliationReport {
+	report := &ReconciliationReport{
+		SnapshotTime: snapshot.Time,
+		Reason:       snapshot.Reason,
+	}
+
+	var sessionNames []string
+	for sessionName := range snapshot.Sessions {
+		sessionNames = append(sessionNames, sessionName)
+	}
+	sort.Strings(sessionNames)
+
+	for _, sessionName := range sessionNames {
+		sessionSnapshot := snapshot.Sessions[sessionName]
+		reconciliation := SessionReconciliation{Session: sessionName}
+
+		session, ok := environ.Session(sessionName)
+		if !ok {
+			reconciliation.Errors = append(reconciliation.Errors, "session is not configured anymore")
+			report.Sessions = append(report.Sessions, reconciliation)
+			continue
+		}
+
+		reconcileSession(ctx, session, sessionSnapshot, &reconciliation)
+		report.Sessions = append(report.Sessions, reconciliation)
+	}
+
+	return report
+}
+
+func reconcileSession(ctx context.Context, session *ExchangeSession, snapshot *SessionRecoverySnapshot, reconciliation *SessionReconciliation) {
+	addError := func(err error) {
+		reconciliation.Errors = append(reconciliation.Errors, err.Error())
+	}
+
+	var symbols = make(map[string]struct{})
+	var snapshotOrders = make(map[uint64]types.Order)
+	for _, o := range snapshot.OpenOrders {
+		symbols[o.Symbol] = struct{}{}
+		snapshotOrders[o.OrderID] = o
+	}
+
+	for symbol := range snapshot.Offsets {
+		symbols[symbol] = struct{}{}
+	}
+
+	for symbol := range snapshot.Positions {
+		symbols[symbol] = struct{}{}
+	}
+
+	var sortedSymbols []string
+	for symbol := range symbols {
+		sortedSymbols = append(sortedSymbols, symbol)
+	}
+	sort.Strings(sortedSymbols)
+
+	for _, symbol := range sortedSymbols {
+		openOrders, err := session.Exchange.QueryOpenOrders(ctx, symbol)
+		if err != nil {
+			addError(errors.Wrapf(err, "can not query %s open orders", symbol))
+			continue
+		}
+
+		var openOrderIDs = make(map[uint64]struct{})
+		for _, o := range openOrders {
+			openOrderIDs[o.OrderID] = struct{}{}
+
+			before, ok := snapshotOrders[o.OrderID]
+			if !ok {
+				reconciliation.UnknownOrders = append(reconciliation.UnknownOrders, o)
+			} else if before.ExecutedQuantity != o.ExecutedQuantity {
+				reconciliation.ChangedOrders = append(reconciliation.ChangedOrders, o)
+			}
+		}
+
+		for _, o := range snapshot.OpenOrders {
+			if _, ok := openOrderIDs[o.OrderID]; !ok && o.Symbol == symbol {
+				reconciliation.ClosedOrders = append(reconciliation.ClosedOrders, o)
+			}
+		}
+
+		offset, ok := snapshot.Offsets[symbol]
+		if !ok || offset.LastTradeTime.IsZero() {
+			continue
+		}
+
+		historyService, ok := session.Exchange.(types.ExchangeTradeHistoryService)
+		if !ok {
+			continue
+		}
+
+		startTime := offset.LastTradeTime
+		trades, err := historyService.QueryTrades(ctx, symbol, &types.TradeQueryOptions{
+			StartTime: &startTime,
+		})
+		if err != nil {
+			addError(errors.Wrapf(err, "can not query %s trades", symbol))
+			continue
+		}
+
+		for _, trade := range trades {
+			t := trade.Time.Time()
+			if t.After(offset.LastTradeTime) || (t.Equal(offset.LastTradeTime) && trade.ID > offset.LastTradeID) {
+				reconciliation.MissedTrades = append(reconciliation.MissedTrades, trade)
+			}
+		}
+	}
+
+	if len(snapshot.Balances) == 0 {
+		return
+	}
+
+	balances, err := session.Exchange.QueryAccountBalances(ctx)
+	if err != nil {
+		addError(errors.Wrap(err, "can not query balances"))
+		return
+	}
+
+	var currencies = make(map[string]struct{})
+	for currency := range snapshot.Balances {
+		currencies[currency] = struct{}{}
+	}
+	for currency := range balances {
+		currencies[currency] = struct{}{}
+	}
+
+	var sortedCurrencies []string
+	for currency := range currencies {
+		sortedCurrencies = append(sortedCurrencies, currency)
+	}
+	sort.Strings(sortedCurrencies)
+
+	for _, currency := range sortedCurrencies {
+		before := snapshot.Balances[currency].Total()
+		after := balances[currency].Total()
+		if before != after {
+			reconciliation.BalanceChanges = append(reconciliation.BalanceChanges, BalanceChange{
+				Currency: currency,
+				Before:   before,
+				After:    after,
+			})
+		}
+	}
+}
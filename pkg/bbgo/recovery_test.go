@@ -0,0 +1,212 @@
+package bbgo
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type recoveryTestExchange struct {
+	types.Exchange
+
+	openOrders []types.Order
+	trades     []types.Trade
+	balances   types.BalanceMap
+}
+
+func (e *recoveryTestExchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	for _, o := range e.openOrders {
+		if o.Symbol == symbol {
+			orders = append(orders, o)
+		}
+	}
+
+	return orders, nil
+}
+
+func (e *recoveryTestExchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
+	for _, trade := range e.trades {
+		if trade.Symbol == symbol && !trade.Time.Time().Before(*options.StartTime) {
+			trades = append(trades, trade)
+		}
+	}
+
+	return trades, nil
+}
+
+func (e *recoveryTestExchange) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) (orders []types.Order, err error) {
+	return nil, nil
+}
+
+func (e *recoveryTestExchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	return e.balances, nil
+}
+
+func newRecoveryTestOrder(orderID uint64, status types.OrderStatus, executedQuantity float64) types.Order {
+	return types.Order{
+		SubmitOrder: types.SubmitOrder{
+			Symbol:   "BTCUSDT",
+			Side:     types.SideTypeBuy,
+			Type:     types.OrderTypeLimit,
+			Price:    100.0,
+			Quantity: 1.0,
+		},
+		OrderID:          orderID,
+		Status:           status,
+		ExecutedQuantity: executedQuantity,
+	}
+}
+
+func TestRecoveryRecorder(t *testing.T) {
+	exchange := &recoveryTestExchange{}
+	userDataStream := &bootstrapTestStream{StandardStream: types.NewStandardStream()}
+	marketDataStream := &bootstrapTestStream{StandardStream: types.NewStandardStream()}
+
+	orderStore := NewOrderStore("BTCUSDT")
+	orderStore.Add(
+		newRecoveryTestOrder(1, types.OrderStatusNew, 0),
+		newRecoveryTestOrder(2, types.OrderStatusPartiallyFilled, 0.5),
+		newRecoveryTestOrder(3, types.OrderStatusFilled, 1.0),
+	)
+
+	account := types.NewAccount()
+	account.UpdateBalances(types.BalanceMap{
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(1.0)},
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(1000.0), Locked: fixedpoint.NewFromFloat(200.0)},
+	})
+
+	environ := NewEnvironment()
+	environ.AddExchangeSession("binance", &ExchangeSession{
+		Name:             "binance",
+		Exchange:         exchange,
+		Account:          account,
+		UserDataStream:   userDataStream,
+		MarketDataStream: marketDataStream,
+		positions: map[string]*types.Position{
+			"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT", Base: fixedpoint.NewFromFloat(0.5)},
+		},
+		orderStores: map[string]*OrderStore{"BTCUSDT": orderStore},
+	})
+
+	dir, err := ioutil.TempDir("", "bbgo-recovery")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	recorder := NewRecoveryRecorder(environ, RecoveryConfig{Path: filepath.Join(dir, "data", "recovery.json")})
+	recorder.BindSessions()
+
+	tradeTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	userDataStream.EmitTradeUpdate(types.Trade{ID: 10, Symbol: "BTCUSDT", Time: types.Time(tradeTime)})
+	// the earlier trade does not move the offset back
+	userDataStream.EmitTradeUpdate(types.Trade{ID: 9, Symbol: "BTCUSDT", Time: types.Time(tradeTime.Add(-time.Minute))})
+	marketDataStream.EmitKLineClosed(types.KLine{Symbol: "BTCUSDT", EndTime: tradeTime})
+
+	// no snapshot, the last run was shut down gracefully
+	report, err := recorder.Reconcile(context.Background())
+	assert.NoError(t, err)
+	assert.Nil(t, report)
+
+	assert.NoError(t, recorder.Write("periodic"))
+
+	snapshot, err := LoadRecoverySnapshot(recorder.Path)
+	if !assert.NoError(t, err) || !assert.NotNil(t, snapshot) {
+		return
+	}
+
+	assert.Equal(t, "periodic", snapshot.Reason)
+	sessionSnapshot := snapshot.Sessions["binance"]
+	if assert.NotNil(t, sessionSnapshot) {
+		if assert.Len(t, sessionSnapshot.OpenOrders, 2) {
+			assert.Equal(t, uint64(1), sessionSnapshot.OpenOrders[0].OrderID)
+			assert.Equal(t, uint64(2), sessionSnapshot.OpenOrders[1].OrderID)
+		}
+		assert.Equal(t, 0.5, sessionSnapshot.Positions["BTCUSDT"].Base.Float64())
+		assert.Equal(t, 1200.0, sessionSnapshot.Balances["USDT"].Total().Float64())
+		assert.Equal(t, int64(10), sessionSnapshot.Offsets["BTCUSDT"].LastTradeID)
+		assert.True(t, tradeTime.Equal(sessionSnapshot.Offsets["BTCUSDT"].LastKLineTime))
+	}
+
+	// while the process was down: order 1 was filled, order 2 was executed more, order 4 was placed manually
+	exchange.openOrders = []types.Order{
+		newRecoveryTestOrder(2, types.OrderStatusPartiallyFilled, 0.8),
+		newRecoveryTestOrder(4, types.OrderStatusNew, 0),
+	}
+	exchange.trades = []types.Trade{
+		{ID: 10, Symbol: "BTCUSDT", Time: types.Time(tradeTime)},
+		{ID: 11, Symbol: "BTCUSDT", Time: types.Time(tradeTime.Add(time.Minute))},
+	}
+	exchange.balances = types.BalanceMap{
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(2.0)},
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(1000.0), Locked: fixedpoint.NewFromFloat(200.0)},
+	}
+
+	report, err = recorder.Reconcile(context.Background())
+	if !assert.NoError(t, err) || !assert.NotNil(t, report) {
+		return
+	}
+
+	assert.False(t, report.IsClean())
+	if assert.Len(t, report.Sessions, 1) {
+		s := report.Sessions[0]
+		if assert.Len(t, s.ClosedOrders, 1) {
+			assert.Equal(t, uint64(1), s.ClosedOrders[0].OrderID)
+		}
+		if assert.Len(t, s.ChangedOrders, 1) {
+			assert.Equal(t, 0.8, s.ChangedOrders[0].ExecutedQuantity)
+		}
+		if assert.Len(t, s.UnknownOrders, 1) {
+			assert.Equal(t, uint64(4), s.UnknownOrders[0].OrderID)
+		}
+		if assert.Len(t, s.MissedTrades, 1) {
+			assert.Equal(t, int64(11), s.MissedTrades[0].ID)
+		}
+		if assert.Len(t, s.BalanceChanges, 1) {
+			assert.Equal(t, BalanceChange{Currency: "BTC", Before: fixedpoint.NewFromFloat(1.0), After: fixedpoint.NewFromFloat(2.0)}, s.BalanceChanges[0])
+		}
+		assert.Empty(t, s.Errors)
+	}
+	assert.Contains(t, report.String(), "missed trade")
+
+	// the snapshot is moved after the reconciliation
+	_, err = os.Stat(recorder.Path)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(recorder.Path + ".reconciled")
+	assert.NoError(t, err)
+
+	assert.NoError(t, recorder.Write("start"))
+	assert.NoError(t, recorder.Clean())
+	snapshot, err = LoadRecoverySnapshot(recorder.Path)
+	assert.NoError(t, err)
+	assert.Nil(t, snapshot)
+}
+
+func TestRecoveryRecorder_RecoverPanic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bbgo-recovery")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	environ := NewEnvironment()
+	recorder := NewRecoveryRecorder(environ, RecoveryConfig{Path: filepath.Join(dir, "recovery.json")})
+
+	assert.Panics(t, func() {
+		defer recorder.RecoverPanic()
+		panic("boom")
+	})
+
+	snapshot, err := LoadRecoverySnapshot(recorder.Path)
+	if assert.NoError(t, err) && assert.NotNil(t, snapshot) {
+		assert.Contains(t, snapshot.Reason, "panic: boom")
+	}
+}
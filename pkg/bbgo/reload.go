@@ -0,0 +1,246 @@
+package bbgo
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultConfigWatchInterval = 5 * time.Second
+
+// ReloadableStrategy is implemented by the strategies that can apply the changed parameters without restarting,
+// e.g., the spreads, the grid ranges or the order sizes. The strategy passed to Reload is loaded from the config,
+// it has the same type of the running strategy but it's not injected or run, the running strategy copies
+// the parameters it supports from it and keeps its own state.
+//
+// Reload is not called from the stream callbacks, the strategy should guard the parameters with its own lock.
+type ReloadableStrategy interface {
+	Reload(ctx context.Context, strategy interface{}) error
+}
+
+// StrategyReloadResult reports the strategies matched by the reloaded config, the strategies are identified by
+// the session and the target, e.g., binance/grid:BTCUSDT
+type StrategyReloadResult struct {
+	// Reloaded are the strategies reloaded with the new parameters
+	Reloaded []string `json:"reloaded"`
+
+	// NotReloadable are the running strategies that do not implement ReloadableStrategy, they need a restart
+	NotReloadable []string `json:"notReloadable,omitempty"`
+
+	// NotRunning are the strategies added to the config, they need a restart
+	NotRunning []string `json:"notRunning,omitempty"`
+
+	// Errors are the reload errors of the strategies
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+func (r *StrategyReloadResult) String() string {
+	return fmt.Sprintf("%d strategies reloaded, %d not reloadable, %d not running, %d errors",
+		len(r.Reloaded), len(r.NotReloadable), len(r.NotRunning), len(r.Errors))
+}
+
+func strategyReloadKey(strategy interface{}, session string) string {
+	s := newRunningStrategy(strategy, session)
+	if s.CrossExchange {
+		return s.Target
+	}
+
+	return s.Session + "/" + s.Target
+}
+
+// ReloadStrategies applies the strategies of the config to the running strategies, the strategies are matched
+// by the session, the strategy ID, the symbol and the order in the config. The strategies of a different type or
+// removed from the config are left untouched.
+func (trader *Trader) ReloadStrategies(ctx context.Context, config *Config) *StrategyReloadResult {
+	loaded := make(map[string][]interface{})
+	for _, mount := range config.ExchangeStrategies {
+		for _, sessionName := range mount.Mounts {
+			key := strategyReloadKey(mount.Strategy, sessionName)
+			loaded[key] = append(loaded[key], mount.Strategy)
+		}
+	}
+
+	for _, strategy := range config.CrossExchangeStrategies {
+		key := strategyReloadKey(strategy, "")
+		loaded[key] = append(loaded[key], strategy)
+	}
+
+	running := make(map[string][]interface{})
+	for sessionName, strategies := range trader.exchangeStrategies {
+		for _, strategy := range strategies {
+			key := strategyReloadKey(strategy, sessionName)
+			running[key] = append(running[key], strategy)
+		}
+	}
+
+	for _, strategy := range trader.crossExchangeStrategies {
+		key := strategyReloadKey(strategy, "")
+		running[key] = append(running[key], strategy)
+	}
+
+	result := &StrategyReloadResult{}
+	for key, strategies := range loaded {
+		for i, strategy := range strategies {
+			name := key
+			if i > 0 {
+				name = fmt.Sprintf("%s#%d", key, i)
+			}
+
+			if i >= len(running[key]) {
+				result.NotRunning = append(result.NotRunning, name)
+				continue
+			}
+
+			current := running[key][i]
+			reloadable, ok := current.(ReloadableStrategy)
+			if !ok || reflect.TypeOf(current) != reflect.TypeOf(strategy) {
+				result.NotReloadable = append(result.NotReloadable, name)
+				continue
+			}
+
+			if err := reloadable.Reload(ctx, strategy); err != nil {
+				if result.Errors == nil {
+					result.Errors = make(map[string]string)
+				}
+
+				result.Errors[name] = err.Error()
+				log.WithError(err).Errorf("strategy %s reload error", name)
+				continue
+			}
+
+			result.Reloaded = append(result.Reloaded, name)
+		}
+	}
+
+	sort.Strings(result.Reloaded)
+	sort.Strings(result.NotReloadable)
+	sort.Strings(result.NotRunning)
+	return result
+}
+
+// ConfigReloader reloads the strategy parameters of the config file, the reload is triggered by
+// the config file changes, SIGHUP or the admin api.
+type ConfigReloader struct {
+	ConfigFile string
+
+	// Interval is the interval of checking the config file changes
+	Interval time.Duration
+
+	trader *Trader
+
+	mu       sync.Mutex
+	checksum []byte
+}
+
+func NewConfigReloader(configFile string, trader *Trader) *ConfigReloader {
+	return &ConfigReloader{
+		ConfigFile: configFile,
+		Interval:   defaultConfigWatchInterval,
+		trader:     trader,
+	}
+}
+
+// readChecksum returns the checksum of the config file and the config files it imports
+func (r *ConfigReloader) readChecksum() ([]byte, error) {
+	sources, err := resolveConfigSources(r.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha256.New()
+	for _, source := range sources {
+		h.Write([]byte(source.File))
+		h.Write(source.Content)
+	}
+
+	return h.Sum(nil), nil
+}
+
+// Reload loads the strategies of the config file and applies them to the running strategies
+func (r *ConfigReloader) Reload(ctx context.Context) (*StrategyReloadResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	checksum, err := r.readChecksum()
+	if err != nil {
+		return nil, err
+	}
+
+	// the invalid config is not reloaded again by the watcher until the file is changed
+	r.checksum = checksum
+
+	config, err := LoadStrategies(r.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	result := r.trader.ReloadStrategies(ctx, config)
+	log.Infof("config %s reloaded: %s", r.ConfigFile, result)
+
+	if len(result.NotReloadable) > 0 || len(result.NotRunning) > 0 {
+		log.Warnf("strategies %v need a restart to apply the config", append(result.NotReloadable, result.NotRunning...))
+	}
+
+	if r.trader.environment != nil {
+		r.trader.environment.Notify(":arrows_counterclockwise: config %s reloaded: %s", r.ConfigFile, result.String())
+	}
+
+	return result, nil
+}
+
+// changed returns true if the content of the config file is changed since the last reload
+func (r *ConfigReloader) changed() (bool, error) {
+	checksum, err := r.readChecksum()
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.checksum == nil {
+		r.checksum = checksum
+		return false, nil
+	}
+
+	return !bytes.Equal(r.checksum, checksum), nil
+}
+
+// Watch reloads the config when the config file is changed, until the context is canceled
+func (r *ConfigReloader) Watch(ctx context.Context) {
+	if _, err := r.changed(); err != nil {
+		log.WithError(err).Errorf("can not read config %s", r.ConfigFile)
+	}
+
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			changed, err := r.changed()
+			if err != nil {
+				log.WithError(err).Errorf("can not read config %s", r.ConfigFile)
+				continue
+			}
+
+			if !changed {
+				continue
+			}
+
+			if _, err := r.Reload(ctx); err != nil {
+				log.WithError(err).Errorf("config %s reload error", r.ConfigFile)
+			}
+		}
+	}
+}
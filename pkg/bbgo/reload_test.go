@@ -0,0 +1,155 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	RegisterStrategy("reload-test", &reloadTestStrategy{})
+}
+
+type reloadTestStrategy struct {
+	Symbol   string  `json:"symbol"`
+	Quantity float64 `json:"quantity"`
+
+	mu sync.Mutex
+}
+
+func (s *reloadTestStrategy) ID() string {
+	return "reload-test"
+}
+
+func (s *reloadTestStrategy) Run(ctx context.Context, orderExecutor OrderExecutor, session *ExchangeSession) error {
+	return nil
+}
+
+func (s *reloadTestStrategy) Reload(ctx context.Context, strategy interface{}) error {
+	next := strategy.(*reloadTestStrategy)
+	if next.Quantity <= 0 {
+		return errors.New("quantity should be greater than zero")
+	}
+
+	s.mu.Lock()
+	s.Quantity = next.Quantity
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *reloadTestStrategy) quantity() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Quantity
+}
+
+const reloadTestConfig = `
+exchangeStrategies:
+- on: binance
+  reload-test:
+    symbol: BTCUSDT
+    quantity: 0.2
+- on: binance
+  test:
+    symbol: BTCUSDT
+- on: [binance, max]
+  reload-test:
+    symbol: ETHUSDT
+    quantity: 1.0
+`
+
+func writeReloadTestConfig(t *testing.T, content string) string {
+	file, err := ioutil.TempFile("", "bbgo-reload-*.yaml")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	_, err = file.WriteString(content)
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+	return file.Name()
+}
+
+func TestTrader_ReloadStrategies(t *testing.T) {
+	configFile := writeReloadTestConfig(t, reloadTestConfig)
+	defer os.Remove(configFile)
+
+	btc := &reloadTestStrategy{Symbol: "BTCUSDT", Quantity: 0.1}
+	eth := &reloadTestStrategy{Symbol: "ETHUSDT", Quantity: 0.5}
+	trader := &Trader{
+		exchangeStrategies: map[string][]SingleExchangeStrategy{
+			"binance": {btc, eth, &TestStrategy{Symbol: "BTCUSDT"}},
+		},
+	}
+
+	config, err := LoadStrategies(configFile)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	result := trader.ReloadStrategies(context.Background(), config)
+	assert.Equal(t, []string{"binance/reload-test:BTCUSDT", "binance/reload-test:ETHUSDT"}, result.Reloaded)
+	assert.Equal(t, []string{"binance/test:BTCUSDT"}, result.NotReloadable)
+	assert.Equal(t, []string{"max/reload-test:ETHUSDT"}, result.NotRunning)
+	assert.Len(t, result.Errors, 0)
+
+	assert.Equal(t, 0.2, btc.quantity())
+	assert.Equal(t, 1.0, eth.quantity())
+
+	// the invalid parameters are reported and not applied
+	config.ExchangeStrategies[0].Strategy.(*reloadTestStrategy).Quantity = 0
+	result = trader.ReloadStrategies(context.Background(), config)
+	assert.Contains(t, result.Errors, "binance/reload-test:BTCUSDT")
+	assert.Equal(t, 0.2, btc.quantity())
+}
+
+func TestConfigReloader_Watch(t *testing.T) {
+	configFile := writeReloadTestConfig(t, reloadTestConfig)
+	defer os.Remove(configFile)
+
+	btc := &reloadTestStrategy{Symbol: "BTCUSDT", Quantity: 0.1}
+	trader := &Trader{
+		exchangeStrategies: map[string][]SingleExchangeStrategy{
+			"binance": {btc},
+		},
+	}
+
+	reloader := NewConfigReloader(configFile, trader)
+	reloader.Interval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reloader.Watch(ctx)
+
+	// the config is not reloaded until it's changed
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 0.1, btc.quantity())
+
+	// the broken config is skipped
+	assert.NoError(t, ioutil.WriteFile(configFile, []byte("exchangeStrategies: {"), 0644))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 0.1, btc.quantity())
+
+	assert.NoError(t, ioutil.WriteFile(configFile, []byte(`
+exchangeStrategies:
+- on: binance
+  reload-test:
+    symbol: BTCUSDT
+    quantity: 0.3
+`), 0644))
+
+	assert.Eventually(t, func() bool {
+		return btc.quantity() == 0.3
+	}, time.Second, 10*time.Millisecond)
+
+	result, err := reloader.Reload(ctx)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"binance/reload-test:BTCUSDT"}, result.Reloaded)
+	}
+}
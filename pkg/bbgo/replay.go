@@ -0,0 +1,91 @@
+package bbgo
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// FilterExchangeStrategies returns the strategy mounts of the given strategy ID
+func FilterExchangeStrategies(mounts []ExchangeStrategyMount, strategyID string) (filtered []ExchangeStrategyMount) {
+	for _, mount := range mounts {
+		if mount.Strategy.ID() == strategyID {
+			filtered = append(filtered, mount)
+		}
+	}
+
+	return filtered
+}
+
+// ReplayLogger logs the decision points of the replayed strategies, i.e., the closed klines that trigger the
+// strategy and the submitted orders, the order updates and the trades. The replay time is the end time of the
+// last closed kline so that the log lines can be matched with the recorded market data.
+type ReplayLogger struct {
+	Logger log.FieldLogger
+
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewReplayLogger(logger log.FieldLogger) *ReplayLogger {
+	return &ReplayLogger{Logger: logger}
+}
+
+// BindSession logs the market data and the order events of the session
+func (l *ReplayLogger) BindSession(session *ExchangeSession) {
+	if session.MarketDataStream != nil {
+		session.MarketDataStream.OnKLineClosed(func(kline types.KLine) {
+			l.LogKLineClosed(session.Name, kline)
+		})
+	}
+
+	executor := session.OrderExecutor
+	if executor == nil {
+		return
+	}
+
+	executor.OnSubmitOrder(func(order types.SubmitOrder) {
+		l.entry(session.Name, "submit").Infof("submit order: %s", order.String())
+	})
+
+	executor.OnSubmitOrderError(func(order types.SubmitOrder, err error) {
+		l.entry(session.Name, "reject").WithError(err).Warnf("submit order error: %s", order.String())
+	})
+
+	executor.OnOrderUpdate(func(order types.Order) {
+		l.entry(session.Name, "order").Infof("order update: %s", order.String())
+	})
+
+	executor.OnTradeUpdate(func(trade types.Trade) {
+		l.entry(session.Name, "trade").Infof("trade: %s", trade.String())
+	})
+}
+
+// LogKLineClosed logs the closed kline and moves the replay time to the kline end time
+func (l *ReplayLogger) LogKLineClosed(sessionName string, kline types.KLine) {
+	l.mu.Lock()
+	if kline.EndTime.After(l.now) {
+		l.now = kline.EndTime
+	}
+	l.mu.Unlock()
+
+	l.entry(sessionName, "kline").Infof("kline closed: %s", kline.String())
+}
+
+// Now returns the current replay time
+func (l *ReplayLogger) Now() time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.now
+}
+
+func (l *ReplayLogger) entry(sessionName, event string) log.FieldLogger {
+	return l.Logger.WithFields(log.Fields{
+		"session":    sessionName,
+		"event":      event,
+		"replayTime": l.Now().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,97 @@
+package bbgo
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestFilterExchangeStrategies(t *testing.T) {
+	mounts := []ExchangeStrategyMount{
+		{Mounts: []string{"binance"}, Strategy: &TestStrategy{Symbol: "BTCUSDT"}},
+		{Mounts: []string{"binance"}, Strategy: &commandTestStrategy{}},
+		{Mounts: []string{"max"}, Strategy: &TestStrategy{Symbol: "ETHUSDT"}},
+	}
+
+	filtered := FilterExchangeStrategies(mounts, "test")
+	if assert.Len(t, filtered, 2) {
+		assert.Equal(t, []string{"binance"}, filtered[0].Mounts)
+		assert.Equal(t, []string{"max"}, filtered[1].Mounts)
+	}
+
+	assert.Empty(t, FilterExchangeStrategies(mounts, "grid"))
+}
+
+func TestReplayLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.New()
+	logger.SetOutput(&buf)
+	logger.SetFormatter(&log.TextFormatter{DisableColors: true, DisableTimestamp: true})
+
+	session := &ExchangeSession{Name: "binance"}
+	session.OrderExecutor = &ExchangeOrderExecutor{Session: session}
+
+	replayLogger := NewReplayLogger(logger)
+	replayLogger.BindSession(session)
+
+	endTime := time.Date(2021, 5, 1, 0, 59, 59, 0, time.UTC)
+	replayLogger.LogKLineClosed("binance", types.KLine{Symbol: "BTCUSDT", Interval: types.Interval1h, EndTime: endTime})
+	assert.Equal(t, endTime, replayLogger.Now())
+
+	// the earlier kline does not move the replay time backward
+	replayLogger.LogKLineClosed("binance", types.KLine{Symbol: "BTCUSDT", Interval: types.Interval1m, EndTime: endTime.Add(-time.Minute)})
+	assert.Equal(t, endTime, replayLogger.Now())
+
+	session.OrderExecutor.EmitSubmitOrder(types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy})
+	session.OrderExecutor.EmitSubmitOrderError(types.SubmitOrder{Symbol: "BTCUSDT"}, assert.AnError)
+	session.OrderExecutor.EmitOrderUpdate(types.Order{OrderID: 1, Status: types.OrderStatusFilled})
+	session.OrderExecutor.EmitTradeUpdate(types.Trade{ID: 2, Symbol: "BTCUSDT"})
+
+	out := buf.String()
+	assert.Contains(t, out, "event=kline")
+	assert.Contains(t, out, "event=submit")
+	assert.Contains(t, out, "event=reject")
+	assert.Contains(t, out, "event=order")
+	assert.Contains(t, out, "event=trade")
+	assert.Contains(t, out, "replayTime=\"2021-05-01T00:59:59Z\"")
+	assert.Contains(t, out, "session=binance")
+}
+
+func TestPersistence_ReadOnly(t *testing.T) {
+	facade := &service.PersistenceServiceFacade{
+		Memory: service.NewMemoryService(),
+	}
+
+	p := &Persistence{
+		PersistenceSelector: &PersistenceSelector{Type: "memory"},
+		Facade:              facade,
+	}
+
+	i := 3
+	assert.NoError(t, p.Save(&i, "grid"))
+
+	facade.ReadOnly = true
+
+	var j int
+	assert.NoError(t, p.Load(&j, "grid"))
+	assert.Equal(t, 3, j)
+
+	k := 5
+	assert.NoError(t, p.Save(&k, "grid"))
+	assert.NoError(t, p.Load(&j, "grid"))
+	assert.Equal(t, 5, j)
+
+	// the persisted value is kept
+	facade.ReadOnly = false
+	assert.NoError(t, p.Load(&j, "grid"))
+	assert.Equal(t, 3, j)
+
+	_, err := (&Persistence{Facade: facade}).backendService("mysql")
+	assert.Error(t, err)
+}
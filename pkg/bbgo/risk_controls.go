@@ -71,4 +71,10 @@ func groupSubmitOrdersBySymbol(orders []types.SubmitOrder) map[string][]types.Su
 
 type RiskControls struct {
 	SessionBasedRiskControl map[string]*SessionBasedRiskControl `json:"sessionBased,omitempty" yaml:"sessionBased,omitempty"`
+
+	// PositionLimits limits the position notional, the order quantity and the open orders by symbol and strategy
+	PositionLimits *PositionLimitConfig `json:"positionLimits,omitempty" yaml:"positionLimits,omitempty"`
+
+	// KillSwitch halts the order submission when the daily loss crosses the threshold
+	KillSwitch *KillSwitchConfig `json:"killSwitch,omitempty" yaml:"killSwitch,omitempty"`
 }
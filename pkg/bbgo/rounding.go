@@ -0,0 +1,48 @@
+package bbgo
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// RoundingOrderExecutor sets the rounding policy of the strategy on the submitted orders,
+// the price and the quantity are rounded by the policy when the session formats the orders.
+type RoundingOrderExecutor struct {
+	OrderExecutor
+
+	Policy types.RoundingPolicy
+}
+
+func NewRoundingOrderExecutor(executor OrderExecutor, policy types.RoundingPolicy) *RoundingOrderExecutor {
+	return &RoundingOrderExecutor{
+		OrderExecutor: executor,
+		Policy:        policy,
+	}
+}
+
+func (e *RoundingOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	var roundedOrders = make([]types.SubmitOrder, len(orders))
+	for i, order := range orders {
+		// the policy of the order takes precedence
+		if order.RoundingPolicy == "" {
+			order.RoundingPolicy = e.Policy
+		}
+
+		roundedOrders[i] = order
+	}
+
+	return e.OrderExecutor.SubmitOrders(ctx, roundedOrders...)
+}
+
+// strategyRoundingPolicy returns the RoundingPolicy field of the strategy struct, the field is usually
+// declared as `RoundingPolicy types.RoundingPolicy` with the json tag "roundingPolicy" to be configured
+func strategyRoundingPolicy(rs reflect.Value) (types.RoundingPolicy, bool) {
+	field, ok := hasField(rs, "RoundingPolicy")
+	if !ok || field.Kind() != reflect.String || field.String() == "" {
+		return "", false
+	}
+
+	return types.RoundingPolicy(field.String()), true
+}
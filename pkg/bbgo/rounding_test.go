@@ -0,0 +1,60 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestRoundingOrderExecutor(t *testing.T) {
+	base := &recordOrderExecutor{ExchangeOrderExecutor: &ExchangeOrderExecutor{}}
+	executor := NewRoundingOrderExecutor(base, types.RoundingPolicyAggressive)
+
+	_, err := executor.SubmitOrders(context.Background(),
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit},
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeLimit, RoundingPolicy: types.RoundingPolicyNearest},
+	)
+	assert.NoError(t, err)
+
+	if assert.Len(t, base.orders, 2) {
+		assert.Equal(t, types.RoundingPolicyAggressive, base.orders[0].RoundingPolicy)
+		assert.Equal(t, types.RoundingPolicyNearest, base.orders[1].RoundingPolicy)
+	}
+}
+
+func TestExchangeSession_FormatOrder(t *testing.T) {
+	session := &ExchangeSession{
+		markets: map[string]types.Market{
+			"BTCUSDT": {Symbol: "BTCUSDT", TickSize: 0.5, StepSize: 0.0001},
+		},
+	}
+
+	order, err := session.FormatOrder(types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimit,
+		Price:    50000.7,
+		Quantity: 0.12345,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 50000.5, order.Price)
+	assert.Equal(t, "50000.5", order.PriceString)
+	assert.Equal(t, "0.1234", order.QuantityString)
+
+	order, err = session.FormatOrder(types.SubmitOrder{
+		Symbol:         "BTCUSDT",
+		Side:           types.SideTypeBuy,
+		Type:           types.OrderTypeLimit,
+		Price:          50000.7,
+		Quantity:       0.12345,
+		RoundingPolicy: types.RoundingPolicyAggressive,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "50001.0", order.PriceString)
+
+	_, err = session.FormatOrder(types.SubmitOrder{Symbol: "BTCUSDT", RoundingPolicy: "up"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,156 @@
+package bbgo
+
+import (
+	"math"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var ErrPriceDeviationExceeded = errors.New("order price deviates too much from the reference price")
+
+var ErrSanityNotionalExceeded = errors.New("order notional exceeds the sanity check notional cap")
+
+// OrderSanityLimits are the fat-finger thresholds, the zero values mean no limit
+type OrderSanityLimits struct {
+	// MaxPriceDeviation is the max deviation ratio of the order price from the reference price, e.g., 0.05 for 5%
+	MaxPriceDeviation fixedpoint.Value `json:"maxPriceDeviation,omitempty" yaml:"maxPriceDeviation,omitempty"`
+
+	// MaxNotional is the max notional (price * quantity in the quote currency) of one order
+	MaxNotional fixedpoint.Value `json:"maxNotional,omitempty" yaml:"maxNotional,omitempty"`
+}
+
+// OrderSanityCheck is the pre-trade check of the session order executor, it rejects the orders priced too far away
+// from the mid price of the order book (or the last price if the order book is not available), and the orders larger
+// than the notional cap. The default limits can be overridden by symbol:
+//
+//	sanityCheck:
+//	  maxPriceDeviation: 0.05
+//	  maxNotional: 5000.0
+//	  symbols:
+//	    BTCUSDT:
+//	      maxNotional: 20000.0
+type OrderSanityCheck struct {
+	// MaxPriceDeviation and MaxNotional are the default limits of all the symbols
+	MaxPriceDeviation fixedpoint.Value `json:"maxPriceDeviation,omitempty" yaml:"maxPriceDeviation,omitempty"`
+	MaxNotional       fixedpoint.Value `json:"maxNotional,omitempty" yaml:"maxNotional,omitempty"`
+
+	// Symbols overrides the non-zero limits by symbol
+	Symbols map[string]OrderSanityLimits `json:"symbols,omitempty" yaml:"symbols,omitempty"`
+}
+
+func (c *OrderSanityCheck) Validate() error {
+	if err := c.defaultLimits().validate(); err != nil {
+		return err
+	}
+
+	for symbol, limits := range c.Symbols {
+		if err := limits.validate(); err != nil {
+			return errors.Wrapf(err, "invalid sanity check limits of %s", symbol)
+		}
+	}
+
+	return nil
+}
+
+func (l OrderSanityLimits) validate() error {
+	if l.MaxPriceDeviation < 0 || l.MaxNotional < 0 {
+		return errors.New("sanity check limits can not be negative")
+	}
+
+	return nil
+}
+
+func (c *OrderSanityCheck) defaultLimits() OrderSanityLimits {
+	return OrderSanityLimits{
+		MaxPriceDeviation: c.MaxPriceDeviation,
+		MaxNotional:       c.MaxNotional,
+	}
+}
+
+// Limits returns the limits of the symbol, the symbol limits override the default limits
+func (c *OrderSanityCheck) Limits(symbol string) OrderSanityLimits {
+	limits := c.defaultLimits()
+	for s, override := range c.Symbols {
+		if !strings.EqualFold(s, symbol) {
+			continue
+		}
+
+		if override.MaxPriceDeviation > 0 {
+			limits.MaxPriceDeviation = override.MaxPriceDeviation
+		}
+
+		if override.MaxNotional > 0 {
+			limits.MaxNotional = override.MaxNotional
+		}
+	}
+
+	return limits
+}
+
+// Check checks the order with the reference price, the market orders are only checked by the notional cap with the
+// reference price. The order is rejected if the reference price is required but not available.
+func (c *OrderSanityCheck) Check(order types.SubmitOrder, referencePrice float64) error {
+	limits := c.Limits(order.Symbol)
+
+	isMarketOrder := order.Type == types.OrderTypeMarket || order.Type == types.OrderTypeStopMarket || order.Price == 0
+
+	if limits.MaxPriceDeviation > 0 && !isMarketOrder {
+		if referencePrice <= 0 {
+			return errors.Wrapf(ErrPriceDeviationExceeded, "the reference price of %s is not available", order.Symbol)
+		}
+
+		deviation := math.Abs(order.Price-referencePrice) / referencePrice
+		if deviation > limits.MaxPriceDeviation.Float64() {
+			return errors.Wrapf(ErrPriceDeviationExceeded, "%s %s order price %f deviates %.2f%% from the reference price %f, max deviation is %.2f%%",
+				order.Symbol, order.Side, order.Price, deviation*100.0, referencePrice, limits.MaxPriceDeviation.Float64()*100.0)
+		}
+	}
+
+	if limits.MaxNotional > 0 {
+		price := order.Price
+		if isMarketOrder {
+			price = referencePrice
+		}
+
+		if price <= 0 {
+			return errors.Wrapf(ErrSanityNotionalExceeded, "the notional of %s %s order can not be determined without the price", order.Symbol, order.Side)
+		}
+
+		if notional := price * order.Quantity; notional > limits.MaxNotional.Float64() {
+			return errors.Wrapf(ErrSanityNotionalExceeded, "%s %s order notional %f > %f", order.Symbol, order.Side, notional, limits.MaxNotional.Float64())
+		}
+	}
+
+	return nil
+}
+
+// ReferencePrice returns the mid price of the order book, or the last price if the order book is not available
+func (session *ExchangeSession) ReferencePrice(symbol string) (float64, bool) {
+	if book, ok := session.OrderBook(symbol); ok && book != nil {
+		if bid, ask, ok := book.BestBidAndAsk(); ok && bid.Price > 0 && ask.Price > 0 {
+			return (bid.Price.Float64() + ask.Price.Float64()) / 2.0, true
+		}
+	}
+
+	return session.LastPrice(symbol)
+}
+
+// checkOrderSanity checks the orders by the session sanity check, the first rejected order is returned with the error
+func (session *ExchangeSession) checkOrderSanity(orders []types.SubmitOrder) (types.SubmitOrder, error) {
+	if session.SanityCheck == nil {
+		return types.SubmitOrder{}, nil
+	}
+
+	for _, order := range orders {
+		referencePrice, _ := session.ReferencePrice(order.Symbol)
+		if err := session.SanityCheck.Check(order, referencePrice); err != nil {
+			return order, err
+		}
+	}
+
+	return types.SubmitOrder{}, nil
+}
@@ -0,0 +1,96 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestOrderSanityCheck_Check(t *testing.T) {
+	check := &OrderSanityCheck{
+		MaxPriceDeviation: fixedpoint.NewFromFloat(0.05),
+		MaxNotional:       fixedpoint.NewFromFloat(1000.0),
+		Symbols: map[string]OrderSanityLimits{
+			"btcusdt": {MaxNotional: fixedpoint.NewFromFloat(5000.0)},
+		},
+	}
+	assert.NoError(t, check.Validate())
+
+	limits := check.Limits("BTCUSDT")
+	assert.Equal(t, fixedpoint.NewFromFloat(0.05), limits.MaxPriceDeviation, "the zero override inherits the default limit")
+	assert.Equal(t, fixedpoint.NewFromFloat(5000.0), limits.MaxNotional)
+
+	// the price deviation
+	assert.NoError(t, check.Check(types.SubmitOrder{Symbol: "BTCUSDT", Type: types.OrderTypeLimit, Price: 51000.0, Quantity: 0.01}, 50000.0))
+	err := check.Check(types.SubmitOrder{Symbol: "BTCUSDT", Type: types.OrderTypeLimit, Price: 5000.0, Quantity: 0.01}, 50000.0)
+	assert.True(t, errors.Is(err, ErrPriceDeviationExceeded))
+	err = check.Check(types.SubmitOrder{Symbol: "BTCUSDT", Type: types.OrderTypeLimit, Price: 51000.0, Quantity: 0.01}, 0)
+	assert.True(t, errors.Is(err, ErrPriceDeviationExceeded), "the order is rejected without the reference price")
+
+	// the notional cap
+	err = check.Check(types.SubmitOrder{Symbol: "BTCUSDT", Type: types.OrderTypeLimit, Price: 50000.0, Quantity: 1.0}, 50000.0)
+	assert.True(t, errors.Is(err, ErrSanityNotionalExceeded))
+	err = check.Check(types.SubmitOrder{Symbol: "ETHUSDT", Type: types.OrderTypeLimit, Price: 4000.0, Quantity: 1.0}, 4000.0)
+	assert.True(t, errors.Is(err, ErrSanityNotionalExceeded))
+
+	// the market orders are checked by the notional cap with the reference price
+	assert.NoError(t, check.Check(types.SubmitOrder{Symbol: "BTCUSDT", Type: types.OrderTypeMarket, Quantity: 0.05}, 50000.0))
+	err = check.Check(types.SubmitOrder{Symbol: "BTCUSDT", Type: types.OrderTypeMarket, Quantity: 0.2}, 50000.0)
+	assert.True(t, errors.Is(err, ErrSanityNotionalExceeded))
+
+	check.Symbols["BTCUSDT"] = OrderSanityLimits{MaxPriceDeviation: fixedpoint.NewFromFloat(-0.1)}
+	assert.Error(t, check.Validate())
+}
+
+func TestExchangeOrderExecutor_SanityCheck(t *testing.T) {
+	exchange := &guardTestExchange{}
+	session := &ExchangeSession{
+		Name:     "binance",
+		Exchange: exchange,
+		markets: map[string]types.Market{
+			"BTCUSDT": {Symbol: "BTCUSDT", TickSize: 0.01, StepSize: 0.0001},
+		},
+		orderBooks: map[string]*types.StreamOrderBook{},
+		lastPrices: map[string]float64{"BTCUSDT": 40000.0},
+		SanityCheck: &OrderSanityCheck{
+			MaxPriceDeviation: fixedpoint.NewFromFloat(0.1),
+		},
+	}
+	session.OrderExecutor = &ExchangeOrderExecutor{Session: session}
+
+	book := types.NewStreamBook("BTCUSDT")
+	book.Load(types.SliceOrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(49990.0), Volume: fixedpoint.NewFromFloat(1.0)}},
+		Asks:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(50010.0), Volume: fixedpoint.NewFromFloat(1.0)}},
+	})
+	session.orderBooks["BTCUSDT"] = book
+
+	price, ok := session.ReferencePrice("BTCUSDT")
+	assert.True(t, ok)
+	assert.Equal(t, 50000.0, price, "the mid price of the order book is preferred")
+
+	var rejectedOrders []types.SubmitOrder
+	session.OrderExecutor.OnSubmitOrderError(func(order types.SubmitOrder, err error) {
+		rejectedOrders = append(rejectedOrders, order)
+	})
+
+	// the whole batch is rejected by the typo price
+	_, err := session.OrderExecutor.SubmitOrders(context.Background(),
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 49000.0, Quantity: 0.01},
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeLimit, Price: 5100.0, Quantity: 0.01},
+	)
+	assert.True(t, errors.Is(err, ErrPriceDeviationExceeded))
+	assert.Len(t, exchange.orders, 0)
+	assert.Len(t, rejectedOrders, 1)
+
+	createdOrders, err := session.OrderExecutor.SubmitOrders(context.Background(),
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeLimit, Price: 51000.0, Quantity: 0.01})
+	assert.NoError(t, err)
+	assert.Len(t, createdOrders, 1)
+}
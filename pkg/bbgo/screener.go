@@ -0,0 +1,269 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type ScreenerCriterion string
+
+const (
+	// ScreenerCriterionVolume ranks the markets by the 24h quote volume, higher is better
+	ScreenerCriterionVolume ScreenerCriterion = "volume"
+
+	// ScreenerCriterionVolatility ranks the markets by the 24h price range (high - low) / last, higher is better
+	ScreenerCriterionVolatility ScreenerCriterion = "volatility"
+
+	// ScreenerCriterionSpread ranks the markets by the bid/ask spread ratio, lower is better
+	ScreenerCriterionSpread ScreenerCriterion = "spread"
+
+	// ScreenerCriterionFundingRate ranks the markets by the absolute last funding rate, higher is better
+	ScreenerCriterionFundingRate ScreenerCriterion = "fundingRate"
+)
+
+func (c ScreenerCriterion) Validate() error {
+	switch c {
+	case ScreenerCriterionVolume, ScreenerCriterionVolatility, ScreenerCriterionSpread, ScreenerCriterionFundingRate:
+		return nil
+	}
+
+	return fmt.Errorf("unsupported screener criterion: %s", c)
+}
+
+// PremiumIndexQuerier is implemented by the futures exchanges that provide the funding rate
+type PremiumIndexQuerier interface {
+	QueryPremiumIndex(ctx context.Context, symbol string) (*types.PremiumIndex, error)
+}
+
+type ScreenerResult struct {
+	Symbol      string  `json:"symbol"`
+	Volume      float64 `json:"volume"`
+	Volatility  float64 `json:"volatility"`
+	Spread      float64 `json:"spread"`
+	FundingRate float64 `json:"fundingRate"`
+	Score       float64 `json:"score"`
+}
+
+func (r ScreenerResult) value(criterion ScreenerCriterion) float64 {
+	switch criterion {
+	case ScreenerCriterionVolume:
+		return r.Volume
+	case ScreenerCriterionVolatility:
+		return r.Volatility
+	case ScreenerCriterionSpread:
+		// lower spread is better
+		return -r.Spread
+	case ScreenerCriterionFundingRate:
+		return math.Abs(r.FundingRate)
+	}
+
+	return 0
+}
+
+// Screener ranks the markets of the universe by the weighted criteria
+type Screener struct {
+	Universe UniverseConfig `json:"universe" yaml:"universe"`
+
+	// Criteria is the criterion weight map, e.g., {volume: 1.0, volatility: 2.0}
+	Criteria map[ScreenerCriterion]float64 `json:"criteria" yaml:"criteria"`
+
+	// Limit limits the number of the results, 0 means no limit
+	Limit int `json:"limit,omitempty" yaml:"limit,omitempty"`
+}
+
+func (s *Screener) Validate() error {
+	if len(s.Criteria) == 0 {
+		return fmt.Errorf("screener criteria can not be empty")
+	}
+
+	for criterion := range s.Criteria {
+		if err := criterion.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Screen screens the markets of the initialized session
+func (s *Screener) Screen(ctx context.Context, session *ExchangeSession) ([]ScreenerResult, error) {
+	return s.ScreenMarkets(ctx, session.Exchange, session.Markets(), session.Account.Balances())
+}
+
+// ScreenMarkets queries the tickers (and the funding rates if needed) of the universe, and returns the ranked results.
+func (s *Screener) ScreenMarkets(ctx context.Context, exchange types.Exchange, markets types.MarketMap, balances types.BalanceMap) ([]ScreenerResult, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	symbols, err := s.Universe.Select(ctx, exchange, markets, balances)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(symbols) == 0 {
+		return nil, nil
+	}
+
+	tickers, err := exchange.QueryTickers(ctx, symbols...)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []ScreenerResult
+	for _, symbol := range symbols {
+		ticker, ok := tickers[symbol]
+		if !ok {
+			continue
+		}
+
+		results = append(results, newScreenerResult(symbol, ticker))
+	}
+
+	if _, ok := s.Criteria[ScreenerCriterionFundingRate]; ok {
+		querier, ok := exchange.(PremiumIndexQuerier)
+		if !ok {
+			return nil, fmt.Errorf("exchange %s does not support funding rate query", exchange.Name())
+		}
+
+		for i := range results {
+			index, err := querier.QueryPremiumIndex(ctx, results[i].Symbol)
+			if err != nil {
+				return nil, err
+			}
+
+			results[i].FundingRate = index.LastFundingRate.Float64()
+		}
+	}
+
+	results = rankScreenerResults(results, s.Criteria)
+	if s.Limit > 0 && len(results) > s.Limit {
+		results = results[:s.Limit]
+	}
+
+	return results, nil
+}
+
+func newScreenerResult(symbol string, ticker types.Ticker) ScreenerResult {
+	result := ScreenerResult{
+		Symbol: symbol,
+		Volume: ticker.Volume * ticker.Last,
+	}
+
+	if ticker.Last > 0 {
+		result.Volatility = (ticker.High - ticker.Low) / ticker.Last
+	}
+
+	if mid := (ticker.Buy + ticker.Sell) / 2.0; ticker.Buy > 0 && ticker.Sell > 0 {
+		result.Spread = (ticker.Sell - ticker.Buy) / mid
+	}
+
+	return result
+}
+
+// rankScreenerResults scores the results by the weighted percentile rank of each criterion,
+// the results are sorted by the score in descending order.
+func rankScreenerResults(results []ScreenerResult, criteria map[ScreenerCriterion]float64) []ScreenerResult {
+	if len(results) == 0 {
+		return results
+	}
+
+	for i := range results {
+		results[i].Score = 0
+	}
+
+	indexes := make([]int, len(results))
+	for criterion, weight := range criteria {
+		for i := range indexes {
+			indexes[i] = i
+		}
+
+		sort.SliceStable(indexes, func(a, b int) bool {
+			return results[indexes[a]].value(criterion) < results[indexes[b]].value(criterion)
+		})
+
+		for rank, idx := range indexes {
+			percentile := 1.0
+			if len(results) > 1 {
+				percentile = float64(rank) / float64(len(results)-1)
+			}
+
+			results[idx].Score += weight * percentile
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score == results[j].Score {
+			return results[i].Symbol < results[j].Symbol
+		}
+
+		return results[i].Score > results[j].Score
+	})
+
+	return results
+}
+
+// ScreenerService caches the screener results for the strategies,
+// the results are refreshed when they are older than the update interval.
+type ScreenerService struct {
+	Screener *Screener
+	Session  *ExchangeSession
+
+	// UpdateInterval is the cache ttl of the results, default to 1 hour
+	UpdateInterval time.Duration
+
+	mu        sync.Mutex
+	results   []ScreenerResult
+	updatedAt time.Time
+}
+
+func NewScreenerService(screener *Screener, session *ExchangeSession) *ScreenerService {
+	return &ScreenerService{
+		Screener:       screener,
+		Session:        session,
+		UpdateInterval: time.Hour,
+	}
+}
+
+func (s *ScreenerService) Results(ctx context.Context) ([]ScreenerResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.results != nil && time.Since(s.updatedAt) < s.UpdateInterval {
+		return s.results, nil
+	}
+
+	results, err := s.Screener.Screen(ctx, s.Session)
+	if err != nil {
+		return nil, err
+	}
+
+	s.results = results
+	s.updatedAt = time.Now()
+	return results, nil
+}
+
+// TopSymbols returns the top n symbols, strategies can use this to rotate into the most attractive symbols.
+func (s *ScreenerService) TopSymbols(ctx context.Context, n int) ([]string, error) {
+	results, err := s.Results(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var symbols []string
+	for i, result := range results {
+		if n > 0 && i >= n {
+			break
+		}
+
+		symbols = append(symbols, result.Symbol)
+	}
+
+	return symbols, nil
+}
@@ -0,0 +1,61 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestNewScreenerResult(t *testing.T) {
+	result := newScreenerResult("BTCUSDT", types.Ticker{
+		Volume: 100.0,
+		Last:   50000.0,
+		High:   52000.0,
+		Low:    48000.0,
+		Buy:    49990.0,
+		Sell:   50010.0,
+	})
+
+	assert.Equal(t, 5000000.0, result.Volume)
+	assert.InDelta(t, 0.08, result.Volatility, 1e-9)
+	assert.InDelta(t, 0.0004, result.Spread, 1e-9)
+}
+
+func TestRankScreenerResults(t *testing.T) {
+	results := []ScreenerResult{
+		{Symbol: "BTCUSDT", Volume: 5000000.0, Volatility: 0.02, Spread: 0.0001},
+		{Symbol: "ETHUSDT", Volume: 3000000.0, Volatility: 0.05, Spread: 0.0002},
+		{Symbol: "LINKUSDT", Volume: 200000.0, Volatility: 0.10, Spread: 0.0010},
+	}
+
+	ranked := rankScreenerResults(results, map[ScreenerCriterion]float64{
+		ScreenerCriterionVolume: 1.0,
+	})
+	assert.Equal(t, "BTCUSDT", ranked[0].Symbol)
+	assert.Equal(t, 1.0, ranked[0].Score)
+	assert.Equal(t, "LINKUSDT", ranked[2].Symbol)
+
+	ranked = rankScreenerResults(ranked, map[ScreenerCriterion]float64{
+		ScreenerCriterionVolatility: 2.0,
+		ScreenerCriterionSpread:     1.0,
+	})
+
+	// LINKUSDT: 2.0 * 1.0 + 1.0 * 0.0, ETHUSDT: 2.0 * 0.5 + 1.0 * 0.5, BTCUSDT: 2.0 * 0.0 + 1.0 * 1.0
+	assert.Equal(t, "LINKUSDT", ranked[0].Symbol)
+	assert.Equal(t, 2.0, ranked[0].Score)
+	assert.Equal(t, "ETHUSDT", ranked[1].Symbol)
+	assert.Equal(t, "BTCUSDT", ranked[2].Symbol)
+}
+
+func TestScreener_Validate(t *testing.T) {
+	screener := Screener{}
+	assert.Error(t, screener.Validate())
+
+	screener.Criteria = map[ScreenerCriterion]float64{"foo": 1.0}
+	assert.Error(t, screener.Validate())
+
+	screener.Criteria = map[ScreenerCriterion]float64{ScreenerCriterionFundingRate: 1.0}
+	assert.NoError(t, screener.Validate())
+}
@@ -3,12 +3,14 @@ package bbgo
 import (
 	"context"
 	"fmt"
+	"math"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/c9s/bbgo/pkg/cmd/cmdutil"
+	"github.com/c9s/bbgo/pkg/exchange/ccxt"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
-	"github.com/c9s/bbgo/pkg/indicator"
 	"github.com/c9s/bbgo/pkg/service"
 	"github.com/c9s/bbgo/pkg/types"
 	"github.com/c9s/bbgo/pkg/util"
@@ -27,110 +29,7 @@ func init() {
 	util.SetEnvVarBool("DEBUG_SMA", &debugSMA)
 }
 
-type StandardIndicatorSet struct {
-	Symbol string
-	// Standard indicators
-	// interval -> window
-	sma   map[types.IntervalWindow]*indicator.SMA
-	ewma  map[types.IntervalWindow]*indicator.EWMA
-	boll  map[types.IntervalWindow]*indicator.BOLL
-	stoch map[types.IntervalWindow]*indicator.STOCH
-
-	store *MarketDataStore
-}
-
-func NewStandardIndicatorSet(symbol string, store *MarketDataStore) *StandardIndicatorSet {
-	set := &StandardIndicatorSet{
-		Symbol: symbol,
-		sma:    make(map[types.IntervalWindow]*indicator.SMA),
-		ewma:   make(map[types.IntervalWindow]*indicator.EWMA),
-		boll:   make(map[types.IntervalWindow]*indicator.BOLL),
-		stoch:  make(map[types.IntervalWindow]*indicator.STOCH),
-		store:  store,
-	}
-
-	// let us pre-defined commonly used intervals
-	for interval := range types.SupportedIntervals {
-		for _, window := range []int{7, 25, 99} {
-			iw := types.IntervalWindow{Interval: interval, Window: window}
-			set.sma[iw] = &indicator.SMA{IntervalWindow: iw}
-			set.sma[iw].Bind(store)
-			if debugSMA {
-				set.sma[iw].OnUpdate(func(value float64) {
-					log.Infof("%s SMA %s: %f", symbol, iw.String(), value)
-				})
-			}
-
-			set.ewma[iw] = &indicator.EWMA{IntervalWindow: iw}
-			set.ewma[iw].Bind(store)
-
-			// if debug EWMA is enabled, we add the debug handler
-			if debugEWMA {
-				set.ewma[iw].OnUpdate(func(value float64) {
-					log.Infof("%s EWMA %s: %f", symbol, iw.String(), value)
-				})
-			}
-
-		}
-
-		// setup boll indicator, we may refactor boll indicator by subscribing SMA indicator,
-		// however, since general used BOLLINGER band use window 21, which is not in the existing SMA indicator sets.
-		// Pull out the bandwidth configuration as the boll Key
-		iw := types.IntervalWindow{Interval: interval, Window: 21}
-		set.boll[iw] = &indicator.BOLL{IntervalWindow: iw, K: 2.0}
-		set.boll[iw].Bind(store)
-	}
-
-	return set
-}
-
-// BOLL returns the bollinger band indicator of the given interval and the window,
-// Please note that the K for std dev is fixed and defaults to 2.0
-func (set *StandardIndicatorSet) BOLL(iw types.IntervalWindow, bandWidth float64) *indicator.BOLL {
-	inc, ok := set.boll[iw]
-	if !ok {
-		inc = &indicator.BOLL{IntervalWindow: iw, K: bandWidth}
-		inc.Bind(set.store)
-		set.boll[iw] = inc
-	}
-
-	return inc
-}
-
-// SMA returns the simple moving average indicator of the given interval and the window size.
-func (set *StandardIndicatorSet) SMA(iw types.IntervalWindow) *indicator.SMA {
-	inc, ok := set.sma[iw]
-	if !ok {
-		inc = &indicator.SMA{IntervalWindow: iw}
-		inc.Bind(set.store)
-		set.sma[iw] = inc
-	}
-
-	return inc
-}
-
-// EWMA returns the exponential weighed moving average indicator of the given interval and the window size.
-func (set *StandardIndicatorSet) EWMA(iw types.IntervalWindow) *indicator.EWMA {
-	inc, ok := set.ewma[iw]
-	if !ok {
-		inc = &indicator.EWMA{IntervalWindow: iw}
-		inc.Bind(set.store)
-		set.ewma[iw] = inc
-	}
-
-	return inc
-}
-
-func (set *StandardIndicatorSet) STOCH(iw types.IntervalWindow) *indicator.STOCH {
-	inc, ok := set.stoch[iw]
-	if !ok {
-		inc = &indicator.STOCH{IntervalWindow: iw}
-		inc.Bind(set.store)
-		set.stoch[iw] = inc
-	}
-
-	return inc
-}
+//go:generate callbackgen -type ExchangeSession
 
 // ExchangeSession presents the exchange connection Session
 // It also maintains and collects the data returned from the stream.
@@ -152,10 +51,32 @@ type ExchangeSession struct {
 	SubAccount   string             `json:"subAccount,omitempty" yaml:"subAccount,omitempty"`
 
 	// Withdrawal is used for enabling withdrawal functions
-	Withdrawal   bool             `json:"withdrawal,omitempty" yaml:"withdrawal,omitempty"`
+	Withdrawal bool `json:"withdrawal,omitempty" yaml:"withdrawal,omitempty"`
+
+	// WithdrawalAddresses are the whitelisted withdrawal addresses by the asset
+	WithdrawalAddresses map[string][]WithdrawalAddress `json:"withdrawalAddresses,omitempty" yaml:"withdrawalAddresses,omitempty"`
+
 	MakerFeeRate fixedpoint.Value `json:"makerFeeRate,omitempty" yaml:"makerFeeRate,omitempty"`
 	TakerFeeRate fixedpoint.Value `json:"takerFeeRate,omitempty" yaml:"takerFeeRate,omitempty"`
 
+	// CCXT is the gateway config of the session with the ccxt bridge exchange
+	CCXT *ccxt.Config `json:"ccxt,omitempty" yaml:"ccxt,omitempty"`
+
+	// Testnet switches the exchange REST and websocket endpoints to the testnet (sandbox) endpoints
+	Testnet bool `json:"testnet,omitempty" yaml:"testnet,omitempty"`
+
+	// WebSocketCompression enables the permessage-deflate compression of the websocket streams,
+	// this reduces the bandwidth of the full-depth subscriptions on many symbols.
+	WebSocketCompression bool `json:"webSocketCompression,omitempty" yaml:"webSocketCompression,omitempty"`
+
+	// IsolateSymbols dispatches the stream events of each symbol on its own worker goroutine,
+	// so that a slow handler of one symbol does not delay the events of the other symbols.
+	IsolateSymbols bool `json:"isolateSymbols,omitempty" yaml:"isolateSymbols,omitempty"`
+
+	// SequenceOrderUpdates drops the duplicated and the out-of-order updates of the user data stream,
+	// for example, the order updates re-sent by the exchange after a reconnect.
+	SequenceOrderUpdates bool `json:"sequenceOrderUpdates,omitempty" yaml:"sequenceOrderUpdates,omitempty"`
+
 	PublicOnly           bool   `json:"publicOnly,omitempty" yaml:"publicOnly"`
 	Margin               bool   `json:"margin,omitempty" yaml:"margin"`
 	IsolatedMargin       bool   `json:"isolatedMargin,omitempty" yaml:"isolatedMargin,omitempty"`
@@ -165,6 +86,25 @@ type ExchangeSession struct {
 	IsolatedFutures       bool   `json:"isolatedFutures,omitempty" yaml:"isolatedFutures,omitempty"`
 	IsolatedFuturesSymbol string `json:"isolatedFuturesSymbol,omitempty" yaml:"isolatedFuturesSymbol,omitempty"`
 
+	// Universe is the symbol universe selector config of the session, used by sync and the multi-symbol strategies
+	Universe *UniverseConfig `json:"universe,omitempty" yaml:"universe,omitempty"`
+
+	// SymbolGuard rejects the orders of the unintended symbols and the oversized orders in the session order executor
+	SymbolGuard *SymbolGuard `json:"symbolGuard,omitempty" yaml:"symbolGuard,omitempty"`
+
+	// SanityCheck rejects the orders priced too far away from the market or larger than the notional cap (fat-finger protection)
+	SanityCheck *OrderSanityCheck `json:"sanityCheck,omitempty" yaml:"sanityCheck,omitempty"`
+
+	// QuoteConversion converts the other quote currencies into the quote currency of the buy orders when the balance is not enough
+	QuoteConversion *QuoteConversion `json:"quoteConversion,omitempty" yaml:"quoteConversion,omitempty"`
+
+	// MarginMonitor monitors the margin level of the margin session and de-risks the account before the liquidation
+	MarginMonitor *MarginMonitorConfig `json:"marginMonitor,omitempty" yaml:"marginMonitor,omitempty"`
+
+	// FuturesLeverage is the leverage map (symbol -> leverage) that will be applied to the futures account
+	FuturesLeverage     map[string]int     `json:"futuresLeverage,omitempty" yaml:"futuresLeverage,omitempty"`
+	FuturesPositionMode types.PositionMode `json:"futuresPositionMode,omitempty" yaml:"futuresPositionMode,omitempty"`
+
 	// ---------------------------
 	// Runtime fields
 	// ---------------------------
@@ -188,8 +128,9 @@ type ExchangeSession struct {
 	// map: symbol -> []trade
 	Trades map[string]*types.TradeSlice `json:"-" yaml:"-"`
 
-	// markets defines market configuration of a symbol
-	markets map[string]types.Market
+	// markets defines market configuration of a symbol, the markets are replaced as a whole when they are refreshed
+	markets      map[string]types.Market
+	marketsMutex sync.RWMutex
 
 	// orderBooks stores the streaming order book
 	orderBooks map[string]*types.StreamOrderBook
@@ -200,16 +141,32 @@ type ExchangeSession struct {
 	lastPrices         map[string]float64
 	lastPriceUpdatedAt time.Time
 
+	// feeTier is the queried fee tier of the account
+	feeTier      *types.FeeTier
+	feeTierMutex sync.Mutex
+
+	// bootstrapping is set to 1 while the historical data is being fed
+	bootstrapping int32
+
 	// marketDataStores contains the market data store of each market
 	marketDataStores map[string]*MarketDataStore
 
 	positions map[string]*types.Position
 
+	// positionUpdateCallbacks are called when the position of the symbol is updated by the trade
+	positionUpdateCallbacks []func(position *types.Position)
+
 	// standard indicators of each market
 	standardIndicatorSets map[string]*StandardIndicatorSet
 
 	orderStores map[string]*OrderStore
 
+	// currencyConverter converts the order notional into the notional currency of the symbol guard
+	currencyConverter *CurrencyConverter
+
+	// marginMonitor is created from the margin monitor config in Init
+	marginMonitor *MarginMonitor
+
 	usedSymbols        map[string]struct{}
 	initializedSymbols map[string]struct{}
 
@@ -267,25 +224,62 @@ func (session *ExchangeSession) Init(ctx context.Context, environ *Environment)
 
 	var log = log.WithField("session", session.Name)
 
-	// load markets first
-
-	var disableMarketsCache = false
-	var markets types.MarketMap
+	// load markets first, the markets could be set before the initialization, e.g., by the strategy test harness
 	var err error
-	if util.SetEnvVarBool("DISABLE_MARKETS_CACHE", &disableMarketsCache); disableMarketsCache {
-		markets, err = session.Exchange.QueryMarkets(ctx)
-	} else {
-		markets, err = LoadExchangeMarketsWithCache(ctx, session.Exchange)
-		if err != nil {
-			return err
+	if len(session.Markets()) == 0 {
+		var disableMarketsCache = false
+		var markets types.MarketMap
+		if util.SetEnvVarBool("DISABLE_MARKETS_CACHE", &disableMarketsCache); disableMarketsCache {
+			markets, err = session.Exchange.QueryMarkets(ctx)
+		} else {
+			markets, err = LoadExchangeMarketsWithCache(ctx, session.Exchange)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(markets) == 0 {
+			return fmt.Errorf("market config should not be empty")
+		}
+
+		session.SetMarkets(markets)
+	}
+
+	if session.SymbolGuard != nil {
+		if err := session.SymbolGuard.Validate(); err != nil {
+			return fmt.Errorf("invalid symbol guard of session %s: %w", session.Name, err)
+		}
+
+		for _, symbol := range session.SymbolGuard.AllowedSymbols {
+			if _, ok := session.Market(strings.ToUpper(symbol)); !ok {
+				log.Warnf("allowed symbol %s of the symbol guard is not found in the markets", symbol)
+			}
 		}
 	}
 
-	if len(markets) == 0 {
-		return fmt.Errorf("market config should not be empty")
+	if session.SanityCheck != nil {
+		if err := session.SanityCheck.Validate(); err != nil {
+			return fmt.Errorf("invalid sanity check of session %s: %w", session.Name, err)
+		}
 	}
 
-	session.markets = markets
+	if session.QuoteConversion != nil {
+		if err := session.QuoteConversion.Validate(); err != nil {
+			return fmt.Errorf("invalid quote conversion of session %s: %w", session.Name, err)
+		}
+	}
+
+	if session.MarginMonitor != nil {
+		if err := session.initMarginMonitor(); err != nil {
+			return fmt.Errorf("invalid margin monitor of session %s: %w", session.Name, err)
+		}
+	}
+
+	if session.Futures {
+		if err := session.configureFutures(ctx); err != nil {
+			return err
+		}
+	}
 
 	// query and initialize the balances
 	log.Infof("querying balances from session %s...", session.Name)
@@ -319,6 +313,10 @@ func (session *ExchangeSession) Init(ctx context.Context, environ *Environment)
 	}
 
 	session.MarketDataStream.OnKLineClosed(func(kline types.KLine) {
+		if session.IsBootstrapping() {
+			return
+		}
+
 		log.WithField("marketData", "kline").Infof("kline closed: %+v", kline)
 	})
 
@@ -362,7 +360,7 @@ func (session *ExchangeSession) initSymbol(ctx context.Context, environ *Environ
 		return nil
 	}
 
-	market, ok := session.markets[symbol]
+	market, ok := session.Market(symbol)
 	if !ok {
 		return fmt.Errorf("market %s is not defined", symbol)
 	}
@@ -392,13 +390,12 @@ func (session *ExchangeSession) initSymbol(ctx context.Context, environ *Environ
 		session.Trades[symbol].Append(trade)
 	})
 
-	position := &types.Position{
-		Symbol:        symbol,
-		BaseCurrency:  market.BaseCurrency,
-		QuoteCurrency: market.QuoteCurrency,
-	}
+	position := types.NewPositionFromMarket(market)
 	position.AddTrades(trades)
-	position.BindStream(session.UserDataStream)
+	if lastPrice, ok := session.lastPrices[symbol]; ok {
+		position.UpdateMarkPrice(fixedpoint.NewFromFloat(lastPrice))
+	}
+	session.bindPosition(position)
 	session.positions[symbol] = position
 
 	orderStore := NewOrderStore(symbol)
@@ -485,16 +482,12 @@ func (session *ExchangeSession) Position(symbol string) (pos *types.Position, ok
 		return pos, ok
 	}
 
-	market, ok := session.markets[symbol]
+	market, ok := session.Market(symbol)
 	if !ok {
 		return nil, false
 	}
 
-	pos = &types.Position{
-		Symbol:        symbol,
-		BaseCurrency:  market.BaseCurrency,
-		QuoteCurrency: market.QuoteCurrency,
-	}
+	pos = types.NewPositionFromMarket(market)
 	ok = true
 	session.positions[symbol] = pos
 	return pos, ok
@@ -504,6 +497,38 @@ func (session *ExchangeSession) Positions() map[string]*types.Position {
 	return session.positions
 }
 
+// bindPosition updates the position by the trades and the closed klines of the position symbol,
+// the position update callbacks are called after the trade is added to the position.
+func (session *ExchangeSession) bindPosition(position *types.Position) {
+	position.BindStream(session.UserDataStream)
+	session.UserDataStream.OnTradeUpdate(func(trade types.Trade) {
+		if trade.Symbol == position.Symbol {
+			session.EmitPositionUpdate(position)
+		}
+	})
+
+	session.MarketDataStream.OnKLineClosed(func(kline types.KLine) {
+		if kline.Symbol == position.Symbol {
+			position.UpdateMarkPrice(fixedpoint.NewFromFloat(kline.Close))
+		}
+	})
+}
+
+// UnrealizedProfit returns the unrealized profit of the symbol position at the last price
+func (session *ExchangeSession) UnrealizedProfit(symbol string) (fixedpoint.Value, bool) {
+	position, ok := session.positions[symbol]
+	if !ok {
+		return 0, false
+	}
+
+	lastPrice, ok := session.LastPrice(symbol)
+	if !ok {
+		return 0, false
+	}
+
+	return position.UnrealizedProfitAt(fixedpoint.NewFromFloat(lastPrice)), true
+}
+
 // MarketDataStore returns the market data store of a symbol
 func (session *ExchangeSession) MarketDataStore(symbol string) (s *MarketDataStore, ok bool) {
 	s, ok = session.marketDataStores[symbol]
@@ -531,14 +556,181 @@ func (session *ExchangeSession) LastPrices() map[string]float64 {
 }
 
 func (session *ExchangeSession) Market(symbol string) (market types.Market, ok bool) {
+	session.marketsMutex.RLock()
 	market, ok = session.markets[symbol]
+	session.marketsMutex.RUnlock()
 	return market, ok
 }
 
 func (session *ExchangeSession) Markets() map[string]types.Market {
+	session.marketsMutex.RLock()
+	defer session.marketsMutex.RUnlock()
 	return session.markets
 }
 
+// SetMarkets sets the markets of the session, the markets are not loaded from the exchange in Init when they are set
+func (session *ExchangeSession) SetMarkets(markets types.MarketMap) {
+	session.marketsMutex.Lock()
+	session.markets = markets
+	session.marketsMutex.Unlock()
+}
+
+// Capabilities returns the capabilities of the session exchange
+func (session *ExchangeSession) Capabilities() types.ExchangeCapabilities {
+	return types.GetExchangeCapabilities(session.Exchange)
+}
+
+// QueryDepositAddress queries the deposit address of the asset, the empty network means the default network of the asset
+func (session *ExchangeSession) QueryDepositAddress(ctx context.Context, asset, network string) (*types.DepositAddress, error) {
+	service, ok := session.Exchange.(types.ExchangeDepositAddressService)
+	if !ok || !session.Capabilities().DepositAddress {
+		return nil, fmt.Errorf("exchange %s does not support the deposit address query", session.ExchangeName)
+	}
+
+	address, err := service.QueryDepositAddress(ctx, strings.ToUpper(asset), network)
+	if err != nil {
+		return nil, fmt.Errorf("can not query the %s deposit address of session %s: %w", asset, session.Name, err)
+	}
+
+	return address, nil
+}
+
+// CancelAllOrders cancels all the open orders of the symbol, it uses the cancel-by-symbol endpoint if the exchange
+// supports it, otherwise the open orders are queried and canceled in one CancelOrders call
+func (session *ExchangeSession) CancelAllOrders(ctx context.Context, symbol string) ([]types.Order, error) {
+	if service, ok := session.Exchange.(types.ExchangeOrderCancelService); ok {
+		return service.CancelOrdersBySymbol(ctx, symbol)
+	}
+
+	openOrders, err := session.Exchange.QueryOpenOrders(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(openOrders) == 0 {
+		return nil, nil
+	}
+
+	return openOrders, session.Exchange.CancelOrders(ctx, openOrders...)
+}
+
+// openOrderSymbols returns the given symbols, or the symbols selected by FindPossibleSymbols if no symbol is given
+func (session *ExchangeSession) openOrderSymbols(ctx context.Context, symbols []string) ([]string, error) {
+	if len(symbols) > 0 {
+		return symbols, nil
+	}
+
+	if session.Account == nil {
+		return nil, fmt.Errorf("the account of session %s is not loaded, the symbols can not be selected", session.Name)
+	}
+
+	return session.FindPossibleSymbols(ctx)
+}
+
+// QueryOpenOrders queries the open orders of the symbols, the symbols are selected by the universe of the session
+// if no symbol is given, since the exchange api queries the open orders by the symbol
+func (session *ExchangeSession) QueryOpenOrders(ctx context.Context, symbols ...string) ([]types.Order, error) {
+	symbols, err := session.openOrderSymbols(ctx, symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []types.Order
+	for _, symbol := range symbols {
+		openOrders, err := session.Exchange.QueryOpenOrders(ctx, symbol)
+		if err != nil {
+			return orders, fmt.Errorf("can not query the %s open orders of session %s: %w", symbol, session.Name, err)
+		}
+
+		orders = append(orders, openOrders...)
+	}
+
+	return orders, nil
+}
+
+// CancelOpenOrders cancels the open orders of the symbols like CancelAllOrders, the symbols are selected by the
+// universe of the session if no symbol is given
+func (session *ExchangeSession) CancelOpenOrders(ctx context.Context, symbols ...string) ([]types.Order, error) {
+	symbols, err := session.openOrderSymbols(ctx, symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	var canceled []types.Order
+	for _, symbol := range symbols {
+		orders, err := session.CancelAllOrders(ctx, symbol)
+		if err != nil {
+			return canceled, fmt.Errorf("can not cancel the %s orders of session %s: %w", symbol, session.Name, err)
+		}
+
+		canceled = append(canceled, orders...)
+	}
+
+	return canceled, nil
+}
+
+// CancelOrderByID cancels the open order by the order id, the order is looked up from the open orders of the symbols,
+// so that the order is canceled with its symbol
+func (session *ExchangeSession) CancelOrderByID(ctx context.Context, orderID uint64, symbols ...string) (types.Order, error) {
+	orders, err := session.QueryOpenOrders(ctx, symbols...)
+	if err != nil {
+		return types.Order{}, err
+	}
+
+	for _, order := range orders {
+		if order.OrderID == orderID {
+			return order, session.Exchange.CancelOrders(ctx, order)
+		}
+	}
+
+	return types.Order{}, fmt.Errorf("open order %d not found in session %s", orderID, session.Name)
+}
+
+// ClosePosition closes the percentage of the session position by a market order, no order is submitted if the quantity
+// is less than the minimal quantity. The order is submitted to the exchange directly, so that the position can still be
+// closed while the order executors are halted, e.g., by the kill switch.
+func (session *ExchangeSession) ClosePosition(ctx context.Context, symbol string, percentage float64) (types.OrderSlice, error) {
+	if percentage <= 0 {
+		percentage = 1.0
+	} else if percentage > 1.0 {
+		return nil, fmt.Errorf("invalid close percentage %f, it should be in (0, 1]", percentage)
+	}
+
+	position, ok := session.positions[symbol]
+	if !ok {
+		return nil, fmt.Errorf("position of %s not found in session %s", symbol, session.Name)
+	}
+
+	market, ok := session.Market(symbol)
+	if !ok {
+		return nil, fmt.Errorf("market %s not found in session %s", symbol, session.Name)
+	}
+
+	base := position.Snapshot().Base.Float64()
+	quantity := math.Abs(base) * percentage
+	if quantity == 0 || quantity < market.MinQuantity {
+		return nil, nil
+	}
+
+	side := types.SideTypeSell
+	if base < 0 {
+		side = types.SideTypeBuy
+	}
+
+	order, err := session.FormatOrder(types.SubmitOrder{
+		Symbol:   symbol,
+		Side:     side,
+		Type:     types.OrderTypeMarket,
+		Quantity: quantity,
+		Market:   market,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return session.Exchange.SubmitOrders(ctx, order)
+}
+
 func (session *ExchangeSession) OrderStore(symbol string) (store *OrderStore, ok bool) {
 	store, ok = session.orderStores[symbol]
 	return store, ok
@@ -574,9 +766,14 @@ func (session *ExchangeSession) FormatOrder(order types.SubmitOrder) (types.Subm
 
 	order.Market = market
 
+	if err := order.RoundingPolicy.Validate(); err != nil {
+		return order, err
+	}
+
 	switch order.Type {
 	case types.OrderTypeStopMarket, types.OrderTypeStopLimit:
-		order.StopPriceString = market.FormatPrice(order.StopPrice)
+		order.StopPrice = market.RoundPrice(order.StopPrice, order.Side, order.RoundingPolicy)
+		order.StopPriceString = market.FormatRoundedPrice(order.StopPrice)
 
 	}
 
@@ -586,14 +783,32 @@ func (session *ExchangeSession) FormatOrder(order types.SubmitOrder) (types.Subm
 		order.PriceString = ""
 
 	default:
-		order.PriceString = market.FormatPrice(order.Price)
+		order.Price = market.RoundPrice(order.Price, order.Side, order.RoundingPolicy)
+		order.PriceString = market.FormatRoundedPrice(order.Price)
 
 	}
 
-	order.QuantityString = market.FormatQuantity(order.Quantity)
+	order.Quantity = market.RoundQuantity(order.Quantity, order.RoundingPolicy)
+	order.QuantityString = market.FormatRoundedQuantity(order.Quantity)
 	return order, nil
 }
 
+// ValidateOrder formats the order by the market precision and verifies it against the exchange capabilities and the
+// market filters, see CheckMarketFilters.
+func (session *ExchangeSession) ValidateOrder(order types.SubmitOrder) (types.SubmitOrder, error) {
+	order, err := session.FormatOrder(order)
+	if err != nil {
+		return order, err
+	}
+
+	if err := checkOrderCapabilities(session.Capabilities(), order); err != nil {
+		return order, fmt.Errorf("%s order of %s is not supported by exchange %s: %w", order.Type, order.Symbol, session.ExchangeName, err)
+	}
+
+	referencePrice, _ := session.ReferencePrice(order.Symbol)
+	return order, CheckMarketFilters(order, referencePrice)
+}
+
 func (session *ExchangeSession) UpdatePrices(ctx context.Context) (err error) {
 	if session.lastPriceUpdatedAt.After(time.Now().Add(-time.Hour)) {
 		return nil
@@ -621,7 +836,9 @@ func (session *ExchangeSession) UpdatePrices(ctx context.Context) (err error) {
 	return err
 }
 
-func (session *ExchangeSession) FindPossibleSymbols() (symbols []string, err error) {
+// FindPossibleSymbols selects the symbols of the session by the universe config,
+// when the universe is not configured, the DefaultUniverse is used.
+func (session *ExchangeSession) FindPossibleSymbols(ctx context.Context) (symbols []string, err error) {
 	// If the session is an isolated margin session, there will be only the isolated margin symbol
 	if session.Margin && session.IsolatedMargin {
 		return []string{
@@ -629,44 +846,66 @@ func (session *ExchangeSession) FindPossibleSymbols() (symbols []string, err err
 		}, nil
 	}
 
-	var balances = session.Account.Balances()
-	var fiatAssets []string
+	var selector UniverseSelector = &DefaultUniverse
+	if session.Universe != nil {
+		selector = session.Universe
+	}
 
-	for _, currency := range types.FiatCurrencies {
-		if balance, ok := balances[currency]; ok && balance.Total() > 0 {
-			fiatAssets = append(fiatAssets, currency)
-		}
+	return selector.SelectSymbols(ctx, session)
+}
+
+// configureFutures applies the position mode and the leverage settings to the futures account
+func (session *ExchangeSession) configureFutures(ctx context.Context) error {
+	if len(session.FuturesPositionMode) == 0 && len(session.FuturesLeverage) == 0 {
+		return nil
 	}
 
-	var symbolMap = map[string]struct{}{}
+	service, ok := session.Exchange.(types.FuturesService)
+	if !ok {
+		return fmt.Errorf("exchange %s does not support futures account configuration", session.ExchangeName)
+	}
 
-	for _, market := range session.Markets() {
-		// ignore the markets that are not fiat currency markets
-		if !util.StringSliceContains(fiatAssets, market.QuoteCurrency) {
-			continue
+	switch session.FuturesPositionMode {
+	case "":
+	case types.PositionModeOneWay, types.PositionModeHedge:
+		log.Infof("setting futures position mode to %s", session.FuturesPositionMode)
+		if err := service.SetPositionMode(ctx, session.FuturesPositionMode); err != nil {
+			return err
 		}
 
-		// ignore the asset that we don't have in the balance sheet
-		balance, hasAsset := balances[market.BaseCurrency]
-		if !hasAsset || balance.Total() == 0 {
-			continue
+	default:
+		return fmt.Errorf("unsupported futures position mode: %s", session.FuturesPositionMode)
+	}
+
+	for symbol, leverage := range session.FuturesLeverage {
+		if _, ok := session.Market(symbol); !ok {
+			return fmt.Errorf("futures market %s is not found", symbol)
 		}
 
-		symbolMap[market.Symbol] = struct{}{}
-	}
+		if leverage <= 0 {
+			return fmt.Errorf("invalid futures leverage %d of %s", leverage, symbol)
+		}
 
-	for s := range symbolMap {
-		symbols = append(symbols, s)
+		log.Infof("setting futures leverage of %s to %dx", symbol, leverage)
+		if err := service.SetLeverage(ctx, symbol, leverage); err != nil {
+			return err
+		}
 	}
 
-	return symbols, nil
+	return nil
 }
 
 func InitExchangeSession(name string, session *ExchangeSession) error {
 	var err error
 	var exchangeName = session.ExchangeName
 	var exchange types.Exchange
-	if session.Key != "" && session.Secret != "" {
+	if exchangeName == types.ExchangeCCXT {
+		if session.CCXT == nil {
+			return fmt.Errorf("ccxt gateway config is required for the ccxt session %s", name)
+		}
+
+		exchange, err = cmdutil.NewCCXTExchange(*session.CCXT, session.Key, session.Secret, session.EnvVarPrefix)
+	} else if session.Key != "" && session.Secret != "" {
 		if !session.PublicOnly {
 			if len(session.Key) == 0 || len(session.Secret) == 0 {
 				return fmt.Errorf("can not create exchange %s: empty key or secret", exchangeName)
@@ -683,6 +922,15 @@ func InitExchangeSession(name string, session *ExchangeSession) error {
 	}
 
 	// configure exchange
+	if session.Testnet {
+		testnetExchange, ok := exchange.(types.TestnetExchange)
+		if !ok {
+			return fmt.Errorf("exchange %s does not support testnet", exchangeName)
+		}
+
+		testnetExchange.UseTestnet()
+	}
+
 	if session.Margin {
 		marginExchange, ok := exchange.(types.MarginExchange)
 		if !ok {
@@ -720,6 +968,27 @@ func InitExchangeSession(name string, session *ExchangeSession) error {
 	session.MarketDataStream = exchange.NewStream()
 	session.MarketDataStream.SetPublicOnly()
 
+	if session.WebSocketCompression {
+		for _, stream := range []types.Stream{session.UserDataStream, session.MarketDataStream} {
+			setter, ok := stream.(types.CompressionSetter)
+			if !ok {
+				return fmt.Errorf("the stream of exchange %s does not support websocket compression", exchangeName)
+			}
+
+			setter.SetCompression(true)
+		}
+	}
+
+	// sequence the order updates before they are dispatched, so that the order of the updates is kept per symbol
+	if session.SequenceOrderUpdates {
+		session.UserDataStream = types.NewOrderSequenceStream(session.UserDataStream)
+	}
+
+	if session.IsolateSymbols {
+		session.UserDataStream = types.NewSymbolDispatchStream(session.UserDataStream)
+		session.MarketDataStream = types.NewSymbolDispatchStream(session.MarketDataStream)
+	}
+
 	// pointer fields
 	session.Subscriptions = make(map[types.Subscription]types.Subscription)
 	session.Account = &types.Account{}
@@ -0,0 +1,169 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestExchangeSession_bindPosition(t *testing.T) {
+	userDataStream := &bootstrapTestStream{StandardStream: types.NewStandardStream()}
+	marketDataStream := &bootstrapTestStream{StandardStream: types.NewStandardStream()}
+	session := &ExchangeSession{
+		Name:             "binance",
+		UserDataStream:   userDataStream,
+		MarketDataStream: marketDataStream,
+		positions:        make(map[string]*types.Position),
+		lastPrices:       make(map[string]float64),
+	}
+
+	position := types.NewPositionFromMarket(types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"})
+	session.bindPosition(position)
+	session.positions["BTCUSDT"] = position
+
+	var updates []*types.Position
+	session.OnPositionUpdate(func(position *types.Position) {
+		updates = append(updates, position.Snapshot())
+	})
+
+	userDataStream.EmitTradeUpdate(types.Trade{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Price: 100.0, Quantity: 2.0, QuoteQuantity: 200.0})
+	userDataStream.EmitTradeUpdate(types.Trade{Symbol: "ETHUSDT", Side: types.SideTypeBuy, Price: 10.0, Quantity: 1.0, QuoteQuantity: 10.0})
+	userDataStream.EmitTradeUpdate(types.Trade{Symbol: "BTCUSDT", Side: types.SideTypeSell, Price: 110.0, Quantity: 1.0, QuoteQuantity: 110.0})
+
+	// the trades of the other symbols are ignored
+	if assert.Len(t, updates, 2) {
+		assert.Equal(t, 2.0, updates[0].Base.Float64())
+		assert.Equal(t, 1.0, updates[1].Base.Float64())
+		assert.Equal(t, 10.0, updates[1].RealizedProfit.Float64())
+	}
+
+	marketDataStream.EmitKLineClosed(types.KLine{Symbol: "BTCUSDT", Close: 120.0})
+	assert.Equal(t, 120.0, position.MarkPrice.Float64())
+	assert.Equal(t, 20.0, position.UnrealizedSpotProfit().Float64())
+
+	session.lastPrices["BTCUSDT"] = 90.0
+	profit, ok := session.UnrealizedProfit("BTCUSDT")
+	assert.True(t, ok)
+	assert.Equal(t, fixedpoint.NewFromFloat(-10.0), profit)
+
+	_, ok = session.UnrealizedProfit("ETHUSDT")
+	assert.False(t, ok)
+}
+
+type cancelBySymbolTestExchange struct {
+	killSwitchTestExchange
+
+	canceledSymbols []string
+}
+
+func (e *cancelBySymbolTestExchange) CancelOrdersBySymbol(ctx context.Context, symbol string) ([]types.Order, error) {
+	e.canceledSymbols = append(e.canceledSymbols, symbol)
+	return e.QueryOpenOrders(ctx, symbol)
+}
+
+func TestExchangeSession_CancelAllOrders(t *testing.T) {
+	openOrders := []types.Order{
+		{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy}, OrderID: 1},
+		{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell}, OrderID: 2},
+		{SubmitOrder: types.SubmitOrder{Symbol: "ETHUSDT", Side: types.SideTypeSell}, OrderID: 3},
+	}
+
+	// the open orders are queried and canceled if the exchange can not cancel the orders by symbol
+	exchange := &killSwitchTestExchange{openOrders: openOrders}
+	session := &ExchangeSession{Name: "binance", Exchange: exchange}
+	canceledOrders, err := session.CancelAllOrders(context.Background(), "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Len(t, canceledOrders, 2)
+	assert.Len(t, exchange.canceledOrders, 2)
+
+	batchExchange := &cancelBySymbolTestExchange{killSwitchTestExchange: killSwitchTestExchange{openOrders: openOrders}}
+	session.Exchange = batchExchange
+	canceledOrders, err = session.CancelAllOrders(context.Background(), "ETHUSDT")
+	assert.NoError(t, err)
+	assert.Len(t, canceledOrders, 1)
+	assert.Equal(t, []string{"ETHUSDT"}, batchExchange.canceledSymbols)
+	assert.Len(t, batchExchange.canceledOrders, 0, "the orders are canceled by the cancel-by-symbol endpoint")
+}
+
+func TestExchangeSession_OpenOrders(t *testing.T) {
+	ctx := context.Background()
+	exchange := &killSwitchTestExchange{openOrders: []types.Order{
+		{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy}, OrderID: 1},
+		{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell}, OrderID: 2},
+		{SubmitOrder: types.SubmitOrder{Symbol: "ETHUSDT", Side: types.SideTypeSell}, OrderID: 3},
+	}}
+
+	session := newKillSwitchTestSession(exchange)
+
+	_, err := session.QueryOpenOrders(ctx)
+	assert.Error(t, err, "the symbols can not be selected without the account")
+
+	orders, err := session.QueryOpenOrders(ctx, "BTCUSDT", "ETHUSDT")
+	assert.NoError(t, err)
+	assert.Len(t, orders, 3)
+
+	// the symbols are selected by the default universe, which requires both the base and the quote balances
+	session.Account = types.NewAccount()
+	session.Account.UpdateBalances(types.BalanceMap{
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(0.1)},
+		"USDT": {Currency: "USDT", Locked: fixedpoint.NewFromFloat(100.0)},
+	})
+
+	orders, err = session.QueryOpenOrders(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, orders, 2)
+
+	order, err := session.CancelOrderByID(ctx, 3, "ETHUSDT")
+	assert.NoError(t, err)
+	assert.Equal(t, "ETHUSDT", order.Symbol)
+
+	_, err = session.CancelOrderByID(ctx, 3)
+	assert.Error(t, err, "order 3 is not in the selected symbols")
+
+	canceledOrders, err := session.CancelOpenOrders(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, canceledOrders, 2)
+	assert.Len(t, exchange.canceledOrders, 3)
+}
+
+func TestExchangeSession_ValidateOrder(t *testing.T) {
+	session := newKillSwitchTestSession(&killSwitchTestExchange{})
+	market := session.markets["BTCUSDT"]
+	market.MinNotional = 10.0
+	session.markets["BTCUSDT"] = market
+
+	order, err := session.ValidateOrder(types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimit,
+		Price:    10000.123,
+		Quantity: 0.00123,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "10000.12", order.PriceString)
+	assert.Equal(t, "0.0012", order.QuantityString)
+
+	_, err = session.ValidateOrder(types.SubmitOrder{Symbol: "ETHUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 100.0, Quantity: 1.0})
+	assert.Error(t, err, "market is not defined")
+
+	_, err = session.ValidateOrder(types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 10000.0, Quantity: 0.00001})
+	assert.Error(t, err, "quantity is less than the minimal quantity")
+
+	_, err = session.ValidateOrder(types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Quantity: 0.01})
+	assert.Error(t, err, "price is required for the limit orders")
+
+	_, err = session.ValidateOrder(types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeLimit, Price: 1000.0, Quantity: 0.005})
+	assert.Error(t, err, "notional is less than the minimal notional")
+
+	// the notional of the market order is estimated by the last price
+	_, err = session.ValidateOrder(types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeMarket, Quantity: 0.0005})
+	assert.Error(t, err)
+
+	order, err = session.ValidateOrder(types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeMarket, Price: 1.0, Quantity: 0.01})
+	assert.NoError(t, err)
+	assert.Equal(t, "", order.PriceString)
+}
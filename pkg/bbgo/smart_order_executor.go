@@ -0,0 +1,516 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+const defaultSmartRetryInterval = time.Second
+
+const defaultSmartCancelTimeout = 3 * time.Second
+
+const defaultSmartMaxRetries = 3
+
+// transientErrorPatterns are the lower case error messages of the errors that happen before the order request is
+// accepted by the exchange, the timeouts, the dropped connections and the gateway errors are not included since the
+// order could be placed before the error, and retrying them places the order twice.
+var transientErrorPatterns = []string{
+	"connection refused",
+	"no such host",
+	"too many requests",
+	"429",
+}
+
+// isTransientSubmitError returns true if the submit error is worth retrying
+func isTransientSubmitError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	// the order request is not sent if the connection can not be established
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, pattern := range transientErrorPatterns {
+		if strings.Contains(msg, pattern) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SmartExecutionConfig configures the retries and the re-pricing of the smart order executor
+//
+//	maxRetries: 3
+//	retryInterval: 1s
+//	repriceTimeout: 30s
+//	maxChaseTicks: 5
+//	marketFallback: true
+type SmartExecutionConfig struct {
+	// MaxRetries is the max number of the retries of the transient submit errors, defaults to 3
+	MaxRetries int `json:"maxRetries,omitempty" yaml:"maxRetries,omitempty"`
+
+	// RetryInterval is the interval between the retries, defaults to 1s
+	RetryInterval types.Duration `json:"retryInterval,omitempty" yaml:"retryInterval,omitempty"`
+
+	// RepriceTimeout re-prices the unfilled limit orders after the timeout, zero disables the re-pricing
+	RepriceTimeout types.Duration `json:"repriceTimeout,omitempty" yaml:"repriceTimeout,omitempty"`
+
+	// MaxChaseTicks is the max number of ticks the re-priced order can move away from the original price
+	MaxChaseTicks int `json:"maxChaseTicks,omitempty" yaml:"maxChaseTicks,omitempty"`
+
+	// MarketFallback submits the remaining quantity with a market order when the chase reaches MaxChaseTicks
+	MarketFallback bool `json:"marketFallback,omitempty" yaml:"marketFallback,omitempty"`
+
+	// CancelTimeout is the max duration of waiting for the update of the canceled order, defaults to 3s
+	CancelTimeout types.Duration `json:"cancelTimeout,omitempty" yaml:"cancelTimeout,omitempty"`
+}
+
+// ExecutionReport is the execution quality report of an order submitted by the smart order executor
+type ExecutionReport struct {
+	Symbol string         `json:"symbol"`
+	Side   types.SideType `json:"side"`
+
+	Quantity       float64 `json:"quantity"`
+	FilledQuantity float64 `json:"filledQuantity"`
+	AveragePrice   float64 `json:"averagePrice"`
+
+	// ArrivalPrice is the reference price (mid price or last price) when the order was submitted
+	ArrivalPrice float64 `json:"arrivalPrice"`
+
+	// Slippage is the ratio of the average price worse than the arrival price, a negative slippage means price improvement
+	Slippage float64 `json:"slippage"`
+
+	Retries        int           `json:"retries"`
+	Reprices       int           `json:"reprices"`
+	MarketFallback bool          `json:"marketFallback"`
+	Duration       time.Duration `json:"duration"`
+}
+
+func (r ExecutionReport) String() string {
+	return fmt.Sprintf("%s %s execution: filled %f/%f at %f, arrival price %f, slippage %.4f%%, retries %d, reprices %d, market fallback %v, duration %s",
+		r.Symbol, r.Side, r.FilledQuantity, r.Quantity, r.AveragePrice, r.ArrivalPrice, r.Slippage*100.0,
+		r.Retries, r.Reprices, r.MarketFallback, r.Duration)
+}
+
+// smartChildOrder is an order submitted for the execution, the original order or a re-priced order
+type smartChildOrder struct {
+	order         types.Order
+	executed      float64
+	tradeQuantity float64
+	tradeNotional float64
+}
+
+func (o *smartChildOrder) filledQuantity() float64 {
+	return math.Max(o.executed, o.tradeQuantity)
+}
+
+func (o *smartChildOrder) filledNotional() float64 {
+	notional := o.tradeNotional
+	if o.executed > o.tradeQuantity {
+		notional += (o.executed - o.tradeQuantity) * o.order.Price
+	}
+
+	return notional
+}
+
+type smartExecution struct {
+	order        types.SubmitOrder
+	market       types.Market
+	arrivalPrice float64
+	startTime    time.Time
+
+	// price is the price of the active order
+	price       float64
+	activeOrder types.Order
+	children    []*smartChildOrder
+
+	retries        int
+	reprices       int
+	marketFallback bool
+
+	updateC chan types.Order
+}
+
+//go:generate callbackgen -type SmartOrderExecutor
+
+// SmartOrderExecutor retries the transient submit errors, and chases the unfilled limit orders: the order is re-priced
+// toward the book after the reprice timeout until it moves MaxChaseTicks away from the original price, the remaining
+// quantity is then submitted with a market order if MarketFallback is enabled. An ExecutionReport is emitted when the
+// execution of the order is done.
+//
+// The re-priced orders and the market order are emitted by the child order callbacks, the caller should add them to
+// its order store, so that their trades are collected:
+//
+//	executor.OnChildOrder(func(order types.Order) { orderStore.Add(order) })
+type SmartOrderExecutor struct {
+	OrderExecutor
+
+	Session *ExchangeSession
+	Config  SmartExecutionConfig
+
+	mu         sync.Mutex
+	executions map[uint64]*smartExecution
+
+	executionReportCallbacks []func(report ExecutionReport)
+
+	childOrderCallbacks []func(order types.Order)
+}
+
+func NewSmartOrderExecutor(executor OrderExecutor, session *ExchangeSession, config SmartExecutionConfig) *SmartOrderExecutor {
+	if config.MaxRetries == 0 {
+		config.MaxRetries = defaultSmartMaxRetries
+	}
+
+	if config.RetryInterval == 0 {
+		config.RetryInterval = types.Duration(defaultSmartRetryInterval)
+	}
+
+	if config.CancelTimeout == 0 {
+		config.CancelTimeout = types.Duration(defaultSmartCancelTimeout)
+	}
+
+	e := &SmartOrderExecutor{
+		OrderExecutor: executor,
+		Session:       session,
+		Config:        config,
+		executions:    make(map[uint64]*smartExecution),
+	}
+
+	executor.OnOrderUpdate(e.handleOrderUpdate)
+	executor.OnTradeUpdate(e.handleTradeUpdate)
+	return e
+}
+
+// submit submits the order with the retries of the transient errors, it returns the number of the retries
+func (e *SmartOrderExecutor) submit(ctx context.Context, order types.SubmitOrder) (createdOrders types.OrderSlice, retries int, err error) {
+	err = util.Retry(ctx, e.Config.MaxRetries+1, e.Config.RetryInterval.Duration(), func() (err2 error) {
+		createdOrders, err2 = e.OrderExecutor.SubmitOrders(ctx, order)
+		return err2
+	}, func(err error) {
+		retries++
+		log.WithError(err).Warnf("can not submit the %s %s order, retrying...", order.Symbol, order.Side)
+	}, isTransientSubmitError)
+
+	// the error handler is also called on the last failed attempt
+	if retries > e.Config.MaxRetries {
+		retries = e.Config.MaxRetries
+	}
+
+	return createdOrders, retries, err
+}
+
+func (e *SmartOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	for _, order := range orders {
+		arrivalPrice, ok := e.Session.ReferencePrice(order.Symbol)
+		if !ok {
+			arrivalPrice = order.Price
+		}
+
+		startTime := time.Now()
+		created, retries, err2 := e.submit(ctx, order)
+		if err2 != nil {
+			err = err2
+			continue
+		}
+
+		createdOrders = append(createdOrders, created...)
+
+		// the orders filled on the submission are not chased
+		if len(created) != 1 || created[0].Status == types.OrderStatusFilled || !e.isChasable(order) {
+			continue
+		}
+
+		market, _ := e.Session.Market(order.Symbol)
+		execution := &smartExecution{
+			order:        order,
+			market:       market,
+			arrivalPrice: arrivalPrice,
+			startTime:    startTime,
+			price:        created[0].Price,
+			activeOrder:  created[0],
+			children:     []*smartChildOrder{{order: created[0]}},
+			retries:      retries,
+			updateC:      make(chan types.Order, 10),
+		}
+
+		e.mu.Lock()
+		e.executions[created[0].OrderID] = execution
+		e.mu.Unlock()
+
+		go e.chase(ctx, execution)
+	}
+
+	return createdOrders, err
+}
+
+func (e *SmartOrderExecutor) isChasable(order types.SubmitOrder) bool {
+	if e.Config.RepriceTimeout == 0 {
+		return false
+	}
+
+	market, ok := e.Session.Market(order.Symbol)
+	if !ok || market.TickSize <= 0 {
+		return false
+	}
+
+	return order.Type == types.OrderTypeLimit || order.Type == types.OrderTypeLimitMaker
+}
+
+func (e *SmartOrderExecutor) handleOrderUpdate(order types.Order) {
+	e.mu.Lock()
+	execution, ok := e.executions[order.OrderID]
+	if ok {
+		for _, child := range execution.children {
+			if child.order.OrderID == order.OrderID {
+				child.executed = math.Max(child.executed, order.ExecutedQuantity)
+			}
+		}
+	}
+	e.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	select {
+	case execution.updateC <- order:
+	default:
+	}
+}
+
+func (e *SmartOrderExecutor) handleTradeUpdate(trade types.Trade) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	execution, ok := e.executions[trade.OrderID]
+	if !ok {
+		return
+	}
+
+	for _, child := range execution.children {
+		if child.order.OrderID == trade.OrderID {
+			child.tradeQuantity += trade.Quantity
+			child.tradeNotional += trade.Quantity * trade.Price
+		}
+	}
+}
+
+func (e *SmartOrderExecutor) filledQuantity(execution *smartExecution) float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var filled float64
+	for _, child := range execution.children {
+		filled += child.filledQuantity()
+	}
+
+	return filled
+}
+
+// nextPrice moves the price one tick toward the book, or joins the best price of the same side if it's better,
+// it returns false if the price can not move further within the max chase ticks
+func (e *SmartOrderExecutor) nextPrice(execution *smartExecution) (float64, bool) {
+	tickSize := execution.market.TickSize
+	maxChase := float64(e.Config.MaxChaseTicks) * tickSize
+
+	var bestPrice float64
+	if book, ok := e.Session.OrderBook(execution.order.Symbol); ok && book != nil {
+		if bid, ask, ok := book.BestBidAndAsk(); ok {
+			bestPrice = bid.Price.Float64()
+			if execution.order.Side == types.SideTypeSell {
+				bestPrice = ask.Price.Float64()
+			}
+		}
+	}
+
+	var price float64
+	switch execution.order.Side {
+	case types.SideTypeBuy:
+		price = math.Min(math.Max(execution.price+tickSize, bestPrice), execution.order.Price+maxChase)
+		if price <= execution.price {
+			return 0, false
+		}
+
+	case types.SideTypeSell:
+		price = execution.price - tickSize
+		if bestPrice > 0 {
+			price = math.Min(price, bestPrice)
+		}
+
+		price = math.Max(price, execution.order.Price-maxChase)
+		if price >= execution.price {
+			return 0, false
+		}
+
+	default:
+		return 0, false
+	}
+
+	return price, true
+}
+
+// cancelActiveOrder cancels the active order and waits for the closed order update until the cancel timeout
+func (e *SmartOrderExecutor) cancelActiveOrder(ctx context.Context, execution *smartExecution) error {
+	if err := e.Session.Exchange.CancelOrders(ctx, execution.activeOrder); err != nil {
+		return err
+	}
+
+	timeout := time.After(e.Config.CancelTimeout.Duration())
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-timeout:
+			log.Warnf("the canceled %s order %d is not updated in %s", execution.order.Symbol, execution.activeOrder.OrderID, e.Config.CancelTimeout.Duration())
+			return nil
+
+		case order := <-execution.updateC:
+			if order.OrderID == execution.activeOrder.OrderID && isClosedOrderStatus(order.Status) {
+				return nil
+			}
+		}
+	}
+}
+
+func isClosedOrderStatus(status types.OrderStatus) bool {
+	switch status {
+	case types.OrderStatusFilled, types.OrderStatusCanceled, types.OrderStatusRejected:
+		return true
+	}
+
+	return false
+}
+
+func (e *SmartOrderExecutor) chase(ctx context.Context, execution *smartExecution) {
+	defer e.finish(execution)
+
+	timer := time.NewTimer(e.Config.RepriceTimeout.Duration())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case order := <-execution.updateC:
+			if order.OrderID != execution.activeOrder.OrderID || !isClosedOrderStatus(order.Status) {
+				continue
+			}
+
+			// the market order is done, or the limit order is filled or canceled outside
+			return
+
+		case <-timer.C:
+			if execution.marketFallback {
+				log.Warnf("the %s market order %d is not updated in %s", execution.order.Symbol, execution.activeOrder.OrderID, e.Config.RepriceTimeout.Duration())
+				return
+			}
+
+			price, canReprice := e.nextPrice(execution)
+			if !canReprice && !e.Config.MarketFallback {
+				// leave the order on the book
+				return
+			}
+
+			if err := e.cancelActiveOrder(ctx, execution); err != nil {
+				log.WithError(err).Errorf("can not cancel the %s order %d for re-pricing", execution.order.Symbol, execution.activeOrder.OrderID)
+				return
+			}
+
+			remaining := execution.order.Quantity - e.filledQuantity(execution)
+			if remaining < execution.market.MinQuantity || remaining <= 0 {
+				return
+			}
+
+			order := execution.order
+			order.Quantity = remaining
+			if canReprice {
+				order.Price = price
+				execution.reprices++
+			} else {
+				order.Type = types.OrderTypeMarket
+				order.Price = 0
+				order.TimeInForce = ""
+				execution.marketFallback = true
+			}
+
+			created, retries, err := e.submit(ctx, order)
+			e.mu.Lock()
+			execution.retries += retries
+			e.mu.Unlock()
+			if err != nil {
+				log.WithError(err).Errorf("can not submit the re-priced %s order", order.Symbol)
+				return
+			}
+
+			if len(created) == 0 {
+				return
+			}
+
+			e.mu.Lock()
+			execution.price = order.Price
+			execution.activeOrder = created[0]
+			execution.children = append(execution.children, &smartChildOrder{order: created[0]})
+			e.executions[created[0].OrderID] = execution
+			e.mu.Unlock()
+
+			e.EmitChildOrder(created[0])
+
+			timer.Reset(e.Config.RepriceTimeout.Duration())
+		}
+	}
+}
+
+func (e *SmartOrderExecutor) finish(execution *smartExecution) {
+	e.mu.Lock()
+	var filled, notional float64
+	for _, child := range execution.children {
+		filled += child.filledQuantity()
+		notional += child.filledNotional()
+		delete(e.executions, child.order.OrderID)
+	}
+
+	report := ExecutionReport{
+		Symbol:         execution.order.Symbol,
+		Side:           execution.order.Side,
+		Quantity:       execution.order.Quantity,
+		FilledQuantity: filled,
+		ArrivalPrice:   execution.arrivalPrice,
+		Retries:        execution.retries,
+		Reprices:       execution.reprices,
+		MarketFallback: execution.marketFallback,
+		Duration:       time.Since(execution.startTime),
+	}
+	e.mu.Unlock()
+
+	if filled > 0 {
+		report.AveragePrice = notional / filled
+	}
+
+	if report.AveragePrice > 0 && report.ArrivalPrice > 0 {
+		report.Slippage = (report.AveragePrice - report.ArrivalPrice) / report.ArrivalPrice
+		if report.Side == types.SideTypeSell {
+			report.Slippage = -report.Slippage
+		}
+	}
+
+	log.Info(report.String())
+	e.EmitExecutionReport(report)
+}
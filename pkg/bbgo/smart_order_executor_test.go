@@ -0,0 +1,183 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type flakyOrderExecutor struct {
+	*recordOrderExecutor
+
+	failures int
+	err      error
+}
+
+func (e *flakyOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	if e.failures > 0 {
+		e.failures--
+		return nil, e.err
+	}
+
+	return e.recordOrderExecutor.SubmitOrders(ctx, orders...)
+}
+
+type smartTestExchange struct {
+	types.Exchange
+
+	executor *recordOrderExecutor
+}
+
+func (e *smartTestExchange) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	for _, order := range orders {
+		order.Status = types.OrderStatusCanceled
+		e.executor.EmitOrderUpdate(order)
+	}
+
+	return nil
+}
+
+func newSmartTestSession(exchange types.Exchange) *ExchangeSession {
+	return &ExchangeSession{
+		Name:     "binance",
+		Exchange: exchange,
+		markets: map[string]types.Market{
+			"BTCUSDT": {Symbol: "BTCUSDT", TickSize: 1.0, StepSize: 0.001, MinQuantity: 0.001},
+		},
+		lastPrices: map[string]float64{"BTCUSDT": 100.0},
+	}
+}
+
+func Test_isTransientSubmitError(t *testing.T) {
+	assert.True(t, isTransientSubmitError(errors.New("429 too many requests")))
+	assert.True(t, isTransientSubmitError(errors.New("dial tcp: connect: connection refused")))
+	assert.True(t, isTransientSubmitError(&net.OpError{Op: "dial", Err: errors.New("i/o timeout")}))
+
+	// the order could be placed before these errors
+	assert.False(t, isTransientSubmitError(errors.New("503 Service Unavailable")))
+	assert.False(t, isTransientSubmitError(errors.New("read tcp: connection reset by peer")))
+	assert.False(t, isTransientSubmitError(errors.New("Post \"https://api.binance.com/api/v3/order\": EOF")))
+	assert.False(t, isTransientSubmitError(&net.OpError{Op: "read", Err: errors.New("i/o timeout")}))
+	assert.False(t, isTransientSubmitError(errors.New("insufficient balance")))
+	assert.False(t, isTransientSubmitError(ErrPriceDeviationExceeded))
+	assert.False(t, isTransientSubmitError(nil))
+}
+
+func TestSmartOrderExecutor_Retry(t *testing.T) {
+	base := &flakyOrderExecutor{
+		recordOrderExecutor: &recordOrderExecutor{ExchangeOrderExecutor: &ExchangeOrderExecutor{}},
+		failures:            2,
+		err:                 errors.New("429 too many requests"),
+	}
+
+	session := newSmartTestSession(&smartTestExchange{executor: base.recordOrderExecutor})
+	executor := NewSmartOrderExecutor(base, session, SmartExecutionConfig{
+		MaxRetries:    3,
+		RetryInterval: types.Duration(time.Millisecond),
+	})
+
+	order := types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 100.0, Quantity: 1.0}
+	createdOrders, retries, err := executor.submit(context.Background(), order)
+	assert.NoError(t, err)
+	assert.Len(t, createdOrders, 1)
+	assert.Equal(t, 2, retries)
+
+	// the permanent errors are not retried
+	base.failures = 2
+	base.err = errors.New("insufficient balance")
+	_, retries, err = executor.submit(context.Background(), order)
+	assert.Error(t, err)
+	assert.Equal(t, 0, retries)
+	assert.Equal(t, 1, base.failures)
+}
+
+func TestSmartOrderExecutor_Chase(t *testing.T) {
+	base := &recordOrderExecutor{ExchangeOrderExecutor: &ExchangeOrderExecutor{}}
+	session := newSmartTestSession(&smartTestExchange{executor: base})
+	executor := NewSmartOrderExecutor(base, session, SmartExecutionConfig{
+		RepriceTimeout: types.Duration(20 * time.Millisecond),
+		MaxChaseTicks:  2,
+		MarketFallback: true,
+		CancelTimeout:  types.Duration(time.Second),
+	})
+
+	var mu sync.Mutex
+	var reports []ExecutionReport
+	executor.OnExecutionReport(func(report ExecutionReport) {
+		mu.Lock()
+		reports = append(reports, report)
+		mu.Unlock()
+	})
+
+	numOfOrders := func() int {
+		base.mu.Lock()
+		defer base.mu.Unlock()
+		return len(base.orders)
+	}
+
+	var childOrders []types.Order
+	executor.OnChildOrder(func(order types.Order) {
+		mu.Lock()
+		childOrders = append(childOrders, order)
+		mu.Unlock()
+	})
+
+	createdOrders, err := executor.SubmitOrders(context.Background(),
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 100.0, Quantity: 1.0})
+	assert.NoError(t, err)
+	assert.Len(t, createdOrders, 1)
+
+	// the first order is partially filled before the re-pricing
+	executor.EmitTradeUpdate(types.Trade{OrderID: createdOrders[0].OrderID, Symbol: "BTCUSDT", Side: types.SideTypeBuy, Price: 100.0, Quantity: 0.3})
+
+	// the order is re-priced by one tick twice, and the remaining quantity is submitted by a market order
+	assert.Eventually(t, func() bool { return numOfOrders() == 4 }, time.Second, 5*time.Millisecond)
+
+	base.mu.Lock()
+	orders := append([]types.SubmitOrder(nil), base.orders...)
+	base.mu.Unlock()
+
+	assert.Equal(t, 101.0, orders[1].Price)
+	assert.Equal(t, 0.7, orders[1].Quantity)
+	assert.Equal(t, 102.0, orders[2].Price)
+	assert.Equal(t, types.OrderTypeMarket, orders[3].Type)
+	assert.Equal(t, 0.7, orders[3].Quantity)
+
+	// the re-priced orders and the market order are emitted to the caller
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(childOrders) == 3
+	}, time.Second, 5*time.Millisecond)
+
+	mu.Lock()
+	assert.Equal(t, uint64(2), childOrders[0].OrderID)
+	assert.Equal(t, uint64(3), childOrders[1].OrderID)
+	assert.Equal(t, uint64(4), childOrders[2].OrderID)
+	assert.Equal(t, types.OrderTypeMarket, childOrders[2].Type)
+	mu.Unlock()
+
+	executor.EmitTradeUpdate(types.Trade{OrderID: 4, Symbol: "BTCUSDT", Side: types.SideTypeBuy, Price: 103.0, Quantity: 0.7})
+	executor.EmitOrderUpdate(types.Order{SubmitOrder: orders[3], OrderID: 4, Status: types.OrderStatusFilled, ExecutedQuantity: 0.7})
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(reports) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	report := reports[0]
+	assert.InDelta(t, 1.0, report.FilledQuantity, 1e-9)
+	assert.InDelta(t, 102.1, report.AveragePrice, 1e-9)
+	assert.Equal(t, 100.0, report.ArrivalPrice)
+	assert.InDelta(t, 0.021, report.Slippage, 1e-9)
+	assert.Equal(t, 2, report.Reprices)
+	assert.True(t, report.MarketFallback)
+}
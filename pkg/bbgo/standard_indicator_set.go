@@ -0,0 +1,199 @@
+package bbgo
+
+import (
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/indicator"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// kLineWindowBinder is implemented by the indicators that are updated by the kline windows
+type kLineWindowBinder interface {
+	Bind(updater indicator.KLineWindowUpdater)
+}
+
+// kLineWindowPrimer feeds the loaded kline window to the newly created indicator
+type kLineWindowPrimer struct {
+	callbacks []func(interval types.Interval, window types.KLineWindow)
+}
+
+func (p *kLineWindowPrimer) OnKLineWindowUpdate(cb func(interval types.Interval, window types.KLineWindow)) {
+	p.callbacks = append(p.callbacks, cb)
+}
+
+func (p *kLineWindowPrimer) prime(interval types.Interval, window types.KLineWindow) {
+	for _, cb := range p.callbacks {
+		cb(interval, window)
+	}
+}
+
+// StandardIndicatorSet is the indicator registry of a symbol in the session, the indicators are created lazily
+// on the first access and cached by the interval and the window, so that the strategies on the same session
+// share one indicator instance instead of duplicating the computation.
+type StandardIndicatorSet struct {
+	Symbol string
+
+	// Standard indicators
+	// interval -> window
+	sma   map[types.IntervalWindow]*indicator.SMA
+	ewma  map[types.IntervalWindow]*indicator.EWMA
+	boll  map[bollKey]*indicator.BOLL
+	stoch map[types.IntervalWindow]*indicator.STOCH
+	rsi   map[types.IntervalWindow]*indicator.RSI
+	atr   map[types.IntervalWindow]*indicator.ATR
+	cci   map[types.IntervalWindow]*indicator.CCI
+
+	mu    sync.Mutex
+	store *MarketDataStore
+}
+
+// bollKey includes the band width since the bands of different widths are different indicators
+type bollKey struct {
+	types.IntervalWindow
+	BandWidth float64
+}
+
+func NewStandardIndicatorSet(symbol string, store *MarketDataStore) *StandardIndicatorSet {
+	return &StandardIndicatorSet{
+		Symbol: symbol,
+		sma:    make(map[types.IntervalWindow]*indicator.SMA),
+		ewma:   make(map[types.IntervalWindow]*indicator.EWMA),
+		boll:   make(map[bollKey]*indicator.BOLL),
+		stoch:  make(map[types.IntervalWindow]*indicator.STOCH),
+		rsi:    make(map[types.IntervalWindow]*indicator.RSI),
+		atr:    make(map[types.IntervalWindow]*indicator.ATR),
+		cci:    make(map[types.IntervalWindow]*indicator.CCI),
+		store:  store,
+	}
+}
+
+// bind binds the indicator to the market data store, the klines already loaded in the store
+// are fed to the indicator first, so that the indicator created later has the same values.
+func (set *StandardIndicatorSet) bind(interval types.Interval, inc kLineWindowBinder) {
+	if window, ok := set.store.KLinesOfInterval(interval); ok && len(window) > 0 {
+		primer := &kLineWindowPrimer{}
+		inc.Bind(primer)
+		primer.prime(interval, window)
+	}
+
+	inc.Bind(set.store)
+}
+
+// BOLL returns the bollinger band indicator of the given interval, the window and the band width
+func (set *StandardIndicatorSet) BOLL(iw types.IntervalWindow, bandWidth float64) *indicator.BOLL {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	key := bollKey{IntervalWindow: iw, BandWidth: bandWidth}
+	inc, ok := set.boll[key]
+	if !ok {
+		inc = &indicator.BOLL{IntervalWindow: iw, K: bandWidth}
+		set.bind(iw.Interval, inc)
+		set.boll[key] = inc
+	}
+
+	return inc
+}
+
+// SMA returns the simple moving average indicator of the given interval and the window size.
+func (set *StandardIndicatorSet) SMA(iw types.IntervalWindow) *indicator.SMA {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	inc, ok := set.sma[iw]
+	if !ok {
+		inc = &indicator.SMA{IntervalWindow: iw}
+		if debugSMA {
+			inc.OnUpdate(func(value float64) {
+				log.Infof("%s SMA %s: %f", set.Symbol, iw.String(), value)
+			})
+		}
+
+		set.bind(iw.Interval, inc)
+		set.sma[iw] = inc
+	}
+
+	return inc
+}
+
+// EWMA returns the exponential weighed moving average indicator of the given interval and the window size.
+func (set *StandardIndicatorSet) EWMA(iw types.IntervalWindow) *indicator.EWMA {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	inc, ok := set.ewma[iw]
+	if !ok {
+		inc = &indicator.EWMA{IntervalWindow: iw}
+		if debugEWMA {
+			inc.OnUpdate(func(value float64) {
+				log.Infof("%s EWMA %s: %f", set.Symbol, iw.String(), value)
+			})
+		}
+
+		set.bind(iw.Interval, inc)
+		set.ewma[iw] = inc
+	}
+
+	return inc
+}
+
+func (set *StandardIndicatorSet) STOCH(iw types.IntervalWindow) *indicator.STOCH {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	inc, ok := set.stoch[iw]
+	if !ok {
+		inc = &indicator.STOCH{IntervalWindow: iw}
+		set.bind(iw.Interval, inc)
+		set.stoch[iw] = inc
+	}
+
+	return inc
+}
+
+// RSI returns the relative strength index indicator of the given interval and the window size.
+func (set *StandardIndicatorSet) RSI(iw types.IntervalWindow) *indicator.RSI {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	inc, ok := set.rsi[iw]
+	if !ok {
+		inc = &indicator.RSI{IntervalWindow: iw}
+		set.bind(iw.Interval, inc)
+		set.rsi[iw] = inc
+	}
+
+	return inc
+}
+
+// ATR returns the average true range indicator of the given interval and the window size.
+func (set *StandardIndicatorSet) ATR(iw types.IntervalWindow) *indicator.ATR {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	inc, ok := set.atr[iw]
+	if !ok {
+		inc = &indicator.ATR{IntervalWindow: iw}
+		set.bind(iw.Interval, inc)
+		set.atr[iw] = inc
+	}
+
+	return inc
+}
+
+// CCI returns the commodity channel index indicator of the given interval and the window size.
+func (set *StandardIndicatorSet) CCI(iw types.IntervalWindow) *indicator.CCI {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	inc, ok := set.cci[iw]
+	if !ok {
+		inc = &indicator.CCI{IntervalWindow: iw}
+		set.bind(iw.Interval, inc)
+		set.cci[iw] = inc
+	}
+
+	return inc
+}
@@ -0,0 +1,60 @@
+package bbgo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestStandardIndicatorSet(t *testing.T) {
+	store := NewMarketDataStore("BTCUSDT")
+	startTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	addKLine := func(i int, price float64) {
+		store.AddKLine(types.KLine{
+			Symbol:    "BTCUSDT",
+			Interval:  types.Interval1m,
+			StartTime: startTime.Add(time.Duration(i) * time.Minute),
+			EndTime:   startTime.Add(time.Duration(i+1)*time.Minute - time.Millisecond),
+			High:      price + 1,
+			Low:       price - 1,
+			Close:     price,
+		})
+	}
+
+	for i := 0; i < 10; i++ {
+		addKLine(i, float64(100+i))
+	}
+
+	set := NewStandardIndicatorSet("BTCUSDT", store)
+
+	iw := types.IntervalWindow{Interval: types.Interval1m, Window: 5}
+	sma := set.SMA(iw)
+
+	// the indicator is primed with the loaded klines
+	assert.Equal(t, 107.0, sma.Last())
+
+	// the indicator is shared
+	assert.Same(t, sma, set.SMA(iw))
+	assert.NotSame(t, sma, set.SMA(types.IntervalWindow{Interval: types.Interval1m, Window: 7}))
+	assert.NotSame(t, sma, set.SMA(types.IntervalWindow{Interval: types.Interval5m, Window: 5}))
+
+	// the bands of different widths are different indicators
+	assert.Same(t, set.BOLL(iw, 2.0), set.BOLL(iw, 2.0))
+	assert.NotSame(t, set.BOLL(iw, 2.0), set.BOLL(iw, 1.0))
+
+	rsi := set.RSI(iw)
+	assert.Equal(t, 100.0, rsi.Last())
+
+	stoch := set.STOCH(iw)
+	numOfK := len(stoch.K)
+
+	addKLine(10, 104)
+	assert.InDelta(t, 106.8, sma.Last(), 1e-9)
+	assert.True(t, rsi.Last() < 100.0)
+
+	// the processed klines are not calculated again
+	assert.Len(t, stoch.K, numOfK+1)
+}
@@ -0,0 +1,363 @@
+package bbgo
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+const defaultStrategyModule = "github.com/c9s/bbgo"
+
+var strategyNamePattern = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+
+var strategyTemplate = template.Must(template.New("strategy").Parse(`package {{ .Name }}
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+
+	"{{ .Module }}/pkg/bbgo"
+	"{{ .Module }}/pkg/fixedpoint"
+	"{{ .Module }}/pkg/types"
+)
+
+const ID = "{{ .Name }}"
+
+var log = logrus.WithField("strategy", ID)
+
+func init() {
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+// Strategy is bound to the config of the exchange strategy:
+//
+//	exchangeStrategies:
+//	- on: binance
+//	  {{ .Name }}:
+//	    symbol: BTCUSDT
+//	    interval: 1m
+//	    quantity: 0.001
+type Strategy struct {
+	Symbol   string           ` + "`json:\"symbol\"`" + `
+	Interval types.Interval   ` + "`json:\"interval\"`" + `
+	Quantity fixedpoint.Value ` + "`json:\"quantity\"`" + `
+}
+
+func (s *Strategy) ID() string {
+	return ID
+}
+
+func (s *Strategy) Validate() error {
+	if len(s.Symbol) == 0 {
+		return errors.New("symbol is required")
+	}
+
+	if s.Quantity <= 0 {
+		return errors.New("quantity should be positive")
+	}
+
+	return nil
+}
+
+func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
+	if len(s.Interval) == 0 {
+		s.Interval = types.Interval1m
+	}
+
+	session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: string(s.Interval)})
+}
+
+func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	session.MarketDataStream.OnKLineClosed(func(kline types.KLine) {
+		if kline.Symbol != s.Symbol || kline.Interval != s.Interval {
+			return
+		}
+
+		// TODO: replace the example signal, it buys the quantity when the kline closes lower than it opens
+		if kline.Close >= kline.Open {
+			return
+		}
+
+		_, err := orderExecutor.SubmitOrders(ctx, types.SubmitOrder{
+			Symbol:   s.Symbol,
+			Side:     types.SideTypeBuy,
+			Type:     types.OrderTypeMarket,
+			Quantity: s.Quantity.Float64(),
+		})
+		if err != nil {
+			log.WithError(err).Errorf("can not submit the %s order", s.Symbol)
+		}
+	})
+
+	return nil
+}
+`))
+
+var strategyTestTemplate = template.Must(template.New("strategy_test").Parse(`package {{ .Name }}
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"{{ .Module }}/pkg/fixedpoint"
+	"{{ .Module }}/pkg/strategytest"
+	"{{ .Module }}/pkg/types"
+)
+
+func TestStrategy_Validate(t *testing.T) {
+	s := &Strategy{Symbol: "BTCUSDT", Quantity: fixedpoint.NewFromFloat(0.01)}
+	assert.NoError(t, s.Validate())
+
+	s.Quantity = 0
+	assert.Error(t, s.Validate())
+}
+
+func TestStrategy_Scenario(t *testing.T) {
+	ctx := context.Background()
+	market := types.Market{
+		Symbol:          "BTCUSDT",
+		PricePrecision:  2,
+		VolumePrecision: 6,
+		BaseCurrency:    "BTC",
+		QuoteCurrency:   "USDT",
+		StepSize:        0.000001,
+		TickSize:        0.01,
+	}
+
+	h := strategytest.New(strategytest.Config{
+		Markets: types.MarketMap{"BTCUSDT": market},
+		Balances: types.BalanceMap{
+			"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(1000.0)},
+		},
+	})
+
+	s := &Strategy{Symbol: "BTCUSDT", Quantity: fixedpoint.NewFromFloat(0.01)}
+	if !assert.NoError(t, h.Run(ctx, s)) {
+		return
+	}
+
+	kLines := strategytest.KLinesFromPrices("BTCUSDT", types.Interval1m, strategytest.DefaultStartTime,
+		100.0, 99.0, 100.0, 98.0, 99.0)
+	assert.NoError(t, h.Play(strategytest.KLines(kLines...)))
+
+	// the klines closed at 99.0 and 98.0 are lower than their open prices
+	assert.Len(t, h.SubmittedOrders(), 2)
+}
+`))
+
+var strategyConfigTemplate = template.Must(template.New("strategy_config").Parse(`---
+exchangeStrategies:
+
+- on: binance
+  {{ .Name }}:
+    symbol: BTCUSDT
+
+    # the kline interval of the signal
+    interval: 1m
+
+    # the base quantity of each order
+    quantity: 0.001
+`))
+
+// StrategyScaffold generates the package skeleton of a new built-in strategy and imports it in the builtin file
+type StrategyScaffold struct {
+	// Name is the package name and the ID of the strategy, e.g., mystrat
+	Name string
+
+	// Module is the go module path, defaults to the module of go.mod in the root directory
+	Module string
+
+	// RootDir is the root directory of the repository, defaults to the current directory
+	RootDir string
+}
+
+func (s *StrategyScaffold) Validate() error {
+	if !strategyNamePattern.MatchString(s.Name) {
+		return fmt.Errorf("invalid strategy name %q, it should be a lower case go package name like mystrat", s.Name)
+	}
+
+	if token.IsKeyword(s.Name) {
+		return fmt.Errorf("invalid strategy name %q, it's a go keyword", s.Name)
+	}
+
+	if _, ok := LoadedExchangeStrategies[s.Name]; ok {
+		return fmt.Errorf("strategy %s is already registered", s.Name)
+	}
+
+	if _, ok := LoadedCrossExchangeStrategies[s.Name]; ok {
+		return fmt.Errorf("strategy %s is already registered", s.Name)
+	}
+
+	return nil
+}
+
+// Generate writes the strategy, the unit test and the example config, and imports the strategy package in
+// pkg/cmd/builtin.go. The generated file paths are returned.
+func (s *StrategyScaffold) Generate() ([]string, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	rootDir := s.RootDir
+	if len(rootDir) == 0 {
+		rootDir = "."
+	}
+
+	module := s.Module
+	if len(module) == 0 {
+		module = readModulePath(filepath.Join(rootDir, "go.mod"))
+	}
+
+	packageDir := filepath.Join(rootDir, "pkg", "strategy", s.Name)
+	if _, err := os.Stat(packageDir); err == nil {
+		return nil, fmt.Errorf("strategy package directory %s already exists", packageDir)
+	}
+
+	configFile := filepath.Join(rootDir, "config", s.Name+".yaml")
+	if _, err := os.Stat(configFile); err == nil {
+		return nil, fmt.Errorf("config file %s already exists", configFile)
+	}
+
+	if err := os.MkdirAll(packageDir, 0755); err != nil {
+		return nil, errors.Wrapf(err, "can not create strategy package directory %s", packageDir)
+	}
+
+	data := struct {
+		Name   string
+		Module string
+	}{
+		Name:   s.Name,
+		Module: module,
+	}
+
+	var files = []struct {
+		path     string
+		template *template.Template
+		gofmt    bool
+	}{
+		{filepath.Join(packageDir, "strategy.go"), strategyTemplate, true},
+		{filepath.Join(packageDir, "strategy_test.go"), strategyTestTemplate, true},
+		{configFile, strategyConfigTemplate, false},
+	}
+
+	var generated []string
+	for _, f := range files {
+		if err := renderTemplateFile(f.path, f.template, data, f.gofmt); err != nil {
+			return generated, err
+		}
+
+		generated = append(generated, f.path)
+	}
+
+	builtinFile := filepath.Join(rootDir, "pkg", "cmd", "builtin.go")
+	if err := addBuiltinStrategyImport(builtinFile, module+"/pkg/strategy/"+s.Name); err != nil {
+		return generated, err
+	}
+
+	return append(generated, builtinFile), nil
+}
+
+func renderTemplateFile(path string, tpl *template.Template, data interface{}, gofmt bool) error {
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return err
+	}
+
+	out := buf.Bytes()
+	if gofmt {
+		formatted, err := format.Source(out)
+		if err != nil {
+			return errors.Wrapf(err, "can not format %s", path)
+		}
+
+		out = formatted
+	}
+
+	return ioutil.WriteFile(path, out, 0644)
+}
+
+// readModulePath reads the module path of go.mod, the bbgo module is returned if go.mod can not be read
+func readModulePath(goModFile string) string {
+	data, err := ioutil.ReadFile(goModFile)
+	if err != nil {
+		return defaultStrategyModule
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "module ") {
+			return strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), `"`)
+		}
+	}
+
+	return defaultStrategyModule
+}
+
+// addBuiltinStrategyImport adds the blank import of the strategy package to the import block of the builtin file,
+// the imports are kept sorted
+func addBuiltinStrategyImport(builtinFile, importPath string) error {
+	data, err := ioutil.ReadFile(builtinFile)
+	if err != nil {
+		return err
+	}
+
+	source := string(data)
+	start := strings.Index(source, "import (")
+	if start < 0 {
+		return fmt.Errorf("import block is not found in %s", builtinFile)
+	}
+
+	end := strings.Index(source[start:], "\n)")
+	if end < 0 {
+		return fmt.Errorf("import block of %s is not closed", builtinFile)
+	}
+	end += start
+
+	var imports []string
+	for _, line := range strings.Split(source[start+len("import ("):end], "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		if line == fmt.Sprintf("_ %q", importPath) {
+			return nil
+		}
+
+		imports = append(imports, line)
+	}
+
+	imports = append(imports, fmt.Sprintf("_ %q", importPath))
+	sort.Strings(imports)
+
+	var buf bytes.Buffer
+	buf.WriteString(source[:start])
+	buf.WriteString("import (\n")
+	for _, line := range imports {
+		buf.WriteString("\t" + line + "\n")
+	}
+	buf.WriteString(source[end+1:])
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return errors.Wrapf(err, "can not format %s", builtinFile)
+	}
+
+	return ioutil.WriteFile(builtinFile, formatted, 0644)
+}
@@ -0,0 +1,101 @@
+package bbgo
+
+import (
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testBuiltinFile = `package cmd
+
+// import built-in strategies
+import (
+	_ "github.com/acme/bot/pkg/strategy/grid"
+	_ "github.com/acme/bot/pkg/strategy/xmaker"
+)
+`
+
+func TestStrategyScaffold_Validate(t *testing.T) {
+	assert.NoError(t, (&StrategyScaffold{Name: "mystrat"}).Validate())
+	assert.Error(t, (&StrategyScaffold{Name: "MyStrat"}).Validate())
+	assert.Error(t, (&StrategyScaffold{Name: "my-strat"}).Validate())
+	assert.Error(t, (&StrategyScaffold{Name: "func"}).Validate())
+	assert.Error(t, (&StrategyScaffold{Name: ""}).Validate())
+}
+
+func TestStrategyScaffold_Generate(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "bbgo-scaffold-")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(rootDir)
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(rootDir, "pkg", "cmd"), 0755))
+	assert.NoError(t, os.MkdirAll(filepath.Join(rootDir, "config"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(rootDir, "go.mod"), []byte("module github.com/acme/bot\n\ngo 1.13\n"), 0644))
+
+	builtinFile := filepath.Join(rootDir, "pkg", "cmd", "builtin.go")
+	assert.NoError(t, ioutil.WriteFile(builtinFile, []byte(testBuiltinFile), 0644))
+
+	scaffold := &StrategyScaffold{Name: "mystrat", RootDir: rootDir}
+	files, err := scaffold.Generate()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, files, 4)
+
+	// the generated go files are parsed with the module import path
+	fset := token.NewFileSet()
+	for _, name := range []string{"strategy.go", "strategy_test.go"} {
+		f, err := parser.ParseFile(fset, filepath.Join(rootDir, "pkg", "strategy", "mystrat", name), nil, parser.ImportsOnly)
+		if assert.NoError(t, err, name) {
+			assert.Equal(t, "mystrat", f.Name.Name)
+		}
+	}
+
+	strategySource, err := ioutil.ReadFile(filepath.Join(rootDir, "pkg", "strategy", "mystrat", "strategy.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(strategySource), `"github.com/acme/bot/pkg/bbgo"`)
+	assert.Contains(t, string(strategySource), `const ID = "mystrat"`)
+
+	assert.FileExists(t, filepath.Join(rootDir, "config", "mystrat.yaml"))
+
+	builtinSource, err := ioutil.ReadFile(builtinFile)
+	assert.NoError(t, err)
+	assert.Equal(t, `package cmd
+
+// import built-in strategies
+import (
+	_ "github.com/acme/bot/pkg/strategy/grid"
+	_ "github.com/acme/bot/pkg/strategy/mystrat"
+	_ "github.com/acme/bot/pkg/strategy/xmaker"
+)
+`, string(builtinSource))
+
+	// the existing strategy package is not overwritten
+	_, err = scaffold.Generate()
+	assert.Error(t, err)
+}
+
+func TestAddBuiltinStrategyImport_Existing(t *testing.T) {
+	file, err := ioutil.TempFile("", "builtin-*.go")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(testBuiltinFile)
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+
+	assert.NoError(t, addBuiltinStrategyImport(file.Name(), "github.com/acme/bot/pkg/strategy/grid"))
+
+	source, err := ioutil.ReadFile(file.Name())
+	assert.NoError(t, err)
+	assert.Equal(t, testBuiltinFile, string(source))
+}
@@ -0,0 +1,57 @@
+package bbgo
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// StrategyState is a read-only snapshot of the running strategy state,
+// all the fields are copied, so that the snapshot can be marshalled and read by the dashboard or the debug tools
+// without racing with the strategy goroutines.
+type StrategyState struct {
+	Strategy string    `json:"strategy"`
+	Session  string    `json:"session,omitempty"`
+	Time     time.Time `json:"time"`
+
+	Position *types.Position `json:"position,omitempty"`
+
+	// Orders is the working orders of the strategy
+	Orders []types.Order `json:"orders"`
+
+	// Indicators is the indicator values that the strategy is using, e.g., {"boll.up": 59000.0}
+	Indicators map[string]float64 `json:"indicators,omitempty"`
+}
+
+// StrategyStateReader is implemented by the strategies that expose their running state.
+// ReadState should take the snapshot under the strategy's own lock so that the position,
+// the working orders and the indicator values are consistent with each other.
+type StrategyStateReader interface {
+	ReadState() StrategyState
+}
+
+// NewStrategyState copies the position, the active orders and the indicator values into a new state snapshot.
+// the position and the order book are copied under their locks, nil components are skipped.
+func NewStrategyState(position *types.Position, orderBook *LocalActiveOrderBook, indicators map[string]float64) StrategyState {
+	state := StrategyState{
+		Time:   time.Now(),
+		Orders: []types.Order{},
+	}
+
+	if position != nil {
+		state.Position = position.Snapshot()
+	}
+
+	if orderBook != nil {
+		state.Orders = orderBook.Orders()
+	}
+
+	if len(indicators) > 0 {
+		state.Indicators = make(map[string]float64, len(indicators))
+		for name, value := range indicators {
+			state.Indicators[name] = value
+		}
+	}
+
+	return state
+}
@@ -0,0 +1,78 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type stateReaderStrategy struct {
+	position   *types.Position
+	orderBook  *LocalActiveOrderBook
+	indicators map[string]float64
+}
+
+func (s *stateReaderStrategy) ID() string {
+	return "state-reader"
+}
+
+func (s *stateReaderStrategy) Run(ctx context.Context, orderExecutor OrderExecutor, session *ExchangeSession) error {
+	return nil
+}
+
+func (s *stateReaderStrategy) ReadState() StrategyState {
+	return NewStrategyState(s.position, s.orderBook, s.indicators)
+}
+
+func TestNewStrategyState(t *testing.T) {
+	position := types.NewPosition("BTCUSDT", "BTC", "USDT")
+	position.Base = fixedpoint.NewFromFloat(1.0)
+
+	orderBook := NewLocalActiveOrderBook()
+	orderBook.Add(types.Order{
+		SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Price: 50000.0, Quantity: 0.1},
+		OrderID:     1,
+		Status:      types.OrderStatusNew,
+	})
+
+	indicators := map[string]float64{"boll.up": 52000.0}
+
+	state := NewStrategyState(position, orderBook, indicators)
+	assert.Equal(t, fixedpoint.NewFromFloat(1.0), state.Position.Base)
+	assert.Len(t, state.Orders, 1)
+	assert.Equal(t, 52000.0, state.Indicators["boll.up"])
+
+	// the state should not be affected by the later updates
+	position.Base = 0
+	indicators["boll.up"] = 0
+	orderBook.Remove(types.Order{SubmitOrder: types.SubmitOrder{Side: types.SideTypeBuy}, OrderID: 1})
+
+	assert.Equal(t, fixedpoint.NewFromFloat(1.0), state.Position.Base)
+	assert.Len(t, state.Orders, 1)
+	assert.Equal(t, 52000.0, state.Indicators["boll.up"])
+
+	state = NewStrategyState(nil, nil, nil)
+	assert.Nil(t, state.Position)
+	assert.NotNil(t, state.Orders)
+}
+
+func TestTrader_StrategyStates(t *testing.T) {
+	environ := NewEnvironment()
+	environ.AddExchangeSession("binance", &ExchangeSession{Name: "binance"})
+
+	trader := NewTrader(environ)
+	assert.NoError(t, trader.AttachStrategyOn("binance", &stateReaderStrategy{
+		position: types.NewPosition("BTCUSDT", "BTC", "USDT"),
+	}))
+
+	states := trader.StrategyStates()
+	if assert.Len(t, states, 1) {
+		assert.Equal(t, "state-reader", states[0].Strategy)
+		assert.Equal(t, "binance", states[0].Session)
+		assert.Equal(t, "BTCUSDT", states[0].Position.Symbol)
+	}
+}
@@ -0,0 +1,140 @@
+package bbgo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var ErrSymbolNotAllowed = errors.New("symbol is not allowed by the session symbol guard")
+
+var ErrNotionalCapExceeded = errors.New("order notional exceeds the session notional cap")
+
+// SymbolGuard is the last-line guard of the session order executor, the orders of the symbols that are not allowed
+// or the orders larger than the notional cap are rejected no matter what the strategies request.
+type SymbolGuard struct {
+	// AllowedSymbols is the tradable symbols of the session, empty means all the symbols are allowed
+	AllowedSymbols []string `json:"allowedSymbols,omitempty" yaml:"allowedSymbols,omitempty"`
+
+	// DeniedSymbols is always rejected, even if the symbol is in the allowed symbols
+	DeniedSymbols []string `json:"deniedSymbols,omitempty" yaml:"deniedSymbols,omitempty"`
+
+	// MaxNotional is the max notional (price * quantity in the quote currency) of one order by symbol
+	MaxNotional map[string]fixedpoint.Value `json:"maxNotional,omitempty" yaml:"maxNotional,omitempty"`
+
+	// NotionalCurrency is the currency of the max notional, e.g., USD, the order notional is converted from the quote
+	// currency by the currency converter. The max notional is in the quote currency of the symbol if it's empty.
+	NotionalCurrency string `json:"notionalCurrency,omitempty" yaml:"notionalCurrency,omitempty"`
+}
+
+func (g *SymbolGuard) Validate() error {
+	for _, symbol := range g.DeniedSymbols {
+		if containsSymbol(g.AllowedSymbols, symbol) {
+			return fmt.Errorf("symbol %s is both allowed and denied", symbol)
+		}
+	}
+
+	for symbol, notional := range g.MaxNotional {
+		if notional <= 0 {
+			return fmt.Errorf("max notional of %s should be greater than zero", symbol)
+		}
+	}
+
+	return nil
+}
+
+// IsAllowed checks the symbol by the allowed symbols and the denied symbols
+func (g *SymbolGuard) IsAllowed(symbol string) bool {
+	if containsSymbol(g.DeniedSymbols, symbol) {
+		return false
+	}
+
+	return len(g.AllowedSymbols) == 0 || containsSymbol(g.AllowedSymbols, symbol)
+}
+
+// Check checks the order by the symbol lists and the notional cap, the last price is used for the market orders.
+// The order is rejected if the notional can not be determined while the symbol has a notional cap.
+func (g *SymbolGuard) Check(order types.SubmitOrder, lastPrice float64) error {
+	if !g.IsAllowed(order.Symbol) {
+		return errors.Wrapf(ErrSymbolNotAllowed, "%s %s order", order.Symbol, order.Side)
+	}
+
+	maxNotional, ok := g.maxNotional(order.Symbol)
+	if !ok {
+		return nil
+	}
+
+	price := order.Price
+	if order.Type == types.OrderTypeMarket || order.Type == types.OrderTypeStopMarket || price == 0 {
+		price = lastPrice
+	}
+
+	if price <= 0 {
+		return errors.Wrapf(ErrNotionalCapExceeded, "the notional of %s %s order can not be determined without the price", order.Symbol, order.Side)
+	}
+
+	if notional := price * order.Quantity; notional > maxNotional.Float64() {
+		return errors.Wrapf(ErrNotionalCapExceeded, "%s %s order notional %f > %f", order.Symbol, order.Side, notional, maxNotional.Float64())
+	}
+
+	return nil
+}
+
+func (g *SymbolGuard) maxNotional(symbol string) (fixedpoint.Value, bool) {
+	for s, notional := range g.MaxNotional {
+		if strings.EqualFold(s, symbol) {
+			return notional, true
+		}
+	}
+
+	return 0, false
+}
+
+func containsSymbol(symbols []string, symbol string) bool {
+	for _, s := range symbols {
+		if strings.EqualFold(s, symbol) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkSymbolGuard checks the orders by the session symbol guard, the first rejected order is returned with the error
+func (session *ExchangeSession) checkSymbolGuard(orders []types.SubmitOrder) (types.SubmitOrder, error) {
+	if session.SymbolGuard == nil {
+		return types.SubmitOrder{}, nil
+	}
+
+	for _, order := range orders {
+		lastPrice, _ := session.LastPrice(order.Symbol)
+		guardedOrder, lastPrice := session.notionalPrices(order, lastPrice)
+		if err := session.SymbolGuard.Check(guardedOrder, lastPrice); err != nil {
+			return order, err
+		}
+	}
+
+	return types.SubmitOrder{}, nil
+}
+
+// notionalPrices converts the order price and the last price into the notional currency of the symbol guard with
+// the cached conversion rate, the prices are zero if the rate is not available, so the capped orders are rejected
+func (session *ExchangeSession) notionalPrices(order types.SubmitOrder, lastPrice float64) (types.SubmitOrder, float64) {
+	currency := session.SymbolGuard.NotionalCurrency
+	if len(currency) == 0 {
+		return order, lastPrice
+	}
+
+	market, ok := session.Market(order.Symbol)
+	if !ok || market.QuoteCurrency == currency {
+		return order, lastPrice
+	}
+
+	rate, _ := session.currencyConverter.CachedRate(market.QuoteCurrency, currency)
+	order.Price *= rate
+	return order, lastPrice * rate
+}
@@ -0,0 +1,139 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type guardTestExchange struct {
+	types.Exchange
+
+	orders []types.SubmitOrder
+}
+
+func (e *guardTestExchange) Name() types.ExchangeName {
+	return types.ExchangeBinance
+}
+
+func (e *guardTestExchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	for _, order := range orders {
+		e.orders = append(e.orders, order)
+		createdOrders = append(createdOrders, types.Order{SubmitOrder: order, OrderID: uint64(len(e.orders))})
+	}
+
+	return createdOrders, nil
+}
+
+func TestSymbolGuard_Check(t *testing.T) {
+	guard := &SymbolGuard{
+		AllowedSymbols: []string{"BTCUSDT", "ethusdt"},
+		DeniedSymbols:  []string{"BNBUSDT"},
+		MaxNotional:    map[string]fixedpoint.Value{"BTCUSDT": fixedpoint.NewFromFloat(1000.0)},
+	}
+	assert.NoError(t, guard.Validate())
+
+	assert.True(t, guard.IsAllowed("ETHUSDT"))
+	assert.False(t, guard.IsAllowed("BNBUSDT"))
+	assert.False(t, guard.IsAllowed("LTCUSDT"))
+
+	err := guard.Check(types.SubmitOrder{Symbol: "LTCUSDT", Type: types.OrderTypeLimit, Price: 100.0, Quantity: 1.0}, 0)
+	assert.True(t, errors.Is(err, ErrSymbolNotAllowed))
+
+	// the notional cap
+	assert.NoError(t, guard.Check(types.SubmitOrder{Symbol: "BTCUSDT", Type: types.OrderTypeLimit, Price: 50000.0, Quantity: 0.02}, 0))
+	err = guard.Check(types.SubmitOrder{Symbol: "BTCUSDT", Type: types.OrderTypeLimit, Price: 50000.0, Quantity: 0.03}, 0)
+	assert.True(t, errors.Is(err, ErrNotionalCapExceeded))
+
+	// the market orders are checked by the last price, and rejected without the last price
+	assert.Error(t, guard.Check(types.SubmitOrder{Symbol: "BTCUSDT", Type: types.OrderTypeMarket, Quantity: 0.03}, 50000.0))
+	assert.NoError(t, guard.Check(types.SubmitOrder{Symbol: "BTCUSDT", Type: types.OrderTypeMarket, Quantity: 0.01}, 50000.0))
+	assert.Error(t, guard.Check(types.SubmitOrder{Symbol: "BTCUSDT", Type: types.OrderTypeMarket, Quantity: 0.01}, 0))
+
+	// no notional cap
+	assert.NoError(t, guard.Check(types.SubmitOrder{Symbol: "ETHUSDT", Type: types.OrderTypeMarket, Quantity: 100.0}, 0))
+
+	guard.DeniedSymbols = append(guard.DeniedSymbols, "BTCUSDT")
+	assert.Error(t, guard.Validate())
+}
+
+func TestExchangeOrderExecutor_SymbolGuard(t *testing.T) {
+	exchange := &guardTestExchange{}
+	session := &ExchangeSession{
+		Name:     "binance",
+		Exchange: exchange,
+		markets: map[string]types.Market{
+			"BTCUSDT": {Symbol: "BTCUSDT", TickSize: 0.01, StepSize: 0.0001},
+			"ETHUSDT": {Symbol: "ETHUSDT", TickSize: 0.01, StepSize: 0.0001},
+		},
+		SymbolGuard: &SymbolGuard{AllowedSymbols: []string{"BTCUSDT"}},
+	}
+	session.OrderExecutor = &ExchangeOrderExecutor{Session: session}
+
+	var rejectedOrders []types.SubmitOrder
+	session.OrderExecutor.OnSubmitOrderError(func(order types.SubmitOrder, err error) {
+		rejectedOrders = append(rejectedOrders, order)
+	})
+
+	// the whole batch is rejected
+	_, err := session.OrderExecutor.SubmitOrders(context.Background(),
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 100.0, Quantity: 1.0},
+		types.SubmitOrder{Symbol: "ETHUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 100.0, Quantity: 1.0},
+	)
+	assert.True(t, errors.Is(err, ErrSymbolNotAllowed))
+	assert.Len(t, exchange.orders, 0)
+	if assert.Len(t, rejectedOrders, 1) {
+		assert.Equal(t, "ETHUSDT", rejectedOrders[0].Symbol)
+	}
+
+	createdOrders, err := session.OrderExecutor.SubmitOrders(context.Background(),
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 100.0, Quantity: 1.0})
+	assert.NoError(t, err)
+	assert.Len(t, createdOrders, 1)
+
+	// the router checks the guard as well
+	router := &ExchangeOrderExecutionRouter{
+		sessions:  map[string]*ExchangeSession{"binance": session},
+		executors: map[string]OrderExecutor{},
+	}
+	_, err = router.SubmitOrdersTo(context.Background(), "binance",
+		types.SubmitOrder{Symbol: "ETHUSDT", Side: types.SideTypeSell, Type: types.OrderTypeLimit, Price: 100.0, Quantity: 1.0})
+	assert.True(t, errors.Is(err, ErrSymbolNotAllowed))
+	assert.Len(t, exchange.orders, 1)
+}
+
+func TestExchangeSession_SymbolGuardNotionalCurrency(t *testing.T) {
+	session := &ExchangeSession{
+		Name: "max",
+		markets: map[string]types.Market{
+			"BTCTWD": {Symbol: "BTCTWD", BaseCurrency: "BTC", QuoteCurrency: "TWD"},
+		},
+		SymbolGuard: &SymbolGuard{
+			MaxNotional:      map[string]fixedpoint.Value{"BTCTWD": fixedpoint.NewFromFloat(1000.0)},
+			NotionalCurrency: "USDT",
+		},
+	}
+
+	order := types.SubmitOrder{Symbol: "BTCTWD", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 1500000.0, Quantity: 0.02}
+
+	// the notional can not be converted without the rate
+	_, err := session.checkSymbolGuard([]types.SubmitOrder{order})
+	assert.True(t, errors.Is(err, ErrNotionalCapExceeded))
+
+	session.currencyConverter = NewCurrencyConverter(CurrencyConversionConfig{}, nil)
+	session.currencyConverter.rates.Add("USDT", "TWD", 30.0)
+
+	// 0.02 * 1500000 TWD = 1000 USDT
+	_, err = session.checkSymbolGuard([]types.SubmitOrder{order})
+	assert.NoError(t, err)
+
+	order.Quantity = 0.03
+	rejectedOrder, err := session.checkSymbolGuard([]types.SubmitOrder{order})
+	assert.True(t, errors.Is(err, ErrNotionalCapExceeded))
+	assert.Equal(t, 1500000.0, rejectedOrder.Price, "the original order is returned")
+}
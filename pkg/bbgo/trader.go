@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sort"
 	"sync"
 
 	"github.com/pkg/errors"
@@ -73,19 +74,46 @@ type Trader struct {
 
 	riskControls *RiskControls
 
+	// positionLimiter checks the orders of the single exchange strategies by the position limits
+	positionLimiter *PositionLimiter
+
+	// killSwitch halts the order submission when the daily loss crosses the threshold
+	killSwitch *KillSwitch
+
+	// pauses stops the new orders of the sessions and the strategies paused by the admin api
+	pauses *PauseController
+
 	crossExchangeStrategies []CrossExchangeStrategy
 	exchangeStrategies      map[string][]SingleExchangeStrategy
 
 	logger Logger
 
 	Graceful Graceful
+
+	// FeatureFlags is injected into the strategies that declare the FeatureFlags field
+	FeatureFlags *FeatureFlags
+
+	watchdog *Watchdog
+
+	// shutdownConfig is the shutdown policies of the strategies
+	shutdownConfig ShutdownConfig
+
+	// shutdownOrderBooks are the working orders of the strategies without the shutdown hook, keyed by the strategy
+	shutdownOrderBooks map[interface{}]*LocalActiveOrderBook
+	shutdownMu         sync.Mutex
+
+	profitRecorder *ProfitRecorder
+
+	emailReporter *EmailReporter
 }
 
 func NewTrader(environ *Environment) *Trader {
 	return &Trader{
 		environment:        environ,
 		exchangeStrategies: make(map[string][]SingleExchangeStrategy),
+		pauses:             NewPauseController(),
 		logger:             log.StandardLogger(),
+		FeatureFlags:       NewFeatureFlags(nil),
 	}
 }
 
@@ -100,6 +128,70 @@ func (trader *Trader) DisableLogging() {
 func (trader *Trader) Configure(userConfig *Config) error {
 	if userConfig.RiskControls != nil {
 		trader.SetRiskControls(userConfig.RiskControls)
+
+		if limits := userConfig.RiskControls.PositionLimits; limits != nil {
+			if err := limits.Validate(); err != nil {
+				return err
+			}
+
+			trader.positionLimiter = NewPositionLimiter(*limits, &trader.environment.Notifiability)
+		}
+
+		if config := userConfig.RiskControls.KillSwitch; config != nil {
+			if err := config.Validate(); err != nil {
+				return err
+			}
+
+			store := trader.environment.PersistenceServiceFacade.Get().NewStore("bbgo", "kill_switch")
+			trader.killSwitch = NewKillSwitch(*config, &trader.environment.Notifiability, store)
+		}
+	}
+
+	if userConfig.Watchdog != nil {
+		if err := userConfig.Watchdog.Validate(); err != nil {
+			return err
+		}
+
+		trader.SetWatchdog(NewWatchdog(*userConfig.Watchdog, &trader.environment.Notifiability))
+	}
+
+	if userConfig.Shutdown != nil {
+		if err := userConfig.Shutdown.Validate(); err != nil {
+			return err
+		}
+
+		trader.SetShutdownConfig(*userConfig.Shutdown)
+	}
+
+	if userConfig.ProfitReport != nil {
+		if err := userConfig.ProfitReport.Validate(); err != nil {
+			return err
+		}
+
+		trader.profitRecorder = NewProfitRecorder(trader.environment.ProfitService, &trader.environment.Notifiability, userConfig.ProfitReport.Periods...)
+	}
+
+	if userConfig.HealthCheck != nil {
+		if err := userConfig.HealthCheck.Validate(); err != nil {
+			return err
+		}
+
+		if trader.environment.HealthChecker != nil {
+			trader.environment.HealthChecker.Config = *userConfig.HealthCheck
+		}
+	}
+
+	if userConfig.Notifications != nil && userConfig.Notifications.Email != nil {
+		reporter, err := NewEmailReporter(*userConfig.Notifications.Email, trader.environment)
+		if err != nil {
+			return err
+		}
+
+		trader.emailReporter = reporter
+	}
+
+	for name, flag := range userConfig.FeatureFlags {
+		trader.FeatureFlags.Set(name, flag)
 	}
 
 	for _, entry := range userConfig.ExchangeStrategies {
@@ -163,12 +255,56 @@ func (trader *Trader) AttachCrossExchangeStrategy(strategy CrossExchangeStrategy
 	return trader
 }
 
+// SetWatchdog enables the liveness watchdog of the strategies
+func (trader *Trader) SetWatchdog(watchdog *Watchdog) {
+	trader.watchdog = watchdog
+}
+
 // SetRiskControls sets the risk controller
 // TODO: provide a more DSL way to configure risk controls
 func (trader *Trader) SetRiskControls(riskControls *RiskControls) {
 	trader.riskControls = riskControls
 }
 
+// StrategyStates reads the state snapshots of the attached strategies that implement StrategyStateReader
+func (trader *Trader) StrategyStates() []StrategyState {
+	var states []StrategyState
+
+	for sessionName, strategies := range trader.exchangeStrategies {
+		for _, strategy := range strategies {
+			reader, ok := strategy.(StrategyStateReader)
+			if !ok {
+				continue
+			}
+
+			state := reader.ReadState()
+			state.Strategy = strategy.ID()
+			state.Session = sessionName
+			states = append(states, state)
+		}
+	}
+
+	for _, strategy := range trader.crossExchangeStrategies {
+		reader, ok := strategy.(StrategyStateReader)
+		if !ok {
+			continue
+		}
+
+		state := reader.ReadState()
+		state.Strategy = strategy.ID()
+		states = append(states, state)
+	}
+
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].Session == states[j].Session {
+			return states[i].Strategy < states[j].Strategy
+		}
+		return states[i].Session < states[j].Session
+	})
+
+	return states
+}
+
 func (trader *Trader) Subscribe() {
 	// pre-subscribe the data
 	for sessionName, strategies := range trader.exchangeStrategies {
@@ -205,6 +341,39 @@ func (trader *Trader) RunSingleExchangeStrategy(ctx context.Context, strategy Si
 		return err
 	}
 
+	if err := trader.LoadPersistenceFields(strategy); err != nil {
+		return err
+	}
+
+	if policy, ok := strategyRoundingPolicy(rs); ok {
+		if err := policy.Validate(); err != nil {
+			return errors.Wrapf(err, "invalid rounding policy of %T", strategy)
+		}
+
+		orderExecutor = NewRoundingOrderExecutor(orderExecutor, policy)
+	}
+
+	if trader.positionLimiter != nil {
+		orderExecutor = NewPositionLimitOrderExecutor(orderExecutor, trader.positionLimiter, session, strategy.ID())
+	}
+
+	if trader.pauses != nil {
+		symbol, _ := isSymbolBasedStrategy(rs)
+		orderExecutor = NewPauseOrderExecutor(orderExecutor, trader.pauses, session.Name, strategy.ID(), symbol)
+	}
+
+	if trader.watchdog != nil {
+		symbol, _ := isSymbolBasedStrategy(rs)
+		heartbeat, explicit, err := injectHeartbeat(rs)
+		if err != nil {
+			return errors.Wrapf(err, "failed to inject Heartbeat on %T", strategy)
+		}
+
+		orderExecutor = trader.watchdog.Watch(watchdogStrategyID(strategy.ID(), symbol, session.Name), session, heartbeat, explicit, orderExecutor)
+	}
+
+	orderExecutor = trader.trackShutdownOrders(strategy, session, orderExecutor)
+
 	if err := injectField(rs, "OrderExecutor", orderExecutor, false); err != nil {
 		return errors.Wrapf(err, "failed to inject OrderExecutor on %T", strategy)
 	}
@@ -232,6 +401,23 @@ func (trader *Trader) RunSingleExchangeStrategy(ctx context.Context, strategy Si
 			}
 		}
 
+		// SessionPosition is the position of the symbol tracked by the session, which is updated by all the trades of the symbol
+		if _, ok := hasField(rs, "SessionPosition"); ok {
+			if position, ok := session.Position(symbol); ok {
+				if err := injectField(rs, "SessionPosition", position, true); err != nil {
+					return errors.Wrapf(err, "failed to inject SessionPosition on %T", strategy)
+				}
+			}
+		}
+
+		if _, ok := hasField(rs, "IndexPrice"); ok {
+			if indexPrice, ok := trader.environment.IndexPrice(symbol); ok {
+				if err := injectField(rs, "IndexPrice", indexPrice, true); err != nil {
+					return errors.Wrapf(err, "failed to inject IndexPrice on %T", strategy)
+				}
+			}
+		}
+
 		if _, ok := hasField(rs, "MarketDataStore"); ok {
 			store, ok := session.MarketDataStore(symbol)
 			if !ok {
@@ -255,6 +441,23 @@ func (trader *Trader) RunSingleExchangeStrategy(ctx context.Context, strategy Si
 }
 
 func (trader *Trader) getSessionOrderExecutor(sessionName string) OrderExecutor {
+	var orderExecutor = trader.getSessionBaseOrderExecutor(sessionName)
+
+	// the kill switch halts the orders of both the single exchange and the cross exchange strategies
+	if trader.killSwitch != nil && trader.killSwitch.Watches(sessionName) {
+		orderExecutor = NewKillSwitchOrderExecutor(orderExecutor, trader.killSwitch)
+	}
+
+	// the cross exchange strategies are paused with the session, the single exchange strategies are wrapped again
+	// with their strategy ID to be paused separately
+	if trader.pauses != nil {
+		orderExecutor = NewPauseOrderExecutor(orderExecutor, trader.pauses, sessionName, "", "")
+	}
+
+	return orderExecutor
+}
+
+func (trader *Trader) getSessionBaseOrderExecutor(sessionName string) OrderExecutor {
 	var session = trader.environment.sessions[sessionName]
 
 	// default to base order executor
@@ -325,11 +528,57 @@ func (trader *Trader) Run(ctx context.Context) error {
 			return err
 		}
 
+		if err := trader.LoadPersistenceFields(strategy); err != nil {
+			return err
+		}
+
+		// the cross exchange strategies are watched only if they emit the heartbeats by themselves
+		if trader.watchdog != nil {
+			if heartbeat, explicit, err := injectHeartbeat(rs); err != nil {
+				return errors.Wrapf(err, "failed to inject Heartbeat on %T", strategy)
+			} else if explicit {
+				trader.watchdog.Watch(strategy.ID(), nil, heartbeat, true, nil)
+			}
+		}
+
 		if err := strategy.CrossRun(ctx, router, trader.environment.sessions); err != nil {
 			return err
 		}
 	}
 
+	if trader.watchdog != nil {
+		for _, session := range trader.environment.sessions {
+			trader.watchdog.BindSession(session)
+		}
+
+		go trader.watchdog.Run(ctx)
+	}
+
+	if trader.profitRecorder != nil {
+		go trader.profitRecorder.Run(ctx)
+	}
+
+	if trader.emailReporter != nil {
+		go trader.emailReporter.Run(ctx)
+	}
+
+	if trader.killSwitch != nil {
+		for _, session := range trader.environment.sessions {
+			trader.killSwitch.AddSession(session)
+		}
+
+		go trader.killSwitch.Run(ctx)
+	}
+
+	if trader.environment.HealthChecker != nil {
+		trader.registerHealthChecks(trader.environment.HealthChecker)
+	}
+
+	// feed the historical data before the real-time data
+	if err := trader.BootstrapHistory(ctx); err != nil {
+		return err
+	}
+
 	return trader.environment.Connect(ctx)
 }
 
@@ -346,6 +595,10 @@ func (trader *Trader) injectCommonServices(rs reflect.Value) error {
 		return errors.Wrap(err, "failed to inject Notifiability")
 	}
 
+	if err := injectField(rs, "FeatureFlags", trader.FeatureFlags, true); err != nil {
+		return errors.Wrap(err, "failed to inject FeatureFlags")
+	}
+
 	if trader.environment.TradeService != nil {
 		if err := injectField(rs, "TradeService", trader.environment.TradeService, true); err != nil {
 			return errors.Wrap(err, "failed to inject TradeService")
@@ -358,6 +611,18 @@ func (trader *Trader) injectCommonServices(rs reflect.Value) error {
 		}
 	}
 
+	if trader.environment.AnalyticsService != nil {
+		if err := injectField(rs, "AnalyticsService", trader.environment.AnalyticsService, true); err != nil {
+			return errors.Wrap(err, "failed to inject AnalyticsService")
+		}
+	}
+
+	if _, ok := hasField(rs, "ProfitRecorder"); ok {
+		if err := injectField(rs, "ProfitRecorder", trader.ProfitRecorder(), true); err != nil {
+			return errors.Wrap(err, "failed to inject ProfitRecorder")
+		}
+	}
+
 
 	if field, ok := hasField(rs, "Persistence"); ok {
 		if trader.environment.PersistenceServiceFacade == nil {
@@ -3,6 +3,8 @@ package bbgo
 import (
 	"context"
 	"fmt"
+	"math"
+	"math/rand"
 	"sync"
 	"time"
 
@@ -24,6 +26,20 @@ type TwapExecution struct {
 	UpdateInterval time.Duration
 	DeadlineTime   time.Time
 
+	// Duration is the time range of the time-sliced execution, when it's set,
+	// the target quantity is released slice by slice over the duration.
+	Duration time.Duration
+
+	// NumOfSlices is the number of the time slices, default to target quantity / slice quantity
+	NumOfSlices int
+
+	// SizeJitter randomizes the size of each time slice, e.g., 0.2 means ±20% of the average slice size
+	SizeJitter fixedpoint.Value
+
+	// MaxParticipationRate limits the filled quantity to the rate of the market trade volume since the start,
+	// e.g., 0.1 means at most 10% of the market volume.
+	MaxParticipationRate fixedpoint.Value
+
 	market           types.Market
 	marketDataStream types.Stream
 
@@ -31,6 +47,9 @@ type TwapExecution struct {
 	userDataStreamCtx    context.Context
 	cancelUserDataStream context.CancelFunc
 
+	schedule     *twapSchedule
+	marketVolume fixedpoint.Value
+
 	orderBook      *types.StreamOrderBook
 	currentPrice   fixedpoint.Value
 	activePosition fixedpoint.Value
@@ -63,6 +82,47 @@ func (e *TwapExecution) connectUserData(ctx context.Context) {
 	}
 }
 
+// errTwapQuantityNotReleased is returned when the released quantity of the time slices or
+// the participation rate is not enough to place an order
+var errTwapQuantityNotReleased = errors.New("twap quantity is not released yet")
+
+func (e *TwapExecution) handleMarketTrade(trade types.Trade) {
+	if trade.Symbol != e.Symbol {
+		return
+	}
+
+	e.mu.Lock()
+	e.marketVolume += fixedpoint.NewFromFloat(trade.Quantity)
+	e.mu.Unlock()
+}
+
+// releasedQuantity returns the quantity that can be filled at the given time,
+// it's limited by the time slices and the max participation rate of the market volume.
+func (e *TwapExecution) releasedQuantity(now time.Time) fixedpoint.Value {
+	released := e.TargetQuantity
+	if e.schedule != nil {
+		released = fixedpoint.Min(released, e.schedule.Released(now))
+	}
+
+	if e.MaxParticipationRate > 0 {
+		e.mu.Lock()
+		volume := e.marketVolume
+		e.mu.Unlock()
+
+		released = fixedpoint.Min(released, volume.Mul(e.MaxParticipationRate))
+	}
+
+	return released
+}
+
+func (e *TwapExecution) isReleaseLimited() bool {
+	return e.schedule != nil || e.MaxParticipationRate > 0
+}
+
+func (e *TwapExecution) isDeadlineExceeded(now time.Time) bool {
+	return e.DeadlineTime != emptyTime && now.After(e.DeadlineTime)
+}
+
 func (e *TwapExecution) newBestPriceOrder() (orderForm types.SubmitOrder, err error) {
 	book := e.orderBook.Copy()
 	sideBook := book.SideBook(e.Side)
@@ -157,6 +217,16 @@ func (e *TwapExecution) newBestPriceOrder() (orderForm types.SubmitOrder, err er
 		orderQuantity = restQuantity
 	}
 
+	now := time.Now()
+	if e.isReleaseLimited() && !e.isDeadlineExceeded(now) {
+		releasedQuantity := e.releasedQuantity(now) - fixedpoint.Abs(base)
+		if releasedQuantity < minQuantity {
+			return orderForm, errTwapQuantityNotReleased
+		}
+
+		orderQuantity = fixedpoint.Min(orderQuantity, releasedQuantity)
+	}
+
 	minNotional := fixedpoint.NewFromFloat(e.market.MinNotional)
 	orderQuantity = AdjustQuantityByMinAmount(orderQuantity, newPrice, minNotional)
 
@@ -174,18 +244,15 @@ func (e *TwapExecution) newBestPriceOrder() (orderForm types.SubmitOrder, err er
 		}
 	}
 
-	if e.DeadlineTime != emptyTime {
-		now := time.Now()
-		if now.After(e.DeadlineTime) {
-			orderForm = types.SubmitOrder{
-				Symbol:   e.Symbol,
-				Side:     e.Side,
-				Type:     types.OrderTypeMarket,
-				Quantity: restQuantity.Float64(),
-				Market:   e.market,
-			}
-			return orderForm, nil
+	if e.isDeadlineExceeded(now) {
+		orderForm = types.SubmitOrder{
+			Symbol:   e.Symbol,
+			Side:     e.Side,
+			Type:     types.OrderTypeMarket,
+			Quantity: restQuantity.Float64(),
+			Market:   e.market,
 		}
+		return orderForm, nil
 	}
 
 	orderForm = types.SubmitOrder{
@@ -269,7 +336,10 @@ func (e *TwapExecution) updateOrder(ctx context.Context) error {
 	}
 
 	orderForm, err := e.newBestPriceOrder()
-	if err != nil {
+	if err == errTwapQuantityNotReleased {
+		log.Debugf("%s twap quantity is not released yet, waiting for the next slice", e.Symbol)
+		return nil
+	} else if err != nil {
 		return err
 	}
 
@@ -332,7 +402,7 @@ func (e *TwapExecution) cancelActiveOrders(ctx context.Context) {
 
 func (e *TwapExecution) orderUpdater(ctx context.Context) {
 	updateLimiter := rate.NewLimiter(rate.Every(3*time.Second), 1)
-	ticker := time.NewTimer(e.UpdateInterval)
+	ticker := time.NewTicker(e.UpdateInterval)
 	defer ticker.Stop()
 
 	// we should stop updater and clean up our open orders, if
@@ -434,10 +504,28 @@ func (e *TwapExecution) Run(parentCtx context.Context) error {
 		return fmt.Errorf("market %s not found", e.Symbol)
 	}
 
+	if e.SliceQuantity == 0 {
+		e.SliceQuantity = e.TargetQuantity
+	}
+
+	if e.Duration > 0 {
+		if e.NumOfSlices == 0 {
+			e.NumOfSlices = int(math.Ceil(e.TargetQuantity.Float64() / e.SliceQuantity.Float64()))
+		}
+
+		e.schedule = newTwapSchedule(time.Now(), e.Duration, e.NumOfSlices, e.TargetQuantity, e.SizeJitter.Float64(),
+			rand.New(rand.NewSource(time.Now().UnixNano())))
+	}
+
 	e.marketDataStream = e.Session.Exchange.NewStream()
 	e.marketDataStream.SetPublicOnly()
 	e.marketDataStream.Subscribe(types.BookChannel, e.Symbol, types.SubscribeOptions{})
 
+	if e.MaxParticipationRate > 0 {
+		e.marketDataStream.Subscribe(types.MarketTradeChannel, e.Symbol, types.SubscribeOptions{})
+		e.marketDataStream.OnMarketTrade(e.handleMarketTrade)
+	}
+
 	e.orderBook = types.NewStreamBook(e.Symbol)
 	e.orderBook.BindStream(e.marketDataStream)
 	go e.connectMarketData(e.executionCtx)
@@ -0,0 +1,85 @@
+package bbgo
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// twapSchedule releases the target quantity slice by slice over the duration,
+// the first slice is released at the start time and the whole target quantity is released at the end.
+type twapSchedule struct {
+	startTime time.Time
+	interval  time.Duration
+	slices    []fixedpoint.Value
+}
+
+// newTwapSchedule splits the target quantity into n slices, each slice size is randomized by the jitter ratio,
+// e.g., jitter 0.2 means the slice size is between 80% and 120% of the average slice size.
+// The sum of the slices is always the target quantity.
+func newTwapSchedule(startTime time.Time, duration time.Duration, n int, target fixedpoint.Value, jitter float64, rnd *rand.Rand) *twapSchedule {
+	if n < 1 {
+		n = 1
+	}
+
+	if jitter < 0 {
+		jitter = 0
+	} else if jitter > 1 {
+		jitter = 1
+	}
+
+	var weights = make([]float64, n)
+	var sum float64
+	for i := range weights {
+		weights[i] = 1.0
+		if jitter > 0 && rnd != nil {
+			weights[i] += jitter * (2.0*rnd.Float64() - 1.0)
+		}
+		sum += weights[i]
+	}
+
+	var slices = make([]fixedpoint.Value, n)
+	var released fixedpoint.Value
+	for i := 0; i < n-1; i++ {
+		slices[i] = target.MulFloat64(weights[i] / sum)
+		released += slices[i]
+	}
+
+	// the last slice takes the rest to avoid the rounding error
+	slices[n-1] = target - released
+
+	return &twapSchedule{
+		startTime: startTime,
+		interval:  duration / time.Duration(n),
+		slices:    slices,
+	}
+}
+
+// NumOfReleasedSlices returns the number of the released slices at the given time
+func (s *twapSchedule) NumOfReleasedSlices(now time.Time) int {
+	if now.Before(s.startTime) {
+		return 0
+	}
+
+	if s.interval <= 0 {
+		return len(s.slices)
+	}
+
+	n := int(now.Sub(s.startTime)/s.interval) + 1
+	if n > len(s.slices) {
+		n = len(s.slices)
+	}
+
+	return n
+}
+
+// Released returns the accumulated quantity of the released slices at the given time
+func (s *twapSchedule) Released(now time.Time) (released fixedpoint.Value) {
+	n := s.NumOfReleasedSlices(now)
+	for i := 0; i < n; i++ {
+		released += s.slices[i]
+	}
+
+	return released
+}
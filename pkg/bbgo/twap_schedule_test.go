@@ -0,0 +1,56 @@
+package bbgo
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestTwapSchedule(t *testing.T) {
+	startTime := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	target := fixedpoint.NewFromFloat(10.0)
+
+	schedule := newTwapSchedule(startTime, time.Hour, 4, target, 0, nil)
+	assert.Equal(t, 0.0, schedule.Released(startTime.Add(-time.Second)).Float64())
+	assert.Equal(t, 2.5, schedule.Released(startTime).Float64())
+	assert.Equal(t, 2.5, schedule.Released(startTime.Add(14*time.Minute)).Float64())
+	assert.Equal(t, 5.0, schedule.Released(startTime.Add(15*time.Minute)).Float64())
+	assert.Equal(t, 10.0, schedule.Released(startTime.Add(45*time.Minute)).Float64())
+	assert.Equal(t, 10.0, schedule.Released(startTime.Add(2*time.Hour)).Float64())
+
+	// the jittered slices still sum to the target quantity
+	schedule = newTwapSchedule(startTime, time.Hour, 7, target, 0.3, rand.New(rand.NewSource(1)))
+	var sum fixedpoint.Value
+	for _, slice := range schedule.slices {
+		assert.True(t, slice > 0)
+		sum += slice
+	}
+	assert.Equal(t, target, sum)
+	assert.Equal(t, target, schedule.Released(startTime.Add(time.Hour)))
+}
+
+func TestTwapExecution_releasedQuantity(t *testing.T) {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	e := &TwapExecution{
+		Symbol:               "BTCUSDT",
+		TargetQuantity:       fixedpoint.NewFromFloat(10.0),
+		MaxParticipationRate: fixedpoint.NewFromFloat(0.1),
+		schedule:             newTwapSchedule(now, time.Hour, 2, fixedpoint.NewFromFloat(10.0), 0, nil),
+	}
+
+	// no market volume yet
+	assert.Equal(t, 0.0, e.releasedQuantity(now).Float64())
+
+	e.handleMarketTrade(types.Trade{Symbol: "BTCUSDT", Quantity: 30.0})
+	e.handleMarketTrade(types.Trade{Symbol: "ETHUSDT", Quantity: 100.0})
+	assert.Equal(t, 3.0, e.releasedQuantity(now).Float64())
+
+	e.handleMarketTrade(types.Trade{Symbol: "BTCUSDT", Quantity: 100.0})
+	assert.Equal(t, 5.0, e.releasedQuantity(now).Float64())
+	assert.Equal(t, 10.0, e.releasedQuantity(now.Add(30*time.Minute)).Float64())
+}
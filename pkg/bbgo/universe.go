@@ -0,0 +1,120 @@
+package bbgo
+
+import (
+	"context"
+	"sort"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+// DefaultUniverse selects the markets quoted in the fiat currencies, and both the base asset and the quote asset
+// are held in the account balances.
+var DefaultUniverse = UniverseConfig{
+	QuoteCurrencies: types.FiatCurrencies,
+	HoldingsOnly:    true,
+}
+
+// UniverseSelector selects the symbols (the universe) to work with from an exchange session
+type UniverseSelector interface {
+	SelectSymbols(ctx context.Context, session *ExchangeSession) ([]string, error)
+}
+
+type TickerQuerier interface {
+	QueryTickers(ctx context.Context, symbol ...string) (map[string]types.Ticker, error)
+}
+
+// UniverseConfig is the configurable universe selector, the filters are applied in the following order:
+// allow list, deny list, quote currencies, holdings, min volume and then top-N by volume.
+type UniverseConfig struct {
+	// Allow is the symbol allow list, when it's not empty, only the listed symbols are selected
+	Allow []string `json:"allow,omitempty" yaml:"allow,omitempty"`
+
+	// Deny is the symbol deny list
+	Deny []string `json:"deny,omitempty" yaml:"deny,omitempty"`
+
+	// QuoteCurrencies filters the markets by the quote currency, e.g., [USDT, BUSD]
+	QuoteCurrencies []string `json:"quoteCurrencies,omitempty" yaml:"quoteCurrencies,omitempty"`
+
+	// HoldingsOnly selects the markets that both the base asset and the quote asset are held in the balances
+	HoldingsOnly bool `json:"holdingsOnly,omitempty" yaml:"holdingsOnly,omitempty"`
+
+	// MinVolume is the minimal 24h volume in the quote currency
+	MinVolume fixedpoint.Value `json:"minVolume,omitempty" yaml:"minVolume,omitempty"`
+
+	// TopN selects the top N markets by the 24h quote volume
+	TopN int `json:"topN,omitempty" yaml:"topN,omitempty"`
+}
+
+func (c *UniverseConfig) SelectSymbols(ctx context.Context, session *ExchangeSession) ([]string, error) {
+	return c.Select(ctx, session.Exchange, session.Markets(), session.Account.Balances())
+}
+
+// Select selects the symbols from the given markets, the tickers are only queried when the volume filters are set.
+func (c *UniverseConfig) Select(ctx context.Context, querier TickerQuerier, markets types.MarketMap, balances types.BalanceMap) ([]string, error) {
+	var symbols []string
+	for symbol, market := range markets {
+		if len(c.Allow) > 0 && !util.StringSliceContains(c.Allow, symbol) {
+			continue
+		}
+
+		if util.StringSliceContains(c.Deny, symbol) {
+			continue
+		}
+
+		if len(c.QuoteCurrencies) > 0 && !util.StringSliceContains(c.QuoteCurrencies, market.QuoteCurrency) {
+			continue
+		}
+
+		if c.HoldingsOnly && !(hasBalance(balances, market.BaseCurrency) && hasBalance(balances, market.QuoteCurrency)) {
+			continue
+		}
+
+		symbols = append(symbols, symbol)
+	}
+
+	sort.Strings(symbols)
+
+	if len(symbols) == 0 || (c.MinVolume == 0 && c.TopN == 0) {
+		return symbols, nil
+	}
+
+	tickers, err := querier.QueryTickers(ctx, symbols...)
+	if err != nil {
+		return nil, err
+	}
+
+	var volumes = make(map[string]float64)
+	var selected []string
+	for _, symbol := range symbols {
+		ticker, ok := tickers[symbol]
+		if !ok {
+			continue
+		}
+
+		quoteVolume := ticker.Volume * ticker.Last
+		if c.MinVolume > 0 && quoteVolume < c.MinVolume.Float64() {
+			continue
+		}
+
+		volumes[symbol] = quoteVolume
+		selected = append(selected, symbol)
+	}
+
+	if c.TopN > 0 && len(selected) > c.TopN {
+		sort.SliceStable(selected, func(i, j int) bool {
+			return volumes[selected[i]] > volumes[selected[j]]
+		})
+
+		selected = selected[:c.TopN]
+		sort.Strings(selected)
+	}
+
+	return selected, nil
+}
+
+func hasBalance(balances types.BalanceMap, currency string) bool {
+	balance, ok := balances[currency]
+	return ok && balance.Total() > 0
+}
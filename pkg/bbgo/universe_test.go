@@ -0,0 +1,73 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type mockTickerQuerier struct {
+	tickers map[string]types.Ticker
+	queried int
+}
+
+func (q *mockTickerQuerier) QueryTickers(ctx context.Context, symbol ...string) (map[string]types.Ticker, error) {
+	q.queried++
+	return q.tickers, nil
+}
+
+func TestUniverseConfig_Select(t *testing.T) {
+	markets := types.MarketMap{
+		"BTCUSDT":  {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"},
+		"ETHUSDT":  {Symbol: "ETHUSDT", BaseCurrency: "ETH", QuoteCurrency: "USDT"},
+		"LINKUSDT": {Symbol: "LINKUSDT", BaseCurrency: "LINK", QuoteCurrency: "USDT"},
+		"ETHBTC":   {Symbol: "ETHBTC", BaseCurrency: "ETH", QuoteCurrency: "BTC"},
+	}
+
+	balances := types.BalanceMap{
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(1.0)},
+		"ETH":  {Currency: "ETH", Available: fixedpoint.NewFromFloat(10.0)},
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(1000.0)},
+	}
+
+	querier := &mockTickerQuerier{
+		tickers: map[string]types.Ticker{
+			"BTCUSDT":  {Volume: 100.0, Last: 50000.0},
+			"ETHUSDT":  {Volume: 1000.0, Last: 3000.0},
+			"LINKUSDT": {Volume: 10000.0, Last: 20.0},
+		},
+	}
+
+	ctx := context.Background()
+
+	symbols, err := DefaultUniverse.Select(ctx, querier, markets, balances)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"BTCUSDT", "ETHUSDT"}, symbols)
+	assert.Equal(t, 0, querier.queried, "tickers should not be queried without the volume filters")
+
+	universe := UniverseConfig{QuoteCurrencies: []string{"USDT"}, Deny: []string{"ETHUSDT"}}
+	symbols, err = universe.Select(ctx, querier, markets, balances)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"BTCUSDT", "LINKUSDT"}, symbols)
+
+	universe = UniverseConfig{Allow: []string{"ETHBTC", "LINKUSDT"}}
+	symbols, err = universe.Select(ctx, querier, markets, balances)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ETHBTC", "LINKUSDT"}, symbols)
+
+	// LINKUSDT quote volume = 200,000
+	universe = UniverseConfig{QuoteCurrencies: []string{"USDT"}, MinVolume: fixedpoint.NewFromFloat(1000000.0)}
+	symbols, err = universe.Select(ctx, querier, markets, balances)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"BTCUSDT", "ETHUSDT"}, symbols)
+
+	// BTCUSDT quote volume = 5,000,000, ETHUSDT quote volume = 3,000,000
+	universe = UniverseConfig{QuoteCurrencies: []string{"USDT"}, TopN: 1}
+	symbols, err = universe.Select(ctx, querier, markets, balances)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"BTCUSDT"}, symbols)
+}
@@ -0,0 +1,339 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultWatchdogTimeout = 5 * time.Minute
+
+const defaultWatchdogCheckInterval = 30 * time.Second
+
+// WatchdogConfig alerts when a strategy stops making progress while its orders are live
+type WatchdogConfig struct {
+	// Timeout is the max duration without the progress of a strategy, defaults to 5m
+	Timeout types.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+
+	// CheckInterval defaults to 30s
+	CheckInterval types.Duration `json:"checkInterval,omitempty" yaml:"checkInterval,omitempty"`
+
+	// CancelOrders cancels the working orders of the stalled strategy after the alert
+	CancelOrders bool `json:"cancelOrders,omitempty" yaml:"cancelOrders,omitempty"`
+}
+
+func (c *WatchdogConfig) Validate() error {
+	if c.Timeout < 0 || c.CheckInterval < 0 {
+		return errors.New("watchdog timeout and checkInterval can not be negative")
+	}
+
+	return nil
+}
+
+// Heartbeat is injected into the strategies that declare the Heartbeat field, the strategy should call Beat
+// periodically (e.g., in its main loop or on every kline) to tell the watchdog that it's still making progress.
+// For the strategies without the Heartbeat field, the progress is inferred from the callback activity
+// of the session streams and the orders submitted by the strategy.
+type Heartbeat struct {
+	mu   sync.Mutex
+	last time.Time
+}
+
+// Beat records the heartbeat, it's safe to call on the nil heartbeat when the watchdog is not enabled
+func (h *Heartbeat) Beat() {
+	if h == nil {
+		return
+	}
+
+	h.beatAt(time.Now())
+}
+
+func (h *Heartbeat) beatAt(t time.Time) {
+	h.mu.Lock()
+	if t.After(h.last) {
+		h.last = t
+	}
+	h.mu.Unlock()
+}
+
+// Last returns the time of the last heartbeat
+func (h *Heartbeat) Last() time.Time {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.last
+}
+
+// watchedStrategy is the liveness state of one strategy instance
+type watchedStrategy struct {
+	id        string
+	session   *ExchangeSession
+	heartbeat *Heartbeat
+
+	// explicit is true if the strategy emits the heartbeats by itself
+	explicit bool
+
+	// executor tracks the orders of the single exchange strategy, it's nil for the cross exchange strategies
+	executor *WatchdogOrderExecutor
+
+	stalled bool
+}
+
+// Watchdog watches the liveness of the strategies, when a strategy stops making progress while its orders are live,
+// the watchdog alerts and optionally cancels the working orders of the strategy.
+type Watchdog struct {
+	*Notifiability
+
+	Config WatchdogConfig
+
+	mu         sync.Mutex
+	strategies []*watchedStrategy
+
+	// lastStalled is the stalled strategies of the last check
+	lastStalled []string
+
+	// sessionActivities is the time of the last stream event dispatched to the callbacks of the session
+	sessionActivities map[string]*Heartbeat
+}
+
+func NewWatchdog(config WatchdogConfig, notifiability *Notifiability) *Watchdog {
+	if config.Timeout == 0 {
+		config.Timeout = types.Duration(defaultWatchdogTimeout)
+	}
+
+	if config.CheckInterval == 0 {
+		config.CheckInterval = types.Duration(defaultWatchdogCheckInterval)
+	}
+
+	return &Watchdog{
+		Notifiability:     notifiability,
+		Config:            config,
+		sessionActivities: make(map[string]*Heartbeat),
+	}
+}
+
+// Watch registers the strategy instance, the returned order executor should be used by the strategy so that
+// its working orders are tracked. The executor is nil for the cross exchange strategies.
+func (w *Watchdog) Watch(id string, session *ExchangeSession, heartbeat *Heartbeat, explicit bool, executor OrderExecutor) OrderExecutor {
+	heartbeat.Beat()
+
+	s := &watchedStrategy{
+		id:        id,
+		session:   session,
+		heartbeat: heartbeat,
+		explicit:  explicit,
+	}
+
+	if executor != nil && session != nil {
+		s.executor = NewWatchdogOrderExecutor(executor, heartbeat)
+		if session.UserDataStream != nil {
+			s.executor.activeOrders.BindStream(session.UserDataStream)
+		}
+	}
+
+	w.mu.Lock()
+	w.strategies = append(w.strategies, s)
+	w.mu.Unlock()
+
+	if s.executor == nil {
+		return executor
+	}
+
+	return s.executor
+}
+
+// BindSession records the stream callback activity of the session, it should be called after the strategies
+// registered their callbacks, so the watchdog callbacks are called after the strategy callbacks return,
+// a hung strategy callback blocks the stream and stops the activity.
+func (w *Watchdog) BindSession(session *ExchangeSession) {
+	w.mu.Lock()
+	activity, ok := w.sessionActivities[session.Name]
+	if !ok {
+		activity = &Heartbeat{}
+		w.sessionActivities[session.Name] = activity
+	}
+	w.mu.Unlock()
+
+	if ok {
+		return
+	}
+
+	if session.MarketDataStream != nil {
+		session.MarketDataStream.OnKLineClosed(func(kline types.KLine) { activity.Beat() })
+		session.MarketDataStream.OnBookUpdate(func(book types.SliceOrderBook) { activity.Beat() })
+		session.MarketDataStream.OnBookSnapshot(func(book types.SliceOrderBook) { activity.Beat() })
+	}
+
+	if session.UserDataStream != nil {
+		session.UserDataStream.OnOrderUpdate(func(order types.Order) { activity.Beat() })
+		session.UserDataStream.OnTradeUpdate(func(trade types.Trade) { activity.Beat() })
+	}
+}
+
+// lastProgress returns the time of the last progress of the strategy
+func (w *Watchdog) lastProgress(s *watchedStrategy) time.Time {
+	last := s.heartbeat.Last()
+	if s.explicit || s.session == nil {
+		return last
+	}
+
+	w.mu.Lock()
+	activity, ok := w.sessionActivities[s.session.Name]
+	w.mu.Unlock()
+
+	// the stream that never emits any event can not tell whether the callbacks are hung
+	if ok && !activity.Last().IsZero() && activity.Last().After(last) {
+		return activity.Last()
+	}
+
+	return last
+}
+
+// Check checks the liveness of the strategies, the stalled strategies are returned
+func (w *Watchdog) Check(ctx context.Context, now time.Time) (stalled []string) {
+	w.mu.Lock()
+	strategies := append([]*watchedStrategy(nil), w.strategies...)
+	w.mu.Unlock()
+
+	timeout := w.Config.Timeout.Duration()
+	for _, s := range strategies {
+		last := w.lastProgress(s)
+		if now.Sub(last) < timeout {
+			if s.stalled {
+				s.stalled = false
+				w.Notify(":white_check_mark: strategy %s is making progress again", s.id)
+			}
+			continue
+		}
+
+		// without the live orders, a stalled strategy can not do any harm
+		var workingOrders types.OrderSlice
+		if s.executor != nil {
+			workingOrders = s.executor.activeOrders.Orders()
+			if len(workingOrders) == 0 {
+				continue
+			}
+		}
+
+		stalled = append(stalled, s.id)
+		if s.stalled {
+			continue
+		}
+
+		s.stalled = true
+		w.Notify(":rotating_light: strategy %s made no progress in the last %s (last progress at %s), %d working orders",
+			s.id, now.Sub(last).Round(time.Second), last.Format(time.RFC3339), len(workingOrders))
+
+		if w.Config.CancelOrders && len(workingOrders) > 0 {
+			if err := s.session.Exchange.CancelOrders(ctx, workingOrders...); err != nil {
+				log.WithError(err).Errorf("watchdog can not cancel the working orders of strategy %s", s.id)
+				w.Notify(":warning: watchdog can not cancel the working orders of strategy %s: %v", s.id, err)
+			} else {
+				w.Notify("watchdog canceled %d working orders of strategy %s", len(workingOrders), s.id)
+			}
+		}
+	}
+
+	w.mu.Lock()
+	w.lastStalled = stalled
+	w.mu.Unlock()
+
+	return stalled
+}
+
+// Stalled returns the stalled strategies found by the last check
+func (w *Watchdog) Stalled() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.lastStalled...)
+}
+
+func (w *Watchdog) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.Config.CheckInterval.Duration())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case now := <-ticker.C:
+			w.Check(ctx, now)
+		}
+	}
+}
+
+// WatchdogOrderExecutor tracks the working orders of the strategy, and every order submission counts as progress
+type WatchdogOrderExecutor struct {
+	OrderExecutor
+
+	heartbeat    *Heartbeat
+	activeOrders *LocalActiveOrderBook
+}
+
+func NewWatchdogOrderExecutor(executor OrderExecutor, heartbeat *Heartbeat) *WatchdogOrderExecutor {
+	return &WatchdogOrderExecutor{
+		OrderExecutor: executor,
+		heartbeat:     heartbeat,
+		activeOrders:  NewLocalActiveOrderBook(),
+	}
+}
+
+func (e *WatchdogOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	e.heartbeat.Beat()
+
+	createdOrders, err := e.OrderExecutor.SubmitOrders(ctx, orders...)
+	for _, order := range createdOrders {
+		// the market orders and the closed orders are not working orders
+		switch order.Status {
+		case types.OrderStatusFilled, types.OrderStatusCanceled, types.OrderStatusRejected:
+			continue
+		}
+
+		if order.Type == types.OrderTypeMarket {
+			continue
+		}
+
+		e.activeOrders.Add(order)
+	}
+
+	return createdOrders, err
+}
+
+// WorkingOrders returns the working orders submitted by the strategy
+func (e *WatchdogOrderExecutor) WorkingOrders() types.OrderSlice {
+	return e.activeOrders.Orders()
+}
+
+func watchdogStrategyID(id, symbol, session string) string {
+	if len(symbol) > 0 {
+		return fmt.Sprintf("%s:%s@%s", id, symbol, session)
+	}
+
+	if len(session) > 0 {
+		return fmt.Sprintf("%s@%s", id, session)
+	}
+
+	return id
+}
+
+// injectHeartbeat injects a new heartbeat into the Heartbeat field of the strategy,
+// explicit is false if the strategy does not declare the field.
+func injectHeartbeat(rs reflect.Value) (heartbeat *Heartbeat, explicit bool, err error) {
+	heartbeat = &Heartbeat{}
+	if _, ok := hasField(rs, "Heartbeat"); !ok {
+		return heartbeat, false, nil
+	}
+
+	if err := injectField(rs, "Heartbeat", heartbeat, true); err != nil {
+		return nil, false, err
+	}
+
+	return heartbeat, true, nil
+}
@@ -0,0 +1,142 @@
+package bbgo
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type watchdogTestExchange struct {
+	guardTestExchange
+
+	canceledOrders []types.Order
+}
+
+func (e *watchdogTestExchange) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	e.canceledOrders = append(e.canceledOrders, orders...)
+	return nil
+}
+
+func newWatchdogTestSession(exchange types.Exchange) *ExchangeSession {
+	session := &ExchangeSession{
+		Name:             "binance",
+		Exchange:         exchange,
+		MarketDataStream: &bootstrapTestStream{StandardStream: types.NewStandardStream()},
+		UserDataStream:   &bootstrapTestStream{StandardStream: types.NewStandardStream()},
+		markets: map[string]types.Market{
+			"BTCUSDT": {Symbol: "BTCUSDT", TickSize: 0.01, StepSize: 0.0001},
+		},
+	}
+	session.OrderExecutor = &ExchangeOrderExecutor{Session: session}
+	return session
+}
+
+func TestHeartbeat_Beat(t *testing.T) {
+	var heartbeat *Heartbeat
+	heartbeat.Beat()
+
+	heartbeat = &Heartbeat{}
+	assert.True(t, heartbeat.Last().IsZero())
+
+	heartbeat.Beat()
+	assert.False(t, heartbeat.Last().IsZero())
+}
+
+func TestWatchdog_Check(t *testing.T) {
+	ctx := context.Background()
+	exchange := &watchdogTestExchange{}
+	session := newWatchdogTestSession(exchange)
+
+	watchdog := NewWatchdog(WatchdogConfig{Timeout: types.Duration(time.Minute), CancelOrders: true}, &Notifiability{})
+
+	heartbeat := &Heartbeat{}
+	executor := watchdog.Watch("grid:BTCUSDT@binance", session, heartbeat, true, session.OrderExecutor)
+
+	now := time.Now()
+
+	// no working orders, the stalled strategy can not do any harm
+	assert.Len(t, watchdog.Check(ctx, now.Add(2*time.Minute)), 0)
+
+	_, err := executor.SubmitOrders(ctx,
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 100.0, Quantity: 1.0},
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeMarket, Quantity: 1.0},
+	)
+	assert.NoError(t, err)
+
+	// the market order is not a working order
+	assert.Len(t, executor.(*WatchdogOrderExecutor).WorkingOrders(), 1)
+
+	assert.Len(t, watchdog.Check(ctx, now.Add(30*time.Second)), 0)
+
+	stalled := watchdog.Check(ctx, now.Add(2*time.Minute))
+	assert.Equal(t, []string{"grid:BTCUSDT@binance"}, stalled)
+	assert.Equal(t, stalled, watchdog.Stalled())
+	assert.Len(t, exchange.canceledOrders, 1)
+
+	// the working orders are canceled only once
+	watchdog.Check(ctx, now.Add(3*time.Minute))
+	assert.Len(t, exchange.canceledOrders, 1)
+
+	heartbeat.Beat()
+	assert.Len(t, watchdog.Check(ctx, time.Now()), 0)
+	assert.Len(t, watchdog.Stalled(), 0)
+}
+
+func TestWatchdog_InferredProgress(t *testing.T) {
+	ctx := context.Background()
+	exchange := &watchdogTestExchange{}
+	session := newWatchdogTestSession(exchange)
+
+	watchdog := NewWatchdog(WatchdogConfig{Timeout: types.Duration(time.Minute)}, &Notifiability{})
+	executor := watchdog.Watch("grid:BTCUSDT@binance", session, &Heartbeat{}, false, session.OrderExecutor)
+	watchdog.BindSession(session)
+
+	createdOrders, err := executor.SubmitOrders(ctx,
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 100.0, Quantity: 1.0})
+	assert.NoError(t, err)
+
+	now := time.Now()
+	assert.Len(t, watchdog.Check(ctx, now.Add(2*time.Minute)), 1)
+
+	// the stream callbacks are dispatched, so the strategy callbacks are not hung
+	session.MarketDataStream.(*bootstrapTestStream).EmitKLineClosed(types.KLine{Symbol: "BTCUSDT"})
+	assert.Len(t, watchdog.Check(ctx, time.Now().Add(30*time.Second)), 0)
+
+	// the filled order is removed from the working orders
+	filledOrder := createdOrders[0]
+	filledOrder.Status = types.OrderStatusFilled
+	session.UserDataStream.(*bootstrapTestStream).EmitOrderUpdate(filledOrder)
+	assert.Len(t, watchdog.Check(ctx, time.Now().Add(2*time.Minute)), 0)
+
+	// the cancellation is not enabled
+	assert.Len(t, exchange.canceledOrders, 0)
+}
+
+func Test_injectHeartbeat(t *testing.T) {
+	type withHeartbeat struct {
+		Heartbeat *Heartbeat
+	}
+
+	s := &withHeartbeat{}
+	heartbeat, explicit, err := injectHeartbeat(reflect.ValueOf(s).Elem())
+	assert.NoError(t, err)
+	assert.True(t, explicit)
+	assert.Equal(t, heartbeat, s.Heartbeat)
+
+	type withoutHeartbeat struct{}
+	heartbeat, explicit, err = injectHeartbeat(reflect.ValueOf(&withoutHeartbeat{}).Elem())
+	assert.NoError(t, err)
+	assert.False(t, explicit)
+	assert.NotNil(t, heartbeat)
+}
+
+func Test_watchdogStrategyID(t *testing.T) {
+	assert.Equal(t, "grid:BTCUSDT@binance", watchdogStrategyID("grid", "BTCUSDT", "binance"))
+	assert.Equal(t, "xnav@max", watchdogStrategyID("xnav", "", "max"))
+	assert.Equal(t, "xarb", watchdogStrategyID("xarb", "", ""))
+}
@@ -0,0 +1,116 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var ErrWithdrawalDisabled = errors.New("withdrawal is not enabled in the session config")
+
+var ErrWithdrawalAddressNotWhitelisted = errors.New("withdrawal address is not whitelisted")
+
+// WithdrawalAddress is a whitelisted withdrawal address of an asset, for example:
+//
+//	sessions:
+//	  binance:
+//	    exchange: binance
+//	    withdrawal: true
+//	    withdrawalAddresses:
+//	      USDT:
+//	      - address: "0x...."
+//	        network: ETH
+type WithdrawalAddress struct {
+	Address    string `json:"address" yaml:"address"`
+	AddressTag string `json:"addressTag,omitempty" yaml:"addressTag,omitempty"`
+	Network    string `json:"network,omitempty" yaml:"network,omitempty"`
+}
+
+func (a WithdrawalAddress) Validate() error {
+	if len(a.Address) == 0 {
+		return errors.New("withdrawal address can not be empty")
+	}
+
+	return nil
+}
+
+// matches checks the address, the address tag and the network, the empty network of the whitelisted address
+// matches any network
+func (a WithdrawalAddress) matches(address string, options *types.WithdrawalOptions) bool {
+	if a.Address != address {
+		return false
+	}
+
+	var tag, network string
+	if options != nil {
+		tag, network = options.AddressTag, options.Network
+	}
+
+	if a.AddressTag != tag {
+		return false
+	}
+
+	return len(a.Network) == 0 || strings.EqualFold(a.Network, network)
+}
+
+// FindWithdrawalAddress finds the whitelisted withdrawal address of the asset
+func (session *ExchangeSession) FindWithdrawalAddress(asset, address string, options *types.WithdrawalOptions) (WithdrawalAddress, bool) {
+	for currency, addresses := range session.WithdrawalAddresses {
+		if !strings.EqualFold(currency, asset) {
+			continue
+		}
+
+		for _, a := range addresses {
+			if a.matches(address, options) {
+				return a, true
+			}
+		}
+	}
+
+	return WithdrawalAddress{}, false
+}
+
+// Withdraw submits the withdrawal to a whitelisted address, the withdrawal must be enabled in the session config
+func (session *ExchangeSession) Withdraw(ctx context.Context, asset string, amount fixedpoint.Value, address string, options *types.WithdrawalOptions) error {
+	if !session.Withdrawal {
+		return errors.Wrapf(ErrWithdrawalDisabled, "session %s", session.Name)
+	}
+
+	if amount <= 0 {
+		return fmt.Errorf("invalid withdrawal amount %f", amount.Float64())
+	}
+
+	if _, ok := session.FindWithdrawalAddress(asset, address, options); !ok {
+		return errors.Wrapf(ErrWithdrawalAddressNotWhitelisted, "%s address %s of session %s", asset, address, session.Name)
+	}
+
+	service, ok := session.Exchange.(types.ExchangeWithdrawalService)
+	if !ok || !session.Capabilities().Withdrawal {
+		return fmt.Errorf("exchange %s does not support withdrawal", session.ExchangeName)
+	}
+
+	return service.Withdrawal(ctx, asset, amount, address, options)
+}
+
+// TransferWallet moves the asset between the wallets of the session exchange account, e.g., from spot to margin
+func (session *ExchangeSession) TransferWallet(ctx context.Context, asset string, amount fixedpoint.Value, from, to types.WalletType) error {
+	if amount <= 0 {
+		return fmt.Errorf("invalid transfer amount %f", amount.Float64())
+	}
+
+	if from == to {
+		return fmt.Errorf("the source and the destination wallets are the same: %s", from)
+	}
+
+	service, ok := session.Exchange.(types.ExchangeWalletTransferService)
+	if !ok || !session.Capabilities().WalletTransfer {
+		return fmt.Errorf("exchange %s does not support wallet transfer", session.ExchangeName)
+	}
+
+	return service.TransferWallet(ctx, asset, amount, from, to)
+}
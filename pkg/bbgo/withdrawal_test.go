@@ -0,0 +1,78 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type withdrawalTestExchange struct {
+	types.Exchange
+
+	withdrawals []string
+	transfers   []types.WalletType
+}
+
+func (e *withdrawalTestExchange) Withdrawal(ctx context.Context, asset string, amount fixedpoint.Value, address string, options *types.WithdrawalOptions) error {
+	e.withdrawals = append(e.withdrawals, address)
+	return nil
+}
+
+func (e *withdrawalTestExchange) TransferWallet(ctx context.Context, asset string, amount fixedpoint.Value, from, to types.WalletType) error {
+	e.transfers = append(e.transfers, to)
+	return nil
+}
+
+func TestExchangeSession_Withdraw(t *testing.T) {
+	ctx := context.Background()
+	exchange := &withdrawalTestExchange{}
+	session := &ExchangeSession{
+		Name:     "binance",
+		Exchange: exchange,
+		WithdrawalAddresses: map[string][]WithdrawalAddress{
+			"USDT": {{Address: "0xabc", Network: "ETH"}},
+			"XRP":  {{Address: "rXYZ", AddressTag: "1234"}},
+		},
+	}
+
+	err := session.Withdraw(ctx, "USDT", fixedpoint.NewFromFloat(100.0), "0xabc", &types.WithdrawalOptions{Network: "ETH"})
+	assert.True(t, errors.Is(err, ErrWithdrawalDisabled))
+
+	session.Withdrawal = true
+	err = session.Withdraw(ctx, "USDT", fixedpoint.NewFromFloat(100.0), "0xabc", &types.WithdrawalOptions{Network: "eth"})
+	assert.NoError(t, err)
+
+	err = session.Withdraw(ctx, "USDT", fixedpoint.NewFromFloat(100.0), "0xabc", &types.WithdrawalOptions{Network: "TRX"})
+	assert.True(t, errors.Is(err, ErrWithdrawalAddressNotWhitelisted), "the network does not match")
+
+	err = session.Withdraw(ctx, "BTC", fixedpoint.NewFromFloat(1.0), "0xabc", nil)
+	assert.True(t, errors.Is(err, ErrWithdrawalAddressNotWhitelisted), "the address is whitelisted for USDT only")
+
+	err = session.Withdraw(ctx, "xrp", fixedpoint.NewFromFloat(10.0), "rXYZ", nil)
+	assert.True(t, errors.Is(err, ErrWithdrawalAddressNotWhitelisted), "the address tag does not match")
+
+	err = session.Withdraw(ctx, "xrp", fixedpoint.NewFromFloat(10.0), "rXYZ", &types.WithdrawalOptions{AddressTag: "1234"})
+	assert.NoError(t, err)
+
+	assert.Error(t, session.Withdraw(ctx, "USDT", 0, "0xabc", &types.WithdrawalOptions{Network: "ETH"}))
+	assert.Equal(t, []string{"0xabc", "rXYZ"}, exchange.withdrawals)
+}
+
+func TestExchangeSession_TransferWallet(t *testing.T) {
+	ctx := context.Background()
+	exchange := &withdrawalTestExchange{}
+	session := &ExchangeSession{Name: "binance", Exchange: exchange}
+
+	assert.NoError(t, session.TransferWallet(ctx, "USDT", fixedpoint.NewFromFloat(100.0), types.WalletTypeSpot, types.WalletTypeMargin))
+	assert.Error(t, session.TransferWallet(ctx, "USDT", fixedpoint.NewFromFloat(100.0), types.WalletTypeSpot, types.WalletTypeSpot))
+	assert.Error(t, session.TransferWallet(ctx, "USDT", 0, types.WalletTypeSpot, types.WalletTypeFutures))
+	assert.Equal(t, []types.WalletType{types.WalletTypeMargin}, exchange.transfers)
+
+	session.Exchange = &killSwitchTestExchange{}
+	assert.Error(t, session.TransferWallet(ctx, "USDT", fixedpoint.NewFromFloat(100.0), types.WalletTypeSpot, types.WalletTypeMargin))
+}
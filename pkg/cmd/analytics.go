@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func init() {
+	analyticsCmd.Flags().String("exchange", "binance", "the exchange of the stored klines")
+	analyticsCmd.Flags().StringSlice("symbols", nil, "the symbols to analyze, e.g., --symbols BTCUSDT,ETHUSDT")
+	analyticsCmd.Flags().String("interval", "1d", "the kline interval")
+	analyticsCmd.Flags().Int("window", 30, "the number of the klines")
+	RootCmd.AddCommand(analyticsCmd)
+}
+
+// go run ./cmd/bbgo analytics --exchange=binance --symbols BTCUSDT,ETHUSDT --interval 1d --window 30
+var analyticsCmd = &cobra.Command{
+	Use:          "analytics",
+	Short:        "report the volatility and the correlation matrix of the symbols from the stored klines",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		exName, err := cmd.Flags().GetString("exchange")
+		if err != nil {
+			return err
+		}
+
+		exchangeName, err := types.ValidExchangeName(exName)
+		if err != nil {
+			return err
+		}
+
+		symbols, err := cmd.Flags().GetStringSlice("symbols")
+		if err != nil {
+			return err
+		}
+
+		if len(symbols) == 0 {
+			return errors.New("--symbols option is required")
+		}
+
+		intervalStr, err := cmd.Flags().GetString("interval")
+		if err != nil {
+			return err
+		}
+
+		interval := types.Interval(intervalStr)
+		if interval.Duration() == 0 {
+			return fmt.Errorf("invalid interval %s", intervalStr)
+		}
+
+		window, err := cmd.Flags().GetInt("window")
+		if err != nil {
+			return err
+		}
+
+		environ := bbgo.NewEnvironment()
+		if err := environ.ConfigureDefaultDatabase(ctx); err != nil {
+			return err
+		}
+
+		if environ.AnalyticsService == nil {
+			return errors.New("database is not configured, the analytics is calculated from the stored klines")
+		}
+
+		endTime := time.Now()
+
+		fmt.Printf("%-14s %10s\n", "SYMBOL", "VOLATILITY")
+		for _, symbol := range symbols {
+			vol, err := environ.AnalyticsService.Volatility(exchangeName, symbol, interval, endTime, window)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("%-14s %9.2f%%\n", symbol, vol*100.0)
+		}
+
+		if len(symbols) < 2 {
+			return nil
+		}
+
+		matrix, err := environ.AnalyticsService.CorrelationMatrix(exchangeName, symbols, interval, endTime, window)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println()
+		fmt.Printf("%-14s", "CORRELATION")
+		for _, symbol := range matrix.Symbols {
+			fmt.Printf(" %10s", symbol)
+		}
+		fmt.Println()
+
+		for i, symbol := range matrix.Symbols {
+			fmt.Printf("%-14s", symbol)
+			for _, c := range matrix.Values[i] {
+				fmt.Printf(" %10.3f", c)
+			}
+			fmt.Println()
+		}
+
+		return nil
+	},
+}
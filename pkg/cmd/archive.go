@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/exchange/binance"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func init() {
+	DownloadArchiveCmd.Flags().String("symbol", "", "the trading pair. e.g, BTCUSDT")
+	DownloadArchiveCmd.Flags().StringSlice("interval", []string{"1m"}, "the kline intervals to download")
+	DownloadArchiveCmd.Flags().Bool("agg-trades", false, "download the aggregated trades instead of the klines")
+	DownloadArchiveCmd.Flags().String("since", "", "download from the date, e.g., 2019-01-01")
+	DownloadArchiveCmd.Flags().String("until", "", "download until the date (exclusive), defaults to today")
+	RootCmd.AddCommand(DownloadArchiveCmd)
+}
+
+// go run ./cmd/bbgo download-archive --symbol BTCUSDT --interval 1m,1h --since 2019-01-01
+var DownloadArchiveCmd = &cobra.Command{
+	Use:          "download-archive",
+	Short:        "download the binance public data archives into the backtest database",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		symbol, err := cmd.Flags().GetString("symbol")
+		if err != nil {
+			return err
+		}
+
+		if len(symbol) == 0 {
+			return errors.New("--symbol option is required")
+		}
+
+		intervals, err := cmd.Flags().GetStringSlice("interval")
+		if err != nil {
+			return err
+		}
+
+		wantAggTrades, err := cmd.Flags().GetBool("agg-trades")
+		if err != nil {
+			return err
+		}
+
+		startTime, err := parseArchiveDateFlag(cmd, "since", time.Now().AddDate(-1, 0, 0))
+		if err != nil {
+			return err
+		}
+
+		endTime, err := parseArchiveDateFlag(cmd, "until", time.Now())
+		if err != nil {
+			return err
+		}
+
+		environ := bbgo.NewEnvironment()
+		if err := environ.ConfigureDefaultDatabase(ctx); err != nil {
+			return err
+		}
+
+		if environ.DatabaseService == nil {
+			return errors.New("database is not configured, please set up the DB_DRIVER and DB_DSN environment variables")
+		}
+
+		db := environ.DatabaseService.DB
+		downloader := binance.NewArchiveDownloader()
+
+		if wantAggTrades {
+			return downloadAggTradeArchives(ctx, downloader, &service.AggTradeService{DB: db}, symbol, startTime, endTime)
+		}
+
+		backtestService := &service.BacktestService{DB: db}
+		for _, interval := range intervals {
+			if err := downloadKLineArchives(ctx, downloader, backtestService, symbol, types.Interval(interval), startTime, endTime); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+}
+
+func parseArchiveDateFlag(cmd *cobra.Command, name string, defaultTime time.Time) (time.Time, error) {
+	value, err := cmd.Flags().GetString(name)
+	if err != nil {
+		return defaultTime, err
+	}
+
+	if len(value) == 0 {
+		return defaultTime, nil
+	}
+
+	return time.ParseInLocation("2006-01-02", value, time.UTC)
+}
+
+// forEachArchive iterates the archives in the time range, the monthly archive is used for the whole month,
+// the daily archives are used for the partial month and for the months that the monthly archive is not published yet.
+func forEachArchive(startTime, endTime time.Time, download func(period binance.ArchivePeriod, date time.Time) error) error {
+	startTime = time.Date(startTime.Year(), startTime.Month(), startTime.Day(), 0, 0, 0, 0, time.UTC)
+
+	for date := startTime; date.Before(endTime); {
+		nextMonth := time.Date(date.Year(), date.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+
+		if date.Day() == 1 && !nextMonth.After(endTime) {
+			err := download(binance.ArchivePeriodMonthly, date)
+			if err == nil {
+				date = nextMonth
+				continue
+			}
+
+			if !errors.Is(err, binance.ErrArchiveNotFound) {
+				return err
+			}
+
+			log.Infof("monthly archive of %s is not found, falling back to the daily archives", date.Format("2006-01"))
+		}
+
+		for ; date.Before(nextMonth) && date.Before(endTime); date = date.AddDate(0, 0, 1) {
+			if err := download(binance.ArchivePeriodDaily, date); err != nil {
+				if errors.Is(err, binance.ErrArchiveNotFound) {
+					log.Warnf("daily archive of %s is not found, skipping", date.Format("2006-01-02"))
+					continue
+				}
+
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func downloadKLineArchives(ctx context.Context, downloader *binance.ArchiveDownloader, backtestService *service.BacktestService, symbol string, interval types.Interval, startTime, endTime time.Time) error {
+	lastKLine, err := backtestService.QueryLastKLine(types.ExchangeBinance, symbol, interval)
+	if err != nil {
+		return err
+	}
+
+	// resume from the last stored kline
+	if lastKLine != nil && lastKLine.StartTime.After(startTime) {
+		startTime = lastKLine.StartTime
+	}
+
+	return forEachArchive(startTime, endTime, func(period binance.ArchivePeriod, date time.Time) error {
+		log.Infof("downloading %s %s %s klines archive of %s...", period, symbol, interval, date.Format("2006-01-02"))
+
+		return downloader.DownloadKLines(ctx, symbol, interval, period, date, func(klines []types.KLine) error {
+			var filtered = make([]types.KLine, 0, len(klines))
+			for _, kline := range klines {
+				if lastKLine != nil && !kline.StartTime.After(lastKLine.StartTime) {
+					continue
+				}
+
+				if !kline.StartTime.Before(endTime) {
+					continue
+				}
+
+				filtered = append(filtered, kline)
+			}
+
+			return backtestService.BatchInsert(filtered)
+		})
+	})
+}
+
+func downloadAggTradeArchives(ctx context.Context, downloader *binance.ArchiveDownloader, aggTradeService *service.AggTradeService, symbol string, startTime, endTime time.Time) error {
+	lastTrade, err := aggTradeService.QueryLast(types.ExchangeBinance, symbol)
+	if err != nil {
+		return err
+	}
+
+	// resume from the last stored trade
+	if lastTrade != nil && lastTrade.Time.Time().After(startTime) {
+		startTime = lastTrade.Time.Time()
+	}
+
+	return forEachArchive(startTime, endTime, func(period binance.ArchivePeriod, date time.Time) error {
+		log.Infof("downloading %s %s aggregated trades archive of %s...", period, symbol, date.Format("2006-01-02"))
+
+		return downloader.DownloadAggTrades(ctx, symbol, period, date, func(trades []types.AggTrade) error {
+			var filtered = make([]types.AggTrade, 0, len(trades))
+			for _, trade := range trades {
+				if lastTrade != nil && trade.ID <= lastTrade.ID {
+					continue
+				}
+
+				if !trade.Time.Time().Before(endTime) {
+					continue
+				}
+
+				filtered = append(filtered, trade)
+			}
+
+			return aggTradeService.BatchInsert(filtered)
+		})
+	})
+}
@@ -209,6 +209,13 @@ var BacktestCmd = &cobra.Command{
 						firstKLine.EndTime)
 				}
 
+				if len(userConfig.Backtest.CompactIntervals) > 0 {
+					if err := syncAndCompactKLines(ctx, backtestService, sourceExchange, symbol, syncFromTime, userConfig.Backtest.CompactIntervals, shouldVerify); err != nil {
+						return err
+					}
+					continue
+				}
+
 				if err := backtestService.Sync(ctx, sourceExchange, symbol, syncFromTime); err != nil {
 					return err
 				}
@@ -426,3 +433,36 @@ func confirmation(s string) bool {
 		}
 	}
 }
+
+// syncAndCompactKLines syncs the 1m klines from the exchange and builds the klines of the compact intervals locally,
+// the compacted klines are verified with the exchange klines if verify is true.
+func syncAndCompactKLines(ctx context.Context, backtestService *service.BacktestService, exchange types.Exchange, symbol string, syncFromTime time.Time, intervals []types.Interval, verify bool) error {
+	if err := backtestService.SyncKLineByInterval(ctx, exchange, symbol, types.Interval1m, syncFromTime, time.Now()); err != nil {
+		return err
+	}
+
+	compactor := &service.KLineCompactor{Store: backtestService}
+	for _, interval := range intervals {
+		kLines, err := compactor.Compact(ctx, exchange.Name(), symbol, interval)
+		if err != nil {
+			return err
+		}
+
+		if !verify {
+			continue
+		}
+
+		mismatches, err := compactor.Verify(ctx, exchange, kLines, 0.0001)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range mismatches {
+			log.Warn(m.String())
+		}
+
+		log.Infof("verified %d compacted %s %s klines, found %d mismatches", len(kLines), symbol, interval, len(mismatches))
+	}
+
+	return nil
+}
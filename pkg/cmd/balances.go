@@ -2,29 +2,33 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 
 	"github.com/pkg/errors"
-	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/types"
 )
 
 func init() {
-	balancesCmd.Flags().String("session", "", "the exchange session name for querying balances")
+	balancesCmd.Flags().String("session", "", "the exchange session name for querying balances, all sessions are aggregated if it's not set")
+	balancesCmd.Flags().String("currency", "", "the reference currency of the value, defaults to the currency of the currencyConversion config or USDT")
+	balancesCmd.Flags().Bool("json", false, "print the balances in json")
 	RootCmd.AddCommand(balancesCmd)
 }
 
 // go run ./cmd/bbgo balances --session=ftx
+// go run ./cmd/bbgo balances --currency=USDT --json
 var balancesCmd = &cobra.Command{
 	Use:          "balances",
+	Short:        "show the balances of the sessions aggregated by the asset with the value in the reference currency",
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
 
-
 		configFile, err := cmd.Flags().GetString("config")
 		if err != nil {
 			return err
@@ -39,6 +43,15 @@ var balancesCmd = &cobra.Command{
 			return err
 		}
 
+		currency, err := cmd.Flags().GetString("currency")
+		if err != nil {
+			return err
+		}
+
+		printJSON, err := cmd.Flags().GetBool("json")
+		if err != nil {
+			return err
+		}
 
 		// if config file exists, use the config loaded from the config file.
 		// otherwise, use a empty config object
@@ -63,33 +76,55 @@ var balancesCmd = &cobra.Command{
 			return err
 		}
 
-
+		var sessions = environ.Sessions()
 		if len(sessionName) > 0 {
 			session, ok := environ.Session(sessionName)
 			if !ok {
 				return fmt.Errorf("session %s not found", sessionName)
 			}
 
-			b, err := session.Exchange.QueryAccountBalances(ctx)
-			if err != nil {
+			sessions = map[string]*bbgo.ExchangeSession{sessionName: session}
+		}
+
+		var conversionConfig bbgo.CurrencyConversionConfig
+		if userConfig.CurrencyConversion != nil {
+			if err := userConfig.CurrencyConversion.Validate(); err != nil {
 				return err
 			}
 
-			b.Print()
-		} else {
-			for _, session := range environ.Sessions() {
+			conversionConfig = *userConfig.CurrencyConversion
+		}
+
+		var balances = make(map[string]types.BalanceMap)
+		for name, session := range sessions {
+			b, err := session.Exchange.QueryAccountBalances(ctx)
+			if err != nil {
+				return errors.Wrapf(err, "can not query the balances of session %s", name)
+			}
 
-				b, err := session.Exchange.QueryAccountBalances(ctx)
-				if err != nil {
-					return err
-				}
+			balances[name] = b
 
-				log.Infof("SESSION %s", session.Name)
-				b.Print()
+			// the markets are used to convert the assets into the reference currency
+			markets, err := session.Exchange.QueryMarkets(ctx)
+			if err != nil {
+				return errors.Wrapf(err, "can not query the markets of session %s", name)
 			}
+
+			session.SetMarkets(markets)
 		}
 
+		converter := bbgo.NewCurrencyConverter(conversionConfig, sessions)
+		if len(currency) == 0 {
+			currency = converter.Currency
+		}
+
+		report := bbgo.AggregateBalances(ctx, converter, currency, balances)
+		if printJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(report)
+		}
 
-		return nil
+		return report.WriteTable(os.Stdout)
 	},
 }
@@ -158,13 +158,13 @@ var cancelOrderCmd = &cobra.Command{
 					}
 				}
 			} else if len(symbol) > 0 {
-				openOrders, err := session.Exchange.QueryOpenOrders(ctx, symbol)
+				orders, err := session.CancelAllOrders(ctx, symbol)
 				if err != nil {
 					return err
 				}
 
-				if err := session.Exchange.CancelOrders(ctx, openOrders...); err != nil {
-					return err
+				for _, o := range orders {
+					log.Info("CANCELED ", o.String())
 				}
 			} else {
 				log.Error("unsupported operation")
@@ -6,6 +6,8 @@ import (
 	"strings"
 
 	"github.com/c9s/bbgo/pkg/exchange/binance"
+	"github.com/c9s/bbgo/pkg/exchange/bitfinex"
+	"github.com/c9s/bbgo/pkg/exchange/ccxt"
 	"github.com/c9s/bbgo/pkg/exchange/ftx"
 	"github.com/c9s/bbgo/pkg/exchange/max"
 	"github.com/c9s/bbgo/pkg/exchange/okex"
@@ -27,6 +29,9 @@ func NewExchangeStandard(n types.ExchangeName, key, secret, passphrase, subAccou
 	case types.ExchangeOKEx:
 		return okex.New(key, secret, passphrase), nil
 
+	case types.ExchangeBitfinex:
+		return bitfinex.New(key, secret), nil
+
 	default:
 		return nil, fmt.Errorf("unsupported exchange: %v", n)
 
@@ -51,6 +56,24 @@ func NewExchangeWithEnvVarPrefix(n types.ExchangeName, varPrefix string) (types.
 	return NewExchangeStandard(n, key, secret, passphrase, subAccount)
 }
 
+// NewCCXTExchange creates the ccxt bridge exchange, if the key and the secret are empty,
+// the credentials are loaded from the env vars with the prefix (defaults to CCXT).
+func NewCCXTExchange(config ccxt.Config, key, secret, varPrefix string) (types.Exchange, error) {
+	var passphrase string
+	if len(key) == 0 || len(secret) == 0 {
+		if len(varPrefix) == 0 {
+			varPrefix = types.ExchangeCCXT.String()
+		}
+
+		varPrefix = strings.ToUpper(varPrefix)
+		key = os.Getenv(varPrefix + "_API_KEY")
+		secret = os.Getenv(varPrefix + "_API_SECRET")
+		passphrase = os.Getenv(varPrefix + "_API_PASSPHRASE")
+	}
+
+	return ccxt.New(config, key, secret, passphrase)
+}
+
 // NewExchange constructor exchange object from viper config.
 func NewExchange(n types.ExchangeName) (types.Exchange, error) {
 	return NewExchangeWithEnvVarPrefix(n, "")
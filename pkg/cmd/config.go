@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+)
+
+func init() {
+	configValidateCmd.Flags().Bool("strict", false, "treat the warnings as errors")
+	configCmd.AddCommand(configValidateCmd)
+	RootCmd.AddCommand(configCmd)
+}
+
+var configCmd = &cobra.Command{
+	Use:          "config",
+	Short:        "config file utilities",
+	SilenceUsage: true,
+
+	// the config file is not loaded here, the broken config should be reported by the sub-commands
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return loadDotenv(cmd)
+	},
+}
+
+// go run ./cmd/bbgo config validate --config bbgo.yaml
+var configValidateCmd = &cobra.Command{
+	Use:          "validate",
+	Short:        "validate the config file without connecting to the exchanges",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configFile, err := cmd.Flags().GetString("config")
+		if err != nil {
+			return err
+		}
+
+		strict, err := cmd.Flags().GetBool("strict")
+		if err != nil {
+			return err
+		}
+
+		if len(configFile) == 0 {
+			return fmt.Errorf("--config option is required")
+		}
+
+		issues, err := bbgo.ValidateConfigFile(configFile)
+		if err != nil {
+			return err
+		}
+
+		for _, issue := range issues {
+			fmt.Fprintln(os.Stderr, issue.String())
+		}
+
+		if issues.HasErrors() || (strict && len(issues) > 0) {
+			return fmt.Errorf("%s: %d issue(s) found", configFile, len(issues))
+		}
+
+		fmt.Printf("%s is valid\n", configFile)
+		return nil
+	},
+}
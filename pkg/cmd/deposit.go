@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"text/tabwriter"
 	"time"
 
 	"github.com/pkg/errors"
@@ -18,6 +19,79 @@ func init() {
 	depositsCmd.Flags().String("session", "", "the exchange session name for querying balances")
 	depositsCmd.Flags().String("asset", "", "the trading pair, like btcusdt")
 	RootCmd.AddCommand(depositsCmd)
+
+	depositAddressCmd.Flags().String("session", "", "the exchange session name, all the sessions supporting the deposit address query are used if it's not set")
+	depositAddressCmd.Flags().String("asset", "", "the asset, like USDT")
+	depositAddressCmd.Flags().String("network", "", "the network, like ETH or TRX, the default network of the asset is used if it's not set")
+	RootCmd.AddCommand(depositAddressCmd)
+}
+
+// go run ./cmd/bbgo deposit-address --asset=USDT --network=TRX
+var depositAddressCmd = &cobra.Command{
+	Use:          "deposit-address",
+	Short:        "query the deposit address of the asset from the sessions",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		if userConfig == nil {
+			return errors.New("config file is required")
+		}
+
+		sessionName, err := cmd.Flags().GetString("session")
+		if err != nil {
+			return err
+		}
+
+		asset, err := cmd.Flags().GetString("asset")
+		if err != nil {
+			return err
+		}
+
+		if len(asset) == 0 {
+			return errors.New("--asset is required")
+		}
+
+		network, err := cmd.Flags().GetString("network")
+		if err != nil {
+			return err
+		}
+
+		environ := bbgo.NewEnvironment()
+		if err := environ.ConfigureExchangeSessions(userConfig); err != nil {
+			return err
+		}
+
+		var sessions = environ.Sessions()
+		if len(sessionName) > 0 {
+			session, ok := environ.Session(sessionName)
+			if !ok {
+				return fmt.Errorf("session %s not found", sessionName)
+			}
+
+			sessions = map[string]*bbgo.ExchangeSession{sessionName: session}
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(w, "SESSION\tASSET\tNETWORK\tADDRESS\tTAG\n")
+		for _, name := range sortedSessionNames(sessions) {
+			session := sessions[name]
+
+			// skip the unsupported sessions only when the session is not specified
+			if len(sessionName) == 0 && !session.Capabilities().DepositAddress {
+				continue
+			}
+
+			address, err := session.QueryDepositAddress(ctx, asset, network)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", name, address.Asset, address.Network, address.Address, address.AddressTag)
+		}
+
+		return w.Flush()
+	},
 }
 
 // go run ./cmd/bbgo deposits --session=ftx --asset="BTC"
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func init() {
+	exportCmd.Flags().String("session", "", "the exchange session of the synced data")
+	exportCmd.Flags().String("symbol", "", "the trading symbol, e.g., BTCUSDT")
+	exportCmd.Flags().String("type", "trades", "the data type to export: trades or klines")
+	exportCmd.Flags().String("interval", "1m", "the kline interval, only for --type klines")
+	exportCmd.Flags().String("from", "", "the start time, e.g., 2021-05-01")
+	exportCmd.Flags().String("to", "", "the end time, e.g., 2021-06-01, defaults to now")
+	exportCmd.Flags().String("format", "csv", "the output format: csv or jsonl, parquet is not supported by this build")
+	exportCmd.Flags().String("output", "", "the output file, defaults to stdout")
+	RootCmd.AddCommand(exportCmd)
+}
+
+// go run ./cmd/bbgo export --config config/bbgo.yaml --session binance --symbol BTCUSDT --type trades --from 2021-05-01 --output trades.csv
+var exportCmd = &cobra.Command{
+	Use:          "export",
+	Short:        "export the synced trades or klines for the analysis",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		configFile, err := cmd.Flags().GetString("config")
+		if err != nil {
+			return err
+		}
+
+		if len(configFile) == 0 {
+			return errors.New("--config option is required")
+		}
+
+		sessionName, err := cmd.Flags().GetString("session")
+		if err != nil {
+			return err
+		}
+
+		symbol, err := cmd.Flags().GetString("symbol")
+		if err != nil {
+			return err
+		}
+
+		if len(symbol) == 0 {
+			return errors.New("--symbol option is required")
+		}
+
+		dataType, err := cmd.Flags().GetString("type")
+		if err != nil {
+			return err
+		}
+
+		interval, err := cmd.Flags().GetString("interval")
+		if err != nil {
+			return err
+		}
+
+		from, err := cmd.Flags().GetString("from")
+		if err != nil {
+			return err
+		}
+
+		if len(from) == 0 {
+			return errors.New("--from option is required")
+		}
+
+		since, err := bbgo.ParseTime(from)
+		if err != nil {
+			return err
+		}
+
+		until := time.Now()
+		to, err := cmd.Flags().GetString("to")
+		if err != nil {
+			return err
+		}
+
+		if len(to) > 0 {
+			until, err = bbgo.ParseTime(to)
+			if err != nil {
+				return err
+			}
+		}
+
+		if until.Before(since) {
+			return fmt.Errorf("the end time %s is before the start time %s", until, since)
+		}
+
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+
+		outputFile, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+
+		var columns []string
+		switch dataType {
+		case "trades":
+			columns = bbgo.TradeExportColumns
+		case "klines":
+			if _, ok := types.SupportedIntervals[types.Interval(interval)]; !ok {
+				return fmt.Errorf("unsupported interval %s", interval)
+			}
+
+			columns = bbgo.KLineExportColumns
+		default:
+			return fmt.Errorf("unsupported export type %s, valid types are trades and klines", dataType)
+		}
+
+		// check the format before creating the output file
+		if _, err := bbgo.NewExportWriter(bbgo.ExportFormat(format), ioutil.Discard, columns); err != nil {
+			return err
+		}
+
+		userConfig, err := bbgo.Load(configFile, false)
+		if err != nil {
+			return err
+		}
+
+		environ := bbgo.NewEnvironment()
+		if err := environ.ConfigureDefaultDatabase(ctx); err != nil {
+			return err
+		}
+
+		if environ.DatabaseService == nil {
+			return errors.New("database service is not enabled, please check your environment variables DB_DRIVER and DB_DSN")
+		}
+
+		if err := environ.ConfigureExchangeSessions(userConfig); err != nil {
+			return err
+		}
+
+		session, ok := environ.Session(sessionName)
+		if !ok {
+			return fmt.Errorf("session %s not found", sessionName)
+		}
+
+		var out io.Writer = os.Stdout
+		if len(outputFile) > 0 {
+			file, err := os.Create(outputFile)
+			if err != nil {
+				return err
+			}
+
+			defer file.Close()
+			out = file
+		}
+
+		writer, err := bbgo.NewExportWriter(bbgo.ExportFormat(format), out, columns)
+		if err != nil {
+			return err
+		}
+
+		var count int
+		switch dataType {
+		case "trades":
+			tradeC, errC := environ.TradeService.QueryTradesCh(ctx, service.QueryTradesInRangeOptions{
+				Exchange:   session.ExchangeName,
+				Symbol:     symbol,
+				Since:      since,
+				Until:      until,
+				IsMargin:   session.Margin,
+				IsFutures:  session.Futures,
+				IsIsolated: session.IsolatedMargin || session.IsolatedFutures,
+			})
+			count, err = bbgo.ExportTrades(writer, tradeC, errC)
+
+		case "klines":
+			backtestService := &service.BacktestService{DB: environ.DatabaseService.DB}
+			klineC, errC := backtestService.QueryKLinesCh(since, until, session.Exchange, []string{symbol}, []types.Interval{types.Interval(interval)})
+			count, err = bbgo.ExportKLines(writer, klineC, errC)
+		}
+
+		if err != nil {
+			return errors.Wrapf(err, "export failed after %d rows", count)
+		}
+
+		log.Infof("exported %d %s %s %s from %s to %s", count, sessionName, symbol, dataType, since.Format(time.RFC3339), until.Format(time.RFC3339))
+		return nil
+	},
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+)
+
+func init() {
+	generateStrategyCmd.Flags().String("dir", ".", "the root directory of the repository")
+	generateStrategyCmd.Flags().String("module", "", "the go module path, defaults to the module of go.mod in the root directory")
+
+	generateCmd.AddCommand(generateStrategyCmd)
+	RootCmd.AddCommand(generateCmd)
+}
+
+var generateCmd = &cobra.Command{
+	Use:          "generate",
+	Short:        "generate the code skeletons",
+	SilenceUsage: true,
+}
+
+// go run ./cmd/bbgo generate strategy mystrat
+var generateStrategyCmd = &cobra.Command{
+	Use:   "strategy [name]",
+	Short: "generate the package skeleton of a new built-in strategy and import it in pkg/cmd/builtin.go",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rootDir, err := cmd.Flags().GetString("dir")
+		if err != nil {
+			return err
+		}
+
+		module, err := cmd.Flags().GetString("module")
+		if err != nil {
+			return err
+		}
+
+		scaffold := &bbgo.StrategyScaffold{
+			Name:    args[0],
+			Module:  module,
+			RootDir: rootDir,
+		}
+
+		files, err := scaffold.Generate()
+		for _, file := range files {
+			log.Infof("generated %s", file)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		log.Infof("strategy %s is generated, run the unit test with: go test ./pkg/strategy/%s/...", scaffold.Name, scaffold.Name)
+		return nil
+	},
+}
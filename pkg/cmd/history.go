@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func init() {
+	historyCmd.PersistentFlags().String("session", "", "the exchange session name of the records, the session is resolved from the config file")
+	historyCmd.PersistentFlags().String("exchange", "", "the exchange name of the records, e.g., binance")
+	historyCmd.PersistentFlags().String("symbol", "", "the trading pair, like btcusdt")
+	historyCmd.PersistentFlags().String("side", "", "the trading side: buy or sell")
+	historyCmd.PersistentFlags().String("since", "", "the start date of the records, e.g., 2021-12-01")
+	historyCmd.PersistentFlags().String("until", "", "the end date (exclusive) of the records")
+	historyCmd.PersistentFlags().Int("limit", 100, "the max number of the records")
+
+	historyCmd.AddCommand(historyTradesCmd, historyOrdersCmd, historySummaryCmd)
+	RootCmd.AddCommand(historyCmd)
+}
+
+// historyCmd queries the trades and the orders synced into the database
+var historyCmd = &cobra.Command{
+	Use:          "history",
+	Short:        "query the synced trades and orders from the database",
+	SilenceUsage: true,
+}
+
+// go run ./cmd/bbgo history trades --session=binance --symbol=BTCUSDT --since=2021-12-01
+var historyTradesCmd = &cobra.Command{
+	Use:   "trades",
+	Short: "list the synced trades",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		environ, options, err := prepareHistoryQuery(cmd)
+		if err != nil {
+			return err
+		}
+
+		trades, err := environ.TradeService.Query(options)
+		if err != nil {
+			return err
+		}
+
+		for _, trade := range trades {
+			fmt.Println(trade.PlainText())
+		}
+
+		return nil
+	},
+}
+
+// go run ./cmd/bbgo history orders --session=binance --symbol=BTCUSDT
+var historyOrdersCmd = &cobra.Command{
+	Use:   "orders",
+	Short: "list the synced orders",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		environ, options, err := prepareHistoryQuery(cmd)
+		if err != nil {
+			return err
+		}
+
+		orders, err := environ.OrderService.Query(service.QueryOrdersOptions{
+			Exchange: options.Exchange,
+			Symbol:   options.Symbol,
+			Side:     options.Side,
+			Ordering: options.Ordering,
+			Since:    options.Since,
+			Until:    options.Until,
+			Limit:    options.Limit,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, order := range orders {
+			fmt.Println(order.String())
+		}
+
+		return nil
+	},
+}
+
+// go run ./cmd/bbgo history summary --since=2021-12-01
+var historySummaryCmd = &cobra.Command{
+	Use:   "summary",
+	Short: "show the trading volume and the fees paid of the synced trades",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		environ, options, err := prepareHistoryQuery(cmd)
+		if err != nil {
+			return err
+		}
+
+		summaries, err := environ.TradeService.QuerySummaries(options)
+		if err != nil {
+			return err
+		}
+
+		fees, err := environ.TradeService.QueryFees(options)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(w, "EXCHANGE\tSYMBOL\tTRADES\tQUANTITY\tQUOTE QUANTITY\n")
+		for _, summary := range summaries {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%f\t%f\n", summary.Exchange, summary.Symbol, summary.NumTrades, summary.Quantity, summary.QuoteQuantity)
+		}
+
+		fmt.Fprintf(w, "\nEXCHANGE\tFEE CURRENCY\tTRADES\tFEE\t\n")
+		for _, fee := range fees {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%f\t\n", fee.Exchange, fee.FeeCurrency, fee.NumTrades, fee.Fee)
+		}
+
+		return w.Flush()
+	},
+}
+
+// prepareHistoryQuery connects the database and parses the query options from the flags of the history commands
+func prepareHistoryQuery(cmd *cobra.Command) (*bbgo.Environment, service.QueryTradesOptions, error) {
+	var options = service.QueryTradesOptions{Ordering: "DESC"}
+
+	exchangeName, err := cmd.Flags().GetString("exchange")
+	if err != nil {
+		return nil, options, err
+	}
+	options.Exchange = types.ExchangeName(exchangeName)
+
+	sessionName, err := cmd.Flags().GetString("session")
+	if err != nil {
+		return nil, options, err
+	}
+
+	if len(sessionName) > 0 {
+		configFile, err := cmd.Flags().GetString("config")
+		if err != nil {
+			return nil, options, err
+		}
+
+		userConfig, err := bbgo.Load(configFile, false)
+		if err != nil {
+			return nil, options, err
+		}
+
+		session, ok := userConfig.Sessions[sessionName]
+		if !ok {
+			return nil, options, fmt.Errorf("session %s not found", sessionName)
+		}
+
+		options.Exchange = session.ExchangeName
+	}
+
+	options.Symbol, err = cmd.Flags().GetString("symbol")
+	if err != nil {
+		return nil, options, err
+	}
+
+	side, err := cmd.Flags().GetString("side")
+	if err != nil {
+		return nil, options, err
+	}
+
+	if len(side) > 0 {
+		options.Side, err = types.StrToSideType(side)
+		if err != nil {
+			return nil, options, err
+		}
+	}
+
+	for _, flag := range []string{"since", "until"} {
+		value, err := cmd.Flags().GetString(flag)
+		if err != nil {
+			return nil, options, err
+		}
+
+		if len(value) == 0 {
+			continue
+		}
+
+		t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return nil, options, errors.Wrapf(err, "invalid --%s date", flag)
+		}
+
+		if flag == "since" {
+			options.Since = &t
+		} else {
+			options.Until = &t
+		}
+	}
+
+	options.Limit, err = cmd.Flags().GetInt("limit")
+	if err != nil {
+		return nil, options, err
+	}
+
+	environ := bbgo.NewEnvironment()
+	if err := environ.ConfigureDefaultDatabase(context.Background()); err != nil {
+		return nil, options, err
+	}
+
+	return environ, options, nil
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+)
+
+func init() {
+	initCmd.Flags().Bool("force", false, "overwrite the existing config file")
+	RootCmd.AddCommand(initCmd)
+}
+
+// go run ./cmd/bbgo init --config bbgo.yaml --dotenv .env.local
+var initCmd = &cobra.Command{
+	Use:          "init",
+	Short:        "set up the config file and the dotenv file interactively",
+	SilenceUsage: true,
+
+	// the config file is generated by this command, it's not loaded
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configFile, err := cmd.Flags().GetString("config")
+		if err != nil {
+			return err
+		}
+
+		dotenvFile, err := cmd.Flags().GetString("dotenv")
+		if err != nil {
+			return err
+		}
+
+		force, err := cmd.Flags().GetBool("force")
+		if err != nil {
+			return err
+		}
+
+		if _, err := os.Stat(configFile); err == nil && !force {
+			return fmt.Errorf("config file %s already exists, use --force to overwrite it", configFile)
+		}
+
+		options, err := bbgo.NewInitWizard(os.Stdin, os.Stdout).Run()
+		if err != nil {
+			return err
+		}
+
+		config, envVars, err := bbgo.GenerateInitConfig(*options)
+		if err != nil {
+			return err
+		}
+
+		issues, err := bbgo.ValidateConfig(config)
+		if err != nil {
+			return err
+		}
+
+		for _, issue := range issues {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", configFile, issue.String())
+		}
+
+		// keep the other variables of the existing dotenv file
+		if existing, err := godotenv.Read(dotenvFile); err == nil {
+			for key, value := range envVars {
+				existing[key] = value
+			}
+			envVars = existing
+		}
+
+		if err := godotenv.Write(envVars, dotenvFile); err != nil {
+			return err
+		}
+
+		// the dotenv file contains the api secrets
+		if err := os.Chmod(dotenvFile, 0600); err != nil {
+			return err
+		}
+
+		if err := ioutil.WriteFile(configFile, config, 0644); err != nil {
+			return err
+		}
+
+		fmt.Printf("\n%s and %s are written, start bbgo with:\n\n", configFile, dotenvFile)
+		fmt.Printf("  bbgo run --config %s --dotenv %s\n", configFile, dotenvFile)
+		return nil
+	},
+}
@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -14,12 +16,17 @@ import (
 
 func init() {
 	marketCmd.Flags().String("session", "", "the exchange session name for querying information")
+	marketCmd.Flags().String("symbol", "", "the trading pair, like BTCUSDT, all the markets are listed if it's not set")
+	marketCmd.Flags().Bool("refresh", false, "re-pull the exchange info and update the markets cache")
+	marketCmd.Flags().Bool("json", false, "print the market info in json")
 	RootCmd.AddCommand(marketCmd)
 }
 
 // go run ./cmd/bbgo market --session=ftx --config=config/bbgo.yaml
+// go run ./cmd/bbgo market --session=binance --symbol=BTCUSDT --refresh
 var marketCmd = &cobra.Command{
 	Use:          "market",
+	Short:        "show the tick size, the step size, the min notional and the fee rates of the markets",
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
@@ -43,15 +50,26 @@ var marketCmd = &cobra.Command{
 		}
 
 		environ := bbgo.NewEnvironment()
-		if err := environ.ConfigureDatabase(ctx); err != nil {
+		if err := environ.ConfigureExchangeSessions(userConfig); err != nil {
 			return err
 		}
 
-		if err := environ.ConfigureExchangeSessions(userConfig); err != nil {
+		sessionName, err := cmd.Flags().GetString("session")
+		if err != nil {
 			return err
 		}
 
-		sessionName, err := cmd.Flags().GetString("session")
+		symbol, err := cmd.Flags().GetString("symbol")
+		if err != nil {
+			return err
+		}
+
+		refresh, err := cmd.Flags().GetBool("refresh")
+		if err != nil {
+			return err
+		}
+
+		printJSON, err := cmd.Flags().GetBool("json")
 		if err != nil {
 			return err
 		}
@@ -61,14 +79,49 @@ var marketCmd = &cobra.Command{
 			return fmt.Errorf("session %s not found", sessionName)
 		}
 
-		markets, err := session.Exchange.QueryMarkets(ctx)
+		loadMarkets := bbgo.LoadExchangeMarketsWithCache
+		if refresh {
+			loadMarkets = bbgo.RefreshExchangeMarketsCache
+		}
+
+		markets, err := loadMarkets(ctx, session.Exchange)
 		if err != nil {
 			return err
 		}
 
-		for _, m := range markets {
-			log.Infof("market: %+v", m)
+		if len(symbol) == 0 {
+			if printJSON {
+				return printJSONIndent(markets)
+			}
+
+			return bbgo.WriteMarketsTable(os.Stdout, markets)
 		}
-		return nil
+
+		market, ok := markets[strings.ToUpper(symbol)]
+		if !ok {
+			return fmt.Errorf("market %s not found in session %s", symbol, sessionName)
+		}
+
+		info := bbgo.MarketInfo{Session: sessionName, Market: market}
+		if !session.PublicOnly {
+			// the fee rates require the account api, the market info is still printed without them
+			if tier, err := session.UpdateFeeTier(ctx); err != nil {
+				log.WithError(err).Warnf("can not query the fee tier of session %s", sessionName)
+			} else {
+				info.FeeTier = &tier
+			}
+		}
+
+		if printJSON {
+			return printJSONIndent(info)
+		}
+
+		return info.WriteTable(os.Stdout)
 	},
 }
+
+func printJSONIndent(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
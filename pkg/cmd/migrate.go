@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/service"
+)
+
+func init() {
+	migrateDownCmd.Flags().Int("steps", 1, "the number of the applied migrations to roll back")
+
+	migrateCmd.AddCommand(migrateUpCmd, migrateDownCmd, migrateStatusCmd)
+	RootCmd.AddCommand(migrateCmd)
+}
+
+// migrateCmd manages the migrations of the database configured by DB_DRIVER and DB_DSN,
+// the sqlite3 database var/data/bbgo.sqlite3 is used if no database is configured.
+var migrateCmd = &cobra.Command{
+	Use:          "migrate",
+	Short:        "apply, roll back or show the database migrations",
+	SilenceUsage: true,
+}
+
+// go run ./cmd/bbgo migrate up
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "apply the pending migrations",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := connectMigrateDatabase()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		pending, err := db.PendingMigrations()
+		if err != nil {
+			return err
+		}
+
+		if len(pending) == 0 {
+			log.Infof("the database is up to date")
+			return nil
+		}
+
+		if err := db.Upgrade(context.Background()); err != nil {
+			return err
+		}
+
+		for _, migration := range pending {
+			log.Infof("applied migration %s", migration.Name)
+		}
+
+		return nil
+	},
+}
+
+// go run ./cmd/bbgo migrate down --steps 1
+var migrateDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "roll back the last applied migrations",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		steps, err := cmd.Flags().GetInt("steps")
+		if err != nil {
+			return err
+		}
+
+		db, err := connectMigrateDatabase()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		return db.Downgrade(context.Background(), steps)
+	},
+}
+
+// go run ./cmd/bbgo migrate status
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "show the applied and the pending migrations",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := connectMigrateDatabase()
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		statuses, err := db.MigrationStatus()
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(w, "VERSION\tSTATUS\tMIGRATION\n")
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied"
+			}
+
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, state, status.Name)
+		}
+
+		return w.Flush()
+	},
+}
+
+// connectMigrateDatabase connects the configured database without applying the migrations
+func connectMigrateDatabase() (*service.DatabaseService, error) {
+	driver, dsn, ok := bbgo.LookupDatabaseEnv()
+	if !ok {
+		driver, dsn = "sqlite3", service.DefaultSQLiteDSN
+	}
+
+	log.Infof("using the %s database", driver)
+
+	db := service.NewDatabaseService(driver, dsn)
+	if err := db.Connect(); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
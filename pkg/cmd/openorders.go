@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func init() {
+	ordersCmd.PersistentFlags().String("session", "", "the exchange session name, all sessions are used if it's not set")
+	ordersCmd.PersistentFlags().StringSlice("symbol", nil, "the trading pairs, like BTCUSDT, the symbols are selected by the universe of the session if it's not set")
+
+	ordersListCmd.Flags().Bool("json", false, "print the orders in json")
+
+	ordersCancelCmd.Flags().Bool("all", false, "cancel all the open orders of the symbols")
+	ordersCancelCmd.Flags().Uint64("order-id", 0, "the order id to cancel, --session is required")
+
+	ordersCmd.AddCommand(ordersListCmd, ordersCancelCmd)
+	RootCmd.AddCommand(ordersCmd)
+}
+
+// sessionOrder is an open order with the name of the session it belongs to
+type sessionOrder struct {
+	Session string `json:"session"`
+	types.Order
+}
+
+// ordersCmd manages the open orders of the configured sessions through the exchange api
+var ordersCmd = &cobra.Command{
+	Use:          "orders",
+	Short:        "list or cancel the open orders of the sessions",
+	SilenceUsage: true,
+}
+
+// go run ./cmd/bbgo orders list --session=binance --symbol=BTCUSDT
+var ordersListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list the open orders",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		printJSON, err := cmd.Flags().GetBool("json")
+		if err != nil {
+			return err
+		}
+
+		sessions, symbols, err := prepareOrderSessions(ctx, cmd)
+		if err != nil {
+			return err
+		}
+
+		var orders = []sessionOrder{}
+		for _, name := range sortedSessionNames(sessions) {
+			sessionOrders, err := sessions[name].QueryOpenOrders(ctx, symbols...)
+			if err != nil {
+				return err
+			}
+
+			for _, o := range sessionOrders {
+				orders = append(orders, sessionOrder{Session: name, Order: o})
+			}
+		}
+
+		if printJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(orders)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(w, "SESSION\tSYMBOL\tORDER ID\tSIDE\tTYPE\tPRICE\tQUANTITY\tEXECUTED\tSTATUS\tCREATED\n")
+		for _, o := range orders {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%f\t%f\t%f\t%s\t%s\n",
+				o.Session, o.Symbol, o.OrderID, o.Side, o.Type, o.Price, o.Quantity, o.ExecutedQuantity, o.Status,
+				o.CreationTime.Time().Format("2006-01-02 15:04:05"))
+		}
+
+		return w.Flush()
+	},
+}
+
+// go run ./cmd/bbgo orders cancel --all
+// go run ./cmd/bbgo orders cancel --session=binance --symbol=BTCUSDT
+// go run ./cmd/bbgo orders cancel --session=binance --order-id=123456
+var ordersCancelCmd = &cobra.Command{
+	Use:   "cancel",
+	Short: "cancel the open orders by --all, --symbol or --order-id",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		all, err := cmd.Flags().GetBool("all")
+		if err != nil {
+			return err
+		}
+
+		orderID, err := cmd.Flags().GetUint64("order-id")
+		if err != nil {
+			return err
+		}
+
+		sessionName, err := cmd.Flags().GetString("session")
+		if err != nil {
+			return err
+		}
+
+		symbolFlags, err := cmd.Flags().GetStringSlice("symbol")
+		if err != nil {
+			return err
+		}
+
+		if !all && orderID == 0 && len(symbolFlags) == 0 {
+			return errors.New("one of --all, --symbol or --order-id is required")
+		}
+
+		if orderID > 0 && len(sessionName) == 0 {
+			return errors.New("--session is required to cancel the order by --order-id")
+		}
+
+		sessions, symbols, err := prepareOrderSessions(ctx, cmd)
+		if err != nil {
+			return err
+		}
+
+		if orderID > 0 {
+			order, err := sessions[sessionName].CancelOrderByID(ctx, orderID, symbols...)
+			if err != nil {
+				return err
+			}
+
+			log.Infof("CANCELED %s", order.String())
+			return nil
+		}
+
+		for _, name := range sortedSessionNames(sessions) {
+			orders, err := sessions[name].CancelOpenOrders(ctx, symbols...)
+			for _, o := range orders {
+				log.WithField("session", name).Infof("CANCELED %s", o.String())
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+}
+
+// prepareOrderSessions loads the sessions selected by --session with the markets, the accounts are loaded when
+// --symbol is not given, so the symbols can be selected by the universe of the sessions
+func prepareOrderSessions(ctx context.Context, cmd *cobra.Command) (map[string]*bbgo.ExchangeSession, []string, error) {
+	configFile, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(configFile) == 0 {
+		return nil, nil, errors.New("--config option is required")
+	}
+
+	userConfig, err := bbgo.Load(configFile, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sessionName, err := cmd.Flags().GetString("session")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	symbols, err := cmd.Flags().GetStringSlice("symbol")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range symbols {
+		symbols[i] = strings.ToUpper(symbols[i])
+	}
+
+	environ := bbgo.NewEnvironment()
+	if err := environ.ConfigureExchangeSessions(userConfig); err != nil {
+		return nil, nil, err
+	}
+
+	var sessions = environ.Sessions()
+	if len(sessionName) > 0 {
+		session, ok := environ.Session(sessionName)
+		if !ok {
+			return nil, nil, fmt.Errorf("session %s not found", sessionName)
+		}
+
+		sessions = map[string]*bbgo.ExchangeSession{sessionName: session}
+	}
+
+	for name, session := range sessions {
+		markets, err := session.Exchange.QueryMarkets(ctx)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "can not query the markets of session %s", name)
+		}
+
+		session.SetMarkets(markets)
+
+		if len(symbols) > 0 {
+			continue
+		}
+
+		account, err := session.Exchange.QueryAccount(ctx)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "can not query the account of session %s", name)
+		}
+
+		session.Account = account
+	}
+
+	return sessions, symbols, nil
+}
+
+func sortedSessionNames(sessions map[string]*bbgo.ExchangeSession) []string {
+	var names []string
+	for name := range sessions {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
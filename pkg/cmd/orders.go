@@ -17,7 +17,6 @@ import (
 	"github.com/c9s/bbgo/pkg/bbgo"
 	"github.com/c9s/bbgo/pkg/exchange/ftx"
 	"github.com/c9s/bbgo/pkg/types"
-	"github.com/c9s/bbgo/pkg/util"
 )
 
 // go run ./cmd/bbgo list-orders [open|closed] --session=ftx --symbol=BTCUSDT
@@ -199,6 +198,26 @@ var executeOrderCmd = &cobra.Command{
 			return err
 		}
 
+		duration, err := cmd.Flags().GetDuration("duration")
+		if err != nil {
+			return err
+		}
+
+		numOfSlices, err := cmd.Flags().GetInt("slices")
+		if err != nil {
+			return err
+		}
+
+		sizeJitter, err := cmd.Flags().GetFloat64("size-jitter")
+		if err != nil {
+			return err
+		}
+
+		maxParticipationRate, err := cmd.Flags().GetFloat64("max-participation-rate")
+		if err != nil {
+			return err
+		}
+
 		var deadlineTime time.Time
 		if deadlineDuration > 0 {
 			deadlineTime = time.Now().Add(deadlineDuration)
@@ -231,6 +250,11 @@ var executeOrderCmd = &cobra.Command{
 			NumOfTicks:     numOfPriceTicks,
 			UpdateInterval: updateInterval,
 			DeadlineTime:   deadlineTime,
+
+			Duration:             duration,
+			NumOfSlices:          numOfSlices,
+			SizeJitter:           fixedpoint.NewFromFloat(sizeJitter),
+			MaxParticipationRate: fixedpoint.NewFromFloat(maxParticipationRate),
 		}
 
 		if err := execution.Run(executionCtx); err != nil {
@@ -263,6 +287,7 @@ var executeOrderCmd = &cobra.Command{
 // go run ./cmd/bbgo submit-order --session=ftx --symbol=BTCUSDT --side=buy --price=<price> --quantity=<quantity>
 var submitOrderCmd = &cobra.Command{
 	Use:          "submit-order",
+	Short:        "submit an order through the session, the order is validated by the market precision and the minimal notional",
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
@@ -289,16 +314,41 @@ var submitOrderCmd = &cobra.Command{
 			return fmt.Errorf("can't get side: %w", err)
 		}
 
-		price, err := cmd.Flags().GetString("price")
+		orderType, err := cmd.Flags().GetString("type")
+		if err != nil {
+			return fmt.Errorf("can't get order type: %w", err)
+		}
+
+		timeInForce, err := cmd.Flags().GetString("time-in-force")
+		if err != nil {
+			return fmt.Errorf("can't get time in force: %w", err)
+		}
+
+		price, err := cmd.Flags().GetFloat64("price")
 		if err != nil {
 			return fmt.Errorf("can't get price: %w", err)
 		}
 
-		quantity, err := cmd.Flags().GetString("quantity")
+		stopPrice, err := cmd.Flags().GetFloat64("stop-price")
+		if err != nil {
+			return fmt.Errorf("can't get stop price: %w", err)
+		}
+
+		quantity, err := cmd.Flags().GetFloat64("quantity")
 		if err != nil {
 			return fmt.Errorf("can't get quantity: %w", err)
 		}
 
+		postOnly, err := cmd.Flags().GetBool("post-only")
+		if err != nil {
+			return err
+		}
+
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			return err
+		}
+
 		environ := bbgo.NewEnvironment()
 		if err := environ.ConfigureExchangeSessions(userConfig); err != nil {
 			return err
@@ -313,22 +363,34 @@ var submitOrderCmd = &cobra.Command{
 			return fmt.Errorf("session %s not found", sessionName)
 		}
 
-		market, ok := session.Market(symbol)
-		if !ok {
-			return fmt.Errorf("market definition %s not found", symbol)
+		so := types.SubmitOrder{
+			ClientOrderID: uuid.New().String(),
+			Symbol:        ftx.TrimUpperString(symbol),
+			Side:          types.SideType(ftx.TrimUpperString(side)),
+			Type:          types.OrderType(ftx.TrimUpperString(orderType)),
+			Quantity:      quantity,
+			Price:         price,
+			StopPrice:     stopPrice,
+			TimeInForce:   ftx.TrimUpperString(timeInForce),
+			PostOnly:      postOnly,
+		}
+
+		switch so.Type {
+		case types.OrderTypeLimit, types.OrderTypeStopLimit:
+			if so.TimeInForce == "" && !so.IsPostOnly() {
+				so.TimeInForce = types.TimeInForceGTC
+			}
 		}
 
-		so := types.SubmitOrder{
-			ClientOrderID:  uuid.New().String(),
-			Symbol:         symbol,
-			Side:           types.SideType(ftx.TrimUpperString(side)),
-			Type:           types.OrderTypeLimit,
-			Quantity:       util.MustParseFloat(quantity),
-			QuantityString: quantity,
-			Price:          util.MustParseFloat(price),
-			PriceString:    price,
-			Market:         market,
-			TimeInForce:    "GTC",
+		// the order is validated like the orders of the strategies before it's sent to the exchange
+		so, err = session.ValidateOrder(so)
+		if err != nil {
+			return err
+		}
+
+		if dryRun {
+			log.Infof("dry run, the order is not submitted: %s", so.String())
+			return nil
 		}
 
 		co, err := session.Exchange.SubmitOrders(ctx, so)
@@ -336,6 +398,10 @@ var submitOrderCmd = &cobra.Command{
 			return err
 		}
 
+		if len(co) == 0 {
+			return fmt.Errorf("the order is not created: %s", so.String())
+		}
+
 		log.Infof("submitted order: %+v\ncreated order: %+v", so, co[0])
 		return nil
 	},
@@ -348,8 +414,13 @@ func init() {
 	submitOrderCmd.Flags().String("session", "", "the exchange session name for sync")
 	submitOrderCmd.Flags().String("symbol", "", "the trading pair, like btcusdt")
 	submitOrderCmd.Flags().String("side", "", "the trading side: buy or sell")
-	submitOrderCmd.Flags().String("price", "", "the trading price")
-	submitOrderCmd.Flags().String("quantity", "", "the trading quantity")
+	submitOrderCmd.Flags().String("type", string(types.OrderTypeLimit), "the order type: limit, limit_maker, market, stop_limit or stop_market")
+	submitOrderCmd.Flags().String("time-in-force", "", "the time in force: GTC, IOC or FOK, defaults to GTC for the limit orders")
+	submitOrderCmd.Flags().Float64("price", 0, "the trading price, it's ignored by the market orders")
+	submitOrderCmd.Flags().Float64("stop-price", 0, "the stop price of the stop orders")
+	submitOrderCmd.Flags().Float64("quantity", 0, "the trading quantity")
+	submitOrderCmd.Flags().Bool("post-only", false, "submit the limit order as a maker-only order")
+	submitOrderCmd.Flags().Bool("dry-run", false, "validate the order without submitting it")
 
 	executeOrderCmd.Flags().String("session", "", "the exchange session name for sync")
 	executeOrderCmd.Flags().String("symbol", "", "the trading pair, like btcusdt")
@@ -360,6 +431,10 @@ func init() {
 	executeOrderCmd.Flags().Duration("update-interval", time.Second*10, "order update time")
 	executeOrderCmd.Flags().Duration("deadline", 0, "deadline of the order execution")
 	executeOrderCmd.Flags().Int("price-ticks", 0, "the number of price tick for the jump spread, default to 0")
+	executeOrderCmd.Flags().Duration("duration", 0, "release the target quantity slice by slice over the duration")
+	executeOrderCmd.Flags().Int("slices", 0, "the number of the time slices, default to target quantity / slice quantity")
+	executeOrderCmd.Flags().Float64("size-jitter", 0, "randomize the size of each time slice, e.g., 0.2 means ±20%")
+	executeOrderCmd.Flags().Float64("max-participation-rate", 0, "the max rate of the market trade volume to fill, e.g., 0.1 means 10%")
 
 	RootCmd.AddCommand(listOrdersCmd)
 	RootCmd.AddCommand(submitOrderCmd)
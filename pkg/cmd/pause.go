@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/c9s/bbgo/pkg/server"
+)
+
+func init() {
+	for _, c := range []*cobra.Command{pauseCmd, resumeCmd} {
+		c.Flags().String("host", server.DefaultBindAddress, "the address of the running bbgo webserver")
+		c.Flags().String("session", "", "the exchange session to pause or resume")
+		c.Flags().String("strategy", "", "the strategy of the session, e.g., grid or grid:BTCUSDT, the whole session is paused if it's not set")
+		RootCmd.AddCommand(c)
+	}
+
+	pauseCmd.Flags().Bool("cancel-orders", false, "cancel the open orders of the paused symbols")
+}
+
+// go run ./cmd/bbgo pause --session binance --strategy grid:BTCUSDT --cancel-orders
+var pauseCmd = &cobra.Command{
+	Use:          "pause",
+	Short:        "stop placing new orders on the session of a running bbgo through the admin api",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cancelOrders, err := cmd.Flags().GetBool("cancel-orders")
+		if err != nil {
+			return err
+		}
+
+		var response struct {
+			CanceledOrders []json.RawMessage `json:"canceledOrders"`
+		}
+
+		if err := requestSessionPause(cmd, "pause", cancelOrders, &response); err != nil {
+			return err
+		}
+
+		if cancelOrders {
+			log.Infof("%d open orders are canceled", len(response.CanceledOrders))
+		}
+
+		log.Infof("paused")
+		return nil
+	},
+}
+
+// go run ./cmd/bbgo resume --session binance --strategy grid:BTCUSDT
+var resumeCmd = &cobra.Command{
+	Use:          "resume",
+	Short:        "resume the session paused by bbgo pause",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := requestSessionPause(cmd, "resume", false, nil); err != nil {
+			return err
+		}
+
+		log.Infof("resumed")
+		return nil
+	},
+}
+
+// requestSessionPause sends the pause or the resume request to the admin api, the token is read from --admin-api-token
+// or the ADMIN_API_TOKEN environment variable
+func requestSessionPause(cmd *cobra.Command, action string, cancelOrders bool, response interface{}) error {
+	host, err := cmd.Flags().GetString("host")
+	if err != nil {
+		return err
+	}
+
+	sessionName, err := cmd.Flags().GetString("session")
+	if err != nil {
+		return err
+	}
+
+	if len(sessionName) == 0 {
+		return errors.New("--session is required")
+	}
+
+	strategy, err := cmd.Flags().GetString("strategy")
+	if err != nil {
+		return err
+	}
+
+	token := viper.GetString("admin-api-token")
+	if len(token) == 0 {
+		return errors.New("--admin-api-token or ADMIN_API_TOKEN is required")
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"strategy":     strategy,
+		"cancelOrders": cancelOrders,
+	})
+	if err != nil {
+		return err
+	}
+
+	u := fmt.Sprintf("http://%s/api/admin/sessions/%s/%s", host, url.PathEscape(sessionName), action)
+	req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error string `json:"error"`
+		}
+
+		if err := json.NewDecoder(resp.Body).Decode(&e); err == nil && len(e.Error) > 0 {
+			return fmt.Errorf("%s error: %s", action, e.Error)
+		}
+
+		return fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+
+	if response == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(response)
+}
@@ -23,6 +23,11 @@ func init() {
 	PnLCmd.Flags().String("symbol", "", "trading symbol")
 	PnLCmd.Flags().Bool("include-transfer", false, "convert transfer records into trades")
 	PnLCmd.Flags().Int("limit", 500, "number of trades")
+	PnLCmd.Flags().String("strategy", "", "show the recorded profit summaries of the strategy instead of calculating the pnl from the trades")
+	PnLCmd.Flags().String("since", "", "the start date of the profit summaries, e.g., 2021-12-01")
+	PnLCmd.Flags().String("until", "", "the end date (exclusive) of the profit summaries")
+	PnLCmd.Flags().String("period", "day", "the period of the profit summaries: day, week or month")
+	PnLCmd.Flags().String("method", "", "the cost basis method of the realized gains: fifo, lifo or average, the average cost pnl report is printed if it's not set")
 	RootCmd.AddCommand(PnLCmd)
 }
 
@@ -61,6 +66,15 @@ var PnLCmd = &cobra.Command{
 			return err
 		}
 
+		strategyID, err := cmd.Flags().GetString("strategy")
+		if err != nil {
+			return err
+		}
+
+		if len(strategyID) > 0 {
+			return printProfitSummaries(ctx, cmd, strategyID, symbol)
+		}
+
 		if len(symbol) == 0 {
 			return errors.New("--symbol [SYMBOL] is required")
 		}
@@ -72,7 +86,7 @@ var PnLCmd = &cobra.Command{
 
 		environ := bbgo.NewEnvironment()
 
-		if err := environ.ConfigureDatabase(ctx); err != nil {
+		if err := environ.ConfigureDefaultDatabase(ctx); err != nil {
 			return err
 		}
 
@@ -175,6 +189,27 @@ var PnLCmd = &cobra.Command{
 
 		currentPrice := currentTick.Last
 
+		method, err := cmd.Flags().GetString("method")
+		if err != nil {
+			return err
+		}
+
+		if len(method) > 0 {
+			costBasisMethod, err := pnl.ParseCostBasisMethod(method)
+			if err != nil {
+				return err
+			}
+
+			calculator := &pnl.CostBasisCalculator{
+				Method: costBasisMethod,
+				Market: market,
+			}
+
+			report := calculator.Calculate(symbol, trades, currentPrice)
+			report.Print()
+			return nil
+		}
+
 		calculator := &pnl.AverageCostCalculator{
 			TradingFeeCurrency: tradingFeeCurrency,
 		}
@@ -184,3 +219,69 @@ var PnLCmd = &cobra.Command{
 		return nil
 	},
 }
+
+// printProfitSummaries prints the profit summaries of the strategy recorded in the profits table
+func printProfitSummaries(ctx context.Context, cmd *cobra.Command, strategyID, symbol string) error {
+	environ := bbgo.NewEnvironment()
+	if err := environ.ConfigureDatabase(ctx); err != nil {
+		return err
+	}
+
+	if environ.ProfitService == nil {
+		return errors.New("database is not configured, the profits are recorded in the database")
+	}
+
+	options := service.QueryProfitsOptions{
+		Strategy: strategyID,
+		Symbol:   symbol,
+	}
+
+	for _, flag := range []string{"since", "until"} {
+		value, err := cmd.Flags().GetString(flag)
+		if err != nil {
+			return err
+		}
+
+		if len(value) == 0 {
+			continue
+		}
+
+		t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return errors.Wrapf(err, "invalid --%s date", flag)
+		}
+
+		if flag == "since" {
+			options.Since = &t
+		} else {
+			options.Until = &t
+		}
+	}
+
+	period, err := cmd.Flags().GetString("period")
+	if err != nil {
+		return err
+	}
+
+	summaries, err := environ.ProfitService.QuerySummaries(options, types.ProfitPeriod(period))
+	if err != nil {
+		return err
+	}
+
+	if len(summaries) == 0 {
+		log.Infof("no profit of strategy %s is found", strategyID)
+		return nil
+	}
+
+	var total = make(map[string]float64)
+	for _, summary := range summaries {
+		fmt.Println(summary.PlainText())
+		total[summary.QuoteCurrency] += summary.Profit
+	}
+
+	for currency, profit := range total {
+		fmt.Printf("total profit: %f %s\n", profit, currency)
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"syscall"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/cmd/cmdutil"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func init() {
+	recordCmd.Flags().String("exchange", "binance", "the exchange to record")
+	recordCmd.Flags().StringSlice("symbols", nil, "the symbols to record, e.g., --symbols BTCUSDT,ETHUSDT")
+	recordCmd.Flags().StringSlice("intervals", []string{"1m"}, "the kline intervals to record")
+	recordCmd.Flags().Bool("book", true, "record the order book snapshots and updates")
+	recordCmd.Flags().Bool("trades", true, "record the market trades")
+	recordCmd.Flags().String("output", "data/record", "the output directory of the compressed record files, empty to disable the file output")
+	recordCmd.Flags().Bool("db", false, "insert the closed klines into the database for back-testing")
+	RootCmd.AddCommand(recordCmd)
+}
+
+// go run ./cmd/bbgo record --exchange binance --symbols BTCUSDT,ETHUSDT --intervals 1m,1h --output data/record
+var recordCmd = &cobra.Command{
+	Use:          "record",
+	Short:        "record the live order books, market trades and klines to the compressed files or the database",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		exName, err := cmd.Flags().GetString("exchange")
+		if err != nil {
+			return err
+		}
+
+		exchangeName, err := types.ValidExchangeName(exName)
+		if err != nil {
+			return err
+		}
+
+		symbols, err := cmd.Flags().GetStringSlice("symbols")
+		if err != nil {
+			return err
+		}
+
+		if len(symbols) == 0 {
+			return errors.New("--symbols option is required")
+		}
+
+		intervals, err := cmd.Flags().GetStringSlice("intervals")
+		if err != nil {
+			return err
+		}
+
+		for _, interval := range intervals {
+			if _, ok := types.SupportedIntervals[types.Interval(interval)]; !ok {
+				return fmt.Errorf("unsupported interval %s", interval)
+			}
+		}
+
+		recordBook, err := cmd.Flags().GetBool("book")
+		if err != nil {
+			return err
+		}
+
+		recordTrades, err := cmd.Flags().GetBool("trades")
+		if err != nil {
+			return err
+		}
+
+		outputDirectory, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+
+		recordDB, err := cmd.Flags().GetBool("db")
+		if err != nil {
+			return err
+		}
+
+		var sinks []bbgo.MarketDataSink
+		if len(outputDirectory) > 0 {
+			sinks = append(sinks, bbgo.NewFileMarketDataSink(outputDirectory))
+		}
+
+		if recordDB {
+			environ := bbgo.NewEnvironment()
+			if err := environ.ConfigureDefaultDatabase(ctx); err != nil {
+				return err
+			}
+
+			if environ.DatabaseService == nil {
+				return errors.New("database service is not enabled, please check your environment variables DB_DRIVER and DB_DSN")
+			}
+
+			sinks = append(sinks, &bbgo.KLineDatabaseSink{
+				KLines: &service.BacktestService{DB: environ.DatabaseService.DB},
+			})
+		}
+
+		if len(sinks) == 0 {
+			return errors.New("nothing to record, please specify --output or --db")
+		}
+
+		ex, err := cmdutil.NewExchange(exchangeName)
+		if err != nil {
+			return err
+		}
+
+		stream := ex.NewStream()
+		stream.SetPublicOnly()
+		for _, symbol := range symbols {
+			for _, interval := range intervals {
+				stream.Subscribe(types.KLineChannel, symbol, types.SubscribeOptions{Interval: interval})
+			}
+
+			if recordBook {
+				stream.Subscribe(types.BookChannel, symbol, types.SubscribeOptions{})
+			}
+
+			if recordTrades {
+				stream.Subscribe(types.MarketTradeChannel, symbol, types.SubscribeOptions{})
+			}
+		}
+
+		recorder := bbgo.NewMarketDataRecorder(sinks...)
+		recorder.BindStream(exchangeName, stream)
+
+		recorderCtx, stopRecorder := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			recorder.Run(recorderCtx)
+			close(done)
+		}()
+
+		log.Infof("connecting to %s...", exchangeName)
+		if err := stream.Connect(ctx); err != nil {
+			stopRecorder()
+			<-done
+			return errors.Wrapf(err, "failed to connect to %s", exchangeName)
+		}
+
+		log.Infof("recording %v market data...", symbols)
+		cmdutil.WaitForSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+
+		if err := stream.Close(); err != nil {
+			log.WithError(err).Error("stream close error")
+		}
+
+		// flush the queued records and close the files
+		stopRecorder()
+		<-done
+		log.Infof("recording stopped")
+		return nil
+	},
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/backtest"
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func init() {
+	ReplayCmd.Flags().String("config", "config/bbgo.yaml", "strategy config file")
+	ReplayCmd.Flags().String("strategy", "", "the ID of the strategy to replay")
+	ReplayCmd.Flags().String("from", "", "the replay start time, e.g., 2021-05-01")
+	ReplayCmd.Flags().String("to", "", "the replay end time, e.g., 2021-05-02")
+	ReplayCmd.Flags().String("session", "", "the session of the recorded market data, default to the backtest session")
+	ReplayCmd.Flags().CountP("verbose", "v", "verbose level")
+	RootCmd.AddCommand(ReplayCmd)
+}
+
+// go run ./cmd/bbgo replay --config config/bbgo.yaml --strategy grid --from 2021-05-01 --to 2021-05-02
+var ReplayCmd = &cobra.Command{
+	Use:          "replay",
+	Short:        "replay the recorded market data with the persisted strategy state and log every decision point",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		verboseCnt, err := cmd.Flags().GetCount("verbose")
+		if err != nil {
+			return err
+		}
+
+		configFile, err := cmd.Flags().GetString("config")
+		if err != nil {
+			return err
+		}
+
+		if len(configFile) == 0 {
+			return errors.New("--config option is required")
+		}
+
+		strategyID, err := cmd.Flags().GetString("strategy")
+		if err != nil {
+			return err
+		}
+
+		if len(strategyID) == 0 {
+			return errors.New("--strategy option is required")
+		}
+
+		from, err := cmd.Flags().GetString("from")
+		if err != nil {
+			return err
+		}
+
+		to, err := cmd.Flags().GetString("to")
+		if err != nil {
+			return err
+		}
+
+		sessionName, err := cmd.Flags().GetString("session")
+		if err != nil {
+			return err
+		}
+
+		userConfig, err := bbgo.Load(configFile, true)
+		if err != nil {
+			return err
+		}
+
+		if userConfig.Backtest == nil {
+			return errors.New("backtest config is not defined, the replay uses the backtest account and symbols")
+		}
+
+		userConfig.ExchangeStrategies = bbgo.FilterExchangeStrategies(userConfig.ExchangeStrategies, strategyID)
+		if len(userConfig.ExchangeStrategies) == 0 {
+			return errors.Errorf("strategy %s is not found in the config file %s", strategyID, configFile)
+		}
+
+		// cross exchange strategies are not supported by the backtest exchange
+		userConfig.CrossExchangeStrategies = nil
+
+		// never touch the trades of the database in the replay
+		userConfig.Backtest.RecordTrades = false
+
+		if len(from) > 0 {
+			userConfig.Backtest.StartTime = from
+		}
+
+		if len(to) > 0 {
+			userConfig.Backtest.EndTime = to
+		}
+
+		if len(sessionName) > 0 {
+			userConfig.Backtest.Session = sessionName
+		}
+
+		startTime, err := userConfig.Backtest.ParseStartTime()
+		if err != nil {
+			return err
+		}
+
+		endTime, err := userConfig.Backtest.ParseEndTime()
+		if err != nil {
+			return err
+		}
+
+		if !endTime.After(startTime) {
+			return errors.Errorf("replay end time %s must be after the start time %s", endTime, startTime)
+		}
+
+		exchangeName, err := types.ValidExchangeName(userConfig.Backtest.Session)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		environ := bbgo.NewEnvironment()
+		if err := BootstrapBacktestEnvironment(ctx, environ, userConfig); err != nil {
+			return err
+		}
+
+		if environ.DatabaseService == nil {
+			return errors.New("database service is not enabled, please check your environment variables DB_DRIVER and DB_DSN")
+		}
+
+		// load the persisted state of the strategy, the state saved during the replay is kept in the memory
+		if userConfig.Persistence != nil {
+			if err := environ.ConfigurePersistence(userConfig.Persistence); err != nil {
+				return errors.Wrap(err, "persistence configure error")
+			}
+		}
+		environ.PersistenceServiceFacade.ReadOnly = true
+
+		backtestService := &service.BacktestService{DB: environ.DatabaseService.DB}
+		environ.BacktestService = backtestService
+
+		backtestExchange, err := backtest.NewExchange(exchangeName, backtestService, userConfig.Backtest)
+		if err != nil {
+			return errors.Wrap(err, "failed to create backtest exchange")
+		}
+
+		environ.SetStartTime(startTime)
+		environ.AddExchange(userConfig.Backtest.Session, backtestExchange)
+
+		if err := environ.Init(ctx); err != nil {
+			return err
+		}
+
+		if verboseCnt > 0 {
+			log.SetLevel(log.DebugLevel)
+		} else {
+			log.SetLevel(log.InfoLevel)
+		}
+
+		replayLogger := bbgo.NewReplayLogger(log.WithField("strategy", strategyID))
+		for _, session := range environ.Sessions() {
+			replayLogger.BindSession(session)
+		}
+
+		trader := bbgo.NewTrader(environ)
+		if err := trader.Configure(userConfig); err != nil {
+			return err
+		}
+
+		log.Infof("replaying strategy %s from %s to %s", strategyID, startTime.Format(time.RFC3339), endTime.Format(time.RFC3339))
+
+		if err := trader.Run(ctx); err != nil {
+			return err
+		}
+
+		<-backtestExchange.Done()
+
+		log.Infof("replay is done, shutting down trader...")
+		shutdownCtx, cancelShutdown := context.WithDeadline(ctx, time.Now().Add(10*time.Second))
+		trader.Graceful.Shutdown(shutdownCtx)
+		cancelShutdown()
+
+		for _, session := range environ.Sessions() {
+			for symbol, trades := range session.Trades {
+				log.Infof("%s %d trades replayed", symbol, len(trades.Trades))
+			}
+		}
+
+		return nil
+	},
+}
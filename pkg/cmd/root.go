@@ -29,24 +29,10 @@ var RootCmd = &cobra.Command{
 	SilenceUsage: true,
 
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		disableDotEnv, err := cmd.Flags().GetBool("no-dotenv")
-		if err != nil {
+		if err := loadDotenv(cmd); err != nil {
 			return err
 		}
 
-		if !disableDotEnv {
-			dotenvFile, err := cmd.Flags().GetString("dotenv")
-			if err != nil {
-				return err
-			}
-
-			if _, err := os.Stat(dotenvFile); err == nil {
-				if err := godotenv.Load(dotenvFile); err != nil {
-					return errors.Wrap(err, "error loading dotenv file")
-				}
-			}
-		}
-
 		configFile, err := cmd.Flags().GetString("config")
 		if err != nil {
 			return errors.Wrapf(err, "failed to get the config flag")
@@ -80,6 +66,31 @@ var RootCmd = &cobra.Command{
 	},
 }
 
+// loadDotenv loads the dotenv file unless --no-dotenv is given
+func loadDotenv(cmd *cobra.Command) error {
+	disableDotEnv, err := cmd.Flags().GetBool("no-dotenv")
+	if err != nil {
+		return err
+	}
+
+	if disableDotEnv {
+		return nil
+	}
+
+	dotenvFile, err := cmd.Flags().GetString("dotenv")
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(dotenvFile); err == nil {
+		if err := godotenv.Load(dotenvFile); err != nil {
+			return errors.Wrap(err, "error loading dotenv file")
+		}
+	}
+
+	return nil
+}
+
 func init() {
 	RootCmd.PersistentFlags().Bool("debug", false, "debug flag")
 	RootCmd.PersistentFlags().String("config", "bbgo.yaml", "config file")
@@ -87,6 +98,8 @@ func init() {
 	RootCmd.PersistentFlags().Bool("no-dotenv", false, "disable built-in dotenv")
 	RootCmd.PersistentFlags().String("dotenv", ".env.local", "the dotenv file you want to load")
 
+	RootCmd.PersistentFlags().Bool("no-auto-migrate", false, "do not apply the pending database migrations on startup, apply them by bbgo migrate up")
+
 	// A flag can be 'persistent' meaning that this flag will be available to
 	// the command it's assigned to as well as every command under that command.
 	// For global flags, assign a flag as a persistent flag on the root.
@@ -94,6 +107,11 @@ func init() {
 	RootCmd.PersistentFlags().String("slack-channel", "dev-bbgo", "slack trading channel")
 	RootCmd.PersistentFlags().String("slack-error-channel", "bbgo-error", "slack error channel")
 
+	RootCmd.PersistentFlags().String("discord-webhook-url", "", "discord webhook url of the default channel")
+
+	RootCmd.PersistentFlags().String("admin-api-token", "", "bearer token of the admin api, the admin api is disabled if it's empty")
+	RootCmd.PersistentFlags().String("grpc-token", "", "bearer token of the grpc api, the trading service is disabled if it's empty")
+
 	RootCmd.PersistentFlags().String("telegram-bot-token", "", "telegram bot token from bot father")
 	RootCmd.PersistentFlags().String("telegram-bot-auth-token", "", "telegram auth token")
 
@@ -2,9 +2,11 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"runtime/pprof"
 	"syscall"
@@ -14,9 +16,11 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	flag "github.com/spf13/pflag"
+	"github.com/spf13/viper"
 
 	"github.com/c9s/bbgo/pkg/bbgo"
 	"github.com/c9s/bbgo/pkg/cmd/cmdutil"
+	"github.com/c9s/bbgo/pkg/grpc"
 	"github.com/c9s/bbgo/pkg/server"
 )
 
@@ -29,6 +33,9 @@ func init() {
 	RunCmd.Flags().Bool("enable-web-server", false, "legacy option, this is renamed to --enable-webserver")
 	RunCmd.Flags().String("cpu-profile", "", "cpu profile")
 	RunCmd.Flags().String("webserver-bind", ":8080", "webserver binding")
+	RunCmd.Flags().Bool("enable-grpc", false, "enable grpc server")
+	RunCmd.Flags().String("grpc-bind", grpc.DefaultBindAddress, "grpc server binding")
+	RunCmd.Flags().Bool("watch-config", false, "reload the strategy parameters when the config file is changed")
 	RunCmd.Flags().Bool("setup", false, "use setup mode")
 	RootCmd.AddCommand(RunCmd)
 }
@@ -73,17 +80,17 @@ func runSetup(baseCtx context.Context, userConfig *bbgo.Config, enableApiServer
 	cmdutil.WaitForSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
 	cancelTrading()
 
-	// graceful period = 15 second
-	shutdownCtx, cancelShutdown := context.WithDeadline(ctx, time.Now().Add(15*time.Second))
+	// the graceful period is configured by the shutdown timeout, default to 30 seconds
+	shutdownCtx, cancelShutdown := context.WithDeadline(ctx, time.Now().Add(trader.ShutdownTimeout()))
 
 	log.Infof("shutting down...")
-	trader.Graceful.Shutdown(shutdownCtx)
+	trader.Shutdown(shutdownCtx)
 	cancelShutdown()
 	return nil
 }
 
 func BootstrapBacktestEnvironment(ctx context.Context, environ *bbgo.Environment, userConfig *bbgo.Config) error {
-	if err := environ.ConfigureDatabase(ctx); err != nil {
+	if err := environ.ConfigureDefaultDatabase(ctx); err != nil {
 		return err
 	}
 
@@ -115,10 +122,40 @@ func BootstrapEnvironment(ctx context.Context, environ *bbgo.Environment, userCo
 		return errors.Wrap(err, "notification configure error")
 	}
 
+	if err := environ.ConfigureOrderWebhooks(ctx, userConfig.OrderWebhooks); err != nil {
+		return errors.Wrap(err, "order webhook configure error")
+	}
+
+	if err := environ.ConfigureEventPublishers(ctx, userConfig.EventPublishers); err != nil {
+		return errors.Wrap(err, "event publisher configure error")
+	}
+
+	if err := environ.ConfigureIndexPrices(ctx, userConfig.IndexPrices); err != nil {
+		return errors.Wrap(err, "index price configure error")
+	}
+
+	if userConfig.KLineCompaction != nil {
+		if err := environ.ConfigureKLineCompaction(ctx, userConfig.KLineCompaction); err != nil {
+			return errors.Wrap(err, "kline compaction configure error")
+		}
+	}
+
+	if err := environ.ConfigureCurrencyConversion(ctx, userConfig.CurrencyConversion); err != nil {
+		return errors.Wrap(err, "currency conversion configure error")
+	}
+
+	if userConfig.BalanceSnapshots != nil {
+		if err := environ.ConfigureBalanceSnapshots(ctx, userConfig.BalanceSnapshots); err != nil {
+			return errors.Wrap(err, "balance snapshot configure error")
+		}
+	}
+
 	return nil
 }
 
-func runConfig(basectx context.Context, userConfig *bbgo.Config, enableWebServer bool, webServerBind string) error {
+// runConfig runs the strategies of the config, the grpc server is started if grpcBind is not empty.
+// The strategy parameters are reloaded from the config file on SIGHUP, or when the file is changed if watchConfig is true.
+func runConfig(basectx context.Context, userConfig *bbgo.Config, configFile string, watchConfig bool, enableWebServer bool, webServerBind string, grpcBind string) error {
 	ctx, cancelTrading := context.WithCancel(basectx)
 	defer cancelTrading()
 
@@ -135,21 +172,69 @@ func runConfig(basectx context.Context, userConfig *bbgo.Config, enableWebServer
 		return err
 	}
 
+	environ.TrackFeeTiers(ctx, 0)
+	environ.TrackMarkets(ctx, 0)
+
+	var recorder *bbgo.RecoveryRecorder
+	if userConfig.Recovery != nil {
+		recorder = bbgo.NewRecoveryRecorder(environ, *userConfig.Recovery)
+
+		// the snapshot left by the last run means the last run was terminated unexpectedly
+		report, err := recorder.Reconcile(ctx)
+		if err != nil {
+			return err
+		}
+
+		if report != nil {
+			log.Warnf("reconciliation report:\n%s", report)
+			environ.Notify(":rotating_light: the last run was terminated unexpectedly, %s", report)
+
+			if !report.IsClean() && userConfig.Recovery.HaltOnMismatch {
+				return errors.Errorf("the recovery snapshot does not match the exchange, please check %s.reconciled", recorder.Path)
+			}
+		}
+
+		defer recorder.RecoverPanic()
+	}
+
 	trader := bbgo.NewTrader(environ)
 	if err := trader.Configure(userConfig); err != nil {
 		return err
 	}
 
+	if recorder != nil {
+		recorder.SetTrader(trader)
+		recorder.BindSessions()
+	}
+
 	if err := trader.Run(ctx); err != nil {
 		return err
 	}
 
+	if recorder != nil {
+		go recorder.Run(ctx)
+	}
+
+	if environ.CommandQueue != nil {
+		trader.RegisterCommandHandlers(environ.CommandQueue)
+		go environ.CommandQueue.Run(ctx)
+	}
+
+	reloader := bbgo.NewConfigReloader(configFile, trader)
+	go reloadConfigOnSignal(ctx, reloader)
+	if watchConfig {
+		go reloader.Watch(ctx)
+	}
+
 	if enableWebServer {
 		go func() {
 			s := &server.Server{
-				Config:  userConfig,
-				Environ: environ,
-				Trader:  trader,
+				Config:     userConfig,
+				Environ:    environ,
+				Trader:     trader,
+				AdminToken: viper.GetString("admin-api-token"),
+
+				ConfigReloader: reloader,
 			}
 
 			if err := s.Run(ctx, webServerBind); err != nil {
@@ -158,17 +243,59 @@ func runConfig(basectx context.Context, userConfig *bbgo.Config, enableWebServer
 		}()
 	}
 
-	cmdutil.WaitForSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	if len(grpcBind) > 0 {
+		go func() {
+			s := grpc.NewServer(environ, viper.GetString("grpc-token"))
+			if err := s.Run(ctx, grpcBind); err != nil {
+				log.WithError(err).Errorf("grpc server error")
+			}
+		}()
+	}
+
+	sig := cmdutil.WaitForSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+
+	if recorder != nil {
+		if err := recorder.Write(fmt.Sprintf("signal: %v", sig)); err != nil {
+			log.WithError(err).Error("can not write the recovery snapshot")
+		}
+	}
 
 	log.Infof("shutting down stratgies...")
-	shutdownCtx, cancelShutdown := context.WithDeadline(ctx, time.Now().Add(30*time.Second))
-	trader.Graceful.Shutdown(shutdownCtx)
+	shutdownCtx, cancelShutdown := context.WithDeadline(ctx, time.Now().Add(trader.ShutdownTimeout()))
+	trader.Shutdown(shutdownCtx)
 	cancelShutdown()
 	cancelTrading()
 
+	// the graceful shutdown is completed, the next start does not need to reconcile
+	if recorder != nil {
+		if err := recorder.Clean(); err != nil {
+			log.WithError(err).Error("can not remove the recovery snapshot")
+		}
+	}
+
 	return nil
 }
 
+// reloadConfigOnSignal reloads the strategy parameters from the config file on SIGHUP
+func reloadConfigOnSignal(ctx context.Context, reloader *bbgo.ConfigReloader) {
+	var sigC = make(chan os.Signal, 1)
+	signal.Notify(sigC, syscall.SIGHUP)
+	defer signal.Stop(sigC)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-sigC:
+			log.Infof("SIGHUP received, reloading config %s...", reloader.ConfigFile)
+			if _, err := reloader.Reload(ctx); err != nil {
+				log.WithError(err).Errorf("config %s reload error", reloader.ConfigFile)
+			}
+		}
+	}
+}
+
 func run(cmd *cobra.Command, args []string) error {
 	setup, err := cmd.Flags().GetBool("setup")
 	if err != nil {
@@ -185,6 +312,21 @@ func run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	enableGrpc, err := cmd.Flags().GetBool("enable-grpc")
+	if err != nil {
+		return err
+	}
+
+	grpcBind, err := cmd.Flags().GetString("grpc-bind")
+	if err != nil {
+		return err
+	}
+
+	watchConfig, err := cmd.Flags().GetBool("watch-config")
+	if err != nil {
+		return err
+	}
+
 	enableWebServerLegacy, err := cmd.Flags().GetBool("enable-web-server")
 	if err != nil {
 		return err
@@ -259,7 +401,11 @@ func run(cmd *cobra.Command, args []string) error {
 			defer pprof.StopCPUProfile()
 		}
 
-		return runConfig(ctx, userConfig, enableWebServer, webServerBind)
+		if !enableGrpc {
+			grpcBind = ""
+		}
+
+		return runConfig(ctx, userConfig, configFile, watchConfig, enableWebServer, webServerBind, grpcBind)
 	}
 
 	return runWrapperBinary(ctx, userConfig, cmd, args)
@@ -277,18 +423,24 @@ func runWrapperBinary(ctx context.Context, userConfig *bbgo.Config, cmd *cobra.C
 		return err
 	}
 
-	if sig := cmdutil.WaitForSignal(ctx, syscall.SIGTERM, syscall.SIGINT); sig != nil {
+	for {
+		sig := cmdutil.WaitForSignal(ctx, syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
+		if sig == nil {
+			return nil
+		}
+
 		log.Infof("sending signal to the child process...")
 		if err := runCmd.Process.Signal(sig); err != nil {
 			return err
 		}
 
-		if err := runCmd.Wait(); err != nil {
-			return err
+		// SIGHUP reloads the config of the child process
+		if sig == syscall.SIGHUP {
+			continue
 		}
-	}
 
-	return nil
+		return runCmd.Wait()
+	}
 }
 
 // buildAndRun builds the package natively and run the binary with the given args
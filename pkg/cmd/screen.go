@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func init() {
+	screenCmd.Flags().String("session", "", "the exchange session name for screening the markets")
+	screenCmd.Flags().StringSlice("by", []string{"volume"}, "the ranking criteria with optional weights: volume, volatility, spread, fundingRate, e.g., --by volume:1,volatility:2")
+	screenCmd.Flags().StringSlice("quote", nil, "the quote currency filter, e.g., --quote USDT,BUSD")
+	screenCmd.Flags().StringSlice("deny", nil, "the symbols to exclude")
+	screenCmd.Flags().Float64("min-volume", 0, "the minimal 24h quote volume")
+	screenCmd.Flags().Int("limit", 20, "the number of the results")
+	RootCmd.AddCommand(screenCmd)
+}
+
+// go run ./cmd/bbgo screen --session=binance --quote USDT --by volume:1,volatility:2 --config=config/bbgo.yaml
+var screenCmd = &cobra.Command{
+	Use:          "screen",
+	Short:        "rank the markets by volume, volatility, spread or funding rate",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		configFile, err := cmd.Flags().GetString("config")
+		if err != nil {
+			return err
+		}
+
+		if len(configFile) == 0 {
+			return errors.New("--config option is required")
+		}
+
+		if _, err := os.Stat(configFile); os.IsNotExist(err) {
+			return err
+		}
+
+		userConfig, err := bbgo.Load(configFile, false)
+		if err != nil {
+			return err
+		}
+
+		sessionName, err := cmd.Flags().GetString("session")
+		if err != nil {
+			return err
+		}
+
+		by, err := cmd.Flags().GetStringSlice("by")
+		if err != nil {
+			return err
+		}
+
+		criteria, err := parseScreenerCriteria(by)
+		if err != nil {
+			return err
+		}
+
+		quoteCurrencies, err := cmd.Flags().GetStringSlice("quote")
+		if err != nil {
+			return err
+		}
+
+		deny, err := cmd.Flags().GetStringSlice("deny")
+		if err != nil {
+			return err
+		}
+
+		minVolume, err := cmd.Flags().GetFloat64("min-volume")
+		if err != nil {
+			return err
+		}
+
+		limit, err := cmd.Flags().GetInt("limit")
+		if err != nil {
+			return err
+		}
+
+		environ := bbgo.NewEnvironment()
+		if err := environ.ConfigureExchangeSessions(userConfig); err != nil {
+			return err
+		}
+
+		session, ok := environ.Session(sessionName)
+		if !ok {
+			return fmt.Errorf("session %s not found", sessionName)
+		}
+
+		markets, err := bbgo.LoadExchangeMarketsWithCache(ctx, session.Exchange)
+		if err != nil {
+			return err
+		}
+
+		screener := &bbgo.Screener{
+			Universe: bbgo.UniverseConfig{
+				QuoteCurrencies: quoteCurrencies,
+				Deny:            deny,
+				MinVolume:       fixedpoint.NewFromFloat(minVolume),
+			},
+			Criteria: criteria,
+			Limit:    limit,
+		}
+
+		results, err := screener.ScreenMarkets(ctx, session.Exchange, markets, types.BalanceMap{})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%-4s %-14s %16s %10s %10s %12s %8s\n", "#", "SYMBOL", "VOLUME", "VOLATILITY", "SPREAD", "FUNDING", "SCORE")
+		for i, result := range results {
+			fmt.Printf("%-4d %-14s %16.2f %9.2f%% %9.4f%% %11.4f%% %8.3f\n",
+				i+1,
+				result.Symbol,
+				result.Volume,
+				result.Volatility*100.0,
+				result.Spread*100.0,
+				result.FundingRate*100.0,
+				result.Score)
+		}
+
+		return nil
+	},
+}
+
+// parseScreenerCriteria parses the criteria like "volume:1", the default weight is 1.0
+func parseScreenerCriteria(args []string) (map[bbgo.ScreenerCriterion]float64, error) {
+	var criteria = make(map[bbgo.ScreenerCriterion]float64)
+	for _, arg := range args {
+		parts := strings.SplitN(arg, ":", 2)
+		criterion := bbgo.ScreenerCriterion(strings.TrimSpace(parts[0]))
+		if err := criterion.Validate(); err != nil {
+			return nil, err
+		}
+
+		weight := 1.0
+		if len(parts) == 2 {
+			w, err := strconv.ParseFloat(parts[1], 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid weight of criterion %s", criterion)
+			}
+			weight = w
+		}
+
+		criteria[criterion] = weight
+	}
+
+	return criteria, nil
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/server"
+)
+
+func init() {
+	stateCmd.Flags().String("host", server.DefaultBindAddress, "the address of the running bbgo webserver")
+	stateCmd.Flags().String("strategy", "", "only show the state of the given strategy ID")
+	stateCmd.Flags().String("session", "", "only show the state of the strategies on the given session")
+	RootCmd.AddCommand(stateCmd)
+}
+
+// go run ./cmd/bbgo state --host localhost:8080 --strategy bollpp
+var stateCmd = &cobra.Command{
+	Use:          "state",
+	Short:        "read the state snapshots of the strategies from a running bbgo webserver",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		host, err := cmd.Flags().GetString("host")
+		if err != nil {
+			return err
+		}
+
+		strategyID, err := cmd.Flags().GetString("strategy")
+		if err != nil {
+			return err
+		}
+
+		sessionName, err := cmd.Flags().GetString("session")
+		if err != nil {
+			return err
+		}
+
+		client := &http.Client{Timeout: 10 * time.Second}
+		resp, err := client.Get(fmt.Sprintf("http://%s/api/strategies/states", host))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("unexpected response status: %s", resp.Status)
+		}
+
+		var payload struct {
+			States []bbgo.StrategyState `json:"states"`
+		}
+
+		if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+			return errors.Wrap(err, "can not decode the strategy states")
+		}
+
+		var states []bbgo.StrategyState
+		for _, state := range payload.States {
+			if len(strategyID) > 0 && state.Strategy != strategyID {
+				continue
+			}
+
+			if len(sessionName) > 0 && state.Session != sessionName {
+				continue
+			}
+
+			states = append(states, state)
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(states)
+	},
+}
@@ -50,7 +50,7 @@ var SyncCmd = &cobra.Command{
 		}
 
 		environ := bbgo.NewEnvironment()
-		if err := environ.ConfigureDatabase(ctx); err != nil {
+		if err := environ.ConfigureDefaultDatabase(ctx); err != nil {
 			return err
 		}
 
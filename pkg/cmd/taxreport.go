@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/accounting/pnl"
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func init() {
+	TaxReportCmd.Flags().String("session", "", "target exchange session")
+	TaxReportCmd.Flags().StringSlice("symbol", nil, "the trading symbols of the disposals, all the traded symbols are included if it's not set")
+	TaxReportCmd.Flags().Int("year", time.Now().Year()-1, "the tax year")
+	TaxReportCmd.Flags().String("format", "csv", "the output format: csv or json")
+	TaxReportCmd.Flags().String("method", "fifo", "the cost basis method of the disposals: fifo, lifo or average")
+	TaxReportCmd.Flags().String("include", "", "the comma separated taxable events besides the disposals: deposits, withdrawals and rewards")
+	TaxReportCmd.Flags().String("currency", "USDT", "the valuation currency of the deposits, the withdrawals and the rewards")
+	TaxReportCmd.Flags().String("output", "", "the output file, the report is written to stdout if it's not set")
+	TaxReportCmd.Flags().Bool("utc", false, "use UTC instead of the local time zone for the year range and the dates")
+	RootCmd.AddCommand(TaxReportCmd)
+}
+
+// TaxReportCmd exports the disposals of the tax year with the acquisition dates, the cost basis, the proceeds and the
+// gains, the deposits, the withdrawals and the rewards can be included as the taxable events.
+var TaxReportCmd = &cobra.Command{
+	Use:          "tax-report",
+	Short:        "export the tax report of the year",
+	Example:      "bbgo tax-report --session binance --year 2021 --format csv --include rewards --output tax-2021.csv",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		configFile, err := cmd.Flags().GetString("config")
+		if err != nil {
+			return err
+		}
+
+		if len(configFile) == 0 {
+			return errors.New("--config option is required")
+		}
+
+		if _, err := os.Stat(configFile); os.IsNotExist(err) {
+			return err
+		}
+
+		userConfig, err := bbgo.Load(configFile, false)
+		if err != nil {
+			return err
+		}
+
+		sessionName, err := cmd.Flags().GetString("session")
+		if err != nil {
+			return err
+		}
+
+		symbols, err := cmd.Flags().GetStringSlice("symbol")
+		if err != nil {
+			return err
+		}
+
+		year, err := cmd.Flags().GetInt("year")
+		if err != nil {
+			return err
+		}
+
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+
+		if format != "csv" && format != "json" {
+			return fmt.Errorf("unsupported format %q, valid formats are csv and json", format)
+		}
+
+		method, err := cmd.Flags().GetString("method")
+		if err != nil {
+			return err
+		}
+
+		costBasisMethod, err := pnl.ParseCostBasisMethod(method)
+		if err != nil {
+			return err
+		}
+
+		include, err := cmd.Flags().GetString("include")
+		if err != nil {
+			return err
+		}
+
+		taxableEvents, err := pnl.ParseTaxEventTypes(include)
+		if err != nil {
+			return err
+		}
+
+		currency, err := cmd.Flags().GetString("currency")
+		if err != nil {
+			return err
+		}
+
+		output, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+
+		useUTC, err := cmd.Flags().GetBool("utc")
+		if err != nil {
+			return err
+		}
+
+		location := time.Local
+		if useUTC {
+			location = time.UTC
+		}
+
+		environ := bbgo.NewEnvironment()
+
+		if err := environ.ConfigureDefaultDatabase(ctx); err != nil {
+			return err
+		}
+
+		if environ.DatabaseService == nil {
+			return errors.New("database is not configured, the tax report is built from the synced records")
+		}
+
+		if err := environ.ConfigureExchangeSessions(userConfig); err != nil {
+			return err
+		}
+
+		session, ok := environ.Session(sessionName)
+		if !ok {
+			return fmt.Errorf("session %s not found", sessionName)
+		}
+
+		if err := environ.SyncSession(ctx, session, symbols...); err != nil {
+			return err
+		}
+
+		exchangeName := session.Exchange.Name()
+		backtestService := &service.BacktestService{DB: environ.DatabaseService.DB}
+
+		builder := &pnl.TaxReportBuilder{
+			Year:          year,
+			Method:        costBasisMethod,
+			Location:      location,
+			TaxableEvents: taxableEvents,
+			Currency:      currency,
+			PriceLookup: func(asset string, t time.Time) (float64, bool) {
+				klines, err := backtestService.QueryKLinesBackward(exchangeName, asset+currency, types.Interval1d, t, 1)
+				if err != nil || len(klines) == 0 {
+					log.Warnf("can not find the daily kline of %s%s at %s, the value is zero", asset, currency, t)
+					return 0, false
+				}
+
+				return klines[0].Close, true
+			},
+		}
+
+		trades, err := environ.TradeService.Query(service.QueryTradesOptions{
+			Exchange: exchangeName,
+		})
+		if err != nil {
+			return err
+		}
+
+		tradesBySymbol := make(map[string][]types.Trade)
+		for _, trade := range trades {
+			tradesBySymbol[trade.Symbol] = append(tradesBySymbol[trade.Symbol], trade)
+		}
+
+		if len(symbols) == 0 {
+			for symbol := range tradesBySymbol {
+				symbols = append(symbols, symbol)
+			}
+			sort.Strings(symbols)
+		}
+
+		for _, symbol := range symbols {
+			market, ok := session.Market(symbol)
+			if !ok {
+				return fmt.Errorf("market config %s not found", symbol)
+			}
+
+			symbolTrades := tradesBySymbol[symbol]
+			sort.SliceStable(symbolTrades, func(i, j int) bool {
+				return symbolTrades[i].Time.Time().Before(symbolTrades[j].Time.Time())
+			})
+
+			log.Infof("%d %s trades loaded", len(symbolTrades), symbol)
+			builder.AddTrades(market, symbolTrades)
+		}
+
+		for _, eventType := range taxableEvents {
+			switch eventType {
+			case pnl.TaxEventDeposit:
+				deposits, err := environ.SyncService.DepositService.Query(exchangeName)
+				if err != nil {
+					return err
+				}
+				builder.AddDeposits(deposits)
+
+			case pnl.TaxEventWithdrawal:
+				withdrawals, err := environ.SyncService.WithdrawService.Query(exchangeName)
+				if err != nil {
+					return err
+				}
+				builder.AddWithdrawals(withdrawals)
+
+			case pnl.TaxEventReward:
+				since, until := builder.Range()
+				rewards, err := environ.RewardService.QueryRange(ctx, exchangeName, since, until)
+				if err != nil {
+					return err
+				}
+				builder.AddRewards(rewards)
+			}
+		}
+
+		report := builder.Build()
+
+		var w io.Writer = os.Stdout
+		if len(output) > 0 {
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+
+			defer f.Close()
+			w = f
+		}
+
+		if format == "json" {
+			encoder := json.NewEncoder(w)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(report)
+		}
+
+		if err := report.WriteCSV(w); err != nil {
+			return err
+		}
+
+		for eventType, totals := range report.Totals() {
+			for currency, gain := range totals {
+				log.Infof("total %s gain: %f %s", eventType, gain, currency)
+			}
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func init() {
+	transferCmd.Flags().String("session", "", "the exchange session name")
+	transferCmd.Flags().String("asset", "", "the asset to transfer, like USDT")
+	transferCmd.Flags().Float64("amount", 0, "the amount to transfer")
+	transferCmd.Flags().String("from", string(types.WalletTypeSpot), "the source wallet: spot, margin or futures")
+	transferCmd.Flags().String("to", "", "the destination wallet: spot, margin or futures")
+	transferCmd.Flags().Bool("confirm", false, "confirm the transfer, nothing is transferred without this flag")
+	RootCmd.AddCommand(transferCmd)
+
+	withdrawCmd.Flags().String("session", "", "the exchange session name")
+	withdrawCmd.Flags().String("asset", "", "the asset to withdraw, like USDT")
+	withdrawCmd.Flags().Float64("amount", 0, "the amount to withdraw")
+	withdrawCmd.Flags().String("address", "", "the withdrawal address, it must be whitelisted in the withdrawalAddresses of the session")
+	withdrawCmd.Flags().String("address-tag", "", "the address tag (memo) of the withdrawal address")
+	withdrawCmd.Flags().String("network", "", "the network of the withdrawal address, like ETH or TRX")
+	withdrawCmd.Flags().Bool("confirm", false, "confirm the withdrawal, nothing is withdrawn without this flag")
+	RootCmd.AddCommand(withdrawCmd)
+}
+
+// go run ./cmd/bbgo transfer --session=binance --asset=USDT --amount=100 --from=spot --to=margin --confirm
+var transferCmd = &cobra.Command{
+	Use:          "transfer",
+	Short:        "transfer the asset between the spot, margin and futures wallets of a session",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		asset, amount, err := getWalletAssetAmount(cmd)
+		if err != nil {
+			return err
+		}
+
+		fromFlag, err := cmd.Flags().GetString("from")
+		if err != nil {
+			return err
+		}
+
+		toFlag, err := cmd.Flags().GetString("to")
+		if err != nil {
+			return err
+		}
+
+		from, err := types.ParseWalletType(fromFlag)
+		if err != nil {
+			return err
+		}
+
+		to, err := types.ParseWalletType(toFlag)
+		if err != nil {
+			return err
+		}
+
+		confirmed, err := cmd.Flags().GetBool("confirm")
+		if err != nil {
+			return err
+		}
+
+		session, err := getWalletSession(cmd)
+		if err != nil {
+			return err
+		}
+
+		if !confirmed {
+			log.Warnf("transferring %f %s from %s to %s of session %s, add --confirm to execute the transfer", amount.Float64(), asset, from, to, session.Name)
+			return nil
+		}
+
+		if err := session.TransferWallet(ctx, asset, amount, from, to); err != nil {
+			return err
+		}
+
+		log.Infof("transferred %f %s from %s to %s of session %s", amount.Float64(), asset, from, to, session.Name)
+		return nil
+	},
+}
+
+// go run ./cmd/bbgo withdraw --session=binance --asset=USDT --amount=100 --address=0x... --network=ETH --confirm
+var withdrawCmd = &cobra.Command{
+	Use:          "withdraw",
+	Short:        "withdraw the asset to a whitelisted address",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		asset, amount, err := getWalletAssetAmount(cmd)
+		if err != nil {
+			return err
+		}
+
+		address, err := cmd.Flags().GetString("address")
+		if err != nil {
+			return err
+		}
+
+		if len(address) == 0 {
+			return errors.New("--address is required")
+		}
+
+		addressTag, err := cmd.Flags().GetString("address-tag")
+		if err != nil {
+			return err
+		}
+
+		network, err := cmd.Flags().GetString("network")
+		if err != nil {
+			return err
+		}
+
+		confirmed, err := cmd.Flags().GetBool("confirm")
+		if err != nil {
+			return err
+		}
+
+		session, err := getWalletSession(cmd)
+		if err != nil {
+			return err
+		}
+
+		options := &types.WithdrawalOptions{Network: network, AddressTag: addressTag}
+		if _, ok := session.FindWithdrawalAddress(asset, address, options); !ok {
+			return errors.Wrapf(bbgo.ErrWithdrawalAddressNotWhitelisted, "%s address %s of session %s", asset, address, session.Name)
+		}
+
+		if !confirmed {
+			log.Warnf("withdrawing %f %s to %s (network %q) from session %s, add --confirm to submit the withdrawal", amount.Float64(), asset, address, network, session.Name)
+			return nil
+		}
+
+		if err := session.Withdraw(ctx, asset, amount, address, options); err != nil {
+			return err
+		}
+
+		log.Infof("submitted the withdrawal of %f %s to %s from session %s", amount.Float64(), asset, address, session.Name)
+		return nil
+	},
+}
+
+func getWalletAssetAmount(cmd *cobra.Command) (string, fixedpoint.Value, error) {
+	asset, err := cmd.Flags().GetString("asset")
+	if err != nil {
+		return "", 0, err
+	}
+
+	if len(asset) == 0 {
+		return "", 0, errors.New("--asset is required")
+	}
+
+	amount, err := cmd.Flags().GetFloat64("amount")
+	if err != nil {
+		return "", 0, err
+	}
+
+	if amount <= 0 {
+		return "", 0, errors.New("--amount must be positive")
+	}
+
+	return strings.ToUpper(asset), fixedpoint.NewFromFloat(amount), nil
+}
+
+func getWalletSession(cmd *cobra.Command) (*bbgo.ExchangeSession, error) {
+	if userConfig == nil {
+		return nil, errors.New("config file is required")
+	}
+
+	sessionName, err := cmd.Flags().GetString("session")
+	if err != nil {
+		return nil, err
+	}
+
+	environ := bbgo.NewEnvironment()
+	if err := environ.ConfigureExchangeSessions(userConfig); err != nil {
+		return nil, err
+	}
+
+	session, ok := environ.Session(sessionName)
+	if !ok {
+		return nil, fmt.Errorf("session %s not found", sessionName)
+	}
+
+	return session, nil
+}
@@ -110,6 +110,9 @@ func (e KLineBatchQuery) Query(ctx context.Context, symbol string, interval type
 				}
 
 				if kline.StartTime.After(endTime) {
+					if len(batchKLines) > 0 {
+						c <- batchKLines
+					}
 					return
 				}
 
@@ -117,7 +120,7 @@ func (e KLineBatchQuery) Query(ctx context.Context, symbol string, interval type
 
 				if len(batchKLines) == BatchSize {
 					c <- batchKLines
-					batchKLines = batchKLines[:0]
+					batchKLines = make([]types.KLine, 0, BatchSize)
 				}
 
 				//The issue is in FTX, prev endtime = next start time , so if add 1 ms , it would query forever.
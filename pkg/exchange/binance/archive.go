@@ -0,0 +1,348 @@
+package binance
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// DefaultArchiveBaseURL is the base url of the binance public data archives, see https://github.com/binance/binance-public-data
+const DefaultArchiveBaseURL = "https://data.binance.vision"
+
+const defaultArchiveBatchSize = 1000
+
+// ErrArchiveNotFound is returned when the archive file does not exist,
+// the monthly archive of the current month is not published until the month ends, use the daily archives instead.
+var ErrArchiveNotFound = errors.New("binance archive not found")
+
+type ArchivePeriod string
+
+const (
+	ArchivePeriodMonthly = ArchivePeriod("monthly")
+	ArchivePeriodDaily   = ArchivePeriod("daily")
+)
+
+func (p ArchivePeriod) dateString(t time.Time) string {
+	if p == ArchivePeriodDaily {
+		return t.Format("2006-01-02")
+	}
+	return t.Format("2006-01")
+}
+
+// ArchiveDownloader downloads the zipped csv archives of the binance public data,
+// it's much faster than querying the REST api page by page for building the multi-year backtest datasets.
+type ArchiveDownloader struct {
+	BaseURL string
+
+	// Futures downloads the USDT-M futures archives instead of the spot archives
+	Futures bool
+
+	// BatchSize is the number of the records passed to the handler at once
+	BatchSize int
+
+	Client *http.Client
+}
+
+func NewArchiveDownloader() *ArchiveDownloader {
+	return &ArchiveDownloader{
+		BaseURL:   DefaultArchiveBaseURL,
+		BatchSize: defaultArchiveBatchSize,
+		Client: &http.Client{
+			Timeout: 10 * time.Minute,
+		},
+	}
+}
+
+func (d *ArchiveDownloader) marketPath() string {
+	if d.Futures {
+		return "futures/um"
+	}
+	return "spot"
+}
+
+// KLineArchiveURL returns the url of the kline archive, e.g.,
+// https://data.binance.vision/data/spot/monthly/klines/BTCUSDT/1m/BTCUSDT-1m-2021-01.zip
+func (d *ArchiveDownloader) KLineArchiveURL(symbol string, interval types.Interval, period ArchivePeriod, date time.Time) string {
+	return fmt.Sprintf("%s/data/%s/%s/klines/%s/%s/%s-%s-%s.zip",
+		d.BaseURL, d.marketPath(), period, symbol, interval, symbol, interval, period.dateString(date))
+}
+
+// AggTradeArchiveURL returns the url of the aggregated trade archive, e.g.,
+// https://data.binance.vision/data/spot/monthly/aggTrades/BTCUSDT/BTCUSDT-aggTrades-2021-01.zip
+func (d *ArchiveDownloader) AggTradeArchiveURL(symbol string, period ArchivePeriod, date time.Time) string {
+	return fmt.Sprintf("%s/data/%s/%s/aggTrades/%s/%s-aggTrades-%s.zip",
+		d.BaseURL, d.marketPath(), period, symbol, symbol, period.dateString(date))
+}
+
+// DownloadKLines downloads the kline archive and passes the parsed klines to the handler in batches
+func (d *ArchiveDownloader) DownloadKLines(ctx context.Context, symbol string, interval types.Interval, period ArchivePeriod, date time.Time, handler func(klines []types.KLine) error) error {
+	var batch []types.KLine
+	err := d.download(ctx, d.KLineArchiveURL(symbol, interval, period, date), func(record []string) error {
+		kline, err := parseArchiveKLine(symbol, interval, record)
+		if err != nil {
+			return err
+		}
+
+		batch = append(batch, kline)
+		if len(batch) >= d.batchSize() {
+			err = handler(batch)
+			batch = nil
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(batch) > 0 {
+		return handler(batch)
+	}
+
+	return nil
+}
+
+// DownloadAggTrades downloads the aggregated trade archive and passes the parsed trades to the handler in batches
+func (d *ArchiveDownloader) DownloadAggTrades(ctx context.Context, symbol string, period ArchivePeriod, date time.Time, handler func(trades []types.AggTrade) error) error {
+	var batch []types.AggTrade
+	err := d.download(ctx, d.AggTradeArchiveURL(symbol, period, date), func(record []string) error {
+		trade, err := parseArchiveAggTrade(symbol, record)
+		if err != nil {
+			return err
+		}
+
+		batch = append(batch, trade)
+		if len(batch) >= d.batchSize() {
+			err = handler(batch)
+			batch = nil
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(batch) > 0 {
+		return handler(batch)
+	}
+
+	return nil
+}
+
+func (d *ArchiveDownloader) batchSize() int {
+	if d.BatchSize > 0 {
+		return d.BatchSize
+	}
+	return defaultArchiveBatchSize
+}
+
+// download saves the zip archive into a temporary file (zip requires random access) and reads the csv records of the archive
+func (d *ArchiveDownloader) download(ctx context.Context, archiveURL string, handler func(record []string) error) error {
+	req, err := http.NewRequest("GET", archiveURL, nil)
+	if err != nil {
+		return err
+	}
+
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return errors.Wrap(ErrArchiveNotFound, archiveURL)
+
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("unexpected response status %s: %s", resp.Status, archiveURL)
+	}
+
+	file, err := ioutil.TempFile("", "bbgo-binance-archive-*.zip")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	size, err := io.Copy(file, resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "can not download %s", archiveURL)
+	}
+
+	log.Debugf("downloaded %s (%d bytes)", archiveURL, size)
+
+	return readArchive(file, size, handler)
+}
+
+// readArchive reads the csv records of all the csv files in the zip archive,
+// the header row (the newer archives have the header) is skipped.
+func readArchive(r io.ReaderAt, size int64, handler func(record []string) error) error {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range archive.File {
+		if !strings.HasSuffix(f.Name, ".csv") {
+			continue
+		}
+
+		if err := readArchiveCSV(f, handler); err != nil {
+			return errors.Wrapf(err, "can not read %s", f.Name)
+		}
+	}
+
+	return nil
+}
+
+func readArchiveCSV(f *zip.File, handler func(record []string) error) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	reader := csv.NewReader(rc)
+	reader.ReuseRecord = true
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if len(record) == 0 {
+			continue
+		}
+
+		// skip the header row
+		if _, err := strconv.ParseInt(record[0], 10, 64); err != nil {
+			continue
+		}
+
+		if err := handler(record); err != nil {
+			return err
+		}
+	}
+}
+
+// parseArchiveTime parses the timestamp in the archive, the timestamps are in milliseconds,
+// but the newer spot archives use microseconds.
+func parseArchiveTime(s string) (time.Time, error) {
+	ts, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if ts > 1e14 {
+		return time.Unix(0, ts*int64(time.Microsecond)), nil
+	}
+
+	return time.Unix(0, ts*int64(time.Millisecond)), nil
+}
+
+// parseArchiveKLine parses the kline record:
+// open_time, open, high, low, close, volume, close_time, quote_volume, count, taker_buy_volume, taker_buy_quote_volume, ignore
+func parseArchiveKLine(symbol string, interval types.Interval, record []string) (kline types.KLine, err error) {
+	if len(record) < 11 {
+		return kline, fmt.Errorf("unexpected kline record: %v", record)
+	}
+
+	var values [11]float64
+	for _, i := range []int{1, 2, 3, 4, 5, 7, 8, 9, 10} {
+		values[i], err = strconv.ParseFloat(record[i], 64)
+		if err != nil {
+			return kline, errors.Wrapf(err, "can not parse kline record: %v", record)
+		}
+	}
+
+	startTime, err := parseArchiveTime(record[0])
+	if err != nil {
+		return kline, err
+	}
+
+	endTime, err := parseArchiveTime(record[6])
+	if err != nil {
+		return kline, err
+	}
+
+	return types.KLine{
+		Exchange:                 types.ExchangeBinance,
+		Symbol:                   symbol,
+		StartTime:                startTime,
+		EndTime:                  endTime,
+		Interval:                 interval,
+		Open:                     values[1],
+		High:                     values[2],
+		Low:                      values[3],
+		Close:                    values[4],
+		Volume:                   values[5],
+		QuoteVolume:              values[7],
+		NumberOfTrades:           uint64(values[8]),
+		TakerBuyBaseAssetVolume:  values[9],
+		TakerBuyQuoteAssetVolume: values[10],
+		Closed:                   true,
+	}, nil
+}
+
+// parseArchiveAggTrade parses the aggregated trade record:
+// agg_trade_id, price, quantity, first_trade_id, last_trade_id, transact_time, is_buyer_maker, (is_best_match)
+func parseArchiveAggTrade(symbol string, record []string) (trade types.AggTrade, err error) {
+	if len(record) < 7 {
+		return trade, fmt.Errorf("unexpected agg trade record: %v", record)
+	}
+
+	trade.Exchange = types.ExchangeBinance
+	trade.Symbol = symbol
+
+	if trade.ID, err = strconv.ParseInt(record[0], 10, 64); err != nil {
+		return trade, err
+	}
+
+	if trade.Price, err = strconv.ParseFloat(record[1], 64); err != nil {
+		return trade, err
+	}
+
+	if trade.Quantity, err = strconv.ParseFloat(record[2], 64); err != nil {
+		return trade, err
+	}
+
+	if trade.FirstTradeID, err = strconv.ParseInt(record[3], 10, 64); err != nil {
+		return trade, err
+	}
+
+	if trade.LastTradeID, err = strconv.ParseInt(record[4], 10, 64); err != nil {
+		return trade, err
+	}
+
+	tradedAt, err := parseArchiveTime(record[5])
+	if err != nil {
+		return trade, err
+	}
+	trade.Time = types.Time(tradedAt)
+
+	if trade.IsBuyerMaker, err = strconv.ParseBool(record[6]); err != nil {
+		return trade, err
+	}
+
+	return trade, nil
+}
@@ -0,0 +1,136 @@
+package binance
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func newTestArchive(t *testing.T, name, content string) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, err := w.Create(name)
+	assert.NoError(t, err)
+
+	_, err = f.Write([]byte(content))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestArchiveDownloader_URL(t *testing.T) {
+	d := NewArchiveDownloader()
+	date := time.Date(2021, time.January, 2, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "https://data.binance.vision/data/spot/monthly/klines/BTCUSDT/1m/BTCUSDT-1m-2021-01.zip",
+		d.KLineArchiveURL("BTCUSDT", types.Interval1m, ArchivePeriodMonthly, date))
+	assert.Equal(t, "https://data.binance.vision/data/spot/daily/aggTrades/BTCUSDT/BTCUSDT-aggTrades-2021-01-02.zip",
+		d.AggTradeArchiveURL("BTCUSDT", ArchivePeriodDaily, date))
+
+	d.Futures = true
+	assert.Equal(t, "https://data.binance.vision/data/futures/um/monthly/klines/BTCUSDT/1h/BTCUSDT-1h-2021-01.zip",
+		d.KLineArchiveURL("BTCUSDT", types.Interval1h, ArchivePeriodMonthly, date))
+}
+
+func Test_parseArchiveTime(t *testing.T) {
+	ms, err := parseArchiveTime("1609459200000")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1609459200), ms.Unix())
+
+	us, err := parseArchiveTime("1609459200000000")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1609459200), us.Unix())
+}
+
+func TestArchiveDownloader_DownloadKLines(t *testing.T) {
+	content := "open_time,open,high,low,close,volume,close_time,quote_volume,count,taker_buy_volume,taker_buy_quote_volume,ignore\n" +
+		"1609459200000,28923.63,28961.66,28913.12,28961.66,27.457032,1609459259999,794382.47,1292,16.777195,485390.43,0\n" +
+		"1609459260000,28961.67,29017.50,28961.01,29009.91,58.477501,1609459319999,1695802.34,1651,33.733818,978176.45,0\n" +
+		"1609459320000,29009.54,29016.71,28973.58,28989.30,42.470329,1609459379999,1231531.21,986,13.247444,384130.02,0\n"
+
+	archive := newTestArchive(t, "BTCUSDT-1m-2021-01.csv", content)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/data/spot/monthly/klines/BTCUSDT/1m/BTCUSDT-1m-2021-01.zip" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(archive)
+	}))
+	defer server.Close()
+
+	d := NewArchiveDownloader()
+	d.BaseURL = server.URL
+	d.BatchSize = 2
+
+	var batches [][]types.KLine
+	err := d.DownloadKLines(context.Background(), "BTCUSDT", types.Interval1m, ArchivePeriodMonthly, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), func(klines []types.KLine) error {
+		batches = append(batches, klines)
+		return nil
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, batches, 2) {
+		assert.Len(t, batches[0], 2)
+		assert.Len(t, batches[1], 1)
+
+		kline := batches[0][0]
+		assert.Equal(t, types.ExchangeBinance, kline.Exchange)
+		assert.Equal(t, "BTCUSDT", kline.Symbol)
+		assert.Equal(t, types.Interval1m, kline.Interval)
+		assert.Equal(t, int64(1609459200), kline.StartTime.Unix())
+		assert.Equal(t, 28923.63, kline.Open)
+		assert.Equal(t, 28961.66, kline.High)
+		assert.Equal(t, 28913.12, kline.Low)
+		assert.Equal(t, 28961.66, kline.Close)
+		assert.Equal(t, 27.457032, kline.Volume)
+		assert.Equal(t, 794382.47, kline.QuoteVolume)
+		assert.Equal(t, uint64(1292), kline.NumberOfTrades)
+		assert.Equal(t, 16.777195, kline.TakerBuyBaseAssetVolume)
+		assert.True(t, kline.Closed)
+	}
+
+	err = d.DownloadKLines(context.Background(), "ETHUSDT", types.Interval1m, ArchivePeriodMonthly, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), func(klines []types.KLine) error {
+		return nil
+	})
+	assert.True(t, errors.Is(err, ErrArchiveNotFound))
+}
+
+func TestArchiveDownloader_DownloadAggTrades(t *testing.T) {
+	content := "26129,0.01633102,4.70443515,27781,27781,1498793709153,true,true\n" +
+		"26130,0.01633100,0.20000000,27782,27783,1498793729741,false,true\n"
+
+	archive := newTestArchive(t, "BNBBTC-aggTrades-2017-07.csv", content)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(archive)
+	}))
+	defer server.Close()
+
+	d := NewArchiveDownloader()
+	d.BaseURL = server.URL
+
+	var trades []types.AggTrade
+	err := d.DownloadAggTrades(context.Background(), "BNBBTC", ArchivePeriodMonthly, time.Date(2017, 7, 1, 0, 0, 0, 0, time.UTC), func(batch []types.AggTrade) error {
+		trades = append(trades, batch...)
+		return nil
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, trades, 2) {
+		assert.Equal(t, int64(26129), trades[0].ID)
+		assert.Equal(t, 0.01633102, trades[0].Price)
+		assert.Equal(t, 4.70443515, trades[0].Quantity)
+		assert.Equal(t, int64(27781), trades[0].FirstTradeID)
+		assert.True(t, trades[0].IsBuyerMaker)
+		assert.Equal(t, int64(1498793709), trades[0].Time.Time().Unix())
+
+		assert.Equal(t, int64(27783), trades[1].LastTradeID)
+		assert.False(t, trades[1].IsBuyerMaker)
+	}
+}
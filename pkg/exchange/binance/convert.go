@@ -50,6 +50,31 @@ func toGlobalMarket(symbol binance.Symbol) types.Market {
 	return market
 }
 
+func toGlobalFuturesMarket(symbol futures.Symbol) types.Market {
+	market := types.Market{
+		Symbol:          symbol.Symbol,
+		LocalSymbol:     symbol.Symbol,
+		PricePrecision:  symbol.PricePrecision,
+		VolumePrecision: symbol.QuantityPrecision,
+		QuoteCurrency:   symbol.QuoteAsset,
+		BaseCurrency:    symbol.BaseAsset,
+	}
+
+	if f := symbol.LotSizeFilter(); f != nil {
+		market.MinQuantity = util.MustParseFloat(f.MinQuantity)
+		market.MaxQuantity = util.MustParseFloat(f.MaxQuantity)
+		market.StepSize = util.MustParseFloat(f.StepSize)
+	}
+
+	if f := symbol.PriceFilter(); f != nil {
+		market.MaxPrice = util.MustParseFloat(f.MaxPrice)
+		market.MinPrice = util.MustParseFloat(f.MinPrice)
+		market.TickSize = util.MustParseFloat(f.TickSize)
+	}
+
+	return market
+}
+
 func toGlobalIsolatedUserAsset(userAsset binance.IsolatedUserAsset) types.IsolatedUserAsset {
 	return types.IsolatedUserAsset{
 		Asset:         userAsset.Asset,
@@ -139,6 +164,83 @@ func toGlobalTicker(stats *binance.PriceChangeStats) (*types.Ticker, error) {
 	}, nil
 }
 
+func toGlobalFuturesTicker(stats *futures.PriceChangeStats) (*types.Ticker, error) {
+	return &types.Ticker{
+		Volume: util.MustParseFloat(stats.Volume),
+		Last:   util.MustParseFloat(stats.LastPrice),
+		Open:   util.MustParseFloat(stats.OpenPrice),
+		High:   util.MustParseFloat(stats.HighPrice),
+		Low:    util.MustParseFloat(stats.LowPrice),
+		Time:   time.Unix(0, stats.CloseTime*int64(time.Millisecond)),
+	}, nil
+}
+
+// toGlobalFuturesBalanceMap converts the futures account assets, the max withdraw amount is the available balance,
+// and the rest of the wallet balance is locked as the margin.
+func toGlobalFuturesBalanceMap(assets []*futures.AccountAsset) types.BalanceMap {
+	balances := types.BalanceMap{}
+	for _, asset := range assets {
+		walletBalance := fixedpoint.Must(fixedpoint.NewFromString(asset.WalletBalance))
+		available := fixedpoint.Must(fixedpoint.NewFromString(asset.MaxWithdrawAmount))
+
+		locked := walletBalance - available
+		if locked < 0 {
+			locked = 0
+		}
+
+		balances[asset.Asset] = types.Balance{
+			Currency:  asset.Asset,
+			Available: available,
+			Locked:    locked,
+		}
+	}
+
+	return balances
+}
+
+func toGlobalFuturesPositions(risks []*futures.PositionRisk) types.PositionMap {
+	positions := types.PositionMap{}
+	for _, risk := range risks {
+		amount := fixedpoint.Must(fixedpoint.NewFromString(risk.PositionAmt))
+		if amount == 0 {
+			continue
+		}
+
+		entryPrice := fixedpoint.Must(fixedpoint.NewFromString(risk.EntryPrice))
+		positions[futuresPositionKey(risk.Symbol, risk.PositionSide)] = types.Position{
+			Symbol:           risk.Symbol,
+			Base:             amount,
+			AverageCost:      entryPrice,
+			EntryPrice:       entryPrice,
+			PositionAmt:      amount,
+			Leverage:         fixedpoint.Must(fixedpoint.NewFromString(risk.Leverage)),
+			UnrealizedProfit: fixedpoint.Must(fixedpoint.NewFromString(risk.UnRealizedProfit)),
+			MaxNotional:      fixedpoint.Must(fixedpoint.NewFromString(risk.MaxNotionalValue)),
+			Isolated:         risk.MarginType == "isolated",
+			IsolatedWallet:   fixedpoint.Must(fixedpoint.NewFromString(risk.IsolatedMargin)),
+			PositionSide:     risk.PositionSide,
+		}
+	}
+
+	return positions
+}
+
+const futuresIncomeTypeFundingFee = "FUNDING_FEE"
+
+func toGlobalFundingFee(income *futures.IncomeHistory) (*types.FundingFee, error) {
+	amount, err := fixedpoint.NewFromString(income.Income)
+	if err != nil {
+		return nil, errors.Wrapf(err, "income parse error, income: %+v", income.Income)
+	}
+
+	return &types.FundingFee{
+		Symbol: income.Symbol,
+		Asset:  income.Asset,
+		Amount: amount,
+		Time:   millisecondTime(income.Time),
+	}, nil
+}
+
 func toLocalOrderType(orderType types.OrderType) (binance.OrderType, error) {
 	switch orderType {
 
@@ -314,6 +416,50 @@ func toGlobalTrade(t binance.TradeV3, isMargin bool) (*types.Trade, error) {
 	}, nil
 }
 
+func toGlobalFuturesTrade(t futures.AccountTrade) (*types.Trade, error) {
+	price, err := strconv.ParseFloat(t.Price, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "price parse error, price: %+v", t.Price)
+	}
+
+	quantity, err := strconv.ParseFloat(t.Quantity, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "quantity parse error, quantity: %+v", t.Quantity)
+	}
+
+	var quoteQuantity = 0.0
+	if len(t.QuoteQuantity) > 0 {
+		quoteQuantity, err = strconv.ParseFloat(t.QuoteQuantity, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "quote quantity parse error, quoteQuantity: %+v", t.QuoteQuantity)
+		}
+	} else {
+		quoteQuantity = price * quantity
+	}
+
+	fee, err := strconv.ParseFloat(t.Commission, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "commission parse error, commission: %+v", t.Commission)
+	}
+
+	return &types.Trade{
+		ID:            t.ID,
+		OrderID:       uint64(t.OrderID),
+		Price:         price,
+		Symbol:        t.Symbol,
+		Exchange:      "binance",
+		Quantity:      quantity,
+		QuoteQuantity: quoteQuantity,
+		Side:          toGlobalFuturesSideType(t.Side),
+		IsBuyer:       t.Buyer,
+		IsMaker:       t.Maker,
+		Fee:           fee,
+		FeeCurrency:   t.CommissionAsset,
+		Time:          types.Time(millisecondTime(t.Time)),
+		IsFutures:     true,
+	}, nil
+}
+
 func toGlobalSideType(side binance.SideType) types.SideType {
 	switch side {
 	case binance.SideTypeBuy:
@@ -0,0 +1,91 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/futures"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// newDepthSnapshotFetcher returns the fetcher that fetches the depth snapshot from the REST api,
+// the depth is converted to the depth event so that we can reuse the event structure to convert it to the global orderbook type
+func newDepthSnapshotFetcher(client *binance.Client, symbol string) types.DepthSnapshotFetcher {
+	return func() (types.SliceOrderBook, int64, error) {
+		if debugBinanceDepth {
+			log.Infof("fetching %s depth snapshot", symbol)
+		}
+
+		response, err := client.NewDepthService().Symbol(symbol).Do(context.Background())
+		if err != nil {
+			return types.SliceOrderBook{}, 0, err
+		}
+
+		event := DepthEvent{
+			Symbol:        symbol,
+			FirstUpdateID: 0,
+			FinalUpdateID: response.LastUpdateID,
+		}
+
+		for _, entry := range response.Bids {
+			event.Bids = append(event.Bids, DepthEntry{PriceLevel: entry.Price, Quantity: entry.Quantity})
+		}
+
+		for _, entry := range response.Asks {
+			event.Asks = append(event.Asks, DepthEntry{PriceLevel: entry.Price, Quantity: entry.Quantity})
+		}
+
+		return depthSnapshot(event)
+	}
+}
+
+// newFuturesDepthSnapshotFetcher returns the fetcher that fetches the depth snapshot of the futures market, the futures
+// depth stream can not be seeded by the spot depth snapshot
+func newFuturesDepthSnapshotFetcher(client *futures.Client, symbol string) types.DepthSnapshotFetcher {
+	return func() (types.SliceOrderBook, int64, error) {
+		if debugBinanceDepth {
+			log.Infof("fetching %s futures depth snapshot", symbol)
+		}
+
+		response, err := client.NewDepthService().Symbol(symbol).Do(context.Background())
+		if err != nil {
+			return types.SliceOrderBook{}, 0, err
+		}
+
+		event := DepthEvent{
+			Symbol:        symbol,
+			FirstUpdateID: 0,
+			FinalUpdateID: response.LastUpdateID,
+		}
+
+		for _, entry := range response.Bids {
+			event.Bids = append(event.Bids, DepthEntry{PriceLevel: entry.Price, Quantity: entry.Quantity})
+		}
+
+		for _, entry := range response.Asks {
+			event.Asks = append(event.Asks, DepthEntry{PriceLevel: entry.Price, Quantity: entry.Quantity})
+		}
+
+		return depthSnapshot(event)
+	}
+}
+
+// depthSnapshot converts the depth snapshot event to the order book with the last update id of the snapshot
+func depthSnapshot(event DepthEvent) (types.SliceOrderBook, int64, error) {
+	if len(event.Asks) == 0 {
+		return types.SliceOrderBook{}, 0, fmt.Errorf("%s depth response error: empty asks", event.Symbol)
+	}
+
+	if len(event.Bids) == 0 {
+		return types.SliceOrderBook{}, 0, fmt.Errorf("%s depth response error: empty bids", event.Symbol)
+	}
+
+	if debugBinanceDepth {
+		log.Infof("fetched %s depth, last update ID = %d", event.Symbol, event.FinalUpdateID)
+	}
+
+	book, err := event.OrderBook()
+	return book, event.FinalUpdateID, err
+}
@@ -24,6 +24,11 @@ import (
 
 const BNB = "BNB"
 
+const (
+	testnetBaseURL        = "https://testnet.binance.vision"
+	futuresTestnetBaseURL = "https://testnet.binancefuture.com"
+)
+
 // 50 per 10 seconds = 5 per second
 var orderLimiter = rate.NewLimiter(5, 5)
 
@@ -35,6 +40,11 @@ func init() {
 	_ = types.Exchange(&Exchange{})
 	_ = types.MarginExchange(&Exchange{})
 	_ = types.FuturesExchange(&Exchange{})
+	_ = types.FuturesService(&Exchange{})
+	_ = types.ExchangeCapabilityProvider(&Exchange{})
+	_ = types.TestnetExchange(&Exchange{})
+	_ = types.ExchangeFeeDiscountService(&Exchange{})
+	_ = types.ExchangeWithdrawalNetworkService(&Exchange{})
 
 	// FIXME: this is not effected since dotenv is loaded in the rootCmd, not in the init function
 	if ok, _ := strconv.ParseBool(os.Getenv("DEBUG_BINANCE_STREAM")); ok {
@@ -47,6 +57,7 @@ type Exchange struct {
 	types.FuturesSettings
 
 	key, secret   string
+	testnet       bool
 	Client        *binance.Client // Spot & Margin
 	futuresClient *futures.Client // USDT-M Futures
 	// deliveryClient	*delivery.Client // Coin-M Futures
@@ -81,11 +92,27 @@ func New(key, secret string) *Exchange {
 	}
 }
 
+// UseTestnet switches the spot and the futures clients to the testnet endpoints,
+// note that the margin api is not available on the testnet.
+func (e *Exchange) UseTestnet() {
+	e.testnet = true
+	e.Client.BaseURL = testnetBaseURL
+	e.futuresClient.BaseURL = futuresTestnetBaseURL
+}
+
+func (e *Exchange) IsTestnet() bool {
+	return e.testnet
+}
+
 func (e *Exchange) Name() types.ExchangeName {
 	return types.ExchangeBinance
 }
 
 func (e *Exchange) QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
+	if e.IsFutures {
+		return e.queryFuturesTicker(ctx, symbol)
+	}
+
 	req := e.Client.NewListPriceChangeStatsService()
 	req.Symbol(strings.ToUpper(symbol))
 	stats, err := req.Do(ctx)
@@ -109,6 +136,10 @@ func (e *Exchange) QueryTickers(ctx context.Context, symbol ...string) (map[stri
 		return tickers, nil
 	}
 
+	if e.IsFutures {
+		return e.queryFuturesTickers(ctx, symbol...)
+	}
+
 	var req = e.Client.NewListPriceChangeStatsService()
 	changeStats, err := req.Do(ctx)
 	if err != nil {
@@ -147,6 +178,10 @@ func (e *Exchange) QueryTickers(ctx context.Context, symbol ...string) (map[stri
 func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
 	log.Info("querying market info...")
 
+	if e.IsFutures {
+		return e.queryFuturesMarkets(ctx)
+	}
+
 	exchangeInfo, err := e.Client.NewExchangeInfoService().Do(ctx)
 	if err != nil {
 		return nil, err
@@ -173,6 +208,7 @@ func (e *Exchange) NewStream() types.Stream {
 	stream := NewStream(e.Client, e.futuresClient)
 	stream.MarginSettings = e.MarginSettings
 	stream.FuturesSettings = e.FuturesSettings
+	stream.testnet = e.testnet
 	return stream
 }
 
@@ -199,6 +235,20 @@ func (e *Exchange) QueryIsolatedMarginAccount(ctx context.Context, symbols ...st
 	return toGlobalIsolatedMarginAccount(account), nil
 }
 
+// RepayMarginAsset repays the borrowed asset of the cross margin account, or the isolated margin account of the isolated symbol
+func (e *Exchange) RepayMarginAsset(ctx context.Context, asset string, amount fixedpoint.Value) error {
+	req := e.Client.NewMarginRepayService()
+	req.Asset(asset)
+	req.Amount(fmt.Sprintf("%f", amount.Float64()))
+	if e.IsIsolatedMargin {
+		req.IsolatedSymbol(e.IsolatedMarginSymbol)
+	}
+
+	log.Infof("repaying margin asset %s amount %f", asset, amount.Float64())
+	_, err := req.Do(ctx)
+	return err
+}
+
 func (e *Exchange) Withdrawal(ctx context.Context, asset string, amount fixedpoint.Value, address string, options *types.WithdrawalOptions) error {
 	req := e.Client.NewCreateWithdrawService()
 	req.Coin(asset)
@@ -210,7 +260,7 @@ func (e *Exchange) Withdrawal(ctx context.Context, asset string, amount fixedpoi
 			req.Network(options.Network)
 		}
 		if options.AddressTag != "" {
-			req.Network(options.AddressTag)
+			req.AddressTag(options.AddressTag)
 		}
 	}
 
@@ -223,6 +273,51 @@ func (e *Exchange) Withdrawal(ctx context.Context, asset string, amount fixedpoi
 	return nil
 }
 
+// TransferWallet transfers the asset between the spot wallet and the cross margin wallet or the USDT-M futures wallet
+func (e *Exchange) TransferWallet(ctx context.Context, asset string, amount fixedpoint.Value, from, to types.WalletType) error {
+	amountString := fmt.Sprintf("%f", amount.Float64())
+
+	switch {
+	case from == types.WalletTypeSpot && to == types.WalletTypeMargin:
+		_, err := e.Client.NewMarginTransferService().Asset(asset).Amount(amountString).Type(binance.MarginTransferTypeToMargin).Do(ctx)
+		return err
+
+	case from == types.WalletTypeMargin && to == types.WalletTypeSpot:
+		_, err := e.Client.NewMarginTransferService().Asset(asset).Amount(amountString).Type(binance.MarginTransferTypeToMain).Do(ctx)
+		return err
+
+	case from == types.WalletTypeSpot && to == types.WalletTypeFutures:
+		_, err := e.Client.NewFuturesTransferService().Asset(asset).Amount(amountString).Type(binance.FuturesTransferTypeToFutures).Do(ctx)
+		return err
+
+	case from == types.WalletTypeFutures && to == types.WalletTypeSpot:
+		_, err := e.Client.NewFuturesTransferService().Asset(asset).Amount(amountString).Type(binance.FuturesTransferTypeToMain).Do(ctx)
+		return err
+	}
+
+	return fmt.Errorf("wallet transfer from %s to %s is not supported", from, to)
+}
+
+func (e *Exchange) QueryDepositAddress(ctx context.Context, asset, network string) (*types.DepositAddress, error) {
+	req := e.Client.NewGetDepositAddressService()
+	req.Coin(asset)
+	if len(network) > 0 {
+		req.Network(network)
+	}
+
+	response, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.DepositAddress{
+		Asset:      response.Coin,
+		Network:    network,
+		Address:    response.Address,
+		AddressTag: response.Tag,
+	}, nil
+}
+
 func (e *Exchange) QueryWithdrawHistory(ctx context.Context, asset string, since, until time.Time) (allWithdraws []types.Withdraw, err error) {
 	startTime := since
 
@@ -394,7 +489,33 @@ func (e *Exchange) PlatformFeeCurrency() string {
 	return BNB
 }
 
+func (e *Exchange) Capabilities() types.ExchangeCapabilities {
+	return types.ExchangeCapabilities{
+		// the margin and the wallet api are not available on the testnet
+		Margin:          !e.testnet,
+		IsolatedMargin:  !e.testnet,
+		Futures:         true,
+		WebSocketKLines: true,
+		WebSocketBook:   true,
+		Withdrawal:      !e.testnet,
+		WalletTransfer:  !e.testnet,
+		DepositAddress:  !e.testnet,
+		OrderTypes: []types.OrderType{
+			types.OrderTypeLimit,
+			types.OrderTypeLimitMaker,
+			types.OrderTypeMarket,
+			types.OrderTypeStopLimit,
+			types.OrderTypeStopMarket,
+		},
+		TimeInForces: []string{types.TimeInForceGTC, types.TimeInForceIOC, types.TimeInForceFOK},
+	}
+}
+
 func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	if e.IsFutures {
+		return e.queryFuturesAccount(ctx)
+	}
+
 	account, err := e.Client.NewGetAccountService().Do(ctx)
 	if err != nil {
 		return nil, err
@@ -419,6 +540,15 @@ func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
 }
 
 func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	if e.IsFutures {
+		futuresOrders, err := e.futuresClient.NewListOpenOrdersService().Symbol(symbol).Do(ctx)
+		if err != nil {
+			return orders, err
+		}
+
+		return toGlobalFuturesOrders(futuresOrders)
+	}
+
 	if e.IsMargin {
 		req := e.Client.NewListMarginOpenOrdersService().Symbol(symbol)
 		req.IsIsolated(e.IsIsolatedMargin)
@@ -465,6 +595,24 @@ func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since,
 		return toGlobalOrders(binanceOrders)
 	}
 
+	if e.IsFutures {
+		req := e.futuresClient.NewListOrdersService().Symbol(symbol)
+
+		if lastOrderID > 0 {
+			req.OrderID(int64(lastOrderID))
+		} else {
+			req.StartTime(since.UnixNano() / int64(time.Millisecond)).
+				EndTime(until.UnixNano() / int64(time.Millisecond))
+		}
+
+		futuresOrders, err := req.Do(ctx)
+		if err != nil {
+			return orders, err
+		}
+
+		return toGlobalFuturesOrders(futuresOrders)
+	}
+
 	req := e.Client.NewListOrdersService().
 		Symbol(symbol)
 
@@ -484,6 +632,10 @@ func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since,
 }
 
 func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) (err2 error) {
+	if e.IsFutures {
+		return e.cancelFuturesOrders(ctx, orders...)
+	}
+
 	for _, o := range orders {
 		var req = e.Client.NewCancelOrderService()
 
@@ -506,8 +658,36 @@ func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) (err
 	return err2
 }
 
+// CancelOrdersBySymbol cancels all the open orders of the symbol in one request, the open orders are queried before
+// the cancel since the cancel response does not contain the full order information
+func (e *Exchange) CancelOrdersBySymbol(ctx context.Context, symbol string) ([]types.Order, error) {
+	orders, err := e.QueryOpenOrders(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(orders) == 0 {
+		return nil, nil
+	}
+
+	if e.IsFutures {
+		return orders, e.futuresClient.NewCancelAllOpenOrdersService().Symbol(symbol).Do(ctx)
+	}
+
+	// the margin open orders are canceled one by one
+	if e.IsMargin {
+		return orders, e.CancelOrders(ctx, orders...)
+	}
+
+	if _, err := e.Client.NewCancelOpenOrdersService().Symbol(symbol).Do(ctx); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
 func (e *Exchange) submitMarginOrder(ctx context.Context, order types.SubmitOrder) (*types.Order, error) {
-	orderType, err := toLocalOrderType(order.Type)
+	orderType, err := toLocalOrderType(order.EffectiveType())
 	if err != nil {
 		return nil, err
 	}
@@ -563,13 +743,16 @@ func (e *Exchange) submitMarginOrder(ctx context.Context, order types.SubmitOrde
 	}
 
 	// could be IOC or FOK
-	if len(order.TimeInForce) > 0 {
-		// TODO: check the TimeInForce value
-		req.TimeInForce(binance.TimeInForceType(order.TimeInForce))
-	} else {
-		switch order.Type {
-		case types.OrderTypeLimit, types.OrderTypeStopLimit:
-			req.TimeInForce(binance.TimeInForceTypeGTC)
+	// the limit maker orders do not accept the time in force parameter
+	if orderType != binance.OrderTypeLimitMaker {
+		if len(order.TimeInForce) > 0 {
+			// TODO: check the TimeInForce value
+			req.TimeInForce(binance.TimeInForceType(order.TimeInForce))
+		} else {
+			switch order.Type {
+			case types.OrderTypeLimit, types.OrderTypeStopLimit:
+				req.TimeInForce(binance.TimeInForceTypeGTC)
+			}
 		}
 	}
 
@@ -601,7 +784,12 @@ func (e *Exchange) submitMarginOrder(ctx context.Context, order types.SubmitOrde
 }
 
 func (e *Exchange) submitFuturesOrder(ctx context.Context, order types.SubmitOrder) (*types.Order, error) {
-	orderType, err := toLocalFuturesOrderType(order.Type)
+	submitType := order.Type
+	if order.IsPostOnly() {
+		submitType = types.OrderTypeLimit
+	}
+
+	orderType, err := toLocalFuturesOrderType(submitType)
 	if err != nil {
 		return nil, err
 	}
@@ -652,7 +840,10 @@ func (e *Exchange) submitFuturesOrder(ctx context.Context, order types.SubmitOrd
 	}
 
 	// could be IOC or FOK
-	if len(order.TimeInForce) > 0 {
+	if order.IsPostOnly() {
+		// futures does not have the limit maker order type, GTX (good till crossing) is the post-only limit order
+		req.TimeInForce(futures.TimeInForceTypeGTX)
+	} else if len(order.TimeInForce) > 0 {
 		// TODO: check the TimeInForce value
 		req.TimeInForce(futures.TimeInForceType(order.TimeInForce))
 	} else {
@@ -720,7 +911,7 @@ func newSpotClientOrderID(originalID string) (clientOrderID string) {
 }
 
 func (e *Exchange) submitSpotOrder(ctx context.Context, order types.SubmitOrder) (*types.Order, error) {
-	orderType, err := toLocalOrderType(order.Type)
+	orderType, err := toLocalOrderType(order.EffectiveType())
 	if err != nil {
 		return nil, err
 	}
@@ -762,13 +953,16 @@ func (e *Exchange) submitSpotOrder(ctx context.Context, order types.SubmitOrder)
 		req.StopPrice(order.StopPriceString)
 	}
 
-	if len(order.TimeInForce) > 0 {
-		// TODO: check the TimeInForce value
-		req.TimeInForce(binance.TimeInForceType(order.TimeInForce))
-	} else {
-		switch order.Type {
-		case types.OrderTypeLimit, types.OrderTypeStopLimit:
-			req.TimeInForce(binance.TimeInForceTypeGTC)
+	// the limit maker orders do not accept the time in force parameter
+	if orderType != binance.OrderTypeLimitMaker {
+		if len(order.TimeInForce) > 0 {
+			// TODO: check the TimeInForce value
+			req.TimeInForce(binance.TimeInForceType(order.TimeInForce))
+		} else {
+			switch order.Type {
+			case types.OrderTypeLimit, types.OrderTypeStopLimit:
+				req.TimeInForce(binance.TimeInForceTypeGTC)
+			}
 		}
 	}
 
@@ -854,6 +1048,10 @@ func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval type
 
 	log.Infof("querying kline %s %s %v", symbol, interval, options)
 
+	if e.IsFutures {
+		return e.queryFuturesKLines(ctx, symbol, interval, limit, options)
+	}
+
 	req := e.Client.NewKlinesService().
 		Symbol(symbol).
 		Interval(string(interval)).
@@ -897,6 +1095,10 @@ func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval type
 }
 
 func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
+	if e.IsFutures {
+		return e.queryFuturesTrades(ctx, symbol, options)
+	}
+
 	var remoteTrades []*binance.TradeV3
 
 	if e.IsMargin {
@@ -15,3 +15,17 @@ func Test_newClientOrderID(t *testing.T) {
 	cID = newSpotClientOrderID("myid1")
 	assert.Equal(t, cID, "x-" + spotBrokerID + "myid1")
 }
+
+func TestStream_baseURL(t *testing.T) {
+	stream := NewStream(nil, nil)
+	assert.Equal(t, "wss://stream.binance.com:9443/ws", stream.baseURL())
+
+	stream.testnet = true
+	assert.Equal(t, "wss://testnet.binance.vision/ws", stream.baseURL())
+
+	stream.IsFutures = true
+	assert.Equal(t, "wss://stream.binancefuture.com/ws", stream.baseURL())
+
+	stream.testnet = false
+	assert.Equal(t, "wss://fstream.binance.com/ws", stream.baseURL())
+}
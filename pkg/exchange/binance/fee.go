@@ -0,0 +1,50 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// bnbBurnDiscount is the spot trading fee discount when the fee is paid with BNB
+var bnbBurnDiscount = fixedpoint.NewFromFloat(0.25)
+
+// BNBBurnStatus is the response of GET /sapi/v1/bnbBurn
+type BNBBurnStatus struct {
+	SpotBNBBurn     bool `json:"spotBNBBurn"`
+	InterestBNBBurn bool `json:"interestBNBBurn"`
+}
+
+// QueryBNBBurnStatus queries whether the spot trading fee and the margin interest are paid with BNB
+func (e *Exchange) QueryBNBBurnStatus(ctx context.Context) (*BNBBurnStatus, error) {
+	body, err := e.signedGet(ctx, "/sapi/v1/bnbBurn", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var status BNBBurnStatus
+	if err := json.Unmarshal(body, &status); err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+// QueryFeeDiscount returns the BNB discount of the spot trading fee, futures and margin accounts have no discount
+func (e *Exchange) QueryFeeDiscount(ctx context.Context) (fixedpoint.Value, error) {
+	if e.IsFutures || e.IsMargin {
+		return 0, nil
+	}
+
+	status, err := e.QueryBNBBurnStatus(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if !status.SpotBNBBurn {
+		return 0, nil
+	}
+
+	return bnbBurnDiscount, nil
+}
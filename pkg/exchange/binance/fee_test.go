@@ -0,0 +1,49 @@
+package binance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func TestExchange_QueryFeeDiscount(t *testing.T) {
+	spotBNBBurn := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/sapi/v1/bnbBurn", r.URL.Path)
+		assert.Equal(t, "key", r.Header.Get("X-MBX-APIKEY"))
+		assert.NotEmpty(t, r.URL.Query().Get("timestamp"))
+		assert.NotEmpty(t, r.URL.Query().Get("signature"))
+
+		if spotBNBBurn {
+			_, _ = w.Write([]byte(`{"spotBNBBurn":true,"interestBNBBurn":false}`))
+		} else {
+			_, _ = w.Write([]byte(`{"spotBNBBurn":false,"interestBNBBurn":false}`))
+		}
+	}))
+	defer server.Close()
+
+	client := binance.NewClient("key", "secret")
+	client.BaseURL = server.URL
+	e := &Exchange{Client: client}
+
+	discount, err := e.QueryFeeDiscount(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, fixedpoint.NewFromFloat(0.25), discount)
+
+	spotBNBBurn = false
+	discount, err = e.QueryFeeDiscount(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, fixedpoint.Value(0), discount)
+
+	// futures account has no bnb discount
+	e.IsFutures = true
+	discount, err = e.QueryFeeDiscount(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, fixedpoint.Value(0), discount)
+}
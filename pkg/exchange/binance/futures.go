@@ -0,0 +1,253 @@
+package binance
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/adshao/go-binance/v2/common"
+
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+// binance responds -4059 "No need to change position side." when the position mode is already set
+const errCodeNoNeedToChangePositionSide = -4059
+
+func (e *Exchange) queryFuturesMarkets(ctx context.Context) (types.MarketMap, error) {
+	exchangeInfo, err := e.futuresClient.NewExchangeInfoService().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	markets := types.MarketMap{}
+	for _, symbol := range exchangeInfo.Symbols {
+		markets[symbol.Symbol] = toGlobalFuturesMarket(symbol)
+	}
+
+	return markets, nil
+}
+
+func (e *Exchange) queryFuturesTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
+	stats, err := e.futuresClient.NewListPriceChangeStatsService().Symbol(strings.ToUpper(symbol)).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return toGlobalFuturesTicker(stats[0])
+}
+
+func (e *Exchange) queryFuturesTickers(ctx context.Context, symbol ...string) (map[string]types.Ticker, error) {
+	changeStats, err := e.futuresClient.NewListPriceChangeStatsService().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]struct{})
+	for _, s := range symbol {
+		m[s] = struct{}{}
+	}
+
+	var tickers = make(map[string]types.Ticker)
+	for _, stats := range changeStats {
+		if _, ok := m[stats.Symbol]; len(symbol) != 0 && !ok {
+			continue
+		}
+
+		ticker, err := toGlobalFuturesTicker(stats)
+		if err != nil {
+			return nil, err
+		}
+
+		tickers[stats.Symbol] = *ticker
+	}
+
+	return tickers, nil
+}
+
+func (e *Exchange) queryFuturesAccount(ctx context.Context) (*types.Account, error) {
+	account, err := e.futuresClient.NewGetAccountService().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &types.Account{
+		AccountType: types.AccountTypeFutures,
+	}
+	a.UpdateBalances(toGlobalFuturesBalanceMap(account.Assets))
+	return a, nil
+}
+
+func (e *Exchange) cancelFuturesOrders(ctx context.Context, orders ...types.Order) (err2 error) {
+	for _, o := range orders {
+		var req = e.futuresClient.NewCancelOrderService()
+
+		// Mandatory
+		req.Symbol(o.Symbol)
+
+		if o.OrderID > 0 {
+			req.OrderID(int64(o.OrderID))
+		} else if len(o.ClientOrderID) > 0 {
+			req.OrigClientOrderID(o.ClientOrderID)
+		}
+
+		_, err := req.Do(ctx)
+		if err != nil {
+			log.WithError(err).Errorf("futures order cancel error")
+			err2 = err
+		}
+	}
+
+	return err2
+}
+
+func (e *Exchange) queryFuturesKLines(ctx context.Context, symbol string, interval types.Interval, limit int, options types.KLineQueryOptions) ([]types.KLine, error) {
+	req := e.futuresClient.NewKlinesService().
+		Symbol(symbol).
+		Interval(string(interval)).
+		Limit(limit)
+
+	if options.StartTime != nil {
+		req.StartTime(options.StartTime.UnixNano() / int64(time.Millisecond))
+	}
+
+	if options.EndTime != nil {
+		req.EndTime(options.EndTime.UnixNano() / int64(time.Millisecond))
+	}
+
+	resp, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var kLines []types.KLine
+	for _, k := range resp {
+		kLines = append(kLines, types.KLine{
+			Exchange:                 types.ExchangeBinance,
+			Symbol:                   symbol,
+			Interval:                 interval,
+			StartTime:                time.Unix(0, k.OpenTime*int64(time.Millisecond)),
+			EndTime:                  time.Unix(0, k.CloseTime*int64(time.Millisecond)),
+			Open:                     util.MustParseFloat(k.Open),
+			Close:                    util.MustParseFloat(k.Close),
+			High:                     util.MustParseFloat(k.High),
+			Low:                      util.MustParseFloat(k.Low),
+			Volume:                   util.MustParseFloat(k.Volume),
+			QuoteVolume:              util.MustParseFloat(k.QuoteAssetVolume),
+			TakerBuyBaseAssetVolume:  util.MustParseFloat(k.TakerBuyBaseAssetVolume),
+			TakerBuyQuoteAssetVolume: util.MustParseFloat(k.TakerBuyQuoteAssetVolume),
+			NumberOfTrades:           uint64(k.TradeNum),
+			Closed:                   true,
+		})
+	}
+
+	return kLines, nil
+}
+
+func (e *Exchange) queryFuturesTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
+	req := e.futuresClient.NewListAccountTradeService().
+		Symbol(symbol)
+
+	if options.Limit > 0 {
+		req.Limit(int(options.Limit))
+	} else {
+		req.Limit(1000)
+	}
+
+	if options.StartTime != nil {
+		req.StartTime(options.StartTime.UnixNano() / int64(time.Millisecond))
+	}
+
+	if options.EndTime != nil {
+		req.EndTime(options.EndTime.UnixNano() / int64(time.Millisecond))
+	}
+
+	// BINANCE uses inclusive last trade ID
+	if options.LastTradeID > 0 {
+		req.FromID(options.LastTradeID)
+	}
+
+	remoteTrades, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range remoteTrades {
+		localTrade, err := toGlobalFuturesTrade(*t)
+		if err != nil {
+			log.WithError(err).Errorf("can not convert binance futures trade: %+v", t)
+			continue
+		}
+
+		trades = append(trades, *localTrade)
+	}
+
+	return trades, nil
+}
+
+// SetLeverage sets the initial leverage of the symbol on the futures account
+func (e *Exchange) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	resp, err := e.futuresClient.NewChangeLeverageService().
+		Symbol(symbol).
+		Leverage(leverage).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("futures leverage changed: %+v", resp)
+	return nil
+}
+
+// SetPositionMode switches the futures account between the one-way mode and the hedge mode (dual side position)
+func (e *Exchange) SetPositionMode(ctx context.Context, mode types.PositionMode) error {
+	err := e.futuresClient.NewChangePositionModeService().
+		DualSide(mode == types.PositionModeHedge).
+		Do(ctx)
+
+	if apiErr, ok := err.(*common.APIError); ok && apiErr.Code == errCodeNoNeedToChangePositionSide {
+		return nil
+	}
+
+	return err
+}
+
+// QueryPositions queries the open positions of the futures account,
+// the positions of the hedge mode are keyed by the symbol and the position side, e.g., BTCUSDT:LONG
+func (e *Exchange) QueryPositions(ctx context.Context) (types.PositionMap, error) {
+	risks, err := e.futuresClient.NewGetPositionRiskService().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return toGlobalFuturesPositions(risks), nil
+}
+
+// QueryFundingFeeHistory queries the funding fees received or paid by the futures account
+func (e *Exchange) QueryFundingFeeHistory(ctx context.Context, symbol string, since, until time.Time) (fees []types.FundingFee, err error) {
+	req := e.futuresClient.NewGetIncomeHistoryService().
+		IncomeType(futuresIncomeTypeFundingFee).
+		StartTime(since.UnixNano() / int64(time.Millisecond)).
+		EndTime(until.UnixNano() / int64(time.Millisecond)).
+		Limit(1000)
+
+	if len(symbol) > 0 {
+		req.Symbol(symbol)
+	}
+
+	incomes, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, income := range incomes {
+		fee, err := toGlobalFundingFee(income)
+		if err != nil {
+			return fees, err
+		}
+
+		fees = append(fees, *fee)
+	}
+
+	return fees, nil
+}
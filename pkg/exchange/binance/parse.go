@@ -153,6 +153,58 @@ func (e *ExecutionReportEvent) Trade() (*types.Trade, error) {
 	}, nil
 }
 
+/*
+trade
+
+{
+  "e": "trade",     // Event type
+  "E": 123456789,   // Event time
+  "s": "BNBBTC",    // Symbol
+  "t": 12345,       // Trade ID
+  "p": "0.001",     // Price
+  "q": "100",       // Quantity
+  "b": 88,          // Buyer order ID
+  "a": 50,          // Seller order ID
+  "T": 123456785,   // Trade time
+  "m": true,        // Is the buyer the market maker?
+  "M": true         // Ignore
+}
+*/
+type MarketTradeEvent struct {
+	EventBase
+
+	Symbol        string `json:"s"`
+	TradeID       int64  `json:"t"`
+	Price         string `json:"p"`
+	Quantity      string `json:"q"`
+	BuyerOrderID  int64  `json:"b"`
+	SellerOrderID int64  `json:"a"`
+	TradeTime     int64  `json:"T"`
+	IsBuyerMaker  bool   `json:"m"`
+}
+
+// Trade converts the public trade event to the market trade, the side is the taker side
+func (e *MarketTradeEvent) Trade() types.Trade {
+	side := types.SideTypeBuy
+	if e.IsBuyerMaker {
+		side = types.SideTypeSell
+	}
+
+	price := util.MustParseFloat(e.Price)
+	quantity := util.MustParseFloat(e.Quantity)
+	return types.Trade{
+		ID:            e.TradeID,
+		Exchange:      types.ExchangeBinance,
+		Symbol:        e.Symbol,
+		Side:          side,
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: price * quantity,
+		IsBuyer:       side == types.SideTypeBuy,
+		Time:          types.Time(time.Unix(0, e.TradeTime*int64(time.Millisecond))),
+	}
+}
+
 /*
 balanceUpdate
 
@@ -271,6 +323,11 @@ func ParseEvent(message string) (interface{}, error) {
 		err := json.Unmarshal([]byte(message), &event)
 		return &event, err
 
+	case "trade":
+		var event MarketTradeEvent
+		err := json.Unmarshal([]byte(message), &event)
+		return &event, err
+
 	case "outboundAccountPosition":
 		var event OutboundAccountPositionEvent
 		err := json.Unmarshal([]byte(message), &event)
@@ -310,6 +367,11 @@ func ParseEvent(message string) (interface{}, error) {
 		err := json.Unmarshal([]byte(message), &event)
 		return &event, err
 
+	case "ACCOUNT_UPDATE":
+		var event AccountUpdateEvent
+		err := json.Unmarshal([]byte(message), &event)
+		return &event, err
+
 	default:
 		id := val.GetInt("id")
 		if id > 0 {
@@ -332,6 +394,9 @@ type DepthEvent struct {
 	FirstUpdateID int64  `json:"U"`
 	FinalUpdateID int64  `json:"u"`
 
+	// PreviousUpdateID is the final update id of the previous event, it's only sent by the futures depth stream
+	PreviousUpdateID int64 `json:"pu"`
+
 	Bids []DepthEntry
 	Asks []DepthEntry
 }
@@ -428,9 +493,10 @@ func parseDepthEvent(val *fastjson.Value) (*DepthEvent, error) {
 			Event: string(val.GetStringBytes("e")),
 			Time:  val.GetInt64("E"),
 		},
-		Symbol:        string(val.GetStringBytes("s")),
-		FirstUpdateID: val.GetInt64("U"),
-		FinalUpdateID: val.GetInt64("u"),
+		Symbol:           string(val.GetStringBytes("s")),
+		FirstUpdateID:    val.GetInt64("U"),
+		FinalUpdateID:    val.GetInt64("u"),
+		PreviousUpdateID: val.GetInt64("pu"),
 	}
 
 	for _, ev := range val.GetArray("b") {
@@ -714,6 +780,177 @@ func (e *OrderTradeUpdateEvent) OrderFutures() (*types.Order, error) {
 	}, nil
 }
 
+func (e *OrderTradeUpdateEvent) TradeFutures() (*types.Trade, error) {
+	if e.OrderTrade.CurrentExecutionType != "TRADE" {
+		return nil, errors.New("order trade update is not a trade")
+	}
+
+	tt := time.Unix(0, e.OrderTrade.OrderTradeTime*int64(time.Millisecond))
+	side := toGlobalFuturesSideType(futures.SideType(e.OrderTrade.Side))
+	price := util.MustParseFloat(e.OrderTrade.LastFilledPrice)
+	quantity := util.MustParseFloat(e.OrderTrade.OrderLastFilledQuantity)
+
+	var fee = 0.0
+	if len(e.OrderTrade.CommissionAmount) > 0 {
+		fee = util.MustParseFloat(e.OrderTrade.CommissionAmount)
+	}
+
+	return &types.Trade{
+		ID:            e.OrderTrade.TradeId,
+		Exchange:      types.ExchangeBinance,
+		Symbol:        e.OrderTrade.Symbol,
+		OrderID:       uint64(e.OrderTrade.OrderId),
+		Side:          side,
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: price * quantity,
+		IsBuyer:       side == types.SideTypeBuy,
+		IsMaker:       e.OrderTrade.IsMaker,
+		Time:          types.Time(tt),
+		Fee:           fee,
+		FeeCurrency:   e.OrderTrade.CommissionAsset,
+		IsFutures:     true,
+	}, nil
+}
+
+/*
+
+ACCOUNT_UPDATE
+
+{
+  "e": "ACCOUNT_UPDATE",                // Event Type
+  "E": 1564745798939,                   // Event Time
+  "T": 1564745798938,                   // Transaction
+  "a": {                                // Update Data
+    "m": "ORDER",                       // Event reason type
+    "B": [                              // Balances
+      {
+        "a": "USDT",                    // Asset
+        "wb": "122624.12345678",        // Wallet Balance
+        "cw": "100.12345678",           // Cross Wallet Balance
+        "bc": "50.12345678"             // Balance Change except PnL and Commission
+      }
+    ],
+    "P": [                              // Positions
+      {
+        "s": "BTCUSDT",                 // Symbol
+        "pa": "0",                      // Position Amount
+        "ep": "0.00000",                // Entry Price
+        "cr": "200",                    // (Pre-fee) Accumulated Realized
+        "up": "0",                      // Unrealized PnL
+        "mt": "isolated",               // Margin Type
+        "iw": "0.00000000",             // Isolated Wallet (if isolated position)
+        "ps": "BOTH"                    // Position Side
+      }
+    ]
+  }
+}
+
+when the funding fee occurs in a crossed position, the event is pushed with the balances only (reason = FUNDING_FEE),
+when it occurs in an isolated position, the event is pushed with the balances and the related position.
+*/
+type FuturesBalance struct {
+	Asset              string           `json:"a"`
+	WalletBalance      fixedpoint.Value `json:"wb"`
+	CrossWalletBalance fixedpoint.Value `json:"cw"`
+	BalanceChange      fixedpoint.Value `json:"bc"`
+}
+
+type FuturesPosition struct {
+	Symbol              string           `json:"s"`
+	PositionAmount      fixedpoint.Value `json:"pa"`
+	EntryPrice          fixedpoint.Value `json:"ep"`
+	AccumulatedRealized fixedpoint.Value `json:"cr"`
+	UnrealizedPnL       fixedpoint.Value `json:"up"`
+	MarginType          string           `json:"mt"`
+	IsolatedWallet      fixedpoint.Value `json:"iw"`
+	PositionSide        string           `json:"ps"`
+}
+
+type AccountUpdate struct {
+	EventReasonType string            `json:"m"`
+	Balances        []FuturesBalance  `json:"B,omitempty"`
+	Positions       []FuturesPosition `json:"P,omitempty"`
+}
+
+type AccountUpdateEvent struct {
+	EventBase
+	Transaction   int64         `json:"T"`
+	AccountUpdate AccountUpdate `json:"a"`
+}
+
+func (e *AccountUpdateEvent) IsFundingFee() bool {
+	return e.AccountUpdate.EventReasonType == "FUNDING_FEE"
+}
+
+func (e *AccountUpdateEvent) BalanceMap() types.BalanceMap {
+	balances := types.BalanceMap{}
+	for _, b := range e.AccountUpdate.Balances {
+		balances[b.Asset] = types.Balance{
+			Currency:  b.Asset,
+			Available: b.WalletBalance,
+		}
+	}
+	return balances
+}
+
+func (e *AccountUpdateEvent) PositionMap() types.PositionMap {
+	positions := types.PositionMap{}
+	for _, p := range e.AccountUpdate.Positions {
+		positions[futuresPositionKey(p.Symbol, p.PositionSide)] = types.Position{
+			Symbol:           p.Symbol,
+			Base:             p.PositionAmount,
+			AverageCost:      p.EntryPrice,
+			EntryPrice:       p.EntryPrice,
+			PositionAmt:      p.PositionAmount,
+			UnrealizedProfit: p.UnrealizedPnL,
+			Isolated:         p.MarginType == "isolated",
+			IsolatedWallet:   p.IsolatedWallet,
+			PositionSide:     p.PositionSide,
+			UpdateTime:       e.Transaction,
+		}
+	}
+	return positions
+}
+
+// FundingFees returns the funding fees of the isolated position,
+// the crossed funding fee event does not contain the symbol, so the symbol of the fee will be empty.
+func (e *AccountUpdateEvent) FundingFees() (fees []types.FundingFee) {
+	if !e.IsFundingFee() {
+		return nil
+	}
+
+	var symbol string
+	if len(e.AccountUpdate.Positions) == 1 {
+		symbol = e.AccountUpdate.Positions[0].Symbol
+	}
+
+	for _, b := range e.AccountUpdate.Balances {
+		if b.BalanceChange == 0 {
+			continue
+		}
+
+		fees = append(fees, types.FundingFee{
+			Symbol: symbol,
+			Asset:  b.Asset,
+			Amount: b.BalanceChange,
+			Time:   time.Unix(0, e.Transaction*int64(time.Millisecond)),
+		})
+	}
+
+	return fees
+}
+
+// futuresPositionKey returns the symbol as the key for the one-way mode position,
+// the positions in the hedge mode are keyed by the symbol and the position side, e.g., BTCUSDT:LONG
+func futuresPositionKey(symbol, positionSide string) string {
+	if positionSide == "" || positionSide == "BOTH" {
+		return symbol
+	}
+
+	return symbol + ":" + positionSide
+}
+
 type EventBase struct {
 	Event string `json:"e"` // event
 	Time  int64  `json:"E"`
@@ -3,8 +3,12 @@ package binance
 import (
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
 )
 
 var jsCommentTrimmer = regexp.MustCompile("(?m)//.*$")
@@ -212,3 +216,136 @@ func TestParseOrderUpdate(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, orderUpdate)
 }
+
+func TestParseAccountUpdateEvent(t *testing.T) {
+	payload := `{
+  "e": "ACCOUNT_UPDATE",
+  "E": 1564745798939,
+  "T": 1564745798938,
+  "a": {
+    "m": "FUNDING_FEE",
+    "B": [
+      {
+        "a": "USDT",
+        "wb": "122624.12345678",
+        "cw": "100.12345678",
+        "bc": "-1.25"
+      }
+    ],
+    "P": [
+      {
+        "s": "BTCUSDT",
+        "pa": "0.5",
+        "ep": "40000.00000",
+        "cr": "200",
+        "up": "12.5",
+        "mt": "isolated",
+        "iw": "2000.00000000",
+        "ps": "BOTH"
+      }
+    ]
+  }
+}`
+
+	event, err := ParseEvent(payload)
+	assert.NoError(t, err)
+
+	accountUpdate, ok := event.(*AccountUpdateEvent)
+	assert.True(t, ok)
+	assert.True(t, accountUpdate.IsFundingFee())
+
+	balances := accountUpdate.BalanceMap()
+	assert.Equal(t, fixedpoint.NewFromFloat(122624.12345678), balances["USDT"].Available)
+
+	positions := accountUpdate.PositionMap()
+	assert.Len(t, positions, 1)
+	assert.Equal(t, fixedpoint.NewFromFloat(0.5), positions["BTCUSDT"].Base)
+	assert.Equal(t, fixedpoint.NewFromFloat(40000.0), positions["BTCUSDT"].AverageCost)
+	assert.True(t, positions["BTCUSDT"].Isolated)
+
+	fees := accountUpdate.FundingFees()
+	assert.Len(t, fees, 1)
+	assert.Equal(t, "BTCUSDT", fees[0].Symbol)
+	assert.Equal(t, "USDT", fees[0].Asset)
+	assert.Equal(t, fixedpoint.NewFromFloat(-1.25), fees[0].Amount)
+
+	// the crossed funding fee event does not contain the position
+	event, err = ParseEvent(`{"e":"ACCOUNT_UPDATE","E":1564745798939,"T":1564745798938,"a":{"m":"FUNDING_FEE","B":[{"a":"USDT","wb":"100","cw":"100","bc":"0.5"}],"P":[]}}`)
+	assert.NoError(t, err)
+
+	fees = event.(*AccountUpdateEvent).FundingFees()
+	assert.Len(t, fees, 1)
+	assert.Equal(t, "", fees[0].Symbol)
+
+	// the order events are not funding fees
+	event, err = ParseEvent(`{"e":"ACCOUNT_UPDATE","E":1564745798939,"T":1564745798938,"a":{"m":"ORDER","B":[{"a":"USDT","wb":"100","cw":"100","bc":"0"}],"P":[{"s":"BTCUSDT","pa":"-0.1","ep":"40000","cr":"0","up":"0","mt":"cross","iw":"0","ps":"SHORT"}]}}`)
+	assert.NoError(t, err)
+
+	accountUpdate = event.(*AccountUpdateEvent)
+	assert.False(t, accountUpdate.IsFundingFee())
+	assert.Len(t, accountUpdate.FundingFees(), 0)
+	assert.Contains(t, accountUpdate.PositionMap(), "BTCUSDT:SHORT")
+}
+
+func TestParseOrderTradeUpdateEvent_Trade(t *testing.T) {
+	payload := `{"e":"ORDER_TRADE_UPDATE","E":1568879465651,"T":1568879465650,"o":{"s":"BTCUSDT","c":"TEST","S":"SELL","o":"LIMIT","f":"GTC","q":"0.002","p":"40000","ap":"40000","sp":"0","x":"TRADE","X":"FILLED","i":8886774,"l":"0.002","z":"0.002","L":"40000","N":"USDT","n":"0.032","T":1568879465651,"t":123,"b":"0","a":"0","m":true,"R":false,"wt":"CONTRACT_PRICE","ot":"LIMIT","ps":"BOTH","cp":false,"rp":"0"}}`
+
+	event, err := ParseEvent(payload)
+	assert.NoError(t, err)
+
+	orderTradeUpdate, ok := event.(*OrderTradeUpdateEvent)
+	assert.True(t, ok)
+
+	trade, err := orderTradeUpdate.TradeFutures()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(123), trade.ID)
+	assert.Equal(t, uint64(8886774), trade.OrderID)
+	assert.Equal(t, types.SideTypeSell, trade.Side)
+	assert.False(t, trade.IsBuyer)
+	assert.True(t, trade.IsMaker)
+	assert.True(t, trade.IsFutures)
+	assert.Equal(t, 40000.0, trade.Price)
+	assert.Equal(t, 0.002, trade.Quantity)
+	assert.InDelta(t, 80.0, trade.QuoteQuantity, 1e-8)
+	assert.Equal(t, 0.032, trade.Fee)
+	assert.Equal(t, "USDT", trade.FeeCurrency)
+}
+
+func TestParseMarketTradeEvent(t *testing.T) {
+	payload := `{"e":"trade","E":123456789,"s":"BNBBTC","t":12345,"p":"0.001","q":"100","b":88,"a":50,"T":123456785,"m":true,"M":true}`
+
+	event, err := ParseEvent(payload)
+	assert.NoError(t, err)
+
+	tradeEvent, ok := event.(*MarketTradeEvent)
+	if !assert.True(t, ok) {
+		return
+	}
+
+	trade := tradeEvent.Trade()
+	assert.Equal(t, "BNBBTC", trade.Symbol)
+	assert.Equal(t, int64(12345), trade.ID)
+	assert.Equal(t, 0.001, trade.Price)
+	assert.Equal(t, 100.0, trade.Quantity)
+	assert.Equal(t, types.SideTypeSell, trade.Side)
+	assert.Equal(t, time.Unix(0, 123456785*int64(time.Millisecond)), time.Time(trade.Time))
+}
+
+func TestParseFuturesDepthEvent(t *testing.T) {
+	payload := `{"e":"depthUpdate","E":123456789,"T":123456788,"s":"BTCUSDT","U":157,"u":160,"pu":149,"b":[["0.0024","10"]],"a":[["0.0026","100"]]}`
+
+	event, err := ParseEvent(payload)
+	assert.NoError(t, err)
+
+	depthEvent, ok := event.(*DepthEvent)
+	if !assert.True(t, ok) {
+		return
+	}
+
+	assert.Equal(t, "BTCUSDT", depthEvent.Symbol)
+	assert.Equal(t, int64(157), depthEvent.FirstUpdateID)
+	assert.Equal(t, int64(160), depthEvent.FinalUpdateID)
+	assert.Equal(t, int64(149), depthEvent.PreviousUpdateID)
+	assert.Len(t, depthEvent.Bids, 1)
+	assert.Len(t, depthEvent.Asks, 1)
+}
@@ -0,0 +1,56 @@
+package binance
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// signedGet sends the signed GET request to the endpoints that are not supported by the binance client
+func (e *Exchange) signedGet(ctx context.Context, path string, params url.Values) ([]byte, error) {
+	if params == nil {
+		params = url.Values{}
+	}
+
+	params.Set("timestamp", strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10))
+
+	mac := hmac.New(sha256.New, []byte(e.Client.SecretKey))
+	mac.Write([]byte(params.Encode()))
+	params.Set("signature", hex.EncodeToString(mac.Sum(nil)))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.Client.BaseURL+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("X-MBX-APIKEY", e.Client.APIKey)
+
+	client := e.Client.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("binance %s request error, status %d: %s", path, resp.StatusCode, string(body))
+	}
+
+	return body, nil
+}
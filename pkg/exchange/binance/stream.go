@@ -74,10 +74,14 @@ type Stream struct {
 
 	publicOnly bool
 
+	// testnet uses the testnet websocket endpoints
+	testnet bool
+
 	// custom callbacks
 	depthEventCallbacks       []func(e *DepthEvent)
 	kLineEventCallbacks       []func(e *KLineEvent)
 	kLineClosedEventCallbacks []func(e *KLineEvent)
+	marketTradeEventCallbacks []func(e *MarketTradeEvent)
 
 	markPriceUpdateEventCallbacks []func(e *MarkPriceUpdateEvent)
 
@@ -90,8 +94,9 @@ type Stream struct {
 	executionReportEventCallbacks         []func(event *ExecutionReportEvent)
 
 	orderTradeUpdateEventCallbacks []func(e *OrderTradeUpdateEvent)
+	accountUpdateEventCallbacks    []func(e *AccountUpdateEvent)
 
-	depthFrames map[string]*DepthFrame
+	depthBuffers map[string]*types.DepthBuffer
 }
 
 func NewStream(client *binance.Client, futuresClient *futures.Client) *Stream {
@@ -101,7 +106,7 @@ func NewStream(client *binance.Client, futuresClient *futures.Client) *Stream {
 		},
 		Client:        client,
 		futuresClient: futuresClient,
-		depthFrames:   make(map[string]*DepthFrame),
+		depthBuffers:  make(map[string]*types.DepthBuffer),
 	}
 
 	stream.OnDepthEvent(func(e *DepthEvent) {
@@ -109,54 +114,47 @@ func NewStream(client *binance.Client, futuresClient *futures.Client) *Stream {
 			log.Infof("received %s depth event updateID %d ~ %d (len %d)", e.Symbol, e.FirstUpdateID, e.FinalUpdateID, e.FinalUpdateID-e.FirstUpdateID)
 		}
 
-		f, ok := stream.depthFrames[e.Symbol]
+		f, ok := stream.depthBuffers[e.Symbol]
 		if !ok {
-			f = &DepthFrame{
-				client:  client,
-				context: context.Background(),
-				Symbol:  e.Symbol,
-				resetC:  make(chan struct{}, 1),
+			fetcher := newDepthSnapshotFetcher(client, e.Symbol)
+			if stream.IsFutures {
+				fetcher = newFuturesDepthSnapshotFetcher(futuresClient, e.Symbol)
 			}
 
-			stream.depthFrames[e.Symbol] = f
-
-			f.OnReady(func(snapshotDepth DepthEvent, bufEvents []DepthEvent) {
-				log.Infof("depth snapshot ready: %s", snapshotDepth.String())
-
-				snapshot, err := snapshotDepth.OrderBook()
-				if err != nil {
-					log.WithError(err).Error("book snapshot convert error")
-					return
-				}
+			f = types.NewDepthBuffer(e.Symbol, fetcher)
+			f.SnapshotDelay = 3 * time.Second
+			stream.depthBuffers[e.Symbol] = f
 
+			f.OnReady(func(snapshot types.SliceOrderBook, updates []types.DepthUpdate) {
 				if valid, err := snapshot.IsValid(); !valid {
-					log.Errorf("depth snapshot is invalid, event: %+v, error: %v", snapshotDepth, err)
+					log.Errorf("%s depth snapshot is invalid, error: %v", snapshot.Symbol, err)
 				}
 
 				stream.EmitBookSnapshot(snapshot)
 
-				for _, e := range bufEvents {
-					bookUpdate, err := e.OrderBook()
-					if err != nil {
-						log.WithError(err).Error("book convert error")
-						return
-					}
-
-					stream.EmitBookUpdate(bookUpdate)
+				for _, u := range updates {
+					stream.EmitBookUpdate(u.Book)
 				}
 			})
 
-			f.OnPush(func(e DepthEvent) {
-				book, err := e.OrderBook()
-				if err != nil {
-					log.WithError(err).Error("book convert error")
-					return
-				}
-
-				stream.EmitBookUpdate(book)
+			f.OnPush(func(update types.DepthUpdate) {
+				stream.EmitBookUpdate(update.Book)
 			})
-		} else {
-			f.PushEvent(*e)
+		}
+
+		book, err := e.OrderBook()
+		if err != nil {
+			log.WithError(err).Error("book convert error")
+			return
+		}
+
+		if err := f.AddUpdate(types.DepthUpdate{
+			FirstUpdateID:    e.FirstUpdateID,
+			FinalUpdateID:    e.FinalUpdateID,
+			PreviousUpdateID: e.PreviousUpdateID,
+			Book:             book,
+		}); err != nil {
+			log.WithError(err).Warn("depth update error")
 		}
 	})
 
@@ -172,6 +170,10 @@ func NewStream(client *binance.Client, futuresClient *futures.Client) *Stream {
 		stream.EmitBalanceSnapshot(snapshot)
 	})
 
+	stream.OnMarketTradeEvent(func(e *MarketTradeEvent) {
+		stream.EmitMarketTrade(e.Trade())
+	})
+
 	stream.OnKLineEvent(func(e *KLineEvent) {
 		kline := e.KLine.KLine()
 		if e.KLine.Closed {
@@ -239,35 +241,58 @@ func NewStream(client *binance.Client, futuresClient *futures.Client) *Stream {
 			stream.EmitOrderUpdate(*order)
 
 		case "TRADE":
-			// TODO
-
-			// trade, err := e.Trade()
-			// if err != nil {
-			// 	log.WithError(err).Error("trade convert error")
-			// 	return
-			// }
+			trade, err := e.TradeFutures()
+			if err != nil {
+				log.WithError(err).Error("trade convert error")
+				return
+			}
 
-			// stream.EmitTradeUpdate(*trade)
+			stream.EmitTradeUpdate(*trade)
 
-			// order, err := e.OrderFutures()
-			// if err != nil {
-			// 	log.WithError(err).Error("order convert error")
-			// 	return
-			// }
+			order, err := e.OrderFutures()
+			if err != nil {
+				log.WithError(err).Error("order convert error")
+				return
+			}
 
 			// Update Order with FILLED event
-			// if order.Status == types.OrderStatusFilled {
-			// 	stream.EmitOrderUpdate(*order)
-			// }
+			if order.Status == types.OrderStatusFilled {
+				stream.EmitOrderUpdate(*order)
+			}
+
 		case "CALCULATED - Liquidation Execution":
 			log.Infof("CALCULATED - Liquidation Execution not support yet.")
 		}
 	})
 
+	stream.OnAccountUpdateEvent(func(e *AccountUpdateEvent) {
+		stream.EmitBalanceUpdate(e.BalanceMap())
+
+		if positions := e.PositionMap(); len(positions) > 0 {
+			stream.EmitPositionUpdate(positions)
+		}
+
+		if !e.IsFundingFee() {
+			return
+		}
+
+		fees := e.FundingFees()
+		if len(fees) > 0 && fees[0].Symbol == "" {
+			// the crossed funding fee event does not tell which symbol the fee belongs to,
+			// query the income history to resolve the funding fees by symbol
+			go stream.emitFundingFeeHistory(time.Unix(0, e.Transaction*int64(time.Millisecond)))
+			return
+		}
+
+		for _, fee := range fees {
+			stream.EmitFundingFee(fee)
+		}
+	})
+
 	stream.OnDisconnect(func() {
 		log.Infof("resetting depth snapshots...")
-		for _, f := range stream.depthFrames {
-			f.emitReset()
+		for _, f := range stream.depthBuffers {
+			f.Reset()
 		}
 	})
 
@@ -296,27 +321,59 @@ func NewStream(client *binance.Client, futuresClient *futures.Client) *Stream {
 	return stream
 }
 
+func (s *Stream) emitFundingFeeHistory(fundingTime time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	incomes, err := s.futuresClient.NewGetIncomeHistoryService().
+		IncomeType(futuresIncomeTypeFundingFee).
+		StartTime(fundingTime.Add(-time.Minute).UnixNano() / int64(time.Millisecond)).
+		EndTime(fundingTime.Add(time.Minute).UnixNano() / int64(time.Millisecond)).
+		Do(ctx)
+	if err != nil {
+		log.WithError(err).Error("funding fee history query error")
+		return
+	}
+
+	for _, income := range incomes {
+		fee, err := toGlobalFundingFee(income)
+		if err != nil {
+			log.WithError(err).Errorf("can not convert funding fee: %+v", income)
+			continue
+		}
+
+		s.EmitFundingFee(*fee)
+	}
+}
+
 func (s *Stream) SetPublicOnly() {
 	s.publicOnly = true
 }
 
-func (s *Stream) dial(listenKey string) (*websocket.Conn, error) {
-	var url string
-	if s.publicOnly {
-		if s.IsFutures {
-			url = "wss://fstream.binance.com/ws/"
-		} else {
-			url = "wss://stream.binance.com:9443/ws"
-		}
-	} else {
-		if s.IsFutures {
-			url = "wss://fstream.binance.com/ws/" + listenKey
-		} else {
-			url = "wss://stream.binance.com:9443/ws/" + listenKey
+// baseURL returns the websocket endpoint by the futures and the testnet settings
+func (s *Stream) baseURL() string {
+	if s.IsFutures {
+		if s.testnet {
+			return "wss://stream.binancefuture.com/ws"
 		}
+		return "wss://fstream.binance.com/ws"
 	}
 
-	conn, _, err := defaultDialer.Dial(url, nil)
+	if s.testnet {
+		return "wss://testnet.binance.vision/ws"
+	}
+	return "wss://stream.binance.com:9443/ws"
+}
+
+func (s *Stream) dial(listenKey string) (*websocket.Conn, error) {
+	url := s.baseURL()
+	if !s.publicOnly {
+		url += "/" + listenKey
+	}
+
+	dialer := *defaultDialer
+	dialer.EnableCompression = s.EnableCompression
+	conn, _, err := dialer.Dial(url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -581,6 +638,12 @@ func (s *Stream) read(ctx context.Context) {
 				}
 			}
 
+			mt, message, err = types.DecompressMessage(mt, message)
+			if err != nil {
+				log.WithError(err).Error("websocket message decompress error")
+				continue
+			}
+
 			// skip non-text messages
 			if mt != websocket.TextMessage {
 				continue
@@ -611,6 +674,9 @@ func (s *Stream) read(ctx context.Context) {
 			case *DepthEvent:
 				s.EmitDepthEvent(e)
 
+			case *MarketTradeEvent:
+				s.EmitMarketTradeEvent(e)
+
 			case *ExecutionReportEvent:
 				s.EmitExecutionReportEvent(e)
 
@@ -622,6 +688,9 @@ func (s *Stream) read(ctx context.Context) {
 
 			case *OrderTradeUpdateEvent:
 				s.EmitOrderTradeUpdateEvent(e)
+
+			case *AccountUpdateEvent:
+				s.EmitAccountUpdateEvent(e)
 			}
 		}
 	}
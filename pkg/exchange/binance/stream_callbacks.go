@@ -34,6 +34,16 @@ func (s *Stream) EmitKLineClosedEvent(e *KLineEvent) {
 	}
 }
 
+func (s *Stream) OnMarketTradeEvent(cb func(e *MarketTradeEvent)) {
+	s.marketTradeEventCallbacks = append(s.marketTradeEventCallbacks, cb)
+}
+
+func (s *Stream) EmitMarketTradeEvent(e *MarketTradeEvent) {
+	for _, cb := range s.marketTradeEventCallbacks {
+		cb(e)
+	}
+}
+
 func (s *Stream) OnMarkPriceUpdateEvent(cb func(e *MarkPriceUpdateEvent)) {
 	s.markPriceUpdateEventCallbacks = append(s.markPriceUpdateEventCallbacks, cb)
 }
@@ -114,6 +124,16 @@ func (s *Stream) EmitOrderTradeUpdateEvent(e *OrderTradeUpdateEvent) {
 	}
 }
 
+func (s *Stream) OnAccountUpdateEvent(cb func(e *AccountUpdateEvent)) {
+	s.accountUpdateEventCallbacks = append(s.accountUpdateEventCallbacks, cb)
+}
+
+func (s *Stream) EmitAccountUpdateEvent(e *AccountUpdateEvent) {
+	for _, cb := range s.accountUpdateEventCallbacks {
+		cb(e)
+	}
+}
+
 type StreamEventHub interface {
 	OnDepthEvent(cb func(e *DepthEvent))
 
@@ -121,6 +141,8 @@ type StreamEventHub interface {
 
 	OnKLineClosedEvent(cb func(e *KLineEvent))
 
+	OnMarketTradeEvent(cb func(e *MarketTradeEvent))
+
 	OnMarkPriceUpdateEvent(cb func(e *MarkPriceUpdateEvent))
 
 	OnContinuousKLineEvent(cb func(e *ContinuousKLineEvent))
@@ -136,4 +158,6 @@ type StreamEventHub interface {
 	OnExecutionReportEvent(cb func(event *ExecutionReportEvent))
 
 	OnOrderTradeUpdateEvent(cb func(e *OrderTradeUpdateEvent))
+
+	OnAccountUpdateEvent(cb func(e *AccountUpdateEvent))
 }
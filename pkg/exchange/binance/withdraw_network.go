@@ -0,0 +1,74 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// CoinNetwork is the network setting of the coin from GET /sapi/v1/capital/config/getall
+type CoinNetwork struct {
+	Coin           string           `json:"coin"`
+	Network        string           `json:"network"`
+	IsDefault      bool             `json:"isDefault"`
+	DepositEnable  bool             `json:"depositEnable"`
+	WithdrawEnable bool             `json:"withdrawEnable"`
+	WithdrawFee    fixedpoint.Value `json:"withdrawFee"`
+	WithdrawMin    fixedpoint.Value `json:"withdrawMin"`
+	WithdrawMax    fixedpoint.Value `json:"withdrawMax"`
+}
+
+// CoinInfo is the coin setting from GET /sapi/v1/capital/config/getall
+type CoinInfo struct {
+	Coin        string        `json:"coin"`
+	NetworkList []CoinNetwork `json:"networkList"`
+}
+
+// QueryCoinInfos queries the deposit and withdrawal settings of all the coins
+func (e *Exchange) QueryCoinInfos(ctx context.Context) ([]CoinInfo, error) {
+	body, err := e.signedGet(ctx, "/sapi/v1/capital/config/getall", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var coins []CoinInfo
+	if err := json.Unmarshal(body, &coins); err != nil {
+		return nil, err
+	}
+
+	return coins, nil
+}
+
+// QueryWithdrawalNetworks returns the withdrawal fee and the limits of each network of the asset
+func (e *Exchange) QueryWithdrawalNetworks(ctx context.Context, asset string) ([]types.WithdrawalNetwork, error) {
+	coins, err := e.QueryCoinInfos(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	asset = strings.ToUpper(asset)
+
+	var networks []types.WithdrawalNetwork
+	for _, coin := range coins {
+		if coin.Coin != asset {
+			continue
+		}
+
+		for _, n := range coin.NetworkList {
+			networks = append(networks, types.WithdrawalNetwork{
+				Asset:     coin.Coin,
+				Network:   n.Network,
+				Fee:       n.WithdrawFee,
+				MinAmount: n.WithdrawMin,
+				MaxAmount: n.WithdrawMax,
+				Enabled:   n.WithdrawEnable,
+				IsDefault: n.IsDefault,
+			})
+		}
+	}
+
+	return networks, nil
+}
@@ -0,0 +1,43 @@
+package binance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func TestExchange_QueryWithdrawalNetworks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/sapi/v1/capital/config/getall", r.URL.Path)
+		assert.NotEmpty(t, r.URL.Query().Get("signature"))
+
+		_, _ = w.Write([]byte(`[
+			{"coin":"BTC","networkList":[{"coin":"BTC","network":"BTC","isDefault":true,"withdrawEnable":true,"withdrawFee":"0.0005","withdrawMin":"0.001","withdrawMax":"9000"}]},
+			{"coin":"USDT","networkList":[
+				{"coin":"USDT","network":"ETH","isDefault":true,"withdrawEnable":true,"withdrawFee":"10","withdrawMin":"20","withdrawMax":"10000000"},
+				{"coin":"USDT","network":"TRX","isDefault":false,"withdrawEnable":false,"withdrawFee":"1","withdrawMin":"10","withdrawMax":"10000000"}
+			]}
+		]`))
+	}))
+	defer server.Close()
+
+	client := binance.NewClient("key", "secret")
+	client.BaseURL = server.URL
+	e := &Exchange{Client: client}
+
+	networks, err := e.QueryWithdrawalNetworks(context.Background(), "usdt")
+	assert.NoError(t, err)
+	if assert.Len(t, networks, 2) {
+		assert.Equal(t, "ETH", networks[0].Network)
+		assert.Equal(t, fixedpoint.NewFromFloat(10.0), networks[0].Fee)
+		assert.Equal(t, fixedpoint.NewFromFloat(20.0), networks[0].MinAmount)
+		assert.True(t, networks[0].IsDefault)
+		assert.False(t, networks[1].Enabled)
+	}
+}
@@ -0,0 +1,198 @@
+package bfxapi
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const defaultHTTPTimeout = time.Second * 15
+const RestBaseURL = "https://api.bitfinex.com/"
+const PublicRestBaseURL = "https://api-pub.bitfinex.com/"
+const WebSocketURL = "wss://api.bitfinex.com/ws/2"
+const PublicWebSocketURL = "wss://api-pub.bitfinex.com/ws/2"
+
+// WalletType is the wallet type of bitfinex, bitfinex keeps the spot balances, the margin collateral
+// and the lending funds in different wallets.
+type WalletType string
+
+const (
+	WalletTypeExchange WalletType = "exchange"
+	WalletTypeMargin   WalletType = "margin"
+	WalletTypeFunding  WalletType = "funding"
+)
+
+type OrderType string
+
+const (
+	OrderTypeLimit             OrderType = "LIMIT"
+	OrderTypeMarket            OrderType = "MARKET"
+	OrderTypeStop              OrderType = "STOP"
+	OrderTypeStopLimit         OrderType = "STOP LIMIT"
+	OrderTypeFOK               OrderType = "FOK"
+	OrderTypeIOC               OrderType = "IOC"
+	OrderTypeExchangeLimit     OrderType = "EXCHANGE LIMIT"
+	OrderTypeExchangeMarket    OrderType = "EXCHANGE MARKET"
+	OrderTypeExchangeStop      OrderType = "EXCHANGE STOP"
+	OrderTypeExchangeStopLimit OrderType = "EXCHANGE STOP LIMIT"
+	OrderTypeExchangeFOK       OrderType = "EXCHANGE FOK"
+	OrderTypeExchangeIOC       OrderType = "EXCHANGE IOC"
+)
+
+// OrderFlagPostOnly is the order flag for the post-only orders
+const OrderFlagPostOnly = 4096
+
+type RestClient struct {
+	BaseURL       *url.URL
+	PublicBaseURL *url.URL
+
+	client *http.Client
+
+	Key, Secret string
+
+	// nonce must be strictly increasing for every authenticated request
+	nonce     int64
+	nonceLock sync.Mutex
+}
+
+func NewClient() *RestClient {
+	u, err := url.Parse(RestBaseURL)
+	if err != nil {
+		panic(err)
+	}
+
+	pu, err := url.Parse(PublicRestBaseURL)
+	if err != nil {
+		panic(err)
+	}
+
+	return &RestClient{
+		BaseURL:       u,
+		PublicBaseURL: pu,
+		client: &http.Client{
+			Timeout: defaultHTTPTimeout,
+		},
+	}
+}
+
+func (c *RestClient) Auth(key, secret string) {
+	c.Key = key
+	c.Secret = secret
+}
+
+// Nonce returns a new strictly increasing nonce in microseconds
+func (c *RestClient) Nonce() string {
+	c.nonceLock.Lock()
+	defer c.nonceLock.Unlock()
+
+	n := time.Now().UnixNano() / int64(time.Microsecond)
+	if n <= c.nonce {
+		n = c.nonce + 1
+	}
+	c.nonce = n
+	return strconv.FormatInt(n, 10)
+}
+
+// newRequest create new public API request. Relative url can be provided in refURL.
+func (c *RestClient) newRequest(method, refURL string, params url.Values) (*http.Request, error) {
+	rel, err := url.Parse(refURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		rel.RawQuery = params.Encode()
+	}
+
+	pathURL := c.PublicBaseURL.ResolveReference(rel)
+	return http.NewRequest(method, pathURL.String(), nil)
+}
+
+// newAuthenticatedRequest creates new http request for authenticated routes.
+// all the authenticated routes of bitfinex are POST requests with a json body.
+func (c *RestClient) newAuthenticatedRequest(refURL string, params url.Values, payload interface{}) (*http.Request, error) {
+	if len(c.Key) == 0 {
+		return nil, errors.New("empty api key")
+	}
+
+	if len(c.Secret) == 0 {
+		return nil, errors.New("empty api secret")
+	}
+
+	rel, err := url.Parse(refURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		rel.RawQuery = params.Encode()
+	}
+
+	pathURL := c.BaseURL.ResolveReference(rel)
+
+	var body = []byte("{}")
+	if payload != nil {
+		body, err = json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// signature payload: /api/v2/auth/r/wallets + nonce + body
+	nonce := c.Nonce()
+	signature := Sign("/api"+pathURL.Path+nonce+string(body), c.Secret)
+
+	req, err := http.NewRequest("POST", pathURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("bfx-nonce", nonce)
+	req.Header.Add("bfx-apikey", c.Key)
+	req.Header.Add("bfx-signature", signature)
+	return req, nil
+}
+
+// sendRequest sends the request to the API server and handle the response
+func (c *RestClient) sendRequest(req *http.Request) (*util.Response, error) {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// newResponse reads the response body and return a new Response object
+	response, err := util.NewResponse(resp)
+	if err != nil {
+		return response, err
+	}
+
+	// Check error, bitfinex returns ["error", ERR_CODE, "error message"] for errors
+	if response.IsError() {
+		return response, errors.New(string(response.Body))
+	}
+
+	return response, nil
+}
+
+// Sign signs the payload with HMAC-SHA384, the signature is in hex format.
+func Sign(payload string, secret string) string {
+	var sig = hmac.New(sha512.New384, []byte(secret))
+	_, err := sig.Write([]byte(payload))
+	if err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(sig.Sum(nil))
+}
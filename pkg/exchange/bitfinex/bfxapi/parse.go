@@ -0,0 +1,334 @@
+package bfxapi
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/valyala/fastjson"
+)
+
+// bitfinex v2 API responds with positional arrays instead of objects,
+// the following parsers convert the arrays into structs by the field index.
+
+type Wallet struct {
+	Type              WalletType
+	Currency          string
+	Balance           fixedpoint.Value
+	UnsettledInterest fixedpoint.Value
+
+	// AvailableBalance is null in some websocket updates, HasAvailable is false in that case.
+	AvailableBalance fixedpoint.Value
+	HasAvailable     bool
+}
+
+type Ticker struct {
+	Symbol              string
+	Bid                 fixedpoint.Value
+	BidSize             fixedpoint.Value
+	Ask                 fixedpoint.Value
+	AskSize             fixedpoint.Value
+	DailyChange         fixedpoint.Value
+	DailyChangeRelative fixedpoint.Value
+	LastPrice           fixedpoint.Value
+	Volume              fixedpoint.Value
+	High                fixedpoint.Value
+	Low                 fixedpoint.Value
+}
+
+type Candle struct {
+	Time   time.Time
+	Open   fixedpoint.Value
+	Close  fixedpoint.Value
+	High   fixedpoint.Value
+	Low    fixedpoint.Value
+	Volume fixedpoint.Value
+}
+
+type Order struct {
+	ID            int64
+	GroupID       int64
+	ClientOrderID int64
+	Symbol        string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+
+	// Amount is the remaining amount, positive means buy, negative means sell
+	Amount fixedpoint.Value
+
+	// AmountOrig is the original amount
+	AmountOrig fixedpoint.Value
+	Type       OrderType
+	Flags      int64
+
+	// Status is a string like "ACTIVE", "EXECUTED @ 107.6(-0.2)", "PARTIALLY FILLED @ ...", "CANCELED"
+	Status       string
+	Price        fixedpoint.Value
+	AveragePrice fixedpoint.Value
+}
+
+type Trade struct {
+	ID            int64
+	Symbol        string
+	Time          time.Time
+	OrderID       int64
+	ExecAmount    fixedpoint.Value
+	ExecPrice     fixedpoint.Value
+	OrderType     OrderType
+	OrderPrice    fixedpoint.Value
+	Maker         bool
+	Fee           fixedpoint.Value
+	FeeCurrency   string
+	ClientOrderID int64
+}
+
+type PairInfo struct {
+	Pair          string
+	MinOrderSize  fixedpoint.Value
+	MaxOrderSize  fixedpoint.Value
+	InitialMargin fixedpoint.Value
+	MinimumMargin fixedpoint.Value
+}
+
+func arrayOf(v *fastjson.Value, minLen int) ([]*fastjson.Value, error) {
+	arr, err := v.Array()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(arr) < minLen {
+		return nil, fmt.Errorf("unexpected array length: %d, expecting at least %d", len(arr), minLen)
+	}
+
+	return arr, nil
+}
+
+// valueOf converts a json number or a json string into fixedpoint.Value, null is converted to zero.
+func valueOf(v *fastjson.Value) (fixedpoint.Value, bool) {
+	if v == nil {
+		return 0, false
+	}
+
+	switch v.Type() {
+	case fastjson.TypeNumber:
+		return fixedpoint.NewFromFloat(v.GetFloat64()), true
+	case fastjson.TypeString:
+		val, err := fixedpoint.NewFromString(string(v.GetStringBytes()))
+		if err != nil {
+			return 0, false
+		}
+		return val, true
+	}
+
+	return 0, false
+}
+
+func numberOf(v *fastjson.Value) fixedpoint.Value {
+	val, _ := valueOf(v)
+	return val
+}
+
+func stringOf(v *fastjson.Value) string {
+	if v == nil || v.Type() != fastjson.TypeString {
+		return ""
+	}
+	return string(v.GetStringBytes())
+}
+
+func int64Of(v *fastjson.Value) int64 {
+	if v == nil {
+		return 0
+	}
+
+	switch v.Type() {
+	case fastjson.TypeNumber:
+		return v.GetInt64()
+	case fastjson.TypeString:
+		i, _ := strconv.ParseInt(string(v.GetStringBytes()), 10, 64)
+		return i
+	}
+
+	return 0
+}
+
+func timeOf(v *fastjson.Value) time.Time {
+	return time.Unix(0, int64Of(v)*int64(time.Millisecond))
+}
+
+// ParseWallet parses [WALLET_TYPE, CURRENCY, BALANCE, UNSETTLED_INTEREST, AVAILABLE_BALANCE, ...]
+func ParseWallet(v *fastjson.Value) (*Wallet, error) {
+	arr, err := arrayOf(v, 4)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet := &Wallet{
+		Type:              WalletType(stringOf(arr[0])),
+		Currency:          stringOf(arr[1]),
+		Balance:           numberOf(arr[2]),
+		UnsettledInterest: numberOf(arr[3]),
+	}
+
+	if len(arr) > 4 {
+		wallet.AvailableBalance, wallet.HasAvailable = valueOf(arr[4])
+	}
+
+	return wallet, nil
+}
+
+func ParseWallets(v *fastjson.Value) (wallets []Wallet, err error) {
+	arr, err := v.Array()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range arr {
+		wallet, err := ParseWallet(item)
+		if err != nil {
+			return wallets, err
+		}
+		wallets = append(wallets, *wallet)
+	}
+
+	return wallets, nil
+}
+
+// ParseTicker parses [SYMBOL, BID, BID_SIZE, ASK, ASK_SIZE, DAILY_CHANGE, DAILY_CHANGE_RELATIVE, LAST_PRICE, VOLUME, HIGH, LOW]
+func ParseTicker(v *fastjson.Value) (*Ticker, error) {
+	arr, err := arrayOf(v, 11)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Ticker{
+		Symbol:              stringOf(arr[0]),
+		Bid:                 numberOf(arr[1]),
+		BidSize:             numberOf(arr[2]),
+		Ask:                 numberOf(arr[3]),
+		AskSize:             numberOf(arr[4]),
+		DailyChange:         numberOf(arr[5]),
+		DailyChangeRelative: numberOf(arr[6]),
+		LastPrice:           numberOf(arr[7]),
+		Volume:              numberOf(arr[8]),
+		High:                numberOf(arr[9]),
+		Low:                 numberOf(arr[10]),
+	}, nil
+}
+
+// ParseCandle parses [MTS, OPEN, CLOSE, HIGH, LOW, VOLUME]
+func ParseCandle(v *fastjson.Value) (*Candle, error) {
+	arr, err := arrayOf(v, 6)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Candle{
+		Time:   timeOf(arr[0]),
+		Open:   numberOf(arr[1]),
+		Close:  numberOf(arr[2]),
+		High:   numberOf(arr[3]),
+		Low:    numberOf(arr[4]),
+		Volume: numberOf(arr[5]),
+	}, nil
+}
+
+// ParseOrder parses [ID, GID, CID, SYMBOL, MTS_CREATE, MTS_UPDATE, AMOUNT, AMOUNT_ORIG, ORDER_TYPE, TYPE_PREV,
+// MTS_TIF, _PLACEHOLDER, FLAGS, ORDER_STATUS, _PLACEHOLDER, _PLACEHOLDER, PRICE, PRICE_AVG, ...]
+func ParseOrder(v *fastjson.Value) (*Order, error) {
+	arr, err := arrayOf(v, 18)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Order{
+		ID:            int64Of(arr[0]),
+		GroupID:       int64Of(arr[1]),
+		ClientOrderID: int64Of(arr[2]),
+		Symbol:        stringOf(arr[3]),
+		CreatedAt:     timeOf(arr[4]),
+		UpdatedAt:     timeOf(arr[5]),
+		Amount:        numberOf(arr[6]),
+		AmountOrig:    numberOf(arr[7]),
+		Type:          OrderType(stringOf(arr[8])),
+		Flags:         int64Of(arr[12]),
+		Status:        stringOf(arr[13]),
+		Price:         numberOf(arr[16]),
+		AveragePrice:  numberOf(arr[17]),
+	}, nil
+}
+
+func ParseOrders(v *fastjson.Value) (orders []Order, err error) {
+	arr, err := v.Array()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range arr {
+		order, err := ParseOrder(item)
+		if err != nil {
+			return orders, err
+		}
+		orders = append(orders, *order)
+	}
+
+	return orders, nil
+}
+
+// ParseTrade parses [ID, SYMBOL, MTS_CREATE, ORDER_ID, EXEC_AMOUNT, EXEC_PRICE, ORDER_TYPE, ORDER_PRICE, MAKER, FEE, FEE_CURRENCY, CID]
+// the fee fields are only available in the "tu" websocket messages and the REST responses.
+func ParseTrade(v *fastjson.Value) (*Trade, error) {
+	arr, err := arrayOf(v, 9)
+	if err != nil {
+		return nil, err
+	}
+
+	trade := &Trade{
+		ID:         int64Of(arr[0]),
+		Symbol:     stringOf(arr[1]),
+		Time:       timeOf(arr[2]),
+		OrderID:    int64Of(arr[3]),
+		ExecAmount: numberOf(arr[4]),
+		ExecPrice:  numberOf(arr[5]),
+		OrderType:  OrderType(stringOf(arr[6])),
+		OrderPrice: numberOf(arr[7]),
+		Maker:      int64Of(arr[8]) == 1,
+	}
+
+	if len(arr) > 10 {
+		trade.Fee = numberOf(arr[9])
+		trade.FeeCurrency = stringOf(arr[10])
+	}
+
+	if len(arr) > 11 {
+		trade.ClientOrderID = int64Of(arr[11])
+	}
+
+	return trade, nil
+}
+
+// ParsePairInfo parses [PAIR, [_PLACEHOLDER, _PLACEHOLDER, _PLACEHOLDER, MIN_ORDER_SIZE, MAX_ORDER_SIZE, _PLACEHOLDER, _PLACEHOLDER, _PLACEHOLDER, INITIAL_MARGIN, MIN_MARGIN]]
+func ParsePairInfo(v *fastjson.Value) (*PairInfo, error) {
+	arr, err := arrayOf(v, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := arrayOf(arr[1], 5)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &PairInfo{
+		Pair:         stringOf(arr[0]),
+		MinOrderSize: numberOf(fields[3]),
+		MaxOrderSize: numberOf(fields[4]),
+	}
+
+	if len(fields) > 9 {
+		info.InitialMargin = numberOf(fields[8])
+		info.MinimumMargin = numberOf(fields[9])
+	}
+
+	return info, nil
+}
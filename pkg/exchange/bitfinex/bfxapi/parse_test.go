@@ -0,0 +1,74 @@
+package bfxapi
+
+import (
+	"testing"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fastjson"
+)
+
+func TestParseWallets(t *testing.T) {
+	v := fastjson.MustParse(`[["exchange","UST",19788.6529257,0,19788.6529257,null,null],["margin","BTC",0.5,0,null,null,null],["funding","USD",100,0.1,99,null,null]]`)
+	wallets, err := ParseWallets(v)
+	assert.NoError(t, err)
+	assert.Len(t, wallets, 3)
+
+	assert.Equal(t, WalletTypeExchange, wallets[0].Type)
+	assert.Equal(t, "UST", wallets[0].Currency)
+	assert.True(t, wallets[0].HasAvailable)
+	assert.Equal(t, fixedpoint.NewFromFloat(19788.6529257), wallets[0].AvailableBalance)
+
+	assert.Equal(t, WalletTypeMargin, wallets[1].Type)
+	assert.False(t, wallets[1].HasAvailable)
+
+	assert.Equal(t, WalletTypeFunding, wallets[2].Type)
+	assert.Equal(t, fixedpoint.NewFromFloat(0.1), wallets[2].UnsettledInterest)
+}
+
+func TestParseOrder(t *testing.T) {
+	v := fastjson.MustParse(`[33950998275,null,1573476747887,"tETHUSD",1573476748000,1573476748000,-0.5,-0.5,"EXCHANGE LIMIT",null,null,null,4096,"ACTIVE",null,null,15,0,0,0,null,null,null,0,0,null,null,null,"API>BFX",null,null,null]`)
+	order, err := ParseOrder(v)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(33950998275), order.ID)
+	assert.Equal(t, int64(1573476747887), order.ClientOrderID)
+	assert.Equal(t, "tETHUSD", order.Symbol)
+	assert.Equal(t, fixedpoint.NewFromFloat(-0.5), order.AmountOrig)
+	assert.Equal(t, OrderTypeExchangeLimit, order.Type)
+	assert.Equal(t, int64(OrderFlagPostOnly), order.Flags)
+	assert.Equal(t, "ACTIVE", order.Status)
+	assert.Equal(t, fixedpoint.NewFromFloat(15), order.Price)
+}
+
+func TestParseTrade(t *testing.T) {
+	v := fastjson.MustParse(`[402088407,"tETHUST",1574963975602,34938060782,-0.2,153.57,"MARKET",0,-1,-0.061668,"UST",0]`)
+	trade, err := ParseTrade(v)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(402088407), trade.ID)
+	assert.Equal(t, "tETHUST", trade.Symbol)
+	assert.Equal(t, int64(34938060782), trade.OrderID)
+	assert.Equal(t, fixedpoint.NewFromFloat(-0.2), trade.ExecAmount)
+	assert.Equal(t, fixedpoint.NewFromFloat(153.57), trade.ExecPrice)
+	assert.False(t, trade.Maker)
+	assert.Equal(t, fixedpoint.NewFromFloat(-0.061668), trade.Fee)
+	assert.Equal(t, "UST", trade.FeeCurrency)
+}
+
+func TestParseNotificationOrders(t *testing.T) {
+	v := fastjson.MustParse(`[1567590617.442,"on-req",null,null,[[30630788061,null,1567590617439,"tBTCUSD",1567590617439,1567590617439,0.001,0.001,"LIMIT",null,null,null,4096,"ACTIVE",null,null,15,0,0,0,null,null,null,0,null,null,null,null,"API>BFX",null,null,null]],null,"SUCCESS","Submitting 1 orders."]`)
+	orders, err := parseNotificationOrders(v)
+	assert.NoError(t, err)
+	assert.Len(t, orders, 1)
+	assert.Equal(t, int64(30630788061), orders[0].ID)
+
+	v = fastjson.MustParse(`[1567590617.442,"on-req",null,null,[],null,"ERROR","Invalid order: minimum size"]`)
+	_, err = parseNotificationOrders(v)
+	assert.Error(t, err)
+}
+
+func TestSign(t *testing.T) {
+	// sha384 hex digest has 96 characters
+	sig := Sign("/api/v2/auth/r/wallets1234{}", "secret")
+	assert.Len(t, sig, 96)
+	assert.Equal(t, sig, Sign("/api/v2/auth/r/wallets1234{}", "secret"))
+}
@@ -0,0 +1,155 @@
+package bfxapi
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valyala/fastjson"
+)
+
+func (c *RestClient) PairInfo(ctx context.Context) ([]PairInfo, error) {
+	req, err := c.newRequest("GET", "/v2/conf/pub/info:pair", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.sendRequest(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := fastjson.ParseBytes(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// the response is wrapped with one more array: [[[PAIR, [...]], ...]]
+	outer, err := arrayOf(v, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := outer[0].Array()
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []PairInfo
+	for _, item := range items {
+		info, err := ParsePairInfo(item)
+		if err != nil {
+			return infos, err
+		}
+		infos = append(infos, *info)
+	}
+
+	return infos, nil
+}
+
+// Tickers queries the tickers of the given symbols, all tickers are returned when symbols is empty.
+// symbols are in the local format like "tBTCUSD".
+func (c *RestClient) Tickers(ctx context.Context, symbols ...string) ([]Ticker, error) {
+	var params = url.Values{}
+	if len(symbols) > 0 {
+		params.Add("symbols", strings.Join(symbols, ","))
+	} else {
+		params.Add("symbols", "ALL")
+	}
+
+	req, err := c.newRequest("GET", "/v2/tickers", params)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.sendRequest(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := fastjson.ParseBytes(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := v.Array()
+	if err != nil {
+		return nil, err
+	}
+
+	var tickers []Ticker
+	for _, item := range items {
+		// skip the funding tickers, they start with "f" and have a different layout
+		if !strings.HasPrefix(stringOf(item.Get("0")), "t") {
+			continue
+		}
+
+		ticker, err := ParseTicker(item)
+		if err != nil {
+			return tickers, err
+		}
+		tickers = append(tickers, *ticker)
+	}
+
+	return tickers, nil
+}
+
+type CandleQuery struct {
+	// TimeFrame is one of 1m, 5m, 15m, 30m, 1h, 3h, 6h, 12h, 1D, 1W, 14D, 1M
+	TimeFrame string
+	Symbol    string
+	Start     *time.Time
+	End       *time.Time
+	Limit     int
+}
+
+// Candles returns the candles in ascending order
+func (c *RestClient) Candles(ctx context.Context, query CandleQuery) ([]Candle, error) {
+	var params = url.Values{}
+	params.Add("sort", "1")
+
+	if query.Start != nil {
+		params.Add("start", strconv.FormatInt(query.Start.UnixNano()/int64(time.Millisecond), 10))
+	}
+
+	if query.End != nil {
+		params.Add("end", strconv.FormatInt(query.End.UnixNano()/int64(time.Millisecond), 10))
+	}
+
+	if query.Limit > 0 {
+		params.Add("limit", strconv.Itoa(query.Limit))
+	}
+
+	req, err := c.newRequest("GET", "/v2/candles/trade:"+query.TimeFrame+":"+query.Symbol+"/hist", params)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.sendRequest(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := fastjson.ParseBytes(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := v.Array()
+	if err != nil {
+		return nil, err
+	}
+
+	var candles []Candle
+	for _, item := range items {
+		candle, err := ParseCandle(item)
+		if err != nil {
+			return candles, err
+		}
+		candles = append(candles, *candle)
+	}
+
+	return candles, nil
+}
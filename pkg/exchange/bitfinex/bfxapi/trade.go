@@ -0,0 +1,178 @@
+package bfxapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/valyala/fastjson"
+)
+
+func (c *RestClient) Wallets(ctx context.Context) ([]Wallet, error) {
+	v, err := c.authenticatedCall(ctx, "/v2/auth/r/wallets", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseWallets(v)
+}
+
+type SubmitOrderRequest struct {
+	Type   OrderType `json:"type"`
+	Symbol string    `json:"symbol"`
+
+	// Amount is positive for buy and negative for sell
+	Amount string `json:"amount"`
+	Price  string `json:"price,omitempty"`
+
+	// PriceAuxLimit is the limit price of the stop limit orders
+	PriceAuxLimit string `json:"price_aux_limit,omitempty"`
+
+	ClientOrderID int64 `json:"cid,omitempty"`
+	GroupID       int64 `json:"gid,omitempty"`
+	Flags         int64 `json:"flags,omitempty"`
+}
+
+func (c *RestClient) SubmitOrder(ctx context.Context, order SubmitOrderRequest) (*Order, error) {
+	v, err := c.authenticatedCall(ctx, "/v2/auth/w/order/submit", order)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := parseNotificationOrders(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(orders) == 0 {
+		return nil, errors.New("empty order submit response")
+	}
+
+	return &orders[0], nil
+}
+
+func (c *RestClient) CancelOrder(ctx context.Context, orderID int64) error {
+	_, err := c.authenticatedCall(ctx, "/v2/auth/w/order/cancel", map[string]interface{}{
+		"id": orderID,
+	})
+	return err
+}
+
+// ActiveOrders returns the active orders, symbol is optional
+func (c *RestClient) ActiveOrders(ctx context.Context, symbol string) ([]Order, error) {
+	path := "/v2/auth/r/orders"
+	if len(symbol) > 0 {
+		path += "/" + symbol
+	}
+
+	v, err := c.authenticatedCall(ctx, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseOrders(v)
+}
+
+type HistoryQuery struct {
+	Start *time.Time `json:"-"`
+	End   *time.Time `json:"-"`
+	Limit int        `json:"-"`
+}
+
+func (q HistoryQuery) payload() map[string]interface{} {
+	var payload = map[string]interface{}{
+		// sort ascending
+		"sort": 1,
+	}
+
+	if q.Start != nil {
+		payload["start"] = q.Start.UnixNano() / int64(time.Millisecond)
+	}
+
+	if q.End != nil {
+		payload["end"] = q.End.UnixNano() / int64(time.Millisecond)
+	}
+
+	if q.Limit > 0 {
+		payload["limit"] = q.Limit
+	}
+
+	return payload
+}
+
+func (c *RestClient) OrderHistory(ctx context.Context, symbol string, query HistoryQuery) ([]Order, error) {
+	v, err := c.authenticatedCall(ctx, "/v2/auth/r/orders/"+symbol+"/hist", query.payload())
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseOrders(v)
+}
+
+func (c *RestClient) TradeHistory(ctx context.Context, symbol string, query HistoryQuery) ([]Trade, error) {
+	v, err := c.authenticatedCall(ctx, "/v2/auth/r/trades/"+symbol+"/hist", query.payload())
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := v.Array()
+	if err != nil {
+		return nil, err
+	}
+
+	var trades []Trade
+	for _, item := range items {
+		trade, err := ParseTrade(item)
+		if err != nil {
+			return trades, err
+		}
+		trades = append(trades, *trade)
+	}
+
+	return trades, nil
+}
+
+func (c *RestClient) authenticatedCall(ctx context.Context, path string, payload interface{}) (*fastjson.Value, error) {
+	req, err := c.newAuthenticatedRequest(path, nil, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.sendRequest(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return fastjson.ParseBytes(response.Body)
+}
+
+// parseNotificationOrders parses the notification response of the write APIs:
+// [MTS, TYPE, MESSAGE_ID, null, ORDER_OR_ORDERS, CODE, STATUS, TEXT]
+func parseNotificationOrders(v *fastjson.Value) ([]Order, error) {
+	arr, err := arrayOf(v, 8)
+	if err != nil {
+		return nil, err
+	}
+
+	if status := stringOf(arr[6]); status != "SUCCESS" {
+		return nil, fmt.Errorf("bitfinex api error: %s %s", status, stringOf(arr[7]))
+	}
+
+	data := arr[4]
+	items, err := data.Array()
+	if err != nil {
+		return nil, err
+	}
+
+	// a single order notification
+	if len(items) > 0 && items[0].Type() == fastjson.TypeNumber {
+		order, err := ParseOrder(data)
+		if err != nil {
+			return nil, err
+		}
+		return []Order{*order}, nil
+	}
+
+	return ParseOrders(data)
+}
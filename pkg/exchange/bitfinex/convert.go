@@ -0,0 +1,289 @@
+package bitfinex
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/c9s/bbgo/pkg/exchange/bitfinex/bfxapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// bitfinex uses its own currency codes for some currencies
+var currencyAliases = map[string]string{
+	"UST": "USDT",
+	"UDC": "USDC",
+	"DSH": "DASH",
+	"IOT": "IOTA",
+	"QTM": "QTUM",
+}
+
+var localSymbolMap = struct {
+	sync.RWMutex
+	symbols map[string]string
+}{symbols: make(map[string]string)}
+
+func toGlobalCurrency(currency string) string {
+	currency = strings.ToUpper(currency)
+	if alias, ok := currencyAliases[currency]; ok {
+		return alias
+	}
+	return currency
+}
+
+// splitLocalPair splits "BTCUSD" or "TESTBTC:TESTUSD" into base and quote currencies
+func splitLocalPair(pair string) (base, quote string) {
+	pair = strings.TrimPrefix(pair, "t")
+	if parts := strings.SplitN(pair, ":", 2); len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+
+	if len(pair) < 6 {
+		return pair, ""
+	}
+
+	return pair[:3], pair[3:]
+}
+
+func toGlobalSymbol(localSymbol string) string {
+	base, quote := splitLocalPair(localSymbol)
+	return toGlobalCurrency(base) + toGlobalCurrency(quote)
+}
+
+// toLocalSymbol converts the global symbol into the bitfinex trading pair symbol, e.g., BTCUSDT => tBTCUST
+// the markets must be loaded before converting the symbols with long currency names.
+func toLocalSymbol(symbol string) string {
+	localSymbolMap.RLock()
+	defer localSymbolMap.RUnlock()
+
+	if s, ok := localSymbolMap.symbols[symbol]; ok {
+		return s
+	}
+
+	for global, local := range map[string]string{"USDT": "UST", "USDC": "UDC"} {
+		if strings.HasSuffix(symbol, global) {
+			return "t" + strings.TrimSuffix(symbol, global) + local
+		}
+	}
+
+	return "t" + symbol
+}
+
+func registerLocalSymbol(symbol, localSymbol string) {
+	localSymbolMap.Lock()
+	localSymbolMap.symbols[symbol] = localSymbol
+	localSymbolMap.Unlock()
+}
+
+// toGlobalBalanceMap converts the wallets of the given wallet type into the balance map.
+// bitfinex splits the funds into exchange, margin and funding wallets, only one wallet type
+// is mapped so that the balances are not double counted.
+func toGlobalBalanceMap(wallets []bfxapi.Wallet, walletType bfxapi.WalletType) types.BalanceMap {
+	var balances = types.BalanceMap{}
+	for _, wallet := range wallets {
+		if wallet.Type != walletType {
+			continue
+		}
+
+		balances[toGlobalCurrency(wallet.Currency)] = toGlobalBalance(wallet)
+	}
+
+	return balances
+}
+
+func toGlobalBalance(wallet bfxapi.Wallet) types.Balance {
+	available := wallet.Balance
+	if wallet.HasAvailable {
+		available = wallet.AvailableBalance
+	}
+
+	locked := wallet.Balance - available
+	if locked < 0 {
+		locked = 0
+	}
+
+	return types.Balance{
+		Currency:  toGlobalCurrency(wallet.Currency),
+		Available: available,
+		Locked:    locked,
+	}
+}
+
+func toLocalOrderType(orderType types.OrderType, timeInForce string, isMargin bool) (bfxapi.OrderType, error) {
+	var localType bfxapi.OrderType
+	switch orderType {
+	case types.OrderTypeLimit, types.OrderTypeLimitMaker:
+		localType = bfxapi.OrderTypeLimit
+		switch timeInForce {
+		case "IOC":
+			localType = bfxapi.OrderTypeIOC
+		case "FOK":
+			localType = bfxapi.OrderTypeFOK
+		}
+
+	case types.OrderTypeIOCLimit:
+		localType = bfxapi.OrderTypeIOC
+
+	case types.OrderTypeMarket:
+		localType = bfxapi.OrderTypeMarket
+
+	case types.OrderTypeStopLimit:
+		localType = bfxapi.OrderTypeStopLimit
+
+	case types.OrderTypeStopMarket:
+		localType = bfxapi.OrderTypeStop
+
+	default:
+		return "", fmt.Errorf("order type %s is not supported", orderType)
+	}
+
+	// the orders without the "EXCHANGE" prefix are margin orders
+	if !isMargin {
+		localType = "EXCHANGE " + localType
+	}
+
+	return localType, nil
+}
+
+func toGlobalOrderType(orderType bfxapi.OrderType, flags int64) types.OrderType {
+	switch bfxapi.OrderType(strings.TrimPrefix(string(orderType), "EXCHANGE ")) {
+	case bfxapi.OrderTypeLimit:
+		if flags&bfxapi.OrderFlagPostOnly > 0 {
+			return types.OrderTypeLimitMaker
+		}
+		return types.OrderTypeLimit
+
+	case bfxapi.OrderTypeIOC, bfxapi.OrderTypeFOK:
+		return types.OrderTypeIOCLimit
+
+	case bfxapi.OrderTypeMarket:
+		return types.OrderTypeMarket
+
+	case bfxapi.OrderTypeStopLimit:
+		return types.OrderTypeStopLimit
+
+	case bfxapi.OrderTypeStop:
+		return types.OrderTypeStopMarket
+	}
+
+	return types.OrderType(orderType)
+}
+
+func toGlobalOrderStatus(status string, executed fixedpoint.Value) types.OrderStatus {
+	switch {
+	case strings.HasPrefix(status, "ACTIVE"):
+		if executed > 0 {
+			return types.OrderStatusPartiallyFilled
+		}
+		return types.OrderStatusNew
+
+	case strings.HasPrefix(status, "EXECUTED"):
+		return types.OrderStatusFilled
+
+	case strings.HasPrefix(status, "PARTIALLY FILLED"):
+		return types.OrderStatusPartiallyFilled
+
+	case strings.HasPrefix(status, "CANCELED"), strings.Contains(status, "CANCELED"):
+		return types.OrderStatusCanceled
+
+	case strings.HasPrefix(status, "INSUFFICIENT"), strings.HasPrefix(status, "RSN_"):
+		return types.OrderStatusRejected
+	}
+
+	return types.OrderStatus(status)
+}
+
+func toGlobalOrder(order bfxapi.Order) types.Order {
+	side := types.SideTypeBuy
+	if order.AmountOrig < 0 {
+		side = types.SideTypeSell
+	}
+
+	quantity := order.AmountOrig.Abs()
+	executed := quantity - order.Amount.Abs()
+	status := toGlobalOrderStatus(order.Status, executed)
+	isMargin := !strings.HasPrefix(string(order.Type), "EXCHANGE")
+
+	var clientOrderID string
+	if order.ClientOrderID > 0 {
+		clientOrderID = fmt.Sprintf("%d", order.ClientOrderID)
+	}
+
+	return types.Order{
+		SubmitOrder: types.SubmitOrder{
+			ClientOrderID: clientOrderID,
+			Symbol:        toGlobalSymbol(order.Symbol),
+			Side:          side,
+			Type:          toGlobalOrderType(order.Type, order.Flags),
+			Quantity:      quantity.Float64(),
+			Price:         order.Price.Float64(),
+			GroupID:       uint32(order.GroupID),
+		},
+		Exchange:         types.ExchangeBitfinex,
+		OrderID:          uint64(order.ID),
+		Status:           status,
+		ExecutedQuantity: executed.Float64(),
+		IsWorking:        status == types.OrderStatusNew || status == types.OrderStatusPartiallyFilled,
+		CreationTime:     types.Time(order.CreatedAt),
+		UpdateTime:       types.Time(order.UpdatedAt),
+		IsMargin:         isMargin,
+	}
+}
+
+func toGlobalTrade(trade bfxapi.Trade) types.Trade {
+	side := types.SideTypeBuy
+	if trade.ExecAmount < 0 {
+		side = types.SideTypeSell
+	}
+
+	quantity := trade.ExecAmount.Abs()
+	return types.Trade{
+		ID:            trade.ID,
+		OrderID:       uint64(trade.OrderID),
+		Exchange:      types.ExchangeBitfinex,
+		Price:         trade.ExecPrice.Float64(),
+		Quantity:      quantity.Float64(),
+		QuoteQuantity: quantity.Mul(trade.ExecPrice).Float64(),
+		Symbol:        toGlobalSymbol(trade.Symbol),
+		Side:          side,
+		IsBuyer:       side == types.SideTypeBuy,
+		IsMaker:       trade.Maker,
+		Time:          types.Time(trade.Time),
+		// bitfinex reports the fee as a negative number
+		Fee:         trade.Fee.Abs().Float64(),
+		FeeCurrency: toGlobalCurrency(trade.FeeCurrency),
+		IsMargin:    !strings.HasPrefix(string(trade.OrderType), "EXCHANGE"),
+	}
+}
+
+func toGlobalTicker(ticker bfxapi.Ticker) types.Ticker {
+	return types.Ticker{
+		Volume: ticker.Volume.Float64(),
+		Last:   ticker.LastPrice.Float64(),
+		Open:   (ticker.LastPrice - ticker.DailyChange).Float64(),
+		High:   ticker.High.Float64(),
+		Low:    ticker.Low.Float64(),
+		Buy:    ticker.Bid.Float64(),
+		Sell:   ticker.Ask.Float64(),
+	}
+}
+
+var supportedIntervals = map[types.Interval]string{
+	types.Interval1m:  "1m",
+	types.Interval5m:  "5m",
+	types.Interval15m: "15m",
+	types.Interval30m: "30m",
+	types.Interval1h:  "1h",
+	types.Interval6h:  "6h",
+	types.Interval12h: "12h",
+	types.Interval1d:  "1D",
+}
+
+func toLocalInterval(interval types.Interval) (string, error) {
+	if s, ok := supportedIntervals[interval]; ok {
+		return s, nil
+	}
+
+	return "", fmt.Errorf("interval %s is not supported by bitfinex", interval)
+}
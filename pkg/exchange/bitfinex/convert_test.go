@@ -0,0 +1,107 @@
+package bitfinex
+
+import (
+	"testing"
+
+	"github.com/c9s/bbgo/pkg/exchange/bitfinex/bfxapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSymbolConversion(t *testing.T) {
+	assert.Equal(t, "BTCUSD", toGlobalSymbol("tBTCUSD"))
+	assert.Equal(t, "BTCUSDT", toGlobalSymbol("tBTCUST"))
+	assert.Equal(t, "TESTBTCTESTUSD", toGlobalSymbol("tTESTBTC:TESTUSD"))
+
+	assert.Equal(t, "tBTCUSD", toLocalSymbol("BTCUSD"))
+	assert.Equal(t, "tETHUST", toLocalSymbol("ETHUSDT"))
+
+	registerLocalSymbol("DOGEUSDT", "tDOGE:UST")
+	assert.Equal(t, "tDOGE:UST", toLocalSymbol("DOGEUSDT"))
+}
+
+func TestToGlobalBalanceMap(t *testing.T) {
+	wallets := []bfxapi.Wallet{
+		{Type: bfxapi.WalletTypeExchange, Currency: "UST", Balance: fixedpoint.NewFromFloat(100.0), AvailableBalance: fixedpoint.NewFromFloat(80.0), HasAvailable: true},
+		{Type: bfxapi.WalletTypeExchange, Currency: "BTC", Balance: fixedpoint.NewFromFloat(1.0)},
+		{Type: bfxapi.WalletTypeMargin, Currency: "BTC", Balance: fixedpoint.NewFromFloat(2.0)},
+		{Type: bfxapi.WalletTypeFunding, Currency: "USD", Balance: fixedpoint.NewFromFloat(500.0)},
+	}
+
+	balances := toGlobalBalanceMap(wallets, bfxapi.WalletTypeExchange)
+	assert.Len(t, balances, 2)
+	assert.Equal(t, fixedpoint.NewFromFloat(80.0), balances["USDT"].Available)
+	assert.Equal(t, fixedpoint.NewFromFloat(20.0), balances["USDT"].Locked)
+	assert.Equal(t, fixedpoint.NewFromFloat(1.0), balances["BTC"].Available)
+
+	balances = toGlobalBalanceMap(wallets, bfxapi.WalletTypeMargin)
+	assert.Len(t, balances, 1)
+	assert.Equal(t, fixedpoint.NewFromFloat(2.0), balances["BTC"].Available)
+
+	balances = toGlobalBalanceMap(wallets, bfxapi.WalletTypeFunding)
+	assert.Len(t, balances, 1)
+	assert.Equal(t, fixedpoint.NewFromFloat(500.0), balances["USD"].Available)
+}
+
+func TestToLocalOrderType(t *testing.T) {
+	orderType, err := toLocalOrderType(types.OrderTypeLimit, "", false)
+	assert.NoError(t, err)
+	assert.Equal(t, bfxapi.OrderTypeExchangeLimit, orderType)
+
+	orderType, err = toLocalOrderType(types.OrderTypeLimit, "IOC", false)
+	assert.NoError(t, err)
+	assert.Equal(t, bfxapi.OrderTypeExchangeIOC, orderType)
+
+	orderType, err = toLocalOrderType(types.OrderTypeMarket, "", true)
+	assert.NoError(t, err)
+	assert.Equal(t, bfxapi.OrderTypeMarket, orderType)
+
+	assert.Equal(t, types.OrderTypeLimitMaker, toGlobalOrderType(bfxapi.OrderTypeExchangeLimit, bfxapi.OrderFlagPostOnly))
+	assert.Equal(t, types.OrderTypeStopLimit, toGlobalOrderType(bfxapi.OrderTypeExchangeStopLimit, 0))
+}
+
+func TestToGlobalOrder(t *testing.T) {
+	order := toGlobalOrder(bfxapi.Order{
+		ID:         1234,
+		Symbol:     "tBTCUST",
+		Amount:     fixedpoint.NewFromFloat(-0.4),
+		AmountOrig: fixedpoint.NewFromFloat(-1.0),
+		Type:       bfxapi.OrderTypeExchangeLimit,
+		Status:     "PARTIALLY FILLED @ 50000.0(-0.6)",
+		Price:      fixedpoint.NewFromFloat(50000.0),
+	})
+
+	assert.Equal(t, "BTCUSDT", order.Symbol)
+	assert.Equal(t, types.SideTypeSell, order.Side)
+	assert.Equal(t, 1.0, order.Quantity)
+	assert.InDelta(t, 0.6, order.ExecutedQuantity, 1e-8)
+	assert.Equal(t, types.OrderStatusPartiallyFilled, order.Status)
+	assert.True(t, order.IsWorking)
+	assert.False(t, order.IsMargin)
+
+	assert.Equal(t, types.OrderStatusFilled, toGlobalOrderStatus("EXECUTED @ 107.6(-0.2)", 0))
+	assert.Equal(t, types.OrderStatusCanceled, toGlobalOrderStatus("CANCELED", 0))
+}
+
+func TestToGlobalTrade(t *testing.T) {
+	trade := toGlobalTrade(bfxapi.Trade{
+		ID:          402088407,
+		Symbol:      "tETHUST",
+		OrderID:     34938060782,
+		ExecAmount:  fixedpoint.NewFromFloat(-0.2),
+		ExecPrice:   fixedpoint.NewFromFloat(150.0),
+		OrderType:   bfxapi.OrderTypeExchangeMarket,
+		Fee:         fixedpoint.NewFromFloat(-0.06),
+		FeeCurrency: "UST",
+	})
+
+	assert.Equal(t, "ETHUSDT", trade.Symbol)
+	assert.Equal(t, types.SideTypeSell, trade.Side)
+	assert.False(t, trade.IsBuyer)
+	assert.Equal(t, 0.2, trade.Quantity)
+	assert.InDelta(t, 30.0, trade.QuoteQuantity, 1e-8)
+	assert.Equal(t, 0.06, trade.Fee)
+	assert.Equal(t, "USDT", trade.FeeCurrency)
+	assert.False(t, trade.IsMargin)
+}
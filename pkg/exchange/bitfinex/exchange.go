@@ -0,0 +1,377 @@
+package bitfinex
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/exchange/bitfinex/bfxapi"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// PlatformToken is the platform token (LEO) of bitfinex
+const PlatformToken = "LEO"
+
+// bitfinex prices are limited to 5 significant digits
+const pricePrecision = 5
+
+var log = logrus.WithFields(logrus.Fields{
+	"exchange": "bitfinex",
+})
+
+type Exchange struct {
+	types.MarginSettings
+
+	key, secret string
+	client      *bfxapi.RestClient
+}
+
+func New(key, secret string) *Exchange {
+	client := bfxapi.NewClient()
+	if len(key) > 0 && len(secret) > 0 {
+		client.Auth(key, secret)
+	}
+
+	return &Exchange{
+		key:    key,
+		secret: secret,
+		client: client,
+	}
+}
+
+func (e *Exchange) Name() types.ExchangeName {
+	return types.ExchangeBitfinex
+}
+
+func (e *Exchange) PlatformFeeCurrency() string {
+	return PlatformToken
+}
+
+func (e *Exchange) Capabilities() types.ExchangeCapabilities {
+	return types.ExchangeCapabilities{
+		Margin:          true,
+		WebSocketKLines: true,
+		WebSocketBook:   true,
+		OrderTypes: []types.OrderType{
+			types.OrderTypeLimit,
+			types.OrderTypeLimitMaker,
+			types.OrderTypeMarket,
+			types.OrderTypeStopLimit,
+			types.OrderTypeStopMarket,
+			types.OrderTypeIOCLimit,
+		},
+		TimeInForces: []string{types.TimeInForceGTC, types.TimeInForceIOC, types.TimeInForceFOK},
+	}
+}
+
+// walletType returns the wallet used for trading, the exchange wallet is used for spot trading
+// and the margin wallet is used when margin is enabled.
+func (e *Exchange) walletType() bfxapi.WalletType {
+	if e.IsMargin {
+		return bfxapi.WalletTypeMargin
+	}
+	return bfxapi.WalletTypeExchange
+}
+
+func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
+	infos, err := e.client.PairInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	markets := types.MarketMap{}
+	for _, info := range infos {
+		localSymbol := "t" + info.Pair
+		base, quote := splitLocalPair(info.Pair)
+		symbol := toGlobalSymbol(localSymbol)
+		registerLocalSymbol(symbol, localSymbol)
+
+		minQuantity := info.MinOrderSize.Float64()
+		volumePrecision := 8
+		markets[symbol] = types.Market{
+			Symbol:          symbol,
+			LocalSymbol:     localSymbol,
+			BaseCurrency:    toGlobalCurrency(base),
+			QuoteCurrency:   toGlobalCurrency(quote),
+			PricePrecision:  pricePrecision,
+			VolumePrecision: volumePrecision,
+			MinQuantity:     minQuantity,
+			MaxQuantity:     info.MaxOrderSize.Float64(),
+			StepSize:        math.Pow10(-volumePrecision),
+			TickSize:        math.Pow10(-pricePrecision),
+			// bitfinex does not have the minimal notional, the min order size is used instead
+			MinNotional: 0,
+			MinAmount:   0,
+		}
+	}
+
+	return markets, nil
+}
+
+func (e *Exchange) QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
+	tickers, err := e.client.Tickers(ctx, toLocalSymbol(symbol))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tickers) == 0 {
+		return nil, fmt.Errorf("ticker of %s not found", symbol)
+	}
+
+	ticker := toGlobalTicker(tickers[0])
+	ticker.Time = time.Now()
+	return &ticker, nil
+}
+
+func (e *Exchange) QueryTickers(ctx context.Context, symbols ...string) (map[string]types.Ticker, error) {
+	var localSymbols []string
+	for _, s := range symbols {
+		localSymbols = append(localSymbols, toLocalSymbol(s))
+	}
+
+	tickers, err := e.client.Tickers(ctx, localSymbols...)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var tickerMap = make(map[string]types.Ticker)
+	for _, t := range tickers {
+		ticker := toGlobalTicker(t)
+		ticker.Time = now
+		tickerMap[toGlobalSymbol(t.Symbol)] = ticker
+	}
+
+	return tickerMap, nil
+}
+
+func (e *Exchange) SupportedInterval() map[types.Interval]int {
+	var intervals = make(map[types.Interval]int)
+	for interval := range supportedIntervals {
+		intervals[interval] = interval.Minutes()
+	}
+	return intervals
+}
+
+func (e *Exchange) IsSupportedInterval(interval types.Interval) bool {
+	_, ok := supportedIntervals[interval]
+	return ok
+}
+
+func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
+	timeFrame, err := toLocalInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := options.Limit
+	if limit == 0 {
+		limit = 1000
+	}
+
+	candles, err := e.client.Candles(ctx, bfxapi.CandleQuery{
+		TimeFrame: timeFrame,
+		Symbol:    toLocalSymbol(symbol),
+		Start:     options.StartTime,
+		End:       options.EndTime,
+		Limit:     limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var kLines []types.KLine
+	for _, candle := range candles {
+		kLines = append(kLines, types.KLine{
+			Exchange:  types.ExchangeBitfinex,
+			Symbol:    symbol,
+			Interval:  interval,
+			StartTime: candle.Time,
+			EndTime:   candle.Time.Add(interval.Duration() - time.Millisecond),
+			Open:      candle.Open.Float64(),
+			Close:     candle.Close.Float64(),
+			High:      candle.High.Float64(),
+			Low:       candle.Low.Float64(),
+			Volume:    candle.Volume.Float64(),
+			Closed:    true,
+		})
+	}
+
+	return kLines, nil
+}
+
+func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	balances, err := e.QueryAccountBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	account := types.NewAccount()
+	account.AccountType = types.AccountTypeSpot
+	account.UpdateBalances(balances)
+	return account, nil
+}
+
+func (e *Exchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	return e.QueryWalletBalances(ctx, e.walletType())
+}
+
+// QueryWalletBalances queries the balances of the given wallet type,
+// this can be used for querying the funding wallet which is not used for trading.
+func (e *Exchange) QueryWalletBalances(ctx context.Context, walletType bfxapi.WalletType) (types.BalanceMap, error) {
+	wallets, err := e.client.Wallets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return toGlobalBalanceMap(wallets, walletType), nil
+}
+
+func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	for _, order := range orders {
+		orderType, err := toLocalOrderType(order.Type, order.TimeInForce, e.IsMargin)
+		if err != nil {
+			return createdOrders, err
+		}
+
+		quantity := order.QuantityString
+		if len(quantity) == 0 {
+			if order.Market.Symbol != "" {
+				quantity = order.Market.FormatQuantity(order.Quantity)
+			} else {
+				quantity = strconv.FormatFloat(order.Quantity, 'f', -1, 64)
+			}
+		}
+
+		// bitfinex uses the sign of the amount as the order side
+		if order.Side == types.SideTypeSell {
+			quantity = "-" + quantity
+		}
+
+		req := bfxapi.SubmitOrderRequest{
+			Type:    orderType,
+			Symbol:  toLocalSymbol(order.Symbol),
+			Amount:  quantity,
+			GroupID: int64(order.GroupID),
+		}
+
+		switch order.Type {
+		case types.OrderTypeLimit, types.OrderTypeLimitMaker, types.OrderTypeIOCLimit:
+			req.Price = formatPrice(order)
+
+		case types.OrderTypeStopLimit:
+			req.Price = strconv.FormatFloat(order.StopPrice, 'f', -1, 64)
+			req.PriceAuxLimit = formatPrice(order)
+
+		case types.OrderTypeStopMarket:
+			req.Price = strconv.FormatFloat(order.StopPrice, 'f', -1, 64)
+		}
+
+		if order.IsPostOnly() {
+			req.Flags |= bfxapi.OrderFlagPostOnly
+		}
+
+		if len(order.ClientOrderID) > 0 {
+			cid, err := strconv.ParseInt(order.ClientOrderID, 10, 64)
+			if err != nil {
+				return createdOrders, errors.Wrapf(err, "bitfinex client order id must be an integer: %s", order.ClientOrderID)
+			}
+			req.ClientOrderID = cid
+		}
+
+		created, err := e.client.SubmitOrder(ctx, req)
+		if err != nil {
+			return createdOrders, err
+		}
+
+		globalOrder := toGlobalOrder(*created)
+		globalOrder.Market = order.Market
+		createdOrders = append(createdOrders, globalOrder)
+	}
+
+	return createdOrders, nil
+}
+
+func formatPrice(order types.SubmitOrder) string {
+	if len(order.PriceString) > 0 {
+		return order.PriceString
+	}
+
+	return strconv.FormatFloat(order.Price, 'g', pricePrecision, 64)
+}
+
+func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	localOrders, err := e.client.ActiveOrders(ctx, toLocalSymbol(symbol))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range localOrders {
+		orders = append(orders, toGlobalOrder(o))
+	}
+
+	return orders, nil
+}
+
+func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) (orders []types.Order, err error) {
+	localOrders, err := e.client.OrderHistory(ctx, toLocalSymbol(symbol), bfxapi.HistoryQuery{
+		Start: &since,
+		End:   &until,
+		Limit: 500,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range localOrders {
+		if uint64(o.ID) <= lastOrderID {
+			continue
+		}
+
+		orders = append(orders, toGlobalOrder(o))
+	}
+
+	return orders, nil
+}
+
+func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	for _, order := range orders {
+		if err := e.client.CancelOrder(ctx, int64(order.OrderID)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) ([]types.Trade, error) {
+	query := bfxapi.HistoryQuery{
+		Start: options.StartTime,
+		End:   options.EndTime,
+		Limit: int(options.Limit),
+	}
+
+	localTrades, err := e.client.TradeHistory(ctx, toLocalSymbol(symbol), query)
+	if err != nil {
+		return nil, err
+	}
+
+	var trades []types.Trade
+	for _, t := range localTrades {
+		if options.LastTradeID > 0 && t.ID <= options.LastTradeID {
+			continue
+		}
+
+		trades = append(trades, toGlobalTrade(t))
+	}
+
+	return trades, nil
+}
+
+func (e *Exchange) NewStream() types.Stream {
+	return NewStream(e.client, e.walletType())
+}
@@ -0,0 +1,142 @@
+package bitfinex
+
+import (
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/valyala/fastjson"
+)
+
+// WebSocketEvent is the json object message of the bitfinex websocket,
+// e.g., {"event":"subscribed","channel":"book","chanId":10961,"symbol":"tBTCUSD"}
+type WebSocketEvent struct {
+	Event   string
+	Channel string
+	ChanID  int64
+	Symbol  string
+	Key     string
+	Status  string
+	Message string
+	Code    int64
+}
+
+// ChannelMessage is the array message of the bitfinex websocket, it could be:
+//
+//	[CHAN_ID, DATA]               -- book or candle snapshot/update
+//	[CHAN_ID, "hb"]               -- heartbeat
+//	[0, TYPE, DATA]               -- account channel, TYPE is one of ws, wu, os, on, ou, oc, te, tu
+type ChannelMessage struct {
+	ChanID int64
+	Type   string
+	Data   *fastjson.Value
+}
+
+func (m *ChannelMessage) IsHeartbeat() bool {
+	return m.Type == "hb"
+}
+
+func Parse(str string) (interface{}, error) {
+	v, err := fastjson.Parse(str)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v.Type() {
+	case fastjson.TypeObject:
+		return parseEvent(v), nil
+
+	case fastjson.TypeArray:
+		return parseChannelMessage(v)
+	}
+
+	return nil, nil
+}
+
+func parseEvent(v *fastjson.Value) *WebSocketEvent {
+	return &WebSocketEvent{
+		Event:   string(v.GetStringBytes("event")),
+		Channel: string(v.GetStringBytes("channel")),
+		ChanID:  v.GetInt64("chanId"),
+		Symbol:  string(v.GetStringBytes("symbol")),
+		Key:     string(v.GetStringBytes("key")),
+		Status:  string(v.GetStringBytes("status")),
+		Message: string(v.GetStringBytes("msg")),
+		Code:    v.GetInt64("code"),
+	}
+}
+
+func parseChannelMessage(v *fastjson.Value) (*ChannelMessage, error) {
+	arr, err := v.Array()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(arr) < 2 {
+		return nil, fmt.Errorf("unexpected channel message length: %d", len(arr))
+	}
+
+	msg := &ChannelMessage{ChanID: arr[0].GetInt64()}
+	if arr[1].Type() == fastjson.TypeString {
+		msg.Type = string(arr[1].GetStringBytes())
+		if len(arr) > 2 {
+			msg.Data = arr[2]
+		}
+		return msg, nil
+	}
+
+	msg.Data = arr[1]
+	return msg, nil
+}
+
+// isSnapshot checks if the data is a list of entries, the snapshot messages are nested arrays
+func isSnapshot(data *fastjson.Value) bool {
+	arr, err := data.Array()
+	if err != nil || len(arr) == 0 {
+		// empty snapshot
+		return err == nil
+	}
+
+	return arr[0].Type() == fastjson.TypeArray
+}
+
+// parseBookEntries parses the book entries [PRICE, COUNT, AMOUNT], the positive amount is the bid side,
+// and the negative amount is the ask side. when count is 0, the price level should be removed.
+func parseBookEntries(symbol string, data *fastjson.Value) (types.SliceOrderBook, error) {
+	book := types.SliceOrderBook{Symbol: symbol}
+
+	var entries []*fastjson.Value
+	if isSnapshot(data) {
+		entries = data.GetArray()
+	} else {
+		entries = []*fastjson.Value{data}
+	}
+
+	for _, entry := range entries {
+		arr, err := entry.Array()
+		if err != nil {
+			return book, err
+		}
+
+		if len(arr) < 3 {
+			return book, fmt.Errorf("unexpected book entry length: %d", len(arr))
+		}
+
+		price := fixedpoint.NewFromFloat(arr[0].GetFloat64())
+		count := arr[1].GetInt64()
+		amount := fixedpoint.NewFromFloat(arr[2].GetFloat64())
+
+		pv := types.PriceVolume{Price: price, Volume: amount.Abs()}
+		if count == 0 {
+			pv.Volume = 0
+		}
+
+		if amount > 0 {
+			book.Bids = append(book.Bids, pv)
+		} else {
+			book.Asks = append(book.Asks, pv)
+		}
+	}
+
+	return book, nil
+}
@@ -0,0 +1,61 @@
+package bitfinex
+
+import (
+	"testing"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEvent(t *testing.T) {
+	e, err := Parse(`{"event":"subscribed","channel":"candles","chanId":343351,"key":"trade:1m:tBTCUSD"}`)
+	assert.NoError(t, err)
+
+	event, ok := e.(*WebSocketEvent)
+	assert.True(t, ok)
+	assert.Equal(t, "subscribed", event.Event)
+	assert.Equal(t, "candles", event.Channel)
+	assert.Equal(t, int64(343351), event.ChanID)
+	assert.Equal(t, "trade:1m:tBTCUSD", event.Key)
+}
+
+func TestParseChannelMessage(t *testing.T) {
+	e, err := Parse(`[17082,"hb"]`)
+	assert.NoError(t, err)
+	msg, ok := e.(*ChannelMessage)
+	assert.True(t, ok)
+	assert.True(t, msg.IsHeartbeat())
+
+	e, err = Parse(`[0,"wu",["exchange","BTC",1.5,0,1.2,null,null]]`)
+	assert.NoError(t, err)
+	msg = e.(*ChannelMessage)
+	assert.Equal(t, int64(0), msg.ChanID)
+	assert.Equal(t, "wu", msg.Type)
+	assert.NotNil(t, msg.Data)
+}
+
+func TestParseBookEntries(t *testing.T) {
+	e, err := Parse(`[17082,[[7254.7,3,3.3],[7254.6,2,-1.5],[7254.5,0,1]]]`)
+	assert.NoError(t, err)
+	msg := e.(*ChannelMessage)
+	assert.True(t, isSnapshot(msg.Data))
+
+	book, err := parseBookEntries("BTCUSD", msg.Data)
+	assert.NoError(t, err)
+	assert.Len(t, book.Bids, 2)
+	assert.Len(t, book.Asks, 1)
+	assert.Equal(t, fixedpoint.NewFromFloat(3.3), book.Bids[0].Volume)
+	assert.Equal(t, fixedpoint.NewFromFloat(1.5), book.Asks[0].Volume)
+
+	// count = 0 removes the price level
+	assert.Equal(t, fixedpoint.Value(0), book.Bids[1].Volume)
+
+	e, err = Parse(`[17082,[7254.7,1,-0.5]]`)
+	assert.NoError(t, err)
+	msg = e.(*ChannelMessage)
+	assert.False(t, isSnapshot(msg.Data))
+
+	book, err = parseBookEntries("BTCUSD", msg.Data)
+	assert.NoError(t, err)
+	assert.Len(t, book.Asks, 1)
+}
@@ -0,0 +1,513 @@
+package bitfinex
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/exchange/bitfinex/bfxapi"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/gorilla/websocket"
+	"github.com/valyala/fastjson"
+)
+
+const readTimeout = 30 * time.Second
+
+type channelInfo struct {
+	Channel  types.Channel
+	Symbol   string
+	Interval types.Interval
+}
+
+//go:generate callbackgen -type Stream -interface
+type Stream struct {
+	types.StandardStream
+
+	Client     *bfxapi.RestClient
+	Conn       *websocket.Conn
+	connLock   sync.Mutex
+	connCtx    context.Context
+	connCancel context.CancelFunc
+
+	publicOnly bool
+	walletType bfxapi.WalletType
+
+	// channels maps the channel id to the subscribed channel
+	channels     map[int64]channelInfo
+	channelsLock sync.Mutex
+
+	lastKLines map[int64]types.KLine
+
+	eventCallbacks  []func(event WebSocketEvent)
+	walletCallbacks []func(wallet bfxapi.Wallet)
+	orderCallbacks  []func(order bfxapi.Order)
+	tradeCallbacks  []func(trade bfxapi.Trade)
+}
+
+func NewStream(client *bfxapi.RestClient, walletType bfxapi.WalletType) *Stream {
+	stream := &Stream{
+		Client: client,
+		StandardStream: types.StandardStream{
+			ReconnectC: make(chan struct{}, 1),
+		},
+		walletType: walletType,
+		channels:   make(map[int64]channelInfo),
+		lastKLines: make(map[int64]types.KLine),
+	}
+
+	stream.OnEvent(func(event WebSocketEvent) {
+		switch event.Event {
+		case "subscribed":
+			stream.registerChannel(event)
+
+		case "auth":
+			if event.Status != "OK" {
+				log.Errorf("websocket auth failed: %s (code %d)", event.Message, event.Code)
+			} else {
+				log.Infof("websocket authenticated")
+			}
+
+		case "error":
+			log.Errorf("websocket error: %s (code %d)", event.Message, event.Code)
+
+		case "info":
+			// code 20051: stop/restart websocket server, we should reconnect
+			if event.Code == 20051 {
+				stream.Reconnect()
+			}
+		}
+	})
+
+	stream.OnWallet(func(wallet bfxapi.Wallet) {
+		if wallet.Type != stream.walletType {
+			return
+		}
+
+		balance := toGlobalBalance(wallet)
+		stream.EmitBalanceUpdate(types.BalanceMap{balance.Currency: balance})
+	})
+
+	stream.OnOrder(func(order bfxapi.Order) {
+		stream.EmitOrderUpdate(toGlobalOrder(order))
+	})
+
+	stream.OnTrade(func(trade bfxapi.Trade) {
+		stream.EmitTradeUpdate(toGlobalTrade(trade))
+	})
+
+	stream.OnConnect(func() {
+		stream.channelsLock.Lock()
+		stream.channels = make(map[int64]channelInfo)
+		stream.lastKLines = make(map[int64]types.KLine)
+		stream.channelsLock.Unlock()
+
+		if !stream.publicOnly {
+			nonce := stream.Client.Nonce()
+			payload := "AUTH" + nonce
+			err := stream.Conn.WriteJSON(map[string]interface{}{
+				"event":       "auth",
+				"apiKey":      stream.Client.Key,
+				"authSig":     bfxapi.Sign(payload, stream.Client.Secret),
+				"authPayload": payload,
+				"authNonce":   nonce,
+			})
+			if err != nil {
+				log.WithError(err).Error("can not send auth message")
+			}
+		}
+
+		for _, subscription := range stream.Subscriptions {
+			req, err := convertSubscription(subscription)
+			if err != nil {
+				log.WithError(err).Errorf("subscription convert error")
+				continue
+			}
+
+			if err := stream.Conn.WriteJSON(req); err != nil {
+				log.WithError(err).Error("subscribe error")
+			}
+		}
+	})
+
+	return stream
+}
+
+func convertSubscription(s types.Subscription) (map[string]interface{}, error) {
+	switch s.Channel {
+	case types.BookChannel:
+		return map[string]interface{}{
+			"event":   "subscribe",
+			"channel": "book",
+			"symbol":  toLocalSymbol(s.Symbol),
+			"prec":    "P0",
+			"freq":    "F0",
+			"len":     "25",
+		}, nil
+
+	case types.KLineChannel:
+		timeFrame, err := toLocalInterval(types.Interval(s.Options.Interval))
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string]interface{}{
+			"event":   "subscribe",
+			"channel": "candles",
+			"key":     "trade:" + timeFrame + ":" + toLocalSymbol(s.Symbol),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported channel: %s", s.Channel)
+}
+
+func (s *Stream) registerChannel(event WebSocketEvent) {
+	var info channelInfo
+	switch event.Channel {
+	case "book":
+		info = channelInfo{Channel: types.BookChannel, Symbol: toGlobalSymbol(event.Symbol)}
+
+	case "candles":
+		// key: trade:1m:tBTCUSD
+		parts := strings.SplitN(event.Key, ":", 3)
+		if len(parts) != 3 {
+			log.Errorf("unexpected candle channel key: %s", event.Key)
+			return
+		}
+
+		info = channelInfo{Channel: types.KLineChannel, Symbol: toGlobalSymbol(parts[2])}
+		for interval, timeFrame := range supportedIntervals {
+			if timeFrame == parts[1] {
+				info.Interval = interval
+			}
+		}
+
+	default:
+		return
+	}
+
+	s.channelsLock.Lock()
+	s.channels[event.ChanID] = info
+	s.channelsLock.Unlock()
+}
+
+func (s *Stream) SetPublicOnly() {
+	s.publicOnly = true
+}
+
+func (s *Stream) Close() error {
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+
+	if s.connCancel != nil {
+		s.connCancel()
+	}
+
+	if s.Conn != nil {
+		return s.Conn.Close()
+	}
+
+	return nil
+}
+
+func (s *Stream) Connect(ctx context.Context) error {
+	err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	// start one re-connector goroutine with the base context
+	go s.Reconnector(ctx)
+
+	s.EmitStart()
+	return nil
+}
+
+func (s *Stream) Reconnector(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-s.ReconnectC:
+			log.Warnf("received reconnect signal, reconnecting...")
+			time.Sleep(3 * time.Second)
+
+			if err := s.connect(ctx); err != nil {
+				log.WithError(err).Errorf("connect error, try to reconnect again...")
+				s.Reconnect()
+			}
+		}
+	}
+}
+
+func (s *Stream) connect(ctx context.Context) error {
+	var url = bfxapi.WebSocketURL
+	if s.publicOnly {
+		url = bfxapi.PublicWebSocketURL
+	}
+
+	conn, err := s.StandardStream.Dial(url)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("websocket connected: %s", url)
+
+	// should only start one connection one time, so we lock the mutex
+	s.connLock.Lock()
+
+	// ensure the previous context is cancelled
+	if s.connCancel != nil {
+		s.connCancel()
+	}
+
+	// create a new context
+	s.connCtx, s.connCancel = context.WithCancel(ctx)
+
+	conn.SetReadDeadline(time.Now().Add(readTimeout))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(readTimeout))
+		return nil
+	})
+
+	s.Conn = conn
+	s.connLock.Unlock()
+
+	s.EmitConnect()
+
+	go s.read(s.connCtx)
+	go s.ping(s.connCtx)
+	return nil
+}
+
+func (s *Stream) read(ctx context.Context) {
+	defer func() {
+		if s.connCancel != nil {
+			s.connCancel()
+		}
+		s.EmitDisconnect()
+	}()
+
+	for {
+		select {
+
+		case <-ctx.Done():
+			return
+
+		default:
+			s.connLock.Lock()
+			conn := s.Conn
+			s.connLock.Unlock()
+
+			if err := conn.SetReadDeadline(time.Now().Add(readTimeout)); err != nil {
+				log.WithError(err).Errorf("set read deadline error: %s", err.Error())
+			}
+
+			mt, message, err := conn.ReadMessage()
+			if err != nil {
+				switch err := err.(type) {
+				case *websocket.CloseError:
+					if err.Code == websocket.CloseNormalClosure {
+						return
+					}
+
+					s.Reconnect()
+					return
+
+				case net.Error:
+					log.WithError(err).Error("network error")
+					s.Reconnect()
+					return
+
+				default:
+					log.WithError(err).Error("unexpected connection error")
+					s.Reconnect()
+					return
+				}
+			}
+
+			mt, message, err = types.DecompressMessage(mt, message)
+			if err != nil {
+				log.WithError(err).Error("websocket message decompress error")
+				continue
+			}
+
+			// skip non-text messages
+			if mt != websocket.TextMessage {
+				continue
+			}
+
+			e, err := Parse(string(message))
+			if err != nil {
+				log.WithError(err).Error("message parse error")
+				continue
+			}
+
+			switch et := e.(type) {
+			case *WebSocketEvent:
+				s.EmitEvent(*et)
+
+			case *ChannelMessage:
+				if err := s.dispatchChannelMessage(et); err != nil {
+					log.WithError(err).Errorf("channel message dispatch error: %s", message)
+				}
+			}
+		}
+	}
+}
+
+func (s *Stream) dispatchChannelMessage(msg *ChannelMessage) error {
+	if msg.IsHeartbeat() || msg.Data == nil {
+		return nil
+	}
+
+	// channel 0 is the account channel
+	if msg.ChanID == 0 {
+		return s.dispatchAccountMessage(msg)
+	}
+
+	s.channelsLock.Lock()
+	info, ok := s.channels[msg.ChanID]
+	s.channelsLock.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	switch info.Channel {
+	case types.BookChannel:
+		book, err := parseBookEntries(info.Symbol, msg.Data)
+		if err != nil {
+			return err
+		}
+
+		if isSnapshot(msg.Data) {
+			s.EmitBookSnapshot(book)
+		} else {
+			s.EmitBookUpdate(book)
+		}
+
+	case types.KLineChannel:
+		var candles []*fastjson.Value
+		if isSnapshot(msg.Data) {
+			// the snapshot candles are in descending order, we only need the latest one
+			candles = msg.Data.GetArray()
+			if len(candles) > 0 {
+				candles = candles[:1]
+			}
+		} else {
+			candles = []*fastjson.Value{msg.Data}
+		}
+
+		for _, c := range candles {
+			candle, err := bfxapi.ParseCandle(c)
+			if err != nil {
+				return err
+			}
+
+			s.handleCandle(msg.ChanID, info, *candle)
+		}
+	}
+
+	return nil
+}
+
+func (s *Stream) handleCandle(chanID int64, info channelInfo, candle bfxapi.Candle) {
+	kline := types.KLine{
+		Exchange:  types.ExchangeBitfinex,
+		Symbol:    info.Symbol,
+		Interval:  info.Interval,
+		StartTime: candle.Time,
+		EndTime:   candle.Time.Add(info.Interval.Duration() - time.Millisecond),
+		Open:      candle.Open.Float64(),
+		Close:     candle.Close.Float64(),
+		High:      candle.High.Float64(),
+		Low:       candle.Low.Float64(),
+		Volume:    candle.Volume.Float64(),
+	}
+
+	// bitfinex does not send the closed flag, close the previous kline when a new kline starts
+	if lastKLine, ok := s.lastKLines[chanID]; ok && kline.StartTime.After(lastKLine.StartTime) {
+		lastKLine.Closed = true
+		s.EmitKLineClosed(lastKLine)
+	}
+
+	s.EmitKLine(kline)
+	s.lastKLines[chanID] = kline
+}
+
+func (s *Stream) dispatchAccountMessage(msg *ChannelMessage) error {
+	switch msg.Type {
+	case "ws":
+		wallets, err := bfxapi.ParseWallets(msg.Data)
+		if err != nil {
+			return err
+		}
+
+		s.EmitBalanceSnapshot(toGlobalBalanceMap(wallets, s.walletType))
+
+	case "wu":
+		wallet, err := bfxapi.ParseWallet(msg.Data)
+		if err != nil {
+			return err
+		}
+
+		s.EmitWallet(*wallet)
+
+	case "os":
+		orders, err := bfxapi.ParseOrders(msg.Data)
+		if err != nil {
+			return err
+		}
+
+		for _, order := range orders {
+			s.EmitOrder(order)
+		}
+
+	case "on", "ou", "oc":
+		order, err := bfxapi.ParseOrder(msg.Data)
+		if err != nil {
+			return err
+		}
+
+		s.EmitOrder(*order)
+
+	case "tu":
+		// "te" is sent before "tu" without the fee fields, we only use "tu" to avoid duplicated trades
+		trade, err := bfxapi.ParseTrade(msg.Data)
+		if err != nil {
+			return err
+		}
+
+		s.EmitTrade(*trade)
+	}
+
+	return nil
+}
+
+func (s *Stream) ping(ctx context.Context) {
+	pingTicker := time.NewTicker(readTimeout / 2)
+	defer pingTicker.Stop()
+
+	for {
+		select {
+
+		case <-ctx.Done():
+			log.Debug("ping worker stopped")
+			return
+
+		case <-pingTicker.C:
+			s.connLock.Lock()
+			conn := s.Conn
+			s.connLock.Unlock()
+
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(3*time.Second)); err != nil {
+				log.WithError(err).Error("ping error", err)
+				s.Reconnect()
+			}
+		}
+	}
+}
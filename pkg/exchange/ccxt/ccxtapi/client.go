@@ -0,0 +1,364 @@
+package ccxtapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+const defaultHTTPTimeout = time.Second * 15
+
+// DefaultBaseURL is the default address of the ccxt-rest gateway
+const DefaultBaseURL = "http://localhost:3000"
+
+// RestClient talks to a CCXT-compatible REST gateway (for example, ccxt-rest),
+// the gateway exposes the ccxt unified api of an exchange instance under /exchanges/{exchange}/{instance}.
+type RestClient struct {
+	BaseURL *url.URL
+
+	client *http.Client
+
+	// Exchange is the ccxt exchange id, e.g., "kraken", "bitstamp"
+	Exchange string
+
+	// InstanceID is the id of the exchange instance created on the gateway
+	InstanceID string
+
+	Key, Secret, Password string
+}
+
+func NewClient(baseURL, exchange string) (*RestClient, error) {
+	if len(baseURL) == 0 {
+		baseURL = DefaultBaseURL
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(exchange) == 0 {
+		return nil, errors.New("ccxt exchange id is required")
+	}
+
+	return &RestClient{
+		BaseURL:    u,
+		Exchange:   exchange,
+		InstanceID: "bbgo-" + exchange,
+		client: &http.Client{
+			Timeout: defaultHTTPTimeout,
+		},
+	}, nil
+}
+
+func (c *RestClient) Auth(key, secret, password string) {
+	c.Key = key
+	c.Secret = secret
+	c.Password = password
+}
+
+// newRequest creates a new request to the path of the exchange instance
+func (c *RestClient) newRequest(ctx context.Context, method, refURL string, params url.Values, payload interface{}) (*http.Request, error) {
+	rel, err := url.Parse(refURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		rel.RawQuery = params.Encode()
+	}
+
+	var body []byte
+	if payload != nil {
+		body, err = json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pathURL := c.BaseURL.ResolveReference(rel)
+	req, err := http.NewRequest(method, pathURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Accept", "application/json")
+	return req, nil
+}
+
+// sendRequest sends the request to the gateway and handle the response
+func (c *RestClient) sendRequest(req *http.Request) (*util.Response, error) {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := util.NewResponse(resp)
+	if err != nil {
+		return response, err
+	}
+
+	if response.IsError() {
+		return response, fmt.Errorf("ccxt gateway error: %s %s: %s", req.Method, req.URL.Path, string(response.Body))
+	}
+
+	return response, nil
+}
+
+func (c *RestClient) call(ctx context.Context, method, path string, params url.Values, payload interface{}, result interface{}) error {
+	req, err := c.newRequest(ctx, method, c.instancePath(path), params, payload)
+	if err != nil {
+		return err
+	}
+
+	response, err := c.sendRequest(req)
+	if err != nil {
+		return err
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return response.DecodeJSON(result)
+}
+
+func (c *RestClient) instancePath(path string) string {
+	return "/exchanges/" + url.PathEscape(c.Exchange) + "/" + url.PathEscape(c.InstanceID) + path
+}
+
+// CreateInstance creates the exchange instance with the api credentials on the gateway
+func (c *RestClient) CreateInstance(ctx context.Context) error {
+	payload := map[string]interface{}{
+		"id": c.InstanceID,
+	}
+
+	if len(c.Key) > 0 {
+		payload["apiKey"] = c.Key
+		payload["secret"] = c.Secret
+	}
+
+	if len(c.Password) > 0 {
+		payload["password"] = c.Password
+	}
+
+	req, err := c.newRequest(ctx, "POST", "/exchanges/"+url.PathEscape(c.Exchange), nil, payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.sendRequest(req)
+	return err
+}
+
+type Precision struct {
+	Amount float64 `json:"amount"`
+	Price  float64 `json:"price"`
+}
+
+type Limit struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+type Limits struct {
+	Amount Limit `json:"amount"`
+	Price  Limit `json:"price"`
+	Cost   Limit `json:"cost"`
+}
+
+// Market is the ccxt unified market structure
+type Market struct {
+	ID        string    `json:"id"`
+	Symbol    string    `json:"symbol"`
+	Base      string    `json:"base"`
+	Quote     string    `json:"quote"`
+	Active    *bool     `json:"active"`
+	Spot      *bool     `json:"spot"`
+	Precision Precision `json:"precision"`
+	Limits    Limits    `json:"limits"`
+}
+
+func (c *RestClient) Markets(ctx context.Context) ([]Market, error) {
+	var markets []Market
+	err := c.call(ctx, "GET", "/markets", nil, nil, &markets)
+	return markets, err
+}
+
+// Ticker is the ccxt unified ticker structure
+type Ticker struct {
+	Symbol      string  `json:"symbol"`
+	Timestamp   int64   `json:"timestamp"`
+	High        float64 `json:"high"`
+	Low         float64 `json:"low"`
+	Bid         float64 `json:"bid"`
+	Ask         float64 `json:"ask"`
+	Open        float64 `json:"open"`
+	Close       float64 `json:"close"`
+	Last        float64 `json:"last"`
+	BaseVolume  float64 `json:"baseVolume"`
+	QuoteVolume float64 `json:"quoteVolume"`
+}
+
+func (c *RestClient) Ticker(ctx context.Context, symbol string) (*Ticker, error) {
+	var ticker Ticker
+	err := c.call(ctx, "GET", "/ticker", url.Values{"symbol": []string{symbol}}, nil, &ticker)
+	return &ticker, err
+}
+
+// Tickers returns the ticker map (ccxt symbol -> ticker)
+func (c *RestClient) Tickers(ctx context.Context, symbols ...string) (map[string]Ticker, error) {
+	var params url.Values
+	if len(symbols) > 0 {
+		params = url.Values{"symbols": symbols}
+	}
+
+	var tickers map[string]Ticker
+	err := c.call(ctx, "GET", "/tickers", params, nil, &tickers)
+	return tickers, err
+}
+
+// OHLCV is [timestamp, open, high, low, close, volume]
+type OHLCV [6]float64
+
+func (c *RestClient) OHLCV(ctx context.Context, symbol, timeframe string, since *time.Time, limit int) ([]OHLCV, error) {
+	params := url.Values{}
+	params.Add("symbol", symbol)
+	params.Add("timeframe", timeframe)
+
+	if since != nil {
+		params.Add("since", strconv.FormatInt(since.UnixNano()/int64(time.Millisecond), 10))
+	}
+
+	if limit > 0 {
+		params.Add("limit", strconv.Itoa(limit))
+	}
+
+	var candles []OHLCV
+	err := c.call(ctx, "GET", "/ohlcv", params, nil, &candles)
+	return candles, err
+}
+
+// Balance is the ccxt unified balance structure, the maps are currency -> amount
+type Balance struct {
+	Free  map[string]float64 `json:"free"`
+	Used  map[string]float64 `json:"used"`
+	Total map[string]float64 `json:"total"`
+}
+
+func (c *RestClient) Balances(ctx context.Context) (*Balance, error) {
+	var balance Balance
+	err := c.call(ctx, "GET", "/balances", nil, nil, &balance)
+	return &balance, err
+}
+
+// Order is the ccxt unified order structure
+type Order struct {
+	ID            string  `json:"id"`
+	ClientOrderID string  `json:"clientOrderId"`
+	Timestamp     int64   `json:"timestamp"`
+	LastTradeTime int64   `json:"lastTradeTimestamp"`
+	Status        string  `json:"status"`
+	Symbol        string  `json:"symbol"`
+	Type          string  `json:"type"`
+	TimeInForce   string  `json:"timeInForce"`
+	Side          string  `json:"side"`
+	Price         float64 `json:"price"`
+	StopPrice     float64 `json:"stopPrice"`
+	Amount        float64 `json:"amount"`
+	Filled        float64 `json:"filled"`
+	Remaining     float64 `json:"remaining"`
+	Average       float64 `json:"average"`
+}
+
+type OrderRequest struct {
+	Symbol string  `json:"symbol"`
+	Type   string  `json:"type"`
+	Side   string  `json:"side"`
+	Amount float64 `json:"amount"`
+	Price  float64 `json:"price,omitempty"`
+
+	// Params is the exchange specific parameters, e.g., clientOrderId, timeInForce
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+func (c *RestClient) CreateOrder(ctx context.Context, request OrderRequest) (*Order, error) {
+	var order Order
+	err := c.call(ctx, "POST", "/order", nil, request, &order)
+	return &order, err
+}
+
+func (c *RestClient) CancelOrder(ctx context.Context, id, symbol string) error {
+	var params url.Values
+	if len(symbol) > 0 {
+		params = url.Values{"symbol": []string{symbol}}
+	}
+
+	return c.call(ctx, "DELETE", "/order/"+url.PathEscape(id), params, nil, nil)
+}
+
+func (c *RestClient) OpenOrders(ctx context.Context, symbol string) ([]Order, error) {
+	var orders []Order
+	err := c.call(ctx, "GET", "/orders/open", newQueryParams(symbol, nil, 0), nil, &orders)
+	return orders, err
+}
+
+func (c *RestClient) ClosedOrders(ctx context.Context, symbol string, since *time.Time, limit int) ([]Order, error) {
+	var orders []Order
+	err := c.call(ctx, "GET", "/orders/closed", newQueryParams(symbol, since, limit), nil, &orders)
+	return orders, err
+}
+
+type Fee struct {
+	Cost     float64 `json:"cost"`
+	Currency string  `json:"currency"`
+}
+
+// Trade is the ccxt unified trade structure
+type Trade struct {
+	ID           string  `json:"id"`
+	Order        string  `json:"order"`
+	Timestamp    int64   `json:"timestamp"`
+	Symbol       string  `json:"symbol"`
+	Type         string  `json:"type"`
+	Side         string  `json:"side"`
+	TakerOrMaker string  `json:"takerOrMaker"`
+	Price        float64 `json:"price"`
+	Amount       float64 `json:"amount"`
+	Cost         float64 `json:"cost"`
+	Fee          *Fee    `json:"fee"`
+}
+
+func (c *RestClient) MyTrades(ctx context.Context, symbol string, since *time.Time, limit int) ([]Trade, error) {
+	var trades []Trade
+	err := c.call(ctx, "GET", "/trades/mine", newQueryParams(symbol, since, limit), nil, &trades)
+	return trades, err
+}
+
+func newQueryParams(symbol string, since *time.Time, limit int) url.Values {
+	params := url.Values{}
+	if len(symbol) > 0 {
+		params.Add("symbol", symbol)
+	}
+
+	if since != nil {
+		params.Add("since", strconv.FormatInt(since.UnixNano()/int64(time.Millisecond), 10))
+	}
+
+	if limit > 0 {
+		params.Add("limit", strconv.Itoa(limit))
+	}
+
+	return params
+}
@@ -0,0 +1,64 @@
+package ccxtapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestClient(t *testing.T) {
+	var createdInstance map[string]interface{}
+	var orderRequest OrderRequest
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/exchanges/kraken", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&createdInstance)
+		_, _ = w.Write([]byte(`{"name":"kraken"}`))
+	})
+	mux.HandleFunc("/exchanges/kraken/bbgo-kraken/ticker", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "BTC/USD", r.URL.Query().Get("symbol"))
+		_, _ = w.Write([]byte(`{"symbol":"BTC/USD","last":50000.5,"bid":50000,"ask":50001,"baseVolume":null}`))
+	})
+	mux.HandleFunc("/exchanges/kraken/bbgo-kraken/order", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		_ = json.NewDecoder(r.Body).Decode(&orderRequest)
+		_, _ = w.Write([]byte(`{"id":"OQCLML-BW3P3","status":"open","symbol":"BTC/USD","side":"buy","type":"limit","amount":0.1,"price":50000}`))
+	})
+	mux.HandleFunc("/exchanges/kraken/bbgo-kraken/balances", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"invalid key"}`, http.StatusUnauthorized)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "kraken")
+	assert.NoError(t, err)
+	client.Auth("key", "secret", "")
+
+	ctx := context.Background()
+	assert.NoError(t, client.CreateInstance(ctx))
+	assert.Equal(t, "bbgo-kraken", createdInstance["id"])
+	assert.Equal(t, "key", createdInstance["apiKey"])
+
+	ticker, err := client.Ticker(ctx, "BTC/USD")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 50000.5, ticker.Last)
+		assert.Equal(t, 0.0, ticker.BaseVolume)
+	}
+
+	order, err := client.CreateOrder(ctx, OrderRequest{Symbol: "BTC/USD", Type: "limit", Side: "buy", Amount: 0.1, Price: 50000})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "OQCLML-BW3P3", order.ID)
+		assert.Equal(t, 0.1, orderRequest.Amount)
+	}
+
+	_, err = client.Balances(ctx)
+	assert.Error(t, err)
+
+	_, err = NewClient(server.URL, "")
+	assert.Error(t, err)
+}
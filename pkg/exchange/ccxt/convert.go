@@ -0,0 +1,223 @@
+package ccxt
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/exchange/ccxt/ccxtapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// toGlobalSymbol converts the ccxt unified symbol "BTC/USDT" to "BTCUSDT"
+func toGlobalSymbol(symbol string) string {
+	return strings.ReplaceAll(symbol, "/", "")
+}
+
+// toGlobalID converts the ccxt string id into the numeric id,
+// the non-numeric ids are hashed, the exchange keeps the reverse lookup of the hashed ids.
+func toGlobalID(id string) uint64 {
+	if n, err := strconv.ParseUint(id, 10, 64); err == nil {
+		return n
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(id))
+	return h.Sum64()
+}
+
+func toGlobalTime(timestamp int64) time.Time {
+	if timestamp == 0 {
+		return time.Now()
+	}
+	return time.Unix(0, timestamp*int64(time.Millisecond))
+}
+
+// toPrecision converts the ccxt precision into the decimal places,
+// ccxt uses decimal places by default, but some exchanges use the tick size (precision < 1).
+func toPrecision(precision float64) int {
+	if precision > 0 && precision < 1 {
+		return int(math.Round(-math.Log10(precision)))
+	}
+	return int(precision)
+}
+
+func toStepSize(precision float64) float64 {
+	if precision > 0 && precision < 1 {
+		return precision
+	}
+	return math.Pow10(-int(precision))
+}
+
+func toGlobalMarket(market ccxtapi.Market) types.Market {
+	return types.Market{
+		Symbol:          toGlobalSymbol(market.Symbol),
+		LocalSymbol:     market.Symbol,
+		BaseCurrency:    market.Base,
+		QuoteCurrency:   market.Quote,
+		PricePrecision:  toPrecision(market.Precision.Price),
+		VolumePrecision: toPrecision(market.Precision.Amount),
+		TickSize:        toStepSize(market.Precision.Price),
+		StepSize:        toStepSize(market.Precision.Amount),
+		MinQuantity:     market.Limits.Amount.Min,
+		MaxQuantity:     market.Limits.Amount.Max,
+		MinPrice:        market.Limits.Price.Min,
+		MaxPrice:        market.Limits.Price.Max,
+		MinNotional:     market.Limits.Cost.Min,
+		MinAmount:       market.Limits.Cost.Min,
+	}
+}
+
+func toGlobalTicker(ticker ccxtapi.Ticker) types.Ticker {
+	return types.Ticker{
+		Time:   toGlobalTime(ticker.Timestamp),
+		Volume: ticker.BaseVolume,
+		Last:   ticker.Last,
+		Open:   ticker.Open,
+		High:   ticker.High,
+		Low:    ticker.Low,
+		Buy:    ticker.Bid,
+		Sell:   ticker.Ask,
+	}
+}
+
+func toGlobalKLine(symbol string, interval types.Interval, candle ccxtapi.OHLCV) types.KLine {
+	startTime := toGlobalTime(int64(candle[0]))
+	return types.KLine{
+		Exchange:  types.ExchangeCCXT,
+		Symbol:    symbol,
+		Interval:  interval,
+		StartTime: startTime,
+		EndTime:   startTime.Add(interval.Duration() - time.Millisecond),
+		Open:      candle[1],
+		High:      candle[2],
+		Low:       candle[3],
+		Close:     candle[4],
+		Volume:    candle[5],
+		Closed:    true,
+	}
+}
+
+func toGlobalBalances(balance *ccxtapi.Balance) types.BalanceMap {
+	var balances = types.BalanceMap{}
+	for currency, free := range balance.Free {
+		balances[currency] = types.Balance{
+			Currency:  currency,
+			Available: fixedpoint.NewFromFloat(free),
+			Locked:    fixedpoint.NewFromFloat(balance.Used[currency]),
+		}
+	}
+
+	return balances
+}
+
+func toGlobalSideType(side string) types.SideType {
+	switch strings.ToLower(side) {
+	case "sell":
+		return types.SideTypeSell
+	}
+	return types.SideTypeBuy
+}
+
+func toLocalSideType(side types.SideType) string {
+	return strings.ToLower(string(side))
+}
+
+func toLocalOrderType(orderType types.OrderType) (string, error) {
+	switch orderType {
+	case types.OrderTypeLimit, types.OrderTypeLimitMaker:
+		return "limit", nil
+	case types.OrderTypeMarket:
+		return "market", nil
+	}
+
+	return "", fmt.Errorf("order type %s is not supported by the ccxt bridge", orderType)
+}
+
+func toGlobalOrderType(orderType string) types.OrderType {
+	switch strings.ToLower(orderType) {
+	case "market":
+		return types.OrderTypeMarket
+	}
+	return types.OrderTypeLimit
+}
+
+func toGlobalOrderStatus(order ccxtapi.Order) types.OrderStatus {
+	switch order.Status {
+	case "open":
+		if order.Filled > 0 {
+			return types.OrderStatusPartiallyFilled
+		}
+		return types.OrderStatusNew
+
+	case "closed":
+		return types.OrderStatusFilled
+
+	case "rejected":
+		return types.OrderStatusRejected
+	}
+
+	// canceled, expired
+	return types.OrderStatusCanceled
+}
+
+func toGlobalOrder(order ccxtapi.Order) types.Order {
+	status := toGlobalOrderStatus(order)
+	return types.Order{
+		SubmitOrder: types.SubmitOrder{
+			ClientOrderID: order.ClientOrderID,
+			Symbol:        toGlobalSymbol(order.Symbol),
+			Side:          toGlobalSideType(order.Side),
+			Type:          toGlobalOrderType(order.Type),
+			Quantity:      order.Amount,
+			Price:         order.Price,
+			StopPrice:     order.StopPrice,
+			TimeInForce:   order.TimeInForce,
+		},
+		Exchange:         types.ExchangeCCXT,
+		OrderID:          toGlobalID(order.ID),
+		Status:           status,
+		ExecutedQuantity: order.Filled,
+		IsWorking:        status == types.OrderStatusNew || status == types.OrderStatusPartiallyFilled,
+		CreationTime:     types.Time(toGlobalTime(order.Timestamp)),
+		UpdateTime:       types.Time(toGlobalTime(order.LastTradeTime)),
+	}
+}
+
+func toGlobalTrade(trade ccxtapi.Trade) types.Trade {
+	side := toGlobalSideType(trade.Side)
+	quoteQuantity := trade.Cost
+	if quoteQuantity == 0 {
+		quoteQuantity = trade.Price * trade.Amount
+	}
+
+	t := types.Trade{
+		ID:            int64(toGlobalID(trade.ID)),
+		OrderID:       toGlobalID(trade.Order),
+		Exchange:      types.ExchangeCCXT,
+		Price:         trade.Price,
+		Quantity:      trade.Amount,
+		QuoteQuantity: quoteQuantity,
+		Symbol:        toGlobalSymbol(trade.Symbol),
+		Side:          side,
+		IsBuyer:       side == types.SideTypeBuy,
+		IsMaker:       trade.TakerOrMaker == "maker",
+		Time:          types.Time(toGlobalTime(trade.Timestamp)),
+	}
+
+	if trade.Fee != nil {
+		t.Fee = trade.Fee.Cost
+		t.FeeCurrency = trade.Fee.Currency
+	}
+
+	return t
+}
+
+// toLocalTimeframe converts the interval to the ccxt timeframe, they share the same format like "1m", "1h", "1d"
+func toLocalTimeframe(interval types.Interval) string {
+	return string(interval)
+}
@@ -0,0 +1,96 @@
+package ccxt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/ccxt/ccxtapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestToGlobalID(t *testing.T) {
+	assert.Equal(t, uint64(12345), toGlobalID("12345"))
+
+	id := toGlobalID("OQCLML-BW3P3-BUCMWZ")
+	assert.NotZero(t, id)
+	assert.Equal(t, id, toGlobalID("OQCLML-BW3P3-BUCMWZ"))
+}
+
+func TestToGlobalMarket(t *testing.T) {
+	market := toGlobalMarket(ccxtapi.Market{
+		Symbol:    "BTC/USDT",
+		Base:      "BTC",
+		Quote:     "USDT",
+		Precision: ccxtapi.Precision{Amount: 8, Price: 0.1},
+		Limits: ccxtapi.Limits{
+			Amount: ccxtapi.Limit{Min: 0.0001},
+			Cost:   ccxtapi.Limit{Min: 10.0},
+		},
+	})
+
+	assert.Equal(t, "BTCUSDT", market.Symbol)
+	assert.Equal(t, "BTC/USDT", market.LocalSymbol)
+	assert.Equal(t, 8, market.VolumePrecision)
+	assert.Equal(t, 1, market.PricePrecision)
+	assert.Equal(t, 0.1, market.TickSize)
+	assert.InDelta(t, 0.00000001, market.StepSize, 1e-12)
+	assert.Equal(t, 10.0, market.MinNotional)
+}
+
+func TestToGlobalOrder(t *testing.T) {
+	order := toGlobalOrder(ccxtapi.Order{
+		ID:     "1001",
+		Status: "open",
+		Symbol: "ETH/BTC",
+		Type:   "limit",
+		Side:   "sell",
+		Price:  0.06,
+		Amount: 1.0,
+		Filled: 0.5,
+	})
+
+	assert.Equal(t, uint64(1001), order.OrderID)
+	assert.Equal(t, "ETHBTC", order.Symbol)
+	assert.Equal(t, types.SideTypeSell, order.Side)
+	assert.Equal(t, types.OrderStatusPartiallyFilled, order.Status)
+	assert.True(t, order.IsWorking)
+
+	order = toGlobalOrder(ccxtapi.Order{ID: "1002", Status: "canceled", Side: "buy", Type: "market"})
+	assert.Equal(t, types.OrderStatusCanceled, order.Status)
+	assert.Equal(t, types.OrderTypeMarket, order.Type)
+	assert.False(t, order.IsWorking)
+}
+
+func TestToGlobalTrade(t *testing.T) {
+	trade := toGlobalTrade(ccxtapi.Trade{
+		ID:           "501",
+		Order:        "1001",
+		Timestamp:    1620000000000,
+		Symbol:       "ETH/BTC",
+		Side:         "buy",
+		TakerOrMaker: "maker",
+		Price:        0.06,
+		Amount:       2.0,
+		Fee:          &ccxtapi.Fee{Cost: 0.0001, Currency: "BTC"},
+	})
+
+	assert.Equal(t, int64(501), trade.ID)
+	assert.Equal(t, uint64(1001), trade.OrderID)
+	assert.True(t, trade.IsBuyer)
+	assert.True(t, trade.IsMaker)
+	assert.InDelta(t, 0.12, trade.QuoteQuantity, 1e-9)
+	assert.Equal(t, "BTC", trade.FeeCurrency)
+	assert.Equal(t, int64(1620000000), trade.Time.Time().Unix())
+}
+
+func TestToGlobalBalances(t *testing.T) {
+	balances := toGlobalBalances(&ccxtapi.Balance{
+		Free: map[string]float64{"BTC": 1.0},
+		Used: map[string]float64{"BTC": 0.5},
+	})
+
+	assert.Equal(t, fixedpoint.NewFromFloat(1.0), balances["BTC"].Available)
+	assert.Equal(t, fixedpoint.NewFromFloat(0.5), balances["BTC"].Locked)
+}
@@ -0,0 +1,483 @@
+package ccxt
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/exchange/ccxt/ccxtapi"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var log = logrus.WithFields(logrus.Fields{
+	"exchange": "ccxt",
+})
+
+func init() {
+	_ = types.Exchange(&Exchange{})
+	_ = types.ExchangeTradeHistoryService(&Exchange{})
+	_ = types.ExchangeCapabilityProvider(&Exchange{})
+}
+
+// Config is the gateway config of the ccxt bridge session
+type Config struct {
+	// URL is the address of the CCXT-compatible REST gateway, e.g., http://localhost:3000
+	URL string `json:"url" yaml:"url"`
+
+	// Exchange is the ccxt exchange id, e.g., kraken, bitstamp
+	Exchange string `json:"exchange" yaml:"exchange"`
+
+	// PollInterval is the polling interval of the stream, defaults to 5 seconds
+	PollInterval types.Duration `json:"pollInterval,omitempty" yaml:"pollInterval,omitempty"`
+}
+
+// Exchange is a generic exchange adapter for the exchanges that are not natively supported by bbgo,
+// all the requests are sent to a CCXT-compatible REST gateway.
+type Exchange struct {
+	config Config
+	client *ccxtapi.RestClient
+
+	mu          sync.Mutex
+	initialized bool
+
+	// localSymbols is the symbol map: BTCUSDT -> BTC/USDT
+	localSymbols map[string]string
+
+	// orderIDs is the reverse lookup of the hashed order ids
+	orderIDs map[uint64]string
+}
+
+func New(config Config, key, secret, password string) (*Exchange, error) {
+	client, err := ccxtapi.NewClient(config.URL, config.Exchange)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(key) > 0 && len(secret) > 0 {
+		client.Auth(key, secret, password)
+	}
+
+	return &Exchange{
+		config:       config,
+		client:       client,
+		localSymbols: make(map[string]string),
+		orderIDs:     make(map[uint64]string),
+	}, nil
+}
+
+func (e *Exchange) Name() types.ExchangeName {
+	return types.ExchangeCCXT
+}
+
+func (e *Exchange) PlatformFeeCurrency() string {
+	return ""
+}
+
+func (e *Exchange) Capabilities() types.ExchangeCapabilities {
+	return types.ExchangeCapabilities{
+		OrderTypes: []types.OrderType{
+			types.OrderTypeLimit,
+			types.OrderTypeLimitMaker,
+			types.OrderTypeMarket,
+		},
+	}
+}
+
+// init creates the exchange instance on the gateway and loads the symbol map
+func (e *Exchange) init(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.initialized {
+		return nil
+	}
+
+	if err := e.client.CreateInstance(ctx); err != nil {
+		return errors.Wrapf(err, "can not create ccxt exchange instance %s", e.config.Exchange)
+	}
+
+	markets, err := e.client.Markets(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, market := range markets {
+		e.localSymbols[toGlobalSymbol(market.Symbol)] = market.Symbol
+	}
+
+	e.initialized = true
+	return nil
+}
+
+func (e *Exchange) toLocalSymbol(symbol string) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if localSymbol, ok := e.localSymbols[symbol]; ok {
+		return localSymbol, nil
+	}
+
+	return "", fmt.Errorf("symbol %s is not found on ccxt exchange %s", symbol, e.config.Exchange)
+}
+
+func (e *Exchange) toLocalOrderID(orderID uint64) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if id, ok := e.orderIDs[orderID]; ok {
+		return id
+	}
+
+	return strconv.FormatUint(orderID, 10)
+}
+
+func (e *Exchange) rememberOrderIDs(orders ...ccxtapi.Order) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, order := range orders {
+		e.orderIDs[toGlobalID(order.ID)] = order.ID
+	}
+}
+
+func (e *Exchange) NewStream() types.Stream {
+	return NewStream(e, e.config.PollInterval.Duration())
+}
+
+func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
+	if err := e.init(ctx); err != nil {
+		return nil, err
+	}
+
+	markets, err := e.client.Markets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	marketMap := types.MarketMap{}
+	for _, market := range markets {
+		// only the spot markets are supported
+		if market.Spot != nil && !*market.Spot {
+			continue
+		}
+
+		if market.Active != nil && !*market.Active {
+			continue
+		}
+
+		m := toGlobalMarket(market)
+		marketMap[m.Symbol] = m
+	}
+
+	return marketMap, nil
+}
+
+func (e *Exchange) QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
+	if err := e.init(ctx); err != nil {
+		return nil, err
+	}
+
+	localSymbol, err := e.toLocalSymbol(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	ticker, err := e.client.Ticker(ctx, localSymbol)
+	if err != nil {
+		return nil, err
+	}
+
+	t := toGlobalTicker(*ticker)
+	return &t, nil
+}
+
+func (e *Exchange) QueryTickers(ctx context.Context, symbols ...string) (map[string]types.Ticker, error) {
+	if err := e.init(ctx); err != nil {
+		return nil, err
+	}
+
+	var localSymbols []string
+	for _, symbol := range symbols {
+		localSymbol, err := e.toLocalSymbol(symbol)
+		if err != nil {
+			return nil, err
+		}
+		localSymbols = append(localSymbols, localSymbol)
+	}
+
+	localTickers, err := e.client.Tickers(ctx, localSymbols...)
+	if err != nil {
+		return nil, err
+	}
+
+	tickers := make(map[string]types.Ticker, len(localTickers))
+	for localSymbol, ticker := range localTickers {
+		tickers[toGlobalSymbol(localSymbol)] = toGlobalTicker(ticker)
+	}
+
+	return tickers, nil
+}
+
+func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
+	if err := e.init(ctx); err != nil {
+		return nil, err
+	}
+
+	localSymbol, err := e.toLocalSymbol(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	candles, err := e.client.OHLCV(ctx, localSymbol, toLocalTimeframe(interval), options.StartTime, options.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var klines []types.KLine
+	for _, candle := range candles {
+		kline := toGlobalKLine(symbol, interval, candle)
+		if options.EndTime != nil && kline.StartTime.After(*options.EndTime) {
+			break
+		}
+
+		klines = append(klines, kline)
+	}
+
+	return klines, nil
+}
+
+func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	balances, err := e.QueryAccountBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	account := &types.Account{
+		AccountType: "SPOT",
+	}
+	account.UpdateBalances(balances)
+	return account, nil
+}
+
+func (e *Exchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	if err := e.init(ctx); err != nil {
+		return nil, err
+	}
+
+	balance, err := e.client.Balances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return toGlobalBalances(balance), nil
+}
+
+func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	if err := e.init(ctx); err != nil {
+		return nil, err
+	}
+
+	for _, order := range orders {
+		localSymbol, err := e.toLocalSymbol(order.Symbol)
+		if err != nil {
+			return createdOrders, err
+		}
+
+		orderType, err := toLocalOrderType(order.Type)
+		if err != nil {
+			return createdOrders, err
+		}
+
+		req := ccxtapi.OrderRequest{
+			Symbol: localSymbol,
+			Type:   orderType,
+			Side:   toLocalSideType(order.Side),
+			Amount: order.Quantity,
+			Params: map[string]interface{}{},
+		}
+
+		if orderType == "limit" {
+			req.Price = order.Price
+		}
+
+		if len(order.ClientOrderID) > 0 {
+			req.Params["clientOrderId"] = order.ClientOrderID
+		}
+
+		if len(order.TimeInForce) > 0 {
+			req.Params["timeInForce"] = order.TimeInForce
+		}
+
+		if order.IsPostOnly() {
+			req.Params["postOnly"] = true
+		}
+
+		createdOrder, err := e.client.CreateOrder(ctx, req)
+		if err != nil {
+			return createdOrders, err
+		}
+
+		e.rememberOrderIDs(*createdOrder)
+
+		created := toGlobalOrder(*createdOrder)
+		created.SubmitOrder = order
+		if len(createdOrder.Status) == 0 {
+			created.Status = types.OrderStatusNew
+			created.IsWorking = true
+		}
+
+		createdOrders = append(createdOrders, created)
+	}
+
+	return createdOrders, nil
+}
+
+func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	if err := e.init(ctx); err != nil {
+		return nil, err
+	}
+
+	// an empty symbol queries the open orders of all the symbols
+	var localSymbol string
+	if len(symbol) > 0 {
+		localSymbol, err = e.toLocalSymbol(symbol)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	openOrders, err := e.client.OpenOrders(ctx, localSymbol)
+	if err != nil {
+		return nil, err
+	}
+
+	e.rememberOrderIDs(openOrders...)
+	for _, order := range openOrders {
+		orders = append(orders, toGlobalOrder(order))
+	}
+
+	return orders, nil
+}
+
+func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) (orders []types.Order, err error) {
+	if err := e.init(ctx); err != nil {
+		return nil, err
+	}
+
+	localSymbol, err := e.toLocalSymbol(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	closedOrders, err := e.client.ClosedOrders(ctx, localSymbol, &since, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	e.rememberOrderIDs(closedOrders...)
+	for _, closedOrder := range closedOrders {
+		order := toGlobalOrder(closedOrder)
+		if order.CreationTime.Time().After(until) {
+			continue
+		}
+
+		orders = append(orders, order)
+	}
+
+	return orders, nil
+}
+
+func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	if err := e.init(ctx); err != nil {
+		return err
+	}
+
+	for _, order := range orders {
+		var localSymbol string
+		if len(order.Symbol) > 0 {
+			s, err := e.toLocalSymbol(order.Symbol)
+			if err != nil {
+				return err
+			}
+			localSymbol = s
+		}
+
+		if err := e.client.CancelOrder(ctx, e.toLocalOrderID(order.OrderID), localSymbol); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) ([]types.Trade, error) {
+	if err := e.init(ctx); err != nil {
+		return nil, err
+	}
+
+	localSymbol, err := e.toLocalSymbol(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	var since *time.Time
+	var limit int
+	if options != nil {
+		since = options.StartTime
+		limit = int(options.Limit)
+	}
+
+	localTrades, err := e.client.MyTrades(ctx, localSymbol, since, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var trades []types.Trade
+	for _, localTrade := range localTrades {
+		trade := toGlobalTrade(localTrade)
+		if options != nil && options.EndTime != nil && trade.Time.Time().After(*options.EndTime) {
+			continue
+		}
+
+		trades = append(trades, trade)
+	}
+
+	return trades, nil
+}
+
+// queryAllTrades queries the trades of all the symbols, it's used by the polling stream
+func (e *Exchange) queryAllTrades(ctx context.Context, since time.Time) ([]types.Trade, error) {
+	localTrades, err := e.client.MyTrades(ctx, "", &since, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var trades []types.Trade
+	for _, localTrade := range localTrades {
+		trades = append(trades, toGlobalTrade(localTrade))
+	}
+
+	return trades, nil
+}
+
+// queryAllClosedOrders queries the closed orders of all the symbols, it's used by the polling stream
+func (e *Exchange) queryAllClosedOrders(ctx context.Context, since time.Time) ([]types.Order, error) {
+	closedOrders, err := e.client.ClosedOrders(ctx, "", &since, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	e.rememberOrderIDs(closedOrders...)
+
+	var orders []types.Order
+	for _, closedOrder := range closedOrders {
+		orders = append(orders, toGlobalOrder(closedOrder))
+	}
+
+	return orders, nil
+}
@@ -0,0 +1,240 @@
+package ccxt
+
+import (
+	"context"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultPollInterval = 5 * time.Second
+
+// Stream is a polling stream since the ccxt REST gateway does not provide the websocket api,
+// the market data stream polls the klines of the subscriptions,
+// the user data stream polls the balances, the open orders and the trades.
+type Stream struct {
+	types.StandardStream
+
+	exchange     *Exchange
+	pollInterval time.Duration
+	publicOnly   bool
+
+	// lastKLines is the last polled kline of each subscription
+	lastKLines map[types.Subscription]types.KLine
+
+	// openOrders is the last polled open orders
+	openOrders map[uint64]types.Order
+
+	seenTrades    map[int64]struct{}
+	lastTradeTime time.Time
+
+	cancel context.CancelFunc
+}
+
+func NewStream(exchange *Exchange, pollInterval time.Duration) *Stream {
+	if pollInterval == 0 {
+		pollInterval = defaultPollInterval
+	}
+
+	return &Stream{
+		StandardStream: types.StandardStream{
+			ReconnectC: make(chan struct{}, 1),
+		},
+		exchange:     exchange,
+		pollInterval: pollInterval,
+		lastKLines:   make(map[types.Subscription]types.KLine),
+		openOrders:   make(map[uint64]types.Order),
+		seenTrades:   make(map[int64]struct{}),
+	}
+}
+
+func (s *Stream) SetPublicOnly() {
+	s.publicOnly = true
+}
+
+func (s *Stream) Connect(ctx context.Context) error {
+	if err := s.exchange.init(ctx); err != nil {
+		return err
+	}
+
+	for _, sub := range s.Subscriptions {
+		if sub.Channel != types.KLineChannel {
+			log.Warnf("channel %s is not supported by the ccxt polling stream, subscription %s is ignored", sub.Channel, sub.Symbol)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+
+	s.lastTradeTime = time.Now()
+
+	if !s.publicOnly {
+		balances, err := s.exchange.QueryAccountBalances(ctx)
+		if err != nil {
+			cancel()
+			return err
+		}
+
+		openOrders, err := s.exchange.QueryOpenOrders(ctx, "")
+		if err != nil {
+			log.WithError(err).Warn("can not query the open orders, the order updates will be emitted from the next poll")
+		}
+
+		for _, order := range openOrders {
+			s.openOrders[order.OrderID] = order
+		}
+
+		s.EmitConnect()
+		s.EmitBalanceSnapshot(balances)
+	} else {
+		s.EmitConnect()
+	}
+
+	s.EmitStart()
+
+	go s.pollWorker(ctx)
+	return nil
+}
+
+func (s *Stream) Close() error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	s.EmitDisconnect()
+	return nil
+}
+
+func (s *Stream) pollWorker(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			if s.publicOnly {
+				s.pollKLines(ctx)
+			} else {
+				s.pollUserData(ctx)
+			}
+		}
+	}
+}
+
+func (s *Stream) pollKLines(ctx context.Context) {
+	for _, sub := range s.Subscriptions {
+		if sub.Channel != types.KLineChannel {
+			continue
+		}
+
+		interval := types.Interval(sub.Options.Interval)
+		klines, err := s.exchange.QueryKLines(ctx, sub.Symbol, interval, types.KLineQueryOptions{Limit: 2})
+		if err != nil {
+			log.WithError(err).Errorf("can not poll %s %s klines", sub.Symbol, interval)
+			continue
+		}
+
+		s.handleKLines(sub, klines)
+	}
+}
+
+// handleKLines emits the kline updates, the previous kline is closed when a kline with a newer start time is received
+func (s *Stream) handleKLines(sub types.Subscription, klines []types.KLine) {
+	for _, kline := range klines {
+		kline.Closed = false
+
+		last, ok := s.lastKLines[sub]
+		if ok && kline.StartTime.Before(last.StartTime) {
+			continue
+		}
+
+		if ok && kline.StartTime.After(last.StartTime) {
+			last.Closed = true
+			s.EmitKLineClosed(last)
+		}
+
+		s.EmitKLine(kline)
+		s.lastKLines[sub] = kline
+	}
+}
+
+func (s *Stream) pollUserData(ctx context.Context) {
+	trades, err := s.exchange.queryAllTrades(ctx, s.lastTradeTime)
+	if err != nil {
+		log.WithError(err).Error("can not poll the trades")
+	} else {
+		s.handleTrades(trades)
+	}
+
+	openOrders, err := s.exchange.QueryOpenOrders(ctx, "")
+	if err != nil {
+		log.WithError(err).Error("can not poll the open orders")
+		return
+	}
+
+	closedOrderIDs := s.handleOpenOrders(openOrders)
+	if len(closedOrderIDs) > 0 {
+		closedOrders, err := s.exchange.queryAllClosedOrders(ctx, s.lastTradeTime.Add(-time.Hour))
+		if err != nil {
+			log.WithError(err).Error("can not poll the closed orders")
+		}
+
+		for _, order := range closedOrders {
+			if _, ok := closedOrderIDs[order.OrderID]; ok {
+				s.EmitOrderUpdate(order)
+			}
+		}
+	}
+
+	if len(trades) > 0 || len(closedOrderIDs) > 0 {
+		balances, err := s.exchange.QueryAccountBalances(ctx)
+		if err != nil {
+			log.WithError(err).Error("can not poll the balances")
+			return
+		}
+
+		s.EmitBalanceUpdate(balances)
+	}
+}
+
+func (s *Stream) handleTrades(trades []types.Trade) {
+	for _, trade := range trades {
+		if _, ok := s.seenTrades[trade.ID]; ok {
+			continue
+		}
+
+		s.seenTrades[trade.ID] = struct{}{}
+		if trade.Time.Time().After(s.lastTradeTime) {
+			s.lastTradeTime = trade.Time.Time()
+		}
+
+		s.EmitTradeUpdate(trade)
+	}
+}
+
+// handleOpenOrders emits the updates of the new and the changed open orders,
+// and returns the ids of the orders that are no longer open.
+func (s *Stream) handleOpenOrders(orders []types.Order) map[uint64]struct{} {
+	var openOrders = make(map[uint64]types.Order, len(orders))
+	for _, order := range orders {
+		openOrders[order.OrderID] = order
+
+		previous, ok := s.openOrders[order.OrderID]
+		if !ok || previous.ExecutedQuantity != order.ExecutedQuantity || previous.Status != order.Status {
+			s.EmitOrderUpdate(order)
+		}
+	}
+
+	var closedOrderIDs = make(map[uint64]struct{})
+	for orderID := range s.openOrders {
+		if _, ok := openOrders[orderID]; !ok {
+			closedOrderIDs[orderID] = struct{}{}
+		}
+	}
+
+	s.openOrders = openOrders
+	return closedOrderIDs
+}
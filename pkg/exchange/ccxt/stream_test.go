@@ -0,0 +1,66 @@
+package ccxt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestStream_handleKLines(t *testing.T) {
+	stream := NewStream(nil, 0)
+
+	var klines, closedKLines []types.KLine
+	stream.OnKLine(func(kline types.KLine) {
+		klines = append(klines, kline)
+	})
+	stream.OnKLineClosed(func(kline types.KLine) {
+		closedKLines = append(closedKLines, kline)
+	})
+
+	sub := types.Subscription{Channel: types.KLineChannel, Symbol: "BTCUSDT", Options: types.SubscribeOptions{Interval: "1m"}}
+	t0 := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	stream.handleKLines(sub, []types.KLine{{StartTime: t0, Close: 1.0}})
+	stream.handleKLines(sub, []types.KLine{{StartTime: t0, Close: 2.0}, {StartTime: t0.Add(time.Minute), Close: 3.0}})
+
+	assert.Len(t, klines, 3)
+	if assert.Len(t, closedKLines, 1) {
+		assert.Equal(t, 2.0, closedKLines[0].Close)
+		assert.True(t, closedKLines[0].Closed)
+	}
+}
+
+func TestStream_handleOpenOrders(t *testing.T) {
+	stream := NewStream(nil, 0)
+
+	var updates []types.Order
+	stream.OnOrderUpdate(func(order types.Order) {
+		updates = append(updates, order)
+	})
+
+	closed := stream.handleOpenOrders([]types.Order{{OrderID: 1}, {OrderID: 2}})
+	assert.Len(t, updates, 2)
+	assert.Len(t, closed, 0)
+
+	closed = stream.handleOpenOrders([]types.Order{{OrderID: 1, ExecutedQuantity: 0.5}})
+	assert.Len(t, updates, 3)
+	assert.Equal(t, map[uint64]struct{}{2: {}}, closed)
+}
+
+func TestStream_handleTrades(t *testing.T) {
+	stream := NewStream(nil, 0)
+
+	var trades []types.Trade
+	stream.OnTradeUpdate(func(trade types.Trade) {
+		trades = append(trades, trade)
+	})
+
+	trade := types.Trade{ID: 1, Time: types.Time(time.Now().Add(time.Minute))}
+	stream.handleTrades([]types.Trade{trade})
+	stream.handleTrades([]types.Trade{trade})
+	assert.Len(t, trades, 1)
+	assert.Equal(t, trade.Time.Time(), stream.lastTradeTime)
+}
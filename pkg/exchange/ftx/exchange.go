@@ -101,6 +101,19 @@ func (e *Exchange) PlatformFeeCurrency() string {
 	return toGlobalCurrency("FTT")
 }
 
+func (e *Exchange) Capabilities() types.ExchangeCapabilities {
+	return types.ExchangeCapabilities{
+		WebSocketKLines: true,
+		WebSocketBook:   true,
+		OrderTypes: []types.OrderType{
+			types.OrderTypeLimit,
+			types.OrderTypeLimitMaker,
+			types.OrderTypeMarket,
+		},
+		TimeInForces: []string{types.TimeInForceGTC, types.TimeInForceIOC},
+	}
+}
+
 func (e *Exchange) NewStream() types.Stream {
 	return NewStream(e.key, e.secret, e.subAccount, e)
 }
@@ -426,11 +439,17 @@ func (e *Exchange) QueryDepositHistory(ctx context.Context, asset string, since,
 func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
 	var createdOrders types.OrderSlice
 	// TODO: currently only support limit and market order
-	// TODO: support time in force
 	for _, so := range orders {
-		if so.TimeInForce != "GTC" && so.TimeInForce != "" {
-			return createdOrders, fmt.Errorf("unsupported TimeInForce %s. only support GTC", so.TimeInForce)
+		if so.TimeInForce != types.TimeInForceGTC && so.TimeInForce != types.TimeInForceIOC && so.TimeInForce != "" {
+			return createdOrders, fmt.Errorf("unsupported TimeInForce %s. only support GTC and IOC", so.TimeInForce)
 		}
+
+		// the post-only mode is a flag of the limit order on ftx
+		orderType := so.Type
+		if so.IsPostOnly() {
+			orderType = types.OrderTypeLimit
+		}
+
 		if err := requestLimit.Wait(ctx); err != nil {
 			logrus.WithError(err).Error("rate limit error")
 		}
@@ -438,11 +457,11 @@ func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder
 			Market:     toLocalSymbol(TrimUpperString(so.Symbol)),
 			Side:       TrimLowerString(string(so.Side)),
 			Price:      so.Price,
-			Type:       TrimLowerString(string(so.Type)),
+			Type:       TrimLowerString(string(orderType)),
 			Size:       so.Quantity,
 			ReduceOnly: false,
-			IOC:        false,
-			PostOnly:   false,
+			IOC:        so.TimeInForce == types.TimeInForceIOC,
+			PostOnly:   so.IsPostOnly(),
 			ClientID:   newSpotClientOrderID(so.ClientOrderID),
 		})
 		if err != nil {
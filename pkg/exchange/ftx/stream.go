@@ -47,8 +47,24 @@ func NewStream(key, secret string, subAccount string, e *Exchange) *Stream {
 		ws:             service.NewWebsocketClientBase(endpoint, 3*time.Second),
 	}
 
-	s.ws.OnMessage((&messageHandler{StandardStream: s.StandardStream}).handleMessage)
+	handler := &messageHandler{StandardStream: s.StandardStream}
+	handler.resubscribe = func(market string) {
+		for _, op := range []operation{unsubscribe, subscribe} {
+			if err := s.ws.Conn().WriteJSON(websocketRequest{
+				Operation: op,
+				Channel:   orderBookChannel,
+				Market:    market,
+			}); err != nil {
+				logger.WithError(err).Errorf("failed to %s the %s orderbook", op, market)
+			}
+		}
+	}
+
+	s.ws.OnMessage(handler.handleMessage)
 	s.ws.OnConnected(func(conn *websocket.Conn) {
+		// the order book snapshots will be pushed again after subscribing
+		handler.resetOrderBooks()
+
 		subs := []websocketRequest{newLoginRequest(s.key, s.secret, time.Now(), s.subAccount)}
 		subs = append(subs, s.subscriptions...)
 		for _, sub := range subs {
@@ -63,6 +79,12 @@ func NewStream(key, secret string, subAccount string, e *Exchange) *Stream {
 	return s
 }
 
+// SetCompression enables the permessage-deflate compression of the websocket connection
+func (s *Stream) SetCompression(enabled bool) {
+	s.StandardStream.SetCompression(enabled)
+	s.ws.SetCompression(enabled)
+}
+
 func (s *Stream) Connect(ctx context.Context) error {
 	// If it's not public only, let's do the authentication.
 	if atomic.LoadInt32(&s.publicOnly) == 0 {
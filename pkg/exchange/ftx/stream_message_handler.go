@@ -8,6 +8,48 @@ import (
 
 type messageHandler struct {
 	*types.StandardStream
+
+	// books are the local order books of the markets, they're used for verifying the checksum of the updates
+	books map[string]*orderBookResponse
+
+	depthBuffers map[string]*types.DepthBuffer
+
+	// resubscribe re-subscribes the order book channel of the market to get a new snapshot
+	resubscribe func(market string)
+}
+
+// depthBuffer returns the depth buffer of the market, FTX pushes the snapshot (the partial message) over the websocket,
+// so the buffer has no snapshot fetcher.
+func (h *messageHandler) depthBuffer(market string) *types.DepthBuffer {
+	if h.depthBuffers == nil {
+		h.depthBuffers = make(map[string]*types.DepthBuffer)
+	}
+
+	if buffer, ok := h.depthBuffers[market]; ok {
+		return buffer
+	}
+
+	buffer := types.NewDepthBuffer(market, nil)
+	buffer.OnReady(func(snapshot types.SliceOrderBook, updates []types.DepthUpdate) {
+		h.EmitBookSnapshot(snapshot)
+		for _, u := range updates {
+			h.EmitBookUpdate(u.Book)
+		}
+	})
+	buffer.OnPush(func(update types.DepthUpdate) {
+		h.EmitBookUpdate(update.Book)
+	})
+
+	h.depthBuffers[market] = buffer
+	return buffer
+}
+
+// resetOrderBooks resets the local order books, it's called when the connection is lost
+func (h *messageHandler) resetOrderBooks() {
+	h.books = nil
+	for _, buffer := range h.depthBuffers {
+		buffer.Reset()
+	}
 }
 
 func (h *messageHandler) handleMessage(message []byte) {
@@ -65,16 +107,47 @@ func (h *messageHandler) handleOrderBook(response websocketResponse) {
 		return
 	}
 
+	buffer := h.depthBuffer(r.Market)
+
 	switch r.Type {
 	case partialRespType:
 		if err := r.verifyChecksum(); err != nil {
 			logger.WithError(err).Errorf("invalid orderbook snapshot")
 			return
 		}
-		h.EmitBookSnapshot(globalOrderBook)
+
+		if h.books == nil {
+			h.books = make(map[string]*orderBookResponse)
+		}
+
+		book := r
+		h.books[r.Market] = &book
+
+		if err := buffer.SetSnapshot(globalOrderBook, 0); err != nil {
+			logger.WithError(err).Errorf("failed to set the orderbook snapshot")
+		}
+
 	case updateRespType:
+		// verify the update against the local order book, a checksum mismatch means some updates are missing
+		if book, ok := h.books[r.Market]; ok {
+			book.update(r)
+			if err := book.verifyChecksum(); err != nil {
+				logger.WithError(err).Errorf("%s orderbook is out of sync, resubscribing", r.Market)
+				delete(h.books, r.Market)
+				buffer.Reset()
+
+				if h.resubscribe != nil {
+					h.resubscribe(r.Market)
+				}
+				return
+			}
+		}
+
 		// emit updates, not the whole orderbook
-		h.EmitBookUpdate(globalOrderBook)
+		if err := buffer.AddUpdate(types.DepthUpdate{Book: globalOrderBook}); err != nil {
+			logger.WithError(err).Errorf("failed to add the orderbook update")
+		}
+
 	default:
 		logger.Errorf("unsupported order book data type %s", r.Type)
 		return
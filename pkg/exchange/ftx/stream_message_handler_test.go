@@ -2,6 +2,7 @@ package ftx
 
 import (
 	"database/sql"
+	"io/ioutil"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -115,4 +116,36 @@ func Test_messageHandler_handleMessage(t *testing.T) {
 		h.handleMessage(input)
 		assert.Equal(t, 1, i)
 	})
+	t.Run("handle orderbook snapshot and out-of-sync update", func(t *testing.T) {
+		snapshot, err := ioutil.ReadFile("./orderbook_snapshot.json")
+		assert.NoError(t, err)
+
+		// the update is not the next update of the snapshot, so the checksum mismatches
+		update, err := ioutil.ReadFile("./orderbook_update.json")
+		assert.NoError(t, err)
+
+		var resubscribed []string
+		h := &messageHandler{StandardStream: &types.StandardStream{}}
+		h.resubscribe = func(market string) {
+			resubscribed = append(resubscribed, market)
+		}
+
+		snapshots, updates := 0, 0
+		h.OnBookSnapshot(func(book types.SliceOrderBook) {
+			snapshots++
+			assert.Equal(t, "BTCUSDT", book.Symbol)
+		})
+		h.OnBookUpdate(func(book types.SliceOrderBook) {
+			updates++
+		})
+
+		h.handleMessage(snapshot)
+		assert.Equal(t, 1, snapshots)
+		assert.True(t, h.depthBuffer("BTC/USDT").Ready())
+
+		h.handleMessage(update)
+		assert.Equal(t, 0, updates)
+		assert.Equal(t, []string{"BTC/USDT"}, resubscribed)
+		assert.False(t, h.depthBuffer("BTC/USDT").Ready())
+	})
 }
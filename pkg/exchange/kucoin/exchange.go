@@ -3,6 +3,7 @@ package kucoin
 import (
 	"context"
 
+	"github.com/c9s/bbgo/pkg/exchange/kucoin/kucoinapi"
 	"github.com/c9s/bbgo/pkg/types"
 	"github.com/sirupsen/logrus"
 )
@@ -16,6 +17,18 @@ var log = logrus.WithFields(logrus.Fields{
 
 type Exchange struct {
 	key, secret, passphrase string
+	testnet                 bool
+	client                  *kucoinapi.RestClient
+}
+
+// UseTestnet switches the client to the kucoin sandbox
+func (e *Exchange) UseTestnet() {
+	e.testnet = true
+	e.client.UseSandbox()
+}
+
+func (e *Exchange) IsTestnet() bool {
+	return e.testnet
 }
 
 func (e *Exchange) NewStream() types.Stream {
@@ -39,9 +52,13 @@ func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval type
 }
 
 func New(key, secret, passphrase string) *Exchange {
+	client := kucoinapi.NewClient()
+	client.Auth(key, secret, passphrase)
+
 	return &Exchange{
 		key:        key,
 		secret:     secret,
 		passphrase: passphrase,
+		client:     client,
 	}
 }
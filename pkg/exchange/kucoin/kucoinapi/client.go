@@ -117,6 +117,17 @@ func (c *RestClient) Auth(key, secret, passphrase string) {
 	c.Passphrase = passphrase
 }
 
+// UseSandbox switches the base url to the sandbox endpoint,
+// the websocket endpoint is also switched since the websocket token is requested from the rest api.
+func (c *RestClient) UseSandbox() {
+	u, err := url.Parse(SandboxRestBaseURL)
+	if err != nil {
+		panic(err)
+	}
+
+	c.BaseURL = u
+}
+
 // NewRequest create new API request. Relative url can be provided in refURL.
 func (c *RestClient) newRequest(method, refURL string, params url.Values, body []byte) (*http.Request, error) {
 	rel, err := url.Parse(refURL)
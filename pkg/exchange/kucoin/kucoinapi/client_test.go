@@ -0,0 +1,15 @@
+package kucoinapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestClient_UseSandbox(t *testing.T) {
+	client := NewClient()
+	assert.Equal(t, RestBaseURL, client.BaseURL.String())
+
+	client.UseSandbox()
+	assert.Equal(t, SandboxRestBaseURL, client.BaseURL.String())
+}
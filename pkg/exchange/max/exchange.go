@@ -26,6 +26,9 @@ var marketDataLimiter = rate.NewLimiter(rate.Every(2*time.Second), 10)
 
 var log = logrus.WithField("exchange", "max")
 
+// maxMultiOrderSize is the max number of the orders in one multi order request
+const maxMultiOrderSize = 14
+
 type Exchange struct {
 	client      *maxapi.RestClient
 	key, secret string
@@ -303,7 +306,14 @@ func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) (err
 
 func toMaxSubmitOrder(o types.SubmitOrder) (*maxapi.Order, error) {
 	symbol := toLocalSymbol(o.Symbol)
-	orderType, err := toLocalOrderType(o.Type)
+
+	// the post-only and the IOC limit orders are separate order types on MAX
+	submitType := o.EffectiveType()
+	if submitType == types.OrderTypeLimit && o.TimeInForce == types.TimeInForceIOC {
+		submitType = types.OrderTypeIOCLimit
+	}
+
+	orderType, err := toLocalOrderType(submitType)
 	if err != nil {
 		return nil, err
 	}
@@ -411,7 +421,7 @@ func (e *Exchange) Withdrawal(ctx context.Context, asset string, amount fixedpoi
 }
 
 func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
-	if len(orders) > 1 && len(orders) < 15 {
+	if len(orders) > 1 {
 		var ordersBySymbol = map[string][]maxapi.Order{}
 		for _, o := range orders {
 			maxOrder, err := toMaxSubmitOrder(o)
@@ -423,27 +433,35 @@ func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder
 		}
 
 		for symbol, orders := range ordersBySymbol {
-			req := e.client.OrderService.NewCreateMultiOrderRequest()
-			req.Market(symbol)
-			req.AddOrders(orders...)
-
-			orderResponses, err := req.Do(ctx)
-			if err != nil {
-				return createdOrders, err
-			}
-
-			for _, resp := range *orderResponses {
-				if len(resp.Error) > 0 {
-					log.Errorf("multi-order submit error: %s", resp.Error)
-					continue
+			// the orders of a market are chunked by the max size of the multi order request
+			for offset := 0; offset < len(orders); offset += maxMultiOrderSize {
+				end := offset + maxMultiOrderSize
+				if end > len(orders) {
+					end = len(orders)
 				}
 
-				o, err := toGlobalOrder(resp.Order)
+				req := e.client.OrderService.NewCreateMultiOrderRequest()
+				req.Market(symbol)
+				req.AddOrders(orders[offset:end]...)
+
+				orderResponses, err := req.Do(ctx)
 				if err != nil {
 					return createdOrders, err
 				}
 
-				createdOrders = append(createdOrders, *o)
+				for _, resp := range *orderResponses {
+					if len(resp.Error) > 0 {
+						log.Errorf("multi-order submit error: %s", resp.Error)
+						continue
+					}
+
+					o, err := toGlobalOrder(resp.Order)
+					if err != nil {
+						return createdOrders, err
+					}
+
+					createdOrders = append(createdOrders, *o)
+				}
 			}
 		}
 
@@ -498,6 +516,24 @@ func (e *Exchange) PlatformFeeCurrency() string {
 	return toGlobalCurrency("max")
 }
 
+func (e *Exchange) Capabilities() types.ExchangeCapabilities {
+	return types.ExchangeCapabilities{
+		BatchOrders:     true,
+		WebSocketKLines: true,
+		WebSocketBook:   true,
+		Withdrawal:      true,
+		OrderTypes: []types.OrderType{
+			types.OrderTypeLimit,
+			types.OrderTypeLimitMaker,
+			types.OrderTypeMarket,
+			types.OrderTypeStopLimit,
+			types.OrderTypeStopMarket,
+			types.OrderTypeIOCLimit,
+		},
+		TimeInForces: []string{types.TimeInForceGTC, types.TimeInForceIOC},
+	}
+}
+
 func (e *Exchange) getLaunchDate() (time.Time, error) {
 	// MAX launch date June 21th, 2018
 	loc, err := time.LoadLocation("Asia/Taipei")
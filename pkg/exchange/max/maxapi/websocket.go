@@ -11,6 +11,8 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
 )
 
 var WebSocketURL = "wss://max-stream.maicoin.com/ws"
@@ -50,6 +52,9 @@ type WebSocketService struct {
 
 	reconnectC chan struct{}
 
+	// compression enables the permessage-deflate compression negotiation
+	compression bool
+
 	// Subscriptions is the subscription request payloads that will be used for sending subscription request
 	Subscriptions []Subscription
 
@@ -136,8 +141,13 @@ func (s *WebSocketService) Auth() error {
 	return s.conn.WriteJSON(auth)
 }
 
+// SetCompression enables the permessage-deflate compression, it must be called before connecting
+func (s *WebSocketService) SetCompression(enabled bool) {
+	s.compression = enabled
+}
+
 func (s *WebSocketService) connect(ctx context.Context) error {
-	dialer := websocket.DefaultDialer
+	dialer := types.NewDialer(s.compression)
 	conn, _, err := dialer.DialContext(ctx, s.baseURL, nil)
 	if err != nil {
 		return err
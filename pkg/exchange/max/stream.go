@@ -174,6 +174,12 @@ func (s *Stream) Subscribe(channel types.Channel, symbol string, options types.S
 	s.websocketService.Subscribe(string(channel), toLocalSymbol(symbol), opt)
 }
 
+// SetCompression enables the permessage-deflate compression of the websocket connection
+func (s *Stream) SetCompression(enabled bool) {
+	s.StandardStream.SetCompression(enabled)
+	s.websocketService.SetCompression(enabled)
+}
+
 func (s *Stream) Connect(ctx context.Context) error {
 	err := s.websocketService.Connect(ctx)
 	if err != nil {
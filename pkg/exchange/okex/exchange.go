@@ -16,6 +16,9 @@ import (
 // OKB is the platform currency of OKEx, pre-allocate static string here
 const OKB = "OKB"
 
+// maxBatchOrderSize is the max number of the orders in one batch place order or batch cancel order request
+const maxBatchOrderSize = 20
+
 var log = logrus.WithFields(logrus.Fields{
 	"exchange": "okex",
 })
@@ -131,6 +134,20 @@ func (e *Exchange) PlatformFeeCurrency() string {
 	return OKB
 }
 
+func (e *Exchange) Capabilities() types.ExchangeCapabilities {
+	return types.ExchangeCapabilities{
+		BatchOrders:     true,
+		WebSocketKLines: true,
+		WebSocketBook:   true,
+		OrderTypes: []types.OrderType{
+			types.OrderTypeLimit,
+			types.OrderTypeLimitMaker,
+			types.OrderTypeMarket,
+		},
+		TimeInForces: []string{types.TimeInForceGTC, types.TimeInForceIOC, types.TimeInForceFOK},
+	}
+}
+
 func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
 	accountBalance, err := e.client.AccountBalances()
 	if err != nil {
@@ -157,11 +174,23 @@ func (e *Exchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap,
 }
 
 func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	for _, batch := range types.SubmitOrderBatches(orders, maxBatchOrderSize) {
+		created, err := e.submitOrderBatch(ctx, batch)
+		createdOrders = append(createdOrders, created...)
+		if err != nil {
+			return createdOrders, err
+		}
+	}
+
+	return createdOrders, nil
+}
+
+func (e *Exchange) submitOrderBatch(ctx context.Context, orders []types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
 	var reqs []*okexapi.PlaceOrderRequest
 	for _, order := range orders {
 		orderReq := e.client.TradeService.NewPlaceOrderRequest()
 
-		orderType, err := toLocalOrderType(order.Type)
+		orderType, err := toLocalOrderType(order.EffectiveType())
 		if err != nil {
 			return nil, err
 		}
@@ -179,7 +208,7 @@ func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder
 
 		// set price field for limit orders
 		switch order.Type {
-		case types.OrderTypeStopLimit, types.OrderTypeLimit:
+		case types.OrderTypeStopLimit, types.OrderTypeLimit, types.OrderTypeLimitMaker:
 			if len(order.PriceString) > 0 {
 				orderReq.Price(order.PriceString)
 			} else if order.Market.Symbol != "" {
@@ -187,10 +216,13 @@ func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder
 			}
 		}
 
-		switch order.TimeInForce {
-		case "FOK":
+		// okex models the time in force and the post-only mode as the order types
+		switch {
+		case orderType == okexapi.OrderTypePostOnly:
+			orderReq.OrderType(orderType)
+		case order.TimeInForce == types.TimeInForceFOK:
 			orderReq.OrderType(okexapi.OrderTypeFOK)
-		case "IOC":
+		case order.TimeInForce == types.TimeInForceIOC:
 			orderReq.OrderType(okexapi.OrderTypeIOC)
 		default:
 			orderReq.OrderType(orderType)
@@ -247,25 +279,30 @@ func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) erro
 		return nil
 	}
 
-	var reqs []*okexapi.CancelOrderRequest
-	for _, order := range orders {
-		if len(order.Symbol) == 0 {
-			return errors.New("symbol is required for canceling an okex order")
+	for _, batch := range types.OrderBatches(orders, maxBatchOrderSize) {
+		var reqs []*okexapi.CancelOrderRequest
+		for _, order := range batch {
+			if len(order.Symbol) == 0 {
+				return errors.New("symbol is required for canceling an okex order")
+			}
+
+			req := e.client.TradeService.NewCancelOrderRequest()
+			req.InstrumentID(toLocalSymbol(order.Symbol))
+			req.OrderID(strconv.FormatUint(order.OrderID, 10))
+			if len(order.ClientOrderID) > 0 {
+				req.ClientOrderID(order.ClientOrderID)
+			}
+			reqs = append(reqs, req)
 		}
 
-		req := e.client.TradeService.NewCancelOrderRequest()
-		req.InstrumentID(toLocalSymbol(order.Symbol))
-		req.OrderID(strconv.FormatUint(order.OrderID, 10))
-		if len(order.ClientOrderID) > 0 {
-			req.ClientOrderID(order.ClientOrderID)
+		batchReq := e.client.TradeService.NewBatchCancelOrderRequest()
+		batchReq.Add(reqs...)
+		if _, err := batchReq.Do(ctx); err != nil {
+			return err
 		}
-		reqs = append(reqs, req)
 	}
 
-	batchReq := e.client.TradeService.NewBatchCancelOrderRequest()
-	batchReq.Add(reqs...)
-	_, err := batchReq.Do(ctx)
-	return err
+	return nil
 }
 
 func (e *Exchange) NewStream() types.Stream {
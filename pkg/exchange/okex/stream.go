@@ -2,17 +2,14 @@ package okex
 
 import (
 	"context"
-	"net"
 	"strconv"
-	"sync"
 	"time"
 
 	"github.com/c9s/bbgo/pkg/exchange/okex/okexapi"
 	"github.com/c9s/bbgo/pkg/types"
-	"github.com/gorilla/websocket"
 )
 
-const readTimeout = 15 * time.Second
+const heartbeatInterval = 7500 * time.Millisecond
 
 type WebsocketOp struct {
 	Op   string      `json:"op"`
@@ -30,11 +27,7 @@ type WebsocketLogin struct {
 type Stream struct {
 	types.StandardStream
 
-	Client     *okexapi.RestClient
-	Conn       *websocket.Conn
-	connLock   sync.Mutex
-	connCtx    context.Context
-	connCancel context.CancelFunc
+	Client *okexapi.RestClient
 
 	publicOnly bool
 
@@ -55,11 +48,9 @@ type CandleKey struct {
 
 func NewStream(client *okexapi.RestClient) *Stream {
 	stream := &Stream{
-		Client: client,
-		StandardStream: types.StandardStream{
-			ReconnectC: make(chan struct{}, 1),
-		},
-		lastCandle: make(map[CandleKey]Candle),
+		Client:         client,
+		StandardStream: types.NewStandardStream(),
+		lastCandle:     make(map[CandleKey]Candle),
 	}
 
 	stream.OnCandleData(func(candle Candle) {
@@ -126,7 +117,7 @@ func NewStream(client *okexapi.RestClient) *Stream {
 				}
 
 				log.Infof("subscribing private channels: %+v", subs)
-				err := stream.Conn.WriteJSON(WebsocketOp{
+				err := stream.WriteJSON(WebsocketOp{
 					Op:   "subscribe",
 					Args: subs,
 				})
@@ -138,58 +129,11 @@ func NewStream(client *okexapi.RestClient) *Stream {
 		}
 	})
 
-	stream.OnConnect(func() {
-		if stream.publicOnly {
-			var subs []WebsocketSubscription
-			for _, subscription := range stream.Subscriptions {
-				sub, err := convertSubscription(subscription)
-				if err != nil {
-					log.WithError(err).Errorf("subscription convert error")
-					continue
-				}
-
-				subs = append(subs, sub)
-			}
-			if len(subs) == 0 {
-				return
-			}
-
-			log.Infof("subscribing channels: %+v", subs)
-			err := stream.Conn.WriteJSON(WebsocketOp{
-				Op:   "subscribe",
-				Args: subs,
-			})
-
-			if err != nil {
-				log.WithError(err).Error("subscribe error")
-			}
-		} else {
-			// login as private channel
-			// sign example:
-			// sign=CryptoJS.enc.Base64.Stringify(CryptoJS.HmacSHA256(timestamp +'GET'+'/users/self/verify', secretKey))
-			msTimestamp := strconv.FormatFloat(float64(time.Now().UnixNano())/float64(time.Second), 'f', -1, 64)
-			payload := msTimestamp + "GET" + "/users/self/verify"
-			sign := okexapi.Sign(payload, stream.Client.Secret)
-			op := WebsocketOp{
-				Op: "login",
-				Args: []WebsocketLogin{
-					{
-						Key:        stream.Client.Key,
-						Passphrase: stream.Client.Passphrase,
-						Timestamp:  msTimestamp,
-						Sign:       sign,
-					},
-				},
-			}
-
-			log.Infof("sending login request: %+v", op)
-			err := stream.Conn.WriteJSON(op)
-			if err != nil {
-				log.WithError(err).Errorf("can not send login message")
-			}
-		}
-	})
-
+	stream.SetEndpointCreator(stream.createEndpoint)
+	stream.SetParser(stream.parseMessage)
+	stream.SetDispatcher(stream.dispatchEvent)
+	stream.SetSubscriptionBuilder(stream.buildSubscriptions)
+	stream.SetHeartbeat(heartbeatInterval, nil)
 	return stream
 }
 
@@ -197,189 +141,86 @@ func (s *Stream) SetPublicOnly() {
 	s.publicOnly = true
 }
 
-func (s *Stream) Close() error {
-	return nil
-}
-
-func (s *Stream) Connect(ctx context.Context) error {
-	err := s.connect(ctx)
-	if err != nil {
-		return err
+func (s *Stream) createEndpoint(ctx context.Context) (string, error) {
+	if s.publicOnly {
+		return okexapi.PublicWebSocketURL, nil
 	}
-
-	// start one re-connector goroutine with the base context
-	go s.Reconnector(ctx)
-
-	s.EmitStart()
-	return nil
+	return okexapi.PrivateWebSocketURL, nil
 }
 
-func (s *Stream) Reconnector(ctx context.Context) {
-	for {
-		select {
-		case <-ctx.Done():
-			return
-
-		case <-s.ReconnectC:
-			log.Warnf("received reconnect signal, reconnecting...")
-			time.Sleep(3 * time.Second)
-
-			if err := s.connect(ctx); err != nil {
-				log.WithError(err).Errorf("connect error, try to reconnect again...")
-				s.Reconnect()
-			}
-		}
-	}
+func (s *Stream) parseMessage(message []byte) (interface{}, error) {
+	return Parse(string(message))
 }
 
-func (s *Stream) connect(ctx context.Context) error {
-	// when in public mode, the listen key is an empty string
-	var url string
-	if s.publicOnly {
-		url = okexapi.PublicWebSocketURL
-	} else {
-		url = okexapi.PrivateWebSocketURL
-	}
+func (s *Stream) dispatchEvent(e interface{}) {
+	switch et := e.(type) {
+	case *WebSocketEvent:
+		s.EmitEvent(*et)
 
+	case *BookData:
+		s.EmitBookData(*et)
 
-	conn, err := s.StandardStream.Dial(url)
-	if err != nil {
-		return err
-	}
+	case *Candle:
+		s.EmitCandleData(*et)
 
-	log.Infof("websocket connected: %s", url)
+	case *okexapi.Account:
+		s.EmitAccount(*et)
 
-	// should only start one connection one time, so we lock the mutex
-	s.connLock.Lock()
+	case []okexapi.OrderDetails:
+		s.EmitOrderDetails(et)
 
-	// ensure the previous context is cancelled
-	if s.connCancel != nil {
-		s.connCancel()
 	}
-
-	// create a new context
-	s.connCtx, s.connCancel = context.WithCancel(ctx)
-
-	conn.SetReadDeadline(time.Now().Add(readTimeout))
-	conn.SetPongHandler(func(string) error {
-		conn.SetReadDeadline(time.Now().Add(readTimeout))
-		return nil
-	})
-
-	s.Conn = conn
-	s.connLock.Unlock()
-
-	s.EmitConnect()
-
-	go s.read(s.connCtx)
-	go s.ping(s.connCtx)
-	return nil
 }
 
-func (s *Stream) read(ctx context.Context) {
-	defer func() {
-		if s.connCancel != nil {
-			s.connCancel()
-		}
-		s.EmitDisconnect()
-	}()
-
-	for {
-		select {
-
-		case <-ctx.Done():
-			return
-
-		default:
-			s.connLock.Lock()
-			conn := s.Conn
-			s.connLock.Unlock()
-
-			if err := conn.SetReadDeadline(time.Now().Add(readTimeout)); err != nil {
-				log.WithError(err).Errorf("set read deadline error: %s", err.Error())
-			}
-
-			mt, message, err := conn.ReadMessage()
-			if err != nil {
-				// if it's a network timeout error, we should re-connect
-				switch err := err.(type) {
-
-				// if it's a websocket related error
-				case *websocket.CloseError:
-					if err.Code == websocket.CloseNormalClosure {
-						return
-					}
-
-					// for unexpected close error, we should re-connect
-					// emit reconnect to start a new connection
-					s.Reconnect()
-					return
-
-				case net.Error:
-					log.WithError(err).Error("network error")
-					s.Reconnect()
-					return
-
-				default:
-					log.WithError(err).Error("unexpected connection error")
-					s.Reconnect()
-					return
-				}
-			}
-
-			// skip non-text messages
-			if mt != websocket.TextMessage {
-				continue
-			}
-
-			e, err := Parse(string(message))
-			if err != nil {
-				log.WithError(err).Error("message parse error")
-			}
-
-			if e != nil {
-				switch et := e.(type) {
-				case *WebSocketEvent:
-					s.EmitEvent(*et)
-
-				case *BookData:
-					s.EmitBookData(*et)
-
-				case *Candle:
-					s.EmitCandleData(*et)
-
-				case *okexapi.Account:
-					s.EmitAccount(*et)
-
-				case []okexapi.OrderDetails:
-					s.EmitOrderDetails(et)
-
-				}
-			}
-		}
+// buildSubscriptions builds the subscribe message of the public channels,
+// or the login message of the private channels, the private channels are subscribed after the login event is received.
+func (s *Stream) buildSubscriptions(subscriptions []types.Subscription) ([]interface{}, error) {
+	if !s.publicOnly {
+		return []interface{}{s.loginOp()}, nil
 	}
-}
 
-func (s *Stream) ping(ctx context.Context) {
-	pingTicker := time.NewTicker(readTimeout / 2)
-	defer pingTicker.Stop()
+	var subs []WebsocketSubscription
+	for _, subscription := range subscriptions {
+		sub, err := convertSubscription(subscription)
+		if err != nil {
+			log.WithError(err).Errorf("subscription convert error")
+			continue
+		}
 
-	for {
-		select {
+		subs = append(subs, sub)
+	}
 
-		case <-ctx.Done():
-			log.Debug("ping worker stopped")
-			return
+	if len(subs) == 0 {
+		return nil, nil
+	}
 
-		case <-pingTicker.C:
-			s.connLock.Lock()
-			conn := s.Conn
-			s.connLock.Unlock()
+	log.Infof("subscribing channels: %+v", subs)
+	return []interface{}{
+		WebsocketOp{
+			Op:   "subscribe",
+			Args: subs,
+		},
+	}, nil
+}
 
-			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(3*time.Second)); err != nil {
-				log.WithError(err).Error("ping error", err)
-				s.Reconnect()
-			}
-		}
+func (s *Stream) loginOp() WebsocketOp {
+	// login as private channel
+	// sign example:
+	// sign=CryptoJS.enc.Base64.Stringify(CryptoJS.HmacSHA256(timestamp +'GET'+'/users/self/verify', secretKey))
+	msTimestamp := strconv.FormatFloat(float64(time.Now().UnixNano())/float64(time.Second), 'f', -1, 64)
+	payload := msTimestamp + "GET" + "/users/self/verify"
+	sign := okexapi.Sign(payload, s.Client.Secret)
+
+	log.Infof("sending login request with key %s", s.Client.Key)
+	return WebsocketOp{
+		Op: "login",
+		Args: []WebsocketLogin{
+			{
+				Key:        s.Client.Key,
+				Passphrase: s.Client.Passphrase,
+				Timestamp:  msTimestamp,
+				Sign:       sign,
+			},
+		},
 	}
 }
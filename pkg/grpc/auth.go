@@ -0,0 +1,64 @@
+package grpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const tradingServicePrefix = "/bbgo.TradingService/"
+
+// authorize checks the bearer token in the authorization metadata of the call. The trading service is disabled if the
+// token is not set, the other services are open since the server binds to localhost by default.
+func (s *Server) authorize(ctx context.Context, fullMethod string) error {
+	if len(s.Token) == 0 {
+		if strings.HasPrefix(fullMethod, tradingServicePrefix) {
+			return status.Error(codes.PermissionDenied, "the trading service is disabled since the grpc token is not set")
+		}
+
+		return nil
+	}
+
+	const prefix = "Bearer "
+
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, value := range md.Get("authorization") {
+			if strings.HasPrefix(value, prefix) {
+				token = strings.TrimPrefix(value, prefix)
+				break
+			}
+		}
+	}
+
+	if len(token) == 0 {
+		return status.Error(codes.Unauthenticated, "bearer token is required")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) != 1 {
+		return status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	return nil
+}
+
+func (s *Server) unaryAuth(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := s.authorize(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, request)
+}
+
+func (s *Server) streamAuth(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := s.authorize(stream.Context(), info.FullMethod); err != nil {
+		return err
+	}
+
+	return handler(srv, stream)
+}
@@ -0,0 +1,148 @@
+package grpc
+
+import (
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const (
+	subscriberBufferSize = 1024
+	defaultBookDepth     = 20
+)
+
+type subscriber struct {
+	request SubscribeRequest
+	C       chan *MarketDataEvent
+}
+
+func newSubscriber(request SubscribeRequest) *subscriber {
+	if request.Depth <= 0 {
+		request.Depth = defaultBookDepth
+	}
+
+	return &subscriber{request: request, C: make(chan *MarketDataEvent, subscriberBufferSize)}
+}
+
+func (s *subscriber) subscribes(channel MarketDataChannel, symbol string) bool {
+	if s.request.Symbol != symbol {
+		return false
+	}
+
+	if len(s.request.Channels) == 0 {
+		return true
+	}
+
+	for _, c := range s.request.Channels {
+		if c == channel {
+			return true
+		}
+	}
+
+	return false
+}
+
+// marketDataBroker binds the market data stream of the session once, and fans out the events to the subscribers
+type marketDataBroker struct {
+	session *bbgo.ExchangeSession
+
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+}
+
+func newMarketDataBroker(session *bbgo.ExchangeSession) *marketDataBroker {
+	b := &marketDataBroker{
+		session:     session,
+		subscribers: make(map[*subscriber]struct{}),
+	}
+
+	session.MarketDataStream.OnKLineClosed(b.handleKLine)
+	session.MarketDataStream.OnBookSnapshot(b.handleBook)
+	session.MarketDataStream.OnBookUpdate(b.handleBook)
+	session.MarketDataStream.OnMarketTrade(b.handleTrade)
+	return b
+}
+
+func (b *marketDataBroker) Subscribe(request SubscribeRequest) *subscriber {
+	s := newSubscriber(request)
+	b.mu.Lock()
+	b.subscribers[s] = struct{}{}
+	b.mu.Unlock()
+	return s
+}
+
+func (b *marketDataBroker) Unsubscribe(s *subscriber) {
+	b.mu.Lock()
+	delete(b.subscribers, s)
+	b.mu.Unlock()
+}
+
+// publish sends the event to the subscribers of the channel, the event is dropped for the slow subscribers
+func (b *marketDataBroker) publish(channel MarketDataChannel, symbol string, newEvent func(s *subscriber) *MarketDataEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for s := range b.subscribers {
+		if !s.subscribes(channel, symbol) {
+			continue
+		}
+
+		event := newEvent(s)
+		if event == nil {
+			continue
+		}
+
+		select {
+		case s.C <- event:
+		default:
+			log.Warnf("grpc subscriber of %s %s %s is too slow, event dropped", b.session.Name, symbol, channel)
+		}
+	}
+}
+
+func (b *marketDataBroker) handleKLine(kline types.KLine) {
+	b.publish(MarketDataChannelKLine, kline.Symbol, func(s *subscriber) *MarketDataEvent {
+		if s.request.Interval != "" && s.request.Interval != kline.Interval {
+			return nil
+		}
+
+		return &MarketDataEvent{Session: b.session.Name, Channel: MarketDataChannelKLine, Symbol: kline.Symbol, KLine: &kline}
+	})
+}
+
+func (b *marketDataBroker) handleBook(book types.SliceOrderBook) {
+	b.publish(MarketDataChannelBook, book.Symbol, func(s *subscriber) *MarketDataEvent {
+		// send the full book instead of the delta if the session maintains the order book
+		snapshot := book
+		if streamBook, ok := b.session.OrderBook(book.Symbol); ok {
+			snapshot = streamBook.Snapshot(s.request.Depth)
+		}
+
+		return &MarketDataEvent{Session: b.session.Name, Channel: MarketDataChannelBook, Symbol: book.Symbol, Book: newBook(snapshot, s.request.Depth)}
+	})
+}
+
+func (b *marketDataBroker) handleTrade(trade types.Trade) {
+	b.publish(MarketDataChannelTrade, trade.Symbol, func(s *subscriber) *MarketDataEvent {
+		return &MarketDataEvent{Session: b.session.Name, Channel: MarketDataChannelTrade, Symbol: trade.Symbol, Trade: &trade}
+	})
+}
+
+func newBook(book types.SliceOrderBook, depth int) *Book {
+	return &Book{
+		Symbol: book.Symbol,
+		Bids:   newPriceVolumes(book.Bids.CopyDepth(depth)),
+		Asks:   newPriceVolumes(book.Asks.CopyDepth(depth)),
+	}
+}
+
+func newPriceVolumes(slice types.PriceVolumeSlice) []PriceVolume {
+	pvs := make([]PriceVolume, 0, len(slice))
+	for _, pv := range slice {
+		pvs = append(pvs, PriceVolume{Price: pv.Price.Float64(), Volume: pv.Volume.Float64()})
+	}
+	return pvs
+}
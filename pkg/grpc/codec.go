@@ -0,0 +1,34 @@
+package grpc
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// CodecName is the content subtype of the messages, i.e., "application/grpc+json"
+const CodecName = "json"
+
+// jsonCodec encodes the messages in JSON, so that the clients can call the services without the generated protobuf stubs,
+// e.g., a python client can pass json.dumps and json.loads as the request serializer and the response deserializer.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return CodecName
+}
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
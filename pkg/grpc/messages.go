@@ -0,0 +1,130 @@
+package grpc
+
+import (
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type Empty struct{}
+
+type Session struct {
+	Name           string             `json:"name"`
+	Exchange       types.ExchangeName `json:"exchange"`
+	PublicOnly     bool               `json:"publicOnly,omitempty"`
+	Margin         bool               `json:"margin,omitempty"`
+	IsolatedMargin bool               `json:"isolatedMargin,omitempty"`
+	Futures        bool               `json:"futures,omitempty"`
+	Symbols        []string           `json:"symbols"`
+
+	// Connected is false if any stream of the session is disconnected
+	Connected bool   `json:"connected"`
+	Error     string `json:"error,omitempty"`
+}
+
+type QuerySessionsResponse struct {
+	Sessions []Session `json:"sessions"`
+}
+
+type QueryBalancesRequest struct {
+	Session string `json:"session"`
+}
+
+type QueryBalancesResponse struct {
+	Session  string          `json:"session"`
+	Balances []types.Balance `json:"balances"`
+}
+
+type QueryOpenOrdersRequest struct {
+	Session string `json:"session"`
+	Symbol  string `json:"symbol"`
+}
+
+type QueryOpenOrdersResponse struct {
+	Orders []types.Order `json:"orders"`
+}
+
+type QueryPositionsRequest struct {
+	Session string `json:"session"`
+
+	// Symbol is optional, all the positions of the session are returned if it's empty
+	Symbol string `json:"symbol,omitempty"`
+}
+
+type Position struct {
+	Symbol            string  `json:"symbol"`
+	BaseCurrency      string  `json:"baseCurrency"`
+	QuoteCurrency     string  `json:"quoteCurrency"`
+	Base              float64 `json:"base"`
+	Quote             float64 `json:"quote"`
+	AverageCost       float64 `json:"averageCost"`
+	RealizedProfit    float64 `json:"realizedProfit"`
+	RealizedNetProfit float64 `json:"realizedNetProfit"`
+	UnrealizedProfit  float64 `json:"unrealizedProfit"`
+}
+
+type QueryPositionsResponse struct {
+	Positions []Position `json:"positions"`
+}
+
+type SubmitOrderRequest struct {
+	Session string            `json:"session"`
+	Order   types.SubmitOrder `json:"order"`
+}
+
+type SubmitOrderResponse struct {
+	Orders []types.Order `json:"orders"`
+}
+
+type CancelOrderRequest struct {
+	Session string `json:"session"`
+	Symbol  string `json:"symbol"`
+	OrderID uint64 `json:"orderID"`
+}
+
+type CancelOrderResponse struct {
+	Order types.Order `json:"order"`
+}
+
+// MarketDataChannel is the channel of the market data subscription
+type MarketDataChannel string
+
+const (
+	MarketDataChannelKLine MarketDataChannel = "kline"
+	MarketDataChannelBook  MarketDataChannel = "book"
+	MarketDataChannelTrade MarketDataChannel = "trade"
+)
+
+type SubscribeRequest struct {
+	Session string `json:"session"`
+	Symbol  string `json:"symbol"`
+
+	// Channels are the subscribed channels, all the channels are subscribed if it's empty
+	Channels []MarketDataChannel `json:"channels,omitempty"`
+
+	// Interval filters the klines, all the closed klines are sent if it's empty
+	Interval types.Interval `json:"interval,omitempty"`
+
+	// Depth is the depth of the book, defaults to 20
+	Depth int `json:"depth,omitempty"`
+}
+
+type PriceVolume struct {
+	Price  float64 `json:"price"`
+	Volume float64 `json:"volume"`
+}
+
+type Book struct {
+	Symbol string        `json:"symbol"`
+	Bids   []PriceVolume `json:"bids"`
+	Asks   []PriceVolume `json:"asks"`
+}
+
+// MarketDataEvent is the event of the market data stream, only the field of the channel is set
+type MarketDataEvent struct {
+	Session string            `json:"session"`
+	Channel MarketDataChannel `json:"channel"`
+	Symbol  string            `json:"symbol"`
+
+	KLine *types.KLine `json:"kline,omitempty"`
+	Book  *Book        `json:"book,omitempty"`
+	Trade *types.Trade `json:"trade,omitempty"`
+}
@@ -0,0 +1,328 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const DefaultBindAddress = "localhost:50051"
+
+// Server exposes the sessions of the running environment to the external tools through gRPC
+type Server struct {
+	Environ *bbgo.Environment
+
+	// Token is the bearer token of the calls, the trading service is disabled if it's empty
+	Token string
+
+	mu      sync.Mutex
+	brokers map[string]*marketDataBroker
+
+	grpcServer *grpc.Server
+}
+
+func NewServer(environ *bbgo.Environment, token string) *Server {
+	s := &Server{
+		Environ: environ,
+		Token:   token,
+		brokers: make(map[string]*marketDataBroker),
+	}
+
+	s.grpcServer = grpc.NewServer(
+		grpc.ForceServerCodec(jsonCodec{}),
+		grpc.UnaryInterceptor(s.unaryAuth),
+		grpc.StreamInterceptor(s.streamAuth),
+	)
+	s.grpcServer.RegisterService(&sessionServiceDesc, s)
+	s.grpcServer.RegisterService(&tradingServiceDesc, s)
+	s.grpcServer.RegisterService(&marketDataServiceDesc, s)
+	return s
+}
+
+// Run serves the gRPC services until the context is canceled
+func (s *Server) Run(ctx context.Context, bind string) error {
+	if len(bind) == 0 {
+		bind = DefaultBindAddress
+	}
+
+	listener, err := net.Listen("tcp", bind)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+		s.grpcServer.GracefulStop()
+	}()
+
+	log.Infof("grpc server is listening on %s", bind)
+	return s.grpcServer.Serve(listener)
+}
+
+func (s *Server) session(name string) (*bbgo.ExchangeSession, error) {
+	session, ok := s.Environ.Session(name)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "session %s not found", name)
+	}
+
+	return session, nil
+}
+
+func (s *Server) QuerySessions(ctx context.Context, request *Empty) (*QuerySessionsResponse, error) {
+	// the readiness report contains the connectivity of the session streams
+	var errs = make(map[string]string)
+	if s.Environ.HealthChecker != nil {
+		report := s.Environ.HealthChecker.Readiness(ctx, time.Now())
+		for _, result := range report.Checks {
+			if !result.Healthy && strings.HasPrefix(result.Name, "session:") {
+				name := strings.Split(result.Name, ":")[1]
+				errs[name] = result.Error
+			}
+		}
+	}
+
+	var response = &QuerySessionsResponse{Sessions: []Session{}}
+	for name, session := range s.Environ.Sessions() {
+		var symbols []string
+		for _, sub := range session.Subscriptions {
+			symbols = append(symbols, sub.Symbol)
+		}
+		sort.Strings(symbols)
+
+		response.Sessions = append(response.Sessions, Session{
+			Name:           name,
+			Exchange:       session.ExchangeName,
+			PublicOnly:     session.PublicOnly,
+			Margin:         session.Margin,
+			IsolatedMargin: session.IsolatedMargin,
+			Futures:        session.Futures,
+			Symbols:        uniqueStrings(symbols),
+			Connected:      errs[name] == "",
+			Error:          errs[name],
+		})
+	}
+
+	sort.Slice(response.Sessions, func(i, j int) bool {
+		return response.Sessions[i].Name < response.Sessions[j].Name
+	})
+
+	return response, nil
+}
+
+func (s *Server) QueryBalances(ctx context.Context, request *QueryBalancesRequest) (*QueryBalancesResponse, error) {
+	session, err := s.session(request.Session)
+	if err != nil {
+		return nil, err
+	}
+
+	var response = &QueryBalancesResponse{Session: session.Name, Balances: []types.Balance{}}
+	if session.Account == nil {
+		return response, nil
+	}
+
+	for _, balance := range session.Account.Balances() {
+		response.Balances = append(response.Balances, balance)
+	}
+
+	sort.Slice(response.Balances, func(i, j int) bool {
+		return response.Balances[i].Currency < response.Balances[j].Currency
+	})
+
+	return response, nil
+}
+
+func (s *Server) QueryOpenOrders(ctx context.Context, request *QueryOpenOrdersRequest) (*QueryOpenOrdersResponse, error) {
+	session, err := s.session(request.Session)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(request.Symbol) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "symbol is required")
+	}
+
+	orders, err := session.Exchange.QueryOpenOrders(ctx, request.Symbol)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	if orders == nil {
+		orders = []types.Order{}
+	}
+
+	return &QueryOpenOrdersResponse{Orders: orders}, nil
+}
+
+func (s *Server) QueryPositions(ctx context.Context, request *QueryPositionsRequest) (*QueryPositionsResponse, error) {
+	session, err := s.session(request.Session)
+	if err != nil {
+		return nil, err
+	}
+
+	var response = &QueryPositionsResponse{Positions: []Position{}}
+	for symbol, position := range session.Positions() {
+		if len(request.Symbol) > 0 && request.Symbol != symbol {
+			continue
+		}
+
+		position.Lock()
+		p := Position{
+			Symbol:            position.Symbol,
+			BaseCurrency:      position.BaseCurrency,
+			QuoteCurrency:     position.QuoteCurrency,
+			Base:              position.Base.Float64(),
+			Quote:             position.Quote.Float64(),
+			AverageCost:       position.AverageCost.Float64(),
+			RealizedProfit:    position.RealizedProfit.Float64(),
+			RealizedNetProfit: position.RealizedNetProfit.Float64(),
+		}
+		position.Unlock()
+
+		if profit, ok := session.UnrealizedProfit(symbol); ok {
+			p.UnrealizedProfit = profit.Float64()
+		}
+
+		response.Positions = append(response.Positions, p)
+	}
+
+	sort.Slice(response.Positions, func(i, j int) bool {
+		return response.Positions[i].Symbol < response.Positions[j].Symbol
+	})
+
+	return response, nil
+}
+
+func (s *Server) SubmitOrder(ctx context.Context, request *SubmitOrderRequest) (*SubmitOrderResponse, error) {
+	session, err := s.session(request.Session)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.PublicOnly {
+		return nil, status.Errorf(codes.FailedPrecondition, "session %s is public only", session.Name)
+	}
+
+	order := request.Order
+	if _, ok := session.Market(order.Symbol); !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "market %s not found in session %s", order.Symbol, session.Name)
+	}
+
+	if order.Quantity <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "order quantity should be greater than zero")
+	}
+
+	// the orders are submitted through the order executor of the session, so the order guards are applied
+	createdOrders, err := session.OrderExecutor.SubmitOrders(ctx, order)
+	if err != nil {
+		return nil, status.Error(codes.Aborted, errors.Wrap(err, "order submit error").Error())
+	}
+
+	return &SubmitOrderResponse{Orders: createdOrders}, nil
+}
+
+func (s *Server) CancelOrder(ctx context.Context, request *CancelOrderRequest) (*CancelOrderResponse, error) {
+	session, err := s.session(request.Session)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.OrderID == 0 {
+		return nil, status.Error(codes.InvalidArgument, "order id is required")
+	}
+
+	order := types.Order{
+		SubmitOrder: types.SubmitOrder{Symbol: request.Symbol},
+		OrderID:     request.OrderID,
+	}
+
+	if err := session.Exchange.CancelOrders(ctx, order); err != nil {
+		return nil, status.Error(codes.Aborted, errors.Wrap(err, "order cancel error").Error())
+	}
+
+	return &CancelOrderResponse{Order: order}, nil
+}
+
+// MarketDataStream is the server stream of the market data events
+type MarketDataStream interface {
+	Context() context.Context
+	Send(event *MarketDataEvent) error
+}
+
+// Subscribe streams the market data events of the symbol until the client cancels the call,
+// the symbol should be subscribed in the session config.
+func (s *Server) Subscribe(request *SubscribeRequest, stream MarketDataStream) error {
+	session, err := s.session(request.Session)
+	if err != nil {
+		return err
+	}
+
+	if len(request.Symbol) == 0 {
+		return status.Error(codes.InvalidArgument, "symbol is required")
+	}
+
+	for _, channel := range request.Channels {
+		switch channel {
+		case MarketDataChannelKLine, MarketDataChannelBook, MarketDataChannelTrade:
+		default:
+			return status.Errorf(codes.InvalidArgument, "unsupported channel %s", channel)
+		}
+	}
+
+	broker := s.broker(session)
+	sub := broker.Subscribe(*request)
+	defer broker.Unsubscribe(sub)
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event := <-sub.C:
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *Server) broker(session *bbgo.ExchangeSession) *marketDataBroker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	broker, ok := s.brokers[session.Name]
+	if !ok {
+		broker = newMarketDataBroker(session)
+		s.brokers[session.Name] = broker
+	}
+
+	return broker
+}
+
+func uniqueStrings(slice []string) []string {
+	var unique []string
+	for i, s := range slice {
+		if i > 0 && slice[i-1] == s {
+			continue
+		}
+		unique = append(unique, s)
+	}
+
+	if unique == nil {
+		return []string{}
+	}
+
+	return unique
+}
@@ -0,0 +1,269 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type testStream struct {
+	types.StandardStream
+}
+
+func (s *testStream) SetPublicOnly() {}
+
+type testExchange struct {
+	types.Exchange
+
+	openOrders     []types.Order
+	submitOrders   []types.SubmitOrder
+	canceledOrders []types.Order
+}
+
+func (e *testExchange) NewStream() types.Stream {
+	return &testStream{StandardStream: types.NewStandardStream()}
+}
+
+func (e *testExchange) QueryOpenOrders(ctx context.Context, symbol string) ([]types.Order, error) {
+	var orders []types.Order
+	for _, o := range e.openOrders {
+		if o.Symbol == symbol {
+			orders = append(orders, o)
+		}
+	}
+	return orders, nil
+}
+
+func (e *testExchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	var createdOrders types.OrderSlice
+	for _, o := range orders {
+		e.submitOrders = append(e.submitOrders, o)
+		createdOrders = append(createdOrders, types.Order{SubmitOrder: o, OrderID: uint64(len(e.submitOrders)), Status: types.OrderStatusNew})
+	}
+	return createdOrders, nil
+}
+
+func (e *testExchange) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	e.canceledOrders = append(e.canceledOrders, orders...)
+	return nil
+}
+
+type testMarketDataStream struct {
+	ctx    context.Context
+	events chan *MarketDataEvent
+}
+
+func (s *testMarketDataStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *testMarketDataStream) Send(event *MarketDataEvent) error {
+	s.events <- event
+	return nil
+}
+
+func newTestServer() (*Server, *testExchange) {
+	exchange := &testExchange{
+		openOrders: []types.Order{
+			{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 9000.0, Quantity: 0.1}, OrderID: 1},
+			{SubmitOrder: types.SubmitOrder{Symbol: "ETHUSDT", Side: types.SideTypeSell, Type: types.OrderTypeLimit, Price: 400.0, Quantity: 1.0}, OrderID: 2},
+		},
+	}
+
+	session := bbgo.NewExchangeSession("binance", exchange)
+	session.ExchangeName = types.ExchangeBinance
+	session.SetMarkets(types.MarketMap{
+		"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT", MinQuantity: 0.0001, StepSize: 0.0001, TickSize: 0.01, PricePrecision: 2, VolumePrecision: 4},
+	})
+	session.Subscribe(types.BookChannel, "BTCUSDT", types.SubscribeOptions{})
+	session.Subscribe(types.KLineChannel, "BTCUSDT", types.SubscribeOptions{Interval: "1m"})
+	session.Account.UpdateBalances(types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(1000.0)},
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(0.5), Locked: fixedpoint.NewFromFloat(0.1)},
+	})
+
+	position, _ := session.Position("BTCUSDT")
+	position.AddTrade(types.Trade{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Price: 10000.0, Quantity: 0.5, QuoteQuantity: 5000.0})
+
+	environ := bbgo.NewEnvironment()
+	environ.AddExchangeSession("binance", session)
+	return NewServer(environ, "secret"), exchange
+}
+
+func TestServer_SessionService(t *testing.T) {
+	server, _ := newTestServer()
+	ctx := context.Background()
+
+	sessions, err := server.QuerySessions(ctx, &Empty{})
+	assert.NoError(t, err)
+	if assert.Len(t, sessions.Sessions, 1) {
+		assert.Equal(t, "binance", sessions.Sessions[0].Name)
+		assert.Equal(t, []string{"BTCUSDT"}, sessions.Sessions[0].Symbols)
+	}
+
+	balances, err := server.QueryBalances(ctx, &QueryBalancesRequest{Session: "binance"})
+	assert.NoError(t, err)
+	if assert.Len(t, balances.Balances, 2) {
+		assert.Equal(t, "BTC", balances.Balances[0].Currency)
+		assert.Equal(t, 0.6, balances.Balances[0].Total().Float64())
+	}
+
+	_, err = server.QueryBalances(ctx, &QueryBalancesRequest{Session: "ftx"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	orders, err := server.QueryOpenOrders(ctx, &QueryOpenOrdersRequest{Session: "binance", Symbol: "BTCUSDT"})
+	assert.NoError(t, err)
+	if assert.Len(t, orders.Orders, 1) {
+		assert.Equal(t, uint64(1), orders.Orders[0].OrderID)
+	}
+
+	_, err = server.QueryOpenOrders(ctx, &QueryOpenOrdersRequest{Session: "binance"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	positions, err := server.QueryPositions(ctx, &QueryPositionsRequest{Session: "binance"})
+	assert.NoError(t, err)
+	if assert.Len(t, positions.Positions, 1) {
+		assert.Equal(t, 0.5, positions.Positions[0].Base)
+		assert.Equal(t, 10000.0, positions.Positions[0].AverageCost)
+	}
+}
+
+func TestServer_TradingService(t *testing.T) {
+	server, exchange := newTestServer()
+	ctx := context.Background()
+
+	response, err := server.SubmitOrder(ctx, &SubmitOrderRequest{
+		Session: "binance",
+		Order:   types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 9500.123, Quantity: 0.01},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, response.Orders, 1)
+	if assert.Len(t, exchange.submitOrders, 1) {
+		assert.Equal(t, 9500.12, exchange.submitOrders[0].Price, "the price is formatted by the market")
+	}
+
+	_, err = server.SubmitOrder(ctx, &SubmitOrderRequest{
+		Session: "binance",
+		Order:   types.SubmitOrder{Symbol: "DOGEUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeMarket, Quantity: 100.0},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.CancelOrder(ctx, &CancelOrderRequest{Session: "binance", Symbol: "BTCUSDT"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.CancelOrder(ctx, &CancelOrderRequest{Session: "binance", Symbol: "BTCUSDT", OrderID: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, exchange.canceledOrders, 1) {
+		assert.Equal(t, uint64(1), exchange.canceledOrders[0].OrderID)
+	}
+}
+
+func TestServer_Authorize(t *testing.T) {
+	server, exchange := newTestServer()
+	info := &grpc.UnaryServerInfo{FullMethod: "/bbgo.TradingService/SubmitOrder"}
+	request := &SubmitOrderRequest{
+		Session: "binance",
+		Order:   types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeMarket, Quantity: 0.01},
+	}
+	handler := func(ctx context.Context, request interface{}) (interface{}, error) {
+		return server.SubmitOrder(ctx, request.(*SubmitOrderRequest))
+	}
+
+	_, err := server.unaryAuth(context.Background(), request, info, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer wrong"))
+	_, err = server.unaryAuth(ctx, request, info, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Len(t, exchange.submitOrders, 0)
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer secret"))
+	_, err = server.unaryAuth(ctx, request, info, handler)
+	assert.NoError(t, err)
+	assert.Len(t, exchange.submitOrders, 1)
+
+	// the trading service is disabled without the token
+	server.Token = ""
+	_, err = server.unaryAuth(context.Background(), request, info, handler)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = server.unaryAuth(context.Background(), &Empty{}, &grpc.UnaryServerInfo{FullMethod: "/bbgo.SessionService/QuerySessions"},
+		func(ctx context.Context, request interface{}) (interface{}, error) {
+			return server.QuerySessions(ctx, request.(*Empty))
+		})
+	assert.NoError(t, err)
+}
+
+func TestServer_Subscribe(t *testing.T) {
+	server, _ := newTestServer()
+	session, _ := server.Environ.Session("binance")
+	marketDataStream := session.MarketDataStream.(*testStream)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &testMarketDataStream{ctx: ctx, events: make(chan *MarketDataEvent, 10)}
+
+	done := make(chan error)
+	go func() {
+		done <- server.Subscribe(&SubscribeRequest{
+			Session:  "binance",
+			Symbol:   "BTCUSDT",
+			Channels: []MarketDataChannel{MarketDataChannelKLine, MarketDataChannelBook},
+			Interval: types.Interval1m,
+		}, stream)
+	}()
+
+	// wait for the subscriber
+	assert.Eventually(t, func() bool {
+		broker := server.broker(session)
+		broker.mu.Lock()
+		defer broker.mu.Unlock()
+		return len(broker.subscribers) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	marketDataStream.EmitKLineClosed(types.KLine{Symbol: "BTCUSDT", Interval: types.Interval5m, Close: 10000.0})
+	marketDataStream.EmitKLineClosed(types.KLine{Symbol: "BTCUSDT", Interval: types.Interval1m, Close: 10001.0})
+	marketDataStream.EmitKLineClosed(types.KLine{Symbol: "ETHUSDT", Interval: types.Interval1m, Close: 400.0})
+	marketDataStream.EmitMarketTrade(types.Trade{Symbol: "BTCUSDT", Price: 10001.0})
+	marketDataStream.EmitBookSnapshot(types.SliceOrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(10000.0), Volume: fixedpoint.NewFromFloat(1.0)}},
+		Asks:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(10002.0), Volume: fixedpoint.NewFromFloat(2.0)}},
+	})
+
+	event := <-stream.events
+	assert.Equal(t, MarketDataChannelKLine, event.Channel)
+	assert.Equal(t, 10001.0, event.KLine.Close)
+
+	event = <-stream.events
+	assert.Equal(t, MarketDataChannelBook, event.Channel)
+	assert.Equal(t, []PriceVolume{{Price: 10000.0, Volume: 1.0}}, event.Book.Bids)
+	assert.Equal(t, []PriceVolume{{Price: 10002.0, Volume: 2.0}}, event.Book.Asks)
+
+	cancel()
+	assert.NoError(t, <-done)
+	assert.Len(t, stream.events, 0)
+}
+
+func TestJSONCodec(t *testing.T) {
+	codec := jsonCodec{}
+	data, err := codec.Marshal(&QueryOpenOrdersRequest{Session: "binance", Symbol: "BTCUSDT"})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"session":"binance","symbol":"BTCUSDT"}`, string(data))
+
+	var request QueryOpenOrdersRequest
+	assert.NoError(t, codec.Unmarshal(data, &request))
+	assert.Equal(t, "BTCUSDT", request.Symbol)
+
+	// the empty message is encoded as the empty body by some clients
+	assert.NoError(t, codec.Unmarshal(nil, &Empty{}))
+}
@@ -0,0 +1,131 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// The service descriptors are written by hand instead of generated by protoc, since the messages are encoded in JSON.
+// The full method names are "/bbgo.SessionService/QuerySessions", "/bbgo.TradingService/SubmitOrder" and so on.
+
+type sessionService interface {
+	QuerySessions(ctx context.Context, request *Empty) (*QuerySessionsResponse, error)
+	QueryBalances(ctx context.Context, request *QueryBalancesRequest) (*QueryBalancesResponse, error)
+	QueryOpenOrders(ctx context.Context, request *QueryOpenOrdersRequest) (*QueryOpenOrdersResponse, error)
+	QueryPositions(ctx context.Context, request *QueryPositionsRequest) (*QueryPositionsResponse, error)
+}
+
+type tradingService interface {
+	SubmitOrder(ctx context.Context, request *SubmitOrderRequest) (*SubmitOrderResponse, error)
+	CancelOrder(ctx context.Context, request *CancelOrderRequest) (*CancelOrderResponse, error)
+}
+
+type marketDataService interface {
+	Subscribe(request *SubscribeRequest, stream MarketDataStream) error
+}
+
+// unaryHandler decodes the request and calls the method through the interceptor
+func unaryHandler(fullMethod string, newRequest func() interface{}, call func(srv interface{}, ctx context.Context, request interface{}) (interface{}, error)) func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	return func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+		request := newRequest()
+		if err := dec(request); err != nil {
+			return nil, err
+		}
+
+		if interceptor == nil {
+			return call(srv, ctx, request)
+		}
+
+		info := &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod}
+		return interceptor(ctx, request, info, func(ctx context.Context, request interface{}) (interface{}, error) {
+			return call(srv, ctx, request)
+		})
+	}
+}
+
+var sessionServiceDesc = grpc.ServiceDesc{
+	ServiceName: "bbgo.SessionService",
+	HandlerType: (*sessionService)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "QuerySessions",
+			Handler: unaryHandler("/bbgo.SessionService/QuerySessions", func() interface{} { return new(Empty) },
+				func(srv interface{}, ctx context.Context, request interface{}) (interface{}, error) {
+					return srv.(sessionService).QuerySessions(ctx, request.(*Empty))
+				}),
+		},
+		{
+			MethodName: "QueryBalances",
+			Handler: unaryHandler("/bbgo.SessionService/QueryBalances", func() interface{} { return new(QueryBalancesRequest) },
+				func(srv interface{}, ctx context.Context, request interface{}) (interface{}, error) {
+					return srv.(sessionService).QueryBalances(ctx, request.(*QueryBalancesRequest))
+				}),
+		},
+		{
+			MethodName: "QueryOpenOrders",
+			Handler: unaryHandler("/bbgo.SessionService/QueryOpenOrders", func() interface{} { return new(QueryOpenOrdersRequest) },
+				func(srv interface{}, ctx context.Context, request interface{}) (interface{}, error) {
+					return srv.(sessionService).QueryOpenOrders(ctx, request.(*QueryOpenOrdersRequest))
+				}),
+		},
+		{
+			MethodName: "QueryPositions",
+			Handler: unaryHandler("/bbgo.SessionService/QueryPositions", func() interface{} { return new(QueryPositionsRequest) },
+				func(srv interface{}, ctx context.Context, request interface{}) (interface{}, error) {
+					return srv.(sessionService).QueryPositions(ctx, request.(*QueryPositionsRequest))
+				}),
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+var tradingServiceDesc = grpc.ServiceDesc{
+	ServiceName: "bbgo.TradingService",
+	HandlerType: (*tradingService)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SubmitOrder",
+			Handler: unaryHandler("/bbgo.TradingService/SubmitOrder", func() interface{} { return new(SubmitOrderRequest) },
+				func(srv interface{}, ctx context.Context, request interface{}) (interface{}, error) {
+					return srv.(tradingService).SubmitOrder(ctx, request.(*SubmitOrderRequest))
+				}),
+		},
+		{
+			MethodName: "CancelOrder",
+			Handler: unaryHandler("/bbgo.TradingService/CancelOrder", func() interface{} { return new(CancelOrderRequest) },
+				func(srv interface{}, ctx context.Context, request interface{}) (interface{}, error) {
+					return srv.(tradingService).CancelOrder(ctx, request.(*CancelOrderRequest))
+				}),
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+var marketDataServiceDesc = grpc.ServiceDesc{
+	ServiceName: "bbgo.MarketDataService",
+	HandlerType: (*marketDataService)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			ServerStreams: true,
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				request := new(SubscribeRequest)
+				if err := stream.RecvMsg(request); err != nil {
+					return err
+				}
+
+				return srv.(marketDataService).Subscribe(request, &marketDataServerStream{ServerStream: stream})
+			},
+		},
+	},
+}
+
+type marketDataServerStream struct {
+	grpc.ServerStream
+}
+
+func (s *marketDataServerStream) Send(event *MarketDataEvent) error {
+	return s.ServerStream.SendMsg(event)
+}
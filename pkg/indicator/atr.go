@@ -0,0 +1,96 @@
+package indicator
+
+import (
+	"math"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const MaxNumOfATR = 5_000
+const MaxNumOfATRTruncateSize = 100
+
+/*
+atr implements the average true range (ATR) indicator with the Wilder's smoothing method
+
+Average True Range (ATR)
+- https://www.investopedia.com/terms/a/atr.asp
+*/
+//go:generate callbackgen -type ATR
+type ATR struct {
+	types.IntervalWindow
+	Values types.Float64Slice
+
+	// TrueRanges keeps the true ranges of the first window to calculate the first average
+	TrueRanges    types.Float64Slice
+	PreviousClose float64
+	EndTime       time.Time
+
+	UpdateCallbacks []func(value float64)
+}
+
+// Update updates the ATR with the new kline
+func (inc *ATR) Update(kLine types.KLine) {
+	// the true range of the first kline is the high low range
+	trueRange := kLine.High - kLine.Low
+	if inc.PreviousClose != 0 {
+		trueRange = math.Max(trueRange, math.Max(
+			math.Abs(kLine.High-inc.PreviousClose),
+			math.Abs(kLine.Low-inc.PreviousClose)))
+	}
+
+	inc.PreviousClose = kLine.Close
+
+	if len(inc.Values) == 0 {
+		inc.TrueRanges.Push(trueRange)
+		if len(inc.TrueRanges) < inc.Window {
+			return
+		}
+
+		inc.Values.Push(inc.TrueRanges.Mean())
+		inc.TrueRanges = nil
+		return
+	}
+
+	atr := (inc.Last()*float64(inc.Window-1) + trueRange) / float64(inc.Window)
+	inc.Values.Push(atr)
+
+	if len(inc.Values) > MaxNumOfATR {
+		inc.Values = inc.Values[MaxNumOfATRTruncateSize-1:]
+	}
+}
+
+func (inc *ATR) Last() float64 {
+	if len(inc.Values) == 0 {
+		return 0.0
+	}
+	return inc.Values[len(inc.Values)-1]
+}
+
+func (inc *ATR) calculateAndUpdate(kLines []types.KLine) {
+	for _, k := range kLines {
+		if inc.EndTime != zeroTime && !k.EndTime.After(inc.EndTime) {
+			continue
+		}
+
+		inc.Update(k)
+		inc.EndTime = k.EndTime
+
+		// the values are pushed once the first window is filled
+		if len(inc.Values) > 0 {
+			inc.EmitUpdate(inc.Last())
+		}
+	}
+}
+
+func (inc *ATR) handleKLineWindowUpdate(interval types.Interval, window types.KLineWindow) {
+	if inc.Interval != interval {
+		return
+	}
+
+	inc.calculateAndUpdate(window)
+}
+
+func (inc *ATR) Bind(updater KLineWindowUpdater) {
+	updater.OnKLineWindowUpdate(inc.handleKLineWindowUpdate)
+}
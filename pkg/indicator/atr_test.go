@@ -0,0 +1,37 @@
+package indicator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// the expected values are calculated by the Wilder's smoothing of the true ranges, the first ATR is the mean of
+// the first 5 true ranges, and the true range of the first kline is its high low range.
+func TestATR(t *testing.T) {
+	high := []float64{101.09, 100.3, 100.72, 98.12, 97.51, 97.83, 96.08, 96.12, 92.04, 92.23, 93.75, 94.61, 96.18, 97.92, 95.65, 95.75, 96.69, 99.16, 98.81, 103.08}
+	low := []float64{97.69, 98.3, 95.61, 93.6, 93.26, 92.72, 93.12, 90.8, 89.06, 88.87, 89.84, 90.04, 93.45, 93.42, 93.28, 93.2, 93.01, 92.9, 95.73, 97.34}
+	close := []float64{98.43, 99.05, 96.13, 94.54, 96.56, 94.46, 94.6, 91.98, 90.79, 90.63, 91.91, 93.72, 95.99, 94.29, 95.05, 94.37, 94.88, 97.45, 98.48, 101.27}
+
+	var kLines []types.KLine
+	for i := range high {
+		kLines = append(kLines, types.KLine{High: high[i], Low: low[i], Close: close[i], EndTime: time.Unix(int64(i*60), 0)})
+	}
+
+	var updates []float64
+	atr := ATR{IntervalWindow: types.IntervalWindow{Interval: types.Interval1m, Window: 5}}
+	atr.OnUpdate(func(value float64) {
+		updates = append(updates, value)
+	})
+
+	atr.calculateAndUpdate(kLines[:4])
+	assert.Empty(t, updates)
+
+	atr.calculateAndUpdate(kLines)
+	assert.Len(t, updates, 16)
+	assert.InDelta(t, 3.856, updates[0], 1e-6)
+	assert.InDelta(t, 4.201498, atr.Last(), 1e-6)
+}
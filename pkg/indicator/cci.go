@@ -0,0 +1,96 @@
+package indicator
+
+import (
+	"math"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const MaxNumOfCCI = 5_000
+const MaxNumOfCCITruncateSize = 100
+
+// cciConstant scales the CCI so that most of the values fall between -100 and 100
+const cciConstant = 0.015
+
+/*
+cci implements the commodity channel index (CCI) indicator
+
+Commodity Channel Index (CCI)
+- https://www.investopedia.com/terms/c/commoditychannelindex.asp
+*/
+//go:generate callbackgen -type CCI
+type CCI struct {
+	types.IntervalWindow
+	Values types.Float64Slice
+
+	// TypicalPrices keeps the typical prices of the last window
+	TypicalPrices types.Float64Slice
+	EndTime       time.Time
+
+	UpdateCallbacks []func(value float64)
+}
+
+// Update updates the CCI with the new kline, returns true when the value is calculated
+func (inc *CCI) Update(kLine types.KLine) bool {
+	inc.TypicalPrices.Push(KLineTypicalPriceMapper(kLine))
+	if len(inc.TypicalPrices) > inc.Window {
+		inc.TypicalPrices = inc.TypicalPrices[len(inc.TypicalPrices)-inc.Window:]
+	}
+
+	if len(inc.TypicalPrices) < inc.Window {
+		return false
+	}
+
+	sma := inc.TypicalPrices.Mean()
+
+	var meanDeviation float64
+	for _, tp := range inc.TypicalPrices {
+		meanDeviation += math.Abs(tp - sma)
+	}
+	meanDeviation /= float64(inc.Window)
+
+	var cci float64
+	if meanDeviation > 0 {
+		cci = (inc.TypicalPrices[len(inc.TypicalPrices)-1] - sma) / (cciConstant * meanDeviation)
+	}
+
+	inc.Values.Push(cci)
+	if len(inc.Values) > MaxNumOfCCI {
+		inc.Values = inc.Values[MaxNumOfCCITruncateSize-1:]
+	}
+
+	return true
+}
+
+func (inc *CCI) Last() float64 {
+	if len(inc.Values) == 0 {
+		return 0.0
+	}
+	return inc.Values[len(inc.Values)-1]
+}
+
+func (inc *CCI) calculateAndUpdate(kLines []types.KLine) {
+	for _, k := range kLines {
+		if inc.EndTime != zeroTime && !k.EndTime.After(inc.EndTime) {
+			continue
+		}
+
+		inc.EndTime = k.EndTime
+		if inc.Update(k) {
+			inc.EmitUpdate(inc.Last())
+		}
+	}
+}
+
+func (inc *CCI) handleKLineWindowUpdate(interval types.Interval, window types.KLineWindow) {
+	if inc.Interval != interval {
+		return
+	}
+
+	inc.calculateAndUpdate(window)
+}
+
+func (inc *CCI) Bind(updater KLineWindowUpdater) {
+	updater.OnKLineWindowUpdate(inc.handleKLineWindowUpdate)
+}
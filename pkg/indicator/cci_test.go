@@ -0,0 +1,27 @@
+package indicator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestCCI(t *testing.T) {
+	kLines := buildTrendTestKLines()
+
+	var updates []float64
+	cci := CCI{IntervalWindow: types.IntervalWindow{Interval: types.Interval1m, Window: 5}}
+	cci.OnUpdate(func(value float64) {
+		updates = append(updates, value)
+	})
+
+	cci.calculateAndUpdate(kLines[:10])
+	cci.calculateAndUpdate(kLines)
+
+	assert.Len(t, updates, 26)
+	assert.InDelta(t, -70.795875, updates[0], 1e-6)
+	assert.InDelta(t, -61.636975, cci.Last(), 1e-6)
+	assert.Len(t, cci.TypicalPrices, 5)
+}
@@ -0,0 +1,146 @@
+package indicator
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const MaxNumOfIchimoku = 5_000
+const MaxNumOfIchimokuTruncateSize = 100
+
+// IchimokuValue is the calculated Ichimoku lines of a kline,
+// note that the senkou spans are plotted BasePeriod klines ahead, and the chikou span is the close price
+// plotted BasePeriod klines behind.
+type IchimokuValue struct {
+	Tenkan  float64
+	Kijun   float64
+	SenkouA float64
+	SenkouB float64
+	Chikou  float64
+}
+
+/*
+ichimoku implements the Ichimoku Cloud (Ichimoku Kinko Hyo) indicator
+
+Ichimoku Cloud
+- https://www.investopedia.com/terms/i/ichimoku-cloud.asp
+*/
+//go:generate callbackgen -type Ichimoku
+type Ichimoku struct {
+	Interval types.Interval
+
+	// ConversionPeriod is the period of the tenkan-sen (conversion line), defaults to 9
+	ConversionPeriod int
+
+	// BasePeriod is the period of the kijun-sen (base line) and the displacement of the spans, defaults to 26
+	BasePeriod int
+
+	// SpanBPeriod is the period of the senkou span B, defaults to 52
+	SpanBPeriod int
+
+	Tenkan  types.Float64Slice
+	Kijun   types.Float64Slice
+	SenkouA types.Float64Slice
+	SenkouB types.Float64Slice
+	Chikou  types.Float64Slice
+
+	KLineWindow types.KLineWindow
+	EndTime     time.Time
+
+	UpdateCallbacks []func(value IchimokuValue)
+}
+
+func (inc *Ichimoku) periods() (conversion, base, spanB int) {
+	conversion, base, spanB = inc.ConversionPeriod, inc.BasePeriod, inc.SpanBPeriod
+	if conversion == 0 {
+		conversion = 9
+	}
+	if base == 0 {
+		base = 26
+	}
+	if spanB == 0 {
+		spanB = 52
+	}
+	return conversion, base, spanB
+}
+
+// Update updates the Ichimoku lines with the new kline, the lines are calculated after SpanBPeriod klines
+func (inc *Ichimoku) Update(kLine types.KLine) bool {
+	conversion, base, spanB := inc.periods()
+
+	inc.KLineWindow.Add(kLine)
+	inc.KLineWindow.Truncate(spanB)
+
+	if len(inc.KLineWindow) < spanB {
+		return false
+	}
+
+	tenkan := midPrice(inc.KLineWindow, conversion)
+	kijun := midPrice(inc.KLineWindow, base)
+
+	inc.Tenkan.Push(tenkan)
+	inc.Kijun.Push(kijun)
+	inc.SenkouA.Push((tenkan + kijun) / 2.0)
+	inc.SenkouB.Push(midPrice(inc.KLineWindow, spanB))
+	inc.Chikou.Push(kLine.Close)
+
+	if len(inc.Tenkan) > MaxNumOfIchimoku {
+		inc.Tenkan = inc.Tenkan[MaxNumOfIchimokuTruncateSize-1:]
+		inc.Kijun = inc.Kijun[MaxNumOfIchimokuTruncateSize-1:]
+		inc.SenkouA = inc.SenkouA[MaxNumOfIchimokuTruncateSize-1:]
+		inc.SenkouB = inc.SenkouB[MaxNumOfIchimokuTruncateSize-1:]
+		inc.Chikou = inc.Chikou[MaxNumOfIchimokuTruncateSize-1:]
+	}
+
+	return true
+}
+
+func (inc *Ichimoku) Last() IchimokuValue {
+	if len(inc.Tenkan) == 0 {
+		return IchimokuValue{}
+	}
+
+	i := len(inc.Tenkan) - 1
+	return IchimokuValue{
+		Tenkan:  inc.Tenkan[i],
+		Kijun:   inc.Kijun[i],
+		SenkouA: inc.SenkouA[i],
+		SenkouB: inc.SenkouB[i],
+		Chikou:  inc.Chikou[i],
+	}
+}
+
+func (inc *Ichimoku) calculateAndUpdate(kLines []types.KLine) {
+	for _, k := range kLines {
+		if inc.EndTime != zeroTime && !k.EndTime.After(inc.EndTime) {
+			continue
+		}
+
+		inc.EndTime = k.EndTime
+		if inc.Update(k) {
+			inc.EmitUpdate(inc.Last())
+		}
+	}
+}
+
+func (inc *Ichimoku) handleKLineWindowUpdate(interval types.Interval, window types.KLineWindow) {
+	if inc.Interval != interval {
+		return
+	}
+
+	inc.calculateAndUpdate(window)
+}
+
+func (inc *Ichimoku) Bind(updater KLineWindowUpdater) {
+	updater.OnKLineWindowUpdate(inc.handleKLineWindowUpdate)
+}
+
+// midPrice returns the middle of the highest high and the lowest low of the last n klines
+func midPrice(window types.KLineWindow, n int) float64 {
+	if len(window) > n {
+		window = window[len(window)-n:]
+	}
+
+	return (window.GetHigh() + window.GetLow()) / 2.0
+}
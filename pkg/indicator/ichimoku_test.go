@@ -0,0 +1,41 @@
+package indicator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestIchimoku(t *testing.T) {
+	kLines := buildTrendTestKLines()
+
+	var updates []IchimokuValue
+	ichimoku := Ichimoku{Interval: types.Interval1m, ConversionPeriod: 3, BasePeriod: 5, SpanBPeriod: 8}
+	ichimoku.OnUpdate(func(value IchimokuValue) {
+		updates = append(updates, value)
+	})
+
+	ichimoku.calculateAndUpdate(kLines[:7])
+	assert.Empty(t, updates, "the lines are calculated after SpanBPeriod klines")
+
+	ichimoku.calculateAndUpdate(kLines)
+	assert.Len(t, updates, 23)
+
+	last := ichimoku.Last()
+	assert.InDelta(t, 88.67, last.Tenkan, 1e-9)
+	assert.InDelta(t, 91.07, last.Kijun, 1e-9)
+	assert.InDelta(t, 89.87, last.SenkouA, 1e-9)
+	assert.InDelta(t, 91.68, last.SenkouB, 1e-9)
+	assert.InDelta(t, 89.03, last.Chikou, 1e-9)
+	assert.Equal(t, last, updates[len(updates)-1])
+}
+
+func TestIchimoku_defaultPeriods(t *testing.T) {
+	ichimoku := Ichimoku{}
+	conversion, base, spanB := ichimoku.periods()
+	assert.Equal(t, 9, conversion)
+	assert.Equal(t, 26, base)
+	assert.Equal(t, 52, spanB)
+}
@@ -33,11 +33,13 @@ func (inc *OBV) update(kLine types.KLine, priceF KLinePriceMapper) {
 	}
 
 	var sign float64 = 0.0
-	if volume > inc.PrePrice {
+	if price > inc.PrePrice {
 		sign = 1.0
-	} else if volume < inc.PrePrice {
+	} else if price < inc.PrePrice {
 		sign = -1.0
 	}
+
+	inc.PrePrice = price
 	obv := inc.Last() + sign*volume
 	inc.Values.Push(obv)
 }
@@ -53,7 +55,7 @@ func (inc *OBV) calculateAndUpdate(kLines []types.KLine) {
 	var priceF = KLineClosePriceMapper
 
 	for i, k := range kLines {
-		if inc.EndTime != zeroTime && k.EndTime.Before(inc.EndTime) {
+		if inc.EndTime != zeroTime && !k.EndTime.After(inc.EndTime) {
 			continue
 		}
 
@@ -33,6 +33,12 @@ func Test_calculateOBV(t *testing.T) {
 			window: 0,
 			want:   types.Float64Slice{3, 1, -1, 5},
 		},
+		{
+			name:   "compare_with_previous_price",
+			kLines: buildKLines([]float64{1, 2, 3, 3, 2}, []float64{1, 5, 1, 4, 2}),
+			window: 0,
+			want:   types.Float64Slice{1, 6, 7, 7, 5},
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,106 @@
+package indicator
+
+import (
+	"math"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const MaxNumOfRSI = 5_000
+const MaxNumOfRSITruncateSize = 100
+
+/*
+rsi implements the relative strength index (RSI) indicator with the Wilder's smoothing method
+
+Relative Strength Index (RSI)
+- https://www.investopedia.com/terms/r/rsi.asp
+*/
+//go:generate callbackgen -type RSI
+type RSI struct {
+	types.IntervalWindow
+	Values types.Float64Slice
+
+	// Prices keeps the prices of the first window to calculate the first averages
+	Prices          types.Float64Slice
+	PreviousPrice   float64
+	PreviousAvgGain float64
+	PreviousAvgLoss float64
+	EndTime         time.Time
+
+	UpdateCallbacks []func(value float64)
+}
+
+// Update updates the RSI with the close price of the new kline
+func (inc *RSI) Update(price float64) {
+	if len(inc.Values) == 0 {
+		inc.Prices.Push(price)
+		if len(inc.Prices) <= inc.Window {
+			inc.PreviousPrice = price
+			return
+		}
+
+		var gain, loss float64
+		for i := 1; i < len(inc.Prices); i++ {
+			diff := inc.Prices[i] - inc.Prices[i-1]
+			gain += math.Max(diff, 0)
+			loss += math.Max(-diff, 0)
+		}
+
+		inc.PreviousAvgGain = gain / float64(inc.Window)
+		inc.PreviousAvgLoss = loss / float64(inc.Window)
+		inc.Prices = nil
+	} else {
+		diff := price - inc.PreviousPrice
+		inc.PreviousAvgGain = (inc.PreviousAvgGain*float64(inc.Window-1) + math.Max(diff, 0)) / float64(inc.Window)
+		inc.PreviousAvgLoss = (inc.PreviousAvgLoss*float64(inc.Window-1) + math.Max(-diff, 0)) / float64(inc.Window)
+	}
+
+	inc.PreviousPrice = price
+
+	var rsi = 100.0
+	if inc.PreviousAvgLoss != 0 {
+		rs := inc.PreviousAvgGain / inc.PreviousAvgLoss
+		rsi = 100.0 - 100.0/(1.0+rs)
+	}
+
+	inc.Values.Push(rsi)
+	if len(inc.Values) > MaxNumOfRSI {
+		inc.Values = inc.Values[MaxNumOfRSITruncateSize-1:]
+	}
+}
+
+func (inc *RSI) Last() float64 {
+	if len(inc.Values) == 0 {
+		return 0.0
+	}
+	return inc.Values[len(inc.Values)-1]
+}
+
+func (inc *RSI) calculateAndUpdate(kLines []types.KLine) {
+	for _, k := range kLines {
+		if inc.EndTime != zeroTime && !k.EndTime.After(inc.EndTime) {
+			continue
+		}
+
+		inc.Update(KLineClosePriceMapper(k))
+		inc.EndTime = k.EndTime
+
+		// the values are pushed once the first window is filled
+		if len(inc.Values) > 0 {
+			inc.EmitUpdate(inc.Last())
+		}
+	}
+}
+
+func (inc *RSI) handleKLineWindowUpdate(interval types.Interval, window types.KLineWindow) {
+	if inc.Interval != interval {
+		return
+	}
+
+	inc.calculateAndUpdate(window)
+}
+
+func (inc *RSI) Bind(updater KLineWindowUpdater) {
+	updater.OnKLineWindowUpdate(inc.handleKLineWindowUpdate)
+}
@@ -0,0 +1,47 @@
+package indicator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// the sample prices of https://school.stockcharts.com/doku.php?id=technical_indicators:relative_strength_index_rsi
+var rsiSamplePrices = []float64{44.34, 44.09, 44.15, 43.61, 44.33, 44.83, 45.10, 45.42, 45.84, 46.08, 45.89, 46.03, 45.61, 46.28, 46.28, 46.00, 46.03, 46.41, 46.22, 45.64, 46.21}
+
+func TestRSI_Update(t *testing.T) {
+	rsi := RSI{IntervalWindow: types.IntervalWindow{Window: 14}}
+	for _, price := range rsiSamplePrices {
+		rsi.Update(price)
+	}
+
+	want := []float64{70.464135, 66.249619, 66.480942, 69.346853, 66.294713, 57.915021, 62.880718}
+	if assert.Len(t, rsi.Values, len(want)) {
+		for i, v := range want {
+			assert.InDelta(t, v, rsi.Values[i], 1e-6)
+		}
+	}
+}
+
+func TestRSI_calculateAndUpdate(t *testing.T) {
+	var kLines []types.KLine
+	for i, price := range rsiSamplePrices {
+		kLines = append(kLines, types.KLine{Close: price, EndTime: time.Unix(int64(i*60), 0)})
+	}
+
+	var updates []float64
+	rsi := RSI{IntervalWindow: types.IntervalWindow{Interval: types.Interval1m, Window: 14}}
+	rsi.OnUpdate(func(value float64) {
+		updates = append(updates, value)
+	})
+
+	// the klines are updated incrementally, the processed klines should be skipped
+	rsi.calculateAndUpdate(kLines[:16])
+	rsi.calculateAndUpdate(kLines)
+
+	assert.Len(t, updates, 7)
+	assert.InDelta(t, 62.880718, rsi.Last(), 1e-6)
+}
@@ -60,7 +60,7 @@ func (inc *STOCH) calculateAndUpdate(kLines []types.KLine) {
 	}
 
 	for i, k := range kLines {
-		if inc.EndTime != zeroTime && k.EndTime.Before(inc.EndTime) {
+		if inc.EndTime != zeroTime && !k.EndTime.After(inc.EndTime) {
 			continue
 		}
 
@@ -0,0 +1,133 @@
+package indicator
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const MaxNumOfStochRSI = 5_000
+const MaxNumOfStochRSITruncateSize = 100
+
+/*
+stochrsi implements the stochastic RSI indicator, the stochastic oscillator applied to the RSI values
+
+Stochastic RSI
+- https://www.investopedia.com/terms/s/stochrsi.asp
+*/
+//go:generate callbackgen -type StochRSI
+type StochRSI struct {
+	// Window is the period of the stochastic oscillator
+	types.IntervalWindow
+
+	// RSIWindow is the period of the RSI, defaults to 14
+	RSIWindow int
+
+	// SmoothK and SmoothD are the SMA periods of %K and %D, default to 3
+	SmoothK int
+	SmoothD int
+
+	RSI RSI
+
+	// Values are the raw stochastic RSI values (0-100)
+	Values types.Float64Slice
+	K      types.Float64Slice
+	D      types.Float64Slice
+
+	EndTime time.Time
+
+	UpdateCallbacks []func(k float64, d float64)
+}
+
+func (inc *StochRSI) periods() (rsiWindow, smoothK, smoothD int) {
+	rsiWindow, smoothK, smoothD = inc.RSIWindow, inc.SmoothK, inc.SmoothD
+	if rsiWindow == 0 {
+		rsiWindow = 14
+	}
+	if smoothK == 0 {
+		smoothK = 3
+	}
+	if smoothD == 0 {
+		smoothD = 3
+	}
+	return rsiWindow, smoothK, smoothD
+}
+
+// Update updates the stochastic RSI with the close price, returns true when both %K and %D are calculated
+func (inc *StochRSI) Update(price float64) bool {
+	rsiWindow, smoothK, smoothD := inc.periods()
+
+	inc.RSI.Window = rsiWindow
+	inc.RSI.Update(price)
+
+	if len(inc.RSI.Values) < inc.Window {
+		return false
+	}
+
+	rsiValues := inc.RSI.Values[len(inc.RSI.Values)-inc.Window:]
+	lowest, highest := rsiValues.Min(), rsiValues.Max()
+
+	var value float64
+	if highest > lowest {
+		value = 100.0 * (inc.RSI.Last() - lowest) / (highest - lowest)
+	}
+
+	inc.Values.Push(value)
+	if len(inc.Values) < smoothK {
+		return false
+	}
+
+	inc.K.Push(inc.Values.Tail(smoothK).Mean())
+	if len(inc.K) < smoothD {
+		return false
+	}
+
+	inc.D.Push(inc.K.Tail(smoothD).Mean())
+
+	if len(inc.D) > MaxNumOfStochRSI {
+		inc.Values = inc.Values[MaxNumOfStochRSITruncateSize-1:]
+		inc.K = inc.K[MaxNumOfStochRSITruncateSize-1:]
+		inc.D = inc.D[MaxNumOfStochRSITruncateSize-1:]
+	}
+
+	return true
+}
+
+func (inc *StochRSI) LastK() float64 {
+	if len(inc.K) == 0 {
+		return 0.0
+	}
+	return inc.K[len(inc.K)-1]
+}
+
+func (inc *StochRSI) LastD() float64 {
+	if len(inc.D) == 0 {
+		return 0.0
+	}
+	return inc.D[len(inc.D)-1]
+}
+
+func (inc *StochRSI) calculateAndUpdate(kLines []types.KLine) {
+	for _, k := range kLines {
+		if inc.EndTime != zeroTime && !k.EndTime.After(inc.EndTime) {
+			continue
+		}
+
+		inc.EndTime = k.EndTime
+		if inc.Update(KLineClosePriceMapper(k)) {
+			inc.EmitUpdate(inc.LastK(), inc.LastD())
+		}
+	}
+}
+
+func (inc *StochRSI) handleKLineWindowUpdate(interval types.Interval, window types.KLineWindow) {
+	if inc.Interval != interval {
+		return
+	}
+
+	inc.calculateAndUpdate(window)
+}
+
+func (inc *StochRSI) Bind(updater KLineWindowUpdater) {
+	updater.OnKLineWindowUpdate(inc.handleKLineWindowUpdate)
+}
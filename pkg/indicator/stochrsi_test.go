@@ -0,0 +1,28 @@
+package indicator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestStochRSI(t *testing.T) {
+	kLines := buildTrendTestKLines()
+
+	var updates int
+	stochRSI := StochRSI{IntervalWindow: types.IntervalWindow{Interval: types.Interval1m, Window: 5}, RSIWindow: 5}
+	stochRSI.OnUpdate(func(k, d float64) {
+		updates++
+	})
+
+	stochRSI.calculateAndUpdate(kLines[:15])
+	stochRSI.calculateAndUpdate(kLines)
+
+	assert.Equal(t, 17, updates)
+	assert.Len(t, stochRSI.Values, 21)
+	assert.Len(t, stochRSI.K, 19)
+	assert.InDelta(t, 14.710050, stochRSI.LastK(), 1e-6)
+	assert.InDelta(t, 6.046869, stochRSI.LastD(), 1e-6)
+}
@@ -0,0 +1,143 @@
+package indicator
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const MaxNumOfSuperTrend = 5_000
+const MaxNumOfSuperTrendTruncateSize = 100
+
+/*
+supertrend implements the SuperTrend indicator, the bands are the middle of the high low range with the
+multiplier of ATR, the line follows the lower band in the uptrend and the upper band in the downtrend.
+
+SuperTrend
+- https://www.investopedia.com/supertrend-indicator-7976167
+*/
+//go:generate callbackgen -type SuperTrend
+type SuperTrend struct {
+	types.IntervalWindow
+
+	// Multiplier is the ATR multiplier of the bands, defaults to 3
+	Multiplier float64
+
+	Values types.Float64Slice
+
+	// Trends is the direction of each value, 1 for the uptrend and -1 for the downtrend
+	Trends []int
+
+	ATR ATR
+
+	UpperBand     float64
+	LowerBand     float64
+	PreviousClose float64
+	EndTime       time.Time
+
+	UpdateCallbacks []func(value float64, trend int)
+}
+
+// Update updates the SuperTrend with the new kline, the values are calculated after Window klines
+func (inc *SuperTrend) Update(kLine types.KLine) bool {
+	multiplier := inc.Multiplier
+	if multiplier == 0 {
+		multiplier = 3.0
+	}
+
+	inc.ATR.Window = inc.Window
+	inc.ATR.Update(kLine)
+
+	previousClose := inc.PreviousClose
+	inc.PreviousClose = kLine.Close
+
+	if len(inc.ATR.Values) == 0 {
+		return false
+	}
+
+	hl2 := (kLine.High + kLine.Low) / 2.0
+	upperBand := hl2 + multiplier*inc.ATR.Last()
+	lowerBand := hl2 - multiplier*inc.ATR.Last()
+
+	var trend int
+	if len(inc.Trends) == 0 {
+		inc.UpperBand = upperBand
+		inc.LowerBand = lowerBand
+
+		trend = -1
+		if kLine.Close >= hl2 {
+			trend = 1
+		}
+	} else {
+		// the bands only move toward the price unless the previous close breaks the band
+		if upperBand < inc.UpperBand || previousClose > inc.UpperBand {
+			inc.UpperBand = upperBand
+		}
+
+		if lowerBand > inc.LowerBand || previousClose < inc.LowerBand {
+			inc.LowerBand = lowerBand
+		}
+
+		trend = inc.LastTrend()
+		if trend == -1 && kLine.Close > inc.UpperBand {
+			trend = 1
+		} else if trend == 1 && kLine.Close < inc.LowerBand {
+			trend = -1
+		}
+	}
+
+	value := inc.UpperBand
+	if trend == 1 {
+		value = inc.LowerBand
+	}
+
+	inc.Values.Push(value)
+	inc.Trends = append(inc.Trends, trend)
+
+	if len(inc.Values) > MaxNumOfSuperTrend {
+		inc.Values = inc.Values[MaxNumOfSuperTrendTruncateSize-1:]
+		inc.Trends = inc.Trends[MaxNumOfSuperTrendTruncateSize-1:]
+	}
+
+	return true
+}
+
+func (inc *SuperTrend) Last() float64 {
+	if len(inc.Values) == 0 {
+		return 0.0
+	}
+	return inc.Values[len(inc.Values)-1]
+}
+
+// LastTrend returns the last direction, 1 for the uptrend and -1 for the downtrend, 0 if not calculated yet
+func (inc *SuperTrend) LastTrend() int {
+	if len(inc.Trends) == 0 {
+		return 0
+	}
+	return inc.Trends[len(inc.Trends)-1]
+}
+
+func (inc *SuperTrend) calculateAndUpdate(kLines []types.KLine) {
+	for _, k := range kLines {
+		if inc.EndTime != zeroTime && !k.EndTime.After(inc.EndTime) {
+			continue
+		}
+
+		inc.EndTime = k.EndTime
+		if inc.Update(k) {
+			inc.EmitUpdate(inc.Last(), inc.LastTrend())
+		}
+	}
+}
+
+func (inc *SuperTrend) handleKLineWindowUpdate(interval types.Interval, window types.KLineWindow) {
+	if inc.Interval != interval {
+		return
+	}
+
+	inc.calculateAndUpdate(window)
+}
+
+func (inc *SuperTrend) Bind(updater KLineWindowUpdater) {
+	updater.OnKLineWindowUpdate(inc.handleKLineWindowUpdate)
+}
@@ -0,0 +1,48 @@
+package indicator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var trendTestHigh = []float64{100.3, 100.01, 97.38, 93.86, 94.97, 94.12, 92.46, 93.39, 89.19, 89.08, 90.29, 89.67, 88.87, 89.43, 91.69, 91.79, 92.7, 92.96, 90.81, 90.28, 91.63, 92.73, 95.5, 94.71, 97.46, 96.24, 95.1, 91.44, 89.52, 89.19}
+var trendTestLow = []float64{97.64, 95.64, 93.65, 93.14, 92.62, 89.31, 89.26, 88.37, 86.2, 85.66, 87.62, 86.52, 86.3, 87.41, 88.03, 88.54, 88.78, 88.32, 87.66, 86.59, 87.36, 90.09, 90.62, 92.21, 92.95, 92.84, 90.98, 87.48, 86.02, 85.9}
+var trendTestClose = []float64{98.94, 96.37, 93.72, 93.32, 92.87, 91.21, 91.67, 88.95, 86.82, 88.72, 89.55, 86.93, 88.01, 88.52, 90.29, 90.74, 92.12, 89.83, 87.74, 88.75, 91.0, 91.57, 93.61, 94.59, 95.47, 94.18, 91.32, 89.02, 86.8, 89.03}
+
+func buildTrendTestKLines() (kLines []types.KLine) {
+	for i := range trendTestHigh {
+		kLines = append(kLines, types.KLine{
+			High:    trendTestHigh[i],
+			Low:     trendTestLow[i],
+			Close:   trendTestClose[i],
+			EndTime: time.Unix(int64(i*60), 0),
+		})
+	}
+	return kLines
+}
+
+func TestSuperTrend(t *testing.T) {
+	kLines := buildTrendTestKLines()
+
+	var updates int
+	st := SuperTrend{IntervalWindow: types.IntervalWindow{Interval: types.Interval1m, Window: 5}, Multiplier: 2.0}
+	st.OnUpdate(func(value float64, trend int) {
+		updates++
+	})
+
+	// the klines are updated incrementally
+	st.calculateAndUpdate(kLines[:20])
+	st.calculateAndUpdate(kLines)
+
+	assert.Equal(t, 26, updates)
+	assert.Equal(t, []int{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, 1, 1, 1, 1, 1, -1, -1}, st.Trends)
+	assert.InDelta(t, 99.327, st.Values[0], 1e-6)
+	assert.InDelta(t, 86.478310, st.Values[19], 1e-6)
+	assert.InDelta(t, 87.815648, st.Values[23], 1e-6)
+	assert.InDelta(t, 94.816935, st.Last(), 1e-6)
+	assert.Equal(t, -1, st.LastTrend())
+}
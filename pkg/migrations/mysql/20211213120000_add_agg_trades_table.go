@@ -0,0 +1,34 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upAddAggTradesTable, downAddAggTradesTable)
+
+}
+
+func upAddAggTradesTable(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `agg_trades`\n(\n    `gid`            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,\n    `id`             BIGINT UNSIGNED NOT NULL,\n    `exchange`       VARCHAR(24)     NOT NULL DEFAULT '',\n    `symbol`         VARCHAR(20)     NOT NULL,\n    `price`          DECIMAL(16, 8)  NOT NULL,\n    `quantity`       DECIMAL(16, 8)  NOT NULL,\n    `first_trade_id` BIGINT UNSIGNED NOT NULL DEFAULT 0,\n    `last_trade_id`  BIGINT UNSIGNED NOT NULL DEFAULT 0,\n    `is_buyer_maker` BOOLEAN         NOT NULL DEFAULT FALSE,\n    `traded_at`      DATETIME(3)     NOT NULL,\n    PRIMARY KEY (`gid`),\n    UNIQUE KEY `agg_trades_id` (`exchange`, `symbol`, `id`),\n    INDEX `agg_trades_traded_at` (`exchange`, `symbol`, `traded_at`)\n);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downAddAggTradesTable(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS `agg_trades`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
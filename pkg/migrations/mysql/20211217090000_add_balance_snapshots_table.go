@@ -0,0 +1,34 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upAddBalanceSnapshotsTable, downAddBalanceSnapshotsTable)
+
+}
+
+func upAddBalanceSnapshotsTable(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `balance_snapshots`\n(\n    `gid`      BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,\n    `session`  VARCHAR(30)     NOT NULL,\n    `exchange` VARCHAR(30)     NOT NULL,\n    `currency` VARCHAR(12)     NOT NULL,\n    `value`    DECIMAL(32, 8)  NOT NULL,\n    `time`     DATETIME(3)     NOT NULL,\n    PRIMARY KEY (`gid`),\n    INDEX `balance_snapshots_session_time` (`session`, `time`)\n);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downAddBalanceSnapshotsTable(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS `balance_snapshots`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
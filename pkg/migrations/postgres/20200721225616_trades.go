@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upTrades, downTrades)
+
+}
+
+func upTrades(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE \"trades\"\n(\n    \"gid\"            BIGSERIAL      NOT NULL,\n    \"id\"             BIGINT,\n    \"exchange\"       VARCHAR(24)    NOT NULL DEFAULT '',\n    \"symbol\"         VARCHAR(8)     NOT NULL,\n    \"price\"          DECIMAL(16, 8) NOT NULL,\n    \"quantity\"       DECIMAL(16, 8) NOT NULL,\n    \"quote_quantity\" DECIMAL(16, 8) NOT NULL,\n    \"fee\"            DECIMAL(16, 8) NOT NULL,\n    \"fee_currency\"   VARCHAR(4)     NOT NULL,\n    \"is_buyer\"       BOOLEAN        NOT NULL DEFAULT FALSE,\n    \"is_maker\"       BOOLEAN        NOT NULL DEFAULT FALSE,\n    \"side\"           VARCHAR(4)     NOT NULL DEFAULT '',\n    \"traded_at\"      TIMESTAMP(3)   NOT NULL,\n    PRIMARY KEY (\"gid\")\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE UNIQUE INDEX \"trades_id\" ON \"trades\" (\"id\");")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downTrades(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS \"trades\";")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upFixSymbolLength, downFixSymbolLength)
+
+}
+
+func upFixSymbolLength(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE trades ALTER COLUMN symbol TYPE VARCHAR(9);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE orders ALTER COLUMN symbol TYPE VARCHAR(9);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downFixSymbolLength(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE trades ALTER COLUMN symbol TYPE VARCHAR(8);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE orders ALTER COLUMN symbol TYPE VARCHAR(8);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upIncreaseSymbolLength, downIncreaseSymbolLength)
+
+}
+
+func upIncreaseSymbolLength(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE \"klines\"\n    ALTER COLUMN \"symbol\" TYPE VARCHAR(12);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE \"okex_klines\"\n    ALTER COLUMN \"symbol\" TYPE VARCHAR(12);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE \"binance_klines\"\n    ALTER COLUMN \"symbol\" TYPE VARCHAR(12);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE \"max_klines\"\n    ALTER COLUMN \"symbol\" TYPE VARCHAR(12);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downIncreaseSymbolLength(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE \"klines\"\n    ALTER COLUMN \"symbol\" TYPE VARCHAR(10);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE \"okex_klines\"\n    ALTER COLUMN \"symbol\" TYPE VARCHAR(10);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE \"binance_klines\"\n    ALTER COLUMN \"symbol\" TYPE VARCHAR(10);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE \"max_klines\"\n    ALTER COLUMN \"symbol\" TYPE VARCHAR(10);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upIncreaseDecimalLength, downIncreaseDecimalLength)
+
+}
+
+func upIncreaseDecimalLength(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE \"klines\"\n    ALTER COLUMN \"volume\" TYPE DECIMAL(20, 8);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE \"okex_klines\"\n    ALTER COLUMN \"volume\" TYPE DECIMAL(20, 8);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE \"binance_klines\"\n    ALTER COLUMN \"volume\" TYPE DECIMAL(20, 8);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE \"max_klines\"\n    ALTER COLUMN \"volume\" TYPE DECIMAL(20, 8);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downIncreaseDecimalLength(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE \"klines\"\n    ALTER COLUMN \"volume\" TYPE DECIMAL(16, 8);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE \"okex_klines\"\n    ALTER COLUMN \"volume\" TYPE DECIMAL(16, 8);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE \"binance_klines\"\n    ALTER COLUMN \"volume\" TYPE DECIMAL(16, 8);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE \"max_klines\"\n    ALTER COLUMN \"volume\" TYPE DECIMAL(16, 8);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
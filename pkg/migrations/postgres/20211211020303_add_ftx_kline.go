@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upAddFtxKline, downAddFtxKline)
+
+}
+
+func upAddFtxKline(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS \"ftx_klines\"\n(\n    \"gid\"                    BIGSERIAL      NOT NULL,\n    \"exchange\"               VARCHAR(10)    NOT NULL,\n    \"start_time\"             TIMESTAMP(3)   NOT NULL,\n    \"end_time\"               TIMESTAMP(3)   NOT NULL,\n    \"interval\"               VARCHAR(3)     NOT NULL,\n    \"symbol\"                 VARCHAR(12)    NOT NULL,\n    \"open\"                   DECIMAL(16, 8) NOT NULL,\n    \"high\"                   DECIMAL(16, 8) NOT NULL,\n    \"low\"                    DECIMAL(16, 8) NOT NULL,\n    \"close\"                  DECIMAL(16, 8) NOT NULL DEFAULT 0.0,\n    \"volume\"                 DECIMAL(20, 8) NOT NULL DEFAULT 0.0,\n    \"closed\"                 BOOLEAN        NOT NULL DEFAULT TRUE,\n    \"last_trade_id\"          BIGINT         NOT NULL DEFAULT 0,\n    \"num_trades\"             BIGINT         NOT NULL DEFAULT 0,\n    \"quote_volume\"           DECIMAL(32, 4) NOT NULL DEFAULT 0.0,\n    \"taker_buy_base_volume\"  DECIMAL(32, 8) NOT NULL,\n    \"taker_buy_quote_volume\" DECIMAL(32, 4) NOT NULL DEFAULT 0.0,\n    PRIMARY KEY (\"gid\")\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX \"ftx_klines_end_time_symbol_interval\" ON \"ftx_klines\" (\"end_time\", \"symbol\", \"interval\");")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downAddFtxKline(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS \"ftx_klines\";")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
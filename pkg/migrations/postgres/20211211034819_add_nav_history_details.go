@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upAddNavHistoryDetails, downAddNavHistoryDetails)
+
+}
+
+func upAddNavHistoryDetails(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE \"nav_history_details\"\n(\n    \"gid\"            BIGSERIAL      NOT NULL,\n    \"exchange\"       VARCHAR(30)    NOT NULL,\n    \"subaccount\"     VARCHAR(30)    NOT NULL,\n    \"time\"           TIMESTAMP(3)   NOT NULL,\n    \"currency\"       VARCHAR(12)    NOT NULL,\n    \"balance_in_usd\" DECIMAL(32, 8) NOT NULL DEFAULT 0.0,\n    \"balance_in_btc\" DECIMAL(32, 8) NOT NULL DEFAULT 0.0,\n    \"balance\"        DECIMAL(32, 8) NOT NULL DEFAULT 0.0,\n    \"available\"      DECIMAL(32, 8) NOT NULL DEFAULT 0.0,\n    \"locked\"         DECIMAL(32, 8) NOT NULL DEFAULT 0.0,\n    PRIMARY KEY (\"gid\")\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX \"idx_nav_history_details\" ON \"nav_history_details\" (\"time\", \"currency\", \"exchange\");")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downAddNavHistoryDetails(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS \"nav_history_details\";")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upUpdateFeeCurrencyLength, downUpdateFeeCurrencyLength)
+
+}
+
+func upUpdateFeeCurrencyLength(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE trades ALTER COLUMN fee_currency TYPE VARCHAR(10);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downUpdateFeeCurrencyLength(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE trades ALTER COLUMN fee_currency TYPE VARCHAR(4);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upAddProfitsTable, downAddProfitsTable)
+
+}
+
+func upAddProfitsTable(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE \"profits\"\n(\n    \"gid\"                  BIGSERIAL      NOT NULL,\n    \"strategy\"             VARCHAR(32)    NOT NULL,\n    \"strategy_instance_id\" VARCHAR(64)    NOT NULL DEFAULT '',\n    \"symbol\"               VARCHAR(20)    NOT NULL,\n    \"base_currency\"        VARCHAR(10)    NOT NULL,\n    \"quote_currency\"       VARCHAR(10)    NOT NULL,\n    \"profit\"               DECIMAL(16, 8) NOT NULL,\n    \"net_profit\"           DECIMAL(16, 8) NOT NULL,\n    \"trade_amount\"         DECIMAL(16, 8) NOT NULL,\n    \"time\"                 TIMESTAMP(3)   NOT NULL,\n    PRIMARY KEY (\"gid\")\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX \"profits_strategy_time\" ON \"profits\" (\"strategy\", \"time\");")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downAddProfitsTable(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS \"profits\";")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upAddBalanceSnapshotsTable, downAddBalanceSnapshotsTable)
+
+}
+
+func upAddBalanceSnapshotsTable(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE \"balance_snapshots\"\n(\n    \"gid\"      BIGSERIAL      NOT NULL,\n    \"session\"  VARCHAR(30)    NOT NULL,\n    \"exchange\" VARCHAR(30)    NOT NULL,\n    \"currency\" VARCHAR(12)    NOT NULL,\n    \"value\"    DECIMAL(32, 8) NOT NULL,\n    \"time\"     TIMESTAMP(3)   NOT NULL,\n    PRIMARY KEY (\"gid\")\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX \"balance_snapshots_session_time\" ON \"balance_snapshots\" (\"session\", \"time\");")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downAddBalanceSnapshotsTable(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS \"balance_snapshots\";")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
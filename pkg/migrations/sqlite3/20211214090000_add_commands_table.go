@@ -0,0 +1,39 @@
+package sqlite3
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upAddCommandsTable, downAddCommandsTable)
+
+}
+
+func upAddCommandsTable(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `commands`\n(\n    `gid`          INTEGER PRIMARY KEY AUTOINCREMENT,\n    `type`         VARCHAR(32) NOT NULL,\n    `strategy`     VARCHAR(64) NOT NULL DEFAULT '',\n    `payload`      TEXT        NOT NULL,\n    `status`       VARCHAR(12) NOT NULL DEFAULT 'pending',\n    `error`        TEXT        NULL,\n    `source`       VARCHAR(32) NOT NULL DEFAULT '',\n    `created_at`   DATETIME(3) NOT NULL,\n    `processed_at` DATETIME(3) NULL\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX `commands_status` ON `commands` (`status`, `gid`);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downAddCommandsTable(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS `commands`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,34 @@
+package sqlite3
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upAddPersistenceTable, downAddPersistenceTable)
+
+}
+
+func upAddPersistenceTable(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `persistence`\n(\n    `id`         VARCHAR(255) NOT NULL PRIMARY KEY,\n    `value`      TEXT         NOT NULL,\n    `updated_at` DATETIME(3)  NOT NULL\n);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downAddPersistenceTable(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS `persistence`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
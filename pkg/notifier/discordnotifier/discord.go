@@ -0,0 +1,325 @@
+package discordnotifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// discord allows up to 10 embeds and 2000 characters of the content in a message
+const (
+	maxEmbeds        = 10
+	maxContentLength = 2000
+	maxRetries       = 3
+)
+
+const defaultTimeout = 10 * time.Second
+
+// EmbedField is the field of the discord embed
+type EmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+type EmbedFooter struct {
+	Text string `json:"text"`
+}
+
+// Embed is the rich content of the discord message
+type Embed struct {
+	Title       string       `json:"title,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Color       int          `json:"color,omitempty"`
+	Fields      []EmbedField `json:"fields,omitempty"`
+	Footer      *EmbedFooter `json:"footer,omitempty"`
+}
+
+// WebhookMessage is the json body of the discord webhook request
+type WebhookMessage struct {
+	Username string  `json:"username,omitempty"`
+	Content  string  `json:"content,omitempty"`
+	Embeds   []Embed `json:"embeds,omitempty"`
+}
+
+type notifyTask struct {
+	WebhookURL string
+	Message    WebhookMessage
+}
+
+type slackAttachmentCreator interface {
+	SlackAttachment() slack.Attachment
+}
+
+// Notifier sends the notifications to the discord channels through the channel webhooks,
+// the routed channel names (e.g., the symbol channels) are mapped to the webhook URLs of the discord channels.
+type Notifier struct {
+	client *http.Client
+
+	// webhookURL is the webhook URL of the default channel
+	webhookURL string
+
+	// channels maps the channel names to the webhook URLs
+	channels map[string]string
+
+	username string
+
+	taskC chan notifyTask
+}
+
+type NotifyOption func(notifier *Notifier)
+
+// WithChannels maps the routed channel names to the webhook URLs
+func WithChannels(channels map[string]string) NotifyOption {
+	return func(notifier *Notifier) {
+		for name, webhookURL := range channels {
+			notifier.channels[name] = webhookURL
+		}
+	}
+}
+
+// WithUsername overrides the default username of the webhook
+func WithUsername(username string) NotifyOption {
+	return func(notifier *Notifier) {
+		notifier.username = username
+	}
+}
+
+func New(webhookURL string, options ...NotifyOption) *Notifier {
+	notifier := &Notifier{
+		client:     &http.Client{Timeout: defaultTimeout},
+		webhookURL: webhookURL,
+		channels:   make(map[string]string),
+		taskC:      make(chan notifyTask, 100),
+	}
+
+	for _, o := range options {
+		o(notifier)
+	}
+
+	go notifier.worker()
+
+	return notifier
+}
+
+func (n *Notifier) worker() {
+	ctx := context.Background()
+	for task := range n.taskC {
+		if err := n.post(ctx, task.WebhookURL, task.Message); err != nil {
+			log.WithError(err).
+				WithField("service", "discord").
+				Errorf("discord webhook error: %s", err.Error())
+		}
+	}
+}
+
+// post sends the message to the webhook, the request is retried after the delay of the rate limit response
+func (n *Notifier) post(ctx context.Context, webhookURL string, message WebhookMessage) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; ; i++ {
+		req, err := http.NewRequest(http.MethodPost, webhookURL, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := n.client.Do(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+
+		switch {
+		case resp.StatusCode >= 200 && resp.StatusCode < 300:
+			return nil
+
+		case resp.StatusCode == http.StatusTooManyRequests && i < maxRetries:
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(retryAfter(resp.Header)):
+			}
+
+		default:
+			return fmt.Errorf("unexpected discord webhook response %d: %s", resp.StatusCode, string(respBody))
+		}
+	}
+}
+
+// retryAfter parses the delay of the rate limit response, the Retry-After header is in seconds
+func retryAfter(header http.Header) time.Duration {
+	seconds, err := strconv.ParseFloat(header.Get("Retry-After"), 64)
+	if err != nil || seconds <= 0 {
+		return time.Second
+	}
+
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func (n *Notifier) Notify(obj interface{}, args ...interface{}) {
+	n.NotifyTo("", obj, args...)
+}
+
+// resolveWebhookURL returns the webhook URL of the channel, the default webhook URL is used if the channel is not mapped
+func (n *Notifier) resolveWebhookURL(channel string) string {
+	if webhookURL, ok := n.channels[channel]; ok && len(webhookURL) > 0 {
+		return webhookURL
+	}
+
+	return n.webhookURL
+}
+
+func filterEmbeds(args []interface{}) (embeds []Embed, pureArgs []interface{}) {
+	var firstEmbedOffset = -1
+	for idx, arg := range args {
+		switch a := arg.(type) {
+
+		// concrete type assert first
+		case slack.Attachment:
+			embeds = append(embeds, EmbedFromSlackAttachment(a))
+
+		case slackAttachmentCreator:
+			embeds = append(embeds, EmbedFromSlackAttachment(a.SlackAttachment()))
+
+		case types.PlainText:
+			embeds = append(embeds, Embed{Description: a.PlainText()})
+
+		default:
+			continue
+		}
+
+		if firstEmbedOffset == -1 {
+			firstEmbedOffset = idx
+		}
+	}
+
+	pureArgs = args
+	if firstEmbedOffset > -1 {
+		pureArgs = args[:firstEmbedOffset]
+	}
+
+	return embeds, pureArgs
+}
+
+func (n *Notifier) NotifyTo(channel string, obj interface{}, args ...interface{}) {
+	webhookURL := n.resolveWebhookURL(channel)
+	if len(webhookURL) == 0 {
+		return
+	}
+
+	embeds, pureArgs := filterEmbeds(args)
+
+	var message = WebhookMessage{Username: n.username}
+
+	switch a := obj.(type) {
+	case string:
+		message.Content = fmt.Sprintf(a, pureArgs...)
+		message.Embeds = embeds
+
+	case slack.Attachment:
+		message.Embeds = append([]Embed{EmbedFromSlackAttachment(a)}, embeds...)
+
+	case slackAttachmentCreator:
+		message.Embeds = append([]Embed{EmbedFromSlackAttachment(a.SlackAttachment())}, embeds...)
+
+	case types.PlainText:
+		message.Content = a.PlainText()
+		message.Embeds = embeds
+
+	default:
+		log.Errorf("discord message conversion error, unsupported object: %T %+v", a, a)
+		return
+	}
+
+	if len(message.Content) > maxContentLength {
+		message.Content = message.Content[:maxContentLength-3] + "..."
+	}
+
+	if len(message.Embeds) > maxEmbeds {
+		message.Embeds = message.Embeds[:maxEmbeds]
+	}
+
+	select {
+	case n.taskC <- notifyTask{WebhookURL: webhookURL, Message: message}:
+	case <-time.After(50 * time.Millisecond):
+		return
+	}
+}
+
+// EmbedFromSlackAttachment converts the slack attachment to the discord embed,
+// so that the objects rendered as the slack attachments can be sent to discord as well.
+func EmbedFromSlackAttachment(attachment slack.Attachment) Embed {
+	embed := Embed{
+		Title:       attachment.Title,
+		Description: attachment.Text,
+		Color:       parseColor(attachment.Color),
+	}
+
+	if len(embed.Description) == 0 {
+		embed.Description = attachment.Pretext
+	}
+
+	for _, field := range attachment.Fields {
+		embed.Fields = append(embed.Fields, EmbedField{
+			Name:   field.Title,
+			Value:  field.Value,
+			Inline: field.Short,
+		})
+	}
+
+	if len(attachment.Footer) > 0 {
+		embed.Footer = &EmbedFooter{Text: attachment.Footer}
+	}
+
+	return embed
+}
+
+// parseColor converts the slack attachment color, which is a hex color code or one of good, warning and danger,
+// to the integer color of the discord embed
+func parseColor(color string) int {
+	switch color {
+	case "":
+		return 0
+	case "good":
+		return 0x2EB67D
+	case "warning":
+		return 0xECB22E
+	case "danger":
+		return 0xE01E5A
+	}
+
+	c, err := strconv.ParseInt(strings.TrimPrefix(color, "#"), 16, 32)
+	if err != nil {
+		return 0
+	}
+
+	return int(c)
+}
+
+// ValidateWebhookURL checks if the webhook URL is a discord webhook URL
+func ValidateWebhookURL(webhookURL string) error {
+	if !strings.HasPrefix(webhookURL, "https://") || !strings.Contains(webhookURL, "/api/webhooks/") {
+		return errors.Errorf("invalid discord webhook url %q", webhookURL)
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package discordnotifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+type testAttachment struct{}
+
+func (testAttachment) SlackAttachment() slack.Attachment {
+	return slack.Attachment{
+		Title: "BTCUSDT Trade",
+		Color: "#228B22",
+		Fields: []slack.AttachmentField{
+			{Title: "Price", Value: "50000", Short: true},
+		},
+	}
+}
+
+type receivedMessage struct {
+	Path    string
+	Message WebhookMessage
+}
+
+func newTestWebhookServer(t *testing.T) (*httptest.Server, chan receivedMessage) {
+	messageC := make(chan receivedMessage, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message WebhookMessage
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+		messageC <- receivedMessage{Path: r.URL.Path, Message: message}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	return server, messageC
+}
+
+func receive(t *testing.T, messageC chan receivedMessage) receivedMessage {
+	select {
+	case m := <-messageC:
+		return m
+	case <-time.After(time.Second):
+		t.Fatal("the message is not received")
+	}
+	return receivedMessage{}
+}
+
+func TestNotifier_NotifyTo(t *testing.T) {
+	server, messageC := newTestWebhookServer(t)
+	defer server.Close()
+
+	notifier := New(server.URL+"/default",
+		WithChannels(map[string]string{"btc": server.URL + "/btc"}),
+		WithUsername("bbgo"))
+
+	notifier.NotifyTo("btc", "%s is filled", "order 1", testAttachment{})
+	m := receive(t, messageC)
+	assert.Equal(t, "/btc", m.Path)
+	assert.Equal(t, "bbgo", m.Message.Username)
+	assert.Equal(t, "order 1 is filled", m.Message.Content)
+	if assert.Len(t, m.Message.Embeds, 1) {
+		assert.Equal(t, "BTCUSDT Trade", m.Message.Embeds[0].Title)
+		assert.Equal(t, 0x228B22, m.Message.Embeds[0].Color)
+		assert.Equal(t, []EmbedField{{Name: "Price", Value: "50000", Inline: true}}, m.Message.Embeds[0].Fields)
+	}
+
+	// the unmapped channel falls back to the default webhook
+	notifier.NotifyTo("eth", testAttachment{})
+	m = receive(t, messageC)
+	assert.Equal(t, "/default", m.Path)
+	assert.Empty(t, m.Message.Content)
+	assert.Len(t, m.Message.Embeds, 1)
+}
+
+func TestNotifier_post_RateLimited(t *testing.T) {
+	var requests = 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0.01")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier := &Notifier{client: server.Client()}
+	err := notifier.post(context.Background(), server.URL, WebhookMessage{Content: "hello"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, requests)
+}
+
+func Test_parseColor(t *testing.T) {
+	assert.Equal(t, 0, parseColor(""))
+	assert.Equal(t, 0xE01E5A, parseColor("danger"))
+	assert.Equal(t, 0xDC143C, parseColor("#DC143C"))
+	assert.Equal(t, 0, parseColor("crimson"))
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	assert.NoError(t, ValidateWebhookURL("https://discord.com/api/webhooks/123/abc"))
+	assert.Error(t, ValidateWebhookURL("http://discord.com/api/webhooks/123/abc"))
+	assert.Error(t, ValidateWebhookURL("https://hooks.slack.com/services/T1/B1/abc"))
+}
@@ -0,0 +1,83 @@
+package discordnotifier
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+var logHookLimiter = rate.NewLimiter(rate.Every(time.Minute), 20)
+
+// discord allows up to 25 fields in an embed
+const maxEmbedFields = 25
+
+const logColorError = 0xE01E5A
+
+// LogHook sends the error logs to the discord channel
+type LogHook struct {
+	send func(message WebhookMessage)
+}
+
+// NewLogHook creates the log hook that sends the error logs to the webhook URL
+func NewLogHook(notifier *Notifier, webhookURL string) *LogHook {
+	return &LogHook{send: func(message WebhookMessage) {
+		message.Username = notifier.username
+
+		select {
+		case notifier.taskC <- notifyTask{WebhookURL: webhookURL, Message: message}:
+		case <-time.After(50 * time.Millisecond):
+		}
+	}}
+}
+
+func (h *LogHook) Levels() []logrus.Level {
+	return []logrus.Level{
+		logrus.ErrorLevel,
+		logrus.PanicLevel,
+	}
+}
+
+func (h *LogHook) Fire(e *logrus.Entry) error {
+	// the errors of the discord service itself are not sent, or a failed message would trigger another message
+	if service, ok := e.Data["service"]; ok && service == "discord" {
+		return nil
+	}
+
+	if !logHookLimiter.Allow() {
+		return nil
+	}
+
+	h.send(WebhookMessage{
+		Content: ":balloon: " + e.Message,
+		Embeds:  []Embed{logEntryEmbed(e)},
+	})
+	return nil
+}
+
+// logEntryEmbed renders the log entry as the embed with the sorted fields
+func logEntryEmbed(e *logrus.Entry) Embed {
+	var keys []string
+	for k := range e.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	embed := Embed{
+		Title: strings.ToUpper(e.Level.String()),
+		Color: logColorError,
+	}
+
+	for _, k := range keys {
+		if len(embed.Fields) == maxEmbedFields {
+			break
+		}
+
+		embed.Fields = append(embed.Fields, EmbedField{Name: k, Value: fmt.Sprintf("%v", e.Data[k]), Inline: true})
+	}
+
+	return embed
+}
@@ -0,0 +1,40 @@
+package discordnotifier
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogHook_Fire(t *testing.T) {
+	var messages []WebhookMessage
+	hook := &LogHook{send: func(message WebhookMessage) {
+		messages = append(messages, message)
+	}}
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	logger.AddHook(hook)
+
+	logger.WithError(errors.New("connection reset")).WithField("session", "binance").Error("stream error")
+	if assert.Len(t, messages, 1) {
+		assert.Equal(t, ":balloon: stream error", messages[0].Content)
+		if assert.Len(t, messages[0].Embeds, 1) {
+			assert.Equal(t, "ERROR", messages[0].Embeds[0].Title)
+			assert.Equal(t, []EmbedField{
+				{Name: "error", Value: "connection reset", Inline: true},
+				{Name: "session", Value: "binance", Inline: true},
+			}, messages[0].Embeds[0].Fields)
+		}
+	}
+
+	// the errors of the discord service are skipped
+	logger.WithField("service", "discord").Error("discord webhook error")
+	assert.Len(t, messages, 1)
+
+	logger.Warn("warning is not sent")
+	assert.Len(t, messages, 1)
+}
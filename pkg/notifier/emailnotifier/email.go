@@ -0,0 +1,109 @@
+package emailnotifier
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const defaultPort = 587
+
+// base64 lines should not be longer than 76 characters (RFC 2045)
+const base64LineLength = 76
+
+type Config struct {
+	Host string
+	Port int
+
+	// Username and Password are the credentials of the PLAIN authentication, the authentication is skipped if the username is empty
+	Username string
+	Password string
+
+	From string
+	To   []string
+}
+
+// Sender sends the HTML emails through the SMTP server, the connection is upgraded by STARTTLS if the server supports it
+type Sender struct {
+	config Config
+
+	// sendMail is used for the testing
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+
+	// now is used for the testing
+	now func() time.Time
+}
+
+func New(config Config) (*Sender, error) {
+	if len(config.Host) == 0 {
+		return nil, errors.New("smtp host is required")
+	}
+
+	if len(config.From) == 0 {
+		return nil, errors.New("email sender address is required")
+	}
+
+	if len(config.To) == 0 {
+		return nil, errors.New("email recipient addresses are required")
+	}
+
+	if config.Port == 0 {
+		config.Port = defaultPort
+	}
+
+	return &Sender{
+		config:   config,
+		sendMail: smtp.SendMail,
+		now:      time.Now,
+	}, nil
+}
+
+// Send sends the HTML email to the recipients
+func (s *Sender) Send(subject, html string) error {
+	var auth smtp.Auth
+	if len(s.config.Username) > 0 {
+		auth = smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+	}
+
+	addr := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	msg := BuildMessage(s.config.From, s.config.To, subject, html, s.now())
+	if err := s.sendMail(addr, auth, s.config.From, s.config.To, msg); err != nil {
+		return errors.Wrapf(err, "can not send the email %q", subject)
+	}
+
+	return nil
+}
+
+// BuildMessage builds the MIME message of the HTML email, the body is base64 encoded
+func BuildMessage(from string, to []string, subject, html string, date time.Time) []byte {
+	var buf bytes.Buffer
+	writeHeader := func(key, value string) {
+		buf.WriteString(fmt.Sprintf("%s: %s\r\n", key, value))
+	}
+
+	writeHeader("From", from)
+	writeHeader("To", strings.Join(to, ", "))
+	writeHeader("Subject", mime.QEncoding.Encode("utf-8", subject))
+	writeHeader("Date", date.Format(time.RFC1123Z))
+	writeHeader("MIME-Version", "1.0")
+	writeHeader("Content-Type", `text/html; charset="utf-8"`)
+	writeHeader("Content-Transfer-Encoding", "base64")
+	buf.WriteString("\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(html))
+	for len(encoded) > base64LineLength {
+		buf.WriteString(encoded[:base64LineLength] + "\r\n")
+		encoded = encoded[base64LineLength:]
+	}
+	buf.WriteString(encoded + "\r\n")
+
+	return buf.Bytes()
+}
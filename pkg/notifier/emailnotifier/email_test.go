@@ -0,0 +1,73 @@
+package emailnotifier
+
+import (
+	"encoding/base64"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{From: "bbgo@example.com", To: []string{"me@example.com"}})
+	assert.Error(t, err)
+
+	_, err = New(Config{Host: "smtp.example.com", To: []string{"me@example.com"}})
+	assert.Error(t, err)
+
+	_, err = New(Config{Host: "smtp.example.com", From: "bbgo@example.com"})
+	assert.Error(t, err)
+
+	sender, err := New(Config{Host: "smtp.example.com", From: "bbgo@example.com", To: []string{"me@example.com"}})
+	if assert.NoError(t, err) {
+		assert.Equal(t, defaultPort, sender.config.Port)
+	}
+}
+
+func TestSender_Send(t *testing.T) {
+	sender, err := New(Config{
+		Host:     "smtp.example.com",
+		Port:     2525,
+		Username: "bbgo",
+		Password: "secret",
+		From:     "bbgo@example.com",
+		To:       []string{"me@example.com", "you@example.com"},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var sentAddr string
+	var sentAuth smtp.Auth
+	var sentTo []string
+	var sentMsg []byte
+	sender.now = func() time.Time { return time.Date(2021, 12, 20, 0, 0, 0, 0, time.UTC) }
+	sender.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		sentAddr, sentAuth, sentTo, sentMsg = addr, a, to, msg
+		return nil
+	}
+
+	html := "<h2>BBGO day report</h2>" + strings.Repeat("<p>profit</p>", 10)
+	assert.NoError(t, sender.Send("[bbgo] day report", html))
+	assert.Equal(t, "smtp.example.com:2525", sentAddr)
+	assert.NotNil(t, sentAuth)
+	assert.Equal(t, []string{"me@example.com", "you@example.com"}, sentTo)
+
+	parts := strings.SplitN(string(sentMsg), "\r\n\r\n", 2)
+	if assert.Len(t, parts, 2) {
+		assert.Contains(t, parts[0], "To: me@example.com, you@example.com\r\n")
+		assert.Contains(t, parts[0], "Subject: [bbgo] day report\r\n")
+		assert.Contains(t, parts[0], "Date: Mon, 20 Dec 2021 00:00:00 +0000\r\n")
+		assert.Contains(t, parts[0], "Content-Type: text/html; charset=\"utf-8\"\r\n")
+
+		for _, line := range strings.Split(strings.TrimSpace(parts[1]), "\r\n") {
+			assert.True(t, len(line) <= base64LineLength)
+		}
+
+		body, err := base64.StdEncoding.DecodeString(strings.Replace(parts[1], "\r\n", "", -1))
+		assert.NoError(t, err)
+		assert.Equal(t, html, string(body))
+	}
+}
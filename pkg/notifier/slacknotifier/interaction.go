@@ -0,0 +1,150 @@
+package slacknotifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+// ActionHandler handles the block action of the interactive message,
+// the returned text is posted back to the message as the response
+type ActionHandler func(user slack.User, action slack.BlockAction) (string, error)
+
+// InteractionHandler is the http handler of the slack interactivity request URL,
+// the requests are verified by the signing secret of the slack app.
+type InteractionHandler struct {
+	SigningSecret string
+
+	// AllowedUsers is the slack user IDs that are allowed to trigger the actions, all users are allowed if it's empty
+	AllowedUsers []string
+
+	mu       sync.Mutex
+	handlers map[string]ActionHandler
+
+	// respond posts the response text to the response URL of the interaction
+	respond func(responseURL, text string) error
+}
+
+func NewInteractionHandler(signingSecret string, allowedUsers []string) *InteractionHandler {
+	return &InteractionHandler{
+		SigningSecret: signingSecret,
+		AllowedUsers:  allowedUsers,
+		handlers:      make(map[string]ActionHandler),
+		respond: func(responseURL, text string) error {
+			return slack.PostWebhook(responseURL, &slack.WebhookMessage{Text: text})
+		},
+	}
+}
+
+// OnAction registers the handler of the block action ID
+func (h *InteractionHandler) OnAction(actionID string, handler ActionHandler) {
+	h.mu.Lock()
+	h.handlers[actionID] = handler
+	h.mu.Unlock()
+}
+
+func (h *InteractionHandler) isAllowed(userID string) bool {
+	if len(h.AllowedUsers) == 0 {
+		return true
+	}
+
+	for _, allowed := range h.AllowedUsers {
+		if allowed == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (h *InteractionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if len(h.SigningSecret) == 0 {
+		http.Error(w, "slack signing secret is not configured", http.StatusForbidden)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	verifier, err := slack.NewSecretsVerifier(r.Header, h.SigningSecret)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if _, err := verifier.Write(body); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := verifier.Ensure(); err != nil {
+		http.Error(w, "invalid slack signature", http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var callback slack.InteractionCallback
+	if err := json.Unmarshal([]byte(r.PostForm.Get("payload")), &callback); err != nil {
+		http.Error(w, "invalid interaction payload", http.StatusBadRequest)
+		return
+	}
+
+	// slack expects the response within 3 seconds, so the actions are handled in the background
+	w.WriteHeader(http.StatusOK)
+
+	if callback.Type != slack.InteractionTypeBlockActions {
+		return
+	}
+
+	if !h.isAllowed(callback.User.ID) {
+		log.Warnf("slack user %s (%s) is not allowed to trigger the actions", callback.User.ID, callback.User.Name)
+		h.reply(callback.ResponseURL, ":no_entry: you are not allowed to trigger this action")
+		return
+	}
+
+	for _, action := range callback.ActionCallback.BlockActions {
+		h.mu.Lock()
+		handler, ok := h.handlers[action.ActionID]
+		h.mu.Unlock()
+
+		if !ok {
+			log.Warnf("unsupported slack action %s", action.ActionID)
+			continue
+		}
+
+		go h.handle(handler, callback.User, *action, callback.ResponseURL)
+	}
+}
+
+func (h *InteractionHandler) handle(handler ActionHandler, user slack.User, action slack.BlockAction, responseURL string) {
+	text, err := handler(user, action)
+	if err != nil {
+		log.WithError(err).Errorf("slack action %s error", action.ActionID)
+		text = ":warning: " + err.Error()
+	}
+
+	h.reply(responseURL, text)
+}
+
+func (h *InteractionHandler) reply(responseURL, text string) {
+	if len(responseURL) == 0 || len(text) == 0 {
+		return
+	}
+
+	if err := h.respond(responseURL, text); err != nil {
+		log.WithError(err).Errorf("slack response error")
+	}
+}
@@ -0,0 +1,94 @@
+package slacknotifier
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSigningSecret = "e6b19c573432dcc6b075501d51b51bb8"
+
+func newSignedInteractionRequest(secret, payload string) *http.Request {
+	body := url.Values{"payload": {payload}}.Encode()
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":" + body))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/slack/interactions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func TestInteractionHandler_ServeHTTP(t *testing.T) {
+	payload := `{"type":"block_actions","user":{"id":"U123","name":"bbgo"},"response_url":"https://hooks.slack.com/actions/T1/2/3",` +
+		`"actions":[{"action_id":"cancel_all_orders","block_id":"b1","type":"button","value":"BTCUSDT"}]}`
+
+	actionC := make(chan slack.BlockAction, 1)
+	responseC := make(chan string, 1)
+
+	handler := NewInteractionHandler(testSigningSecret, []string{"U123"})
+	handler.respond = func(responseURL, text string) error {
+		responseC <- text
+		return nil
+	}
+	handler.OnAction("cancel_all_orders", func(user slack.User, action slack.BlockAction) (string, error) {
+		actionC <- action
+		return "canceled by " + user.ID, nil
+	})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, newSignedInteractionRequest(testSigningSecret, payload))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	select {
+	case action := <-actionC:
+		assert.Equal(t, "BTCUSDT", action.Value)
+	case <-time.After(time.Second):
+		t.Fatal("the action is not handled")
+	}
+
+	select {
+	case text := <-responseC:
+		assert.Equal(t, "canceled by U123", text)
+	case <-time.After(time.Second):
+		t.Fatal("the response is not posted")
+	}
+
+	// the request signed by another secret is rejected
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, newSignedInteractionRequest("another secret", payload))
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	// the user not in the allowed list can not trigger the actions
+	handler.AllowedUsers = []string{"U456"}
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, newSignedInteractionRequest(testSigningSecret, payload))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	select {
+	case text := <-responseC:
+		assert.Contains(t, text, "not allowed")
+	case <-time.After(time.Second):
+		t.Fatal("the response is not posted")
+	}
+	assert.Len(t, actionC, 0)
+
+	// the interaction endpoint is disabled without the signing secret
+	handler.SigningSecret = ""
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, newSignedInteractionRequest("", payload))
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+}
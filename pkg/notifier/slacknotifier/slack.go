@@ -19,6 +19,12 @@ type slackAttachmentCreator interface {
 	SlackAttachment() slack.Attachment
 }
 
+// slackBlocksCreator is implemented by the objects that can be rendered as the block kit message blocks,
+// the message blocks take precedence over the attachment
+type slackBlocksCreator interface {
+	SlackBlocks() []slack.Block
+}
+
 type Notifier struct {
 	client  *slack.Client
 	channel string
@@ -58,6 +64,7 @@ func (n *Notifier) worker() {
 			_, _, err := n.client.PostMessageContext(ctx, task.Channel, task.Opts...)
 			if err != nil {
 				log.WithError(err).
+					WithField("service", "slack").
 					WithField("channel", task.Channel).
 					Errorf("slack api error: %s", err.Error())
 			}
@@ -111,6 +118,17 @@ func filterSlackAttachments(args []interface{}) (slackAttachments []slack.Attach
 	return slackAttachments, pureArgs
 }
 
+// blocksFallbackText returns the text of the first section block
+func blocksFallbackText(blocks []slack.Block) string {
+	for _, block := range blocks {
+		if section, ok := block.(*slack.SectionBlock); ok && section.Text != nil {
+			return section.Text.Text
+		}
+	}
+
+	return ""
+}
+
 func (n *Notifier) NotifyTo(channel string, obj interface{}, args ...interface{}) {
 	if len(channel) == 0 {
 		channel = n.channel
@@ -128,6 +146,14 @@ func (n *Notifier) NotifyTo(channel string, obj interface{}, args ...interface{}
 	case slack.Attachment:
 		opts = append(opts, slack.MsgOptionAttachments(append([]slack.Attachment{a}, slackAttachments...)...))
 
+	case slackBlocksCreator:
+		blocks := a.SlackBlocks()
+
+		// the text is the fallback of the notifications that can not render the blocks
+		opts = append(opts, slack.MsgOptionText(blocksFallbackText(blocks), false),
+			slack.MsgOptionBlocks(blocks...),
+			slack.MsgOptionAttachments(slackAttachments...))
+
 	case slackAttachmentCreator:
 		// convert object to slack attachment (if supported)
 		opts = append(opts, slack.MsgOptionAttachments(append([]slack.Attachment{a.SlackAttachment()}, slackAttachments...)...))
@@ -66,6 +66,18 @@ func (it *Interaction) Command(command string, h func(m *telebot.Message)) {
 	it.bot.Handle(command, h)
 }
 
+// IsOwner returns true if the message is sent by the authorized owner
+func (it *Interaction) IsOwner(m *telebot.Message) bool {
+	return it.session != nil && it.session.Owner != nil && m.Sender != nil && m.Sender.ID == it.session.Owner.ID
+}
+
+// Reply sends the message to the chat of the received message
+func (it *Interaction) Reply(m *telebot.Message, message string) {
+	if _, err := it.bot.Send(m.Chat, message); err != nil {
+		log.WithError(err).Error("failed to send telegram message")
+	}
+}
+
 func (it *Interaction) SetAuthToken(token string) {
 	it.AuthToken = token
 }
@@ -145,6 +157,14 @@ func (it *Interaction) HandleHelp(m *telebot.Message) {
 help	- show this help message
 auth	- authorize current telegram user to access telegram bot with authentication token or one-time password. ex. /auth my-token
 info	- show information about current chat
+pause	- pause the strategy. ex. /pause grid:BTCUSDT
+resume	- resume the strategy. ex. /resume grid:BTCUSDT
+cancel	- cancel the order. ex. /cancel binance BTCUSDT 12345
+set	- set the strategy parameter. ex. /set grid:BTCUSDT quantity 0.01
+balance	- show the balances of the sessions. ex. /balance binance
+position	- show the positions of the sessions. ex. /position binance BTCUSDT
+closeposition	- close the position by a market order. ex. /closeposition binance BTCUSDT 0.5
+halt	- halt the order submission until the kill switch is reset
 `
 	if _, err := it.bot.Send(m.Chat, message); err != nil {
 		log.WithError(err).Error("failed to send help message")
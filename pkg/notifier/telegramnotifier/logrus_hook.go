@@ -0,0 +1,67 @@
+package telegramnotifier
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+var logHookLimiter = rate.NewLimiter(rate.Every(time.Minute), 20)
+
+// LogHook sends the error logs to the telegram chats
+type LogHook struct {
+	send func(message string)
+}
+
+// NewLogHook creates the log hook that sends the error logs to the owner, or to all the subscribed chats if broadcast is true
+func NewLogHook(interaction *Interaction, broadcast bool) *LogHook {
+	send := interaction.SendToOwner
+	if broadcast {
+		send = interaction.Broadcast
+	}
+
+	return &LogHook{send: send}
+}
+
+func (h *LogHook) Levels() []logrus.Level {
+	return []logrus.Level{
+		logrus.ErrorLevel,
+		logrus.PanicLevel,
+	}
+}
+
+func (h *LogHook) Fire(e *logrus.Entry) error {
+	// the errors of the telegram service itself are not sent, or a failed message would trigger another message
+	if service, ok := e.Data["service"]; ok && service == "telegram" {
+		return nil
+	}
+
+	if !logHookLimiter.Allow() {
+		return nil
+	}
+
+	h.send(formatLogEntry(e))
+	return nil
+}
+
+// formatLogEntry formats the log entry with the level, the message and the sorted fields
+func formatLogEntry(e *logrus.Entry) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🎈 %s: %s", strings.ToUpper(e.Level.String()), e.Message))
+
+	var keys []string
+	for k := range e.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		sb.WriteString(fmt.Sprintf("\n%s: %v", k, e.Data[k]))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,33 @@
+package telegramnotifier
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogHook_Fire(t *testing.T) {
+	var messages []string
+	hook := &LogHook{send: func(message string) {
+		messages = append(messages, message)
+	}}
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	logger.AddHook(hook)
+
+	logger.WithError(errors.New("connection reset")).WithField("session", "binance").Error("stream error")
+	if assert.Len(t, messages, 1) {
+		assert.Equal(t, "🎈 ERROR: stream error\nerror: connection reset\nsession: binance", messages[0])
+	}
+
+	// the errors of the telegram service are skipped
+	logger.WithField("service", "telegram").Error("failed to send message")
+	assert.Len(t, messages, 1)
+
+	logger.Warn("warning is not sent")
+	assert.Len(t, messages, 1)
+}
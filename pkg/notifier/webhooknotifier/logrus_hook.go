@@ -0,0 +1,55 @@
+package webhooknotifier
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+var logHookLimiter = rate.NewLimiter(rate.Every(time.Minute), 20)
+
+// LogHook sends the error logs to the webhook as the error events
+type LogHook struct {
+	send func(payload Payload)
+}
+
+func NewLogHook(notifier *Notifier) *LogHook {
+	return &LogHook{send: notifier.send}
+}
+
+func (h *LogHook) Levels() []logrus.Level {
+	return []logrus.Level{
+		logrus.ErrorLevel,
+		logrus.PanicLevel,
+	}
+}
+
+func (h *LogHook) Fire(e *logrus.Entry) error {
+	// the errors of the webhook service itself are not sent, or a failed request would trigger another request
+	if service, ok := e.Data["service"]; ok && service == "webhook" {
+		return nil
+	}
+
+	if !logHookLimiter.Allow() {
+		return nil
+	}
+
+	// the error values are converted to strings, since the errors are marshalled as empty json objects
+	fields := make(map[string]interface{}, len(e.Data))
+	for k, v := range e.Data {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+
+		fields[k] = v
+	}
+
+	h.send(Payload{
+		Event:   EventError,
+		Message: fmt.Sprintf("%s: %s", e.Level.String(), e.Message),
+		Fields:  fields,
+	})
+	return nil
+}
@@ -0,0 +1,32 @@
+package webhooknotifier
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogHook_Fire(t *testing.T) {
+	var payloads []Payload
+	hook := &LogHook{send: func(payload Payload) {
+		payloads = append(payloads, payload)
+	}}
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	logger.AddHook(hook)
+
+	logger.WithError(errors.New("connection reset")).WithField("session", "binance").Error("stream error")
+	if assert.Len(t, payloads, 1) {
+		assert.Equal(t, EventError, payloads[0].Event)
+		assert.Equal(t, "error: stream error", payloads[0].Message)
+		assert.Equal(t, map[string]interface{}{"error": "connection reset", "session": "binance"}, payloads[0].Fields)
+	}
+
+	// the errors of the webhook service are skipped
+	logger.WithField("service", "webhook").Error("webhook notification error")
+	assert.Len(t, payloads, 1)
+}
@@ -0,0 +1,318 @@
+package webhooknotifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// Event is the type of the notification sent to the webhook
+type Event string
+
+const (
+	EventTrade    Event = "trade"
+	EventOrder    Event = "order"
+	EventPosition Event = "position"
+	EventError    Event = "error"
+
+	// EventMessage is the text notification without the trade, order or position object
+	EventMessage Event = "message"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// ValidateEvent returns an error if the event is not supported
+func ValidateEvent(event Event) error {
+	switch event {
+	case EventTrade, EventOrder, EventPosition, EventError, EventMessage:
+		return nil
+	}
+
+	return fmt.Errorf("unsupported webhook notification event %q", event)
+}
+
+// Payload is the default json body of the webhook request, it's also the data of the body templates
+type Payload struct {
+	Event   Event     `json:"event"`
+	Channel string    `json:"channel,omitempty"`
+	Time    time.Time `json:"time"`
+
+	// Message is the text of the notification, the objects are rendered as the plain text
+	Message string `json:"message,omitempty"`
+
+	Trade       *types.Trade       `json:"trade,omitempty"`
+	Order       *types.Order       `json:"order,omitempty"`
+	SubmitOrder *types.SubmitOrder `json:"submitOrder,omitempty"`
+	Position    *types.Position    `json:"position,omitempty"`
+
+	// Fields is the fields of the error log
+	Fields map[string]interface{} `json:"fields,omitempty"`
+}
+
+// templateFuncs are the functions of the body templates, e.g., {"text": {{ json .Message }}}
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		out, err := json.Marshal(v)
+		return string(out), err
+	},
+}
+
+// ParseTemplate parses the body template with the template functions
+func ParseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Parse(text)
+}
+
+type Config struct {
+	URL string
+
+	// Events filters the events to send, all the events are sent if it's empty
+	Events []Event
+
+	// Headers are the extra headers of the requests, e.g., the authorization header
+	Headers map[string]string
+
+	// Template is the body template of all the events, the json payload is sent if it's empty
+	Template string
+
+	// Templates overrides the body template by the event
+	Templates map[Event]string
+
+	Timeout time.Duration
+}
+
+// Notifier posts the trade, order, position and error notifications to the webhook URL,
+// the request body is the json payload or rendered by the go templates.
+type Notifier struct {
+	url     string
+	client  *http.Client
+	headers map[string]string
+	events  map[Event]struct{}
+
+	template  *template.Template
+	templates map[Event]*template.Template
+
+	taskC chan Payload
+
+	// now is used for the testing
+	now func() time.Time
+}
+
+func New(config Config) (*Notifier, error) {
+	if len(config.URL) == 0 {
+		return nil, errors.New("webhook url is required")
+	}
+
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+
+	notifier := &Notifier{
+		url:       config.URL,
+		client:    &http.Client{Timeout: timeout},
+		headers:   config.Headers,
+		events:    make(map[Event]struct{}),
+		templates: make(map[Event]*template.Template),
+		taskC:     make(chan Payload, 100),
+		now:       time.Now,
+	}
+
+	for _, event := range config.Events {
+		if err := ValidateEvent(event); err != nil {
+			return nil, err
+		}
+
+		notifier.events[event] = struct{}{}
+	}
+
+	if len(config.Template) > 0 {
+		tpl, err := ParseTemplate("webhook", config.Template)
+		if err != nil {
+			return nil, errors.Wrap(err, "can not parse the webhook template")
+		}
+
+		notifier.template = tpl
+	}
+
+	for event, text := range config.Templates {
+		if err := ValidateEvent(event); err != nil {
+			return nil, err
+		}
+
+		tpl, err := ParseTemplate(string(event), text)
+		if err != nil {
+			return nil, errors.Wrapf(err, "can not parse the %s webhook template", event)
+		}
+
+		notifier.templates[event] = tpl
+	}
+
+	go notifier.worker()
+
+	return notifier, nil
+}
+
+func (n *Notifier) worker() {
+	ctx := context.Background()
+	for payload := range n.taskC {
+		if err := n.post(ctx, payload); err != nil {
+			log.WithError(err).
+				WithField("service", "webhook").
+				Errorf("webhook notification error: %s", err.Error())
+		}
+	}
+}
+
+// render renders the request body of the payload, the json payload is used if there is no template
+func (n *Notifier) render(payload Payload) ([]byte, error) {
+	tpl, ok := n.templates[payload.Event]
+	if !ok {
+		tpl = n.template
+	}
+
+	if tpl == nil {
+		return json.Marshal(payload)
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, payload); err != nil {
+		return nil, errors.Wrapf(err, "can not render the %s webhook template", payload.Event)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (n *Notifier) post(ctx context.Context, payload Payload) error {
+	body, err := n.render(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range n.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := n.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected webhook response %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
+
+// send queues the payload if the event is enabled
+func (n *Notifier) send(payload Payload) {
+	if len(n.events) > 0 {
+		if _, ok := n.events[payload.Event]; !ok {
+			return
+		}
+	}
+
+	payload.Time = n.now()
+
+	select {
+	case n.taskC <- payload:
+	case <-time.After(50 * time.Millisecond):
+		return
+	}
+}
+
+func (n *Notifier) Notify(obj interface{}, args ...interface{}) {
+	n.NotifyTo("", obj, args...)
+}
+
+func (n *Notifier) NotifyTo(channel string, obj interface{}, args ...interface{}) {
+	payload := newPayload(obj, args...)
+	payload.Channel = channel
+	n.send(payload)
+}
+
+// newPayload creates the payload of the notification, the event is determined by the first trade, order or position
+// object of the notification, the string notification is formatted with the arguments before the first object.
+func newPayload(obj interface{}, args ...interface{}) Payload {
+	var payload = Payload{Event: EventMessage}
+	var firstObjectOffset = -1
+
+	for idx, arg := range append([]interface{}{obj}, args...) {
+		if !payload.setObject(arg) {
+			continue
+		}
+
+		if firstObjectOffset == -1 {
+			firstObjectOffset = idx
+		}
+	}
+
+	switch a := obj.(type) {
+	case string:
+		pureArgs := args
+		if firstObjectOffset > 0 {
+			pureArgs = args[:firstObjectOffset-1]
+		}
+
+		payload.Message = fmt.Sprintf(a, pureArgs...)
+
+	case types.PlainText:
+		payload.Message = a.PlainText()
+
+	case types.Stringer:
+		payload.Message = a.String()
+	}
+
+	return payload
+}
+
+// setObject sets the object of the payload, the first object decides the event
+func (p *Payload) setObject(obj interface{}) bool {
+	var event Event
+
+	switch o := obj.(type) {
+	case types.Trade:
+		p.Trade, event = &o, EventTrade
+	case *types.Trade:
+		p.Trade, event = o, EventTrade
+	case types.Order:
+		p.Order, event = &o, EventOrder
+	case *types.Order:
+		p.Order, event = o, EventOrder
+	case types.SubmitOrder:
+		p.SubmitOrder, event = &o, EventOrder
+	case *types.SubmitOrder:
+		p.SubmitOrder, event = o, EventOrder
+	case *types.Position:
+		p.Position, event = o, EventPosition
+	case *types.LogNotification:
+		p.Fields, event = o.Fields, EventError
+	default:
+		return false
+	}
+
+	if p.Event == EventMessage {
+		p.Event = event
+	}
+
+	return true
+}
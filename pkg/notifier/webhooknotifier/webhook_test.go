@@ -0,0 +1,111 @@
+package webhooknotifier
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type receivedRequest struct {
+	Header http.Header
+	Body   []byte
+}
+
+func newTestServer() (*httptest.Server, chan receivedRequest) {
+	requestC := make(chan receivedRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requestC <- receivedRequest{Header: r.Header, Body: body}
+	}))
+	return server, requestC
+}
+
+func receive(t *testing.T, requestC chan receivedRequest) receivedRequest {
+	select {
+	case r := <-requestC:
+		return r
+	case <-time.After(time.Second):
+		t.Fatal("the request is not received")
+	}
+	return receivedRequest{}
+}
+
+func TestNotifier_Notify(t *testing.T) {
+	server, requestC := newTestServer()
+	defer server.Close()
+
+	notifier, err := New(Config{
+		URL:     server.URL,
+		Events:  []Event{EventTrade, EventOrder},
+		Headers: map[string]string{"Authorization": "Bearer token"},
+		Templates: map[Event]string{
+			EventOrder: `{"text": {{ json .Message }}, "orderID": {{ .Order.OrderID }}}`,
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the trade is sent as the json payload
+	notifier.NotifyTo("btc", &types.Trade{ID: 1, Symbol: "BTCUSDT", Price: 50000.0, Quantity: 0.1})
+	r := receive(t, requestC)
+	assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+	var payload Payload
+	assert.NoError(t, json.Unmarshal(r.Body, &payload))
+	assert.Equal(t, EventTrade, payload.Event)
+	assert.Equal(t, "btc", payload.Channel)
+	if assert.NotNil(t, payload.Trade) {
+		assert.Equal(t, "BTCUSDT", payload.Trade.Symbol)
+	}
+
+	// the order is rendered by the order template
+	notifier.Notify("order %d is \"filled\"", 2, &types.Order{OrderID: 2})
+	r = receive(t, requestC)
+	assert.JSONEq(t, `{"text": "order 2 is \"filled\"", "orderID": 2}`, string(r.Body))
+
+	// the message event is filtered
+	notifier.Notify("hello")
+	select {
+	case <-requestC:
+		t.Fatal("the message event should be filtered")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	_, err := New(Config{})
+	assert.Error(t, err)
+
+	_, err = New(Config{URL: "http://localhost", Events: []Event{"kline"}})
+	assert.Error(t, err)
+
+	_, err = New(Config{URL: "http://localhost", Template: `{{ .Message `})
+	assert.Error(t, err)
+}
+
+func Test_newPayload(t *testing.T) {
+	payload := newPayload("position %s updated", "BTCUSDT", &types.Position{Symbol: "BTCUSDT"})
+	assert.Equal(t, EventPosition, payload.Event)
+	assert.Equal(t, "position BTCUSDT updated", payload.Message)
+	assert.NotNil(t, payload.Position)
+
+	payload = newPayload(types.SubmitOrder{Symbol: "BTCUSDT"})
+	assert.Equal(t, EventOrder, payload.Event)
+	assert.NotNil(t, payload.SubmitOrder)
+
+	payload = newPayload(&types.LogNotification{Level: "error", Message: "stream error", Fields: map[string]interface{}{"session": "binance"}})
+	assert.Equal(t, EventError, payload.Event)
+	assert.Equal(t, map[string]interface{}{"session": "binance"}, payload.Fields)
+
+	payload = newPayload("hello %s", "world")
+	assert.Equal(t, EventMessage, payload.Event)
+	assert.Equal(t, "hello world", payload.Message)
+}
@@ -0,0 +1,90 @@
+package pubsub
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const kafkaRestContentType = "application/vnd.kafka.json.v2+json"
+
+type KafkaRestConfig struct {
+	// URL is the endpoint of the kafka REST proxy, e.g., http://localhost:8082
+	URL string `json:"url" yaml:"url"`
+
+	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+	Password string `json:"password,omitempty" yaml:"password,omitempty"`
+
+	Timeout types.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// KafkaRestPublisher produces the messages to the kafka topics through the kafka REST proxy (v2 API),
+// so that no kafka client library is needed.
+type KafkaRestPublisher struct {
+	config KafkaRestConfig
+	client *http.Client
+}
+
+func NewKafkaRestPublisher(config KafkaRestConfig) *KafkaRestPublisher {
+	timeout := config.Timeout.Duration()
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+
+	return &KafkaRestPublisher{
+		config: config,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+type kafkaRecord struct {
+	Value json.RawMessage `json:"value"`
+}
+
+type kafkaProduceRequest struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+func (p *KafkaRestPublisher) Publish(ctx context.Context, topic string, payload []byte) error {
+	body, err := json.Marshal(kafkaProduceRequest{
+		Records: []kafkaRecord{{Value: payload}},
+	})
+	if err != nil {
+		return err
+	}
+
+	endpoint := strings.TrimSuffix(p.config.URL, "/") + "/topics/" + url.PathEscape(topic)
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", kafkaRestContentType)
+	if len(p.config.Username) > 0 {
+		req.SetBasicAuth(p.config.Username, p.config.Password)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("kafka rest proxy responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (p *KafkaRestPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,41 @@
+package pubsub
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKafkaRestPublisher_Publish(t *testing.T) {
+	var path, contentType, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		contentType = r.Header.Get("Content-Type")
+		data, _ := ioutil.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	publisher := NewKafkaRestPublisher(KafkaRestConfig{URL: server.URL + "/"})
+	err := publisher.Publish(context.Background(), "bbgo.trade", []byte(`{"id":1}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "/topics/bbgo.trade", path)
+	assert.Equal(t, kafkaRestContentType, contentType)
+	assert.JSONEq(t, `{"records":[{"value":{"id":1}}]}`, body)
+}
+
+func TestKafkaRestPublisher_PublishError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	publisher := NewKafkaRestPublisher(KafkaRestConfig{URL: server.URL})
+	err := publisher.Publish(context.Background(), "bbgo.trade", []byte(`{}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,87 @@
+package pubsub
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNATSPublisher_Publish(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer listener.Close()
+
+	type received struct {
+		connect string
+		pub     string
+		payload string
+	}
+
+	receivedC := make(chan received, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		_, _ = io.WriteString(conn, "INFO {\"server_id\":\"test\"}\r\n")
+
+		var r received
+		r.connect, _ = reader.ReadString('\n')
+		if ping, _ := reader.ReadString('\n'); strings.TrimSpace(ping) == "PING" {
+			_, _ = io.WriteString(conn, "PONG\r\n")
+		}
+
+		r.pub, _ = reader.ReadString('\n')
+		r.payload, _ = reader.ReadString('\n')
+		receivedC <- r
+	}()
+
+	publisher := NewNATSPublisher(NATSConfig{URL: "nats://user:secret@" + listener.Addr().String()})
+	defer publisher.Close()
+
+	err = publisher.Publish(context.Background(), "bbgo.trade", []byte(`{"id":1}`))
+	assert.NoError(t, err)
+
+	r := <-receivedC
+	assert.True(t, strings.HasPrefix(r.connect, "CONNECT "))
+	assert.Contains(t, r.connect, `"user":"user"`)
+	assert.Contains(t, r.connect, `"pass":"secret"`)
+	assert.Equal(t, "PUB bbgo.trade 8\r\n", r.pub)
+	assert.Equal(t, "{\"id\":1}\r\n", r.payload)
+}
+
+func TestNATSPublisher_ConnectError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		_, _ = io.WriteString(conn, "INFO {}\r\n")
+		_, _ = reader.ReadString('\n')
+		_, _ = reader.ReadString('\n')
+		_, _ = io.WriteString(conn, "-ERR 'Authorization Violation'\r\n")
+	}()
+
+	publisher := NewNATSPublisher(NATSConfig{URL: "nats://" + listener.Addr().String()})
+	err = publisher.Publish(context.Background(), "bbgo.trade", []byte(`{}`))
+	assert.EqualError(t, err, "nats connect error: -ERR 'Authorization Violation'")
+}
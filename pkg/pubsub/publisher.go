@@ -0,0 +1,11 @@
+package pubsub
+
+import (
+	"context"
+)
+
+// Publisher publishes the message payload to the topic of the external message bus
+type Publisher interface {
+	Publish(ctx context.Context, topic string, payload []byte) error
+	Close() error
+}
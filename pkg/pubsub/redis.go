@@ -0,0 +1,54 @@
+package pubsub
+
+import (
+	"context"
+	"net"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type RedisConfig struct {
+	Host     string `json:"host" yaml:"host"`
+	Port     string `json:"port" yaml:"port"`
+	Password string `json:"password,omitempty" yaml:"password,omitempty"`
+	DB       int    `json:"db" yaml:"db"`
+
+	// MaxLen trims the stream to approximately the max length, zero means no trimming
+	MaxLen int64 `json:"maxLen,omitempty" yaml:"maxLen,omitempty"`
+}
+
+// RedisStreamPublisher appends the messages to the redis streams, the topic is used as the stream key
+type RedisStreamPublisher struct {
+	client *redis.Client
+	maxLen int64
+}
+
+func NewRedisStreamPublisher(config RedisConfig) *RedisStreamPublisher {
+	port := config.Port
+	if len(port) == 0 {
+		port = "6379"
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     net.JoinHostPort(config.Host, port),
+		Password: config.Password,
+		DB:       config.DB,
+	})
+
+	return &RedisStreamPublisher{
+		client: client,
+		maxLen: config.MaxLen,
+	}
+}
+
+func (p *RedisStreamPublisher) Publish(ctx context.Context, topic string, payload []byte) error {
+	return p.client.XAdd(ctx, &redis.XAddArgs{
+		Stream:       topic,
+		MaxLenApprox: p.maxLen,
+		Values:       map[string]interface{}{"data": payload},
+	}).Err()
+}
+
+func (p *RedisStreamPublisher) Close() error {
+	return p.client.Close()
+}
@@ -0,0 +1,231 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWSProvider reads the secret from the AWS Secrets Manager, the reference is the secret id with the optional
+// json field, e.g., ${aws:bbgo/binance#apiKey}. The credentials are read from the standard AWS environment variables.
+type AWSProvider struct {
+	// Region defaults to the AWS_REGION or the AWS_DEFAULT_REGION environment variable
+	Region string
+
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// Endpoint overrides the secrets manager endpoint of the region
+	Endpoint string
+
+	Client *http.Client
+
+	// now is used for the testing
+	now func() time.Time
+}
+
+func (p *AWSProvider) credentials() (region, accessKeyID, secretAccessKey, sessionToken string) {
+	region, accessKeyID, secretAccessKey, sessionToken = p.Region, p.AccessKeyID, p.SecretAccessKey, p.SessionToken
+	if len(region) == 0 {
+		region = os.Getenv("AWS_REGION")
+	}
+	if len(region) == 0 {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+
+	if len(accessKeyID) == 0 {
+		accessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		secretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		sessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+
+	return region, accessKeyID, secretAccessKey, sessionToken
+}
+
+func (p *AWSProvider) GetSecret(ctx context.Context, ref string) (string, error) {
+	region, accessKeyID, secretAccessKey, sessionToken := p.credentials()
+	if len(region) == 0 {
+		return "", errors.New("aws region is not set, please set AWS_REGION")
+	}
+
+	if len(accessKeyID) == 0 || len(secretAccessKey) == 0 {
+		return "", errors.New("aws credentials are not set, please set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+
+	secretID, field := splitField(ref)
+	payload, err := json.Marshal(map[string]string{"SecretId": secretID})
+	if err != nil {
+		return "", err
+	}
+
+	endpoint := p.Endpoint
+	if len(endpoint) == 0 {
+		endpoint = "https://secretsmanager." + region + ".amazonaws.com/"
+	}
+
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	if len(sessionToken) > 0 {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+
+	now := time.Now
+	if p.now != nil {
+		now = p.now
+	}
+	signRequest(req, payload, accessKeyID, secretAccessKey, region, "secretsmanager", now())
+
+	client := p.Client
+	if client == nil {
+		client = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("aws secrets manager responds %s: %s", resp.Status, body)
+	}
+
+	var output struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(body, &output); err != nil {
+		return "", err
+	}
+
+	if len(field) == 0 {
+		return output.SecretString, nil
+	}
+
+	// the key/value secrets are stored as the json object
+	var data map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(output.SecretString))
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err != nil {
+		return "", fmt.Errorf("secret %s is not a json object: %w", secretID, err)
+	}
+
+	return selectField(data, field)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// signRequest signs the request with the AWS signature version 4, the host and the x-amz-* headers are signed
+func signRequest(req *http.Request, payload []byte, accessKeyID, secretAccessKey, region, service string, t time.Time) {
+	t = t.UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if name == "content-type" || strings.HasPrefix(name, "x-amz-") {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+
+	var names []string
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if len(path) == 0 {
+		path = "/"
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		sha256Hex(payload),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyID, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(values url.Values) string {
+	var keys []string
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		vs := values[key]
+		sort.Strings(vs)
+		for _, v := range vs {
+			pairs = append(pairs, awsEscape(key)+"="+awsEscape(v))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+// awsEscape escapes the string by RFC 3986, the space is escaped as %20
+func awsEscape(s string) string {
+	return strings.Replace(url.QueryEscape(s), "+", "%20", -1)
+}
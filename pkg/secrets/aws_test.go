@@ -0,0 +1,81 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_signRequest(t *testing.T) {
+	// the example of the AWS signature version 4 documentation
+	req, err := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	signRequest(req, nil, "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "us-east-1", "iam",
+		time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, "+
+		"SignedHeaders=content-type;host;x-amz-date, "+
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7", req.Header.Get("Authorization"))
+}
+
+func TestAWSProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" ||
+			!strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var input struct {
+			SecretId string
+		}
+		_ = json.NewDecoder(r.Body).Decode(&input)
+
+		switch input.SecretId {
+		case "bbgo/binance":
+			_, _ = w.Write([]byte(`{"Name": "bbgo/binance", "SecretString": "{\"apiKey\": \"key\", \"apiSecret\": \"secret\"}"}`))
+		case "bbgo/token":
+			_, _ = w.Write([]byte(`{"Name": "bbgo/token", "SecretString": "plain-token"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type": "ResourceNotFoundException"}`))
+		}
+	}))
+	defer server.Close()
+
+	provider := &AWSProvider{
+		Region:          "ap-northeast-1",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "SECRET",
+		Endpoint:        server.URL,
+	}
+	ctx := context.Background()
+
+	value, err := provider.GetSecret(ctx, "bbgo/binance#apiSecret")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", value)
+
+	value, err = provider.GetSecret(ctx, "bbgo/token")
+	assert.NoError(t, err)
+	assert.Equal(t, "plain-token", value)
+
+	_, err = provider.GetSecret(ctx, "bbgo/token#apiKey")
+	assert.Error(t, err, "the plain text secret has no fields")
+
+	_, err = provider.GetSecret(ctx, "bbgo/not-found")
+	assert.Error(t, err)
+
+	_, err = (&AWSProvider{Region: "ap-northeast-1", AccessKeyID: "AKID", Endpoint: server.URL}).GetSecret(ctx, "bbgo/token")
+	assert.Error(t, err, "the secret access key is required")
+}
@@ -0,0 +1,34 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// EnvProvider reads the secret from the environment variable, e.g., ${env:BINANCE_API_KEY}
+type EnvProvider struct{}
+
+func (p EnvProvider) GetSecret(ctx context.Context, ref string) (string, error) {
+	value, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", ref)
+	}
+
+	return value, nil
+}
+
+// FileProvider reads the secret from the file, e.g., the docker secrets ${file:/run/secrets/binance_api_key},
+// the trailing newline of the file is removed.
+type FileProvider struct{}
+
+func (p FileProvider) GetSecret(ctx context.Context, ref string) (string, error) {
+	content, err := ioutil.ReadFile(ref)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
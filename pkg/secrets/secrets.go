@@ -0,0 +1,138 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Provider resolves the secret of the reference, e.g., the vault path or the aws secret id
+type Provider interface {
+	GetSecret(ctx context.Context, ref string) (string, error)
+}
+
+// ProviderFunc is an adapter of the function to the Provider
+type ProviderFunc func(ctx context.Context, ref string) (string, error)
+
+func (f ProviderFunc) GetSecret(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+var providersMutex sync.Mutex
+
+var providers = map[string]Provider{
+	"env":   EnvProvider{},
+	"file":  FileProvider{},
+	"vault": &VaultProvider{},
+	"aws":   &AWSProvider{},
+}
+
+// Register registers the provider of the name, the secret is referenced by ${name:ref} in the config
+func Register(name string, provider Provider) {
+	providersMutex.Lock()
+	providers[name] = provider
+	providersMutex.Unlock()
+}
+
+func lookupProvider(name string) (Provider, bool) {
+	providersMutex.Lock()
+	defer providersMutex.Unlock()
+	provider, ok := providers[name]
+	return provider, ok
+}
+
+// expressionPattern matches the escaped $$ and the ${...} expressions
+var expressionPattern = regexp.MustCompile(`\$\$|\$\{([^{}]+)\}`)
+
+// providerPattern matches the provider reference, e.g., vault:secret/data/bbgo#api_key,
+// the ${VAR:-default} expression is not a provider reference
+var providerPattern = regexp.MustCompile(`^([a-z][a-z0-9_]*):([^-].*)$`)
+
+// Interpolate replaces the expressions in the content. ${VAR} is replaced by the environment variable, it's an error
+// if the variable is not set. ${VAR:-default} falls back to the default value if the variable is not set or empty.
+// ${provider:ref} is replaced by the secret of the provider, e.g., ${file:/run/secrets/api_key}. $$ is replaced by $.
+//
+// The comment lines are left untouched. The values are not quoted, quote the expression if the value might
+// contain the special characters of YAML.
+func Interpolate(ctx context.Context, content []byte) ([]byte, error) {
+	var cache = make(map[string]string)
+	var lines = bytes.SplitAfter(content, []byte("\n"))
+	var out bytes.Buffer
+	var firstErr error
+
+	for _, line := range lines {
+		if bytes.HasPrefix(bytes.TrimSpace(line), []byte("#")) {
+			out.Write(line)
+			continue
+		}
+
+		line = expressionPattern.ReplaceAllFunc(line, func(match []byte) []byte {
+			if string(match) == "$$" {
+				return []byte("$")
+			}
+
+			expr := string(match[2 : len(match)-1])
+			if value, ok := cache[expr]; ok {
+				return []byte(value)
+			}
+
+			value, err := resolve(ctx, expr)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return match
+			}
+
+			cache[expr] = value
+			return []byte(value)
+		})
+
+		out.Write(line)
+	}
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return out.Bytes(), nil
+}
+
+func resolve(ctx context.Context, expr string) (string, error) {
+	expr = strings.TrimSpace(expr)
+
+	if matches := providerPattern.FindStringSubmatch(expr); matches != nil {
+		name, ref := matches[1], matches[2]
+		provider, ok := lookupProvider(name)
+		if !ok {
+			return "", fmt.Errorf("secret provider %s is not registered", name)
+		}
+
+		value, err := provider.GetSecret(ctx, ref)
+		if err != nil {
+			return "", fmt.Errorf("can not get secret %s from %s: %w", ref, name, err)
+		}
+
+		return value, nil
+	}
+
+	name, defaultValue, hasDefault := expr, "", false
+	if idx := strings.Index(expr, ":-"); idx >= 0 {
+		name, defaultValue, hasDefault = expr[:idx], expr[idx+2:], true
+	}
+
+	value, ok := os.LookupEnv(name)
+	if hasDefault && len(value) == 0 {
+		return defaultValue, nil
+	}
+
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,92 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpolate(t *testing.T) {
+	os.Setenv("BBGO_TEST_API_KEY", "key-123")
+	os.Setenv("BBGO_TEST_EMPTY", "")
+	defer os.Unsetenv("BBGO_TEST_API_KEY")
+	defer os.Unsetenv("BBGO_TEST_EMPTY")
+
+	var calls int
+	Register("test", ProviderFunc(func(ctx context.Context, ref string) (string, error) {
+		calls++
+		if ref == "missing" {
+			return "", errors.New("not found")
+		}
+		return "secret-of-" + ref, nil
+	}))
+
+	content := []byte(`# ${NOT_SET} in the comment is ignored
+sessions:
+  binance:
+    key: "${BBGO_TEST_API_KEY}"
+    secret: "${test:binance}"
+    passphrase: "${test:binance}"
+    envVarPrefix: ${BBGO_TEST_EMPTY:-BINANCE}
+    note: "$${HOME} ${ NOT_SET:-default }"
+`)
+
+	out, err := Interpolate(context.Background(), content)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `# ${NOT_SET} in the comment is ignored
+sessions:
+  binance:
+    key: "key-123"
+    secret: "secret-of-binance"
+    passphrase: "secret-of-binance"
+    envVarPrefix: BINANCE
+    note: "${HOME} default"
+`, string(out))
+	}
+	assert.Equal(t, 1, calls, "the same secret is resolved once")
+
+	_, err = Interpolate(context.Background(), []byte("key: ${NOT_SET}"))
+	assert.Error(t, err)
+
+	_, err = Interpolate(context.Background(), []byte("key: ${test:missing}"))
+	assert.Error(t, err)
+
+	_, err = Interpolate(context.Background(), []byte("key: ${unknown:ref}"))
+	assert.Error(t, err)
+}
+
+func TestFileProvider(t *testing.T) {
+	file, err := ioutil.TempFile("", "bbgo-secret-*")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString("s3cr3t\n")
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+
+	out, err := Interpolate(context.Background(), []byte("secret: ${file:"+file.Name()+"}"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "secret: s3cr3t", string(out))
+	}
+
+	_, err = FileProvider{}.GetSecret(context.Background(), file.Name()+".missing")
+	assert.Error(t, err)
+}
+
+func TestEnvProvider(t *testing.T) {
+	os.Setenv("BBGO_TEST_SECRET", "value")
+	defer os.Unsetenv("BBGO_TEST_SECRET")
+
+	value, err := EnvProvider{}.GetSecret(context.Background(), "BBGO_TEST_SECRET")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", value)
+
+	_, err = EnvProvider{}.GetSecret(context.Background(), "BBGO_TEST_NOT_SET")
+	assert.Error(t, err)
+}
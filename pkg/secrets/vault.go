@@ -0,0 +1,114 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const defaultHTTPTimeout = 10 * time.Second
+
+// VaultProvider reads the secret from the HashiCorp Vault KV secrets engine, the reference is the path with the field,
+// e.g., ${vault:secret/data/bbgo#binance_api_key}. Both the KV version 1 and version 2 are supported.
+type VaultProvider struct {
+	// Address is the vault address, defaults to the VAULT_ADDR environment variable
+	Address string
+
+	// Token is the vault token, defaults to the VAULT_TOKEN environment variable
+	Token string
+
+	Client *http.Client
+}
+
+func (p *VaultProvider) GetSecret(ctx context.Context, ref string) (string, error) {
+	address, token := p.Address, p.Token
+	if len(address) == 0 {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	if len(token) == 0 {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+
+	if len(address) == 0 {
+		return "", errors.New("vault address is not set, please set VAULT_ADDR")
+	}
+
+	path, field := splitField(ref)
+	url := strings.TrimRight(address, "/") + "/v1/" + strings.TrimLeft(path, "/")
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("X-Vault-Token", token)
+
+	client := p.Client
+	if client == nil {
+		client = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault responds %s", resp.Status)
+	}
+
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&body); err != nil {
+		return "", err
+	}
+
+	data := body.Data
+
+	// the kv version 2 wraps the secret data with the metadata
+	if inner, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = inner
+		}
+	}
+
+	return selectField(data, field)
+}
+
+// splitField splits the reference into the secret name and the field, e.g., secret/data/bbgo#api_key
+func splitField(ref string) (name, field string) {
+	if idx := strings.LastIndex(ref, "#"); idx >= 0 {
+		return ref[:idx], ref[idx+1:]
+	}
+
+	return ref, ""
+}
+
+// selectField returns the field of the secret data, the field can be omitted if the secret has only one field
+func selectField(data map[string]interface{}, field string) (string, error) {
+	if len(field) == 0 {
+		if len(data) != 1 {
+			return "", fmt.Errorf("the secret has %d fields, please specify the field by #field", len(data))
+		}
+
+		for _, value := range data {
+			return fmt.Sprint(value), nil
+		}
+	}
+
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("field %s is not found in the secret", field)
+	}
+
+	return fmt.Sprint(value), nil
+}
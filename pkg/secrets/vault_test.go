@@ -0,0 +1,59 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVaultProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/secret/data/bbgo":
+			_, _ = w.Write([]byte(`{"data": {"data": {"binance_api_key": "key", "binance_api_secret": "secret", "pin": 12345678}, "metadata": {"version": 1}}}`))
+
+		case "/v1/kv/bbgo":
+			_, _ = w.Write([]byte(`{"data": {"api_key": "v1-key"}}`))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider := &VaultProvider{Address: server.URL, Token: "token"}
+	ctx := context.Background()
+
+	value, err := provider.GetSecret(ctx, "secret/data/bbgo#binance_api_secret")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", value)
+
+	value, err = provider.GetSecret(ctx, "secret/data/bbgo#pin")
+	assert.NoError(t, err)
+	assert.Equal(t, "12345678", value)
+
+	// the field can be omitted if the secret has only one field
+	value, err = provider.GetSecret(ctx, "kv/bbgo")
+	assert.NoError(t, err)
+	assert.Equal(t, "v1-key", value)
+
+	_, err = provider.GetSecret(ctx, "secret/data/bbgo")
+	assert.Error(t, err)
+
+	_, err = provider.GetSecret(ctx, "secret/data/bbgo#not_found")
+	assert.Error(t, err)
+
+	_, err = provider.GetSecret(ctx, "secret/data/other#key")
+	assert.Error(t, err)
+
+	_, err = (&VaultProvider{Address: server.URL, Token: "wrong"}).GetSecret(ctx, "kv/bbgo")
+	assert.Error(t, err)
+}
@@ -0,0 +1,307 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// registerAdminRoutes registers the admin api for operating the running trader remotely,
+// the admin api is enabled only if the admin token is set.
+func (s *Server) registerAdminRoutes(r *gin.Engine) {
+	if len(s.AdminToken) == 0 {
+		return
+	}
+
+	s.dashboard = newDashboard(s.Environ)
+
+	admin := r.Group("/api/admin", s.adminAuth)
+	admin.GET("/dashboard", s.adminDashboard)
+	admin.GET("/dashboard/ws", s.adminDashboardStream)
+	admin.GET("/strategies", s.adminListStrategies)
+	admin.POST("/strategies/:strategy/pause", s.adminPauseStrategy)
+	admin.POST("/strategies/:strategy/resume", s.adminResumeStrategy)
+	admin.GET("/sessions/:session/balances", s.adminListBalances)
+	admin.GET("/sessions/:session/positions", s.adminListPositions)
+	admin.POST("/sessions/:session/orders/cancel", s.adminCancelOrders)
+	admin.GET("/sessions/:session/deposit-address", s.adminDepositAddress)
+	admin.GET("/pauses", s.adminListPauses)
+	admin.POST("/sessions/:session/pause", s.adminPauseSession)
+	admin.POST("/sessions/:session/resume", s.adminResumeSession)
+	admin.POST("/sync", s.adminSync)
+	admin.POST("/config/reload", s.adminReloadConfig)
+}
+
+// adminAuth checks the bearer token of the request, the browsers can not set the header of the websocket request,
+// so the token of the websocket request is passed by the token query parameter.
+func (s *Server) adminAuth(c *gin.Context) {
+	const prefix = "Bearer "
+
+	var token string
+	if header := c.GetHeader("Authorization"); strings.HasPrefix(header, prefix) {
+		token = strings.TrimPrefix(header, prefix)
+	} else if websocket.IsWebSocketUpgrade(c.Request) {
+		token = c.Query("token")
+	}
+
+	if len(token) == 0 {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "bearer token is required"})
+		return
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.AdminToken)) != 1 {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+		return
+	}
+
+	c.Next()
+}
+
+func (s *Server) adminListStrategies(c *gin.Context) {
+	if s.Trader == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "trader is not running"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"strategies": s.Trader.RunningStrategies()})
+}
+
+func (s *Server) adminPauseStrategy(c *gin.Context) {
+	if s.Trader == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "trader is not running"})
+		return
+	}
+
+	target := c.Param("strategy")
+	if err := s.Trader.SuspendStrategy(c, target); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	logrus.Infof("strategy %s is paused by the admin api", target)
+	s.Environ.Notify(":double_vertical_bar: strategy %s is paused by the admin api", target)
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func (s *Server) adminResumeStrategy(c *gin.Context) {
+	if s.Trader == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "trader is not running"})
+		return
+	}
+
+	target := c.Param("strategy")
+	if err := s.Trader.ResumeStrategy(c, target); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	logrus.Infof("strategy %s is resumed by the admin api", target)
+	s.Environ.Notify(":arrow_forward: strategy %s is resumed by the admin api", target)
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func (s *Server) adminListPauses(c *gin.Context) {
+	if s.Trader == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "trader is not running"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pauses": s.Trader.Pauses()})
+}
+
+// sessionPauseRequest is the optional body of the session pause and resume api
+type sessionPauseRequest struct {
+	// Strategy pauses the strategy of the session only, e.g., grid or grid:BTCUSDT
+	Strategy string `json:"strategy"`
+
+	// CancelOrders cancels the open orders of the paused symbols
+	CancelOrders bool `json:"cancelOrders"`
+}
+
+func (s *Server) adminPauseSession(c *gin.Context) {
+	if s.Trader == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "trader is not running"})
+		return
+	}
+
+	var request sessionPauseRequest
+	if err := c.ShouldBindJSON(&request); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	orders, err := s.Trader.PauseSession(c, c.Param("session"), request.Strategy, request.CancelOrders)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if orders == nil {
+		orders = []types.Order{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "canceledOrders": orders})
+}
+
+func (s *Server) adminResumeSession(c *gin.Context) {
+	if s.Trader == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "trader is not running"})
+		return
+	}
+
+	var request sessionPauseRequest
+	if err := c.ShouldBindJSON(&request); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.Trader.ResumeSession(c.Param("session"), request.Strategy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func (s *Server) adminListBalances(c *gin.Context) {
+	// the same response as the session balance api
+	s.getSessionAccountBalance(c)
+}
+
+func (s *Server) adminListPositions(c *gin.Context) {
+	sessionName := c.Param("session")
+	session, ok := s.Environ.Session(sessionName)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("session %s not found", sessionName)})
+		return
+	}
+
+	var positions = make(map[string]*types.Position)
+	for symbol, position := range session.Positions() {
+		positions[symbol] = position.Snapshot()
+	}
+
+	c.JSON(http.StatusOK, gin.H{"positions": positions})
+}
+
+func (s *Server) adminCancelOrders(c *gin.Context) {
+	sessionName := c.Param("session")
+	session, ok := s.Environ.Session(sessionName)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("session %s not found", sessionName)})
+		return
+	}
+
+	var request struct {
+		Symbol string `json:"symbol"`
+
+		// OrderID is the order to cancel, all the open orders of the symbol are canceled if it's zero
+		OrderID uint64 `json:"orderID"`
+	}
+
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(request.Symbol) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "symbol is required"})
+		return
+	}
+
+	if request.OrderID > 0 {
+		order := types.Order{SubmitOrder: types.SubmitOrder{Symbol: request.Symbol}, OrderID: request.OrderID}
+		if err := session.Exchange.CancelOrders(c, order); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		logrus.Infof("%s order %d of session %s is canceled by the admin api", request.Symbol, request.OrderID, sessionName)
+		c.JSON(http.StatusOK, gin.H{"orders": []types.Order{order}})
+		return
+	}
+
+	orders, err := session.CancelAllOrders(c, request.Symbol)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if orders == nil {
+		orders = []types.Order{}
+	}
+
+	logrus.Infof("%d %s orders of session %s are canceled by the admin api", len(orders), request.Symbol, sessionName)
+	c.JSON(http.StatusOK, gin.H{"orders": orders})
+}
+
+// adminDepositAddress queries the deposit address by the asset and the network, e.g., ?asset=USDT&network=TRX
+func (s *Server) adminDepositAddress(c *gin.Context) {
+	sessionName := c.Param("session")
+	session, ok := s.Environ.Session(sessionName)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("session %s not found", sessionName)})
+		return
+	}
+
+	asset := c.Query("asset")
+	if len(asset) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "asset is required"})
+		return
+	}
+
+	if !session.Capabilities().DepositAddress {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": fmt.Sprintf("exchange %s does not support the deposit address query", session.ExchangeName)})
+		return
+	}
+
+	address, err := session.QueryDepositAddress(c, asset, c.Query("network"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"depositAddress": address})
+}
+
+func (s *Server) adminSync(c *gin.Context) {
+	if s.Environ.IsSyncing() == bbgo.Syncing {
+		c.JSON(http.StatusConflict, gin.H{"error": "the trading data is syncing"})
+		return
+	}
+
+	go func() {
+		if err := s.Environ.Sync(context.Background()); err != nil {
+			logrus.WithError(err).Error("sync error")
+		}
+	}()
+
+	c.JSON(http.StatusAccepted, gin.H{"success": true})
+}
+
+// adminReloadConfig reloads the strategy parameters from the config file, the strategies that are not reloaded
+// are listed in the result
+func (s *Server) adminReloadConfig(c *gin.Context) {
+	if s.ConfigReloader == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "config reload is not enabled"})
+		return
+	}
+
+	result, err := s.ConfigReloader.Reload(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,267 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type adminTestStream struct {
+	types.StandardStream
+}
+
+func (s *adminTestStream) SetPublicOnly() {}
+
+type adminTestExchange struct {
+	types.Exchange
+
+	openOrders     []types.Order
+	canceledOrders []types.Order
+}
+
+func (e *adminTestExchange) NewStream() types.Stream {
+	return &adminTestStream{StandardStream: types.NewStandardStream()}
+}
+
+func (e *adminTestExchange) QueryOpenOrders(ctx context.Context, symbol string) ([]types.Order, error) {
+	return e.openOrders, nil
+}
+
+func (e *adminTestExchange) QueryDepositAddress(ctx context.Context, asset, network string) (*types.DepositAddress, error) {
+	return &types.DepositAddress{Asset: asset, Network: network, Address: "0x" + asset}, nil
+}
+
+func (e *adminTestExchange) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	e.canceledOrders = append(e.canceledOrders, orders...)
+	return nil
+}
+
+type adminTestStrategy struct {
+	Symbol string `json:"symbol"`
+
+	paused bool
+}
+
+func (s *adminTestStrategy) ID() string {
+	return "admin-test"
+}
+
+func (s *adminTestStrategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	return nil
+}
+
+func (s *adminTestStrategy) Pause() error {
+	s.paused = true
+	return nil
+}
+
+func (s *adminTestStrategy) Resume() error {
+	s.paused = false
+	return nil
+}
+
+func newAdminTestServer(t *testing.T) (*Server, *gin.Engine, *adminTestExchange, *adminTestStrategy) {
+	gin.SetMode(gin.TestMode)
+
+	exchange := &adminTestExchange{
+		openOrders: []types.Order{
+			{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT"}, OrderID: 1},
+			{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT"}, OrderID: 2},
+		},
+	}
+
+	market := types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"}
+	session := bbgo.NewExchangeSession("binance", exchange)
+	session.SetMarkets(types.MarketMap{"BTCUSDT": market})
+	session.Account.UpdateBalances(types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(1000.0)},
+	})
+
+	position, _ := session.Position("BTCUSDT")
+	position.AddTrade(types.Trade{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Price: 10000.0, Quantity: 0.5, QuoteQuantity: 5000.0})
+
+	environ := bbgo.NewEnvironment()
+	environ.AddExchangeSession("binance", session)
+
+	strategy := &adminTestStrategy{Symbol: "BTCUSDT"}
+	trader := bbgo.NewTrader(environ)
+	assert.NoError(t, trader.AttachStrategyOn("binance", strategy))
+
+	s := &Server{Environ: environ, Trader: trader, AdminToken: "secret"}
+	return s, s.newEngine(), exchange, strategy
+}
+
+func adminRequest(r *gin.Engine, method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	var data []byte
+	if body != nil {
+		data, _ = json.Marshal(body)
+	}
+
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAdminAPI_Auth(t *testing.T) {
+	_, r, _, _ := newAdminTestServer(t)
+
+	assert.Equal(t, http.StatusUnauthorized, adminRequest(r, "GET", "/api/admin/strategies", "", nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, adminRequest(r, "GET", "/api/admin/strategies", "wrong", nil).Code)
+	assert.Equal(t, http.StatusOK, adminRequest(r, "GET", "/api/admin/strategies", "secret", nil).Code)
+}
+
+func TestAdminAPI_Strategies(t *testing.T) {
+	_, r, _, strategy := newAdminTestServer(t)
+
+	w := adminRequest(r, "GET", "/api/admin/strategies", "secret", nil)
+	var response struct {
+		Strategies []bbgo.RunningStrategy `json:"strategies"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	if assert.Len(t, response.Strategies, 1) {
+		assert.Equal(t, "admin-test:BTCUSDT", response.Strategies[0].Target)
+		assert.True(t, response.Strategies[0].Suspendable)
+	}
+
+	assert.Equal(t, http.StatusOK, adminRequest(r, "POST", "/api/admin/strategies/admin-test:BTCUSDT/pause", "secret", nil).Code)
+	assert.True(t, strategy.paused)
+
+	assert.Equal(t, http.StatusOK, adminRequest(r, "POST", "/api/admin/strategies/admin-test/resume", "secret", nil).Code)
+	assert.False(t, strategy.paused)
+
+	assert.Equal(t, http.StatusBadRequest, adminRequest(r, "POST", "/api/admin/strategies/grid/pause", "secret", nil).Code)
+}
+
+func TestAdminAPI_Sessions(t *testing.T) {
+	_, r, exchange, _ := newAdminTestServer(t)
+
+	w := adminRequest(r, "GET", "/api/admin/sessions/binance/balances", "secret", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"USDT"`)
+
+	w = adminRequest(r, "GET", "/api/admin/sessions/binance/positions", "secret", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var positions struct {
+		Positions map[string]types.Position `json:"positions"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &positions))
+	assert.Equal(t, 0.5, positions.Positions["BTCUSDT"].Base.Float64())
+
+	assert.Equal(t, http.StatusNotFound, adminRequest(r, "GET", "/api/admin/sessions/ftx/positions", "secret", nil).Code)
+
+	w = adminRequest(r, "POST", "/api/admin/sessions/binance/orders/cancel", "secret", map[string]interface{}{"symbol": "BTCUSDT", "orderID": 2})
+	assert.Equal(t, http.StatusOK, w.Code)
+	if assert.Len(t, exchange.canceledOrders, 1) {
+		assert.Equal(t, uint64(2), exchange.canceledOrders[0].OrderID)
+	}
+
+	w = adminRequest(r, "POST", "/api/admin/sessions/binance/orders/cancel", "secret", map[string]interface{}{"symbol": "BTCUSDT"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, exchange.canceledOrders, 3)
+
+	w = adminRequest(r, "POST", "/api/admin/sessions/binance/orders/cancel", "secret", map[string]interface{}{})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAdminAPI_DepositAddress(t *testing.T) {
+	_, r, _, _ := newAdminTestServer(t)
+
+	w := adminRequest(r, "GET", "/api/admin/sessions/binance/deposit-address?asset=usdt&network=ETH", "secret", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		DepositAddress types.DepositAddress `json:"depositAddress"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, types.DepositAddress{Asset: "USDT", Network: "ETH", Address: "0xUSDT"}, response.DepositAddress)
+
+	assert.Equal(t, http.StatusBadRequest, adminRequest(r, "GET", "/api/admin/sessions/binance/deposit-address", "secret", nil).Code)
+	assert.Equal(t, http.StatusNotFound, adminRequest(r, "GET", "/api/admin/sessions/ftx/deposit-address?asset=USDT", "secret", nil).Code)
+}
+
+func TestAdminAPI_PauseSession(t *testing.T) {
+	_, r, exchange, _ := newAdminTestServer(t)
+
+	w := adminRequest(r, "POST", "/api/admin/sessions/binance/pause", "secret", map[string]interface{}{"strategy": "admin-test:BTCUSDT", "cancelOrders": true})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, exchange.canceledOrders, 2)
+
+	w = adminRequest(r, "GET", "/api/admin/pauses", "secret", nil)
+	var response struct {
+		Pauses []bbgo.SessionPause `json:"pauses"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	if assert.Len(t, response.Pauses, 1) {
+		assert.Equal(t, "binance", response.Pauses[0].Session)
+		assert.Equal(t, "admin-test:BTCUSDT", response.Pauses[0].Strategy)
+	}
+
+	assert.Equal(t, http.StatusOK, adminRequest(r, "POST", "/api/admin/sessions/binance/resume", "secret", map[string]interface{}{"strategy": "admin-test:BTCUSDT"}).Code)
+	assert.Equal(t, http.StatusBadRequest, adminRequest(r, "POST", "/api/admin/sessions/binance/resume", "secret", map[string]interface{}{"strategy": "admin-test:BTCUSDT"}).Code)
+
+	// the whole session is paused without the body
+	assert.Equal(t, http.StatusOK, adminRequest(r, "POST", "/api/admin/sessions/binance/pause", "secret", nil).Code)
+	assert.Len(t, exchange.canceledOrders, 2)
+
+	assert.Equal(t, http.StatusBadRequest, adminRequest(r, "POST", "/api/admin/sessions/ftx/pause", "secret", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, adminRequest(r, "POST", "/api/admin/sessions/binance/pause", "secret", map[string]interface{}{"strategy": "grid"}).Code)
+}
+
+func TestAdminAPI_ReloadConfig(t *testing.T) {
+	s, r, _, _ := newAdminTestServer(t)
+
+	w := adminRequest(r, "POST", "/api/admin/config/reload", "secret", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	file, err := ioutil.TempFile("", "bbgo-admin-*.yaml")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString("sessions: {}\n")
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+
+	s.ConfigReloader = bbgo.NewConfigReloader(file.Name(), s.Trader)
+	w = adminRequest(r, "POST", "/api/admin/config/reload", "secret", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var result bbgo.StrategyReloadResult
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Len(t, result.Reloaded, 0)
+
+	s.ConfigReloader = bbgo.NewConfigReloader(file.Name()+".missing", s.Trader)
+	w = adminRequest(r, "POST", "/api/admin/config/reload", "secret", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAdminAPI_Disabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	s := &Server{Environ: bbgo.NewEnvironment()}
+	r := s.newEngine()
+
+	// the admin routes are not registered without the token, the request falls to the assets handler
+	w := adminRequest(r, "GET", "/api/admin/strategies", "", nil)
+	assert.NotEqual(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,225 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const (
+	dashboardPushInterval    = 2 * time.Second
+	dashboardPnLInterval     = time.Minute
+	dashboardMaxPnLPoints    = 24 * 60
+	dashboardMaxRecentTrades = 50
+)
+
+// PnLPoint is the profit of the session at the time, the profits are summed up over the positions in the quote currencies
+type PnLPoint struct {
+	Time       time.Time `json:"time"`
+	Realized   float64   `json:"realized"`
+	Unrealized float64   `json:"unrealized"`
+}
+
+type DashboardSession struct {
+	Name     string             `json:"name"`
+	Exchange types.ExchangeName `json:"exchange"`
+
+	// Healthy is false if any stream of the session is disconnected or stale
+	Healthy bool                     `json:"healthy"`
+	Health  []bbgo.HealthCheckResult `json:"health"`
+
+	Positions    []*types.Position `json:"positions"`
+	OpenOrders   []types.Order     `json:"openOrders"`
+	RecentTrades []types.Trade     `json:"recentTrades"`
+	PnL          []PnLPoint        `json:"pnl"`
+}
+
+type DashboardSnapshot struct {
+	Time     time.Time          `json:"time"`
+	Sessions []DashboardSession `json:"sessions"`
+}
+
+// dashboard collects the live state of the sessions for the web dashboard, and records the pnl history in memory
+type dashboard struct {
+	environ *bbgo.Environment
+
+	mu         sync.Mutex
+	pnlHistory map[string][]PnLPoint
+}
+
+func newDashboard(environ *bbgo.Environment) *dashboard {
+	return &dashboard{
+		environ:    environ,
+		pnlHistory: make(map[string][]PnLPoint),
+	}
+}
+
+// Run records the pnl of the sessions periodically until the context is done
+func (d *dashboard) Run(ctx context.Context) {
+	ticker := time.NewTicker(dashboardPnLInterval)
+	defer ticker.Stop()
+
+	d.recordPnL(time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case now := <-ticker.C:
+			d.recordPnL(now)
+		}
+	}
+}
+
+func sessionPnL(session *bbgo.ExchangeSession, now time.Time) PnLPoint {
+	point := PnLPoint{Time: now}
+	for symbol, position := range session.Positions() {
+		snapshot := position.Snapshot()
+		point.Realized += snapshot.RealizedProfit.Float64()
+
+		if profit, ok := session.UnrealizedProfit(symbol); ok {
+			point.Unrealized += profit.Float64()
+		}
+	}
+
+	return point
+}
+
+func (d *dashboard) recordPnL(now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for name, session := range d.environ.Sessions() {
+		history := append(d.pnlHistory[name], sessionPnL(session, now))
+		if len(history) > dashboardMaxPnLPoints {
+			history = history[len(history)-dashboardMaxPnLPoints:]
+		}
+
+		d.pnlHistory[name] = history
+	}
+}
+
+func (d *dashboard) Snapshot(ctx context.Context, now time.Time) DashboardSnapshot {
+	var healthResults []bbgo.HealthCheckResult
+	if d.environ.HealthChecker != nil {
+		healthResults = d.environ.HealthChecker.Readiness(ctx, now).Checks
+	}
+
+	snapshot := DashboardSnapshot{Time: now, Sessions: []DashboardSession{}}
+	for name, session := range d.environ.Sessions() {
+		s := DashboardSession{
+			Name:         name,
+			Exchange:     session.ExchangeName,
+			Healthy:      true,
+			Health:       []bbgo.HealthCheckResult{},
+			Positions:    []*types.Position{},
+			OpenOrders:   []types.Order{},
+			RecentTrades: []types.Trade{},
+		}
+
+		for _, result := range healthResults {
+			if !strings.HasPrefix(result.Name, "session:"+name+":") {
+				continue
+			}
+
+			s.Health = append(s.Health, result)
+			s.Healthy = s.Healthy && result.Healthy
+		}
+
+		for _, position := range session.Positions() {
+			s.Positions = append(s.Positions, position.Snapshot())
+		}
+		sort.Slice(s.Positions, func(i, j int) bool { return s.Positions[i].Symbol < s.Positions[j].Symbol })
+
+		for _, store := range session.OrderStores() {
+			for _, order := range store.Orders() {
+				if order.Status == types.OrderStatusNew || order.Status == types.OrderStatusPartiallyFilled {
+					s.OpenOrders = append(s.OpenOrders, order)
+				}
+			}
+		}
+		sort.Slice(s.OpenOrders, func(i, j int) bool { return s.OpenOrders[i].OrderID < s.OpenOrders[j].OrderID })
+
+		for _, trades := range session.Trades {
+			s.RecentTrades = append(s.RecentTrades, trades.Copy()...)
+		}
+		sort.Slice(s.RecentTrades, func(i, j int) bool {
+			return s.RecentTrades[i].Time.Time().After(s.RecentTrades[j].Time.Time())
+		})
+		if len(s.RecentTrades) > dashboardMaxRecentTrades {
+			s.RecentTrades = s.RecentTrades[:dashboardMaxRecentTrades]
+		}
+
+		d.mu.Lock()
+		s.PnL = append(append([]PnLPoint{}, d.pnlHistory[name]...), sessionPnL(session, now))
+		d.mu.Unlock()
+
+		snapshot.Sessions = append(snapshot.Sessions, s)
+	}
+
+	sort.Slice(snapshot.Sessions, func(i, j int) bool { return snapshot.Sessions[i].Name < snapshot.Sessions[j].Name })
+	return snapshot
+}
+
+var dashboardUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+
+	// the websocket is authenticated by the admin token, so the dashboard can be served from the frontend dev server
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+func (s *Server) adminDashboard(c *gin.Context) {
+	c.JSON(http.StatusOK, s.dashboard.Snapshot(c, time.Now()))
+}
+
+// adminDashboardStream pushes the dashboard snapshot through the websocket periodically
+func (s *Server) adminDashboardStream(c *gin.Context) {
+	conn, err := dashboardUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		logrus.WithError(err).Error("dashboard websocket upgrade error")
+		return
+	}
+
+	defer conn.Close()
+
+	// the reader detects the closed connection, the messages from the client are ignored
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(dashboardPushInterval)
+	defer ticker.Stop()
+
+	ctx := c.Request.Context()
+	for {
+		if err := conn.WriteJSON(s.dashboard.Snapshot(ctx, time.Now())); err != nil {
+			logrus.WithError(err).Debug("dashboard websocket write error")
+			return
+		}
+
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestDashboard_Snapshot(t *testing.T) {
+	_, r, _, _ := newAdminTestServer(t)
+
+	w := adminRequest(r, "GET", "/api/admin/dashboard", "secret", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var snapshot DashboardSnapshot
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &snapshot))
+	if assert.Len(t, snapshot.Sessions, 1) {
+		session := snapshot.Sessions[0]
+		assert.Equal(t, "binance", session.Name)
+		assert.Len(t, session.Positions, 1)
+		assert.Len(t, session.OpenOrders, 0)
+
+		// the current pnl is always appended to the history
+		assert.Len(t, session.PnL, 1)
+	}
+}
+
+func TestDashboard_RecordPnL(t *testing.T) {
+	server, _, _, _ := newAdminTestServer(t)
+	now := time.Now()
+	for i := 0; i < dashboardMaxPnLPoints+10; i++ {
+		server.dashboard.recordPnL(now.Add(time.Duration(i) * time.Minute))
+	}
+
+	snapshot := server.dashboard.Snapshot(context.Background(), now)
+	if assert.Len(t, snapshot.Sessions, 1) {
+		assert.Len(t, snapshot.Sessions[0].PnL, dashboardMaxPnLPoints+1)
+	}
+
+	session, _ := server.Environ.Session("binance")
+	session.Trades["BTCUSDT"] = &types.TradeSlice{Trades: []types.Trade{
+		{ID: 1, Symbol: "BTCUSDT", Time: types.Time(now.Add(-time.Minute))},
+		{ID: 2, Symbol: "BTCUSDT", Time: types.Time(now)},
+	}}
+
+	snapshot = server.dashboard.Snapshot(context.Background(), now)
+	if assert.Len(t, snapshot.Sessions[0].RecentTrades, 2) {
+		assert.Equal(t, int64(2), snapshot.Sessions[0].RecentTrades[0].ID, "the latest trade comes first")
+	}
+}
+
+func TestDashboard_Stream(t *testing.T) {
+	_, r, _, _ := newAdminTestServer(t)
+	httpServer := httptest.NewServer(r)
+	defer httpServer.Close()
+
+	url := "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/api/admin/dashboard/ws"
+
+	_, resp, err := websocket.DefaultDialer.Dial(url+"?token=wrong", nil)
+	assert.Error(t, err)
+	if assert.NotNil(t, resp) {
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(url+"?token=secret", nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+
+	var snapshot DashboardSnapshot
+	assert.NoError(t, conn.ReadJSON(&snapshot))
+	assert.Len(t, snapshot.Sessions, 1)
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+)
+
+const eventStreamPingInterval = 30 * time.Second
+
+// eventStreamUpgrader checks the origin of the websocket request if the admin token is not set,
+// so that the other websites can not read the events through the browsers of the users.
+var eventStreamUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+var eventStreamTypes = []bbgo.EventType{
+	bbgo.EventTypeOrder,
+	bbgo.EventTypeTrade,
+	bbgo.EventTypePosition,
+	bbgo.EventTypePnL,
+	bbgo.EventTypeNotification,
+}
+
+// eventStreamAuth requires the admin token if it's set, otherwise the event stream is open to the local consumers
+func (s *Server) eventStreamAuth(c *gin.Context) {
+	if len(s.AdminToken) == 0 {
+		c.Next()
+		return
+	}
+
+	s.adminAuth(c)
+}
+
+// parseEventStreamTypes parses the comma separated event types, e.g., order,trade
+func parseEventStreamTypes(value string) ([]bbgo.EventType, error) {
+	var events []bbgo.EventType
+	for _, name := range splitQueryList(value) {
+		var found = false
+		for _, event := range eventStreamTypes {
+			if string(event) == name {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("unsupported event %q", name)
+		}
+
+		events = append(events, bbgo.EventType(name))
+	}
+
+	return events, nil
+}
+
+func splitQueryList(value string) (items []string) {
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) > 0 {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// eventStream rebroadcasts the events of the event feed as the json messages through the websocket,
+// the events and the sessions query parameters filter the events, e.g., ?events=order,trade&sessions=binance
+func (s *Server) eventStream(c *gin.Context) {
+	if s.Environ == nil || s.Environ.EventFeed == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "event feed is not configured"})
+		return
+	}
+
+	events, err := parseEventStreamTypes(c.Query("events"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sessions := splitQueryList(c.Query("sessions"))
+	for _, name := range sessions {
+		if _, ok := s.Environ.Session(name); !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("session %s not found", name)})
+			return
+		}
+	}
+
+	upgrader := eventStreamUpgrader
+	if len(s.AdminToken) > 0 {
+		// the websocket is authenticated by the admin token, the origin check is not needed
+		upgrader.CheckOrigin = func(r *http.Request) bool { return true }
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		logrus.WithError(err).Error("event stream websocket upgrade error")
+		return
+	}
+
+	defer conn.Close()
+
+	sub := s.Environ.EventFeed.Subscribe(events, sessions)
+	defer sub.Close()
+
+	// the reader detects the closed connection, the messages from the client are ignored
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(eventStreamPingInterval)
+	defer ping.Stop()
+
+	ctx := c.Request.Context()
+	for {
+		select {
+		case <-done:
+			return
+
+		case <-ctx.Done():
+			return
+
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second)); err != nil {
+				logrus.WithError(err).Debug("event stream websocket ping error")
+				return
+			}
+
+		case message := <-sub.C:
+			if err := conn.WriteJSON(message); err != nil {
+				logrus.WithError(err).Debug("event stream websocket write error")
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func Test_parseEventStreamTypes(t *testing.T) {
+	events, err := parseEventStreamTypes("order, trade,,position")
+	assert.NoError(t, err)
+	assert.Equal(t, []bbgo.EventType{bbgo.EventTypeOrder, bbgo.EventTypeTrade, bbgo.EventTypePosition}, events)
+
+	events, err = parseEventStreamTypes("")
+	assert.NoError(t, err)
+	assert.Len(t, events, 0)
+
+	_, err = parseEventStreamTypes("order,kline")
+	assert.Error(t, err)
+}
+
+func TestEventStream(t *testing.T) {
+	server, r, _, _ := newAdminTestServer(t)
+	httpServer := httptest.NewServer(r)
+	defer httpServer.Close()
+
+	url := "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/api/events/ws"
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.Error(t, err)
+	if assert.NotNil(t, resp) {
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "the admin token is required if it's set")
+	}
+
+	_, resp, err = websocket.DefaultDialer.Dial(url+"?token=secret&events=balance", nil)
+	assert.Error(t, err)
+	if assert.NotNil(t, resp) {
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	}
+
+	_, resp, err = websocket.DefaultDialer.Dial(url+"?token=secret&sessions=max", nil)
+	assert.Error(t, err)
+	if assert.NotNil(t, resp) {
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(url+"?token=secret&events=trade,notification&sessions=binance", nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+
+	feed := server.Environ.EventFeed
+	assert.Eventually(t, func() bool {
+		return feed.NumOfSubscriptions() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	feed.Emit(bbgo.EventTypeOrder, "binance", types.Order{OrderID: 1})
+	feed.Emit(bbgo.EventTypeTrade, "max", types.Trade{ID: 1})
+	feed.Emit(bbgo.EventTypeTrade, "binance", types.Trade{ID: 2})
+	feed.Notify("kill switch is triggered")
+
+	var message struct {
+		bbgo.EventMessage
+		Data types.Trade `json:"data"`
+	}
+	assert.NoError(t, conn.ReadJSON(&message))
+	assert.Equal(t, bbgo.EventTypeTrade, message.Event)
+	assert.Equal(t, "binance", message.Session)
+	assert.Equal(t, int64(2), message.Data.ID)
+
+	var notification struct {
+		bbgo.EventMessage
+		Data bbgo.EventNotification `json:"data"`
+	}
+	assert.NoError(t, conn.ReadJSON(&notification))
+	assert.Equal(t, bbgo.EventTypeNotification, notification.Event)
+	assert.Equal(t, "kill switch is triggered", notification.Data.Text)
+
+	conn.Close()
+	assert.Eventually(t, func() bool {
+		return feed.NumOfSubscriptions() == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestEventStream_WithoutAdminToken(t *testing.T) {
+	server, _, _, _ := newAdminTestServer(t)
+	server.AdminToken = ""
+
+	httpServer := httptest.NewServer(server.newEngine())
+	defer httpServer.Close()
+
+	url := "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/api/events/ws"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if assert.NoError(t, err) {
+		conn.Close()
+	}
+
+	// the cross-origin request is rejected without the admin token
+	_, resp, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": []string{"http://example.com"}})
+	assert.Error(t, err)
+	if assert.NotNil(t, resp) {
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	}
+}
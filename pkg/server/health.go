@@ -0,0 +1,39 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+)
+
+// healthz is the liveness probe, it fails when the process can not recover by itself and should be restarted
+func (s *Server) healthz(c *gin.Context) {
+	if s.Environ == nil || s.Environ.HealthChecker == nil {
+		c.JSON(http.StatusOK, bbgo.HealthReport{Healthy: true, Time: time.Now(), Checks: []bbgo.HealthCheckResult{}})
+		return
+	}
+
+	respondHealthReport(c, s.Environ.HealthChecker.Liveness(c.Request.Context(), time.Now()))
+}
+
+// readyz is the readiness probe, it fails when any session, the database or a strategy is not ready
+func (s *Server) readyz(c *gin.Context) {
+	if s.Environ == nil || s.Environ.HealthChecker == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"healthy": false, "error": "environment is not configured"})
+		return
+	}
+
+	respondHealthReport(c, s.Environ.HealthChecker.Readiness(c.Request.Context(), time.Now()))
+}
+
+func respondHealthReport(c *gin.Context, report bbgo.HealthReport) {
+	if !report.Healthy {
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
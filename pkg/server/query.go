@@ -0,0 +1,265 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultQueryLimit = 500
+
+// parseQueryTime parses the time of the query parameter, both RFC3339 and the date format like 2021-01-01 are accepted
+func parseQueryTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
+
+// parseQueryTimeRange parses the since and until parameters, the until time is exclusive
+func parseQueryTimeRange(c *gin.Context) (since, until *time.Time, err error) {
+	if value := c.Query("since"); len(value) > 0 {
+		t, err := parseQueryTime(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid since time %q: %w", value, err)
+		}
+		since = &t
+	}
+
+	if value := c.Query("until"); len(value) > 0 {
+		t, err := parseQueryTime(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid until time %q: %w", value, err)
+		}
+		until = &t
+	}
+
+	return since, until, nil
+}
+
+// queryExchange resolves the exchange name of the session parameter, the exchange parameter is used if the session
+// is not given
+func (s *Server) queryExchange(c *gin.Context) (types.ExchangeName, error) {
+	sessionName := c.Query("session")
+	if len(sessionName) == 0 {
+		return types.ExchangeName(c.Query("exchange")), nil
+	}
+
+	session, ok := s.Environ.Session(sessionName)
+	if !ok {
+		return "", fmt.Errorf("session %s not found", sessionName)
+	}
+
+	return session.ExchangeName, nil
+}
+
+// parseQueryTradesOptions parses the filters and the pagination of the trade queries:
+// session (or exchange), symbol, side, since, until, gid and limit
+func (s *Server) parseQueryTradesOptions(c *gin.Context) (options service.QueryTradesOptions, err error) {
+	options.Exchange, err = s.queryExchange(c)
+	if err != nil {
+		return options, err
+	}
+
+	options.Symbol = c.Query("symbol")
+
+	if value := c.Query("side"); len(value) > 0 {
+		options.Side, err = types.StrToSideType(value)
+		if err != nil {
+			return options, err
+		}
+	}
+
+	options.Since, options.Until, err = parseQueryTimeRange(c)
+	if err != nil {
+		return options, err
+	}
+
+	options.LastGID, err = strconv.ParseInt(c.DefaultQuery("gid", "0"), 10, 64)
+	if err != nil {
+		return options, fmt.Errorf("invalid gid: %w", err)
+	}
+
+	options.Limit, err = strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultQueryLimit)))
+	if err != nil {
+		return options, fmt.Errorf("invalid limit: %w", err)
+	}
+
+	options.Ordering = "DESC"
+	return options, nil
+}
+
+func (s *Server) listTrades(c *gin.Context) {
+	if s.Environ.TradeService == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database is not configured"})
+		return
+	}
+
+	options, err := s.parseQueryTradesOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trades, err := s.Environ.TradeService.Query(options)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		logrus.WithError(err).Error("trade query error")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"trades": trades,
+	})
+}
+
+// tradeSummary returns the trading volume and the fees paid of the trades filtered by the query
+func (s *Server) tradeSummary(c *gin.Context) {
+	if s.Environ.TradeService == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database is not configured"})
+		return
+	}
+
+	options, err := s.parseQueryTradesOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	summaries, err := s.Environ.TradeService.QuerySummaries(options)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		logrus.WithError(err).Error("trade summary query error")
+		return
+	}
+
+	fees, err := s.Environ.TradeService.QueryFees(options)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		logrus.WithError(err).Error("fee query error")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"summaries": summaries,
+		"fees":      fees,
+	})
+}
+
+func (s *Server) listClosedOrders(c *gin.Context) {
+	if s.Environ.OrderService == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database is not configured"})
+		return
+	}
+
+	tradeOptions, err := s.parseQueryTradesOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	orders, err := s.Environ.OrderService.Query(service.QueryOrdersOptions{
+		Exchange: tradeOptions.Exchange,
+		Symbol:   tradeOptions.Symbol,
+		Side:     tradeOptions.Side,
+		LastGID:  tradeOptions.LastGID,
+		Ordering: tradeOptions.Ordering,
+		Since:    tradeOptions.Since,
+		Until:    tradeOptions.Until,
+		Limit:    tradeOptions.Limit,
+	})
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		logrus.WithError(err).Error("order query error")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"orders": orders,
+	})
+}
+
+// listProfits returns the recorded profits of the strategies, the profits are summarized by the period
+// (day, week or month) if the period parameter is given
+func (s *Server) listProfits(c *gin.Context) {
+	if s.Environ.ProfitService == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database is not configured"})
+		return
+	}
+
+	since, until, err := parseQueryTimeRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	options := service.QueryProfitsOptions{
+		Strategy: c.Query("strategy"),
+		Symbol:   c.Query("symbol"),
+		Since:    since,
+		Until:    until,
+	}
+
+	if period := c.Query("period"); len(period) > 0 {
+		summaries, err := s.Environ.ProfitService.QuerySummaries(options, types.ProfitPeriod(period))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"summaries": summaries,
+		})
+		return
+	}
+
+	profits, err := s.Environ.ProfitService.Query(options)
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		logrus.WithError(err).Error("profit query error")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"profits": profits,
+	})
+}
+
+// listBalanceSnapshots returns the balance snapshots of the sessions in the ascending order of the time,
+// which are recorded by the balance snapshot job
+func (s *Server) listBalanceSnapshots(c *gin.Context) {
+	if s.Environ.BalanceSnapshotService == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database is not configured"})
+		return
+	}
+
+	since, until, err := parseQueryTimeRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	snapshots, err := s.Environ.BalanceSnapshotService.Query(service.QueryBalanceSnapshotsOptions{
+		Session:  c.Query("session"),
+		Currency: c.Query("currency"),
+		Since:    since,
+		Until:    until,
+	})
+	if err != nil {
+		c.Status(http.StatusBadRequest)
+		logrus.WithError(err).Error("balance snapshot query error")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"snapshots": snapshots,
+	})
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func Test_parseQueryTime(t *testing.T) {
+	v, err := parseQueryTime("2021-01-02T03:04:05Z")
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC), v.UTC())
+	}
+
+	v, err = parseQueryTime("2021-01-02")
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Date(2021, 1, 2, 0, 0, 0, 0, time.Local), v)
+	}
+
+	_, err = parseQueryTime("yesterday")
+	assert.Error(t, err)
+}
+
+func TestServer_QueryTrades(t *testing.T) {
+	server, r, _, _ := newAdminTestServer(t)
+
+	db := service.NewDatabaseService("sqlite3", ":memory:")
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	if err := db.Upgrade(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	tradeService := &service.TradeService{DB: db.DB}
+	server.Environ.TradeService = tradeService
+	server.Environ.OrderService = &service.OrderService{DB: db.DB}
+
+	session, _ := server.Environ.Session("binance")
+	session.ExchangeName = types.ExchangeBinance
+
+	tradeTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, tradeService.BatchInsert([]types.Trade{
+		{ID: 1, Exchange: "binance", Symbol: "BTCUSDT", Side: types.SideTypeBuy, Quantity: 0.1, QuoteQuantity: 100.0, Fee: 0.1, FeeCurrency: "BNB", Time: types.Time(tradeTime)},
+		{ID: 2, Exchange: "binance", Symbol: "BTCUSDT", Side: types.SideTypeSell, Quantity: 0.1, QuoteQuantity: 110.0, Fee: 0.1, FeeCurrency: "BNB", Time: types.Time(tradeTime.Add(time.Hour))},
+		{ID: 3, Exchange: "max", Symbol: "BTCUSDT", Side: types.SideTypeBuy, Quantity: 0.1, QuoteQuantity: 100.0, Fee: 0.1, FeeCurrency: "MAX", Time: types.Time(tradeTime)},
+	}))
+
+	t.Run("filter by session and side", func(t *testing.T) {
+		w := adminRequest(r, "GET", "/api/trades?session=binance&side=sell&since=2021-01-01T00:00:00Z", "secret", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Trades []types.Trade `json:"trades"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		if assert.Len(t, resp.Trades, 1) {
+			assert.Equal(t, int64(2), resp.Trades[0].ID)
+		}
+	})
+
+	t.Run("summary", func(t *testing.T) {
+		w := adminRequest(r, "GET", "/api/trades/summary?session=binance", "secret", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Summaries []service.TradeSummary `json:"summaries"`
+			Fees      []service.FeeSummary   `json:"fees"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		if assert.Len(t, resp.Summaries, 1) {
+			assert.Equal(t, int64(2), resp.Summaries[0].NumTrades)
+			assert.InDelta(t, 210.0, resp.Summaries[0].QuoteQuantity, 1e-9)
+		}
+		if assert.Len(t, resp.Fees, 1) {
+			assert.Equal(t, "BNB", resp.Fees[0].FeeCurrency)
+		}
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		w := adminRequest(r, "GET", "/api/trades?session=unknown", "secret", nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = adminRequest(r, "GET", "/api/orders/closed?until=tomorrow", "secret", nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestServer_QueryBalanceSnapshots(t *testing.T) {
+	server, r, _, _ := newAdminTestServer(t)
+
+	db := service.NewDatabaseService("sqlite3", ":memory:")
+	if err := db.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	if err := db.Upgrade(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshotService := &service.BalanceSnapshotService{DB: db.DB}
+	server.Environ.BalanceSnapshotService = snapshotService
+
+	snapshotTime := time.Date(2021, 12, 17, 0, 0, 0, 0, time.UTC)
+	for i, session := range []string{"binance", "max", "binance"} {
+		assert.NoError(t, snapshotService.Insert(&types.BalanceSnapshot{
+			Session:  session,
+			Currency: "USDT",
+			Value:    1000.0 + float64(i),
+			Time:     types.Time(snapshotTime.Add(time.Duration(i) * time.Hour)),
+		}))
+	}
+
+	w := adminRequest(r, "GET", "/api/balance-snapshots?session=binance", "secret", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Snapshots []types.BalanceSnapshot `json:"snapshots"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	if assert.Len(t, resp.Snapshots, 2) {
+		assert.Equal(t, 1000.0, resp.Snapshots[0].Value)
+		assert.Equal(t, 1002.0, resp.Snapshots[1].Value)
+	}
+
+	w = adminRequest(r, "GET", "/api/balance-snapshots?since=today", "secret", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
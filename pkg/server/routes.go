@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"math/rand"
@@ -45,6 +46,15 @@ type Server struct {
 	Setup         *Setup
 	OpenInBrowser bool
 
+	// AdminToken is the bearer token of the admin api, the admin api is disabled if it's empty
+	AdminToken string
+
+	// ConfigReloader reloads the strategy parameters through the admin api
+	ConfigReloader *bbgo.ConfigReloader
+
+	// dashboard is created with the admin api
+	dashboard *dashboard
+
 	srv *http.Server
 }
 
@@ -61,6 +71,9 @@ func (s *Server) newEngine() *gin.Engine {
 	}))
 
 	r.GET("/api/ping", s.ping)
+	r.GET("/healthz", s.healthz)
+	r.GET("/readyz", s.readyz)
+	r.GET("/api/events/ws", s.eventStreamAuth, s.eventStream)
 
 	if s.Setup != nil {
 		r.POST("/api/setup/test-db", s.setupTestDB)
@@ -88,41 +101,12 @@ func (s *Server) newEngine() *gin.Engine {
 		})
 	})
 
-	r.GET("/api/trades", func(c *gin.Context) {
-		if s.Environ.TradeService == nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "database is not configured"})
-			return
-		}
-
-		exchange := c.Query("exchange")
-		symbol := c.Query("symbol")
-		gidStr := c.DefaultQuery("gid", "0")
-		lastGID, err := strconv.ParseInt(gidStr, 10, 64)
-		if err != nil {
-			logrus.WithError(err).Error("last gid parse error")
-			c.Status(http.StatusBadRequest)
-			return
-		}
-
-		trades, err := s.Environ.TradeService.Query(service.QueryTradesOptions{
-			Exchange: types.ExchangeName(exchange),
-			Symbol:   symbol,
-			LastGID:  lastGID,
-			Ordering: "DESC",
-		})
-		if err != nil {
-			c.Status(http.StatusBadRequest)
-			logrus.WithError(err).Error("order query error")
-			return
-		}
-
-		c.JSON(http.StatusOK, gin.H{
-			"trades": trades,
-		})
-	})
-
+	r.GET("/api/trades", s.listTrades)
+	r.GET("/api/trades/summary", s.tradeSummary)
 	r.GET("/api/orders/closed", s.listClosedOrders)
 	r.GET("/api/trading-volume", s.tradingVolume)
+	r.GET("/api/profits", s.listProfits)
+	r.GET("/api/balance-snapshots", s.listBalanceSnapshots)
 
 	r.POST("/api/sessions/test", func(c *gin.Context) {
 		var session bbgo.ExchangeSession
@@ -231,12 +215,28 @@ func (s *Server) newEngine() *gin.Engine {
 	})
 
 	r.GET("/api/strategies/single", s.listStrategies)
+	r.GET("/api/strategies/states", s.listStrategyStates)
+	r.GET("/api/feature-flags", s.listFeatureFlags)
+	r.PUT("/api/feature-flags/:name", s.updateFeatureFlag)
+	r.GET("/api/commands", s.listCommands)
+	r.POST("/api/commands", s.enqueueCommand)
+
+	if s.Environ.SlackInteraction != nil {
+		r.POST("/api/slack/interactions", gin.WrapH(s.Environ.SlackInteraction))
+	}
+
+	s.registerAdminRoutes(r)
+
 	r.NoRoute(s.assetsHandler)
 	return r
 }
 
 func (s *Server) RunWithListener(ctx context.Context, l net.Listener) error {
 	r := s.newEngine()
+	if s.dashboard != nil {
+		go s.dashboard.Run(ctx)
+	}
+
 	bind := l.Addr().String()
 
 	if s.OpenInBrowser {
@@ -249,6 +249,10 @@ func (s *Server) RunWithListener(ctx context.Context, l net.Listener) error {
 
 func (s *Server) Run(ctx context.Context, bindArgs ...string) error {
 	r := s.newEngine()
+	if s.dashboard != nil {
+		go s.dashboard.Run(ctx)
+	}
+
 	bind := resolveBind(bindArgs)
 	if s.OpenInBrowser {
 		openBrowser(ctx, bind)
@@ -262,59 +266,143 @@ func (s *Server) ping(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "pong"})
 }
 
-func (s *Server) listClosedOrders(c *gin.Context) {
-	if s.Environ.OrderService == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "database is not configured"})
+func (s *Server) listStrategies(c *gin.Context) {
+	var stashes []map[string]interface{}
+
+	for _, mount := range s.Config.ExchangeStrategies {
+		stash, err := mount.Map()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		stash["strategy"] = mount.Strategy.ID()
+
+		stashes = append(stashes, stash)
+	}
+
+	if len(stashes) == 0 {
+		c.JSON(http.StatusOK, gin.H{"strategies": []int{}})
+	}
+	c.JSON(http.StatusOK, gin.H{"strategies": stashes})
+}
+
+func (s *Server) listStrategyStates(c *gin.Context) {
+	if s.Trader == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "trader is not running"})
 		return
 	}
 
-	exchange := c.Query("exchange")
-	symbol := c.Query("symbol")
-	gidStr := c.DefaultQuery("gid", "0")
+	states := s.Trader.StrategyStates()
+	if states == nil {
+		states = []bbgo.StrategyState{}
+	}
 
-	lastGID, err := strconv.ParseInt(gidStr, 10, 64)
-	if err != nil {
-		logrus.WithError(err).Error("last gid parse error")
-		c.Status(http.StatusBadRequest)
+	c.JSON(http.StatusOK, gin.H{"states": states})
+}
+
+func (s *Server) listFeatureFlags(c *gin.Context) {
+	if s.Trader == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "trader is not running"})
 		return
 	}
 
-	orders, err := s.Environ.OrderService.Query(service.QueryOrdersOptions{
-		Exchange: types.ExchangeName(exchange),
-		Symbol:   symbol,
-		LastGID:  lastGID,
-		Ordering: "DESC",
+	c.JSON(http.StatusOK, gin.H{
+		"flags":   s.Trader.FeatureFlags.Flags(),
+		"metrics": s.Trader.FeatureFlags.Metrics(),
 	})
+}
+
+func (s *Server) updateFeatureFlag(c *gin.Context) {
+	if s.Trader == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "trader is not running"})
+		return
+	}
+
+	var flag bbgo.FeatureFlag
+	if err := c.BindJSON(&flag); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if flag.Percentage < 0 || flag.Percentage > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "percentage should be between 0 and 100"})
+		return
+	}
+
+	name := c.Param("name")
+	s.Trader.FeatureFlags.Set(name, flag)
+	c.JSON(http.StatusOK, gin.H{"name": name, "flag": flag})
+}
+
+func (s *Server) listCommands(c *gin.Context) {
+	if s.Environ.CommandQueue == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database is not configured"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
 	if err != nil {
-		c.Status(http.StatusBadRequest)
-		logrus.WithError(err).Error("order query error")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"orders": orders,
-	})
+	commands, err := s.Environ.CommandQueue.Service.QueryLast(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if commands == nil {
+		commands = []types.Command{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"commands": commands})
 }
 
-func (s *Server) listStrategies(c *gin.Context) {
-	var stashes []map[string]interface{}
+func (s *Server) enqueueCommand(c *gin.Context) {
+	if s.Environ.CommandQueue == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database is not configured"})
+		return
+	}
 
-	for _, mount := range s.Config.ExchangeStrategies {
-		stash, err := mount.Map()
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	var request struct {
+		Type     types.CommandType `json:"type"`
+		Strategy string            `json:"strategy"`
+		Payload  json.RawMessage   `json:"payload"`
+	}
+
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch request.Type {
+	case types.CommandTypePauseStrategy, types.CommandTypeSuspendStrategy, types.CommandTypeResumeStrategy, types.CommandTypeSetParameter:
+		if request.Strategy == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "strategy is required"})
 			return
 		}
 
-		stash["strategy"] = mount.Strategy.ID()
+	case types.CommandTypeCancelOrder, types.CommandTypeCancelAllOrders, types.CommandTypeClosePosition, types.CommandTypeResetKillSwitch, types.CommandTypeHalt:
 
-		stashes = append(stashes, stash)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unsupported command type %s", request.Type)})
+		return
 	}
 
-	if len(stashes) == 0 {
-		c.JSON(http.StatusOK, gin.H{"strategies": []int{}})
+	var payload interface{}
+	if len(request.Payload) > 0 {
+		payload = request.Payload
 	}
-	c.JSON(http.StatusOK, gin.H{"strategies": stashes})
+
+	command, err := s.Environ.CommandQueue.Enqueue(request.Type, request.Strategy, payload, "api")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"command": command})
 }
 
 func (s *Server) listSessions(c *gin.Context) {
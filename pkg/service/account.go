@@ -24,11 +24,11 @@ func (s *AccountService) InsertAsset(time time.Time, name types.ExchangeName, ac
 
 	var err error
 	for _, v := range assets {
-		_, _err := s.DB.Exec(`
+		_, _err := s.DB.Exec(s.DB.Rebind(`
 			insert into nav_history_details ( exchange, subaccount, time, currency, balance_in_usd, balance_in_btc,
 		                                              balance,available,locked)
 				values (?,?,?,?,?,?,?,?,?);
-		`, name, account, time, v.Currency, v.InUSD, v.InBTC, v.Total, 0, 0 /* v.Available, v.Lock */)
+		`), name, account, time, v.Currency, v.InUSD, v.InBTC, v.Total, 0, 0 /* v.Available, v.Lock */)
 
 		err = multierr.Append(err, _err) // successful request
 
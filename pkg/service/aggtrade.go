@@ -0,0 +1,73 @@
+package service
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// AggTradeService stores the aggregated public trades
+type AggTradeService struct {
+	DB *sqlx.DB
+}
+
+// QueryLast queries the last aggregated trade of the symbol, nil is returned if there is no trade
+func (s *AggTradeService) QueryLast(ex types.ExchangeName, symbol string) (*types.AggTrade, error) {
+	rows, err := s.DB.NamedQuery("SELECT * FROM `agg_trades` WHERE `exchange` = :exchange AND `symbol` = :symbol ORDER BY `id` DESC LIMIT 1", map[string]interface{}{
+		"exchange": ex,
+		"symbol":   symbol,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	if rows.Next() {
+		var trade types.AggTrade
+		err = rows.StructScan(&trade)
+		return &trade, err
+	}
+
+	return nil, rows.Err()
+}
+
+// Query queries the aggregated trades in the time range [since, until)
+func (s *AggTradeService) Query(ex types.ExchangeName, symbol string, since, until time.Time) ([]types.AggTrade, error) {
+	rows, err := s.DB.NamedQuery("SELECT * FROM `agg_trades` WHERE `exchange` = :exchange AND `symbol` = :symbol AND `traded_at` >= :since AND `traded_at` < :until ORDER BY `id` ASC", map[string]interface{}{
+		"exchange": ex,
+		"symbol":   symbol,
+		"since":    since,
+		"until":    until,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var trades []types.AggTrade
+	for rows.Next() {
+		var trade types.AggTrade
+		if err := rows.StructScan(&trade); err != nil {
+			return trades, err
+		}
+
+		trades = append(trades, trade)
+	}
+
+	return trades, rows.Err()
+}
+
+// BatchInsert inserts the aggregated trades in one statement
+func (s *AggTradeService) BatchInsert(trades []types.AggTrade) error {
+	if len(trades) == 0 {
+		return nil
+	}
+
+	_, err := s.DB.NamedExec("INSERT INTO `agg_trades` (`id`, `exchange`, `symbol`, `price`, `quantity`, `first_trade_id`, `last_trade_id`, `is_buyer_maker`, `traded_at`)"+
+		" VALUES (:id, :exchange, :symbol, :price, :quantity, :first_trade_id, :last_trade_id, :is_buyer_maker, :traded_at)", trades)
+	return err
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestAggTradeService(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	xdb := sqlx.NewDb(db.DB, "sqlite3")
+	service := &AggTradeService{DB: xdb}
+
+	last, err := service.QueryLast(types.ExchangeBinance, "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Nil(t, last)
+
+	now := time.Now().Truncate(time.Second)
+	err = service.BatchInsert([]types.AggTrade{
+		{ID: 1, Exchange: types.ExchangeBinance, Symbol: "BTCUSDT", Price: 48000, Quantity: 0.1, FirstTradeID: 1, LastTradeID: 2, Time: types.Time(now.Add(-time.Minute))},
+		{ID: 2, Exchange: types.ExchangeBinance, Symbol: "BTCUSDT", Price: 48001, Quantity: 0.2, FirstTradeID: 3, LastTradeID: 3, IsBuyerMaker: true, Time: types.Time(now)},
+	})
+	assert.NoError(t, err)
+
+	last, err = service.QueryLast(types.ExchangeBinance, "BTCUSDT")
+	assert.NoError(t, err)
+	if assert.NotNil(t, last) {
+		assert.Equal(t, int64(2), last.ID)
+		assert.Equal(t, 48001.0, last.Price)
+		assert.True(t, last.IsBuyerMaker)
+	}
+
+	trades, err := service.Query(types.ExchangeBinance, "BTCUSDT", now.Add(-time.Hour), now)
+	assert.NoError(t, err)
+	if assert.Len(t, trades, 1) {
+		assert.Equal(t, int64(1), trades[0].ID)
+	}
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// KLineQuerier queries the stored klines, it's implemented by BacktestService
+type KLineQuerier interface {
+	QueryKLinesBackward(exchange types.ExchangeName, symbol string, interval types.Interval, endTime time.Time, limit int) ([]types.KLine, error)
+}
+
+// CorrelationMatrix is the pairwise correlation of the log returns of the symbols
+type CorrelationMatrix struct {
+	Symbols []string    `json:"symbols"`
+	Values  [][]float64 `json:"values"`
+}
+
+// Get returns the correlation of the two symbols
+func (m *CorrelationMatrix) Get(a, b string) (float64, bool) {
+	i, j := -1, -1
+	for k, symbol := range m.Symbols {
+		if symbol == a {
+			i = k
+		}
+		if symbol == b {
+			j = k
+		}
+	}
+
+	if i < 0 || j < 0 {
+		return 0, false
+	}
+
+	return m.Values[i][j], true
+}
+
+// AnalyticsService computes the rolling volatility and the correlations from the stored klines,
+// it's used by the pair trading strategies and the portfolio risk report.
+type AnalyticsService struct {
+	KLines KLineQuerier
+}
+
+// Volatility returns the annualized volatility (the standard deviation of the log returns)
+// of the last window klines before the end time
+func (s *AnalyticsService) Volatility(exchange types.ExchangeName, symbol string, interval types.Interval, endTime time.Time, window int) (float64, error) {
+	kLines, err := s.KLines.QueryKLinesBackward(exchange, symbol, interval, endTime, window+1)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(kLines) < 3 {
+		return 0, fmt.Errorf("insufficient %s %s klines for calculating volatility: %d", symbol, interval, len(kLines))
+	}
+
+	return AnnualizedVolatility(LogReturns(kLines), interval), nil
+}
+
+// CorrelationMatrix returns the correlations of the log returns of the symbols, the returns are aligned by the
+// kline end time, so that the missing klines of a symbol do not shift the series.
+func (s *AnalyticsService) CorrelationMatrix(exchange types.ExchangeName, symbols []string, interval types.Interval, endTime time.Time, window int) (*CorrelationMatrix, error) {
+	var series = make(map[string][]types.KLine, len(symbols))
+	for _, symbol := range symbols {
+		kLines, err := s.KLines.QueryKLinesBackward(exchange, symbol, interval, endTime, window+1)
+		if err != nil {
+			return nil, err
+		}
+
+		series[symbol] = kLines
+	}
+
+	var matrix = &CorrelationMatrix{
+		Symbols: symbols,
+		Values:  make([][]float64, len(symbols)),
+	}
+
+	for i := range symbols {
+		matrix.Values[i] = make([]float64, len(symbols))
+		matrix.Values[i][i] = 1.0
+	}
+
+	for i := 0; i < len(symbols); i++ {
+		for j := i + 1; j < len(symbols); j++ {
+			a, b := alignLogReturns(series[symbols[i]], series[symbols[j]])
+			if len(a) < 2 {
+				return nil, fmt.Errorf("insufficient aligned klines for calculating correlation of %s and %s", symbols[i], symbols[j])
+			}
+
+			c := Correlation(a, b)
+			matrix.Values[i][j] = c
+			matrix.Values[j][i] = c
+		}
+	}
+
+	return matrix, nil
+}
+
+// LogReturns returns the log returns of the close prices
+func LogReturns(kLines []types.KLine) (returns []float64) {
+	for i := 1; i < len(kLines); i++ {
+		if kLines[i-1].Close <= 0 || kLines[i].Close <= 0 {
+			continue
+		}
+
+		returns = append(returns, math.Log(kLines[i].Close/kLines[i-1].Close))
+	}
+
+	return returns
+}
+
+// alignLogReturns returns the log returns of the klines that both series have the previous and the current klines
+func alignLogReturns(a, b []types.KLine) (ra, rb []float64) {
+	var closes = make(map[int64]float64, len(b))
+	for _, k := range b {
+		closes[k.EndTime.Unix()] = k.Close
+	}
+
+	for i := 1; i < len(a); i++ {
+		prevB, ok := closes[a[i-1].EndTime.Unix()]
+		if !ok {
+			continue
+		}
+
+		curB, ok := closes[a[i].EndTime.Unix()]
+		if !ok {
+			continue
+		}
+
+		if a[i-1].Close <= 0 || a[i].Close <= 0 || prevB <= 0 || curB <= 0 {
+			continue
+		}
+
+		ra = append(ra, math.Log(a[i].Close/a[i-1].Close))
+		rb = append(rb, math.Log(curB/prevB))
+	}
+
+	return ra, rb
+}
+
+// AnnualizedVolatility returns the sample standard deviation of the returns scaled to one year
+func AnnualizedVolatility(returns []float64, interval types.Interval) float64 {
+	duration := interval.Duration()
+	if duration == 0 {
+		return 0
+	}
+
+	periodsPerYear := float64(365*24*time.Hour) / float64(duration)
+	return StdDev(returns) * math.Sqrt(periodsPerYear)
+}
+
+// StdDev returns the sample standard deviation
+func StdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+
+	mean := types.Float64Slice(values).Mean()
+
+	var sum float64
+	for _, v := range values {
+		sum += (v - mean) * (v - mean)
+	}
+
+	return math.Sqrt(sum / float64(len(values)-1))
+}
+
+// Correlation returns the Pearson correlation coefficient of the two series with the same length
+func Correlation(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) < 2 {
+		return 0
+	}
+
+	meanA := types.Float64Slice(a).Mean()
+	meanB := types.Float64Slice(b).Mean()
+
+	var cov, varA, varB float64
+	for i := range a {
+		da, db := a[i]-meanA, b[i]-meanB
+		cov += da * db
+		varA += da * da
+		varB += db * db
+	}
+
+	if varA == 0 || varB == 0 {
+		return 0
+	}
+
+	return cov / math.Sqrt(varA*varB)
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type mockKLineQuerier map[string][]types.KLine
+
+func (q mockKLineQuerier) QueryKLinesBackward(exchange types.ExchangeName, symbol string, interval types.Interval, endTime time.Time, limit int) ([]types.KLine, error) {
+	kLines := q[symbol]
+	if len(kLines) > limit {
+		kLines = kLines[len(kLines)-limit:]
+	}
+	return kLines, nil
+}
+
+func buildDailyKLines(symbol string, closes ...float64) (kLines []types.KLine) {
+	startTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, c := range closes {
+		kLines = append(kLines, types.KLine{
+			Symbol:   symbol,
+			Interval: types.Interval1d,
+			Close:    c,
+			EndTime:  startTime.AddDate(0, 0, i+1).Add(-time.Millisecond),
+		})
+	}
+	return kLines
+}
+
+func TestCorrelation(t *testing.T) {
+	assert.InDelta(t, 1.0, Correlation([]float64{1, 2, 3}, []float64{2, 4, 6}), 1e-9)
+	assert.InDelta(t, -1.0, Correlation([]float64{1, 2, 3}, []float64{3, 2, 1}), 1e-9)
+	assert.Equal(t, 0.0, Correlation([]float64{1, 1, 1}, []float64{3, 2, 1}))
+}
+
+func TestStdDev(t *testing.T) {
+	assert.InDelta(t, 1.0, StdDev([]float64{1, 2, 3}), 1e-9)
+	assert.Equal(t, 0.0, StdDev([]float64{1}))
+}
+
+func TestAnalyticsService(t *testing.T) {
+	querier := mockKLineQuerier{
+		"BTCUSDT": buildDailyKLines("BTCUSDT", 100, 110, 99, 108.9, 98.01),
+		"ETHUSDT": buildDailyKLines("ETHUSDT", 10, 11, 9.9, 10.89, 9.801),
+		"USDTTWD": buildDailyKLines("USDTTWD", 28, 27, 28, 27, 28),
+	}
+
+	s := &AnalyticsService{KLines: querier}
+
+	vol, err := s.Volatility("binance", "BTCUSDT", types.Interval1d, time.Now(), 10)
+	assert.NoError(t, err)
+
+	returns := []float64{math.Log(1.1), math.Log(0.9), math.Log(1.1), math.Log(0.9)}
+	assert.InDelta(t, StdDev(returns)*math.Sqrt(365), vol, 1e-9)
+
+	matrix, err := s.CorrelationMatrix("binance", []string{"BTCUSDT", "ETHUSDT", "USDTTWD"}, types.Interval1d, time.Now(), 10)
+	assert.NoError(t, err)
+
+	c, ok := matrix.Get("BTCUSDT", "ETHUSDT")
+	assert.True(t, ok)
+	assert.InDelta(t, 1.0, c, 1e-9)
+
+	c, ok = matrix.Get("USDTTWD", "BTCUSDT")
+	assert.True(t, ok)
+	assert.True(t, c < 0)
+
+	c, _ = matrix.Get("USDTTWD", "USDTTWD")
+	assert.Equal(t, 1.0, c)
+
+	_, ok = matrix.Get("BTCUSDT", "LTCUSDT")
+	assert.False(t, ok)
+}
+
+func Test_alignLogReturns(t *testing.T) {
+	a := buildDailyKLines("BTCUSDT", 100, 110, 121, 133.1)
+	b := buildDailyKLines("ETHUSDT", 10, 11, 12.1, 13.31)
+
+	// remove the third kline of b, only the first return can be aligned
+	b = append(b[:2], b[3])
+
+	ra, rb := alignLogReturns(a, b)
+	assert.Len(t, ra, 1)
+	assert.Len(t, rb, 1)
+	assert.InDelta(t, math.Log(1.1), rb[0], 1e-9)
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// BalanceSnapshotService stores the balance snapshots of the sessions for the equity curve
+type BalanceSnapshotService struct {
+	DB *sqlx.DB
+}
+
+type QueryBalanceSnapshotsOptions struct {
+	Session  string
+	Currency string
+	Since    *time.Time
+	Until    *time.Time
+}
+
+// Insert inserts the snapshot and sets the GID of the snapshot
+func (s *BalanceSnapshotService) Insert(snapshot *types.BalanceSnapshot) error {
+	gid, err := insertGID(s.DB, "INSERT INTO `balance_snapshots` (`session`, `exchange`, `currency`, `value`, `time`)"+
+		" VALUES (:session, :exchange, :currency, :value, :time)", snapshot)
+	if err != nil {
+		return err
+	}
+
+	snapshot.GID = gid
+	return nil
+}
+
+// Query queries the snapshots in the ascending order of the time
+func (s *BalanceSnapshotService) Query(options QueryBalanceSnapshotsOptions) ([]types.BalanceSnapshot, error) {
+	var where []string
+	var args = map[string]interface{}{}
+
+	if len(options.Session) > 0 {
+		where = append(where, "`session` = :session")
+		args["session"] = options.Session
+	}
+
+	if len(options.Currency) > 0 {
+		where = append(where, "`currency` = :currency")
+		args["currency"] = options.Currency
+	}
+
+	if options.Since != nil {
+		where = append(where, "`time` >= :since")
+		args["since"] = *options.Since
+	}
+
+	if options.Until != nil {
+		where = append(where, "`time` < :until")
+		args["until"] = *options.Until
+	}
+
+	sql := "SELECT * FROM `balance_snapshots`"
+	if len(where) > 0 {
+		sql += " WHERE " + strings.Join(where, " AND ")
+	}
+	sql += " ORDER BY `time` ASC, `gid` ASC"
+
+	rows, err := s.DB.NamedQuery(sql, args)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var snapshots []types.BalanceSnapshot
+	for rows.Next() {
+		var snapshot types.BalanceSnapshot
+		if err := rows.StructScan(&snapshot); err != nil {
+			return snapshots, err
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, rows.Err()
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestBalanceSnapshotService(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	service := &BalanceSnapshotService{DB: sqlx.NewDb(db.DB, "sqlite3")}
+
+	day := time.Date(2021, 12, 17, 0, 0, 0, 0, time.Local)
+	snapshots := []types.BalanceSnapshot{
+		{Session: "binance", Exchange: types.ExchangeBinance, Currency: "USDT", Value: 1000.0, Time: types.Time(day)},
+		{Session: "max", Exchange: types.ExchangeMax, Currency: "USDT", Value: 500.0, Time: types.Time(day)},
+		{Session: "binance", Exchange: types.ExchangeBinance, Currency: "USDT", Value: 1010.5, Time: types.Time(day.Add(time.Hour))},
+	}
+
+	for i := range snapshots {
+		assert.NoError(t, service.Insert(&snapshots[i]))
+		assert.NotZero(t, snapshots[i].GID)
+	}
+
+	records, err := service.Query(QueryBalanceSnapshotsOptions{Session: "binance"})
+	assert.NoError(t, err)
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, 1000.0, records[0].Value)
+		assert.Equal(t, 1010.5, records[1].Value)
+		assert.Equal(t, types.ExchangeBinance, records[1].Exchange)
+	}
+
+	since := day.Add(time.Minute)
+	records, err = service.Query(QueryBalanceSnapshotsOptions{Currency: "USDT", Since: &since})
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// CommandService stores the control commands of the command queue
+type CommandService struct {
+	DB *sqlx.DB
+}
+
+// Insert inserts the pending command and sets the GID of the command
+func (s *CommandService) Insert(command *types.Command) error {
+	if len(command.Status) == 0 {
+		command.Status = types.CommandStatusPending
+	}
+
+	if command.CreatedAt.Time().IsZero() {
+		command.CreatedAt = types.Time(time.Now())
+	}
+
+	if command.Payload == nil {
+		command.Payload = []byte("{}")
+	}
+
+	gid, err := insertGID(s.DB, "INSERT INTO `commands` (`type`, `strategy`, `payload`, `status`, `source`, `created_at`)"+
+		" VALUES (:type, :strategy, :payload, :status, :source, :created_at)", command)
+	if err != nil {
+		return err
+	}
+
+	command.GID = gid
+	return nil
+}
+
+// QueryPending queries the pending commands in the issued order
+func (s *CommandService) QueryPending(limit int) ([]types.Command, error) {
+	return s.query("SELECT * FROM `commands` WHERE `status` = :status ORDER BY `gid` ASC LIMIT :limit", map[string]interface{}{
+		"status": types.CommandStatusPending,
+		"limit":  limit,
+	})
+}
+
+// QueryLast queries the last commands in the descending order
+func (s *CommandService) QueryLast(limit int) ([]types.Command, error) {
+	return s.query("SELECT * FROM `commands` ORDER BY `gid` DESC LIMIT :limit", map[string]interface{}{
+		"limit": limit,
+	})
+}
+
+func (s *CommandService) query(sql string, args map[string]interface{}) ([]types.Command, error) {
+	rows, err := s.DB.NamedQuery(sql, args)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var commands []types.Command
+	for rows.Next() {
+		var command types.Command
+		if err := rows.StructScan(&command); err != nil {
+			return commands, err
+		}
+
+		commands = append(commands, command)
+	}
+
+	return commands, rows.Err()
+}
+
+// MarkProcessed updates the status of the processed command, the error message is saved for the failed command
+func (s *CommandService) MarkProcessed(gid int64, status types.CommandStatus, errorMessage string) error {
+	var errorValue interface{}
+	if len(errorMessage) > 0 {
+		errorValue = errorMessage
+	}
+
+	_, err := s.DB.NamedExec("UPDATE `commands` SET `status` = :status, `error` = :error, `processed_at` = :processed_at WHERE `gid` = :gid", map[string]interface{}{
+		"gid":          gid,
+		"status":       status,
+		"error":        errorValue,
+		"processed_at": time.Now(),
+	})
+	return err
+}
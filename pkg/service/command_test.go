@@ -0,0 +1,60 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestCommandService(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	xdb := sqlx.NewDb(db.DB, "sqlite3")
+	service := &CommandService{DB: xdb}
+
+	pause := &types.Command{Type: types.CommandTypePauseStrategy, Strategy: "grid", Source: "api"}
+	assert.NoError(t, service.Insert(pause))
+	assert.NotZero(t, pause.GID)
+
+	cancel := &types.Command{
+		Type:    types.CommandTypeCancelOrder,
+		Payload: []byte(`{"session":"binance","symbol":"BTCUSDT","orderID":123}`),
+		Source:  "telegram",
+	}
+	assert.NoError(t, service.Insert(cancel))
+
+	commands, err := service.QueryPending(10)
+	assert.NoError(t, err)
+	if assert.Len(t, commands, 2) {
+		assert.Equal(t, pause.GID, commands[0].GID)
+		assert.Equal(t, types.CommandStatusPending, commands[0].Status)
+		assert.Equal(t, "grid", commands[0].Strategy)
+		assert.JSONEq(t, `{}`, string(commands[0].Payload))
+		assert.JSONEq(t, `{"session":"binance","symbol":"BTCUSDT","orderID":123}`, string(commands[1].Payload))
+	}
+
+	assert.NoError(t, service.MarkProcessed(pause.GID, types.CommandStatusDone, ""))
+	assert.NoError(t, service.MarkProcessed(cancel.GID, types.CommandStatusFailed, "order not found"))
+
+	commands, err = service.QueryPending(10)
+	assert.NoError(t, err)
+	assert.Len(t, commands, 0)
+
+	commands, err = service.QueryLast(10)
+	assert.NoError(t, err)
+	if assert.Len(t, commands, 2) {
+		assert.Equal(t, cancel.GID, commands[0].GID)
+		assert.Equal(t, types.CommandStatusFailed, commands[0].Status)
+		assert.Equal(t, "order not found", commands[0].Error.String)
+		assert.NotNil(t, commands[0].ProcessedAt)
+		assert.False(t, commands[1].Error.Valid)
+	}
+}
@@ -2,15 +2,27 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/c9s/rockhopper"
 	"github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 
 	mysqlMigrations "github.com/c9s/bbgo/pkg/migrations/mysql"
+	postgresMigrations "github.com/c9s/bbgo/pkg/migrations/postgres"
 	sqlite3Migrations "github.com/c9s/bbgo/pkg/migrations/sqlite3"
 )
 
+// DefaultSQLiteDSN is the sqlite3 database file used by the sync and the backtest when no database is configured
+const DefaultSQLiteDSN = "var/data/bbgo.sqlite3"
+
+// sqliteBusyTimeout is the milliseconds to wait for the lock, the sync writes the records concurrently
+const sqliteBusyTimeout = 5000
+
 type DatabaseService struct {
 	Driver string
 	DSN    string
@@ -18,13 +30,17 @@ type DatabaseService struct {
 }
 
 func NewDatabaseService(driver, dsn string) *DatabaseService {
-	if driver == "mysql" {
+	switch driver {
+	case "mysql":
 		var err error
 		dsn, err = ReformatMysqlDSN(dsn)
 		if err != nil {
 			// incorrect mysql dsn is logical exception
 			panic(err)
 		}
+
+	case "sqlite3":
+		dsn = ReformatSQLiteDSN(dsn)
 	}
 
 	return &DatabaseService{
@@ -35,33 +51,96 @@ func NewDatabaseService(driver, dsn string) *DatabaseService {
 }
 
 func (s *DatabaseService) Connect() error {
+	var memory = false
+	if s.Driver == "sqlite3" {
+		path := SQLiteFilePath(s.DSN)
+		memory = len(path) == 0
+
+		// create the directory of the database file, e.g., var/data
+		if dir := filepath.Dir(path); !memory && dir != "." {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return err
+			}
+		}
+	}
+
 	var err error
-	s.DB, err = sqlx.Connect(s.Driver, s.DSN)
-	return err
-}
+	if s.Driver == "postgres" {
+		// the queries are rewritten by the postgres driver, the driver name of sqlx selects the bind type of postgres
+		var db *sql.DB
+		db, err = sql.Open(postgresDriverName, s.DSN)
+		if err != nil {
+			return err
+		}
 
-func (s *DatabaseService) Close() error {
-	return s.DB.Close()
-}
+		if err = db.Ping(); err != nil {
+			_ = db.Close()
+			return err
+		}
+
+		s.DB = sqlx.NewDb(db, s.Driver)
+	} else {
+		s.DB, err = sqlx.Connect(s.Driver, s.DSN)
+	}
 
-func (s *DatabaseService) Upgrade(ctx context.Context) error {
-	dialect, err := rockhopper.LoadDialect(s.Driver)
 	if err != nil {
 		return err
 	}
 
-	var migrations rockhopper.MigrationSlice
+	// every connection of the in-memory database is a new database
+	if memory {
+		s.DB.SetMaxOpenConns(1)
+	}
+
+	return nil
+}
+
+func (s *DatabaseService) Close() error {
+	return s.DB.Close()
+}
+
+// MigrationStatus is the status of a migration embedded in the binary
+type MigrationStatus struct {
+	Version int64  `json:"version"`
+	Name    string `json:"name"`
+	Applied bool   `json:"applied"`
+}
 
+// migrations returns the migrations of the driver, the migrations are compiled into the go files of pkg/migrations
+func (s *DatabaseService) migrations() (rockhopper.MigrationSlice, error) {
 	switch s.Driver {
 	case "sqlite3":
-		migrations = sqlite3Migrations.Migrations()
+		return sqlite3Migrations.Migrations(), nil
 	case "mysql":
-		migrations = mysqlMigrations.Migrations()
+		return mysqlMigrations.Migrations(), nil
+	case "postgres":
+		return postgresMigrations.Migrations(), nil
+	}
+
+	return nil, fmt.Errorf("database driver %s is not supported", s.Driver)
+}
+
+func (s *DatabaseService) migrator() (*rockhopper.DB, rockhopper.MigrationSlice, error) {
+	dialect, err := rockhopper.LoadDialect(s.Driver)
+	if err != nil {
+		return nil, nil, err
+	}
 
+	migrations, err := s.migrations()
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// sqlx.DB is different from sql.DB
-	rh := rockhopper.New(s.Driver, dialect, s.DB.DB)
+	return rockhopper.New(s.Driver, dialect, s.DB.DB), migrations, nil
+}
+
+// Upgrade applies the pending migrations
+func (s *DatabaseService) Upgrade(ctx context.Context) error {
+	rh, migrations, err := s.migrator()
+	if err != nil {
+		return err
+	}
 
 	currentVersion, err := rh.CurrentVersion()
 	if err != nil {
@@ -75,6 +154,106 @@ func (s *DatabaseService) Upgrade(ctx context.Context) error {
 	return nil
 }
 
+// Downgrade rolls back the last applied migrations by the given steps
+func (s *DatabaseService) Downgrade(ctx context.Context, steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("the steps of the downgrade should be positive, got %d", steps)
+	}
+
+	rh, migrations, err := s.migrator()
+	if err != nil {
+		return err
+	}
+
+	currentVersion, err := rh.CurrentVersion()
+	if err != nil {
+		return err
+	}
+
+	to := downgradeVersion(migrations, currentVersion, steps)
+	if to == currentVersion {
+		return nil
+	}
+
+	m, err := migrations.Find(currentVersion)
+	if err != nil {
+		return err
+	}
+
+	// rockhopper.Down also rolls back the migration of the target version, and it never rolls back the first
+	// migration, so the migrations newer than the remaining version are rolled back one by one
+	for ; m != nil && m.Version > to; m = m.Previous {
+		if err := m.Down(ctx, rh); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// downgradeVersion returns the version that remains applied after rolling back the steps of the applied migrations,
+// 0 if all the migrations are rolled back
+func downgradeVersion(migrations rockhopper.MigrationSlice, currentVersion int64, steps int) int64 {
+	var applied []int64
+	for _, m := range migrations {
+		if m.Version <= currentVersion {
+			applied = append(applied, m.Version)
+		}
+	}
+
+	if steps >= len(applied) {
+		return 0
+	}
+
+	return applied[len(applied)-steps-1]
+}
+
+// MigrationStatus returns the status of the migrations of the driver in the version order
+func (s *DatabaseService) MigrationStatus() ([]MigrationStatus, error) {
+	rh, migrations, err := s.migrator()
+	if err != nil {
+		return nil, err
+	}
+
+	currentVersion, err := rh.CurrentVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	return migrationStatus(migrations, currentVersion), nil
+}
+
+func migrationStatus(migrations rockhopper.MigrationSlice, currentVersion int64) []MigrationStatus {
+	var statuses []MigrationStatus
+	for _, m := range migrations {
+		name := strings.TrimSuffix(filepath.Base(m.Source), filepath.Ext(m.Source))
+		statuses = append(statuses, MigrationStatus{
+			Version: m.Version,
+			Name:    name,
+			Applied: m.Version <= currentVersion,
+		})
+	}
+
+	return statuses
+}
+
+// PendingMigrations returns the migrations not applied yet
+func (s *DatabaseService) PendingMigrations() ([]MigrationStatus, error) {
+	statuses, err := s.MigrationStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []MigrationStatus
+	for _, status := range statuses {
+		if !status.Applied {
+			pending = append(pending, status)
+		}
+	}
+
+	return pending, nil
+}
+
 func ReformatMysqlDSN(dsn string) (string, error) {
 	config, err := mysql.ParseDSN(dsn)
 	if err != nil {
@@ -86,3 +265,35 @@ func ReformatMysqlDSN(dsn string) (string, error) {
 	dsn = config.FormatDSN()
 	return dsn, nil
 }
+
+// ReformatSQLiteDSN sets the busy timeout of the sqlite3 dsn if it's not set, so that the concurrent writes wait for
+// the lock instead of failing with "database is locked"
+func ReformatSQLiteDSN(dsn string) string {
+	// _timeout is the alias of _busy_timeout
+	if strings.Contains(dsn, "_timeout=") {
+		return dsn
+	}
+
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+
+	return fmt.Sprintf("%s%s_busy_timeout=%d", dsn, separator, sqliteBusyTimeout)
+}
+
+// SQLiteFilePath returns the database file of the sqlite3 dsn, it's empty for the in-memory database
+func SQLiteFilePath(dsn string) string {
+	path := strings.TrimPrefix(dsn, "file:")
+
+	var query string
+	if i := strings.Index(path, "?"); i >= 0 {
+		path, query = path[:i], path[i+1:]
+	}
+
+	if path == ":memory:" || len(path) == 0 || strings.Contains(query, "mode=memory") {
+		return ""
+	}
+
+	return path
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/c9s/rockhopper"
+	"github.com/stretchr/testify/assert"
+
+	postgresMigrations "github.com/c9s/bbgo/pkg/migrations/postgres"
+)
+
+func TestReformatSQLiteDSN(t *testing.T) {
+	assert.Equal(t, "bbgo.sqlite3?_busy_timeout=5000", ReformatSQLiteDSN("bbgo.sqlite3"))
+	assert.Equal(t, "file:bbgo.sqlite3?cache=shared&_busy_timeout=5000", ReformatSQLiteDSN("file:bbgo.sqlite3?cache=shared"))
+	assert.Equal(t, "bbgo.sqlite3?_timeout=100", ReformatSQLiteDSN("bbgo.sqlite3?_timeout=100"))
+	assert.Equal(t, "bbgo.sqlite3?_busy_timeout=100", ReformatSQLiteDSN("bbgo.sqlite3?_busy_timeout=100"))
+}
+
+func TestSQLiteFilePath(t *testing.T) {
+	assert.Equal(t, "var/data/bbgo.sqlite3", SQLiteFilePath(DefaultSQLiteDSN))
+	assert.Equal(t, "bbgo.sqlite3", SQLiteFilePath("file:bbgo.sqlite3?_busy_timeout=5000"))
+	assert.Equal(t, "", SQLiteFilePath(":memory:"))
+	assert.Equal(t, "", SQLiteFilePath("file:test.db?mode=memory&cache=shared"))
+}
+
+func TestDatabaseService_SQLite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bbgo-sqlite-*")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	// the directory of the database file is created
+	dsn := filepath.Join(dir, "data", "bbgo.sqlite3")
+	db := NewDatabaseService("sqlite3", dsn)
+	if !assert.NoError(t, db.Connect()) {
+		return
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	assert.NoError(t, db.Upgrade(ctx))
+
+	// upgrading the migrated database does nothing
+	assert.NoError(t, db.Upgrade(ctx))
+
+	_, err = os.Stat(dsn)
+	assert.NoError(t, err)
+
+	for _, table := range []string{"trades", "orders", "klines", "persistence", "profits"} {
+		var count int
+		assert.NoError(t, db.DB.Get(&count, "SELECT COUNT(*) FROM "+table), table)
+	}
+}
+
+func migrationNames(t *testing.T, dir string) map[string]bool {
+	files, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+
+	versionPrefix := regexp.MustCompile(`^\d+_`)
+	m := make(map[string]bool)
+	for _, file := range files {
+		m[versionPrefix.ReplaceAllString(file.Name(), "")] = true
+	}
+	return m
+}
+
+// the sqlite3 migrations should follow the mysql migrations, except the column length changes that sqlite3 doesn't need
+func TestMigrations_SQLite3(t *testing.T) {
+	mysqlOnly := map[string]bool{
+		"klines_symbol_length.sql":    true,
+		"increase_symbol_length.sql":  true,
+		"increase_decimal_length.sql": true,
+	}
+
+	sqlite3Names := migrationNames(t, "../../migrations/sqlite3")
+	for name := range migrationNames(t, "../../migrations/mysql") {
+		if mysqlOnly[name] {
+			continue
+		}
+
+		assert.True(t, sqlite3Names[name], "sqlite3 migration %s is missing", name)
+	}
+}
+
+// the postgres migrations follow the mysql migrations with the same versions, and they are compiled into the go migrations
+func TestMigrations_Postgres(t *testing.T) {
+	postgresNames := migrationNames(t, "../../migrations/postgres")
+	for name := range migrationNames(t, "../../migrations/mysql") {
+		assert.True(t, postgresNames[name], "postgres migration %s is missing", name)
+	}
+
+	// every sql migration is compiled
+	assert.Len(t, postgresMigrations.Migrations(), len(postgresNames))
+}
+
+func TestDatabaseService_MigrationStatus(t *testing.T) {
+	db := NewDatabaseService("sqlite3", ":memory:")
+	if !assert.NoError(t, db.Connect()) {
+		return
+	}
+	defer db.Close()
+
+	pending, err := db.PendingMigrations()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	all, err := db.MigrationStatus()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Len(t, pending, len(all))
+	assert.Equal(t, "20200721225616_trades", all[0].Name)
+
+	ctx := context.Background()
+	if !assert.NoError(t, db.Upgrade(ctx)) {
+		return
+	}
+
+	pending, err = db.PendingMigrations()
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+
+	// roll back the profits and the balance snapshots tables
+	if !assert.NoError(t, db.Downgrade(ctx, 2)) {
+		return
+	}
+
+	pending, err = db.PendingMigrations()
+	assert.NoError(t, err)
+	if assert.Len(t, pending, 2) {
+		assert.Equal(t, "20211216090000_add_profits_table", pending[0].Name)
+		assert.Equal(t, "20211217090000_add_balance_snapshots_table", pending[1].Name)
+	}
+
+	assert.Error(t, db.Downgrade(ctx, 0))
+}
+
+func Test_downgradeVersion(t *testing.T) {
+	migrations := rockhopper.MigrationSlice{
+		&rockhopper.Migration{Version: 1},
+		&rockhopper.Migration{Version: 2},
+		&rockhopper.Migration{Version: 3},
+	}
+
+	assert.Equal(t, int64(2), downgradeVersion(migrations, 3, 1))
+	assert.Equal(t, int64(1), downgradeVersion(migrations, 3, 2))
+	assert.Equal(t, int64(0), downgradeVersion(migrations, 3, 3))
+	assert.Equal(t, int64(0), downgradeVersion(migrations, 3, 5))
+	assert.Equal(t, int64(1), downgradeVersion(migrations, 2, 1))
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	batch2 "github.com/c9s/bbgo/pkg/exchange/batch"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultCompactionBatchSize = 1000
+
+// KLineStore stores and queries the klines, it's implemented by BacktestService
+type KLineStore interface {
+	QueryFirstKLine(ex types.ExchangeName, symbol string, interval types.Interval) (*types.KLine, error)
+	QueryLastKLine(ex types.ExchangeName, symbol string, interval types.Interval) (*types.KLine, error)
+	QueryKLinesForward(exchange types.ExchangeName, symbol string, interval types.Interval, startTime time.Time, limit int) ([]types.KLine, error)
+	BatchInsert(kLines []types.KLine) error
+}
+
+// KLineMismatch is the difference between the compacted kline and the kline of the exchange
+type KLineMismatch struct {
+	Symbol    string
+	Interval  types.Interval
+	StartTime time.Time
+
+	// Field is the mismatched field, e.g., "close", or "missing" if the exchange does not have the kline
+	Field    string
+	Stored   float64
+	Exchange float64
+}
+
+func (m KLineMismatch) String() string {
+	if m.Field == "missing" {
+		return fmt.Sprintf("%s %s kline at %s is not found on the exchange", m.Symbol, m.Interval, m.StartTime)
+	}
+
+	return fmt.Sprintf("%s %s kline at %s %s mismatch: stored %f, exchange %f", m.Symbol, m.Interval, m.StartTime, m.Field, m.Stored, m.Exchange)
+}
+
+// KLineCompactor builds the klines of the higher intervals (e.g., 5m, 1h, 1d) from the stored klines of the base
+// interval (1m), so that only the base interval needs to be synchronized from the exchange.
+// The compaction is incremental, it continues from the last stored kline of the target interval.
+type KLineCompactor struct {
+	Store KLineStore
+
+	// BaseInterval is the interval of the source klines, defaults to 1m
+	BaseInterval types.Interval
+
+	// BatchSize is the number of the klines of one query and one insert, defaults to 1000
+	BatchSize int
+}
+
+func (c *KLineCompactor) baseInterval() types.Interval {
+	if len(c.BaseInterval) == 0 {
+		return types.Interval1m
+	}
+
+	return c.BaseInterval
+}
+
+func (c *KLineCompactor) batchSize() int {
+	if c.BatchSize <= 0 {
+		return defaultCompactionBatchSize
+	}
+
+	return c.BatchSize
+}
+
+// Compact builds and stores the closed klines of the interval from the stored base klines, the compacted klines
+// are returned. The kline that is not closed yet (the base klines of the whole interval are not stored yet) is not stored.
+// If some base klines are missing, the kline is built from the stored base klines.
+func (c *KLineCompactor) Compact(ctx context.Context, exchange types.ExchangeName, symbol string, interval types.Interval) ([]types.KLine, error) {
+	baseInterval := c.baseInterval()
+	duration, baseDuration := interval.Duration(), baseInterval.Duration()
+	if duration == 0 || baseDuration == 0 || duration <= baseDuration || duration%baseDuration != 0 {
+		return nil, fmt.Errorf("interval %s can not be compacted from %s", interval, baseInterval)
+	}
+
+	var startTime time.Time
+
+	lastKLine, err := c.Store.QueryLastKLine(exchange, symbol, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastKLine != nil {
+		startTime = lastKLine.StartTime.Add(duration)
+	} else {
+		firstKLine, err := c.Store.QueryFirstKLine(exchange, symbol, baseInterval)
+		if err != nil {
+			return nil, err
+		}
+
+		if firstKLine == nil {
+			return nil, nil
+		}
+
+		// the kline that the first base kline is in the middle of is incomplete, it's skipped
+		startTime = firstKLine.StartTime
+	}
+
+	var compacted []types.KLine
+
+	builder := types.NewKLineBuilder(exchange, symbol, interval)
+	builder.OnKLineClosed(func(k types.KLine) {
+		if !k.StartTime.Before(startTime) {
+			compacted = append(compacted, k)
+		}
+	})
+
+	batchSize := c.batchSize()
+	cursor := startTime
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		kLines, err := c.Store.QueryKLinesForward(exchange, symbol, baseInterval, cursor, batchSize)
+		if err != nil {
+			return nil, errors.Wrapf(err, "can not query %s %s klines", symbol, baseInterval)
+		}
+
+		for _, k := range kLines {
+			if k.StartTime.Before(startTime) {
+				continue
+			}
+
+			builder.AddKLine(k)
+		}
+
+		if len(kLines) < batchSize {
+			break
+		}
+
+		cursor = kLines[len(kLines)-1].EndTime.Add(time.Millisecond)
+	}
+
+	for i := 0; i < len(compacted); i += batchSize {
+		end := i + batchSize
+		if end > len(compacted) {
+			end = len(compacted)
+		}
+
+		if err := c.Store.BatchInsert(compacted[i:end]); err != nil {
+			return nil, errors.Wrapf(err, "can not insert %s %s klines", symbol, interval)
+		}
+	}
+
+	if len(compacted) > 0 {
+		log.Infof("compacted %d %s %s klines from %s to %s", len(compacted), symbol, interval,
+			compacted[0].StartTime, compacted[len(compacted)-1].EndTime)
+	}
+
+	return compacted, nil
+}
+
+// Verify compares the compacted klines with the klines queried from the exchange, the prices and the volume
+// are compared by the relative tolerance, e.g., 0.0001
+func (c *KLineCompactor) Verify(ctx context.Context, exchange types.Exchange, kLines []types.KLine, tolerance float64) ([]KLineMismatch, error) {
+	if len(kLines) == 0 {
+		return nil, nil
+	}
+
+	symbol, interval := kLines[0].Symbol, kLines[0].Interval
+	startTime, endTime := kLines[0].StartTime, kLines[len(kLines)-1].EndTime
+
+	remoteKLines := make(map[int64]types.KLine)
+
+	batch := &batch2.KLineBatchQuery{Exchange: exchange}
+	kLineC, errC := batch.Query(ctx, symbol, interval, startTime, endTime)
+	for ks := range kLineC {
+		for _, k := range ks {
+			remoteKLines[k.StartTime.Unix()] = k
+		}
+	}
+
+	if err := <-errC; err != nil {
+		return nil, err
+	}
+
+	var mismatches []KLineMismatch
+	for _, k := range kLines {
+		remote, ok := remoteKLines[k.StartTime.Unix()]
+		if !ok {
+			mismatches = append(mismatches, KLineMismatch{Symbol: symbol, Interval: interval, StartTime: k.StartTime, Field: "missing"})
+			continue
+		}
+
+		fields := []struct {
+			name             string
+			stored, exchange float64
+		}{
+			{"open", k.Open, remote.Open},
+			{"high", k.High, remote.High},
+			{"low", k.Low, remote.Low},
+			{"close", k.Close, remote.Close},
+			{"volume", k.Volume, remote.Volume},
+		}
+
+		for _, f := range fields {
+			if !withinTolerance(f.stored, f.exchange, tolerance) {
+				mismatches = append(mismatches, KLineMismatch{
+					Symbol:    symbol,
+					Interval:  interval,
+					StartTime: k.StartTime,
+					Field:     f.name,
+					Stored:    f.stored,
+					Exchange:  f.exchange,
+				})
+			}
+		}
+	}
+
+	return mismatches, nil
+}
+
+func withinTolerance(a, b, tolerance float64) bool {
+	if a == b {
+		return true
+	}
+
+	return math.Abs(a-b) <= tolerance*math.Max(math.Abs(a), math.Abs(b))
+}
+
+// Run compacts the klines of the symbols and the intervals periodically until the context is canceled,
+// the compacted klines are verified with the exchange if the verify tolerance is greater than zero.
+func (c *KLineCompactor) Run(ctx context.Context, exchange types.Exchange, symbols []string, intervals []types.Interval, period time.Duration, verifyTolerance float64) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		for _, symbol := range symbols {
+			for _, interval := range intervals {
+				kLines, err := c.Compact(ctx, exchange.Name(), symbol, interval)
+				if err != nil {
+					log.WithError(err).Errorf("can not compact %s %s klines", symbol, interval)
+					continue
+				}
+
+				if verifyTolerance <= 0 {
+					continue
+				}
+
+				mismatches, err := c.Verify(ctx, exchange, kLines, verifyTolerance)
+				if err != nil {
+					log.WithError(err).Errorf("can not verify %s %s klines", symbol, interval)
+					continue
+				}
+
+				for _, m := range mismatches {
+					log.Warn(m.String())
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+		}
+	}
+}
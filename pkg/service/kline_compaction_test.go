@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type mockKLineStore struct {
+	kLines map[types.Interval][]types.KLine
+}
+
+func (s *mockKLineStore) QueryFirstKLine(ex types.ExchangeName, symbol string, interval types.Interval) (*types.KLine, error) {
+	kLines := s.kLines[interval]
+	if len(kLines) == 0 {
+		return nil, nil
+	}
+	return &kLines[0], nil
+}
+
+func (s *mockKLineStore) QueryLastKLine(ex types.ExchangeName, symbol string, interval types.Interval) (*types.KLine, error) {
+	kLines := s.kLines[interval]
+	if len(kLines) == 0 {
+		return nil, nil
+	}
+	return &kLines[len(kLines)-1], nil
+}
+
+func (s *mockKLineStore) QueryKLinesForward(exchange types.ExchangeName, symbol string, interval types.Interval, startTime time.Time, limit int) (kLines []types.KLine, err error) {
+	for _, k := range s.kLines[interval] {
+		if k.StartTime.Before(startTime) {
+			continue
+		}
+
+		kLines = append(kLines, k)
+		if len(kLines) == limit {
+			break
+		}
+	}
+	return kLines, nil
+}
+
+func (s *mockKLineStore) BatchInsert(kLines []types.KLine) error {
+	for _, k := range kLines {
+		s.kLines[k.Interval] = append(s.kLines[k.Interval], k)
+	}
+	return nil
+}
+
+type mockKLineExchange struct {
+	types.Exchange
+
+	kLines []types.KLine
+}
+
+func (e *mockKLineExchange) QueryKLines(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) (kLines []types.KLine, err error) {
+	for _, k := range e.kLines {
+		if k.Interval != interval || k.StartTime.Before(*options.StartTime) {
+			continue
+		}
+		kLines = append(kLines, k)
+	}
+	return kLines, nil
+}
+
+var compactionStartTime = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// buildMinuteKLines builds the 1m klines from the given minute with the closes 1, 2, 3...
+func buildMinuteKLines(fromMinute, n int) (kLines []types.KLine) {
+	for i := fromMinute; i < fromMinute+n; i++ {
+		startTime := compactionStartTime.Add(time.Duration(i) * time.Minute)
+		kLines = append(kLines, types.KLine{
+			Exchange:  types.ExchangeBinance,
+			Symbol:    "BTCUSDT",
+			Interval:  types.Interval1m,
+			StartTime: startTime,
+			EndTime:   startTime.Add(time.Minute - time.Millisecond),
+			Open:      float64(i),
+			High:      float64(i) + 0.5,
+			Low:       float64(i) - 0.5,
+			Close:     float64(i + 1),
+			Volume:    1.0,
+			Closed:    true,
+		})
+	}
+	return kLines
+}
+
+func TestKLineCompactor_Compact(t *testing.T) {
+	ctx := context.Background()
+
+	// the first kline starts at 00:02, the 00:00 5m kline is incomplete
+	store := &mockKLineStore{kLines: map[types.Interval][]types.KLine{
+		types.Interval1m: buildMinuteKLines(2, 11),
+	}}
+
+	compactor := &KLineCompactor{Store: store, BatchSize: 4}
+
+	kLines, err := compactor.Compact(ctx, types.ExchangeBinance, "BTCUSDT", types.Interval5m)
+	assert.NoError(t, err)
+	if assert.Len(t, kLines, 1) {
+		k := kLines[0]
+		assert.Equal(t, compactionStartTime.Add(5*time.Minute), k.StartTime)
+		assert.Equal(t, compactionStartTime.Add(10*time.Minute-time.Millisecond), k.EndTime)
+		assert.Equal(t, types.Interval5m, k.Interval)
+		assert.Equal(t, 5.0, k.Open)
+		assert.Equal(t, 9.5, k.High)
+		assert.Equal(t, 4.5, k.Low)
+		assert.Equal(t, 10.0, k.Close)
+		assert.Equal(t, 5.0, k.Volume)
+	}
+
+	// the 00:10 kline is not closed yet
+	assert.Len(t, store.kLines[types.Interval5m], 1)
+
+	// nothing is compacted until the 00:10 kline is closed
+	kLines, err = compactor.Compact(ctx, types.ExchangeBinance, "BTCUSDT", types.Interval5m)
+	assert.NoError(t, err)
+	assert.Len(t, kLines, 0)
+
+	// the compaction continues from the last 5m kline
+	store.kLines[types.Interval1m] = append(store.kLines[types.Interval1m], buildMinuteKLines(13, 7)...)
+	kLines, err = compactor.Compact(ctx, types.ExchangeBinance, "BTCUSDT", types.Interval5m)
+	assert.NoError(t, err)
+	if assert.Len(t, kLines, 2) {
+		assert.Equal(t, compactionStartTime.Add(10*time.Minute), kLines[0].StartTime)
+		assert.Equal(t, 10.0, kLines[0].Open)
+		assert.Equal(t, 15.0, kLines[0].Close)
+		assert.Equal(t, compactionStartTime.Add(15*time.Minute), kLines[1].StartTime)
+	}
+	assert.Len(t, store.kLines[types.Interval5m], 3)
+
+	_, err = compactor.Compact(ctx, types.ExchangeBinance, "BTCUSDT", types.Interval1m)
+	assert.Error(t, err)
+
+	kLines, err = (&KLineCompactor{Store: &mockKLineStore{}}).Compact(ctx, types.ExchangeBinance, "BTCUSDT", types.Interval1h)
+	assert.NoError(t, err)
+	assert.Len(t, kLines, 0)
+}
+
+func TestKLineCompactor_Verify(t *testing.T) {
+	ctx := context.Background()
+
+	store := &mockKLineStore{kLines: map[types.Interval][]types.KLine{
+		types.Interval1m: buildMinuteKLines(0, 15),
+	}}
+
+	compactor := &KLineCompactor{Store: store}
+	kLines, err := compactor.Compact(ctx, types.ExchangeBinance, "BTCUSDT", types.Interval5m)
+	if !assert.NoError(t, err) || !assert.Len(t, kLines, 3) {
+		return
+	}
+
+	remote := make([]types.KLine, 2)
+	copy(remote, kLines[:2])
+	remote[0].Close += remote[0].Close * 0.00001
+	remote[1].Volume = 6.0
+
+	exchange := &mockKLineExchange{kLines: remote}
+	mismatches, err := compactor.Verify(ctx, exchange, kLines, 0.0001)
+	assert.NoError(t, err)
+	if assert.Len(t, mismatches, 2) {
+		assert.Equal(t, "volume", mismatches[0].Field)
+		assert.Equal(t, 5.0, mismatches[0].Stored)
+		assert.Equal(t, 6.0, mismatches[0].Exchange)
+
+		assert.Equal(t, "missing", mismatches[1].Field)
+		assert.Equal(t, kLines[2].StartTime, mismatches[1].StartTime)
+	}
+}
@@ -61,6 +61,8 @@ func (s *OrderService) Sync(ctx context.Context, exchange types.Exchange, symbol
 
 	b := &batch.ClosedOrderBatchQuery{Exchange: exchange}
 	ordersC, errC := b.Query(ctx, symbol, startTime, time.Now(), lastID)
+
+	var orders []types.Order
 	for order := range ordersC {
 		select {
 
@@ -80,9 +82,23 @@ func (s *OrderService) Sync(ctx context.Context, exchange types.Exchange, symbol
 			continue
 		}
 
-		if err := s.Insert(order); err != nil {
+		// postgres can not update the same row twice in one statement
+		orderKeys[order.OrderID] = struct{}{}
+
+		orders = append(orders, order)
+		if len(orders) < syncBatchSize {
+			continue
+		}
+
+		if err := s.BatchInsert(orders); err != nil {
 			return err
 		}
+
+		orders = nil
+	}
+
+	if err := s.BatchInsert(orders); err != nil {
+		return err
 	}
 
 	return <-errC
@@ -119,18 +135,37 @@ type AggOrder struct {
 type QueryOrdersOptions struct {
 	Exchange types.ExchangeName
 	Symbol   string
+	Side     types.SideType
 	LastGID  int64
 	Ordering string
+
+	// Since and Until filter the orders by the creation time, Until is exclusive
+	Since *time.Time
+	Until *time.Time
+
+	// Limit is the max number of the orders, default to 500
+	Limit int
 }
 
 func (s *OrderService) Query(options QueryOrdersOptions) ([]AggOrder, error) {
 	sql := genOrderSQL(options)
 
-	rows, err := s.DB.NamedQuery(sql, map[string]interface{}{
+	args := map[string]interface{}{
 		"exchange": options.Exchange,
 		"symbol":   options.Symbol,
+		"side":     options.Side,
 		"gid":      options.LastGID,
-	})
+	}
+
+	if options.Since != nil {
+		args["since"] = *options.Since
+	}
+
+	if options.Until != nil {
+		args["until"] = *options.Until
+	}
+
+	rows, err := s.DB.NamedQuery(sql, args)
 	if err != nil {
 		return nil, err
 	}
@@ -145,35 +180,50 @@ func genOrderSQL(options QueryOrdersOptions) string {
 	ordering := "ASC"
 	switch v := strings.ToUpper(options.Ordering); v {
 	case "DESC", "ASC":
-		ordering = options.Ordering
+		ordering = v
+	}
+
+	limit := options.Limit
+	if limit <= 0 {
+		limit = 500
 	}
 
+	// the columns are qualified since the trades table has the same columns
 	var where []string
 	if options.LastGID > 0 {
 		switch ordering {
 		case "ASC":
-			where = append(where, "gid > :gid")
+			where = append(where, "orders.gid > :gid")
 		case "DESC":
-			where = append(where, "gid < :gid")
+			where = append(where, "orders.gid < :gid")
 
 		}
 	}
 
 	if len(options.Exchange) > 0 {
-		where = append(where, "exchange = :exchange")
+		where = append(where, "orders.exchange = :exchange")
 	}
 	if len(options.Symbol) > 0 {
-		where = append(where, "symbol = :symbol")
+		where = append(where, "orders.symbol = :symbol")
+	}
+	if len(options.Side) > 0 {
+		where = append(where, "orders.side = :side")
+	}
+	if options.Since != nil {
+		where = append(where, "orders.created_at >= :since")
+	}
+	if options.Until != nil {
+		where = append(where, "orders.created_at < :until")
 	}
 
-	sql := `SELECT orders.*, IFNULL(SUM(t.price * t.quantity)/SUM(t.quantity), orders.price) AS average_price FROM orders` +
+	sql := `SELECT orders.*, COALESCE(SUM(t.price * t.quantity)/SUM(t.quantity), orders.price) AS average_price FROM orders` +
 		` LEFT JOIN trades AS t ON (t.order_id = orders.order_id)`
 	if len(where) > 0 {
 		sql += ` WHERE ` + strings.Join(where, " AND ")
 	}
 	sql += ` GROUP BY orders.gid `
 	sql += ` ORDER BY orders.gid ` + ordering
-	sql += ` LIMIT ` + strconv.Itoa(500)
+	sql += ` LIMIT ` + strconv.Itoa(limit)
 
 	log.Info(sql)
 	return sql
@@ -205,19 +255,23 @@ func (s *OrderService) scanRows(rows *sqlx.Rows) (orders []types.Order, err erro
 	return orders, rows.Err()
 }
 
-func (s *OrderService) Insert(order types.Order) (err error) {
-	if s.DB.DriverName() == "mysql" {
-		_, err = s.DB.NamedExec(`
-			INSERT INTO orders (exchange, order_id, client_order_id, order_type, status, symbol, price, stop_price, quantity, executed_quantity, side, is_working, time_in_force, created_at, updated_at, is_margin, is_futures, is_isolated)
-			VALUES (:exchange, :order_id, :client_order_id, :order_type, :status, :symbol, :price, :stop_price, :quantity, :executed_quantity, :side, :is_working, :time_in_force, :created_at, :updated_at, :is_margin, :is_futures, :is_isolated)
-			ON DUPLICATE KEY UPDATE status=:status, executed_quantity=:executed_quantity, is_working=:is_working, updated_at=:updated_at`, order)
-		return err
+func (s *OrderService) Insert(order types.Order) error {
+	return s.BatchInsert([]types.Order{order})
+}
+
+// BatchInsert inserts the orders in one statement, the existing orders of the same order id and exchange are updated
+// with the latest status, so the sync can be re-run safely.
+func (s *OrderService) BatchInsert(orders []types.Order) error {
+	if len(orders) == 0 {
+		return nil
 	}
 
-	_, err = s.DB.NamedExec(`
-			INSERT INTO orders (exchange, order_id, client_order_id, order_type, status, symbol, price, stop_price, quantity, executed_quantity, side, is_working, time_in_force, created_at, updated_at, is_margin, is_futures, is_isolated)
-			VALUES (:exchange, :order_id, :client_order_id, :order_type, :status, :symbol, :price, :stop_price, :quantity, :executed_quantity, :side, :is_working, :time_in_force, :created_at, :updated_at, :is_margin, :is_futures, :is_isolated)
-	`, order)
+	sql := `INSERT INTO orders (exchange, order_id, client_order_id, order_type, status, symbol, price, stop_price, quantity, executed_quantity, side, is_working, time_in_force, created_at, updated_at, is_margin, is_futures, is_isolated)
+			VALUES (:exchange, :order_id, :client_order_id, :order_type, :status, :symbol, :price, :stop_price, :quantity, :executed_quantity, :side, :is_working, :time_in_force, :created_at, :updated_at, :is_margin, :is_futures, :is_isolated)` +
+		upsertClause(s.DB.DriverName(),
+			[]string{"order_id", "exchange"},
+			[]string{"status", "executed_quantity", "is_working", "updated_at"})
 
+	_, err := s.DB.NamedExec(sql, orders)
 	return err
 }
@@ -2,23 +2,83 @@ package service
 
 import (
 	"testing"
+	"time"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
 )
 
 func Test_genOrderSQL(t *testing.T) {
 	t.Run("accept empty options", func(t *testing.T) {
 		o := QueryOrdersOptions{}
-		assert.Equal(t, "SELECT orders.*, IFNULL(SUM(t.price * t.quantity)/SUM(t.quantity), orders.price) AS average_price FROM orders LEFT JOIN trades AS t ON (t.order_id = orders.order_id) GROUP BY orders.gid  ORDER BY orders.gid ASC LIMIT 500", genOrderSQL(o))
+		assert.Equal(t, "SELECT orders.*, COALESCE(SUM(t.price * t.quantity)/SUM(t.quantity), orders.price) AS average_price FROM orders LEFT JOIN trades AS t ON (t.order_id = orders.order_id) GROUP BY orders.gid  ORDER BY orders.gid ASC LIMIT 500", genOrderSQL(o))
 	})
 
 	t.Run("different ordering ", func(t *testing.T) {
 		o := QueryOrdersOptions{}
-		assert.Equal(t, "SELECT orders.*, IFNULL(SUM(t.price * t.quantity)/SUM(t.quantity), orders.price) AS average_price FROM orders LEFT JOIN trades AS t ON (t.order_id = orders.order_id) GROUP BY orders.gid  ORDER BY orders.gid ASC LIMIT 500", genOrderSQL(o))
+		assert.Equal(t, "SELECT orders.*, COALESCE(SUM(t.price * t.quantity)/SUM(t.quantity), orders.price) AS average_price FROM orders LEFT JOIN trades AS t ON (t.order_id = orders.order_id) GROUP BY orders.gid  ORDER BY orders.gid ASC LIMIT 500", genOrderSQL(o))
 		o.Ordering = "ASC"
-		assert.Equal(t, "SELECT orders.*, IFNULL(SUM(t.price * t.quantity)/SUM(t.quantity), orders.price) AS average_price FROM orders LEFT JOIN trades AS t ON (t.order_id = orders.order_id) GROUP BY orders.gid  ORDER BY orders.gid ASC LIMIT 500", genOrderSQL(o))
+		assert.Equal(t, "SELECT orders.*, COALESCE(SUM(t.price * t.quantity)/SUM(t.quantity), orders.price) AS average_price FROM orders LEFT JOIN trades AS t ON (t.order_id = orders.order_id) GROUP BY orders.gid  ORDER BY orders.gid ASC LIMIT 500", genOrderSQL(o))
 		o.Ordering = "DESC"
-		assert.Equal(t, "SELECT orders.*, IFNULL(SUM(t.price * t.quantity)/SUM(t.quantity), orders.price) AS average_price FROM orders LEFT JOIN trades AS t ON (t.order_id = orders.order_id) GROUP BY orders.gid  ORDER BY orders.gid DESC LIMIT 500", genOrderSQL(o))
+		assert.Equal(t, "SELECT orders.*, COALESCE(SUM(t.price * t.quantity)/SUM(t.quantity), orders.price) AS average_price FROM orders LEFT JOIN trades AS t ON (t.order_id = orders.order_id) GROUP BY orders.gid  ORDER BY orders.gid DESC LIMIT 500", genOrderSQL(o))
+	})
+
+	t.Run("qualify the filters", func(t *testing.T) {
+		since := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		o := QueryOrdersOptions{
+			Exchange: "max",
+			Symbol:   "BTCUSDT",
+			Side:     types.SideTypeSell,
+			LastGID:  10,
+			Ordering: "desc",
+			Since:    &since,
+			Limit:    100,
+		}
+		assert.Equal(t, "SELECT orders.*, COALESCE(SUM(t.price * t.quantity)/SUM(t.quantity), orders.price) AS average_price FROM orders LEFT JOIN trades AS t ON (t.order_id = orders.order_id) WHERE orders.gid < :gid AND orders.exchange = :exchange AND orders.symbol = :symbol AND orders.side = :side AND orders.created_at >= :since GROUP BY orders.gid  ORDER BY orders.gid DESC LIMIT 100", genOrderSQL(o))
 	})
+}
+
+func TestOrderService_BatchInsert(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	xdb := sqlx.NewDb(db.DB, "sqlite3")
+	service := &OrderService{DB: xdb}
+
+	var orders []types.Order
+	for i := 0; i < 3; i++ {
+		orders = append(orders, types.Order{
+			SubmitOrder: types.SubmitOrder{
+				Symbol:      "BTCUSDT",
+				Side:        types.SideTypeBuy,
+				Type:        types.OrderTypeLimit,
+				Quantity:    0.1,
+				Price:       1000.0,
+				TimeInForce: "GTC",
+			},
+			Exchange: "binance",
+			OrderID:  uint64(i + 1),
+			Status:   types.OrderStatusNew,
+		})
+	}
+
+	assert.NoError(t, service.BatchInsert(orders))
+
+	// re-run the sync with the filled order
+	orders[2].Status = types.OrderStatusFilled
+	orders[2].ExecutedQuantity = 0.1
+	assert.NoError(t, service.BatchInsert(orders))
 
+	records, err := service.QueryLast("binance", "BTCUSDT", false, false, false, 10)
+	if assert.NoError(t, err) && assert.Len(t, records, 3) {
+		assert.Equal(t, uint64(3), records[0].OrderID)
+		assert.Equal(t, types.OrderStatusFilled, records[0].Status)
+		assert.Equal(t, 0.1, records[0].ExecutedQuantity)
+	}
 }
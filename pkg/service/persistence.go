@@ -1,5 +1,7 @@
 package service
 
+import "time"
+
 type PersistenceService interface {
 	NewStore(id string, subIDs ...string) Store
 }
@@ -15,6 +17,13 @@ type RedisPersistenceConfig struct {
 	Port     string `yaml:"port" json:"port" env:"REDIS_PORT"`
 	Password string `yaml:"password,omitempty" json:"password,omitempty" env:"REDIS_PASSWORD"`
 	DB       int    `yaml:"db" json:"db" env:"REDIS_DB"`
+
+	// Namespace is prepended to the keys, the bbgo instances with the same namespace share the persisted state,
+	// so that a standby instance on another host can take over the state of the failed one.
+	Namespace string `yaml:"namespace,omitempty" json:"namespace,omitempty" env:"REDIS_NAMESPACE"`
+
+	// TTL is the expiration of the saved values, the values never expire if it's zero.
+	TTL time.Duration `yaml:"ttl,omitempty" json:"ttl,omitempty" env:"REDIS_TTL"`
 }
 
 type JsonPersistenceConfig struct {
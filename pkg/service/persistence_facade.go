@@ -1,21 +1,89 @@
 package service
 
+import "sync"
+
 type PersistenceServiceFacade struct {
 	Redis  *RedisPersistenceService
 	Json   *JsonPersistenceService
+	SQL    *SQLPersistenceService
 	Memory *MemoryService
+
+	// ReadOnly makes the facade return the read-only persistence services,
+	// the persisted values are loaded but the saved values are kept in the memory.
+	ReadOnly bool
+
+	readOnlyServices map[string]*ReadOnlyPersistenceService
+	mu               sync.Mutex
 }
 
 // Get returns the preferred persistence service by fallbacks
-// Redis will be preferred at the first position.
+// Redis will be preferred at the first position, and then json, sql and memory.
 func (facade *PersistenceServiceFacade) Get() PersistenceService {
 	if facade.Redis != nil {
+		if facade.ReadOnly {
+			return facade.ReadOnlyService("redis")
+		}
 		return facade.Redis
 	}
 
 	if facade.Json != nil {
+		if facade.ReadOnly {
+			return facade.ReadOnlyService("json")
+		}
 		return facade.Json
 	}
 
+	if facade.SQL != nil {
+		if facade.ReadOnly {
+			return facade.ReadOnlyService("sql")
+		}
+		return facade.SQL
+	}
+
+	if facade.ReadOnly {
+		return facade.ReadOnlyService("memory")
+	}
 	return facade.Memory
 }
+
+// ReadOnlyService returns the read-only wrapper of the persistence service of the given type,
+// the wrappers are cached so that the stores of the same type share the values saved in the memory.
+func (facade *PersistenceServiceFacade) ReadOnlyService(t string) PersistenceService {
+	facade.mu.Lock()
+	defer facade.mu.Unlock()
+
+	if s, ok := facade.readOnlyServices[t]; ok {
+		return s
+	}
+
+	var source PersistenceService
+	switch t {
+	case "redis":
+		if facade.Redis != nil {
+			source = facade.Redis
+		}
+
+	case "json":
+		if facade.Json != nil {
+			source = facade.Json
+		}
+
+	case "sql":
+		if facade.SQL != nil {
+			source = facade.SQL
+		}
+
+	case "memory":
+		if facade.Memory != nil {
+			source = facade.Memory
+		}
+	}
+
+	if facade.readOnlyServices == nil {
+		facade.readOnlyServices = make(map[string]*ReadOnlyPersistenceService)
+	}
+
+	s := NewReadOnlyPersistenceService(source)
+	facade.readOnlyServices[t] = s
+	return s
+}
@@ -0,0 +1,54 @@
+package service
+
+// ReadOnlyPersistenceService loads the persisted values from the source persistence service,
+// the saved values are kept in the memory only, so that the replay does not overwrite the persisted state.
+type ReadOnlyPersistenceService struct {
+	Source PersistenceService
+	Memory *MemoryService
+}
+
+func NewReadOnlyPersistenceService(source PersistenceService) *ReadOnlyPersistenceService {
+	return &ReadOnlyPersistenceService{
+		Source: source,
+		Memory: NewMemoryService(),
+	}
+}
+
+func (s *ReadOnlyPersistenceService) NewStore(id string, subIDs ...string) Store {
+	store := &ReadOnlyStore{
+		memory: s.Memory.NewStore(id, subIDs...),
+	}
+
+	if s.Source != nil {
+		store.source = s.Source.NewStore(id, subIDs...)
+	}
+
+	return store
+}
+
+type ReadOnlyStore struct {
+	source Store
+	memory Store
+}
+
+// Load loads the value saved in the memory first, and then falls back to the source store
+func (store *ReadOnlyStore) Load(val interface{}) error {
+	err := store.memory.Load(val)
+	if err != ErrPersistenceNotExists {
+		return err
+	}
+
+	if store.source == nil {
+		return ErrPersistenceNotExists
+	}
+
+	return store.source.Load(val)
+}
+
+func (store *ReadOnlyStore) Save(val interface{}) error {
+	return store.memory.Save(val)
+}
+
+func (store *ReadOnlyStore) Reset() error {
+	return store.memory.Reset()
+}
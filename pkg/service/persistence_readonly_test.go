@@ -0,0 +1,80 @@
+package service
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadOnlyPersistenceService(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bbgo-persistence")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	source := &JsonPersistenceService{Directory: dir}
+
+	i := 3
+	assert.NoError(t, source.NewStore("state", "grid").Save(&i))
+
+	service := NewReadOnlyPersistenceService(source)
+	store := service.NewStore("state", "grid")
+
+	var j int
+	assert.NoError(t, store.Load(&j))
+	assert.Equal(t, 3, j)
+
+	// the saved value is kept in the memory
+	k := 5
+	assert.NoError(t, store.Save(&k))
+	assert.NoError(t, store.Load(&j))
+	assert.Equal(t, 5, j)
+
+	// the source store is not changed
+	assert.NoError(t, source.NewStore("state", "grid").Load(&j))
+	assert.Equal(t, 3, j)
+
+	// reset falls back to the source value
+	assert.NoError(t, store.Reset())
+	assert.NoError(t, store.Load(&j))
+	assert.Equal(t, 3, j)
+
+	err = service.NewStore("state", "other").Load(&j)
+	assert.Equal(t, ErrPersistenceNotExists, err)
+
+	err = NewReadOnlyPersistenceService(nil).NewStore("state").Load(&j)
+	assert.Equal(t, ErrPersistenceNotExists, err)
+}
+
+func TestPersistenceServiceFacade_ReadOnly(t *testing.T) {
+	facade := &PersistenceServiceFacade{
+		Memory:   NewMemoryService(),
+		ReadOnly: true,
+	}
+
+	i := 3
+	assert.NoError(t, facade.Memory.NewStore("state").Save(&i))
+
+	store := facade.Get().NewStore("state")
+
+	var j int
+	assert.NoError(t, store.Load(&j))
+	assert.Equal(t, 3, j)
+
+	k := 5
+	assert.NoError(t, store.Save(&k))
+
+	// the read-only service is shared by the stores of the same type
+	assert.NoError(t, facade.ReadOnlyService("memory").NewStore("state").Load(&j))
+	assert.Equal(t, 5, j)
+
+	assert.NoError(t, facade.Memory.NewStore("state").Load(&j))
+	assert.Equal(t, 3, j)
+
+	// redis is not configured
+	err := facade.ReadOnlyService("redis").NewStore("state").Load(&j)
+	assert.Equal(t, ErrPersistenceNotExists, err)
+}
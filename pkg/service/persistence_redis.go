@@ -5,12 +5,16 @@ import (
 	"encoding/json"
 	"net"
 	"strings"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 )
 
 type RedisPersistenceService struct {
 	redis *redis.Client
+
+	namespace string
+	ttl       time.Duration
 }
 
 func NewRedisPersistenceService(config *RedisPersistenceConfig) *RedisPersistenceService {
@@ -22,25 +26,40 @@ func NewRedisPersistenceService(config *RedisPersistenceConfig) *RedisPersistenc
 	})
 
 	return &RedisPersistenceService{
-		redis: client,
+		redis:     client,
+		namespace: config.Namespace,
+		ttl:       config.TTL,
 	}
 }
 
 func (s *RedisPersistenceService) NewStore(id string, subIDs ...string) Store {
+	return &RedisStore{
+		redis: s.redis,
+		ID:    redisStoreKey(s.namespace, id, subIDs...),
+		TTL:   s.ttl,
+	}
+}
+
+// redisStoreKey joins the namespace, the store id and the sub ids with colons, e.g., bbgo-prod:state:grid:BTCUSDT
+func redisStoreKey(namespace, id string, subIDs ...string) string {
 	if len(subIDs) > 0 {
 		id += ":" + strings.Join(subIDs, ":")
 	}
 
-	return &RedisStore{
-		redis: s.redis,
-		ID:    id,
+	if len(namespace) > 0 {
+		id = namespace + ":" + id
 	}
+
+	return id
 }
 
 type RedisStore struct {
 	redis *redis.Client
 
 	ID string
+
+	// TTL is the expiration of the saved value, zero means no expiration
+	TTL time.Duration
 }
 
 func (store *RedisStore) Load(val interface{}) error {
@@ -67,7 +86,7 @@ func (store *RedisStore) Save(val interface{}) error {
 		return err
 	}
 
-	cmd := store.redis.Set(context.Background(), store.ID, data, 0)
+	cmd := store.redis.Set(context.Background(), store.ID, data, store.TTL)
 	_, err = cmd.Result()
 	return err
 }
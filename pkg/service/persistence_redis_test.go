@@ -1,7 +1,9 @@
 package service
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -39,3 +41,32 @@ func TestRedisPersistentService(t *testing.T) {
 	err = store.Reset()
 	assert.NoError(t, err)
 }
+
+func TestRedisPersistentService_NamespaceAndTTL(t *testing.T) {
+	redisService := NewRedisPersistenceService(&RedisPersistenceConfig{
+		Host:      "127.0.0.1",
+		Port:      "6379",
+		DB:        0,
+		Namespace: "bbgo-test",
+		TTL:       time.Minute,
+	})
+
+	store := redisService.NewStore("state", "grid", "BTCUSDT")
+	assert.Equal(t, "bbgo-test:state:grid:BTCUSDT", store.(*RedisStore).ID)
+
+	fp := fixedpoint.NewFromFloat(1.5)
+	assert.NoError(t, store.Save(&fp))
+
+	ttl, err := redisService.redis.TTL(context.Background(), "bbgo-test:state:grid:BTCUSDT").Result()
+	if assert.NoError(t, err) {
+		assert.True(t, ttl > 0 && ttl <= time.Minute, "the saved value should expire in a minute")
+	}
+
+	assert.NoError(t, store.Reset())
+}
+
+func Test_redisStoreKey(t *testing.T) {
+	assert.Equal(t, "bbgo", redisStoreKey("", "bbgo"))
+	assert.Equal(t, "bbgo:test", redisStoreKey("", "bbgo", "test"))
+	assert.Equal(t, "prod:state:grid:BTCUSDT", redisStoreKey("prod", "state", "grid", "BTCUSDT"))
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// SQLPersistenceConfig enables the persistence in the `persistence` table, the database configured
+// by the DB_DRIVER and DB_DSN environment variables is used if the driver and the dsn are not set.
+type SQLPersistenceConfig struct {
+	Driver string `yaml:"driver,omitempty" json:"driver,omitempty"`
+	DSN    string `yaml:"dsn,omitempty" json:"dsn,omitempty"`
+}
+
+// SQLPersistenceService stores the json encoded values in the `persistence` table of the database
+type SQLPersistenceService struct {
+	DB *sqlx.DB
+}
+
+func (s *SQLPersistenceService) NewStore(id string, subIDs ...string) Store {
+	if len(subIDs) > 0 {
+		id += ":" + strings.Join(subIDs, ":")
+	}
+
+	return &SQLStore{
+		DB: s.DB,
+		ID: id,
+	}
+}
+
+type SQLStore struct {
+	DB *sqlx.DB
+
+	ID string
+}
+
+func (store *SQLStore) Load(val interface{}) error {
+	var data string
+	if err := store.DB.Get(&data, store.DB.Rebind("SELECT `value` FROM `persistence` WHERE `id` = ?"), store.ID); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrPersistenceNotExists
+		}
+
+		return err
+	}
+
+	if len(data) == 0 {
+		return ErrPersistenceNotExists
+	}
+
+	return json.Unmarshal([]byte(data), val)
+}
+
+func (store *SQLStore) Save(val interface{}) error {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+
+	// REPLACE INTO is supported by both mysql and sqlite3
+	sql := "REPLACE INTO `persistence` (`id`, `value`, `updated_at`) VALUES (?, ?, ?)"
+	if store.DB.DriverName() == "postgres" {
+		sql = "INSERT INTO `persistence` (`id`, `value`, `updated_at`) VALUES (?, ?, ?)" +
+			" ON CONFLICT (`id`) DO UPDATE SET `value` = EXCLUDED.`value`, `updated_at` = EXCLUDED.`updated_at`"
+	}
+
+	_, err = store.DB.Exec(store.DB.Rebind(sql), store.ID, string(data), time.Now())
+	return err
+}
+
+func (store *SQLStore) Reset() error {
+	_, err := store.DB.Exec(store.DB.Rebind("DELETE FROM `persistence` WHERE `id` = ?"), store.ID)
+	return err
+}
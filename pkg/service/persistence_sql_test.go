@@ -0,0 +1,42 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLPersistenceService(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	service := &SQLPersistenceService{DB: sqlx.NewDb(db.DB, "sqlite3")}
+	store := service.NewStore("state", "grid", "BTCUSDT")
+
+	var i int
+	assert.Equal(t, ErrPersistenceNotExists, store.Load(&i))
+
+	i = 3
+	assert.NoError(t, store.Save(&i))
+
+	var j int
+	assert.NoError(t, store.Load(&j))
+	assert.Equal(t, 3, j)
+
+	// the value is replaced
+	i = 5
+	assert.NoError(t, store.Save(&i))
+	assert.NoError(t, store.Load(&j))
+	assert.Equal(t, 5, j)
+
+	// the stores of the other ids are not affected
+	assert.Equal(t, ErrPersistenceNotExists, service.NewStore("state", "grid", "ETHUSDT").Load(&j))
+
+	assert.NoError(t, store.Reset())
+	assert.Equal(t, ErrPersistenceNotExists, store.Load(&j))
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// postgresDriverName is the sql driver registered for the postgres database. The queries of the services quote the
+// identifiers with the backquotes for mysql and sqlite3, the driver rewrites them into the double quotes before
+// the queries are sent to postgres, so that the services share the same queries across the databases.
+const postgresDriverName = "bbgo-postgres"
+
+func init() {
+	sql.Register(postgresDriverName, &postgresDriver{})
+}
+
+// postgresQuery rewrites the backquoted identifiers of the query into the double quoted identifiers,
+// the backquotes inside the string literals and the quoted identifiers are kept.
+func postgresQuery(query string) string {
+	if !strings.Contains(query, "`") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query))
+
+	var quote rune
+	for _, c := range query {
+		switch {
+		case quote != 0:
+			// the escaped quote ('') closes and reopens the literal, it's kept as it is
+			if c == quote {
+				quote = 0
+			}
+
+		case c == '\'' || c == '"':
+			quote = c
+
+		case c == '`':
+			c = '"'
+		}
+
+		b.WriteRune(c)
+	}
+
+	return b.String()
+}
+
+type postgresDriver struct{}
+
+func (d *postgresDriver) Open(name string) (driver.Conn, error) {
+	conn, err := pq.Driver{}.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &postgresConn{Conn: conn}, nil
+}
+
+// postgresConn rewrites the queries of the lib/pq connection
+type postgresConn struct {
+	driver.Conn
+}
+
+func (c *postgresConn) Prepare(query string) (driver.Stmt, error) {
+	return c.Conn.Prepare(postgresQuery(query))
+}
+
+func (c *postgresConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, postgresQuery(query))
+	}
+
+	return c.Prepare(query)
+}
+
+func (c *postgresConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+
+	return c.Conn.Begin()
+}
+
+func (c *postgresConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if e, ok := c.Conn.(driver.ExecerContext); ok {
+		return e.ExecContext(ctx, postgresQuery(query), args)
+	}
+
+	// database/sql prepares the statement if the connection doesn't execute the query directly
+	return nil, driver.ErrSkip
+}
+
+func (c *postgresConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if q, ok := c.Conn.(driver.QueryerContext); ok {
+		return q.QueryContext(ctx, postgresQuery(query), args)
+	}
+
+	return nil, driver.ErrSkip
+}
+
+func (c *postgresConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+
+	return nil
+}
+
+func (c *postgresConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+
+	// use the default conversion of database/sql
+	return driver.ErrSkip
+}
+
+// insertGID executes the named insert query and returns the gid of the inserted row. The lib/pq driver doesn't support
+// LastInsertId, the gid is returned by the RETURNING clause on postgres.
+func insertGID(db *sqlx.DB, query string, arg interface{}) (int64, error) {
+	if db.DriverName() != "postgres" {
+		result, err := db.NamedExec(query, arg)
+		if err != nil {
+			return 0, err
+		}
+
+		return result.LastInsertId()
+	}
+
+	rows, err := db.NamedQuery(query+" RETURNING `gid`", arg)
+	if err != nil {
+		return 0, err
+	}
+
+	defer rows.Close()
+
+	var gid int64
+	if rows.Next() {
+		if err := rows.Scan(&gid); err != nil {
+			return 0, err
+		}
+	}
+
+	return gid, rows.Err()
+}
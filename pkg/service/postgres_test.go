@@ -0,0 +1,17 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_postgresQuery(t *testing.T) {
+	assert.Equal(t, "SELECT * FROM trades WHERE id = $1", postgresQuery("SELECT * FROM trades WHERE id = $1"))
+	assert.Equal(t, `SELECT "value" FROM "persistence" WHERE "id" = $1`, postgresQuery("SELECT `value` FROM `persistence` WHERE `id` = $1"))
+
+	// the backquotes of the string literals and the quoted identifiers are kept
+	assert.Equal(t, `UPDATE "commands" SET "error" = 'it''s `+"`quoted`"+`' WHERE "gid" = 1`,
+		postgresQuery("UPDATE `commands` SET `error` = 'it''s `quoted`' WHERE `gid` = 1"))
+	assert.Equal(t, `SELECT "a`+"`"+`b" FROM "t"`, postgresQuery("SELECT \"a`b\" FROM `t`"))
+}
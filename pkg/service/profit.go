@@ -0,0 +1,137 @@
+package service
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// ProfitService stores the realized profits of the strategies
+type ProfitService struct {
+	DB *sqlx.DB
+}
+
+type QueryProfitsOptions struct {
+	Strategy string
+	Symbol   string
+	Since    *time.Time
+	Until    *time.Time
+}
+
+// Insert inserts the profit and sets the GID of the profit
+func (s *ProfitService) Insert(profit *types.StrategyProfit) error {
+	gid, err := insertGID(s.DB, "INSERT INTO `profits` (`strategy`, `strategy_instance_id`, `symbol`, `base_currency`, `quote_currency`, `profit`, `net_profit`, `trade_amount`, `time`)"+
+		" VALUES (:strategy, :strategy_instance_id, :symbol, :base_currency, :quote_currency, :profit, :net_profit, :trade_amount, :time)", profit)
+	if err != nil {
+		return err
+	}
+
+	profit.GID = gid
+	return nil
+}
+
+// Query queries the profits in the ascending order of the time
+func (s *ProfitService) Query(options QueryProfitsOptions) ([]types.StrategyProfit, error) {
+	var where []string
+	var args = map[string]interface{}{}
+
+	if len(options.Strategy) > 0 {
+		where = append(where, "`strategy` = :strategy")
+		args["strategy"] = options.Strategy
+	}
+
+	if len(options.Symbol) > 0 {
+		where = append(where, "`symbol` = :symbol")
+		args["symbol"] = options.Symbol
+	}
+
+	if options.Since != nil {
+		where = append(where, "`time` >= :since")
+		args["since"] = *options.Since
+	}
+
+	if options.Until != nil {
+		where = append(where, "`time` < :until")
+		args["until"] = *options.Until
+	}
+
+	sql := "SELECT * FROM `profits`"
+	if len(where) > 0 {
+		sql += " WHERE " + strings.Join(where, " AND ")
+	}
+	sql += " ORDER BY `time` ASC, `gid` ASC"
+
+	rows, err := s.DB.NamedQuery(sql, args)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var profits []types.StrategyProfit
+	for rows.Next() {
+		var profit types.StrategyProfit
+		if err := rows.StructScan(&profit); err != nil {
+			return profits, err
+		}
+
+		profits = append(profits, profit)
+	}
+
+	return profits, rows.Err()
+}
+
+// QuerySummaries aggregates the profits by the strategy, the quote currency and the period,
+// the summaries are sorted by the period start time and then the strategy.
+// The profits are aggregated in the local time zone, so the aggregation does not depend on the date functions of the database.
+func (s *ProfitService) QuerySummaries(options QueryProfitsOptions, period types.ProfitPeriod) ([]types.ProfitSummary, error) {
+	if err := period.Validate(); err != nil {
+		return nil, err
+	}
+
+	profits, err := s.Query(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return SummarizeProfits(profits, period), nil
+}
+
+// SummarizeProfits aggregates the profits by the strategy, the quote currency and the period
+func SummarizeProfits(profits []types.StrategyProfit, period types.ProfitPeriod) []types.ProfitSummary {
+	var summaries = make(map[string]*types.ProfitSummary)
+	for _, profit := range profits {
+		t := profit.Time.Time().Local()
+		key := profit.Strategy + ":" + profit.QuoteCurrency + ":" + period.Start(t).Format(time.RFC3339)
+		summary, ok := summaries[key]
+		if !ok {
+			summary = types.NewProfitSummary(profit.Strategy, profit.QuoteCurrency, period, t)
+			summaries[key] = summary
+		}
+
+		summary.Add(profit)
+	}
+
+	var results []types.ProfitSummary
+	for _, summary := range summaries {
+		results = append(results, *summary)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if !results[i].Since.Equal(results[j].Since) {
+			return results[i].Since.Before(results[j].Since)
+		}
+
+		if results[i].Strategy != results[j].Strategy {
+			return results[i].Strategy < results[j].Strategy
+		}
+
+		return results[i].QuoteCurrency < results[j].QuoteCurrency
+	})
+
+	return results
+}
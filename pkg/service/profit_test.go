@@ -0,0 +1,60 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestProfitService(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	service := &ProfitService{DB: sqlx.NewDb(db.DB, "sqlite3")}
+
+	day := time.Date(2021, 12, 15, 10, 0, 0, 0, time.Local)
+	profits := []types.StrategyProfit{
+		{Strategy: "grid", Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT", Profit: 10.0, NetProfit: 9.0, TradeAmount: 100.0, Time: types.Time(day)},
+		{Strategy: "grid", Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT", Profit: -5.0, NetProfit: -6.0, TradeAmount: 100.0, Time: types.Time(day.Add(time.Hour))},
+		{Strategy: "grid", Symbol: "ETHUSDT", BaseCurrency: "ETH", QuoteCurrency: "USDT", Profit: 2.0, NetProfit: 1.5, TradeAmount: 50.0, Time: types.Time(day.AddDate(0, 0, 1))},
+		{Strategy: "bollpp", Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT", Profit: 1.0, NetProfit: 1.0, TradeAmount: 10.0, Time: types.Time(day)},
+	}
+
+	for i := range profits {
+		assert.NoError(t, service.Insert(&profits[i]))
+		assert.NotZero(t, profits[i].GID)
+	}
+
+	records, err := service.Query(QueryProfitsOptions{Strategy: "grid", Symbol: "BTCUSDT"})
+	assert.NoError(t, err)
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, 10.0, records[0].Profit)
+		assert.Equal(t, -6.0, records[1].NetProfit)
+	}
+
+	summaries, err := service.QuerySummaries(QueryProfitsOptions{Strategy: "grid"}, types.ProfitPeriodDay)
+	assert.NoError(t, err)
+	if assert.Len(t, summaries, 2) {
+		assert.Equal(t, 5.0, summaries[0].Profit)
+		assert.Equal(t, 2, summaries[0].NumProfits)
+		assert.Equal(t, 2.0, summaries[1].Profit)
+	}
+
+	summaries, err = service.QuerySummaries(QueryProfitsOptions{Strategy: "grid"}, types.ProfitPeriodMonth)
+	assert.NoError(t, err)
+	if assert.Len(t, summaries, 1) {
+		assert.Equal(t, 7.0, summaries[0].Profit)
+		assert.Equal(t, 3, summaries[0].NumProfits)
+	}
+
+	_, err = service.QuerySummaries(QueryProfitsOptions{}, types.ProfitPeriod("year"))
+	assert.Error(t, err)
+}
@@ -37,6 +37,23 @@ func (s *RewardService) QueryLast(ex types.ExchangeName, limit int) ([]types.Rew
 	return s.scanRows(rows)
 }
 
+// QueryRange queries the rewards created in the time range [since, until) in the ascending order of the creation time,
+// the spent rewards and the airdrops are included.
+func (s *RewardService) QueryRange(ctx context.Context, ex types.ExchangeName, since, until time.Time) ([]types.Reward, error) {
+	sql := "SELECT * FROM `rewards` WHERE `exchange` = :exchange AND `created_at` >= :since AND `created_at` < :until ORDER BY `created_at` ASC"
+	rows, err := s.DB.NamedQueryContext(ctx, sql, map[string]interface{}{
+		"exchange": ex,
+		"since":    since,
+		"until":    until,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	return s.scanRows(rows)
+}
+
 func (s *RewardService) Sync(ctx context.Context, exchange types.Exchange) error {
 	service, ok := exchange.(types.ExchangeRewardService)
 	if !ok {
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"strconv"
 	"testing"
 	"time"
 
@@ -139,3 +140,40 @@ func TestRewardService_AggregateUnspentCurrencyPosition(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, fixedpoint.Value(1), v)
 }
+
+func TestRewardService_QueryRange(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	ctx := context.Background()
+
+	xdb := sqlx.NewDb(db.DB, "sqlite3")
+	service := &RewardService{DB: xdb}
+
+	since := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	until := since.AddDate(1, 0, 0)
+	for i, createdAt := range []time.Time{since.AddDate(0, 0, -1), since, until.Add(-time.Second), until} {
+		err = service.Insert(types.Reward{
+			UUID:      "test0" + strconv.Itoa(i),
+			Exchange:  "max",
+			Type:      "airdrop",
+			Currency:  "MAX",
+			Quantity:  fixedpoint.NewFromFloat(10.0),
+			State:     "done",
+			Spent:     true,
+			CreatedAt: types.Time(createdAt),
+		})
+		assert.NoError(t, err)
+	}
+
+	rewards, err := service.QueryRange(ctx, types.ExchangeMax, since, until)
+	assert.NoError(t, err)
+	if assert.Len(t, rewards, 2) {
+		assert.Equal(t, "test01", rewards[0].UUID)
+		assert.Equal(t, "test02", rewards[1].UUID)
+	}
+}
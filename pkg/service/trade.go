@@ -20,8 +20,13 @@ var ErrTradeNotFound = errors.New("trade not found")
 type QueryTradesOptions struct {
 	Exchange types.ExchangeName
 	Symbol   string
+	Side     types.SideType
 	LastGID  int64
 
+	// Since and Until filter the trades by the trade time, Until is exclusive
+	Since *time.Time
+	Until *time.Time
+
 	// ASC or DESC
 	Ordering string
 	Limit    int
@@ -95,6 +100,7 @@ func (s *TradeService) Sync(ctx context.Context, exchange types.Exchange, symbol
 		LastTradeID: lastTradeID,
 	})
 
+	var trades []types.Trade
 	for trade := range tradeC {
 		select {
 		case <-ctx.Done():
@@ -125,9 +131,20 @@ func (s *TradeService) Sync(ctx context.Context, exchange types.Exchange, symbol
 			trade.Liquidity(),
 			trade.Time.String())
 
-		if err := s.Insert(trade); err != nil {
+		trades = append(trades, trade)
+		if len(trades) < syncBatchSize {
+			continue
+		}
+
+		if err := s.BatchInsert(trades); err != nil {
 			return err
 		}
+
+		trades = nil
+	}
+
+	if err := s.BatchInsert(trades); err != nil {
+		return err
 	}
 
 	return <-errC
@@ -144,9 +161,12 @@ func (s *TradeService) QueryTradingVolume(startTime time.Time, options TradingVo
 
 	sql := ""
 	driverName := s.DB.DriverName()
-	if driverName == "mysql" {
+	switch driverName {
+	case "mysql":
 		sql = generateMysqlTradingVolumeQuerySQL(options)
-	} else {
+	case "postgres":
+		sql = generatePostgresTradingVolumeQuerySQL(options)
+	default:
 		sql = generateSqliteTradingVolumeSQL(options)
 	}
 
@@ -276,6 +296,59 @@ func generateMysqlTradingVolumeQuerySQL(options TradingVolumeQueryOptions) strin
 	return sql
 }
 
+func generatePostgresTimeRangeClauses(timeRangeColumn, period string) (selectors []string, groupBys []string, orderBys []string) {
+	year := "CAST(EXTRACT(YEAR FROM " + timeRangeColumn + ") AS INTEGER)"
+	month := "CAST(EXTRACT(MONTH FROM " + timeRangeColumn + ") AS INTEGER)"
+	day := "CAST(EXTRACT(DAY FROM " + timeRangeColumn + ") AS INTEGER)"
+
+	switch period {
+	case "month":
+		selectors = append(selectors, year+" AS year", month+" AS month")
+		groupBys = append([]string{month, year}, groupBys...)
+		orderBys = append(orderBys, "year ASC", "month ASC")
+
+	case "year":
+		selectors = append(selectors, year+" AS year")
+		groupBys = append([]string{year}, groupBys...)
+		orderBys = append(orderBys, "year ASC")
+
+	case "day":
+		fallthrough
+
+	default:
+		selectors = append(selectors, year+" AS year", month+" AS month", day+" AS day")
+		groupBys = append([]string{day, month, year}, groupBys...)
+		orderBys = append(orderBys, "year ASC", "month ASC", "day ASC")
+	}
+
+	return
+}
+
+func generatePostgresTradingVolumeQuerySQL(options TradingVolumeQueryOptions) string {
+	timeRangeColumn := "traded_at"
+	sel, groupBys, orderBys := generatePostgresTimeRangeClauses(timeRangeColumn, options.GroupByPeriod)
+
+	switch options.SegmentBy {
+	case "symbol":
+		sel = append(sel, "symbol")
+		groupBys = append([]string{"symbol"}, groupBys...)
+		orderBys = append(orderBys, "symbol")
+	case "exchange":
+		sel = append(sel, "exchange")
+		groupBys = append([]string{"exchange"}, groupBys...)
+		orderBys = append(orderBys, "exchange")
+	}
+
+	sel = append(sel, "SUM(quantity * price) AS quote_volume")
+	where := []string{timeRangeColumn + " > :start_time"}
+	sql := `SELECT ` + strings.Join(sel, ", ") + ` FROM trades` +
+		` WHERE ` + strings.Join(where, " AND ") +
+		` GROUP BY ` + strings.Join(groupBys, ", ") +
+		` ORDER BY ` + strings.Join(orderBys, ", ")
+
+	return sql
+}
+
 // QueryLast queries the last trade from the database
 func (s *TradeService) QueryLast(ex types.ExchangeName, symbol string, isMargin, isFutures, isIsolated bool, limit int) ([]types.Trade, error) {
 	log.Debugf("querying last trade exchange = %s AND symbol = %s AND is_margin = %v AND is_futures = %v AND is_isolated = %v", ex, symbol, isMargin, isFutures, isIsolated)
@@ -319,10 +392,9 @@ func (s *TradeService) Query(options QueryTradesOptions) ([]types.Trade, error)
 
 	log.Info(sql)
 
-	args := map[string]interface{}{
-		"exchange": options.Exchange,
-		"symbol":   options.Symbol,
-	}
+	args := queryTradesArgs(options)
+	args["gid"] = options.LastGID
+
 	rows, err := s.DB.NamedQuery(sql, args)
 	if err != nil {
 		return nil, err
@@ -333,6 +405,157 @@ func (s *TradeService) Query(options QueryTradesOptions) ([]types.Trade, error)
 	return s.scanRows(rows)
 }
 
+// TradeSummary is the trading volume of the symbol aggregated from the synced trades
+type TradeSummary struct {
+	Exchange      types.ExchangeName `db:"exchange" json:"exchange"`
+	Symbol        string             `db:"symbol" json:"symbol"`
+	NumTrades     int64              `db:"num_trades" json:"numTrades"`
+	Quantity      float64            `db:"quantity" json:"quantity"`
+	QuoteQuantity float64            `db:"quote_quantity" json:"quoteQuantity"`
+}
+
+// FeeSummary is the fee paid in the fee currency aggregated from the synced trades
+type FeeSummary struct {
+	Exchange    types.ExchangeName `db:"exchange" json:"exchange"`
+	FeeCurrency string             `db:"fee_currency" json:"feeCurrency"`
+	NumTrades   int64              `db:"num_trades" json:"numTrades"`
+	Fee         float64            `db:"fee" json:"fee"`
+}
+
+// QuerySummaries aggregates the trading volume of the trades filtered by the options for each exchange and symbol,
+// the pagination options are ignored.
+func (s *TradeService) QuerySummaries(options QueryTradesOptions) ([]TradeSummary, error) {
+	sql := queryTradeSummariesSQL(options)
+	rows, err := s.DB.NamedQuery(sql, queryTradesArgs(options))
+	if err != nil {
+		return nil, errors.Wrap(err, "query trade summaries error")
+	}
+
+	defer rows.Close()
+
+	var summaries []TradeSummary
+	for rows.Next() {
+		var summary TradeSummary
+		if err := rows.StructScan(&summary); err != nil {
+			return nil, err
+		}
+
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, rows.Err()
+}
+
+// QueryFees aggregates the fees of the trades filtered by the options for each exchange and fee currency,
+// the pagination options are ignored.
+func (s *TradeService) QueryFees(options QueryTradesOptions) ([]FeeSummary, error) {
+	sql := queryFeesSQL(options)
+	rows, err := s.DB.NamedQuery(sql, queryTradesArgs(options))
+	if err != nil {
+		return nil, errors.Wrap(err, "query fees error")
+	}
+
+	defer rows.Close()
+
+	var fees []FeeSummary
+	for rows.Next() {
+		var fee FeeSummary
+		if err := rows.StructScan(&fee); err != nil {
+			return nil, err
+		}
+
+		fees = append(fees, fee)
+	}
+
+	return fees, rows.Err()
+}
+
+func queryTradeSummariesSQL(options QueryTradesOptions) string {
+	sql := `SELECT exchange, symbol, COUNT(*) AS num_trades, SUM(quantity) AS quantity, SUM(quote_quantity) AS quote_quantity FROM trades`
+	if where := queryTradesConditions(options); len(where) > 0 {
+		sql += ` WHERE ` + strings.Join(where, " AND ")
+	}
+
+	sql += ` GROUP BY exchange, symbol ORDER BY exchange, symbol`
+	return sql
+}
+
+func queryFeesSQL(options QueryTradesOptions) string {
+	sql := `SELECT exchange, fee_currency, COUNT(*) AS num_trades, SUM(fee) AS fee FROM trades`
+	if where := queryTradesConditions(options); len(where) > 0 {
+		sql += ` WHERE ` + strings.Join(where, " AND ")
+	}
+
+	sql += ` GROUP BY exchange, fee_currency ORDER BY exchange, fee_currency`
+	return sql
+}
+
+// QueryTradesInRangeOptions filters the trades of the symbol traded between Since and Until
+type QueryTradesInRangeOptions struct {
+	Exchange types.ExchangeName
+	Symbol   string
+	Since    time.Time
+	Until    time.Time
+
+	IsMargin   bool
+	IsFutures  bool
+	IsIsolated bool
+}
+
+// QueryTradesCh streams the trades in the time range ordered by the trade time,
+// so that a large range can be exported without loading all the trades into the memory.
+func (s *TradeService) QueryTradesCh(ctx context.Context, options QueryTradesInRangeOptions) (chan types.Trade, chan error) {
+	sql := "SELECT * FROM trades WHERE exchange = :exchange AND symbol = :symbol" +
+		" AND is_margin = :is_margin AND is_futures = :is_futures AND is_isolated = :is_isolated" +
+		" AND traded_at BETWEEN :since AND :until ORDER BY traded_at ASC, gid ASC"
+
+	rows, err := s.DB.NamedQueryContext(ctx, sql, map[string]interface{}{
+		"exchange":    options.Exchange,
+		"symbol":      options.Symbol,
+		"is_margin":   options.IsMargin,
+		"is_futures":  options.IsFutures,
+		"is_isolated": options.IsIsolated,
+		"since":       options.Since,
+		"until":       options.Until,
+	})
+
+	ch := make(chan types.Trade, 500)
+	errC := make(chan error, 1)
+	if err != nil {
+		close(ch)
+		errC <- err
+		close(errC)
+		return ch, errC
+	}
+
+	go func() {
+		defer close(errC)
+		defer close(ch)
+		defer rows.Close()
+
+		for rows.Next() {
+			var trade types.Trade
+			if err := rows.StructScan(&trade); err != nil {
+				errC <- err
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				errC <- ctx.Err()
+				return
+			case ch <- trade:
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			errC <- err
+		}
+	}()
+
+	return ch, errC
+}
+
 func (s *TradeService) Load(ctx context.Context, id int64) (*types.Trade, error) {
 	var trade types.Trade
 
@@ -403,15 +626,7 @@ func queryTradesSQL(options QueryTradesOptions) string {
 		ordering = v
 	}
 
-	var where []string
-
-	if len(options.Exchange) > 0 {
-		where = append(where, `exchange = :exchange`)
-	}
-
-	if len(options.Symbol) > 0 {
-		where = append(where, `symbol = :symbol`)
-	}
+	where := queryTradesConditions(options)
 
 	if options.LastGID > 0 {
 		switch ordering {
@@ -437,6 +652,51 @@ func queryTradesSQL(options QueryTradesOptions) string {
 	return sql
 }
 
+// queryTradesConditions returns the filter conditions of the options, the pagination is not included
+func queryTradesConditions(options QueryTradesOptions) []string {
+	var where []string
+
+	if len(options.Exchange) > 0 {
+		where = append(where, `exchange = :exchange`)
+	}
+
+	if len(options.Symbol) > 0 {
+		where = append(where, `symbol = :symbol`)
+	}
+
+	if len(options.Side) > 0 {
+		where = append(where, `side = :side`)
+	}
+
+	if options.Since != nil {
+		where = append(where, `traded_at >= :since`)
+	}
+
+	if options.Until != nil {
+		where = append(where, `traded_at < :until`)
+	}
+
+	return where
+}
+
+func queryTradesArgs(options QueryTradesOptions) map[string]interface{} {
+	args := map[string]interface{}{
+		"exchange": options.Exchange,
+		"symbol":   options.Symbol,
+		"side":     options.Side,
+	}
+
+	if options.Since != nil {
+		args["since"] = *options.Since
+	}
+
+	if options.Until != nil {
+		args["until"] = *options.Until
+	}
+
+	return args
+}
+
 func (s *TradeService) scanRows(rows *sqlx.Rows) (trades []types.Trade, err error) {
 	for rows.Next() {
 		var trade types.Trade
@@ -451,10 +711,23 @@ func (s *TradeService) scanRows(rows *sqlx.Rows) (trades []types.Trade, err erro
 }
 
 func (s *TradeService) Insert(trade types.Trade) error {
-	_, err := s.DB.NamedExec(`
-			INSERT INTO trades (id, exchange, order_id, symbol, price, quantity, quote_quantity, side, is_buyer, is_maker, fee, fee_currency, traded_at, is_margin, is_futures, is_isolated)
-			VALUES (:id, :exchange, :order_id, :symbol, :price, :quantity, :quote_quantity, :side, :is_buyer, :is_maker, :fee, :fee_currency, :traded_at, :is_margin, :is_futures, :is_isolated)`,
-		trade)
+	return s.BatchInsert([]types.Trade{trade})
+}
+
+// BatchInsert inserts the trades in one statement, the existing trades of the same exchange, symbol, side and id
+// are updated, so the sync can be re-run safely. The strategy and the pnl marked on the trades are kept.
+func (s *TradeService) BatchInsert(trades []types.Trade) error {
+	if len(trades) == 0 {
+		return nil
+	}
+
+	sql := `INSERT INTO trades (id, exchange, order_id, symbol, price, quantity, quote_quantity, side, is_buyer, is_maker, fee, fee_currency, traded_at, is_margin, is_futures, is_isolated)
+			VALUES (:id, :exchange, :order_id, :symbol, :price, :quantity, :quote_quantity, :side, :is_buyer, :is_maker, :fee, :fee_currency, :traded_at, :is_margin, :is_futures, :is_isolated)` +
+		upsertClause(s.DB.DriverName(),
+			[]string{"exchange", "symbol", "side", "id"},
+			[]string{"order_id", "price", "quantity", "quote_quantity", "is_buyer", "is_maker", "fee", "fee_currency", "traded_at", "is_margin", "is_futures", "is_isolated"})
+
+	_, err := s.DB.NamedExec(sql, trades)
 	return err
 }
 
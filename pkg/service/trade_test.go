@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
@@ -55,6 +56,61 @@ func Test_tradeService(t *testing.T) {
 	assert.Equal(t, 10.0, tradeRecord.PnL.Float64)
 }
 
+func TestTradeService_QueryTradesCh(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	xdb := sqlx.NewDb(db.DB, "sqlite3")
+	service := &TradeService{DB: xdb}
+
+	startTime := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		err = service.Insert(types.Trade{
+			ID:       int64(i + 1),
+			OrderID:  uint64(i + 1),
+			Exchange: "binance",
+			Price:    1000.0 + float64(i),
+			Quantity: 0.1,
+			Symbol:   "BTCUSDT",
+			Side:     "BUY",
+			IsBuyer:  true,
+			Time:     types.Time(startTime.Add(time.Duration(i) * time.Hour)),
+		})
+		assert.NoError(t, err)
+	}
+
+	err = service.Insert(types.Trade{
+		ID:       100,
+		OrderID:  100,
+		Exchange: "binance",
+		Price:    10.0,
+		Quantity: 1.0,
+		Symbol:   "ETHUSDT",
+		Side:     "SELL",
+		Time:     types.Time(startTime.Add(time.Hour)),
+	})
+	assert.NoError(t, err)
+
+	tradeC, errC := service.QueryTradesCh(context.Background(), QueryTradesInRangeOptions{
+		Exchange: "binance",
+		Symbol:   "BTCUSDT",
+		Since:    startTime.Add(time.Hour),
+		Until:    startTime.Add(3 * time.Hour),
+	})
+
+	var ids []int64
+	for trade := range tradeC {
+		ids = append(ids, trade.ID)
+	}
+
+	assert.NoError(t, <-errC)
+	assert.Equal(t, []int64{2, 3, 4}, ids)
+}
+
 func Test_queryTradingVolumeSQL(t *testing.T) {
 	t.Run("group by different period", func(t *testing.T) {
 		o := TradingVolumeQueryOptions{
@@ -72,6 +128,14 @@ func Test_queryTradingVolumeSQL(t *testing.T) {
 		}
 	})
 
+	t.Run("postgres", func(t *testing.T) {
+		o := TradingVolumeQueryOptions{
+			GroupByPeriod: "year",
+			SegmentBy:     "symbol",
+		}
+		assert.Equal(t, "SELECT CAST(EXTRACT(YEAR FROM traded_at) AS INTEGER) AS year, symbol, SUM(quantity * price) AS quote_volume FROM trades WHERE traded_at > :start_time GROUP BY symbol, CAST(EXTRACT(YEAR FROM traded_at) AS INTEGER) ORDER BY year ASC, symbol", generatePostgresTradingVolumeQuerySQL(o))
+	})
+
 }
 
 func Test_queryTradesSQL(t *testing.T) {
@@ -104,4 +168,105 @@ func Test_queryTradesSQL(t *testing.T) {
 			Limit:    500,
 		}))
 	})
+
+	t.Run("filter by side and time range", func(t *testing.T) {
+		since := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		until := since.AddDate(0, 1, 0)
+		assert.Equal(t, "SELECT * FROM trades WHERE symbol = :symbol AND side = :side AND traded_at >= :since AND traded_at < :until ORDER BY gid ASC LIMIT 500", queryTradesSQL(QueryTradesOptions{
+			Symbol: "btc",
+			Side:   types.SideTypeBuy,
+			Since:  &since,
+			Until:  &until,
+			Limit:  500,
+		}))
+	})
+}
+
+func Test_queryTradeSummariesSQL(t *testing.T) {
+	assert.Equal(t, "SELECT exchange, symbol, COUNT(*) AS num_trades, SUM(quantity) AS quantity, SUM(quote_quantity) AS quote_quantity FROM trades GROUP BY exchange, symbol ORDER BY exchange, symbol", queryTradeSummariesSQL(QueryTradesOptions{}))
+	assert.Equal(t, "SELECT exchange, fee_currency, COUNT(*) AS num_trades, SUM(fee) AS fee FROM trades WHERE exchange = :exchange GROUP BY exchange, fee_currency ORDER BY exchange, fee_currency", queryFeesSQL(QueryTradesOptions{Exchange: "max", LastGID: 10, Limit: 100}))
+}
+
+func TestTradeService_QuerySummaries(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	xdb := sqlx.NewDb(db.DB, "sqlite3")
+	service := &TradeService{DB: xdb}
+
+	tradeTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	trades := []types.Trade{
+		{ID: 1, Exchange: "binance", Symbol: "BTCUSDT", Side: types.SideTypeBuy, Price: 1000.0, Quantity: 0.1, QuoteQuantity: 100.0, Fee: 0.1, FeeCurrency: "BNB", Time: types.Time(tradeTime)},
+		{ID: 2, Exchange: "binance", Symbol: "BTCUSDT", Side: types.SideTypeSell, Price: 1100.0, Quantity: 0.1, QuoteQuantity: 110.0, Fee: 0.2, FeeCurrency: "BNB", Time: types.Time(tradeTime.Add(time.Hour))},
+		{ID: 3, Exchange: "binance", Symbol: "ETHUSDT", Side: types.SideTypeBuy, Price: 100.0, Quantity: 1.0, QuoteQuantity: 100.0, Fee: 0.1, FeeCurrency: "USDT", Time: types.Time(tradeTime.AddDate(0, 1, 0))},
+	}
+	assert.NoError(t, service.BatchInsert(trades))
+
+	summaries, err := service.QuerySummaries(QueryTradesOptions{Exchange: "binance"})
+	if assert.NoError(t, err) && assert.Len(t, summaries, 2) {
+		assert.Equal(t, "BTCUSDT", summaries[0].Symbol)
+		assert.Equal(t, int64(2), summaries[0].NumTrades)
+		assert.InDelta(t, 0.2, summaries[0].Quantity, 1e-9)
+		assert.InDelta(t, 210.0, summaries[0].QuoteQuantity, 1e-9)
+	}
+
+	until := tradeTime.AddDate(0, 0, 1)
+	fees, err := service.QueryFees(QueryTradesOptions{Exchange: "binance", Until: &until})
+	if assert.NoError(t, err) && assert.Len(t, fees, 1) {
+		assert.Equal(t, "BNB", fees[0].FeeCurrency)
+		assert.Equal(t, int64(2), fees[0].NumTrades)
+		assert.InDelta(t, 0.3, fees[0].Fee, 1e-9)
+	}
+
+	records, err := service.Query(QueryTradesOptions{Side: types.SideTypeBuy, LastGID: 1, Limit: 10})
+	if assert.NoError(t, err) && assert.Len(t, records, 1) {
+		assert.Equal(t, int64(3), records[0].ID)
+	}
+}
+
+func TestTradeService_BatchInsert(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	xdb := sqlx.NewDb(db.DB, "sqlite3")
+	service := &TradeService{DB: xdb}
+
+	var trades []types.Trade
+	for i := 0; i < 3; i++ {
+		trades = append(trades, types.Trade{
+			ID:       int64(i + 1),
+			OrderID:  uint64(i + 1),
+			Exchange: "binance",
+			Price:    1000.0,
+			Quantity: 0.1,
+			Symbol:   "BTCUSDT",
+			Side:     "BUY",
+			Fee:      0.1,
+		})
+	}
+
+	assert.NoError(t, service.BatchInsert(trades))
+	assert.NoError(t, service.Mark(context.Background(), 1, "grid"))
+
+	// re-run the sync with the updated fee
+	trades[0].Fee = 0.2
+	assert.NoError(t, service.BatchInsert(trades))
+
+	records, err := service.QueryLast("binance", "BTCUSDT", false, false, false, 10)
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+
+	record, err := service.Load(context.Background(), 1)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 0.2, record.Fee)
+		assert.Equal(t, "grid", record.StrategyID.String)
+	}
 }
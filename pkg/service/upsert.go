@@ -0,0 +1,34 @@
+package service
+
+import (
+	"strings"
+)
+
+// syncBatchSize is the number of the rows inserted by one statement in the sync, 50 rows of the orders stay within
+// the 999 variables limit of the older sqlite3 versions
+const syncBatchSize = 50
+
+// upsertClause returns the clause appended to the multi-row insert statement, the rows conflict with the unique key
+// columns are updated with the inserted values of the update columns.
+//
+// The inserted values are referenced by VALUES() and excluded instead of the named parameters, since the named
+// parameters of the clause are not repeated for each row of the bulk insert.
+func upsertClause(driverName string, uniqueColumns, updateColumns []string) string {
+	var sets []string
+	for _, column := range updateColumns {
+		switch driverName {
+		case "mysql":
+			sets = append(sets, column+" = VALUES("+column+")")
+		default:
+			sets = append(sets, column+" = excluded."+column)
+		}
+	}
+
+	switch driverName {
+	case "mysql":
+		return " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+	default:
+		// sqlite3 and postgres
+		return " ON CONFLICT (" + strings.Join(uniqueColumns, ", ") + ") DO UPDATE SET " + strings.Join(sets, ", ")
+	}
+}
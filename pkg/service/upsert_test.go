@@ -0,0 +1,16 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_upsertClause(t *testing.T) {
+	unique := []string{"order_id", "exchange"}
+	update := []string{"status", "updated_at"}
+
+	assert.Equal(t, " ON DUPLICATE KEY UPDATE status = VALUES(status), updated_at = VALUES(updated_at)", upsertClause("mysql", unique, update))
+	assert.Equal(t, " ON CONFLICT (order_id, exchange) DO UPDATE SET status = excluded.status, updated_at = excluded.updated_at", upsertClause("sqlite3", unique, update))
+	assert.Equal(t, " ON CONFLICT (order_id, exchange) DO UPDATE SET status = excluded.status, updated_at = excluded.updated_at", upsertClause("postgres", unique, update))
+}
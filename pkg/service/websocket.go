@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+
+	"github.com/c9s/bbgo/pkg/types"
 )
 
 //go:generate callbackgen -type WebsocketClientBase
@@ -18,6 +20,9 @@ type WebsocketClientBase struct {
 	reconnectC        chan struct{}
 	reconnectDuration time.Duration
 
+	// compression enables the permessage-deflate compression negotiation
+	compression bool
+
 	connectedCallbacks    []func(conn *websocket.Conn)
 	disconnectedCallbacks []func(conn *websocket.Conn)
 	messageCallbacks      []func(message []byte)
@@ -51,6 +56,12 @@ func (s *WebsocketClientBase) Listen(ctx context.Context) {
 				continue
 			}
 
+			mt, msg, err = types.DecompressMessage(mt, msg)
+			if err != nil {
+				s.EmitError(err)
+				continue
+			}
+
 			if mt != websocket.TextMessage {
 				continue
 			}
@@ -68,6 +79,11 @@ func (s *WebsocketClientBase) Connect(ctx context.Context) error {
 	return nil
 }
 
+// SetCompression enables the permessage-deflate compression, it must be called before connecting
+func (s *WebsocketClientBase) SetCompression(enabled bool) {
+	s.compression = enabled
+}
+
 func (s *WebsocketClientBase) Reconnect() {
 	select {
 	case s.reconnectC <- struct{}{}:
@@ -76,7 +92,7 @@ func (s *WebsocketClientBase) Reconnect() {
 }
 
 func (s *WebsocketClientBase) connect(ctx context.Context) error {
-	dialer := websocket.DefaultDialer
+	dialer := types.NewDialer(s.compression)
 	conn, _, err := dialer.DialContext(ctx, s.baseURL, nil)
 	if err != nil {
 		return err
@@ -3,16 +3,21 @@ package slacklog
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
 	"golang.org/x/time/rate"
+
+	"github.com/c9s/bbgo/pkg/slack/slackstyle"
 )
 
 var limiter = rate.NewLimiter(rate.Every(time.Minute), 45)
 
+const maxSectionFields = 10
+
 type LogHook struct {
 	Slack        *slack.Client
 	ErrorChannel string
@@ -40,38 +45,40 @@ func (t *LogHook) Fire(e *logrus.Entry) error {
 		return nil
 	}
 
-	var color = ""
+	text := ":balloon: " + e.Message
+	_, _, err := t.Slack.PostMessageContext(context.Background(), t.ErrorChannel,
+		slack.MsgOptionText(text, false),
+		slack.MsgOptionBlocks(logEntryBlocks(e)...))
+
+	return err
+}
 
+// logEntryBlocks renders the log entry as the message blocks, the fields are sorted by the key
+func logEntryBlocks(e *logrus.Entry) []slack.Block {
+	var icon = ":warning:"
 	switch e.Level {
-	case logrus.DebugLevel:
-		color = "#9B30FF"
-	case logrus.InfoLevel:
-		color = "good"
 	case logrus.ErrorLevel, logrus.FatalLevel, logrus.PanicLevel:
-		color = "danger"
-	default:
-		color = "warning"
+		icon = ":red_circle:"
 	}
 
-	var slackAttachments []slack.Attachment = nil
-
-	// error fields
-	var fields []slack.AttachmentField
-	for k, d := range e.Data {
-		fields = append(fields, slack.AttachmentField{
-			Title: k, Value: fmt.Sprintf("%v", d),
-		})
+	var keys []string
+	for k := range e.Data {
+		keys = append(keys, k)
 	}
+	sort.Strings(keys)
 
-	slackAttachments = append(slackAttachments, slack.Attachment{
-		Color:  color,
-		Title:  strings.ToUpper(e.Level.String()),
-		Fields: fields,
-	})
+	// the section block accepts up to 10 fields
+	var fields []*slack.TextBlockObject
+	for _, k := range keys {
+		if len(fields) == maxSectionFields {
+			break
+		}
 
-	_, _, err := t.Slack.PostMessageContext(context.Background(), t.ErrorChannel,
-		slack.MsgOptionText(":balloon: "+e.Message, true),
-		slack.MsgOptionAttachments(slackAttachments...))
+		fields = append(fields, slackstyle.Field(k, fmt.Sprintf("%v", e.Data[k])))
+	}
 
-	return err
+	return []slack.Block{
+		slack.NewSectionBlock(slackstyle.Markdown(icon+" *"+strings.ToUpper(e.Level.String())+"* "+e.Message), fields, nil),
+		slack.NewContextBlock("", slackstyle.Markdown(e.Time.Format(time.RFC3339))),
+	}
 }
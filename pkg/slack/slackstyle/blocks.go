@@ -0,0 +1,25 @@
+package slackstyle
+
+import "github.com/slack-go/slack"
+
+// Markdown creates the markdown text object of the message blocks
+func Markdown(text string) *slack.TextBlockObject {
+	return slack.NewTextBlockObject(slack.MarkdownType, text, false, false)
+}
+
+// PlainText creates the plain text object of the message blocks, the emoji shortcodes are rendered
+func PlainText(text string) *slack.TextBlockObject {
+	return slack.NewTextBlockObject(slack.PlainTextType, text, true, false)
+}
+
+// Field creates the field of the section block with the bold title
+func Field(title, value string) *slack.TextBlockObject {
+	return Markdown("*" + title + "*\n" + value)
+}
+
+// ConfirmButton creates the danger button that asks for the confirmation before the action is sent
+func ConfirmButton(actionID, value, text, question string) *slack.ButtonBlockElement {
+	button := slack.NewButtonBlockElement(actionID, value, PlainText(text)).WithStyle(slack.StyleDanger)
+	button.Confirm = slack.NewConfirmationBlockObject(PlainText(text), Markdown(question), PlainText("Confirm"), PlainText("Back"))
+	return button
+}
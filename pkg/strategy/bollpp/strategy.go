@@ -45,6 +45,7 @@ type Strategy struct {
 	*bbgo.Persistence
 
 	StandardIndicatorSet *bbgo.StandardIndicatorSet
+	ProfitRecorder       *bbgo.ProfitRecorder
 
 	Symbol    string           `json:"symbol"`
 	Interval  types.Interval   `json:"interval"`
@@ -72,6 +73,11 @@ type Strategy struct {
 	// defaultBoll is the BOLLINGER indicator we used for predicting the price.
 	defaultBoll *indicator.BOLL
 	neutralBoll *indicator.BOLL
+
+	// stateMutex protects the position, the maker orders and the indicator values during the order placing cycle,
+	// so that ReadState can read a consistent snapshot.
+	stateMutex      sync.Mutex
+	indicatorValues map[string]float64
 }
 
 func (s *Strategy) ID() string {
@@ -134,6 +140,29 @@ func (s *Strategy) LoadState() error {
 }
 
 // cancelOrders cancels the orders gracefully
+// ReadState implements bbgo.StrategyStateReader
+func (s *Strategy) ReadState() bbgo.StrategyState {
+	s.stateMutex.Lock()
+	defer s.stateMutex.Unlock()
+
+	var position *types.Position
+	if s.state != nil {
+		position = s.state.Position
+	}
+
+	return bbgo.NewStrategyState(position, s.activeMakerOrders, s.indicatorValues)
+}
+
+// updateIndicatorValues records the indicator values used by the current order placing cycle
+func (s *Strategy) updateIndicatorValues() {
+	s.indicatorValues = map[string]float64{
+		"defaultBoll.up":   s.defaultBoll.LastUpBand(),
+		"defaultBoll.down": s.defaultBoll.LastDownBand(),
+		"neutralBoll.up":   s.neutralBoll.LastUpBand(),
+		"neutralBoll.down": s.neutralBoll.LastDownBand(),
+	}
+}
+
 func (s *Strategy) cancelOrders(ctx context.Context) {
 	if err := s.session.Exchange.CancelOrders(ctx, s.activeMakerOrders.Orders()...); err != nil {
 		log.WithError(err).Errorf("can not cancel %s orders", s.Symbol)
@@ -288,6 +317,7 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 			Time:            trade.Time.Time(),
 		}
 		s.state.ProfitStats.AddProfit(p)
+		s.ProfitRecorder.Record(ID, p)
 		s.Notify(&p)
 		s.Notify(&s.state.ProfitStats)
 	})
@@ -308,7 +338,11 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 	// go s.tradeCollector.Run(ctx)
 
 	session.UserDataStream.OnStart(func() {
+		s.stateMutex.Lock()
+		defer s.stateMutex.Unlock()
+
 		s.placeOrders(ctx, orderExecutor)
+		s.updateIndicatorValues()
 	})
 
 	session.MarketDataStream.OnKLineClosed(func(kline types.KLine) {
@@ -316,10 +350,14 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 			return
 		}
 
+		s.stateMutex.Lock()
+		defer s.stateMutex.Unlock()
+
 		s.cancelOrders(ctx)
 
 		s.tradeCollector.Process()
 		s.placeOrders(ctx, orderExecutor)
+		s.updateIndicatorValues()
 	})
 
 	// s.book = types.NewStreamBook(s.Symbol)
@@ -0,0 +1,320 @@
+package dca
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const ID = "dca"
+
+const stateKey = "state-v1"
+
+var log = logrus.WithField("strategy", ID)
+
+func init() {
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+type State struct {
+	// NextTime is the next scheduled buy time
+	NextTime time.Time `json:"nextTime"`
+
+	// Spent is the total quote amount of the filled buy trades, it's checked against the budget
+	Spent fixedpoint.Value `json:"spent"`
+
+	NumOfBuys int `json:"numOfBuys"`
+
+	Position *types.Position `json:"position,omitempty"`
+}
+
+// DipMultiplier buys more when the price is below the moving average
+type DipMultiplier struct {
+	// MovingAverage is the moving average to compare, e.g., type: SMA, interval: 1d, window: 30
+	MovingAverage bbgo.MovingAverageSettings `json:"movingAverage"`
+
+	// Threshold is the ratio below the moving average to apply the multiplier, e.g., 0.05 means 5% below
+	Threshold fixedpoint.Value `json:"threshold,omitempty"`
+
+	// Multiplier is applied to the quote amount, e.g., 2.0 buys the double amount
+	Multiplier fixedpoint.Value `json:"multiplier"`
+}
+
+type Strategy struct {
+	*bbgo.Notifiability `json:"-"`
+	*bbgo.Persistence
+	*bbgo.Graceful `json:"-"`
+
+	// StandardIndicatorSet contains the standard indicators of a market (symbol)
+	// This field will be injected automatically since we defined the Symbol field.
+	*bbgo.StandardIndicatorSet `json:"-"`
+
+	Symbol string       `json:"symbol"`
+	Market types.Market `json:"-"`
+
+	// Schedule is the cron spec of the buy schedule, e.g., "0 8 * * *" or "@every 4h",
+	// the schedule is checked on the closed 1m klines, so it works in the back-testing as well.
+	Schedule string `json:"schedule"`
+
+	// Amount is the quote amount of each buy
+	Amount fixedpoint.Value `json:"amount"`
+
+	DipMultiplier *DipMultiplier `json:"dipMultiplier,omitempty"`
+
+	// Budget is the total quote amount to spend, zero means no limit
+	Budget fixedpoint.Value `json:"budget,omitempty"`
+
+	schedule cron.Schedule
+
+	movingAverage types.Float64Indicator
+
+	state *State
+
+	orderStore     *bbgo.OrderStore
+	tradeCollector *bbgo.TradeCollector
+}
+
+func (s *Strategy) ID() string {
+	return ID
+}
+
+func (s *Strategy) Validate() error {
+	if len(s.Symbol) == 0 {
+		return errors.New("symbol is required")
+	}
+
+	if s.Amount <= 0 {
+		return errors.New("amount should be greater than zero")
+	}
+
+	if _, err := cron.ParseStandard(s.Schedule); err != nil {
+		return errors.Wrapf(err, "invalid schedule %q", s.Schedule)
+	}
+
+	if s.Budget < 0 {
+		return errors.New("budget can not be negative")
+	}
+
+	if s.DipMultiplier != nil {
+		if s.DipMultiplier.Multiplier <= 0 {
+			return errors.New("dipMultiplier.multiplier should be greater than zero")
+		}
+
+		if len(s.DipMultiplier.MovingAverage.Interval) == 0 {
+			return errors.New("dipMultiplier.movingAverage.interval is required")
+		}
+	}
+
+	return nil
+}
+
+func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
+	session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: string(types.Interval1m)})
+
+	if s.DipMultiplier != nil {
+		session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: string(s.DipMultiplier.MovingAverage.Interval)})
+	}
+}
+
+func (s *Strategy) SaveState() error {
+	if err := s.Persistence.Save(s.state, ID, s.Symbol, stateKey); err != nil {
+		return err
+	}
+
+	log.Infof("state is saved => %+v", s.state)
+	return nil
+}
+
+func (s *Strategy) LoadState() error {
+	var state State
+
+	if err := s.Persistence.Load(&state, ID, s.Symbol, stateKey); err != nil {
+		if err != service.ErrPersistenceNotExists {
+			return err
+		}
+
+		s.state = &State{}
+	} else {
+		s.state = &state
+		log.Infof("state is restored: %+v", s.state)
+	}
+
+	if s.state.Position == nil {
+		s.state.Position = types.NewPositionFromMarket(s.Market)
+	}
+
+	return nil
+}
+
+// buyAmount returns the quote amount of this round, the dip multiplier is applied when the price is below the
+// moving average, and the amount is capped by the remaining budget. Zero is returned when the budget is used up.
+func (s *Strategy) buyAmount(price, movingAverage float64) fixedpoint.Value {
+	amount := s.Amount
+
+	if s.DipMultiplier != nil && movingAverage > 0 {
+		threshold := movingAverage * (1.0 - s.DipMultiplier.Threshold.Float64())
+		if price < threshold {
+			amount = amount.Mul(s.DipMultiplier.Multiplier)
+		}
+	}
+
+	if s.Budget > 0 {
+		remaining := s.Budget - s.state.Spent
+		if remaining <= 0 {
+			return 0
+		}
+
+		if amount > remaining {
+			amount = remaining
+		}
+	}
+
+	return amount
+}
+
+// isDue checks the schedule by the kline end time and moves the next time forward when the buy is due
+func (s *Strategy) isDue(now time.Time) bool {
+	if s.state.NextTime.IsZero() {
+		s.state.NextTime = s.schedule.Next(now)
+		log.Infof("next %s dca buy is scheduled at %s", s.Symbol, s.state.NextTime)
+		return false
+	}
+
+	if now.Before(s.state.NextTime) {
+		return false
+	}
+
+	s.state.NextTime = s.schedule.Next(now)
+	return true
+}
+
+// buyOrder returns the market buy order of the quote amount, the quantity is rounded down to the step size of the
+// market, so the order is rejected if the rounded quantity is below the min quantity or the min notional.
+func (s *Strategy) buyOrder(amount fixedpoint.Value, price float64) (types.SubmitOrder, error) {
+	quantity := s.Market.RoundQuantity(amount.Float64()/price, types.RoundingPolicyDown)
+	order := types.SubmitOrder{
+		Symbol:         s.Symbol,
+		Market:         s.Market,
+		Side:           types.SideTypeBuy,
+		Type:           types.OrderTypeMarket,
+		Quantity:       quantity,
+		QuantityString: s.Market.FormatRoundedQuantity(quantity),
+	}
+
+	return order, bbgo.CheckMarketFilters(order, price)
+}
+
+func (s *Strategy) buy(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession, price float64) {
+	var movingAverage float64
+	if s.movingAverage != nil {
+		movingAverage = s.movingAverage.Last()
+	}
+
+	amount := s.buyAmount(price, movingAverage)
+	if amount == 0 {
+		s.Notify("%s dca budget %f %s is used up, skipping", s.Symbol, s.Budget.Float64(), s.Market.QuoteCurrency)
+		return
+	}
+
+	order, err := s.buyOrder(amount, price)
+	if err != nil {
+		log.WithError(err).Warnf("%s dca amount %f can not be bought, skipping", s.Symbol, amount.Float64())
+		return
+	}
+
+	quoteBalance, ok := session.Account.Balance(s.Market.QuoteCurrency)
+	if !ok || quoteBalance.Available < amount {
+		s.Notify("%s dca quote balance %s is not enough for %f", s.Symbol, s.Market.QuoteCurrency, amount.Float64())
+		return
+	}
+
+	s.Notify("Submitting %s dca buy order, amount %f %s, quantity %f at price %f",
+		s.Symbol, amount.Float64(), s.Market.QuoteCurrency, order.Quantity, price)
+
+	createdOrders, err := orderExecutor.SubmitOrders(ctx, order)
+	if err != nil {
+		log.WithError(err).Error("submit order error")
+		return
+	}
+
+	s.orderStore.Add(createdOrders...)
+	s.tradeCollector.Process()
+	s.state.NumOfBuys++
+}
+
+func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	schedule, err := cron.ParseStandard(s.Schedule)
+	if err != nil {
+		return err
+	}
+	s.schedule = schedule
+
+	if s.DipMultiplier != nil {
+		if s.StandardIndicatorSet == nil {
+			return errors.New("StandardIndicatorSet can not be nil, injection failed?")
+		}
+
+		s.movingAverage, err = s.DipMultiplier.MovingAverage.Indicator(s.StandardIndicatorSet)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := s.LoadState(); err != nil {
+		return err
+	}
+
+	s.orderStore = bbgo.NewOrderStore(s.Symbol)
+	s.orderStore.BindStream(session.UserDataStream)
+
+	s.tradeCollector = bbgo.NewTradeCollector(s.Symbol, s.state.Position, s.orderStore)
+	s.tradeCollector.OnTrade(func(trade types.Trade) {
+		s.Notifiability.Notify(trade)
+		s.state.Spent += fixedpoint.NewFromFloat(trade.Price * trade.Quantity)
+	})
+	s.tradeCollector.OnPositionUpdate(func(position *types.Position) {
+		log.Infof("%s dca position: %s", s.Symbol, position)
+
+		if err := s.SaveState(); err != nil {
+			log.WithError(err).Error("can not save state")
+		}
+	})
+	s.tradeCollector.BindStream(session.UserDataStream)
+
+	session.MarketDataStream.OnKLineClosed(func(kline types.KLine) {
+		if kline.Symbol != s.Symbol || kline.Interval != types.Interval1m {
+			return
+		}
+
+		if !s.isDue(kline.EndTime) {
+			return
+		}
+
+		s.buy(ctx, orderExecutor, session, kline.Close)
+
+		if err := s.SaveState(); err != nil {
+			log.WithError(err).Error("can not save state")
+		}
+	})
+
+	s.Graceful.OnShutdown(func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		if err := s.SaveState(); err != nil {
+			log.WithError(err).Error("can not save state")
+		} else {
+			s.Notify("%s dca spent %f %s in %d buys", s.Symbol, s.state.Spent.Float64(), s.Market.QuoteCurrency, s.state.NumOfBuys)
+		}
+	})
+
+	return nil
+}
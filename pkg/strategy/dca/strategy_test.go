@@ -0,0 +1,154 @@
+package dca
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/strategytest"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestStrategy_Validate(t *testing.T) {
+	s := &Strategy{Symbol: "BTCUSDT", Schedule: "0 8 * * *", Amount: fixedpoint.NewFromFloat(50.0)}
+	assert.NoError(t, s.Validate())
+
+	s.Schedule = "@every 4h"
+	assert.NoError(t, s.Validate())
+
+	s.Schedule = "every day"
+	assert.Error(t, s.Validate())
+
+	s.Schedule = "@daily"
+	s.DipMultiplier = &DipMultiplier{MovingAverage: bbgo.MovingAverageSettings{Type: "SMA", Interval: "1d"}}
+	assert.Error(t, s.Validate())
+
+	s.DipMultiplier.Multiplier = fixedpoint.NewFromFloat(2.0)
+	assert.NoError(t, s.Validate())
+}
+
+func TestStrategy_buyAmount(t *testing.T) {
+	s := &Strategy{
+		Amount: fixedpoint.NewFromFloat(50.0),
+		Budget: fixedpoint.NewFromFloat(220.0),
+		DipMultiplier: &DipMultiplier{
+			Threshold:  fixedpoint.NewFromFloat(0.05),
+			Multiplier: fixedpoint.NewFromFloat(2.0),
+		},
+		state: &State{},
+	}
+
+	// not enough below the moving average
+	assert.Equal(t, 50.0, s.buyAmount(96.0, 100.0).Float64())
+
+	// the dip multiplier is applied
+	assert.Equal(t, 100.0, s.buyAmount(94.0, 100.0).Float64())
+
+	// the moving average is not ready
+	assert.Equal(t, 50.0, s.buyAmount(94.0, 0).Float64())
+
+	// capped by the remaining budget
+	s.state.Spent = fixedpoint.NewFromFloat(180.0)
+	assert.Equal(t, 40.0, s.buyAmount(94.0, 100.0).Float64())
+
+	// the budget is used up
+	s.state.Spent = fixedpoint.NewFromFloat(220.0)
+	assert.Equal(t, fixedpoint.Value(0), s.buyAmount(94.0, 100.0))
+}
+
+func TestStrategy_buyOrder(t *testing.T) {
+	s := &Strategy{
+		Symbol: "BTCUSDT",
+		Market: types.Market{Symbol: "BTCUSDT", MinNotional: 10.0, MinQuantity: 0.0001, StepSize: 0.0001, TickSize: 0.01},
+	}
+
+	// the quantity is rounded down to the step size
+	order, err := s.buyOrder(fixedpoint.NewFromFloat(50.0), 30000.0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0016, order.Quantity)
+	assert.Equal(t, "0.0016", order.QuantityString)
+
+	// the notional of the rounded quantity 0.0003 is below the min notional
+	_, err = s.buyOrder(fixedpoint.NewFromFloat(10.5), 30000.0)
+	assert.Equal(t, bbgo.ErrOrderNotionalTooSmall, errors.Cause(err))
+}
+
+func TestStrategy_isDue(t *testing.T) {
+	schedule, err := cron.ParseStandard("0 8 * * *")
+	assert.NoError(t, err)
+
+	s := &Strategy{Symbol: "BTCUSDT", schedule: schedule, state: &State{}}
+
+	now := time.Date(2021, 6, 1, 7, 0, 0, 0, time.Local)
+	assert.False(t, s.isDue(now))
+	assert.Equal(t, time.Date(2021, 6, 1, 8, 0, 0, 0, time.Local), s.state.NextTime)
+
+	assert.False(t, s.isDue(now.Add(59*time.Minute)))
+	assert.True(t, s.isDue(now.Add(time.Hour)))
+	assert.Equal(t, time.Date(2021, 6, 2, 8, 0, 0, 0, time.Local), s.state.NextTime)
+	assert.False(t, s.isDue(now.Add(2*time.Hour)))
+}
+
+func TestStrategy_Scenario(t *testing.T) {
+	ctx := context.Background()
+	market := types.Market{
+		Symbol:          "BTCUSDT",
+		PricePrecision:  2,
+		VolumePrecision: 6,
+		BaseCurrency:    "BTC",
+		QuoteCurrency:   "USDT",
+		MinNotional:     10.0,
+		StepSize:        0.000001,
+		TickSize:        0.01,
+	}
+
+	h := strategytest.New(strategytest.Config{
+		Markets: types.MarketMap{"BTCUSDT": market},
+		Balances: types.BalanceMap{
+			"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(1000.0)},
+		},
+	})
+
+	s := &Strategy{
+		Symbol:   "BTCUSDT",
+		Schedule: "@every 1h",
+		Amount:   fixedpoint.NewFromFloat(100.0),
+		Budget:   fixedpoint.NewFromFloat(250.0),
+	}
+	if !assert.NoError(t, h.Run(ctx, s)) {
+		return
+	}
+
+	// 3 hours of the 1m klines, the last market order is filled by the extra kline
+	prices := make([]float64, 3*60+2)
+	for i := range prices {
+		prices[i] = 100.0
+	}
+
+	kLines := strategytest.KLinesFromPrices("BTCUSDT", types.Interval1m, strategytest.DefaultStartTime, prices...)
+	assert.NoError(t, h.Play(strategytest.KLines(kLines...)))
+
+	orders := h.SubmittedOrders()
+	if assert.Len(t, orders, 3) {
+		assert.Equal(t, 1.0, orders[0].Quantity)
+		assert.Equal(t, 1.0, orders[1].Quantity)
+
+		// capped by the remaining budget
+		assert.Equal(t, 0.5, orders[2].Quantity)
+	}
+
+	assert.Len(t, h.Trades(), 3)
+	assert.InDelta(t, 750.0, h.Balances()["USDT"].Available.Float64(), 1e-8)
+
+	var state State
+	assert.NoError(t, h.LoadState(&state, ID, s.Symbol, stateKey))
+	assert.Equal(t, 250.0, state.Spent.Float64())
+	assert.Equal(t, 3, state.NumOfBuys)
+	assert.Equal(t, 2.5, state.Position.Base.Float64())
+}
@@ -0,0 +1,411 @@
+package funding
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const ID = "funding"
+
+const stateKey = "state-v1"
+
+var log = logrus.WithField("strategy", ID)
+
+func init() {
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+type State struct {
+	// Quantity is the base quantity of the hedged position, spot long and perpetual short, zero means no position
+	Quantity fixedpoint.Value `json:"quantity"`
+
+	EntryTime time.Time `json:"entryTime,omitempty"`
+
+	NumOfEntries int `json:"numOfEntries"`
+
+	// AccumulatedFundingFee is the funding fee received by the unwound positions, the fee paid is negative
+	AccumulatedFundingFee fixedpoint.Value `json:"accumulatedFundingFee"`
+}
+
+// Strategy holds the spot long and the perpetual short of the same underlying to capture the funding fee,
+// the position is opened when the funding rate is greater than the entry funding rate,
+// and unwound when the funding rate drops below the exit funding rate (the funding turns negative by default).
+type Strategy struct {
+	*bbgo.Notifiability
+	*bbgo.Persistence
+	*bbgo.Graceful
+
+	Symbol string `json:"symbol"`
+
+	// SpotSession is the session of the spot long leg
+	SpotSession string `json:"spotSession"`
+
+	// FuturesSession is the session of the perpetual short leg, the session should be configured with futures: true
+	FuturesSession string `json:"futuresSession"`
+
+	// Quantity is the max base quantity of the hedged position
+	Quantity fixedpoint.Value `json:"quantity"`
+
+	// EntryFundingRate is the min funding rate to open the position, e.g., 0.0003 means 0.03% per funding period
+	EntryFundingRate fixedpoint.Value `json:"entryFundingRate"`
+
+	// ExitFundingRate unwinds the position when the funding rate is lower than it, defaults to 0
+	ExitFundingRate fixedpoint.Value `json:"exitFundingRate"`
+
+	// UpdateInterval is the interval of checking the funding rate, defaults to 1m
+	UpdateInterval types.Duration `json:"updateInterval"`
+
+	spot, futures *fundingSession
+
+	state *State
+	mu    sync.Mutex
+}
+
+// fundingSession is the market of one leg
+type fundingSession struct {
+	name    string
+	session *bbgo.ExchangeSession
+	market  types.Market
+}
+
+func (s *Strategy) ID() string {
+	return ID
+}
+
+func (s *Strategy) Validate() error {
+	if len(s.Symbol) == 0 {
+		return errors.New("symbol is required")
+	}
+
+	if len(s.SpotSession) == 0 || len(s.FuturesSession) == 0 {
+		return errors.New("spotSession and futuresSession are required")
+	}
+
+	if s.SpotSession == s.FuturesSession {
+		return errors.New("spotSession and futuresSession should be different sessions")
+	}
+
+	if s.Quantity <= 0 {
+		return errors.New("quantity should be greater than zero")
+	}
+
+	if s.EntryFundingRate <= s.ExitFundingRate {
+		return errors.New("entryFundingRate should be greater than exitFundingRate")
+	}
+
+	return nil
+}
+
+func (s *Strategy) CrossSubscribe(sessions map[string]*bbgo.ExchangeSession) {
+	for _, name := range []string{s.SpotSession, s.FuturesSession} {
+		session, ok := sessions[name]
+		if !ok {
+			panic(fmt.Errorf("session %s is not defined", name))
+		}
+
+		session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: string(types.Interval1m)})
+	}
+}
+
+// fundingRate queries the last funding rate and the mark price of the perpetual
+func (s *Strategy) fundingRate(ctx context.Context) (*types.PremiumIndex, error) {
+	querier, ok := s.futures.session.Exchange.(bbgo.PremiumIndexQuerier)
+	if !ok {
+		return nil, fmt.Errorf("exchange %s does not support the funding rate query", s.futures.session.ExchangeName)
+	}
+
+	return querier.QueryPremiumIndex(ctx, s.Symbol)
+}
+
+// entryQuantity returns the quantity that both legs can open, it's limited by the spot quote balance
+func (s *Strategy) entryQuantity(price float64) float64 {
+	b, ok := s.spot.session.Account.Balance(s.spot.market.QuoteCurrency)
+	if !ok || price <= 0 {
+		return 0
+	}
+
+	quantity := math.Min(s.Quantity.Float64(), b.Available.Float64()/price)
+	quantity = s.spot.market.CanonicalizeVolume(s.futures.market.CanonicalizeVolume(quantity))
+	for _, market := range []types.Market{s.spot.market, s.futures.market} {
+		if quantity < market.MinQuantity || quantity*price < market.MinNotional {
+			return 0
+		}
+	}
+
+	return quantity
+}
+
+// submitLegs submits the market orders of both legs at the same time, if only one leg is submitted,
+// the submitted leg is reverted, so that the position is never left unhedged.
+func (s *Strategy) submitLegs(ctx context.Context, router bbgo.OrderExecutionRouter, spotOrder, futuresOrder types.SubmitOrder) error {
+	legs := []struct {
+		session *fundingSession
+		order   types.SubmitOrder
+	}{
+		{s.spot, spotOrder},
+		{s.futures, futuresOrder},
+	}
+
+	var errs = make([]error, len(legs))
+	var wg sync.WaitGroup
+	for i, leg := range legs {
+		wg.Add(1)
+		go func(i int, sessionName string, order types.SubmitOrder) {
+			defer wg.Done()
+			_, errs[i] = router.SubmitOrdersTo(ctx, sessionName, order)
+		}(i, leg.session.name, leg.order)
+	}
+	wg.Wait()
+
+	switch {
+	case errs[0] != nil && errs[1] != nil:
+		return errors.Wrapf(errs[0], "%s funding arbitrage orders are rejected, futures leg error: %v", s.Symbol, errs[1])
+
+	case errs[0] != nil || errs[1] != nil:
+		failed, submitted := 0, 1
+		if errs[1] != nil {
+			failed, submitted = 1, 0
+		}
+
+		revert := legs[submitted].order
+		revert.Side = oppositeSide(revert.Side)
+		revert.ReduceOnly = legs[submitted].session == s.futures
+		if _, err := router.SubmitOrdersTo(ctx, legs[submitted].session.name, revert); err != nil {
+			s.Notify("⚠️ %s funding arbitrage leg on %s is unhedged, revert error: %v", s.Symbol, legs[submitted].session.name, err)
+		}
+
+		return errors.Wrapf(errs[failed], "%s funding arbitrage %s leg is rejected", s.Symbol, legs[failed].session.name)
+	}
+
+	return nil
+}
+
+func oppositeSide(side types.SideType) types.SideType {
+	if side == types.SideTypeBuy {
+		return types.SideTypeSell
+	}
+	return types.SideTypeBuy
+}
+
+func (s *Strategy) enter(ctx context.Context, router bbgo.OrderExecutionRouter, index *types.PremiumIndex) {
+	quantity := s.entryQuantity(index.MarkPrice.Float64())
+	if quantity <= 0 {
+		log.Infof("%s funding rate %s is above the entry rate, but the balance is not enough", s.Symbol, index.LastFundingRate.Percentage())
+		return
+	}
+
+	err := s.submitLegs(ctx, router, types.SubmitOrder{
+		Symbol:   s.Symbol,
+		Market:   s.spot.market,
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeMarket,
+		Quantity: quantity,
+	}, types.SubmitOrder{
+		Symbol:   s.Symbol,
+		Market:   s.futures.market,
+		Side:     types.SideTypeSell,
+		Type:     types.OrderTypeMarket,
+		Quantity: quantity,
+	})
+	if err != nil {
+		log.WithError(err).Error("can not open the funding arbitrage position")
+		return
+	}
+
+	s.mu.Lock()
+	s.state.Quantity = fixedpoint.NewFromFloat(quantity)
+	s.state.EntryTime = index.Time
+	s.state.NumOfEntries++
+	s.mu.Unlock()
+
+	s.Notify("%s funding rate %s, opened the funding arbitrage position: spot long and perpetual short %f at %f",
+		s.Symbol, index.LastFundingRate.Percentage(), quantity, index.MarkPrice.Float64())
+}
+
+func (s *Strategy) unwind(ctx context.Context, router bbgo.OrderExecutionRouter, index *types.PremiumIndex) {
+	s.mu.Lock()
+	quantity := s.state.Quantity.Float64()
+	entryTime := s.state.EntryTime
+	s.mu.Unlock()
+
+	// the spot fee may be deducted from the base currency, so the spot leg sells what is left
+	spotQuantity := quantity
+	if b, ok := s.spot.session.Account.Balance(s.spot.market.BaseCurrency); ok {
+		spotQuantity = s.spot.market.CanonicalizeVolume(math.Min(quantity, b.Available.Float64()))
+	}
+
+	err := s.submitLegs(ctx, router, types.SubmitOrder{
+		Symbol:   s.Symbol,
+		Market:   s.spot.market,
+		Side:     types.SideTypeSell,
+		Type:     types.OrderTypeMarket,
+		Quantity: spotQuantity,
+	}, types.SubmitOrder{
+		Symbol:     s.Symbol,
+		Market:     s.futures.market,
+		Side:       types.SideTypeBuy,
+		Type:       types.OrderTypeMarket,
+		Quantity:   quantity,
+		ReduceOnly: true,
+	})
+	if err != nil {
+		log.WithError(err).Error("can not unwind the funding arbitrage position")
+		return
+	}
+
+	fundingFee := s.queryFundingFee(ctx, entryTime, index.Time)
+
+	s.mu.Lock()
+	s.state.Quantity = 0
+	s.state.EntryTime = time.Time{}
+	s.state.AccumulatedFundingFee += fundingFee
+	s.mu.Unlock()
+
+	s.Notify("%s funding rate %s, unwound the funding arbitrage position %f, funding fee %f %s",
+		s.Symbol, index.LastFundingRate.Percentage(), quantity, fundingFee.Float64(), s.futures.market.QuoteCurrency)
+}
+
+// queryFundingFee returns the funding fee of the position if the futures exchange supports the funding fee query
+func (s *Strategy) queryFundingFee(ctx context.Context, since, until time.Time) (fee fixedpoint.Value) {
+	futuresService, ok := s.futures.session.Exchange.(types.FuturesService)
+	if !ok || since.IsZero() {
+		return 0
+	}
+
+	fees, err := futuresService.QueryFundingFeeHistory(ctx, s.Symbol, since, until)
+	if err != nil {
+		log.WithError(err).Errorf("can not query %s funding fee history", s.Symbol)
+		return 0
+	}
+
+	for _, f := range fees {
+		fee += f.Amount
+	}
+	return fee
+}
+
+func (s *Strategy) check(ctx context.Context, router bbgo.OrderExecutionRouter) {
+	index, err := s.fundingRate(ctx)
+	if err != nil {
+		log.WithError(err).Errorf("can not query %s funding rate", s.Symbol)
+		return
+	}
+
+	if index.Time.IsZero() {
+		index.Time = time.Now()
+	}
+
+	s.mu.Lock()
+	hedged := s.state.Quantity > 0
+	s.mu.Unlock()
+
+	switch {
+	case !hedged && index.LastFundingRate >= s.EntryFundingRate:
+		s.enter(ctx, router, index)
+
+	case hedged && index.LastFundingRate < s.ExitFundingRate:
+		s.unwind(ctx, router, index)
+	}
+}
+
+func (s *Strategy) SaveState() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.Persistence.Save(s.state, ID, s.Symbol, stateKey); err != nil {
+		return err
+	}
+
+	log.Infof("state is saved => %+v", s.state)
+	return nil
+}
+
+func (s *Strategy) LoadState() error {
+	var state State
+	if err := s.Persistence.Load(&state, ID, s.Symbol, stateKey); err != nil {
+		if err != service.ErrPersistenceNotExists {
+			return err
+		}
+
+		s.state = &State{}
+	} else {
+		s.state = &state
+		log.Infof("state is restored: %+v", s.state)
+	}
+
+	return nil
+}
+
+func (s *Strategy) CrossRun(ctx context.Context, router bbgo.OrderExecutionRouter, sessions map[string]*bbgo.ExchangeSession) error {
+	if s.UpdateInterval == 0 {
+		s.UpdateInterval = types.Duration(time.Minute)
+	}
+
+	for _, leg := range []struct {
+		name    string
+		futures bool
+		target  **fundingSession
+	}{{s.SpotSession, false, &s.spot}, {s.FuturesSession, true, &s.futures}} {
+		session, ok := sessions[leg.name]
+		if !ok {
+			return fmt.Errorf("session %s is not defined", leg.name)
+		}
+
+		if leg.futures && !session.Futures {
+			return fmt.Errorf("session %s is not a futures session", leg.name)
+		} else if !leg.futures && session.Futures {
+			return fmt.Errorf("session %s is a futures session, the spot leg requires a spot session", leg.name)
+		}
+
+		market, ok := session.Market(s.Symbol)
+		if !ok {
+			return fmt.Errorf("market %s is not defined on session %s", s.Symbol, leg.name)
+		}
+
+		*leg.target = &fundingSession{name: leg.name, session: session, market: market}
+	}
+
+	if err := s.LoadState(); err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.UpdateInterval.Duration())
+		defer ticker.Stop()
+
+		s.check(ctx, router)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-ticker.C:
+				s.check(ctx, router)
+			}
+		}
+	}()
+
+	s.Graceful.OnShutdown(func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		if err := s.SaveState(); err != nil {
+			log.WithError(err).Error("can not save state")
+		} else {
+			s.Notify("%s funding arbitrage: %d entries, position %f, accumulated funding fee %f",
+				s.Symbol, s.state.NumOfEntries, s.state.Quantity.Float64(), s.state.AccumulatedFundingFee.Float64())
+		}
+	})
+
+	return nil
+}
@@ -0,0 +1,214 @@
+package funding
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var testMarket = types.Market{
+	Symbol:          "BTCUSDT",
+	BaseCurrency:    "BTC",
+	QuoteCurrency:   "USDT",
+	VolumePrecision: 3,
+	MinQuantity:     0.001,
+	MinNotional:     10.0,
+}
+
+type testFuturesExchange struct {
+	types.Exchange
+
+	index types.PremiumIndex
+	fees  []types.FundingFee
+}
+
+func (e *testFuturesExchange) QueryPremiumIndex(ctx context.Context, symbol string) (*types.PremiumIndex, error) {
+	index := e.index
+	return &index, nil
+}
+
+func (e *testFuturesExchange) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	return nil
+}
+
+func (e *testFuturesExchange) SetPositionMode(ctx context.Context, mode types.PositionMode) error {
+	return nil
+}
+
+func (e *testFuturesExchange) QueryPositions(ctx context.Context) (types.PositionMap, error) {
+	return nil, nil
+}
+
+func (e *testFuturesExchange) QueryFundingFeeHistory(ctx context.Context, symbol string, since, until time.Time) ([]types.FundingFee, error) {
+	return e.fees, nil
+}
+
+type testRouter struct {
+	mu     sync.Mutex
+	orders map[string][]types.SubmitOrder
+	errs   map[string]error
+}
+
+func (r *testRouter) SubmitOrdersTo(ctx context.Context, session string, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.errs[session]; err != nil {
+		return nil, err
+	}
+
+	r.orders[session] = append(r.orders[session], orders...)
+	return nil, nil
+}
+
+func newTestStrategy(exchange *testFuturesExchange, btc, usdt float64) *Strategy {
+	account := types.NewAccount()
+	account.UpdateBalances(types.BalanceMap{
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(btc)},
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(usdt)},
+	})
+
+	return &Strategy{
+		Notifiability:    &bbgo.Notifiability{},
+		Symbol:           "BTCUSDT",
+		SpotSession:      "binance",
+		FuturesSession:   "binance-futures",
+		Quantity:         fixedpoint.NewFromFloat(1.0),
+		EntryFundingRate: fixedpoint.NewFromFloat(0.0003),
+		spot: &fundingSession{
+			name:    "binance",
+			session: &bbgo.ExchangeSession{Name: "binance", Account: account},
+			market:  testMarket,
+		},
+		futures: &fundingSession{
+			name:    "binance-futures",
+			session: &bbgo.ExchangeSession{Name: "binance-futures", Exchange: exchange, Account: types.NewAccount()},
+			market:  testMarket,
+		},
+		state: &State{},
+	}
+}
+
+func TestStrategy_Validate(t *testing.T) {
+	s := newTestStrategy(&testFuturesExchange{}, 0, 0)
+	assert.NoError(t, s.Validate())
+
+	s.FuturesSession = "binance"
+	assert.Error(t, s.Validate())
+
+	s = newTestStrategy(&testFuturesExchange{}, 0, 0)
+	s.ExitFundingRate = fixedpoint.NewFromFloat(0.0005)
+	assert.Error(t, s.Validate())
+
+	s = newTestStrategy(&testFuturesExchange{}, 0, 0)
+	s.Quantity = 0
+	assert.Error(t, s.Validate())
+}
+
+func TestStrategy_entryQuantity(t *testing.T) {
+	s := newTestStrategy(&testFuturesExchange{}, 0, 50000.0)
+	assert.Equal(t, 1.0, s.entryQuantity(40000.0))
+
+	// limited by the quote balance
+	s = newTestStrategy(&testFuturesExchange{}, 0, 10000.0)
+	assert.Equal(t, 0.25, s.entryQuantity(40000.0))
+
+	// less than the min notional
+	s = newTestStrategy(&testFuturesExchange{}, 0, 5.0)
+	assert.Equal(t, 0.0, s.entryQuantity(40000.0))
+}
+
+func TestStrategy_check(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	exchange := &testFuturesExchange{
+		index: types.PremiumIndex{
+			Symbol:          "BTCUSDT",
+			MarkPrice:       fixedpoint.NewFromFloat(40000.0),
+			LastFundingRate: fixedpoint.NewFromFloat(0.0001),
+			Time:            now,
+		},
+	}
+
+	s := newTestStrategy(exchange, 0, 20000.0)
+	router := &testRouter{orders: map[string][]types.SubmitOrder{}, errs: map[string]error{}}
+
+	// the funding rate is lower than the entry funding rate
+	s.check(ctx, router)
+	assert.Len(t, router.orders, 0)
+
+	exchange.index.LastFundingRate = fixedpoint.NewFromFloat(0.0005)
+	s.check(ctx, router)
+	if assert.Len(t, router.orders["binance"], 1) && assert.Len(t, router.orders["binance-futures"], 1) {
+		assert.Equal(t, types.SideTypeBuy, router.orders["binance"][0].Side)
+		assert.Equal(t, 0.5, router.orders["binance"][0].Quantity)
+		assert.Equal(t, types.SideTypeSell, router.orders["binance-futures"][0].Side)
+		assert.Equal(t, 0.5, router.orders["binance-futures"][0].Quantity)
+	}
+	assert.Equal(t, 0.5, s.state.Quantity.Float64())
+	assert.Equal(t, 1, s.state.NumOfEntries)
+
+	// the position is held while the funding rate is positive
+	exchange.index.LastFundingRate = fixedpoint.NewFromFloat(0.0001)
+	s.check(ctx, router)
+	assert.Len(t, router.orders["binance"], 1)
+
+	// the funding rate turns negative
+	s.spot.session.Account.UpdateBalances(types.BalanceMap{
+		"BTC": {Currency: "BTC", Available: fixedpoint.NewFromFloat(0.4995)},
+	})
+	exchange.fees = []types.FundingFee{
+		{Symbol: "BTCUSDT", Asset: "USDT", Amount: fixedpoint.NewFromFloat(6.0)},
+		{Symbol: "BTCUSDT", Asset: "USDT", Amount: fixedpoint.NewFromFloat(-1.0)},
+	}
+	exchange.index.LastFundingRate = fixedpoint.NewFromFloat(-0.0001)
+	exchange.index.Time = now.Add(24 * time.Hour)
+	s.check(ctx, router)
+	if assert.Len(t, router.orders["binance"], 2) && assert.Len(t, router.orders["binance-futures"], 2) {
+		// the spot leg sells the base balance left after the fee
+		assert.Equal(t, types.SideTypeSell, router.orders["binance"][1].Side)
+		assert.Equal(t, 0.499, router.orders["binance"][1].Quantity)
+
+		assert.Equal(t, types.SideTypeBuy, router.orders["binance-futures"][1].Side)
+		assert.Equal(t, 0.5, router.orders["binance-futures"][1].Quantity)
+		assert.True(t, router.orders["binance-futures"][1].ReduceOnly)
+	}
+	assert.Equal(t, fixedpoint.Value(0), s.state.Quantity)
+	assert.Equal(t, 5.0, s.state.AccumulatedFundingFee.Float64())
+}
+
+func TestStrategy_submitLegs_revert(t *testing.T) {
+	exchange := &testFuturesExchange{
+		index: types.PremiumIndex{
+			Symbol:          "BTCUSDT",
+			MarkPrice:       fixedpoint.NewFromFloat(40000.0),
+			LastFundingRate: fixedpoint.NewFromFloat(0.0005),
+		},
+	}
+
+	s := newTestStrategy(exchange, 0, 80000.0)
+	router := &testRouter{
+		orders: map[string][]types.SubmitOrder{},
+		errs:   map[string]error{"binance-futures": errors.New("margin is insufficient")},
+	}
+
+	s.check(context.Background(), router)
+
+	// the spot leg is reverted
+	if assert.Len(t, router.orders["binance"], 2) {
+		assert.Equal(t, types.SideTypeBuy, router.orders["binance"][0].Side)
+		assert.Equal(t, types.SideTypeSell, router.orders["binance"][1].Side)
+		assert.Equal(t, 1.0, router.orders["binance"][1].Quantity)
+	}
+	assert.Equal(t, fixedpoint.Value(0), s.state.Quantity)
+	assert.Equal(t, 0, s.state.NumOfEntries)
+}
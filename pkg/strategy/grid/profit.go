@@ -0,0 +1,79 @@
+package grid
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// GridProfit is the arbitrage profit of one grid, the grid is identified by the buy price of the round trip
+type GridProfit struct {
+	Price      fixedpoint.Value `json:"price"`
+	Currency   string           `json:"currency"`
+	Profit     fixedpoint.Value `json:"profit"`
+	RoundTrips int              `json:"roundTrips"`
+	UpdatedAt  time.Time        `json:"updatedAt"`
+}
+
+// arbitrageProfit calculates the profit of the round trip between the filled order and the paired order,
+// the profit is in the base currency for the long mode since the long mode buys more quantity back,
+// otherwise the profit is in the quote currency. The grid price is the buy price of the round trip.
+func arbitrageProfit(market types.Market, long bool, filledOrder, pairedOrder types.Order) (gridPrice, profit float64, currency string) {
+	buyOrder, sellOrder := pairedOrder, filledOrder
+	if filledOrder.Side == types.SideTypeBuy {
+		buyOrder, sellOrder = filledOrder, pairedOrder
+	}
+
+	if long {
+		return buyOrder.Price, buyOrder.Quantity - sellOrder.Quantity, market.BaseCurrency
+	}
+
+	return buyOrder.Price, sellOrder.Quantity*sellOrder.Price - buyOrder.Quantity*buyOrder.Price, market.QuoteCurrency
+}
+
+// AddGridProfit adds the round trip profit to the grid and the accumulative arbitrage profit
+func (s *State) AddGridProfit(gridPrice fixedpoint.Value, profit fixedpoint.Value, currency string, now time.Time) *GridProfit {
+	if s.GridProfits == nil {
+		s.GridProfits = make(map[fixedpoint.Value]*GridProfit)
+	}
+
+	gp, ok := s.GridProfits[gridPrice]
+	if !ok {
+		gp = &GridProfit{Price: gridPrice, Currency: currency}
+		s.GridProfits[gridPrice] = gp
+	}
+
+	gp.Profit += profit
+	gp.RoundTrips++
+	gp.UpdatedAt = now
+
+	s.AccumulativeArbitrageProfit += profit
+	return gp
+}
+
+// GridProfitReport returns the profit of each grid ordered by the grid price
+func (s *State) GridProfitReport(symbol string) string {
+	var prices []fixedpoint.Value
+	for price := range s.GridProfits {
+		prices = append(prices, price)
+	}
+
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i] < prices[j]
+	})
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s grid profits:\n", symbol))
+	for _, price := range prices {
+		gp := s.GridProfits[price]
+		sb.WriteString(fmt.Sprintf("grid %f: %f %s in %d round trips\n",
+			gp.Price.Float64(), gp.Profit.Float64(), gp.Currency, gp.RoundTrips))
+	}
+
+	sb.WriteString(fmt.Sprintf("accumulative arbitrage profit: %f", s.AccumulativeArbitrageProfit.Float64()))
+	return sb.String()
+}
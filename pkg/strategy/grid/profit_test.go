@@ -0,0 +1,81 @@
+package grid
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func newOrder(side types.SideType, price, quantity float64) types.Order {
+	return types.Order{
+		SubmitOrder: types.SubmitOrder{
+			Symbol:   "BTCUSDT",
+			Side:     side,
+			Price:    price,
+			Quantity: quantity,
+		},
+	}
+}
+
+func Test_arbitrageProfit(t *testing.T) {
+	market := types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"}
+
+	// the sell order is filled against the buy order
+	gridPrice, profit, currency := arbitrageProfit(market, false,
+		newOrder(types.SideTypeSell, 110.0, 1.0),
+		newOrder(types.SideTypeBuy, 100.0, 1.0))
+	assert.Equal(t, 100.0, gridPrice)
+	assert.Equal(t, 10.0, profit)
+	assert.Equal(t, "USDT", currency)
+
+	// the buy order is filled against the sell order
+	gridPrice, profit, currency = arbitrageProfit(market, false,
+		newOrder(types.SideTypeBuy, 100.0, 1.0),
+		newOrder(types.SideTypeSell, 110.0, 1.0))
+	assert.Equal(t, 100.0, gridPrice)
+	assert.Equal(t, 10.0, profit)
+	assert.Equal(t, "USDT", currency)
+
+	// the long mode buys more quantity back with the same amount
+	gridPrice, profit, currency = arbitrageProfit(market, true,
+		newOrder(types.SideTypeBuy, 100.0, 1.1),
+		newOrder(types.SideTypeSell, 110.0, 1.0))
+	assert.Equal(t, 100.0, gridPrice)
+	assert.InDelta(t, 0.1, profit, 1e-9)
+	assert.Equal(t, "BTC", currency)
+}
+
+func TestState_AddGridProfit(t *testing.T) {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	state := &State{}
+
+	state.AddGridProfit(fixedpoint.NewFromFloat(100.0), fixedpoint.NewFromFloat(10.0), "USDT", now)
+	gp := state.AddGridProfit(fixedpoint.NewFromFloat(100.0), fixedpoint.NewFromFloat(5.0), "USDT", now)
+	state.AddGridProfit(fixedpoint.NewFromFloat(90.0), fixedpoint.NewFromFloat(8.0), "USDT", now)
+
+	assert.Equal(t, 15.0, gp.Profit.Float64())
+	assert.Equal(t, 2, gp.RoundTrips)
+	assert.Equal(t, 23.0, state.AccumulativeArbitrageProfit.Float64())
+	assert.Len(t, state.GridProfits, 2)
+
+	assert.Equal(t, "BTCUSDT grid profits:\n"+
+		"grid 90.000000: 8.000000 USDT in 1 round trips\n"+
+		"grid 100.000000: 15.000000 USDT in 2 round trips\n"+
+		"accumulative arbitrage profit: 23.000000", state.GridProfitReport("BTCUSDT"))
+
+	// the grid profits are restored from the persisted state
+	data, err := json.Marshal(state)
+	assert.NoError(t, err)
+
+	var restored State
+	assert.NoError(t, json.Unmarshal(data, &restored))
+	if assert.Contains(t, restored.GridProfits, fixedpoint.NewFromFloat(100.0)) {
+		assert.Equal(t, 15.0, restored.GridProfits[fixedpoint.NewFromFloat(100.0)].Profit.Float64())
+		assert.Equal(t, 2, restored.GridProfits[fixedpoint.NewFromFloat(100.0)].RoundTrips)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -35,6 +36,9 @@ type State struct {
 
 	AccumulativeArbitrageProfit fixedpoint.Value `json:"accumulativeArbitrageProfit"`
 
+	// GridProfits is the arbitrage profit of each grid, [grid buy price] -> profit
+	GridProfits map[fixedpoint.Value]*GridProfit `json:"gridProfits,omitempty"`
+
 	// any created orders for tracking trades
 	// [source Order ID] -> arbitrage order
 	ArbitrageOrders map[uint64]types.Order `json:"arbitrageOrders"`
@@ -89,6 +93,10 @@ type Strategy struct {
 	// CatchUp let the maker grid catch up with the price change.
 	CatchUp bool `json:"catchUp" yaml:"catchUp"`
 
+	// ReconcileInterval is the interval of reconciling the active orders with the exchange open orders,
+	// so that the orders filled while the user data stream was disconnected are still handled. disabled if zero.
+	ReconcileInterval types.Duration `json:"reconcileInterval,omitempty" yaml:"reconcileInterval,omitempty"`
+
 	// Long means you want to hold more base asset than the quote asset.
 	Long bool `json:"long,omitempty" yaml:"long,omitempty"`
 
@@ -327,6 +335,7 @@ func (s *Strategy) placeGridSellOrders(orderExecutor bbgo.OrderExecutor, session
 	log.Infof("submitting %d sell orders...", len(orderForms))
 	createdOrders, err := orderExecutor.SubmitOrders(context.Background(), orderForms...)
 	s.activeOrders.Add(createdOrders...)
+	s.orderStore.Add(createdOrders...)
 	return err
 }
 
@@ -344,6 +353,7 @@ func (s *Strategy) placeGridBuyOrders(orderExecutor bbgo.OrderExecutor, session
 	log.Infof("submitting %d buy orders...", len(orderForms))
 	createdOrders, err := orderExecutor.SubmitOrders(context.Background(), orderForms...)
 	s.activeOrders.Add(createdOrders...)
+	s.orderStore.Add(createdOrders...)
 
 	return err
 }
@@ -440,57 +450,45 @@ func (s *Strategy) handleFilledOrder(filledOrder types.Order) {
 
 	// calculate arbitrage profit
 	// TODO: apply fee rate here
-	if s.Long {
-		switch filledOrder.Side {
-		case types.SideTypeSell:
-			if buyOrder, ok := s.state.ArbitrageOrders[filledOrder.OrderID]; ok {
-				// use base asset quantity here
-				baseProfit := buyOrder.Quantity - filledOrder.Quantity
-				s.state.AccumulativeArbitrageProfit += fixedpoint.NewFromFloat(baseProfit)
-				s.Notify("%s grid arbitrage profit %f %s, accumulative arbitrage profit %f %s",
-					s.Symbol,
-					baseProfit, s.Market.BaseCurrency,
-					s.state.AccumulativeArbitrageProfit.Float64(), s.Market.BaseCurrency,
-				)
-			}
+	if pairedOrder, ok := s.state.ArbitrageOrders[filledOrder.OrderID]; ok {
+		gridPrice, profit, currency := arbitrageProfit(s.Market, s.Long, filledOrder, pairedOrder)
+		gridProfit := s.state.AddGridProfit(fixedpoint.NewFromFloat(gridPrice), fixedpoint.NewFromFloat(profit), currency, time.Now())
+		delete(s.state.ArbitrageOrders, filledOrder.OrderID)
 
-		case types.SideTypeBuy:
-			if sellOrder, ok := s.state.ArbitrageOrders[filledOrder.OrderID]; ok {
-				// use base asset quantity here
-				baseProfit := filledOrder.Quantity - sellOrder.Quantity
-				s.state.AccumulativeArbitrageProfit += fixedpoint.NewFromFloat(baseProfit)
-				s.Notify("%s grid arbitrage profit %f %s, accumulative arbitrage profit %f %s",
-					s.Symbol,
-					baseProfit, s.Market.BaseCurrency,
-					s.state.AccumulativeArbitrageProfit.Float64(), s.Market.BaseCurrency,
-				)
-			}
-		}
-	} else if !s.Long && s.Quantity > 0 {
-		switch filledOrder.Side {
-		case types.SideTypeSell:
-			if buyOrder, ok := s.state.ArbitrageOrders[filledOrder.OrderID]; ok {
-				// use base asset quantity here
-				quoteProfit := (filledOrder.Quantity * filledOrder.Price) - (buyOrder.Quantity * buyOrder.Price)
-				s.state.AccumulativeArbitrageProfit += fixedpoint.NewFromFloat(quoteProfit)
-				s.Notify("%s grid arbitrage profit %f %s, accumulative arbitrage profit %f %s",
-					s.Symbol,
-					quoteProfit, s.Market.QuoteCurrency,
-					s.state.AccumulativeArbitrageProfit.Float64(), s.Market.QuoteCurrency,
-				)
-			}
-		case types.SideTypeBuy:
-			if sellOrder, ok := s.state.ArbitrageOrders[filledOrder.OrderID]; ok {
-				// use base asset quantity here
-				quoteProfit := (sellOrder.Quantity * sellOrder.Price) - (filledOrder.Quantity * filledOrder.Price)
-				s.state.AccumulativeArbitrageProfit += fixedpoint.NewFromFloat(quoteProfit)
-				s.Notify("%s grid arbitrage profit %f %s, accumulative arbitrage profit %f %s", s.Symbol,
-					quoteProfit, s.Market.QuoteCurrency,
-					s.state.AccumulativeArbitrageProfit.Float64(), s.Market.QuoteCurrency,
-				)
-			}
+		s.Notify("%s grid %f arbitrage profit %f %s, grid profit %f %s in %d round trips, accumulative arbitrage profit %f %s",
+			s.Symbol,
+			gridPrice,
+			profit, currency,
+			gridProfit.Profit.Float64(), currency, gridProfit.RoundTrips,
+			s.state.AccumulativeArbitrageProfit.Float64(), currency,
+		)
+	}
+
+	// save the state on every fill, so that the grid can be restored after the process crashed
+	if err := s.SaveState(); err != nil {
+		log.WithError(err).Error("can not save grid state")
+	}
+}
+
+func (s *Strategy) cancelPreviousGridOrders(ctx context.Context, session *bbgo.ExchangeSession) error {
+	openOrders, err := session.Exchange.QueryOpenOrders(ctx, s.Symbol)
+	if err != nil {
+		return err
+	}
+
+	var previousOrders []types.Order
+	for _, o := range openOrders {
+		if o.GroupID == s.groupID {
+			previousOrders = append(previousOrders, o)
 		}
 	}
+
+	if len(previousOrders) == 0 {
+		return nil
+	}
+
+	log.Infof("canceling %d previous grid orders...", len(previousOrders))
+	return session.Exchange.CancelOrders(ctx, previousOrders...)
 }
 
 func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
@@ -583,6 +581,10 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 	s.activeOrders.OnFilled(s.handleFilledOrder)
 	s.activeOrders.BindStream(session.UserDataStream)
 
+	if s.ReconcileInterval > 0 {
+		go s.activeOrders.RunReconciliation(ctx, session.Exchange, s.ReconcileInterval.Duration())
+	}
+
 	s.tradeCollector = bbgo.NewTradeCollector(s.Symbol, s.state.Position, s.orderStore)
 
 	s.tradeCollector.OnTrade(func(trade types.Trade) {
@@ -614,6 +616,10 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 			s.Notify("%s: %s grid is saved", ID, s.Symbol)
 		}
 
+		if len(s.state.GridProfits) > 0 {
+			s.Notify(s.state.GridProfitReport(s.Symbol))
+		}
+
 		// now we can cancel the open orders
 		log.Infof("canceling active orders...")
 		if err := session.Exchange.CancelOrders(ctx, s.activeOrders.Orders()...); err != nil {
@@ -626,6 +632,11 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 		if len(s.state.Orders) > 0 {
 			s.Notifiability.Notify("restoring %s %d grid orders...", s.Symbol, len(s.state.Orders))
 
+			// the grid orders are left open when the process crashed, cancel them before restoring the grid
+			if err := s.cancelPreviousGridOrders(ctx, session); err != nil {
+				log.WithError(err).Error("previous grid orders cancel error")
+			}
+
 			createdOrders, err := orderExecutor.SubmitOrders(ctx, s.state.Orders...)
 			if err != nil {
 				log.WithError(err).Error("active orders restore error")
@@ -0,0 +1,487 @@
+package meanrevert
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/indicator"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const ID = "meanrevert"
+
+const stateKey = "state-v1"
+
+var log = logrus.WithField("strategy", ID)
+
+func init() {
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+// State is persisted on every position change and on shutdown,
+// so the position and the profit stats survive the restarts.
+type State struct {
+	Position    *types.Position  `json:"position,omitempty"`
+	ProfitStats bbgo.ProfitStats `json:"profitStats,omitempty"`
+}
+
+// Strategy is a long only mean reversion strategy, it is also meant to be the reference implementation
+// of a signal based strategy:
+//
+// - the indicators come from the injected StandardIndicatorSet, they are updated before the strategy
+// receives the closed kline.
+//
+// - the position is tracked from the strategy's own trades by the order store and the trade collector,
+// so the trades of the other strategies on the same symbol are not counted.
+//
+// - the risk limits (the max position and the stop loss) are checked before any order is submitted.
+//
+// - the lifecycle hooks: the strategy stops trading when it's suspended, and flattens the position on shutdown
+// if the ClosePosition option is set.
+//
+// Entry: the RSI is oversold and the close price is below the lower band of the bollinger band.
+// Exit: the close price reverts to the middle band, or the stop loss is hit.
+type Strategy struct {
+	*bbgo.Graceful      `json:"-"`
+	*bbgo.Notifiability `json:"-"`
+	*bbgo.Persistence
+
+	StandardIndicatorSet *bbgo.StandardIndicatorSet `json:"-"`
+	ProfitRecorder       *bbgo.ProfitRecorder       `json:"-"`
+
+	Symbol string       `json:"symbol"`
+	Market types.Market `json:"-"`
+
+	// Interval is the kline interval of the indicators and the signals, defaults to 15m
+	Interval types.Interval `json:"interval,omitempty"`
+
+	// RSIWindow defaults to 14
+	RSIWindow int `json:"rsiWindow,omitempty"`
+
+	// OversoldRSI is the RSI level to enter, defaults to 30
+	OversoldRSI float64 `json:"oversoldRSI,omitempty"`
+
+	// BollingerWindow defaults to 20
+	BollingerWindow int `json:"bollingerWindow,omitempty"`
+
+	// BandWidth is the times of the standard deviation of the bands, defaults to 2.0
+	BandWidth float64 `json:"bandWidth,omitempty"`
+
+	// Quantity is the base quantity of each entry
+	Quantity fixedpoint.Value `json:"quantity"`
+
+	// MaxPosition is the max base position, the strategy scales in until the max position is reached,
+	// defaults to the quantity (only one entry)
+	MaxPosition fixedpoint.Value `json:"maxPosition,omitempty"`
+
+	// StopLossPercentage exits the position when the price drops below the average cost by the percentage,
+	// e.g., 0.05 exits at 5% below the average cost, zero disables the stop loss
+	StopLossPercentage fixedpoint.Value `json:"stopLossPercentage,omitempty"`
+
+	session       *bbgo.ExchangeSession
+	orderExecutor bbgo.OrderExecutor
+
+	state *State
+
+	// suspended stops the entries and the exits until the strategy is resumed
+	suspended bool
+
+	rsi  *indicator.RSI
+	boll *indicator.BOLL
+
+	orderStore     *bbgo.OrderStore
+	tradeCollector *bbgo.TradeCollector
+
+	// mu protects the state and the indicator values during the signal cycle,
+	// so that ReadState can read a consistent snapshot
+	mu              sync.Mutex
+	indicatorValues map[string]float64
+}
+
+func (s *Strategy) ID() string {
+	return ID
+}
+
+func (s *Strategy) Validate() error {
+	if len(s.Symbol) == 0 {
+		return errors.New("symbol is required")
+	}
+
+	if s.Quantity <= 0 {
+		return errors.New("quantity should be greater than zero")
+	}
+
+	if s.MaxPosition < 0 || (s.MaxPosition > 0 && s.MaxPosition < s.Quantity) {
+		return errors.New("maxPosition should be greater than or equal to the quantity")
+	}
+
+	if s.StopLossPercentage < 0 || s.StopLossPercentage >= fixedpoint.NewFromFloat(1.0) {
+		return errors.New("stopLossPercentage should be between 0 and 1")
+	}
+
+	if s.OversoldRSI < 0 || s.OversoldRSI > 100 {
+		return errors.New("oversoldRSI should be between 0 and 100")
+	}
+
+	if s.RSIWindow < 0 || s.BollingerWindow < 0 || s.BandWidth < 0 {
+		return errors.New("rsiWindow, bollingerWindow and bandWidth can not be negative")
+	}
+
+	return nil
+}
+
+func (s *Strategy) setDefaults() {
+	if len(s.Interval) == 0 {
+		s.Interval = types.Interval15m
+	}
+
+	if s.RSIWindow == 0 {
+		s.RSIWindow = 14
+	}
+
+	if s.OversoldRSI == 0 {
+		s.OversoldRSI = 30.0
+	}
+
+	if s.BollingerWindow == 0 {
+		s.BollingerWindow = 20
+	}
+
+	if s.BandWidth == 0 {
+		s.BandWidth = 2.0
+	}
+
+	if s.MaxPosition == 0 {
+		s.MaxPosition = s.Quantity
+	}
+}
+
+func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
+	s.setDefaults()
+	session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: string(s.Interval)})
+}
+
+func (s *Strategy) SaveState() error {
+	if err := s.Persistence.Save(s.state, ID, s.Symbol, stateKey); err != nil {
+		return err
+	}
+
+	log.Infof("state is saved => %+v", s.state)
+	return nil
+}
+
+func (s *Strategy) LoadState() error {
+	var state State
+
+	if err := s.Persistence.Load(&state, ID, s.Symbol, stateKey); err != nil {
+		if err != service.ErrPersistenceNotExists {
+			return err
+		}
+
+		s.state = &State{}
+	} else {
+		s.state = &state
+		log.Infof("state is restored: %+v", s.state)
+	}
+
+	if s.state.Position == nil {
+		s.state.Position = types.NewPositionFromMarket(s.Market)
+	}
+
+	s.state.ProfitStats.Symbol = s.Market.Symbol
+	s.state.ProfitStats.BaseCurrency = s.Market.BaseCurrency
+	s.state.ProfitStats.QuoteCurrency = s.Market.QuoteCurrency
+	if s.state.ProfitStats.AccumulatedSince == 0 {
+		s.state.ProfitStats.AccumulatedSince = time.Now().Unix()
+	}
+
+	return nil
+}
+
+// ReadState implements bbgo.StrategyStateReader
+func (s *Strategy) ReadState() bbgo.StrategyState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var position *types.Position
+	if s.state != nil {
+		position = s.state.Position
+	}
+
+	return bbgo.NewStrategyState(position, nil, s.indicatorValues)
+}
+
+// shouldEnter checks the entry signal: the RSI is oversold and the close price is below the lower band
+func (s *Strategy) shouldEnter(rsi, closePrice, downBand float64) bool {
+	return rsi <= s.OversoldRSI && closePrice < downBand
+}
+
+// shouldExit returns the exit reason of the long position, the empty reason means holding the position
+func (s *Strategy) shouldExit(closePrice, sma, averageCost float64) string {
+	if s.StopLossPercentage > 0 && averageCost > 0 && closePrice <= averageCost*(1.0-s.StopLossPercentage.Float64()) {
+		return "stop loss"
+	}
+
+	if sma > 0 && closePrice >= sma {
+		return "mean reversion"
+	}
+
+	return ""
+}
+
+// entryQuantity returns the quantity of the next entry, the quantity is limited by the max position
+// and the available quote balance, zero means the entry should be skipped.
+func (s *Strategy) entryQuantity(base, price float64) float64 {
+	quantity := math.Min(s.Quantity.Float64(), s.MaxPosition.Float64()-base)
+
+	if b, ok := s.session.Account.Balance(s.Market.QuoteCurrency); ok {
+		quantity = math.Min(quantity, b.Available.Float64()/price)
+	} else {
+		quantity = 0
+	}
+
+	quantity = s.Market.CanonicalizeVolume(quantity)
+	if quantity <= 0 || quantity < s.Market.MinQuantity || quantity*price < s.Market.MinNotional {
+		return 0
+	}
+
+	return quantity
+}
+
+// exitQuantity returns the quantity to exit the position, it's limited by the base balance
+// since the fee might be deducted from the base currency
+func (s *Strategy) exitQuantity(base, price float64) float64 {
+	quantity := base
+	if b, ok := s.session.Account.Balance(s.Market.BaseCurrency); ok {
+		quantity = math.Min(quantity, b.Available.Float64())
+	}
+
+	quantity = s.Market.CanonicalizeVolume(quantity)
+	if quantity < s.Market.MinQuantity || quantity*price < s.Market.MinNotional {
+		return 0
+	}
+
+	return quantity
+}
+
+func (s *Strategy) submitOrder(ctx context.Context, orderExecutor bbgo.OrderExecutor, side types.SideType, quantity float64) error {
+	createdOrders, err := orderExecutor.SubmitOrders(ctx, types.SubmitOrder{
+		Symbol:   s.Symbol,
+		Market:   s.Market,
+		Side:     side,
+		Type:     types.OrderTypeMarket,
+		Quantity: quantity,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.orderStore.Add(createdOrders...)
+	return nil
+}
+
+func (s *Strategy) exit(ctx context.Context, orderExecutor bbgo.OrderExecutor, reason string, quantity, closePrice float64) {
+	if err := s.submitOrder(ctx, orderExecutor, types.SideTypeSell, quantity); err != nil {
+		log.WithError(err).Errorf("can not exit the %s position", s.Symbol)
+		return
+	}
+
+	s.Notify(":chart_with_upwards_trend: %s %s exit: selling %f at the close price %f", s.Symbol, reason, quantity, closePrice)
+}
+
+func (s *Strategy) enter(ctx context.Context, orderExecutor bbgo.OrderExecutor, quantity, closePrice, rsi float64) {
+	if err := s.submitOrder(ctx, orderExecutor, types.SideTypeBuy, quantity); err != nil {
+		log.WithError(err).Errorf("can not enter the %s position", s.Symbol)
+		return
+	}
+
+	s.Notify(":chart_with_downwards_trend: %s entry: buying %f at the close price %f, rsi %.2f", s.Symbol, quantity, closePrice, rsi)
+}
+
+func (s *Strategy) handleKLineClosed(ctx context.Context, orderExecutor bbgo.OrderExecutor, k types.KLine) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the trades of the last orders are processed before the position is checked
+	s.tradeCollector.Process()
+
+	if s.suspended {
+		return
+	}
+
+	// the indicators are not ready until their windows are filled
+	if len(s.rsi.Values) == 0 || len(s.boll.SMA) == 0 {
+		return
+	}
+
+	rsi := s.rsi.Last()
+	sma := s.boll.LastSMA()
+	downBand := s.boll.LastDownBand()
+
+	s.indicatorValues = map[string]float64{
+		"rsi":       rsi,
+		"boll.up":   s.boll.LastUpBand(),
+		"boll.sma":  sma,
+		"boll.down": downBand,
+	}
+
+	position := s.state.Position.Snapshot()
+	base := position.Base.Float64()
+	averageCost := position.AverageCost.Float64()
+
+	if quantity := s.exitQuantity(base, k.Close); quantity > 0 {
+		if reason := s.shouldExit(k.Close, sma, averageCost); len(reason) > 0 {
+			log.Infof("%s %s exit: close price %f, sma %f, average cost %f, position %f",
+				s.Symbol, reason, k.Close, sma, averageCost, base)
+			s.exit(ctx, orderExecutor, reason, quantity, k.Close)
+			return
+		}
+	}
+
+	if !s.shouldEnter(rsi, k.Close, downBand) {
+		return
+	}
+
+	quantity := s.entryQuantity(base, k.Close)
+	if quantity <= 0 {
+		log.Infof("%s entry signal is skipped, the position %f reaches the max position %f or the quote balance is not enough",
+			s.Symbol, base, s.MaxPosition.Float64())
+		return
+	}
+
+	log.Infof("%s entry: rsi %f, close price %f, down band %f", s.Symbol, rsi, k.Close, downBand)
+	s.enter(ctx, orderExecutor, quantity, k.Close, rsi)
+}
+
+// Suspend implements bbgo.StrategySuspender, the strategy only submits the market orders,
+// so there is no working order to cancel
+func (s *Strategy) Suspend(ctx context.Context) error {
+	s.mu.Lock()
+	s.suspended = true
+	s.mu.Unlock()
+
+	s.Notify("%s %s is suspended", ID, s.Symbol)
+	return nil
+}
+
+// Resume implements bbgo.StrategySuspender
+func (s *Strategy) Resume(ctx context.Context) error {
+	s.mu.Lock()
+	s.suspended = false
+	s.mu.Unlock()
+
+	s.Notify("%s %s is resumed", ID, s.Symbol)
+	return nil
+}
+
+// Shutdown implements bbgo.StrategyShutdowner, the position is flattened by a market order if the ClosePosition option is set
+func (s *Strategy) Shutdown(ctx context.Context, options bbgo.ShutdownOptions) error {
+	if !options.ClosePosition {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.suspended = true
+	s.tradeCollector.Process()
+
+	base := s.state.Position.Base.Float64()
+	price, ok := s.session.LastPrice(s.Symbol)
+	if !ok {
+		return errors.Errorf("last price of %s is not found", s.Symbol)
+	}
+
+	quantity := s.exitQuantity(base, price)
+	if quantity <= 0 {
+		return nil
+	}
+
+	log.Infof("closing the %s position %f on shutdown", s.Symbol, base)
+	if err := s.submitOrder(ctx, s.orderExecutor, types.SideTypeSell, quantity); err != nil {
+		return errors.Wrapf(err, "can not close the %s position", s.Symbol)
+	}
+
+	s.Notify("%s %s position %f is closed on shutdown", ID, s.Symbol, quantity)
+	return nil
+}
+
+func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	s.session = session
+	s.orderExecutor = orderExecutor
+	s.setDefaults()
+
+	if err := s.LoadState(); err != nil {
+		return err
+	}
+
+	s.rsi = s.StandardIndicatorSet.RSI(types.IntervalWindow{Interval: s.Interval, Window: s.RSIWindow})
+	s.boll = s.StandardIndicatorSet.BOLL(types.IntervalWindow{Interval: s.Interval, Window: s.BollingerWindow}, s.BandWidth)
+
+	s.orderStore = bbgo.NewOrderStore(s.Symbol)
+	s.orderStore.BindStream(session.UserDataStream)
+
+	s.tradeCollector = bbgo.NewTradeCollector(s.Symbol, s.state.Position, s.orderStore)
+	s.tradeCollector.OnTrade(func(trade types.Trade) {
+		s.Notify(trade)
+		s.state.ProfitStats.AddTrade(trade)
+	})
+
+	s.tradeCollector.OnProfit(func(trade types.Trade, profit fixedpoint.Value, netProfit fixedpoint.Value) {
+		p := bbgo.Profit{
+			Symbol:          s.Symbol,
+			Profit:          profit,
+			NetProfit:       netProfit,
+			TradeAmount:     fixedpoint.NewFromFloat(trade.QuoteQuantity),
+			ProfitMargin:    profit.DivFloat64(trade.QuoteQuantity),
+			NetProfitMargin: netProfit.DivFloat64(trade.QuoteQuantity),
+			QuoteCurrency:   s.state.Position.QuoteCurrency,
+			BaseCurrency:    s.state.Position.BaseCurrency,
+			Time:            trade.Time.Time(),
+		}
+		s.state.ProfitStats.AddProfit(p)
+		s.ProfitRecorder.Record(ID, p)
+		s.Notify(&p)
+		s.Notify(&s.state.ProfitStats)
+	})
+
+	s.tradeCollector.OnPositionUpdate(func(position *types.Position) {
+		log.Infof("position changed: %s", position)
+		s.Notify(position)
+
+		if err := s.SaveState(); err != nil {
+			log.WithError(err).Error("can not save state")
+		}
+	})
+
+	s.tradeCollector.BindStream(session.UserDataStream)
+
+	session.MarketDataStream.OnKLineClosed(func(k types.KLine) {
+		if k.Symbol != s.Symbol || k.Interval != s.Interval {
+			return
+		}
+
+		s.handleKLineClosed(ctx, orderExecutor, k)
+	})
+
+	s.Graceful.OnShutdown(func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if err := s.SaveState(); err != nil {
+			log.WithError(err).Error("can not save state")
+		}
+	})
+
+	return nil
+}
@@ -0,0 +1,255 @@
+package meanrevert
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/strategytest"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var testMarket = types.Market{
+	Symbol:          "BTCUSDT",
+	PricePrecision:  2,
+	VolumePrecision: 4,
+	BaseCurrency:    "BTC",
+	QuoteCurrency:   "USDT",
+	MinNotional:     10.0,
+	MinQuantity:     0.0001,
+	StepSize:        0.0001,
+	TickSize:        0.01,
+}
+
+func TestStrategy_Validate(t *testing.T) {
+	s := &Strategy{Symbol: "BTCUSDT"}
+	assert.Error(t, s.Validate())
+
+	s.Quantity = fixedpoint.NewFromFloat(0.5)
+	assert.NoError(t, s.Validate())
+
+	s.MaxPosition = fixedpoint.NewFromFloat(0.1)
+	assert.Error(t, s.Validate())
+
+	s.MaxPosition = fixedpoint.NewFromFloat(1.0)
+	s.StopLossPercentage = fixedpoint.NewFromFloat(1.0)
+	assert.Error(t, s.Validate())
+
+	s.StopLossPercentage = fixedpoint.NewFromFloat(0.05)
+	s.OversoldRSI = 120.0
+	assert.Error(t, s.Validate())
+}
+
+func TestStrategy_shouldExit(t *testing.T) {
+	s := &Strategy{}
+	assert.Equal(t, "", s.shouldExit(90.0, 95.0, 100.0))
+	assert.Equal(t, "mean reversion", s.shouldExit(95.0, 95.0, 100.0))
+
+	s.StopLossPercentage = fixedpoint.NewFromFloat(0.05)
+	assert.Equal(t, "stop loss", s.shouldExit(90.0, 95.0, 100.0))
+	assert.Equal(t, "", s.shouldExit(96.0, 98.0, 100.0))
+}
+
+func TestStrategy_entryQuantity(t *testing.T) {
+	account := types.NewAccount()
+	account.UpdateBalances(types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(1000.0)},
+	})
+
+	s := &Strategy{
+		Market:      testMarket,
+		Quantity:    fixedpoint.NewFromFloat(5.0),
+		MaxPosition: fixedpoint.NewFromFloat(12.0),
+		session:     &bbgo.ExchangeSession{Account: account},
+	}
+
+	assert.Equal(t, 5.0, s.entryQuantity(0, 100.0))
+
+	// limited by the max position
+	assert.Equal(t, 2.0, s.entryQuantity(10.0, 100.0))
+	assert.Equal(t, 0.0, s.entryQuantity(12.0, 100.0))
+
+	// limited by the quote balance
+	assert.Equal(t, 2.5, s.entryQuantity(0, 400.0))
+
+	// less than the min notional
+	assert.Equal(t, 0.0, s.entryQuantity(11.9999, 100.0))
+}
+
+func newTestHarness() *strategytest.Harness {
+	return strategytest.New(strategytest.Config{
+		Markets: types.MarketMap{"BTCUSDT": testMarket},
+		Balances: types.BalanceMap{
+			"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(10000.0)},
+		},
+	})
+}
+
+func newTestStrategy() *Strategy {
+	return &Strategy{
+		Symbol:          "BTCUSDT",
+		Interval:        types.Interval1m,
+		RSIWindow:       3,
+		BollingerWindow: 5,
+		BandWidth:       1.0,
+		Quantity:        fixedpoint.NewFromFloat(0.5),
+		MaxPosition:     fixedpoint.NewFromFloat(1.0),
+	}
+}
+
+func TestStrategy_MeanReversion(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h := newTestHarness()
+	s := newTestStrategy()
+	if !assert.NoError(t, h.Run(ctx, s)) {
+		return
+	}
+
+	kLines := strategytest.KLinesFromPrices("BTCUSDT", types.Interval1m, strategytest.DefaultStartTime,
+		100.0, 101.0, 100.0, 101.0, 100.0, 90.0, 88.0, 80.0, 95.0, 96.0)
+
+	err := h.Play(
+		strategytest.KLines(kLines[:5]...),
+		strategytest.StepFunc(func(h *strategytest.Harness) error {
+			// the price stays in the bands
+			assert.Len(t, h.SubmittedOrders(), 0)
+			return nil
+		}),
+		// the price drops below the lower band with the oversold RSI, and the strategy scales in
+		strategytest.KLines(kLines[5:7]...),
+		strategytest.StepFunc(func(h *strategytest.Harness) error {
+			orders := h.SubmittedOrders()
+			if assert.Len(t, orders, 2) {
+				assert.Equal(t, types.SideTypeBuy, orders[0].Side)
+				assert.Equal(t, types.OrderTypeMarket, orders[0].Type)
+				assert.Equal(t, 0.5, orders[0].Quantity)
+				assert.Equal(t, types.SideTypeBuy, orders[1].Side)
+			}
+			return nil
+		}),
+		// the entry signal is skipped since the max position is reached
+		strategytest.KLines(kLines[7]),
+		strategytest.StepFunc(func(h *strategytest.Harness) error {
+			assert.Len(t, h.SubmittedOrders(), 2)
+			assert.Equal(t, 1.0, s.state.Position.Base.Float64())
+			assert.InDelta(t, 89.0, s.state.Position.AverageCost.Float64(), 1e-9)
+			return nil
+		}),
+		// the price reverts to the middle band
+		strategytest.KLines(kLines[8:]...),
+	)
+	assert.NoError(t, err)
+
+	orders := h.SubmittedOrders()
+	if assert.Len(t, orders, 3) {
+		assert.Equal(t, types.SideTypeSell, orders[2].Side)
+		assert.Equal(t, 1.0, orders[2].Quantity)
+	}
+
+	var state State
+	assert.NoError(t, h.LoadState(&state, ID, "BTCUSDT", stateKey))
+	if assert.NotNil(t, state.Position) {
+		assert.Equal(t, 0.0, state.Position.Base.Float64())
+	}
+	assert.InDelta(t, 6.0, state.ProfitStats.AccumulatedPnL.Float64(), 1e-9)
+
+	strategyState := s.ReadState()
+	assert.InDelta(t, 89.8, strategyState.Indicators["boll.sma"], 1e-9)
+}
+
+func TestStrategy_StopLoss(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h := newTestHarness()
+	s := newTestStrategy()
+	s.StopLossPercentage = fixedpoint.NewFromFloat(0.05)
+	if !assert.NoError(t, h.Run(ctx, s)) {
+		return
+	}
+
+	kLines := strategytest.KLinesFromPrices("BTCUSDT", types.Interval1m, strategytest.DefaultStartTime,
+		100.0, 101.0, 100.0, 101.0, 100.0, 90.0, 80.0, 85.0)
+
+	// the position is entered at 90 and the close price 80 is below the stop loss price 85.5
+	assert.NoError(t, h.Play(strategytest.KLines(kLines...)))
+
+	orders := h.SubmittedOrders()
+	if assert.Len(t, orders, 2) {
+		assert.Equal(t, types.SideTypeBuy, orders[0].Side)
+		assert.Equal(t, types.SideTypeSell, orders[1].Side)
+		assert.Equal(t, 0.5, orders[1].Quantity)
+	}
+
+	trades := h.Trades()
+	if assert.Len(t, trades, 2) {
+		assert.Equal(t, 90.0, trades[0].Price)
+		assert.Equal(t, 80.0, trades[1].Price)
+	}
+}
+
+func TestStrategy_Suspend(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h := newTestHarness()
+	s := newTestStrategy()
+	if !assert.NoError(t, h.Run(ctx, s)) {
+		return
+	}
+
+	kLines := strategytest.KLinesFromPrices("BTCUSDT", types.Interval1m, strategytest.DefaultStartTime,
+		100.0, 101.0, 100.0, 101.0, 100.0, 90.0, 88.0)
+
+	err := h.Play(
+		strategytest.KLines(kLines[:5]...),
+		strategytest.StepFunc(func(h *strategytest.Harness) error {
+			return s.Suspend(ctx)
+		}),
+		// the entry signal is ignored while the strategy is suspended
+		strategytest.KLines(kLines[5]),
+		strategytest.StepFunc(func(h *strategytest.Harness) error {
+			assert.Len(t, h.SubmittedOrders(), 0)
+			return s.Resume(ctx)
+		}),
+		strategytest.KLines(kLines[6]),
+	)
+	assert.NoError(t, err)
+	assert.Len(t, h.SubmittedOrders(), 1)
+}
+
+func TestStrategy_Shutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h := strategytest.New(strategytest.Config{
+		Markets: types.MarketMap{"BTCUSDT": testMarket},
+		Balances: types.BalanceMap{
+			"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(10000.0)},
+		},
+		ShutdownOptions: bbgo.ShutdownOptions{ClosePosition: true},
+	})
+
+	s := newTestStrategy()
+	if !assert.NoError(t, h.Run(ctx, s)) {
+		return
+	}
+
+	kLines := strategytest.KLinesFromPrices("BTCUSDT", types.Interval1m, strategytest.DefaultStartTime,
+		100.0, 101.0, 100.0, 101.0, 100.0, 90.0, 91.0)
+	assert.NoError(t, h.Play(strategytest.KLines(kLines...)))
+
+	h.Shutdown(ctx)
+
+	orders := h.SubmittedOrders()
+	if assert.Len(t, orders, 2) {
+		assert.Equal(t, types.SideTypeSell, orders[1].Side)
+		assert.Equal(t, types.OrderTypeMarket, orders[1].Type)
+		assert.Equal(t, 0.5, orders[1].Quantity)
+	}
+}
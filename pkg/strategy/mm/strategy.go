@@ -0,0 +1,420 @@
+package mm
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const ID = "mm"
+
+const stateKey = "state-v1"
+
+const defaultUpdateInterval = time.Minute
+
+var log = logrus.WithField("strategy", ID)
+
+func init() {
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+type State struct {
+	Position *types.Position `json:"position,omitempty"`
+}
+
+// Strategy quotes both sides around the mid price of the order book, the quotes are skewed by the inventory:
+// when the position is long, the quotes are shifted down to sell the inventory and buy less, and vice versa.
+type Strategy struct {
+	*bbgo.Graceful      `json:"-"`
+	*bbgo.Notifiability `json:"-"`
+	*bbgo.Persistence
+
+	Symbol string       `json:"symbol"`
+	Market types.Market `json:"-"`
+
+	// Spread is the ratio between the mid price and the first layer, e.g., 0.001 quotes the bid at -0.1% and the ask at +0.1%
+	Spread fixedpoint.Value `json:"spread"`
+
+	// Layers is the number of the orders of each side, defaults to 1
+	Layers int `json:"layers,omitempty"`
+
+	// LayerSpread is the price ratio between the layers
+	LayerSpread fixedpoint.Value `json:"layerSpread,omitempty"`
+
+	// Quantity is the order quantity of each layer
+	Quantity fixedpoint.Value `json:"quantity"`
+
+	// MaxInventory is the position (in base currency) that the skew reaches the maximum,
+	// the side that increases the position is not quoted beyond it. Zero disables the inventory skew.
+	MaxInventory fixedpoint.Value `json:"maxInventory,omitempty"`
+
+	// SkewRatio is the price ratio that the quotes are shifted at the max inventory, e.g., 0.002
+	SkewRatio fixedpoint.Value `json:"skewRatio,omitempty"`
+
+	// RequoteThreshold is the mid price change ratio that requotes on the book updates,
+	// zero requotes on every book update.
+	RequoteThreshold fixedpoint.Value `json:"requoteThreshold,omitempty"`
+
+	// UpdateInterval requotes periodically even if the book does not move, defaults to 1m
+	UpdateInterval types.Duration `json:"updateInterval,omitempty"`
+
+	session *bbgo.ExchangeSession
+	book    *types.StreamOrderBook
+
+	state *State
+
+	activeMakerOrders *bbgo.LocalActiveOrderBook
+	orderStore        *bbgo.OrderStore
+	tradeCollector    *bbgo.TradeCollector
+
+	// mu protects the requote cycle
+	mu sync.Mutex
+
+	// lastMidPrice is the mid price of the last quotes
+	lastMidPrice float64
+
+	// positionChanged forces the next requote since the inventory skew is changed,
+	// it's set by the trade collector which might run in the requote cycle, so it's not protected by mu.
+	positionChanged int32
+}
+
+func (s *Strategy) ID() string {
+	return ID
+}
+
+func (s *Strategy) Validate() error {
+	if len(s.Symbol) == 0 {
+		return errors.New("symbol is required")
+	}
+
+	if s.Spread <= 0 {
+		return errors.New("spread should be greater than zero")
+	}
+
+	if s.Quantity <= 0 {
+		return errors.New("quantity should be greater than zero")
+	}
+
+	if s.Layers < 0 {
+		return errors.New("layers can not be negative")
+	}
+
+	if s.MaxInventory < 0 || s.SkewRatio < 0 {
+		return errors.New("maxInventory and skewRatio can not be negative")
+	}
+
+	if s.SkewRatio >= s.Spread && s.MaxInventory > 0 {
+		return errors.New("skewRatio should be less than the spread, or the quotes cross the mid price")
+	}
+
+	return nil
+}
+
+// Reload applies the quoting parameters of the reloaded config, the quotes are replaced on the next requote.
+// The update interval can not be changed without restarting.
+func (s *Strategy) Reload(ctx context.Context, strategy interface{}) error {
+	next, ok := strategy.(*Strategy)
+	if !ok {
+		return fmt.Errorf("unexpected strategy type %T", strategy)
+	}
+
+	if err := next.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Spread = next.Spread
+	s.Layers = next.Layers
+	s.LayerSpread = next.LayerSpread
+	s.Quantity = next.Quantity
+	s.MaxInventory = next.MaxInventory
+	s.SkewRatio = next.SkewRatio
+	s.RequoteThreshold = next.RequoteThreshold
+
+	// force the next requote
+	s.lastMidPrice = 0
+
+	log.Infof("%s parameters are reloaded: spread %f, layers %d, quantity %f",
+		s.Symbol, s.Spread.Float64(), s.Layers, s.Quantity.Float64())
+	return nil
+}
+
+func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
+	session.Subscribe(types.BookChannel, s.Symbol, types.SubscribeOptions{})
+}
+
+func (s *Strategy) SaveState() error {
+	if err := s.Persistence.Save(s.state, ID, s.Symbol, stateKey); err != nil {
+		return err
+	}
+
+	log.Infof("state is saved => %+v", s.state)
+	return nil
+}
+
+func (s *Strategy) LoadState() error {
+	var state State
+
+	if err := s.Persistence.Load(&state, ID, s.Symbol, stateKey); err != nil {
+		if err != service.ErrPersistenceNotExists {
+			return err
+		}
+
+		s.state = &State{}
+	} else {
+		s.state = &state
+		log.Infof("state is restored: %+v", s.state)
+	}
+
+	if s.state.Position == nil {
+		s.state.Position = types.NewPositionFromMarket(s.Market)
+	}
+
+	return nil
+}
+
+// inventorySkew returns the position ratio to the max inventory, it's between -1 and 1
+func (s *Strategy) inventorySkew(base fixedpoint.Value) float64 {
+	if s.MaxInventory <= 0 {
+		return 0
+	}
+
+	return math.Max(-1.0, math.Min(1.0, base.Float64()/s.MaxInventory.Float64()))
+}
+
+// quotes generates the layered orders around the reservation price, the reservation price is the mid price shifted
+// by the inventory skew. The quotes never cross the best bid and the best ask.
+func (s *Strategy) quotes(bestBid, bestAsk float64, base fixedpoint.Value) (orders []types.SubmitOrder) {
+	mid := (bestBid + bestAsk) / 2.0
+	skew := s.inventorySkew(base)
+	reservation := mid * (1.0 - skew*s.SkewRatio.Float64())
+
+	layers := s.Layers
+	if layers == 0 {
+		layers = 1
+	}
+
+	tick := s.Market.TickSize
+	for i := 0; i < layers; i++ {
+		spread := s.Spread.Float64() + float64(i)*s.LayerSpread.Float64()
+
+		// stop increasing the position beyond the max inventory
+		if skew < 1.0 {
+			bidPrice := math.Min(reservation*(1.0-spread), bestAsk-tick)
+			orders = append(orders, s.newOrder(types.SideTypeBuy, bidPrice))
+		}
+
+		if skew > -1.0 {
+			askPrice := math.Max(reservation*(1.0+spread), bestBid+tick)
+			orders = append(orders, s.newOrder(types.SideTypeSell, askPrice))
+		}
+	}
+
+	return orders
+}
+
+func (s *Strategy) newOrder(side types.SideType, price float64) types.SubmitOrder {
+	return types.SubmitOrder{
+		Symbol:   s.Symbol,
+		Market:   s.Market,
+		Side:     side,
+		Type:     types.OrderTypeLimitMaker,
+		Price:    price,
+		Quantity: s.Quantity.Float64(),
+	}
+}
+
+// affordable filters the orders by the available balances
+func (s *Strategy) affordable(orders []types.SubmitOrder) (affordableOrders []types.SubmitOrder) {
+	var quoteAvailable, baseAvailable float64
+	if b, ok := s.session.Account.Balance(s.Market.QuoteCurrency); ok {
+		quoteAvailable = b.Available.Float64()
+	}
+
+	if b, ok := s.session.Account.Balance(s.Market.BaseCurrency); ok {
+		baseAvailable = b.Available.Float64()
+	}
+
+	for _, o := range orders {
+		switch o.Side {
+		case types.SideTypeBuy:
+			if quoteAvailable < o.Price*o.Quantity {
+				continue
+			}
+			quoteAvailable -= o.Price * o.Quantity
+
+		case types.SideTypeSell:
+			if baseAvailable < o.Quantity {
+				continue
+			}
+			baseAvailable -= o.Quantity
+		}
+
+		affordableOrders = append(affordableOrders, o)
+	}
+
+	return affordableOrders
+}
+
+// shouldRequote checks if the mid price moves more than the requote threshold or the position is changed
+func (s *Strategy) shouldRequote(mid float64) bool {
+	if atomic.LoadInt32(&s.positionChanged) == 1 || s.lastMidPrice == 0 || s.activeMakerOrders.NumOfOrders() == 0 {
+		return true
+	}
+
+	return math.Abs(mid-s.lastMidPrice)/s.lastMidPrice >= s.RequoteThreshold.Float64()
+}
+
+// requote cancels the active maker orders and places the new quotes
+func (s *Strategy) requote(ctx context.Context, orderExecutor bbgo.OrderExecutor, force bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bid, ask, ok := s.book.BestBidAndAsk()
+	if !ok {
+		return
+	}
+
+	bestBid, bestAsk := bid.Price.Float64(), ask.Price.Float64()
+	mid := (bestBid + bestAsk) / 2.0
+	if !force && !s.shouldRequote(mid) {
+		return
+	}
+
+	if err := s.cancelOrders(ctx); err != nil {
+		log.WithError(err).Errorf("can not cancel %s orders", s.Symbol)
+		return
+	}
+
+	s.tradeCollector.Process()
+
+	orders := s.affordable(s.quotes(bestBid, bestAsk, s.state.Position.Base))
+	s.lastMidPrice = mid
+	atomic.StoreInt32(&s.positionChanged, 0)
+
+	if len(orders) == 0 {
+		log.Warnf("%s no affordable quotes", s.Symbol)
+		return
+	}
+
+	createdOrders, err := orderExecutor.SubmitOrders(ctx, orders...)
+	if err != nil {
+		log.WithError(err).Errorf("can not place %s quotes", s.Symbol)
+	}
+
+	s.orderStore.Add(createdOrders...)
+	s.activeMakerOrders.Add(createdOrders...)
+}
+
+func (s *Strategy) cancelOrders(ctx context.Context) error {
+	orders := s.activeMakerOrders.Orders()
+	if len(orders) == 0 {
+		return nil
+	}
+
+	if err := s.session.Exchange.CancelOrders(ctx, orders...); err != nil {
+		return err
+	}
+
+	// the order updates of the canceled orders might not arrive yet
+	for _, o := range orders {
+		s.activeMakerOrders.Remove(o)
+	}
+
+	return nil
+}
+
+func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	s.session = session
+
+	book, ok := session.OrderBook(s.Symbol)
+	if !ok {
+		return fmt.Errorf("order book of %s is not found, is the book channel subscribed?", s.Symbol)
+	}
+	s.book = book
+
+	if s.UpdateInterval == 0 {
+		s.UpdateInterval = types.Duration(defaultUpdateInterval)
+	}
+
+	if err := s.LoadState(); err != nil {
+		return err
+	}
+
+	s.activeMakerOrders = bbgo.NewLocalActiveOrderBook()
+	s.activeMakerOrders.BindStream(session.UserDataStream)
+
+	s.orderStore = bbgo.NewOrderStore(s.Symbol)
+	s.orderStore.BindStream(session.UserDataStream)
+
+	s.tradeCollector = bbgo.NewTradeCollector(s.Symbol, s.state.Position, s.orderStore)
+	s.tradeCollector.OnTrade(func(trade types.Trade) {
+		s.Notifiability.Notify(trade)
+	})
+	s.tradeCollector.OnPositionUpdate(func(position *types.Position) {
+		log.Infof("%s position changed: %s", s.Symbol, position)
+		atomic.StoreInt32(&s.positionChanged, 1)
+
+		if err := s.SaveState(); err != nil {
+			log.WithError(err).Error("can not save state")
+		}
+	})
+	s.tradeCollector.BindStream(session.UserDataStream)
+
+	// the stream order book is bound before the strategies run, so the book is already updated in these callbacks,
+	// the position changes are requoted on the next book update or the next update interval.
+	onBook := func(book types.SliceOrderBook) {
+		if book.Symbol != s.Symbol {
+			return
+		}
+
+		s.requote(ctx, orderExecutor, false)
+	}
+	session.MarketDataStream.OnBookSnapshot(onBook)
+	session.MarketDataStream.OnBookUpdate(onBook)
+
+	go func() {
+		ticker := time.NewTicker(s.UpdateInterval.Duration())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-ticker.C:
+				s.requote(ctx, orderExecutor, true)
+			}
+		}
+	}()
+
+	s.Graceful.OnShutdown(func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if err := s.cancelOrders(ctx); err != nil {
+			log.WithError(err).Errorf("can not cancel %s orders", s.Symbol)
+		}
+
+		if err := s.SaveState(); err != nil {
+			log.WithError(err).Error("can not save state")
+		}
+	})
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package mm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/strategytest"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var testMarket = types.Market{
+	Symbol:          "BTCUSDT",
+	PricePrecision:  2,
+	VolumePrecision: 4,
+	BaseCurrency:    "BTC",
+	QuoteCurrency:   "USDT",
+	MinNotional:     1.0,
+	MinQuantity:     0.0001,
+	StepSize:        0.0001,
+	TickSize:        0.01,
+}
+
+func newTestStrategy() *Strategy {
+	return &Strategy{
+		Symbol:       "BTCUSDT",
+		Market:       testMarket,
+		Spread:       fixedpoint.NewFromFloat(0.01),
+		Layers:       2,
+		LayerSpread:  fixedpoint.NewFromFloat(0.01),
+		Quantity:     fixedpoint.NewFromFloat(0.1),
+		MaxInventory: fixedpoint.NewFromFloat(0.2),
+		SkewRatio:    fixedpoint.NewFromFloat(0.005),
+	}
+}
+
+func pricesOf(orders []types.SubmitOrder, side types.SideType) (prices []float64) {
+	for _, o := range orders {
+		if o.Side == side {
+			prices = append(prices, o.Price)
+		}
+	}
+
+	return prices
+}
+
+func TestStrategy_Validate(t *testing.T) {
+	s := newTestStrategy()
+	assert.NoError(t, s.Validate())
+
+	s.SkewRatio = fixedpoint.NewFromFloat(0.01)
+	assert.Error(t, s.Validate())
+
+	s.MaxInventory = 0
+	assert.NoError(t, s.Validate())
+
+	s.Quantity = 0
+	assert.Error(t, s.Validate())
+}
+
+func TestStrategy_quotes(t *testing.T) {
+	s := newTestStrategy()
+
+	assert.Equal(t, 0.5, s.inventorySkew(fixedpoint.NewFromFloat(0.1)))
+	assert.Equal(t, -1.0, s.inventorySkew(fixedpoint.NewFromFloat(-0.5)))
+
+	// no inventory, the quotes are symmetric around the mid price
+	orders := s.quotes(99.0, 101.0, 0)
+	assert.InDeltaSlice(t, []float64{99.0, 98.0}, pricesOf(orders, types.SideTypeBuy), 1e-9)
+	assert.InDeltaSlice(t, []float64{101.0, 102.0}, pricesOf(orders, types.SideTypeSell), 1e-9)
+
+	// long inventory shifts the quotes down
+	orders = s.quotes(99.0, 101.0, fixedpoint.NewFromFloat(0.1))
+	assert.InDeltaSlice(t, []float64{98.7525, 97.755}, pricesOf(orders, types.SideTypeBuy), 1e-9)
+	assert.InDeltaSlice(t, []float64{100.7475, 101.745}, pricesOf(orders, types.SideTypeSell), 1e-9)
+
+	// the bids are not quoted at the max inventory
+	orders = s.quotes(99.0, 101.0, fixedpoint.NewFromFloat(0.3))
+	assert.Len(t, pricesOf(orders, types.SideTypeBuy), 0)
+	assert.Len(t, pricesOf(orders, types.SideTypeSell), 2)
+
+	// the quotes never cross the book
+	s.Spread = fixedpoint.NewFromFloat(0.0001)
+	s.LayerSpread = 0
+	s.Layers = 1
+	orders = s.quotes(100.0, 100.02, fixedpoint.NewFromFloat(-0.2))
+	assert.InDeltaSlice(t, []float64{100.01}, pricesOf(orders, types.SideTypeBuy), 1e-9)
+}
+
+func TestStrategy_Scenario(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h := strategytest.New(strategytest.Config{
+		Markets: types.MarketMap{"BTCUSDT": testMarket},
+		Balances: types.BalanceMap{
+			"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(10000.0)},
+			"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(1.0)},
+		},
+	})
+
+	s := newTestStrategy()
+	s.RequoteThreshold = fixedpoint.NewFromFloat(0.001)
+	if !assert.NoError(t, h.Run(ctx, s)) {
+		return
+	}
+
+	openPrices := func(side types.SideType) (prices []float64) {
+		for _, o := range h.OpenOrders() {
+			if o.Side == side {
+				prices = append(prices, o.Price)
+			}
+		}
+		return prices
+	}
+
+	err := h.Play(
+		strategytest.Book(strategytest.NewBook("BTCUSDT", [][2]float64{{99.0, 1.0}}, [][2]float64{{101.0, 1.0}})),
+		strategytest.StepFunc(func(h *strategytest.Harness) error {
+			assert.Equal(t, []float64{99.0, 98.0}, openPrices(types.SideTypeBuy))
+			assert.Equal(t, []float64{101.0, 102.0}, openPrices(types.SideTypeSell))
+			return nil
+		}),
+
+		// the mid price does not move enough, the quotes are kept
+		strategytest.Book(strategytest.NewBook("BTCUSDT", [][2]float64{{99.05, 1.0}}, [][2]float64{{101.0, 1.0}})),
+		strategytest.StepFunc(func(h *strategytest.Harness) error {
+			assert.Len(t, h.SubmittedOrders(), 4)
+			return nil
+		}),
+
+		// the price drops and the first bid is filled, the quotes are skewed down by the long position
+		strategytest.Book(strategytest.NewBook("BTCUSDT", [][2]float64{{98.5, 1.0}}, [][2]float64{{98.9, 1.0}})),
+		strategytest.StepFunc(func(h *strategytest.Harness) error {
+			assert.Len(t, h.Trades(), 1)
+			assert.Equal(t, 0.1, s.state.Position.Base.Float64())
+
+			asks := openPrices(types.SideTypeSell)
+			if assert.Len(t, asks, 2) {
+				// the unskewed ask would be 98.7 * 1.01
+				assert.True(t, asks[0] < 98.7*1.01)
+			}
+			return nil
+		}),
+	)
+	assert.NoError(t, err)
+
+	// fill the bids to exceed the max inventory, then the bids are not quoted
+	for _, o := range h.OpenOrders() {
+		if o.Side == types.SideTypeBuy {
+			assert.NoError(t, h.FillOrder(o.OrderID, 0, 0))
+		}
+	}
+
+	assert.NoError(t, h.Play(strategytest.Book(strategytest.NewBook("BTCUSDT", [][2]float64{{97.0, 1.0}}, [][2]float64{{97.2, 1.0}}))))
+	assert.InDelta(t, 0.3, s.state.Position.Base.Float64(), 1e-9)
+	assert.Len(t, openPrices(types.SideTypeBuy), 0)
+	assert.Len(t, openPrices(types.SideTypeSell), 2)
+
+	h.Shutdown(ctx)
+	assert.Len(t, h.OpenOrders(), 0)
+}
+
+func TestStrategy_Reload(t *testing.T) {
+	s := newTestStrategy()
+	s.lastMidPrice = 100.0
+
+	next := newTestStrategy()
+	next.Spread = fixedpoint.NewFromFloat(0.02)
+	next.Layers = 1
+	next.Quantity = fixedpoint.NewFromFloat(0.05)
+	assert.NoError(t, s.Reload(context.Background(), next))
+
+	assert.Equal(t, 0.02, s.Spread.Float64())
+	assert.Equal(t, 1, s.Layers)
+	assert.Equal(t, 0.0, s.lastMidPrice, "the next book update requotes")
+
+	orders := s.quotes(99.0, 101.0, 0)
+	if assert.Len(t, orders, 2) {
+		assert.InDelta(t, 98.0, orders[0].Price, 1e-9)
+		assert.Equal(t, 0.05, orders[0].Quantity)
+	}
+
+	// the invalid parameters are not applied
+	invalid := newTestStrategy()
+	invalid.Quantity = 0
+	assert.Error(t, s.Reload(context.Background(), invalid))
+	assert.Equal(t, 0.05, s.Quantity.Float64())
+
+	assert.Error(t, s.Reload(context.Background(), &struct{}{}))
+}
@@ -1,9 +1,12 @@
-package kline
+package rebalance
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
 	"github.com/c9s/bbgo/pkg/bbgo"
@@ -14,6 +17,8 @@ import (
 
 const ID = "rebalance"
 
+const defaultInterval = time.Hour
+
 var log = logrus.WithField("strategy", ID)
 
 func init() {
@@ -49,26 +54,100 @@ func ElementwiseProduct(m1, m2 map[string]fixedpoint.Value) map[string]fixedpoin
 	return m
 }
 
+// Strategy keeps the portfolio at the target weights, e.g., 50% BTC / 30% ETH / 20% USDT,
+// the weight drift is checked on every interval and the currencies that drift out of the threshold band
+// are traded against the base currency to restore the target weights.
 type Strategy struct {
 	Notifiability *bbgo.Notifiability
 
-	Interval     types.Duration              `json:"interval"`
-	BaseCurrency string                      `json:"baseCurrency"`
-	Weights      map[string]fixedpoint.Value `json:"weights"`
-	Threshold    fixedpoint.Value            `json:"threshold"`
-	IgnoreLocked bool                        `json:"ignoreLocked"`
-	Verbose      bool                        `json:"verbose"`
+	// Interval is the interval of checking the weight drift, defaults to 1h
+	Interval types.Duration `json:"interval"`
+
+	// BaseCurrency is the quote currency of the rebalance markets, e.g., USDT rebalances BTC by BTCUSDT
+	BaseCurrency string `json:"baseCurrency"`
+
+	// Weights is the target weights of the currencies, the weights are normalized, so they don't need to sum up to 1
+	Weights map[string]fixedpoint.Value `json:"weights"`
+
+	// Threshold is the tolerance band of the weight drift, the currency is rebalanced when |target - current| >= threshold
+	Threshold fixedpoint.Value `json:"threshold"`
+
+	// IgnoreLocked only counts the available balances, the balances locked by the open orders are ignored
+	IgnoreLocked bool `json:"ignoreLocked"`
+
+	// Verbose notifies the current weights and the rebalance orders
+	Verbose bool `json:"verbose"`
+
+	markets map[string]types.Market
 }
 
 func (s *Strategy) ID() string {
 	return ID
 }
 
-func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {}
+func (s *Strategy) Validate() error {
+	if len(s.BaseCurrency) == 0 {
+		return errors.New("baseCurrency is required")
+	}
+
+	if len(s.Weights) == 0 {
+		return errors.New("weights are required")
+	}
+
+	for currency, weight := range s.Weights {
+		if weight < 0 {
+			return fmt.Errorf("weight of %s can not be negative", currency)
+		}
+	}
+
+	if Sum(s.Weights) == 0 {
+		return errors.New("the sum of the weights should be greater than zero")
+	}
+
+	if s.Threshold < 0 || s.Threshold >= fixedpoint.NewFromFloat(1.0) {
+		return errors.New("threshold should be between 0 and 1")
+	}
+
+	return nil
+}
+
+// symbols returns the rebalance markets of the non-base currencies
+func (s *Strategy) symbols() map[string]string {
+	symbols := make(map[string]string)
+	for currency := range s.Weights {
+		if currency != s.BaseCurrency {
+			symbols[currency] = currency + s.BaseCurrency
+		}
+	}
+	return symbols
+}
+
+// Subscribe subscribes the 1m klines to keep the last prices of the rebalance markets updated
+func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
+	for _, symbol := range s.symbols() {
+		session.Subscribe(types.KLineChannel, symbol, types.SubscribeOptions{Interval: string(types.Interval1m)})
+	}
+}
 
 func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
 	s.Weights = Normalize(s.Weights)
 
+	if s.Interval == 0 {
+		s.Interval = types.Duration(defaultInterval)
+	}
+
+	s.markets = make(map[string]types.Market)
+	for currency, symbol := range s.symbols() {
+		market, ok := session.Market(symbol)
+		if !ok {
+			return fmt.Errorf("market %s of %s is not found", symbol, currency)
+		}
+
+		s.markets[currency] = market
+	}
+
+	s.rebalance(ctx, orderExecutor, session)
+
 	go func() {
 		ticker := time.NewTicker(util.MillisecondsJitter(s.Interval.Duration(), 1000))
 		defer ticker.Stop()
@@ -98,12 +177,46 @@ func (s *Strategy) rebalance(ctx context.Context, orderExecutor bbgo.OrderExecut
 	marketValues := ElementwiseProduct(prices, quantities)
 
 	orders := s.generateSubmitOrders(prices, marketValues)
+
+	if s.Verbose {
+		s.Notifiability.Notify("current weights: %s, total value: %f %s, %d rebalance orders",
+			formatWeights(Normalize(marketValues)), Sum(marketValues).Float64(), s.BaseCurrency, len(orders))
+
+		for i := range orders {
+			s.Notifiability.Notify(&orders[i])
+		}
+	}
+
+	if len(orders) == 0 {
+		return
+	}
+
 	_, err = orderExecutor.SubmitOrders(ctx, orders...)
 	if err != nil {
+		log.WithError(err).Error("submit rebalance orders error")
 		return
 	}
 }
 
+func formatWeights(weights map[string]fixedpoint.Value) string {
+	var currencies []string
+	for currency := range weights {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	var s string
+	for i, currency := range currencies {
+		if i > 0 {
+			s += ", "
+		}
+		s += currency + " " + weights[currency].Percentage()
+	}
+	return s
+}
+
+// getPrices returns the prices in the base currency, the last prices of the session are used
+// and the tickers are queried for the markets without the last price
 func (s *Strategy) getPrices(ctx context.Context, session *bbgo.ExchangeSession) (map[string]fixedpoint.Value, error) {
 	prices := make(map[string]fixedpoint.Value)
 
@@ -114,6 +227,11 @@ func (s *Strategy) getPrices(ctx context.Context, session *bbgo.ExchangeSession)
 		}
 
 		symbol := currency + s.BaseCurrency
+		if price, ok := session.LastPrice(symbol); ok && price > 0 {
+			prices[currency] = fixedpoint.NewFromFloat(price)
+			continue
+		}
+
 		ticker, err := session.Exchange.QueryTicker(ctx, symbol)
 		if err != nil {
 			s.Notifiability.Notify("query ticker error: %s", err.Error())
@@ -130,22 +248,30 @@ func (s *Strategy) getQuantities(balances types.BalanceMap) map[string]fixedpoin
 	quantities := make(map[string]fixedpoint.Value)
 	for currency := range s.Weights {
 		if s.IgnoreLocked {
-			quantities[currency] = balances[currency].Total()
-		} else {
 			quantities[currency] = balances[currency].Available
+		} else {
+			quantities[currency] = balances[currency].Total()
 		}
 	}
 	return quantities
 }
 
+// generateSubmitOrders generates the market orders of the currencies that drift out of the threshold band,
+// the orders smaller than the market limits are skipped, and the sell orders are placed before the buy orders,
+// so that the base currency of the sell orders can be used by the buy orders.
 func (s *Strategy) generateSubmitOrders(prices, marketValues map[string]fixedpoint.Value) []types.SubmitOrder {
 	var submitOrders []types.SubmitOrder
 
-	currentWeights := Normalize(marketValues)
 	totalValue := Sum(marketValues)
 
 	log.Infof("total value: %f", totalValue.Float64())
 
+	if totalValue <= 0 {
+		return nil
+	}
+
+	currentWeights := Normalize(marketValues)
+
 	for currency, target := range s.Weights {
 		if currency == s.BaseCurrency {
 			continue
@@ -167,13 +293,31 @@ func (s *Strategy) generateSubmitOrders(prices, marketValues map[string]fixedpoi
 			quantity = quantity.Abs()
 		}
 
+		market := s.markets[currency]
+		q := market.CanonicalizeVolume(quantity.Float64())
+		if q < market.MinQuantity || q*price.Float64() < market.MinNotional {
+			log.Infof("%s rebalance quantity %f is less than the market limits, skip", symbol, q)
+			continue
+		}
+
+		log.Infof("%s weight %s -> %s, %s %f", currency, weight.Percentage(), target.Percentage(), side, q)
+
 		order := types.SubmitOrder{
 			Symbol:   symbol,
+			Market:   market,
 			Side:     side,
 			Type:     types.OrderTypeMarket,
-			Quantity: quantity.Float64()}
+			Quantity: q}
 
 		submitOrders = append(submitOrders, order)
 	}
+
+	sort.Slice(submitOrders, func(i, j int) bool {
+		if submitOrders[i].Side != submitOrders[j].Side {
+			return submitOrders[i].Side == types.SideTypeSell
+		}
+		return submitOrders[i].Symbol < submitOrders[j].Symbol
+	})
+
 	return submitOrders
 }
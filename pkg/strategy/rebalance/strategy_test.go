@@ -0,0 +1,180 @@
+package rebalance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/strategytest"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var testMarkets = types.MarketMap{
+	"BTCUSDT": {
+		Symbol:          "BTCUSDT",
+		PricePrecision:  2,
+		VolumePrecision: 4,
+		BaseCurrency:    "BTC",
+		QuoteCurrency:   "USDT",
+		MinNotional:     10.0,
+		MinQuantity:     0.0001,
+		StepSize:        0.0001,
+		TickSize:        0.01,
+	},
+	"ETHUSDT": {
+		Symbol:          "ETHUSDT",
+		PricePrecision:  2,
+		VolumePrecision: 4,
+		BaseCurrency:    "ETH",
+		QuoteCurrency:   "USDT",
+		MinNotional:     10.0,
+		MinQuantity:     0.0001,
+		StepSize:        0.0001,
+		TickSize:        0.01,
+	},
+}
+
+func newTestStrategy() *Strategy {
+	return &Strategy{
+		BaseCurrency: "USDT",
+		Weights: map[string]fixedpoint.Value{
+			"BTC":  fixedpoint.NewFromFloat(0.5),
+			"ETH":  fixedpoint.NewFromFloat(0.3),
+			"USDT": fixedpoint.NewFromFloat(0.2),
+		},
+		Threshold: fixedpoint.NewFromFloat(0.02),
+	}
+}
+
+func TestStrategy_Validate(t *testing.T) {
+	s := newTestStrategy()
+	assert.NoError(t, s.Validate())
+
+	s.Weights["ETH"] = fixedpoint.NewFromFloat(-0.1)
+	assert.Error(t, s.Validate())
+
+	s = newTestStrategy()
+	s.Threshold = fixedpoint.NewFromFloat(1.0)
+	assert.Error(t, s.Validate())
+
+	s = newTestStrategy()
+	s.BaseCurrency = ""
+	assert.Error(t, s.Validate())
+}
+
+func TestStrategy_generateSubmitOrders(t *testing.T) {
+	s := newTestStrategy()
+	s.markets = map[string]types.Market{"BTC": testMarkets["BTCUSDT"], "ETH": testMarkets["ETHUSDT"]}
+
+	prices := map[string]fixedpoint.Value{
+		"BTC":  fixedpoint.NewFromFloat(100.0),
+		"ETH":  fixedpoint.NewFromFloat(10.0),
+		"USDT": fixedpoint.NewFromFloat(1.0),
+	}
+
+	// BTC 55%, ETH 26%, USDT 19%: BTC and ETH are out of the band
+	orders := s.generateSubmitOrders(prices, map[string]fixedpoint.Value{
+		"BTC":  fixedpoint.NewFromFloat(5500.0),
+		"ETH":  fixedpoint.NewFromFloat(2600.0),
+		"USDT": fixedpoint.NewFromFloat(1900.0),
+	})
+	if assert.Len(t, orders, 2) {
+		// the sell order goes first
+		assert.Equal(t, "BTCUSDT", orders[0].Symbol)
+		assert.Equal(t, types.SideTypeSell, orders[0].Side)
+		assert.InDelta(t, 5.0, orders[0].Quantity, 1e-4)
+
+		assert.Equal(t, "ETHUSDT", orders[1].Symbol)
+		assert.Equal(t, types.SideTypeBuy, orders[1].Side)
+		assert.InDelta(t, 40.0, orders[1].Quantity, 1e-4)
+	}
+
+	// the drifts are within the band
+	orders = s.generateSubmitOrders(prices, map[string]fixedpoint.Value{
+		"BTC":  fixedpoint.NewFromFloat(5100.0),
+		"ETH":  fixedpoint.NewFromFloat(2900.0),
+		"USDT": fixedpoint.NewFromFloat(2000.0),
+	})
+	assert.Len(t, orders, 0)
+
+	// the orders smaller than the min notional are skipped
+	s.Threshold = 0
+	orders = s.generateSubmitOrders(prices, map[string]fixedpoint.Value{
+		"BTC":  fixedpoint.NewFromFloat(5005.0),
+		"ETH":  fixedpoint.NewFromFloat(2995.0),
+		"USDT": fixedpoint.NewFromFloat(2000.0),
+	})
+	assert.Len(t, orders, 0)
+
+	assert.Len(t, s.generateSubmitOrders(prices, map[string]fixedpoint.Value{}), 0)
+}
+
+func TestStrategy_Scenario(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	start := strategytest.DefaultStartTime
+	history := append(
+		strategytest.KLinesFromPrices("BTCUSDT", types.Interval1m, start.Add(-time.Minute), 100.0),
+		strategytest.KLinesFromPrices("ETHUSDT", types.Interval1m, start.Add(-time.Minute), 10.0)...,
+	)
+
+	h := strategytest.New(strategytest.Config{
+		Markets: testMarkets,
+		Balances: types.BalanceMap{
+			"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(10000.0)},
+		},
+		History: history,
+	})
+
+	s := newTestStrategy()
+	s.Interval = types.Duration(24 * time.Hour)
+	if !assert.NoError(t, h.Run(ctx, s)) {
+		return
+	}
+
+	// the portfolio is rebalanced at start
+	orders := h.SubmittedOrders()
+	if assert.Len(t, orders, 2) {
+		// the submitted orders are formatted by the executor, only the order fields are compared
+		assert.Equal(t, "BTCUSDT", orders[0].Symbol)
+		assert.Equal(t, types.SideTypeBuy, orders[0].Side)
+		assert.Equal(t, types.OrderTypeMarket, orders[0].Type)
+		assert.Equal(t, 50.0, orders[0].Quantity)
+
+		assert.Equal(t, "ETHUSDT", orders[1].Symbol)
+		assert.Equal(t, types.SideTypeBuy, orders[1].Side)
+		assert.Equal(t, types.OrderTypeMarket, orders[1].Type)
+		assert.Equal(t, 300.0, orders[1].Quantity)
+	}
+
+	err := h.Play(
+		strategytest.KLines(strategytest.KLinesFromPrices("BTCUSDT", types.Interval1m, start, 100.0, 120.0)...),
+		strategytest.KLines(strategytest.KLinesFromPrices("ETHUSDT", types.Interval1m, start, 10.0, 10.0)...),
+		strategytest.StepFunc(func(h *strategytest.Harness) error {
+			balances := h.Balances()
+			assert.Equal(t, 50.0, balances["BTC"].Available.Float64())
+			assert.Equal(t, 300.0, balances["ETH"].Available.Float64())
+			assert.Equal(t, 2000.0, balances["USDT"].Available.Float64())
+
+			// BTC rises to 120, the portfolio drifts to BTC 54.5%, ETH 27.3%, USDT 18.2%
+			s.rebalance(ctx, h.Session().OrderExecutor, h.Session())
+			return nil
+		}),
+	)
+	assert.NoError(t, err)
+
+	orders = h.SubmittedOrders()
+	if assert.Len(t, orders, 4) {
+		assert.Equal(t, "BTCUSDT", orders[2].Symbol)
+		assert.Equal(t, types.SideTypeSell, orders[2].Side)
+		assert.InDelta(t, 4.1666, orders[2].Quantity, 1e-4)
+
+		assert.Equal(t, "ETHUSDT", orders[3].Symbol)
+		assert.Equal(t, types.SideTypeBuy, orders[3].Side)
+		assert.InDelta(t, 30.0, orders[3].Quantity, 1e-3)
+	}
+}
@@ -0,0 +1,396 @@
+package trailingstop
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const ID = "trailingstop"
+
+const stateKey = "state-v1"
+
+const (
+	OrderTypeMarket = "market"
+	OrderTypeStop   = "stop"
+)
+
+var log = logrus.WithField("strategy", ID)
+
+func init() {
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+type State struct {
+	// Side is the side of the guarded position, buy for the long position and sell for the short position
+	Side types.SideType `json:"side,omitempty"`
+
+	// Peak is the highest price since the long position is opened, or the lowest price since the short position is opened
+	Peak fixedpoint.Value `json:"peak,omitempty"`
+}
+
+// Strategy is a protective strategy that can be attached alongside the other strategies of the same symbol,
+// it watches the session position (the position of all the trades of the symbol) and exits the position when
+// the price draws down from the peak by the trailing percentage or drops below the average cost by the stop loss percentage.
+type Strategy struct {
+	*bbgo.Graceful      `json:"-"`
+	*bbgo.Notifiability `json:"-"`
+	*bbgo.Persistence
+
+	Symbol string       `json:"symbol"`
+	Market types.Market `json:"-"`
+
+	// Interval is the kline interval to check the stop price, defaults to 1m
+	Interval types.Interval `json:"interval,omitempty"`
+
+	// TrailingPercentage is the drawdown from the peak to exit, e.g., 0.05 exits the long position when
+	// the price drops 5% from the highest price since the position is opened
+	TrailingPercentage fixedpoint.Value `json:"trailingPercentage,omitempty"`
+
+	// StopLossPercentage is the loss from the average cost of the position to exit, e.g., 0.1
+	StopLossPercentage fixedpoint.Value `json:"stopLossPercentage,omitempty"`
+
+	// OrderType is "market" or "stop", defaults to "market".
+	// market: the position is exited by a market order when the close price reaches the stop price.
+	// stop: a stop market order is kept on the exchange and updated when the peak moves.
+	OrderType string `json:"orderType,omitempty"`
+
+	session  *bbgo.ExchangeSession
+	position *types.Position
+
+	state *State
+
+	// activeStopOrders is the working stop order placed in the stop order type
+	activeStopOrders *bbgo.LocalActiveOrderBook
+
+	mu sync.Mutex
+}
+
+func (s *Strategy) ID() string {
+	return ID
+}
+
+func (s *Strategy) Validate() error {
+	if len(s.Symbol) == 0 {
+		return errors.New("symbol is required")
+	}
+
+	if s.TrailingPercentage == 0 && s.StopLossPercentage == 0 {
+		return errors.New("either trailingPercentage or stopLossPercentage is required")
+	}
+
+	if s.TrailingPercentage < 0 || s.TrailingPercentage >= fixedpoint.NewFromFloat(1.0) {
+		return errors.New("trailingPercentage should be between 0 and 1")
+	}
+
+	if s.StopLossPercentage < 0 || s.StopLossPercentage >= fixedpoint.NewFromFloat(1.0) {
+		return errors.New("stopLossPercentage should be between 0 and 1")
+	}
+
+	switch strings.ToLower(s.OrderType) {
+	case "", OrderTypeMarket, OrderTypeStop:
+	default:
+		return errors.Errorf("unsupported order type %s, valid types are: market, stop", s.OrderType)
+	}
+
+	return nil
+}
+
+func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
+	if len(s.Interval) == 0 {
+		s.Interval = types.Interval1m
+	}
+
+	session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: string(s.Interval)})
+}
+
+func (s *Strategy) SaveState() error {
+	if err := s.Persistence.Save(s.state, ID, s.Symbol, stateKey); err != nil {
+		return err
+	}
+
+	log.Infof("state is saved => %+v", s.state)
+	return nil
+}
+
+func (s *Strategy) LoadState() error {
+	var state State
+
+	if err := s.Persistence.Load(&state, ID, s.Symbol, stateKey); err != nil {
+		if err != service.ErrPersistenceNotExists {
+			return err
+		}
+
+		s.state = &State{}
+	} else {
+		s.state = &state
+		log.Infof("state is restored: %+v", s.state)
+	}
+
+	return nil
+}
+
+// isDust checks if the position is too small to exit
+func (s *Strategy) isDust(base, price float64) bool {
+	quantity := math.Abs(base)
+	return quantity == 0 || quantity < s.Market.MinQuantity || quantity*price < s.Market.MinNotional
+}
+
+// stopPrice returns the tighter one of the trailing stop price and the stop loss price
+func (s *Strategy) stopPrice(side types.SideType, peak, averageCost float64) (float64, bool) {
+	var stopPrices []float64
+
+	if s.TrailingPercentage > 0 && peak > 0 {
+		if side == types.SideTypeBuy {
+			stopPrices = append(stopPrices, peak*(1.0-s.TrailingPercentage.Float64()))
+		} else {
+			stopPrices = append(stopPrices, peak*(1.0+s.TrailingPercentage.Float64()))
+		}
+	}
+
+	if s.StopLossPercentage > 0 && averageCost > 0 {
+		if side == types.SideTypeBuy {
+			stopPrices = append(stopPrices, averageCost*(1.0-s.StopLossPercentage.Float64()))
+		} else {
+			stopPrices = append(stopPrices, averageCost*(1.0+s.StopLossPercentage.Float64()))
+		}
+	}
+
+	if len(stopPrices) == 0 {
+		return 0, false
+	}
+
+	stopPrice := stopPrices[0]
+	for _, p := range stopPrices[1:] {
+		if side == types.SideTypeBuy {
+			stopPrice = math.Max(stopPrice, p)
+		} else {
+			stopPrice = math.Min(stopPrice, p)
+		}
+	}
+
+	return stopPrice, true
+}
+
+// updatePeak updates the peak price by the kline range, the peak is reset when the position side is changed
+func (s *Strategy) updatePeak(side types.SideType, k types.KLine) bool {
+	peak := s.state.Peak.Float64()
+	if s.state.Side != side {
+		s.state.Side = side
+		peak = 0
+	}
+
+	newPeak := peak
+	if side == types.SideTypeBuy {
+		newPeak = math.Max(peak, k.High)
+	} else if peak == 0 || k.Low < peak {
+		newPeak = k.Low
+	}
+
+	if newPeak == peak && s.state.Peak != 0 {
+		return false
+	}
+
+	s.state.Peak = fixedpoint.NewFromFloat(newPeak)
+	return true
+}
+
+func (s *Strategy) check(ctx context.Context, orderExecutor bbgo.OrderExecutor, k types.KLine) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	position := s.position.Snapshot()
+	base := position.Base.Float64()
+
+	if s.isDust(base, k.Close) {
+		if s.state.Side != "" || s.activeStopOrders.NumOfOrders() > 0 {
+			s.cancelStopOrders(ctx)
+			s.resetState()
+		}
+		return
+	}
+
+	side := types.SideTypeBuy
+	if base < 0 {
+		side = types.SideTypeSell
+	}
+
+	if s.updatePeak(side, k) {
+		if err := s.SaveState(); err != nil {
+			log.WithError(err).Error("can not save state")
+		}
+	}
+
+	stopPrice, ok := s.stopPrice(side, s.state.Peak.Float64(), position.AverageCost.Float64())
+	if !ok {
+		return
+	}
+
+	triggered := (side == types.SideTypeBuy && k.Close <= stopPrice) || (side == types.SideTypeSell && k.Close >= stopPrice)
+	if triggered {
+		log.Warnf("%s close price %f reaches the stop price %f, peak %f, average cost %f, exiting the position %f",
+			s.Symbol, k.Close, stopPrice, s.state.Peak.Float64(), position.AverageCost.Float64(), base)
+		s.exit(ctx, orderExecutor, side, base, stopPrice)
+		return
+	}
+
+	if strings.ToLower(s.OrderType) == OrderTypeStop {
+		s.updateStopOrder(ctx, orderExecutor, side, base, stopPrice)
+	}
+}
+
+// exitQuantity returns the quantity to exit the position, the long position in the spot account
+// is limited by the base balance, the canceled quantity is added since the balance update might not arrive yet
+func (s *Strategy) exitQuantity(side types.SideType, base, canceledQuantity float64) float64 {
+	quantity := math.Abs(base)
+	if side == types.SideTypeBuy {
+		if b, ok := s.session.Account.Balance(s.Market.BaseCurrency); ok {
+			quantity = math.Min(quantity, b.Available.Float64()+canceledQuantity)
+		}
+	}
+
+	return s.Market.CanonicalizeVolume(quantity)
+}
+
+func (s *Strategy) exit(ctx context.Context, orderExecutor bbgo.OrderExecutor, side types.SideType, base, stopPrice float64) {
+	canceledQuantity := s.cancelStopOrders(ctx)
+
+	quantity := s.exitQuantity(side, base, canceledQuantity)
+	if quantity <= 0 {
+		log.Errorf("%s no balance to exit the position %f", s.Symbol, base)
+		return
+	}
+
+	_, err := orderExecutor.SubmitOrders(ctx, types.SubmitOrder{
+		Symbol:   s.Symbol,
+		Market:   s.Market,
+		Side:     side.Reverse(),
+		Type:     types.OrderTypeMarket,
+		Quantity: quantity,
+	})
+	if err != nil {
+		log.WithError(err).Errorf("can not exit the %s position", s.Symbol)
+		return
+	}
+
+	s.Notify(":octagonal_sign: %s position %f is exited at the stop price %f", s.Symbol, base, stopPrice)
+	s.resetState()
+}
+
+// updateStopOrder places the stop order or moves it to the new stop price, the stop price of the long position
+// only moves up and the stop price of the short position only moves down, unless the position size is changed.
+func (s *Strategy) updateStopOrder(ctx context.Context, orderExecutor bbgo.OrderExecutor, side types.SideType, base, stopPrice float64) {
+	if orders := s.activeStopOrders.Orders(); len(orders) > 0 {
+		order := orders[0]
+		remaining := order.Quantity - order.ExecutedQuantity
+		moved := (side == types.SideTypeBuy && stopPrice-order.StopPrice >= s.Market.TickSize) ||
+			(side == types.SideTypeSell && order.StopPrice-stopPrice >= s.Market.TickSize)
+
+		if !moved && remaining == s.exitQuantity(side, base, remaining) {
+			return
+		}
+	}
+
+	canceledQuantity := s.cancelStopOrders(ctx)
+	quantity := s.exitQuantity(side, base, canceledQuantity)
+	if quantity <= 0 {
+		return
+	}
+
+	createdOrders, err := orderExecutor.SubmitOrders(ctx, types.SubmitOrder{
+		Symbol:    s.Symbol,
+		Market:    s.Market,
+		Side:      side.Reverse(),
+		Type:      types.OrderTypeStopMarket,
+		StopPrice: stopPrice,
+		Quantity:  quantity,
+	})
+	if err != nil {
+		log.WithError(err).Errorf("can not place the %s stop order", s.Symbol)
+		return
+	}
+
+	s.activeStopOrders.Add(createdOrders...)
+}
+
+// cancelStopOrders cancels the working stop orders and returns the canceled remaining quantity
+func (s *Strategy) cancelStopOrders(ctx context.Context) (canceledQuantity float64) {
+	orders := s.activeStopOrders.Orders()
+	if len(orders) == 0 {
+		return 0
+	}
+
+	if err := s.session.Exchange.CancelOrders(ctx, orders...); err != nil {
+		log.WithError(err).Errorf("can not cancel the %s stop orders", s.Symbol)
+		return 0
+	}
+
+	for _, o := range orders {
+		canceledQuantity += o.Quantity - o.ExecutedQuantity
+		s.activeStopOrders.Remove(o)
+	}
+
+	return canceledQuantity
+}
+
+func (s *Strategy) resetState() {
+	s.state.Side = ""
+	s.state.Peak = 0
+
+	if err := s.SaveState(); err != nil {
+		log.WithError(err).Error("can not save state")
+	}
+}
+
+func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	s.session = session
+
+	if len(s.Interval) == 0 {
+		s.Interval = types.Interval1m
+	}
+
+	position, ok := session.Position(s.Symbol)
+	if !ok {
+		return errors.Errorf("position of %s is not found", s.Symbol)
+	}
+	s.position = position
+
+	if err := s.LoadState(); err != nil {
+		return err
+	}
+
+	s.activeStopOrders = bbgo.NewLocalActiveOrderBook()
+	s.activeStopOrders.BindStream(session.UserDataStream)
+
+	session.MarketDataStream.OnKLineClosed(func(k types.KLine) {
+		if k.Symbol != s.Symbol || k.Interval != s.Interval {
+			return
+		}
+
+		s.check(ctx, orderExecutor, k)
+	})
+
+	s.Graceful.OnShutdown(func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.cancelStopOrders(ctx)
+
+		if err := s.SaveState(); err != nil {
+			log.WithError(err).Error("can not save state")
+		}
+	})
+
+	return nil
+}
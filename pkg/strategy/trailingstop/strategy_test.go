@@ -0,0 +1,183 @@
+package trailingstop
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/strategytest"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var testMarket = types.Market{
+	Symbol:          "BTCUSDT",
+	PricePrecision:  2,
+	VolumePrecision: 4,
+	BaseCurrency:    "BTC",
+	QuoteCurrency:   "USDT",
+	MinNotional:     1.0,
+	MinQuantity:     0.0001,
+	StepSize:        0.0001,
+	TickSize:        0.01,
+}
+
+func TestStrategy_Validate(t *testing.T) {
+	s := &Strategy{Symbol: "BTCUSDT"}
+	assert.Error(t, s.Validate())
+
+	s.TrailingPercentage = fixedpoint.NewFromFloat(0.05)
+	assert.NoError(t, s.Validate())
+
+	s.StopLossPercentage = fixedpoint.NewFromFloat(1.0)
+	assert.Error(t, s.Validate())
+
+	s.StopLossPercentage = fixedpoint.NewFromFloat(0.1)
+	s.OrderType = "limit"
+	assert.Error(t, s.Validate())
+}
+
+func TestStrategy_stopPrice(t *testing.T) {
+	s := &Strategy{
+		TrailingPercentage: fixedpoint.NewFromFloat(0.05),
+		StopLossPercentage: fixedpoint.NewFromFloat(0.1),
+	}
+
+	// the tighter stop price is used
+	stopPrice, ok := s.stopPrice(types.SideTypeBuy, 100.0, 100.0)
+	assert.True(t, ok)
+	assert.InDelta(t, 95.0, stopPrice, 1e-9)
+
+	stopPrice, _ = s.stopPrice(types.SideTypeBuy, 100.0, 120.0)
+	assert.InDelta(t, 108.0, stopPrice, 1e-9)
+
+	stopPrice, _ = s.stopPrice(types.SideTypeSell, 100.0, 100.0)
+	assert.InDelta(t, 105.0, stopPrice, 1e-9)
+
+	s.TrailingPercentage = 0
+	_, ok = s.stopPrice(types.SideTypeBuy, 100.0, 0)
+	assert.False(t, ok)
+}
+
+func newTestHarness() *strategytest.Harness {
+	return strategytest.New(strategytest.Config{
+		Markets: types.MarketMap{"BTCUSDT": testMarket},
+		Balances: types.BalanceMap{
+			"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(10000.0)},
+		},
+	})
+}
+
+// openPosition submits the market buy order which is filled by the first kline
+func openPosition(ctx context.Context, h *strategytest.Harness) error {
+	_, err := h.Exchange().SubmitOrders(ctx, types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeMarket,
+		Quantity: 1.0,
+	})
+	return err
+}
+
+func TestStrategy_MarketExit(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h := newTestHarness()
+	s := &Strategy{
+		Symbol:             "BTCUSDT",
+		TrailingPercentage: fixedpoint.NewFromFloat(0.05),
+	}
+	if !assert.NoError(t, h.Run(ctx, s)) || !assert.NoError(t, openPosition(ctx, h)) {
+		return
+	}
+
+	kLines := strategytest.KLinesFromPrices("BTCUSDT", types.Interval1m, strategytest.DefaultStartTime, 100.0, 110.0, 120.0, 115.0, 113.0, 112.0)
+	err := h.Play(
+		strategytest.KLines(kLines[:4]...),
+		strategytest.StepFunc(func(h *strategytest.Harness) error {
+			// the peak is 120, the stop price is 114
+			assert.Equal(t, 120.0, s.state.Peak.Float64())
+			assert.Len(t, h.SubmittedOrders(), 1)
+			return nil
+		}),
+		strategytest.KLines(kLines[4]),
+		strategytest.StepFunc(func(h *strategytest.Harness) error {
+			orders := h.SubmittedOrders()
+			if assert.Len(t, orders, 2) {
+				assert.Equal(t, types.SideTypeSell, orders[1].Side)
+				assert.Equal(t, types.OrderTypeMarket, orders[1].Type)
+				assert.Equal(t, 1.0, orders[1].Quantity)
+			}
+			return nil
+		}),
+		// the market order is filled at the open price of the next kline
+		strategytest.KLines(kLines[5]),
+	)
+	assert.NoError(t, err)
+
+	trades := h.Trades()
+	if assert.Len(t, trades, 2) {
+		assert.Equal(t, 113.0, trades[1].Price)
+	}
+
+	position, _ := h.Session().Position("BTCUSDT")
+	assert.Equal(t, 0.0, position.Base.Float64())
+
+	var state State
+	assert.NoError(t, h.LoadState(&state, ID, "BTCUSDT", stateKey))
+	assert.Equal(t, fixedpoint.Value(0), state.Peak)
+}
+
+func TestStrategy_StopOrder(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h := newTestHarness()
+	s := &Strategy{
+		Symbol:             "BTCUSDT",
+		TrailingPercentage: fixedpoint.NewFromFloat(0.05),
+		StopLossPercentage: fixedpoint.NewFromFloat(0.1),
+		OrderType:          OrderTypeStop,
+	}
+	if !assert.NoError(t, h.Run(ctx, s)) || !assert.NoError(t, openPosition(ctx, h)) {
+		return
+	}
+
+	stopPrices := func() (prices []float64) {
+		for _, o := range h.SubmittedOrders() {
+			if o.Type == types.OrderTypeStopMarket {
+				prices = append(prices, o.StopPrice)
+			}
+		}
+		return prices
+	}
+
+	kLines := strategytest.KLinesFromPrices("BTCUSDT", types.Interval1m, strategytest.DefaultStartTime, 100.0, 110.0, 120.0, 116.0, 110.0)
+	err := h.Play(
+		strategytest.KLines(kLines[:4]...),
+		strategytest.StepFunc(func(h *strategytest.Harness) error {
+			// the stop order follows the peak and stays when the price goes down
+			assert.InDeltaSlice(t, []float64{95.0, 104.5, 114.0}, stopPrices(), 1e-9)
+
+			openOrders := h.OpenOrders()
+			if assert.Len(t, openOrders, 1) {
+				assert.InDelta(t, 114.0, openOrders[0].StopPrice, 1e-9)
+			}
+			return nil
+		}),
+		// the stop order is triggered by the low price of the kline
+		strategytest.KLines(kLines[4]),
+	)
+	assert.NoError(t, err)
+
+	trades := h.Trades()
+	if assert.Len(t, trades, 2) {
+		assert.InDelta(t, 114.0, trades[1].Price, 1e-9)
+	}
+
+	assert.Len(t, h.OpenOrders(), 0)
+	assert.Len(t, stopPrices(), 3)
+	assert.Equal(t, types.SideType(""), s.state.Side)
+}
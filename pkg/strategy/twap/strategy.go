@@ -0,0 +1,153 @@
+package twap
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const ID = "twap"
+
+var log = logrus.WithField("strategy", ID)
+
+func init() {
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+// Strategy executes the target quantity by the time-sliced maker orders,
+// it's a standalone wrapper of the bbgo.TwapExecution.
+type Strategy struct {
+	*bbgo.Notifiability `json:"-"`
+	*bbgo.Graceful      `json:"-"`
+
+	Symbol string         `json:"symbol"`
+	Side   types.SideType `json:"side"`
+
+	// TargetQuantity is the total quantity to execute
+	TargetQuantity fixedpoint.Value `json:"targetQuantity"`
+
+	// SliceQuantity is the max quantity of each child order
+	SliceQuantity fixedpoint.Value `json:"sliceQuantity"`
+
+	// Duration is the time range to release the target quantity slice by slice
+	Duration types.Duration `json:"duration,omitempty"`
+
+	// NumOfSlices is the number of the time slices, default to target quantity / slice quantity
+	NumOfSlices int `json:"numOfSlices,omitempty"`
+
+	// SizeJitter randomizes the size of each time slice, e.g., 0.2 means ±20% of the average slice size
+	SizeJitter fixedpoint.Value `json:"sizeJitter,omitempty"`
+
+	// MaxParticipationRate limits the filled quantity to the rate of the market trade volume, e.g., 0.1 means 10%
+	MaxParticipationRate fixedpoint.Value `json:"maxParticipationRate,omitempty"`
+
+	// NumOfTicks is the number of the price ticks to jump into the spread
+	NumOfTicks int `json:"numOfTicks,omitempty"`
+
+	// StopPrice is the price limit of the child orders
+	StopPrice fixedpoint.Value `json:"stopPrice,omitempty"`
+
+	UpdateInterval types.Duration `json:"updateInterval,omitempty"`
+
+	// Deadline is the max execution time, the rest quantity is executed by a market order after the deadline
+	Deadline types.Duration `json:"deadline,omitempty"`
+
+	execution *bbgo.TwapExecution
+}
+
+func (s *Strategy) ID() string {
+	return ID
+}
+
+func (s *Strategy) Validate() error {
+	if len(s.Symbol) == 0 {
+		return errors.New("symbol is required")
+	}
+
+	switch s.Side {
+	case types.SideTypeBuy, types.SideTypeSell:
+	default:
+		return errors.New("side should be either buy or sell")
+	}
+
+	if s.TargetQuantity <= 0 {
+		return errors.New("targetQuantity should be greater than zero")
+	}
+
+	if s.SliceQuantity < 0 || s.NumOfSlices < 0 {
+		return errors.New("sliceQuantity and numOfSlices can not be negative")
+	}
+
+	if s.SizeJitter < 0 || s.SizeJitter > fixedpoint.NewFromInt(1) {
+		return errors.New("sizeJitter should be between 0 and 1")
+	}
+
+	if s.MaxParticipationRate < 0 || s.MaxParticipationRate > fixedpoint.NewFromInt(1) {
+		return errors.New("maxParticipationRate should be between 0 and 1")
+	}
+
+	if s.Deadline > 0 && s.Duration > 0 && s.Deadline < s.Duration {
+		return errors.New("deadline should be longer than the duration")
+	}
+
+	return nil
+}
+
+// Subscribe is empty since the execution uses its own streams
+func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {}
+
+func (s *Strategy) newExecution(session *bbgo.ExchangeSession, now time.Time) *bbgo.TwapExecution {
+	var deadlineTime time.Time
+	if s.Deadline > 0 {
+		deadlineTime = now.Add(s.Deadline.Duration())
+	}
+
+	return &bbgo.TwapExecution{
+		Session:              session,
+		Symbol:               s.Symbol,
+		Side:                 s.Side,
+		TargetQuantity:       s.TargetQuantity,
+		SliceQuantity:        s.SliceQuantity,
+		StopPrice:            s.StopPrice,
+		NumOfTicks:           s.NumOfTicks,
+		UpdateInterval:       s.UpdateInterval.Duration(),
+		DeadlineTime:         deadlineTime,
+		Duration:             s.Duration.Duration(),
+		NumOfSlices:          s.NumOfSlices,
+		SizeJitter:           s.SizeJitter,
+		MaxParticipationRate: s.MaxParticipationRate,
+	}
+}
+
+func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	s.execution = s.newExecution(session, time.Now())
+
+	s.Notify("Starting %s twap execution: %s %f, duration %s", s.Symbol, s.Side, s.TargetQuantity.Float64(), s.Duration.Duration())
+	if err := s.execution.Run(ctx); err != nil {
+		return err
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-s.execution.Done():
+			s.Notify("%s twap execution is completed", s.Symbol)
+		}
+	}()
+
+	s.Graceful.OnShutdown(func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		log.Infof("shutting down %s twap execution...", s.Symbol)
+		s.execution.Shutdown(ctx)
+	})
+
+	return nil
+}
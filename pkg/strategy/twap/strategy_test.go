@@ -0,0 +1,53 @@
+package twap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestStrategy_Validate(t *testing.T) {
+	s := &Strategy{
+		Symbol:         "BTCUSDT",
+		Side:           types.SideTypeBuy,
+		TargetQuantity: fixedpoint.NewFromFloat(10.0),
+		SliceQuantity:  fixedpoint.NewFromFloat(1.0),
+		Duration:       types.Duration(time.Hour),
+		SizeJitter:     fixedpoint.NewFromFloat(0.2),
+	}
+	assert.NoError(t, s.Validate())
+
+	s.Deadline = types.Duration(30 * time.Minute)
+	assert.Error(t, s.Validate())
+	s.Deadline = 0
+
+	s.MaxParticipationRate = fixedpoint.NewFromFloat(1.5)
+	assert.Error(t, s.Validate())
+	s.MaxParticipationRate = 0
+
+	s.Side = ""
+	assert.Error(t, s.Validate())
+}
+
+func TestStrategy_newExecution(t *testing.T) {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	s := &Strategy{
+		Symbol:               "BTCUSDT",
+		Side:                 types.SideTypeSell,
+		TargetQuantity:       fixedpoint.NewFromFloat(10.0),
+		Duration:             types.Duration(time.Hour),
+		NumOfSlices:          6,
+		MaxParticipationRate: fixedpoint.NewFromFloat(0.1),
+		Deadline:             types.Duration(2 * time.Hour),
+	}
+
+	execution := s.newExecution(nil, now)
+	assert.Equal(t, time.Hour, execution.Duration)
+	assert.Equal(t, 6, execution.NumOfSlices)
+	assert.Equal(t, now.Add(2*time.Hour), execution.DeadlineTime)
+	assert.Equal(t, 0.1, execution.MaxParticipationRate.Float64())
+}
@@ -0,0 +1,444 @@
+package xarb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const ID = "xarb"
+
+const stateKey = "state-v1"
+
+var log = logrus.WithField("strategy", ID)
+
+func init() {
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+type State struct {
+	NumOfArbitrages int `json:"numOfArbitrages"`
+
+	// AccumulatedProfit is the expected quote profit of the submitted arbitrages after the fees
+	AccumulatedProfit fixedpoint.Value `json:"accumulatedProfit"`
+
+	// NumOfUnhedgedLegs is the number of the arbitrages that only one leg is submitted
+	NumOfUnhedgedLegs int `json:"numOfUnhedgedLegs"`
+}
+
+type Strategy struct {
+	*bbgo.Notifiability
+	*bbgo.Persistence
+	*bbgo.Graceful
+
+	// Heartbeat is injected when the watchdog is enabled
+	*bbgo.Heartbeat
+
+	Symbol string `json:"symbol"`
+
+	// Sessions are the two exchange sessions to arbitrage
+	Sessions []string `json:"sessions"`
+
+	// MinSpread is the min profit ratio of the arbitrage after the taker fees and the slippage, e.g., 0.001 means 0.1%
+	MinSpread fixedpoint.Value `json:"minSpread"`
+
+	// Slippage is the price ratio of the IOC orders to cross the best price, e.g., 0.0005 means 0.05%
+	Slippage fixedpoint.Value `json:"slippage"`
+
+	// Quantity is the max base quantity of one arbitrage
+	Quantity fixedpoint.Value `json:"quantity"`
+
+	UpdateInterval types.Duration `json:"updateInterval"`
+
+	// CoolDown is the wait time after an arbitrage, so the balances and the order books are updated before the next check
+	CoolDown types.Duration `json:"coolDown"`
+
+	// MinInventoryRatio is the min base inventory ratio of a session, when the ratio is lower,
+	// the arbitrage that buys on the session uses the RebalanceMinSpread so the inventory is moved back.
+	MinInventoryRatio fixedpoint.Value `json:"minInventoryRatio,omitempty"`
+
+	// RebalanceMinSpread is the min spread of the rebalancing arbitrage, it's usually lower than the MinSpread
+	RebalanceMinSpread fixedpoint.Value `json:"rebalanceMinSpread,omitempty"`
+
+	sessions []*arbSession
+
+	lastArbitrageTime time.Time
+
+	state *State
+	mu    sync.Mutex
+}
+
+// arbSession is the market data and the fee of one side of the arbitrage
+type arbSession struct {
+	name    string
+	session *bbgo.ExchangeSession
+	market  types.Market
+	book    *types.StreamOrderBook
+
+	takerFeeRate float64
+}
+
+// opportunity is the arbitrage that buys on one session and sells on the other session
+type opportunity struct {
+	buy, sell *arbSession
+
+	// buyPrice and sellPrice are the IOC order prices with the slippage
+	buyPrice, sellPrice float64
+	quantity            float64
+
+	// spread is the profit ratio after the taker fees and the slippage
+	spread float64
+}
+
+// Profit returns the expected quote profit of the opportunity
+func (o opportunity) Profit() float64 {
+	return o.spread * o.buyPrice * o.quantity
+}
+
+func (s *Strategy) ID() string {
+	return ID
+}
+
+func (s *Strategy) Validate() error {
+	if len(s.Symbol) == 0 {
+		return errors.New("symbol is required")
+	}
+
+	if len(s.Sessions) != 2 || s.Sessions[0] == s.Sessions[1] {
+		return errors.New("sessions should be two different session names")
+	}
+
+	if s.Quantity <= 0 {
+		return errors.New("quantity should be greater than zero")
+	}
+
+	if s.MinSpread < 0 || s.Slippage < 0 {
+		return errors.New("minSpread and slippage can not be negative")
+	}
+
+	if s.MinInventoryRatio < 0 || s.MinInventoryRatio >= fixedpoint.NewFromFloat(0.5) {
+		return errors.New("minInventoryRatio should be between 0 and 0.5")
+	}
+
+	return nil
+}
+
+func (s *Strategy) CrossSubscribe(sessions map[string]*bbgo.ExchangeSession) {
+	for _, name := range s.Sessions {
+		session, ok := sessions[name]
+		if !ok {
+			panic(fmt.Errorf("session %s is not defined", name))
+		}
+
+		session.Subscribe(types.BookChannel, s.Symbol, types.SubscribeOptions{})
+	}
+}
+
+// netSpread returns the profit ratio of buying at the ask price and selling at the bid price,
+// the taker fees of both legs and the slippage of both legs are deducted.
+func netSpread(ask, bid, buyFeeRate, sellFeeRate, slippage float64) float64 {
+	if ask <= 0 {
+		return 0
+	}
+
+	return (bid-ask)/ask - buyFeeRate - sellFeeRate - 2*slippage
+}
+
+// arbitrageQuantity returns the max quantity that both legs can execute, it's limited by the order book volumes,
+// the quote balance of the buy session and the base balance of the sell session.
+func arbitrageQuantity(maxQuantity, askVolume, bidVolume, quoteBalance, baseBalance, buyPrice float64) float64 {
+	quantity := maxQuantity
+	for _, q := range []float64{askVolume, bidVolume, baseBalance} {
+		if q < quantity {
+			quantity = q
+		}
+	}
+
+	if buyPrice > 0 && quoteBalance/buyPrice < quantity {
+		quantity = quoteBalance / buyPrice
+	}
+
+	if quantity < 0 {
+		return 0
+	}
+
+	return quantity
+}
+
+// inventoryRatio returns the base inventory ratio of the session among the arbitrage sessions
+func (s *Strategy) inventoryRatio(target *arbSession) float64 {
+	var total, base float64
+	for _, a := range s.sessions {
+		b, ok := a.session.Account.Balance(a.market.BaseCurrency)
+		if !ok {
+			continue
+		}
+
+		total += b.Total().Float64()
+		if a == target {
+			base = b.Total().Float64()
+		}
+	}
+
+	if total == 0 {
+		return 0.5
+	}
+
+	return base / total
+}
+
+// minSpreadOf returns the min spread of buying on the session
+func (s *Strategy) minSpreadOf(buy *arbSession) float64 {
+	if s.MinInventoryRatio > 0 && s.inventoryRatio(buy) < s.MinInventoryRatio.Float64() {
+		return s.RebalanceMinSpread.Float64()
+	}
+
+	return s.MinSpread.Float64()
+}
+
+// findOpportunity checks the best ask of the buy session against the best bid of the sell session
+func (s *Strategy) findOpportunity(buy, sell *arbSession) (opportunity, bool) {
+	ask, ok := buy.book.BestAsk()
+	if !ok {
+		return opportunity{}, false
+	}
+
+	bid, ok := sell.book.BestBid()
+	if !ok {
+		return opportunity{}, false
+	}
+
+	slippage := s.Slippage.Float64()
+	spread := netSpread(ask.Price.Float64(), bid.Price.Float64(), buy.takerFeeRate, sell.takerFeeRate, slippage)
+	if spread < s.minSpreadOf(buy) {
+		return opportunity{}, false
+	}
+
+	o := opportunity{
+		buy:       buy,
+		sell:      sell,
+		buyPrice:  ask.Price.Float64() * (1.0 + slippage),
+		sellPrice: bid.Price.Float64() * (1.0 - slippage),
+		spread:    spread,
+	}
+
+	var quoteBalance, baseBalance float64
+	if b, ok := buy.session.Account.Balance(buy.market.QuoteCurrency); ok {
+		quoteBalance = b.Available.Float64()
+	}
+
+	if b, ok := sell.session.Account.Balance(sell.market.BaseCurrency); ok {
+		baseBalance = b.Available.Float64()
+	}
+
+	// keep the fee of the buy leg in the quote balance
+	quoteBalance = quoteBalance / (1.0 + buy.takerFeeRate)
+
+	quantity := arbitrageQuantity(s.Quantity.Float64(), ask.Volume.Float64(), bid.Volume.Float64(), quoteBalance, baseBalance, o.buyPrice)
+	quantity = buy.market.CanonicalizeVolume(sell.market.CanonicalizeVolume(quantity))
+
+	for _, a := range []struct {
+		market types.Market
+		price  float64
+	}{{buy.market, o.buyPrice}, {sell.market, o.sellPrice}} {
+		if quantity < a.market.MinQuantity || quantity*a.price < a.market.MinNotional {
+			log.Debugf("%s arbitrage quantity %f is less than the min quantity or the min notional of %s", s.Symbol, quantity, a.market.Symbol)
+			return opportunity{}, false
+		}
+	}
+
+	if quantity <= 0 {
+		return opportunity{}, false
+	}
+
+	o.quantity = quantity
+	return o, true
+}
+
+// execute submits the IOC orders of both legs at the same time
+func (s *Strategy) execute(ctx context.Context, router bbgo.OrderExecutionRouter, o opportunity) {
+	legs := []struct {
+		session *arbSession
+		order   types.SubmitOrder
+	}{
+		{o.buy, types.SubmitOrder{
+			Symbol:      s.Symbol,
+			Market:      o.buy.market,
+			Side:        types.SideTypeBuy,
+			Type:        types.OrderTypeLimit,
+			Price:       o.buyPrice,
+			Quantity:    o.quantity,
+			TimeInForce: "IOC",
+		}},
+		{o.sell, types.SubmitOrder{
+			Symbol:      s.Symbol,
+			Market:      o.sell.market,
+			Side:        types.SideTypeSell,
+			Type:        types.OrderTypeLimit,
+			Price:       o.sellPrice,
+			Quantity:    o.quantity,
+			TimeInForce: "IOC",
+		}},
+	}
+
+	var errs = make([]error, len(legs))
+	var wg sync.WaitGroup
+	for i, leg := range legs {
+		wg.Add(1)
+		go func(i int, sessionName string, order types.SubmitOrder) {
+			defer wg.Done()
+			_, errs[i] = router.SubmitOrdersTo(ctx, sessionName, order)
+		}(i, leg.session.name, leg.order)
+	}
+	wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case errs[0] != nil && errs[1] != nil:
+		log.WithError(errs[0]).Errorf("%s arbitrage orders are rejected, sell leg error: %v", s.Symbol, errs[1])
+		return
+
+	case errs[0] != nil || errs[1] != nil:
+		s.state.NumOfUnhedgedLegs++
+		s.Notify("⚠️ %s arbitrage leg is unhedged, buy %s error: %v, sell %s error: %v",
+			s.Symbol, o.buy.name, errs[0], o.sell.name, errs[1])
+		return
+	}
+
+	s.state.NumOfArbitrages++
+	s.state.AccumulatedProfit += fixedpoint.NewFromFloat(o.Profit())
+
+	s.Notify("%s arbitrage: buy %f on %s at %f, sell on %s at %f, spread %.4f%%, expected profit %f %s",
+		s.Symbol, o.quantity,
+		o.buy.name, o.buyPrice,
+		o.sell.name, o.sellPrice,
+		o.spread*100.0, o.Profit(), o.buy.market.QuoteCurrency)
+}
+
+func (s *Strategy) check(ctx context.Context, router bbgo.OrderExecutionRouter, now time.Time) {
+	if now.Sub(s.lastArbitrageTime) < s.CoolDown.Duration() {
+		return
+	}
+
+	a, b := s.sessions[0], s.sessions[1]
+
+	o, ok := s.findOpportunity(a, b)
+	if !ok {
+		o, ok = s.findOpportunity(b, a)
+	}
+
+	if !ok {
+		return
+	}
+
+	s.lastArbitrageTime = now
+	s.execute(ctx, router, o)
+}
+
+func (s *Strategy) SaveState() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.Persistence.Save(s.state, ID, s.Symbol, stateKey); err != nil {
+		return err
+	}
+
+	log.Infof("state is saved => %+v", s.state)
+	return nil
+}
+
+func (s *Strategy) LoadState() error {
+	var state State
+	if err := s.Persistence.Load(&state, ID, s.Symbol, stateKey); err != nil {
+		if err != service.ErrPersistenceNotExists {
+			return err
+		}
+
+		s.state = &State{}
+	} else {
+		s.state = &state
+		log.Infof("state is restored: %+v", s.state)
+	}
+
+	return nil
+}
+
+func (s *Strategy) CrossRun(ctx context.Context, router bbgo.OrderExecutionRouter, sessions map[string]*bbgo.ExchangeSession) error {
+	if s.UpdateInterval == 0 {
+		s.UpdateInterval = types.Duration(time.Second)
+	}
+
+	if s.CoolDown == 0 {
+		s.CoolDown = types.Duration(5 * time.Second)
+	}
+
+	for _, name := range s.Sessions {
+		session, ok := sessions[name]
+		if !ok {
+			return fmt.Errorf("session %s is not defined", name)
+		}
+
+		market, ok := session.Market(s.Symbol)
+		if !ok {
+			return fmt.Errorf("market %s is not defined on session %s", s.Symbol, name)
+		}
+
+		a := &arbSession{
+			name:    name,
+			session: session,
+			market:  market,
+			book:    types.NewStreamBook(s.Symbol),
+		}
+
+		if fee, ok := session.ExchangeFee(); ok {
+			a.takerFeeRate = fee.TakerFeeRate.Float64()
+		}
+
+		a.book.BindStream(session.MarketDataStream)
+		s.sessions = append(s.sessions, a)
+	}
+
+	if err := s.LoadState(); err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.UpdateInterval.Duration())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-ticker.C:
+				s.Heartbeat.Beat()
+				s.check(ctx, router, time.Now())
+			}
+		}
+	}()
+
+	s.Graceful.OnShutdown(func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		if err := s.SaveState(); err != nil {
+			log.WithError(err).Error("can not save state")
+		} else {
+			s.Notify("%s arbitrage: %d arbitrages, expected profit %f, %d unhedged legs",
+				s.Symbol, s.state.NumOfArbitrages, s.state.AccumulatedProfit.Float64(), s.state.NumOfUnhedgedLegs)
+		}
+	})
+
+	return nil
+}
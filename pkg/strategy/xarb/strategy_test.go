@@ -0,0 +1,159 @@
+package xarb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func newTestArbSession(name string, bid, ask, volume float64, balances types.BalanceMap) *arbSession {
+	account := types.NewAccount()
+	account.UpdateBalances(balances)
+
+	book := types.NewStreamBook("BTCUSDT")
+	book.Load(types.SliceOrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(bid), Volume: fixedpoint.NewFromFloat(volume)}},
+		Asks:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(ask), Volume: fixedpoint.NewFromFloat(volume)}},
+	})
+
+	return &arbSession{
+		name:    name,
+		session: &bbgo.ExchangeSession{Name: name, Account: account},
+		market: types.Market{
+			Symbol:          "BTCUSDT",
+			BaseCurrency:    "BTC",
+			QuoteCurrency:   "USDT",
+			VolumePrecision: 4,
+			MinQuantity:     0.001,
+			MinNotional:     10.0,
+		},
+		book:         book,
+		takerFeeRate: 0.001,
+	}
+}
+
+func newBalances(btc, usdt float64) types.BalanceMap {
+	return types.BalanceMap{
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(btc)},
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(usdt)},
+	}
+}
+
+func Test_netSpread(t *testing.T) {
+	assert.InDelta(t, 0.01-0.002-0.001, netSpread(100.0, 101.0, 0.001, 0.001, 0.0005), 1e-9)
+	assert.True(t, netSpread(100.0, 100.1, 0.001, 0.001, 0) < 0)
+}
+
+func Test_arbitrageQuantity(t *testing.T) {
+	assert.Equal(t, 1.0, arbitrageQuantity(1.0, 5.0, 5.0, 1000.0, 5.0, 100.0))
+	assert.Equal(t, 0.5, arbitrageQuantity(1.0, 0.5, 5.0, 1000.0, 5.0, 100.0))
+	assert.Equal(t, 0.3, arbitrageQuantity(1.0, 5.0, 5.0, 1000.0, 0.3, 100.0))
+	assert.Equal(t, 0.2, arbitrageQuantity(1.0, 5.0, 5.0, 20.0, 5.0, 100.0))
+}
+
+func TestStrategy_findOpportunity(t *testing.T) {
+	s := &Strategy{
+		Symbol:    "BTCUSDT",
+		MinSpread: fixedpoint.NewFromFloat(0.002),
+		Quantity:  fixedpoint.NewFromFloat(1.0),
+	}
+
+	// buy on a at 100, sell on b at 101
+	a := newTestArbSession("a", 99.0, 100.0, 2.0, newBalances(1.0, 1000.0))
+	b := newTestArbSession("b", 101.0, 102.0, 2.0, newBalances(0.4, 1000.0))
+	s.sessions = []*arbSession{a, b}
+
+	o, ok := s.findOpportunity(a, b)
+	if assert.True(t, ok) {
+		assert.Equal(t, "a", o.buy.name)
+		assert.Equal(t, 100.0, o.buyPrice)
+		assert.Equal(t, 101.0, o.sellPrice)
+
+		// limited by the base balance of the sell session
+		assert.Equal(t, 0.4, o.quantity)
+		assert.InDelta(t, 0.008, o.spread, 1e-9)
+	}
+
+	_, ok = s.findOpportunity(b, a)
+	assert.False(t, ok)
+
+	// the spread is lower than the min spread
+	s.MinSpread = fixedpoint.NewFromFloat(0.01)
+	_, ok = s.findOpportunity(a, b)
+	assert.False(t, ok)
+
+	// the session a has less inventory, so the rebalancing spread is used for buying on a
+	s.MinInventoryRatio = fixedpoint.NewFromFloat(0.3)
+	s.RebalanceMinSpread = fixedpoint.NewFromFloat(0.005)
+	a.session.Account.UpdateBalances(newBalances(0.1, 1000.0))
+	assert.InDelta(t, 0.2, s.inventoryRatio(a), 1e-9)
+
+	_, ok = s.findOpportunity(a, b)
+	assert.True(t, ok)
+}
+
+type testRouter struct {
+	mu     sync.Mutex
+	orders map[string][]types.SubmitOrder
+	errs   map[string]error
+}
+
+func (r *testRouter) SubmitOrdersTo(ctx context.Context, session string, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.errs[session]; err != nil {
+		return nil, err
+	}
+
+	r.orders[session] = append(r.orders[session], orders...)
+	return nil, nil
+}
+
+func TestStrategy_check(t *testing.T) {
+	s := &Strategy{
+		Notifiability: &bbgo.Notifiability{},
+		Symbol:        "BTCUSDT",
+		MinSpread:     fixedpoint.NewFromFloat(0.002),
+		Quantity:      fixedpoint.NewFromFloat(1.0),
+		CoolDown:      types.Duration(5 * time.Second),
+		state:         &State{},
+	}
+
+	a := newTestArbSession("a", 101.0, 102.0, 2.0, newBalances(1.0, 1000.0))
+	b := newTestArbSession("b", 99.0, 100.0, 2.0, newBalances(1.0, 1000.0))
+	s.sessions = []*arbSession{a, b}
+
+	router := &testRouter{orders: map[string][]types.SubmitOrder{}, errs: map[string]error{}}
+
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	s.check(context.Background(), router, now)
+
+	if assert.Len(t, router.orders["b"], 1) && assert.Len(t, router.orders["a"], 1) {
+		assert.Equal(t, types.SideTypeBuy, router.orders["b"][0].Side)
+		assert.Equal(t, types.SideTypeSell, router.orders["a"][0].Side)
+		assert.Equal(t, "IOC", router.orders["a"][0].TimeInForce)
+		assert.Equal(t, 1.0, router.orders["a"][0].Quantity)
+	}
+	assert.Equal(t, 1, s.state.NumOfArbitrages)
+	assert.InDelta(t, 0.008*100.0, s.state.AccumulatedProfit.Float64(), 1e-6)
+
+	// cool down
+	s.check(context.Background(), router, now.Add(time.Second))
+	assert.Len(t, router.orders["b"], 1)
+
+	// one leg is rejected
+	router.errs["a"] = errors.New("insufficient balance")
+	s.check(context.Background(), router, now.Add(10*time.Second))
+	assert.Equal(t, 1, s.state.NumOfArbitrages)
+	assert.Equal(t, 1, s.state.NumOfUnhedgedLegs)
+}
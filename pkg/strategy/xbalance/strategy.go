@@ -113,6 +113,49 @@ type Address struct {
 	AddressTag string           `json:"addressTag"`
 	Network    string           `json:"network"`
 	ForeignFee fixedpoint.Value `json:"foreignFee"`
+
+	// Networks are the alternative addresses of the other networks, the cheapest viable network is
+	// selected by the withdrawal fees when the exchange provides them.
+	Networks []Address `json:"networks,omitempty"`
+}
+
+// selectWithdrawalAddress selects the address of the cheapest network that accepts the amount and returns the
+// withdrawal fee of the network. The configured address and its foreign fee are used when the exchange does not
+// provide the withdrawal networks.
+func selectWithdrawalAddress(ctx context.Context, exchange types.Exchange, asset string, address Address, amount fixedpoint.Value) (Address, fixedpoint.Value, error) {
+	networkService, ok := exchange.(types.ExchangeWithdrawalNetworkService)
+	if !ok || len(address.Networks) == 0 {
+		return address, address.ForeignFee, nil
+	}
+
+	candidates := map[string]Address{}
+	var names []string
+	for _, a := range append([]Address{address}, address.Networks...) {
+		if len(a.Network) == 0 {
+			continue
+		}
+
+		if _, exists := candidates[a.Network]; !exists {
+			candidates[a.Network] = a
+			names = append(names, a.Network)
+		}
+	}
+
+	if len(names) == 0 {
+		return address, address.ForeignFee, nil
+	}
+
+	networks, err := networkService.QueryWithdrawalNetworks(ctx, asset)
+	if err != nil {
+		return address, 0, err
+	}
+
+	network, err := types.CheapestWithdrawalNetwork(networks, amount, names...)
+	if err != nil {
+		return address, 0, err
+	}
+
+	return candidates[network.Network], network.Fee, nil
 }
 
 func (a *Address) UnmarshalJSON(body []byte) error {
@@ -230,8 +273,16 @@ func (s *Strategy) checkBalance(ctx context.Context, sessions map[string]*bbgo.E
 		return
 	}
 
-	if toAddress.ForeignFee > 0 {
-		requiredAmount += toAddress.ForeignFee
+	toAddress, fee, err := selectWithdrawalAddress(ctx, fromSession.Exchange, s.Asset, toAddress, requiredAmount)
+	if err != nil {
+		log.WithError(err).Errorf("can not select the %s withdrawal network of session %s", s.Asset, lowLevelSession.Name)
+		s.Notifiability.Notify("Can not select the %s withdrawal network of session %s: %v", s.Asset, lowLevelSession.Name, err)
+		return
+	}
+
+	if fee > 0 {
+		s.Notifiability.Notify("Selected %s withdrawal network %q, fee %f %s", s.Asset, toAddress.Network, fee.Float64(), s.Asset)
+		requiredAmount += fee
 	}
 
 	if s.state != nil {
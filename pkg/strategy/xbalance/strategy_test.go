@@ -1,9 +1,11 @@
 package xbalance
 
 import (
+	"context"
 	"testing"
 
 	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -17,3 +19,51 @@ func TestState_PlainText(t *testing.T) {
 
 	assert.Equal(t, "USDT transfer stats:\ndaily number of transfers: 1\ndaily amount of transfers 1000", state.PlainText())
 }
+
+type withdrawalNetworkExchange struct {
+	types.Exchange
+
+	networks []types.WithdrawalNetwork
+}
+
+func (e *withdrawalNetworkExchange) QueryWithdrawalNetworks(ctx context.Context, asset string) ([]types.WithdrawalNetwork, error) {
+	return e.networks, nil
+}
+
+func Test_selectWithdrawalAddress(t *testing.T) {
+	address := Address{
+		Address:    "0xabc",
+		Network:    "ETH",
+		ForeignFee: fixedpoint.NewFromFloat(1.0),
+		Networks: []Address{
+			{Address: "Txyz", Network: "TRX"},
+			{Address: "bnb123", Network: "BSC"},
+		},
+	}
+
+	exchange := &withdrawalNetworkExchange{
+		networks: []types.WithdrawalNetwork{
+			{Asset: "USDT", Network: "ETH", Fee: fixedpoint.NewFromFloat(10.0), MinAmount: fixedpoint.NewFromFloat(20.0), Enabled: true, IsDefault: true},
+			{Asset: "USDT", Network: "TRX", Fee: fixedpoint.NewFromFloat(1.0), MinAmount: fixedpoint.NewFromFloat(10.0), Enabled: false},
+			{Asset: "USDT", Network: "BSC", Fee: fixedpoint.NewFromFloat(0.8), MinAmount: fixedpoint.NewFromFloat(10.0), Enabled: true},
+			{Asset: "USDT", Network: "SOL", Fee: fixedpoint.NewFromFloat(0.1), MinAmount: fixedpoint.NewFromFloat(1.0), Enabled: true},
+		},
+	}
+
+	// the disabled network and the network without the receiving address are skipped
+	selected, fee, err := selectWithdrawalAddress(context.Background(), exchange, "USDT", address, fixedpoint.NewFromFloat(100.0))
+	assert.NoError(t, err)
+	assert.Equal(t, "bnb123", selected.Address)
+	assert.Equal(t, "BSC", selected.Network)
+	assert.Equal(t, fixedpoint.NewFromFloat(0.8), fee)
+
+	// the exchange does not provide the withdrawal networks
+	selected, fee, err = selectWithdrawalAddress(context.Background(), &withdrawalNetworkExchange{}, "USDT", Address{Address: "0xabc", ForeignFee: fixedpoint.NewFromFloat(1.0)}, fixedpoint.NewFromFloat(100.0))
+	assert.NoError(t, err)
+	assert.Equal(t, "0xabc", selected.Address)
+	assert.Equal(t, fixedpoint.NewFromFloat(1.0), fee)
+
+	// no network accepts the amount
+	_, _, err = selectWithdrawalAddress(context.Background(), exchange, "USDT", address, fixedpoint.NewFromFloat(5.0))
+	assert.Error(t, err)
+}
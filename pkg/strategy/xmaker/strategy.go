@@ -92,6 +92,8 @@ type Strategy struct {
 	*bbgo.Notifiability
 	*bbgo.Persistence
 
+	ProfitRecorder *bbgo.ProfitRecorder
+
 	Symbol string `json:"symbol"`
 
 	// SourceExchange session name
@@ -109,6 +111,10 @@ type Strategy struct {
 	AskMargin     fixedpoint.Value `json:"askMargin"`
 	UseDepthPrice bool             `json:"useDepthPrice"`
 
+	// TransferAmount is the base amount of one rebalance transfer between the sessions, when it's set,
+	// the withdrawal fee of the cheapest network is added to the bid and ask margins as the transfer cost.
+	TransferAmount fixedpoint.Value `json:"transferAmount,omitempty"`
+
 	EnableBollBandMargin bool             `json:"enableBollBandMargin"`
 	BollBandInterval     types.Interval   `json:"bollBandInterval"`
 	BollBandMargin       fixedpoint.Value `json:"bollBandMargin"`
@@ -162,6 +168,9 @@ type Strategy struct {
 	lastPrice float64
 	groupID   uint32
 
+	// transferCostMargin is the withdrawal fee ratio of the transfer amount
+	transferCostMargin fixedpoint.Value
+
 	stopC chan struct{}
 }
 
@@ -331,8 +340,8 @@ func (s *Strategy) updateQuote(ctx context.Context, orderExecutionRouter bbgo.Or
 	var accumulativeBidQuantity, accumulativeAskQuantity fixedpoint.Value
 	var bidQuantity = s.Quantity
 	var askQuantity = s.Quantity
-	var bidMargin = s.BidMargin
-	var askMargin = s.AskMargin
+	var bidMargin = s.BidMargin + s.transferCostMargin
+	var askMargin = s.AskMargin + s.transferCostMargin
 	var pips = s.Pips
 
 	if s.EnableBollBandMargin {
@@ -635,6 +644,7 @@ func (s *Strategy) processTrade(trade types.Trade) {
 			Time:            trade.Time.Time(),
 		}
 		s.state.ProfitStats.AddProfit(p)
+		s.ProfitRecorder.Record(ID, p)
 		s.Notify(&p)
 	} else {
 		log.Infof("position changed: %s", s.state.Position)
@@ -648,6 +658,26 @@ func (s *Strategy) processTrade(trade types.Trade) {
 	}
 }
 
+// queryTransferCostMargin returns the withdrawal fee of the cheapest network as the ratio of the transfer amount
+func queryTransferCostMargin(ctx context.Context, exchange types.Exchange, asset string, amount fixedpoint.Value) (fixedpoint.Value, error) {
+	networkService, ok := exchange.(types.ExchangeWithdrawalNetworkService)
+	if !ok {
+		return 0, fmt.Errorf("exchange %s does not provide the withdrawal networks", exchange.Name())
+	}
+
+	networks, err := networkService.QueryWithdrawalNetworks(ctx, asset)
+	if err != nil {
+		return 0, err
+	}
+
+	network, err := types.CheapestWithdrawalNetwork(networks, amount)
+	if err != nil {
+		return 0, err
+	}
+
+	return network.Fee.Div(amount), nil
+}
+
 func (s *Strategy) Validate() error {
 	if s.Quantity == 0 || s.QuantityScale == nil {
 		return errors.New("quantity or quantityScale can not be empty")
@@ -772,6 +802,16 @@ func (s *Strategy) CrossRun(ctx context.Context, orderExecutionRouter bbgo.Order
 		return fmt.Errorf("maker session market %s is not defined", s.Symbol)
 	}
 
+	if s.TransferAmount > 0 {
+		margin, err := queryTransferCostMargin(ctx, s.sourceSession.Exchange, s.sourceMarket.BaseCurrency, s.TransferAmount)
+		if err != nil {
+			log.WithError(err).Warnf("can not query the %s transfer cost, the transfer cost is not included in the margins", s.sourceMarket.BaseCurrency)
+		} else {
+			s.transferCostMargin = margin
+			log.Infof("%s transfer cost margin: %f", s.Symbol, margin.Float64())
+		}
+	}
+
 	standardIndicatorSet, ok := s.sourceSession.StandardIndicatorSet(s.Symbol)
 	if !ok {
 		return fmt.Errorf("%s standard indicator set not found", s.Symbol)
@@ -793,18 +833,12 @@ func (s *Strategy) CrossRun(ctx context.Context, orderExecutionRouter bbgo.Order
 		s.Notify("%s position is restored => %f", s.Symbol, s.state.HedgePosition.Float64())
 	}
 
-	if s.makerSession.MakerFeeRate > 0 || s.makerSession.TakerFeeRate > 0 {
-		s.state.Position.SetExchangeFeeRate(types.ExchangeName(s.MakerExchange), types.ExchangeFee{
-			MakerFeeRate: s.makerSession.MakerFeeRate,
-			TakerFeeRate: s.makerSession.TakerFeeRate,
-		})
+	if fee, ok := s.makerSession.ExchangeFee(); ok {
+		s.state.Position.SetExchangeFeeRate(types.ExchangeName(s.MakerExchange), fee)
 	}
 
-	if s.sourceSession.MakerFeeRate > 0 || s.sourceSession.TakerFeeRate > 0 {
-		s.state.Position.SetExchangeFeeRate(types.ExchangeName(s.SourceExchange), types.ExchangeFee{
-			MakerFeeRate: s.sourceSession.MakerFeeRate,
-			TakerFeeRate: s.sourceSession.TakerFeeRate,
-		})
+	if fee, ok := s.sourceSession.ExchangeFee(); ok {
+		s.state.Position.SetExchangeFeeRate(types.ExchangeName(s.SourceExchange), fee)
 	}
 
 	s.book = types.NewStreamBook(s.Symbol)
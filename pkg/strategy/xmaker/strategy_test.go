@@ -1,6 +1,7 @@
 package xmaker
 
 import (
+	"context"
 	"testing"
 
 	"github.com/c9s/bbgo/pkg/fixedpoint"
@@ -34,3 +35,31 @@ func Test_aggregatePrice(t *testing.T) {
 	assert.Equal(t, fixedpoint.NewFromFloat(1100.0), aggregatedPrice3)
 
 }
+
+type withdrawalNetworkExchange struct {
+	types.Exchange
+
+	networks []types.WithdrawalNetwork
+}
+
+func (e *withdrawalNetworkExchange) QueryWithdrawalNetworks(ctx context.Context, asset string) ([]types.WithdrawalNetwork, error) {
+	return e.networks, nil
+}
+
+func Test_queryTransferCostMargin(t *testing.T) {
+	exchange := &withdrawalNetworkExchange{
+		networks: []types.WithdrawalNetwork{
+			{Asset: "BTC", Network: "BTC", Fee: fixedpoint.NewFromFloat(0.0005), MinAmount: fixedpoint.NewFromFloat(0.001), Enabled: true, IsDefault: true},
+			{Asset: "BTC", Network: "BSC", Fee: fixedpoint.NewFromFloat(0.0001), MinAmount: fixedpoint.NewFromFloat(0.01), Enabled: true},
+		},
+	}
+
+	margin, err := queryTransferCostMargin(context.Background(), exchange, "BTC", fixedpoint.NewFromFloat(0.1))
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.001, margin.Float64(), 1e-8)
+
+	// the cheaper network does not accept the small amount
+	margin, err = queryTransferCostMargin(context.Background(), exchange, "BTC", fixedpoint.NewFromFloat(0.005))
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.1, margin.Float64(), 1e-8)
+}
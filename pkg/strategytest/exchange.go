@@ -0,0 +1,522 @@
+package strategytest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var ErrInsufficientBalance = errors.New("insufficient balance")
+
+// Stream is the stream of the simulated exchange, the events are emitted by the harness
+type Stream struct {
+	types.StandardStream
+}
+
+func NewStream() *Stream {
+	return &Stream{StandardStream: types.NewStandardStream()}
+}
+
+func (s *Stream) SetPublicOnly() {}
+
+func (s *Stream) Connect(ctx context.Context) error {
+	return nil
+}
+
+func (s *Stream) Close() error {
+	return nil
+}
+
+// Exchange is the simulated exchange of the harness, the submitted orders are kept open until they are matched
+// by the scripted klines, the scripted order books or the scripted fills.
+type Exchange struct {
+	name    types.ExchangeName
+	markets types.MarketMap
+
+	makerFeeRate, takerFeeRate float64
+
+	mu       sync.Mutex
+	balances types.BalanceMap
+	history  []types.KLine
+	now      time.Time
+
+	orderID, tradeID uint64
+
+	submittedOrders []types.SubmitOrder
+	orders          map[uint64]*types.Order
+	trades          []types.Trade
+
+	// userDataStream receives the order updates, the trade updates and the balance updates
+	userDataStream *Stream
+}
+
+func NewExchange(name types.ExchangeName, markets types.MarketMap, balances types.BalanceMap) *Exchange {
+	if balances == nil {
+		balances = types.BalanceMap{}
+	}
+
+	return &Exchange{
+		name:     name,
+		markets:  markets,
+		balances: balances,
+		orders:   make(map[uint64]*types.Order),
+	}
+}
+
+// SetFeeRates sets the fee rates of the simulated trades, the limit orders are filled as maker
+func (e *Exchange) SetFeeRates(makerFeeRate, takerFeeRate float64) {
+	e.makerFeeRate = makerFeeRate
+	e.takerFeeRate = takerFeeRate
+}
+
+// BindUserDataStream sets the stream that receives the order updates, the trade updates and the balance updates
+func (e *Exchange) BindUserDataStream(stream *Stream) {
+	e.userDataStream = stream
+}
+
+// AddHistory adds the historical klines returned by QueryKLines
+func (e *Exchange) AddHistory(kLines ...types.KLine) {
+	e.mu.Lock()
+	e.history = append(e.history, kLines...)
+	e.mu.Unlock()
+}
+
+func (e *Exchange) Name() types.ExchangeName {
+	return e.name
+}
+
+func (e *Exchange) PlatformFeeCurrency() string {
+	return ""
+}
+
+func (e *Exchange) NewStream() types.Stream {
+	return NewStream()
+}
+
+func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
+	return e.markets, nil
+}
+
+func (e *Exchange) QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
+	return nil, fmt.Errorf("ticker of %s is not simulated", symbol)
+}
+
+func (e *Exchange) QueryTickers(ctx context.Context, symbol ...string) (map[string]types.Ticker, error) {
+	return nil, errors.New("tickers are not simulated")
+}
+
+func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var kLines []types.KLine
+	for _, k := range e.history {
+		if k.Symbol != symbol || k.Interval != interval {
+			continue
+		}
+
+		if options.EndTime != nil && k.StartTime.After(*options.EndTime) {
+			continue
+		}
+
+		if options.StartTime != nil && k.StartTime.Before(*options.StartTime) {
+			continue
+		}
+
+		kLines = append(kLines, k)
+	}
+
+	if options.Limit > 0 && len(kLines) > options.Limit {
+		kLines = kLines[len(kLines)-options.Limit:]
+	}
+
+	return kLines, nil
+}
+
+func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	account := types.NewAccount()
+	account.UpdateBalances(e.Balances())
+	return account, nil
+}
+
+func (e *Exchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	return e.Balances(), nil
+}
+
+// Balances returns the copy of the current balances
+func (e *Exchange) Balances() types.BalanceMap {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	balances := make(types.BalanceMap, len(e.balances))
+	for currency, b := range e.balances {
+		balances[currency] = b
+	}
+
+	return balances
+}
+
+// lock moves the available balance to the locked balance, the lock must be held
+func (e *Exchange) lock(currency string, amount fixedpoint.Value) error {
+	b := e.balances[currency]
+	if b.Available < amount {
+		return errors.Wrapf(ErrInsufficientBalance, "%s available %f is less than %f", currency, b.Available.Float64(), amount.Float64())
+	}
+
+	b.Currency = currency
+	b.Available -= amount
+	b.Locked += amount
+	e.balances[currency] = b
+	return nil
+}
+
+// unlock moves the locked balance back to the available balance, the lock must be held
+func (e *Exchange) unlock(currency string, amount fixedpoint.Value) {
+	b := e.balances[currency]
+	b.Available += amount
+	b.Locked -= amount
+	e.balances[currency] = b
+}
+
+// lockedAmount returns the currency and the amount that the order locks
+func lockedAmount(market types.Market, order types.SubmitOrder, quantity float64) (string, fixedpoint.Value) {
+	if order.Side == types.SideTypeSell {
+		return market.BaseCurrency, fixedpoint.NewFromFloat(quantity)
+	}
+
+	// the market buy order is settled by the fill price, so nothing is locked
+	if order.Type == types.OrderTypeMarket {
+		return market.QuoteCurrency, 0
+	}
+
+	return market.QuoteCurrency, fixedpoint.NewFromFloat(order.Price * quantity)
+}
+
+// SubmitOrders creates the orders and locks the balances, the order updates of the created orders and the balance
+// snapshot are emitted synchronously. The orders stop at the first rejected order like the real exchanges.
+func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	createdOrders, err = e.submitOrders(orders...)
+
+	if e.userDataStream != nil && len(createdOrders) > 0 {
+		for _, o := range createdOrders {
+			e.userDataStream.EmitOrderUpdate(o)
+		}
+
+		e.userDataStream.EmitBalanceSnapshot(e.Balances())
+	}
+
+	return createdOrders, err
+}
+
+func (e *Exchange) submitOrders(orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, o := range orders {
+		market, ok := e.markets[o.Symbol]
+		if !ok {
+			return createdOrders, fmt.Errorf("market %s is not defined", o.Symbol)
+		}
+
+		currency, amount := lockedAmount(market, o, o.Quantity)
+		if err := e.lock(currency, amount); err != nil {
+			return createdOrders, err
+		}
+
+		e.orderID++
+		order := types.Order{
+			SubmitOrder:      o,
+			Exchange:         e.name,
+			OrderID:          e.orderID,
+			Status:           types.OrderStatusNew,
+			ExecutedQuantity: 0,
+			IsWorking:        true,
+			CreationTime:     types.Time(e.now),
+			UpdateTime:       types.Time(e.now),
+		}
+
+		e.submittedOrders = append(e.submittedOrders, o)
+		e.orders[order.OrderID] = &order
+		createdOrders = append(createdOrders, order)
+	}
+
+	return createdOrders, nil
+}
+
+func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	for _, o := range e.OpenOrders() {
+		if o.Symbol == symbol {
+			orders = append(orders, o)
+		}
+	}
+
+	return orders, nil
+}
+
+// CancelOrders cancels the open orders, the order updates of the canceled orders are emitted synchronously
+func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	canceled, err := e.cancelOrders(orders...)
+
+	if e.userDataStream != nil && len(canceled) > 0 {
+		for _, o := range canceled {
+			e.userDataStream.EmitOrderUpdate(o)
+		}
+
+		e.userDataStream.EmitBalanceSnapshot(e.Balances())
+	}
+
+	return err
+}
+
+func (e *Exchange) cancelOrders(orders ...types.Order) (canceled []types.Order, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, o := range orders {
+		order, ok := e.orders[o.OrderID]
+		if !ok {
+			return canceled, fmt.Errorf("order %d not found", o.OrderID)
+		}
+
+		if !order.IsWorking {
+			continue
+		}
+
+		market := e.markets[order.Symbol]
+		currency, amount := lockedAmount(market, order.SubmitOrder, order.Quantity-order.ExecutedQuantity)
+		e.unlock(currency, amount)
+
+		order.Status = types.OrderStatusCanceled
+		order.IsWorking = false
+		order.UpdateTime = types.Time(e.now)
+		canceled = append(canceled, *order)
+	}
+
+	return canceled, nil
+}
+
+// fill executes the quantity of the order at the price and settles the balances, the lock must be held
+func (e *Exchange) fill(order *types.Order, price, quantity float64, isMaker bool) (types.Trade, error) {
+	if !order.IsWorking {
+		return types.Trade{}, fmt.Errorf("order %d is not working", order.OrderID)
+	}
+
+	remaining := order.Quantity - order.ExecutedQuantity
+	if quantity <= 0 || quantity > remaining {
+		quantity = remaining
+	}
+
+	market := e.markets[order.Symbol]
+	quoteQuantity := price * quantity
+
+	feeRate := e.takerFeeRate
+	if isMaker {
+		feeRate = e.makerFeeRate
+	}
+	fee := quoteQuantity * feeRate
+
+	currency, amount := lockedAmount(market, order.SubmitOrder, quantity)
+	e.unlock(currency, amount)
+
+	base := e.balances[market.BaseCurrency]
+	quote := e.balances[market.QuoteCurrency]
+	base.Currency, quote.Currency = market.BaseCurrency, market.QuoteCurrency
+
+	switch order.Side {
+	case types.SideTypeBuy:
+		base.Available += fixedpoint.NewFromFloat(quantity)
+		quote.Available -= fixedpoint.NewFromFloat(quoteQuantity + fee)
+	case types.SideTypeSell:
+		base.Available -= fixedpoint.NewFromFloat(quantity)
+		quote.Available += fixedpoint.NewFromFloat(quoteQuantity - fee)
+	}
+
+	e.balances[market.BaseCurrency] = base
+	e.balances[market.QuoteCurrency] = quote
+
+	order.ExecutedQuantity += quantity
+	order.UpdateTime = types.Time(e.now)
+	if order.ExecutedQuantity >= order.Quantity {
+		order.Status = types.OrderStatusFilled
+		order.IsWorking = false
+	} else {
+		order.Status = types.OrderStatusPartiallyFilled
+	}
+
+	e.tradeID++
+	trade := types.Trade{
+		ID:            int64(e.tradeID),
+		OrderID:       order.OrderID,
+		Exchange:      e.name,
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: quoteQuantity,
+		Symbol:        order.Symbol,
+		Side:          order.Side,
+		IsBuyer:       order.Side == types.SideTypeBuy,
+		IsMaker:       isMaker,
+		Time:          types.Time(e.now),
+		Fee:           fee,
+		FeeCurrency:   market.QuoteCurrency,
+	}
+
+	e.trades = append(e.trades, trade)
+	return trade, nil
+}
+
+// execution is the trade and the updated order of a fill
+type execution struct {
+	trade types.Trade
+	order types.Order
+}
+
+// publish emits the trade updates, the order updates and the balance snapshot of the executions,
+// it must be called without holding the lock since the strategies may submit orders in the callbacks.
+func (e *Exchange) publish(executions []execution) {
+	if e.userDataStream == nil || len(executions) == 0 {
+		return
+	}
+
+	for _, exec := range executions {
+		e.userDataStream.EmitTradeUpdate(exec.trade)
+		e.userDataStream.EmitOrderUpdate(exec.order)
+	}
+
+	e.userDataStream.EmitBalanceSnapshot(e.Balances())
+}
+
+// fillOrder fills the order by the scripted fill
+func (e *Exchange) fillOrder(orderID uint64, price, quantity float64) (execution, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	order, ok := e.orders[orderID]
+	if !ok {
+		return execution{}, fmt.Errorf("order %d not found", orderID)
+	}
+
+	if price == 0 {
+		price = order.Price
+	}
+
+	trade, err := e.fill(order, price, quantity, order.Type != types.OrderTypeMarket)
+	if err != nil {
+		return execution{}, err
+	}
+
+	return execution{trade: trade, order: *order}, nil
+}
+
+// matchPrices fills the open orders of the symbol, the market orders are filled at the market price,
+// the buy limit orders are filled when the low price reaches the order price and
+// the sell limit orders are filled when the high price reaches the order price.
+// The sell stop orders are triggered when the low price reaches the stop price, and the buy stop orders are triggered
+// when the high price reaches the stop price, the triggered orders are filled at the stop price or the limit price.
+func (e *Exchange) matchPrices(symbol string, marketBuyPrice, marketSellPrice, low, high float64) (executions []execution) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var orderIDs []uint64
+	for id, o := range e.orders {
+		if o.Symbol == symbol && o.IsWorking {
+			orderIDs = append(orderIDs, id)
+		}
+	}
+
+	// match the orders in the submission order
+	sort.Slice(orderIDs, func(i, j int) bool { return orderIDs[i] < orderIDs[j] })
+
+	for _, id := range orderIDs {
+		order := e.orders[id]
+
+		var price float64
+		var isMaker bool
+		switch order.Type {
+		case types.OrderTypeMarket:
+			price = marketBuyPrice
+			if order.Side == types.SideTypeSell {
+				price = marketSellPrice
+			}
+
+		case types.OrderTypeLimit, types.OrderTypeLimitMaker:
+			if (order.Side == types.SideTypeBuy && low <= order.Price) || (order.Side == types.SideTypeSell && high >= order.Price) {
+				price = order.Price
+				isMaker = true
+			}
+
+		case types.OrderTypeStopMarket, types.OrderTypeStopLimit:
+			if (order.Side == types.SideTypeSell && low <= order.StopPrice) || (order.Side == types.SideTypeBuy && high >= order.StopPrice) {
+				price = order.StopPrice
+				if order.Type == types.OrderTypeStopLimit {
+					price = order.Price
+				}
+			}
+		}
+
+		if price <= 0 {
+			continue
+		}
+
+		trade, err := e.fill(order, price, 0, isMaker)
+		if err != nil {
+			continue
+		}
+
+		executions = append(executions, execution{trade: trade, order: *order})
+	}
+
+	return executions
+}
+
+func (e *Exchange) setTime(t time.Time) {
+	e.mu.Lock()
+	e.now = t
+	e.mu.Unlock()
+}
+
+// SubmittedOrders returns the submit orders received by the exchange
+func (e *Exchange) SubmittedOrders() []types.SubmitOrder {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]types.SubmitOrder(nil), e.submittedOrders...)
+}
+
+// Orders returns the latest state of the orders ordered by the order id
+func (e *Exchange) Orders() (orders []types.Order) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, o := range e.orders {
+		orders = append(orders, *o)
+	}
+
+	sort.Slice(orders, func(i, j int) bool { return orders[i].OrderID < orders[j].OrderID })
+	return orders
+}
+
+// OpenOrders returns the working orders ordered by the order id
+func (e *Exchange) OpenOrders() (orders []types.Order) {
+	for _, o := range e.Orders() {
+		if o.IsWorking {
+			orders = append(orders, o)
+		}
+	}
+
+	return orders
+}
+
+// Trades returns the simulated trades
+func (e *Exchange) Trades() []types.Trade {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]types.Trade(nil), e.trades...)
+}
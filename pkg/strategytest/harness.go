@@ -0,0 +1,256 @@
+package strategytest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const DefaultSessionName = "test"
+
+var DefaultStartTime = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Config is the setup of the simulated session
+type Config struct {
+	// Session is the session name that the strategies are attached on, defaults to "test"
+	Session string
+
+	// Exchange is the exchange name of the simulated session, defaults to binance
+	Exchange types.ExchangeName
+
+	Markets  types.MarketMap
+	Balances types.BalanceMap
+
+	MakerFeeRate float64
+	TakerFeeRate float64
+
+	// StartTime is the start time of the environment, the history klines before the start time are loaded
+	// into the market data store and fed to the history bootstrappers
+	StartTime time.Time
+
+	// History is the historical klines returned by the exchange kline query
+	History []types.KLine
+
+	// ShutdownOptions is passed to the shutdown hooks of the strategies on Shutdown
+	ShutdownOptions bbgo.ShutdownOptions
+}
+
+// Harness runs the strategies on a simulated exchange session, the market data are scripted by the test and
+// the submitted orders are matched by the scripted klines, order books or fills, so that the strategy logic
+// can be asserted by the submitted orders, the trades and the strategy state.
+type Harness struct {
+	Config Config
+
+	environ  *bbgo.Environment
+	session  *bbgo.ExchangeSession
+	trader   *bbgo.Trader
+	exchange *Exchange
+
+	marketDataStream *Stream
+}
+
+func New(config Config) *Harness {
+	if len(config.Session) == 0 {
+		config.Session = DefaultSessionName
+	}
+
+	if len(config.Exchange) == 0 {
+		config.Exchange = types.ExchangeBinance
+	}
+
+	if config.StartTime.IsZero() {
+		config.StartTime = DefaultStartTime
+	}
+
+	exchange := NewExchange(config.Exchange, config.Markets, config.Balances)
+	exchange.SetFeeRates(config.MakerFeeRate, config.TakerFeeRate)
+	exchange.AddHistory(config.History...)
+	exchange.setTime(config.StartTime)
+
+	return &Harness{
+		Config:   config,
+		exchange: exchange,
+	}
+}
+
+// Run initializes the simulated session and runs the strategies through the trader,
+// so the strategies are subscribed, injected, validated and bootstrapped in the same way of the bbgo run command.
+func (h *Harness) Run(ctx context.Context, strategies ...bbgo.SingleExchangeStrategy) error {
+	if h.trader != nil {
+		return errors.New("the harness is already running")
+	}
+
+	if len(h.Config.Markets) == 0 {
+		return errors.New("markets are required")
+	}
+
+	environ := bbgo.NewEnvironment()
+	environ.SetStartTime(h.Config.StartTime)
+
+	session := bbgo.NewExchangeSession(h.Config.Session, h.exchange)
+	session.SetMarkets(h.Config.Markets)
+	environ.AddExchangeSession(h.Config.Session, session)
+
+	userDataStream, ok := session.UserDataStream.(*Stream)
+	if !ok {
+		return fmt.Errorf("unexpected user data stream %T", session.UserDataStream)
+	}
+
+	marketDataStream, ok := session.MarketDataStream.(*Stream)
+	if !ok {
+		return fmt.Errorf("unexpected market data stream %T", session.MarketDataStream)
+	}
+
+	h.exchange.BindUserDataStream(userDataStream)
+
+	if err := environ.Init(ctx); err != nil {
+		return err
+	}
+
+	trader := bbgo.NewTrader(environ)
+	trader.DisableLogging()
+	trader.SetShutdownOptions(h.Config.ShutdownOptions)
+	if err := trader.AttachStrategyOn(h.Config.Session, strategies...); err != nil {
+		return err
+	}
+
+	h.environ = environ
+	h.session = session
+	h.trader = trader
+	h.marketDataStream = marketDataStream
+
+	return trader.Run(ctx)
+}
+
+// Play applies the steps in order and stops at the first error
+func (h *Harness) Play(steps ...Step) error {
+	if h.trader == nil {
+		return errors.New("the harness is not running, call Run first")
+	}
+
+	for i, step := range steps {
+		if err := step.Apply(h); err != nil {
+			return errors.Wrapf(err, "step #%d", i)
+		}
+	}
+
+	return nil
+}
+
+// PushKLine matches the open orders by the kline and then emits the closed kline, the market orders are filled at
+// the open price and the limit orders are filled at their prices when the kline range reaches them.
+func (h *Harness) PushKLine(k types.KLine) {
+	h.exchange.setTime(k.EndTime)
+
+	executions := h.exchange.matchPrices(k.Symbol, k.Open, k.Open, k.Low, k.High)
+	h.exchange.publish(executions)
+
+	k.Closed = true
+	h.marketDataStream.EmitKLineClosed(k)
+}
+
+// PushBook matches the open orders by the best prices of the book and then emits the book snapshot,
+// the market orders and the crossed limit orders are filled at the best prices.
+func (h *Harness) PushBook(book types.SliceOrderBook) {
+	// without the asks or the bids, the limit orders of the side can not be matched
+	var marketBuyPrice, marketSellPrice float64
+	var low, high = math.MaxFloat64, 0.0
+
+	if ask, ok := book.BestAsk(); ok {
+		marketBuyPrice = ask.Price.Float64()
+		low = marketBuyPrice
+	}
+
+	if bid, ok := book.BestBid(); ok {
+		marketSellPrice = bid.Price.Float64()
+		high = marketSellPrice
+	}
+
+	// a buy limit order crosses the book when its price is greater than or equal to the best ask
+	executions := h.exchange.matchPrices(book.Symbol, marketBuyPrice, marketSellPrice, low, high)
+	h.exchange.publish(executions)
+
+	h.marketDataStream.EmitBookSnapshot(book)
+}
+
+// PushMarketTrade emits the public market trade
+func (h *Harness) PushMarketTrade(trade types.Trade) {
+	h.marketDataStream.EmitMarketTrade(trade)
+}
+
+// FillOrder fills the order at the price, zero price means the order price and zero quantity means the remaining quantity
+func (h *Harness) FillOrder(orderID uint64, price, quantity float64) error {
+	exec, err := h.exchange.fillOrder(orderID, price, quantity)
+	if err != nil {
+		return err
+	}
+
+	h.exchange.publish([]execution{exec})
+	return nil
+}
+
+// SetTime sets the time of the simulated orders and trades
+func (h *Harness) SetTime(t time.Time) {
+	h.exchange.setTime(t)
+}
+
+// Shutdown runs the shutdown hooks and the graceful shutdown callbacks of the strategies
+func (h *Harness) Shutdown(ctx context.Context) {
+	if h.trader != nil {
+		h.trader.Shutdown(ctx)
+	}
+}
+
+func (h *Harness) Exchange() *Exchange {
+	return h.exchange
+}
+
+func (h *Harness) Environment() *bbgo.Environment {
+	return h.environ
+}
+
+func (h *Harness) Session() *bbgo.ExchangeSession {
+	return h.session
+}
+
+// SubmittedOrders returns the submit orders received by the simulated exchange
+func (h *Harness) SubmittedOrders() []types.SubmitOrder {
+	return h.exchange.SubmittedOrders()
+}
+
+// Orders returns the latest state of the created orders
+func (h *Harness) Orders() []types.Order {
+	return h.exchange.Orders()
+}
+
+// OpenOrders returns the working orders
+func (h *Harness) OpenOrders() []types.Order {
+	return h.exchange.OpenOrders()
+}
+
+// Trades returns the simulated trades
+func (h *Harness) Trades() []types.Trade {
+	return h.exchange.Trades()
+}
+
+// Balances returns the balances of the simulated exchange
+func (h *Harness) Balances() types.BalanceMap {
+	return h.exchange.Balances()
+}
+
+// LoadState loads the persisted strategy state from the memory persistence, e.g., LoadState(&state, ID, symbol, "state-v1")
+func (h *Harness) LoadState(val interface{}, subIDs ...string) error {
+	if h.environ == nil {
+		return errors.New("the harness is not running, call Run first")
+	}
+
+	// the strategies are injected with the default memory store
+	store := h.environ.PersistenceServiceFacade.Memory.NewStore("default", subIDs...)
+	return store.Load(val)
+}
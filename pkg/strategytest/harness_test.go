@@ -0,0 +1,202 @@
+package strategytest
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var testMarket = types.Market{
+	Symbol:          "BTCUSDT",
+	PricePrecision:  2,
+	VolumePrecision: 4,
+	BaseCurrency:    "BTC",
+	QuoteCurrency:   "USDT",
+	MinNotional:     1.0,
+	MinQuantity:     0.0001,
+	StepSize:        0.0001,
+	TickSize:        0.01,
+}
+
+// dipBuyer places a limit buy order below the close price when the price drops under the threshold,
+// and cancels the open orders on shutdown
+type dipBuyer struct {
+	*bbgo.Graceful
+
+	Symbol    string
+	Market    types.Market
+	Threshold float64
+
+	NumOfFilled int
+
+	orderStore *bbgo.OrderStore
+}
+
+func (s *dipBuyer) ID() string {
+	return "dipbuyer"
+}
+
+func (s *dipBuyer) Subscribe(session *bbgo.ExchangeSession) {
+	session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: string(types.Interval1m)})
+}
+
+func (s *dipBuyer) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	s.orderStore = bbgo.NewOrderStore(s.Symbol)
+	s.orderStore.RemoveFilled = true
+	s.orderStore.RemoveCancelled = true
+	s.orderStore.BindStream(session.UserDataStream)
+
+	session.UserDataStream.OnOrderUpdate(func(order types.Order) {
+		if order.Status == types.OrderStatusFilled {
+			s.NumOfFilled++
+		}
+	})
+
+	session.MarketDataStream.OnKLineClosed(func(k types.KLine) {
+		if k.Symbol != s.Symbol || k.Close >= s.Threshold || s.orderStore.NumOfOrders() > 0 {
+			return
+		}
+
+		createdOrders, err := orderExecutor.SubmitOrders(ctx, types.SubmitOrder{
+			Symbol:   s.Symbol,
+			Market:   s.Market,
+			Side:     types.SideTypeBuy,
+			Type:     types.OrderTypeLimit,
+			Price:    k.Close - 1.0,
+			Quantity: 0.1,
+		})
+		if err == nil {
+			s.orderStore.Add(createdOrders...)
+		}
+	})
+
+	s.Graceful.OnShutdown(func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+		_ = session.Exchange.CancelOrders(ctx, s.orderStore.Orders()...)
+	})
+
+	return nil
+}
+
+func newTestHarness() *Harness {
+	return New(Config{
+		Markets: types.MarketMap{"BTCUSDT": testMarket},
+		Balances: types.BalanceMap{
+			"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(1000.0)},
+			"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(1.0)},
+		},
+		MakerFeeRate: 0.001,
+	})
+}
+
+func TestHarness_KLineScenario(t *testing.T) {
+	ctx := context.Background()
+	h := newTestHarness()
+	s := &dipBuyer{Symbol: "BTCUSDT", Threshold: 100.0}
+	if !assert.NoError(t, h.Run(ctx, s)) {
+		return
+	}
+
+	// the market is injected by the trader
+	assert.Equal(t, "USDT", s.Market.QuoteCurrency)
+
+	kLines := KLinesFromPrices("BTCUSDT", types.Interval1m, DefaultStartTime, 101.0, 99.0, 98.5, 97.0)
+
+	err := h.Play(
+		KLines(kLines[:2]...),
+		StepFunc(func(h *Harness) error {
+			assert.Len(t, h.SubmittedOrders(), 1)
+			assert.Equal(t, 98.0, h.SubmittedOrders()[0].Price)
+			assert.Len(t, h.OpenOrders(), 1)
+
+			// the quote amount of the limit buy order is locked
+			usdt := h.Session().Account.Balances()["USDT"]
+			assert.Equal(t, 9.8, usdt.Locked.Float64())
+			return nil
+		}),
+
+		// the low price 98.5 does not reach the order price
+		KLines(kLines[2]),
+		StepFunc(func(h *Harness) error {
+			assert.Len(t, h.Trades(), 0)
+			return nil
+		}),
+
+		KLines(kLines[3]),
+	)
+	assert.NoError(t, err)
+
+	trades := h.Trades()
+	if assert.Len(t, trades, 1) {
+		assert.Equal(t, 98.0, trades[0].Price)
+		assert.True(t, trades[0].IsMaker)
+		assert.InDelta(t, 0.0098, trades[0].Fee, 1e-9)
+	}
+	assert.Equal(t, 1, s.NumOfFilled)
+
+	balances := h.Balances()
+	assert.InDelta(t, 1.1, balances["BTC"].Available.Float64(), 1e-8)
+
+	// another buy order is placed at 96 after the fill
+	assert.Len(t, h.SubmittedOrders(), 2)
+	assert.Len(t, h.OpenOrders(), 1)
+
+	h.Shutdown(ctx)
+	assert.Len(t, h.OpenOrders(), 0)
+
+	orders := h.Orders()
+	assert.Equal(t, types.OrderStatusCanceled, orders[len(orders)-1].Status)
+
+	usdt := h.Session().Account.Balances()["USDT"]
+	assert.Equal(t, 0.0, usdt.Locked.Float64())
+	assert.InDelta(t, 1000.0-9.8-0.0098, usdt.Available.Float64(), 1e-8)
+}
+
+func TestHarness_BookAndFillScenario(t *testing.T) {
+	ctx := context.Background()
+	h := newTestHarness()
+	s := &dipBuyer{Symbol: "BTCUSDT", Threshold: 0}
+	if !assert.NoError(t, h.Run(ctx, s)) {
+		return
+	}
+
+	createdOrders, err := h.Session().OrderExecutor.SubmitOrders(ctx,
+		types.SubmitOrder{Symbol: "BTCUSDT", Market: testMarket, Side: types.SideTypeSell, Type: types.OrderTypeLimit, Price: 105.0, Quantity: 0.5},
+		types.SubmitOrder{Symbol: "BTCUSDT", Market: testMarket, Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 90.0, Quantity: 1.0},
+	)
+	if !assert.NoError(t, err) || !assert.Len(t, createdOrders, 2) {
+		return
+	}
+
+	// selling more than the available base balance is rejected
+	_, err = h.Session().OrderExecutor.SubmitOrders(ctx,
+		types.SubmitOrder{Symbol: "BTCUSDT", Market: testMarket, Side: types.SideTypeSell, Type: types.OrderTypeMarket, Quantity: 1.0})
+	assert.Error(t, err)
+
+	err = h.Play(
+		// the best bid reaches the sell order price
+		Book(NewBook("BTCUSDT", [][2]float64{{104.0, 1.0}, {105.0, 1.0}}, [][2]float64{{106.0, 1.0}})),
+		Fill(createdOrders[1].OrderID, 0, 0.4),
+	)
+	assert.NoError(t, err)
+
+	orders := h.Orders()
+	if assert.Len(t, orders, 2) {
+		assert.Equal(t, types.OrderStatusFilled, orders[0].Status)
+		assert.Equal(t, types.OrderStatusPartiallyFilled, orders[1].Status)
+		assert.Equal(t, 0.4, orders[1].ExecutedQuantity)
+	}
+
+	balances := h.Balances()
+	assert.InDelta(t, 0.9, balances["BTC"].Available.Float64(), 1e-8)
+	assert.InDelta(t, 54.0, balances["USDT"].Locked.Float64(), 1e-8)
+
+	// the filled order can not be filled again
+	assert.Error(t, h.FillOrder(createdOrders[0].OrderID, 0, 0))
+}
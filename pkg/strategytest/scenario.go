@@ -0,0 +1,131 @@
+package strategytest
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// Step is one scripted event of the scenario
+type Step interface {
+	Apply(h *Harness) error
+}
+
+// StepFunc is the custom step, e.g., the assertion in the middle of the scenario
+type StepFunc func(h *Harness) error
+
+func (f StepFunc) Apply(h *Harness) error {
+	return f(h)
+}
+
+type kLineStep struct {
+	kLines []types.KLine
+}
+
+func (s kLineStep) Apply(h *Harness) error {
+	for _, k := range s.kLines {
+		h.PushKLine(k)
+	}
+
+	return nil
+}
+
+// KLines pushes the closed klines one by one
+func KLines(kLines ...types.KLine) Step {
+	return kLineStep{kLines: kLines}
+}
+
+type bookStep struct {
+	book types.SliceOrderBook
+}
+
+func (s bookStep) Apply(h *Harness) error {
+	h.PushBook(s.book)
+	return nil
+}
+
+// Book pushes the order book snapshot
+func Book(book types.SliceOrderBook) Step {
+	return bookStep{book: book}
+}
+
+type fillStep struct {
+	orderID         uint64
+	price, quantity float64
+}
+
+func (s fillStep) Apply(h *Harness) error {
+	return h.FillOrder(s.orderID, s.price, s.quantity)
+}
+
+// Fill fills the order, zero price means the order price and zero quantity means the remaining quantity
+func Fill(orderID uint64, price, quantity float64) Step {
+	return fillStep{orderID: orderID, price: price, quantity: quantity}
+}
+
+type marketTradeStep struct {
+	trades []types.Trade
+}
+
+func (s marketTradeStep) Apply(h *Harness) error {
+	for _, trade := range s.trades {
+		h.PushMarketTrade(trade)
+	}
+
+	return nil
+}
+
+// MarketTrades pushes the public market trades
+func MarketTrades(trades ...types.Trade) Step {
+	return marketTradeStep{trades: trades}
+}
+
+// KLinesFromPrices builds the consecutive klines from the close prices, the open price of each kline is the previous
+// close price, so the high and the low prices cover the price move between the klines.
+func KLinesFromPrices(symbol string, interval types.Interval, startTime time.Time, prices ...float64) []types.KLine {
+	var kLines []types.KLine
+	var duration = interval.Duration()
+
+	for i, price := range prices {
+		open := price
+		if i > 0 {
+			open = prices[i-1]
+		}
+
+		kLineStartTime := startTime.Add(time.Duration(i) * duration)
+		kLines = append(kLines, types.KLine{
+			Symbol:    symbol,
+			Interval:  interval,
+			StartTime: kLineStartTime,
+			EndTime:   kLineStartTime.Add(duration - time.Millisecond),
+			Open:      open,
+			Close:     price,
+			High:      math.Max(open, price),
+			Low:       math.Min(open, price),
+			Volume:    1.0,
+			Closed:    true,
+		})
+	}
+
+	return kLines
+}
+
+// NewBook builds the order book snapshot from the price and volume pairs,
+// the bids are sorted from the highest price and the asks are sorted from the lowest price.
+func NewBook(symbol string, bids, asks [][2]float64) types.SliceOrderBook {
+	book := types.SliceOrderBook{Symbol: symbol}
+	for _, pv := range bids {
+		book.Bids = append(book.Bids, types.PriceVolume{Price: fixedpoint.NewFromFloat(pv[0]), Volume: fixedpoint.NewFromFloat(pv[1])})
+	}
+
+	for _, pv := range asks {
+		book.Asks = append(book.Asks, types.PriceVolume{Price: fixedpoint.NewFromFloat(pv[0]), Volume: fixedpoint.NewFromFloat(pv[1])})
+	}
+
+	sort.Slice(book.Bids, func(i, j int) bool { return book.Bids[i].Price > book.Bids[j].Price })
+	sort.Slice(book.Asks, func(i, j int) bool { return book.Asks[i].Price < book.Asks[j].Price })
+	return book
+}
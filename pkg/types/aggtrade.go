@@ -0,0 +1,36 @@
+package types
+
+// AggTrade is the aggregated public trade, the trades that are filled at the same time, same price and same taker side
+// are aggregated into one record. It's used for building the backtest datasets from the public data archives.
+type AggTrade struct {
+	GID          int64        `json:"gid" db:"gid"`
+	ID           int64        `json:"id" db:"id"`
+	Exchange     ExchangeName `json:"exchange" db:"exchange"`
+	Symbol       string       `json:"symbol" db:"symbol"`
+	Price        float64      `json:"price" db:"price"`
+	Quantity     float64      `json:"quantity" db:"quantity"`
+	FirstTradeID int64        `json:"firstTradeID" db:"first_trade_id"`
+	LastTradeID  int64        `json:"lastTradeID" db:"last_trade_id"`
+	IsBuyerMaker bool         `json:"isBuyerMaker" db:"is_buyer_maker"`
+	Time         Time         `json:"tradedAt" db:"traded_at"`
+}
+
+// Trade converts the aggregated trade to the market trade, the side is the taker side
+func (t AggTrade) Trade() Trade {
+	side := SideTypeBuy
+	if t.IsBuyerMaker {
+		side = SideTypeSell
+	}
+
+	return Trade{
+		ID:            t.LastTradeID,
+		Exchange:      t.Exchange,
+		Symbol:        t.Symbol,
+		Side:          side,
+		Price:         t.Price,
+		Quantity:      t.Quantity,
+		QuoteQuantity: t.Price * t.Quantity,
+		IsBuyer:       side == SideTypeBuy,
+		Time:          t.Time,
+	}
+}
@@ -0,0 +1,29 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggTrade_Trade(t *testing.T) {
+	tradeTime := Time(time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC))
+	trade := AggTrade{
+		ID:           10,
+		Exchange:     ExchangeBinance,
+		Symbol:       "BTCUSDT",
+		Price:        100.0,
+		Quantity:     2.0,
+		FirstTradeID: 20,
+		LastTradeID:  22,
+		IsBuyerMaker: true,
+		Time:         tradeTime,
+	}.Trade()
+
+	assert.Equal(t, int64(22), trade.ID)
+	assert.Equal(t, SideTypeSell, trade.Side)
+	assert.False(t, trade.IsBuyer)
+	assert.Equal(t, 200.0, trade.QuoteQuantity)
+	assert.Equal(t, tradeTime, trade.Time)
+}
@@ -0,0 +1,16 @@
+package types
+
+// BalanceSnapshot is the total value of the session balances in the reference currency at the time,
+// which is recorded in the balance_snapshots table
+type BalanceSnapshot struct {
+	GID int64 `json:"gid" db:"gid"`
+
+	Session  string       `json:"session" db:"session"`
+	Exchange ExchangeName `json:"exchange" db:"exchange"`
+
+	// Currency is the reference currency of the value, e.g., USDT
+	Currency string  `json:"currency" db:"currency"`
+	Value    float64 `json:"value" db:"value"`
+
+	Time Time `json:"time" db:"time"`
+}
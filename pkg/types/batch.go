@@ -0,0 +1,37 @@
+package types
+
+// SubmitOrderBatches splits the orders into the batches of the given size for the batch order endpoints
+func SubmitOrderBatches(orders []SubmitOrder, size int) (batches [][]SubmitOrder) {
+	if size <= 0 {
+		return [][]SubmitOrder{orders}
+	}
+
+	for len(orders) > size {
+		batches = append(batches, orders[:size])
+		orders = orders[size:]
+	}
+
+	if len(orders) > 0 {
+		batches = append(batches, orders)
+	}
+
+	return batches
+}
+
+// OrderBatches splits the orders into the batches of the given size for the batch cancel endpoints
+func OrderBatches(orders []Order, size int) (batches [][]Order) {
+	if size <= 0 {
+		return [][]Order{orders}
+	}
+
+	for len(orders) > size {
+		batches = append(batches, orders[:size])
+		orders = orders[size:]
+	}
+
+	if len(orders) > 0 {
+		batches = append(batches, orders)
+	}
+
+	return batches
+}
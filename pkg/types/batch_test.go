@@ -0,0 +1,29 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubmitOrderBatches(t *testing.T) {
+	orders := make([]SubmitOrder, 5)
+	batches := SubmitOrderBatches(orders, 2)
+	if assert.Len(t, batches, 3) {
+		assert.Len(t, batches[0], 2)
+		assert.Len(t, batches[1], 2)
+		assert.Len(t, batches[2], 1)
+	}
+
+	assert.Len(t, SubmitOrderBatches(orders, 0), 1)
+	assert.Len(t, SubmitOrderBatches(nil, 2), 0)
+}
+
+func TestOrderBatches(t *testing.T) {
+	orders := make([]Order, 4)
+	batches := OrderBatches(orders, 2)
+	if assert.Len(t, batches, 2) {
+		assert.Len(t, batches[0], 2)
+		assert.Len(t, batches[1], 2)
+	}
+}
@@ -0,0 +1,108 @@
+package types
+
+import "strings"
+
+// ExchangeCapabilities describes the features supported by an exchange integration,
+// strategies and the order executor can check the capabilities and adapt their behavior instead of failing at runtime.
+type ExchangeCapabilities struct {
+	Margin         bool `json:"margin"`
+	IsolatedMargin bool `json:"isolatedMargin"`
+	Futures        bool `json:"futures"`
+
+	// OCO is the one-cancels-the-other order support
+	OCO bool `json:"oco"`
+
+	// BatchOrders means the orders are submitted or canceled in one request
+	BatchOrders bool `json:"batchOrders"`
+
+	WebSocketKLines bool `json:"webSocketKLines"`
+	WebSocketBook   bool `json:"webSocketBook"`
+
+	Withdrawal bool `json:"withdrawal"`
+
+	// WalletTransfer is the transfer between the spot, margin and futures wallets
+	WalletTransfer bool `json:"walletTransfer"`
+
+	// DepositAddress is the deposit address query
+	DepositAddress bool `json:"depositAddress"`
+
+	// OrderTypes is the supported order types, empty means the supported order types are unknown
+	OrderTypes []OrderType `json:"orderTypes,omitempty"`
+
+	// TimeInForces is the supported time-in-force values of the limit orders, empty means the supported values are unknown
+	TimeInForces []string `json:"timeInForces,omitempty"`
+}
+
+// ExchangeCapabilityProvider is implemented by the exchanges that declare their capabilities
+type ExchangeCapabilityProvider interface {
+	Capabilities() ExchangeCapabilities
+}
+
+// SupportsOrderType checks if the order type is supported, an empty order type list is treated as "unknown",
+// and all the order types are allowed in that case.
+func (c ExchangeCapabilities) SupportsOrderType(orderType OrderType) bool {
+	if len(c.OrderTypes) == 0 {
+		return true
+	}
+
+	for _, t := range c.OrderTypes {
+		if t == orderType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SupportsTimeInForce checks if the time in force is supported, an empty time in force means the exchange default (GTC),
+// and an empty time-in-force list is treated as "unknown" like the order types.
+func (c ExchangeCapabilities) SupportsTimeInForce(timeInForce string) bool {
+	if len(timeInForce) == 0 || len(c.TimeInForces) == 0 {
+		return true
+	}
+
+	for _, t := range c.TimeInForces {
+		if strings.EqualFold(t, timeInForce) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SupportsPostOnly checks if the post-only limit orders are supported,
+// the post-only flag is mapped to the limit maker order or the native post-only parameter of the exchange.
+func (c ExchangeCapabilities) SupportsPostOnly() bool {
+	return c.SupportsOrderType(OrderTypeLimitMaker)
+}
+
+// GetExchangeCapabilities returns the declared capabilities of the exchange,
+// if the exchange does not declare the capabilities, the capabilities are inferred from the implemented interfaces.
+func GetExchangeCapabilities(exchange Exchange) ExchangeCapabilities {
+	if provider, ok := exchange.(ExchangeCapabilityProvider); ok {
+		return provider.Capabilities()
+	}
+
+	var capabilities ExchangeCapabilities
+	if _, ok := exchange.(MarginExchange); ok {
+		capabilities.Margin = true
+	}
+
+	if _, ok := exchange.(FuturesExchange); ok {
+		capabilities.Futures = true
+	}
+
+	if _, ok := exchange.(ExchangeWithdrawalService); ok {
+		capabilities.Withdrawal = true
+	}
+
+	if _, ok := exchange.(ExchangeWalletTransferService); ok {
+		capabilities.WalletTransfer = true
+	}
+
+	if _, ok := exchange.(ExchangeDepositAddressService); ok {
+		capabilities.DepositAddress = true
+	}
+
+	return capabilities
+}
@@ -0,0 +1,70 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testMarginExchange struct {
+	Exchange
+	MarginSettings
+}
+
+type testCapabilityExchange struct {
+	Exchange
+}
+
+func (e *testCapabilityExchange) Capabilities() ExchangeCapabilities {
+	return ExchangeCapabilities{
+		BatchOrders: true,
+		OrderTypes:  []OrderType{OrderTypeLimit, OrderTypeMarket},
+	}
+}
+
+func TestExchangeCapabilities_SupportsOrderType(t *testing.T) {
+	var unknown ExchangeCapabilities
+	assert.True(t, unknown.SupportsOrderType(OrderTypeStopLimit))
+
+	capabilities := ExchangeCapabilities{OrderTypes: []OrderType{OrderTypeLimit, OrderTypeMarket}}
+	assert.True(t, capabilities.SupportsOrderType(OrderTypeLimit))
+	assert.False(t, capabilities.SupportsOrderType(OrderTypeLimitMaker))
+}
+
+func TestExchangeCapabilities_SupportsTimeInForce(t *testing.T) {
+	var unknown ExchangeCapabilities
+	assert.True(t, unknown.SupportsTimeInForce(TimeInForceFOK))
+	assert.True(t, unknown.SupportsPostOnly())
+
+	capabilities := ExchangeCapabilities{
+		OrderTypes:   []OrderType{OrderTypeLimit, OrderTypeMarket},
+		TimeInForces: []string{TimeInForceGTC, TimeInForceIOC},
+	}
+	assert.True(t, capabilities.SupportsTimeInForce(""))
+	assert.True(t, capabilities.SupportsTimeInForce("ioc"))
+	assert.False(t, capabilities.SupportsTimeInForce(TimeInForceFOK))
+	assert.False(t, capabilities.SupportsPostOnly())
+}
+
+func TestGetExchangeCapabilities(t *testing.T) {
+	capabilities := GetExchangeCapabilities(&testCapabilityExchange{})
+	assert.True(t, capabilities.BatchOrders)
+	assert.False(t, capabilities.SupportsOrderType(OrderTypeStopMarket))
+
+	// inferred from the implemented interfaces
+	capabilities = GetExchangeCapabilities(&testMarginExchange{})
+	assert.True(t, capabilities.Margin)
+	assert.False(t, capabilities.Futures)
+	assert.False(t, capabilities.Withdrawal)
+	assert.False(t, capabilities.WalletTransfer)
+	assert.False(t, capabilities.DepositAddress)
+}
+
+func TestParseWalletType(t *testing.T) {
+	walletType, err := ParseWalletType(" Margin")
+	assert.NoError(t, err)
+	assert.Equal(t, WalletTypeMargin, walletType)
+
+	_, err = ParseWalletType("funding")
+	assert.Error(t, err)
+}
@@ -0,0 +1,86 @@
+package types
+
+import (
+	"database/sql"
+	"encoding/json"
+)
+
+// CommandType is the type of the control command queued for the trader
+type CommandType string
+
+const (
+	CommandTypePauseStrategy   CommandType = "pause_strategy"
+	CommandTypeSuspendStrategy CommandType = "suspend_strategy"
+	CommandTypeResumeStrategy  CommandType = "resume_strategy"
+	CommandTypeCancelOrder     CommandType = "cancel_order"
+	CommandTypeSetParameter    CommandType = "set_parameter"
+
+	// CommandTypeResetKillSwitch resumes the order submission halted by the kill switch
+	CommandTypeResetKillSwitch CommandType = "reset_kill_switch"
+
+	// CommandTypeHalt trips the kill switch manually, the order submission is halted until the kill switch is reset
+	CommandTypeHalt CommandType = "halt"
+
+	// CommandTypeClosePosition closes the session position of the symbol by a market order
+	CommandTypeClosePosition CommandType = "close_position"
+
+	// CommandTypeCancelAllOrders cancels all the open orders of the symbol
+	CommandTypeCancelAllOrders CommandType = "cancel_all_orders"
+)
+
+type CommandStatus string
+
+const (
+	CommandStatusPending CommandStatus = "pending"
+	CommandStatusDone    CommandStatus = "done"
+	CommandStatusFailed  CommandStatus = "failed"
+)
+
+// Command is the control command stored in the command queue, the command is executed once the trader is running,
+// so the commands issued while the bot is restarting are not lost.
+type Command struct {
+	GID int64 `json:"gid" db:"gid"`
+
+	Type CommandType `json:"type" db:"type"`
+
+	// Strategy is the ID (or the instance ID) of the target strategy
+	Strategy string `json:"strategy" db:"strategy"`
+
+	// Payload is the json payload of the command, e.g., CancelOrderCommandPayload
+	Payload json.RawMessage `json:"payload" db:"payload"`
+
+	Status CommandStatus  `json:"status" db:"status"`
+	Error  sql.NullString `json:"error" db:"error"`
+
+	// Source is where the command is issued, e.g., api, telegram
+	Source string `json:"source" db:"source"`
+
+	CreatedAt   Time  `json:"createdAt" db:"created_at"`
+	ProcessedAt *Time `json:"processedAt,omitempty" db:"processed_at"`
+}
+
+type CancelOrderCommandPayload struct {
+	Session string `json:"session"`
+	Symbol  string `json:"symbol"`
+	OrderID uint64 `json:"orderID"`
+}
+
+type CancelAllOrdersCommandPayload struct {
+	// Session is the target session, the orders are canceled on all the sessions that have the market if it's empty
+	Session string `json:"session,omitempty"`
+	Symbol  string `json:"symbol"`
+}
+
+type SetParameterCommandPayload struct {
+	// Parameter is the json field name of the strategy parameter, e.g., quantity
+	Parameter string          `json:"parameter"`
+	Value     json.RawMessage `json:"value"`
+}
+
+type ClosePositionCommandPayload struct {
+	Session string `json:"session"`
+	Symbol  string `json:"symbol"`
+
+	// Percentage is the percentage of the position to close, defaults to 1.0 (100%)
+	Percentage float64 `json:"percentage,omitempty"`
+}
@@ -0,0 +1,64 @@
+package types
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io/ioutil"
+
+	"github.com/gorilla/websocket"
+)
+
+// CompressionSetter is implemented by the streams that support the websocket compression,
+// SetCompression must be called before the stream is connected.
+type CompressionSetter interface {
+	SetCompression(enabled bool)
+}
+
+// NewDialer returns a websocket dialer, if compression is true,
+// the permessage-deflate extension is negotiated with the server and the messages are decompressed transparently.
+func NewDialer(compression bool) *websocket.Dialer {
+	dialer := *websocket.DefaultDialer
+	dialer.EnableCompression = compression
+	return &dialer
+}
+
+// DecompressMessage decompresses the gzip or zlib compressed binary message (e.g., Huobi sends gzip compressed binary frames),
+// the decompressed message is returned as a text message. Other messages are returned as is.
+func DecompressMessage(messageType int, message []byte) (int, []byte, error) {
+	if messageType != websocket.BinaryMessage || len(message) < 2 {
+		return messageType, message, nil
+	}
+
+	switch {
+	case message[0] == 0x1f && message[1] == 0x8b:
+		reader, err := gzip.NewReader(bytes.NewReader(message))
+		if err != nil {
+			return messageType, message, err
+		}
+		defer reader.Close()
+
+		data, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return messageType, message, err
+		}
+
+		return websocket.TextMessage, data, nil
+
+	case message[0] == 0x78 && (uint16(message[0])<<8|uint16(message[1]))%31 == 0:
+		reader, err := zlib.NewReader(bytes.NewReader(message))
+		if err != nil {
+			return messageType, message, err
+		}
+		defer reader.Close()
+
+		data, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return messageType, message, err
+		}
+
+		return websocket.TextMessage, data, nil
+	}
+
+	return messageType, message, nil
+}
@@ -0,0 +1,51 @@
+package types
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecompressMessage(t *testing.T) {
+	payload := []byte(`{"ping":1492420473027}`)
+
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	_, _ = gw.Write(payload)
+	_ = gw.Close()
+
+	mt, message, err := DecompressMessage(websocket.BinaryMessage, gzipped.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, websocket.TextMessage, mt)
+	assert.Equal(t, payload, message)
+
+	var zipped bytes.Buffer
+	zw := zlib.NewWriter(&zipped)
+	_, _ = zw.Write(payload)
+	_ = zw.Close()
+
+	mt, message, err = DecompressMessage(websocket.BinaryMessage, zipped.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, websocket.TextMessage, mt)
+	assert.Equal(t, payload, message)
+
+	// text messages are returned as is
+	mt, message, err = DecompressMessage(websocket.TextMessage, payload)
+	assert.NoError(t, err)
+	assert.Equal(t, websocket.TextMessage, mt)
+	assert.Equal(t, payload, message)
+
+	// corrupted gzip message
+	_, _, err = DecompressMessage(websocket.BinaryMessage, []byte{0x1f, 0x8b, 0x00})
+	assert.Error(t, err)
+}
+
+func TestNewDialer(t *testing.T) {
+	assert.True(t, NewDialer(true).EnableCompression)
+	assert.False(t, NewDialer(false).EnableCompression)
+	assert.False(t, websocket.DefaultDialer.EnableCompression, "the default dialer should not be modified")
+}
@@ -0,0 +1,218 @@
+package types
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/sigchan"
+)
+
+// SourceVolume is the volume of a price level provided by the source (the exchange session)
+type SourceVolume struct {
+	Source string           `json:"source"`
+	Volume fixedpoint.Value `json:"volume"`
+}
+
+// ConsolidatedPriceLevel is the merged price level of the sources, Volume is the total volume of the sources
+type ConsolidatedPriceLevel struct {
+	Price   fixedpoint.Value `json:"price"`
+	Volume  fixedpoint.Value `json:"volume"`
+	Sources []SourceVolume   `json:"sources"`
+}
+
+// BestSource returns the source with the largest volume of the level
+func (l ConsolidatedPriceLevel) BestSource() (SourceVolume, bool) {
+	if len(l.Sources) == 0 {
+		return SourceVolume{}, false
+	}
+
+	best := l.Sources[0]
+	for _, s := range l.Sources[1:] {
+		if s.Volume > best.Volume {
+			best = s
+		}
+	}
+
+	return best, true
+}
+
+// ConsolidatedBook merges the order books of the same symbol from multiple sources (usually the exchange sessions),
+// so that the cross-exchange strategies can see the liquidity in one view. Each level keeps the volumes of the sources.
+type ConsolidatedBook struct {
+	Symbol string
+
+	// C is signaled when any of the source books is changed
+	C sigchan.Chan
+
+	mu    sync.Mutex
+	books map[string]*RBTOrderBook
+}
+
+func NewConsolidatedBook(symbol string) *ConsolidatedBook {
+	return &ConsolidatedBook{
+		Symbol: symbol,
+		C:      sigchan.New(60),
+		books:  make(map[string]*RBTOrderBook),
+	}
+}
+
+func (b *ConsolidatedBook) book(source string) *RBTOrderBook {
+	book, ok := b.books[source]
+	if !ok {
+		book = NewRBOrderBook(b.Symbol)
+		b.books[source] = book
+	}
+	return book
+}
+
+// Load replaces the book of the source with the snapshot
+func (b *ConsolidatedBook) Load(source string, snapshot SliceOrderBook) {
+	b.mu.Lock()
+	b.book(source).Load(snapshot)
+	b.mu.Unlock()
+	b.C.Emit()
+}
+
+// Update applies the depth update to the book of the source
+func (b *ConsolidatedBook) Update(source string, update SliceOrderBook) {
+	b.mu.Lock()
+	b.book(source).Update(update)
+	b.mu.Unlock()
+	b.C.Emit()
+}
+
+// Remove removes the book of the source, e.g., when the stream of the source is disconnected
+func (b *ConsolidatedBook) Remove(source string) {
+	b.mu.Lock()
+	delete(b.books, source)
+	b.mu.Unlock()
+	b.C.Emit()
+}
+
+// Sources returns the sorted source names of the book
+func (b *ConsolidatedBook) Sources() (sources []string) {
+	b.mu.Lock()
+	for source := range b.books {
+		sources = append(sources, source)
+	}
+	b.mu.Unlock()
+
+	sort.Strings(sources)
+	return sources
+}
+
+// BindStream updates the book of the source with the order book events of the stream
+func (b *ConsolidatedBook) BindStream(source string, stream StandardStreamEventHub) {
+	stream.OnBookSnapshot(func(book SliceOrderBook) {
+		if book.Symbol != b.Symbol {
+			return
+		}
+
+		b.Load(source, book)
+	})
+
+	stream.OnBookUpdate(func(book SliceOrderBook) {
+		if book.Symbol != b.Symbol {
+			return
+		}
+
+		b.Update(source, book)
+	})
+
+	stream.OnDisconnect(func() {
+		b.Remove(source)
+	})
+}
+
+// BestBid returns the highest bid price level of all the sources
+func (b *ConsolidatedBook) BestBid() (ConsolidatedPriceLevel, bool) {
+	levels := b.Bids(1)
+	if len(levels) == 0 {
+		return ConsolidatedPriceLevel{}, false
+	}
+
+	return levels[0], true
+}
+
+// BestAsk returns the lowest ask price level of all the sources
+func (b *ConsolidatedBook) BestAsk() (ConsolidatedPriceLevel, bool) {
+	levels := b.Asks(1)
+	if len(levels) == 0 {
+		return ConsolidatedPriceLevel{}, false
+	}
+
+	return levels[0], true
+}
+
+// Spread returns the spread between the consolidated best ask and best bid,
+// a negative spread means the books of the sources are crossed (an arbitrage opportunity).
+func (b *ConsolidatedBook) Spread() (fixedpoint.Value, bool) {
+	bid, ok := b.BestBid()
+	if !ok {
+		return 0, false
+	}
+
+	ask, ok := b.BestAsk()
+	if !ok {
+		return 0, false
+	}
+
+	return ask.Price - bid.Price, true
+}
+
+// Bids returns the merged bid levels in descending order, zero depth means the full depth
+func (b *ConsolidatedBook) Bids(depth int) []ConsolidatedPriceLevel {
+	return b.merge(SideTypeBuy, depth)
+}
+
+// Asks returns the merged ask levels in ascending order, zero depth means the full depth
+func (b *ConsolidatedBook) Asks(depth int) []ConsolidatedPriceLevel {
+	return b.merge(SideTypeSell, depth)
+}
+
+func (b *ConsolidatedBook) merge(side SideType, depth int) []ConsolidatedPriceLevel {
+	var levels = make(map[fixedpoint.Value]*ConsolidatedPriceLevel)
+
+	b.mu.Lock()
+	for source, book := range b.books {
+		var sideBook OrderBook = book
+		if depth > 0 {
+			// the top levels of the merged book are always within the top levels of each source
+			sideBook = book.CopyDepth(depth)
+		}
+
+		for _, pv := range sideBook.SideBook(side) {
+			level, ok := levels[pv.Price]
+			if !ok {
+				level = &ConsolidatedPriceLevel{Price: pv.Price}
+				levels[pv.Price] = level
+			}
+
+			level.Volume += pv.Volume
+			level.Sources = append(level.Sources, SourceVolume{Source: source, Volume: pv.Volume})
+		}
+	}
+	b.mu.Unlock()
+
+	var merged = make([]ConsolidatedPriceLevel, 0, len(levels))
+	for _, level := range levels {
+		sort.Slice(level.Sources, func(i, j int) bool {
+			return level.Sources[i].Source < level.Sources[j].Source
+		})
+		merged = append(merged, *level)
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		if side == SideTypeBuy {
+			return merged[i].Price > merged[j].Price
+		}
+		return merged[i].Price < merged[j].Price
+	})
+
+	if depth > 0 && len(merged) > depth {
+		merged = merged[:depth]
+	}
+
+	return merged
+}
@@ -0,0 +1,114 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func TestConsolidatedBook(t *testing.T) {
+	book := NewConsolidatedBook("BTCUSDT")
+
+	book.Load("binance", SliceOrderBook{
+		Symbol: "BTCUSDT",
+		Bids: PriceVolumeSlice{
+			{Price: fixedpoint.NewFromFloat(100.0), Volume: fixedpoint.NewFromFloat(1.0)},
+			{Price: fixedpoint.NewFromFloat(99.0), Volume: fixedpoint.NewFromFloat(2.0)},
+		},
+		Asks: PriceVolumeSlice{
+			{Price: fixedpoint.NewFromFloat(102.0), Volume: fixedpoint.NewFromFloat(1.0)},
+			{Price: fixedpoint.NewFromFloat(103.0), Volume: fixedpoint.NewFromFloat(2.0)},
+		},
+	})
+
+	book.Load("max", SliceOrderBook{
+		Symbol: "BTCUSDT",
+		Bids: PriceVolumeSlice{
+			{Price: fixedpoint.NewFromFloat(100.0), Volume: fixedpoint.NewFromFloat(3.0)},
+			{Price: fixedpoint.NewFromFloat(98.0), Volume: fixedpoint.NewFromFloat(1.0)},
+		},
+		Asks: PriceVolumeSlice{
+			{Price: fixedpoint.NewFromFloat(101.0), Volume: fixedpoint.NewFromFloat(0.5)},
+		},
+	})
+
+	assert.Equal(t, []string{"binance", "max"}, book.Sources())
+
+	bids := book.Bids(0)
+	if assert.Len(t, bids, 3) {
+		assert.Equal(t, fixedpoint.NewFromFloat(100.0), bids[0].Price)
+		assert.Equal(t, fixedpoint.NewFromFloat(4.0), bids[0].Volume)
+		assert.Equal(t, []SourceVolume{
+			{Source: "binance", Volume: fixedpoint.NewFromFloat(1.0)},
+			{Source: "max", Volume: fixedpoint.NewFromFloat(3.0)},
+		}, bids[0].Sources)
+
+		best, ok := bids[0].BestSource()
+		assert.True(t, ok)
+		assert.Equal(t, "max", best.Source)
+
+		assert.Equal(t, fixedpoint.NewFromFloat(99.0), bids[1].Price)
+		assert.Equal(t, fixedpoint.NewFromFloat(98.0), bids[2].Price)
+	}
+
+	asks := book.Asks(2)
+	if assert.Len(t, asks, 2) {
+		assert.Equal(t, fixedpoint.NewFromFloat(101.0), asks[0].Price)
+		assert.Equal(t, "max", asks[0].Sources[0].Source)
+		assert.Equal(t, fixedpoint.NewFromFloat(102.0), asks[1].Price)
+		assert.Equal(t, "binance", asks[1].Sources[0].Source)
+	}
+
+	spread, ok := book.Spread()
+	assert.True(t, ok)
+	assert.Equal(t, fixedpoint.NewFromFloat(1.0), spread)
+
+	// the best ask of max is taken
+	book.Update("max", SliceOrderBook{
+		Symbol: "BTCUSDT",
+		Asks: PriceVolumeSlice{
+			{Price: fixedpoint.NewFromFloat(101.0), Volume: 0},
+		},
+	})
+
+	ask, ok := book.BestAsk()
+	assert.True(t, ok)
+	assert.Equal(t, fixedpoint.NewFromFloat(102.0), ask.Price)
+
+	book.Remove("binance")
+	assert.Equal(t, []string{"max"}, book.Sources())
+
+	bid, ok := book.BestBid()
+	assert.True(t, ok)
+	assert.Equal(t, fixedpoint.NewFromFloat(3.0), bid.Volume)
+
+	_, ok = book.BestAsk()
+	assert.False(t, ok)
+}
+
+func TestConsolidatedBook_BindStream(t *testing.T) {
+	stream := &StandardStream{}
+	book := NewConsolidatedBook("BTCUSDT")
+	book.BindStream("binance", stream)
+
+	stream.EmitBookSnapshot(SliceOrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(100.0), Volume: fixedpoint.NewFromFloat(1.0)}},
+	})
+
+	// other symbols are ignored
+	stream.EmitBookUpdate(SliceOrderBook{
+		Symbol: "ETHUSDT",
+		Bids:   PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(4000.0), Volume: fixedpoint.NewFromFloat(1.0)}},
+	})
+
+	bid, ok := book.BestBid()
+	assert.True(t, ok)
+	assert.Equal(t, fixedpoint.NewFromFloat(100.0), bid.Price)
+	assert.Len(t, book.C, 1)
+
+	stream.EmitDisconnect()
+	assert.Empty(t, book.Sources())
+}
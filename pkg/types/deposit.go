@@ -1,6 +1,7 @@
 package types
 
 import (
+	"context"
 	"time"
 )
 
@@ -36,3 +37,17 @@ type Deposit struct {
 func (d Deposit) EffectiveTime() time.Time {
 	return d.Time.Time()
 }
+
+// DepositAddress is the deposit address of an asset on a network
+type DepositAddress struct {
+	Asset      string `json:"asset"`
+	Network    string `json:"network,omitempty"`
+	Address    string `json:"address"`
+	AddressTag string `json:"addressTag,omitempty"`
+}
+
+// ExchangeDepositAddressService queries the deposit address of the asset, the empty network means the default network
+// of the asset
+type ExchangeDepositAddressService interface {
+	QueryDepositAddress(ctx context.Context, asset, network string) (*DepositAddress, error)
+}
@@ -0,0 +1,234 @@
+package types
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DepthUpdate is the depth diff update with the update id range,
+// the update ids are zero if the exchange does not provide the sequence of the updates.
+type DepthUpdate struct {
+	FirstUpdateID int64
+	FinalUpdateID int64
+
+	// PreviousUpdateID is the final update id of the previous update, it's set by the exchanges chaining the updates
+	// by the previous update id instead of the consecutive update ids, e.g., binance futures.
+	PreviousUpdateID int64
+
+	Book SliceOrderBook
+}
+
+// follows returns true if the update continues the final update id of the last update
+func (u DepthUpdate) follows(finalUpdateID int64) bool {
+	if u.PreviousUpdateID > 0 {
+		return u.PreviousUpdateID == finalUpdateID
+	}
+
+	return u.FirstUpdateID <= finalUpdateID+1
+}
+
+// covers returns true if the first update after the snapshot connects to the final update id of the snapshot
+func (u DepthUpdate) covers(finalUpdateID int64) bool {
+	// the previous update of the first update may be older than the snapshot, but not newer
+	if u.PreviousUpdateID > 0 {
+		return u.PreviousUpdateID <= finalUpdateID && u.FinalUpdateID > finalUpdateID
+	}
+
+	nextID := finalUpdateID + 1
+	return u.FirstUpdateID <= nextID && u.FinalUpdateID >= nextID
+}
+
+// DepthSnapshotFetcher fetches the depth snapshot and the final update id of the snapshot
+type DepthSnapshotFetcher func() (snapshot SliceOrderBook, finalUpdateID int64, err error)
+
+// DepthBuffer buffers the depth updates until the depth snapshot is loaded, and then verifies the sequence of the updates.
+//
+// When the fetcher is set, the snapshot is fetched (from the REST api) once the first update is received, the buffered
+// updates older than the snapshot are dropped. Otherwise, the snapshot is expected to be pushed with SetSnapshot
+// (e.g., the partial message of the websocket).
+//
+// When a gap of the update sequence is detected, the buffer is reset and the snapshot is reloaded.
+// The callbacks are called with the lock held, so they should not call the methods of the buffer.
+//
+//go:generate callbackgen -type DepthBuffer
+type DepthBuffer struct {
+	Symbol string
+
+	// SnapshotDelay is the delay before fetching the snapshot, so that the updates can be buffered
+	SnapshotDelay time.Duration
+
+	fetcher DepthSnapshotFetcher
+
+	mu            sync.Mutex
+	buffer        []DepthUpdate
+	finalUpdateID int64
+	ready         bool
+	fetching      bool
+
+	// generation is increased on every reset, so that the snapshot fetched before the reset is discarded
+	generation int
+
+	readyCallbacks []func(snapshot SliceOrderBook, updates []DepthUpdate)
+	pushCallbacks  []func(update DepthUpdate)
+	resetCallbacks []func()
+}
+
+func NewDepthBuffer(symbol string, fetcher DepthSnapshotFetcher) *DepthBuffer {
+	return &DepthBuffer{
+		Symbol:  symbol,
+		fetcher: fetcher,
+	}
+}
+
+// Ready returns true when the snapshot is loaded
+func (b *DepthBuffer) Ready() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.ready
+}
+
+// Reset drops the snapshot and the buffered updates, the snapshot will be reloaded
+func (b *DepthBuffer) Reset() {
+	b.mu.Lock()
+	b.reset()
+	b.mu.Unlock()
+}
+
+func (b *DepthBuffer) reset() {
+	b.generation++
+	b.buffer = nil
+	b.finalUpdateID = 0
+	b.ready = false
+	b.fetching = false
+	b.EmitReset()
+}
+
+// AddUpdate adds the depth update, the update is buffered if the snapshot is not loaded yet,
+// an error is returned if the update sequence is broken, the buffer resets itself in that case.
+func (b *DepthBuffer) AddUpdate(update DepthUpdate) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.ready {
+		// the updates without the update ids can not be ordered against the snapshot, drop them
+		if update.FinalUpdateID != 0 {
+			b.buffer = append(b.buffer, update)
+		}
+
+		b.fetchSnapshot()
+		return nil
+	}
+
+	// the updates without the update ids are passed through
+	if update.FinalUpdateID == 0 {
+		b.EmitPush(update)
+		return nil
+	}
+
+	// drop the old updates
+	if update.FinalUpdateID <= b.finalUpdateID {
+		return nil
+	}
+
+	if !update.follows(b.finalUpdateID) {
+		err := fmt.Errorf("%s depth update gap detected, expected the update after %d, got %d ~ %d (previous %d), resetting",
+			b.Symbol, b.finalUpdateID, update.FirstUpdateID, update.FinalUpdateID, update.PreviousUpdateID)
+
+		b.reset()
+
+		// the update is the first update of the next snapshot
+		b.buffer = append(b.buffer, update)
+		b.fetchSnapshot()
+		return err
+	}
+
+	b.finalUpdateID = update.FinalUpdateID
+	b.EmitPush(update)
+	return nil
+}
+
+// SetSnapshot sets the snapshot pushed from the exchange, the buffered updates newer than the snapshot are emitted
+// along with the snapshot.
+func (b *DepthBuffer) SetSnapshot(snapshot SliceOrderBook, finalUpdateID int64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// the snapshot replaces the current snapshot
+	if b.ready {
+		b.reset()
+	}
+
+	return b.setSnapshot(snapshot, finalUpdateID)
+}
+
+func (b *DepthBuffer) setSnapshot(snapshot SliceOrderBook, finalUpdateID int64) error {
+	var updates []DepthUpdate
+	for _, u := range b.buffer {
+		if u.FinalUpdateID <= finalUpdateID {
+			continue
+		}
+
+		updates = append(updates, u)
+	}
+
+	// the first update should cover the next update id of the snapshot,
+	// otherwise, some updates are missing between the snapshot and the buffered updates.
+	if len(updates) > 0 && (updates[0].FirstUpdateID > 0 || updates[0].PreviousUpdateID > 0) {
+		first := updates[0]
+		if !first.covers(finalUpdateID) {
+			b.reset()
+			return fmt.Errorf("%s depth snapshot update id %d mismatches the buffered update %d ~ %d (previous %d), resetting",
+				b.Symbol, finalUpdateID, first.FirstUpdateID, first.FinalUpdateID, first.PreviousUpdateID)
+		}
+	}
+
+	b.buffer = nil
+	b.finalUpdateID = finalUpdateID
+	if len(updates) > 0 {
+		b.finalUpdateID = updates[len(updates)-1].FinalUpdateID
+	}
+
+	b.ready = true
+	b.fetching = false
+	b.EmitReady(snapshot, updates)
+	return nil
+}
+
+// fetchSnapshot starts fetching the snapshot in the background if the fetcher is set
+func (b *DepthBuffer) fetchSnapshot() {
+	if b.fetcher == nil || b.fetching {
+		return
+	}
+
+	b.fetching = true
+	go b.loadSnapshot(b.generation)
+}
+
+func (b *DepthBuffer) loadSnapshot(generation int) {
+	if b.SnapshotDelay > 0 {
+		time.Sleep(b.SnapshotDelay)
+	}
+
+	snapshot, finalUpdateID, err := b.fetcher()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// the buffer was reset while fetching the snapshot
+	if generation != b.generation {
+		return
+	}
+
+	if err != nil {
+		log.WithError(err).Errorf("%s depth snapshot fetch error, resetting", b.Symbol)
+		b.reset()
+		return
+	}
+
+	if err := b.setSnapshot(snapshot, finalUpdateID); err != nil {
+		log.WithError(err).Error("depth snapshot error")
+	}
+}
@@ -0,0 +1,182 @@
+package types
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func newTestDepthBook(price float64) SliceOrderBook {
+	return SliceOrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(price), Volume: fixedpoint.NewFromFloat(1.0)}},
+	}
+}
+
+func TestDepthBuffer_FetchSnapshot(t *testing.T) {
+	fetchC := make(chan struct{})
+	readyC := make(chan []DepthUpdate, 1)
+
+	buffer := NewDepthBuffer("BTCUSDT", func() (SliceOrderBook, int64, error) {
+		<-fetchC
+		return newTestDepthBook(100.0), 3, nil
+	})
+
+	buffer.OnReady(func(snapshot SliceOrderBook, updates []DepthUpdate) {
+		assert.Equal(t, "BTCUSDT", snapshot.Symbol)
+		readyC <- updates
+	})
+
+	var pushed []DepthUpdate
+	buffer.OnPush(func(update DepthUpdate) {
+		pushed = append(pushed, update)
+	})
+
+	resets := 0
+	buffer.OnReset(func() {
+		resets++
+	})
+
+	assert.NoError(t, buffer.AddUpdate(DepthUpdate{FirstUpdateID: 1, FinalUpdateID: 2}))
+	assert.NoError(t, buffer.AddUpdate(DepthUpdate{FirstUpdateID: 3, FinalUpdateID: 4}))
+	assert.NoError(t, buffer.AddUpdate(DepthUpdate{FirstUpdateID: 5, FinalUpdateID: 6}))
+	assert.False(t, buffer.Ready())
+
+	// release the first fetch only, the fetch after the gap blocks
+	fetchC <- struct{}{}
+
+	select {
+	case updates := <-readyC:
+		// the update 1 ~ 2 is older than the snapshot
+		if assert.Len(t, updates, 2) {
+			assert.Equal(t, int64(3), updates[0].FirstUpdateID)
+			assert.Equal(t, int64(6), updates[1].FinalUpdateID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("depth buffer is not ready")
+	}
+
+	assert.True(t, buffer.Ready())
+
+	// old update is dropped
+	assert.NoError(t, buffer.AddUpdate(DepthUpdate{FirstUpdateID: 5, FinalUpdateID: 6}))
+	assert.NoError(t, buffer.AddUpdate(DepthUpdate{FirstUpdateID: 7, FinalUpdateID: 8}))
+	if assert.Len(t, pushed, 1) {
+		assert.Equal(t, int64(8), pushed[0].FinalUpdateID)
+	}
+
+	// gap detected
+	err := buffer.AddUpdate(DepthUpdate{FirstUpdateID: 10, FinalUpdateID: 11})
+	assert.Error(t, err)
+	assert.Equal(t, 1, resets)
+	assert.Len(t, pushed, 1)
+}
+
+func TestDepthBuffer_SnapshotMismatch(t *testing.T) {
+	buffer := NewDepthBuffer("BTCUSDT", nil)
+
+	resets := 0
+	buffer.OnReset(func() {
+		resets++
+	})
+
+	assert.NoError(t, buffer.AddUpdate(DepthUpdate{FirstUpdateID: 10, FinalUpdateID: 12}))
+
+	// the updates between 6 and 9 are missing
+	err := buffer.SetSnapshot(newTestDepthBook(100.0), 5)
+	assert.Error(t, err)
+	assert.False(t, buffer.Ready())
+	assert.Equal(t, 1, resets)
+}
+
+func TestDepthBuffer_FetchError(t *testing.T) {
+	resetC := make(chan struct{}, 1)
+	buffer := NewDepthBuffer("BTCUSDT", func() (SliceOrderBook, int64, error) {
+		return SliceOrderBook{}, 0, errors.New("rate limited")
+	})
+	buffer.OnReset(func() {
+		resetC <- struct{}{}
+	})
+
+	assert.NoError(t, buffer.AddUpdate(DepthUpdate{FirstUpdateID: 1, FinalUpdateID: 2}))
+
+	select {
+	case <-resetC:
+	case <-time.After(time.Second):
+		t.Fatal("depth buffer is not reset")
+	}
+
+	assert.False(t, buffer.Ready())
+}
+
+func TestDepthBuffer_PushedSnapshot(t *testing.T) {
+	buffer := NewDepthBuffer("BTCUSDT", nil)
+
+	var snapshots []SliceOrderBook
+	buffer.OnReady(func(snapshot SliceOrderBook, updates []DepthUpdate) {
+		assert.Empty(t, updates)
+		snapshots = append(snapshots, snapshot)
+	})
+
+	var pushed []DepthUpdate
+	buffer.OnPush(func(update DepthUpdate) {
+		pushed = append(pushed, update)
+	})
+
+	// the updates without the update ids before the snapshot are dropped
+	assert.NoError(t, buffer.AddUpdate(DepthUpdate{Book: newTestDepthBook(99.0)}))
+	assert.Empty(t, pushed)
+
+	assert.NoError(t, buffer.SetSnapshot(newTestDepthBook(100.0), 0))
+	assert.Len(t, snapshots, 1)
+
+	assert.NoError(t, buffer.AddUpdate(DepthUpdate{Book: newTestDepthBook(101.0)}))
+	assert.NoError(t, buffer.AddUpdate(DepthUpdate{Book: newTestDepthBook(102.0)}))
+	assert.Len(t, pushed, 2)
+
+	// a new snapshot replaces the current one
+	assert.NoError(t, buffer.SetSnapshot(newTestDepthBook(103.0), 0))
+	assert.Len(t, snapshots, 2)
+}
+
+func TestDepthBuffer_PreviousUpdateID(t *testing.T) {
+	buffer := NewDepthBuffer("BTCUSDT", nil)
+
+	var pushed []DepthUpdate
+	buffer.OnPush(func(update DepthUpdate) {
+		pushed = append(pushed, update)
+	})
+
+	resets := 0
+	buffer.OnReset(func() {
+		resets++
+	})
+
+	// the futures update ids are not consecutive, the updates are chained by the previous update id
+	assert.NoError(t, buffer.AddUpdate(DepthUpdate{FirstUpdateID: 90, FinalUpdateID: 95, PreviousUpdateID: 85}))
+	assert.NoError(t, buffer.AddUpdate(DepthUpdate{FirstUpdateID: 98, FinalUpdateID: 110, PreviousUpdateID: 95}))
+
+	// the first update after the snapshot starts before the snapshot
+	assert.NoError(t, buffer.SetSnapshot(newTestDepthBook(100.0), 100))
+	assert.True(t, buffer.Ready())
+
+	assert.NoError(t, buffer.AddUpdate(DepthUpdate{FirstUpdateID: 115, FinalUpdateID: 120, PreviousUpdateID: 110}))
+	if assert.Len(t, pushed, 1) {
+		assert.Equal(t, int64(120), pushed[0].FinalUpdateID)
+	}
+
+	// the previous update id mismatches the last update
+	err := buffer.AddUpdate(DepthUpdate{FirstUpdateID: 130, FinalUpdateID: 135, PreviousUpdateID: 125})
+	assert.Error(t, err)
+	assert.Equal(t, 1, resets)
+
+	// the updates between the snapshot and the first buffered update are missing
+	buffer = NewDepthBuffer("BTCUSDT", nil)
+	assert.NoError(t, buffer.AddUpdate(DepthUpdate{FirstUpdateID: 130, FinalUpdateID: 135, PreviousUpdateID: 125}))
+	assert.Error(t, buffer.SetSnapshot(newTestDepthBook(100.0), 120))
+	assert.False(t, buffer.Ready())
+}
@@ -26,13 +26,13 @@ func (n *ExchangeName) UnmarshalJSON(data []byte) error {
 	}
 
 	switch s {
-	case "max", "binance", "ftx", "okex":
+	case "max", "binance", "ftx", "okex", "bitfinex", "ccxt":
 		*n = ExchangeName(s)
 		return nil
 
 	}
 
-	return fmt.Errorf("unknown or unsupported exchange name: %s, valid names are: max, binance, ftx", s)
+	return fmt.Errorf("unknown or unsupported exchange name: %s, valid names are: max, binance, ftx, okex, bitfinex, ccxt", s)
 }
 
 func (n ExchangeName) String() string {
@@ -44,10 +44,14 @@ const (
 	ExchangeBinance  = ExchangeName("binance")
 	ExchangeFTX      = ExchangeName("ftx")
 	ExchangeOKEx     = ExchangeName("okex")
+	ExchangeBitfinex = ExchangeName("bitfinex")
 	ExchangeBacktest = ExchangeName("backtest")
+
+	// ExchangeCCXT is the bridge exchange that talks to a CCXT-compatible REST gateway
+	ExchangeCCXT = ExchangeName("ccxt")
 )
 
-var SupportedExchanges = []ExchangeName{"binance", "max", "ftx", "okex"}
+var SupportedExchanges = []ExchangeName{"binance", "max", "ftx", "okex", "bitfinex"}
 
 func ValidExchangeName(a string) (ExchangeName, error) {
 	switch strings.ToLower(a) {
@@ -59,6 +63,10 @@ func ValidExchangeName(a string) (ExchangeName, error) {
 		return ExchangeFTX, nil
 	case "okex":
 		return ExchangeOKEx, nil
+	case "bitfinex", "bfx":
+		return ExchangeBitfinex, nil
+	case "ccxt":
+		return ExchangeCCXT, nil
 	}
 
 	return "", fmt.Errorf("invalid exchange name: %s", a)
@@ -86,6 +94,12 @@ type ExchangeTradeService interface {
 	CancelOrders(ctx context.Context, orders ...Order) error
 }
 
+// ExchangeOrderCancelService is implemented by the exchanges that can cancel all the open orders of a symbol
+// in one request, it returns the canceled orders
+type ExchangeOrderCancelService interface {
+	CancelOrdersBySymbol(ctx context.Context, symbol string) ([]Order, error)
+}
+
 type ExchangeTradeHistoryService interface {
 	QueryTrades(ctx context.Context, symbol string, options *TradeQueryOptions) ([]Trade, error)
 	QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) (orders []Order, err error)
@@ -117,6 +131,13 @@ type ExchangeWithdrawalService interface {
 	Withdrawal(ctx context.Context, asset string, amount fixedpoint.Value, address string, options *WithdrawalOptions) error
 }
 
+// TestnetExchange is implemented by the exchanges that provide the testnet (sandbox) endpoints,
+// UseTestnet must be called before the streams are created.
+type TestnetExchange interface {
+	UseTestnet()
+	IsTestnet() bool
+}
+
 type ExchangeRewardService interface {
 	QueryRewards(ctx context.Context, startTime time.Time) ([]Reward, error)
 }
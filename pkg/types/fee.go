@@ -0,0 +1,85 @@
+package types
+
+import (
+	"context"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// FeeTier is the actual fee rates of the account, the discount is applied when the fee is paid with the platform token
+type FeeTier struct {
+	MakerFeeRate fixedpoint.Value `json:"makerFeeRate"`
+	TakerFeeRate fixedpoint.Value `json:"takerFeeRate"`
+
+	// FeeDiscount is the discount ratio of the fee, e.g., 0.25 when the binance fee is paid with BNB
+	FeeDiscount fixedpoint.Value `json:"feeDiscount,omitempty"`
+
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (t FeeTier) discounted(rate fixedpoint.Value) fixedpoint.Value {
+	if t.FeeDiscount <= 0 {
+		return rate
+	}
+
+	return rate.Mul(fixedpoint.NewFromInt(1) - t.FeeDiscount)
+}
+
+// EffectiveMakerFeeRate returns the maker fee rate with the discount
+func (t FeeTier) EffectiveMakerFeeRate() fixedpoint.Value {
+	return t.discounted(t.MakerFeeRate)
+}
+
+// EffectiveTakerFeeRate returns the taker fee rate with the discount
+func (t FeeTier) EffectiveTakerFeeRate() fixedpoint.Value {
+	return t.discounted(t.TakerFeeRate)
+}
+
+// FeeRate returns the effective fee rate of the maker or the taker
+func (t FeeTier) FeeRate(isMaker bool) float64 {
+	if isMaker {
+		return t.EffectiveMakerFeeRate().Float64()
+	}
+
+	return t.EffectiveTakerFeeRate().Float64()
+}
+
+// RoundTripFeeRate returns the total fee rate of opening and closing a position,
+// a strategy needs the price edge larger than it to profit.
+func (t FeeTier) RoundTripFeeRate(openAsMaker, closeAsMaker bool) float64 {
+	return t.FeeRate(openAsMaker) + t.FeeRate(closeAsMaker)
+}
+
+// ExchangeFee returns the effective fee rates for the position fee calculation
+func (t FeeTier) ExchangeFee() ExchangeFee {
+	return ExchangeFee{
+		MakerFeeRate: t.EffectiveMakerFeeRate(),
+		TakerFeeRate: t.EffectiveTakerFeeRate(),
+	}
+}
+
+// FeeTierFromAccount returns the fee tier of the account fee rates, the commission fields are used
+// when the exchange does not return the fee rates.
+func FeeTierFromAccount(account *Account) (FeeTier, bool) {
+	tier := FeeTier{
+		MakerFeeRate: account.MakerFeeRate,
+		TakerFeeRate: account.TakerFeeRate,
+	}
+
+	if tier.MakerFeeRate == 0 && tier.TakerFeeRate == 0 {
+		tier.MakerFeeRate = account.MakerCommission
+		tier.TakerFeeRate = account.TakerCommission
+	}
+
+	if tier.MakerFeeRate == 0 && tier.TakerFeeRate == 0 {
+		return tier, false
+	}
+
+	return tier, true
+}
+
+// ExchangeFeeDiscountService is implemented by the exchanges that discount the fee paid with the platform token
+type ExchangeFeeDiscountService interface {
+	QueryFeeDiscount(ctx context.Context) (fixedpoint.Value, error)
+}
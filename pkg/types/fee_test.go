@@ -0,0 +1,50 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func TestFeeTier(t *testing.T) {
+	tier := FeeTier{
+		MakerFeeRate: fixedpoint.NewFromFloat(0.001),
+		TakerFeeRate: fixedpoint.NewFromFloat(0.002),
+	}
+
+	assert.InDelta(t, 0.001, tier.FeeRate(true), 1e-9)
+	assert.InDelta(t, 0.002, tier.FeeRate(false), 1e-9)
+	assert.InDelta(t, 0.003, tier.RoundTripFeeRate(true, false), 1e-9)
+
+	tier.FeeDiscount = fixedpoint.NewFromFloat(0.25)
+	assert.InDelta(t, 0.00075, tier.EffectiveMakerFeeRate().Float64(), 1e-9)
+	assert.InDelta(t, 0.0015, tier.EffectiveTakerFeeRate().Float64(), 1e-9)
+	assert.InDelta(t, 0.0015, tier.RoundTripFeeRate(true, true), 1e-9)
+
+	fee := tier.ExchangeFee()
+	assert.Equal(t, tier.EffectiveMakerFeeRate(), fee.MakerFeeRate)
+	assert.Equal(t, tier.EffectiveTakerFeeRate(), fee.TakerFeeRate)
+}
+
+func TestFeeTierFromAccount(t *testing.T) {
+	_, ok := FeeTierFromAccount(&Account{})
+	assert.False(t, ok)
+
+	// max returns the fee rates
+	tier, ok := FeeTierFromAccount(&Account{
+		MakerFeeRate: fixedpoint.NewFromFloat(0.0005),
+		TakerFeeRate: fixedpoint.NewFromFloat(0.0015),
+	})
+	assert.True(t, ok)
+	assert.Equal(t, fixedpoint.NewFromFloat(0.0005), tier.MakerFeeRate)
+
+	// binance and ftx return the commissions
+	tier, ok = FeeTierFromAccount(&Account{
+		MakerCommission: fixedpoint.NewFromFloat(0.001),
+		TakerCommission: fixedpoint.NewFromFloat(0.001),
+	})
+	assert.True(t, ok)
+	assert.Equal(t, fixedpoint.NewFromFloat(0.001), tier.TakerFeeRate)
+}
@@ -11,3 +11,12 @@ type FundingRate struct {
 	FundingTime time.Time
 	Time        time.Time
 }
+
+// FundingFee is the funding fee payment of a perpetual futures position,
+// Amount is positive when the funding fee is received and negative when it's paid.
+type FundingFee struct {
+	Symbol string           `json:"symbol"`
+	Asset  string           `json:"asset"`
+	Amount fixedpoint.Value `json:"amount"`
+	Time   time.Time        `json:"time"`
+}
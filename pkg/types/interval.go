@@ -3,13 +3,44 @@ package types
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 )
 
 type Interval string
 
 func (i Interval) Minutes() int {
-	return SupportedIntervals[i]
+	if minutes, ok := SupportedIntervals[i]; ok {
+		return minutes
+	}
+
+	// the intervals that are not supported by the exchanges (e.g., 3m) can still be built by KLineBuilder
+	return parseIntervalMinutes(string(i))
+}
+
+// parseIntervalMinutes parses the interval like 3m, 8h and 2d, returns zero if the interval is invalid
+func parseIntervalMinutes(s string) int {
+	if len(s) < 2 {
+		return 0
+	}
+
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n <= 0 {
+		return 0
+	}
+
+	switch s[len(s)-1] {
+	case 'm':
+		return n
+	case 'h':
+		return n * 60
+	case 'd':
+		return n * 60 * 24
+	case 'w':
+		return n * 60 * 24 * 7
+	}
+
+	return 0
 }
 
 func (i Interval) Duration() time.Duration {
@@ -41,6 +72,7 @@ func (s IntervalSlice) StringSlice() (slice []string) {
 }
 
 var Interval1m = Interval("1m")
+var Interval3m = Interval("3m")
 var Interval5m = Interval("5m")
 var Interval15m = Interval("15m")
 var Interval30m = Interval("30m")
@@ -0,0 +1,18 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterval_Minutes(t *testing.T) {
+	assert.Equal(t, 60, Interval1h.Minutes())
+	assert.Equal(t, 3, Interval3m.Minutes())
+	assert.Equal(t, 8*60, Interval("8h").Minutes())
+	assert.Equal(t, 7*24*60, Interval("1w").Minutes())
+	assert.Equal(t, 0, Interval("xm").Minutes())
+	assert.Equal(t, 0, Interval("3s").Minutes())
+	assert.Equal(t, 3*time.Minute, Interval3m.Duration())
+}
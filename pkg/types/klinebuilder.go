@@ -0,0 +1,229 @@
+package types
+
+import (
+	"sync"
+	"time"
+)
+
+// KLineBuilder builds the klines of the higher intervals locally from the klines of the base interval (e.g., 1m)
+// or from the raw trades, so that the strategies can use the intervals that the exchange does not stream, and the
+// back-tests only need the klines of the base interval.
+//
+// The klines are aligned to the interval from the unix epoch, the same as the exchanges do,
+// e.g., the 4h klines start at 00:00, 04:00, 08:00 (UTC) and so on.
+//
+//go:generate callbackgen -type KLineBuilder
+type KLineBuilder struct {
+	Exchange  ExchangeName
+	Symbol    string
+	Intervals []Interval
+
+	mu      sync.Mutex
+	current map[Interval]*KLine
+
+	// kLineCallbacks are called when the kline of the interval is updated but not closed yet
+	kLineCallbacks []func(kline KLine)
+
+	kLineClosedCallbacks []func(kline KLine)
+}
+
+func NewKLineBuilder(exchange ExchangeName, symbol string, intervals ...Interval) *KLineBuilder {
+	return &KLineBuilder{
+		Exchange:  exchange,
+		Symbol:    symbol,
+		Intervals: intervals,
+		current:   make(map[Interval]*KLine),
+	}
+}
+
+// BindStream builds the klines from the closed klines of the base interval of the stream
+func (b *KLineBuilder) BindStream(stream StandardStreamEventHub, baseInterval Interval) {
+	stream.OnKLineClosed(func(kline KLine) {
+		if kline.Symbol != b.Symbol || kline.Interval != baseInterval {
+			return
+		}
+
+		b.AddKLine(kline)
+	})
+}
+
+// AddKLine merges the closed kline of the base interval into the klines of the intervals
+func (b *KLineBuilder) AddKLine(kline KLine) {
+	var updated, closed []KLine
+
+	b.mu.Lock()
+	for _, interval := range b.Intervals {
+		duration := interval.Duration()
+		if duration == 0 {
+			continue
+		}
+
+		startTime := alignTime(kline.StartTime, duration)
+		current, emitted := b.open(interval, startTime)
+		closed = append(closed, emitted...)
+
+		current.High = fmax(current.High, kline.High)
+		current.Low = fmin(current.Low, kline.Low)
+		current.Close = kline.Close
+		current.Volume += kline.Volume
+		current.QuoteVolume += kline.QuoteVolume
+		current.TakerBuyBaseAssetVolume += kline.TakerBuyBaseAssetVolume
+		current.TakerBuyQuoteAssetVolume += kline.TakerBuyQuoteAssetVolume
+		current.NumberOfTrades += kline.NumberOfTrades
+		if kline.LastTradeID > current.LastTradeID {
+			current.LastTradeID = kline.LastTradeID
+		}
+
+		if current.Open == 0 {
+			current.Open = kline.Open
+		}
+
+		// the kline is closed when the base kline reaches the end of the interval
+		if !klineEndTime(kline).Before(startTime.Add(duration)) {
+			current.Closed = true
+			closed = append(closed, *current)
+			delete(b.current, interval)
+		} else {
+			updated = append(updated, *current)
+		}
+	}
+	b.mu.Unlock()
+
+	b.emit(updated, closed)
+}
+
+// AddTrade merges the trade into the klines of the intervals. Since a trade can not tell whether the kline is
+// closed, the klines are closed when the trade of the next kline arrives or when Tick is called after the end time.
+func (b *KLineBuilder) AddTrade(trade Trade) {
+	if trade.Symbol != b.Symbol {
+		return
+	}
+
+	var updated, closed []KLine
+	var tradeTime = time.Time(trade.Time)
+
+	b.mu.Lock()
+	for _, interval := range b.Intervals {
+		duration := interval.Duration()
+		if duration == 0 {
+			continue
+		}
+
+		current, emitted := b.open(interval, alignTime(tradeTime, duration))
+		closed = append(closed, emitted...)
+
+		if current.Open == 0 {
+			current.Open = trade.Price
+		}
+
+		current.High = fmax(current.High, trade.Price)
+		current.Low = fmin(current.Low, trade.Price)
+		current.Close = trade.Price
+		current.Volume += trade.Quantity
+		current.QuoteVolume += trade.Price * trade.Quantity
+		current.NumberOfTrades++
+
+		if trade.Side == SideTypeBuy {
+			current.TakerBuyBaseAssetVolume += trade.Quantity
+			current.TakerBuyQuoteAssetVolume += trade.Price * trade.Quantity
+		}
+
+		if trade.ID > 0 && uint64(trade.ID) > current.LastTradeID {
+			current.LastTradeID = uint64(trade.ID)
+		}
+
+		updated = append(updated, *current)
+	}
+	b.mu.Unlock()
+
+	b.emit(updated, closed)
+}
+
+// Tick closes the klines that end before the given time, it should be called periodically when building from trades
+func (b *KLineBuilder) Tick(now time.Time) {
+	var closed []KLine
+
+	b.mu.Lock()
+	for _, interval := range b.Intervals {
+		current, ok := b.current[interval]
+		if !ok {
+			continue
+		}
+
+		if now.Before(current.StartTime.Add(interval.Duration())) {
+			continue
+		}
+
+		current.Closed = true
+		closed = append(closed, *current)
+		delete(b.current, interval)
+	}
+	b.mu.Unlock()
+
+	b.emit(nil, closed)
+}
+
+// open returns the kline of the interval starting at the start time, the previous kline that is not closed
+// (e.g., some base klines are missing) is returned as the closed kline.
+func (b *KLineBuilder) open(interval Interval, startTime time.Time) (current *KLine, closed []KLine) {
+	current, ok := b.current[interval]
+	if ok && current.StartTime.Equal(startTime) {
+		return current, nil
+	}
+
+	if ok {
+		current.Closed = true
+		closed = append(closed, *current)
+	}
+
+	current = &KLine{
+		Exchange:  b.Exchange,
+		Symbol:    b.Symbol,
+		Interval:  interval,
+		StartTime: startTime,
+		EndTime:   startTime.Add(interval.Duration() - time.Millisecond),
+	}
+	b.current[interval] = current
+	return current, closed
+}
+
+func (b *KLineBuilder) emit(updated, closed []KLine) {
+	for _, k := range closed {
+		b.EmitKLineClosed(k)
+	}
+
+	for _, k := range updated {
+		b.EmitKLine(k)
+	}
+}
+
+// alignTime aligns the time to the interval duration from the unix epoch
+func alignTime(t time.Time, duration time.Duration) time.Time {
+	ms := t.UnixNano() / int64(time.Millisecond)
+	d := int64(duration / time.Millisecond)
+	return time.Unix(0, (ms-ms%d)*int64(time.Millisecond)).In(t.Location())
+}
+
+// klineEndTime returns the exclusive end time of the kline
+func klineEndTime(kline KLine) time.Time {
+	if duration := kline.Interval.Duration(); duration > 0 {
+		return kline.StartTime.Add(duration)
+	}
+
+	return kline.EndTime.Add(time.Millisecond)
+}
+
+func fmax(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// fmin treats zero as the unset value
+func fmin(a, b float64) float64 {
+	if a == 0 || b < a {
+		return b
+	}
+	return a
+}
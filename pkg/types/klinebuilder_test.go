@@ -0,0 +1,134 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKLineBuilder_AddKLine(t *testing.T) {
+	builder := NewKLineBuilder(ExchangeBinance, "BTCUSDT", Interval3m, Interval15m)
+
+	var closed []KLine
+	builder.OnKLineClosed(func(kline KLine) {
+		closed = append(closed, kline)
+	})
+
+	var updates int
+	builder.OnKLine(func(kline KLine) {
+		updates++
+	})
+
+	startTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		price := 100.0 + float64(i)
+		builder.AddKLine(KLine{
+			Exchange:       ExchangeBinance,
+			Symbol:         "BTCUSDT",
+			Interval:       Interval1m,
+			StartTime:      startTime.Add(time.Duration(i) * time.Minute),
+			EndTime:        startTime.Add(time.Duration(i+1)*time.Minute - time.Millisecond),
+			Open:           price,
+			High:           price + 2.0,
+			Low:            price - 1.0,
+			Close:          price + 1.0,
+			Volume:         1.0,
+			QuoteVolume:    price,
+			NumberOfTrades: 10,
+			Closed:         true,
+		})
+	}
+
+	if assert.Len(t, closed, 1) {
+		k := closed[0]
+		assert.Equal(t, Interval3m, k.Interval)
+		assert.Equal(t, startTime, k.StartTime)
+		assert.Equal(t, startTime.Add(3*time.Minute-time.Millisecond), k.EndTime)
+		assert.Equal(t, 100.0, k.Open)
+		assert.Equal(t, 104.0, k.High)
+		assert.Equal(t, 99.0, k.Low)
+		assert.Equal(t, 103.0, k.Close)
+		assert.Equal(t, 3.0, k.Volume)
+		assert.Equal(t, 303.0, k.QuoteVolume)
+		assert.Equal(t, uint64(30), k.NumberOfTrades)
+		assert.True(t, k.Closed)
+	}
+
+	// 15m: 4 updates, 3m: 2 updates (the 3rd kline closes it) + 1 update of the next kline
+	assert.Equal(t, 7, updates)
+}
+
+func TestKLineBuilder_MissingKLines(t *testing.T) {
+	builder := NewKLineBuilder(ExchangeBinance, "BTCUSDT", Interval3m)
+
+	var closed []KLine
+	builder.OnKLineClosed(func(kline KLine) {
+		closed = append(closed, kline)
+	})
+
+	startTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	builder.AddKLine(KLine{Symbol: "BTCUSDT", Interval: Interval1m, StartTime: startTime, Open: 1.0, High: 1.0, Low: 1.0, Close: 1.0})
+
+	// the kline of the next interval closes the incomplete kline
+	builder.AddKLine(KLine{Symbol: "BTCUSDT", Interval: Interval1m, StartTime: startTime.Add(4 * time.Minute), Open: 2.0, High: 2.0, Low: 2.0, Close: 2.0})
+
+	if assert.Len(t, closed, 1) {
+		assert.Equal(t, startTime, closed[0].StartTime)
+		assert.Equal(t, 1.0, closed[0].Close)
+	}
+}
+
+func TestKLineBuilder_AddTrade(t *testing.T) {
+	builder := NewKLineBuilder(ExchangeBinance, "BTCUSDT", Interval1m)
+
+	var closed []KLine
+	builder.OnKLineClosed(func(kline KLine) {
+		closed = append(closed, kline)
+	})
+
+	startTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	builder.AddTrade(Trade{ID: 1, Symbol: "BTCUSDT", Price: 100.0, Quantity: 1.0, Side: SideTypeBuy, Time: Time(startTime.Add(time.Second))})
+	builder.AddTrade(Trade{ID: 2, Symbol: "BTCUSDT", Price: 98.0, Quantity: 2.0, Side: SideTypeSell, Time: Time(startTime.Add(20 * time.Second))})
+	builder.AddTrade(Trade{ID: 3, Symbol: "BTCUSDT", Price: 101.0, Quantity: 1.0, Side: SideTypeBuy, Time: Time(startTime.Add(50 * time.Second))})
+
+	// other symbols are ignored
+	builder.AddTrade(Trade{ID: 4, Symbol: "ETHUSDT", Price: 4000.0, Quantity: 1.0, Time: Time(startTime.Add(55 * time.Second))})
+
+	builder.Tick(startTime.Add(30 * time.Second))
+	assert.Empty(t, closed)
+
+	builder.Tick(startTime.Add(time.Minute))
+	if assert.Len(t, closed, 1) {
+		k := closed[0]
+		assert.Equal(t, 100.0, k.Open)
+		assert.Equal(t, 101.0, k.High)
+		assert.Equal(t, 98.0, k.Low)
+		assert.Equal(t, 101.0, k.Close)
+		assert.Equal(t, 4.0, k.Volume)
+		assert.Equal(t, 2.0, k.TakerBuyBaseAssetVolume)
+		assert.Equal(t, uint64(3), k.NumberOfTrades)
+		assert.Equal(t, uint64(3), k.LastTradeID)
+	}
+}
+
+func TestKLineBuilder_BindStream(t *testing.T) {
+	stream := &StandardStream{}
+	builder := NewKLineBuilder(ExchangeBinance, "BTCUSDT", Interval3m)
+	builder.BindStream(stream, Interval1m)
+
+	var closed []KLine
+	builder.OnKLineClosed(func(kline KLine) {
+		closed = append(closed, kline)
+	})
+
+	startTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		stream.EmitKLineClosed(KLine{Symbol: "BTCUSDT", Interval: Interval1m, StartTime: startTime.Add(time.Duration(i) * time.Minute), Close: 1.0})
+
+		// the klines of the other intervals are ignored
+		stream.EmitKLineClosed(KLine{Symbol: "BTCUSDT", Interval: Interval5m, StartTime: startTime, Close: 1.0})
+	}
+
+	assert.Len(t, closed, 1)
+}
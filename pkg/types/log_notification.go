@@ -0,0 +1,62 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/c9s/bbgo/pkg/slack/slackstyle"
+)
+
+// LogNotification is the notification of a log entry, it's sent through the notifiers
+// so that the log entries can be routed by the severity of the notification rules.
+type LogNotification struct {
+	// Level is the log level, e.g., error, warning
+	Level   string                 `json:"level"`
+	Message string                 `json:"message"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+	Time    time.Time              `json:"time"`
+}
+
+func (n *LogNotification) sortedKeys() []string {
+	var keys []string
+	for k := range n.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (n *LogNotification) PlainText() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s", strings.ToUpper(n.Level), n.Message))
+
+	for _, k := range n.sortedKeys() {
+		sb.WriteString(fmt.Sprintf("\n%s: %v", k, n.Fields[k]))
+	}
+
+	return sb.String()
+}
+
+func (n *LogNotification) SlackAttachment() slack.Attachment {
+	var fields []slack.AttachmentField
+	for _, k := range n.sortedKeys() {
+		fields = append(fields, slack.AttachmentField{Title: k, Value: fmt.Sprintf("%v", n.Fields[k]), Short: true})
+	}
+
+	color := "warning"
+	if n.Level != "warning" {
+		color = slackstyle.Red
+	}
+
+	return slack.Attachment{
+		Color:  color,
+		Title:  strings.ToUpper(n.Level),
+		Text:   n.Message,
+		Fields: fields,
+		Footer: n.Time.Format(time.RFC3339),
+	}
+}
@@ -1,6 +1,11 @@
 package types
 
-import "github.com/c9s/bbgo/pkg/fixedpoint"
+import (
+	"context"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
 
 type FuturesExchange interface {
 	UseFutures()
@@ -28,6 +33,25 @@ func (s *FuturesSettings) UseIsolatedFutures(symbol string) {
 	s.IsolatedFuturesSymbol = symbol
 }
 
+type PositionMode string
+
+const (
+	// PositionModeOneWay holds only one position of a symbol, the long and short orders are netted
+	PositionModeOneWay PositionMode = "oneway"
+
+	// PositionModeHedge holds the long position and the short position of a symbol separately
+	PositionModeHedge PositionMode = "hedge"
+)
+
+// FuturesService is implemented by the futures exchanges that support the account configuration,
+// the position queries and the funding fee queries.
+type FuturesService interface {
+	SetLeverage(ctx context.Context, symbol string, leverage int) error
+	SetPositionMode(ctx context.Context, mode PositionMode) error
+	QueryPositions(ctx context.Context) (PositionMap, error)
+	QueryFundingFeeHistory(ctx context.Context, symbol string, since, until time.Time) ([]FundingFee, error)
+}
+
 type MarginExchange interface {
 	UseMargin()
 	UseIsolatedMargin(symbol string)
@@ -35,6 +59,17 @@ type MarginExchange interface {
 	// QueryMarginAccount(ctx context.Context) (*binance.MarginAccount, error)
 }
 
+// MarginAccountService is implemented by the exchanges that support querying the margin accounts
+type MarginAccountService interface {
+	QueryMarginAccount(ctx context.Context) (*MarginAccount, error)
+	QueryIsolatedMarginAccount(ctx context.Context, symbols ...string) (*IsolatedMarginAccount, error)
+}
+
+// MarginBorrowRepayService is implemented by the exchanges that support repaying the borrowed margin assets
+type MarginBorrowRepayService interface {
+	RepayMarginAsset(ctx context.Context, asset string, amount fixedpoint.Value) error
+}
+
 type MarginSettings struct {
 	IsMargin             bool
 	IsIsolatedMargin     bool
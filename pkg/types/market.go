@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/leekchan/accounting"
+	"gopkg.in/yaml.v3"
 )
 
 type Duration time.Duration
@@ -23,6 +24,21 @@ func (d *Duration) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
+	return d.set(o)
+}
+
+// UnmarshalYAML parses the duration strings like 30s of the yaml config, the numbers are seconds
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	var o interface{}
+
+	if err := value.Decode(&o); err != nil {
+		return err
+	}
+
+	return d.set(o)
+}
+
+func (d *Duration) set(o interface{}) error {
 	switch t := o.(type) {
 	case string:
 		dd, err := time.ParseDuration(t)
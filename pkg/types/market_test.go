@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
 )
 
 func TestFormatQuantity(t *testing.T) {
@@ -66,3 +67,16 @@ func TestDurationParse(t *testing.T) {
 		})
 	}
 }
+
+func TestDurationParse_YAML(t *testing.T) {
+	var a struct {
+		Timeout  Duration `yaml:"timeout"`
+		Interval Duration `yaml:"interval"`
+	}
+
+	assert.NoError(t, yaml.Unmarshal([]byte("timeout: 2m3s\ninterval: 10\n"), &a))
+	assert.Equal(t, Duration(2*time.Minute+3*time.Second), a.Timeout)
+	assert.Equal(t, Duration(10*time.Second), a.Interval)
+
+	assert.Error(t, yaml.Unmarshal([]byte("timeout: 2x\n"), &a))
+}
@@ -65,6 +65,18 @@ const (
 	OrderTypeIOCLimit   OrderType = "IOC_LIMIT"
 )
 
+// the time-in-force values of the limit orders
+const (
+	// TimeInForceGTC is good till canceled, the default time in force of the limit orders
+	TimeInForceGTC = "GTC"
+
+	// TimeInForceIOC is immediate or cancel, the unfilled quantity is canceled right away
+	TimeInForceIOC = "IOC"
+
+	// TimeInForceFOK is fill or kill, the order is canceled if it can not be filled completely
+	TimeInForceFOK = "FOK"
+)
+
 /*
 func (t *OrderType) Scan(v interface{}) error {
 	switch d := v.(type) {
@@ -111,6 +123,12 @@ type SubmitOrder struct {
 
 	TimeInForce string `json:"timeInForce,omitempty" db:"time_in_force"` // GTC, IOC, FOK
 
+	// PostOnly makes the limit order maker-only, the order is rejected instead of taking the liquidity
+	PostOnly bool `json:"postOnly,omitempty" db:"-"`
+
+	// RoundingPolicy is the policy of rounding the price and the quantity when formatting the order, defaults to round down
+	RoundingPolicy RoundingPolicy `json:"-" db:"-"`
+
 	GroupID uint32 `json:"groupID,omitempty"`
 
 	MarginSideEffect MarginOrderSideEffectType `json:"marginSideEffect,omitempty"` // AUTO_REPAY = repay, MARGIN_BUY = borrow, defaults to  NO_SIDE_EFFECT
@@ -121,6 +139,21 @@ type SubmitOrder struct {
 	ClosePosition bool `json:"closePosition" db:"close_position"`
 }
 
+// IsPostOnly returns true if the order only adds liquidity, which is either a limit maker order or a limit order with the post-only flag
+func (o SubmitOrder) IsPostOnly() bool {
+	return o.Type == OrderTypeLimitMaker || (o.PostOnly && o.Type == OrderTypeLimit)
+}
+
+// EffectiveType returns the order type with the post-only flag applied,
+// it's used by the exchanges that model the post-only mode as an order type.
+func (o SubmitOrder) EffectiveType() OrderType {
+	if o.IsPostOnly() {
+		return OrderTypeLimitMaker
+	}
+
+	return o.Type
+}
+
 func (o *SubmitOrder) String() string {
 	return fmt.Sprintf("SubmitOrder %s %s %s %f @ %f", o.Symbol, o.Type, o.Side, o.Quantity, o.Price)
 }
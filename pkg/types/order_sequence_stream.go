@@ -0,0 +1,207 @@
+package types
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultOrderSequenceRetention = time.Hour
+
+// closedOrderStatusRank is the rank of the filled, canceled and rejected orders
+const closedOrderStatusRank = 2
+
+// orderUpdateKey is the progress of an order update, the updates of an order are ordered by
+// the executed quantity, the status and the update time, since the executed quantity never decreases and
+// the status only moves forward, while the update time of some exchanges is not precise enough.
+type orderUpdateKey struct {
+	executedQuantity float64
+	statusRank       int
+	updateTime       time.Time
+}
+
+func newOrderUpdateKey(order Order) orderUpdateKey {
+	return orderUpdateKey{
+		executedQuantity: order.ExecutedQuantity,
+		statusRank:       orderStatusRank(order.Status),
+		updateTime:       order.UpdateTime.Time(),
+	}
+}
+
+// after returns true if the key is ahead of the other key
+func (k orderUpdateKey) after(other orderUpdateKey) bool {
+	if k.executedQuantity != other.executedQuantity {
+		return k.executedQuantity > other.executedQuantity
+	}
+
+	if k.statusRank != other.statusRank {
+		return k.statusRank > other.statusRank
+	}
+
+	return k.updateTime.After(other.updateTime)
+}
+
+func orderStatusRank(status OrderStatus) int {
+	switch status {
+	case OrderStatusNew:
+		return 0
+	case OrderStatusPartiallyFilled:
+		return 1
+	case OrderStatusFilled, OrderStatusCanceled, OrderStatusRejected:
+		return closedOrderStatusRank
+	}
+
+	return 0
+}
+
+type closedOrderRecord struct {
+	orderID  uint64
+	closedAt time.Time
+}
+
+// OrderUpdateSequencer dedupes the order updates by the order ID and drops the updates that are older than the
+// last accepted update of the same order, for example, the duplicated updates re-sent after a reconnect or the
+// "partially filled" update delivered after the "filled" update.
+type OrderUpdateSequencer struct {
+	// Retention is how long the closed orders are remembered, the late updates of the closed orders are dropped within the retention
+	Retention time.Duration
+
+	mu           sync.Mutex
+	lastUpdates  map[uint64]orderUpdateKey
+	closedOrders []closedOrderRecord
+}
+
+func NewOrderUpdateSequencer() *OrderUpdateSequencer {
+	return &OrderUpdateSequencer{
+		Retention:   defaultOrderSequenceRetention,
+		lastUpdates: make(map[uint64]orderUpdateKey),
+	}
+}
+
+// Accept returns true if the order update is ahead of the last accepted update of the order,
+// the duplicated and the stale updates are rejected.
+func (s *OrderUpdateSequencer) Accept(order Order) bool {
+	return s.accept(order, time.Now())
+}
+
+func (s *OrderUpdateSequencer) accept(order Order, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(now)
+
+	key := newOrderUpdateKey(order)
+	if last, ok := s.lastUpdates[order.OrderID]; ok {
+		if !key.after(last) {
+			return false
+		}
+
+		// a closed order is closed only once
+		if key.statusRank == closedOrderStatusRank && last.statusRank == closedOrderStatusRank {
+			return false
+		}
+	}
+
+	s.lastUpdates[order.OrderID] = key
+	if key.statusRank == closedOrderStatusRank {
+		s.closedOrders = append(s.closedOrders, closedOrderRecord{orderID: order.OrderID, closedAt: now})
+	}
+
+	return true
+}
+
+// prune removes the closed orders that are out of the retention
+func (s *OrderUpdateSequencer) prune(now time.Time) {
+	var i = 0
+	for ; i < len(s.closedOrders); i++ {
+		record := s.closedOrders[i]
+		if now.Sub(record.closedAt) < s.Retention {
+			break
+		}
+
+		delete(s.lastUpdates, record.orderID)
+	}
+
+	if i > 0 {
+		s.closedOrders = s.closedOrders[i:]
+	}
+}
+
+// Len returns the number of the tracked orders
+func (s *OrderUpdateSequencer) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.lastUpdates)
+}
+
+// OrderSequenceStream wraps the stream and passes the order updates through the OrderUpdateSequencer,
+// so that the strategies receive the updates of each order once and in order.
+// The other events are emitted from the underlying stream directly.
+type OrderSequenceStream struct {
+	StandardStream
+
+	// Stream is the underlying stream
+	Stream Stream
+
+	Sequencer *OrderUpdateSequencer
+}
+
+func NewOrderSequenceStream(stream Stream) *OrderSequenceStream {
+	s := &OrderSequenceStream{
+		StandardStream: NewStandardStream(),
+		Stream:         stream,
+		Sequencer:      NewOrderUpdateSequencer(),
+	}
+	s.bind()
+	return s
+}
+
+func (s *OrderSequenceStream) bind() {
+	s.Stream.OnStart(s.EmitStart)
+	s.Stream.OnConnect(s.EmitConnect)
+	s.Stream.OnDisconnect(s.EmitDisconnect)
+	s.Stream.OnReconnect(s.EmitReconnect)
+	s.Stream.OnBalanceSnapshot(s.EmitBalanceSnapshot)
+	s.Stream.OnBalanceUpdate(s.EmitBalanceUpdate)
+	s.Stream.OnPositionUpdate(s.EmitPositionUpdate)
+	s.Stream.OnPositionSnapshot(s.EmitPositionSnapshot)
+	s.Stream.OnTradeUpdate(s.EmitTradeUpdate)
+	s.Stream.OnKLine(s.EmitKLine)
+	s.Stream.OnKLineClosed(s.EmitKLineClosed)
+	s.Stream.OnBookUpdate(s.EmitBookUpdate)
+	s.Stream.OnBookSnapshot(s.EmitBookSnapshot)
+	s.Stream.OnMarketTrade(s.EmitMarketTrade)
+	s.Stream.OnFundingFee(s.EmitFundingFee)
+
+	s.Stream.OnOrderUpdate(func(order Order) {
+		if !s.Sequencer.Accept(order) {
+			log.Debugf("dropped the duplicated or stale %s order update: %d %s executed %f",
+				order.Symbol, order.OrderID, order.Status, order.ExecutedQuantity)
+			return
+		}
+
+		s.EmitOrderUpdate(order)
+	})
+}
+
+func (s *OrderSequenceStream) Subscribe(channel Channel, symbol string, options SubscribeOptions) {
+	s.Stream.Subscribe(channel, symbol, options)
+}
+
+func (s *OrderSequenceStream) SetPublicOnly() {
+	s.Stream.SetPublicOnly()
+}
+
+func (s *OrderSequenceStream) Connect(ctx context.Context) error {
+	return s.Stream.Connect(ctx)
+}
+
+func (s *OrderSequenceStream) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.CloseC)
+	})
+
+	return s.Stream.Close()
+}
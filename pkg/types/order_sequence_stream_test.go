@@ -0,0 +1,75 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderUpdateSequencer_Accept(t *testing.T) {
+	sequencer := NewOrderUpdateSequencer()
+	now := time.Date(2021, 12, 20, 10, 0, 0, 0, time.UTC)
+
+	newOrder := func(status OrderStatus, executed float64, updateTime time.Time) Order {
+		return Order{
+			SubmitOrder:      SubmitOrder{Symbol: "BTCUSDT", Quantity: 1.0},
+			OrderID:          1,
+			Status:           status,
+			ExecutedQuantity: executed,
+			UpdateTime:       Time(updateTime),
+		}
+	}
+
+	assert.True(t, sequencer.accept(newOrder(OrderStatusNew, 0, now), now))
+	assert.False(t, sequencer.accept(newOrder(OrderStatusNew, 0, now), now), "duplicated update")
+
+	assert.True(t, sequencer.accept(newOrder(OrderStatusPartiallyFilled, 0.5, now.Add(time.Second)), now))
+	assert.True(t, sequencer.accept(newOrder(OrderStatusFilled, 1.0, now.Add(2*time.Second)), now))
+
+	// the late partially filled update and the re-sent filled update are dropped
+	assert.False(t, sequencer.accept(newOrder(OrderStatusPartiallyFilled, 0.5, now.Add(time.Second)), now))
+	assert.False(t, sequencer.accept(newOrder(OrderStatusFilled, 1.0, now.Add(3*time.Second)), now))
+
+	// the executed quantity orders the updates with the same update time
+	order := newOrder(OrderStatusPartiallyFilled, 0.2, now)
+	order.OrderID = 2
+	assert.True(t, sequencer.accept(order, now))
+	order.ExecutedQuantity = 0.1
+	assert.False(t, sequencer.accept(order, now))
+	order.ExecutedQuantity = 0.3
+	assert.True(t, sequencer.accept(order, now))
+
+	// the closed orders are pruned after the retention
+	assert.Equal(t, 2, sequencer.Len())
+	assert.True(t, sequencer.accept(Order{OrderID: 3, Status: OrderStatusNew}, now.Add(2*time.Hour)))
+	assert.Equal(t, 2, sequencer.Len())
+}
+
+func TestOrderSequenceStream(t *testing.T) {
+	source := &testStream{StandardStream: NewStandardStream()}
+	stream := NewOrderSequenceStream(source)
+	defer stream.Close()
+
+	var orders []Order
+	stream.OnOrderUpdate(func(order Order) {
+		orders = append(orders, order)
+	})
+
+	var trades []Trade
+	stream.OnTradeUpdate(func(trade Trade) {
+		trades = append(trades, trade)
+	})
+
+	source.EmitOrderUpdate(Order{OrderID: 1, Status: OrderStatusNew})
+	source.EmitOrderUpdate(Order{OrderID: 1, Status: OrderStatusCanceled})
+	source.EmitOrderUpdate(Order{OrderID: 1, Status: OrderStatusNew})
+	source.EmitOrderUpdate(Order{OrderID: 1, Status: OrderStatusCanceled})
+	source.EmitTradeUpdate(Trade{ID: 1, Symbol: "BTCUSDT"})
+
+	if assert.Len(t, orders, 2) {
+		assert.Equal(t, OrderStatusNew, orders[0].Status)
+		assert.Equal(t, OrderStatusCanceled, orders[1].Status)
+	}
+	assert.Len(t, trades, 1)
+}
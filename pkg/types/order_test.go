@@ -0,0 +1,25 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubmitOrder_EffectiveType(t *testing.T) {
+	order := SubmitOrder{Type: OrderTypeLimit}
+	assert.False(t, order.IsPostOnly())
+	assert.Equal(t, OrderTypeLimit, order.EffectiveType())
+
+	order.PostOnly = true
+	assert.True(t, order.IsPostOnly())
+	assert.Equal(t, OrderTypeLimitMaker, order.EffectiveType())
+
+	// the post-only flag is ignored by the non-limit orders
+	order.Type = OrderTypeMarket
+	assert.False(t, order.IsPostOnly())
+	assert.Equal(t, OrderTypeMarket, order.EffectiveType())
+
+	order = SubmitOrder{Type: OrderTypeLimitMaker}
+	assert.True(t, order.IsPostOnly())
+}
@@ -36,13 +36,21 @@ type MutexOrderBook struct {
 
 	Symbol    string
 	OrderBook OrderBook
+
+	// version is increased on every change of the order book, the snapshot is rebuilt when the version changes
+	version         int64
+	snapshot        *SliceOrderBook
+	snapshotVersion int64
+	snapshotDepth   int
 }
 
+// NewMutexOrderBook creates the order book indexed by the price levels (red-black tree),
+// set ENABLE_RBT_ORDERBOOK=false to fall back to the slice order book.
 func NewMutexOrderBook(symbol string) *MutexOrderBook {
-	var book OrderBook = NewSliceOrderBook(symbol)
+	var book OrderBook = NewRBOrderBook(symbol)
 
-	if v, _ := strconv.ParseBool(os.Getenv("ENABLE_RBT_ORDERBOOK")); v {
-		book = NewRBOrderBook(symbol)
+	if v, err := strconv.ParseBool(os.Getenv("ENABLE_RBT_ORDERBOOK")); err == nil && !v {
+		book = NewSliceOrderBook(symbol)
 	}
 
 	return &MutexOrderBook{
@@ -85,12 +93,14 @@ func (b *MutexOrderBook) BestAsk() (pv PriceVolume, ok bool) {
 func (b *MutexOrderBook) Load(book SliceOrderBook) {
 	b.Lock()
 	b.OrderBook.Load(book)
+	b.version++
 	b.Unlock()
 }
 
 func (b *MutexOrderBook) Reset() {
 	b.Lock()
 	b.OrderBook.Reset()
+	b.version++
 	b.Unlock()
 }
 
@@ -111,9 +121,39 @@ func (b *MutexOrderBook) Copy() OrderBook {
 func (b *MutexOrderBook) Update(update SliceOrderBook) {
 	b.Lock()
 	b.OrderBook.Update(update)
+	b.version++
 	b.Unlock()
 }
 
+// Snapshot returns a copy of the bids (descending) and the asks (ascending) with the given depth, zero depth means
+// the full depth. The price levels are collected once per order book change and shared by the following readers,
+// every reader gets its own copy, so the strategies can keep or modify the returned book without holding the lock.
+func (b *MutexOrderBook) Snapshot(depth int) SliceOrderBook {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.snapshot == nil || b.snapshotVersion != b.version || b.snapshotDepth != depth {
+		var book = b.OrderBook
+		if depth > 0 {
+			book = book.CopyDepth(depth)
+		}
+
+		b.snapshot = &SliceOrderBook{
+			Symbol: b.Symbol,
+			Bids:   book.SideBook(SideTypeBuy).Copy(),
+			Asks:   book.SideBook(SideTypeSell).Copy(),
+		}
+		b.snapshotVersion = b.version
+		b.snapshotDepth = depth
+	}
+
+	return SliceOrderBook{
+		Symbol: b.snapshot.Symbol,
+		Bids:   b.snapshot.Bids.Copy(),
+		Asks:   b.snapshot.Asks.Copy(),
+	}
+}
+
 // StreamOrderBook receives streaming data from websocket connection and
 // update the order book with mutex lock, so you can safely access it.
 type StreamOrderBook struct {
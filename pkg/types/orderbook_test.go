@@ -136,3 +136,46 @@ func TestOrderBook_IsValid(t *testing.T) {
 	assert.False(t, isValid)
 	assert.EqualError(t, err, "bid price 80000.000000 > ask price 100.000000")
 }
+
+func TestMutexOrderBook_Snapshot(t *testing.T) {
+	book := NewMutexOrderBook("BTCUSDT")
+	book.Load(SliceOrderBook{
+		Symbol: "BTCUSDT",
+		Bids: PriceVolumeSlice{
+			{Price: fixedpoint.NewFromFloat(100.0), Volume: fixedpoint.NewFromFloat(1.0)},
+			{Price: fixedpoint.NewFromFloat(99.0), Volume: fixedpoint.NewFromFloat(2.0)},
+			{Price: fixedpoint.NewFromFloat(98.0), Volume: fixedpoint.NewFromFloat(3.0)},
+		},
+		Asks: PriceVolumeSlice{
+			{Price: fixedpoint.NewFromFloat(101.0), Volume: fixedpoint.NewFromFloat(1.0)},
+			{Price: fixedpoint.NewFromFloat(102.0), Volume: fixedpoint.NewFromFloat(2.0)},
+		},
+	})
+
+	snapshot := book.Snapshot(2)
+	assert.Equal(t, "BTCUSDT", snapshot.Symbol)
+	if assert.Len(t, snapshot.Bids, 2) {
+		assert.Equal(t, fixedpoint.NewFromFloat(100.0), snapshot.Bids[0].Price)
+		assert.Equal(t, fixedpoint.NewFromFloat(99.0), snapshot.Bids[1].Price)
+	}
+	if assert.Len(t, snapshot.Asks, 2) {
+		assert.Equal(t, fixedpoint.NewFromFloat(101.0), snapshot.Asks[0].Price)
+	}
+
+	// modifying the snapshot does not affect the other readers
+	snapshot.Bids[0].Volume = fixedpoint.NewFromFloat(10.0)
+	assert.Equal(t, fixedpoint.NewFromFloat(1.0), book.Snapshot(2).Bids[0].Volume)
+
+	book.Update(SliceOrderBook{
+		Symbol: "BTCUSDT",
+		Bids: PriceVolumeSlice{
+			{Price: fixedpoint.NewFromFloat(100.0), Volume: 0},
+		},
+	})
+
+	snapshot = book.Snapshot(0)
+	if assert.Len(t, snapshot.Bids, 2) {
+		assert.Equal(t, fixedpoint.NewFromFloat(99.0), snapshot.Bids[0].Price)
+	}
+	assert.Len(t, snapshot.Asks, 2)
+}
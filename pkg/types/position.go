@@ -49,6 +49,20 @@ type Position struct {
 	IsolatedWallet         fixedpoint.Value `json:"isolatedWallet"`
 	UpdateTime             int64            `json:"updateTime"`
 
+	// FundingFee and BorrowInterest are the accrued holding cost of the current open position in quote currency,
+	// a positive value means the cost is paid, a negative value means the fee is received.
+	// the holding cost is realized proportionally into the net profit when the position is reduced.
+	FundingFee     fixedpoint.Value `json:"fundingFee"`
+	BorrowInterest fixedpoint.Value `json:"borrowInterest"`
+
+	// RealizedProfit and RealizedNetProfit are the accumulated profit of the closed quantity,
+	// the net profit is the profit after the trading fee and the holding cost
+	RealizedProfit    fixedpoint.Value `json:"realizedProfit"`
+	RealizedNetProfit fixedpoint.Value `json:"realizedNetProfit"`
+
+	// MarkPrice is the last price used to calculate the unrealized profit of the spot position
+	MarkPrice fixedpoint.Value `json:"markPrice,omitempty"`
+
 	sync.Mutex
 }
 
@@ -69,10 +83,147 @@ func NewPosition(symbol, base, quote string) *Position {
 	}
 }
 
+// Snapshot returns a copy of the position which is taken under the position lock,
+// the copy can be read or marshalled without racing with the trade updates.
+func (p *Position) Snapshot() *Position {
+	p.Lock()
+	defer p.Unlock()
+
+	snapshot := &Position{
+		Symbol:                 p.Symbol,
+		BaseCurrency:           p.BaseCurrency,
+		QuoteCurrency:          p.QuoteCurrency,
+		Market:                 p.Market,
+		Base:                   p.Base,
+		Quote:                  p.Quote,
+		AverageCost:            p.AverageCost,
+		ApproximateAverageCost: p.ApproximateAverageCost,
+		Isolated:               p.Isolated,
+		Leverage:               p.Leverage,
+		InitialMargin:          p.InitialMargin,
+		MaintMargin:            p.MaintMargin,
+		OpenOrderInitialMargin: p.OpenOrderInitialMargin,
+		PositionInitialMargin:  p.PositionInitialMargin,
+		UnrealizedProfit:       p.UnrealizedProfit,
+		EntryPrice:             p.EntryPrice,
+		MaxNotional:            p.MaxNotional,
+		PositionSide:           p.PositionSide,
+		PositionAmt:            p.PositionAmt,
+		Notional:               p.Notional,
+		IsolatedWallet:         p.IsolatedWallet,
+		UpdateTime:             p.UpdateTime,
+		FundingFee:             p.FundingFee,
+		BorrowInterest:         p.BorrowInterest,
+		RealizedProfit:         p.RealizedProfit,
+		RealizedNetProfit:      p.RealizedNetProfit,
+		MarkPrice:              p.MarkPrice,
+	}
+
+	if p.FeeRate != nil {
+		feeRate := *p.FeeRate
+		snapshot.FeeRate = &feeRate
+	}
+
+	if p.ExchangeFeeRates != nil {
+		snapshot.ExchangeFeeRates = make(map[ExchangeName]ExchangeFee, len(p.ExchangeFeeRates))
+		for ex, fee := range p.ExchangeFeeRates {
+			snapshot.ExchangeFeeRates[ex] = fee
+		}
+	}
+
+	return snapshot
+}
+
 func (p *Position) Reset() {
 	p.Base = 0
 	p.Quote = 0
 	p.AverageCost = 0
+	p.FundingFee = 0
+	p.BorrowInterest = 0
+	p.RealizedProfit = 0
+	p.RealizedNetProfit = 0
+}
+
+// UpdateMarkPrice updates the mark price of the position, which is used for calculating the unrealized profit
+func (p *Position) UpdateMarkPrice(price fixedpoint.Value) {
+	p.Lock()
+	p.MarkPrice = price
+	p.Unlock()
+}
+
+// UnrealizedProfitAt returns the unrealized profit of the position if the position is closed at the given price
+func (p *Position) UnrealizedProfitAt(price fixedpoint.Value) fixedpoint.Value {
+	p.Lock()
+	defer p.Unlock()
+	return p.unrealizedProfitAt(price)
+}
+
+// unrealizedProfitAt works for both long and short positions since the base is negative for the short position
+func (p *Position) unrealizedProfitAt(price fixedpoint.Value) fixedpoint.Value {
+	if p.Base == 0 || price == 0 {
+		return 0
+	}
+
+	return (price - p.AverageCost).Mul(p.Base)
+}
+
+// UnrealizedSpotProfit returns the unrealized profit of the position at the mark price
+func (p *Position) UnrealizedSpotProfit() fixedpoint.Value {
+	p.Lock()
+	defer p.Unlock()
+	return p.unrealizedProfitAt(p.MarkPrice)
+}
+
+// HoldingCost returns the accrued holding cost (funding fee and borrow interest) of the current open position in quote currency
+func (p *Position) HoldingCost() fixedpoint.Value {
+	p.Lock()
+	defer p.Unlock()
+	return p.FundingFee + p.BorrowInterest
+}
+
+// AddFundingFee adds the funding fee payment to the holding cost,
+// fee.Amount is positive when the funding fee is received and negative when it's paid.
+func (p *Position) AddFundingFee(fee FundingFee) {
+	p.Lock()
+	defer p.Unlock()
+	p.FundingFee -= p.toQuoteAmount(fee.Asset, fee.Amount)
+}
+
+// AddBorrowInterest adds the interest of the borrowed asset to the holding cost
+func (p *Position) AddBorrowInterest(asset string, interest fixedpoint.Value) {
+	p.Lock()
+	defer p.Unlock()
+	p.BorrowInterest += p.toQuoteAmount(asset, interest)
+}
+
+// toQuoteAmount converts the amount in base currency into quote currency by the average cost
+func (p *Position) toQuoteAmount(asset string, amount fixedpoint.Value) fixedpoint.Value {
+	if asset == p.BaseCurrency && asset != p.QuoteCurrency {
+		return amount.Mul(p.AverageCost)
+	}
+	return amount
+}
+
+// realizeHoldingCost moves the holding cost of the closed quantity out of the position
+// and returns the realized holding cost, this must be called before the base is updated.
+func (p *Position) realizeHoldingCost(closedQuantity fixedpoint.Value) fixedpoint.Value {
+	base := p.Base.Abs()
+	if base == 0 {
+		return 0
+	}
+
+	if closedQuantity >= base {
+		cost := p.FundingFee + p.BorrowInterest
+		p.FundingFee = 0
+		p.BorrowInterest = 0
+		return cost
+	}
+
+	fundingFee := p.FundingFee.Mul(closedQuantity).Div(base)
+	borrowInterest := p.BorrowInterest.Mul(closedQuantity).Div(base)
+	p.FundingFee -= fundingFee
+	p.BorrowInterest -= borrowInterest
+	return fundingFee + borrowInterest
 }
 
 func (p *Position) SetFeeRate(exchangeFee ExchangeFee) {
@@ -92,6 +243,9 @@ func (p *Position) SlackAttachment() slack.Attachment {
 	averageCost := p.AverageCost
 	base := p.Base
 	quote := p.Quote
+	holdingCost := p.FundingFee + p.BorrowInterest
+	realizedProfit := p.RealizedProfit
+	unrealizedProfit := p.unrealizedProfitAt(p.MarkPrice)
 	p.Unlock()
 
 	var posType = ""
@@ -108,29 +262,57 @@ func (p *Position) SlackAttachment() slack.Attachment {
 		color = "#DC143C"
 	}
 
+	fields := []slack.AttachmentField{
+		{Title: "Average Cost", Value: util.FormatFloat(averageCost.Float64(), 2), Short: true},
+		{Title: p.BaseCurrency, Value: util.FormatFloat(base.Float64(), 4), Short: true},
+		{Title: p.QuoteCurrency, Value: util.FormatFloat(quote.Float64(), 2)},
+	}
+
+	if holdingCost != 0 {
+		fields = append(fields, slack.AttachmentField{Title: "Holding Cost", Value: util.FormatFloat(holdingCost.Float64(), 4) + " " + p.QuoteCurrency, Short: true})
+	}
+
+	if realizedProfit != 0 {
+		fields = append(fields, slack.AttachmentField{Title: "Realized Profit", Value: util.FormatFloat(realizedProfit.Float64(), 4) + " " + p.QuoteCurrency, Short: true})
+	}
+
+	if unrealizedProfit != 0 {
+		fields = append(fields, slack.AttachmentField{Title: "Unrealized Profit", Value: util.FormatFloat(unrealizedProfit.Float64(), 4) + " " + p.QuoteCurrency, Short: true})
+	}
+
 	title := util.Render(posType+` Position {{ .Symbol }} `, p)
 	return slack.Attachment{
 		// Pretext:       "",
 		// Text:  text,
-		Title: title,
-		Color: color,
-		Fields: []slack.AttachmentField{
-			{Title: "Average Cost", Value: util.FormatFloat(averageCost.Float64(), 2), Short: true},
-			{Title: p.BaseCurrency, Value: util.FormatFloat(base.Float64(), 4), Short: true},
-			{Title: p.QuoteCurrency, Value: util.FormatFloat(quote.Float64(), 2)},
-		},
+		Title:  title,
+		Color:  color,
+		Fields: fields,
 		Footer: util.Render("update time {{ . }}", time.Now().Format(time.RFC822)),
 		// FooterIcon: "",
 	}
 }
 
 func (p *Position) PlainText() string {
-	return fmt.Sprintf("Position %s: average cost = %f, base = %f, quote = %f",
+	text := fmt.Sprintf("Position %s: average cost = %f, base = %f, quote = %f",
 		p.Symbol,
 		p.AverageCost.Float64(),
 		p.Base.Float64(),
 		p.Quote.Float64(),
 	)
+
+	if holdingCost := p.FundingFee + p.BorrowInterest; holdingCost != 0 {
+		text += fmt.Sprintf(", holding cost = %f", holdingCost.Float64())
+	}
+
+	if p.RealizedProfit != 0 {
+		text += fmt.Sprintf(", realized profit = %f", p.RealizedProfit.Float64())
+	}
+
+	if unrealizedProfit := p.unrealizedProfitAt(p.MarkPrice); unrealizedProfit != 0 {
+		text += fmt.Sprintf(", unrealized profit = %f", unrealizedProfit.Float64())
+	}
+
+	return text
 }
 
 func (p *Position) String() string {
@@ -148,6 +330,12 @@ func (p *Position) BindStream(stream Stream) {
 			p.AddTrade(trade)
 		}
 	})
+
+	stream.OnFundingFee(func(fee FundingFee) {
+		if p.Symbol == fee.Symbol {
+			p.AddFundingFee(fee)
+		}
+	})
 }
 
 func (p *Position) AddTrades(trades []Trade) (fixedpoint.Value, fixedpoint.Value, bool) {
@@ -200,6 +388,14 @@ func (p *Position) AddTrade(t Trade) (profit fixedpoint.Value, netProfit fixedpo
 	p.Lock()
 	defer p.Unlock()
 
+	// accumulate the realized profit before the lock is released
+	defer func() {
+		if madeProfit {
+			p.RealizedProfit += profit
+			p.RealizedNetProfit += netProfit
+		}
+	}()
+
 	// Base > 0 means we're in long position
 	// Base < 0  means we're in short position
 	switch t.Side {
@@ -209,7 +405,7 @@ func (p *Position) AddTrade(t Trade) (profit fixedpoint.Value, netProfit fixedpo
 			// convert short position to long position
 			if p.Base+quantity > 0 {
 				profit = (p.AverageCost - price).Mul(-p.Base)
-				netProfit = (p.ApproximateAverageCost - price).Mul(-p.Base) - feeInQuote
+				netProfit = (p.ApproximateAverageCost - price).Mul(-p.Base) - feeInQuote - p.realizeHoldingCost(-p.Base)
 				p.Base += quantity
 				p.Quote -= quoteQuantity
 				p.AverageCost = price
@@ -217,10 +413,11 @@ func (p *Position) AddTrade(t Trade) (profit fixedpoint.Value, netProfit fixedpo
 				return profit, netProfit, true
 			} else {
 				// covering short position
+				holdingCost := p.realizeHoldingCost(quantity)
 				p.Base += quantity
 				p.Quote -= quoteQuantity
 				profit = (p.AverageCost - price).Mul(quantity)
-				netProfit = (p.ApproximateAverageCost - price).Mul(quantity) - feeInQuote
+				netProfit = (p.ApproximateAverageCost - price).Mul(quantity) - feeInQuote - holdingCost
 				return profit, netProfit, true
 			}
 		}
@@ -237,17 +434,18 @@ func (p *Position) AddTrade(t Trade) (profit fixedpoint.Value, netProfit fixedpo
 			// convert long position to short position
 			if p.Base-quantity < 0 {
 				profit = (price - p.AverageCost).Mul(p.Base)
-				netProfit = (price - p.ApproximateAverageCost).Mul(p.Base) - feeInQuote
+				netProfit = (price - p.ApproximateAverageCost).Mul(p.Base) - feeInQuote - p.realizeHoldingCost(p.Base)
 				p.Base -= quantity
 				p.Quote += quoteQuantity
 				p.AverageCost = price
 				p.ApproximateAverageCost = price
 				return profit, netProfit, true
 			} else {
+				holdingCost := p.realizeHoldingCost(quantity)
 				p.Base -= quantity
 				p.Quote += quoteQuantity
 				profit = (price - p.AverageCost).Mul(quantity)
-				netProfit = (price - p.ApproximateAverageCost).Mul(quantity) - feeInQuote
+				netProfit = (price - p.ApproximateAverageCost).Mul(quantity) - feeInQuote - holdingCost
 				return profit, netProfit, true
 			}
 		}
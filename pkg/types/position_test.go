@@ -263,3 +263,118 @@ func TestPosition(t *testing.T) {
 		})
 	}
 }
+
+func TestPosition_HoldingCost(t *testing.T) {
+	pos := &Position{
+		Symbol:        "BTCUSDT",
+		BaseCurrency:  "BTC",
+		QuoteCurrency: "USDT",
+	}
+
+	pos.AddTrade(Trade{
+		Exchange:      ExchangeBinance,
+		Price:         1000.0,
+		Quantity:      2.0,
+		QuoteQuantity: 2000.0,
+		Symbol:        "BTCUSDT",
+		Side:          SideTypeBuy,
+		IsFutures:     true,
+	})
+
+	// paid 10 USDT funding fee and received 2 USDT
+	pos.AddFundingFee(FundingFee{Symbol: "BTCUSDT", Asset: "USDT", Amount: fixedpoint.NewFromFloat(-10.0)})
+	pos.AddFundingFee(FundingFee{Symbol: "BTCUSDT", Asset: "USDT", Amount: fixedpoint.NewFromFloat(2.0)})
+
+	// borrow interest in base currency is converted by the average cost
+	pos.AddBorrowInterest("BTC", fixedpoint.NewFromFloat(0.002))
+	assert.Equal(t, fixedpoint.NewFromFloat(8.0), pos.FundingFee)
+	assert.Equal(t, fixedpoint.NewFromFloat(2.0), pos.BorrowInterest)
+	assert.Equal(t, fixedpoint.NewFromFloat(10.0), pos.HoldingCost())
+
+	// close half of the position, half of the holding cost is realized
+	profit, netProfit, madeProfit := pos.AddTrade(Trade{
+		Exchange:      ExchangeBinance,
+		Price:         1100.0,
+		Quantity:      1.0,
+		QuoteQuantity: 1100.0,
+		Symbol:        "BTCUSDT",
+		Side:          SideTypeSell,
+		IsFutures:     true,
+	})
+	assert.True(t, madeProfit)
+	assert.Equal(t, fixedpoint.NewFromFloat(100.0), profit)
+	assert.Equal(t, fixedpoint.NewFromFloat(95.0), netProfit)
+	assert.Equal(t, fixedpoint.NewFromFloat(5.0), pos.HoldingCost())
+
+	// close the rest of the position, the holding cost is cleared
+	_, netProfit, _ = pos.AddTrade(Trade{
+		Exchange:      ExchangeBinance,
+		Price:         1100.0,
+		Quantity:      1.0,
+		QuoteQuantity: 1100.0,
+		Symbol:        "BTCUSDT",
+		Side:          SideTypeSell,
+		IsFutures:     true,
+	})
+	assert.Equal(t, fixedpoint.NewFromFloat(95.0), netProfit)
+	assert.Equal(t, fixedpoint.Value(0), pos.HoldingCost())
+}
+
+func TestPosition_Snapshot(t *testing.T) {
+	pos := NewPosition("BTCUSDT", "BTC", "USDT")
+	pos.SetExchangeFeeRate(ExchangeBinance, ExchangeFee{
+		MakerFeeRate: fixedpoint.NewFromFloat(0.001),
+		TakerFeeRate: fixedpoint.NewFromFloat(0.001),
+	})
+	pos.AddTrade(Trade{
+		Exchange:      ExchangeBinance,
+		Price:         3000.0,
+		Quantity:      1.0,
+		QuoteQuantity: 3000.0,
+		Symbol:        "BTCUSDT",
+		Side:          SideTypeBuy,
+	})
+
+	snapshot := pos.Snapshot()
+	assert.Equal(t, pos.Base, snapshot.Base)
+	assert.Equal(t, pos.AverageCost, snapshot.AverageCost)
+
+	// the snapshot should not be affected by the later updates
+	pos.AddTrade(Trade{
+		Exchange:      ExchangeBinance,
+		Price:         3100.0,
+		Quantity:      1.0,
+		QuoteQuantity: 3100.0,
+		Symbol:        "BTCUSDT",
+		Side:          SideTypeBuy,
+	})
+	pos.SetExchangeFeeRate(ExchangeMax, ExchangeFee{})
+
+	assert.Equal(t, fixedpoint.NewFromFloat(1.0), snapshot.Base)
+	assert.Len(t, snapshot.ExchangeFeeRates, 1)
+}
+
+func TestPosition_RealizedProfit(t *testing.T) {
+	pos := NewPosition("BTCUSDT", "BTC", "USDT")
+
+	pos.AddTrade(Trade{Symbol: "BTCUSDT", Side: SideTypeBuy, Price: 100.0, Quantity: 2.0, QuoteQuantity: 200.0})
+	assert.Equal(t, fixedpoint.Value(0), pos.RealizedProfit)
+	assert.Equal(t, 20.0, pos.UnrealizedProfitAt(fixedpoint.NewFromFloat(110.0)).Float64())
+
+	pos.AddTrade(Trade{Symbol: "BTCUSDT", Side: SideTypeSell, Price: 110.0, Quantity: 1.0, QuoteQuantity: 110.0})
+	assert.Equal(t, 10.0, pos.RealizedProfit.Float64())
+
+	// the long position is converted to the short position, only the closed quantity is realized
+	pos.AddTrade(Trade{Symbol: "BTCUSDT", Side: SideTypeSell, Price: 120.0, Quantity: 2.0, QuoteQuantity: 240.0})
+	assert.Equal(t, 30.0, pos.RealizedProfit.Float64())
+	assert.Equal(t, -1.0, pos.Base.Float64())
+	assert.Equal(t, 120.0, pos.AverageCost.Float64())
+
+	// the unrealized profit of the short position
+	pos.UpdateMarkPrice(fixedpoint.NewFromFloat(100.0))
+	assert.Equal(t, 20.0, pos.UnrealizedSpotProfit().Float64())
+
+	snapshot := pos.Snapshot()
+	assert.Equal(t, 30.0, snapshot.RealizedProfit.Float64())
+	assert.Contains(t, snapshot.PlainText(), "realized profit = 30.000000, unrealized profit = 20.000000")
+}
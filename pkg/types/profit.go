@@ -0,0 +1,147 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+// StrategyProfit is the realized profit of a strategy trade, which is recorded in the profits table
+type StrategyProfit struct {
+	GID int64 `json:"gid" db:"gid"`
+
+	Strategy           string `json:"strategy" db:"strategy"`
+	StrategyInstanceID string `json:"strategyInstanceID" db:"strategy_instance_id"`
+
+	Symbol        string `json:"symbol" db:"symbol"`
+	BaseCurrency  string `json:"baseCurrency" db:"base_currency"`
+	QuoteCurrency string `json:"quoteCurrency" db:"quote_currency"`
+
+	// Profit is the profit of the closed position, NetProfit is the profit after the trading fee
+	Profit      float64 `json:"profit" db:"profit"`
+	NetProfit   float64 `json:"netProfit" db:"net_profit"`
+	TradeAmount float64 `json:"tradeAmount" db:"trade_amount"`
+
+	Time Time `json:"time" db:"time"`
+}
+
+// ProfitPeriod is the period of the profit summary
+type ProfitPeriod string
+
+const (
+	ProfitPeriodDay   ProfitPeriod = "day"
+	ProfitPeriodWeek  ProfitPeriod = "week"
+	ProfitPeriodMonth ProfitPeriod = "month"
+)
+
+func (p ProfitPeriod) Validate() error {
+	switch p {
+	case ProfitPeriodDay, ProfitPeriodWeek, ProfitPeriodMonth:
+		return nil
+	}
+
+	return fmt.Errorf("unsupported profit period %q, valid periods are day, week and month", string(p))
+}
+
+// Start returns the beginning of the period that contains the given time, the week starts from Monday
+func (p ProfitPeriod) Start(t time.Time) time.Time {
+	day := util.BeginningOfTheDay(t)
+	switch p {
+	case ProfitPeriodWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+
+	case ProfitPeriodMonth:
+		return day.AddDate(0, 0, 1-day.Day())
+	}
+
+	return day
+}
+
+// End returns the beginning of the next period
+func (p ProfitPeriod) End(t time.Time) time.Time {
+	start := p.Start(t)
+	switch p {
+	case ProfitPeriodWeek:
+		return start.AddDate(0, 0, 7)
+
+	case ProfitPeriodMonth:
+		return start.AddDate(0, 1, 0)
+	}
+
+	return start.AddDate(0, 0, 1)
+}
+
+func (p ProfitPeriod) layout() string {
+	if p == ProfitPeriodMonth {
+		return "2006-01"
+	}
+
+	return "2006-01-02"
+}
+
+// ProfitSummary is the aggregated realized profit of a strategy in the period
+type ProfitSummary struct {
+	Strategy      string       `json:"strategy"`
+	QuoteCurrency string       `json:"quoteCurrency"`
+	Period        ProfitPeriod `json:"period"`
+	Since         time.Time    `json:"since"`
+
+	Profit      float64 `json:"profit"`
+	NetProfit   float64 `json:"netProfit"`
+	GrossProfit float64 `json:"grossProfit"`
+	GrossLoss   float64 `json:"grossLoss"`
+	TradeAmount float64 `json:"tradeAmount"`
+
+	// NumProfits is the number of the profit records, WinningRatio is the ratio of the positive profit records
+	NumProfits   int     `json:"numProfits"`
+	NumWinnings  int     `json:"numWinnings"`
+	WinningRatio float64 `json:"winningRatio"`
+}
+
+// NewProfitSummary creates the summary of the period that contains the given time
+func NewProfitSummary(strategy, quoteCurrency string, period ProfitPeriod, t time.Time) *ProfitSummary {
+	return &ProfitSummary{
+		Strategy:      strategy,
+		QuoteCurrency: quoteCurrency,
+		Period:        period,
+		Since:         period.Start(t),
+	}
+}
+
+// Add adds the profit into the summary, the profit should be in the summary period
+func (s *ProfitSummary) Add(profit StrategyProfit) {
+	s.Profit += profit.Profit
+	s.NetProfit += profit.NetProfit
+	s.TradeAmount += profit.TradeAmount
+
+	if profit.Profit > 0 {
+		s.GrossProfit += profit.Profit
+		s.NumWinnings++
+	} else if profit.Profit < 0 {
+		s.GrossLoss += profit.Profit
+	}
+
+	s.NumProfits++
+	s.WinningRatio = float64(s.NumWinnings) / float64(s.NumProfits)
+}
+
+// Contains returns true if the time is in the summary period
+func (s *ProfitSummary) Contains(t time.Time) bool {
+	return !t.Before(s.Since) && t.Before(s.Period.End(s.Since))
+}
+
+func (s *ProfitSummary) PlainText() string {
+	return fmt.Sprintf("%s %s profit since %s: profit %f %s, net profit %f %s, gross profit %f, gross loss %f, %d profits, winning ratio %.2f%%",
+		s.Strategy,
+		s.Period,
+		s.Since.Format(s.Period.layout()),
+		s.Profit, s.QuoteCurrency,
+		s.NetProfit, s.QuoteCurrency,
+		s.GrossProfit,
+		s.GrossLoss,
+		s.NumProfits,
+		s.WinningRatio*100.0,
+	)
+}
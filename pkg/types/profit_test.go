@@ -0,0 +1,45 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfitPeriod_Start(t *testing.T) {
+	// Wednesday
+	now := time.Date(2021, 12, 15, 13, 30, 0, 0, time.Local)
+
+	assert.Equal(t, time.Date(2021, 12, 15, 0, 0, 0, 0, time.Local), ProfitPeriodDay.Start(now))
+	assert.Equal(t, time.Date(2021, 12, 16, 0, 0, 0, 0, time.Local), ProfitPeriodDay.End(now))
+
+	assert.Equal(t, time.Date(2021, 12, 13, 0, 0, 0, 0, time.Local), ProfitPeriodWeek.Start(now))
+	assert.Equal(t, time.Date(2021, 12, 20, 0, 0, 0, 0, time.Local), ProfitPeriodWeek.End(now))
+
+	// Sunday belongs to the week started from Monday
+	sunday := time.Date(2021, 12, 19, 23, 0, 0, 0, time.Local)
+	assert.Equal(t, time.Date(2021, 12, 13, 0, 0, 0, 0, time.Local), ProfitPeriodWeek.Start(sunday))
+
+	assert.Equal(t, time.Date(2021, 12, 1, 0, 0, 0, 0, time.Local), ProfitPeriodMonth.Start(now))
+	assert.Equal(t, time.Date(2022, 1, 1, 0, 0, 0, 0, time.Local), ProfitPeriodMonth.End(now))
+}
+
+func TestProfitSummary(t *testing.T) {
+	now := time.Date(2021, 12, 15, 13, 30, 0, 0, time.Local)
+	summary := NewProfitSummary("grid", "USDT", ProfitPeriodDay, now)
+	summary.Add(StrategyProfit{Profit: 10.0, NetProfit: 9.0, TradeAmount: 100.0})
+	summary.Add(StrategyProfit{Profit: -2.0, NetProfit: -3.0, TradeAmount: 100.0})
+	summary.Add(StrategyProfit{Profit: 2.0, NetProfit: 1.0, TradeAmount: 100.0})
+
+	assert.Equal(t, 10.0, summary.Profit)
+	assert.Equal(t, 7.0, summary.NetProfit)
+	assert.Equal(t, 12.0, summary.GrossProfit)
+	assert.Equal(t, -2.0, summary.GrossLoss)
+	assert.Equal(t, 3, summary.NumProfits)
+	assert.InDelta(t, 2.0/3.0, summary.WinningRatio, 1e-9)
+
+	assert.True(t, summary.Contains(now))
+	assert.False(t, summary.Contains(now.AddDate(0, 0, 1)))
+	assert.Contains(t, summary.PlainText(), "grid day profit since 2021-12-15")
+}
@@ -13,6 +13,10 @@ type RBTOrderBook struct {
 	Bids   *RBTree
 	Asks   *RBTree
 
+	// bestBid and bestAsk are cached after every change, so that querying them does not traverse the trees
+	bestBid, bestAsk       PriceVolume
+	hasBestBid, hasBestAsk bool
+
 	loadCallbacks   []func(book *RBTOrderBook)
 	updateCallbacks []func(book *RBTOrderBook)
 }
@@ -26,21 +30,24 @@ func NewRBOrderBook(symbol string) *RBTOrderBook {
 }
 
 func (b *RBTOrderBook) BestBid() (PriceVolume, bool) {
-	right := b.Bids.Rightmost()
-	if right != nil {
-		return PriceVolume{Price: right.key, Volume: right.value}, true
-	}
-
-	return PriceVolume{}, false
+	return b.bestBid, b.hasBestBid
 }
 
 func (b *RBTOrderBook) BestAsk() (PriceVolume, bool) {
-	left := b.Asks.Leftmost()
-	if left != nil {
-		return PriceVolume{Price: left.key, Volume: left.value}, true
+	return b.bestAsk, b.hasBestAsk
+}
+
+// updateBest refreshes the cached best bid and ask, it costs O(log n)
+func (b *RBTOrderBook) updateBest() {
+	b.bestBid, b.hasBestBid = PriceVolume{}, false
+	if right := b.Bids.Rightmost(); right != nil {
+		b.bestBid, b.hasBestBid = PriceVolume{Price: right.key, Volume: right.value}, true
 	}
 
-	return PriceVolume{}, false
+	b.bestAsk, b.hasBestAsk = PriceVolume{}, false
+	if left := b.Asks.Leftmost(); left != nil {
+		b.bestAsk, b.hasBestAsk = PriceVolume{Price: left.key, Volume: left.value}, true
+	}
 }
 
 func (b *RBTOrderBook) Spread() (fixedpoint.Value, bool) {
@@ -90,6 +97,7 @@ func (b *RBTOrderBook) Update(book SliceOrderBook) {
 func (b *RBTOrderBook) Reset() {
 	b.Bids = NewRBTree()
 	b.Asks = NewRBTree()
+	b.updateBest()
 }
 
 func (b *RBTOrderBook) updateAsks(pvs PriceVolumeSlice) {
@@ -115,18 +123,19 @@ func (b *RBTOrderBook) updateBids(pvs PriceVolumeSlice) {
 func (b *RBTOrderBook) update(book SliceOrderBook) {
 	b.updateBids(book.Bids)
 	b.updateAsks(book.Asks)
+	b.updateBest()
 }
 
-func (b *RBTOrderBook) load(book SliceOrderBook) {
-	b.Reset()
-	b.updateBids(book.Bids)
-	b.updateAsks(book.Asks)
+// Depth returns the number of the price levels of the bids and the asks
+func (b *RBTOrderBook) Depth() (bids, asks int) {
+	return b.Bids.Size(), b.Asks.Size()
 }
 
 func (b *RBTOrderBook) Copy() OrderBook {
 	var book = NewRBOrderBook(b.Symbol)
 	book.Asks = b.Asks.Copy()
 	book.Bids = b.Bids.Copy()
+	book.updateBest()
 	return book
 }
 
@@ -134,6 +143,7 @@ func (b *RBTOrderBook) CopyDepth(limit int) OrderBook {
 	var book = NewRBOrderBook(b.Symbol)
 	book.Asks = b.Asks.CopyInorder(limit)
 	book.Bids = b.Bids.CopyInorderReverse(limit)
+	book.updateBest()
 	return book
 }
 
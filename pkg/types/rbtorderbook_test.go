@@ -76,3 +76,58 @@ func TestRBOrderBook_LoadAndDelete(t *testing.T) {
 	ask, ok = book.BestAsk()
 	assert.False(t, ok)
 }
+
+func TestRBOrderBook_BestCache(t *testing.T) {
+	book := NewRBOrderBook("BTCUSDT")
+	book.Load(SliceOrderBook{
+		Symbol: "BTCUSDT",
+		Bids: PriceVolumeSlice{
+			{Price: fixedpoint.NewFromFloat(2800.0), Volume: fixedpoint.NewFromFloat(1.0)},
+			{Price: fixedpoint.NewFromFloat(2790.0), Volume: fixedpoint.NewFromFloat(2.0)},
+		},
+		Asks: PriceVolumeSlice{
+			{Price: fixedpoint.NewFromFloat(2810.0), Volume: fixedpoint.NewFromFloat(1.0)},
+			{Price: fixedpoint.NewFromFloat(2820.0), Volume: fixedpoint.NewFromFloat(2.0)},
+		},
+	})
+
+	bids, asks := book.Depth()
+	assert.Equal(t, 2, bids)
+	assert.Equal(t, 2, asks)
+
+	// remove the best bid and update the volume of the best ask
+	book.Update(SliceOrderBook{
+		Symbol: "BTCUSDT",
+		Bids: PriceVolumeSlice{
+			{Price: fixedpoint.NewFromFloat(2800.0), Volume: 0},
+		},
+		Asks: PriceVolumeSlice{
+			{Price: fixedpoint.NewFromFloat(2810.0), Volume: fixedpoint.NewFromFloat(3.0)},
+		},
+	})
+
+	bid, ok := book.BestBid()
+	assert.True(t, ok)
+	assert.Equal(t, fixedpoint.NewFromFloat(2790.0), bid.Price)
+	assert.Equal(t, fixedpoint.NewFromFloat(2.0), bid.Volume)
+
+	ask, ok := book.BestAsk()
+	assert.True(t, ok)
+	assert.Equal(t, fixedpoint.NewFromFloat(2810.0), ask.Price)
+	assert.Equal(t, fixedpoint.NewFromFloat(3.0), ask.Volume)
+
+	spread, ok := book.Spread()
+	assert.True(t, ok)
+	assert.Equal(t, fixedpoint.NewFromFloat(20.0), spread)
+
+	depthBook := book.CopyDepth(1)
+	ask, ok = depthBook.BestAsk()
+	assert.True(t, ok)
+	assert.Equal(t, fixedpoint.NewFromFloat(2810.0), ask.Price)
+
+	book.Reset()
+	_, ok = book.BestBid()
+	assert.False(t, ok)
+	_, ok = book.BestAsk()
+	assert.False(t, ok)
+}
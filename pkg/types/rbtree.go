@@ -9,15 +9,18 @@ import (
 type RBTree struct {
 	Root *RBNode
 	size int
-}
 
-var neel = &RBNode{ color: Black }
+	// neel is the sentinel (NIL) node of the tree, each tree has its own sentinel
+	// since the parent of the sentinel is modified when deleting nodes.
+	neel *RBNode
+}
 
 func NewRBTree() *RBTree {
-	var root = neel
-	root.parent = neel
+	var neel = &RBNode{color: Black}
+	neel.parent = neel
 	return &RBTree{
-		Root: root,
+		Root: neel,
+		neel: neel,
 	}
 }
 
@@ -35,7 +38,7 @@ func (tree *RBTree) Delete(key fixedpoint.Value) bool {
 
 	// the deleting node has only one child, it's easy,
 	// we just connect the child the parent of the deleting node
-	if deleting.left == neel || deleting.right == neel {
+	if deleting.left == tree.neel || deleting.right == tree.neel {
 		y = deleting
 		// fmt.Printf("y = deleting = %+v\n", y)
 	} else {
@@ -47,7 +50,7 @@ func (tree *RBTree) Delete(key fixedpoint.Value) bool {
 	}
 
 	// y.left or y.right could be neel
-	if y.left != neel {
+	if y.left != tree.neel {
 		x = y.left
 	} else {
 		x = y.right
@@ -56,7 +59,7 @@ func (tree *RBTree) Delete(key fixedpoint.Value) bool {
 	// fmt.Printf("x = %+v\n", y)
 	x.parent = y.parent
 
-	if y.parent == neel {
+	if y.parent == tree.neel {
 		tree.Root = x
 	} else if y == y.parent.left {
 		y.parent.left = x
@@ -144,17 +147,17 @@ func (tree *RBTree) DeleteFixup(current *RBNode) {
 }
 
 func (tree *RBTree) Upsert(key, val fixedpoint.Value) {
-	var y = neel
+	var y = tree.neel
 	var x = tree.Root
 	var node = &RBNode{
 		key:   key,
 		value: val,
 		color: Red,
-		left:  neel,
-		right: neel,
+		left:  tree.neel,
+		right: tree.neel,
 	}
 
-	for x != neel {
+	for x != tree.neel {
 		y = x
 
 		if node.key == x.key {
@@ -170,7 +173,7 @@ func (tree *RBTree) Upsert(key, val fixedpoint.Value) {
 
 	node.parent = y
 
-	if y == neel {
+	if y == tree.neel {
 		tree.Root = node
 	} else if node.key < y.key {
 		y.left = node
@@ -178,21 +181,22 @@ func (tree *RBTree) Upsert(key, val fixedpoint.Value) {
 		y.right = node
 	}
 
+	tree.size++
 	tree.InsertFixup(node)
 }
 
 func (tree *RBTree) Insert(key, val fixedpoint.Value) {
-	var y = neel
+	var y = tree.neel
 	var x = tree.Root
 	var node = &RBNode{
 		key:   key,
 		value: val,
 		color: Red,
-		left:  neel,
-		right: neel,
+		left:  tree.neel,
+		right: tree.neel,
 	}
 
-	for x != neel {
+	for x != tree.neel {
 		y = x
 
 		if node.key < x.key {
@@ -204,7 +208,7 @@ func (tree *RBTree) Insert(key, val fixedpoint.Value) {
 
 	node.parent = y
 
-	if y == neel {
+	if y == tree.neel {
 		tree.Root = node
 	} else if node.key < y.key {
 		y.left = node
@@ -218,7 +222,7 @@ func (tree *RBTree) Insert(key, val fixedpoint.Value) {
 
 func (tree *RBTree) Search(key fixedpoint.Value) *RBNode {
 	var current = tree.Root
-	for current != neel && key != current.key {
+	for current != tree.neel && key != current.key {
 		if key < current.key {
 			current = current.left
 		} else {
@@ -226,7 +230,7 @@ func (tree *RBTree) Search(key fixedpoint.Value) *RBNode {
 		}
 	}
 
-	if current == neel {
+	if current == tree.neel {
 		return nil
 	}
 
@@ -291,13 +295,13 @@ func (tree *RBTree) RotateLeft(x *RBNode) {
 	var y = x.right
 	x.right = y.left
 
-	if y.left != neel {
+	if y.left != tree.neel {
 		y.left.parent = x
 	}
 
 	y.parent = x.parent
 
-	if x.parent == neel {
+	if x.parent == tree.neel {
 		tree.Root = y
 	} else if x == x.parent.left {
 		x.parent.left = y
@@ -313,13 +317,13 @@ func (tree *RBTree) RotateRight(y *RBNode) {
 	x := y.left
 	y.left = x.right
 
-	if x.right != neel {
+	if x.right != tree.neel {
 		x.right.parent = y
 	}
 
 	x.parent = y.parent
 
-	if y.parent == neel {
+	if y.parent == tree.neel {
 		tree.Root = x
 	} else if y == y.parent.left {
 		y.parent.left = x
@@ -336,15 +340,15 @@ func (tree *RBTree) Rightmost() *RBNode {
 }
 
 func (tree *RBTree) RightmostOf(current *RBNode) *RBNode {
-	if current == neel || current == nil {
+	if current == tree.neel || current == nil {
 		return nil
 	}
 
-	for current.right != neel && current.right != nil {
+	for current.right != tree.neel && current.right != nil {
 		current = current.right
 	}
 
-	if current == neel {
+	if current == tree.neel {
 		return nil
 	}
 
@@ -356,15 +360,15 @@ func (tree *RBTree) Leftmost() *RBNode {
 }
 
 func (tree *RBTree) LeftmostOf(current *RBNode) *RBNode {
-	if current == neel || current == nil {
+	if current == tree.neel || current == nil {
 		return nil
 	}
 
-	for current.left != neel && current.left != nil {
+	for current.left != tree.neel && current.left != nil {
 		current = current.left
 	}
 
-	if current == neel {
+	if current == tree.neel {
 		return nil
 	}
 
@@ -372,12 +376,12 @@ func (tree *RBTree) LeftmostOf(current *RBNode) *RBNode {
 }
 
 func (tree *RBTree) Successor(current *RBNode) *RBNode {
-	if current.right != neel {
+	if current.right != tree.neel {
 		return tree.LeftmostOf(current.right)
 	}
 
 	var newNode = current.parent
-	for newNode != neel && current == newNode.right {
+	for newNode != tree.neel && current == newNode.right {
 		current = newNode
 		newNode = newNode.parent
 	}
@@ -390,7 +394,7 @@ func (tree *RBTree) Preorder(cb func(n *RBNode)) {
 }
 
 func (tree *RBTree) PreorderOf(current *RBNode, cb func(n *RBNode)) {
-	if current != neel && current != nil {
+	if current != tree.neel && current != nil {
 		cb(current)
 		tree.PreorderOf(current.left, cb)
 		tree.PreorderOf(current.right, cb)
@@ -403,13 +407,16 @@ func (tree *RBTree) Inorder(cb func(n *RBNode) bool) {
 }
 
 func (tree *RBTree) InorderOf(current *RBNode, cb func(n *RBNode) bool) {
-	if current != neel && current != nil {
-		tree.InorderOf(current.left, cb)
-		if !cb(current) {
-			return
-		}
-		tree.InorderOf(current.right, cb)
+	tree.inorderOf(current, cb)
+}
+
+// inorderOf returns false when the callback stops the traversal, so that the traversal of the parents stops too
+func (tree *RBTree) inorderOf(current *RBNode, cb func(n *RBNode) bool) bool {
+	if current == tree.neel || current == nil {
+		return true
 	}
+
+	return tree.inorderOf(current.left, cb) && cb(current) && tree.inorderOf(current.right, cb)
 }
 
 // InorderReverse traverses the tree in descending order
@@ -418,13 +425,15 @@ func (tree *RBTree) InorderReverse(cb func(n *RBNode) bool) {
 }
 
 func (tree *RBTree) InorderReverseOf(current *RBNode, cb func(n *RBNode) bool) {
-	if current != neel && current != nil {
-		tree.InorderReverseOf(current.right, cb)
-		if !cb(current) {
-			return
-		}
-		tree.InorderReverseOf(current.left, cb)
+	tree.inorderReverseOf(current, cb)
+}
+
+func (tree *RBTree) inorderReverseOf(current *RBNode, cb func(n *RBNode) bool) bool {
+	if current == tree.neel || current == nil {
+		return true
 	}
+
+	return tree.inorderReverseOf(current.right, cb) && cb(current) && tree.inorderReverseOf(current.left, cb)
 }
 
 func (tree *RBTree) Postorder(cb func(n *RBNode) bool) {
@@ -432,23 +441,27 @@ func (tree *RBTree) Postorder(cb func(n *RBNode) bool) {
 }
 
 func (tree *RBTree) PostorderOf(current *RBNode, cb func(n *RBNode) bool) {
-	if current != neel && current != nil {
-		tree.PostorderOf(current.left, cb)
-		tree.PostorderOf(current.right, cb)
-		if !cb(current) {
-			return
-		}
+	tree.postorderOf(current, cb)
+}
+
+func (tree *RBTree) postorderOf(current *RBNode, cb func(n *RBNode) bool) bool {
+	if current == tree.neel || current == nil {
+		return true
 	}
+
+	return tree.postorderOf(current.left, cb) && tree.postorderOf(current.right, cb) && cb(current)
 }
 
-func (tree *RBTree) copyNode(node *RBNode) *RBNode {
-	if node == neel {
-		return neel
+// copyNode copies the subtree into the new tree, the sentinel and the parent pointers are replaced with the new tree's
+func (tree *RBTree) copyNode(newTree *RBTree, node, parent *RBNode) *RBNode {
+	if node == tree.neel {
+		return newTree.neel
 	}
 
 	newNode := *node
-	newNode.left = tree.copyNode(node.left)
-	newNode.right = tree.copyNode(node.right)
+	newNode.parent = parent
+	newNode.left = tree.copyNode(newTree, node.left, &newNode)
+	newNode.right = tree.copyNode(newTree, node.right, &newNode)
 	return &newNode
 }
 
@@ -492,6 +505,7 @@ func (tree *RBTree) Print() {
 
 func (tree *RBTree) Copy() *RBTree {
 	newTree := NewRBTree()
-	newTree.Root = tree.copyNode(tree.Root)
+	newTree.Root = tree.copyNode(newTree, tree.Root, newTree.neel)
+	newTree.size = tree.size
 	return newTree
 }
@@ -140,3 +140,68 @@ func TestTree(t *testing.T) {
 	assert.True(t, deleted)
 
 }
+
+func TestRBTree_UpsertSize(t *testing.T) {
+	tree := NewRBTree()
+	tree.Upsert(fixedpoint.NewFromInt(10), fixedpoint.NewFromInt(1))
+	tree.Upsert(fixedpoint.NewFromInt(11), fixedpoint.NewFromInt(1))
+	assert.Equal(t, 2, tree.Size())
+
+	// update the existing node
+	tree.Upsert(fixedpoint.NewFromInt(10), fixedpoint.NewFromInt(2))
+	assert.Equal(t, 2, tree.Size())
+	assert.Equal(t, fixedpoint.NewFromInt(2), tree.Search(fixedpoint.NewFromInt(10)).value)
+
+	assert.True(t, tree.Delete(fixedpoint.NewFromInt(10)))
+	assert.Equal(t, 1, tree.Size())
+}
+
+func TestRBTree_InorderStop(t *testing.T) {
+	tree := NewRBTree()
+	for i := 1; i <= 100; i++ {
+		tree.Insert(fixedpoint.NewFromInt(i), fixedpoint.NewFromInt(i))
+	}
+
+	var keys []fixedpoint.Value
+	tree.Inorder(func(n *RBNode) bool {
+		keys = append(keys, n.key)
+		return len(keys) < 3
+	})
+	assert.Equal(t, []fixedpoint.Value{fixedpoint.NewFromInt(1), fixedpoint.NewFromInt(2), fixedpoint.NewFromInt(3)}, keys)
+
+	keys = nil
+	tree.InorderReverse(func(n *RBNode) bool {
+		keys = append(keys, n.key)
+		return len(keys) < 3
+	})
+	assert.Equal(t, []fixedpoint.Value{fixedpoint.NewFromInt(100), fixedpoint.NewFromInt(99), fixedpoint.NewFromInt(98)}, keys)
+
+	newTree := tree.CopyInorderReverse(5)
+	assert.Equal(t, 5, newTree.Size())
+	assert.Equal(t, fixedpoint.NewFromInt(96), newTree.Leftmost().key)
+}
+
+func TestRBTree_CopyIsIndependent(t *testing.T) {
+	tree := NewRBTree()
+	for i := 1; i <= 50; i++ {
+		tree.Insert(fixedpoint.NewFromInt(i), fixedpoint.NewFromInt(i))
+	}
+
+	newTree := tree.Copy()
+	assert.Equal(t, 50, newTree.Size())
+
+	// deleting from the copy rebalances the copy only
+	for i := 1; i <= 40; i++ {
+		assert.True(t, newTree.Delete(fixedpoint.NewFromInt(i)))
+	}
+
+	assert.Equal(t, 10, newTree.Size())
+	assert.Equal(t, fixedpoint.NewFromInt(41), newTree.Leftmost().key)
+	assert.Equal(t, fixedpoint.NewFromInt(50), newTree.Rightmost().key)
+
+	assert.Equal(t, 50, tree.Size())
+	assert.Equal(t, fixedpoint.NewFromInt(1), tree.Leftmost().key)
+	for i := 1; i <= 50; i++ {
+		assert.NotNil(t, tree.Search(fixedpoint.NewFromInt(i)))
+	}
+}
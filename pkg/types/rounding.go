@@ -0,0 +1,108 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// RoundingPolicy defines how the order price and quantity are rounded to the tick size and the step size of the market
+type RoundingPolicy string
+
+const (
+	// RoundingPolicyDown rounds the price and the quantity down to the tick size and the step size, it's the default policy
+	RoundingPolicyDown = RoundingPolicy("down")
+
+	// RoundingPolicyNearest rounds the price and the quantity to the nearest tick and step
+	RoundingPolicyNearest = RoundingPolicy("nearest")
+
+	// RoundingPolicyAggressive rounds the price toward the fill, i.e., the buy price is rounded up and the sell price
+	// is rounded down. The quantity is rounded down so that the order does not exceed the balance.
+	RoundingPolicyAggressive = RoundingPolicy("aggressive")
+)
+
+func (p RoundingPolicy) Validate() error {
+	switch p {
+	case "", RoundingPolicyDown, RoundingPolicyNearest, RoundingPolicyAggressive:
+		return nil
+	}
+
+	return fmt.Errorf("invalid rounding policy %q, valid policies are: down, nearest, aggressive", string(p))
+}
+
+// roundingEpsilon absorbs the float error of the division, e.g., 0.3 / 0.1 = 2.9999999999999996
+const roundingEpsilon = 1e-9
+
+// RoundPrice rounds the price to the tick size of the market with the policy, the side is used by the aggressive policy
+func (m Market) RoundPrice(price float64, side SideType, policy RoundingPolicy) float64 {
+	if policy == RoundingPolicyAggressive {
+		if side == SideTypeBuy {
+			return roundToStep(price, m.TickSize, math.Ceil)
+		}
+
+		return roundToStep(price, m.TickSize, math.Floor)
+	}
+
+	return roundToStep(price, m.TickSize, roundingFunc(policy))
+}
+
+// RoundQuantity rounds the quantity to the step size of the market with the policy
+func (m Market) RoundQuantity(quantity float64, policy RoundingPolicy) float64 {
+	if policy == RoundingPolicyAggressive {
+		policy = RoundingPolicyDown
+	}
+
+	return roundToStep(quantity, m.StepSize, roundingFunc(policy))
+}
+
+// FormatRoundedPrice formats the price that is already rounded to the tick size
+func (m Market) FormatRoundedPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', stepPrecision(m.TickSize), 64)
+}
+
+// FormatRoundedQuantity formats the quantity that is already rounded to the step size
+func (m Market) FormatRoundedQuantity(quantity float64) string {
+	return strconv.FormatFloat(quantity, 'f', stepPrecision(m.StepSize), 64)
+}
+
+func roundingFunc(policy RoundingPolicy) func(float64) float64 {
+	if policy == RoundingPolicyNearest {
+		return math.Round
+	}
+
+	return math.Floor
+}
+
+func roundToStep(value, step float64, round func(float64) float64) float64 {
+	if step <= 0 {
+		return value
+	}
+
+	n := value / step
+	if math.Abs(n-math.Round(n)) < roundingEpsilon {
+		n = math.Round(n)
+	} else {
+		n = round(n)
+	}
+
+	// round again with the precision to remove the float error of the multiplication
+	p := math.Pow10(stepPrecision(step))
+	return math.Round(n*step*p) / p
+}
+
+// stepPrecision returns the number of the decimal places of the step, e.g., 0.05 => 2, 0.5 => 1, 10 => 0,
+// -1 is returned for the unknown step so that the value is formatted with the necessary digits.
+func stepPrecision(step float64) int {
+	if step <= 0 {
+		return -1
+	}
+
+	s := strconv.FormatFloat(step, 'f', -1, 64)
+	i := strings.IndexByte(s, '.')
+	if i < 0 {
+		return 0
+	}
+
+	return len(s) - i - 1
+}
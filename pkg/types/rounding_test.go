@@ -0,0 +1,51 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarket_RoundPrice(t *testing.T) {
+	market := Market{Symbol: "BTCUSDT", TickSize: 0.5, StepSize: 0.001}
+
+	assert.Equal(t, 100.5, market.RoundPrice(100.7, SideTypeBuy, RoundingPolicyDown))
+	assert.Equal(t, 100.5, market.RoundPrice(100.7, SideTypeBuy, ""))
+	assert.Equal(t, 101.0, market.RoundPrice(100.8, SideTypeSell, RoundingPolicyNearest))
+
+	// aggressive rounds toward the fill
+	assert.Equal(t, 101.0, market.RoundPrice(100.6, SideTypeBuy, RoundingPolicyAggressive))
+	assert.Equal(t, 100.5, market.RoundPrice(100.9, SideTypeSell, RoundingPolicyAggressive))
+
+	// the price on the tick is not changed
+	assert.Equal(t, 100.5, market.RoundPrice(100.5, SideTypeBuy, RoundingPolicyAggressive))
+
+	// the float error should not move the price one tick away
+	market = Market{Symbol: "XRPUSDT", TickSize: 0.1, StepSize: 0.1}
+	assert.Equal(t, 0.3, market.RoundPrice(0.1+0.2, SideTypeSell, RoundingPolicyDown))
+	assert.Equal(t, 0.3, market.RoundPrice(0.1+0.2, SideTypeBuy, RoundingPolicyAggressive))
+	assert.Equal(t, "0.3", market.FormatRoundedPrice(market.RoundPrice(0.29999999999, SideTypeBuy, RoundingPolicyDown)))
+}
+
+func TestMarket_RoundQuantity(t *testing.T) {
+	market := Market{Symbol: "BTCUSDT", TickSize: 0.01, StepSize: 0.001}
+
+	assert.Equal(t, 0.123, market.RoundQuantity(0.1239, RoundingPolicyDown))
+	assert.Equal(t, 0.124, market.RoundQuantity(0.1236, RoundingPolicyNearest))
+	assert.Equal(t, 0.123, market.RoundQuantity(0.1239, RoundingPolicyAggressive))
+	assert.Equal(t, "0.123", market.FormatRoundedQuantity(0.123))
+}
+
+func TestRoundingPolicy_Validate(t *testing.T) {
+	assert.NoError(t, RoundingPolicy("").Validate())
+	assert.NoError(t, RoundingPolicyAggressive.Validate())
+	assert.Error(t, RoundingPolicy("up").Validate())
+}
+
+func Test_stepPrecision(t *testing.T) {
+	assert.Equal(t, 2, stepPrecision(0.05))
+	assert.Equal(t, 1, stepPrecision(0.5))
+	assert.Equal(t, 0, stepPrecision(10))
+	assert.Equal(t, 8, stepPrecision(0.00000001))
+	assert.Equal(t, -1, stepPrecision(0))
+}
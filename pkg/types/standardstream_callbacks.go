@@ -34,6 +34,16 @@ func (stream *StandardStream) EmitDisconnect() {
 	}
 }
 
+func (stream *StandardStream) OnReconnect(cb func()) {
+	stream.reconnectCallbacks = append(stream.reconnectCallbacks, cb)
+}
+
+func (stream *StandardStream) EmitReconnect() {
+	for _, cb := range stream.reconnectCallbacks {
+		cb()
+	}
+}
+
 func (stream *StandardStream) OnTradeUpdate(cb func(trade Trade)) {
 	stream.tradeUpdateCallbacks = append(stream.tradeUpdateCallbacks, cb)
 }
@@ -114,6 +124,16 @@ func (stream *StandardStream) EmitBookSnapshot(book SliceOrderBook) {
 	}
 }
 
+func (stream *StandardStream) OnMarketTrade(cb func(trade Trade)) {
+	stream.marketTradeCallbacks = append(stream.marketTradeCallbacks, cb)
+}
+
+func (stream *StandardStream) EmitMarketTrade(trade Trade) {
+	for _, cb := range stream.marketTradeCallbacks {
+		cb(trade)
+	}
+}
+
 func (stream *StandardStream) OnPositionUpdate(cb func(position PositionMap)) {
 	stream.PositionUpdateCallbacks = append(stream.PositionUpdateCallbacks, cb)
 }
@@ -134,6 +154,16 @@ func (stream *StandardStream) EmitPositionSnapshot(position PositionMap) {
 	}
 }
 
+func (stream *StandardStream) OnFundingFee(cb func(fee FundingFee)) {
+	stream.fundingFeeCallbacks = append(stream.fundingFeeCallbacks, cb)
+}
+
+func (stream *StandardStream) EmitFundingFee(fee FundingFee) {
+	for _, cb := range stream.fundingFeeCallbacks {
+		cb(fee)
+	}
+}
+
 type StandardStreamEventHub interface {
 	OnStart(cb func())
 
@@ -141,6 +171,8 @@ type StandardStreamEventHub interface {
 
 	OnDisconnect(cb func())
 
+	OnReconnect(cb func())
+
 	OnTradeUpdate(cb func(trade Trade))
 
 	OnOrderUpdate(cb func(order Order))
@@ -157,7 +189,11 @@ type StandardStreamEventHub interface {
 
 	OnBookSnapshot(cb func(book SliceOrderBook))
 
+	OnMarketTrade(cb func(trade Trade))
+
 	OnPositionUpdate(cb func(position PositionMap))
 
 	OnPositionSnapshot(cb func(position PositionMap))
+
+	OnFundingFee(cb func(fee FundingFee))
 }
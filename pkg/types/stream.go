@@ -2,8 +2,18 @@ package types
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultHeartbeatInterval = 10 * time.Second
+	minReconnectBackoff      = time.Second
+	maxReconnectBackoff      = time.Minute
 )
 
 type Stream interface {
@@ -21,10 +31,52 @@ var BookChannel = Channel("book")
 
 var KLineChannel = Channel("kline")
 
+// MarketTradeChannel is the public trade channel of the symbol
+var MarketTradeChannel = Channel("trade")
+
+// Parser parses the raw websocket message into the exchange specific event
+type Parser func(message []byte) (interface{}, error)
+
+// Dispatcher dispatches the parsed event to the stream callbacks
+type Dispatcher func(e interface{})
+
+// EndpointCreator returns the websocket endpoint, it's called on every (re)connect,
+// so that the adapter can refresh the listen key or the token.
+type EndpointCreator func(ctx context.Context) (string, error)
+
+// SubscriptionBuilder builds the messages sent right after the connection is established,
+// e.g., the subscribe messages of the public channels or the login message of the private channels.
+// Since it's called on every (re)connect, the subscriptions are restored after reconnecting.
+type SubscriptionBuilder func(subscriptions []Subscription) ([]interface{}, error)
+
+// Heartbeat sends the application level heartbeat message, e.g., {"op":"ping"}
+type Heartbeat func(conn *websocket.Conn) error
+
 //go:generate callbackgen -type StandardStream -interface
 type StandardStream struct {
 	ReconnectC chan struct{}
 
+	// CloseC is closed when the stream is closed, the reconnector stops reconnecting after that
+	CloseC chan struct{}
+
+	// Conn is the current websocket connection, it's replaced when reconnecting
+	Conn       *websocket.Conn
+	ConnCtx    context.Context
+	ConnCancel context.CancelFunc
+
+	connLock  sync.Mutex
+	closeOnce sync.Once
+
+	parser              Parser
+	dispatcher          Dispatcher
+	endpointCreator     EndpointCreator
+	subscriptionBuilder SubscriptionBuilder
+	heartbeat           Heartbeat
+	heartbeatInterval   time.Duration
+
+	// EnableCompression enables the permessage-deflate compression negotiation
+	EnableCompression bool
+
 	Subscriptions []Subscription
 
 	startCallbacks []func()
@@ -33,6 +85,8 @@ type StandardStream struct {
 
 	disconnectCallbacks []func()
 
+	reconnectCallbacks []func()
+
 	// private trade update callbacks
 	tradeUpdateCallbacks []func(trade Trade)
 
@@ -52,10 +106,45 @@ type StandardStream struct {
 
 	bookSnapshotCallbacks []func(book SliceOrderBook)
 
+	// public market trade callbacks
+	marketTradeCallbacks []func(trade Trade)
+
 	// Futures
 	PositionUpdateCallbacks []func(position PositionMap)
 
 	PositionSnapshotCallbacks []func(position PositionMap)
+
+	fundingFeeCallbacks []func(fee FundingFee)
+}
+
+func NewStandardStream() StandardStream {
+	return StandardStream{
+		ReconnectC: make(chan struct{}, 1),
+		CloseC:     make(chan struct{}),
+	}
+}
+
+func (stream *StandardStream) SetParser(parser Parser) {
+	stream.parser = parser
+}
+
+func (stream *StandardStream) SetDispatcher(dispatcher Dispatcher) {
+	stream.dispatcher = dispatcher
+}
+
+func (stream *StandardStream) SetEndpointCreator(creator EndpointCreator) {
+	stream.endpointCreator = creator
+}
+
+func (stream *StandardStream) SetSubscriptionBuilder(builder SubscriptionBuilder) {
+	stream.subscriptionBuilder = builder
+}
+
+// SetHeartbeat sets the heartbeat interval, the connection is considered dead if nothing is read in two intervals.
+// When heartbeat is nil, the websocket ping control frames are sent.
+func (stream *StandardStream) SetHeartbeat(interval time.Duration, heartbeat Heartbeat) {
+	stream.heartbeatInterval = interval
+	stream.heartbeat = heartbeat
 }
 
 func (stream *StandardStream) Subscribe(channel Channel, symbol string, options SubscribeOptions) {
@@ -73,8 +162,13 @@ func (stream *StandardStream) Reconnect() {
 	}
 }
 
+// SetCompression implements CompressionSetter
+func (stream *StandardStream) SetCompression(enabled bool) {
+	stream.EnableCompression = enabled
+}
+
 func (stream *StandardStream) Dial(url string) (*websocket.Conn, error) {
-	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	conn, _, err := NewDialer(stream.EnableCompression).Dial(url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -84,6 +178,296 @@ func (stream *StandardStream) Dial(url string) (*websocket.Conn, error) {
 	return conn, nil
 }
 
+// Connect dials the endpoint and starts the reconnector, the adapters that set up the endpoint creator,
+// the parser and the dispatcher can use it as their Connect method.
+func (stream *StandardStream) Connect(ctx context.Context) error {
+	if err := stream.DialAndConnect(ctx); err != nil {
+		return err
+	}
+
+	// start one re-connector goroutine with the base context
+	go stream.Reconnector(ctx)
+
+	stream.EmitStart()
+	return nil
+}
+
+// DialAndConnect creates a new connection, sends the subscriptions and starts the reader and the heartbeat workers
+func (stream *StandardStream) DialAndConnect(ctx context.Context) error {
+	if stream.endpointCreator == nil {
+		return errors.New("websocket endpoint creator is not set")
+	}
+
+	url, err := stream.endpointCreator(ctx)
+	if err != nil {
+		return err
+	}
+
+	conn, err := stream.Dial(url)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("websocket connected: %s", url)
+
+	connCtx, connCancel := stream.SetConn(ctx, conn)
+
+	if err := stream.sendSubscriptions(); err != nil {
+		connCancel()
+		_ = conn.Close()
+		return err
+	}
+
+	stream.EmitConnect()
+
+	go stream.Read(connCtx, conn, connCancel)
+	go stream.ping(connCtx, conn, connCancel)
+	return nil
+}
+
+// SetConn replaces the current connection, the context of the previous connection is cancelled
+func (stream *StandardStream) SetConn(ctx context.Context, conn *websocket.Conn) (context.Context, context.CancelFunc) {
+	stream.connLock.Lock()
+	defer stream.connLock.Unlock()
+
+	// ensure the previous context is cancelled
+	if stream.ConnCancel != nil {
+		stream.ConnCancel()
+	}
+
+	if stream.Conn != nil {
+		_ = stream.Conn.Close()
+	}
+
+	stream.ConnCtx, stream.ConnCancel = context.WithCancel(ctx)
+	stream.Conn = conn
+	return stream.ConnCtx, stream.ConnCancel
+}
+
+// WriteJSON writes the message to the current connection
+func (stream *StandardStream) WriteJSON(v interface{}) error {
+	stream.connLock.Lock()
+	defer stream.connLock.Unlock()
+
+	if stream.Conn == nil {
+		return errors.New("websocket is not connected")
+	}
+
+	return stream.Conn.WriteJSON(v)
+}
+
+func (stream *StandardStream) sendSubscriptions() error {
+	if stream.subscriptionBuilder == nil {
+		return nil
+	}
+
+	messages, err := stream.subscriptionBuilder(stream.Subscriptions)
+	if err != nil {
+		return err
+	}
+
+	for _, message := range messages {
+		if err := stream.WriteJSON(message); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (stream *StandardStream) readTimeout() time.Duration {
+	if stream.heartbeatInterval > 0 {
+		return stream.heartbeatInterval * 2
+	}
+	return defaultHeartbeatInterval * 2
+}
+
+func (stream *StandardStream) isClosed() bool {
+	select {
+	case <-stream.CloseC:
+		return true
+	default:
+		return false
+	}
+}
+
+// Read reads the messages from the connection until the connection is closed,
+// the messages are parsed by the parser and then dispatched by the dispatcher.
+// A reconnect is signaled on read errors unless the stream is closed.
+func (stream *StandardStream) Read(ctx context.Context, conn *websocket.Conn, cancel context.CancelFunc) {
+	defer func() {
+		cancel()
+		stream.EmitDisconnect()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-stream.CloseC:
+			return
+
+		default:
+		}
+
+		if err := conn.SetReadDeadline(time.Now().Add(stream.readTimeout())); err != nil {
+			log.WithError(err).Errorf("set read deadline error")
+		}
+
+		mt, message, err := conn.ReadMessage()
+		if err != nil {
+			// the connection is closed by us, or replaced by a new connection
+			if stream.isClosed() || ctx.Err() != nil {
+				return
+			}
+
+			log.WithError(err).Error("websocket read error, reconnecting...")
+			stream.Reconnect()
+			return
+		}
+
+		mt, message, err = DecompressMessage(mt, message)
+		if err != nil {
+			log.WithError(err).Error("websocket message decompress error")
+			continue
+		}
+
+		// skip non-text messages
+		if mt != websocket.TextMessage {
+			continue
+		}
+
+		var e interface{} = message
+		if stream.parser != nil {
+			e, err = stream.parser(message)
+			if err != nil {
+				log.WithError(err).Errorf("websocket message parse error: %s", message)
+				continue
+			}
+		}
+
+		if e != nil && stream.dispatcher != nil {
+			stream.dispatcher(e)
+		}
+	}
+}
+
+func (stream *StandardStream) ping(ctx context.Context, conn *websocket.Conn, cancel context.CancelFunc) {
+	interval := stream.heartbeatInterval
+	if interval == 0 {
+		interval = defaultHeartbeatInterval
+	}
+
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(stream.readTimeout()))
+	})
+
+	pingTicker := time.NewTicker(interval)
+	defer pingTicker.Stop()
+
+	for {
+		select {
+
+		case <-ctx.Done():
+			log.Debug("ping worker stopped")
+			return
+
+		case <-pingTicker.C:
+			var err error
+			if stream.heartbeat != nil {
+				stream.connLock.Lock()
+				err = stream.heartbeat(conn)
+				stream.connLock.Unlock()
+			} else {
+				err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(3*time.Second))
+			}
+
+			if err != nil {
+				log.WithError(err).Error("ping error, reconnecting...")
+				cancel()
+				stream.Reconnect()
+				return
+			}
+		}
+	}
+}
+
+// Reconnector reconnects when the reconnect signal is received,
+// the reconnect delay grows exponentially from 1 second to 1 minute until the connection is established.
+func (stream *StandardStream) Reconnector(ctx context.Context) {
+	backoff := minReconnectBackoff
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-stream.CloseC:
+			return
+
+		case <-stream.ReconnectC:
+			log.Warnf("received reconnect signal, reconnecting in %s...", backoff)
+
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-stream.CloseC:
+				return
+
+			case <-time.After(backoff):
+			}
+
+			if err := stream.DialAndConnect(ctx); err != nil {
+				log.WithError(err).Errorf("reconnect error, try to reconnect again...")
+				backoff = nextReconnectBackoff(backoff)
+				stream.Reconnect()
+				continue
+			}
+
+			backoff = minReconnectBackoff
+			stream.EmitReconnect()
+		}
+	}
+}
+
+func nextReconnectBackoff(backoff time.Duration) time.Duration {
+	backoff *= 2
+	if backoff > maxReconnectBackoff {
+		return maxReconnectBackoff
+	}
+	return backoff
+}
+
+// Close stops the reconnector and closes the current connection
+func (stream *StandardStream) Close() error {
+	stream.closeOnce.Do(func() {
+		if stream.CloseC != nil {
+			close(stream.CloseC)
+		}
+	})
+
+	stream.connLock.Lock()
+	defer stream.connLock.Unlock()
+
+	if stream.ConnCancel != nil {
+		stream.ConnCancel()
+	}
+
+	if stream.Conn == nil {
+		return nil
+	}
+
+	// send the close frame and then close the underlying connection
+	err := stream.Conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+	if err != nil {
+		log.WithError(err).Warn("websocket close message error")
+	}
+
+	return stream.Conn.Close()
+}
+
 // SubscribeOptions provides the standard stream options
 type SubscribeOptions struct {
 	Interval string `json:"interval,omitempty"`
@@ -0,0 +1,128 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_nextReconnectBackoff(t *testing.T) {
+	assert.Equal(t, 2*time.Second, nextReconnectBackoff(time.Second))
+	assert.Equal(t, 32*time.Second, nextReconnectBackoff(16*time.Second))
+	assert.Equal(t, time.Minute, nextReconnectBackoff(32*time.Second))
+	assert.Equal(t, time.Minute, nextReconnectBackoff(time.Minute))
+}
+
+func TestStandardStream_Lifecycle(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	subscribeC := make(chan string, 10)
+	connC := make(chan *websocket.Conn, 10)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		subscribeC <- string(message)
+		connC <- conn
+
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"price":"48000"}`))
+
+		// keep reading until the connection is closed
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	stream := NewStandardStream()
+	stream.SetEndpointCreator(func(ctx context.Context) (string, error) {
+		return "ws" + strings.TrimPrefix(server.URL, "http"), nil
+	})
+	stream.SetSubscriptionBuilder(func(subscriptions []Subscription) ([]interface{}, error) {
+		var args []string
+		for _, sub := range subscriptions {
+			args = append(args, sub.Symbol)
+		}
+		return []interface{}{map[string]interface{}{"op": "subscribe", "args": args}}, nil
+	})
+	stream.SetParser(func(message []byte) (interface{}, error) {
+		var e map[string]string
+		err := json.Unmarshal(message, &e)
+		return e, err
+	})
+
+	eventC := make(chan map[string]string, 10)
+	stream.SetDispatcher(func(e interface{}) {
+		eventC <- e.(map[string]string)
+	})
+
+	connectC := make(chan struct{}, 10)
+	reconnectC := make(chan struct{}, 10)
+	stream.OnConnect(func() { connectC <- struct{}{} })
+	stream.OnReconnect(func() { reconnectC <- struct{}{} })
+
+	stream.Subscribe(KLineChannel, "BTCUSDT", SubscribeOptions{Interval: "1m"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := stream.Connect(ctx)
+	assert.NoError(t, err)
+
+	waitFor := func(c chan struct{}) bool {
+		select {
+		case <-c:
+			return true
+		case <-time.After(5 * time.Second):
+			return false
+		}
+	}
+
+	assert.True(t, waitFor(connectC))
+
+	select {
+	case message := <-subscribeC:
+		assert.JSONEq(t, `{"op":"subscribe","args":["BTCUSDT"]}`, message)
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription message is not received")
+	}
+
+	select {
+	case e := <-eventC:
+		assert.Equal(t, "48000", e["price"])
+	case <-time.After(5 * time.Second):
+		t.Fatal("event is not dispatched")
+	}
+
+	// drop the connection from the server side, the stream should reconnect and resubscribe
+	conn := <-connC
+	_ = conn.Close()
+
+	assert.True(t, waitFor(reconnectC))
+
+	select {
+	case message := <-subscribeC:
+		assert.JSONEq(t, `{"op":"subscribe","args":["BTCUSDT"]}`, message)
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription message is not received after reconnecting")
+	}
+
+	assert.NoError(t, stream.Close())
+	assert.True(t, stream.isClosed())
+}
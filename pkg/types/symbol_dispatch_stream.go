@@ -0,0 +1,192 @@
+package types
+
+import (
+	"context"
+	"sync"
+)
+
+const defaultSymbolDispatchQueueSize = 1024
+
+// symbolWorker runs the events of one symbol in order
+type symbolWorker struct {
+	C chan func()
+}
+
+// SymbolDispatchStream wraps the stream and dispatches the events of each symbol (trades, orders, klines and books)
+// on a dedicated worker goroutine, so that a slow handler of one busy symbol does not delay the events of the other
+// symbols. The events of the same symbol are still delivered in order.
+//
+// The connection events are emitted synchronously from the underlying stream. The account events (balances and
+// positions) are emitted after the queued symbol events are drained, so that the balance update of a fill is not
+// delivered before the trade and the order update of the fill.
+type SymbolDispatchStream struct {
+	StandardStream
+
+	// Stream is the underlying stream
+	Stream Stream
+
+	// QueueSize is the buffer size of the event queue of each symbol, the underlying stream is blocked when the
+	// queue is full, so that the events are not dropped.
+	QueueSize int
+
+	mu      sync.Mutex
+	workers map[string]*symbolWorker
+}
+
+func NewSymbolDispatchStream(stream Stream) *SymbolDispatchStream {
+	s := &SymbolDispatchStream{
+		StandardStream: NewStandardStream(),
+		Stream:         stream,
+		QueueSize:      defaultSymbolDispatchQueueSize,
+		workers:        make(map[string]*symbolWorker),
+	}
+	s.bind()
+	return s
+}
+
+func (s *SymbolDispatchStream) bind() {
+	s.Stream.OnStart(s.EmitStart)
+	s.Stream.OnConnect(s.EmitConnect)
+	s.Stream.OnDisconnect(s.EmitDisconnect)
+	s.Stream.OnReconnect(s.EmitReconnect)
+
+	s.Stream.OnBalanceSnapshot(func(balances BalanceMap) {
+		s.dispatchAccount(func() { s.EmitBalanceSnapshot(balances) })
+	})
+
+	s.Stream.OnBalanceUpdate(func(balances BalanceMap) {
+		s.dispatchAccount(func() { s.EmitBalanceUpdate(balances) })
+	})
+
+	s.Stream.OnPositionUpdate(func(positions PositionMap) {
+		s.dispatchAccount(func() { s.EmitPositionUpdate(positions) })
+	})
+
+	s.Stream.OnPositionSnapshot(func(positions PositionMap) {
+		s.dispatchAccount(func() { s.EmitPositionSnapshot(positions) })
+	})
+
+	s.Stream.OnTradeUpdate(func(trade Trade) {
+		s.dispatch(trade.Symbol, func() { s.EmitTradeUpdate(trade) })
+	})
+
+	s.Stream.OnOrderUpdate(func(order Order) {
+		s.dispatch(order.Symbol, func() { s.EmitOrderUpdate(order) })
+	})
+
+	s.Stream.OnKLine(func(kline KLine) {
+		s.dispatch(kline.Symbol, func() { s.EmitKLine(kline) })
+	})
+
+	s.Stream.OnKLineClosed(func(kline KLine) {
+		s.dispatch(kline.Symbol, func() { s.EmitKLineClosed(kline) })
+	})
+
+	s.Stream.OnBookUpdate(func(book SliceOrderBook) {
+		s.dispatch(book.Symbol, func() { s.EmitBookUpdate(book) })
+	})
+
+	s.Stream.OnBookSnapshot(func(book SliceOrderBook) {
+		s.dispatch(book.Symbol, func() { s.EmitBookSnapshot(book) })
+	})
+
+	s.Stream.OnMarketTrade(func(trade Trade) {
+		s.dispatch(trade.Symbol, func() { s.EmitMarketTrade(trade) })
+	})
+
+	s.Stream.OnFundingFee(func(fee FundingFee) {
+		s.dispatch(fee.Symbol, func() { s.EmitFundingFee(fee) })
+	})
+}
+
+// dispatch queues the event to the worker of the symbol, the event without symbol is emitted directly
+func (s *SymbolDispatchStream) dispatch(symbol string, emit func()) {
+	if symbol == "" {
+		emit()
+		return
+	}
+
+	s.mu.Lock()
+	worker, ok := s.workers[symbol]
+	if !ok {
+		select {
+		case <-s.CloseC:
+			s.mu.Unlock()
+			return
+		default:
+		}
+
+		worker = &symbolWorker{C: make(chan func(), s.QueueSize)}
+		s.workers[symbol] = worker
+		go s.work(worker)
+	}
+	s.mu.Unlock()
+
+	select {
+	case worker.C <- emit:
+	case <-s.CloseC:
+	}
+}
+
+// dispatchAccount emits the account event after the events queued before it are emitted by the workers
+func (s *SymbolDispatchStream) dispatchAccount(emit func()) {
+	s.mu.Lock()
+	workers := make([]*symbolWorker, 0, len(s.workers))
+	for _, worker := range s.workers {
+		workers = append(workers, worker)
+	}
+	s.mu.Unlock()
+
+	// the barrier is queued behind the pending events of each symbol
+	doneC := make(chan struct{}, len(workers))
+	for _, worker := range workers {
+		select {
+		case worker.C <- func() { doneC <- struct{}{} }:
+		case <-s.CloseC:
+			return
+		}
+	}
+
+	for range workers {
+		select {
+		case <-doneC:
+		case <-s.CloseC:
+			return
+		}
+	}
+
+	emit()
+}
+
+func (s *SymbolDispatchStream) work(worker *symbolWorker) {
+	for {
+		select {
+		case <-s.CloseC:
+			return
+
+		case emit := <-worker.C:
+			emit()
+		}
+	}
+}
+
+func (s *SymbolDispatchStream) Subscribe(channel Channel, symbol string, options SubscribeOptions) {
+	s.Stream.Subscribe(channel, symbol, options)
+}
+
+func (s *SymbolDispatchStream) SetPublicOnly() {
+	s.Stream.SetPublicOnly()
+}
+
+func (s *SymbolDispatchStream) Connect(ctx context.Context) error {
+	return s.Stream.Connect(ctx)
+}
+
+// Close closes the underlying stream and stops the workers, the queued events are dropped
+func (s *SymbolDispatchStream) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.CloseC)
+	})
+
+	return s.Stream.Close()
+}
@@ -0,0 +1,95 @@
+package types
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testStream struct {
+	StandardStream
+}
+
+func (s *testStream) SetPublicOnly() {}
+
+func TestSymbolDispatchStream(t *testing.T) {
+	source := &testStream{StandardStream: NewStandardStream()}
+	stream := NewSymbolDispatchStream(source)
+	defer stream.Close()
+
+	var mu sync.Mutex
+	var btcTrades []int64
+	blockC := make(chan struct{})
+	ethC := make(chan Trade, 1)
+	btcDoneC := make(chan struct{})
+
+	stream.OnTradeUpdate(func(trade Trade) {
+		switch trade.Symbol {
+		case "BTCUSDT":
+			// the first trade is a slow handler
+			if trade.ID == 1 {
+				<-blockC
+			}
+
+			mu.Lock()
+			btcTrades = append(btcTrades, trade.ID)
+			if len(btcTrades) == 3 {
+				close(btcDoneC)
+			}
+			mu.Unlock()
+
+		case "ETHUSDT":
+			ethC <- trade
+		}
+	})
+
+	balanceC := make(chan []int64, 1)
+	stream.OnBalanceUpdate(func(balances BalanceMap) {
+		mu.Lock()
+		balanceC <- append([]int64(nil), btcTrades...)
+		mu.Unlock()
+	})
+
+	source.EmitTradeUpdate(Trade{ID: 1, Symbol: "BTCUSDT"})
+	source.EmitTradeUpdate(Trade{ID: 2, Symbol: "BTCUSDT"})
+	source.EmitTradeUpdate(Trade{ID: 3, Symbol: "BTCUSDT"})
+	source.EmitTradeUpdate(Trade{ID: 4, Symbol: "ETHUSDT"})
+
+	// ETHUSDT is not blocked by the slow handler of BTCUSDT
+	select {
+	case trade := <-ethC:
+		assert.Equal(t, int64(4), trade.ID)
+	case <-time.After(time.Second):
+		t.Fatal("ETHUSDT trade is blocked")
+	}
+
+	// the balance update waits for the queued trades
+	go source.EmitBalanceUpdate(BalanceMap{})
+
+	select {
+	case <-balanceC:
+		t.Fatal("the balance update is emitted before the queued trades")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(blockC)
+
+	select {
+	case <-btcDoneC:
+	case <-time.After(time.Second):
+		t.Fatal("BTCUSDT trades are not delivered")
+	}
+
+	select {
+	case trades := <-balanceC:
+		assert.Equal(t, []int64{1, 2, 3}, trades)
+	case <-time.After(time.Second):
+		t.Fatal("the balance update is not delivered")
+	}
+
+	mu.Lock()
+	assert.Equal(t, []int64{1, 2, 3}, btcTrades)
+	mu.Unlock()
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/slack-go/slack"
 
+	"github.com/c9s/bbgo/pkg/slack/slackstyle"
 	"github.com/c9s/bbgo/pkg/util"
 )
 
@@ -128,6 +129,32 @@ func (trade Trade) SlackAttachment() slack.Attachment {
 	}
 }
 
+// SlackActionCancelAllOrders is the action ID of the "cancel all orders" button, the button value is the symbol
+const SlackActionCancelAllOrders = "cancel_all_orders"
+
+// SlackBlocks renders the trade as the message blocks with the button that cancels all the open orders of the symbol
+func (trade Trade) SlackBlocks() []slack.Block {
+	text := util.Render(slackTradeTextTemplate, trade)
+	fields := []*slack.TextBlockObject{
+		slackstyle.Field("Exchange", trade.Exchange.String()),
+		slackstyle.Field("Price", util.FormatFloat(trade.Price, 2)),
+		slackstyle.Field("Quantity", util.FormatFloat(trade.Quantity, 4)),
+		slackstyle.Field("QuoteQuantity", util.FormatFloat(trade.QuoteQuantity, 2)),
+		slackstyle.Field("Fee", util.FormatFloat(trade.Fee, 4)+" "+trade.FeeCurrency),
+		slackstyle.Field("Liquidity", trade.Liquidity()),
+		slackstyle.Field("Order ID", strconv.FormatUint(trade.OrderID, 10)),
+	}
+
+	return []slack.Block{
+		slack.NewSectionBlock(slackstyle.Markdown(text), fields, nil),
+		slack.NewContextBlock("", slackstyle.Markdown("trade time "+trade.Time.Time().Format(time.StampMilli))),
+		slack.NewActionBlock("",
+			slackstyle.ConfirmButton(SlackActionCancelAllOrders, trade.Symbol,
+				"Cancel all "+trade.Symbol+" orders",
+				"Cancel all the open orders of *"+trade.Symbol+"* on all the sessions?")),
+	}
+}
+
 func (trade Trade) Liquidity() (o string) {
 	if trade.IsMaker {
 		o += "MAKER"
@@ -0,0 +1,44 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrade_SlackBlocks(t *testing.T) {
+	trade := Trade{
+		ID:            1,
+		OrderID:       2,
+		Exchange:      ExchangeBinance,
+		Price:         50000.0,
+		Quantity:      0.1,
+		QuoteQuantity: 5000.0,
+		Symbol:        "BTCUSDT",
+		Side:          SideTypeBuy,
+		Fee:           0.0001,
+		FeeCurrency:   "BTC",
+		Time:          Time(time.Now()),
+	}
+
+	blocks := trade.SlackBlocks()
+	if assert.Len(t, blocks, 3) {
+		section, ok := blocks[0].(*slack.SectionBlock)
+		if assert.True(t, ok) {
+			assert.Contains(t, section.Text.Text, "BTCUSDT")
+			assert.Len(t, section.Fields, 7)
+		}
+
+		actions, ok := blocks[2].(*slack.ActionBlock)
+		if assert.True(t, ok) && assert.Len(t, actions.Elements.ElementSet, 1) {
+			button, ok := actions.Elements.ElementSet[0].(*slack.ButtonBlockElement)
+			if assert.True(t, ok) {
+				assert.Equal(t, SlackActionCancelAllOrders, button.ActionID)
+				assert.Equal(t, "BTCUSDT", button.Value)
+				assert.NotNil(t, button.Confirm)
+			}
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// WalletType is the wallet (account) of an exchange that holds the funds
+type WalletType string
+
+const (
+	WalletTypeSpot    WalletType = "spot"
+	WalletTypeMargin  WalletType = "margin"
+	WalletTypeFutures WalletType = "futures"
+)
+
+// ParseWalletType parses the wallet type case-insensitively
+func ParseWalletType(s string) (WalletType, error) {
+	switch t := WalletType(strings.ToLower(strings.TrimSpace(s))); t {
+	case WalletTypeSpot, WalletTypeMargin, WalletTypeFutures:
+		return t, nil
+	}
+
+	return "", fmt.Errorf("invalid wallet type %q, valid wallet types are: spot, margin, futures", s)
+}
+
+// ExchangeWalletTransferService moves the funds between the wallets of the same exchange account
+type ExchangeWalletTransferService interface {
+	TransferWallet(ctx context.Context, asset string, amount fixedpoint.Value, from, to WalletType) error
+}
@@ -1,8 +1,11 @@
 package types
 
 import (
+	"context"
 	"fmt"
 	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
 )
 
 type Withdraw struct {
@@ -35,3 +38,71 @@ type WithdrawalOptions struct {
 	AddressTag string
 }
 
+// WithdrawalNetwork is the withdrawal setting of an asset on one network
+type WithdrawalNetwork struct {
+	Asset   string           `json:"asset"`
+	Network string           `json:"network"`
+	Fee     fixedpoint.Value `json:"fee"`
+
+	MinAmount fixedpoint.Value `json:"minAmount"`
+
+	// MaxAmount is the max amount of one withdrawal, zero means no limit
+	MaxAmount fixedpoint.Value `json:"maxAmount,omitempty"`
+
+	Enabled   bool `json:"enabled"`
+	IsDefault bool `json:"isDefault"`
+}
+
+// Accepts returns true if the network is enabled and the amount is in the withdrawal limits
+func (n WithdrawalNetwork) Accepts(amount fixedpoint.Value) bool {
+	if !n.Enabled {
+		return false
+	}
+
+	if amount < n.MinAmount || amount <= n.Fee {
+		return false
+	}
+
+	return n.MaxAmount == 0 || amount <= n.MaxAmount
+}
+
+// ExchangeWithdrawalNetworkService is implemented by the exchanges that provide the withdrawal fee of each network
+type ExchangeWithdrawalNetworkService interface {
+	QueryWithdrawalNetworks(ctx context.Context, asset string) ([]WithdrawalNetwork, error)
+}
+
+// CheapestWithdrawalNetwork returns the network with the lowest fee that accepts the amount,
+// the candidates limit the networks that the receiver supports, empty candidates means any network.
+// The default network is preferred when the fees are equal.
+func CheapestWithdrawalNetwork(networks []WithdrawalNetwork, amount fixedpoint.Value, candidates ...string) (*WithdrawalNetwork, error) {
+	var allowed map[string]struct{}
+	if len(candidates) > 0 {
+		allowed = make(map[string]struct{}, len(candidates))
+		for _, c := range candidates {
+			allowed[c] = struct{}{}
+		}
+	}
+
+	var cheapest *WithdrawalNetwork
+	for i, n := range networks {
+		if allowed != nil {
+			if _, ok := allowed[n.Network]; !ok {
+				continue
+			}
+		}
+
+		if !n.Accepts(amount) {
+			continue
+		}
+
+		if cheapest == nil || n.Fee < cheapest.Fee || (n.Fee == cheapest.Fee && n.IsDefault) {
+			cheapest = &networks[i]
+		}
+	}
+
+	if cheapest == nil {
+		return nil, fmt.Errorf("no withdrawal network accepts the amount %f, candidates: %v", amount.Float64(), candidates)
+	}
+
+	return cheapest, nil
+}
@@ -0,0 +1,40 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func TestCheapestWithdrawalNetwork(t *testing.T) {
+	networks := []WithdrawalNetwork{
+		{Asset: "USDT", Network: "ETH", Fee: fixedpoint.NewFromFloat(10.0), MinAmount: fixedpoint.NewFromFloat(20.0), Enabled: true, IsDefault: true},
+		{Asset: "USDT", Network: "TRX", Fee: fixedpoint.NewFromFloat(1.0), MinAmount: fixedpoint.NewFromFloat(10.0), Enabled: true},
+		{Asset: "USDT", Network: "BSC", Fee: fixedpoint.NewFromFloat(0.8), MinAmount: fixedpoint.NewFromFloat(10.0), Enabled: false},
+		{Asset: "USDT", Network: "SOL", Fee: fixedpoint.NewFromFloat(1.0), MinAmount: fixedpoint.NewFromFloat(1.0), MaxAmount: fixedpoint.NewFromFloat(100.0), Enabled: true},
+	}
+
+	// the disabled network is skipped
+	n, err := CheapestWithdrawalNetwork(networks, fixedpoint.NewFromFloat(50.0))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "TRX", n.Network)
+	}
+
+	// the receiver only supports the given networks
+	n, err = CheapestWithdrawalNetwork(networks, fixedpoint.NewFromFloat(50.0), "ETH", "SOL")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "SOL", n.Network)
+	}
+
+	// the amount exceeds the max amount of SOL
+	n, err = CheapestWithdrawalNetwork(networks, fixedpoint.NewFromFloat(500.0), "ETH", "SOL")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "ETH", n.Network)
+	}
+
+	// less than the min amount of all the candidates
+	_, err = CheapestWithdrawalNetwork(networks, fixedpoint.NewFromFloat(5.0), "ETH", "TRX")
+	assert.Error(t, err)
+}